@@ -40,6 +40,7 @@
 //   - Stack traces are collected more liberally.
 //   - Metrics are written to stderr on exit.
 //   - The default logging level is set to debug.
+//   - The configureFailPoint command is available for injecting errors and latency in tests.
 //
 // Debug builds are orthogonal to production releases, development releases, and local/host builds.
 // For example, the host build could be made non-debug, and the production release, in theory, could be a debug build.
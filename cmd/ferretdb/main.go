@@ -35,7 +35,9 @@ import (
 	"github.com/FerretDB/FerretDB/build/version"
 	"github.com/FerretDB/FerretDB/internal/clientconn"
 	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/registry"
+	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/debug"
 	"github.com/FerretDB/FerretDB/internal/util/debugbuild"
 	"github.com/FerretDB/FerretDB/internal/util/logging"
@@ -60,6 +62,10 @@ var cli struct {
 		TLSCertFile string `default:""                help:"TLS cert file path."`
 		TLSKeyFile  string `default:""                help:"TLS key file path."`
 		TLSCAFile   string `default:""                help:"TLS CA file path." name:"tls-ca-file"`
+
+		HandshakeTimeout time.Duration `default:"30s" help:"Time for a client to send the first message; 0 disables."`
+		AcceptRateLimit  float64       `default:"0"   help:"Maximum new connections per second from a single IP address; 0 disables."`
+		ShutdownTimeout  time.Duration `default:"10s" help:"Time to wait for requests in progress on shutdown."`
 	} `embed:"" prefix:"listen-"`
 
 	ProxyAddr string `default:""                help:"Proxy address."`
@@ -71,16 +77,36 @@ var cli struct {
 	Log struct {
 		Level string `default:"${default_log_level}" help:"${help_log_level}"`
 		UUID  bool   `default:"false"                help:"Add instance UUID to all log messages." negatable:""`
+
+		File           string        `default:""    help:"Also write logs to the given file."`
+		FileMaxSize    int64         `default:"100" help:"Maximum log file size in MiB before rotation; 0 disables."`
+		FileMaxAge     time.Duration `default:"0s"  help:"Maximum log file age before rotation; 0 disables."`
+		FileMaxBackups int           `default:"10"  help:"Maximum number of rotated log files to keep; 0 keeps all."`
 	} `embed:"" prefix:"log-"`
 
 	MetricsUUID bool `default:"false" help:"Add instance UUID to all metrics." negatable:""`
 
 	Telemetry telemetry.Flag `default:"undecided" help:"Enable or disable basic telemetry. See https://beacon.ferretdb.io."`
 
+	NumericTypes string `default:"${default_numeric_types}" help:"${help_numeric_types}" enum:"${enum_numeric_types}"`
+
+	Maintenance        bool   `default:"false" help:"Start in maintenance mode that rejects write commands."`
+	MaintenanceMessage string `default:""      help:"Error message for write commands rejected in maintenance mode."`
+
+	EnableTestCommands bool `default:"false" help:"Enable test commands: sleep, waitForFailPoint, and configureFailPoint."`
+
 	Test struct {
 		RecordsDir            string `default:"" help:"Experimental: directory for record files."`
+		ShapeReportFile       string `default:"" help:"Experimental: file for response shape report in diff modes."`
 		DisableFilterPushdown bool   `default:"false" help:"Experimental: disable filter pushdown."`
 		EnableSortPushdown    bool   `default:"false" help:"Experimental: enable sort pushdown."`
+		DeterministicSeed     int64  `default:"0" help:"Experimental: deterministic ObjectIDs and time seed; 0 disables."`
+
+		//nolint:lll // for readability
+		Backends            map[string]string `default:"" help:"Experimental: additional backends for SQLite handler as name=URI pairs separated by ';'."`
+		BackendsMappingFile string            `default:"" help:"Experimental: JSON file with databases and collections mapping to backends."`
+		CacheCollections    map[string]int    `default:"" help:"Experimental: cache point reads of collections as db.collection=size pairs separated by ';'."`
+		ArchiveURL          string            `default:"" help:"Experimental: archive storage URL for archiveCollection and restoreCollection commands."`
 
 		//nolint:lll // for readability
 		Telemetry struct {
@@ -148,14 +174,19 @@ var (
 		kong.Vars{
 			"default_log_level":      defaultLogLevel().String(),
 			"default_mode":           clientconn.AllModes[0],
+			"default_numeric_types":  common.AllNumericTypes[0],
 			"default_postgresql_url": "postgres://127.0.0.1:5432/ferretdb",
 
 			"help_log_level":                 fmt.Sprintf("Log level: '%s'.", strings.Join(logLevels, "', '")),
 			"help_mode":                      fmt.Sprintf("Operation mode: '%s'.", strings.Join(clientconn.AllModes, "', '")),
 			"help_handler":                   fmt.Sprintf("Backend handler: '%s'.", strings.Join(registry.Handlers(), "', '")),
 			"help_telemetry_undecided_delay": "Experimental: telemetry: delay for undecided state.",
+			"help_numeric_types": fmt.Sprintf(
+				"Numeric types of administrative responses: '%s'.", strings.Join(common.AllNumericTypes, "', '"),
+			),
 
-			"enum_mode": strings.Join(clientconn.AllModes, ","),
+			"enum_mode":          strings.Join(clientconn.AllModes, ","),
+			"enum_numeric_types": strings.Join(common.AllNumericTypes, ","),
 		},
 		kong.DefaultEnvars("FERRETDB"),
 	}
@@ -238,7 +269,19 @@ func setupLogger(stateProvider *state.Provider) *zap.Logger {
 		log.Fatal(err)
 	}
 
-	logging.Setup(level, logUUID)
+	var file *logging.RotatingFile
+	if cli.Log.File != "" {
+		if file, err = logging.NewRotatingFile(&logging.NewRotatingFileOpts{
+			Path:       cli.Log.File,
+			MaxSize:    cli.Log.FileMaxSize * 1024 * 1024,
+			MaxAge:     cli.Log.FileMaxAge,
+			MaxBackups: cli.Log.FileMaxBackups,
+		}); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	logging.SetupWithFile(level, logUUID, file)
 	l := zap.L()
 
 	l.Info("Starting FerretDB "+info.Version+"...", startupFields...)
@@ -300,6 +343,10 @@ func run() {
 	// safe to always enable
 	runtime.SetBlockProfileRate(10000)
 
+	if seed := cli.Test.DeterministicSeed; seed != 0 {
+		types.SetDeterministic(seed)
+	}
+
 	stateProvider := setupState()
 
 	metricsRegisterer := setupMetrics(stateProvider)
@@ -351,6 +398,7 @@ func run() {
 		Logger:        logger,
 		ConnMetrics:   metrics.ConnMetrics,
 		StateProvider: stateProvider,
+		NumericTypes:  common.NumericTypes(cli.NumericTypes),
 
 		PostgreSQLURL: pgFlags.PostgreSQLURL,
 
@@ -361,6 +409,10 @@ func run() {
 		TestOpts: registry.TestOpts{
 			DisableFilterPushdown: cli.Test.DisableFilterPushdown,
 			EnableSortPushdown:    cli.Test.EnableSortPushdown,
+			Backends:              cli.Test.Backends,
+			BackendsMappingFile:   cli.Test.BackendsMappingFile,
+			CacheCollections:      cli.Test.CacheCollections,
+			ArchiveURL:            cli.Test.ArchiveURL,
 		},
 	})
 	if err != nil {
@@ -381,6 +433,16 @@ func run() {
 		Handler:        h,
 		Logger:         logger,
 		TestRecordsDir: cli.Test.RecordsDir,
+
+		HandshakeTimeout: cli.Listen.HandshakeTimeout,
+		AcceptRateLimit:  cli.Listen.AcceptRateLimit,
+		ShutdownTimeout:  cli.Listen.ShutdownTimeout,
+
+		Maintenance:        cli.Maintenance,
+		MaintenanceMessage: cli.MaintenanceMessage,
+
+		TestShapeReportFile: cli.Test.ShapeReportFile,
+		EnableTestCommands:  cli.EnableTestCommands,
 	})
 
 	metricsRegisterer.MustRegister(l)
@@ -393,6 +393,624 @@ func TestAggregateCompatCount(t *testing.T) {
 	testAggregateStagesCompat(t, testCases)
 }
 
+func TestAggregateCompatFacet(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Count": {
+			pipeline: bson.A{bson.D{{"$facet", bson.D{
+				{"count", bson.A{bson.D{{"$count", "n"}}}},
+			}}}},
+		},
+		"Multiple": {
+			pipeline: bson.A{bson.D{{"$facet", bson.D{
+				{"count", bson.A{bson.D{{"$count", "n"}}}},
+				{"top", bson.A{
+					bson.D{{"$sort", bson.D{{"_id", -1}}}},
+					bson.D{{"$limit", 2}},
+				}},
+				{"matched", bson.A{
+					bson.D{{"$match", bson.D{{"v", 42}}}},
+					bson.D{{"$sort", bson.D{{"_id", 1}}}},
+					bson.D{{"$project", bson.D{{"v", 1}}}},
+				}},
+			}}}},
+		},
+		"SameInput": {
+			pipeline: bson.A{bson.D{{"$facet", bson.D{
+				{"modified", bson.A{
+					bson.D{{"$sort", bson.D{{"_id", 1}}}},
+					bson.D{{"$addFields", bson.D{{"v", "modified"}}}},
+				}},
+				{"original", bson.A{
+					bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				}},
+			}}}},
+		},
+		"AfterMatch": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", 42}}}},
+				bson.D{{"$facet", bson.D{
+					{"ids", bson.A{
+						bson.D{{"$sort", bson.D{{"_id", 1}}}},
+						bson.D{{"$project", bson.D{{"_id", 1}}}},
+					}},
+				}}},
+			},
+		},
+		"NotObject": {
+			pipeline:   bson.A{bson.D{{"$facet", 1}}},
+			resultType: emptyResult,
+		},
+		"Empty": {
+			pipeline:   bson.A{bson.D{{"$facet", bson.D{}}}},
+			resultType: emptyResult,
+		},
+		"NotArray": {
+			pipeline:   bson.A{bson.D{{"$facet", bson.D{{"a", 1}}}}},
+			resultType: emptyResult,
+		},
+		"EmptyPipeline": {
+			pipeline:   bson.A{bson.D{{"$facet", bson.D{{"a", bson.A{}}}}}},
+			resultType: emptyResult,
+		},
+		"DollarName": {
+			pipeline:   bson.A{bson.D{{"$facet", bson.D{{"$a", bson.A{bson.D{{"$count", "n"}}}}}}}},
+			resultType: emptyResult,
+		},
+		"Nested": {
+			pipeline: bson.A{bson.D{{"$facet", bson.D{
+				{"a", bson.A{bson.D{{"$facet", bson.D{{"b", bson.A{bson.D{{"$count", "n"}}}}}}}}},
+			}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompat(t, testCases)
+}
+
+func TestAggregateCompatBucket(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Default": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{0, 10, 100}},
+				{"default", "other"},
+			}}}},
+		},
+		"DefaultLess": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{0, 10, 100}},
+				{"default", -1},
+			}}}},
+		},
+		"Output": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{"a", "m", "z"}},
+				{"default", "other"},
+				{"output", bson.D{
+					{"count", bson.D{{"$count", bson.D{}}}},
+					{"total", bson.D{{"$sum", "$v"}}},
+				}},
+			}}}},
+		},
+		"NoDefault": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{0, 10}},
+			}}}},
+			resultType: emptyResult,
+		},
+		"NotObject": {
+			pipeline:   bson.A{bson.D{{"$bucket", 1}}},
+			resultType: emptyResult,
+		},
+		"MissingBoundaries": {
+			pipeline:   bson.A{bson.D{{"$bucket", bson.D{{"groupBy", "$v"}}}}},
+			resultType: emptyResult,
+		},
+		"GroupByNotExpression": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "v"},
+				{"boundaries", bson.A{0, 10}},
+			}}}},
+			resultType: emptyResult,
+		},
+		"SingleBoundary": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{0}},
+			}}}},
+			resultType: emptyResult,
+		},
+		"UnsortedBoundaries": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{10, 0}},
+			}}}},
+			resultType: emptyResult,
+		},
+		"MixedBoundaries": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{0, "a"}},
+			}}}},
+			resultType: emptyResult,
+		},
+		"DefaultWithinBoundaries": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{0, 10}},
+				{"default", 5},
+			}}}},
+			resultType: emptyResult,
+		},
+		"UnknownField": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{0, 10}},
+				{"foo", 1},
+			}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompat(t, testCases)
+}
+
+func TestAggregateCompatBucketAuto(t *testing.T) {
+	t.Parallel()
+
+	// mixed types are not used as the order of values of different types in buckets is an implementation detail
+	providers := []shareddata.Provider{shareddata.Int32s, shareddata.Int64s, shareddata.Strings}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"One": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{{"groupBy", "$v"}, {"buckets", 1}}}}},
+		},
+		"Two": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{{"groupBy", "$v"}, {"buckets", 2}}}}},
+		},
+		"MoreThanDocuments": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{{"groupBy", "$v"}, {"buckets", 100}}}}},
+		},
+		"Output": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{
+				{"groupBy", "$v"},
+				{"buckets", int64(3)},
+				{"output", bson.D{{"n", bson.D{{"$count", bson.D{}}}}}},
+			}}}},
+		},
+		"NotObject": {
+			pipeline:   bson.A{bson.D{{"$bucketAuto", 1}}},
+			resultType: emptyResult,
+		},
+		"MissingBuckets": {
+			pipeline:   bson.A{bson.D{{"$bucketAuto", bson.D{{"groupBy", "$v"}}}}},
+			resultType: emptyResult,
+		},
+		"ZeroBuckets": {
+			pipeline:   bson.A{bson.D{{"$bucketAuto", bson.D{{"groupBy", "$v"}, {"buckets", 0}}}}},
+			resultType: emptyResult,
+		},
+		"FractionalBuckets": {
+			pipeline:   bson.A{bson.D{{"$bucketAuto", bson.D{{"groupBy", "$v"}, {"buckets", 1.5}}}}},
+			resultType: emptyResult,
+		},
+		"StringBuckets": {
+			pipeline:   bson.A{bson.D{{"$bucketAuto", bson.D{{"groupBy", "$v"}, {"buckets", "1"}}}}},
+			resultType: emptyResult,
+		},
+		"UnknownField": {
+			pipeline: bson.A{bson.D{{"$bucketAuto", bson.D{
+				{"groupBy", "$v"},
+				{"buckets", 1},
+				{"foo", 1},
+			}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
+
+func TestAggregateCompatSortByCount(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Path": {
+			pipeline: bson.A{
+				bson.D{{"$sortByCount", "$v"}},
+				bson.D{{"$sort", bson.D{{"count", -1}, {"_id", 1}}}},
+			},
+		},
+		"Expression": {
+			pipeline: bson.A{
+				bson.D{{"$sortByCount", bson.D{{"$type", "$v"}}}},
+				bson.D{{"$sort", bson.D{{"count", -1}, {"_id", 1}}}},
+			},
+		},
+		"NotPath": {
+			pipeline:   bson.A{bson.D{{"$sortByCount", "v"}}},
+			resultType: emptyResult,
+		},
+		"NotExpression": {
+			pipeline:   bson.A{bson.D{{"$sortByCount", bson.D{{"v", 1}}}}},
+			resultType: emptyResult,
+		},
+		"InvalidType": {
+			pipeline:   bson.A{bson.D{{"$sortByCount", 1}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompat(t, testCases)
+}
+
+func TestAggregateCompatSample(t *testing.T) {
+	t.Parallel()
+
+	// sampled documents are random, so only their number is compared
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Count": {
+			pipeline: bson.A{
+				bson.D{{"$sample", bson.D{{"size", 3}}}},
+				bson.D{{"$count", "count"}},
+			},
+		},
+		"CountDouble": {
+			pipeline: bson.A{
+				bson.D{{"$sample", bson.D{{"size", 2.9}}}},
+				bson.D{{"$count", "count"}},
+			},
+		},
+		"CountAfterMatch": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "number"}}}}}},
+				bson.D{{"$sample", bson.D{{"size", int64(2)}}}},
+				bson.D{{"$count", "count"}},
+			},
+		},
+		"All": {
+			pipeline: bson.A{
+				bson.D{{"$sample", bson.D{{"size", 1000}}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+		},
+		"NotObject": {
+			pipeline:   bson.A{bson.D{{"$sample", 1}}},
+			resultType: emptyResult,
+		},
+		"MissingSize": {
+			pipeline:   bson.A{bson.D{{"$sample", bson.D{}}}},
+			resultType: emptyResult,
+		},
+		"SizeString": {
+			pipeline:   bson.A{bson.D{{"$sample", bson.D{{"size", "1"}}}}},
+			resultType: emptyResult,
+		},
+		"SizeNegative": {
+			pipeline:   bson.A{bson.D{{"$sample", bson.D{{"size", -1}}}}},
+			resultType: emptyResult,
+		},
+		"UnknownOption": {
+			pipeline:   bson.A{bson.D{{"$sample", bson.D{{"size", 1}, {"foo", 1}}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompat(t, testCases)
+}
+
+func TestAggregateCompatReplaceRoot(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Document": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "object"}}}}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$replaceRoot", bson.D{{"newRoot", "$v"}}}},
+			},
+		},
+		"NewDocument": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$replaceRoot", bson.D{{"newRoot", bson.D{{"id", "$_id"}, {"value", "$v"}}}}}},
+			},
+		},
+		"Root": {
+			pipeline: bson.A{
+				bson.D{{"$replaceRoot", bson.D{{"newRoot", "$$ROOT"}}}},
+			},
+		},
+		"NotDocument": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$replaceRoot", bson.D{{"newRoot", "$v"}}}},
+			},
+			resultType: emptyResult,
+		},
+		"Missing": {
+			pipeline: bson.A{
+				bson.D{{"$replaceRoot", bson.D{{"newRoot", "$missing"}}}},
+			},
+			resultType: emptyResult,
+		},
+		"NotObject": {
+			pipeline:   bson.A{bson.D{{"$replaceRoot", "$v"}}},
+			resultType: emptyResult,
+		},
+		"NoNewRoot": {
+			pipeline:   bson.A{bson.D{{"$replaceRoot", bson.D{}}}},
+			resultType: emptyResult,
+		},
+		"UnknownField": {
+			pipeline:   bson.A{bson.D{{"$replaceRoot", bson.D{{"newRoot", "$v"}, {"foo", 1}}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompat(t, testCases)
+}
+
+func TestAggregateCompatReplaceWith(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Document": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "object"}}}}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$replaceWith", "$v"}},
+			},
+		},
+		"NewDocument": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$replaceWith", bson.D{{"id", "$_id"}, {"value", "$v"}, {"missing", "$missing"}}}},
+			},
+		},
+		"NotDocument": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$replaceWith", "$v"}},
+			},
+			resultType: emptyResult,
+		},
+		"Literal": {
+			pipeline:   bson.A{bson.D{{"$replaceWith", 1}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompat(t, testCases)
+}
+
+func TestAggregateCompatDensify(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Bounds": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "int"}}}}}},
+				bson.D{{"$densify", bson.D{
+					{"field", "v"},
+					{"range", bson.D{{"step", 1}, {"bounds", bson.A{-2, 3}}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"v", 1}, {"_id", 1}}}},
+			},
+		},
+		"NotObject": {
+			pipeline:   bson.A{bson.D{{"$densify", 1}}},
+			resultType: emptyResult,
+		},
+		"MissingField": {
+			pipeline:   bson.A{bson.D{{"$densify", bson.D{{"range", bson.D{{"step", 1}, {"bounds", "full"}}}}}}},
+			resultType: emptyResult,
+		},
+		"MissingRange": {
+			pipeline:   bson.A{bson.D{{"$densify", bson.D{{"field", "v"}}}}},
+			resultType: emptyResult,
+		},
+		"UnknownField": {
+			pipeline: bson.A{bson.D{{"$densify", bson.D{
+				{"field", "v"},
+				{"range", bson.D{{"step", 1}, {"bounds", "full"}}},
+				{"foo", 1},
+			}}}},
+			resultType: emptyResult,
+		},
+		"FieldType": {
+			pipeline:   bson.A{bson.D{{"$densify", bson.D{{"field", 1}, {"range", bson.D{{"step", 1}, {"bounds", "full"}}}}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompat(t, testCases)
+}
+
+func TestAggregateCompatFill(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Value": {
+			pipeline: bson.A{bson.D{{"$fill", bson.D{{"output", bson.D{{"v", bson.D{{"value", "filled"}}}}}}}}},
+		},
+		"ValueExpression": {
+			pipeline: bson.A{bson.D{{"$fill", bson.D{{"output", bson.D{{"v", bson.D{{"value", "$_id"}}}}}}}}},
+		},
+		"NotObject": {
+			pipeline:   bson.A{bson.D{{"$fill", 1}}},
+			resultType: emptyResult,
+		},
+		"MissingOutput": {
+			pipeline:   bson.A{bson.D{{"$fill", bson.D{}}}},
+			resultType: emptyResult,
+		},
+		"UnknownField": {
+			pipeline: bson.A{bson.D{{"$fill", bson.D{
+				{"output", bson.D{{"v", bson.D{{"value", 1}}}}},
+				{"foo", 1},
+			}}}},
+			resultType: emptyResult,
+		},
+		"OutputType": {
+			pipeline:   bson.A{bson.D{{"$fill", bson.D{{"output", 1}}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompat(t, testCases)
+}
+
+func TestAggregateCompatRedact(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Keep": {
+			pipeline: bson.A{bson.D{{"$redact", "$$KEEP"}}},
+		},
+		"Prune": {
+			pipeline:   bson.A{bson.D{{"$redact", "$$PRUNE"}}},
+			resultType: emptyResult,
+		},
+		"Descend": {
+			pipeline: bson.A{bson.D{{"$redact", "$$DESCEND"}}},
+		},
+		"CondDocument": {
+			pipeline: bson.A{bson.D{{"$redact", bson.D{{"$cond", bson.D{
+				{"if", "$v"},
+				{"then", "$$DESCEND"},
+				{"else", "$$PRUNE"},
+			}}}}}},
+		},
+		"CondArray": {
+			pipeline: bson.A{bson.D{{"$redact", bson.D{{"$cond", bson.A{"$v", "$$KEEP", "$$PRUNE"}}}}}},
+		},
+		"CondNested": {
+			pipeline: bson.A{bson.D{{"$redact", bson.D{{"$cond", bson.D{
+				{"if", "$_id"},
+				{"then", bson.D{{"$cond", bson.A{"$v", "$$DESCEND", "$$PRUNE"}}}},
+				{"else", "$$DESCEND"},
+			}}}}}},
+		},
+		"InvalidResult": {
+			pipeline:   bson.A{bson.D{{"$redact", "$v"}}},
+			resultType: emptyResult,
+		},
+		"UndefinedVariable": {
+			pipeline:   bson.A{bson.D{{"$redact", "$$FOO"}}},
+			resultType: emptyResult,
+		},
+		"CondMissingIf": {
+			pipeline:   bson.A{bson.D{{"$redact", bson.D{{"$cond", bson.D{{"then", "$$KEEP"}, {"else", "$$PRUNE"}}}}}}},
+			resultType: emptyResult,
+		},
+		"CondMissingElse": {
+			pipeline:   bson.A{bson.D{{"$redact", bson.D{{"$cond", bson.D{{"if", true}, {"then", "$$KEEP"}}}}}}},
+			resultType: emptyResult,
+		},
+		"CondUnknownParam": {
+			pipeline: bson.A{bson.D{{"$redact", bson.D{{"$cond", bson.D{
+				{"if", true}, {"then", "$$KEEP"}, {"else", "$$PRUNE"}, {"foo", 1},
+			}}}}}},
+			resultType: emptyResult,
+		},
+		"CondWrongArgs": {
+			pipeline:   bson.A{bson.D{{"$redact", bson.D{{"$cond", bson.A{true, "$$KEEP"}}}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompat(t, testCases)
+}
+
+func TestAggregateCompatSetWindowFields(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"Rank": {
+			pipeline: bson.A{
+				bson.D{{"$setWindowFields", bson.D{
+					{"sortBy", bson.D{{"v", 1}}},
+					{"output", bson.D{
+						{"rank", bson.D{{"$rank", bson.D{}}}},
+						{"denseRank", bson.D{{"$denseRank", bson.D{}}}},
+					}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+		},
+		"SumPartition": {
+			pipeline: bson.A{
+				bson.D{{"$setWindowFields", bson.D{
+					{"partitionBy", bson.D{{"$type", "$v"}}},
+					{"output", bson.D{{"total", bson.D{{"$sum", "$v"}}}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+		},
+		"AvgDocuments": {
+			pipeline: bson.A{
+				bson.D{{"$setWindowFields", bson.D{
+					{"sortBy", bson.D{{"_id", 1}}},
+					{"output", bson.D{{"avg", bson.D{
+						{"$avg", "$v"},
+						{"window", bson.D{{"documents", bson.A{-1, "current"}}}},
+					}}}},
+				}}},
+			},
+		},
+		"SumDocumentsUnbounded": {
+			pipeline: bson.A{
+				bson.D{{"$setWindowFields", bson.D{
+					{"sortBy", bson.D{{"_id", 1}}},
+					{"output", bson.D{{"total", bson.D{
+						{"$sum", int32(1)},
+						{"window", bson.D{{"documents", bson.A{"unbounded", "current"}}}},
+					}}}},
+				}}},
+			},
+		},
+		"MissingOutput": {
+			pipeline:   bson.A{bson.D{{"$setWindowFields", bson.D{}}}},
+			resultType: emptyResult,
+		},
+		"NotObject": {
+			pipeline:   bson.A{bson.D{{"$setWindowFields", 1}}},
+			resultType: emptyResult,
+		},
+		"RankWithoutSortBy": {
+			pipeline: bson.A{bson.D{{"$setWindowFields", bson.D{
+				{"output", bson.D{{"rank", bson.D{{"$rank", bson.D{}}}}}},
+			}}}},
+			resultType: emptyResult,
+		},
+		"DocumentsWithoutSortBy": {
+			pipeline: bson.A{bson.D{{"$setWindowFields", bson.D{
+				{"output", bson.D{{"total", bson.D{
+					{"$sum", "$v"},
+					{"window", bson.D{{"documents", bson.A{-1, 1}}}},
+				}}}},
+			}}}},
+			resultType: emptyResult,
+		},
+		"UnknownFunction": {
+			pipeline: bson.A{bson.D{{"$setWindowFields", bson.D{
+				{"output", bson.D{{"v", bson.D{{"$foo", "$v"}}}}},
+			}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testAggregateStagesCompat(t, testCases)
+}
+
 func TestAggregateCompatGroupDeterministicCollections(t *testing.T) {
 	t.Parallel()
 
@@ -1237,6 +1855,42 @@ func TestAggregateCompatGroupSum(t *testing.T) {
 	testAggregateStagesCompatWithProviders(t, providers, testCases)
 }
 
+func TestAggregateCompatGroupAvg(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{shareddata.Int32s, shareddata.Strings}
+
+	testCases := map[string]aggregateStagesCompatTestCase{
+		"GroupNullID": {
+			pipeline: bson.A{
+				bson.D{{"$group", bson.D{
+					{"_id", nil},
+					{"avg", bson.D{{"$avg", "$v"}}},
+				}}},
+			},
+		},
+		"GroupByID": {
+			pipeline: bson.A{
+				bson.D{{"$group", bson.D{
+					{"_id", "$_id"},
+					{"avg", bson.D{{"$avg", "$v"}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", -1}}}},
+			},
+		},
+		"NonExistent": {
+			pipeline: bson.A{
+				bson.D{{"$group", bson.D{
+					{"_id", nil},
+					{"avg", bson.D{{"$avg", "$non-existent"}}},
+				}}},
+			},
+		},
+	}
+
+	testAggregateStagesCompatWithProviders(t, providers, testCases)
+}
+
 func TestAggregateCompatMatch(t *testing.T) {
 	t.Parallel()
 
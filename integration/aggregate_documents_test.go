@@ -17,12 +17,14 @@ package integration
 import (
 	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/integration/setup"
 )
@@ -1248,3 +1250,1126 @@ func TestAggregateCommandCursor(t *testing.T) {
 		})
 	}
 }
+
+func TestAggregateOut(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", 1}, {"v", "foo"}},
+		bson.D{{"_id", 2}, {"v", "bar"}},
+		bson.D{{"_id", 3}, {"v", "baz"}},
+	})
+	require.NoError(t, err)
+
+	target := collection.Database().Collection(collection.Name() + "_out")
+
+	_, err = target.InsertOne(ctx, bson.D{{"_id", 42}, {"v", "replaced"}})
+	require.NoError(t, err)
+
+	cursor, err := collection.Aggregate(ctx, bson.A{
+		bson.D{{"$match", bson.D{{"_id", bson.D{{"$gt", 1}}}}}},
+		bson.D{{"$out", target.Name()}},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, FetchAll(t, ctx, cursor))
+
+	expected := []bson.D{
+		{{"_id", int32(2)}, {"v", "bar"}},
+		{{"_id", int32(3)}, {"v", "baz"}},
+	}
+	AssertEqualDocumentsSlice(t, expected, FindAll(t, ctx, target))
+
+	t.Run("Document", func(t *testing.T) {
+		cursor, err := collection.Aggregate(ctx, bson.A{
+			bson.D{{"$project", bson.D{{"v", 0}}}},
+			bson.D{{"$out", bson.D{{"db", collection.Database().Name()}, {"coll", target.Name()}}}},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, FetchAll(t, ctx, cursor))
+
+		expected := []bson.D{{{"_id", int32(1)}}, {{"_id", int32(2)}}, {{"_id", int32(3)}}}
+		AssertEqualDocumentsSlice(t, expected, FindAll(t, ctx, target))
+	})
+}
+
+func TestAggregateMerge(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:vet // used for test only
+		merge    any      // required, $merge stage argument
+		expected []bson.D // expected documents of the target collection
+		err      *mongo.CommandError
+		skip     string // optional, skip test with a specified reason
+	}{
+		"String": {
+			merge: "target",
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", "foo"}, {"w", "old"}},
+				{{"_id", int32(2)}, {"v", "bar"}},
+				{{"_id", int32(42)}, {"v", "old"}},
+			},
+		},
+		"Replace": {
+			merge: bson.D{{"into", "target"}, {"whenMatched", "replace"}},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", "foo"}},
+				{{"_id", int32(2)}, {"v", "bar"}},
+				{{"_id", int32(42)}, {"v", "old"}},
+			},
+		},
+		"KeepExistingDiscard": {
+			merge: bson.D{{"into", "target"}, {"whenMatched", "keepExisting"}, {"whenNotMatched", "discard"}},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", "old"}, {"w", "old"}},
+				{{"_id", int32(42)}, {"v", "old"}},
+			},
+		},
+		"Pipeline": {
+			merge: bson.D{
+				{"into", bson.D{{"coll", "target"}}},
+				{"whenMatched", bson.A{bson.D{{"$set", bson.D{{"w", "new"}}}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", "old"}, {"w", "new"}},
+				{{"_id", int32(2)}, {"v", "bar"}},
+				{{"_id", int32(42)}, {"v", "old"}},
+			},
+		},
+		"WhenMatchedFail": {
+			merge: bson.D{{"into", "target"}, {"whenMatched", "fail"}},
+			err: &mongo.CommandError{
+				Code: 11000,
+				Name: "DuplicateKey",
+			},
+		},
+		"WhenNotMatchedFail": {
+			merge: bson.D{{"into", "target"}, {"whenNotMatched", "fail"}},
+			err: &mongo.CommandError{
+				Code: 13113,
+				Name: "MergeStageNoMatchingDocument",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			if tc.skip != "" {
+				t.Skip(tc.skip)
+			}
+
+			t.Parallel()
+
+			ctx, collection := setup.Setup(t)
+
+			_, err := collection.InsertMany(ctx, []any{
+				bson.D{{"_id", 1}, {"v", "foo"}},
+				bson.D{{"_id", 2}, {"v", "bar"}},
+			})
+			require.NoError(t, err)
+
+			target := collection.Database().Collection("target")
+
+			_, err = target.InsertMany(ctx, []any{
+				bson.D{{"_id", 1}, {"v", "old"}, {"w", "old"}},
+				bson.D{{"_id", 42}, {"v", "old"}},
+			})
+			require.NoError(t, err)
+
+			cursor, err := collection.Aggregate(ctx, bson.A{bson.D{{"$merge", tc.merge}}})
+			if tc.err != nil {
+				AssertMatchesCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Empty(t, FetchAll(t, ctx, cursor))
+
+			AssertEqualDocumentsSlice(t, tc.expected, FindAll(t, ctx, target))
+		})
+	}
+}
+
+func TestAggregateOutMergeErrors(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:vet // used for test only
+		pipeline bson.A // required, aggregation pipeline stages
+
+		err        *mongo.CommandError // required
+		altMessage string              // optional, alternative error message
+		skip       string              // optional, skip test with a specified reason
+	}{
+		"OutNotLast": {
+			pipeline: bson.A{
+				bson.D{{"$out", "target"}},
+				bson.D{{"$match", bson.D{}}},
+			},
+			err: &mongo.CommandError{
+				Code:    40601,
+				Name:    "Location40601",
+				Message: "$out can only be the final stage in the pipeline",
+			},
+		},
+		"MergeNotLast": {
+			pipeline: bson.A{
+				bson.D{{"$merge", "target"}},
+				bson.D{{"$match", bson.D{}}},
+			},
+			err: &mongo.CommandError{
+				Code:    40601,
+				Name:    "Location40601",
+				Message: "$merge can only be the final stage in the pipeline",
+			},
+		},
+		"OutNotLastInvalidNextStage": {
+			pipeline: bson.A{
+				bson.D{{"$out", "target"}},
+				bson.D{{"$match", 42}},
+			},
+			err: &mongo.CommandError{
+				Code:    15959,
+				Name:    "Location15959",
+				Message: "the match filter must be an expression in an object",
+			},
+		},
+		"OutInvalidType": {
+			pipeline: bson.A{bson.D{{"$out", 42}}},
+			err: &mongo.CommandError{
+				Code:    16990,
+				Name:    "Location16990",
+				Message: "$out only supports a string or object argument, but found int",
+			},
+		},
+		"MergeInvalidType": {
+			pipeline: bson.A{bson.D{{"$merge", 42}}},
+			err: &mongo.CommandError{
+				Code:    51182,
+				Name:    "Location51182",
+				Message: "$merge only supports a string or object argument, but found int",
+			},
+		},
+		"MergeInvalidWhenMatched": {
+			pipeline: bson.A{bson.D{{"$merge", bson.D{{"into", "target"}, {"whenMatched", "foo"}}}}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "Enumeration value 'foo' for field '$merge.whenMatched' is not a valid value.",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			if tc.skip != "" {
+				t.Skip(tc.skip)
+			}
+
+			t.Parallel()
+
+			require.NotNil(t, tc.pipeline, "pipeline must not be nil")
+			require.NotNil(t, tc.err, "err must not be nil")
+
+			ctx, collection := setup.Setup(t)
+
+			_, err := collection.Aggregate(ctx, tc.pipeline)
+			AssertEqualAltCommandError(t, *tc.err, tc.altMessage, err)
+		})
+	}
+}
+
+func TestAggregateBucketStages(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", 1}, {"v", 1}},
+		bson.D{{"_id", 2}, {"v", 5}},
+		bson.D{{"_id", 3}, {"v", 5}},
+		bson.D{{"_id", 4}, {"v", 12}},
+		bson.D{{"_id", 5}, {"v", 42}},
+		bson.D{{"_id", 6}, {"v", "foo"}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		pipeline bson.A   // required
+		expected []bson.D // required
+	}{
+		"Bucket": {
+			pipeline: bson.A{bson.D{{"$bucket", bson.D{
+				{"groupBy", "$v"},
+				{"boundaries", bson.A{0, 10, 20}},
+				{"default", "other"},
+				{"output", bson.D{{"n", bson.D{{"$count", bson.D{}}}}, {"total", bson.D{{"$sum", "$v"}}}}},
+			}}}},
+			expected: []bson.D{
+				{{"_id", int32(0)}, {"n", int32(3)}, {"total", int32(11)}},
+				{{"_id", int32(10)}, {"n", int32(1)}, {"total", int32(12)}},
+				{{"_id", "other"}, {"n", int32(2)}, {"total", int32(42)}},
+			},
+		},
+		"BucketAuto": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$type", "number"}}}}}},
+				bson.D{{"$bucketAuto", bson.D{{"groupBy", "$v"}, {"buckets", 3}}}},
+			},
+			expected: []bson.D{
+				{{"_id", bson.D{{"min", int32(1)}, {"max", int32(12)}}}, {"count", int32(3)}},
+				{{"_id", bson.D{{"min", int32(12)}, {"max", int32(42)}}}, {"count", int32(2)}},
+			},
+		},
+		"SortByCount": {
+			pipeline: bson.A{
+				bson.D{{"$sortByCount", "$v"}},
+				bson.D{{"$limit", 1}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(5)}, {"count", int32(2)}},
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, tc.pipeline)
+			require.NoError(t, err)
+
+			AssertEqualDocumentsSlice(t, tc.expected, FetchAll(t, ctx, cursor))
+		})
+	}
+}
+
+func TestAggregateSample(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	var docs []any
+	for i := 0; i < 20; i++ {
+		docs = append(docs, bson.D{{"_id", int32(i)}})
+	}
+
+	_, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		pipeline bson.A // required
+		expected int    // required
+	}{
+		"Size": {
+			pipeline: bson.A{bson.D{{"$sample", bson.D{{"size", 5}}}}},
+			expected: 5,
+		},
+		"SizeTooBig": {
+			pipeline: bson.A{bson.D{{"$sample", bson.D{{"size", 100}}}}},
+			expected: 20,
+		},
+		"AfterMatch": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"_id", bson.D{{"$lt", 10}}}}}},
+				bson.D{{"$sample", bson.D{{"size", 5}}}},
+			},
+			expected: 5,
+		},
+		"Zero": {
+			pipeline: bson.A{bson.D{{"$sample", bson.D{{"size", 0}}}}},
+			expected: 0,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, tc.pipeline)
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+			require.Len(t, res, tc.expected)
+
+			seen := map[int32]struct{}{}
+
+			for _, doc := range res {
+				id := doc[0].Value.(int32)
+				assert.True(t, id >= 0 && id < 20, "unexpected _id %d", id)
+
+				if name == "AfterMatch" {
+					assert.Less(t, id, int32(10))
+				}
+
+				assert.NotContains(t, seen, id, "duplicate _id %d", id)
+				seen[id] = struct{}{}
+			}
+		})
+	}
+}
+
+func TestAggregateSetWindowFields(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", 1}, {"g", "a"}, {"v", 1}},
+		bson.D{{"_id", 2}, {"g", "a"}, {"v", 5}},
+		bson.D{{"_id", 3}, {"g", "a"}, {"v", 5}},
+		bson.D{{"_id", 4}, {"g", "a"}, {"v", 12}},
+		bson.D{{"_id", 5}, {"g", "b"}, {"v", 42}},
+		bson.D{{"_id", 6}, {"g", "b"}, {"v", 2}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		pipeline bson.A   // required
+		expected []bson.D // required
+	}{
+		"Rank": {
+			pipeline: bson.A{
+				bson.D{{"$setWindowFields", bson.D{
+					{"partitionBy", "$g"},
+					{"sortBy", bson.D{{"v", 1}}},
+					{"output", bson.D{
+						{"rank", bson.D{{"$rank", bson.D{}}}},
+						{"dense", bson.D{{"$denseRank", bson.D{}}}},
+					}},
+				}}},
+				bson.D{{"$project", bson.D{{"rank", 1}, {"dense", 1}}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"rank", int32(1)}, {"dense", int32(1)}},
+				{{"_id", int32(2)}, {"rank", int32(2)}, {"dense", int32(2)}},
+				{{"_id", int32(3)}, {"rank", int32(2)}, {"dense", int32(2)}},
+				{{"_id", int32(4)}, {"rank", int32(4)}, {"dense", int32(3)}},
+				{{"_id", int32(5)}, {"rank", int32(2)}, {"dense", int32(2)}},
+				{{"_id", int32(6)}, {"rank", int32(1)}, {"dense", int32(1)}},
+			},
+		},
+		"SumPartition": {
+			pipeline: bson.A{
+				bson.D{{"$setWindowFields", bson.D{
+					{"partitionBy", "$g"},
+					{"output", bson.D{{"total", bson.D{{"$sum", "$v"}}}}},
+				}}},
+				bson.D{{"$project", bson.D{{"total", 1}}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"total", int32(23)}},
+				{{"_id", int32(2)}, {"total", int32(23)}},
+				{{"_id", int32(3)}, {"total", int32(23)}},
+				{{"_id", int32(4)}, {"total", int32(23)}},
+				{{"_id", int32(5)}, {"total", int32(44)}},
+				{{"_id", int32(6)}, {"total", int32(44)}},
+			},
+		},
+		"MovingAvgDocuments": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"g", "a"}}}},
+				bson.D{{"$setWindowFields", bson.D{
+					{"sortBy", bson.D{{"_id", 1}}},
+					{"output", bson.D{{"avg", bson.D{
+						{"$avg", "$v"},
+						{"window", bson.D{{"documents", bson.A{-1, "current"}}}},
+					}}}},
+				}}},
+				bson.D{{"$project", bson.D{{"avg", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"avg", float64(1)}},
+				{{"_id", int32(2)}, {"avg", float64(3)}},
+				{{"_id", int32(3)}, {"avg", float64(5)}},
+				{{"_id", int32(4)}, {"avg", 8.5}},
+			},
+		},
+		"SumRange": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"g", "a"}}}},
+				bson.D{{"$setWindowFields", bson.D{
+					{"sortBy", bson.D{{"v", 1}}},
+					{"output", bson.D{{"total", bson.D{
+						{"$sum", "$v"},
+						{"window", bson.D{{"range", bson.A{-4, 0}}}},
+					}}}},
+				}}},
+				bson.D{{"$project", bson.D{{"total", 1}}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"total", int32(1)}},
+				{{"_id", int32(2)}, {"total", int32(11)}},
+				{{"_id", int32(3)}, {"total", int32(11)}},
+				{{"_id", int32(4)}, {"total", int32(12)}},
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, tc.pipeline)
+			require.NoError(t, err)
+
+			AssertEqualDocumentsSlice(t, tc.expected, FetchAll(t, ctx, cursor))
+		})
+	}
+}
+
+func TestAggregateDensify(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	day := func(d int) time.Time {
+		return time.Date(2023, time.January, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", 1}, {"p", "a"}, {"v", int32(1)}, {"t", day(1)}},
+		bson.D{{"_id", 2}, {"p", "a"}, {"v", int32(4)}, {"t", day(3)}},
+		bson.D{{"_id", 3}, {"p", "b"}, {"v", int32(2)}, {"t", day(2)}},
+		bson.D{{"_id", 4}, {"p", "b"}, {"v", int32(5)}, {"t", day(4)}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		pipeline bson.A   // required
+		expected []bson.D // required
+	}{
+		"Numbers": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"p", "a"}}}},
+				bson.D{{"$densify", bson.D{
+					{"field", "v"},
+					{"range", bson.D{{"step", 1}, {"bounds", bson.A{0, 6}}}},
+				}}},
+				bson.D{{"$project", bson.D{{"_id", 1}, {"v", 1}}}},
+			},
+			expected: []bson.D{
+				{{"v", int32(0)}},
+				{{"_id", int32(1)}, {"v", int32(1)}},
+				{{"v", int32(2)}},
+				{{"v", int32(3)}},
+				{{"_id", int32(2)}, {"v", int32(4)}},
+				{{"v", int32(5)}},
+			},
+		},
+		"PartitionFull": {
+			pipeline: bson.A{
+				bson.D{{"$densify", bson.D{
+					{"field", "v"},
+					{"partitionByFields", bson.A{"p"}},
+					{"range", bson.D{{"step", 2}, {"bounds", "full"}}},
+				}}},
+				bson.D{{"$replaceWith", bson.D{{"p", "$p"}, {"v", "$v"}}}},
+			},
+			expected: []bson.D{
+				{{"p", "a"}, {"v", int32(1)}},
+				{{"p", "a"}, {"v", int32(3)}},
+				{{"p", "a"}, {"v", int32(4)}},
+				{{"p", "a"}, {"v", int32(5)}},
+				{{"p", "b"}, {"v", int32(1)}},
+				{{"p", "b"}, {"v", int32(2)}},
+				{{"p", "b"}, {"v", int32(3)}},
+				{{"p", "b"}, {"v", int32(5)}},
+			},
+		},
+		"PartitionPartition": {
+			pipeline: bson.A{
+				bson.D{{"$densify", bson.D{
+					{"field", "v"},
+					{"partitionByFields", bson.A{"p"}},
+					{"range", bson.D{{"step", 2}, {"bounds", "partition"}}},
+				}}},
+				bson.D{{"$replaceWith", bson.D{{"p", "$p"}, {"v", "$v"}}}},
+			},
+			expected: []bson.D{
+				{{"p", "a"}, {"v", int32(1)}},
+				{{"p", "a"}, {"v", int32(3)}},
+				{{"p", "a"}, {"v", int32(4)}},
+				{{"p", "b"}, {"v", int32(2)}},
+				{{"p", "b"}, {"v", int32(4)}},
+				{{"p", "b"}, {"v", int32(5)}},
+			},
+		},
+		"Dates": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"p", "b"}}}},
+				bson.D{{"$densify", bson.D{
+					{"field", "t"},
+					{"range", bson.D{{"step", 1}, {"unit", "day"}, {"bounds", "full"}}},
+				}}},
+				bson.D{{"$project", bson.D{{"_id", 0}, {"t", 1}}}},
+			},
+			expected: []bson.D{
+				{{"t", primitive.NewDateTimeFromTime(day(2))}},
+				{{"t", primitive.NewDateTimeFromTime(day(3))}},
+				{{"t", primitive.NewDateTimeFromTime(day(4))}},
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, tc.pipeline)
+			require.NoError(t, err)
+
+			AssertEqualDocumentsSlice(t, tc.expected, FetchAll(t, ctx, cursor))
+		})
+	}
+}
+
+func TestAggregateFill(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", 1}, {"p", "a"}, {"v", int32(10)}},
+		bson.D{{"_id", 2}, {"p", "a"}, {"v", nil}},
+		bson.D{{"_id", 3}, {"p", "a"}},
+		bson.D{{"_id", 4}, {"p", "a"}, {"v", int32(40)}},
+		bson.D{{"_id", 5}, {"p", "b"}},
+		bson.D{{"_id", 6}, {"p", "b"}, {"v", 1.5}},
+		bson.D{{"_id", 7}, {"p", "b"}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		pipeline bson.A   // required
+		expected []bson.D // required
+	}{
+		"Value": {
+			pipeline: bson.A{
+				bson.D{{"$fill", bson.D{{"output", bson.D{{"v", bson.D{{"value", "$p"}}}}}}}},
+				bson.D{{"$project", bson.D{{"v", 1}}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", int32(10)}},
+				{{"_id", int32(2)}, {"v", "a"}},
+				{{"_id", int32(3)}, {"v", "a"}},
+				{{"_id", int32(4)}, {"v", int32(40)}},
+				{{"_id", int32(5)}, {"v", "b"}},
+				{{"_id", int32(6)}, {"v", 1.5}},
+				{{"_id", int32(7)}, {"v", "b"}},
+			},
+		},
+		"LOCF": {
+			pipeline: bson.A{
+				bson.D{{"$fill", bson.D{
+					{"partitionByFields", bson.A{"p"}},
+					{"sortBy", bson.D{{"_id", 1}}},
+					{"output", bson.D{{"v", bson.D{{"method", "locf"}}}}},
+				}}},
+				bson.D{{"$project", bson.D{{"v", 1}}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", int32(10)}},
+				{{"_id", int32(2)}, {"v", int32(10)}},
+				{{"_id", int32(3)}, {"v", int32(10)}},
+				{{"_id", int32(4)}, {"v", int32(40)}},
+				{{"_id", int32(5)}, {"v", nil}},
+				{{"_id", int32(6)}, {"v", 1.5}},
+				{{"_id", int32(7)}, {"v", 1.5}},
+			},
+		},
+		"Linear": {
+			pipeline: bson.A{
+				bson.D{{"$fill", bson.D{
+					{"partitionBy", "$p"},
+					{"sortBy", bson.D{{"_id", 1}}},
+					{"output", bson.D{{"v", bson.D{{"method", "linear"}}}}},
+				}}},
+				bson.D{{"$project", bson.D{{"v", 1}}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"v", int32(10)}},
+				{{"_id", int32(2)}, {"v", float64(20)}},
+				{{"_id", int32(3)}, {"v", float64(30)}},
+				{{"_id", int32(4)}, {"v", int32(40)}},
+				{{"_id", int32(5)}, {"v", nil}},
+				{{"_id", int32(6)}, {"v", 1.5}},
+				{{"_id", int32(7)}, {"v", nil}},
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, tc.pipeline)
+			require.NoError(t, err)
+
+			AssertEqualDocumentsSlice(t, tc.expected, FetchAll(t, ctx, cursor))
+		})
+	}
+}
+
+func TestAggregateRedact(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{
+			{"_id", 1},
+			{"visible", true},
+			{"public", bson.D{{"visible", true}, {"v", "a"}}},
+			{"secret", bson.D{{"visible", false}, {"v", "b"}}},
+			{"items", bson.A{
+				bson.D{{"visible", 1}, {"v", "c"}},
+				bson.D{{"visible", 0}, {"v", "d"}},
+				bson.D{{"visible", nil}},
+				bson.D{{"visible", "yes"}},
+				int32(42),
+			}},
+		},
+		bson.D{{"_id", 2}, {"visible", false}},
+		bson.D{{"_id", 3}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		pipeline bson.A   // required
+		expected []bson.D // required
+	}{
+		"Descend": {
+			pipeline: bson.A{
+				bson.D{{"$redact", bson.D{{"$cond", bson.D{
+					{"if", "$visible"},
+					{"then", "$$DESCEND"},
+					{"else", "$$PRUNE"},
+				}}}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+			expected: []bson.D{{
+				{"_id", int32(1)},
+				{"visible", true},
+				{"public", bson.D{{"visible", true}, {"v", "a"}}},
+				{"items", bson.A{
+					bson.D{{"visible", int32(1)}, {"v", "c"}},
+					bson.D{{"visible", "yes"}},
+					int32(42),
+				}},
+			}},
+		},
+		"Keep": {
+			pipeline: bson.A{
+				bson.D{{"$redact", bson.D{{"$cond", bson.A{"$visible", "$$KEEP", "$$PRUNE"}}}}},
+				bson.D{{"$project", bson.D{{"public", 1}, {"secret", 1}}}},
+			},
+			expected: []bson.D{{
+				{"_id", int32(1)},
+				{"public", bson.D{{"visible", true}, {"v", "a"}}},
+				{"secret", bson.D{{"visible", false}, {"v", "b"}}},
+			}},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, tc.pipeline)
+			require.NoError(t, err)
+
+			AssertEqualDocumentsSlice(t, tc.expected, FetchAll(t, ctx, cursor))
+		})
+	}
+}
+
+func TestAggregateReplaceRootErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", 1}, {"v", int32(42)}})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		pipeline bson.A             // required
+		err      mongo.CommandError // required
+	}{
+		"ReplaceRootNotObject": {
+			pipeline: bson.A{bson.D{{"$replaceRoot", bson.D{{"newRoot", "$v"}}}}},
+			err: mongo.CommandError{
+				Code: 40228,
+				Name: "Location40228",
+				Message: "'newRoot' expression must evaluate to an object, but resulting value was: 42. " +
+					"Type of resulting value: 'int'.",
+			},
+		},
+		"ReplaceWithMissing": {
+			pipeline: bson.A{bson.D{{"$replaceWith", "$missing"}}},
+			err: mongo.CommandError{
+				Code: 40228,
+				Name: "Location40228",
+				Message: "'replacement document' must evaluate to an object, but resulting value was: MISSING. " +
+					"Type of resulting value: 'missing'.",
+			},
+		},
+		"RedactInvalidResult": {
+			pipeline: bson.A{bson.D{{"$redact", "$v"}}},
+			err: mongo.CommandError{
+				Code: 17053,
+				Name: "Location17053",
+				Message: "$redact's expression should not return anything aside from the variables " +
+					"$$KEEP, $$DESCEND, and $$PRUNE, but returned 42",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, tc.pipeline)
+			if err == nil {
+				// errors are returned on first getMore for pipelines that are not evaluated eagerly
+				defer cursor.Close(ctx)
+				cursor.Next(ctx)
+				err = cursor.Err()
+			}
+
+			AssertMatchesCommandError(t, tc.err, err)
+		})
+	}
+}
+
+func TestAggregateGeoNear(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	insertGeoDocuments(t, ctx, collection)
+
+	for name, tc := range map[string]struct {
+		pipeline  bson.A             // required
+		expected  []bson.D           // optional
+		distances map[string]float64 // optional, expected distances by _id in returned order
+		err       *mongo.CommandError
+	}{
+		"Spherical": {
+			pipeline: bson.A{
+				bson.D{{"$geoNear", bson.D{
+					{"near", bson.D{{"type", "Point"}, {"coordinates", bson.A{0.0, 0.0}}}},
+					{"key", "loc"},
+					{"distanceField", "dist"},
+					{"maxDistance", 200_000},
+					{"distanceMultiplier", 0.001},
+					{"spherical", true},
+				}}},
+			},
+			distances: map[string]float64{"a": 0, "b": 157.425},
+		},
+		"LegacyQueryIncludeLocs": {
+			pipeline: bson.A{
+				bson.D{{"$geoNear", bson.D{
+					{"near", bson.A{0.0, 0.0}},
+					{"key", "loc"},
+					{"distanceField", "dist"},
+					{"includeLocs", "where"},
+					{"query", bson.D{{"_id", bson.D{{"$ne", "a"}}}}},
+					{"maxDistance", 2.5},
+				}}},
+				bson.D{{"$project", bson.D{{"where", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", "b"}, {"where", bson.A{1.0, 1.0}}},
+				{{"_id", "c"}, {"where", bson.D{{"type", "Point"}, {"coordinates", bson.A{2.0, 0.0}}}}},
+			},
+		},
+		"NotFirstStage": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{}}},
+				bson.D{{"$geoNear", bson.D{
+					{"near", bson.A{0.0, 0.0}},
+					{"key", "loc"},
+					{"distanceField", "dist"},
+				}}},
+			},
+			err: &mongo.CommandError{
+				Code: 40603,
+				Name: "Location40603",
+			},
+		},
+		"MissingDistanceField": {
+			pipeline: bson.A{
+				bson.D{{"$geoNear", bson.D{
+					{"near", bson.A{0.0, 0.0}},
+					{"key", "loc"},
+				}}},
+			},
+			err: &mongo.CommandError{
+				Code: 2,
+				Name: "BadValue",
+			},
+		},
+		"MatchNear": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"loc", bson.D{{"$near", bson.A{0.0, 0.0}}}}}}},
+			},
+			err: &mongo.CommandError{
+				Code: 2,
+				Name: "BadValue",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, tc.pipeline)
+			if tc.err != nil {
+				assert.Nil(t, cursor)
+				AssertMatchesCommandError(t, *tc.err, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			res := FetchAll(t, ctx, cursor)
+
+			if tc.distances == nil {
+				AssertEqualDocumentsSlice(t, tc.expected, res)
+				return
+			}
+
+			require.Len(t, res, len(tc.distances))
+
+			for _, doc := range res {
+				m := doc.Map()
+				assert.InDelta(t, tc.distances[m["_id"].(string)], m["dist"], 0.001, "%v", doc)
+			}
+
+			ids := CollectIDs(t, res)
+			assert.True(t, slices.IsSortedFunc(ids, func(a, b any) int {
+				switch da, db := tc.distances[a.(string)], tc.distances[b.(string)]; {
+				case da < db:
+					return -1
+				case da > db:
+					return 1
+				default:
+					return 0
+				}
+			}))
+		})
+	}
+}
+
+func TestAggregateServerSideJavaScript(t *testing.T) {
+	setup.SkipForMongoDB(t, "MongoDB executes server-side JavaScript")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", 1}, {"v", int32(42)}})
+	require.NoError(t, err)
+
+	expected := mongo.CommandError{
+		Code:    31264,
+		Name:    "Location31264",
+		Message: "Cannot run server-side javascript without the javascript engine enabled",
+	}
+
+	for name, pipeline := range map[string]bson.A{
+		"Function": {bson.D{{"$project", bson.D{{"v", bson.D{{"$function", bson.D{
+			{"body", "function(v) { return v; }"},
+			{"args", bson.A{"$v"}},
+			{"lang", "js"},
+		}}}}}}}},
+		"Accumulator": {bson.D{{"$group", bson.D{{"_id", nil}, {"v", bson.D{{"$accumulator", bson.D{
+			{"init", "function() { return 0; }"},
+			{"accumulate", "function(s, v) { return s + v; }"},
+			{"accumulateArgs", bson.A{"$v"}},
+			{"merge", "function(a, b) { return a + b; }"},
+			{"lang", "js"},
+		}}}}}}}},
+		"FunctionInMatch": {bson.D{{"$match", bson.D{{"$expr", bson.D{{"$function", bson.D{
+			{"body", "function(v) { return v == 42; }"},
+			{"args", bson.A{"$v"}},
+			{"lang", "js"},
+		}}}}}}}},
+	} {
+		name, pipeline := name, pipeline
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, pipeline)
+			if err == nil {
+				defer cursor.Close(ctx)
+				cursor.Next(ctx)
+				err = cursor.Err()
+			}
+
+			AssertEqualCommandError(t, expected, err)
+		})
+	}
+}
+
+func TestAggregateOptimizedPipeline(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"k", "a"}, {"v", int32(1)}, {"w", "foo"}},
+		bson.D{{"_id", int32(2)}, {"k", "b"}, {"v", int32(2)}, {"w", "bar"}},
+		bson.D{{"_id", int32(3)}, {"k", "a"}, {"v", int32(3)}, {"w", "baz"}},
+		bson.D{{"_id", int32(4)}, {"k", "b"}, {"v", int32(4)}},
+	})
+	require.NoError(t, err)
+
+	// $match stages are moved before $sort and merged,
+	// and only fields used by $group are kept
+	pipeline := bson.A{
+		bson.D{{"$sort", bson.D{{"_id", -1}}}},
+		bson.D{{"$match", bson.D{{"v", bson.D{{"$gt", int32(1)}}}}}},
+		bson.D{{"$match", bson.D{{"k", bson.D{{"$ne", "z"}}}}}},
+		bson.D{{"$limit", int32(10)}},
+		bson.D{{"$limit", int32(3)}},
+		bson.D{{"$group", bson.D{{"_id", "$k"}, {"sum", bson.D{{"$sum", "$v"}}}}}},
+		bson.D{{"$sort", bson.D{{"_id", 1}}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	require.NoError(t, err)
+
+	var res []bson.D
+	require.NoError(t, cursor.All(ctx, &res))
+
+	expected := []bson.D{
+		{{"_id", "a"}, {"sum", int32(3)}},
+		{{"_id", "b"}, {"sum", int32(6)}},
+	}
+	assert.Equal(t, expected, res)
+
+	if setup.IsMongoDB(t) {
+		return
+	}
+
+	var explain bson.D
+	err = collection.Database().RunCommand(ctx, bson.D{{"explain", bson.D{
+		{"aggregate", collection.Name()},
+		{"pipeline", pipeline},
+		{"cursor", bson.D{}},
+	}}}).Decode(&explain)
+	require.NoError(t, err)
+
+	m := explain.Map()
+
+	queryPlanner, ok := m["queryPlanner"].(bson.D)
+	require.True(t, ok)
+	assert.Equal(t, true, queryPlanner.Map()["optimizedPipeline"])
+
+	stages, ok := m["stages"].(bson.A)
+	require.True(t, ok)
+
+	var names []string
+	for _, s := range stages {
+		names = append(names, s.(bson.D)[0].Key)
+	}
+
+	assert.Equal(t, []string{"$match", "$sort", "$project", "$limit", "$group", "$sort"}, names)
+}
+
+func TestAggregateGroupPushdown(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"k", "a"}, {"v", int32(1)}, {"f", true}},
+		bson.D{{"_id", int32(2)}, {"k", "b"}, {"v", int64(2)}, {"f", false}},
+		bson.D{{"_id", int32(3)}, {"k", "a"}, {"v", int32(3)}, {"f", true}},
+		bson.D{{"_id", int32(4)}, {"v", "4"}, {"f", true}},
+		bson.D{{"_id", int32(5)}, {"k", nil}, {"v", int32(5)}, {"d", 0.5}},
+		bson.D{{"_id", int32(6)}, {"k", int32(1)}, {"v", int32(6)}, {"d", 1.5}},
+		bson.D{{"_id", int32(7)}, {"k", int64(1)}, {"v", int32(7)}, {"d", bson.A{int32(1)}}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		pipeline bson.A   // required
+		expected []bson.D // required
+	}{
+		"GroupBy": {
+			pipeline: bson.A{
+				bson.D{{"$group", bson.D{
+					{"_id", "$k"},
+					{"count", bson.D{{"$count", bson.D{}}}},
+					{"sum", bson.D{{"$sum", "$v"}}},
+					{"avg", bson.D{{"$avg", "$v"}}},
+					{"ones", bson.D{{"$sum", int32(1)}}},
+				}}},
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			},
+			expected: []bson.D{
+				{{"_id", nil}, {"count", int32(2)}, {"sum", int32(5)}, {"avg", 5.0}, {"ones", int32(2)}},
+				{{"_id", int32(1)}, {"count", int32(2)}, {"sum", int32(13)}, {"avg", 6.5}, {"ones", int32(2)}},
+				{{"_id", "a"}, {"count", int32(2)}, {"sum", int32(4)}, {"avg", 2.0}, {"ones", int32(2)}},
+				{{"_id", "b"}, {"count", int32(1)}, {"sum", int64(2)}, {"avg", 2.0}, {"ones", int32(1)}},
+			},
+		},
+		"Match": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"f", true}}}},
+				bson.D{{"$group", bson.D{{"_id", nil}, {"count", bson.D{{"$sum", int32(1)}}}, {"avg", bson.D{{"$avg", "$v"}}}}}},
+			},
+			expected: []bson.D{
+				{{"_id", nil}, {"count", int32(3)}, {"avg", 2.0}},
+			},
+		},
+		"MatchEmpty": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"k", bson.D{{"$eq", "z"}}}}}},
+				bson.D{{"$group", bson.D{{"_id", nil}, {"count", bson.D{{"$count", bson.D{}}}}}}},
+			},
+			expected: []bson.D{},
+		},
+		"SumDoubles": {
+			pipeline: bson.A{
+				bson.D{{"$group", bson.D{{"_id", nil}, {"sum", bson.D{{"$sum", "$d"}}}}}},
+			},
+			expected: []bson.D{
+				{{"_id", nil}, {"sum", 2.0}},
+			},
+		},
+		"GroupByArray": {
+			pipeline: bson.A{
+				bson.D{{"$group", bson.D{{"_id", "$d"}, {"count", bson.D{{"$count", bson.D{}}}}}}},
+			},
+			expected: []bson.D{
+				{{"_id", nil}, {"count", int32(4)}},
+				{{"_id", 0.5}, {"count", int32(1)}},
+				{{"_id", 1.5}, {"count", int32(1)}},
+				{{"_id", bson.A{int32(1)}}, {"count", int32(1)}},
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, tc.pipeline)
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+
+			if len(tc.expected) == 0 {
+				assert.Empty(t, res)
+				return
+			}
+
+			// the order of groups is not specified
+			assert.ElementsMatch(t, tc.expected, res)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestApplyOps(t *testing.T) {
+	t.Parallel()
+
+	// applyOps is implemented only by SQLite handler
+	if !setup.IsSQLite(t) {
+		t.Skip("applyOps is supported only by SQLite backend")
+	}
+
+	ctx, collection := setup.Setup(t)
+	admin := collection.Database().Client().Database("admin")
+	ns := collection.Database().Name() + "." + collection.Name()
+
+	var res bson.D
+	err := admin.RunCommand(ctx, bson.D{
+		{"applyOps", bson.A{
+			bson.D{{"op", "i"}, {"ns", ns}, {"o", bson.D{{"_id", int32(1)}}}},
+			bson.D{{"op", "i"}, {"ns", ns}, {"o", bson.D{{"_id", int32(2)}}}},
+			bson.D{
+				{"op", "u"},
+				{"ns", ns},
+				{"o", bson.D{{"$v", int32(1)}, {"$set", bson.D{{"v", int32(42)}}}}},
+				{"o2", bson.D{{"_id", int32(1)}}},
+			},
+			bson.D{
+				{"op", "u"},
+				{"ns", ns},
+				{"o", bson.D{{"_id", int32(3)}, {"v", "upserted"}}},
+				{"o2", bson.D{{"_id", int32(3)}}},
+				{"b", true},
+			},
+			bson.D{{"op", "d"}, {"ns", ns}, {"o", bson.D{{"_id", int32(2)}}}},
+			bson.D{{"op", "n"}, {"ns", ""}, {"o", bson.D{{"msg", "noop"}}}},
+		}},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	expected := bson.D{
+		{"applied", int32(6)},
+		{"results", bson.A{true, true, true, true, true, true}},
+		{"ok", float64(1)},
+	}
+	AssertEqualDocuments(t, expected, res)
+
+	expectedDocs := []bson.D{
+		{{"_id", int32(1)}, {"v", int32(42)}},
+		{{"_id", int32(3)}, {"v", "upserted"}},
+	}
+	AssertEqualDocumentsSlice(t, expectedDocs, FindAll(t, ctx, collection))
+}
+
+func TestApplyOpsErrors(t *testing.T) {
+	t.Parallel()
+
+	if !setup.IsSQLite(t) {
+		t.Skip("applyOps is supported only by SQLite backend")
+	}
+
+	ctx, collection := setup.Setup(t)
+	admin := collection.Database().Client().Database("admin")
+	ns := collection.Database().Name() + "." + collection.Name()
+
+	for name, tc := range map[string]struct {
+		db      *mongo.Database
+		command bson.D
+		err     *mongo.CommandError
+	}{
+		"NotAdmin": {
+			db: collection.Database(),
+			command: bson.D{
+				{"applyOps", bson.A{bson.D{{"op", "i"}, {"ns", ns}, {"o", bson.D{}}}}},
+			},
+			err: &mongo.CommandError{
+				Code:    13,
+				Name:    "Unauthorized",
+				Message: "applyOps may only be run against the admin database.",
+			},
+		},
+		"InvalidNamespace": {
+			db: admin,
+			command: bson.D{
+				{"applyOps", bson.A{bson.D{{"op", "i"}, {"ns", "invalid"}, {"o", bson.D{}}}}},
+			},
+			err: &mongo.CommandError{
+				Code:    73,
+				Name:    "InvalidNamespace",
+				Message: "Invalid namespace specified 'invalid'",
+			},
+		},
+		"InvalidOpType": {
+			db: admin,
+			command: bson.D{
+				{"applyOps", bson.A{bson.D{{"op", "x"}, {"ns", ns}, {"o", bson.D{}}}}},
+			},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "applyOps entry 0 has an invalid operation type 'x'",
+			},
+		},
+		"MissingO2": {
+			db: admin,
+			command: bson.D{
+				{"applyOps", bson.A{bson.D{{"op", "u"}, {"ns", ns}, {"o", bson.D{{"$set", bson.D{{"v", int32(1)}}}}}}}},
+			},
+			err: &mongo.CommandError{
+				Code:    40414,
+				Name:    "Location40414",
+				Message: "applyOps entry 0 of type 'u' is missing the required field 'o2'",
+			},
+		},
+		"DeltaUpdate": {
+			db: admin,
+			command: bson.D{
+				{"applyOps", bson.A{bson.D{
+					{"op", "u"},
+					{"ns", ns},
+					{"o", bson.D{{"$v", int32(2)}, {"diff", bson.D{{"u", bson.D{{"v", int32(1)}}}}}}},
+					{"o2", bson.D{{"_id", int32(1)}}},
+				}}},
+			},
+			err: &mongo.CommandError{
+				Code:    238,
+				Name:    "NotImplemented",
+				Message: "applyOps entry 0: only update oplog entries with '$v: 1' are supported",
+			},
+		},
+		"DuplicateKey": {
+			db: admin,
+			command: bson.D{
+				{"applyOps", bson.A{
+					bson.D{{"op", "i"}, {"ns", ns}, {"o", bson.D{{"_id", "dup"}}}},
+					bson.D{{"op", "i"}, {"ns", ns}, {"o", bson.D{{"_id", "dup"}}}},
+				}},
+			},
+			err: &mongo.CommandError{
+				Code:    11000,
+				Name:    "DuplicateKey",
+				Message: "applyOps entry 1 failed: E11000 duplicate key error collection: " + ns,
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.db.RunCommand(ctx, tc.command).Err()
+			AssertEqualCommandError(t, *tc.err, err)
+		})
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestBulkWrite(t *testing.T) {
+	t.Parallel()
+
+	// bulkWrite is available only in MongoDB 8.0+
+	if !setup.IsSQLite(t) {
+		t.Skip("bulkWrite is supported only by SQLite backend")
+	}
+
+	for name, tc := range map[string]struct {
+		ordered bool
+
+		expectedBatch    bson.A
+		expectedCounts   bson.D
+		expectedDocs     []bson.D
+		expectedOtherDoc []bson.D
+	}{
+		"Ordered": {
+			ordered: true,
+			expectedBatch: bson.A{
+				bson.D{{"ok", float64(1)}, {"idx", int32(0)}, {"n", int32(1)}},
+				bson.D{{"ok", float64(1)}, {"idx", int32(1)}, {"n", int32(1)}},
+				bson.D{{"ok", float64(1)}, {"idx", int32(2)}, {"n", int32(1)}},
+				bson.D{{"ok", float64(1)}, {"idx", int32(3)}, {"n", int32(1)}, {"nModified", int32(1)}},
+				bson.D{{"ok", float64(1)}, {"idx", int32(4)}, {"n", int32(1)}},
+				bson.D{{"ok", float64(0)}, {"idx", int32(5)}, {"code", int32(11000)}, {"codeName", "DuplicateKey"}},
+			},
+			expectedCounts: bson.D{
+				{"nErrors", int32(1)},
+				{"nInserted", int32(3)},
+				{"nMatched", int32(1)},
+				{"nModified", int32(1)},
+				{"nUpserted", int32(0)},
+				{"nDeleted", int32(1)},
+			},
+			expectedDocs:     []bson.D{{{"_id", int32(1)}, {"v", int32(42)}}},
+			expectedOtherDoc: []bson.D{{{"_id", "a"}}},
+		},
+		"Unordered": {
+			ordered: false,
+			expectedBatch: bson.A{
+				bson.D{{"ok", float64(1)}, {"idx", int32(0)}, {"n", int32(1)}},
+				bson.D{{"ok", float64(1)}, {"idx", int32(1)}, {"n", int32(1)}},
+				bson.D{{"ok", float64(1)}, {"idx", int32(2)}, {"n", int32(1)}},
+				bson.D{{"ok", float64(1)}, {"idx", int32(3)}, {"n", int32(1)}, {"nModified", int32(1)}},
+				bson.D{{"ok", float64(1)}, {"idx", int32(4)}, {"n", int32(1)}},
+				bson.D{{"ok", float64(0)}, {"idx", int32(5)}, {"code", int32(11000)}, {"codeName", "DuplicateKey"}},
+				bson.D{{"ok", float64(1)}, {"idx", int32(6)}, {"n", int32(1)}},
+			},
+			expectedCounts: bson.D{
+				{"nErrors", int32(1)},
+				{"nInserted", int32(4)},
+				{"nMatched", int32(1)},
+				{"nModified", int32(1)},
+				{"nUpserted", int32(0)},
+				{"nDeleted", int32(1)},
+			},
+			expectedDocs:     []bson.D{{{"_id", int32(1)}, {"v", int32(42)}}, {{"_id", int32(3)}}},
+			expectedOtherDoc: []bson.D{{{"_id", "a"}}},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, collection := setup.Setup(t)
+			other := collection.Database().Collection(collection.Name() + "_other")
+			admin := collection.Database().Client().Database("admin")
+
+			var res bson.D
+			err := admin.RunCommand(ctx, bson.D{
+				{"bulkWrite", int32(1)},
+				{"ops", bson.A{
+					bson.D{{"insert", int32(0)}, {"document", bson.D{{"_id", int32(1)}}}},
+					bson.D{{"insert", int32(0)}, {"document", bson.D{{"_id", int32(2)}}}},
+					bson.D{{"insert", int32(1)}, {"document", bson.D{{"_id", "a"}}}},
+					bson.D{
+						{"update", int32(0)},
+						{"filter", bson.D{{"_id", int32(1)}}},
+						{"updateMods", bson.D{{"$set", bson.D{{"v", int32(42)}}}}},
+					},
+					bson.D{{"delete", int32(0)}, {"filter", bson.D{{"_id", int32(2)}}}},
+					bson.D{{"insert", int32(1)}, {"document", bson.D{{"_id", "a"}}}},
+					bson.D{{"insert", int32(0)}, {"document", bson.D{{"_id", int32(3)}}}},
+				}},
+				{"nsInfo", bson.A{
+					bson.D{{"ns", collection.Database().Name() + "." + collection.Name()}},
+					bson.D{{"ns", collection.Database().Name() + "." + other.Name()}},
+				}},
+				{"ordered", tc.ordered},
+			}).Decode(&res)
+			require.NoError(t, err)
+
+			// error messages are not compared
+			var actual bson.A
+			for _, r := range res.Map()["cursor"].(bson.D).Map()["firstBatch"].(bson.A) {
+				d := r.(bson.D)
+
+				var filtered bson.D
+				for _, e := range d {
+					if e.Key != "errmsg" && !(e.Key == "n" && d.Map()["ok"] == float64(0)) { //nolint:staticcheck // for clarity
+						filtered = append(filtered, e)
+					}
+				}
+
+				actual = append(actual, filtered)
+			}
+
+			assert.Equal(t, tc.expectedBatch, actual)
+
+			for _, e := range tc.expectedCounts {
+				assert.Equal(t, e.Value, res.Map()[e.Key], e.Key)
+			}
+
+			AssertEqualDocumentsSlice(t, tc.expectedDocs, FindAll(t, ctx, collection))
+			AssertEqualDocumentsSlice(t, tc.expectedOtherDoc, FindAll(t, ctx, other))
+		})
+	}
+}
+
+func TestBulkWriteErrors(t *testing.T) {
+	t.Parallel()
+
+	if !setup.IsSQLite(t) {
+		t.Skip("bulkWrite is supported only by SQLite backend")
+	}
+
+	ctx, collection := setup.Setup(t)
+	admin := collection.Database().Client().Database("admin")
+	ns := collection.Database().Name() + "." + collection.Name()
+
+	for name, tc := range map[string]struct {
+		db      *mongo.Database
+		command bson.D
+		err     *mongo.CommandError
+	}{
+		"NotAdmin": {
+			db: collection.Database(),
+			command: bson.D{
+				{"bulkWrite", int32(1)},
+				{"ops", bson.A{bson.D{{"insert", int32(0)}, {"document", bson.D{}}}}},
+				{"nsInfo", bson.A{bson.D{{"ns", ns}}}},
+			},
+			err: &mongo.CommandError{
+				Code:    13,
+				Name:    "Unauthorized",
+				Message: "bulkWrite may only be run against the admin database.",
+			},
+		},
+		"EmptyOps": {
+			db: admin,
+			command: bson.D{
+				{"bulkWrite", int32(1)},
+				{"ops", bson.A{}},
+				{"nsInfo", bson.A{bson.D{{"ns", ns}}}},
+			},
+			err: &mongo.CommandError{
+				Code:    16,
+				Name:    "InvalidLength",
+				Message: "Write batch sizes must be between 1 and 100000. Got 0 operations.",
+			},
+		},
+		"InvalidNsInfoIndex": {
+			db: admin,
+			command: bson.D{
+				{"bulkWrite", int32(1)},
+				{"ops", bson.A{bson.D{{"insert", int32(1)}, {"document", bson.D{}}}}},
+				{"nsInfo", bson.A{bson.D{{"ns", ns}}}},
+			},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "BulkWrite ops entry 0 has an invalid nsInfo index.",
+			},
+		},
+		"MissingDocument": {
+			db: admin,
+			command: bson.D{
+				{"bulkWrite", int32(1)},
+				{"ops", bson.A{bson.D{{"insert", int32(0)}}}},
+				{"nsInfo", bson.A{bson.D{{"ns", ns}}}},
+			},
+			err: &mongo.CommandError{
+				Code:    40414,
+				Name:    "Location40414",
+				Message: "BSON field 'bulkWrite.ops.document' is missing but a required field",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.db.RunCommand(ctx, tc.command).Err()
+			AssertEqualCommandError(t, *tc.err, err)
+		})
+	}
+}
@@ -0,0 +1,327 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+// skipForCapped skips the test for backends that do not support capped collections yet.
+func skipForCapped(t *testing.T) {
+	t.Helper()
+
+	if !setup.IsMongoDB(t) && !setup.IsSQLite(t) {
+		t.Skip("https://github.com/FerretDB/FerretDB/issues/2283")
+	}
+}
+
+func TestCappedCollectionEviction(t *testing.T) {
+	t.Parallel()
+
+	skipForCapped(t)
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	opts := options.CreateCollection().SetCapped(true).SetSizeInBytes(1 << 20).SetMaxDocuments(3)
+	err := db.CreateCollection(ctx, collection.Name(), opts)
+	require.NoError(t, err)
+
+	for i := int32(1); i <= 5; i++ {
+		_, err = collection.InsertOne(ctx, bson.D{{"_id", i}})
+		require.NoError(t, err)
+	}
+
+	cursor, err := collection.Find(ctx, bson.D{})
+	require.NoError(t, err)
+
+	var res []bson.D
+	require.NoError(t, cursor.All(ctx, &res))
+	assert.Equal(t, []bson.D{{{"_id", int32(3)}}, {{"_id", int32(4)}}, {{"_id", int32(5)}}}, res)
+
+	specs, err := db.ListCollectionSpecifications(ctx, bson.D{{"name", collection.Name()}})
+	require.NoError(t, err)
+	require.Len(t, specs, 1)
+
+	capped, err := specs[0].Options.LookupErr("capped")
+	require.NoError(t, err)
+	assert.True(t, capped.Boolean())
+}
+
+func TestCappedCollectionTailable(t *testing.T) {
+	t.Parallel()
+
+	skipForCapped(t)
+
+	ctx, collection := setup.Setup(t)
+
+	opts := options.CreateCollection().SetCapped(true).SetSizeInBytes(1 << 20)
+	err := collection.Database().CreateCollection(ctx, collection.Name(), opts)
+	require.NoError(t, err)
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", int32(1)}})
+	require.NoError(t, err)
+
+	findOpts := options.Find().SetCursorType(options.TailableAwait).SetMaxAwaitTime(100 * time.Millisecond)
+	cursor, err := collection.Find(ctx, bson.D{}, findOpts)
+	require.NoError(t, err)
+
+	defer cursor.Close(ctx)
+
+	require.True(t, cursor.TryNext(ctx))
+	assert.Equal(t, int32(1), cursor.Current.Lookup("_id").Int32())
+
+	// no new documents yet, but the cursor stays alive
+	require.False(t, cursor.TryNext(ctx))
+	require.NoError(t, cursor.Err())
+	require.NotZero(t, cursor.ID())
+
+	_, err = collection.InsertMany(ctx, []any{bson.D{{"_id", int32(2)}}, bson.D{{"_id", int32(3)}}})
+	require.NoError(t, err)
+
+	require.True(t, cursor.TryNext(ctx))
+	assert.Equal(t, int32(2), cursor.Current.Lookup("_id").Int32())
+
+	require.True(t, cursor.TryNext(ctx))
+	assert.Equal(t, int32(3), cursor.Current.Lookup("_id").Int32())
+}
+
+func TestCappedCollectionTailableGetMore(t *testing.T) {
+	t.Parallel()
+
+	skipForCapped(t)
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	opts := options.CreateCollection().SetCapped(true).SetSizeInBytes(1 << 20)
+	err := db.CreateCollection(ctx, collection.Name(), opts)
+	require.NoError(t, err)
+
+	_, err = collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}}, bson.D{{"_id", int32(2)}}, bson.D{{"_id", int32(3)}},
+	})
+	require.NoError(t, err)
+
+	var res bson.D
+	err = db.RunCommand(ctx, bson.D{
+		{"find", collection.Name()},
+		{"tailable", true},
+		{"awaitData", true},
+		{"batchSize", 1},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	cursorID := res.Map()["cursor"].(bson.D).Map()["id"]
+	require.NotZero(t, cursorID)
+
+	// batchSize could be changed by each getMore
+	err = db.RunCommand(ctx, bson.D{
+		{"getMore", cursorID},
+		{"collection", collection.Name()},
+		{"batchSize", 2},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	nextBatch := res.Map()["cursor"].(bson.D).Map()["nextBatch"].(bson.A)
+	assert.Equal(t, bson.A{bson.D{{"_id", int32(2)}}, bson.D{{"_id", int32(3)}}}, nextBatch)
+
+	// maxTimeMS limits the time getMore waits for new documents
+	start := time.Now()
+	err = db.RunCommand(ctx, bson.D{
+		{"getMore", cursorID},
+		{"collection", collection.Name()},
+		{"maxTimeMS", 300},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+
+	cursor := res.Map()["cursor"].(bson.D).Map()
+	assert.Empty(t, cursor["nextBatch"])
+	assert.Equal(t, cursorID, cursor["id"])
+}
+
+func TestCappedCollectionErrors(t *testing.T) {
+	t.Parallel()
+
+	skipForCapped(t)
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	t.Run("MissingSize", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(ctx, bson.D{{"create", collection.Name() + "_missing"}, {"capped", true}}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    72,
+			Name:    "InvalidOptions",
+			Message: "the 'size' field is required when 'capped' is true",
+		}, err)
+	})
+
+	t.Run("TailableEmpty", func(t *testing.T) {
+		t.Parallel()
+
+		name := collection.Name() + "_empty"
+		opts := options.CreateCollection().SetCapped(true).SetSizeInBytes(4096)
+		require.NoError(t, db.CreateCollection(ctx, name, opts))
+
+		// the initial query matches nothing, so the cursor is dead
+		var res bson.D
+		err := db.RunCommand(ctx, bson.D{{"find", name}, {"tailable", true}}).Decode(&res)
+		require.NoError(t, err)
+
+		cursor := res.Map()["cursor"].(bson.D)
+		assert.Equal(t, int64(0), cursor.Map()["id"])
+	})
+}
+
+func TestCappedCollectionConvertToCapped(t *testing.T) {
+	t.Parallel()
+
+	skipForCapped(t)
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	for i := int32(1); i <= 3; i++ {
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", i}, {"v", "foo"}})
+		require.NoError(t, err)
+	}
+
+	err := db.RunCommand(ctx, bson.D{{"convertToCapped", collection.Name()}, {"size", 1 << 20}}).Err()
+	require.NoError(t, err)
+
+	specs, err := db.ListCollectionSpecifications(ctx, bson.D{{"name", collection.Name()}})
+	require.NoError(t, err)
+	require.Len(t, specs, 1)
+
+	capped, err := specs[0].Options.LookupErr("capped")
+	require.NoError(t, err)
+	assert.True(t, capped.Boolean())
+
+	cursor, err := collection.Find(ctx, bson.D{})
+	require.NoError(t, err)
+
+	var res []bson.D
+	require.NoError(t, cursor.All(ctx, &res))
+
+	expected := []bson.D{
+		{{"_id", int32(1)}, {"v", "foo"}},
+		{{"_id", int32(2)}, {"v", "foo"}},
+		{{"_id", int32(3)}, {"v", "foo"}},
+	}
+	assert.Equal(t, expected, res)
+
+	t.Run("NonExistent", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(ctx, bson.D{{"convertToCapped", "non-existent"}, {"size", 1 << 20}}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    26,
+			Name:    "NamespaceNotFound",
+			Message: "source collection " + db.Name() + ".non-existent does not exist",
+		}, err)
+	})
+
+	t.Run("MissingSize", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(ctx, bson.D{{"convertToCapped", collection.Name()}}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    40414,
+			Name:    "Location40414",
+			Message: "BSON field 'convertToCapped.size' is missing but a required field",
+		}, err)
+	})
+}
+
+func TestCappedCollectionCloneCollectionAsCapped(t *testing.T) {
+	t.Parallel()
+
+	skipForCapped(t)
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	for i := int32(1); i <= 5; i++ {
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", i}})
+		require.NoError(t, err)
+	}
+
+	target := collection.Name() + "_capped"
+
+	err := db.RunCommand(ctx, bson.D{
+		{"cloneCollectionAsCapped", collection.Name()},
+		{"toCollection", target},
+		{"size", 4096},
+	}).Err()
+	require.NoError(t, err)
+
+	specs, err := db.ListCollectionSpecifications(ctx, bson.D{{"name", target}})
+	require.NoError(t, err)
+	require.Len(t, specs, 1)
+
+	capped, err := specs[0].Options.LookupErr("capped")
+	require.NoError(t, err)
+	assert.True(t, capped.Boolean())
+
+	for _, name := range []string{collection.Name(), target} {
+		n, err := db.Collection(name).CountDocuments(ctx, bson.D{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), n, name)
+	}
+
+	t.Run("TargetExists", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(ctx, bson.D{
+			{"cloneCollectionAsCapped", collection.Name()},
+			{"toCollection", target},
+			{"size", 4096},
+		}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    48,
+			Name:    "NamespaceExists",
+			Message: "a collection '" + db.Name() + "." + target + "' already exists",
+		}, err)
+	})
+
+	t.Run("SourceNonExistent", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(ctx, bson.D{
+			{"cloneCollectionAsCapped", "non-existent"},
+			{"toCollection", collection.Name() + "_other"},
+			{"size", 4096},
+		}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    26,
+			Name:    "NamespaceNotFound",
+			Message: "source collection " + db.Name() + ".non-existent does not exist",
+		}, err)
+	})
+}
@@ -0,0 +1,163 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+// skipForClustered skips the test for backends that do not support clustered collections.
+func skipForClustered(t *testing.T) {
+	t.Helper()
+
+	if !setup.IsMongoDB(t) && !setup.IsSQLite(t) {
+		t.Skip("Clustered collections are supported only by SQLite backend")
+	}
+}
+
+func TestClusteredCollection(t *testing.T) {
+	t.Parallel()
+
+	skipForClustered(t)
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	opts := options.CreateCollection().SetClusteredIndex(bson.D{{"key", bson.D{{"_id", 1}}}, {"unique", true}})
+	err := db.CreateCollection(ctx, collection.Name(), opts)
+	require.NoError(t, err)
+
+	_, err = collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "c"}, {"v", int32(1)}},
+		bson.D{{"_id", "a"}, {"v", int32(2)}},
+		bson.D{{"_id", "b"}, {"v", int32(3)}},
+	})
+	require.NoError(t, err)
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "a"}})
+	ns := db.Name() + `.` + collection.Name()
+	AssertEqualAltWriteError(t, mongo.WriteError{
+		Code:    11000,
+		Message: `E11000 duplicate key error collection: ` + ns + ` index: _id_ dup key: { _id: "a" }`,
+	}, `E11000 duplicate key error collection: `+ns, err)
+
+	// documents are returned in the _id order
+	cursor, err := collection.Find(ctx, bson.D{})
+	require.NoError(t, err)
+
+	var res []bson.D
+	require.NoError(t, cursor.All(ctx, &res))
+	assert.Equal(t, []bson.D{
+		{{"_id", "a"}, {"v", int32(2)}},
+		{{"_id", "b"}, {"v", int32(3)}},
+		{{"_id", "c"}, {"v", int32(1)}},
+	}, res)
+
+	_, err = collection.UpdateOne(ctx, bson.D{{"_id", "b"}}, bson.D{{"$set", bson.D{{"v", int32(4)}}}})
+	require.NoError(t, err)
+
+	_, err = collection.DeleteOne(ctx, bson.D{{"_id", "c"}})
+	require.NoError(t, err)
+
+	var doc bson.D
+	require.NoError(t, collection.FindOne(ctx, bson.D{{"_id", "b"}}).Decode(&doc))
+	assert.Equal(t, bson.D{{"_id", "b"}, {"v", int32(4)}}, doc)
+
+	count, err := collection.CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	specs, err := db.ListCollectionSpecifications(ctx, bson.D{{"name", collection.Name()}})
+	require.NoError(t, err)
+	require.Len(t, specs, 1)
+
+	clustered, err := specs[0].Options.LookupErr("clusteredIndex")
+	require.NoError(t, err)
+
+	var clusteredIndex bson.D
+	require.NoError(t, clustered.Unmarshal(&clusteredIndex))
+	assert.Equal(t, bson.D{
+		{"v", int32(2)},
+		{"key", bson.D{{"_id", int32(1)}}},
+		{"name", "_id_"},
+		{"unique", true},
+	}, clusteredIndex)
+}
+
+func TestClusteredCollectionErrors(t *testing.T) {
+	t.Parallel()
+
+	skipForClustered(t)
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	for name, tc := range map[string]struct {
+		clusteredIndex any
+		err            *mongo.CommandError
+	}{
+		"WrongType": {
+			clusteredIndex: "_id",
+			err: &mongo.CommandError{
+				Code:    14,
+				Name:    "TypeMismatch",
+				Message: "BSON field 'create.clusteredIndex' is the wrong type 'string', expected types '[bool, object]'",
+			},
+		},
+		"MissingUnique": {
+			clusteredIndex: bson.D{{"key", bson.D{{"_id", 1}}}},
+			err: &mongo.CommandError{
+				Code:    40414,
+				Name:    "Location40414",
+				Message: "BSON field 'create.clusteredIndex.unique' is missing but a required field",
+			},
+		},
+		"WrongKey": {
+			clusteredIndex: bson.D{{"key", bson.D{{"v", 1}}}, {"unique", true}},
+			err: &mongo.CommandError{
+				Code:    197,
+				Name:    "InvalidIndexSpecificationOption",
+				Message: "The clusteredIndex option is only supported for key: {_id: 1}",
+			},
+		},
+		"NotUnique": {
+			clusteredIndex: bson.D{{"key", bson.D{{"_id", 1}}}, {"unique", false}},
+			err: &mongo.CommandError{
+				Code:    197,
+				Name:    "InvalidIndexSpecificationOption",
+				Message: "The clusteredIndex option requires unique: true",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := db.RunCommand(ctx, bson.D{
+				{"create", collection.Name() + name},
+				{"clusteredIndex", tc.clusteredIndex},
+			}).Err()
+			AssertEqualCommandError(t, *tc.err, err)
+		})
+	}
+}
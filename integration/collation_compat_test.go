@@ -0,0 +1,387 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+// setupCollationCompat returns target and compat collections with strings
+// that differ only by case and diacritics.
+func setupCollationCompat(t *testing.T) *setup.SetupCompatResult {
+	t.Helper()
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers: []shareddata.Provider{shareddata.Strings},
+	})
+
+	docs := []any{
+		bson.D{{"_id", "collation-lower"}, {"v", "cote"}},
+		bson.D{{"_id", "collation-upper"}, {"v", "COTE"}},
+		bson.D{{"_id", "collation-diacritics"}, {"v", "côte"}},
+		bson.D{{"_id", "collation-upper-diacritics"}, {"v", "Côte"}},
+		bson.D{{"_id", "collation-array"}, {"v", bson.A{"Cote", "foo"}}},
+	}
+
+	for _, c := range []*mongo.Collection{s.TargetCollections[0], s.CompatCollections[0]} {
+		_, err := c.InsertMany(s.Ctx, docs)
+		require.NoError(t, err)
+	}
+
+	return s
+}
+
+func TestCollationCompat(t *testing.T) {
+	t.Parallel()
+
+	s := setupCollationCompat(t)
+	ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+	for name, tc := range map[string]struct {
+		filter    bson.D
+		collation *options.Collation
+	}{
+		"Simple": {
+			filter:    bson.D{{"v", "cote"}},
+			collation: &options.Collation{Locale: "simple"},
+		},
+		"Strength1": {
+			filter:    bson.D{{"v", "cote"}},
+			collation: &options.Collation{Locale: "fr", Strength: 1},
+		},
+		"Strength2": {
+			filter:    bson.D{{"v", "cote"}},
+			collation: &options.Collation{Locale: "en", Strength: 2},
+		},
+		"Strength3": {
+			filter:    bson.D{{"v", "cote"}},
+			collation: &options.Collation{Locale: "en", Strength: 3},
+		},
+		"CaseLevel": {
+			filter:    bson.D{{"v", "cote"}},
+			collation: &options.Collation{Locale: "en", Strength: 1, CaseLevel: true},
+		},
+		"Gt": {
+			filter:    bson.D{{"v", bson.D{{"$gt", "cote"}}}},
+			collation: &options.Collation{Locale: "en", Strength: 2},
+		},
+		"In": {
+			filter:    bson.D{{"v", bson.D{{"$in", bson.A{"COTE", "FOO"}}}}},
+			collation: &options.Collation{Locale: "en", Strength: 2},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			t.Run("Find", func(t *testing.T) {
+				t.Parallel()
+
+				opts := options.Find().SetCollation(tc.collation).SetSort(bson.D{{"v", 1}, {"_id", 1}})
+
+				targetCursor, targetErr := targetCollection.Find(ctx, tc.filter, opts)
+				compatCursor, compatErr := compatCollection.Find(ctx, tc.filter, opts)
+				require.NoError(t, compatErr)
+				require.NoError(t, targetErr)
+
+				AssertEqualDocumentsSlice(t, FetchAll(t, ctx, compatCursor), FetchAll(t, ctx, targetCursor))
+			})
+
+			t.Run("Aggregate", func(t *testing.T) {
+				t.Parallel()
+
+				pipeline := bson.A{
+					bson.D{{"$match", tc.filter}},
+					bson.D{{"$sort", bson.D{{"v", -1}, {"_id", 1}}}},
+				}
+				opts := options.Aggregate().SetCollation(tc.collation)
+
+				targetCursor, targetErr := targetCollection.Aggregate(ctx, pipeline, opts)
+				compatCursor, compatErr := compatCollection.Aggregate(ctx, pipeline, opts)
+				require.NoError(t, compatErr)
+				require.NoError(t, targetErr)
+
+				AssertEqualDocumentsSlice(t, FetchAll(t, ctx, compatCursor), FetchAll(t, ctx, targetCursor))
+			})
+
+			t.Run("Distinct", func(t *testing.T) {
+				t.Parallel()
+
+				opts := options.Distinct().SetCollation(tc.collation)
+
+				targetRes, targetErr := targetCollection.Distinct(ctx, "v", tc.filter, opts)
+				compatRes, compatErr := compatCollection.Distinct(ctx, "v", tc.filter, opts)
+				require.NoError(t, compatErr)
+				require.NoError(t, targetErr)
+
+				// the first found value of equal ones is returned, so compare only the number of values
+				assert.Len(t, targetRes, len(compatRes))
+			})
+		})
+	}
+}
+
+func TestCollationCompatErrors(t *testing.T) {
+	t.Parallel()
+
+	s := setupCollationCompat(t)
+	ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+	for name, tc := range map[string]struct {
+		collation bson.D
+	}{
+		"MissingLocale": {
+			collation: bson.D{{"strength", int32(2)}},
+		},
+		"InvalidLocale": {
+			collation: bson.D{{"locale", "xx_invalid"}},
+		},
+		"InvalidStrength": {
+			collation: bson.D{{"locale", "en"}, {"strength", int32(6)}},
+		},
+		"UnknownField": {
+			collation: bson.D{{"locale", "en"}, {"foo", int32(1)}},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			command := bson.D{
+				{"find", targetCollection.Name()},
+				{"filter", bson.D{{"v", "cote"}}},
+				{"collation", tc.collation},
+			}
+
+			var targetRes, compatRes bson.D
+			targetErr := targetCollection.Database().RunCommand(ctx, command).Decode(&targetRes)
+
+			command[0] = bson.E{"find", compatCollection.Name()}
+			compatErr := compatCollection.Database().RunCommand(ctx, command).Decode(&compatRes)
+
+			require.Error(t, compatErr)
+			AssertMatchesCommandError(t, compatErr, targetErr)
+		})
+	}
+}
+
+func TestIndexesCollationCompat(tt *testing.T) {
+	tt.Parallel()
+
+	t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/3175")
+
+	s := setupCollationCompat(tt)
+	ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+	model := mongo.IndexModel{
+		Keys:    bson.D{{"v", 1}},
+		Options: options.Index().SetCollation(&options.Collation{Locale: "en", Strength: 2}),
+	}
+
+	targetName, targetErr := targetCollection.Indexes().CreateOne(ctx, model)
+	compatName, compatErr := compatCollection.Indexes().CreateOne(ctx, model)
+	require.NoError(t, compatErr)
+	require.NoError(t, targetErr)
+	require.Equal(t, compatName, targetName)
+
+	targetCursor, targetErr := targetCollection.Indexes().List(ctx)
+	compatCursor, compatErr := compatCollection.Indexes().List(ctx)
+	require.NoError(t, compatErr)
+	require.NoError(t, targetErr)
+
+	targetSpecs := FetchAll(t, ctx, targetCursor)
+	compatSpecs := FetchAll(t, ctx, compatCursor)
+	require.Len(t, targetSpecs, len(compatSpecs))
+
+	for i := range compatSpecs {
+		// ICU version differs
+		for _, spec := range []bson.D{compatSpecs[i], targetSpecs[i]} {
+			if c, ok := spec.Map()["collation"].(bson.D); ok {
+				for j, e := range c {
+					if e.Key == "version" {
+						c[j].Value = nil
+					}
+				}
+			}
+		}
+
+		AssertEqualDocuments(t, compatSpecs[i], targetSpecs[i])
+	}
+}
+
+// setupCollectionCollationCompat returns target and compat collections
+// created with the default collation and strings that differ only by case and diacritics.
+func setupCollectionCollationCompat(t *testing.T) (context.Context, *mongo.Collection, *mongo.Collection) {
+	t.Helper()
+
+	if !setup.IsMongoDB(t) && !setup.IsSQLite(t) {
+		t.Skip("https://github.com/FerretDB/FerretDB/issues/3175")
+	}
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers: []shareddata.Provider{shareddata.Strings},
+	})
+	ctx, targetColl, compatColl := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+	docs := []any{
+		bson.D{{"_id", "collation-lower"}, {"v", "cote"}},
+		bson.D{{"_id", "collation-upper"}, {"v", "COTE"}},
+		bson.D{{"_id", "collation-diacritics"}, {"v", "côte"}},
+	}
+
+	opts := options.CreateCollection().SetCollation(&options.Collation{Locale: "en", Strength: 2})
+
+	// recreate collections with the default collation
+	for _, c := range []*mongo.Collection{targetColl, compatColl} {
+		require.NoError(t, c.Drop(ctx))
+		require.NoError(t, c.Database().CreateCollection(ctx, c.Name(), opts))
+
+		_, err := c.InsertMany(ctx, docs)
+		require.NoError(t, err)
+	}
+
+	return ctx, targetColl, compatColl
+}
+
+func TestCollectionCollationCompat(t *testing.T) {
+	t.Parallel()
+
+	ctx, targetColl, compatColl := setupCollectionCollationCompat(t)
+
+	t.Run("Find", func(t *testing.T) {
+		t.Parallel()
+
+		filter := bson.D{{"v", "COTE"}}
+		opts := options.Find().SetSort(bson.D{{"_id", 1}})
+
+		targetCursor, targetErr := targetColl.Find(ctx, filter, opts)
+		compatCursor, compatErr := compatColl.Find(ctx, filter, opts)
+		require.NoError(t, compatErr)
+		require.NoError(t, targetErr)
+
+		compatRes := FetchAll(t, ctx, compatCursor)
+		require.NotEmpty(t, compatRes)
+		AssertEqualDocumentsSlice(t, compatRes, FetchAll(t, ctx, targetCursor))
+	})
+
+	t.Run("ExplicitSimple", func(t *testing.T) {
+		t.Parallel()
+
+		filter := bson.D{{"v", "COTE"}}
+		opts := options.Find().SetCollation(&options.Collation{Locale: "simple"})
+
+		targetCursor, targetErr := targetColl.Find(ctx, filter, opts)
+		compatCursor, compatErr := compatColl.Find(ctx, filter, opts)
+		require.NoError(t, compatErr)
+		require.NoError(t, targetErr)
+
+		AssertEqualDocumentsSlice(t, FetchAll(t, ctx, compatCursor), FetchAll(t, ctx, targetCursor))
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		t.Parallel()
+
+		filter := bson.D{{"v", "cote"}}
+
+		targetCount, targetErr := targetColl.CountDocuments(ctx, filter)
+		compatCount, compatErr := compatColl.CountDocuments(ctx, filter)
+		require.NoError(t, compatErr)
+		require.NoError(t, targetErr)
+		assert.Equal(t, compatCount, targetCount)
+	})
+
+	t.Run("ListCollections", func(t *testing.T) {
+		t.Parallel()
+
+		targetSpecs, targetErr := targetColl.Database().ListCollectionSpecifications(
+			ctx, bson.D{{"name", targetColl.Name()}},
+		)
+		compatSpecs, compatErr := compatColl.Database().ListCollectionSpecifications(
+			ctx, bson.D{{"name", compatColl.Name()}},
+		)
+		require.NoError(t, compatErr)
+		require.NoError(t, targetErr)
+		require.Len(t, compatSpecs, 1)
+		require.Len(t, targetSpecs, 1)
+
+		var targetOpts, compatOpts bson.D
+		require.NoError(t, bson.Unmarshal(targetSpecs[0].Options, &targetOpts))
+		require.NoError(t, bson.Unmarshal(compatSpecs[0].Options, &compatOpts))
+
+		targetCollation, ok := targetOpts.Map()["collation"].(bson.D)
+		require.True(t, ok)
+		compatCollation, ok := compatOpts.Map()["collation"].(bson.D)
+		require.True(t, ok)
+
+		// ICU version differs
+		assert.Equal(t, compatCollation.Map()["locale"], targetCollation.Map()["locale"])
+		assert.Equal(t, compatCollation.Map()["strength"], targetCollation.Map()["strength"])
+	})
+}
+
+func TestCollectionCollationCompatWrite(t *testing.T) {
+	t.Parallel()
+
+	// subtests modify collections, so they are not parallel
+	ctx, targetColl, compatColl := setupCollectionCollationCompat(t)
+
+	t.Run("Update", func(t *testing.T) {
+		filter := bson.D{{"v", "COTE"}}
+		update := bson.D{{"$set", bson.D{{"updated", true}}}}
+
+		targetRes, targetErr := targetColl.UpdateMany(ctx, filter, update)
+		compatRes, compatErr := compatColl.UpdateMany(ctx, filter, update)
+		require.NoError(t, compatErr)
+		require.NoError(t, targetErr)
+		require.NotZero(t, compatRes.MatchedCount)
+		assert.Equal(t, compatRes, targetRes)
+
+		opts := options.Find().SetSort(bson.D{{"_id", 1}})
+
+		targetCursor, targetErr := targetColl.Find(ctx, bson.D{}, opts)
+		compatCursor, compatErr := compatColl.Find(ctx, bson.D{}, opts)
+		require.NoError(t, compatErr)
+		require.NoError(t, targetErr)
+
+		AssertEqualDocumentsSlice(t, FetchAll(t, ctx, compatCursor), FetchAll(t, ctx, targetCursor))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		filter := bson.D{{"v", "cote"}}
+
+		targetRes, targetErr := targetColl.DeleteMany(ctx, filter)
+		compatRes, compatErr := compatColl.DeleteMany(ctx, filter)
+		require.NoError(t, compatErr)
+		require.NoError(t, targetErr)
+		require.NotZero(t, compatRes.DeletedCount)
+		assert.Equal(t, compatRes, targetRes)
+
+		targetCount, targetErr := targetColl.CountDocuments(ctx, bson.D{})
+		compatCount, compatErr := compatColl.CountDocuments(ctx, bson.D{})
+		require.NoError(t, compatErr)
+		require.NoError(t, targetErr)
+		assert.Equal(t, compatCount, targetCount)
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestCollectionCollationWrite(t *testing.T) {
+	t.Parallel()
+
+	if !setup.IsMongoDB(t) && !setup.IsSQLite(t) {
+		t.Skip("https://github.com/FerretDB/FerretDB/issues/3175")
+	}
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	opts := options.CreateCollection().SetCollation(&options.Collation{Locale: "en", Strength: 2})
+	require.NoError(t, db.CreateCollection(ctx, collection.Name(), opts))
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "lower"}, {"v", "cote"}},
+		bson.D{{"_id", "upper"}, {"v", "COTE"}},
+		bson.D{{"_id", "diacritics"}, {"v", "côte"}},
+	})
+	require.NoError(t, err)
+
+	simple := &options.Collation{Locale: "simple"}
+
+	updateRes, err := collection.UpdateMany(ctx, bson.D{{"v", "COTE"}}, bson.D{{"$set", bson.D{{"default", true}}}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), updateRes.MatchedCount)
+	assert.Equal(t, int64(2), updateRes.ModifiedCount)
+
+	updateRes, err = collection.UpdateMany(
+		ctx,
+		bson.D{{"v", "COTE"}},
+		bson.D{{"$set", bson.D{{"simple", true}}}},
+		options.Update().SetCollation(simple),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), updateRes.MatchedCount)
+
+	deleteRes, err := collection.DeleteMany(ctx, bson.D{{"v", "cote"}}, options.Delete().SetCollation(simple))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleteRes.DeletedCount)
+
+	deleteRes, err = collection.DeleteMany(ctx, bson.D{{"v", "cote"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleteRes.DeletedCount)
+
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+	require.NoError(t, err)
+
+	expected := []bson.D{{{"_id", "diacritics"}, {"v", "côte"}}}
+	AssertEqualDocumentsSlice(t, expected, FetchAll(t, ctx, cursor))
+}
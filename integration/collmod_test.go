@@ -0,0 +1,322 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+// skipForCollMod skips the test for backends that do not support validators and TTL indexes yet.
+func skipForCollMod(t *testing.T) {
+	t.Helper()
+
+	if !setup.IsMongoDB(t) && !setup.IsSQLite(t) {
+		t.Skip("https://github.com/FerretDB/FerretDB/issues/1510")
+	}
+}
+
+// requireValidationFailure checks that err is a write error caused by a failed document validation.
+func requireValidationFailure(t *testing.T, err error) {
+	t.Helper()
+
+	var we mongo.WriteException
+	require.ErrorAs(t, err, &we)
+	require.Len(t, we.WriteErrors, 1)
+	assert.Equal(t, 121, we.WriteErrors[0].Code)
+}
+
+func TestCollModValidator(t *testing.T) {
+	t.Parallel()
+
+	skipForCollMod(t)
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	validator := bson.D{{"v", bson.D{{"$type", "string"}}}}
+	opts := options.CreateCollection().SetValidator(validator)
+	require.NoError(t, db.CreateCollection(ctx, collection.Name(), opts))
+
+	options := func() bson.D {
+		specs, err := db.ListCollectionSpecifications(ctx, bson.D{{"name", collection.Name()}})
+		require.NoError(t, err)
+		require.Len(t, specs, 1)
+
+		var res bson.D
+		require.NoError(t, bson.Unmarshal(specs[0].Options, &res))
+
+		return res
+	}
+
+	expected := bson.D{
+		{"validator", validator},
+		{"validationLevel", "strict"},
+		{"validationAction", "error"},
+	}
+	AssertEqualDocuments(t, expected, options())
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "string"}, {"v", "foo"}})
+	require.NoError(t, err)
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "int"}, {"v", int32(42)}})
+	requireValidationFailure(t, err)
+
+	_, err = collection.UpdateOne(ctx, bson.D{{"_id", "string"}}, bson.D{{"$set", bson.D{{"v", int32(42)}}}})
+	requireValidationFailure(t, err)
+
+	var res bson.D
+	err = db.RunCommand(ctx, bson.D{
+		{"collMod", collection.Name()},
+		{"validationAction", "warn"},
+	}).Decode(&res)
+	require.NoError(t, err)
+	AssertEqualDocuments(t, bson.D{{"ok", float64(1)}}, res)
+
+	expected = bson.D{
+		{"validator", validator},
+		{"validationLevel", "strict"},
+		{"validationAction", "warn"},
+	}
+	AssertEqualDocuments(t, expected, options())
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "int"}, {"v", int32(42)}})
+	require.NoError(t, err)
+
+	err = db.RunCommand(ctx, bson.D{
+		{"collMod", collection.Name()},
+		{"validationAction", "error"},
+		{"validationLevel", "moderate"},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	// updates of documents that already fail validation are allowed with moderate level
+	_, err = collection.UpdateOne(ctx, bson.D{{"_id", "int"}}, bson.D{{"$set", bson.D{{"v", int64(42)}}}})
+	require.NoError(t, err)
+
+	_, err = collection.UpdateOne(ctx, bson.D{{"_id", "string"}}, bson.D{{"$set", bson.D{{"v", int32(42)}}}})
+	requireValidationFailure(t, err)
+
+	err = db.RunCommand(ctx, bson.D{
+		{"collMod", collection.Name()},
+		{"validator", bson.D{}},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "double"}, {"v", 42.0}})
+	require.NoError(t, err)
+}
+
+func TestCollModValidatorBypass(t *testing.T) {
+	t.Parallel()
+
+	skipForCollMod(t)
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	validator := bson.D{{"v", bson.D{{"$type", "string"}}}}
+	opts := options.CreateCollection().SetValidator(validator)
+	require.NoError(t, db.CreateCollection(ctx, collection.Name(), opts))
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "int"}, {"v", int32(42)}})
+	requireValidationFailure(t, err)
+
+	_, err = collection.InsertOne(
+		ctx, bson.D{{"_id", "int"}, {"v", int32(42)}},
+		options.InsertOne().SetBypassDocumentValidation(true),
+	)
+	require.NoError(t, err)
+
+	_, err = collection.UpdateOne(
+		ctx, bson.D{{"_id", "int"}}, bson.D{{"$set", bson.D{{"v", int64(42)}}}},
+		options.Update().SetBypassDocumentValidation(true),
+	)
+	require.NoError(t, err)
+
+	source := db.Collection(collection.Name() + "_source")
+	_, err = source.InsertOne(ctx, bson.D{{"_id", "double"}, {"v", 42.0}})
+	require.NoError(t, err)
+
+	pipeline := bson.A{bson.D{{"$merge", bson.D{{"into", collection.Name()}}}}}
+
+	_, err = source.Aggregate(ctx, pipeline)
+
+	var ce mongo.CommandError
+	require.ErrorAs(t, err, &ce)
+	assert.Equal(t, int32(121), ce.Code)
+
+	_, err = source.Aggregate(ctx, pipeline, options.Aggregate().SetBypassDocumentValidation(true))
+	require.NoError(t, err)
+
+	count, err := collection.CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestCollModValidatorErrors(t *testing.T) {
+	t.Parallel()
+
+	skipForCollMod(t)
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	require.NoError(t, db.CreateCollection(ctx, collection.Name()))
+
+	for name, tc := range map[string]struct {
+		command bson.D
+		err     *mongo.CommandError
+	}{
+		"ValidatorWrongType": {
+			command: bson.D{{"collMod", collection.Name()}, {"validator", "foo"}},
+			err: &mongo.CommandError{
+				Code:    14,
+				Name:    "TypeMismatch",
+				Message: "BSON field 'collMod.validator' is the wrong type 'string', expected type 'object'",
+			},
+		},
+		"InvalidLevel": {
+			command: bson.D{{"collMod", collection.Name()}, {"validationLevel", "foo"}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "Enumeration value 'foo' for field 'collMod.validationLevel' is not a valid value.",
+			},
+		},
+		"InvalidAction": {
+			command: bson.D{{"collMod", collection.Name()}, {"validationAction", "foo"}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "Enumeration value 'foo' for field 'collMod.validationAction' is not a valid value.",
+			},
+		},
+		"NonExistentCollection": {
+			command: bson.D{{"collMod", "non-existent"}, {"validator", bson.D{{"v", int32(1)}}}},
+			err: &mongo.CommandError{
+				Code:    26,
+				Name:    "NamespaceNotFound",
+				Message: "ns does not exist",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var res bson.D
+			err := db.RunCommand(ctx, tc.command).Decode(&res)
+			AssertEqualCommandError(t, *tc.err, err)
+		})
+	}
+}
+
+func TestCollModIndexTTL(t *testing.T) {
+	t.Parallel()
+
+	skipForCollMod(t)
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{"v", 1}}, Options: options.Index().SetExpireAfterSeconds(3600)},
+		{Keys: bson.D{{"foo", 1}}},
+	})
+	require.NoError(t, err)
+
+	var res bson.D
+	err = db.RunCommand(ctx, bson.D{
+		{"collMod", collection.Name()},
+		{"index", bson.D{{"keyPattern", bson.D{{"v", 1}}}, {"expireAfterSeconds", int32(60)}}},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	expected := bson.D{
+		{"expireAfterSeconds_old", int64(3600)},
+		{"expireAfterSeconds_new", int64(60)},
+		{"ok", float64(1)},
+	}
+	AssertEqualDocuments(t, expected, res)
+
+	// no changes are reported
+	err = db.RunCommand(ctx, bson.D{
+		{"collMod", collection.Name()},
+		{"index", bson.D{{"name", "v_1"}, {"expireAfterSeconds", int32(60)}}},
+	}).Decode(&res)
+	require.NoError(t, err)
+	AssertEqualDocuments(t, bson.D{{"ok", float64(1)}}, res)
+
+	cursor, err := collection.Indexes().List(ctx)
+	require.NoError(t, err)
+
+	var ttl any
+
+	for _, spec := range FetchAll(t, ctx, cursor) {
+		if m := spec.Map(); m["name"] == "v_1" {
+			ttl = m["expireAfterSeconds"]
+		}
+	}
+
+	assert.EqualValues(t, 60, ttl)
+
+	for name, tc := range map[string]struct {
+		index bson.D
+		err   *mongo.CommandError
+	}{
+		"NotTTL": {
+			index: bson.D{{"name", "foo_1"}, {"expireAfterSeconds", int32(60)}},
+			err: &mongo.CommandError{
+				Code:    72,
+				Name:    "InvalidOptions",
+				Message: "no expireAfterSeconds field to update",
+			},
+		},
+		"NotFound": {
+			index: bson.D{{"name", "missing"}, {"expireAfterSeconds", int32(60)}},
+			err: &mongo.CommandError{
+				Code:    27,
+				Name:    "IndexNotFound",
+				Message: "cannot find index missing for ns " + db.Name() + "." + collection.Name(),
+			},
+		},
+		"WrongType": {
+			index: bson.D{{"name", "v_1"}, {"expireAfterSeconds", "60"}},
+			err: &mongo.CommandError{
+				Code: 14,
+				Name: "TypeMismatch",
+				Message: "BSON field 'collMod.index.expireAfterSeconds' is the wrong type 'string', " +
+					"expected types '[long, int, decimal, double']",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var res bson.D
+			err := db.RunCommand(ctx, bson.D{{"collMod", collection.Name()}, {"index", tc.index}}).Decode(&res)
+			AssertEqualCommandError(t, *tc.err, err)
+		})
+	}
+}
@@ -15,6 +15,7 @@
 package integration
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"runtime"
@@ -611,13 +612,78 @@ func TestGetParameterCommandAuthenticationMechanisms(t *testing.T) {
 		require.NoError(t, err)
 
 		expected := bson.D{
-			{"authenticationMechanisms", bson.A{"PLAIN"}},
+			{"authenticationMechanisms", bson.A{"PLAIN", "SCRAM-SHA-256"}},
 			{"ok", float64(1)},
 		}
 		require.Equal(t, expected, res)
 	})
 }
 
+func TestCommandsAdministrationSetParameter(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, &setup.SetupOpts{
+		DatabaseName: "admin",
+	})
+	ctx, db := s.Ctx, s.Collection.Database()
+
+	var res bson.D
+	err := db.RunCommand(ctx, bson.D{{"getParameter", 1}, {"logLevel", 1}}).Decode(&res)
+	require.NoError(t, err)
+
+	logLevel := must.NotFail(ConvertDocument(t, res).Get("logLevel"))
+
+	err = db.RunCommand(ctx, bson.D{{"setParameter", 1}, {"logLevel", logLevel}}).Decode(&res)
+	require.NoError(t, err)
+	AssertEqualDocuments(t, bson.D{{"was", logLevel}, {"ok", float64(1)}}, res)
+
+	err = db.RunCommand(ctx, bson.D{{"getParameter", bson.D{{"showDetails", true}}}, {"logLevel", 1}}).Decode(&res)
+	require.NoError(t, err)
+
+	details := must.NotFail(ConvertDocument(t, res).Get("logLevel")).(*types.Document)
+	assert.Equal(t, logLevel, must.NotFail(details.Get("value")))
+	assert.Equal(t, true, must.NotFail(details.Get("settableAtRuntime")))
+
+	t.Run("Unrecognized", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(ctx, bson.D{{"setParameter", 1}, {"unknownParameter", 1}}).Err()
+
+		expected := mongo.CommandError{
+			Code:    72,
+			Name:    "InvalidOptions",
+			Message: "attempted to set unrecognized parameter [unknownParameter], use help:true to see options ",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+
+	t.Run("WrongType", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(ctx, bson.D{{"setParameter", 1}, {"quiet", "true"}}).Err()
+
+		var ce mongo.CommandError
+		require.ErrorAs(t, err, &ce)
+		assert.Equal(t, int32(14), ce.Code)
+	})
+
+	t.Run("NotSettableAtRuntime", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(ctx, bson.D{
+			{"setParameter", 1},
+			{"authenticationMechanisms", bson.A{"PLAIN"}},
+		}).Err()
+
+		expected := mongo.CommandError{
+			Code:    20,
+			Name:    "IllegalOperation",
+			Message: "not allowed to change [authenticationMechanisms] at runtime",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+}
+
 func TestCommandsAdministrationBuildInfo(t *testing.T) {
 	t.Parallel()
 	ctx, collection := setup.Setup(t)
@@ -648,6 +714,9 @@ func TestCommandsAdministrationBuildInfo(t *testing.T) {
 	assert.Equal(t, int32(16777216), must.NotFail(doc.Get("maxBsonObjectSize")))
 	_, ok = must.NotFail(doc.Get("buildEnvironment")).(*types.Document)
 	assert.True(t, ok)
+
+	_, ok = must.NotFail(doc.Get("javascriptEngine")).(string)
+	assert.True(t, ok)
 }
 
 func TestCommandsAdministrationBuildInfoFerretdbExtensions(t *testing.T) {
@@ -673,6 +742,8 @@ func TestCommandsAdministrationBuildInfoFerretdbExtensions(t *testing.T) {
 	assert.True(t, ok)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, aggregationStagesArray)
+
+	assert.Equal(t, "none", must.NotFail(doc.Get("javascriptEngine")))
 }
 
 func TestCommandsAdministrationCollStatsEmpty(tt *testing.T) {
@@ -732,6 +803,42 @@ func TestCommandsAdministrationCollStats(tt *testing.T) {
 	assert.InDelta(t, 32_000, must.NotFail(doc.Get("totalSize")), 30_000)
 }
 
+func TestCommandsAdministrationCollStatsSizeDelta(tt *testing.T) {
+	tt.Parallel()
+
+	t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/3259")
+	ctx, collection := setup.Setup(t)
+
+	// size returns the collection size as int32 or int64
+	size := func() any {
+		var actual bson.D
+		err := collection.Database().RunCommand(ctx, bson.D{{"collStats", collection.Name()}}).Decode(&actual)
+		require.NoError(t, err)
+
+		return must.NotFail(ConvertDocument(t, actual).Get("size"))
+	}
+
+	doc1 := bson.D{{"_id", "1"}, {"v", "foo"}}
+	doc2 := bson.D{{"_id", "2"}, {"v", int64(42)}}
+	doc1Updated := bson.D{{"_id", "1"}, {"v", "foobarbaz"}}
+
+	bsonSize := func(doc bson.D) int64 {
+		return int64(len(must.NotFail(bson.Marshal(doc))))
+	}
+
+	_, err := collection.InsertMany(ctx, []any{doc1, doc2})
+	require.NoError(t, err)
+	assert.EqualValues(t, bsonSize(doc1)+bsonSize(doc2), size())
+
+	_, err = collection.ReplaceOne(ctx, bson.D{{"_id", "1"}}, doc1Updated)
+	require.NoError(t, err)
+	assert.EqualValues(t, bsonSize(doc1Updated)+bsonSize(doc2), size())
+
+	_, err = collection.DeleteOne(ctx, bson.D{{"_id", "2"}})
+	require.NoError(t, err)
+	assert.EqualValues(t, bsonSize(doc1Updated), size())
+}
+
 func TestCommandsAdministrationCollStatsWithScale(tt *testing.T) {
 	tt.Parallel()
 
@@ -852,6 +959,47 @@ func TestCommandsAdministrationDataSizeErrors(tt *testing.T) {
 	}
 }
 
+func TestCommandsAdministrationCompact(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.DocumentsStrings)
+
+	_, err := collection.DeleteMany(ctx, bson.D{})
+	require.NoError(t, err)
+
+	var res bson.D
+	err = collection.Database().RunCommand(ctx, bson.D{{"compact", collection.Name()}, {"dryRun", true}}).Decode(&res)
+	require.NoError(t, err)
+
+	doc := ConvertDocument(t, res)
+	assert.Equal(t, float64(1), must.NotFail(doc.Get("ok")))
+
+	_, err = doc.Get("estimatedBytesFreed")
+	assert.NoError(t, err)
+
+	err = collection.Database().RunCommand(ctx, bson.D{{"compact", collection.Name()}, {"force", true}}).Decode(&res)
+	require.NoError(t, err)
+
+	doc = ConvertDocument(t, res)
+	assert.Equal(t, float64(1), must.NotFail(doc.Get("ok")))
+
+	_, err = doc.Get("bytesFreed")
+	assert.NoError(t, err)
+
+	t.Run("NonExistentCollection", func(t *testing.T) {
+		t.Parallel()
+
+		err := collection.Database().RunCommand(ctx, bson.D{{"compact", "non-existent"}}).Err()
+
+		expected := mongo.CommandError{
+			Code:    26,
+			Name:    "NamespaceNotFound",
+			Message: "collection does not exist",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+}
+
 func TestCommandsAdministrationDBStats(tt *testing.T) {
 	tt.Parallel()
 
@@ -963,9 +1111,13 @@ func TestCommandsAdministrationDBStatsEmptyWithScale(tt *testing.T) {
 func TestCommandsAdministrationServerStatus(t *testing.T) {
 	ctx, collection := setup.Setup(t)
 
+	// backend version and pool are reported only after FerretDB connects to the backend
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "server-status"}})
+	require.NoError(t, err)
+
 	var actual bson.D
 	command := bson.D{{"serverStatus", int32(1)}}
-	err := collection.Database().RunCommand(ctx, command).Decode(&actual)
+	err = collection.Database().RunCommand(ctx, command).Decode(&actual)
 	require.NoError(t, err)
 
 	doc := ConvertDocument(t, actual)
@@ -987,6 +1139,16 @@ func TestCommandsAdministrationServerStatus(t *testing.T) {
 
 	assert.WithinDuration(t, time.Now(), must.NotFail(doc.Get("localTime")).(time.Time), 2*time.Second)
 
+	connections, ok := must.NotFail(doc.Get("connections")).(*types.Document)
+	require.True(t, ok)
+
+	// at least the current connection is open and active
+	current := must.NotFail(connections.Get("current")).(int32)
+	assert.GreaterOrEqual(t, current, int32(1))
+	assert.Greater(t, must.NotFail(connections.Get("available")), int32(0))
+	assert.GreaterOrEqual(t, must.NotFail(connections.Get("totalCreated")), current)
+	assert.GreaterOrEqual(t, must.NotFail(connections.Get("active")), int32(1))
+
 	catalogStats, ok := must.NotFail(doc.Get("catalogStats")).(*types.Document)
 	assert.True(t, ok)
 
@@ -998,6 +1160,23 @@ func TestCommandsAdministrationServerStatus(t *testing.T) {
 	assert.Equal(t, int32(0), must.NotFail(catalogStats.Get("timeseries")))
 	assert.Equal(t, int32(0), must.NotFail(catalogStats.Get("views")))
 	assert.Equal(t, int32(0), must.NotFail(catalogStats.Get("internalViews")))
+
+	if setup.IsMongoDB(t) {
+		return
+	}
+
+	backend, ok := must.NotFail(doc.GetByPath(types.NewStaticPath("ferretdb", "backend"))).(*types.Document)
+	require.True(t, ok)
+
+	assert.Contains(t, []string{"PostgreSQL", "SQLite"}, must.NotFail(backend.Get("name")))
+	assert.NotEmpty(t, must.NotFail(backend.Get("version")))
+
+	pool, ok := must.NotFail(backend.Get("pool")).(*types.Document)
+	require.True(t, ok)
+
+	assert.GreaterOrEqual(t, must.NotFail(pool.Get("open")), int32(1))
+	assert.GreaterOrEqual(t, must.NotFail(pool.Get("waitCount")), int64(0))
+	assert.True(t, backend.Has("lastError"))
 }
 
 func TestCommandsAdministrationServerStatusMetrics(t *testing.T) {
@@ -1178,6 +1357,54 @@ func TestCommandsAdministrationServerStatusStress(t *testing.T) {
 	wg.Wait()
 }
 
+func TestCommandsAdministrationTop(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.DocumentsStrings)
+
+	_, err := collection.Find(ctx, bson.D{})
+	require.NoError(t, err)
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "top"}})
+	require.NoError(t, err)
+
+	var res bson.D
+	err = collection.Database().Client().Database("admin").RunCommand(ctx, bson.D{{"top", 1}}).Decode(&res)
+	require.NoError(t, err)
+
+	doc := ConvertDocument(t, res)
+	assert.Equal(t, float64(1), must.NotFail(doc.Get("ok")))
+
+	totals, ok := must.NotFail(doc.Get("totals")).(*types.Document)
+	require.True(t, ok)
+	assert.Equal(t, "all times in microseconds", must.NotFail(totals.Get("note")))
+
+	ns, ok := must.NotFail(totals.Get(collection.Database().Name() + "." + collection.Name())).(*types.Document)
+	require.True(t, ok)
+
+	for _, field := range []string{"queries", "insert"} {
+		stat, ok := must.NotFail(ns.Get(field)).(*types.Document)
+		require.True(t, ok, field)
+
+		count, ok := must.NotFail(stat.Get("count")).(int64)
+		require.True(t, ok, field)
+		assert.GreaterOrEqual(t, count, int64(1), field)
+	}
+
+	t.Run("NonAdmin", func(t *testing.T) {
+		t.Parallel()
+
+		err := collection.Database().RunCommand(ctx, bson.D{{"top", 1}}).Err()
+
+		expected := mongo.CommandError{
+			Code:    13,
+			Name:    "Unauthorized",
+			Message: "top may only be run against the admin database.",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+}
+
 func TestCommandsAdministrationCurrentOp(t *testing.T) {
 	t.Parallel()
 
@@ -1198,6 +1425,128 @@ func TestCommandsAdministrationCurrentOp(t *testing.T) {
 	assert.True(t, ok)
 }
 
+func TestCommandsAdministrationCurrentOpIdleCursors(t *testing.T) {
+	setup.SkipForMongoDB(t, "FerretDB-specific command's extensions")
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.Strings)
+
+	opts := options.Find().SetBatchSize(1).SetComment("idle cursor test")
+	cursor, err := collection.Find(ctx, bson.D{{"v", bson.D{{"$exists", true}}}}, opts)
+	require.NoError(t, err)
+
+	defer cursor.Close(ctx)
+
+	require.True(t, cursor.Next(ctx))
+
+	var res bson.D
+	err = collection.Database().Client().Database("admin").RunCommand(ctx, bson.D{
+		{"currentOp", int32(1)},
+		{"$all", true},
+		{"$ownOps", true},
+		{"cursor.cursorId", cursor.ID()},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	doc := ConvertDocument(t, res)
+
+	inprog := must.NotFail(doc.Get("inprog")).(*types.Array)
+	require.Equal(t, 1, inprog.Len())
+
+	op := must.NotFail(inprog.Get(0)).(*types.Document)
+	assert.Equal(t, "idleCursor", must.NotFail(op.Get("type")))
+	assert.Equal(t, collection.Database().Name()+"."+collection.Name(), must.NotFail(op.Get("ns")))
+	assert.Len(t, must.NotFail(op.Get("queryShapeHash")), 64)
+
+	originatingCommand := must.NotFail(op.GetByPath(types.NewStaticPath("cursor", "originatingCommand"))).(*types.Document)
+	assert.Equal(t, "idle cursor test", must.NotFail(originatingCommand.Get("comment")))
+
+	// getMore accepts comment of any type
+	err = collection.Database().RunCommand(ctx, bson.D{
+		{"getMore", cursor.ID()},
+		{"collection", collection.Name()},
+		{"batchSize", int32(1)},
+		{"comment", bson.D{{"getMore", "comment"}}},
+	}).Err()
+	require.NoError(t, err)
+}
+
+func TestCommandsAdministrationKillOp(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, nil)
+	ctx := s.Ctx
+	admin := s.Collection.Database().Client().Database("admin")
+
+	err := admin.RunCommand(ctx, bson.D{{"sleep", 1}, {"millis", 1}}).Err()
+
+	var ce mongo.CommandError
+	if errors.As(err, &ce) && ce.Code == 59 {
+		t.Skip("test commands are not enabled")
+	}
+
+	require.NoError(t, err)
+
+	comment := t.Name()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- admin.RunCommand(ctx, bson.D{{"sleep", 1}, {"secs", 10}, {"comment", comment}}).Err()
+	}()
+
+	var opid any
+
+	require.Eventually(t, func() bool {
+		var res bson.D
+		err := admin.RunCommand(ctx, bson.D{
+			{"currentOp", int32(1)},
+			{"command.comment", comment},
+		}).Decode(&res)
+		require.NoError(t, err)
+
+		inprog := must.NotFail(ConvertDocument(t, res).Get("inprog")).(*types.Array)
+		if inprog.Len() == 0 {
+			return false
+		}
+
+		op := must.NotFail(inprog.Get(0)).(*types.Document)
+		assert.Equal(t, "op", must.NotFail(op.Get("type")))
+		assert.Equal(t, true, must.NotFail(op.Get("active")))
+		assert.Equal(t, "admin.$cmd", must.NotFail(op.Get("ns")))
+		assert.Contains(t, op.Keys(), "secs_running")
+
+		opid = must.NotFail(op.Get("opid"))
+
+		return true
+	}, 5*time.Second, 50*time.Millisecond)
+
+	var res bson.D
+	err = admin.RunCommand(ctx, bson.D{{"killOp", int32(1)}, {"op", opid}}).Decode(&res)
+	require.NoError(t, err)
+	AssertEqualDocuments(t, bson.D{{"info", "attempting to kill op"}, {"ok", float64(1)}}, res)
+
+	expected := mongo.CommandError{
+		Code:    11601,
+		Name:    "Interrupted",
+		Message: "operation was interrupted",
+	}
+	AssertEqualCommandError(t, expected, <-errCh)
+
+	t.Run("NonAdmin", func(t *testing.T) {
+		t.Parallel()
+
+		err := s.Collection.Database().RunCommand(ctx, bson.D{{"killOp", int32(1)}, {"op", int32(1)}}).Err()
+
+		expected := mongo.CommandError{
+			Code:    13,
+			Name:    "Unauthorized",
+			Message: "killOp may only be run against the admin database.",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+}
+
 func TestCommandsAdministrationKillCursors(t *testing.T) {
 	t.Parallel()
 
@@ -1315,3 +1664,256 @@ func TestCommandsAdministrationKillCursors(t *testing.T) {
 		AssertMatchesCommandError(t, expectedErr, c.Err())
 	})
 }
+
+// TestCommandsAdministrationFsync is not parallel because fsync lock blocks writes of all connections.
+func TestCommandsAdministrationFsync(t *testing.T) {
+	if !setup.IsMongoDB(t) && !setup.IsSQLite(t) {
+		t.Skip("fsync is tested only for SQLite backend")
+	}
+
+	ctx, collection := setup.Setup(t)
+	admin := collection.Database().Client().Database("admin")
+
+	var res bson.D
+	err := admin.RunCommand(ctx, bson.D{{"fsync", int32(1)}}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), must.NotFail(ConvertDocument(t, res).Get("ok")))
+
+	err = admin.RunCommand(ctx, bson.D{{"fsync", int32(1)}, {"lock", true}}).Decode(&res)
+	require.NoError(t, err)
+
+	locked := true
+	defer func() {
+		if locked {
+			_ = admin.RunCommand(ctx, bson.D{{"fsyncUnlock", int32(1)}}).Err()
+		}
+	}()
+
+	doc := ConvertDocument(t, res)
+	assert.Equal(t, int64(1), must.NotFail(doc.Get("lockCount")))
+	assert.Equal(t, "now locked against writes, use db.fsyncUnlock() to unlock", must.NotFail(doc.Get("info")))
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, insertErr := collection.InsertOne(ctx, bson.D{{"_id", "fsync"}})
+		errCh <- insertErr
+	}()
+
+	// reads are not blocked
+	_, err = collection.CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+
+	select {
+	case err = <-errCh:
+		t.Fatalf("insert was not blocked by fsync lock: %v", err)
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	err = admin.RunCommand(ctx, bson.D{{"fsyncUnlock", int32(1)}}).Decode(&res)
+	require.NoError(t, err)
+
+	locked = false
+
+	doc = ConvertDocument(t, res)
+	assert.Equal(t, int64(0), must.NotFail(doc.Get("lockCount")))
+	assert.Equal(t, "fsyncUnlock completed", must.NotFail(doc.Get("info")))
+
+	require.NoError(t, <-errCh)
+
+	err = admin.RunCommand(ctx, bson.D{{"fsyncUnlock", int32(1)}}).Err()
+	expected := mongo.CommandError{
+		Code:    20,
+		Name:    "IllegalOperation",
+		Message: "fsyncUnlock called when not locked",
+	}
+	AssertEqualCommandError(t, expected, err)
+
+	err = collection.Database().RunCommand(ctx, bson.D{{"fsync", int32(1)}}).Err()
+	expected = mongo.CommandError{
+		Code:    13,
+		Name:    "Unauthorized",
+		Message: "fsync may only be run against the admin database.",
+	}
+	AssertEqualCommandError(t, expected, err)
+}
+
+func TestCommandsAdministrationMaintenanceMode(t *testing.T) {
+	// maintenance mode affects all connections, so the test is not parallel
+	setup.SkipForMongoDB(t, "FerretDB-specific command")
+
+	ctx, collection := setup.Setup(t)
+	admin := collection.Database().Client().Database("admin")
+
+	const message = "Upgrading storage"
+
+	var res bson.D
+	err := admin.RunCommand(ctx, bson.D{{"setMaintenanceMode", true}, {"message", message}}).Decode(&res)
+	require.NoError(t, err)
+
+	enabled := true
+	defer func() {
+		if enabled {
+			_ = admin.RunCommand(ctx, bson.D{{"setMaintenanceMode", false}}).Err()
+		}
+	}()
+
+	doc := ConvertDocument(t, res)
+	assert.Equal(t, false, must.NotFail(doc.Get("was")))
+	assert.Equal(t, message, must.NotFail(doc.Get("message")))
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "maintenance"}})
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    10107,
+		Name:    "NotWritablePrimary",
+		Message: message,
+		Labels:  []string{"RetryableWriteError"},
+	}, err)
+
+	// reads are served
+	_, err = collection.CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+
+	err = admin.RunCommand(ctx, bson.D{{"hello", int32(1)}}).Decode(&res)
+	require.NoError(t, err)
+
+	doc = ConvertDocument(t, res)
+	assert.Equal(t, false, must.NotFail(doc.Get("isWritablePrimary")))
+	assert.Equal(t, true, must.NotFail(doc.Get("readOnly")))
+	assert.Equal(t, message, must.NotFail(doc.Get("maintenanceMessage")))
+
+	err = admin.RunCommand(ctx, bson.D{{"setMaintenanceMode", false}}).Decode(&res)
+	require.NoError(t, err)
+
+	enabled = false
+
+	assert.Equal(t, true, must.NotFail(ConvertDocument(t, res).Get("was")))
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "maintenance"}})
+	require.NoError(t, err)
+
+	err = admin.RunCommand(ctx, bson.D{{"hello", int32(1)}}).Decode(&res)
+	require.NoError(t, err)
+
+	doc = ConvertDocument(t, res)
+	assert.Equal(t, true, must.NotFail(doc.Get("isWritablePrimary")))
+	assert.False(t, doc.Has("maintenanceMessage"))
+
+	err = collection.Database().RunCommand(ctx, bson.D{{"setMaintenanceMode", true}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    13,
+		Name:    "Unauthorized",
+		Message: "setMaintenanceMode may only be run against the admin database.",
+	}, err)
+}
+
+func TestCommandsAdministrationShutdownErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	t.Run("NonAdmin", func(t *testing.T) {
+		t.Parallel()
+
+		err := collection.Database().RunCommand(ctx, bson.D{{"shutdown", int32(1)}}).Err()
+
+		expected := mongo.CommandError{
+			Code:    13,
+			Name:    "Unauthorized",
+			Message: "shutdown may only be run against the admin database.",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+
+	t.Run("TimeoutSecsType", func(t *testing.T) {
+		t.Parallel()
+
+		err := collection.Database().Client().Database("admin").RunCommand(
+			ctx, bson.D{{"shutdown", int32(1)}, {"timeoutSecs", "1"}},
+		).Err()
+
+		expected := mongo.CommandError{
+			Code:    14,
+			Name:    "TypeMismatch",
+			Message: "BSON field 'shutdown.timeoutSecs' is the wrong type 'string', expected types '[long, int, decimal, double]'",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+}
+
+func TestCommandsAdministrationLogRotate(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	t.Run("Rotate", func(t *testing.T) {
+		t.Parallel()
+
+		var res bson.D
+		err := collection.Database().Client().Database("admin").RunCommand(
+			ctx, bson.D{{"logRotate", int32(1)}},
+		).Decode(&res)
+		require.NoError(t, err)
+
+		assert.Equal(t, bson.D{{"ok", float64(1)}}, res)
+	})
+
+	t.Run("NonAdmin", func(t *testing.T) {
+		t.Parallel()
+
+		err := collection.Database().RunCommand(ctx, bson.D{{"logRotate", int32(1)}}).Err()
+
+		expected := mongo.CommandError{
+			Code:    13,
+			Name:    "Unauthorized",
+			Message: "logRotate may only be run against the admin database.",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+}
+
+//nolint:paralleltest // we change a global server parameter
+func TestCommandsAdministrationResponseWarnings(t *testing.T) {
+	if !setup.IsSQLite(t) {
+		t.Skip("Response warnings are FerretDB-specific and added only by SQLite backend")
+	}
+
+	ctx, collection := setup.Setup(t, shareddata.Int32s)
+	admin := collection.Database().Client().Database("admin")
+
+	err := admin.RunCommand(ctx, bson.D{{"setParameter", 1}, {"ferretdbResponseWarnings", true}}).Err()
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		err = admin.RunCommand(ctx, bson.D{{"setParameter", 1}, {"ferretdbResponseWarnings", false}}).Err()
+		require.NoError(t, err)
+	})
+
+	var res bson.D
+	err = collection.Database().RunCommand(ctx, bson.D{
+		{"find", collection.Name()},
+		{"filter", bson.D{{"v", bson.D{{"$ne", int32(0)}}}}},
+		{"sort", bson.D{{"v", 1}}},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	expected := "filter was not pushed down to the backend; all documents were scanned; " +
+		"sort was not pushed down to the backend; documents were sorted in memory"
+	assert.Equal(t, expected, must.NotFail(ConvertDocument(t, res).Get("warning")))
+
+	err = collection.Database().RunCommand(ctx, bson.D{
+		{"find", collection.Name()},
+		{"filter", bson.D{{"v", bson.D{{"$gt", int32(0)}}}}},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	if setup.IsPushdownDisabled() {
+		expected = "filter was not pushed down to the backend; all documents were scanned"
+		assert.Equal(t, expected, must.NotFail(ConvertDocument(t, res).Get("warning")))
+	} else {
+		assert.False(t, ConvertDocument(t, res).Has("warning"), "range filter is pushed down")
+	}
+
+	err = collection.Database().RunCommand(ctx, bson.D{{"find", collection.Name()}}).Decode(&res)
+	require.NoError(t, err)
+	assert.False(t, ConvertDocument(t, res).Has("warning"))
+}
@@ -74,10 +74,8 @@ func TestCommandsDiagnosticExplain(t *testing.T) {
 		},
 	} {
 		name, tc := name, tc
-		t.Run(name, func(tt *testing.T) {
-			tt.Parallel()
-
-			t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/3050")
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
 
 			var actual bson.D
 
@@ -166,7 +164,6 @@ func TestCommandsDiagnosticGetLog(t *testing.T) {
 				Name:    "OperationFailed",
 				Message: `No log named 'nonExistentName'`,
 			},
-			altMessage: `no RecentEntries named: nonExistentName`,
 		},
 		"Nil": {
 			command: bson.D{{"getLog", nil}},
@@ -186,14 +183,12 @@ func TestCommandsDiagnosticGetLog(t *testing.T) {
 		},
 	} {
 		name, tc := name, tc
-		t.Run(name, func(tt *testing.T) {
+		t.Run(name, func(t *testing.T) {
 			if tc.skip != "" {
-				tt.Skip(tc.skip)
+				t.Skip(tc.skip)
 			}
 
-			tt.Parallel()
-
-			t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/3261")
+			t.Parallel()
 
 			require.NotNil(t, tc.command, "command must not be nil")
 
@@ -217,6 +212,10 @@ func TestCommandsDiagnosticGetLog(t *testing.T) {
 					assert.Equal(t, m[key], item)
 				}
 			}
+
+			if log, ok := m["log"].(bson.A); ok {
+				assert.GreaterOrEqual(t, m["totalLinesWritten"], int64(len(log)))
+			}
 		})
 	}
 }
@@ -299,11 +298,149 @@ func TestCommandsDiagnosticValidate(t *testing.T) {
 	testutil.AssertEqual(t, expected, actual)
 }
 
-func TestCommandsDiagnosticValidateError(t *testing.T) {
-	t.Skip("https://github.com/FerretDB/FerretDB/issues/2704")
+func TestCommandsDiagnosticValidateRepair(t *testing.T) {
+	t.Parallel()
+
+	if !setup.IsSQLite(t) {
+		t.Skip("repair is supported only by SQLite backend and standalone MongoDB")
+	}
+
+	ctx, collection := setup.Setup(t, shareddata.Doubles)
+
+	var doc bson.D
+	err := collection.Database().RunCommand(
+		ctx, bson.D{{"validate", collection.Name()}, {"full", true}, {"repair", true}},
+	).Decode(&doc)
+	require.NoError(t, err)
+
+	actual := ConvertDocument(t, doc)
+
+	assert.Equal(t, true, must.NotFail(actual.Get("valid")))
+	assert.Equal(t, false, must.NotFail(actual.Get("repaired")))
+	assert.Equal(t, int32(0), must.NotFail(actual.Get("nInvalidDocuments")))
+
+	nrecords := must.NotFail(actual.Get("nrecords")).(int32)
+	assert.Greater(t, nrecords, int32(0))
+
+	keysPerIndex := must.NotFail(actual.Get("keysPerIndex")).(*types.Document)
+	assert.Equal(t, int64(nrecords), must.NotFail(keysPerIndex.Get("_id_")))
+}
+
+func TestCommandsDiagnosticValidateDBMetadata(t *testing.T) {
+	t.Parallel()
+
+	if !setup.IsSQLite(t) && !setup.IsMongoDB(t) {
+		t.Skip("validateDBMetadata is supported only by SQLite backend")
+	}
+
+	ctx, collection := setup.Setup(t, shareddata.Doubles)
+	db := collection.Database()
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{"v", -1}}})
+	require.NoError(t, err)
+
+	var res bson.D
+	err = db.RunCommand(ctx, bson.D{
+		{"validateDBMetadata", int32(1)},
+		{"apiParameters", bson.D{{"version", "1"}, {"strict", true}}},
+		{"db", db.Name()},
+		{"collection", collection.Name()},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	actual := ConvertDocument(t, res)
+	testutil.AssertEqual(t, must.NotFail(types.NewDocument(
+		"apiVersionErrors", types.MakeArray(0),
+		"ok", float64(1),
+	)), actual)
 
+	t.Run("MissingAPIParameters", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(ctx, bson.D{{"validateDBMetadata", int32(1)}}).Err()
+
+		expected := mongo.CommandError{
+			Code:    40414,
+			Name:    "Location40414",
+			Message: "BSON field 'validateDBMetadata.apiParameters' is missing but a required field",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+}
+
+func TestCommandsDiagnosticListCursors(t *testing.T) {
 	t.Parallel()
 
+	setup.SkipForMongoDB(t, "listCursors is FerretDB specific command")
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}},
+		bson.D{{"_id", int32(2)}},
+		bson.D{{"_id", int32(3)}},
+	})
+	require.NoError(t, err)
+
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetBatchSize(1))
+	require.NoError(t, err)
+
+	defer cursor.Close(ctx)
+
+	admin := collection.Database().Client().Database("admin")
+
+	// listCursors returns cursors of all connections, so only the cursor of this test is checked
+	getCursor := func(t *testing.T) *types.Document {
+		t.Helper()
+
+		var res bson.D
+		err := admin.RunCommand(ctx, bson.D{{"listCursors", int32(1)}}).Decode(&res)
+		require.NoError(t, err)
+
+		cursors := must.NotFail(ConvertDocument(t, res).Get("cursors")).(*types.Array)
+
+		for i := 0; i < cursors.Len(); i++ {
+			c := must.NotFail(cursors.Get(i)).(*types.Document)
+			if must.NotFail(c.Get("id")) == cursor.ID() {
+				return c
+			}
+		}
+
+		t.Fatalf("cursor %d not found", cursor.ID())
+
+		return nil
+	}
+
+	c := getCursor(t)
+	assert.Equal(t, collection.Database().Name()+"."+collection.Name(), must.NotFail(c.Get("ns")))
+	assert.Equal(t, int64(1), must.NotFail(c.Get("nDocsReturned")))
+	assert.Contains(t, c.Keys(), "ageMillis")
+	assert.Contains(t, c.Keys(), "originatingCommand")
+
+	// fetch the first batch and the next one
+	require.True(t, cursor.Next(ctx))
+	require.True(t, cursor.Next(ctx))
+
+	c = getCursor(t)
+	assert.Equal(t, int64(2), must.NotFail(c.Get("nDocsReturned")))
+
+	t.Run("NonAdmin", func(t *testing.T) {
+		err := collection.Database().RunCommand(ctx, bson.D{{"listCursors", int32(1)}}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    13,
+			Name:    "Unauthorized",
+			Message: "listCursors may only be run against the admin database.",
+		}, err)
+	})
+}
+
+func TestCommandsDiagnosticValidateError(t *testing.T) {
+	t.Parallel()
+
+	if !setup.IsMongoDB(t) && !setup.IsSQLite(t) {
+		t.Skip("https://github.com/FerretDB/FerretDB/issues/2704")
+	}
+
 	for name, tc := range map[string]struct { //nolint:vet // for readability
 		command bson.D
 		err     *mongo.CommandError
@@ -72,3 +72,24 @@ func TestCommandsReplication(t *testing.T) {
 		})
 	}
 }
+
+func TestCommandsReplicationCompression(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	for _, command := range []string{"ismaster", "isMaster", "hello"} {
+		command := command
+		t.Run(command, func(t *testing.T) {
+			t.Parallel()
+
+			var actual bson.D
+			err := collection.Database().RunCommand(
+				ctx,
+				bson.D{{command, 1}, {"compression", bson.A{"zstd", "unknown", "snappy"}}},
+			).Decode(&actual)
+			require.NoError(t, err)
+
+			assert.Equal(t, bson.A{"zstd", "snappy"}, actual.Map()["compression"])
+		})
+	}
+}
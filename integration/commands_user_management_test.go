@@ -0,0 +1,246 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestCommandsUserManagement(t *testing.T) {
+	t.Parallel()
+
+	if !setup.IsMongoDB(t) && !setup.IsSQLite(t) {
+		t.Skip("user management commands are implemented only for SQLite backend")
+	}
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+	dbName := db.Name()
+
+	var res bson.D
+	err := db.RunCommand(ctx, bson.D{
+		{"createUser", "testuser"},
+		{"pwd", "password"},
+		{"roles", bson.A{"read"}},
+		{"mechanisms", bson.A{"SCRAM-SHA-256"}},
+	}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{"ok", float64(1)}}, res)
+
+	t.Cleanup(func() {
+		// the user might be already dropped by the test
+		_ = db.RunCommand(ctx, bson.D{{"dropUser", "testuser"}}).Err()
+	})
+
+	err = db.RunCommand(ctx, bson.D{
+		{"createUser", "testuser"},
+		{"pwd", "password"},
+		{"roles", bson.A{}},
+		{"mechanisms", bson.A{"SCRAM-SHA-256"}},
+	}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    51003,
+		Name:    "Location51003",
+		Message: `User "testuser@` + dbName + `" already exists`,
+	}, err)
+
+	usersInfo := func(t *testing.T) *types.Document {
+		t.Helper()
+
+		var res bson.D
+		err := db.RunCommand(ctx, bson.D{{"usersInfo", "testuser"}}).Decode(&res)
+		require.NoError(t, err)
+
+		doc := ConvertDocument(t, res)
+		users := must.NotFail(doc.Get("users")).(*types.Array)
+		require.Equal(t, 1, users.Len())
+
+		user := must.NotFail(users.Get(0)).(*types.Document)
+		assert.False(t, user.Has("credentials"))
+
+		return user
+	}
+
+	user := usersInfo(t)
+	assert.Equal(t, dbName+".testuser", must.NotFail(user.Get("_id")))
+	assert.Equal(t, "testuser", must.NotFail(user.Get("user")))
+	assert.Equal(t, dbName, must.NotFail(user.Get("db")))
+	assert.Equal(t, must.NotFail(types.NewArray("SCRAM-SHA-256")), must.NotFail(user.Get("mechanisms")))
+	assert.Equal(t, must.NotFail(types.NewArray(
+		must.NotFail(types.NewDocument("role", "read", "db", dbName)),
+	)), must.NotFail(user.Get("roles")))
+
+	err = db.RunCommand(ctx, bson.D{
+		{"updateUser", "testuser"},
+		{"roles", bson.A{bson.D{{"role", "readWrite"}, {"db", dbName}}}},
+		{"customData", bson.D{{"foo", "bar"}}},
+	}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{"ok", float64(1)}}, res)
+
+	user = usersInfo(t)
+	assert.Equal(t, must.NotFail(types.NewArray(
+		must.NotFail(types.NewDocument("role", "readWrite", "db", dbName)),
+	)), must.NotFail(user.Get("roles")))
+	assert.Equal(t, must.NotFail(types.NewDocument("foo", "bar")), must.NotFail(user.Get("customData")))
+
+	err = db.RunCommand(ctx, bson.D{{"dropUser", "testuser"}}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{"ok", float64(1)}}, res)
+
+	err = db.RunCommand(ctx, bson.D{{"usersInfo", 1}}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{"users", bson.A{}}, {"ok", float64(1)}}, res)
+
+	err = db.RunCommand(ctx, bson.D{{"dropUser", "testuser"}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    11,
+		Name:    "UserNotFound",
+		Message: "User 'testuser@" + dbName + "' not found",
+	}, err)
+}
+
+func TestCommandsUserManagementRoles(t *testing.T) {
+	t.Parallel()
+
+	if !setup.IsMongoDB(t) && !setup.IsSQLite(t) {
+		t.Skip("user management commands are implemented only for SQLite backend")
+	}
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+	dbName := db.Name()
+
+	var res bson.D
+	err := db.RunCommand(ctx, bson.D{
+		{"createUser", "testuser"},
+		{"pwd", "password"},
+		{"roles", bson.A{"read"}},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = db.RunCommand(ctx, bson.D{{"dropUser", "testuser"}}).Err()
+	})
+
+	userRoles := func(t *testing.T) *types.Array {
+		t.Helper()
+
+		var res bson.D
+		err := db.RunCommand(ctx, bson.D{{"usersInfo", "testuser"}}).Decode(&res)
+		require.NoError(t, err)
+
+		users := must.NotFail(ConvertDocument(t, res).Get("users")).(*types.Array)
+		require.Equal(t, 1, users.Len())
+
+		return must.NotFail(must.NotFail(users.Get(0)).(*types.Document).Get("roles")).(*types.Array)
+	}
+
+	err = db.RunCommand(ctx, bson.D{
+		{"grantRolesToUser", "testuser"},
+		{"roles", bson.A{"read", "readWrite", bson.D{{"role", "dbAdmin"}, {"db", dbName}}}},
+	}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{"ok", float64(1)}}, res)
+
+	roles := userRoles(t)
+	require.Equal(t, 3, roles.Len())
+
+	for _, role := range []string{"read", "readWrite", "dbAdmin"} {
+		assert.True(t, roles.Contains(must.NotFail(types.NewDocument("role", role, "db", dbName))), role)
+	}
+
+	err = db.RunCommand(ctx, bson.D{
+		{"revokeRolesFromUser", "testuser"},
+		{"roles", bson.A{"read", "dbAdmin"}},
+	}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{"ok", float64(1)}}, res)
+
+	assert.Equal(t, must.NotFail(types.NewArray(
+		must.NotFail(types.NewDocument("role", "readWrite", "db", dbName)),
+	)), userRoles(t))
+
+	err = db.RunCommand(ctx, bson.D{
+		{"grantRolesToUser", "testuser"},
+		{"roles", bson.A{"clusterAdmin"}},
+	}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    31,
+		Name:    "RoleNotFound",
+		Message: "Could not find role: clusterAdmin@" + dbName,
+	}, err)
+
+	err = db.RunCommand(ctx, bson.D{
+		{"revokeRolesFromUser", "nosuchuser"},
+		{"roles", bson.A{"read"}},
+	}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    11,
+		Name:    "UserNotFound",
+		Message: "User 'nosuchuser@" + dbName + "' not found",
+	}, err)
+}
+
+func TestCommandsUserManagementRolesInfo(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+	dbName := db.Name()
+
+	var res bson.D
+	err := db.RunCommand(ctx, bson.D{{"rolesInfo", "readWrite"}}).Decode(&res)
+	require.NoError(t, err)
+
+	doc := ConvertDocument(t, res)
+	roles := must.NotFail(doc.Get("roles")).(*types.Array)
+	require.Equal(t, 1, roles.Len())
+
+	role := must.NotFail(roles.Get(0)).(*types.Document)
+	assert.Equal(t, "readWrite", must.NotFail(role.Get("role")))
+	assert.Equal(t, dbName, must.NotFail(role.Get("db")))
+	assert.Equal(t, true, must.NotFail(role.Get("isBuiltin")))
+	assert.False(t, role.Has("privileges"))
+
+	err = db.RunCommand(ctx, bson.D{{"rolesInfo", "nosuchrole"}}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{"roles", bson.A{}}, {"ok", float64(1)}}, res)
+
+	// only user-defined roles are returned by default
+	err = db.RunCommand(ctx, bson.D{{"rolesInfo", 1}}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{"roles", bson.A{}}, {"ok", float64(1)}}, res)
+
+	err = db.RunCommand(ctx, bson.D{
+		{"rolesInfo", bson.D{{"role", "read"}, {"db", dbName}}},
+		{"showPrivileges", true},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	doc = ConvertDocument(t, res)
+	roles = must.NotFail(doc.Get("roles")).(*types.Array)
+	require.Equal(t, 1, roles.Len())
+	assert.True(t, must.NotFail(roles.Get(0)).(*types.Document).Has("privileges"))
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestCountCommandPushdown(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"v", "foo"}},
+		bson.D{{"_id", int32(2)}, {"v", "bar"}},
+		bson.D{{"_id", int32(3)}, {"v", "foo"}},
+		bson.D{{"_id", int32(4)}, {"v", int32(42)}},
+		bson.D{{"_id", int32(5)}, {"a", bson.A{"foo"}}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		command  bson.D
+		expected int32
+		pushdown bool
+	}{
+		"All": {
+			command:  bson.D{},
+			expected: 5,
+			pushdown: true,
+		},
+		"SkipLimit": {
+			command:  bson.D{{"skip", int64(1)}, {"limit", int64(3)}},
+			expected: 3,
+			pushdown: true,
+		},
+		"SkipAll": {
+			command:  bson.D{{"skip", int64(10)}},
+			expected: 0,
+			pushdown: true,
+		},
+		"Eq": {
+			command:  bson.D{{"query", bson.D{{"v", "foo"}}}},
+			expected: 2,
+			pushdown: true,
+		},
+		"EqLimit": {
+			command:  bson.D{{"query", bson.D{{"v", "foo"}}}, {"limit", int64(1)}},
+			expected: 1,
+			pushdown: true,
+		},
+		"Number": {
+			command:  bson.D{{"query", bson.D{{"v", int32(42)}}}},
+			expected: 1,
+		},
+		"Gt": {
+			command:  bson.D{{"query", bson.D{{"v", bson.D{{"$gt", "bar"}}}}}},
+			expected: 2,
+		},
+		"Array": {
+			command:  bson.D{{"query", bson.D{{"a", "foo"}}}},
+			expected: 1,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			command := append(bson.D{{"count", collection.Name()}}, tc.command...)
+
+			t.Run("Explain", func(t *testing.T) {
+				setup.SkipForMongoDB(t, "pushdown is FerretDB specific feature")
+
+				if !setup.IsSQLite(t) {
+					t.Skip("count pushdown is supported only by SQLite backend")
+				}
+
+				var res bson.D
+				err := collection.Database().RunCommand(ctx, bson.D{{"explain", command}}).Decode(&res)
+				require.NoError(t, err)
+
+				doc := ConvertDocument(t, res)
+
+				countPushdown, _ := doc.Get("countPushdown")
+				assert.Equal(t, tc.pushdown && !setup.IsPushdownDisabled(), countPushdown)
+
+				estimatedCount, _ := doc.Get("estimatedCount")
+				assert.Equal(t, false, estimatedCount)
+			})
+
+			t.Run("Count", func(t *testing.T) {
+				var res bson.D
+				err := collection.Database().RunCommand(ctx, command).Decode(&res)
+				require.NoError(t, err)
+
+				n, _ := ConvertDocument(t, res).Get("n")
+				assert.Equal(t, tc.expected, n)
+			})
+		})
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestFailPointFailCommand(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ErrorCode", func(t *testing.T) {
+		t.Parallel()
+
+		s := setup.SetupWithOpts(t, nil)
+		ctx, collection := s.Ctx, s.Collection
+
+		s.ConfigureFailPoint(t, "failCommand", bson.D{{"times", 1}}, bson.D{
+			{"failCommands", bson.A{"find"}},
+			{"errorCode", 2},
+		})
+
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", "foo"}})
+		require.NoError(t, err, "insert is not affected")
+
+		_, err = collection.Find(ctx, bson.D{})
+		expected := mongo.CommandError{
+			Code:    2,
+			Name:    "BadValue",
+			Message: "Failing command via 'failCommand' failpoint",
+		}
+		AssertEqualCommandError(t, expected, err)
+
+		cursor, err := collection.Find(ctx, bson.D{})
+		require.NoError(t, err, "failpoint is disabled after one activation")
+		require.NoError(t, cursor.Close(ctx))
+	})
+
+	t.Run("BlockConnection", func(t *testing.T) {
+		t.Parallel()
+
+		s := setup.SetupWithOpts(t, nil)
+		ctx, collection := s.Ctx, s.Collection
+
+		s.ConfigureFailPoint(t, "failCommand", bson.D{{"times", 1}}, bson.D{
+			{"failCommands", bson.A{"count"}},
+			{"blockConnection", true},
+			{"blockTimeMS", 200},
+		})
+
+		start := time.Now()
+		_, err := collection.EstimatedDocumentCount(ctx)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	})
+
+	t.Run("CloseConnection", func(t *testing.T) {
+		t.Parallel()
+
+		s := setup.SetupWithOpts(t, nil)
+		ctx, collection := s.Ctx, s.Collection
+
+		s.ConfigureFailPoint(t, "failCommand", bson.D{{"times", 1}}, bson.D{
+			{"failCommands", bson.A{"insert"}},
+			{"closeConnection", true},
+		})
+
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", "foo"}})
+		require.Error(t, err)
+		assert.True(t, mongo.IsNetworkError(err) || errors.Is(err, io.EOF), "%v", err)
+	})
+}
+
+func TestFailPointFailBackend(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, nil)
+	ctx, collection := s.Ctx, s.Collection
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "foo"}})
+	require.NoError(t, err)
+
+	s.ConfigureFailPoint(t, "failBackend", "alwaysOn", bson.D{
+		{"methods", bson.A{"Collection.Query"}},
+		{"errorMessage", "injected"},
+	})
+
+	_, err = collection.Find(ctx, bson.D{})
+	require.Error(t, err)
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "bar"}})
+	require.NoError(t, err, "only Collection.Query fails")
+}
+
+func TestFailPointMaxTimeMS(t *testing.T) {
+	t.Parallel()
+
+	if !setup.IsSQLite(t) {
+		t.Skip("failBackend failpoint is supported only by SQLite backend")
+	}
+
+	s := setup.SetupWithOpts(t, nil)
+	ctx, collection := s.Ctx, s.Collection
+	db := collection.Database()
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "foo"}, {"v", int32(1)}})
+	require.NoError(t, err)
+
+	s.ConfigureFailPoint(t, "failBackend", "alwaysOn", bson.D{
+		{"methods", bson.A{"Collection.Query", "Collection.Count"}},
+		{"blockTimeMS", 5000},
+	})
+
+	expected := mongo.CommandError{
+		Code:    50,
+		Name:    "MaxTimeMSExpired",
+		Message: "operation exceeded time limit",
+	}
+
+	for name, command := range map[string]bson.D{
+		"Count": {
+			{"count", collection.Name()},
+			{"maxTimeMS", 100},
+		},
+		"Update": {
+			{"update", collection.Name()},
+			{"updates", bson.A{bson.D{{"q", bson.D{{"_id", "foo"}}}, {"u", bson.D{{"$set", bson.D{{"v", int32(2)}}}}}}}},
+			{"maxTimeMS", 100},
+		},
+		"Delete": {
+			{"delete", collection.Name()},
+			{"deletes", bson.A{bson.D{{"q", bson.D{{"_id", "foo"}}}, {"limit", 1}}}},
+			{"maxTimeMS", 100},
+		},
+	} {
+		name, command := name, command
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			err := db.RunCommand(ctx, command).Err()
+			AssertEqualCommandError(t, expected, err)
+			assert.Less(t, time.Since(start), 5*time.Second)
+		})
+	}
+}
+
+func TestFailPointConfigureErrors(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, nil)
+	ctx := s.Ctx
+
+	// skips the test if failpoints are not available
+	s.ConfigureFailPoint(t, "failCommand", "off", nil)
+
+	for name, tc := range map[string]struct {
+		db       string
+		command  bson.D
+		expected mongo.CommandError
+	}{
+		"NotAdmin": {
+			db:      s.Collection.Database().Name(),
+			command: bson.D{{"configureFailPoint", "failCommand"}, {"mode", "off"}},
+			expected: mongo.CommandError{
+				Code:    13,
+				Name:    "Unauthorized",
+				Message: "configureFailPoint may only be run against the admin database.",
+			},
+		},
+		"UnknownFailPoint": {
+			db:      "admin",
+			command: bson.D{{"configureFailPoint", "noSuchFailPoint"}, {"mode", "off"}},
+			expected: mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "Cannot find failpoint noSuchFailPoint",
+			},
+		},
+		"BadMode": {
+			db:      "admin",
+			command: bson.D{{"configureFailPoint", "failCommand"}, {"mode", "sometimes"}},
+			expected: mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: `Unsupported mode "sometimes"`,
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := s.Collection.Database().Client().Database(tc.db).RunCommand(ctx, tc.command).Err()
+			AssertEqualCommandError(t, tc.expected, err)
+		})
+	}
+}
+
+func TestFailPointWaitForFailPoint(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, nil)
+	ctx, collection := s.Ctx, s.Collection
+	admin := collection.Database().Client().Database("admin")
+
+	s.ConfigureFailPoint(t, "failCommand", "alwaysOn", bson.D{
+		{"failCommands", bson.A{"find"}},
+		{"errorCode", 2},
+	})
+
+	_, err := collection.Find(ctx, bson.D{})
+	require.Error(t, err)
+
+	err = admin.RunCommand(ctx, bson.D{
+		{"waitForFailPoint", "failCommand"},
+		{"timesEntered", 1},
+		{"maxTimeMS", 1000},
+	}).Err()
+	require.NoError(t, err)
+
+	err = admin.RunCommand(ctx, bson.D{
+		{"waitForFailPoint", "failCommand"},
+		{"timesEntered", 2},
+		{"maxTimeMS", 100},
+	}).Err()
+	expected := mongo.CommandError{
+		Code:    50,
+		Name:    "MaxTimeMSExpired",
+		Message: "operation exceeded time limit",
+	}
+	AssertEqualCommandError(t, expected, err)
+}
+
+func TestSleep(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, nil)
+	ctx := s.Ctx
+	admin := s.Collection.Database().Client().Database("admin")
+
+	start := time.Now()
+	err := admin.RunCommand(ctx, bson.D{{"sleep", 1}, {"millis", 200}}).Err()
+
+	var ce mongo.CommandError
+	if errors.As(err, &ce) && ce.Code == 59 {
+		t.Skip("test commands are not enabled")
+	}
+
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+
+	err = s.Collection.Database().RunCommand(ctx, bson.D{{"sleep", 1}, {"millis", 1}}).Err()
+	expected := mongo.CommandError{
+		Code:    13,
+		Name:    "Unauthorized",
+		Message: "sleep may only be run against the admin database.",
+	}
+	AssertEqualCommandError(t, expected, err)
+}
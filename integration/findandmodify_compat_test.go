@@ -774,6 +774,242 @@ func TestFindAndModifyCompatRemove(t *testing.T) {
 	testFindAndModifyCompat(t, testCases)
 }
 
+func TestFindAndModifyCompatFields(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]findAndModifyCompatTestCase{
+		"Include": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "document-composite"}}},
+				{"update", bson.D{{"$set", bson.D{{"foo", "bar"}}}}},
+				{"fields", bson.D{{"v", true}}},
+			},
+		},
+		"ExcludeNew": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "document-composite"}}},
+				{"update", bson.D{{"$set", bson.D{{"foo", "bar"}}}}},
+				{"fields", bson.D{{"_id", false}, {"v", false}}},
+				{"new", true},
+			},
+		},
+		"Slice": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "array-three"}}},
+				{"update", bson.D{{"$set", bson.D{{"foo", "bar"}}}}},
+				{"fields", bson.D{{"v", bson.D{{"$slice", -2}}}}},
+			},
+		},
+		"ElemMatchRemove": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "array-three"}}},
+				{"remove", true},
+				{"fields", bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"$type", "string"}}}}}}},
+			},
+		},
+		"Positional": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "array-three"}, {"v", int32(42)}}},
+				{"update", bson.D{{"$set", bson.D{{"foo", "bar"}}}}},
+				{"fields", bson.D{{"v.$", true}}},
+				{"new", true},
+			},
+		},
+		"Invalid": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "array-three"}}},
+				{"remove", true},
+				{"fields", bson.D{{"v", bson.D{{"$slice", "invalid"}}}}},
+			},
+			resultType: emptyResult,
+		},
+	}
+
+	testFindAndModifyCompat(t, testCases)
+}
+
+func TestFindAndModifyCompatArrayFilters(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]findAndModifyCompatTestCase{
+		"AllPositional": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "array-three"}}},
+				{"update", bson.D{{"$set", bson.D{{"v.$[]", "bar"}}}}},
+				{"new", true},
+			},
+		},
+		"Filtered": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "array-three"}}},
+				{"update", bson.D{{"$set", bson.D{{"v.$[x]", "bar"}}}}},
+				{"arrayFilters", bson.A{bson.D{{"x", int32(42)}}}},
+				{"new", true},
+			},
+		},
+		"FilteredNoMatch": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "array-three"}}},
+				{"update", bson.D{{"$set", bson.D{{"v.$[x]", "bar"}}}}},
+				{"arrayFilters", bson.A{bson.D{{"x", int32(100)}}}},
+				{"new", true},
+			},
+		},
+		"FilteredDocumentField": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "array-documents"}}},
+				{"update", bson.D{{"$inc", bson.D{{"v.$[e].field", int32(1)}}}}},
+				{"arrayFilters", bson.A{bson.D{{"e.field", bson.D{{"$gt", int32(43)}}}}}},
+				{"new", true},
+			},
+		},
+		"NonArray": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "int32"}}},
+				{"update", bson.D{{"$set", bson.D{{"v.$[x]", "bar"}}}}},
+				{"arrayFilters", bson.A{bson.D{{"x", int32(42)}}}},
+			},
+		},
+		"MissingPath": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "int32"}}},
+				{"update", bson.D{{"$set", bson.D{{"missing.$[]", "bar"}}}}},
+			},
+		},
+		"UnusedFilter": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "array-three"}}},
+				{"update", bson.D{{"$set", bson.D{{"v.$[x]", "bar"}}}}},
+				{"arrayFilters", bson.A{bson.D{{"x", int32(42)}}, bson.D{{"y", int32(42)}}}},
+			},
+			resultType: emptyResult,
+		},
+		"MissingFilter": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "array-three"}}},
+				{"update", bson.D{{"$set", bson.D{{"v.$[y]", "bar"}}}}},
+				{"arrayFilters", bson.A{}},
+			},
+			resultType: emptyResult,
+		},
+		"InvalidIdentifier": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "array-three"}}},
+				{"update", bson.D{{"$set", bson.D{{"v.$[X]", "bar"}}}}},
+				{"arrayFilters", bson.A{bson.D{{"X", int32(42)}}}},
+			},
+			resultType: emptyResult,
+		},
+		"DuplicateIdentifier": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "array-three"}}},
+				{"update", bson.D{{"$set", bson.D{{"v.$[x]", "bar"}}}}},
+				{"arrayFilters", bson.A{bson.D{{"x", int32(42)}}, bson.D{{"x", "foo"}}}},
+			},
+			resultType: emptyResult,
+		},
+		"Remove": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "array-three"}}},
+				{"remove", true},
+				{"arrayFilters", bson.A{bson.D{{"x", int32(42)}}}},
+			},
+			resultType: emptyResult,
+		},
+	}
+
+	testFindAndModifyCompat(t, testCases)
+}
+
+func TestFindAndModifyCompatPipeline(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]findAndModifyCompatTestCase{
+		"Set": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "int32"}}},
+				{"update", bson.A{bson.D{{"$set", bson.D{{"foo", "bar"}}}}}},
+				{"new", true},
+			},
+		},
+		"AddFieldsFieldPath": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "int32"}}},
+				{"update", bson.A{bson.D{{"$addFields", bson.D{{"copy", "$v"}, {"missing", "$foo"}}}}}},
+				{"new", true},
+			},
+		},
+		"Unset": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "int32"}}},
+				{"update", bson.A{bson.D{{"$unset", bson.A{"v", "_id"}}}}},
+				{"new", true},
+			},
+		},
+		"Project": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "int32"}}},
+				{"update", bson.A{
+					bson.D{{"$set", bson.D{{"foo", "bar"}}}},
+					bson.D{{"$project", bson.D{{"foo", true}}}},
+				}},
+				{"new", true},
+			},
+		},
+		"ReplaceWith": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "int32"}}},
+				{"update", bson.A{bson.D{{"$replaceWith", bson.D{{"old", "$v"}}}}}},
+				{"new", true},
+			},
+		},
+		"ReplaceRoot": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "int32"}}},
+				{"update", bson.A{bson.D{{"$replaceRoot", bson.D{{"newRoot", bson.D{{"_id", "$_id"}, {"old", "$v"}}}}}}}},
+				{"new", true},
+			},
+		},
+		"Upsert": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "pipeline-upsert"}}},
+				{"update", bson.A{bson.D{{"$set", bson.D{{"v", "upserted"}}}}}},
+				{"upsert", true},
+				{"new", true},
+			},
+		},
+		"NotAllowedStage": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "int32"}}},
+				{"update", bson.A{bson.D{{"$match", bson.D{{"v", int32(42)}}}}}},
+			},
+			resultType: emptyResult,
+		},
+		"ReplaceWithNonObject": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "int32"}}},
+				{"update", bson.A{bson.D{{"$replaceWith", "$v"}}}},
+			},
+		},
+		"ChangeID": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "int32"}}},
+				{"update", bson.A{bson.D{{"$set", bson.D{{"_id", "changed"}}}}}},
+			},
+		},
+		"ArrayFilters": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "int32"}}},
+				{"update", bson.A{bson.D{{"$set", bson.D{{"foo", "bar"}}}}}},
+				{"arrayFilters", bson.A{bson.D{{"x", int32(42)}}}},
+			},
+			resultType: emptyResult,
+		},
+	}
+
+	testFindAndModifyCompat(t, testCases)
+}
+
 // findAndModifyCompatTestCase describes findAndModify compatibility test case.
 type findAndModifyCompatTestCase struct {
 	command bson.D
@@ -202,7 +202,8 @@ func TestFindAndModifyCommandErrors(t *testing.T) {
 					`cannot use the part (v of v.-1) to traverse the element ` +
 					`({v: [ { foo: [ { bar: "hello" }, { bar: "world" } ] } ]})`,
 			},
-			altMessage: "cannot use path 'v.-1' to traverse the document",
+			altMessage: `cannot use the part (v of v.-1) to traverse the element ` +
+				`({v: [ { foo: [ { bar: "hello" }, { bar: "world" } ] } ]})`,
 		},
 		"RenameUnsuitableValue": {
 			command: bson.D{
@@ -215,7 +216,7 @@ func TestFindAndModifyCommandErrors(t *testing.T) {
 				Message: `Plan executor error during findAndModify :: caused by :: ` +
 					`cannot use the part (bar of v.0.foo.0.bar.z) to traverse the element ({bar: "hello"})`,
 			},
-			altMessage: `types.getByPath: can't access string by path "z"`,
+			altMessage: `cannot use the part (bar of v.0.foo.0.bar.z) to traverse the element ({bar: "hello"})`,
 		},
 		"IncTypeMismatch": {
 			command: bson.D{
@@ -17,6 +17,7 @@ package integration
 import (
 	"math"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/AlekSi/pointer"
@@ -532,8 +533,6 @@ func TestGetMoreBatchSizeCursor(t *testing.T) {
 			}
 
 			// next batch obtain from implicit call to `getMore` has the rest of the documents, not default batchSize
-			// 16MB batchSize limit
-			// TODO https://github.com/FerretDB/FerretDB/issues/2824
 			ok := cursor.Next(ctx)
 			require.True(t, ok, "expected to have next document")
 			require.Equal(t, 118, cursor.RemainingBatchLength())
@@ -552,8 +551,6 @@ func TestGetMoreBatchSizeCursor(t *testing.T) {
 			require.Equal(t, 0, cursor.RemainingBatchLength())
 
 			// next batch obtain from implicit call to `getMore` has the rest of the documents, not 0 batchSize
-			// 16MB batchSize limit
-			// TODO https://github.com/FerretDB/FerretDB/issues/2824
 			ok := cursor.Next(ctx)
 			require.True(t, ok, "expected to have next document")
 			require.Equal(t, 219, cursor.RemainingBatchLength())
@@ -582,6 +579,61 @@ func TestGetMoreBatchSizeCursor(t *testing.T) {
 	})
 }
 
+func TestGetMoreBatchSizeLimit(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	// documents of about 15.9 MB; two of them do not fit into a single 16 MiB batch
+	v := strings.Repeat("x", 15_900_000)
+
+	for i := int32(0); i < 3; i++ {
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", i}, {"v", v}})
+		require.NoError(t, err)
+	}
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", int32(3)}, {"v", "small"}})
+	require.NoError(t, err)
+
+	for name, f := range map[string]func() (*mongo.Cursor, error){
+		"Find": func() (*mongo.Cursor, error) {
+			return collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+		},
+		"Aggregate": func() (*mongo.Cursor, error) {
+			return collection.Aggregate(ctx, bson.A{bson.D{{"$sort", bson.D{{"_id", 1}}}}})
+		},
+	} {
+		name, f := name, f
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := f()
+			require.NoError(t, err)
+
+			defer cursor.Close(ctx)
+
+			// each batch, including the ones returned by getMore, contains only one large document
+			expected := []struct {
+				id        int32
+				remaining int
+			}{
+				{0, 0},
+				{1, 0},
+				{2, 1},
+				{3, 0},
+			}
+
+			for _, e := range expected {
+				require.True(t, cursor.Next(ctx), "expected to have next document")
+				assert.Equal(t, e.id, cursor.Current.Lookup("_id").Int32())
+				assert.Equal(t, e.remaining, cursor.RemainingBatchLength())
+			}
+
+			require.False(t, cursor.Next(ctx), "cursor exhausted, not expecting next document")
+			require.NoError(t, cursor.Err())
+		})
+	}
+}
+
 func TestGetMoreCommandConnection(t *testing.T) {
 	t.Parallel()
 
@@ -897,9 +949,7 @@ func TestGetMoreCommandMaxTimeMSCursor(t *testing.T) {
 	_, err := collection.InsertMany(ctx, arr)
 	require.NoError(t, err)
 
-	t.Run("FindExpire", func(tt *testing.T) {
-		t := setup.FailsForFerretDB(tt, "https://github.com/FerretDB/FerretDB/issues/2983")
-
+	t.Run("FindExpire", func(t *testing.T) {
 		opts := options.Find().
 			// set batchSize big enough to hit maxTimeMS
 			SetBatchSize(2000).
@@ -936,9 +986,7 @@ func TestGetMoreCommandMaxTimeMSCursor(t *testing.T) {
 		AssertMatchesCommandError(t, mongo.CommandError{Code: 50, Name: "MaxTimeMSExpired"}, cursor.Err())
 	})
 
-	t.Run("FindGetMoreMaxTimeMS", func(tt *testing.T) {
-		t := setup.FailsForFerretDB(tt, "https://github.com/FerretDB/FerretDB/issues/2983")
-
+	t.Run("FindGetMoreMaxTimeMS", func(t *testing.T) {
 		var res bson.D
 		err := collection.Database().RunCommand(ctx, bson.D{
 			{"find", collection.Name()},
@@ -975,9 +1023,7 @@ func TestGetMoreCommandMaxTimeMSCursor(t *testing.T) {
 		)
 	})
 
-	t.Run("AggregateExpire", func(tt *testing.T) {
-		t := setup.FailsForFerretDB(tt, "https://github.com/FerretDB/FerretDB/issues/2983")
-
+	t.Run("AggregateExpire", func(t *testing.T) {
 		opts := options.Aggregate().
 			// set batchSize big enough to hit maxTimeMS
 			SetBatchSize(2000).
@@ -1013,9 +1059,7 @@ func TestGetMoreCommandMaxTimeMSCursor(t *testing.T) {
 		AssertMatchesCommandError(t, mongo.CommandError{Code: 50, Name: "MaxTimeMSExpired"}, cursor.Err())
 	})
 
-	t.Run("AggregateGetMoreMaxTimeMS", func(tt *testing.T) {
-		t := setup.FailsForFerretDB(tt, "https://github.com/FerretDB/FerretDB/issues/2983")
-
+	t.Run("AggregateGetMoreMaxTimeMS", func(t *testing.T) {
 		var res bson.D
 		err := collection.Database().RunCommand(ctx, bson.D{
 			{"aggregate", collection.Name()},
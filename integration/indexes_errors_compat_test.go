@@ -0,0 +1,75 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+// TestCreateIndexesCompatKeyErrors checks that invalid index key patterns
+// are rejected with the same errors by all backends.
+func TestCreateIndexesCompatKeyErrors(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers: []shareddata.Provider{shareddata.Int32s},
+	})
+
+	ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+	for name, key := range map[string]bson.D{
+		"Zero":          {{"v", 0}},
+		"ZeroDouble":    {{"v", 0.0}},
+		"UnknownPlugin": {{"v", "foo"}},
+		"Bool":          {{"v", true}},
+		"Null":          {{"v", nil}},
+		"Document":      {{"v", bson.D{}}},
+		"Array":         {{"v", bson.A{1}}},
+		"EmptyField":    {{"", 1}},
+		"EmptyElement":  {{"v..foo", 1}},
+		"LeadingDot":    {{".v", 1}},
+		"TrailingDot":   {{"v.", 1}},
+		"DollarPrefix":  {{"$v", 1}},
+		"NestedDollar":  {{"v.$foo", 1}},
+		"CompoundZero":  {{"v", 1}, {"foo", 0}},
+	} {
+		name, key := name, key
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			indexes := bson.A{bson.D{{"key", key}, {"name", "test_index"}}}
+
+			var targetRes, compatRes bson.D
+			targetErr := targetCollection.Database().RunCommand(ctx, bson.D{
+				{"createIndexes", targetCollection.Name()},
+				{"indexes", indexes},
+			}).Decode(&targetRes)
+			compatErr := compatCollection.Database().RunCommand(ctx, bson.D{
+				{"createIndexes", compatCollection.Name()},
+				{"indexes", indexes},
+			}).Decode(&compatRes)
+
+			require.Error(t, compatErr)
+			AssertMatchesCommandError(t, compatErr, targetErr)
+		})
+	}
+}
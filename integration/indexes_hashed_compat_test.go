@@ -0,0 +1,156 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+// TestIndexesHashedCompatFind checks that equality filters return the same documents
+// when hashed indexes are used.
+func TestIndexesHashedCompatFind(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers: []shareddata.Provider{shareddata.Scalars},
+	})
+
+	ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+	model := mongo.IndexModel{Keys: bson.D{{"v", "hashed"}}}
+
+	targetName, targetErr := targetCollection.Indexes().CreateOne(ctx, model)
+	compatName, compatErr := compatCollection.Indexes().CreateOne(ctx, model)
+	require.NoError(t, compatErr)
+	require.NoError(t, targetErr)
+	require.Equal(t, compatName, targetName)
+
+	for name, filter := range map[string]bson.D{
+		"Int32":     {{"v", int32(42)}},
+		"Int64":     {{"v", int64(42)}},
+		"Double":    {{"v", 42.0}},
+		"Fraction":  {{"v", 42.13}},
+		"MaxDouble": {{"v", math.MaxFloat64}},
+		"String":    {{"v", "foo"}},
+		"Bool":      {{"v", true}},
+		"ObjectID":  {{"v", primitive.ObjectID{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x10, 0x11}}},
+		"DateTime":  {{"v", primitive.NewDateTimeFromTime(time.Date(2021, 11, 1, 10, 18, 42, 123000000, time.UTC))}},
+		"Null":      {{"v", nil}},
+		"Eq":        {{"v", bson.D{{"$eq", int32(42)}}}},
+		"Ne":        {{"v", bson.D{{"$ne", int32(42)}}}},
+		"Missing":   {{"v", "missing"}},
+	} {
+		name, filter := name, filter
+
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			t.Parallel()
+
+			opts := options.Find().SetSort(bson.D{{"_id", 1}})
+
+			targetCursor, targetErr := targetCollection.Find(ctx, filter, opts)
+			compatCursor, compatErr := compatCollection.Find(ctx, filter, opts)
+			require.NoError(t, compatErr)
+			require.NoError(t, targetErr)
+
+			targetRes := FetchAll(t, ctx, targetCursor)
+			compatRes := FetchAll(t, ctx, compatCursor)
+
+			t.Logf("Compat (expected) IDs: %v", CollectIDs(t, compatRes))
+			t.Logf("Target (actual)   IDs: %v", CollectIDs(t, targetRes))
+			AssertEqualDocumentsSlice(t, compatRes, targetRes)
+		})
+	}
+}
+
+// TestIndexesHashedCompatErrors checks hashed index restrictions.
+func TestIndexesHashedCompatErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Unique", func(tt *testing.T) {
+		tt.Parallel()
+
+		t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/3175")
+
+		s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+			Providers: []shareddata.Provider{shareddata.Int32s},
+		})
+
+		ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+		model := mongo.IndexModel{Keys: bson.D{{"v", "hashed"}}, Options: options.Index().SetUnique(true)}
+
+		_, targetErr := targetCollection.Indexes().CreateOne(ctx, model)
+		_, compatErr := compatCollection.Indexes().CreateOne(ctx, model)
+		require.Error(t, compatErr)
+		AssertMatchesCommandError(t, compatErr, targetErr)
+	})
+
+	t.Run("InsertArray", func(tt *testing.T) {
+		tt.Parallel()
+
+		t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/3175")
+
+		s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+			Providers: []shareddata.Provider{shareddata.Int32s},
+		})
+
+		ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+		model := mongo.IndexModel{Keys: bson.D{{"v", "hashed"}}}
+
+		_, targetErr := targetCollection.Indexes().CreateOne(ctx, model)
+		_, compatErr := compatCollection.Indexes().CreateOne(ctx, model)
+		require.NoError(t, compatErr)
+		require.NoError(t, targetErr)
+
+		doc := bson.D{{"_id", "array"}, {"v", bson.A{int32(1)}}}
+
+		_, targetErr = targetCollection.InsertOne(ctx, doc)
+		_, compatErr = compatCollection.InsertOne(ctx, doc)
+		require.Error(t, compatErr)
+		AssertMatchesWriteError(t, compatErr, targetErr)
+	})
+
+	t.Run("ExistingArray", func(tt *testing.T) {
+		tt.Parallel()
+
+		t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/3175")
+
+		s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+			Providers: []shareddata.Provider{shareddata.ArrayMixedTypes},
+		})
+
+		ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+		model := mongo.IndexModel{Keys: bson.D{{"v", "hashed"}}}
+
+		_, targetErr := targetCollection.Indexes().CreateOne(ctx, model)
+		_, compatErr := compatCollection.Indexes().CreateOne(ctx, model)
+		require.Error(t, compatErr)
+		AssertMatchesCommandError(t, compatErr, targetErr)
+	})
+}
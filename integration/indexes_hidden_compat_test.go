@@ -0,0 +1,160 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+	"github.com/FerretDB/FerretDB/internal/util/testutil/testtb"
+)
+
+// hiddenIndexes returns names of hidden indexes of the given collection.
+func hiddenIndexes(t testtb.TB, ctx context.Context, collection *mongo.Collection) []string {
+	t.Helper()
+
+	cursor, err := collection.Indexes().List(ctx)
+	require.NoError(t, err)
+
+	var res []string
+
+	for _, spec := range FetchAll(t, ctx, cursor) {
+		m := spec.Map()
+		if hidden, _ := m["hidden"].(bool); hidden {
+			res = append(res, m["name"].(string))
+		}
+	}
+
+	return res
+}
+
+// TestIndexesHiddenCompat checks that hidden indexes can be created, listed, unhidden,
+// and do not change query results.
+func TestIndexesHiddenCompat(tt *testing.T) {
+	tt.Parallel()
+
+	t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/3175")
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers: []shareddata.Provider{shareddata.Scalars},
+	})
+
+	ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+	model := mongo.IndexModel{Keys: bson.D{{"v", 1}}, Options: options.Index().SetHidden(true)}
+
+	targetName, targetErr := targetCollection.Indexes().CreateOne(ctx, model)
+	compatName, compatErr := compatCollection.Indexes().CreateOne(ctx, model)
+	require.NoError(t, compatErr)
+	require.NoError(t, targetErr)
+	require.Equal(t, compatName, targetName)
+
+	assert.Equal(t, hiddenIndexes(t, ctx, compatCollection), hiddenIndexes(t, ctx, targetCollection))
+
+	filter := bson.D{{"v", int32(42)}}
+	opts := options.Find().SetSort(bson.D{{"_id", 1}})
+
+	targetCursor, targetErr := targetCollection.Find(ctx, filter, opts)
+	compatCursor, compatErr := compatCollection.Find(ctx, filter, opts)
+	require.NoError(t, compatErr)
+	require.NoError(t, targetErr)
+	AssertEqualDocumentsSlice(t, FetchAll(t, ctx, compatCursor), FetchAll(t, ctx, targetCursor))
+
+	for _, hidden := range []bool{false, false, true} {
+		command := bson.D{
+			{"collMod", targetCollection.Name()},
+			{"index", bson.D{{"name", targetName}, {"hidden", hidden}}},
+		}
+
+		var targetRes, compatRes bson.D
+		targetErr = targetCollection.Database().RunCommand(ctx, command).Decode(&targetRes)
+		compatErr = compatCollection.Database().RunCommand(ctx, command).Decode(&compatRes)
+		require.NoError(t, compatErr)
+		require.NoError(t, targetErr)
+		AssertEqualDocuments(t, compatRes, targetRes)
+
+		assert.Equal(t, hiddenIndexes(t, ctx, compatCollection), hiddenIndexes(t, ctx, targetCollection))
+	}
+}
+
+// TestIndexesHiddenCompatErrors checks collMod errors for index hiding.
+func TestIndexesHiddenCompatErrors(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		index          bson.D // required
+		failsForSQLite string // non-empty value expects test to fail for SQLite backend
+	}{
+		"IDIndex": {
+			index:          bson.D{{"name", "_id_"}, {"hidden", true}},
+			failsForSQLite: "https://github.com/FerretDB/FerretDB/issues/3175",
+		},
+		"NotFound": {
+			index:          bson.D{{"name", "missing"}, {"hidden", true}},
+			failsForSQLite: "https://github.com/FerretDB/FerretDB/issues/3175",
+		},
+		"KeyNotFound": {
+			index:          bson.D{{"keyPattern", bson.D{{"missing", 1}}}, {"hidden", true}},
+			failsForSQLite: "https://github.com/FerretDB/FerretDB/issues/3175",
+		},
+		"MissingField": {
+			index: bson.D{{"name", "_id_"}},
+		},
+		"NameAndKey": {
+			index:          bson.D{{"name", "_id_"}, {"keyPattern", bson.D{{"_id", 1}}}, {"hidden", true}},
+			failsForSQLite: "https://github.com/FerretDB/FerretDB/issues/3175",
+		},
+		"WrongType": {
+			index:          bson.D{{"name", "_id_"}, {"hidden", "true"}},
+			failsForSQLite: "https://github.com/FerretDB/FerretDB/issues/3175",
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(tt *testing.T) {
+			tt.Parallel()
+
+			var t testtb.TB = tt
+			if tc.failsForSQLite != "" {
+				t = setup.FailsForSQLite(tt, tc.failsForSQLite)
+			}
+
+			s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+				Providers: []shareddata.Provider{shareddata.Int32s},
+			})
+
+			ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+			var targetRes, compatRes bson.D
+			targetErr := targetCollection.Database().RunCommand(
+				ctx, bson.D{{"collMod", targetCollection.Name()}, {"index", tc.index}},
+			).Decode(&targetRes)
+			compatErr := compatCollection.Database().RunCommand(
+				ctx, bson.D{{"collMod", compatCollection.Name()}, {"index", tc.index}},
+			).Decode(&compatRes)
+
+			require.Error(t, compatErr)
+			AssertMatchesCommandError(t, compatErr, targetErr)
+		})
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+// TestIndexesMultikeyCompatFind checks that filters return the same documents
+// when arrays of mixed types are indexed by multikey index.
+func TestIndexesMultikeyCompatFind(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers: []shareddata.Provider{shareddata.ArrayMixedTypes},
+	})
+
+	ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+	for _, keys := range []bson.D{{{"v", 1}}, {{"v.foo", 1}}} {
+		_, targetErr := targetCollection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: keys})
+		_, compatErr := compatCollection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: keys})
+		require.NoError(t, compatErr)
+		require.NoError(t, targetErr)
+	}
+
+	for name, filter := range map[string]bson.D{
+		"Int32":         {{"v", int32(42)}},
+		"Double":        {{"v", 42.0}},
+		"String":        {{"v", "foo"}},
+		"Null":          {{"v", nil}},
+		"EmptyArray":    {{"v", bson.A{}}},
+		"NestedArray":   {{"v", bson.A{int32(42), "foo"}}},
+		"Gt":            {{"v", bson.D{{"$gt", int32(42)}}}},
+		"Lte":           {{"v", bson.D{{"$lte", int64(42)}}}},
+		"In":            {{"v", bson.D{{"$in", bson.A{int32(44), "bar"}}}}},
+		"Ne":            {{"v", bson.D{{"$ne", int32(42)}}}},
+		"ElemMatch":     {{"v", bson.D{{"$elemMatch", bson.D{{"$gte", int32(43)}}}}}},
+		"All":           {{"v", bson.D{{"$all", bson.A{int32(42), "foo"}}}}},
+		"Size":          {{"v", bson.D{{"$size", int32(3)}}}},
+		"DotNotation":   {{"v.foo", int32(42)}},
+		"ArrayIndex":    {{"v.0", int32(42)}},
+		"TypeString":    {{"v", bson.D{{"$type", "string"}}}},
+		"ExistsNested":  {{"v.foo", bson.D{{"$exists", true}}}},
+		"GtAndLtRanges": {{"v", bson.D{{"$gt", int32(42)}, {"$lt", int32(43)}}}},
+	} {
+		name, filter := name, filter
+
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			t.Parallel()
+
+			opts := options.Find().SetSort(bson.D{{"_id", 1}})
+
+			targetCursor, targetErr := targetCollection.Find(ctx, filter, opts)
+			compatCursor, compatErr := compatCollection.Find(ctx, filter, opts)
+			require.NoError(t, compatErr)
+			require.NoError(t, targetErr)
+
+			targetRes := FetchAll(t, ctx, targetCursor)
+			compatRes := FetchAll(t, ctx, compatCursor)
+
+			t.Logf("Compat (expected) IDs: %v", CollectIDs(t, compatRes))
+			t.Logf("Target (actual)   IDs: %v", CollectIDs(t, targetRes))
+			AssertEqualDocumentsSlice(t, compatRes, targetRes)
+		})
+	}
+}
+
+// TestIndexesMultikeyCompatUnique checks that unique multikey index
+// rejects documents sharing any array element with other documents.
+func TestIndexesMultikeyCompatUnique(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:vet // for readability
+		keys bson.D   // required, unique index key
+		docs []bson.D // required, documents to insert one by one
+	}{
+		"ArrayElements": {
+			keys: bson.D{{"v", 1}},
+			docs: []bson.D{
+				{{"_id", 1}, {"v", bson.A{int32(1), "foo"}}},
+				{{"_id", 2}, {"v", bson.A{"foo", true}}},
+			},
+		},
+		"ArrayAndScalar": {
+			keys: bson.D{{"v", 1}},
+			docs: []bson.D{
+				{{"_id", 1}, {"v", int32(2)}},
+				{{"_id", 2}, {"v", bson.A{int32(1), int32(2)}}},
+			},
+		},
+		"ScalarAndArray": {
+			keys: bson.D{{"v", 1}},
+			docs: []bson.D{
+				{{"_id", 1}, {"v", bson.A{int32(1), int32(2)}}},
+				{{"_id", 2}, {"v", int32(2)}},
+			},
+		},
+		"NumericTypes": {
+			keys: bson.D{{"v", 1}},
+			docs: []bson.D{
+				{{"_id", 1}, {"v", bson.A{int32(1), "foo"}}},
+				{{"_id", 2}, {"v", bson.A{1.0}}},
+			},
+		},
+		"SameDocumentDuplicates": {
+			keys: bson.D{{"v", 1}},
+			docs: []bson.D{
+				{{"_id", 1}, {"v", bson.A{int32(1), int64(1), 1.0}}},
+				{{"_id", 2}, {"v", bson.A{int32(2)}}},
+			},
+		},
+		"NoIntersection": {
+			keys: bson.D{{"v", 1}},
+			docs: []bson.D{
+				{{"_id", 1}, {"v", bson.A{int32(1), "foo"}}},
+				{{"_id", 2}, {"v", bson.A{int32(2), "bar"}}},
+			},
+		},
+		"NestedArray": {
+			keys: bson.D{{"v", 1}},
+			docs: []bson.D{
+				{{"_id", 1}, {"v", bson.A{bson.A{int32(1)}}}},
+				{{"_id", 2}, {"v", bson.A{int32(1)}}},
+			},
+		},
+		"ArrayDocuments": {
+			keys: bson.D{{"v.foo", 1}},
+			docs: []bson.D{
+				{{"_id", 1}, {"v", bson.A{bson.D{{"foo", int32(1)}}, bson.D{{"foo", "bar"}}}}},
+				{{"_id", 2}, {"v", bson.D{{"foo", "bar"}}}},
+			},
+		},
+		"Compound": {
+			keys: bson.D{{"v", 1}, {"foo", 1}},
+			docs: []bson.D{
+				{{"_id", 1}, {"v", bson.A{int32(1), int32(2)}}, {"foo", "bar"}},
+				{{"_id", 2}, {"v", bson.A{int32(2), int32(3)}}, {"foo", "baz"}},
+				{{"_id", 3}, {"v", int32(2)}, {"foo", "bar"}},
+			},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(tt *testing.T) {
+			tt.Helper()
+			tt.Parallel()
+
+			t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/3175")
+
+			s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+				Providers: []shareddata.Provider{shareddata.ArrayMixedTypes},
+			})
+
+			ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+			_, targetErr := targetCollection.DeleteMany(ctx, bson.D{})
+			_, compatErr := compatCollection.DeleteMany(ctx, bson.D{})
+			require.NoError(t, compatErr)
+			require.NoError(t, targetErr)
+
+			model := mongo.IndexModel{Keys: tc.keys, Options: options.Index().SetUnique(true)}
+
+			_, targetErr = targetCollection.Indexes().CreateOne(ctx, model)
+			_, compatErr = compatCollection.Indexes().CreateOne(ctx, model)
+			require.NoError(t, compatErr)
+			require.NoError(t, targetErr)
+
+			for _, doc := range tc.docs {
+				_, targetErr = targetCollection.InsertOne(ctx, doc)
+				_, compatErr = compatCollection.InsertOne(ctx, doc)
+
+				if targetErr != nil {
+					t.Logf("Target error: %v", targetErr)
+					t.Logf("Compat error: %v", compatErr)
+
+					// error messages are intentionally not compared
+					AssertMatchesWriteError(t, compatErr, targetErr)
+
+					continue
+				}
+
+				require.NoError(t, compatErr, "compat error; target returned no error")
+			}
+
+			assert.Equal(t, FindAll(t, ctx, compatCollection), FindAll(t, ctx, targetCollection))
+		})
+	}
+}
+
+// TestIndexesMultikeyCompatParallelArrays checks that compound index
+// could not index documents with array values for more than one indexed field.
+func TestIndexesMultikeyCompatParallelArrays(tt *testing.T) {
+	tt.Parallel()
+
+	t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/3175")
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers: []shareddata.Provider{shareddata.ArrayMixedTypes},
+	})
+
+	ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+	doc := bson.D{{"_id", "parallel"}, {"foo", bson.A{int32(1)}}, {"v", bson.A{int32(2)}}}
+
+	_, targetErr := targetCollection.InsertOne(ctx, doc)
+	_, compatErr := compatCollection.InsertOne(ctx, doc)
+	require.NoError(t, compatErr)
+	require.NoError(t, targetErr)
+
+	// existing document has parallel arrays
+	model := mongo.IndexModel{Keys: bson.D{{"v", 1}, {"foo", 1}}}
+
+	_, targetErr = targetCollection.Indexes().CreateOne(ctx, model)
+	_, compatErr = compatCollection.Indexes().CreateOne(ctx, model)
+	require.Error(t, compatErr)
+	AssertMatchesCommandError(t, compatErr, targetErr)
+
+	_, targetErr = targetCollection.DeleteOne(ctx, bson.D{{"_id", "parallel"}})
+	_, compatErr = compatCollection.DeleteOne(ctx, bson.D{{"_id", "parallel"}})
+	require.NoError(t, compatErr)
+	require.NoError(t, targetErr)
+
+	_, targetErr = targetCollection.Indexes().CreateOne(ctx, model)
+	_, compatErr = compatCollection.Indexes().CreateOne(ctx, model)
+	require.NoError(t, compatErr)
+	require.NoError(t, targetErr)
+
+	// inserted document has parallel arrays
+	_, targetErr = targetCollection.InsertOne(ctx, doc)
+	_, compatErr = compatCollection.InsertOne(ctx, doc)
+	require.Error(t, compatErr)
+	AssertMatchesWriteError(t, compatErr, targetErr)
+
+	// updated document has parallel arrays
+	update := bson.D{{"$set", bson.D{{"foo", bson.A{int32(1), int32(2)}}}}}
+
+	_, targetErr = targetCollection.UpdateOne(ctx, bson.D{{"_id", "array-mixed-numbers"}}, update)
+	_, compatErr = compatCollection.UpdateOne(ctx, bson.D{{"_id", "array-mixed-numbers"}}, update)
+	require.Error(t, compatErr)
+	AssertMatchesWriteError(t, compatErr, targetErr)
+
+	assert.Equal(t, FindAll(t, ctx, compatCollection), FindAll(t, ctx, targetCollection))
+}
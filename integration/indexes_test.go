@@ -26,8 +26,8 @@ import (
 	"github.com/FerretDB/FerretDB/integration/shareddata"
 )
 
-func TestDropIndexesCommandErrors(tt *testing.T) {
-	tt.Parallel()
+func TestDropIndexesCommandErrors(t *testing.T) {
+	t.Parallel()
 
 	for name, tc := range map[string]struct { //nolint:vet // for readability
 		toCreate []mongo.IndexModel // optional, if set, create the given indexes before drop is called
@@ -132,14 +132,12 @@ func TestDropIndexesCommandErrors(tt *testing.T) {
 		},
 	} {
 		name, tc := name, tc
-		tt.Run(name, func(tt *testing.T) {
+		t.Run(name, func(t *testing.T) {
 			if tc.skip != "" {
-				tt.Skip(tc.skip)
+				t.Skip(tc.skip)
 			}
 
-			tt.Parallel()
-
-			t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/3183")
+			t.Parallel()
 
 			if tc.command != nil {
 				require.Nil(t, tc.toDrop, "toDrop must be nil when using command")
@@ -177,8 +175,8 @@ func TestDropIndexesCommandErrors(tt *testing.T) {
 	}
 }
 
-func TestCreateIndexesCommandInvalidSpec(tt *testing.T) {
-	tt.Parallel()
+func TestCreateIndexesCommandInvalidSpec(t *testing.T) {
+	t.Parallel()
 
 	for name, tc := range map[string]struct {
 		indexes        any  // optional
@@ -371,14 +369,12 @@ func TestCreateIndexesCommandInvalidSpec(tt *testing.T) {
 		},
 	} {
 		name, tc := name, tc
-		tt.Run(name, func(tt *testing.T) {
+		t.Run(name, func(t *testing.T) {
 			if tc.skip != "" {
-				tt.Skip(tc.skip)
+				t.Skip(tc.skip)
 			}
 
-			tt.Parallel()
-
-			t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/3183")
+			t.Parallel()
 
 			if tc.missingIndexes {
 				require.Nil(t, tc.indexes, "indexes must be nil if missingIndexes is true")
@@ -413,8 +409,8 @@ func TestCreateIndexesCommandInvalidSpec(tt *testing.T) {
 	}
 }
 
-func TestCreateIndexesCommandInvalidCollection(tt *testing.T) {
-	tt.Parallel()
+func TestCreateIndexesCommandInvalidCollection(t *testing.T) {
+	t.Parallel()
 
 	for name, tc := range map[string]struct {
 		collectionName any
@@ -469,14 +465,12 @@ func TestCreateIndexesCommandInvalidCollection(tt *testing.T) {
 		},
 	} {
 		name, tc := name, tc
-		tt.Run(name, func(tt *testing.T) {
+		t.Run(name, func(t *testing.T) {
 			if tc.skip != "" {
-				tt.Skip(tc.skip)
+				t.Skip(tc.skip)
 			}
 
-			tt.Parallel()
-
-			t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/3183")
+			t.Parallel()
 
 			provider := shareddata.ArrayDocuments // one provider is enough to check for errors
 			ctx, collection := setup.Setup(t, provider)
@@ -495,8 +489,8 @@ func TestCreateIndexesCommandInvalidCollection(tt *testing.T) {
 	}
 }
 
-func TestDropIndexesCommandInvalidCollection(tt *testing.T) {
-	tt.Parallel()
+func TestDropIndexesCommandInvalidCollection(t *testing.T) {
+	t.Parallel()
 
 	for name, tc := range map[string]struct {
 		collectionName any
@@ -545,14 +539,12 @@ func TestDropIndexesCommandInvalidCollection(tt *testing.T) {
 		},
 	} {
 		name, tc := name, tc
-		tt.Run(name, func(tt *testing.T) {
+		t.Run(name, func(t *testing.T) {
 			if tc.skip != "" {
-				tt.Skip(tc.skip)
+				t.Skip(tc.skip)
 			}
 
-			tt.Parallel()
-
-			t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/3183")
+			t.Parallel()
 
 			provider := shareddata.ArrayDocuments // one provider is enough to check for errors
 			ctx, collection := setup.Setup(t, provider)
@@ -570,3 +562,42 @@ func TestDropIndexesCommandInvalidCollection(tt *testing.T) {
 		})
 	}
 }
+
+func TestReIndexCommand(t *testing.T) {
+	t.Parallel()
+
+	setup.SkipForMongoDB(t, "reIndex is only allowed on a standalone mongod instance")
+
+	ctx, collection := setup.Setup(t, shareddata.Composites)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{"v", 1}}})
+	require.NoError(t, err)
+
+	var res bson.D
+	err = collection.Database().RunCommand(ctx, bson.D{{"reIndex", collection.Name()}}).Decode(&res)
+	require.NoError(t, err)
+
+	expected := bson.D{
+		{"nIndexesWas", int32(2)},
+		{"nIndexes", int32(2)},
+		{"indexes", bson.A{
+			bson.D{{"v", int32(2)}, {"key", bson.D{{"_id", int32(1)}}}, {"name", "_id_"}},
+			bson.D{{"v", int32(2)}, {"key", bson.D{{"v", int32(1)}}}, {"name", "v_1"}},
+		}},
+		{"ok", float64(1)},
+	}
+	AssertEqualDocuments(t, expected, res)
+
+	t.Run("NonExistentCollection", func(t *testing.T) {
+		t.Parallel()
+
+		err := collection.Database().RunCommand(ctx, bson.D{{"reIndex", "non-existent"}}).Err()
+
+		expected := mongo.CommandError{
+			Code:    26,
+			Name:    "NamespaceNotFound",
+			Message: "collection does not exist",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+}
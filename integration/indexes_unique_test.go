@@ -0,0 +1,100 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestIndexesUniqueCompound(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"a", "x"}, {"b", int32(1)}},
+		bson.D{{"_id", int32(2)}, {"a", "x"}, {"b", int32(2)}},
+		bson.D{{"_id", int32(3)}, {"a", "y"}},
+	})
+	require.NoError(t, err)
+
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{"a", 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	assert.True(t, mongo.IsDuplicateKeyError(err), "%v", err)
+
+	name, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{"a", 1}, {"b", -1}},
+		Options: options.Index().SetUnique(true),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "a_1_b_-1", name)
+
+	cursor, err := collection.Indexes().List(ctx)
+	require.NoError(t, err)
+
+	expected := []bson.D{
+		{{"v", int32(2)}, {"key", bson.D{{"_id", int32(1)}}}, {"name", "_id_"}},
+		{{"v", int32(2)}, {"key", bson.D{{"a", int32(1)}, {"b", int32(-1)}}}, {"name", "a_1_b_-1"}, {"unique", true}},
+	}
+	AssertEqualDocumentsSlice(t, expected, FetchAll(t, ctx, cursor))
+
+	for name, tc := range map[string]struct {
+		doc       bson.D
+		duplicate bool
+	}{
+		"Duplicate": {
+			doc:       bson.D{{"_id", int32(4)}, {"a", "x"}, {"b", int32(1)}},
+			duplicate: true,
+		},
+		"Unique": {
+			doc: bson.D{{"_id", int32(6)}, {"a", "y"}, {"b", int32(1)}},
+		},
+	} {
+		name, tc := name, tc
+		t.Run("Insert"+name, func(t *testing.T) {
+			_, err := collection.InsertOne(ctx, tc.doc)
+
+			if tc.duplicate {
+				assert.True(t, mongo.IsDuplicateKeyError(err), "%v", err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("UpdateDuplicate", func(t *testing.T) {
+		_, err := collection.UpdateOne(ctx, bson.D{{"_id", int32(2)}}, bson.D{{"$set", bson.D{{"b", int32(1)}}}})
+		assert.True(t, mongo.IsDuplicateKeyError(err), "%v", err)
+	})
+
+	t.Run("DropIndex", func(t *testing.T) {
+		_, err := collection.Indexes().DropOne(ctx, "a_1_b_-1")
+		require.NoError(t, err)
+
+		_, err = collection.InsertOne(ctx, bson.D{{"_id", int32(7)}, {"a", "x"}, {"b", int32(1)}})
+		require.NoError(t, err)
+	})
+}
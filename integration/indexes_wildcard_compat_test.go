@@ -0,0 +1,153 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+// TestIndexesWildcardCompatFind checks that filters on nested paths return the same documents
+// when wildcard indexes are used.
+func TestIndexesWildcardCompatFind(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers: []shareddata.Provider{shareddata.ArrayMixedTypes, shareddata.ArrayDocuments},
+	})
+
+	ctx, targetCollections, compatCollections := s.Ctx, s.TargetCollections, s.CompatCollections
+
+	for i := range targetCollections {
+		for _, keys := range []bson.D{{{"$**", 1}}, {{"v.foo.$**", 1}}} {
+			_, targetErr := targetCollections[i].Indexes().CreateOne(ctx, mongo.IndexModel{Keys: keys})
+			_, compatErr := compatCollections[i].Indexes().CreateOne(ctx, mongo.IndexModel{Keys: keys})
+			require.NoError(t, compatErr)
+			require.NoError(t, targetErr)
+		}
+	}
+
+	for name, filter := range map[string]bson.D{
+		"Int32":           {{"v", int32(42)}},
+		"String":          {{"v", "foo"}},
+		"DotNotation":     {{"v.foo", int32(42)}},
+		"DotNotationEq":   {{"v.foo", bson.D{{"$eq", int32(42)}}}},
+		"DotNotationNe":   {{"v.foo", bson.D{{"$ne", int32(42)}}}},
+		"Nested":          {{"v.foo.bar", "hello"}},
+		"NestedDouble":    {{"v.foo.bar", 42.0}},
+		"NestedMissing":   {{"v.foo.baz", "hello"}},
+		"ArrayIndex":      {{"v.0.foo.0.bar", "hello"}},
+		"NestedDocument":  {{"v.foo", bson.D{{"bar", "hello"}}}},
+		"NestedNull":      {{"v.foo.bar", nil}},
+		"NestedTwoFields": {{"v.foo.bar", "hello"}, {"v.foo", bson.D{{"$exists", true}}}},
+	} {
+		name, filter := name, filter
+
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			t.Parallel()
+
+			opts := options.Find().SetSort(bson.D{{"_id", 1}})
+
+			for i := range targetCollections {
+				targetCursor, targetErr := targetCollections[i].Find(ctx, filter, opts)
+				compatCursor, compatErr := compatCollections[i].Find(ctx, filter, opts)
+				require.NoError(t, compatErr)
+				require.NoError(t, targetErr)
+
+				targetRes := FetchAll(t, ctx, targetCursor)
+				compatRes := FetchAll(t, ctx, compatCursor)
+
+				t.Logf("Compat (expected) IDs: %v", CollectIDs(t, compatRes))
+				t.Logf("Target (actual)   IDs: %v", CollectIDs(t, targetRes))
+				AssertEqualDocumentsSlice(t, compatRes, targetRes)
+			}
+		})
+	}
+}
+
+// TestIndexesWildcardCompatErrors checks that invalid wildcard indexes are rejected.
+func TestIndexesWildcardCompatErrors(t *testing.T) {
+	t.Parallel()
+
+	for name, model := range map[string]mongo.IndexModel{
+		"Descending": {Keys: bson.D{{"$**", -1}}},
+		"Compound":   {Keys: bson.D{{"$**", 1}, {"v", 1}}},
+		"NotLast":    {Keys: bson.D{{"v.$**.foo", 1}}},
+		"Unique":     {Keys: bson.D{{"v.$**", 1}}, Options: options.Index().SetUnique(true)},
+	} {
+		name, model := name, model
+
+		t.Run(name, func(tt *testing.T) {
+			tt.Helper()
+			tt.Parallel()
+
+			t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/3175")
+
+			s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+				Providers: []shareddata.Provider{shareddata.Int32s},
+			})
+
+			ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+			_, targetErr := targetCollection.Indexes().CreateOne(ctx, model)
+			_, compatErr := compatCollection.Indexes().CreateOne(ctx, model)
+
+			require.Error(t, compatErr)
+			AssertMatchesCommandError(t, compatErr, targetErr)
+		})
+	}
+}
+
+// TestIndexesWildcardPushdown checks that filters on nested paths covered by wildcard indexes are pushed down.
+func TestIndexesWildcardPushdown(tt *testing.T) {
+	setup.SkipForMongoDB(tt, "MongoDB does not report pushdown")
+
+	tt.Parallel()
+
+	t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/3175")
+
+	ctx, collection := setup.Setup(tt, shareddata.ArrayDocuments)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{"v.$**", 1}}})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		filter   bson.D
+		pushdown bool
+	}{
+		"Covered":    {filter: bson.D{{"v.foo.bar", "hello"}}, pushdown: true},
+		"Prefix":     {filter: bson.D{{"v", "hello"}}, pushdown: true},
+		"NotCovered": {filter: bson.D{{"foo.bar", "hello"}}, pushdown: false},
+		"ArrayIndex": {filter: bson.D{{"v.0.foo", "hello"}}, pushdown: false},
+	} {
+		if setup.IsPushdownDisabled() {
+			tc.pushdown = false
+		}
+
+		var res bson.D
+		explain := bson.D{{"explain", bson.D{{"find", collection.Name()}, {"filter", tc.filter}}}}
+		require.NoError(t, collection.Database().RunCommand(ctx, explain).Decode(&res), name)
+		assert.Equal(t, tc.pushdown, res.Map()["pushdown"], name)
+	}
+}
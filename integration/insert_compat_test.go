@@ -209,7 +209,28 @@ func TestInsertCompat(t *testing.T) {
 			},
 			ordered: false,
 		},
+		"UnorderedManyErrors": {
+			insert:  insertManyWithDuplicates(1000, 150),
+			ordered: false,
+		},
 	}
 
 	testInsertCompat(t, testCases)
 }
+
+// insertManyWithDuplicates returns n documents where every step-th document
+// has the same _id as the previous one.
+func insertManyWithDuplicates(n, step int) []any {
+	res := make([]any, n)
+
+	for i := range res {
+		id := int32(i)
+		if i > 0 && i%step == 0 {
+			id--
+		}
+
+		res[i] = bson.D{{"_id", id}, {"v", i}}
+	}
+
+	return res
+}
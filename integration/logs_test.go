@@ -0,0 +1,49 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestLogsIgnoredField(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, nil)
+	logs := s.Logs(t)
+
+	db := s.Collection.Database().Client().Database("admin")
+
+	var res bson.D
+	err := db.RunCommand(s.Ctx, bson.D{
+		{"getParameter", bson.D{{"allParameters", false}}},
+		{"authenticationMechanisms", 1},
+		{"comment", "log test"},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	// other commands (like ones sent by the driver or test setup) may log ignored fields too
+	filtered := logs.FilterField(zap.String("command", "getParameter")).FilterField(zap.String("field", "comment"))
+	setup.AssertLogged(t, filtered, zapcore.DebugLevel, "ignoring field")
+
+	setup.AssertNotLogged(t, logs, zapcore.ErrorLevel, "")
+}
@@ -0,0 +1,134 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// opQuery sends OP_QUERY message over the raw wire connection and returns OP_REPLY response.
+func opQuery(t *testing.T, c *setup.WireConn, query *wire.OpQuery) *wire.OpReply {
+	t.Helper()
+
+	header, body := c.RoundTrip(wire.OpCodeQuery, query)
+	require.Equal(t, wire.OpCodeReply, header.OpCode)
+
+	reply, ok := body.(*wire.OpReply)
+	require.True(t, ok)
+
+	return reply
+}
+
+func TestOpQueryReplyFlags(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, nil)
+	db := s.Collection.Database().Name()
+	collection := s.Collection.Name()
+
+	c := s.DialWire(t)
+
+	isMaster := &wire.OpQuery{
+		FullCollectionName: db + ".$cmd",
+		NumberToReturn:     -1,
+		Query:              must.NotFail(types.NewDocument("isMaster", int32(1))),
+	}
+
+	t.Run("IsMaster", func(t *testing.T) {
+		reply := opQuery(t, c, isMaster)
+
+		assert.Equal(t, wire.OpReplyFlags(wire.OpReplyAwaitCapable), reply.ResponseFlags)
+		assert.Equal(t, int64(0), reply.CursorID)
+		require.Equal(t, int32(1), reply.NumberReturned)
+		assert.Equal(t, float64(1), must.NotFail(reply.Documents[0].Get("ok")))
+	})
+
+	t.Run("CommandError", func(t *testing.T) {
+		setup.SkipForMongoDB(t, "MongoDB does not support most commands over OP_QUERY")
+
+		reply := opQuery(t, c, &wire.OpQuery{
+			FullCollectionName: db + ".$cmd",
+			NumberToReturn:     -1,
+			Query:              must.NotFail(types.NewDocument("ping", int32(1))),
+		})
+
+		// command errors are returned as regular replies
+		assert.Equal(t, wire.OpReplyFlags(wire.OpReplyAwaitCapable), reply.ResponseFlags)
+		require.Equal(t, int32(1), reply.NumberReturned)
+
+		doc := reply.Documents[0]
+		assert.Equal(t, float64(0), must.NotFail(doc.Get("ok")))
+		assert.Equal(t, int32(238), must.NotFail(doc.Get("code")))
+		assert.Equal(t, "NotImplemented", must.NotFail(doc.Get("codeName")))
+	})
+
+	t.Run("QueryFailure", func(t *testing.T) {
+		setup.SkipForMongoDB(t, "MongoDB returns different error for legacy queries")
+
+		reply := opQuery(t, c, &wire.OpQuery{
+			FullCollectionName: db + "." + collection,
+			Query:              must.NotFail(types.NewDocument()),
+		})
+
+		assert.Equal(t, wire.OpReplyFlags(wire.OpReplyAwaitCapable|wire.OpReplyQueryFailure), reply.ResponseFlags)
+		require.Equal(t, int32(1), reply.NumberReturned)
+
+		doc := reply.Documents[0]
+		assert.Equal(t, []string{"$err", "code"}, doc.Keys())
+		assert.Equal(t, int32(238), must.NotFail(doc.Get("code")))
+	})
+
+	t.Run("AwaitDataWithoutTailable", func(t *testing.T) {
+		setup.SkipForMongoDB(t, "MongoDB returns different error for legacy queries")
+
+		reply := opQuery(t, c, &wire.OpQuery{
+			Flags:              wire.OpQueryFlags(wire.OpQueryAwaitData),
+			FullCollectionName: db + "." + collection,
+			Query:              must.NotFail(types.NewDocument()),
+		})
+
+		assert.Equal(t, wire.OpReplyFlags(wire.OpReplyAwaitCapable|wire.OpReplyQueryFailure), reply.ResponseFlags)
+		require.Equal(t, int32(1), reply.NumberReturned)
+
+		expected := must.NotFail(types.NewDocument(
+			"$err", "Cannot set 'awaitData' without also setting 'tailable'",
+			"code", int32(9),
+		))
+		assert.Equal(t, expected, reply.Documents[0])
+	})
+
+	t.Run("TailableIgnoredForCommands", func(t *testing.T) {
+		query := *isMaster
+		query.Flags = wire.OpQueryFlags(wire.OpQueryTailableCursor | wire.OpQueryAwaitData)
+
+		reply := opQuery(t, c, &query)
+
+		assert.Equal(t, wire.OpReplyFlags(wire.OpReplyAwaitCapable), reply.ResponseFlags)
+		require.Equal(t, int32(1), reply.NumberReturned)
+		assert.Equal(t, float64(1), must.NotFail(reply.Documents[0].Get("ok")))
+	})
+
+	// the connection is still usable after errors
+	reply := opQuery(t, c, isMaster)
+	assert.Equal(t, int32(1), reply.NumberReturned)
+}
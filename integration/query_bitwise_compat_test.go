@@ -0,0 +1,62 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+func TestQueryBitwiseCompatBinary(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{shareddata.Binaries, shareddata.Int32s, shareddata.Int64s}
+
+	testCases := map[string]queryCompatTestCase{}
+
+	for _, op := range []string{"$bitsAllClear", "$bitsAllSet", "$bitsAnyClear", "$bitsAnySet"} {
+		for name, mask := range map[string]any{
+			"Positions":          bson.A{1, 3, 5},
+			"PositionsBeyond64":  bson.A{1, 100},
+			"PositionsEmpty":     bson.A{},
+			"Int32":              int32(42),
+			"Int64":              int64(0x0d002a),
+			"Binary":             primitive.Binary{Data: []byte{42}},
+			"BinaryThreeBytes":   primitive.Binary{Data: []byte{42, 0, 13}},
+			"BinaryNineBytes":    primitive.Binary{Data: []byte{0, 0, 0, 0, 0, 0, 0, 0, 1}},
+			"BinaryUserSubtype":  primitive.Binary{Subtype: 0x80, Data: []byte{2}},
+			"BinaryEmpty":        primitive.Binary{Data: []byte{}},
+			"PositionsSignBit":   bson.A{63},
+			"PositionsBeyondLen": bson.A{24},
+		} {
+			tc := queryCompatTestCase{
+				filter: bson.D{{"v", bson.D{{op, mask}}}},
+			}
+
+			// "any" operators never match empty masks
+			if (op == "$bitsAnyClear" || op == "$bitsAnySet") && (name == "PositionsEmpty" || name == "BinaryEmpty") {
+				tc.resultType = emptyResult
+			}
+
+			testCases[op+name] = tc
+		}
+	}
+
+	testQueryCompatWithProviders(t, providers, testCases)
+}
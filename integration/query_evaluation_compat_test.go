@@ -300,6 +300,31 @@ func TestQueryEvaluationCompatMod(t *testing.T) {
 			filter:     bson.D{{"v", bson.D{{"$mod", bson.A{math.Inf(+1), 0}}}}},
 			resultType: emptyResult,
 		},
+		"NotArray": {
+			filter:     bson.D{{"v", bson.D{{"$mod", int32(2)}}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testQueryCompat(t, testCases)
+}
+
+func TestQueryEvaluationCompatComment(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]queryCompatTestCase{
+		"String": {
+			filter: bson.D{{"$comment", "foo"}},
+		},
+		"Document": {
+			filter: bson.D{{"$comment", bson.D{{"foo", "bar"}}}},
+		},
+		"WithFilter": {
+			filter: bson.D{{"v", int32(42)}, {"$comment", "foo"}},
+		},
+		"InAnd": {
+			filter: bson.D{{"$and", bson.A{bson.D{{"$comment", "foo"}}, bson.D{{"v", int32(42)}}}}},
+		},
 	}
 
 	testQueryCompat(t, testCases)
@@ -388,7 +413,45 @@ func TestQueryEvaluationCompatExpr(t *testing.T) {
 		},
 		"Gt": {
 			filter: bson.D{{"$expr", bson.D{{"$gt", bson.A{"$v", 2}}}}},
-			skip:   "https://github.com/FerretDB/FerretDB/issues/1456",
+		},
+		"Gte": {
+			filter: bson.D{{"$expr", bson.D{{"$gte", bson.A{"$v", int64(42)}}}}},
+		},
+		"Lt": {
+			filter: bson.D{{"$expr", bson.D{{"$lt", bson.A{"$v", "foo"}}}}},
+		},
+		"Lte": {
+			filter: bson.D{{"$expr", bson.D{{"$lte", bson.A{"$v", nil}}}}},
+		},
+		"Eq": {
+			filter: bson.D{{"$expr", bson.D{{"$eq", bson.A{"$v", "$v"}}}}},
+		},
+		"EqMissing": {
+			filter: bson.D{{"$expr", bson.D{{"$eq", bson.A{"$non-existent", nil}}}}},
+		},
+		"Ne": {
+			filter: bson.D{{"$expr", bson.D{{"$ne", bson.A{"$v", int32(42)}}}}},
+		},
+		"Cmp": {
+			filter: bson.D{{"$expr", bson.D{{"$cmp", bson.A{"$v", "$_id"}}}}},
+		},
+		"FieldComparison": {
+			filter: bson.D{{"$expr", bson.D{{"$gt", bson.A{"$v", "$_id"}}}}},
+		},
+		"And": {
+			filter: bson.D{{"$expr", bson.D{{"$and", bson.A{
+				bson.D{{"$gt", bson.A{"$v", 0}}},
+				bson.D{{"$lt", bson.A{"$v", 100}}},
+			}}}}},
+		},
+		"Or": {
+			filter: bson.D{{"$expr", bson.D{{"$or", bson.A{
+				bson.D{{"$eq", bson.A{"$v", "foo"}}},
+				bson.D{{"$eq", bson.A{"$v", int32(42)}}},
+			}}}}},
+		},
+		"Not": {
+			filter: bson.D{{"$expr", bson.D{{"$not", bson.A{bson.D{{"$eq", bson.A{"$v", nil}}}}}}}},
 		},
 	}
 
@@ -189,7 +189,6 @@ func TestQueryEvaluationExprErrors(t *testing.T) {
 				Name:    "Location16020",
 				Message: "Expression $gt takes exactly 2 arguments. 1 were passed in.",
 			},
-			skip: "https://github.com/FerretDB/FerretDB/issues/1456",
 		},
 		"GtOneParameter": {
 			filter: bson.D{{"$expr", bson.D{{"$gt", bson.A{1}}}}},
@@ -198,7 +197,22 @@ func TestQueryEvaluationExprErrors(t *testing.T) {
 				Name:    "Location16020",
 				Message: "Expression $gt takes exactly 2 arguments. 1 were passed in.",
 			},
-			skip: "https://github.com/FerretDB/FerretDB/issues/1456",
+		},
+		"NotTwoParameters": {
+			filter: bson.D{{"$expr", bson.D{{"$not", bson.A{true, false}}}}},
+			err: &mongo.CommandError{
+				Code:    16020,
+				Name:    "Location16020",
+				Message: "Expression $not takes exactly 1 arguments. 2 were passed in.",
+			},
+		},
+		"GtInvalidNestedExpression": {
+			filter: bson.D{{"$expr", bson.D{{"$gt", bson.A{bson.D{{"$non-existent", "foo"}}, 1}}}}},
+			err: &mongo.CommandError{
+				Code:    168,
+				Name:    "InvalidPipelineOperator",
+				Message: "Unrecognized expression '$non-existent'",
+			},
 		},
 		"GtThreeParameters": {
 			filter: bson.D{{"$expr", bson.D{{"$gt", bson.A{1, 2, 3}}}}},
@@ -207,7 +221,6 @@ func TestQueryEvaluationExprErrors(t *testing.T) {
 				Name:    "Location16020",
 				Message: "Expression $gt takes exactly 2 arguments. 3 were passed in.",
 			},
-			skip: "https://github.com/FerretDB/FerretDB/issues/1456",
 		},
 	} {
 		name, tc := name, tc
@@ -225,3 +238,80 @@ func TestQueryEvaluationExprErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryEvaluationExprFieldComparison(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "over"}, {"spent", int32(120)}, {"budget", int32(100)}},
+		bson.D{{"_id", "under"}, {"spent", int32(80)}, {"budget", int32(100)}},
+		bson.D{{"_id", "exact"}, {"spent", 100.0}, {"budget", int64(100)}},
+		bson.D{{"_id", "no-budget"}, {"spent", int32(10)}},
+	})
+	require.NoError(t, err)
+
+	overBudget := bson.D{{"$expr", bson.D{{"$gt", bson.A{"$spent", "$budget"}}}}}
+
+	cursor, err := collection.Find(ctx, overBudget, options.Find().SetSort(bson.D{{"_id", 1}}))
+	require.NoError(t, err)
+
+	var res []bson.D
+	require.NoError(t, cursor.All(ctx, &res))
+	assert.Equal(t, []any{"no-budget", "over"}, CollectIDs(t, res))
+
+	updateRes, err := collection.UpdateMany(
+		ctx,
+		bson.D{{"$expr", bson.D{{"$eq", bson.A{"$spent", "$budget"}}}}},
+		bson.D{{"$set", bson.D{{"closed", true}}}},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), updateRes.ModifiedCount)
+
+	deleteRes, err := collection.DeleteMany(ctx, overBudget)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleteRes.DeletedCount)
+
+	cursor, err = collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+	require.NoError(t, err)
+
+	res = nil
+	require.NoError(t, cursor.All(ctx, &res))
+
+	expected := []bson.D{
+		{{"_id", "exact"}, {"spent", 100.0}, {"budget", int64(100)}, {"closed", true}},
+		{{"_id", "under"}, {"spent", int32(80)}, {"budget", int32(100)}},
+	}
+	AssertEqualDocumentsSlice(t, expected, res)
+}
+
+func TestQueryEvaluationWhere(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.Scalars)
+
+	t.Run("BadType", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := collection.Find(ctx, bson.D{{"$where", int32(42)}})
+		expected := mongo.CommandError{
+			Code:    2,
+			Name:    "BadValue",
+			Message: "$where got bad type",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+
+	t.Run("JavaScript", func(t *testing.T) {
+		setup.SkipForMongoDB(t, "MongoDB executes server-side JavaScript")
+
+		t.Parallel()
+
+		_, err := collection.Find(ctx, bson.D{{"$where", "this.v == 42"}})
+		expected := mongo.CommandError{
+			Code:    2,
+			Name:    "BadValue",
+			Message: "no globalScriptEngine in $where parsing",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+}
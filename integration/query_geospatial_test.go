@@ -0,0 +1,157 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+// insertGeoDocuments inserts documents with GeoJSON points and legacy coordinate pairs.
+func insertGeoDocuments(t *testing.T, ctx context.Context, collection *mongo.Collection) {
+	t.Helper()
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "a"}, {"loc", bson.D{{"type", "Point"}, {"coordinates", bson.A{0.0, 0.0}}}}},
+		bson.D{{"_id", "b"}, {"loc", bson.A{1.0, 1.0}}},
+		bson.D{{"_id", "c"}, {"loc", bson.D{{"type", "Point"}, {"coordinates", bson.A{2.0, 0.0}}}}},
+		bson.D{{"_id", "d"}, {"loc", bson.D{{"type", "Point"}, {"coordinates", bson.A{10.0, 10.0}}}}},
+		bson.D{{"_id", "e"}, {"v", "no location"}},
+	})
+	require.NoError(t, err)
+}
+
+func TestQueryGeospatial(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	insertGeoDocuments(t, ctx, collection)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{"loc", "2dsphere"}}})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		filter      bson.D
+		sort        bool  // sort by _id, for operators that do not return documents by distance
+		expectedIDs []any // optional
+		err         *mongo.CommandError
+	}{
+		"GeoWithinBox": {
+			filter:      bson.D{{"loc", bson.D{{"$geoWithin", bson.D{{"$box", bson.A{bson.A{-0.5, -0.5}, bson.A{1.5, 1.5}}}}}}}},
+			sort:        true,
+			expectedIDs: []any{"a", "b"},
+		},
+		"GeoWithinPolygon": {
+			filter: bson.D{{"loc", bson.D{{"$geoWithin", bson.D{{"$geometry", bson.D{
+				{"type", "Polygon"},
+				{"coordinates", bson.A{bson.A{
+					bson.A{-1.0, -1.0}, bson.A{3.0, -1.0}, bson.A{3.0, 3.0}, bson.A{-1.0, 3.0}, bson.A{-1.0, -1.0},
+				}}},
+			}}}}}}},
+			sort:        true,
+			expectedIDs: []any{"a", "b", "c"},
+		},
+		"GeoWithinCenter": {
+			filter:      bson.D{{"loc", bson.D{{"$geoWithin", bson.D{{"$center", bson.A{bson.A{0.0, 0.0}, 1.5}}}}}}},
+			sort:        true,
+			expectedIDs: []any{"a", "b"},
+		},
+		"GeoWithinCenterSphere": {
+			filter: bson.D{{"loc", bson.D{{"$geoWithin", bson.D{
+				{"$centerSphere", bson.A{bson.A{0.0, 0.0}, 2.5 * math.Pi / 180}},
+			}}}}},
+			sort:        true,
+			expectedIDs: []any{"a", "b", "c"},
+		},
+		"GeoWithinUnknownSpecifier": {
+			filter: bson.D{{"loc", bson.D{{"$geoWithin", bson.D{{"$foo", bson.A{0.0, 0.0}}}}}}},
+			err: &mongo.CommandError{
+				Code: 2,
+				Name: "BadValue",
+			},
+		},
+		"Near": {
+			filter: bson.D{{"loc", bson.D{{"$near", bson.D{
+				{"$geometry", bson.D{{"type", "Point"}, {"coordinates", bson.A{2.1, 0.0}}}},
+				{"$maxDistance", 200_000},
+			}}}}},
+			expectedIDs: []any{"c", "b"},
+		},
+		"NearMinDistance": {
+			filter: bson.D{{"loc", bson.D{{"$near", bson.D{
+				{"$geometry", bson.D{{"type", "Point"}, {"coordinates", bson.A{0.0, 0.0}}}},
+				{"$minDistance", 1},
+			}}}}},
+			expectedIDs: []any{"b", "c", "d"},
+		},
+		"NearSphereLegacy": {
+			filter: bson.D{{"loc", bson.D{
+				{"$nearSphere", bson.A{0.0, 0.0}},
+				{"$maxDistance", 0.03},
+			}}},
+			expectedIDs: []any{"a", "b"},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := options.Find()
+			if tc.sort {
+				opts.SetSort(bson.D{{"_id", 1}})
+			}
+
+			cursor, err := collection.Find(ctx, tc.filter, opts)
+			if tc.err != nil {
+				assert.Nil(t, cursor)
+				AssertMatchesCommandError(t, *tc.err, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedIDs, CollectIDs(t, FetchAll(t, ctx, cursor)))
+		})
+	}
+}
+
+func TestQueryGeospatialIndexErrors(tt *testing.T) {
+	tt.Parallel()
+
+	t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/3175")
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{"loc", "2dsphere"}}})
+	require.NoError(t, err)
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "invalid"}, {"loc", "not a geometry"}})
+
+	expected := mongo.WriteException{WriteErrors: []mongo.WriteError{{
+		Code:    16755,
+		Message: "Can't extract geo keys",
+	}}}
+	AssertMatchesWriteError(t, expected, err)
+}
@@ -208,6 +208,51 @@ func TestQueryProjectionCompat(t *testing.T) {
 				{"v.array", true},
 			},
 		},
+		"IncludeFieldExcludeIDLast": {
+			filter:      bson.D{},
+			projection:  bson.D{{"v", true}, {"_id", false}},
+			skipIDCheck: true,
+		},
+		"SliceLimit": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", 2}}}},
+		},
+		"SliceNegativeLimit": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", -1}}}},
+		},
+		"SliceSkipLimit": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", bson.A{1, 2}}}}},
+		},
+		"SliceNegativeSkipLimit": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", bson.A{-2, 1}}}}},
+		},
+		"SliceInclusion": {
+			filter:     bson.D{},
+			projection: bson.D{{"foo", true}, {"v", bson.D{{"$slice", 1}}}},
+		},
+		"SliceExclusion": {
+			filter:     bson.D{},
+			projection: bson.D{{"foo", false}, {"v", bson.D{{"$slice", 1}}}},
+		},
+		"SliceDotNotation": {
+			filter:     bson.D{},
+			projection: bson.D{{"v.foo", bson.D{{"$slice", 1}}}},
+		},
+		"ElemMatch": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"$gt", 0}}}}}},
+		},
+		"ElemMatchField": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"foo", bson.D{{"$exists", true}}}}}}}},
+		},
+		"ElemMatchInclusion": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"$lt", 42}}}}}, {"foo", true}},
+		},
 		"DotNotationManyExclude": {
 			filter: bson.D{},
 			projection: bson.D{
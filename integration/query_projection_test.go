@@ -232,6 +232,69 @@ func TestQueryProjectionErrors(t *testing.T) {
 				Message: "positional projection cannot be used with exclusion",
 			},
 		},
+		"SliceArrayLen": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", bson.A{1}}}}},
+			err: &mongo.CommandError{
+				Code:    31272,
+				Name:    "Location31272",
+				Message: "$slice array argument should be of form [skip, limit]",
+			},
+		},
+		"SliceWrongType": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", "1"}}}},
+			err: &mongo.CommandError{
+				Code:    31273,
+				Name:    "Location31273",
+				Message: "$slice only supports numbers and [skip, limit] arrays",
+			},
+		},
+		"SliceLimitZero": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$slice", bson.A{1, 0}}}}},
+			err: &mongo.CommandError{
+				Code:    31259,
+				Name:    "Location31259",
+				Message: "$slice limit must be positive",
+			},
+		},
+		"ElemMatchNotDocument": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$elemMatch", 42}}}},
+			err: &mongo.CommandError{
+				Code:    31274,
+				Name:    "Location31274",
+				Message: "elemMatch: Invalid argument, object required.",
+			},
+		},
+		"ElemMatchNested": {
+			filter:     bson.D{},
+			projection: bson.D{{"v.foo", bson.D{{"$elemMatch", bson.D{{"$gt", 42}}}}}},
+			err: &mongo.CommandError{
+				Code:    31275,
+				Name:    "Location31275",
+				Message: "Cannot use $elemMatch projection on a nested field.",
+			},
+		},
+		"ElemMatchPositional": {
+			filter:     bson.D{{"v", 42}},
+			projection: bson.D{{"v.$", true}, {"foo", bson.D{{"$elemMatch", bson.D{{"$gt", 42}}}}}},
+			err: &mongo.CommandError{
+				Code:    31255,
+				Name:    "Location31255",
+				Message: "Cannot specify positional operator and $elemMatch.",
+			},
+		},
+		"ElemMatchExclusion": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", bson.D{{"$elemMatch", bson.D{{"$gt", 42}}}}}, {"foo", false}},
+			err: &mongo.CommandError{
+				Code:    31254,
+				Name:    "Location31254",
+				Message: "Cannot do exclusion on field foo in inclusion projection",
+			},
+		},
 	} {
 		name, tc := name, tc
 		t.Run(name, func(t *testing.T) {
@@ -252,3 +315,88 @@ func TestQueryProjectionErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryProjectionPushdown(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"a", int32(3)}, {"b", bson.D{{"c", "x"}, {"d", "y"}}}, {"e", 0.1 + 0.2}, {"f", "foo"}},
+		bson.D{{"_id", int32(2)}, {"a", int32(1)}, {"b", bson.D{{"c", "z"}}}, {"f", "bar"}},
+		bson.D{{"_id", int32(3)}, {"a", int32(2)}, {"e", nil}, {"f", "foo"}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		filter     bson.D
+		sort       bson.D
+		projection bson.D
+		expected   []bson.D
+	}{
+		"Inclusion": {
+			projection: bson.D{{"e", 1}, {"a", true}},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"a", int32(3)}, {"e", 0.1 + 0.2}},
+				{{"_id", int32(2)}, {"a", int32(1)}},
+				{{"_id", int32(3)}, {"a", int32(2)}, {"e", nil}},
+			},
+		},
+		"FilterSort": {
+			filter:     bson.D{{"f", "foo"}},
+			sort:       bson.D{{"a", 1}},
+			projection: bson.D{{"e", 1}, {"_id", 0}},
+			expected: []bson.D{
+				{{"e", nil}},
+				{{"e", 0.1 + 0.2}},
+			},
+		},
+		"DotNotation": {
+			filter:     bson.D{{"b.c", bson.D{{"$exists", true}}}},
+			projection: bson.D{{"b.d", 1}},
+			expected: []bson.D{
+				{{"_id", int32(1)}, {"b", bson.D{{"d", "y"}}}},
+				{{"_id", int32(2)}, {"b", bson.D{}}},
+			},
+		},
+		"Exclusion": {
+			filter:     bson.D{{"_id", int32(2)}},
+			projection: bson.D{{"b", 0}, {"a", 0}},
+			expected: []bson.D{
+				{{"_id", int32(2)}, {"f", "bar"}},
+			},
+		},
+		"TopLevelOperator": {
+			filter:     bson.D{{"$or", bson.A{bson.D{{"a", int32(1)}}, bson.D{{"e", nil}}}}},
+			projection: bson.D{{"f", 1}},
+			expected: []bson.D{
+				{{"_id", int32(2)}, {"f", "bar"}},
+				{{"_id", int32(3)}, {"f", "foo"}},
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filter := tc.filter
+			if filter == nil {
+				filter = bson.D{}
+			}
+
+			sort := tc.sort
+			if sort == nil {
+				sort = bson.D{{"_id", 1}}
+			}
+
+			opts := options.Find().SetProjection(tc.projection).SetSort(sort)
+
+			cursor, err := collection.Find(ctx, filter, opts)
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
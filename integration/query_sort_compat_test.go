@@ -0,0 +1,164 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+// sortCompatValue returns a random value for sort compatibility tests.
+// Arrays and documents are generated up to the given depth.
+func sortCompatValue(r *rand.Rand, depth int) any {
+	n := 11
+	if depth > 0 {
+		n = 9
+	}
+
+	switch r.Intn(n) {
+	case 0:
+		return int32(r.Intn(10) - 5)
+	case 1:
+		return int64(r.Intn(10) - 5)
+	case 2:
+		return []float64{-2.5, 0.5, 3, math.Inf(1), math.NaN()}[r.Intn(5)]
+	case 3:
+		return []string{"", "a", "B", "foo", "fooo"}[r.Intn(5)]
+	case 4:
+		return r.Intn(2) == 0
+	case 5:
+		return nil
+	case 6:
+		return time.Date(2023, 1, 1+r.Intn(10), 0, 0, 0, 0, time.UTC)
+	case 7:
+		return primitive.ObjectID{byte(r.Intn(3))}
+	case 8:
+		return primitive.Binary{Data: []byte{byte(r.Intn(3))}}
+	case 9:
+		arr := bson.A{}
+		for i := r.Intn(4); i > 0; i-- {
+			arr = append(arr, sortCompatValue(r, depth+1))
+		}
+
+		return arr
+	default:
+		doc := bson.D{}
+		for i := r.Intn(3); i > 0; i-- {
+			doc = append(doc, bson.E{Key: []string{"b", "c"}[r.Intn(2)], Value: sortCompatValue(r, depth+1)})
+		}
+
+		return doc
+	}
+}
+
+// sortCompatProvider provides the same random documents for sort compatibility tests.
+type sortCompatProvider struct{}
+
+// Name implements shareddata.Provider interface.
+func (sortCompatProvider) Name() string {
+	return "SortRandom"
+}
+
+// Docs implements shareddata.Provider interface.
+func (sortCompatProvider) Docs() []bson.D {
+	// use a fixed seed to return the same documents and make failures reproducible
+	r := rand.New(rand.NewSource(1))
+
+	docs := make([]bson.D, 200)
+
+	for i := range docs {
+		doc := bson.D{{"_id", int32(i)}}
+
+		// leave some fields missing
+		if r.Intn(5) > 0 {
+			doc = append(doc, bson.E{"v", sortCompatValue(r, 0)})
+		}
+
+		if r.Intn(5) > 0 {
+			a := bson.A{}
+			for j := r.Intn(4); j > 0; j-- {
+				a = append(a, bson.D{{"b", sortCompatValue(r, 1)}})
+			}
+
+			doc = append(doc, bson.E{"a", a})
+		}
+
+		docs[i] = doc
+	}
+
+	return docs
+}
+
+func TestQueryCompatSortRandom(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers: []shareddata.Provider{sortCompatProvider{}},
+	})
+	ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+	for name, sort := range map[string]bson.D{
+		"Asc":                {{"v", 1}, {"_id", 1}},
+		"Desc":               {{"v", -1}, {"_id", 1}},
+		"ArrayIndexAsc":      {{"v.0", 1}, {"_id", 1}},
+		"ArrayIndexDesc":     {{"v.0", -1}, {"_id", 1}},
+		"EmbeddedAsc":        {{"v.b", 1}, {"_id", 1}},
+		"EmbeddedDesc":       {{"v.b", -1}, {"_id", 1}},
+		"ArrayDocumentsAsc":  {{"a.b", 1}, {"_id", 1}},
+		"ArrayDocumentsDesc": {{"a.b", -1}, {"_id", 1}},
+		"MultipleKeys":       {{"a.b", 1}, {"v", -1}, {"_id", 1}},
+	} {
+		name, sort := name, sort
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			t.Run("Find", func(t *testing.T) {
+				t.Parallel()
+
+				opts := options.Find().SetSort(sort)
+
+				targetCursor, targetErr := targetCollection.Find(ctx, bson.D{}, opts)
+				compatCursor, compatErr := compatCollection.Find(ctx, bson.D{}, opts)
+				require.NoError(t, compatErr)
+				require.NoError(t, targetErr)
+
+				AssertEqualDocumentsSlice(t, FetchAll(t, ctx, compatCursor), FetchAll(t, ctx, targetCursor))
+			})
+
+			t.Run("Aggregate", func(t *testing.T) {
+				t.Parallel()
+
+				pipeline := bson.A{bson.D{{"$sort", sort}}}
+
+				targetCursor, targetErr := targetCollection.Aggregate(ctx, pipeline)
+				compatCursor, compatErr := compatCollection.Aggregate(ctx, pipeline)
+				require.NoError(t, compatErr)
+				require.NoError(t, targetErr)
+
+				AssertEqualDocumentsSlice(t, FetchAll(t, ctx, compatCursor), FetchAll(t, ctx, targetCursor))
+			})
+		})
+	}
+}
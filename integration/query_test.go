@@ -29,7 +29,6 @@ import (
 
 	"github.com/FerretDB/FerretDB/integration/setup"
 	"github.com/FerretDB/FerretDB/integration/shareddata"
-	"github.com/FerretDB/FerretDB/internal/util/testutil/testtb"
 )
 
 func TestQueryBadFindType(t *testing.T) {
@@ -265,6 +264,65 @@ func TestQuerySortErrors(t *testing.T) {
 	}
 }
 
+func TestQuerySortPushdown(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "int"}, {"v", int32(42)}},
+		bson.D{{"_id", "string"}, {"v", "foo"}},
+		bson.D{{"_id", "missing"}},
+		bson.D{{"_id", "double"}, {"v", 41.5}},
+		bson.D{{"_id", "true"}, {"v", true}},
+		bson.D{{"_id", "null"}, {"v", nil}},
+		bson.D{{"_id", "long"}, {"v", int64(43)}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		sort     bson.D
+		expected []any
+	}{
+		"Ascending": {
+			sort:     bson.D{{"v", 1}, {"_id", 1}},
+			expected: []any{"missing", "null", "double", "int", "long", "string", "true"},
+		},
+		"Descending": {
+			sort:     bson.D{{"v", -1}, {"_id", 1}},
+			expected: []any{"true", "string", "long", "int", "double", "missing", "null"},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			query := bson.D{{"find", collection.Name()}, {"sort", tc.sort}}
+
+			t.Run("Explain", func(t *testing.T) {
+				setup.SkipForMongoDB(t, "pushdown is FerretDB specific feature")
+
+				var res bson.D
+				err := collection.Database().RunCommand(ctx, bson.D{{"explain", query}}).Decode(&res)
+				require.NoError(t, err)
+
+				sortingPushdown, _ := ConvertDocument(t, res).Get("sortingPushdown")
+				assert.Equal(t, setup.IsSortPushdownEnabled(), sortingPushdown)
+			})
+
+			t.Run("Find", func(t *testing.T) {
+				cursor, err := collection.Database().RunCommandCursor(ctx, query)
+				require.NoError(t, err)
+
+				var res []bson.D
+				require.NoError(t, cursor.All(ctx, &res))
+
+				assert.Equal(t, tc.expected, CollectIDs(t, res))
+			})
+		})
+	}
+}
+
 func TestQueryMaxTimeMSErrors(t *testing.T) {
 	t.Parallel()
 	ctx, collection := setup.Setup(t)
@@ -820,6 +878,86 @@ func TestQueryCommandSingleBatch(t *testing.T) {
 	}
 }
 
+func TestQueryCommandTailableOptions(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	arr, _ := generateDocuments(0, 5)
+	_, err := collection.InsertMany(ctx, arr)
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct { //nolint:vet // used for testing only
+		options bson.D // required, find options
+
+		err        *mongo.CommandError // required, expected error from MongoDB
+		altMessage string              // optional, alternative error message for FerretDB, ignored if empty
+	}{
+		"AwaitDataWithoutTailable": {
+			options: bson.D{{"awaitData", true}},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "Cannot set 'awaitData' without also setting 'tailable'",
+			},
+		},
+		"AwaitDataWithTailableFalse": {
+			options: bson.D{{"tailable", false}, {"awaitData", true}},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "Cannot set 'awaitData' without also setting 'tailable'",
+			},
+		},
+		"TailableSort": {
+			options: bson.D{{"tailable", true}, {"sort", bson.D{{"v", 1}}}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "cannot use tailable option with a sort other than {$natural: 1}",
+			},
+		},
+		"TailableNaturalDescending": {
+			options: bson.D{{"tailable", true}, {"sort", bson.D{{"$natural", -1}}}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "cannot use tailable option with a sort other than {$natural: 1}",
+			},
+		},
+		"TailableSingleBatch": {
+			options: bson.D{{"tailable", true}, {"singleBatch", true}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "cannot use tailable option with the 'singleBatch' option",
+			},
+		},
+		"TailableNonCapped": {
+			options: bson.D{{"tailable", true}, {"awaitData", true}},
+			err: &mongo.CommandError{
+				Code: 2,
+				Name: "BadValue",
+				Message: "error processing query: ns=" + collection.Database().Name() + "." + collection.Name() +
+					"Tree: $and\nSort: {}\nProj: {}\n tailable cursor requested on non capped collection",
+			},
+			altMessage: "error processing query: ns=" + collection.Database().Name() + "." + collection.Name() +
+				" tailable cursor requested on non capped collection",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			command := append(bson.D{{"find", collection.Name()}}, tc.options...)
+
+			var res bson.D
+			err := collection.Database().RunCommand(ctx, command).Decode(&res)
+			assert.Nil(t, res)
+			AssertEqualAltCommandError(t, *tc.err, tc.altMessage, err)
+		})
+	}
+}
+
 func TestQueryCommandLimitPushDown(t *testing.T) {
 	t.Parallel()
 
@@ -833,43 +971,38 @@ func TestQueryCommandLimitPushDown(t *testing.T) {
 		sort    bson.D // optional, nil to leave sort unset
 		optSkip *int64 // optional, nil to leave optSkip unset
 
-		len            int                 // expected length of results
-		queryPushdown  bool                // optional, set true for expected pushdown for query
-		limitPushdown  bool                // optional, set true for expected pushdown for limit
-		err            *mongo.CommandError // optional, expected error from MongoDB
-		altMessage     string              // optional, alternative error message for FerretDB, ignored if empty
-		skip           string              // optional, skip test with a specified reason
-		failsForSQLite string              // optional, if set, the case is expected to fail for SQLite due to given issue
+		len           int                 // expected length of results
+		queryPushdown bool                // optional, set true for expected pushdown for query
+		limitPushdown bool                // optional, set true for expected pushdown for limit
+		skipPushdown  bool                // optional, set true for expected pushdown for skip
+		err           *mongo.CommandError // optional, expected error from MongoDB
+		altMessage    string              // optional, alternative error message for FerretDB, ignored if empty
+		skip          string              // optional, skip test with a specified reason
 	}{
 		"Simple": {
-			limit:          1,
-			len:            1,
-			limitPushdown:  true,
-			failsForSQLite: "https://github.com/FerretDB/FerretDB/issues/3181",
+			limit:         1,
+			len:           1,
+			limitPushdown: true,
 		},
 		"AlmostAll": {
-			limit:          int64(len(shareddata.Composites.Docs()) - 1),
-			len:            len(shareddata.Composites.Docs()) - 1,
-			limitPushdown:  true,
-			failsForSQLite: "https://github.com/FerretDB/FerretDB/issues/3181",
+			limit:         int64(len(shareddata.Composites.Docs()) - 1),
+			len:           len(shareddata.Composites.Docs()) - 1,
+			limitPushdown: true,
 		},
 		"All": {
-			limit:          int64(len(shareddata.Composites.Docs())),
-			len:            len(shareddata.Composites.Docs()),
-			limitPushdown:  true,
-			failsForSQLite: "https://github.com/FerretDB/FerretDB/issues/3181",
+			limit:         int64(len(shareddata.Composites.Docs())),
+			len:           len(shareddata.Composites.Docs()),
+			limitPushdown: true,
 		},
 		"More": {
-			limit:          int64(len(shareddata.Composites.Docs()) + 1),
-			len:            len(shareddata.Composites.Docs()),
-			limitPushdown:  true,
-			failsForSQLite: "https://github.com/FerretDB/FerretDB/issues/3181",
+			limit:         int64(len(shareddata.Composites.Docs()) + 1),
+			len:           len(shareddata.Composites.Docs()),
+			limitPushdown: true,
 		},
 		"Big": {
-			limit:          1000,
-			len:            len(shareddata.Composites.Docs()),
-			limitPushdown:  true,
-			failsForSQLite: "https://github.com/FerretDB/FerretDB/issues/3181",
+			limit:         1000,
+			len:           len(shareddata.Composites.Docs()),
+			limitPushdown: true,
 		},
 		"Zero": {
 			limit:         0,
@@ -948,7 +1081,28 @@ func TestQueryCommandLimitPushDown(t *testing.T) {
 			optSkip:       pointer.ToInt64(1),
 			limit:         2,
 			len:           2,
-			limitPushdown: false,
+			limitPushdown: true,
+			skipPushdown:  true,
+		},
+		"SkipWithoutLimit": {
+			optSkip:      pointer.ToInt64(int64(len(shareddata.Composites.Docs()) - 1)),
+			len:          1,
+			skipPushdown: true,
+		},
+		"SkipSort": {
+			sort:          bson.D{{"_id", 1}},
+			optSkip:       pointer.ToInt64(2),
+			limit:         2,
+			len:           2,
+			limitPushdown: true,
+			skipPushdown:  true,
+		},
+		"SkipFilter": {
+			filter:        bson.D{{"_id", "array"}},
+			optSkip:       pointer.ToInt64(1),
+			limit:         3,
+			len:           0,
+			queryPushdown: true,
 		},
 	} {
 		tc, name := tc, name
@@ -978,13 +1132,8 @@ func TestQueryCommandLimitPushDown(t *testing.T) {
 				rest...,
 			)
 
-			t.Run("Explain", func(tt *testing.T) {
-				setup.SkipForMongoDB(tt, "pushdown is FerretDB specific feature")
-
-				var t testtb.TB = tt
-				if tc.failsForSQLite != "" {
-					t = setup.FailsForSQLite(tt, tc.failsForSQLite)
-				}
+			t.Run("Explain", func(t *testing.T) {
+				setup.SkipForMongoDB(t, "pushdown is FerretDB specific feature")
 
 				var res bson.D
 				err := collection.Database().RunCommand(ctx, bson.D{{"explain", query}}).Decode(&res)
@@ -999,8 +1148,14 @@ func TestQueryCommandLimitPushDown(t *testing.T) {
 
 				var msg string
 
+				// SQLite handler reports if filter pushdown is enabled, not if the given filter is pushed down
+				if setup.IsSQLite(t) {
+					tc.queryPushdown = true
+				}
+
 				if !setup.IsSortPushdownEnabled() && tc.sort != nil {
 					tc.limitPushdown = false
+					tc.skipPushdown = false
 					msg = "Sort pushdown is disabled, but target resulted with limitPushdown"
 				}
 
@@ -1013,6 +1168,9 @@ func TestQueryCommandLimitPushDown(t *testing.T) {
 				limitPushdown, _ := doc.Get("limitPushdown")
 				assert.Equal(t, tc.limitPushdown, limitPushdown, msg)
 
+				skipPushdown, _ := doc.Get("skipPushdown")
+				assert.Equal(t, tc.skipPushdown, skipPushdown, msg)
+
 				queryPushdown, _ := ConvertDocument(t, res).Get("pushdown")
 				assert.Equal(t, tc.queryPushdown, queryPushdown, msg)
 			})
@@ -1037,3 +1195,509 @@ func TestQueryCommandLimitPushDown(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryCommandNatural(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	// insert documents one by one in an order different from _id order
+	for _, id := range []int32{3, 1, 4, 0, 2} {
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", id}})
+		require.NoError(t, err)
+	}
+
+	for name, tc := range map[string]struct { //nolint:vet // used for testing only
+		sort  bson.D // optional, nil to leave sort unset
+		hint  bson.D // optional, nil to leave hint unset
+		limit int64  // optional, 0 to leave limit unset
+
+		expected []any // required, expected _id values in order
+	}{
+		"SortAscending": {
+			sort:     bson.D{{"$natural", 1}},
+			expected: []any{int32(3), int32(1), int32(4), int32(0), int32(2)},
+		},
+		"SortDescending": {
+			sort:     bson.D{{"$natural", -1}},
+			expected: []any{int32(2), int32(0), int32(4), int32(1), int32(3)},
+		},
+		"SortDescendingLimit": {
+			sort:     bson.D{{"$natural", int64(-1)}},
+			limit:    2,
+			expected: []any{int32(2), int32(0)},
+		},
+		"HintAscending": {
+			hint:     bson.D{{"$natural", 1}},
+			expected: []any{int32(3), int32(1), int32(4), int32(0), int32(2)},
+		},
+		"HintDescending": {
+			hint:     bson.D{{"$natural", -1}},
+			expected: []any{int32(2), int32(0), int32(4), int32(1), int32(3)},
+		},
+		"HintWithSort": {
+			hint:     bson.D{{"$natural", -1}},
+			sort:     bson.D{{"_id", 1}},
+			expected: []any{int32(0), int32(1), int32(2), int32(3), int32(4)},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			command := bson.D{{"find", collection.Name()}}
+
+			if tc.sort != nil {
+				command = append(command, bson.E{"sort", tc.sort})
+			}
+
+			if tc.hint != nil {
+				command = append(command, bson.E{"hint", tc.hint})
+			}
+
+			if tc.limit != 0 {
+				command = append(command, bson.E{"limit", tc.limit})
+			}
+
+			cursor, err := collection.Database().RunCommandCursor(ctx, command)
+			require.NoError(t, err)
+
+			defer cursor.Close(ctx)
+
+			docs := FetchAll(t, ctx, cursor)
+			assert.Equal(t, tc.expected, CollectIDs(t, docs))
+		})
+	}
+}
+
+func TestQueryCommandMinMax(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	// insert documents one by one in an order different from _id order
+	for _, id := range []int32{3, 1, 4, 0, 2} {
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", id}, {"v", id * 10}})
+		require.NoError(t, err)
+	}
+
+	for name, tc := range map[string]struct { //nolint:vet // used for testing only
+		filter bson.D // optional, nil to leave filter unset
+		hint   any    // optional, nil to leave hint unset
+		min    bson.D // optional, nil to leave min unset
+		max    bson.D // optional, nil to leave max unset
+
+		expected []any               // expected _id values in order
+		err      *mongo.CommandError // expected error
+	}{
+		"MinMax": {
+			hint:     "_id_",
+			min:      bson.D{{"_id", 1}},
+			max:      bson.D{{"_id", 3}},
+			expected: []any{int32(1), int32(2)},
+		},
+		"MinOnly": {
+			hint:     bson.D{{"_id", 1}},
+			min:      bson.D{{"_id", 3}},
+			expected: []any{int32(3), int32(4)},
+		},
+		"MaxOnly": {
+			hint:     bson.D{{"_id", 1}},
+			max:      bson.D{{"_id", 2}},
+			expected: []any{int32(0), int32(1)},
+		},
+		"Filter": {
+			filter:   bson.D{{"_id", bson.D{{"$ne", 1}}}},
+			hint:     "_id_",
+			min:      bson.D{{"_id", 0}},
+			max:      bson.D{{"_id", 3}},
+			expected: []any{int32(0), int32(2)},
+		},
+		"NoHint": {
+			min: bson.D{{"_id", 1}},
+			err: &mongo.CommandError{
+				Code:    51173,
+				Name:    "Location51173",
+				Message: "When using min()/max() a hint of which index to use must be specified",
+			},
+		},
+		"NaturalHint": {
+			hint: bson.D{{"$natural", 1}},
+			max:  bson.D{{"_id", 1}},
+			err: &mongo.CommandError{
+				Code:    51173,
+				Name:    "Location51173",
+				Message: "When using min()/max() a hint of which index to use must be specified",
+			},
+		},
+		"FieldsMismatch": {
+			hint: "_id_",
+			min:  bson.D{{"_id", 1}},
+			max:  bson.D{{"v", 3}},
+			err: &mongo.CommandError{
+				Code:    51176,
+				Name:    "Location51176",
+				Message: "min() and max() must have the same field names",
+			},
+		},
+		"InvalidIndex": {
+			hint: "_id_",
+			min:  bson.D{{"v", 1}},
+			err: &mongo.CommandError{
+				Code: 51174,
+				Name: "Location51174",
+				Message: "error processing query: planner returned error :: caused by :: " +
+					"The index chosen is not valid for satisfying the min/max query",
+			},
+		},
+		"UnknownHint": {
+			hint: "nonexistent",
+			min:  bson.D{{"_id", 1}},
+			err: &mongo.CommandError{
+				Code: 2,
+				Name: "BadValue",
+				Message: "error processing query: planner returned error :: caused by :: " +
+					"hint provided does not correspond to an existing index",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			command := bson.D{{"find", collection.Name()}}
+
+			if tc.filter != nil {
+				command = append(command, bson.E{"filter", tc.filter})
+			}
+
+			if tc.hint != nil {
+				command = append(command, bson.E{"hint", tc.hint})
+			}
+
+			if tc.min != nil {
+				command = append(command, bson.E{"min", tc.min})
+			}
+
+			if tc.max != nil {
+				command = append(command, bson.E{"max", tc.max})
+			}
+
+			cursor, err := collection.Database().RunCommandCursor(ctx, command)
+			if tc.err != nil {
+				AssertEqualCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			defer cursor.Close(ctx)
+
+			docs := FetchAll(t, ctx, cursor)
+			assert.Equal(t, tc.expected, CollectIDs(t, docs))
+		})
+	}
+}
+
+func TestQueryCommandHint(t *testing.T) {
+	t.Parallel()
+
+	if !setup.IsMongoDB(t) && !setup.IsSQLite(t) {
+		t.Skip("https://github.com/FerretDB/FerretDB/issues/3175")
+	}
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "a"}, {"v", int32(1)}},
+		bson.D{{"_id", "b"}, {"v", int32(2)}},
+		bson.D{{"_id", "c"}, {"v", int32(3)}},
+	})
+	require.NoError(t, err)
+
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{"v", 1}}})
+	require.NoError(t, err)
+
+	filter := bson.D{{"_id", "b"}}
+
+	for name, tc := range map[string]struct {
+		hint any // required
+
+		err *mongo.CommandError // expected error, nil for success
+	}{
+		"Name": {
+			hint: "v_1",
+		},
+		"Key": {
+			hint: bson.D{{"v", 1}},
+		},
+		"ID": {
+			hint: bson.D{{"_id", 1}},
+		},
+		"Natural": {
+			hint: bson.D{{"$natural", -1}},
+		},
+		"UnknownName": {
+			hint: "nonexistent",
+			err: &mongo.CommandError{
+				Code: 2,
+				Name: "BadValue",
+				Message: "error processing query: planner returned error :: caused by :: " +
+					"hint provided does not correspond to an existing index",
+			},
+		},
+		"UnknownKey": {
+			hint: bson.D{{"v", -1}},
+			err: &mongo.CommandError{
+				Code: 2,
+				Name: "BadValue",
+				Message: "error processing query: planner returned error :: caused by :: " +
+					"hint provided does not correspond to an existing index",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			t.Run("Find", func(t *testing.T) {
+				t.Parallel()
+
+				cursor, err := collection.Find(ctx, filter, options.Find().SetHint(tc.hint))
+				if tc.err != nil {
+					AssertEqualCommandError(t, *tc.err, err)
+					return
+				}
+
+				require.NoError(t, err)
+
+				docs := FetchAll(t, ctx, cursor)
+				assert.Equal(t, []any{"b"}, CollectIDs(t, docs))
+			})
+
+			t.Run("Count", func(t *testing.T) {
+				t.Parallel()
+
+				n, err := collection.CountDocuments(ctx, filter, options.Count().SetHint(tc.hint))
+				if tc.err != nil {
+					AssertEqualCommandError(t, *tc.err, err)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), n)
+			})
+
+			t.Run("Aggregate", func(t *testing.T) {
+				t.Parallel()
+
+				pipeline := bson.A{bson.D{{"$match", filter}}}
+
+				cursor, err := collection.Aggregate(ctx, pipeline, options.Aggregate().SetHint(tc.hint))
+				if tc.err != nil {
+					AssertEqualCommandError(t, *tc.err, err)
+					return
+				}
+
+				require.NoError(t, err)
+
+				docs := FetchAll(t, ctx, cursor)
+				assert.Equal(t, []any{"b"}, CollectIDs(t, docs))
+			})
+
+			if tc.err != nil {
+				return
+			}
+
+			t.Run("Update", func(t *testing.T) {
+				t.Parallel()
+
+				update := bson.D{{"$set", bson.D{{"v", int32(2)}}}}
+
+				res, err := collection.UpdateOne(ctx, filter, update, options.Update().SetHint(tc.hint))
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), res.MatchedCount)
+			})
+
+			t.Run("Delete", func(t *testing.T) {
+				t.Parallel()
+
+				res, err := collection.DeleteMany(ctx, bson.D{{"_id", "none"}}, options.Delete().SetHint(tc.hint))
+				require.NoError(t, err)
+				assert.Equal(t, int64(0), res.DeletedCount)
+			})
+
+			t.Run("Explain", func(t *testing.T) {
+				t.Parallel()
+
+				var res bson.D
+				err := collection.Database().RunCommand(ctx, bson.D{{"explain", bson.D{
+					{"find", collection.Name()},
+					{"filter", filter},
+					{"hint", tc.hint},
+				}}}).Decode(&res)
+				require.NoError(t, err)
+
+				queryPlanner, ok := res.Map()["queryPlanner"].(bson.D)
+				require.True(t, ok)
+				assert.NotNil(t, queryPlanner.Map()["winningPlan"])
+			})
+		})
+	}
+}
+
+func TestQueryCommandReturnKey(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	for _, id := range []int32{1, 0} {
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", id}, {"v", id * 10}})
+		require.NoError(t, err)
+	}
+
+	for name, tc := range map[string]struct { //nolint:vet // used for testing only
+		hint     any      // optional, nil to leave hint unset
+		expected []bson.D // required
+	}{
+		"Hint": {
+			hint:     "_id_",
+			expected: []bson.D{{{"_id", int32(0)}}, {{"_id", int32(1)}}},
+		},
+		"NoHint": {
+			expected: []bson.D{{}, {}},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			command := bson.D{
+				{"find", collection.Name()},
+				{"sort", bson.D{{"_id", 1}}},
+				{"projection", bson.D{{"v", 1}}},
+				{"returnKey", true},
+			}
+
+			if tc.hint != nil {
+				command = append(command, bson.E{"hint", tc.hint})
+			}
+
+			cursor, err := collection.Database().RunCommandCursor(ctx, command)
+			require.NoError(t, err)
+
+			defer cursor.Close(ctx)
+
+			AssertEqualDocumentsSlice(t, tc.expected, FetchAll(t, ctx, cursor))
+		})
+	}
+}
+
+func TestQueryCommandShowRecordID(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	for _, id := range []int32{1, 0, 2} {
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", id}, {"v", id * 10}})
+		require.NoError(t, err)
+	}
+
+	cursor, err := collection.Database().RunCommandCursor(ctx, bson.D{
+		{"find", collection.Name()},
+		{"sort", bson.D{{"_id", 1}}},
+		{"projection", bson.D{{"_id", 1}}},
+		{"showRecordId", true},
+	})
+	require.NoError(t, err)
+
+	defer cursor.Close(ctx)
+
+	docs := FetchAll(t, ctx, cursor)
+	require.Len(t, docs, 3)
+
+	recordIDs := make(map[int64]struct{}, len(docs))
+
+	for i, doc := range docs {
+		require.Len(t, doc, 2)
+		assert.Equal(t, bson.E{"_id", int32(i)}, doc[0])
+		assert.Equal(t, "$recordId", doc[1].Key)
+
+		recordID, ok := doc[1].Value.(int64)
+		require.True(t, ok, "%T", doc[1].Value)
+
+		recordIDs[recordID] = struct{}{}
+	}
+
+	assert.Len(t, recordIDs, 3, "record IDs should be unique")
+}
+
+func TestQueryCommandResumeToken(t *testing.T) {
+	t.Parallel()
+
+	if !setup.IsMongoDB(t) && !setup.IsSQLite(t) {
+		t.Skip("resume tokens are supported by SQLite backend only")
+	}
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	for i := int32(1); i <= 5; i++ {
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", i}})
+		require.NoError(t, err)
+	}
+
+	var res bson.D
+	err := db.RunCommand(ctx, bson.D{
+		{"find", collection.Name()},
+		{"hint", bson.D{{"$natural", 1}}},
+		{"batchSize", 2},
+		{"$_requestResumeToken", true},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	c := res.Map()["cursor"].(bson.D).Map()
+	assert.Equal(t, bson.A{bson.D{{"_id", int32(1)}}, bson.D{{"_id", int32(2)}}}, c["firstBatch"])
+
+	token := c["postBatchResumeToken"]
+	require.NotNil(t, token)
+
+	// the scan is resumed after the last document of the first batch
+	err = db.RunCommand(ctx, bson.D{
+		{"find", collection.Name()},
+		{"hint", bson.D{{"$natural", 1}}},
+		{"$_requestResumeToken", true},
+		{"$_resumeAfter", token},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	c = res.Map()["cursor"].(bson.D).Map()
+	expected := bson.A{bson.D{{"_id", int32(3)}}, bson.D{{"_id", int32(4)}}, bson.D{{"_id", int32(5)}}}
+	assert.Equal(t, expected, c["firstBatch"])
+	assert.NotNil(t, c["postBatchResumeToken"])
+
+	t.Run("NoHint", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(ctx, bson.D{
+			{"find", collection.Name()},
+			{"$_requestResumeToken", true},
+		}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    2,
+			Name:    "BadValue",
+			Message: "$_requestResumeToken can only be used if hint: {$natural:1} is also specified",
+		}, err)
+	})
+
+	t.Run("NoRequest", func(t *testing.T) {
+		t.Parallel()
+
+		err := db.RunCommand(ctx, bson.D{
+			{"find", collection.Name()},
+			{"hint", bson.D{{"$natural", 1}}},
+			{"$_resumeAfter", token},
+		}).Err()
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    2,
+			Name:    "BadValue",
+			Message: "$_resumeAfter can only be set if $_requestResumeToken is true",
+		}, err)
+	})
+}
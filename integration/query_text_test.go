@@ -0,0 +1,202 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestQueryText(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "a"}, {"title", "Coffee shop"}, {"body", "The best coffee in town"}},
+		bson.D{{"_id", "b"}, {"title", "Tea house"}, {"body", "Tea and coffee"}},
+		bson.D{{"_id", "c"}, {"title", "Bakery"}, {"body", "Fresh bread and cakes"}},
+		bson.D{{"_id", "d"}, {"title", "Coffee"}},
+	})
+	require.NoError(t, err)
+
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{"title", "text"}, {"body", "text"}},
+		Options: options.Index().SetWeights(bson.D{{"title", int32(10)}}),
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		filter      bson.D
+		expectedIDs []any // sorted by text score
+		err         *mongo.CommandError
+	}{
+		"Term": {
+			filter:      bson.D{{"$text", bson.D{{"$search", "coffee"}}}},
+			expectedIDs: []any{"d", "a", "b"},
+		},
+		"Stemmed": {
+			filter:      bson.D{{"$text", bson.D{{"$search", "cake"}}}},
+			expectedIDs: []any{"c"},
+		},
+		"Negated": {
+			filter:      bson.D{{"$text", bson.D{{"$search", "coffee -tea"}}}},
+			expectedIDs: []any{"d", "a"},
+		},
+		"Phrase": {
+			filter:      bson.D{{"$text", bson.D{{"$search", `"coffee shop"`}}}},
+			expectedIDs: []any{"a"},
+		},
+		"WithFilter": {
+			filter:      bson.D{{"$text", bson.D{{"$search", "coffee"}}}, {"_id", bson.D{{"$ne", "d"}}}},
+			expectedIDs: []any{"a", "b"},
+		},
+		"NotObject": {
+			filter: bson.D{{"$text", "coffee"}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "$text expects an object",
+			},
+		},
+		"ExtraFields": {
+			filter: bson.D{{"$text", bson.D{{"$search", "coffee"}, {"foo", "bar"}}}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "extra fields in $text",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := options.Find().
+				SetProjection(bson.D{{"score", bson.D{{"$meta", "textScore"}}}}).
+				SetSort(bson.D{{"score", bson.D{{"$meta", "textScore"}}}})
+
+			cursor, err := collection.Find(ctx, tc.filter, opts)
+			if tc.err != nil {
+				AssertEqualCommandError(t, *tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+
+			var prev float64
+
+			ids := make([]any, len(res))
+			for i, doc := range res {
+				ids[i] = doc.Map()["_id"]
+
+				score, ok := doc.Map()["score"].(float64)
+				require.True(t, ok, "text score must be set")
+				assert.Positive(t, score)
+
+				if i > 0 {
+					assert.LessOrEqual(t, score, prev)
+				}
+
+				prev = score
+			}
+
+			assert.Equal(t, tc.expectedIDs, ids)
+		})
+	}
+}
+
+func TestQueryTextErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "a"}, {"v", "coffee"}})
+	require.NoError(t, err)
+
+	t.Run("NoIndex", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := collection.Find(ctx, bson.D{{"$text", bson.D{{"$search", "coffee"}}}})
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    27,
+			Name:    "IndexNotFound",
+			Message: "text index required for $text query",
+		}, err)
+	})
+
+	t.Run("ScoreWithoutText", func(t *testing.T) {
+		t.Parallel()
+
+		opts := options.Find().SetProjection(bson.D{{"score", bson.D{{"$meta", "textScore"}}}})
+
+		_, err := collection.Find(ctx, bson.D{}, opts)
+		AssertEqualCommandError(t, mongo.CommandError{
+			Code:    40218,
+			Name:    "Location40218",
+			Message: "query requires text score metadata, but it is not available",
+		}, err)
+	})
+}
+
+func TestQueryTextListIndexes(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{"title", "text"}, {"body", "text"}},
+		Options: options.Index().SetWeights(bson.D{{"title", int32(10)}}).SetName("text_idx"),
+	})
+	require.NoError(t, err)
+
+	cursor, err := collection.Indexes().List(ctx)
+	require.NoError(t, err)
+
+	var res []bson.D
+	require.NoError(t, cursor.All(ctx, &res))
+	require.Len(t, res, 2)
+
+	expected := bson.D{
+		{"v", int32(2)},
+		{"key", bson.D{{"_fts", "text"}, {"_ftsx", int32(1)}}},
+		{"name", "text_idx"},
+		{"weights", bson.D{{"body", int32(1)}, {"title", int32(10)}}},
+		{"default_language", "english"},
+		{"language_override", "language"},
+		{"textIndexVersion", int32(3)},
+	}
+	AssertEqualDocuments(t, expected, res[1])
+
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{"v", "text"}},
+		Options: options.Index().SetName("other_text_idx"),
+	})
+	AssertEqualAltCommandError(t, mongo.CommandError{
+		Code:    85,
+		Name:    "IndexOptionsConflict",
+		Message: "An equivalent index already exists with a different name and options",
+	}, "One of the specified indexes already exists with a different name", err)
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestSessionsCommandsCompat(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers: []shareddata.Provider{shareddata.Scalars},
+	})
+
+	ctx, targetDB, compatDB := s.Ctx, s.TargetCollections[0].Database(), s.CompatCollections[0].Database()
+
+	unknown := bson.D{{"id", primitive.Binary{Subtype: 0x04, Data: must.NotFail(uuid.New().MarshalBinary())}}}
+
+	for name, command := range map[string]bson.D{
+		"EndSessions":        {{"endSessions", bson.A{unknown}}},
+		"EndSessionsEmpty":   {{"endSessions", bson.A{}}},
+		"EndSessionsInt":     {{"endSessions", int32(1)}},
+		"EndSessionsIntElem": {{"endSessions", bson.A{int32(1)}}},
+		"EndSessionsNoID":    {{"endSessions", bson.A{bson.D{}}}},
+		"EndSessionsStrID":   {{"endSessions", bson.A{bson.D{{"id", "foo"}}}}},
+		"EndSessionsBinID": {{"endSessions", bson.A{
+			bson.D{{"id", primitive.Binary{Subtype: 0x00, Data: []byte{42}}}},
+		}}},
+		"RefreshSessions":    {{"refreshSessions", bson.A{unknown}}},
+		"RefreshSessionsStr": {{"refreshSessions", "foo"}},
+		"KillSessions":       {{"killSessions", bson.A{unknown}}},
+		"KillSessionsNoID":   {{"killSessions", bson.A{bson.D{{"foo", "bar"}}}}},
+		"KillAllSessionsUser": {{"killAllSessions", bson.A{
+			bson.D{{"user", "not-existing-user"}, {"db", "admin"}},
+		}}},
+		"KillAllSessionsNoUser": {{"killAllSessions", bson.A{bson.D{{"db", "admin"}}}}},
+	} {
+		name, command := name, command
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var targetRes, compatRes bson.D
+			targetErr := targetDB.RunCommand(ctx, command).Decode(&targetRes)
+			compatErr := compatDB.RunCommand(ctx, command).Decode(&compatRes)
+
+			if targetErr != nil {
+				t.Logf("Target error: %v", targetErr)
+				t.Logf("Compat error: %v", compatErr)
+
+				// error messages are intentionally not compared
+				AssertMatchesCommandError(t, compatErr, targetErr)
+
+				return
+			}
+			require.NoError(t, compatErr, "compat error; target returned no error")
+
+			AssertEqualDocuments(t, compatRes, targetRes)
+		})
+	}
+}
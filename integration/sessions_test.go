@@ -0,0 +1,99 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+func TestSessionsStartEnd(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, nil)
+	ctx, collection := s.Ctx, s.Collection
+	db := collection.Database()
+
+	var res bson.D
+	err := db.RunCommand(ctx, bson.D{{"startSession", int32(1)}}).Decode(&res)
+	require.NoError(t, err)
+
+	doc := ConvertDocument(t, res)
+	assert.Equal(t, float64(1), must.NotFail(doc.Get("ok")))
+	assert.Equal(t, int32(30), must.NotFail(doc.Get("timeoutMinutes")))
+
+	id := must.NotFail(must.NotFail(doc.Get("id")).(*types.Document).Get("id")).(types.Binary)
+	assert.Equal(t, types.BinaryUUID, id.Subtype)
+	assert.Len(t, id.B, 16)
+
+	lsid := bson.D{{"id", primitive.Binary{Subtype: byte(id.Subtype), Data: id.B}}}
+
+	// the started session could be used by commands;
+	// the driver adds its own implicit lsid, so the raw connection is used
+	var msg wire.OpMsg
+	require.NoError(t, msg.SetSections(wire.OpMsgSection{Documents: []*types.Document{must.NotFail(types.NewDocument(
+		"find", collection.Name(),
+		"lsid", must.NotFail(types.NewDocument("id", id)),
+		"$db", db.Name(),
+	))}}))
+
+	_, body := s.DialWire(t).RoundTrip(wire.OpCodeMsg, &msg)
+	reply, ok := body.(*wire.OpMsg)
+	require.True(t, ok)
+	assert.Equal(t, float64(1), must.NotFail(must.NotFail(reply.Document()).Get("ok")))
+
+	var status bson.D
+	err = db.RunCommand(ctx, bson.D{{"serverStatus", int32(1)}}).Decode(&status)
+	require.NoError(t, err)
+
+	cache, ok := must.NotFail(ConvertDocument(t, status).Get("logicalSessionRecordCache")).(*types.Document)
+	require.True(t, ok)
+	assert.GreaterOrEqual(t, must.NotFail(cache.Get("activeSessionsCount")), int32(1))
+
+	for _, command := range []string{"refreshSessions", "endSessions", "killSessions"} {
+		err = db.RunCommand(ctx, bson.D{{command, bson.A{lsid}}}).Decode(&res)
+		require.NoError(t, err, command)
+		assert.Equal(t, bson.D{{"ok", float64(1)}}, res, command)
+	}
+}
+
+func TestSessionsImplicit(t *testing.T) {
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t)
+
+	// the driver sends lsid with every command, as logicalSessionTimeoutMinutes is reported by hello
+	sess, err := collection.Database().Client().StartSession()
+	require.NoError(t, err)
+
+	defer sess.EndSession(ctx)
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "session"}})
+	require.NoError(t, err)
+
+	var hello bson.D
+	err = collection.Database().RunCommand(ctx, bson.D{{"hello", int32(1)}}).Decode(&hello)
+	require.NoError(t, err)
+	assert.Equal(t, int32(30), hello.Map()["logicalSessionTimeoutMinutes"])
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package setup
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FerretDB/FerretDB/internal/util/testutil/testtb"
+)
+
+// ConfigureFailPoint configures failpoint with the given name, mode and data,
+// and disables it when the test finishes.
+//
+// Failpoints are configured per listener, so the test is skipped if the target system
+// is not in-process FerretDB (other tests running in parallel could be affected),
+// or if test commands are not enabled.
+func (s *SetupResult) ConfigureFailPoint(tb testtb.TB, name string, mode any, data bson.D) {
+	tb.Helper()
+
+	if s.logs == nil {
+		tb.Skip("failpoints are tested only with in-process FerretDB")
+	}
+
+	admin := s.Collection.Database().Client().Database("admin")
+
+	cmd := bson.D{{"configureFailPoint", name}, {"mode", mode}}
+	if data != nil {
+		cmd = append(cmd, bson.E{"data", data})
+	}
+
+	err := admin.RunCommand(s.Ctx, cmd).Err()
+
+	var ce mongo.CommandError
+	if errors.As(err, &ce) && ce.Code == 59 {
+		tb.Skip("failpoints are available only with test commands enabled")
+	}
+
+	require.NoError(tb, err)
+
+	tb.Cleanup(func() {
+		// s.Ctx could be already canceled
+		err := admin.RunCommand(context.Background(), bson.D{{"configureFailPoint", name}, {"mode", "off"}}).Err()
+		require.NoError(tb, err)
+	})
+}
@@ -181,12 +181,13 @@ func setupListener(tb testtb.TB, ctx context.Context, logger *zap.Logger) string
 	require.NoError(tb, err)
 
 	listenerOpts := clientconn.NewListenerOpts{
-		ProxyAddr:      *targetProxyAddrF,
-		Mode:           clientconn.NormalMode,
-		Metrics:        listenerMetrics,
-		Handler:        h,
-		Logger:         logger,
-		TestRecordsDir: filepath.Join("..", "tmp", "records"),
+		ProxyAddr:          *targetProxyAddrF,
+		Mode:               clientconn.NormalMode,
+		Metrics:            listenerMetrics,
+		Handler:            h,
+		Logger:             logger,
+		TestRecordsDir:     filepath.Join("..", "tmp", "records"),
+		EnableTestCommands: true,
 	}
 
 	if *targetProxyAddrF != "" {
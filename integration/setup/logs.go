@@ -0,0 +1,104 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package setup
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/FerretDB/FerretDB/internal/util/testutil/testtb"
+)
+
+// observeLogs returns a logger that writes to the given logger and records entries
+// of any level in memory, so tests could assert log side effects of in-process FerretDB.
+//
+// Entries are recorded only after the returned function is called at the end of the setup,
+// so commands sent by the setup itself (like reaping and filling collections) are not recorded.
+func observeLogs(logger *zap.Logger) (*zap.Logger, *observer.ObservedLogs, func()) {
+	level := zap.NewAtomicLevelAt(zapcore.InvalidLevel)
+	core, logs := observer.New(level)
+
+	logger = logger.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(c, core)
+	}))
+
+	return logger, logs, func() { level.SetLevel(zapcore.DebugLevel) }
+}
+
+// requireLogs returns recorded log entries or skips the test if they are not available.
+func requireLogs(tb testtb.TB, logs *observer.ObservedLogs) *observer.ObservedLogs {
+	tb.Helper()
+
+	if logs == nil {
+		tb.Skip("logs are available only for in-process FerretDB")
+	}
+
+	return logs
+}
+
+// Logs returns log entries of in-process FerretDB recorded during the test.
+//
+// The test is skipped if the target system is not in-process FerretDB.
+func (s *SetupResult) Logs(tb testtb.TB) *observer.ObservedLogs {
+	tb.Helper()
+
+	return requireLogs(tb, s.logs)
+}
+
+// Logs returns log entries of in-process target FerretDB recorded during the test.
+//
+// The test is skipped if the target system is not in-process FerretDB.
+func (s *SetupCompatResult) Logs(tb testtb.TB) *observer.ObservedLogs {
+	tb.Helper()
+
+	return requireLogs(tb, s.logs)
+}
+
+// AssertLogged asserts that at least one entry of the given level with the message containing
+// the given snippet was logged, and returns all such entries.
+func AssertLogged(tb testtb.TB, logs *observer.ObservedLogs, level zapcore.Level, snippet string) []observer.LoggedEntry {
+	tb.Helper()
+
+	res := logs.FilterLevelExact(level).FilterMessageSnippet(snippet).All()
+	if len(res) > 0 {
+		return res
+	}
+
+	var messages []string
+	for _, e := range logs.All() {
+		messages = append(messages, e.Level.CapitalString()+" "+e.Message)
+	}
+
+	tb.Errorf(
+		"No %s log entry containing %q was found. Logged entries:\n%s",
+		level.CapitalString(), snippet, strings.Join(messages, "\n"),
+	)
+
+	return nil
+}
+
+// AssertNotLogged asserts that no entry of the given level with the message containing
+// the given snippet was logged.
+func AssertNotLogged(tb testtb.TB, logs *observer.ObservedLogs, level zapcore.Level, snippet string) {
+	tb.Helper()
+
+	res := logs.FilterLevelExact(level).FilterMessageSnippet(snippet).All()
+	for _, e := range res {
+		tb.Errorf("Unexpected %s log entry: %s %v", level.CapitalString(), e.Message, e.ContextMap())
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package setup
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+
+	"github.com/FerretDB/FerretDB/internal/util/observability"
+	"github.com/FerretDB/FerretDB/internal/util/testutil/testtb"
+)
+
+// Test databases and collections (namespaces) are dropped after successful tests.
+// Namespaces of failed tests are left for debugging and tagged with the time of the failure
+// by storing markers in that database and collection,
+// and then dropped by the reaper when they become stale.
+// Namespaces of crashed tests are dropped by the next run of the same test.
+const (
+	reaperDatabase   = "testnamespaces"
+	reaperCollection = "namespaces"
+)
+
+// reapOnce ensures that stale namespaces of each system are reaped only once per run.
+var reapOnce = map[string]*sync.Once{
+	"target": new(sync.Once),
+	"compat": new(sync.Once),
+}
+
+// reaperMarker represents a marker of a test namespace.
+type reaperMarker struct {
+	ID         string    `bson:"_id"`
+	Database   string    `bson:"database"`
+	Collection string    `bson:"collection,omitempty"` // empty for the whole database
+	Created    time.Time `bson:"created"`
+}
+
+// reaperMarkers returns the collection with markers.
+func reaperMarkers(client *mongo.Client) *mongo.Collection {
+	return client.Database(reaperDatabase).Collection(reaperCollection)
+}
+
+// tagNamespace stores the marker with the current time for the given database,
+// or for the given collection if it is not empty.
+// It is called only for namespaces of failed tests, so tests do not contend on markers.
+//
+// Errors are logged, but do not fail the test.
+func tagNamespace(tb testtb.TB, ctx context.Context, client *mongo.Client, database, collection string) {
+	tb.Helper()
+
+	m := &reaperMarker{
+		ID:         database,
+		Database:   database,
+		Collection: collection,
+		Created:    time.Now().UTC().Truncate(time.Millisecond),
+	}
+
+	if collection != "" {
+		m.ID += "." + collection
+	}
+
+	_, err := reaperMarkers(client).ReplaceOne(ctx, bson.D{{"_id", m.ID}}, m, options.Replace().SetUpsert(true))
+	if err != nil {
+		tb.Logf("Failed to tag namespace %s: %s.", m.ID, err)
+	}
+}
+
+// reapStaleNamespaces drops test namespaces that were tagged before the -reap-stale-after duration.
+//
+// It does that only once per run for the given system ("target" or "compat").
+func reapStaleNamespaces(tb testtb.TB, ctx context.Context, client *mongo.Client, system string) {
+	tb.Helper()
+
+	staleAfter := *reapStaleAfterF
+	if staleAfter <= 0 {
+		return
+	}
+
+	reapOnce[system].Do(func() {
+		ctx, span := otel.Tracer("").Start(ctx, "reapStaleNamespaces")
+		defer span.End()
+
+		defer observability.FuncCall(ctx)()
+
+		markers := reaperMarkers(client)
+
+		filter := bson.D{{"created", bson.D{{"$lt", time.Now().Add(-staleAfter)}}}}
+
+		cursor, err := markers.Find(ctx, filter)
+		if err != nil {
+			tb.Logf("Failed to find stale namespaces of %s system: %s.", system, err)
+			return
+		}
+
+		var stale []reaperMarker
+		if err = cursor.All(ctx, &stale); err != nil {
+			tb.Logf("Failed to find stale namespaces of %s system: %s.", system, err)
+			return
+		}
+
+		for _, m := range stale {
+			db := client.Database(m.Database)
+
+			if m.Collection == "" {
+				err = db.Drop(ctx)
+			} else {
+				err = db.Collection(m.Collection).Drop(ctx)
+			}
+
+			if err != nil {
+				tb.Logf("Failed to drop stale namespace %s of %s system: %s.", m.ID, system, err)
+				continue
+			}
+
+			if _, err = markers.DeleteOne(ctx, bson.D{{"_id", m.ID}}); err != nil {
+				tb.Logf("Failed to untag stale namespace %s of %s system: %s.", m.ID, system, err)
+				continue
+			}
+
+			tb.Logf("Dropped stale namespace %s of %s system created at %s.", m.ID, system, m.Created)
+		}
+	})
+}
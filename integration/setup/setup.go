@@ -23,12 +23,14 @@ import (
 	"path/filepath"
 	"runtime/trace"
 	"strings"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/integration/shareddata"
@@ -61,6 +63,16 @@ var (
 
 	disableFilterPushdownF = flag.Bool("disable-filter-pushdown", false, "disable filter pushdown")
 	enableSortPushdownF    = flag.Bool("enable-sort-pushdown", false, "enable sort pushdown")
+
+	deterministicSeedF = flag.Int64(
+		"deterministic-seed", 0,
+		"in-process FerretDB: seed for deterministic ObjectIDs and server time; 0 disables",
+	)
+
+	reapStaleAfterF = flag.Duration(
+		"reap-stale-after", time.Hour,
+		"drop test databases and collections left by previous runs if they are older than that; 0 disables",
+	)
 )
 
 // Other globals.
@@ -97,6 +109,8 @@ type SetupResult struct {
 	Ctx        context.Context
 	Collection *mongo.Collection
 	MongoDBURI string
+
+	logs *observer.ObservedLogs // nil if target system is not in-process FerretDB
 }
 
 // IsUnixSocket returns true if MongoDB URI is a Unix socket.
@@ -136,8 +150,12 @@ func SetupWithOpts(tb testtb.TB, opts *SetupOpts) *SetupResult {
 	}
 	logger := testutil.LevelLogger(tb, level)
 
+	var logs *observer.ObservedLogs
+	startLogs := func() {}
+
 	uri := *targetURLF
 	if uri == "" {
+		logger, logs, startLogs = observeLogs(logger)
 		uri = setupListener(tb, setupCtx, logger)
 	}
 
@@ -163,14 +181,18 @@ func SetupWithOpts(tb testtb.TB, opts *SetupOpts) *SetupResult {
 	// register cleanup function after setupListener registers its own to preserve full logs
 	tb.Cleanup(cancel)
 
+	reapStaleNamespaces(tb, setupCtx, client, "target")
+
 	collection := setupCollection(tb, setupCtx, client, opts)
 
 	level.SetLevel(*logLevelF)
+	startLogs()
 
 	return &SetupResult{
 		Ctx:        ctx,
 		Collection: collection,
 		MongoDBURI: uri,
+		logs:       logs,
 	}
 }
 
@@ -237,6 +259,13 @@ func setupCollection(tb testtb.TB, ctx context.Context, client *mongo.Client, op
 		tb.Cleanup(func() {
 			if tb.Failed() {
 				tb.Logf("Keeping %s.%s for debugging.", databaseName, collectionName)
+
+				if ownDatabase {
+					tagNamespace(tb, ctx, client, databaseName, "")
+				} else {
+					tagNamespace(tb, ctx, client, databaseName, collectionName)
+				}
+
 				return
 			}
 
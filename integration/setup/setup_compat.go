@@ -24,6 +24,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/FerretDB/FerretDB/integration/shareddata"
 	"github.com/FerretDB/FerretDB/internal/util/observability"
@@ -55,6 +56,8 @@ type SetupCompatResult struct {
 	Ctx               context.Context
 	TargetCollections []*mongo.Collection
 	CompatCollections []*mongo.Collection
+
+	logs *observer.ObservedLogs // nil if target system is not in-process FerretDB
 }
 
 // SetupCompatWithOpts setups the compatibility test according to given options.
@@ -94,7 +97,11 @@ func SetupCompatWithOpts(tb testtb.TB, opts *SetupCompatOpts) *SetupCompatResult
 	logger := testutil.LevelLogger(tb, level)
 
 	var targetClient *mongo.Client
+	var logs *observer.ObservedLogs
+	startLogs := func() {}
+
 	if *targetURLF == "" {
+		logger, logs, startLogs = observeLogs(logger)
 		uri := setupListener(tb, setupCtx, logger)
 		targetClient = setupClient(tb, setupCtx, uri)
 	} else {
@@ -104,17 +111,21 @@ func SetupCompatWithOpts(tb testtb.TB, opts *SetupCompatOpts) *SetupCompatResult
 	// register cleanup function after setupListener registers its own to preserve full logs
 	tb.Cleanup(cancel)
 
+	reapStaleNamespaces(tb, setupCtx, targetClient, "target")
 	targetCollections := setupCompatCollections(tb, setupCtx, targetClient, opts, *targetBackendF)
 
 	compatClient := setupClient(tb, setupCtx, *compatURLF)
+	reapStaleNamespaces(tb, setupCtx, compatClient, "compat")
 	compatCollections := setupCompatCollections(tb, setupCtx, compatClient, opts, "mongodb")
 
 	level.SetLevel(*logLevelF)
+	startLogs()
 
 	return &SetupCompatResult{
 		Ctx:               ctx,
 		TargetCollections: targetCollections,
 		CompatCollections: compatCollections,
+		logs:              logs,
 	}
 }
 
@@ -145,6 +156,7 @@ func setupCompatCollections(tb testtb.TB, ctx context.Context, client *mongo.Cli
 	// delete database unless test failed
 	tb.Cleanup(func() {
 		if tb.Failed() {
+			tagNamespace(tb, ctx, client, opts.databaseName, "")
 			return
 		}
 
@@ -32,6 +32,7 @@ import (
 
 	"github.com/FerretDB/FerretDB/integration/shareddata"
 	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
+	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/debug"
 	"github.com/FerretDB/FerretDB/internal/util/logging"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -88,6 +89,11 @@ func Startup() {
 		zap.S().Infof("Target system: %s (%s).", *targetBackendF, u)
 	} else {
 		zap.S().Infof("Target system: %s (built-in).", *targetBackendF)
+
+		if seed := *deterministicSeedF; seed != 0 {
+			zap.S().Infof("Using deterministic ObjectIDs and server time with seed %d.", seed)
+			types.SetDeterministic(seed)
+		}
 	}
 
 	if u := *compatURLF; u != "" {
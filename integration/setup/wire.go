@@ -0,0 +1,180 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package setup
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil/testtb"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// wireReadTimeout is the maximum time to wait for a single raw response.
+const wireReadTimeout = 30 * time.Second
+
+// WireConn is a minimal raw wire protocol client.
+//
+// It sends hand-crafted messages and reads raw responses,
+// so it could be used to test protocol-level features that the official driver hides:
+// checksums, flags, malformed input, etc.
+type WireConn struct {
+	tb        testtb.TB
+	conn      net.Conn
+	bufr      *bufio.Reader
+	bufw      *bufio.Writer
+	requestID int32
+}
+
+// DialWire opens a raw wire protocol connection to the target system.
+//
+// The connection is closed automatically when test ends.
+// The test is skipped for TLS connections.
+func (s *SetupResult) DialWire(tb testtb.TB) *WireConn {
+	tb.Helper()
+
+	opts := options.Client().ApplyURI(s.MongoDBURI)
+	if opts.TLSConfig != nil {
+		tb.Skip("raw wire connections are not supported over TLS")
+	}
+
+	require.NotEmpty(tb, opts.Hosts)
+
+	network := "tcp"
+	if s.IsUnixSocket(tb) {
+		network = "unix"
+	}
+
+	conn, err := net.Dial(network, opts.Hosts[0])
+	require.NoError(tb, err)
+
+	tb.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return &WireConn{
+		tb:   tb,
+		conn: conn,
+		bufr: bufio.NewReader(conn),
+		bufw: bufio.NewWriter(conn),
+	}
+}
+
+// NextRequestID returns a new request ID for the message sent over this connection.
+func (c *WireConn) NextRequestID() int32 {
+	c.requestID++
+	return c.requestID
+}
+
+// WriteRaw writes the given bytes to the connection as is.
+func (c *WireConn) WriteRaw(b []byte) {
+	c.tb.Helper()
+
+	_, err := c.bufw.Write(b)
+	require.NoError(c.tb, err)
+	require.NoError(c.tb, c.bufw.Flush())
+}
+
+// ReadRaw reads a single message and returns its header and body bytes without parsing the body.
+//
+// Unlike other methods, it returns an error instead of failing the test,
+// so it could be used to check that the connection was closed (io.EOF is returned in that case).
+func (c *WireConn) ReadRaw() (*wire.MsgHeader, []byte, error) {
+	if err := c.conn.SetReadDeadline(time.Now().Add(wireReadTimeout)); err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	b := make([]byte, wire.MsgHeaderLen)
+	if _, err := io.ReadFull(c.bufr, b); err != nil {
+		return nil, nil, err
+	}
+
+	header := &wire.MsgHeader{
+		MessageLength: int32(binary.LittleEndian.Uint32(b[0:4])),
+		RequestID:     int32(binary.LittleEndian.Uint32(b[4:8])),
+		ResponseTo:    int32(binary.LittleEndian.Uint32(b[8:12])),
+		OpCode:        wire.OpCode(binary.LittleEndian.Uint32(b[12:16])),
+	}
+
+	if header.MessageLength < wire.MsgHeaderLen || header.MessageLength > wire.MaxMsgLen {
+		return nil, nil, lazyerrors.Errorf("invalid message length %d", header.MessageLength)
+	}
+
+	body := make([]byte, header.MessageLength-wire.MsgHeaderLen)
+	if _, err := io.ReadFull(c.bufr, body); err != nil {
+		return nil, nil, err
+	}
+
+	return header, body, nil
+}
+
+// Send marshals the given message body, sends it with a new request ID, and returns that ID.
+func (c *WireConn) Send(opCode wire.OpCode, body wire.MsgBody) int32 {
+	c.tb.Helper()
+
+	b, err := body.MarshalBinary()
+	require.NoError(c.tb, err)
+
+	requestID := c.NextRequestID()
+	c.WriteRaw(WireMessage(requestID, opCode, b))
+
+	return requestID
+}
+
+// Receive reads and parses a single message.
+func (c *WireConn) Receive() (*wire.MsgHeader, wire.MsgBody) {
+	c.tb.Helper()
+
+	require.NoError(c.tb, c.conn.SetReadDeadline(time.Now().Add(wireReadTimeout)))
+
+	header, body, err := wire.ReadMessage(c.bufr)
+	require.NoError(c.tb, err)
+
+	return header, body
+}
+
+// RoundTrip sends the given message body and returns the response to it.
+func (c *WireConn) RoundTrip(opCode wire.OpCode, body wire.MsgBody) (*wire.MsgHeader, wire.MsgBody) {
+	c.tb.Helper()
+
+	requestID := c.Send(opCode, body)
+
+	header, resBody := c.Receive()
+	require.Equal(c.tb, requestID, header.ResponseTo)
+
+	return header, resBody
+}
+
+// WireMessage returns raw message bytes for the given request ID, opcode, and body bytes.
+//
+// The message length in the header is set to the actual length,
+// so it should be changed by the caller to test invalid lengths.
+func WireMessage(requestID int32, opCode wire.OpCode, body []byte) []byte {
+	header := &wire.MsgHeader{
+		MessageLength: int32(wire.MsgHeaderLen + len(body)),
+		RequestID:     requestID,
+		OpCode:        opCode,
+	}
+
+	return append(must.NotFail(header.MarshalBinary()), body...)
+}
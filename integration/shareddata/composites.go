@@ -257,6 +257,23 @@ var ArrayRegexes = &Values[string]{
 	},
 }
 
+// ArrayMixedTypes contains arrays with values of mixed types and scalars for tests.
+// It is used for multikey indexes: each array element is indexed separately.
+var ArrayMixedTypes = &Values[string]{
+	name: "ArrayMixedTypes",
+	data: map[string]any{
+		"array-mixed-numbers":   bson.A{int32(42), 42.13, int64(43)},
+		"array-mixed-scalars":   bson.A{"foo", int32(42), nil, true},
+		"array-mixed-nested":    bson.A{bson.A{int32(42), "foo"}, int32(44), "bar"},
+		"array-mixed-documents": bson.A{bson.D{{"foo", int32(42)}}, int32(42), "foo"},
+		"array-mixed-empty":     bson.A{bson.A{}, int64(42)},
+		"array-duplicates":      bson.A{int32(42), 42.0, int64(42)},
+		"scalar-int32":          int32(42),
+		"scalar-string":         "foo",
+		"document":              bson.D{{"foo", int32(42)}},
+	},
+}
+
 // ArrayDocuments contains array with documents with arrays: {"v": [{"foo": [{"bar": "hello"}]}, ...]}.
 // This data set is helpful for dot notation tests: v.0.foo.0.bar.
 var ArrayDocuments = &Values[string]{
@@ -0,0 +1,117 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+	"github.com/FerretDB/FerretDB/internal/util/testutil/testtb"
+)
+
+// startTransaction starts a new session with a started transaction and returns its context.
+func startTransaction(t testtb.TB, collection *mongo.Collection) mongo.SessionContext {
+	t.Helper()
+
+	sess, err := collection.Database().Client().StartSession()
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		sess.EndSession(testutil.Ctx(t))
+	})
+
+	require.NoError(t, sess.StartTransaction())
+
+	return mongo.NewSessionContext(testutil.Ctx(t), sess)
+}
+
+func TestTransactionsCommit(tt *testing.T) {
+	setup.SkipForMongoDB(tt, "Transactions require replica set")
+
+	tt.Parallel()
+
+	t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/1547")
+
+	ctx, collection := setup.Setup(t)
+
+	sctx := startTransaction(t, collection)
+
+	_, err := collection.InsertOne(sctx, bson.D{{"_id", "commit"}})
+	require.NoError(t, err)
+
+	// changes are visible inside the transaction
+	n, err := collection.CountDocuments(sctx, bson.D{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	// but not outside of it
+	n, err = collection.CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	require.NoError(t, sctx.CommitTransaction(sctx))
+
+	n, err = collection.CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestTransactionsAbort(tt *testing.T) {
+	setup.SkipForMongoDB(tt, "Transactions require replica set")
+
+	tt.Parallel()
+
+	t := setup.FailsForSQLite(tt, "https://github.com/FerretDB/FerretDB/issues/1547")
+
+	ctx, collection := setup.Setup(t)
+
+	sctx := startTransaction(t, collection)
+
+	_, err := collection.InsertOne(sctx, bson.D{{"_id", "abort"}})
+	require.NoError(t, err)
+
+	require.NoError(t, sctx.AbortTransaction(sctx))
+
+	n, err := collection.CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	// the next operation with the same transaction number fails
+	err = collection.Database().RunCommand(sctx, bson.D{{"commitTransaction", int32(1)}}).Err()
+	require.Error(t, err)
+}
+
+func TestTransactionsErrors(t *testing.T) {
+	setup.SkipForMongoDB(t, "Transactions require replica set")
+
+	t.Parallel()
+
+	_, collection := setup.Setup(t)
+
+	sctx := startTransaction(t, collection)
+
+	err := collection.Database().RunCommand(sctx, bson.D{{"listCollections", int32(1)}}).Err()
+	AssertEqualCommandError(t, mongo.CommandError{
+		Code:    263,
+		Name:    "OperationNotSupportedInTransaction",
+		Message: "Cannot run 'listCollections' in a multi-document transaction.",
+	}, err)
+}
@@ -0,0 +1,392 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unified
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+// sensitiveCommands are commands that are not observed unless observeSensitiveCommands is set.
+var sensitiveCommands = map[string]struct{}{
+	"authenticate":    {},
+	"saslStart":       {},
+	"saslContinue":    {},
+	"getnonce":        {},
+	"createUser":      {},
+	"updateUser":      {},
+	"copydbgetnonce":  {},
+	"copydbsaslstart": {},
+	"copydb":          {},
+	"hello":           {},
+	"isMaster":        {},
+	"ismaster":        {},
+}
+
+// commandEvent represents a single observed command monitoring event.
+type commandEvent struct {
+	typ          string // commandStartedEvent, commandSucceededEvent, or commandFailedEvent
+	commandName  string
+	databaseName string
+	command      bson.Raw // for started events
+	reply        bson.Raw // for succeeded events
+}
+
+// String implements fmt.Stringer interface.
+func (e commandEvent) String() string {
+	return fmt.Sprintf("%s(%s)", e.typ, e.commandName)
+}
+
+// clientEntity represents a client entity with observed events.
+type clientEntity struct {
+	client           *mongo.Client
+	observe          map[string]struct{}
+	ignore           map[string]struct{}
+	observeSensitive bool
+
+	m      sync.Mutex
+	events []commandEvent
+}
+
+// observedEvents returns a copy of all observed events.
+func (c *clientEntity) observedEvents() []commandEvent {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	return append([]commandEvent(nil), c.events...)
+}
+
+// record records the event if it should be observed.
+func (c *clientEntity) record(e commandEvent) {
+	if _, ok := c.observe[e.typ]; !ok {
+		return
+	}
+
+	if _, ok := c.ignore[e.commandName]; ok {
+		return
+	}
+
+	if _, ok := sensitiveCommands[e.commandName]; ok && !c.observeSensitive {
+		return
+	}
+
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.events = append(c.events, e)
+}
+
+// monitor returns a command monitor that records events.
+func (c *clientEntity) monitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			c.record(commandEvent{
+				typ:          "commandStartedEvent",
+				commandName:  e.CommandName,
+				databaseName: e.DatabaseName,
+				command:      e.Command,
+			})
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			c.record(commandEvent{
+				typ:         "commandSucceededEvent",
+				commandName: e.CommandName,
+				reply:       e.Reply,
+			})
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			c.record(commandEvent{
+				typ:         "commandFailedEvent",
+				commandName: e.CommandName,
+			})
+		},
+	}
+}
+
+// entities contains all entities of a single test case.
+type entities struct {
+	clients     map[string]*clientEntity
+	databases   map[string]*mongo.Database
+	collections map[string]*mongo.Collection
+	sessions    map[string]mongo.Session
+	results     map[string]bson.RawValue
+}
+
+// newEntities creates an empty entity map.
+func newEntities() *entities {
+	return &entities{
+		clients:     map[string]*clientEntity{},
+		databases:   map[string]*mongo.Database{},
+		collections: map[string]*mongo.Collection{},
+		sessions:    map[string]mongo.Session{},
+		results:     map[string]bson.RawValue{},
+	}
+}
+
+// close ends all sessions and disconnects all clients.
+func (e *entities) close(ctx context.Context) {
+	for _, s := range e.sessions {
+		s.EndSession(ctx)
+	}
+
+	for _, c := range e.clients {
+		_ = c.client.Disconnect(ctx)
+	}
+}
+
+// client returns client entity with the given id.
+func (e *entities) client(t *testing.T, id string) *clientEntity {
+	t.Helper()
+
+	c, ok := e.clients[id]
+	require.True(t, ok, "client entity %q not found", id)
+
+	return c
+}
+
+// createEntity creates a single entity from createEntities.
+func (r *runner) createEntity(raw bson.Raw) {
+	r.t.Helper()
+
+	elems, err := raw.Elements()
+	require.NoError(r.t, err)
+	require.Len(r.t, elems, 1)
+
+	typ, spec := elems[0].Key(), elems[0].Value().Document()
+
+	switch typ {
+	case "client":
+		r.createClient(spec)
+
+	case "database":
+		var s struct {
+			ID              string   `bson:"id"`
+			Client          string   `bson:"client"`
+			DatabaseName    string   `bson:"databaseName"`
+			DatabaseOptions bson.Raw `bson:"databaseOptions"`
+		}
+		require.NoError(r.t, bson.Unmarshal(spec, &s))
+
+		rc, wc := r.collectionOptions(s.DatabaseOptions)
+		opts := options.Database().SetReadConcern(rc).SetWriteConcern(wc)
+
+		client := r.entities.client(r.t, s.Client)
+		r.entities.databases[s.ID] = client.client.Database(s.DatabaseName, opts)
+
+	case "collection":
+		var s struct {
+			ID                string   `bson:"id"`
+			Database          string   `bson:"database"`
+			CollectionName    string   `bson:"collectionName"`
+			CollectionOptions bson.Raw `bson:"collectionOptions"`
+		}
+		require.NoError(r.t, bson.Unmarshal(spec, &s))
+
+		db, ok := r.entities.databases[s.Database]
+		require.True(r.t, ok, "database entity %q not found", s.Database)
+
+		rc, wc := r.collectionOptions(s.CollectionOptions)
+		opts := options.Collection().SetReadConcern(rc).SetWriteConcern(wc)
+
+		r.entities.collections[s.ID] = db.Collection(s.CollectionName, opts)
+
+	case "session":
+		var s struct {
+			ID             string   `bson:"id"`
+			Client         string   `bson:"client"`
+			SessionOptions bson.Raw `bson:"sessionOptions"`
+		}
+		require.NoError(r.t, bson.Unmarshal(spec, &s))
+
+		opts := options.Session()
+
+		if s.SessionOptions != nil {
+			elems, err := s.SessionOptions.Elements()
+			require.NoError(r.t, err)
+
+			for _, elem := range elems {
+				switch elem.Key() {
+				case "causalConsistency":
+					opts.SetCausalConsistency(elem.Value().Boolean())
+				case "snapshot":
+					opts.SetSnapshot(elem.Value().Boolean())
+				default:
+					r.t.Skipf("session option %q is not supported", elem.Key())
+				}
+			}
+		}
+
+		client := r.entities.client(r.t, s.Client)
+
+		session, err := client.client.StartSession(opts)
+		require.NoError(r.t, err)
+
+		r.entities.sessions[s.ID] = session
+
+	default:
+		r.t.Skipf("%s entities are not supported", typ)
+	}
+}
+
+// createClient creates a client entity.
+func (r *runner) createClient(spec bson.Raw) {
+	r.t.Helper()
+
+	var s struct {
+		ID                            string   `bson:"id"`
+		URIOptions                    bson.Raw `bson:"uriOptions"`
+		UseMultipleMongoses           *bool    `bson:"useMultipleMongoses"`
+		ObserveEvents                 []string `bson:"observeEvents"`
+		IgnoreCommandMonitoringEvents []string `bson:"ignoreCommandMonitoringEvents"`
+		ObserveSensitiveCommands      bool     `bson:"observeSensitiveCommands"`
+		StoreEventsAsEntities         bson.Raw `bson:"storeEventsAsEntities"`
+		ServerAPI                     bson.Raw `bson:"serverApi"`
+		ObserveLogMessages            bson.Raw `bson:"observeLogMessages"`
+	}
+	require.NoError(r.t, bson.Unmarshal(spec, &s))
+
+	switch {
+	case s.StoreEventsAsEntities != nil:
+		r.t.Skip("storeEventsAsEntities is not supported")
+	case s.ServerAPI != nil:
+		r.t.Skip("serverApi is not supported")
+	case s.ObserveLogMessages != nil:
+		r.t.Skip("observeLogMessages is not supported")
+	}
+
+	c := &clientEntity{
+		observe:          map[string]struct{}{},
+		ignore:           map[string]struct{}{},
+		observeSensitive: s.ObserveSensitiveCommands,
+	}
+
+	for _, e := range s.ObserveEvents {
+		if !strings.HasPrefix(e, "command") {
+			r.t.Skipf("%s events are not supported", e)
+		}
+
+		c.observe[e] = struct{}{}
+	}
+
+	for _, name := range s.IgnoreCommandMonitoringEvents {
+		c.ignore[name] = struct{}{}
+	}
+
+	opts := options.Client().ApplyURI(r.opts.MongoDBURI).SetMonitor(c.monitor())
+
+	if s.URIOptions != nil {
+		elems, err := s.URIOptions.Elements()
+		require.NoError(r.t, err)
+
+		for _, elem := range elems {
+			v := elem.Value()
+
+			switch elem.Key() {
+			case "retryWrites":
+				opts.SetRetryWrites(v.Boolean())
+			case "retryReads":
+				opts.SetRetryReads(v.Boolean())
+			case "appname":
+				opts.SetAppName(v.StringValue())
+			case "readConcernLevel":
+				opts.SetReadConcern(readconcern.New(readconcern.Level(v.StringValue())))
+			case "w":
+				opts.SetWriteConcern(writeConcern(r.t, v))
+			case "heartbeatFrequencyMS", "serverSelectionTimeoutMS", "connectTimeoutMS":
+				// not important for a single in-process server
+			default:
+				r.t.Skipf("URI option %q is not supported", elem.Key())
+			}
+		}
+	}
+
+	var err error
+	c.client, err = mongo.Connect(r.ctx, opts)
+	require.NoError(r.t, err)
+
+	r.entities.clients[s.ID] = c
+}
+
+// collectionOptions returns read and write concerns from databaseOptions or collectionOptions of the entity.
+// Nil values mean that concerns are inherited.
+func (r *runner) collectionOptions(spec bson.Raw) (*readconcern.ReadConcern, *writeconcern.WriteConcern) {
+	r.t.Helper()
+
+	if spec == nil {
+		return nil, nil
+	}
+
+	elems, err := spec.Elements()
+	require.NoError(r.t, err)
+
+	var rc *readconcern.ReadConcern
+	var wc *writeconcern.WriteConcern
+
+	for _, elem := range elems {
+		v := elem.Value()
+
+		switch elem.Key() {
+		case "readConcern":
+			level, _ := v.Document().Lookup("level").StringValueOK()
+			rc = readconcern.New(readconcern.Level(level))
+
+		case "writeConcern":
+			wc = writeconcern.New()
+			if w, err := v.Document().LookupErr("w"); err == nil {
+				wc = writeConcern(r.t, w)
+			}
+
+		case "readPreference":
+			mode, _ := v.Document().Lookup("mode").StringValueOK()
+			if mode != "primary" {
+				r.t.Skipf("read preference %q is not supported", mode)
+			}
+
+		default:
+			r.t.Skipf("option %q is not supported", elem.Key())
+		}
+	}
+
+	return rc, wc
+}
+
+// writeConcern returns write concern for the given `w` value.
+func writeConcern(t *testing.T, w bson.RawValue) *writeconcern.WriteConcern {
+	t.Helper()
+
+	if s, ok := w.StringValueOK(); ok {
+		if s != "majority" {
+			t.Skipf("write concern %q is not supported", s)
+		}
+
+		return writeconcern.New(writeconcern.WMajority())
+	}
+
+	n, ok := w.AsInt64OK()
+	require.True(t, ok, "unexpected w value %s", w)
+
+	return writeconcern.New(writeconcern.W(int(n)))
+}
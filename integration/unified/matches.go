@@ -0,0 +1,325 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unified
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// typeAliases maps BSON types to their $type aliases.
+var typeAliases = map[bsontype.Type]string{
+	bsontype.Double:           "double",
+	bsontype.String:           "string",
+	bsontype.EmbeddedDocument: "object",
+	bsontype.Array:            "array",
+	bsontype.Binary:           "binData",
+	bsontype.Undefined:        "undefined",
+	bsontype.ObjectID:         "objectId",
+	bsontype.Boolean:          "bool",
+	bsontype.DateTime:         "date",
+	bsontype.Null:             "null",
+	bsontype.Regex:            "regex",
+	bsontype.DBPointer:        "dbPointer",
+	bsontype.JavaScript:       "javascript",
+	bsontype.Symbol:           "symbol",
+	bsontype.CodeWithScope:    "javascriptWithScope",
+	bsontype.Int32:            "int",
+	bsontype.Timestamp:        "timestamp",
+	bsontype.Int64:            "long",
+	bsontype.Decimal128:       "decimal",
+	bsontype.MinKey:           "minKey",
+	bsontype.MaxKey:           "maxKey",
+}
+
+// documentValue returns the document as a value.
+func documentValue(doc bson.Raw) bson.RawValue {
+	return bson.RawValue{Type: bsontype.EmbeddedDocument, Value: doc}
+}
+
+// toValue converts the given Go value to BSON value.
+func toValue(v any) (bson.RawValue, error) {
+	if v == nil {
+		return bson.RawValue{Type: bsontype.Null}, nil
+	}
+
+	t, b, err := bson.MarshalValue(v)
+	if err != nil {
+		return bson.RawValue{}, err
+	}
+
+	return bson.RawValue{Type: t, Value: b}, nil
+}
+
+// match returns an error if the actual value does not match the expected one.
+//
+// Actual value with zero type represents a missing value.
+// Root-level documents may contain extra fields; nested documents may not.
+func (r *runner) match(expected, actual bson.RawValue, root bool) error {
+	return r.matchPath("", expected, actual, root)
+}
+
+// matchPath is match that reports the path of mismatched value.
+func (r *runner) matchPath(path string, expected, actual bson.RawValue, root bool) error {
+	if doc, ok := expected.DocumentOK(); ok {
+		if op, arg, ok := specialOperator(doc); ok {
+			return r.matchOperator(path, op, arg, actual, root)
+		}
+	}
+
+	if actual.Type == 0 {
+		return fmt.Errorf("%s: expected %s, got nothing", path, expected)
+	}
+
+	if isNumber(expected) && isNumber(actual) {
+		if !numbersEqual(expected, actual) {
+			return fmt.Errorf("%s: expected %s, got %s", path, expected, actual)
+		}
+
+		return nil
+	}
+
+	if expected.Type != actual.Type {
+		return fmt.Errorf("%s: expected %s of type %s, got %s of type %s", path, expected, expected.Type, actual, actual.Type)
+	}
+
+	switch expected.Type {
+	case bsontype.EmbeddedDocument:
+		return r.matchDocument(path, expected.Document(), actual.Document(), root)
+
+	case bsontype.Array:
+		expectedValues, err := expected.Array().Values()
+		if err != nil {
+			return err
+		}
+
+		actualValues, err := actual.Array().Values()
+		if err != nil {
+			return err
+		}
+
+		if len(expectedValues) != len(actualValues) {
+			return fmt.Errorf("%s: expected %s, got %s", path, expected, actual)
+		}
+
+		// documents of root-level arrays (such as cursor results) are root-level documents too
+		for i := range expectedValues {
+			if err = r.matchPath(fmt.Sprintf("%s.%d", path, i), expectedValues[i], actualValues[i], root); err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	default:
+		if !expected.Equal(actual) {
+			return fmt.Errorf("%s: expected %s, got %s", path, expected, actual)
+		}
+
+		return nil
+	}
+}
+
+// matchDocument matches documents field by field.
+func (r *runner) matchDocument(path string, expected, actual bson.Raw, root bool) error {
+	expectedElems, err := expected.Elements()
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]struct{}, len(expectedElems))
+
+	for _, elem := range expectedElems {
+		key := elem.Key()
+		keys[key] = struct{}{}
+
+		v, err := actual.LookupErr(key)
+		if err != nil {
+			v = bson.RawValue{}
+		}
+
+		if err = r.matchPath(path+"."+key, elem.Value(), v, false); err != nil {
+			return err
+		}
+	}
+
+	if root {
+		return nil
+	}
+
+	actualElems, err := actual.Elements()
+	if err != nil {
+		return err
+	}
+
+	for _, elem := range actualElems {
+		if _, ok := keys[elem.Key()]; !ok {
+			return fmt.Errorf("%s: unexpected field %q in %s", path, elem.Key(), actual)
+		}
+	}
+
+	return nil
+}
+
+// specialOperator returns the operator and its argument if the document is a special matching operator
+// like `{$$exists: true}`.
+func specialOperator(doc bson.Raw) (string, bson.RawValue, bool) {
+	elems, err := doc.Elements()
+	if err != nil || len(elems) != 1 || !strings.HasPrefix(elems[0].Key(), "$$") {
+		return "", bson.RawValue{}, false
+	}
+
+	return elems[0].Key(), elems[0].Value(), true
+}
+
+// matchOperator matches the actual value using the special matching operator.
+func (r *runner) matchOperator(path, op string, arg, actual bson.RawValue, root bool) error {
+	switch op {
+	case "$$exists":
+		if exists := actual.Type != 0; exists != arg.Boolean() {
+			return fmt.Errorf("%s: expected exists=%t, got %s", path, arg.Boolean(), actual)
+		}
+
+		return nil
+
+	case "$$unsetOrMatches":
+		if actual.Type == 0 {
+			return nil
+		}
+
+		return r.matchPath(path, arg, actual, root)
+	}
+
+	if actual.Type == 0 {
+		return fmt.Errorf("%s: expected %s %s, got nothing", path, op, arg)
+	}
+
+	switch op {
+	case "$$type":
+		var aliases []string
+
+		if s, ok := arg.StringValueOK(); ok {
+			aliases = append(aliases, s)
+		} else {
+			values, err := arg.Array().Values()
+			if err != nil {
+				return err
+			}
+
+			for _, v := range values {
+				aliases = append(aliases, v.StringValue())
+			}
+		}
+
+		for _, alias := range aliases {
+			if typeAliases[actual.Type] == alias || (alias == "number" && isNumber(actual)) {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("%s: expected type %v, got %s of type %s", path, aliases, actual, actual.Type)
+
+	case "$$matchesEntity":
+		v, ok := r.entities.results[arg.StringValue()]
+		if !ok {
+			return fmt.Errorf("%s: entity %q not found", path, arg.StringValue())
+		}
+
+		return r.matchPath(path, v, actual, root)
+
+	case "$$matchesHexBytes":
+		b, err := hex.DecodeString(arg.StringValue())
+		if err != nil {
+			return err
+		}
+
+		if _, data, ok := actual.BinaryOK(); !ok || !bytes.Equal(b, data) {
+			return fmt.Errorf("%s: expected bytes %s, got %s", path, arg.StringValue(), actual)
+		}
+
+		return nil
+
+	case "$$sessionLsid":
+		s, ok := r.entities.sessions[arg.StringValue()]
+		if !ok {
+			return fmt.Errorf("%s: session entity %q not found", path, arg.StringValue())
+		}
+
+		return r.matchPath(path, documentValue(s.ID()), actual, false)
+
+	case "$$lte":
+		if !isNumber(actual) || numberValue(actual) > numberValue(arg) {
+			return fmt.Errorf("%s: expected value <= %s, got %s", path, arg, actual)
+		}
+
+		return nil
+
+	case "$$matchAsDocument", "$$matchAsRoot":
+		doc := actual
+
+		if s, ok := actual.StringValueOK(); ok && op == "$$matchAsDocument" {
+			var raw bson.Raw
+			if err := bson.UnmarshalExtJSON([]byte(s), false, &raw); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			doc = documentValue(raw)
+		}
+
+		return r.matchPath(path, arg, doc, op == "$$matchAsRoot")
+
+	default:
+		r.t.Skipf("special operator %s is not supported", op)
+		panic("not reached")
+	}
+}
+
+// isNumber returns true if the value is a number of any type except decimal.
+func isNumber(v bson.RawValue) bool {
+	switch v.Type {
+	case bsontype.Double, bsontype.Int32, bsontype.Int64:
+		return true
+	default:
+		return false
+	}
+}
+
+// numberValue returns the number as float64.
+func numberValue(v bson.RawValue) float64 {
+	switch v.Type {
+	case bsontype.Double:
+		return v.Double()
+	case bsontype.Int32:
+		return float64(v.Int32())
+	case bsontype.Int64:
+		return float64(v.Int64())
+	default:
+		panic(fmt.Sprintf("unexpected type %s", v.Type))
+	}
+}
+
+// numbersEqual returns true if numbers are equal regardless of their types.
+func numbersEqual(a, b bson.RawValue) bool {
+	if a.Type != bsontype.Double && b.Type != bsontype.Double {
+		return a.AsInt64() == b.AsInt64()
+	}
+
+	return numberValue(a) == numberValue(b)
+}
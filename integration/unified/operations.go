@@ -0,0 +1,1124 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unified
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// operation represents a single operation of the test case.
+type operation struct {
+	Name                 string         `bson:"name"`
+	Object               string         `bson:"object"`
+	Arguments            bson.Raw       `bson:"arguments"`
+	ExpectError          *expectedError `bson:"expectError"`
+	ExpectResult         bson.RawValue  `bson:"expectResult"`
+	SaveResultAsEntity   string         `bson:"saveResultAsEntity"`
+	IgnoreResultAndError bool           `bson:"ignoreResultAndError"`
+}
+
+// expectedError represents the expected error of the operation.
+type expectedError struct {
+	IsError            *bool         `bson:"isError"`
+	IsClientError      *bool         `bson:"isClientError"`
+	IsTimeoutError     *bool         `bson:"isTimeoutError"`
+	ErrorContains      string        `bson:"errorContains"`
+	ErrorCode          *int32        `bson:"errorCode"`
+	ErrorCodeName      string        `bson:"errorCodeName"`
+	ErrorLabelsContain []string      `bson:"errorLabelsContain"`
+	ErrorLabelsOmit    []string      `bson:"errorLabelsOmit"`
+	ExpectResult       bson.RawValue `bson:"expectResult"`
+}
+
+// expectedEvents represents events expected to be observed by the client entity.
+type expectedEvents struct {
+	Client            string     `bson:"client"`
+	EventType         string     `bson:"eventType"`
+	Events            []bson.Raw `bson:"events"`
+	IgnoreExtraEvents bool       `bson:"ignoreExtraEvents"`
+}
+
+// arguments provides access to operation's arguments and tracks which of them were used.
+type arguments struct {
+	r      *runner
+	op     string
+	values map[string]bson.RawValue
+}
+
+// newArguments parses operation's arguments.
+func (r *runner) newArguments(op string, raw bson.Raw) *arguments {
+	r.t.Helper()
+
+	args := &arguments{
+		r:      r,
+		op:     op,
+		values: map[string]bson.RawValue{},
+	}
+
+	if raw == nil {
+		return args
+	}
+
+	elems, err := raw.Elements()
+	require.NoError(r.t, err)
+
+	for _, elem := range elems {
+		args.values[elem.Key()] = elem.Value()
+	}
+
+	return args
+}
+
+// get returns the argument value and marks it as used.
+func (a *arguments) get(key string) (bson.RawValue, bool) {
+	v, ok := a.values[key]
+	delete(a.values, key)
+
+	return v, ok
+}
+
+// required returns the required argument value.
+func (a *arguments) required(key string) bson.RawValue {
+	a.r.t.Helper()
+
+	v, ok := a.get(key)
+	require.True(a.r.t, ok, "%s: missing required argument %q", a.op, key)
+
+	return v
+}
+
+// document returns the document argument, or nil if it is not set.
+func (a *arguments) document(key string) bson.Raw {
+	v, ok := a.get(key)
+	if !ok {
+		return nil
+	}
+
+	return v.Document()
+}
+
+// done skips the test if some arguments were not used.
+func (a *arguments) done() {
+	a.r.t.Helper()
+
+	if len(a.values) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(a.values))
+	for k := range a.values {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	a.r.t.Skipf("%s: arguments %s are not supported", a.op, strings.Join(keys, ", "))
+}
+
+// toInterface converts BSON value to a Go value suitable for driver's options.
+func toInterface(v bson.RawValue) any {
+	switch v.Type {
+	case bsontype.String:
+		return v.StringValue()
+	case bsontype.EmbeddedDocument:
+		return v.Document()
+	case bsontype.Array:
+		return documents(v)
+	default:
+		return v
+	}
+}
+
+// documents returns array elements as documents.
+func documents(v bson.RawValue) []bson.Raw {
+	values, err := v.Array().Values()
+	if err != nil {
+		panic(err)
+	}
+
+	res := make([]bson.Raw, len(values))
+	for i, v := range values {
+		res[i] = v.Document()
+	}
+
+	return res
+}
+
+// anyValues returns array elements as values.
+func anyValues(v bson.RawValue) []any {
+	values, err := v.Array().Values()
+	if err != nil {
+		panic(err)
+	}
+
+	res := make([]any, len(values))
+	for i, v := range values {
+		res[i] = v
+	}
+
+	return res
+}
+
+// stringComment returns the comment argument for driver's methods that accept only string comments.
+func (a *arguments) stringComment() (string, bool) {
+	a.r.t.Helper()
+
+	v, ok := a.get("comment")
+	if !ok {
+		return "", false
+	}
+
+	s, ok := v.StringValueOK()
+	if !ok {
+		a.r.t.Skipf("%s: non-string comment is not supported by the driver", a.op)
+	}
+
+	return s, true
+}
+
+// runOperation runs a single operation and checks its result.
+func (r *runner) runOperation(op *operation) {
+	r.t.Helper()
+
+	if op.Object == "testRunner" {
+		r.runTestRunnerOperation(op)
+		return
+	}
+
+	args := r.newArguments(op.Name, op.Arguments)
+	ctx := r.ctx
+
+	if v, ok := args.get("session"); ok {
+		s, ok := r.entities.sessions[v.StringValue()]
+		require.True(r.t, ok, "session entity %q not found", v.StringValue())
+
+		ctx = mongo.NewSessionContext(ctx, s)
+	}
+
+	var res any
+	var err error
+
+	if coll, ok := r.entities.collections[op.Object]; ok {
+		res, err = r.runCollectionOperation(ctx, coll, op.Name, args)
+	} else if db, ok := r.entities.databases[op.Object]; ok {
+		res, err = r.runDatabaseOperation(ctx, db, op.Name, args)
+	} else if s, ok := r.entities.sessions[op.Object]; ok {
+		res, err = r.runSessionOperation(ctx, s, op.Name, args)
+	} else if c, ok := r.entities.clients[op.Object]; ok {
+		res, err = r.runClientOperation(ctx, c.client, op.Name, args)
+	} else {
+		require.Fail(r.t, "entity not found", "%s", op.Object)
+	}
+
+	if op.IgnoreResultAndError {
+		return
+	}
+
+	if op.ExpectError != nil {
+		r.checkError(op.ExpectError, res, err)
+		return
+	}
+
+	require.NoError(r.t, err, "operation %s", op.Name)
+
+	actual, err := toValue(res)
+	require.NoError(r.t, err)
+
+	if op.ExpectResult.Type != 0 {
+		err = r.match(op.ExpectResult, actual, true)
+		require.NoError(r.t, err, "operation %s result %s", op.Name, actual)
+	}
+
+	if op.SaveResultAsEntity != "" {
+		r.entities.results[op.SaveResultAsEntity] = actual
+	}
+}
+
+// checkError checks that the operation returned the expected error.
+func (r *runner) checkError(expected *expectedError, res any, err error) {
+	r.t.Helper()
+
+	require.Error(r.t, err, "expected error, got result %v", res)
+
+	var se mongo.ServerError
+	isServerError := errors.As(err, &se)
+
+	if expected.IsClientError != nil {
+		isClientError := !isServerError && !mongo.IsNetworkError(err)
+		require.Equal(r.t, *expected.IsClientError, isClientError, "isClientError: %v", err)
+	}
+
+	if expected.IsTimeoutError != nil {
+		require.Equal(r.t, *expected.IsTimeoutError, mongo.IsTimeout(err), "isTimeoutError: %v", err)
+	}
+
+	if expected.ErrorContains != "" {
+		require.Contains(r.t, strings.ToLower(err.Error()), strings.ToLower(expected.ErrorContains))
+	}
+
+	if expected.ErrorCode != nil {
+		require.True(r.t, isServerError && se.HasErrorCode(int(*expected.ErrorCode)), "expected code %d: %v", *expected.ErrorCode, err)
+	}
+
+	if expected.ErrorCodeName != "" {
+		require.Contains(r.t, errorCodeNames(err), expected.ErrorCodeName, "%v", err)
+	}
+
+	for _, label := range expected.ErrorLabelsContain {
+		require.True(r.t, isServerError && se.HasErrorLabel(label), "expected label %q: %v", label, err)
+	}
+
+	for _, label := range expected.ErrorLabelsOmit {
+		require.False(r.t, isServerError && se.HasErrorLabel(label), "unexpected label %q: %v", label, err)
+	}
+
+	if expected.ExpectResult.Type != 0 {
+		actual, err := toValue(res)
+		require.NoError(r.t, err)
+		require.NoError(r.t, r.match(expected.ExpectResult, actual, true))
+	}
+}
+
+// errorCodeNames returns all error code names of the server error.
+func errorCodeNames(err error) []string {
+	var res []string
+
+	var ce mongo.CommandError
+	if errors.As(err, &ce) {
+		res = append(res, ce.Name)
+	}
+
+	var we mongo.WriteException
+	if errors.As(err, &we) && we.WriteConcernError != nil {
+		res = append(res, we.WriteConcernError.Name)
+	}
+
+	var bwe mongo.BulkWriteException
+	if errors.As(err, &bwe) && bwe.WriteConcernError != nil {
+		res = append(res, bwe.WriteConcernError.Name)
+	}
+
+	return res
+}
+
+// runCollectionOperation runs operation on the collection entity.
+func (r *runner) runCollectionOperation(ctx context.Context, coll *mongo.Collection, name string, args *arguments) (any, error) { //nolint:lll // for readability
+	r.t.Helper()
+
+	switch name {
+	case "aggregate":
+		pipeline, opts := r.aggregateArgs(args)
+		return aggregate(ctx, coll.Aggregate, pipeline, opts)
+
+	case "bulkWrite":
+		return r.bulkWrite(ctx, coll, args)
+
+	case "countDocuments":
+		filter := args.document("filter")
+		opts := options.Count()
+
+		if v, ok := args.get("skip"); ok {
+			opts.SetSkip(v.AsInt64())
+		}
+
+		if v, ok := args.get("limit"); ok {
+			opts.SetLimit(v.AsInt64())
+		}
+
+		if v, ok := args.get("hint"); ok {
+			opts.SetHint(toInterface(v))
+		}
+
+		if s, ok := args.stringComment(); ok {
+			opts.SetComment(s)
+		}
+
+		args.done()
+
+		return coll.CountDocuments(ctx, filter, opts)
+
+	case "estimatedDocumentCount":
+		opts := options.EstimatedDocumentCount()
+
+		if v, ok := args.get("comment"); ok {
+			opts.SetComment(v)
+		}
+
+		if v, ok := args.get("maxTimeMS"); ok {
+			opts.SetMaxTime(time.Duration(v.AsInt64()) * time.Millisecond)
+		}
+
+		args.done()
+
+		return coll.EstimatedDocumentCount(ctx, opts)
+
+	case "distinct":
+		field := args.required("fieldName").StringValue()
+		filter := args.document("filter")
+		opts := options.Distinct()
+
+		if v, ok := args.get("comment"); ok {
+			opts.SetComment(v)
+		}
+
+		args.done()
+
+		return coll.Distinct(ctx, field, filter, opts)
+
+	case "find":
+		filter := args.document("filter")
+		opts := options.Find()
+
+		if v, ok := args.get("sort"); ok {
+			opts.SetSort(v.Document())
+		}
+
+		if v, ok := args.get("projection"); ok {
+			opts.SetProjection(v.Document())
+		}
+
+		if v, ok := args.get("skip"); ok {
+			opts.SetSkip(v.AsInt64())
+		}
+
+		if v, ok := args.get("limit"); ok {
+			opts.SetLimit(v.AsInt64())
+		}
+
+		if v, ok := args.get("batchSize"); ok {
+			opts.SetBatchSize(int32(v.AsInt64()))
+		}
+
+		if v, ok := args.get("allowDiskUse"); ok {
+			opts.SetAllowDiskUse(v.Boolean())
+		}
+
+		if v, ok := args.get("hint"); ok {
+			opts.SetHint(toInterface(v))
+		}
+
+		if v, ok := args.get("let"); ok {
+			opts.SetLet(v.Document())
+		}
+
+		if s, ok := args.stringComment(); ok {
+			opts.SetComment(s)
+		}
+
+		args.done()
+
+		cursor, err := coll.Find(ctx, filter, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return allDocuments(ctx, cursor)
+
+	case "findOneAndDelete":
+		filter := args.required("filter").Document()
+		opts := options.FindOneAndDelete()
+
+		if v, ok := args.get("sort"); ok {
+			opts.SetSort(v.Document())
+		}
+
+		if v, ok := args.get("projection"); ok {
+			opts.SetProjection(v.Document())
+		}
+
+		if v, ok := args.get("hint"); ok {
+			opts.SetHint(toInterface(v))
+		}
+
+		if v, ok := args.get("let"); ok {
+			opts.SetLet(v.Document())
+		}
+
+		if v, ok := args.get("comment"); ok {
+			opts.SetComment(v)
+		}
+
+		args.done()
+
+		return singleResult(coll.FindOneAndDelete(ctx, filter, opts))
+
+	case "findOneAndReplace":
+		filter := args.required("filter").Document()
+		replacement := args.required("replacement").Document()
+		opts := options.FindOneAndReplace()
+
+		if v, ok := args.get("sort"); ok {
+			opts.SetSort(v.Document())
+		}
+
+		if v, ok := args.get("projection"); ok {
+			opts.SetProjection(v.Document())
+		}
+
+		if v, ok := args.get("upsert"); ok {
+			opts.SetUpsert(v.Boolean())
+		}
+
+		if v, ok := args.get("returnDocument"); ok {
+			opts.SetReturnDocument(returnDocument(v))
+		}
+
+		if v, ok := args.get("hint"); ok {
+			opts.SetHint(toInterface(v))
+		}
+
+		if v, ok := args.get("let"); ok {
+			opts.SetLet(v.Document())
+		}
+
+		if v, ok := args.get("comment"); ok {
+			opts.SetComment(v)
+		}
+
+		args.done()
+
+		return singleResult(coll.FindOneAndReplace(ctx, filter, replacement, opts))
+
+	case "findOneAndUpdate":
+		filter := args.required("filter").Document()
+		update := toInterface(args.required("update"))
+		opts := options.FindOneAndUpdate()
+
+		if v, ok := args.get("sort"); ok {
+			opts.SetSort(v.Document())
+		}
+
+		if v, ok := args.get("projection"); ok {
+			opts.SetProjection(v.Document())
+		}
+
+		if v, ok := args.get("upsert"); ok {
+			opts.SetUpsert(v.Boolean())
+		}
+
+		if v, ok := args.get("returnDocument"); ok {
+			opts.SetReturnDocument(returnDocument(v))
+		}
+
+		if v, ok := args.get("arrayFilters"); ok {
+			opts.SetArrayFilters(options.ArrayFilters{Filters: anyValues(v)})
+		}
+
+		if v, ok := args.get("hint"); ok {
+			opts.SetHint(toInterface(v))
+		}
+
+		if v, ok := args.get("let"); ok {
+			opts.SetLet(v.Document())
+		}
+
+		if v, ok := args.get("comment"); ok {
+			opts.SetComment(v)
+		}
+
+		args.done()
+
+		return singleResult(coll.FindOneAndUpdate(ctx, filter, update, opts))
+
+	case "insertOne":
+		doc := args.required("document").Document()
+		opts := options.InsertOne()
+
+		if v, ok := args.get("comment"); ok {
+			opts.SetComment(v)
+		}
+
+		args.done()
+
+		res, err := coll.InsertOne(ctx, doc, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return bson.D{{"insertedId", res.InsertedID}}, nil
+
+	case "insertMany":
+		docs := documents(args.required("documents"))
+		opts := options.InsertMany()
+
+		if v, ok := args.get("ordered"); ok {
+			opts.SetOrdered(v.Boolean())
+		}
+
+		if v, ok := args.get("comment"); ok {
+			opts.SetComment(v)
+		}
+
+		args.done()
+
+		in := make([]any, len(docs))
+		for i, doc := range docs {
+			in[i] = doc
+		}
+
+		res, err := coll.InsertMany(ctx, in, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		ids := make(bson.D, len(res.InsertedIDs))
+		for i, id := range res.InsertedIDs {
+			ids[i] = bson.E{Key: strconv.Itoa(i), Value: id}
+		}
+
+		return bson.D{{"insertedIds", ids}}, nil
+
+	case "deleteOne", "deleteMany":
+		filter := args.required("filter").Document()
+		opts := options.Delete()
+
+		if v, ok := args.get("hint"); ok {
+			opts.SetHint(toInterface(v))
+		}
+
+		if v, ok := args.get("let"); ok {
+			opts.SetLet(v.Document())
+		}
+
+		if v, ok := args.get("comment"); ok {
+			opts.SetComment(v)
+		}
+
+		args.done()
+
+		f := coll.DeleteOne
+		if name == "deleteMany" {
+			f = coll.DeleteMany
+		}
+
+		res, err := f(ctx, filter, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return bson.D{{"deletedCount", res.DeletedCount}}, nil
+
+	case "updateOne", "updateMany", "replaceOne":
+		filter := args.required("filter").Document()
+
+		var update any
+		if name == "replaceOne" {
+			update = args.required("replacement").Document()
+		} else {
+			update = toInterface(args.required("update"))
+		}
+
+		opts := options.Update()
+
+		if v, ok := args.get("upsert"); ok {
+			opts.SetUpsert(v.Boolean())
+		}
+
+		if v, ok := args.get("arrayFilters"); ok {
+			opts.SetArrayFilters(options.ArrayFilters{Filters: anyValues(v)})
+		}
+
+		if v, ok := args.get("hint"); ok {
+			opts.SetHint(toInterface(v))
+		}
+
+		if v, ok := args.get("let"); ok {
+			opts.SetLet(v.Document())
+		}
+
+		if v, ok := args.get("comment"); ok {
+			opts.SetComment(v)
+		}
+
+		args.done()
+
+		var res *mongo.UpdateResult
+		var err error
+
+		switch name {
+		case "updateOne":
+			res, err = coll.UpdateOne(ctx, filter, update, opts)
+		case "updateMany":
+			res, err = coll.UpdateMany(ctx, filter, update, opts)
+		default:
+			res, err = coll.ReplaceOne(ctx, filter, update, replaceOptions(opts))
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		doc := bson.D{
+			{"matchedCount", res.MatchedCount},
+			{"modifiedCount", res.ModifiedCount},
+			{"upsertedCount", res.UpsertedCount},
+		}
+
+		if res.UpsertedID != nil {
+			doc = append(doc, bson.E{"upsertedId", res.UpsertedID})
+		}
+
+		return doc, nil
+
+	case "createIndex":
+		keys := args.required("keys").Document()
+		opts := options.Index()
+
+		if v, ok := args.get("name"); ok {
+			opts.SetName(v.StringValue())
+		}
+
+		if v, ok := args.get("unique"); ok {
+			opts.SetUnique(v.Boolean())
+		}
+
+		args.done()
+
+		return coll.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: keys, Options: opts})
+
+	case "listIndexes":
+		args.done()
+
+		cursor, err := coll.Indexes().List(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return allDocuments(ctx, cursor)
+
+	case "drop":
+		args.done()
+		return nil, coll.Drop(ctx)
+
+	default:
+		r.t.Skipf("collection operation %q is not supported", name)
+		panic("not reached")
+	}
+}
+
+// aggregateArgs returns pipeline and options of aggregate operation.
+func (r *runner) aggregateArgs(args *arguments) ([]bson.Raw, *options.AggregateOptions) {
+	r.t.Helper()
+
+	pipeline := documents(args.required("pipeline"))
+	opts := options.Aggregate()
+
+	if v, ok := args.get("batchSize"); ok {
+		opts.SetBatchSize(int32(v.AsInt64()))
+	}
+
+	if v, ok := args.get("allowDiskUse"); ok {
+		opts.SetAllowDiskUse(v.Boolean())
+	}
+
+	if v, ok := args.get("hint"); ok {
+		opts.SetHint(toInterface(v))
+	}
+
+	if v, ok := args.get("let"); ok {
+		opts.SetLet(v.Document())
+	}
+
+	if s, ok := args.stringComment(); ok {
+		opts.SetComment(s)
+	}
+
+	if v, ok := args.get("maxTimeMS"); ok {
+		opts.SetMaxTime(time.Duration(v.AsInt64()) * time.Millisecond)
+	}
+
+	args.done()
+
+	return pipeline, opts
+}
+
+// aggregate runs the aggregation using collection's or database's Aggregate method.
+func aggregate(ctx context.Context, f func(context.Context, any, ...*options.AggregateOptions) (*mongo.Cursor, error), pipeline []bson.Raw, opts *options.AggregateOptions) (any, error) { //nolint:lll // for readability
+	cursor, err := f(ctx, pipeline, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return allDocuments(ctx, cursor)
+}
+
+// allDocuments returns all cursor's documents as BSON array.
+func allDocuments(ctx context.Context, cursor *mongo.Cursor) (any, error) {
+	res := bson.A{}
+
+	var docs []bson.Raw
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	for _, doc := range docs {
+		res = append(res, doc)
+	}
+
+	return res, nil
+}
+
+// singleResult returns the document of findOneAnd* operations, or null if there is no document.
+func singleResult(res *mongo.SingleResult) (any, error) {
+	doc, err := res.DecodeBytes()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// returnDocument returns returnDocument option value.
+func returnDocument(v bson.RawValue) options.ReturnDocument {
+	if v.StringValue() == "After" {
+		return options.After
+	}
+
+	return options.Before
+}
+
+// replaceOptions converts update options to replace options.
+func replaceOptions(opts *options.UpdateOptions) *options.ReplaceOptions {
+	return &options.ReplaceOptions{
+		Upsert:  opts.Upsert,
+		Hint:    opts.Hint,
+		Let:     opts.Let,
+		Comment: opts.Comment,
+	}
+}
+
+// bulkWrite runs bulkWrite operation.
+func (r *runner) bulkWrite(ctx context.Context, coll *mongo.Collection, args *arguments) (any, error) {
+	r.t.Helper()
+
+	requests, err := args.required("requests").Array().Values()
+	require.NoError(r.t, err)
+
+	opts := options.BulkWrite()
+
+	if v, ok := args.get("ordered"); ok {
+		opts.SetOrdered(v.Boolean())
+	}
+
+	if v, ok := args.get("let"); ok {
+		opts.SetLet(v.Document())
+	}
+
+	if v, ok := args.get("comment"); ok {
+		opts.SetComment(v)
+	}
+
+	args.done()
+
+	models := make([]mongo.WriteModel, len(requests))
+
+	for i, req := range requests {
+		elems, err := req.Document().Elements()
+		require.NoError(r.t, err)
+		require.Len(r.t, elems, 1)
+
+		name := elems[0].Key()
+		reqArgs := r.newArguments("bulkWrite "+name, elems[0].Value().Document())
+
+		switch name {
+		case "insertOne":
+			models[i] = mongo.NewInsertOneModel().SetDocument(reqArgs.required("document").Document())
+
+		case "deleteOne", "deleteMany":
+			filter := reqArgs.required("filter").Document()
+
+			if name == "deleteOne" {
+				m := mongo.NewDeleteOneModel().SetFilter(filter)
+				if v, ok := reqArgs.get("hint"); ok {
+					m.SetHint(toInterface(v))
+				}
+
+				models[i] = m
+			} else {
+				m := mongo.NewDeleteManyModel().SetFilter(filter)
+				if v, ok := reqArgs.get("hint"); ok {
+					m.SetHint(toInterface(v))
+				}
+
+				models[i] = m
+			}
+
+		case "replaceOne":
+			m := mongo.NewReplaceOneModel().
+				SetFilter(reqArgs.required("filter").Document()).
+				SetReplacement(reqArgs.required("replacement").Document())
+
+			if v, ok := reqArgs.get("upsert"); ok {
+				m.SetUpsert(v.Boolean())
+			}
+
+			if v, ok := reqArgs.get("hint"); ok {
+				m.SetHint(toInterface(v))
+			}
+
+			models[i] = m
+
+		case "updateOne", "updateMany":
+			filter := reqArgs.required("filter").Document()
+			update := toInterface(reqArgs.required("update"))
+
+			if name == "updateOne" {
+				m := mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update)
+
+				if v, ok := reqArgs.get("upsert"); ok {
+					m.SetUpsert(v.Boolean())
+				}
+
+				if v, ok := reqArgs.get("arrayFilters"); ok {
+					m.SetArrayFilters(options.ArrayFilters{Filters: anyValues(v)})
+				}
+
+				if v, ok := reqArgs.get("hint"); ok {
+					m.SetHint(toInterface(v))
+				}
+
+				models[i] = m
+			} else {
+				m := mongo.NewUpdateManyModel().SetFilter(filter).SetUpdate(update)
+
+				if v, ok := reqArgs.get("upsert"); ok {
+					m.SetUpsert(v.Boolean())
+				}
+
+				if v, ok := reqArgs.get("arrayFilters"); ok {
+					m.SetArrayFilters(options.ArrayFilters{Filters: anyValues(v)})
+				}
+
+				if v, ok := reqArgs.get("hint"); ok {
+					m.SetHint(toInterface(v))
+				}
+
+				models[i] = m
+			}
+
+		default:
+			r.t.Skipf("bulkWrite request %q is not supported", name)
+		}
+
+		reqArgs.done()
+	}
+
+	res, err := coll.BulkWrite(ctx, models, opts)
+	if res == nil {
+		return nil, err
+	}
+
+	indexes := make([]int64, 0, len(res.UpsertedIDs))
+	for i := range res.UpsertedIDs {
+		indexes = append(indexes, i)
+	}
+
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+
+	upserted := bson.D{}
+	for _, i := range indexes {
+		upserted = append(upserted, bson.E{strconv.FormatInt(i, 10), res.UpsertedIDs[i]})
+	}
+
+	return bson.D{
+		{"deletedCount", res.DeletedCount},
+		{"insertedCount", res.InsertedCount},
+		{"matchedCount", res.MatchedCount},
+		{"modifiedCount", res.ModifiedCount},
+		{"upsertedCount", res.UpsertedCount},
+		{"upsertedIds", upserted},
+	}, err
+}
+
+// runDatabaseOperation runs operation on the database entity.
+func (r *runner) runDatabaseOperation(ctx context.Context, db *mongo.Database, name string, args *arguments) (any, error) { //nolint:lll // for readability
+	r.t.Helper()
+
+	switch name {
+	case "aggregate":
+		pipeline, opts := r.aggregateArgs(args)
+		return aggregate(ctx, db.Aggregate, pipeline, opts)
+
+	case "runCommand":
+		command := args.required("command").Document()
+		args.get("commandName")
+		args.done()
+
+		return db.RunCommand(ctx, command).DecodeBytes()
+
+	case "createCollection":
+		coll := args.required("collection").StringValue()
+		args.done()
+
+		return nil, db.CreateCollection(ctx, coll)
+
+	case "dropCollection":
+		coll := args.required("collection").StringValue()
+		args.done()
+
+		return nil, db.Collection(coll).Drop(ctx)
+
+	case "listCollections":
+		filter := args.document("filter")
+		if filter == nil {
+			filter = bson.Raw(must.NotFail(bson.Marshal(bson.D{})))
+		}
+
+		args.done()
+
+		cursor, err := db.ListCollections(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		return allDocuments(ctx, cursor)
+
+	default:
+		r.t.Skipf("database operation %q is not supported", name)
+		panic("not reached")
+	}
+}
+
+// runClientOperation runs operation on the client entity.
+func (r *runner) runClientOperation(ctx context.Context, client *mongo.Client, name string, args *arguments) (any, error) { //nolint:lll // for readability
+	r.t.Helper()
+
+	switch name {
+	case "listDatabases":
+		args.done()
+
+		res, err := client.ListDatabases(ctx, bson.D{})
+		if err != nil {
+			return nil, err
+		}
+
+		return res.Databases, nil
+
+	default:
+		r.t.Skipf("client operation %q is not supported", name)
+		panic("not reached")
+	}
+}
+
+// runSessionOperation runs operation on the session entity.
+func (r *runner) runSessionOperation(ctx context.Context, s mongo.Session, name string, args *arguments) (any, error) { //nolint:lll // for readability
+	r.t.Helper()
+
+	args.done()
+
+	switch name {
+	case "startTransaction":
+		return nil, s.StartTransaction()
+	case "commitTransaction":
+		return nil, s.CommitTransaction(ctx)
+	case "abortTransaction":
+		return nil, s.AbortTransaction(ctx)
+	case "endSession":
+		s.EndSession(ctx)
+		return nil, nil
+	default:
+		r.t.Skipf("session operation %q is not supported", name)
+		panic("not reached")
+	}
+}
+
+// runTestRunnerOperation runs special test runner operation.
+func (r *runner) runTestRunnerOperation(op *operation) {
+	r.t.Helper()
+
+	args := r.newArguments(op.Name, op.Arguments)
+
+	switch op.Name {
+	case "failPoint":
+		args.required("client")
+		failPoint := args.required("failPoint").Document()
+		args.done()
+
+		r.configureFailPoint(failPoint)
+
+	case "assertSameLsidOnLastTwoCommands", "assertDifferentLsidOnLastTwoCommands":
+		client := r.entities.client(r.t, args.required("client").StringValue())
+		args.done()
+
+		var started []commandEvent
+
+		for _, e := range client.observedEvents() {
+			if e.typ == "commandStartedEvent" {
+				started = append(started, e)
+			}
+		}
+
+		require.GreaterOrEqual(r.t, len(started), 2)
+
+		first := started[len(started)-2].command.Lookup("lsid")
+		second := started[len(started)-1].command.Lookup("lsid")
+
+		if op.Name == "assertSameLsidOnLastTwoCommands" {
+			require.True(r.t, first.Equal(second), "%s != %s", first, second)
+		} else {
+			require.False(r.t, first.Equal(second), "%s == %s", first, second)
+		}
+
+	default:
+		r.t.Skipf("test runner operation %q is not supported", op.Name)
+	}
+}
+
+// configureFailPoint configures failpoint using the internal client,
+// and disables it when the test finishes.
+//
+// The test is skipped if failpoints are not available.
+func (r *runner) configureFailPoint(failPoint bson.Raw) {
+	r.t.Helper()
+
+	admin := r.opts.Client.Database("admin")
+
+	err := admin.RunCommand(r.ctx, failPoint).Err()
+
+	var ce mongo.CommandError
+	if errors.As(err, &ce) && ce.Code == 59 {
+		r.t.Skip("failpoints are available only in debug builds")
+	}
+
+	if errors.As(err, &ce) && ce.Code == 2 {
+		r.t.Skipf("failpoint is not supported: %s", ce.Message)
+	}
+
+	require.NoError(r.t, err)
+
+	name := failPoint.Lookup("configureFailPoint").StringValue()
+
+	r.t.Cleanup(func() {
+		err := admin.RunCommand(context.Background(), bson.D{{"configureFailPoint", name}, {"mode", "off"}}).Err()
+		require.NoError(r.t, err)
+	})
+}
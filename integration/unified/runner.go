@@ -0,0 +1,161 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unified
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// runner runs a single test case.
+type runner struct {
+	t        *testing.T
+	ctx      context.Context
+	opts     *RunOpts
+	entities *entities
+}
+
+// run runs the given test case of the given file.
+func (r *runner) run(tf *testFile, tc *testCase) {
+	r.entities = newEntities()
+	r.t.Cleanup(func() {
+		r.entities.close(context.Background())
+	})
+
+	for _, raw := range tf.CreateEntities {
+		r.createEntity(raw)
+	}
+
+	for _, data := range tf.InitialData {
+		r.insertInitialData(&data)
+	}
+
+	for i := range tc.Operations {
+		r.runOperation(&tc.Operations[i])
+	}
+
+	for i := range tc.ExpectEvents {
+		r.checkEvents(&tc.ExpectEvents[i])
+	}
+
+	for _, data := range tc.Outcome {
+		r.checkOutcome(&data)
+	}
+}
+
+// insertInitialData replaces collection's documents with the given ones using the internal client.
+func (r *runner) insertInitialData(data *collectionData) {
+	r.t.Helper()
+
+	db := r.opts.Client.Database(data.DatabaseName)
+	coll := db.Collection(data.CollectionName)
+
+	require.NoError(r.t, coll.Drop(r.ctx))
+
+	if len(data.Documents) == 0 {
+		require.NoError(r.t, db.CreateCollection(r.ctx, data.CollectionName))
+		return
+	}
+
+	docs := make([]any, len(data.Documents))
+	for i, doc := range data.Documents {
+		docs[i] = doc
+	}
+
+	_, err := coll.InsertMany(r.ctx, docs)
+	require.NoError(r.t, err)
+}
+
+// checkOutcome checks that collection contains exactly the given documents using the internal client.
+func (r *runner) checkOutcome(data *collectionData) {
+	r.t.Helper()
+
+	coll := r.opts.Client.Database(data.DatabaseName).Collection(data.CollectionName)
+
+	cursor, err := coll.Find(r.ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+	require.NoError(r.t, err)
+
+	var actual []bson.Raw
+	require.NoError(r.t, cursor.All(r.ctx, &actual))
+
+	require.Len(
+		r.t, actual, len(data.Documents),
+		"unexpected documents in %s.%s: %v", data.DatabaseName, data.CollectionName, actual,
+	)
+
+	for i, expected := range data.Documents {
+		err = r.match(documentValue(expected), documentValue(actual[i]), false)
+		require.NoError(r.t, err, "outcome %s.%s document %d", data.DatabaseName, data.CollectionName, i)
+	}
+}
+
+// checkEvents checks events observed by the client entity.
+func (r *runner) checkEvents(expected *expectedEvents) {
+	r.t.Helper()
+
+	if expected.EventType != "" && expected.EventType != "command" {
+		r.t.Skipf("%s events are not supported", expected.EventType)
+	}
+
+	client := r.entities.client(r.t, expected.Client)
+	actual := client.observedEvents()
+
+	if expected.IgnoreExtraEvents {
+		require.GreaterOrEqual(r.t, len(actual), len(expected.Events), "events: %v", actual)
+		actual = actual[:len(expected.Events)]
+	} else {
+		require.Len(r.t, actual, len(expected.Events), "events: %v", actual)
+	}
+
+	for i, raw := range expected.Events {
+		elems, err := raw.Elements()
+		require.NoError(r.t, err)
+		require.Len(r.t, elems, 1)
+
+		typ := elems[0].Key()
+		require.Equal(r.t, typ, actual[i].typ, "event %d: %v", i, actual[i])
+
+		var spec struct {
+			Command      bson.Raw `bson:"command"`
+			Reply        bson.Raw `bson:"reply"`
+			CommandName  string   `bson:"commandName"`
+			DatabaseName string   `bson:"databaseName"`
+		}
+
+		require.NoError(r.t, elems[0].Value().Unmarshal(&spec))
+
+		if spec.CommandName != "" {
+			require.Equal(r.t, spec.CommandName, actual[i].commandName, "event %d", i)
+		}
+
+		if spec.DatabaseName != "" {
+			require.Equal(r.t, spec.DatabaseName, actual[i].databaseName, "event %d", i)
+		}
+
+		if spec.Command != nil {
+			err = r.match(documentValue(spec.Command), documentValue(actual[i].command), true)
+			require.NoError(r.t, err, "event %d command %s", i, actual[i].command)
+		}
+
+		if spec.Reply != nil {
+			err = r.match(documentValue(spec.Reply), documentValue(actual[i].reply), true)
+			require.NoError(r.t, err, "event %d reply %s", i, actual[i].reply)
+		}
+	}
+}
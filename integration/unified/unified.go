@@ -0,0 +1,268 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package unified runs MongoDB drivers' unified test format spec tests against FerretDB.
+//
+// Only a subset of the format that is used by CRUD, retryable writes, and sessions tests is supported.
+// Tests that use unsupported features (entities, operations, arguments, events, etc.) are skipped
+// with a reason, so they are visible in the test output.
+//
+// See https://github.com/mongodb/specifications/blob/master/source/unified-test-format/unified-test-format.rst.
+package unified
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RunOpts represents Run options.
+type RunOpts struct {
+	// MongoDBURI is used to create client entities.
+	MongoDBURI string
+
+	// Client is an internal client used to prepare and check data,
+	// and to configure failpoints.
+	Client *mongo.Client
+
+	// Skip maps test file names (without extension) or "<file name>/<test description>"
+	// to the reasons why those files or tests are skipped.
+	Skip map[string]string
+}
+
+// testFile represents a single unified test format file.
+type testFile struct {
+	Description       string             `bson:"description"`
+	SchemaVersion     string             `bson:"schemaVersion"`
+	RunOnRequirements []runOnRequirement `bson:"runOnRequirements"`
+	CreateEntities    []bson.Raw         `bson:"createEntities"`
+	InitialData       []collectionData   `bson:"initialData"`
+	Tests             []testCase         `bson:"tests"`
+}
+
+// runOnRequirement represents a single requirement of the test file or test case.
+type runOnRequirement struct {
+	MinServerVersion string   `bson:"minServerVersion"`
+	MaxServerVersion string   `bson:"maxServerVersion"`
+	Topologies       []string `bson:"topologies"`
+	ServerParameters bson.Raw `bson:"serverParameters"`
+	Auth             *bool    `bson:"auth"`
+	Serverless       string   `bson:"serverless"`
+	CSFLE            *bool    `bson:"csfle"`
+}
+
+// collectionData represents documents of a collection, used for initial data and expected outcome.
+type collectionData struct {
+	CollectionName string     `bson:"collectionName"`
+	DatabaseName   string     `bson:"databaseName"`
+	Documents      []bson.Raw `bson:"documents"`
+}
+
+// testCase represents a single test of the test file.
+type testCase struct {
+	Description       string             `bson:"description"`
+	RunOnRequirements []runOnRequirement `bson:"runOnRequirements"`
+	SkipReason        string             `bson:"skipReason"`
+	Operations        []operation        `bson:"operations"`
+	ExpectEvents      []expectedEvents   `bson:"expectEvents"`
+	Outcome           []collectionData   `bson:"outcome"`
+}
+
+// Run runs all unified test format files in the given directory as subtests.
+//
+// Files and tests are run sequentially because they use fixed database and collection names.
+func Run(t *testing.T, ctx context.Context, dir string, opts *RunOpts) {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	version := serverVersion(t, ctx, opts.Client)
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+
+		t.Run(name, func(t *testing.T) {
+			if reason, ok := opts.Skip[name]; ok {
+				t.Skip(reason)
+			}
+
+			b, err := os.ReadFile(file)
+			require.NoError(t, err)
+
+			var tf testFile
+			require.NoError(t, bson.UnmarshalExtJSON(b, false, &tf))
+
+			if !strings.HasPrefix(tf.SchemaVersion, "1.") {
+				t.Skipf("unsupported schema version %s", tf.SchemaVersion)
+			}
+
+			if reason := checkRunOn(tf.RunOnRequirements, version); reason != "" {
+				t.Skip(reason)
+			}
+
+			for _, tc := range tf.Tests {
+				tc := tc
+
+				t.Run(tc.Description, func(t *testing.T) {
+					if reason, ok := opts.Skip[name+"/"+tc.Description]; ok {
+						t.Skip(reason)
+					}
+
+					if tc.SkipReason != "" {
+						t.Skip(tc.SkipReason)
+					}
+
+					if reason := checkRunOn(tc.RunOnRequirements, version); reason != "" {
+						t.Skip(reason)
+					}
+
+					r := &runner{
+						t:    t,
+						ctx:  ctx,
+						opts: opts,
+					}
+
+					r.run(&tf, &tc)
+				})
+			}
+		})
+	}
+}
+
+// serverVersion returns the version of the target system.
+func serverVersion(t *testing.T, ctx context.Context, client *mongo.Client) []int {
+	t.Helper()
+
+	var res struct {
+		Version string `bson:"version"`
+	}
+
+	err := client.Database("admin").RunCommand(ctx, bson.D{{"buildInfo", 1}}).Decode(&res)
+	require.NoError(t, err)
+
+	return parseVersion(res.Version)
+}
+
+// parseVersion parses version string like "6.0.42" into its numeric parts.
+func parseVersion(s string) []int {
+	parts := strings.Split(s, ".")
+	res := make([]int, len(parts))
+
+	for i, p := range parts {
+		res[i], _ = strconv.Atoi(p)
+	}
+
+	return res
+}
+
+// compareVersions returns -1, 0, or 1 if version a is less than, equal to, or greater than b.
+// Missing parts are treated as zeros.
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+
+		if i < len(a) {
+			x = a[i]
+		}
+
+		if i < len(b) {
+			y = b[i]
+		}
+
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// checkRunOn returns an empty string if at least one of requirements is met (or there are no requirements),
+// and the skip reason otherwise.
+func checkRunOn(reqs []runOnRequirement, version []int) string {
+	if len(reqs) == 0 {
+		return ""
+	}
+
+	var reasons []string
+
+	for _, req := range reqs {
+		reason := req.check(version)
+		if reason == "" {
+			return ""
+		}
+
+		reasons = append(reasons, reason)
+	}
+
+	return "run on requirements are not met: " + strings.Join(reasons, "; ")
+}
+
+// check returns an empty string if requirement is met, and the reason otherwise.
+//
+// FerretDB is always a single server without authentication requirements.
+func (req *runOnRequirement) check(version []int) string {
+	if req.MinServerVersion != "" && compareVersions(version, parseVersion(req.MinServerVersion)) < 0 {
+		return "minServerVersion " + req.MinServerVersion
+	}
+
+	if req.MaxServerVersion != "" && compareVersions(version, parseVersion(req.MaxServerVersion)) > 0 {
+		return "maxServerVersion " + req.MaxServerVersion
+	}
+
+	if len(req.Topologies) > 0 {
+		var single bool
+
+		for _, topology := range req.Topologies {
+			if topology == "single" {
+				single = true
+				break
+			}
+		}
+
+		if !single {
+			return "topologies " + strings.Join(req.Topologies, ", ")
+		}
+	}
+
+	if req.ServerParameters != nil {
+		return "serverParameters are not supported"
+	}
+
+	if req.Auth != nil && *req.Auth {
+		return "auth is required"
+	}
+
+	if req.Serverless == "require" {
+		return "serverless is required"
+	}
+
+	if req.CSFLE != nil && *req.CSFLE {
+		return "CSFLE is required"
+	}
+
+	return ""
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/unified"
+)
+
+// testUnified runs unified format spec tests from the given directory.
+//
+// Tests from skip are skipped for FerretDB, but not for MongoDB.
+func testUnified(t *testing.T, dir string, skip map[string]string) {
+	t.Helper()
+
+	s := setup.SetupWithOpts(t, nil)
+
+	if setup.IsMongoDB(t) {
+		skip = nil
+	}
+
+	unified.Run(t, s.Ctx, dir, &unified.RunOpts{
+		MongoDBURI: s.MongoDBURI,
+		Client:     s.Collection.Database().Client(),
+		Skip:       skip,
+	})
+}
+
+func TestUnifiedCRUD(t *testing.T) {
+	t.Parallel()
+
+	const (
+		letReason             = "let parameter is not supported"
+		dotsAndDollarsReason  = "Dots and dollars in field names are not supported"
+		documentCommentReason = "Non-string comments are not supported"
+		pipelineUpdateReason  = "Updates with aggregation pipeline are not supported"
+	)
+
+	skip := map[string]string{
+		"aggregate-let":                         letReason,
+		"bulkWrite-deleteMany-let":              letReason,
+		"bulkWrite-deleteOne-let":               letReason,
+		"bulkWrite-replaceOne-let":              letReason,
+		"bulkWrite-updateMany-let":              letReason,
+		"bulkWrite-updateOne-let":               letReason,
+		"deleteMany-let":                        letReason,
+		"deleteOne-let":                         letReason,
+		"find-let":                              letReason,
+		"findOneAndDelete-let":                  letReason,
+		"findOneAndReplace-let":                 letReason,
+		"findOneAndUpdate-let":                  letReason,
+		"replaceOne-let":                        letReason,
+		"updateMany-let":                        letReason,
+		"updateOne-let":                         letReason,
+		"bulkWrite-insertOne-dots_and_dollars":  dotsAndDollarsReason,
+		"bulkWrite-updateMany-dots_and_dollars": dotsAndDollarsReason,
+		"bulkWrite-updateOne-dots_and_dollars":  dotsAndDollarsReason,
+		"findOneAndReplace-dots_and_dollars":    dotsAndDollarsReason,
+		"findOneAndUpdate-dots_and_dollars":     dotsAndDollarsReason,
+		"insertMany-dots_and_dollars":           dotsAndDollarsReason,
+		"insertOne-dots_and_dollars":            dotsAndDollarsReason,
+		"updateMany-dots_and_dollars":           dotsAndDollarsReason,
+		"updateOne-dots_and_dollars":            dotsAndDollarsReason,
+		"updateWithPipelines":                   pipelineUpdateReason,
+		"bulkWrite-arrayFilters":                "arrayFilters are not supported by bulk updates",
+		"aggregate-merge-errorResponse":         "$merge stage is not supported",
+		"db-aggregate":                          "Database-level aggregation is not supported",
+		"findOneAndUpdate-errorResponse":        "findAndModify does not return DuplicateKey error",
+		"estimatedDocumentCount/estimatedDocumentCount with maxTimeMS": "maxTimeMS is not supported by count",
+
+		"bulkWrite-comment/BulkWrite with document comment":                 documentCommentReason,
+		"deleteMany-comment/deleteMany with document comment":               documentCommentReason,
+		"deleteOne-comment/deleteOne with document comment":                 documentCommentReason,
+		"distinct-comment/distinct with document comment":                   documentCommentReason,
+		"findOneAndDelete-comment/findOneAndDelete with document comment":   documentCommentReason,
+		"findOneAndReplace-comment/findOneAndReplace with document comment": documentCommentReason,
+		"findOneAndUpdate-comment/findOneAndUpdate with document comment":   documentCommentReason,
+		"replaceOne-comment/ReplaceOne with document comment":               documentCommentReason,
+		"updateMany-comment/UpdateMany with document comment":               documentCommentReason,
+		"updateOne-comment/UpdateOne with document comment":                 documentCommentReason,
+	}
+
+	if setup.IsSQLite(t) {
+		for _, name := range []string{
+			"findOneAndDelete-comment", "findOneAndDelete-hint",
+			"findOneAndReplace-comment", "findOneAndReplace-hint",
+			"findOneAndUpdate-comment", "findOneAndUpdate-hint", "findOneAndUpdate-errorResponse",
+		} {
+			skip[name] = "findAndModify is not implemented for SQLite yet"
+		}
+	}
+
+	testUnified(t, "unified/testdata/crud", skip)
+}
+
+func TestUnifiedRetryableWrites(t *testing.T) {
+	t.Parallel()
+
+	testUnified(t, "unified/testdata/retryable-writes", nil)
+}
+
+func TestUnifiedSessions(t *testing.T) {
+	t.Parallel()
+
+	testUnified(t, "unified/testdata/sessions", map[string]string{
+		"snapshot-sessions-not-supported-server-error": "Snapshot reads are silently ignored",
+	})
+}
@@ -556,6 +556,19 @@ func TestUpdateFieldCompatRename(t *testing.T) {
 			update:     bson.D{{"$rename", bson.D{{"foo.0.baz", int32(1)}}}},
 			resultType: emptyResult,
 		},
+		"DotNotationArraySource": {
+			update:     bson.D{{"$rename", bson.D{{"v.array.0", "foo"}}}},
+			providers:  []shareddata.Provider{shareddata.Composites},
+			resultType: emptyResult,
+		},
+		"DotNotationArrayDest": {
+			update:    bson.D{{"$rename", bson.D{{"v.foo", "v.array.0"}}}},
+			providers: []shareddata.Provider{shareddata.Composites},
+		},
+		"DotNotationArrayDestOutOfArray": {
+			update:    bson.D{{"$rename", bson.D{{"v.foo", "v.array.100"}}}},
+			providers: []shareddata.Provider{shareddata.Composites},
+		},
 		"DotNotationMultipleFields": {
 			update: bson.D{{"$rename", bson.D{{"v.foo", "v.bar"}, {"v.42", "v.43"}}}},
 		},
@@ -0,0 +1,280 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+// skipForViews skips the test for backends that do not support views yet.
+func skipForViews(t *testing.T) {
+	t.Helper()
+
+	if !setup.IsMongoDB(t) && !setup.IsSQLite(t) {
+		t.Skip("https://github.com/FerretDB/FerretDB/issues/2348")
+	}
+}
+
+func TestViews(t *testing.T) {
+	t.Parallel()
+
+	skipForViews(t)
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"v", "foo"}, {"n", int32(1)}},
+		bson.D{{"_id", int32(2)}, {"v", "bar"}, {"n", int32(2)}},
+		bson.D{{"_id", int32(3)}, {"v", "baz"}, {"n", int32(3)}},
+	})
+	require.NoError(t, err)
+
+	pipeline := bson.A{bson.D{{"$match", bson.D{{"n", bson.D{{"$gt", int32(1)}}}}}}}
+	require.NoError(t, db.CreateView(ctx, "view", collection.Name(), pipeline))
+
+	nestedPipeline := bson.A{bson.D{{"$project", bson.D{{"n", int32(0)}}}}}
+	require.NoError(t, db.CreateView(ctx, "nested", "view", nestedPipeline))
+
+	view := db.Collection("view")
+	nested := db.Collection("nested")
+
+	t.Run("Find", func(t *testing.T) {
+		t.Parallel()
+
+		opts := options.Find().SetSort(bson.D{{"_id", -1}})
+		cursor, err := view.Find(ctx, bson.D{{"v", bson.D{{"$ne", "foo"}}}}, opts)
+		require.NoError(t, err)
+
+		var res []bson.D
+		require.NoError(t, cursor.All(ctx, &res))
+
+		expected := []bson.D{
+			{{"_id", int32(3)}, {"v", "baz"}, {"n", int32(3)}},
+			{{"_id", int32(2)}, {"v", "bar"}, {"n", int32(2)}},
+		}
+		AssertEqualDocumentsSlice(t, expected, res)
+	})
+
+	t.Run("FindNested", func(t *testing.T) {
+		t.Parallel()
+
+		cursor, err := nested.Find(ctx, bson.D{{"_id", int32(2)}})
+		require.NoError(t, err)
+
+		var res []bson.D
+		require.NoError(t, cursor.All(ctx, &res))
+
+		AssertEqualDocumentsSlice(t, []bson.D{{{"_id", int32(2)}, {"v", "bar"}}}, res)
+	})
+
+	t.Run("Aggregate", func(t *testing.T) {
+		t.Parallel()
+
+		cursor, err := nested.Aggregate(ctx, bson.A{
+			bson.D{{"$sort", bson.D{{"_id", 1}}}},
+			bson.D{{"$limit", int32(1)}},
+		})
+		require.NoError(t, err)
+
+		var res []bson.D
+		require.NoError(t, cursor.All(ctx, &res))
+
+		AssertEqualDocumentsSlice(t, []bson.D{{{"_id", int32(2)}, {"v", "bar"}}}, res)
+	})
+
+	t.Run("ListCollections", func(t *testing.T) {
+		t.Parallel()
+
+		specs, err := db.ListCollectionSpecifications(ctx, bson.D{{"name", "view"}})
+		require.NoError(t, err)
+		require.Len(t, specs, 1)
+
+		assert.Equal(t, "view", specs[0].Name)
+		assert.Equal(t, "view", specs[0].Type)
+		assert.True(t, specs[0].ReadOnly)
+
+		var opts bson.D
+		require.NoError(t, bson.Unmarshal(specs[0].Options, &opts))
+
+		AssertEqualDocuments(t, bson.D{{"viewOn", collection.Name()}, {"pipeline", pipeline}}, opts)
+	})
+
+	t.Run("Insert", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := view.InsertOne(ctx, bson.D{{"_id", int32(4)}})
+
+		expected := mongo.CommandError{
+			Code:    166,
+			Name:    "CommandNotSupportedOnView",
+			Message: "Namespace " + db.Name() + ".view is a view, not a collection",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := view.UpdateOne(ctx, bson.D{{"_id", int32(2)}}, bson.D{{"$set", bson.D{{"v", "qux"}}}})
+
+		expected := mongo.CommandError{
+			Code:    166,
+			Name:    "CommandNotSupportedOnView",
+			Message: "Namespace " + db.Name() + ".view is a view, not a collection",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := nested.DeleteMany(ctx, bson.D{})
+
+		expected := mongo.CommandError{
+			Code:    166,
+			Name:    "CommandNotSupportedOnView",
+			Message: "Namespace " + db.Name() + ".nested is a view, not a collection",
+		}
+		AssertEqualCommandError(t, expected, err)
+	})
+}
+
+func TestViewsCreateErrors(t *testing.T) {
+	t.Parallel()
+
+	skipForViews(t)
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	require.NoError(t, db.CreateView(ctx, "view", collection.Name(), bson.A{}))
+
+	for name, tc := range map[string]struct {
+		command bson.D
+		err     *mongo.CommandError
+	}{
+		"ViewOnType": {
+			command: bson.D{{"create", "v"}, {"viewOn", int32(1)}},
+			err: &mongo.CommandError{
+				Code:    14,
+				Name:    "TypeMismatch",
+				Message: "BSON field 'create.viewOn' is the wrong type 'int', expected type 'string'",
+			},
+		},
+		"PipelineWithoutViewOn": {
+			command: bson.D{{"create", "v"}, {"pipeline", bson.A{}}},
+			err: &mongo.CommandError{
+				Code:    72,
+				Name:    "InvalidOptions",
+				Message: "'pipeline' requires 'viewOn' to also be specified",
+			},
+		},
+		"PipelineType": {
+			command: bson.D{{"create", "v"}, {"viewOn", collection.Name()}, {"pipeline", "foo"}},
+			err: &mongo.CommandError{
+				Code:    14,
+				Name:    "TypeMismatch",
+				Message: "BSON field 'create.pipeline' is the wrong type 'string', expected type 'array'",
+			},
+		},
+		"Out": {
+			command: bson.D{
+				{"create", "v"},
+				{"viewOn", collection.Name()},
+				{"pipeline", bson.A{bson.D{{"$out", "foo"}}}},
+			},
+			err: &mongo.CommandError{
+				Code:    157,
+				Name:    "OptionNotSupportedOnView",
+				Message: "$out cannot be used in a view definition",
+			},
+		},
+		"ViewExists": {
+			command: bson.D{{"create", "view"}, {"viewOn", collection.Name()}},
+			err: &mongo.CommandError{
+				Code:    48,
+				Name:    "NamespaceExists",
+				Message: "Collection " + db.Name() + ".view already exists.",
+			},
+		},
+		"CollectionOverView": {
+			command: bson.D{{"create", "view"}},
+			err: &mongo.CommandError{
+				Code:    48,
+				Name:    "NamespaceExists",
+				Message: "Collection " + db.Name() + ".view already exists.",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := db.RunCommand(ctx, tc.command).Err()
+			AssertEqualCommandError(t, *tc.err, err)
+		})
+	}
+}
+
+func TestViewsCollModAndDrop(t *testing.T) {
+	t.Parallel()
+
+	skipForViews(t)
+
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"n", int32(1)}},
+		bson.D{{"_id", int32(2)}, {"n", int32(2)}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, db.CreateView(ctx, "view", collection.Name(), bson.A{}))
+
+	err = db.RunCommand(ctx, bson.D{
+		{"collMod", "view"},
+		{"viewOn", collection.Name()},
+		{"pipeline", bson.A{bson.D{{"$match", bson.D{{"n", int32(2)}}}}}},
+	}).Err()
+	require.NoError(t, err)
+
+	cursor, err := db.Collection("view").Find(ctx, bson.D{})
+	require.NoError(t, err)
+
+	var res []bson.D
+	require.NoError(t, cursor.All(ctx, &res))
+	AssertEqualDocumentsSlice(t, []bson.D{{{"_id", int32(2)}, {"n", int32(2)}}}, res)
+
+	require.NoError(t, db.Collection("view").Drop(ctx))
+
+	names, err := db.ListCollectionNames(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.NotContains(t, names, "view")
+
+	// the underlying collection is not affected
+	count, err := collection.CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
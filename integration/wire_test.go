@@ -0,0 +1,114 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// opMsgBody returns raw OP_MSG body bytes for the given command document.
+// If checksum is true, checksumPresent flag is set and a placeholder for the checksum is added.
+func opMsgBody(t *testing.T, doc *types.Document, checksum bool) []byte {
+	t.Helper()
+
+	var msg wire.OpMsg
+	require.NoError(t, msg.SetSections(wire.OpMsgSection{Documents: []*types.Document{doc}}))
+
+	b, err := msg.MarshalBinary()
+	require.NoError(t, err)
+
+	if checksum {
+		binary.LittleEndian.PutUint32(b, uint32(wire.OpMsgChecksumPresent))
+		b = append(b, 0, 0, 0, 0)
+	}
+
+	return b
+}
+
+// setChecksum sets CRC-32C checksum of the raw OP_MSG message in its last bytes.
+func setChecksum(msg []byte) {
+	n := len(msg) - crc32.Size
+	sum := crc32.Checksum(msg[:n], crc32.MakeTable(crc32.Castagnoli))
+	binary.LittleEndian.PutUint32(msg[n:], sum)
+}
+
+func TestWireOpMsgChecksum(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, nil)
+	db := s.Collection.Database().Name()
+
+	ping := must.NotFail(types.NewDocument("ping", int32(1), "$db", db))
+
+	t.Run("Valid", func(t *testing.T) {
+		t.Parallel()
+
+		c := s.DialWire(t)
+
+		requestID := c.NextRequestID()
+		msg := setup.WireMessage(requestID, wire.OpCodeMsg, opMsgBody(t, ping, true))
+		setChecksum(msg)
+		c.WriteRaw(msg)
+
+		header, body := c.Receive()
+		assert.Equal(t, requestID, header.ResponseTo)
+		require.Equal(t, wire.OpCodeMsg, header.OpCode)
+
+		doc, err := body.(*wire.OpMsg).Document()
+		require.NoError(t, err)
+		assert.Equal(t, float64(1), must.NotFail(doc.Get("ok")))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		c := s.DialWire(t)
+
+		msg := setup.WireMessage(c.NextRequestID(), wire.OpCodeMsg, opMsgBody(t, ping, true))
+		setChecksum(msg)
+		msg[len(msg)-1]++
+		c.WriteRaw(msg)
+
+		// the connection is closed without a response
+		_, _, err := c.ReadRaw()
+		assert.Error(t, err)
+	})
+}
+
+func TestWireMalformedMessage(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, nil)
+
+	c := s.DialWire(t)
+
+	// message length is smaller than the header length
+	msg := setup.WireMessage(c.NextRequestID(), wire.OpCodeMsg, nil)
+	binary.LittleEndian.PutUint32(msg, 4)
+	c.WriteRaw(msg)
+
+	_, _, err := c.ReadRaw()
+	assert.Error(t, err)
+}
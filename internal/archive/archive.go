@@ -0,0 +1,331 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package archive provides archival of cold collections to object storage.
+//
+// Archived collections are stored in the mongodump format, so they could be restored by mongorestore too:
+//   - `<database>/<collection>.bson` contains concatenated BSON documents;
+//   - `<database>/<collection>.metadata.json` contains collection options and indexes.
+//
+// Additionally, `catalog.json` contains the list of archived collections
+// as `{"collections": ["<database>.<collection>", ...]}`.
+//
+// Archived collections are dropped from the backend; operations on them should return an error
+// until they are restored.
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// catalogKey is the key of the catalog object.
+const catalogKey = "catalog.json"
+
+// batchSize is the number of documents inserted at once during restore.
+const batchSize = 100
+
+var (
+	// ErrArchived is returned when the collection is already archived.
+	ErrArchived = errors.New("collection is archived")
+
+	// ErrNotArchived is returned when the collection is not archived.
+	ErrNotArchived = errors.New("collection is not archived")
+)
+
+// catalog represents the catalog object.
+type catalog struct {
+	Collections []string `json:"collections"`
+}
+
+// Archive archives and restores collections.
+//
+// It is safe for concurrent use.
+type Archive struct {
+	s Storage
+
+	// serializes ArchiveCollection and RestoreCollection
+	opM sync.Mutex
+
+	rw       sync.RWMutex
+	archived map[string]struct{} // "database.collection"
+}
+
+// New creates a new Archive for the given storage, loading the catalog from it.
+func New(ctx context.Context, s Storage) (*Archive, error) {
+	a := &Archive{
+		s:        s,
+		archived: map[string]struct{}{},
+	}
+
+	b, err := s.Get(ctx, catalogKey)
+	if errors.Is(err, ErrNotExist) {
+		return a, nil
+	}
+
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var c catalog
+	if err = json.Unmarshal(b, &c); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	for _, ns := range c.Collections {
+		a.archived[ns] = struct{}{}
+	}
+
+	return a, nil
+}
+
+// Archived returns true if the given collection is archived.
+func (a *Archive) Archived(dbName, collectionName string) bool {
+	a.rw.RLock()
+	defer a.rw.RUnlock()
+
+	_, ok := a.archived[dbName+"."+collectionName]
+
+	return ok
+}
+
+// List returns archived collections as sorted "database.collection" namespaces.
+func (a *Archive) List() []string {
+	a.rw.RLock()
+	defer a.rw.RUnlock()
+
+	res := maps.Keys(a.archived)
+	sort.Strings(res)
+
+	return res
+}
+
+// ArchiveCollection stores the collection with all documents and indexes to the storage,
+// and drops it from the backend.
+//
+// Documents written to the collection during archiving may be lost.
+// If the collection does not exist, backends.ErrorCodeCollectionDoesNotExist is returned.
+// If it is already archived, ErrArchived is returned.
+func (a *Archive) ArchiveCollection(ctx context.Context, b backends.Backend, dbName, collectionName string) error {
+	a.opM.Lock()
+	defer a.opM.Unlock()
+
+	if a.Archived(dbName, collectionName) {
+		return ErrArchived
+	}
+
+	db, err := b.Database(dbName)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	list, err := db.ListCollections(ctx, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	var info *backends.CollectionInfo
+
+	for i, c := range list.Collections {
+		if c.Name == collectionName {
+			info = &list.Collections[i]
+			break
+		}
+	}
+
+	if info == nil {
+		return backends.NewError(backends.ErrorCodeCollectionDoesNotExist, nil)
+	}
+
+	c, err := db.Collection(collectionName)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	indexes, err := c.ListIndexes(ctx, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	mdb, err := json.MarshalIndent(newMetadata(collectionName, info, indexes.Indexes), "", "  ")
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	res, err := c.Query(ctx, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	docs, err := marshalDocuments(res.Iter)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	prefix := dbName + "/" + collectionName
+
+	if err = a.s.Put(ctx, prefix+".bson", docs); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err = a.s.Put(ctx, prefix+".metadata.json", mdb); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err = a.setArchived(ctx, dbName+"."+collectionName, true); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err = db.DropCollection(ctx, &backends.DropCollectionParams{Name: collectionName}); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// RestoreCollection creates the collection with all documents and indexes from the storage,
+// and deletes it from the storage.
+//
+// If the collection is not archived, ErrNotArchived is returned.
+// If the collection already exists, backends.ErrorCodeCollectionAlreadyExists is returned.
+func (a *Archive) RestoreCollection(ctx context.Context, b backends.Backend, dbName, collectionName string) error {
+	a.opM.Lock()
+	defer a.opM.Unlock()
+
+	if !a.Archived(dbName, collectionName) {
+		return ErrNotArchived
+	}
+
+	prefix := dbName + "/" + collectionName
+
+	mdb, err := a.s.Get(ctx, prefix+".metadata.json")
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	var md metadata
+	if err = json.Unmarshal(mdb, &md); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	data, err := a.s.Get(ctx, prefix+".bson")
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	docs, err := unmarshalDocuments(data)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	db, err := b.Database(dbName)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	err = db.CreateCollection(ctx, &backends.CreateCollectionParams{Name: collectionName, Capped: md.capped()})
+	if err != nil {
+		return err
+	}
+
+	if err = restoreDocuments(ctx, db, collectionName, md.indexes(), docs); err == nil {
+		err = a.setArchived(ctx, dbName+"."+collectionName, false)
+	}
+
+	if err != nil {
+		_ = db.DropCollection(ctx, &backends.DropCollectionParams{Name: collectionName})
+		return lazyerrors.Error(err)
+	}
+
+	// the collection is restored even if objects can't be deleted
+	_ = a.s.Delete(ctx, prefix+".bson")
+	_ = a.s.Delete(ctx, prefix+".metadata.json")
+
+	return nil
+}
+
+// restoreDocuments creates indexes and inserts documents into the existing collection.
+//
+//nolint:lll // for readability
+func restoreDocuments(ctx context.Context, db backends.Database, collectionName string, indexes []backends.IndexInfo, docs []*types.Document) error {
+	c, err := db.Collection(collectionName)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if len(indexes) > 0 {
+		if _, err = c.CreateIndexes(ctx, &backends.CreateIndexesParams{Indexes: indexes}); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	for i := 0; i < len(docs); i += batchSize {
+		end := i + batchSize
+		if end > len(docs) {
+			end = len(docs)
+		}
+
+		if _, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: docs[i:end]}); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// setArchived marks or unmarks the collection as archived and stores the catalog.
+func (a *Archive) setArchived(ctx context.Context, ns string, archived bool) error {
+	a.rw.Lock()
+	defer a.rw.Unlock()
+
+	_, was := a.archived[ns]
+
+	if archived {
+		a.archived[ns] = struct{}{}
+	} else {
+		delete(a.archived, ns)
+	}
+
+	c := catalog{Collections: maps.Keys(a.archived)}
+	sort.Strings(c.Collections)
+
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err == nil {
+		err = a.s.Put(ctx, catalogKey, b)
+	}
+
+	if err != nil {
+		// restore the previous state
+		if was {
+			a.archived[ns] = struct{}{}
+		} else {
+			delete(a.archived, ns)
+		}
+
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/sqlite"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestIndexKeyJSON(t *testing.T) {
+	t.Parallel()
+
+	key := indexKey{{Field: "b"}, {Field: "a.c", Descending: true}}
+
+	b, err := json.Marshal(key)
+	require.NoError(t, err)
+	assert.Equal(t, `{"b":1,"a.c":-1}`, string(b))
+
+	var actual indexKey
+	require.NoError(t, json.Unmarshal(b, &actual))
+	assert.Equal(t, key, actual)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"a":"text"}`), &actual))
+}
+
+func TestArchive(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+	dir := t.TempDir()
+
+	s, err := NewStorage("file:" + dir + "/")
+	require.NoError(t, err)
+
+	a, err := New(ctx, s)
+	require.NoError(t, err)
+
+	sb, err := sqlite.NewBackend(&sqlite.NewBackendParams{URI: "file:./?mode=memory", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	t.Cleanup(sb.Close)
+
+	dbName, collectionName := testutil.DatabaseName(t), testutil.CollectionName(t)
+
+	db, err := sb.Database(dbName)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	c, err := db.Collection(collectionName)
+	require.NoError(t, err)
+
+	docs := make([]*types.Document, 150)
+	for i := range docs {
+		docs[i] = must.NotFail(types.NewDocument("_id", int32(i), "v", "foo"))
+	}
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: docs})
+	require.NoError(t, err)
+
+	index := backends.IndexInfo{Name: "v_-1", Key: []backends.IndexKeyPair{{Field: "v", Descending: true}}}
+	_, err = c.CreateIndexes(ctx, &backends.CreateIndexesParams{Indexes: []backends.IndexInfo{index}})
+	require.NoError(t, err)
+
+	require.NoError(t, a.ArchiveCollection(ctx, sb, dbName, collectionName))
+	assert.True(t, a.Archived(dbName, collectionName))
+	assert.ErrorIs(t, a.ArchiveCollection(ctx, sb, dbName, collectionName), ErrArchived)
+
+	list, err := db.ListCollections(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, list.Collections)
+
+	assert.FileExists(t, filepath.Join(dir, dbName, collectionName+".bson"))
+	b, err := os.ReadFile(filepath.Join(dir, dbName, collectionName+".metadata.json"))
+	require.NoError(t, err)
+
+	var md metadata
+	require.NoError(t, json.Unmarshal(b, &md))
+	assert.Equal(t, collectionName, md.CollectionName)
+	assert.Equal(t, []backends.IndexInfo{index}, md.indexes())
+
+	// the catalog is persisted
+	a, err = New(ctx, s)
+	require.NoError(t, err)
+	assert.Equal(t, []string{dbName + "." + collectionName}, a.List())
+
+	require.NoError(t, a.RestoreCollection(ctx, sb, dbName, collectionName))
+	assert.False(t, a.Archived(dbName, collectionName))
+	assert.ErrorIs(t, a.RestoreCollection(ctx, sb, dbName, collectionName), ErrNotArchived)
+	assert.NoFileExists(t, filepath.Join(dir, dbName, collectionName+".bson"))
+
+	res, err := c.Query(ctx, nil)
+	require.NoError(t, err)
+
+	actual, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](res.Iter))
+	require.NoError(t, err)
+	require.Len(t, actual, len(docs))
+
+	for i := range docs {
+		testutil.AssertEqual(t, docs[i], actual[i])
+	}
+
+	indexes, err := c.ListIndexes(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, indexes.Indexes, 2)
+	assert.Equal(t, index, indexes.Indexes[1])
+
+	err = a.ArchiveCollection(ctx, sb, dbName, "missing")
+	assert.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist))
+}
@@ -0,0 +1,245 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// metadata represents the `<collection>.metadata.json` object in the mongodump format.
+type metadata struct {
+	Options        options     `json:"options"`
+	Indexes        []indexSpec `json:"indexes"`
+	CollectionName string      `json:"collectionName"`
+	Type           string      `json:"type"`
+}
+
+// options represents collection options.
+type options struct {
+	Capped bool  `json:"capped,omitempty"`
+	Size   int64 `json:"size,omitempty"`
+	Max    int64 `json:"max,omitempty"`
+}
+
+// indexSpec represents a single index specification.
+type indexSpec struct {
+	V                  int32    `json:"v"`
+	Key                indexKey `json:"key"`
+	Name               string   `json:"name"`
+	Unique             bool     `json:"unique,omitempty"`
+	ExpireAfterSeconds *int64   `json:"expireAfterSeconds,omitempty"`
+}
+
+// indexKey represents an index key as an ordered JSON object like `{"a": 1, "b": -1}`.
+type indexKey []backends.IndexKeyPair
+
+// MarshalJSON implements json.Marshaler interface.
+func (k indexKey) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, pair := range k {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		field, err := json.Marshal(pair.Field)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		order := 1
+		if pair.Descending {
+			order = -1
+		}
+
+		fmt.Fprintf(&buf, "%s:%d", field, order)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (k *indexKey) UnmarshalJSON(data []byte) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	if t, err := d.Token(); err != nil || t != json.Delim('{') {
+		return fmt.Errorf("archive: invalid index key %s", data)
+	}
+
+	var res indexKey
+
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		field, _ := t.(string)
+
+		if t, err = d.Token(); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		n, _ := t.(json.Number)
+
+		order, err := n.Int64()
+		if err != nil || (order != 1 && order != -1) {
+			return fmt.Errorf("archive: unsupported index key order %v for %q", t, field)
+		}
+
+		res = append(res, backends.IndexKeyPair{Field: field, Descending: order == -1})
+	}
+
+	*k = res
+
+	return nil
+}
+
+// newMetadata returns metadata of the collection.
+func newMetadata(collectionName string, info *backends.CollectionInfo, indexes []backends.IndexInfo) *metadata {
+	md := &metadata{
+		Indexes:        make([]indexSpec, len(indexes)),
+		CollectionName: collectionName,
+		Type:           "collection",
+	}
+
+	if info.Capped != nil {
+		md.Options = options{
+			Capped: true,
+			Size:   info.Capped.Size,
+			Max:    info.Capped.Documents,
+		}
+	}
+
+	for i, index := range indexes {
+		md.Indexes[i] = indexSpec{
+			V:                  2,
+			Key:                index.Key,
+			Name:               index.Name,
+			Unique:             index.Unique,
+			ExpireAfterSeconds: index.ExpireAfterSeconds,
+		}
+	}
+
+	return md
+}
+
+// capped returns capped collection parameters, or nil for non-capped collections.
+func (md *metadata) capped() *backends.CappedParams {
+	if !md.Options.Capped {
+		return nil
+	}
+
+	return &backends.CappedParams{
+		Size:      md.Options.Size,
+		Documents: md.Options.Max,
+	}
+}
+
+// indexes returns indexes other than the default one.
+func (md *metadata) indexes() []backends.IndexInfo {
+	res := make([]backends.IndexInfo, 0, len(md.Indexes))
+
+	for _, spec := range md.Indexes {
+		if spec.Name == "_id_" {
+			continue
+		}
+
+		res = append(res, backends.IndexInfo{
+			Name:               spec.Name,
+			Key:                spec.Key,
+			Unique:             spec.Unique,
+			ExpireAfterSeconds: spec.ExpireAfterSeconds,
+		})
+	}
+
+	return res
+}
+
+// marshalDocuments consumes the iterator and returns documents as concatenated BSON documents,
+// like in `<collection>.bson` files created by mongodump.
+func marshalDocuments(iter types.DocumentsIterator) ([]byte, error) {
+	defer iter.Close()
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		bdoc, err := bson.ConvertDocument(doc)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if err = bdoc.WriteTo(w); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// unmarshalDocuments returns documents from concatenated BSON documents.
+func unmarshalDocuments(b []byte) ([]*types.Document, error) {
+	r := bufio.NewReader(bytes.NewReader(b))
+
+	var res []*types.Document
+
+	for {
+		if _, err := r.Peek(1); errors.Is(err, io.EOF) {
+			return res, nil
+		}
+
+		var bdoc bson.Document
+		if err := bdoc.ReadFrom(r); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		doc, err := types.ConvertDocument(&bdoc)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		res = append(res, doc)
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// s3Storage stores objects in S3-compatible object storage.
+//
+// Path-style requests signed with AWS Signature Version 4 are used.
+type s3Storage struct {
+	client       *http.Client
+	endpoint     *url.URL
+	bucket       string
+	prefix       string
+	region       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+}
+
+// newS3Storage creates a new S3 storage for the given `s3:` URL.
+func newS3Storage(u *url.URL) (*s3Storage, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("archive.NewStorage: bucket is not set")
+	}
+
+	q := u.Query()
+
+	region := q.Get("region")
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	endpoint := q.Get("endpoint")
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+
+	eu, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	s := &s3Storage{
+		client:       http.DefaultClient,
+		endpoint:     eu,
+		bucket:       u.Host,
+		prefix:       cleanPrefix(u.Path),
+		region:       region,
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+	}
+
+	if s.accessKey == "" || s.secretKey == "" {
+		return nil, fmt.Errorf("archive.NewStorage: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY should be set")
+	}
+
+	return s, nil
+}
+
+// Put implements Storage interface.
+func (s *s3Storage) Put(ctx context.Context, key string, data []byte) error {
+	res, err := s.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return s.check(res, http.StatusOK)
+}
+
+// Get implements Storage interface.
+func (s *s3Storage) Get(ctx context.Context, key string) ([]byte, error) {
+	res, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, ErrNotExist
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, s.check(res, http.StatusOK)
+	}
+
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return b, nil
+}
+
+// Delete implements Storage interface.
+func (s *s3Storage) Delete(ctx context.Context, key string) error {
+	res, err := s.do(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	// S3 returns 204 even if the object does not exist, but some compatible implementations return 404
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil
+	}
+
+	return s.check(res, http.StatusOK, http.StatusNoContent)
+}
+
+// do sends a signed request for the given object.
+func (s *s3Storage) do(ctx context.Context, method, key string, body []byte) (*http.Response, error) {
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.bucket + "/" + s.prefix + key
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	req.ContentLength = int64(len(body))
+
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	sign(req, body, s.region, s.accessKey, s.secretKey, time.Now())
+
+	return s.client.Do(req)
+}
+
+// check closes the response body and returns an error if the status code is not one of the expected.
+func (s *s3Storage) check(res *http.Response, codes ...int) error {
+	defer res.Body.Close()
+
+	for _, code := range codes {
+		if res.StatusCode == code {
+			return nil
+		}
+	}
+
+	b, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+
+	return fmt.Errorf("archive: unexpected S3 response %s: %s", res.Status, b)
+}
+
+// sign adds AWS Signature Version 4 headers to the S3 request.
+//
+// Host and all other set headers are signed.
+func sign(req *http.Request, body []byte, region, accessKey, secretKey string, t time.Time) {
+	const (
+		algorithm = "AWS4-HMAC-SHA256"
+		service   = "s3"
+	)
+
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+
+	payloadHash := sha256.Sum256(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := algorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := []byte("AWS4" + secretKey)
+	for _, v := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, v)
+	}
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set(
+		"Authorization",
+		algorithm+" Credential="+accessKey+"/"+scope+", SignedHeaders="+signedHeaders+", Signature="+signature,
+	)
+}
+
+// hmacSHA256 returns HMAC-SHA256 of the data with the given key.
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
+
+// check interfaces
+var (
+	_ Storage = (*s3Storage)(nil)
+)
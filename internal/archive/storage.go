@@ -0,0 +1,159 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// ErrNotExist is returned by Storage.Get when the object does not exist.
+var ErrNotExist = errors.New("object does not exist")
+
+// Storage is an object storage for archives.
+//
+// Keys are slash-separated paths relative to the storage root.
+type Storage interface {
+	// Put stores the object, replacing the existing one.
+	Put(ctx context.Context, key string, data []byte) error
+
+	// Get returns the object or ErrNotExist.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Delete deletes the object; it is not an error if it does not exist.
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStorage returns a storage for the given URL.
+//
+// Supported URLs are:
+//   - `file:/path/to/directory/` for a local (or mounted) directory;
+//   - `s3://bucket/prefix/?region=us-east-1&endpoint=http://host:port` for S3-compatible object storage,
+//     with credentials taken from the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY,
+//     and (optionally) AWS_SESSION_TOKEN environment variables.
+func NewStorage(u string) (Storage, error) {
+	pu, err := url.Parse(u)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	switch pu.Scheme {
+	case "file":
+		return newDirStorage(pu)
+	case "s3":
+		return newS3Storage(pu)
+	default:
+		return nil, fmt.Errorf("archive.NewStorage: unexpected URL scheme %q", pu.Scheme)
+	}
+}
+
+// dirStorage stores objects as files in a directory.
+type dirStorage struct {
+	dir string
+}
+
+// newDirStorage creates a new directory storage for the given `file:` URL.
+func newDirStorage(u *url.URL) (*dirStorage, error) {
+	dir := u.Path
+	if dir == "" {
+		dir = u.Opaque
+	}
+
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("archive.NewStorage: %q is not a directory", dir)
+	}
+
+	return &dirStorage{dir: dir}, nil
+}
+
+// path returns the file path for the given key.
+func (s *dirStorage) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key))
+}
+
+// Put implements Storage interface.
+//
+// The object is written to a temporary file first, then renamed.
+func (s *dirStorage) Put(ctx context.Context, key string, data []byte) error {
+	p := s.path(key)
+
+	if err := os.MkdirAll(filepath.Dir(p), 0o777); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o666); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err := os.Rename(tmp, p); err != nil {
+		_ = os.Remove(tmp)
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// Get implements Storage interface.
+func (s *dirStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	b, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotExist
+	}
+
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return b, nil
+}
+
+// Delete implements Storage interface.
+func (s *dirStorage) Delete(ctx context.Context, key string) error {
+	err := os.Remove(s.path(key))
+	if err == nil || errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return lazyerrors.Error(err)
+}
+
+// cleanPrefix returns a prefix of object keys without leading slash and with trailing slash (if not empty).
+func cleanPrefix(prefix string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+
+	return prefix + "/"
+}
+
+// check interfaces
+var (
+	_ Storage = (*dirStorage)(nil)
+)
@@ -0,0 +1,47 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backends
+
+import "github.com/FerretDB/FerretDB/internal/types"
+
+// AggregateOp represents an operator of the accumulator pushed down to the backend.
+type AggregateOp int
+
+const (
+	_ AggregateOp = iota
+
+	// AggregateCount counts documents of the group.
+	// The result is int32 if it fits, int64 otherwise.
+	AggregateCount
+
+	// AggregateSum sums numbers at the accumulator's path; other values are ignored.
+	// The result type follows $sum: int32 if all numbers are int32 and the sum fits,
+	// int64 if it fits, and double otherwise; int32(0) if there are no numbers.
+	AggregateSum
+
+	// AggregateAvg averages numbers at the accumulator's path; other values are ignored.
+	// The result is double, or null if there are no numbers.
+	AggregateAvg
+)
+
+// AggregateAccumulator represents a single accumulator of the group pushed down to the backend.
+//
+// The result of the accumulator is set to the Name field of the group's document.
+// Path is not used for AggregateCount.
+type AggregateAccumulator struct {
+	Name string
+	Op   AggregateOp
+	Path types.Path
+}
@@ -16,6 +16,7 @@ package backends
 
 import (
 	"context"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -37,6 +38,9 @@ type Backend interface {
 	Database(string) (Database, error)
 	ListDatabases(context.Context, *ListDatabasesParams) (*ListDatabasesResult, error)
 	DropDatabase(context.Context, *DropDatabaseParams) error
+	BeginTransaction(context.Context, *BeginTransactionParams) (Transaction, error)
+	Checkpoint(context.Context, *CheckpointParams) error
+	Status(context.Context, *StatusParams) (*StatusResult, error)
 
 	prometheus.Collector
 
@@ -132,6 +136,75 @@ func (bc *backendContract) DropDatabase(ctx context.Context, params *DropDatabas
 	return err
 }
 
+// BeginTransactionParams represents the parameters of Backend.BeginTransaction method.
+type BeginTransactionParams struct{}
+
+// BeginTransaction starts a new multi-document transaction.
+//
+// Database and Collection methods called with the context returned by Transaction.Context
+// run inside that transaction.
+// Backends that do not support transactions return ErrorCodeTransactionsNotSupported.
+func (bc *backendContract) BeginTransaction(ctx context.Context, params *BeginTransactionParams) (Transaction, error) {
+	defer observability.FuncCall(ctx)()
+
+	res, err := bc.b.BeginTransaction(ctx, params)
+	checkError(err, ErrorCodeTransactionsNotSupported)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newTransactionContract(res), nil
+}
+
+// CheckpointParams represents the parameters of Backend.Checkpoint method.
+type CheckpointParams struct{}
+
+// Checkpoint flushes pending writes of all databases to the persistent storage.
+func (bc *backendContract) Checkpoint(ctx context.Context, params *CheckpointParams) error {
+	defer observability.FuncCall(ctx)()
+
+	err := bc.b.Checkpoint(ctx, params)
+	checkError(err)
+
+	return err
+}
+
+// StatusParams represents the parameters of Backend.Status method.
+type StatusParams struct{}
+
+// StatusResult represents the results of Backend.Status method.
+type StatusResult struct {
+	Name    string
+	Version string // may be empty if the backend version is not known yet
+	Pool    PoolStats
+
+	LastError     error // nil if there were no errors
+	LastErrorTime time.Time
+}
+
+// PoolStats represents connection pool statistics.
+//
+// For backends with multiple pools (like one per database), values are summed.
+type PoolStats struct {
+	MaxOpen      int
+	Open         int
+	InUse        int
+	Idle         int
+	WaitCount    int64
+	WaitDuration time.Duration
+}
+
+// Status returns the backend's name, version, connection pool statistics, and the last error.
+func (bc *backendContract) Status(ctx context.Context, params *StatusParams) (*StatusResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	res, err := bc.b.Status(ctx, params)
+	checkError(err)
+
+	return res, err
+}
+
 // Describe implements prometheus.Collector.
 func (bc *backendContract) Describe(ch chan<- *prometheus.Desc) {
 	bc.b.Describe(ch)
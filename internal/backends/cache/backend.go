@@ -0,0 +1,235 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cache provides a backend decorator that caches point reads by _id.
+//
+// Only configured collections are cached, each in a separate LRU cache.
+// Cached documents are invalidated on every write made through the decorator;
+// changes made by other FerretDB instances or directly in the underlying database are not seen
+// until cached documents are evicted.
+// Capped collections are not cached.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// Parts of Prometheus metric names.
+const (
+	namespace = "ferretdb"
+	subsystem = "backend_cache"
+)
+
+// collectionState represents the cache of a single collection.
+type collectionState struct {
+	dbName         string
+	collectionName string
+	size           int
+
+	m       sync.Mutex
+	lru     *lru
+	version uint64 // incremented on every invalidation
+	capped  *bool  // nil if not known yet
+	hits    int64
+	misses  int64
+}
+
+// reset drops all cached documents and forgets whether the collection is capped.
+func (s *collectionState) reset() {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.lru = newLRU(s.size)
+	s.version++
+	s.capped = nil
+}
+
+// invalidate drops cached documents with given _id values.
+func (s *collectionState) invalidate(ids ...any) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for _, id := range ids {
+		if cacheableID(id) {
+			s.lru.remove(id)
+		}
+	}
+
+	s.version++
+}
+
+// backend implements backends.Backend interface.
+type backend struct {
+	b      backends.Backend
+	states map[string]*collectionState // by "database.collection"; fixed after creation
+}
+
+// NewBackendParams represents the parameters of NewBackend function.
+type NewBackendParams struct {
+	Backend     backends.Backend
+	Collections map[string]int // maximum number of cached documents by "database.collection"
+}
+
+// NewBackend creates a new backend that caches point reads of the given backend.
+//
+// It takes ownership of the given backend; it is closed when the returned backend is closed.
+func NewBackend(params *NewBackendParams) (backends.Backend, error) {
+	states := make(map[string]*collectionState, len(params.Collections))
+
+	for ns, size := range params.Collections {
+		dbName, collectionName, ok := strings.Cut(ns, ".")
+		if !ok || dbName == "" || collectionName == "" {
+			return nil, fmt.Errorf("cache.NewBackend: invalid namespace %q", ns)
+		}
+
+		if size <= 0 {
+			return nil, fmt.Errorf("cache.NewBackend: invalid size %d for %q", size, ns)
+		}
+
+		states[ns] = &collectionState{
+			dbName:         dbName,
+			collectionName: collectionName,
+			size:           size,
+			lru:            newLRU(size),
+		}
+	}
+
+	return backends.BackendContract(&backend{
+		b:      params.Backend,
+		states: states,
+	}), nil
+}
+
+// Close implements backends.Backend interface.
+func (b *backend) Close() {
+	b.b.Close()
+}
+
+// Database implements backends.Backend interface.
+func (b *backend) Database(name string) (backends.Database, error) {
+	db, err := b.b.Database(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDatabase(b, db, name), nil
+}
+
+// ListDatabases implements backends.Backend interface.
+//
+//nolint:lll // for readability
+func (b *backend) ListDatabases(ctx context.Context, params *backends.ListDatabasesParams) (*backends.ListDatabasesResult, error) {
+	return b.b.ListDatabases(ctx, params)
+}
+
+// DropDatabase implements backends.Backend interface.
+func (b *backend) DropDatabase(ctx context.Context, params *backends.DropDatabaseParams) error {
+	err := b.b.DropDatabase(ctx, params)
+
+	for _, s := range b.states {
+		if s.dbName == params.Name {
+			s.reset()
+		}
+	}
+
+	return err
+}
+
+// BeginTransaction implements backends.Backend interface.
+//
+//nolint:lll // for readability
+func (b *backend) BeginTransaction(ctx context.Context, params *backends.BeginTransactionParams) (backends.Transaction, error) {
+	return b.b.BeginTransaction(ctx, params)
+}
+
+// Checkpoint implements backends.Backend interface.
+func (b *backend) Checkpoint(ctx context.Context, params *backends.CheckpointParams) error {
+	return b.b.Checkpoint(ctx, params)
+}
+
+// Status implements backends.Backend interface.
+func (b *backend) Status(ctx context.Context, params *backends.StatusParams) (*backends.StatusResult, error) {
+	return b.b.Status(ctx, params)
+}
+
+// Describe implements prometheus.Collector.
+func (b *backend) Describe(ch chan<- *prometheus.Desc) {
+	b.b.Describe(ch)
+
+	ch <- hitsDesc
+	ch <- missesDesc
+	ch <- documentsDesc
+}
+
+// Collect implements prometheus.Collector.
+func (b *backend) Collect(ch chan<- prometheus.Metric) {
+	b.b.Collect(ch)
+
+	for _, s := range b.states {
+		s.m.Lock()
+		hits, misses, documents := s.hits, s.misses, s.lru.len()
+		s.m.Unlock()
+
+		ch <- prometheus.MustNewConstMetric(hitsDesc, prometheus.CounterValue, float64(hits), s.dbName, s.collectionName)
+		ch <- prometheus.MustNewConstMetric(missesDesc, prometheus.CounterValue, float64(misses), s.dbName, s.collectionName)
+		ch <- prometheus.MustNewConstMetric(documentsDesc, prometheus.GaugeValue, float64(documents), s.dbName, s.collectionName)
+	}
+}
+
+// state returns the cache of the given collection, or nil if it is not cached.
+func (b *backend) state(dbName, collectionName string) *collectionState {
+	return b.states[dbName+"."+collectionName]
+}
+
+// cacheableID returns true if point reads by the given _id value can be cached.
+func cacheableID(id any) bool {
+	switch id.(type) {
+	case string, types.ObjectID:
+		return true
+	default:
+		return false
+	}
+}
+
+// Metric descriptions.
+var (
+	hitsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "hits_total"),
+		"The total number of point reads served from the cache.",
+		[]string{"db", "collection"}, nil,
+	)
+	missesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "misses_total"),
+		"The total number of point reads not served from the cache.",
+		[]string{"db", "collection"}, nil,
+	)
+	documentsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "documents"),
+		"The current number of cached point reads.",
+		[]string{"db", "collection"}, nil,
+	)
+)
+
+// check interfaces
+var (
+	_ backends.Backend = (*backend)(nil)
+)
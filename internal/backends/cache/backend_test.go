@@ -0,0 +1,144 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/sqlite"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// queryID returns documents returned by the point read.
+func queryID(t *testing.T, c backends.Collection, id any) []*types.Document {
+	t.Helper()
+
+	res, err := c.Query(testutil.Ctx(t), &backends.QueryParams{ID: id})
+	require.NoError(t, err)
+
+	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](res.Iter))
+	require.NoError(t, err)
+
+	return docs
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	sb, err := sqlite.NewBackend(&sqlite.NewBackendParams{URI: "file:" + t.TempDir() + "/", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	dbName, collectionName := testutil.DatabaseName(t), testutil.CollectionName(t)
+
+	b, err := NewBackend(&NewBackendParams{
+		Backend:     sb,
+		Collections: map[string]int{dbName + "." + collectionName: 10},
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(b.Close)
+
+	db, err := b.Database(dbName)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	c, err := db.Collection(collectionName)
+	require.NoError(t, err)
+
+	// the underlying collection is used to check what is served from the cache
+	sdb, err := sb.Database(dbName)
+	require.NoError(t, err)
+
+	defer sdb.Close()
+
+	sc, err := sdb.Collection(collectionName)
+	require.NoError(t, err)
+
+	id := types.NewObjectID()
+	doc := must.NotFail(types.NewDocument("_id", id, "v", "old"))
+
+	// negative result is cached
+	assert.Empty(t, queryID(t, c, id))
+
+	_, err = sc.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{doc}})
+	require.NoError(t, err)
+	assert.Empty(t, queryID(t, c, id))
+
+	// writes made through the cache invalidate it
+	_, err = c.DeleteAll(ctx, &backends.DeleteAllParams{IDs: []any{id}})
+	require.NoError(t, err)
+	assert.Empty(t, queryID(t, c, id))
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{doc}})
+	require.NoError(t, err)
+
+	docs := queryID(t, c, id)
+	require.Len(t, docs, 1)
+	testutil.AssertEqual(t, doc, docs[0])
+
+	// returned documents can be modified by the caller
+	docs[0].Set("v", "modified")
+
+	updated := must.NotFail(types.NewDocument("_id", id, "v", "new"))
+	_, err = sc.Update(ctx, &backends.UpdateParams{Docs: must.NotFail(types.NewArray(updated))})
+	require.NoError(t, err)
+
+	docs = queryID(t, c, id)
+	require.Len(t, docs, 1)
+	testutil.AssertEqual(t, doc, docs[0])
+
+	_, err = c.Update(ctx, &backends.UpdateParams{Docs: must.NotFail(types.NewArray(updated))})
+	require.NoError(t, err)
+
+	// whole documents are cached even if only some fields are requested
+	res, err := c.Query(ctx, &backends.QueryParams{ID: id, Fields: []string{"_id"}})
+	require.NoError(t, err)
+
+	docs, err = iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](res.Iter))
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	testutil.AssertEqual(t, updated, docs[0])
+
+	docs = queryID(t, c, id)
+	require.Len(t, docs, 1)
+	testutil.AssertEqual(t, updated, docs[0])
+
+	// dropping the collection resets the cache
+	require.NoError(t, db.DropCollection(ctx, &backends.DropCollectionParams{Name: collectionName}))
+	assert.Empty(t, queryID(t, c, id))
+
+	// capped collections are not cached
+	err = db.CreateCollection(ctx, &backends.CreateCollectionParams{
+		Name:   collectionName,
+		Capped: &backends.CappedParams{Size: 1 << 20},
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, queryID(t, c, id))
+
+	_, err = sc.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{doc}})
+	require.NoError(t, err)
+	assert.Len(t, queryID(t, c, id), 1)
+}
@@ -0,0 +1,299 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// collection implements backends.Collection interface for cached collections.
+type collection struct {
+	db *database
+	c  backends.Collection
+	s  *collectionState
+}
+
+// newCollection creates a new Collection.
+func newCollection(db *database, c backends.Collection, s *collectionState) backends.Collection {
+	return backends.CollectionContract(&collection{
+		db: db,
+		c:  c,
+		s:  s,
+	})
+}
+
+// Query implements backends.Collection interface.
+//
+// Point reads (with params.ID set) are served from the cache if possible.
+func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*backends.QueryResult, error) {
+	if params == nil || !cacheableID(params.ID) || params.RecordIDAfter != 0 || params.Sample != 0 {
+		return c.c.Query(ctx, params)
+	}
+
+	id := params.ID
+
+	c.s.m.Lock()
+	doc, ok := c.s.lru.get(id)
+	version, capped := c.s.version, c.s.capped
+
+	if ok {
+		c.s.hits++
+	}
+	c.s.m.Unlock()
+
+	if ok {
+		return queryResult(doc), nil
+	}
+
+	if capped == nil {
+		isCapped, err := c.isCapped(ctx)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		c.s.m.Lock()
+		if c.s.version == version {
+			c.s.capped = &isCapped
+		}
+		c.s.m.Unlock()
+
+		capped = &isCapped
+	}
+
+	if *capped {
+		return c.c.Query(ctx, params)
+	}
+
+	// whole documents are cached, so projection is not pushed down
+	wholeParams := *params
+	wholeParams.Fields = nil
+
+	res, err := c.c.Query(ctx, &wholeParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// the backend may return other documents too
+	doc, err = findByID(res.Iter, id)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	c.s.m.Lock()
+	c.s.misses++
+
+	// do not cache documents that could be changed during the query
+	if c.s.version == version {
+		var cached *types.Document
+		if doc != nil {
+			cached = doc.DeepCopy()
+		}
+
+		c.s.lru.add(id, cached)
+	}
+	c.s.m.Unlock()
+
+	return queryResult(doc), nil
+}
+
+// Aggregate implements backends.Collection interface.
+func (c *collection) Aggregate(ctx context.Context, params *backends.AggregateParams) (*backends.AggregateResult, error) {
+	return c.c.Aggregate(ctx, params)
+}
+
+// Count implements backends.Collection interface.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	return c.c.Count(ctx, params)
+}
+
+// InsertAll implements backends.Collection interface.
+func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllParams) (*backends.InsertAllResult, error) {
+	res, err := c.c.InsertAll(ctx, params)
+
+	c.s.invalidate(documentIDs(params.Docs)...)
+
+	return res, err
+}
+
+// UpsertAll implements backends.Collection interface.
+func (c *collection) UpsertAll(ctx context.Context, params *backends.UpsertAllParams) (*backends.UpsertAllResult, error) {
+	res, err := c.c.UpsertAll(ctx, params)
+
+	c.s.invalidate(documentIDs(params.Docs)...)
+
+	return res, err
+}
+
+// Update implements backends.Collection interface.
+func (c *collection) Update(ctx context.Context, params *backends.UpdateParams) (*backends.UpdateResult, error) {
+	res, err := c.c.Update(ctx, params)
+
+	docs := make([]*types.Document, 0, params.Docs.Len())
+
+	for i := 0; i < params.Docs.Len(); i++ {
+		if doc, ok := must.NotFail(params.Docs.Get(i)).(*types.Document); ok {
+			docs = append(docs, doc)
+		}
+	}
+
+	c.s.invalidate(documentIDs(docs)...)
+
+	return res, err
+}
+
+// DeleteAll implements backends.Collection interface.
+func (c *collection) DeleteAll(ctx context.Context, params *backends.DeleteAllParams) (*backends.DeleteAllResult, error) {
+	res, err := c.c.DeleteAll(ctx, params)
+
+	c.s.invalidate(params.IDs...)
+
+	return res, err
+}
+
+// Explain implements backends.Collection interface.
+func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams) (*backends.ExplainResult, error) {
+	return c.c.Explain(ctx, params)
+}
+
+// ListIndexes implements backends.Collection interface.
+//
+//nolint:lll // for readability
+func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndexesParams) (*backends.ListIndexesResult, error) {
+	return c.c.ListIndexes(ctx, params)
+}
+
+// CreateIndexes implements backends.Collection interface.
+//
+//nolint:lll // for readability
+func (c *collection) CreateIndexes(ctx context.Context, params *backends.CreateIndexesParams) (*backends.CreateIndexesResult, error) {
+	return c.c.CreateIndexes(ctx, params)
+}
+
+// DropIndexes implements backends.Collection interface.
+//
+//nolint:lll // for readability
+func (c *collection) DropIndexes(ctx context.Context, params *backends.DropIndexesParams) (*backends.DropIndexesResult, error) {
+	return c.c.DropIndexes(ctx, params)
+}
+
+// ReIndex implements backends.Collection interface.
+func (c *collection) ReIndex(ctx context.Context, params *backends.ReIndexParams) (*backends.ReIndexResult, error) {
+	return c.c.ReIndex(ctx, params)
+}
+
+// Compact implements backends.Collection interface.
+//
+// Documents are not changed, so the cache is kept.
+func (c *collection) Compact(ctx context.Context, params *backends.CompactParams) (*backends.CompactResult, error) {
+	return c.c.Compact(ctx, params)
+}
+
+// Validate implements backends.Collection interface.
+//
+// The repair may remove documents, so the cache is reset in that case.
+func (c *collection) Validate(ctx context.Context, params *backends.ValidateParams) (*backends.ValidateResult, error) {
+	res, err := c.c.Validate(ctx, params)
+
+	if params.Repair {
+		c.s.reset()
+	}
+
+	return res, err
+}
+
+// UpdateMetadata implements backends.Collection interface.
+//
+// Documents are not changed, so the cache is kept.
+//
+//nolint:lll // for readability
+func (c *collection) UpdateMetadata(ctx context.Context, params *backends.UpdateMetadataParams) (*backends.UpdateMetadataResult, error) {
+	return c.c.UpdateMetadata(ctx, params)
+}
+
+// isCapped returns true if the collection exists and is capped.
+func (c *collection) isCapped(ctx context.Context) (bool, error) {
+	list, err := c.db.db.ListCollections(ctx, nil)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	for _, info := range list.Collections {
+		if info.Name == c.s.collectionName {
+			return info.Capped != nil, nil
+		}
+	}
+
+	return false, nil
+}
+
+// findByID consumes the given iterator and returns the document with the identical _id value, if any.
+func findByID(iter types.DocumentsIterator, id any) (*types.Document, error) {
+	defer iter.Close()
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			return nil, nil
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if v, _ := doc.Get("_id"); types.Identical(v, id) {
+			return doc, nil
+		}
+	}
+}
+
+// queryResult returns a query result with a copy of the given document, or without documents if it is nil.
+func queryResult(doc *types.Document) *backends.QueryResult {
+	docs := []*types.Document{}
+	if doc != nil {
+		docs = append(docs, doc.DeepCopy())
+	}
+
+	return &backends.QueryResult{
+		Iter: iterator.Values(iterator.ForSlice(docs)),
+
+		// at most one document is always sorted
+		SortPushdown: true,
+	}
+}
+
+// documentIDs returns _id values of the given documents.
+func documentIDs(docs []*types.Document) []any {
+	ids := make([]any, 0, len(docs))
+
+	for _, doc := range docs {
+		if id, err := doc.Get("_id"); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// check interfaces
+var (
+	_ backends.Collection = (*collection)(nil)
+)
@@ -0,0 +1,109 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+)
+
+// database implements backends.Database interface.
+type database struct {
+	b    *backend
+	db   backends.Database
+	name string
+}
+
+// newDatabase creates a new Database.
+func newDatabase(b *backend, db backends.Database, name string) backends.Database {
+	return backends.DatabaseContract(&database{
+		b:    b,
+		db:   db,
+		name: name,
+	})
+}
+
+// Close implements backends.Database interface.
+func (db *database) Close() {
+	db.db.Close()
+}
+
+// Collection implements backends.Database interface.
+func (db *database) Collection(name string) (backends.Collection, error) {
+	c, err := db.db.Collection(name)
+	if err != nil {
+		return nil, err
+	}
+
+	s := db.b.state(db.name, name)
+	if s == nil {
+		return c, nil
+	}
+
+	return newCollection(db, c, s), nil
+}
+
+// ListCollections implements backends.Database interface.
+//
+//nolint:lll // for readability
+func (db *database) ListCollections(ctx context.Context, params *backends.ListCollectionsParams) (*backends.ListCollectionsResult, error) {
+	return db.db.ListCollections(ctx, params)
+}
+
+// CreateCollection implements backends.Database interface.
+func (db *database) CreateCollection(ctx context.Context, params *backends.CreateCollectionParams) error {
+	err := db.db.CreateCollection(ctx, params)
+
+	if s := db.b.state(db.name, params.Name); s != nil && err == nil {
+		s.reset()
+	}
+
+	return err
+}
+
+// DropCollection implements backends.Database interface.
+func (db *database) DropCollection(ctx context.Context, params *backends.DropCollectionParams) error {
+	err := db.db.DropCollection(ctx, params)
+
+	if s := db.b.state(db.name, params.Name); s != nil {
+		s.reset()
+	}
+
+	return err
+}
+
+// RenameCollection implements backends.Database interface.
+func (db *database) RenameCollection(ctx context.Context, params *backends.RenameCollectionParams) error {
+	err := db.db.RenameCollection(ctx, params)
+
+	for _, name := range []string{params.OldName, params.NewName} {
+		if s := db.b.state(db.name, name); s != nil {
+			s.reset()
+		}
+	}
+
+	return err
+}
+
+// Stats implements backends.Database interface.
+func (db *database) Stats(ctx context.Context, params *backends.StatsParams) (*backends.StatsResult, error) {
+	return db.db.Stats(ctx, params)
+}
+
+// check interfaces
+var (
+	_ backends.Database = (*database)(nil)
+)
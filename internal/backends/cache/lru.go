@@ -0,0 +1,92 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"container/list"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// lruEntry represents a single cached point read.
+type lruEntry struct {
+	id  any             // string or types.ObjectID
+	doc *types.Document // nil if there is no document with that _id
+}
+
+// lru is a least recently used cache of point reads.
+//
+// It is not safe for concurrent use.
+type lru struct {
+	size    int
+	l       *list.List
+	entries map[any]*list.Element
+}
+
+// newLRU creates a new LRU cache with the given maximum number of entries.
+func newLRU(size int) *lru {
+	if size <= 0 {
+		panic("invalid LRU size")
+	}
+
+	return &lru{
+		size:    size,
+		l:       list.New(),
+		entries: make(map[any]*list.Element, size),
+	}
+}
+
+// get returns cached document (that may be nil) for the given _id, and true if it was cached.
+func (c *lru) get(id any) (*types.Document, bool) {
+	e := c.entries[id]
+	if e == nil {
+		return nil, false
+	}
+
+	c.l.MoveToFront(e)
+
+	return e.Value.(*lruEntry).doc, true
+}
+
+// add adds document (that may be nil) for the given _id, evicting the least recently used entry if needed.
+func (c *lru) add(id any, doc *types.Document) {
+	if e := c.entries[id]; e != nil {
+		e.Value.(*lruEntry).doc = doc
+		c.l.MoveToFront(e)
+
+		return
+	}
+
+	c.entries[id] = c.l.PushFront(&lruEntry{id: id, doc: doc})
+
+	if c.l.Len() > c.size {
+		e := c.l.Back()
+		c.l.Remove(e)
+		delete(c.entries, e.Value.(*lruEntry).id)
+	}
+}
+
+// remove removes the entry for the given _id, if any.
+func (c *lru) remove(id any) {
+	if e := c.entries[id]; e != nil {
+		c.l.Remove(e)
+		delete(c.entries, id)
+	}
+}
+
+// len returns the number of cached entries.
+func (c *lru) len() int {
+	return c.l.Len()
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestLRU(t *testing.T) {
+	t.Parallel()
+
+	c := newLRU(2)
+
+	doc1 := must.NotFail(types.NewDocument("_id", "1"))
+	doc2 := must.NotFail(types.NewDocument("_id", "2"))
+
+	c.add("1", doc1)
+	c.add("2", doc2)
+	c.add("missing", nil)
+	assert.Equal(t, 2, c.len())
+
+	// the least recently used entry is evicted
+	_, ok := c.get("1")
+	assert.False(t, ok)
+
+	doc, ok := c.get("2")
+	assert.True(t, ok)
+	assert.Same(t, doc2, doc)
+
+	doc, ok = c.get("missing")
+	assert.True(t, ok)
+	assert.Nil(t, doc)
+
+	// "2" is now the least recently used entry
+	c.add("1", doc1)
+	_, ok = c.get("2")
+	assert.False(t, ok)
+
+	c.remove("1")
+	_, ok = c.get("1")
+	assert.False(t, ok)
+	assert.Equal(t, 1, c.len())
+}
@@ -33,10 +33,22 @@ import (
 // See collectionContract and its methods for additional details.
 type Collection interface {
 	Query(context.Context, *QueryParams) (*QueryResult, error)
+	Aggregate(context.Context, *AggregateParams) (*AggregateResult, error)
+	Count(context.Context, *CountParams) (*CountResult, error)
 	InsertAll(context.Context, *InsertAllParams) (*InsertAllResult, error)
+	UpsertAll(context.Context, *UpsertAllParams) (*UpsertAllResult, error)
 	Update(context.Context, *UpdateParams) (*UpdateResult, error)
 	DeleteAll(context.Context, *DeleteAllParams) (*DeleteAllResult, error)
 	Explain(context.Context, *ExplainParams) (*ExplainResult, error)
+
+	ListIndexes(context.Context, *ListIndexesParams) (*ListIndexesResult, error)
+	CreateIndexes(context.Context, *CreateIndexesParams) (*CreateIndexesResult, error)
+	DropIndexes(context.Context, *DropIndexesParams) (*DropIndexesResult, error)
+	ReIndex(context.Context, *ReIndexParams) (*ReIndexResult, error)
+	Compact(context.Context, *CompactParams) (*CompactResult, error)
+	Validate(context.Context, *ValidateParams) (*ValidateResult, error)
+
+	UpdateMetadata(context.Context, *UpdateMetadataParams) (*UpdateMetadataResult, error)
 }
 
 // collectionContract implements Collection interface.
@@ -58,31 +70,175 @@ func CollectionContract(c Collection) Collection {
 
 // QueryParams represents the parameters of Collection.Query method.
 type QueryParams struct {
-	// nothing for now - no pushdowns yet
+	// If not zero, only documents with record IDs greater than that value are returned;
+	// see types.Document.RecordID. That's used by tailable cursors and resume tokens.
+	RecordIDAfter int64
+
+	// If true, documents are returned in reverse natural order.
+	ReverseNatural bool
+
+	// If not zero, up to Sample randomly selected documents are returned in random order;
+	// ReverseNatural is ignored in that case.
+	// Backends may return more documents than requested, but never fewer if the collection has enough.
+	Sample int64
+
+	// If not nil, only documents with identical _id value (of string or ObjectID type) may be returned.
+	// Backends may ignore it and return other documents too; the handler filters them anyway.
+	ID any
+
+	// If not empty, the name of the existing index selected by the hint.
+	// Backends should not use other indexes for the query; they may not use that index too.
+	Index string
+
+	// If not empty, only documents matching all conditions may be returned.
+	// Backends may ignore any of them and return other documents too; the handler filters them anyway.
+	Filter []FilterCondition
+
+	// If not empty, documents should be returned sorted by those keys, with ties in natural order;
+	// ReverseNatural is ignored in that case, and Sample takes precedence.
+	// Backends may ignore it; QueryResult.SortPushdown is false in that case, and the handler sorts documents.
+	Sort []SortParams
+
+	// If not zero, up to Limit documents are returned after skipping the first Skip documents.
+	// Backends should ignore both if Filter or ID is set, if Sort is ignored, or if Sample is set,
+	// as the handler filters or sorts documents itself in those cases;
+	// QueryResult.LimitPushdown is false then, and the handler skips and limits documents.
+	Limit int64
+	Skip  int64
+
+	// If not empty, only those top-level fields are needed by the handler;
+	// backends may remove other fields from returned documents or ignore it and return whole documents.
+	Fields []string
+
+	// no other pushdowns yet
 	// TODO https://github.com/FerretDB/FerretDB/issues/3235
 }
 
 // QueryResult represents the results of Collection.Query method.
 type QueryResult struct {
 	Iter types.DocumentsIterator
+
+	// True if documents are sorted by QueryParams.Sort.
+	SortPushdown bool
+
+	// True if QueryParams.Limit and QueryParams.Skip were applied.
+	LimitPushdown bool
 }
 
 // Query executes a query against the collection.
 //
 // If database or collection does not exist it returns empty iterator.
 //
+// Documents are returned in natural order, which is the insertion order,
+// or in reverse natural order if params.ReverseNatural is true,
+// or in random order if params.Sample is set.
+// Documents are returned with record IDs set; see types.Document.RecordID.
+//
 // The passed context should be used for canceling the initial query.
 // It also can be used to close the returned iterator and free underlying resources,
 // but doing so is not necessary - the handler will do that anyway.
 func (cc *collectionContract) Query(ctx context.Context, params *QueryParams) (*QueryResult, error) {
 	defer observability.FuncCall(ctx)()
 
+	if err := checkFailPoint(ctx, "Collection.Query"); err != nil {
+		return nil, err
+	}
+
 	res, err := cc.c.Query(ctx, params)
 	checkError(err)
 
 	return res, err
 }
 
+// AggregateParams represents the parameters of Collection.Aggregate method.
+type AggregateParams struct {
+	// If not empty, only documents matching all conditions are aggregated.
+	// Unlike QueryParams.Filter, conditions can't be ignored;
+	// if some of them can't be applied exactly, the backend should not execute the aggregation.
+	Filter []FilterCondition
+
+	// If not nil, documents are grouped by the value at that path, with missing values grouped with nulls;
+	// otherwise, all documents form a single group with null _id.
+	GroupBy *types.Path
+
+	// Accumulators applied to documents of each group.
+	Accumulators []AggregateAccumulator
+}
+
+// AggregateResult represents the results of Collection.Aggregate method.
+type AggregateResult struct {
+	// Nil if the backend can't execute the aggregation;
+	// the handler processes pipeline stages itself in that case.
+	Iter types.DocumentsIterator
+}
+
+// Aggregate groups documents of the collection and applies accumulators to each group
+// like the $group stage does.
+//
+// Returned documents contain _id with the group key and fields of accumulators in the given order.
+// Groups are returned in the order of their first documents in natural order.
+//
+// That method is optional: backends that don't support it, or can't execute the given aggregation
+// exactly like the handler would for the current collection's data, return a result with nil Iter.
+//
+// If database or collection does not exist it returns empty iterator.
+func (cc *collectionContract) Aggregate(ctx context.Context, params *AggregateParams) (*AggregateResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	if err := checkFailPoint(ctx, "Collection.Aggregate"); err != nil {
+		return nil, err
+	}
+
+	res, err := cc.c.Aggregate(ctx, params)
+	checkError(err)
+
+	return res, err
+}
+
+// CountParams represents the parameters of Collection.Count method.
+type CountParams struct {
+	// If not empty, only documents matching all conditions are counted.
+	// Like for AggregateParams.Filter, conditions can't be ignored;
+	// if some of them can't be applied exactly, the backend should not count documents.
+	Filter []FilterCondition
+
+	// If true, the backend may return an estimated number of documents (for example, from catalog statistics)
+	// instead of counting them. It is set only with an empty Filter.
+	Estimated bool
+}
+
+// CountResult represents the results of Collection.Count method.
+type CountResult struct {
+	// Number of documents; valid only if CountPushdown is true.
+	Count int64
+
+	// True if documents were counted by the backend;
+	// the handler counts them itself otherwise.
+	CountPushdown bool
+
+	// True if Count is an estimate rather than the exact number of documents.
+	Estimated bool
+}
+
+// Count returns the number of documents of the collection matching the given filter.
+//
+// That method is optional: backends that don't support it, or can't apply the given filter exactly
+// for the current collection's data, return a result with false CountPushdown.
+//
+// If database or collection does not exist it returns zero count.
+func (cc *collectionContract) Count(ctx context.Context, params *CountParams) (*CountResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	if err := checkFailPoint(ctx, "Collection.Count"); err != nil {
+		return nil, err
+	}
+
+	res, err := cc.c.Count(ctx, params)
+	checkError(err)
+
+	return res, err
+}
+
 // InsertAllParams represents the parameters of Collection.InsertAll method.
 type InsertAllParams struct {
 	Docs []*types.Document
@@ -97,20 +253,75 @@ type InsertAllResult struct{}
 // If some documents cannot be inserted, the operation should be rolled back,
 // and the first encountered error should be returned.
 //
+// If a document has a duplicate _id, ErrorCodeInsertDuplicateID is returned.
+// If a document violates other unique index, ErrorCodeIndexDuplicateKey is returned.
+//
 // All documents are expected to be valid and include _id fields.
 // They will be frozen.
 //
+// For capped collections, the oldest documents are removed in the same transaction
+// to keep the collection within its size and documents limits.
+//
 // Both database and collection may or may not exist; they should be created automatically if needed.
 // TODO https://github.com/FerretDB/FerretDB/issues/3069
 func (cc *collectionContract) InsertAll(ctx context.Context, params *InsertAllParams) (*InsertAllResult, error) {
 	defer observability.FuncCall(ctx)()
 
+	if err := checkFailPoint(ctx, "Collection.InsertAll"); err != nil {
+		return nil, err
+	}
+
 	for _, doc := range params.Docs {
 		doc.Freeze()
 	}
 
 	res, err := cc.c.InsertAll(ctx, params)
-	checkError(err, ErrorCodeInsertDuplicateID)
+	checkError(err, ErrorCodeInsertDuplicateID, ErrorCodeIndexDuplicateKey)
+
+	return res, err
+}
+
+// UpsertAllParams represents the parameters of Collection.UpsertAll method.
+type UpsertAllParams struct {
+	Docs []*types.Document
+}
+
+// UpsertAllResult represents the results of Collection.UpsertAll method.
+type UpsertAllResult struct {
+	Inserted int32
+	Replaced int32
+}
+
+// UpsertAll inserts documents into the collection,
+// replacing existing documents with the same _id values.
+//
+// The operation should be atomic, including the check for existing documents,
+// so concurrent upserts of the same _id never fail with a duplicate key error.
+// If some documents cannot be upserted, the operation should be rolled back,
+// and the first encountered error should be returned.
+//
+// If a document violates a unique index other than the default _id index, ErrorCodeIndexDuplicateKey is returned.
+//
+// All documents are expected to be valid and include _id fields.
+// They will be frozen.
+//
+// For capped collections, the oldest documents are removed in the same transaction
+// to keep the collection within its size and documents limits.
+//
+// Both database and collection may or may not exist; they should be created automatically if needed.
+func (cc *collectionContract) UpsertAll(ctx context.Context, params *UpsertAllParams) (*UpsertAllResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	if err := checkFailPoint(ctx, "Collection.UpsertAll"); err != nil {
+		return nil, err
+	}
+
+	for _, doc := range params.Docs {
+		doc.Freeze()
+	}
+
+	res, err := cc.c.UpsertAll(ctx, params)
+	checkError(err, ErrorCodeIndexDuplicateKey)
 
 	return res, err
 }
@@ -129,12 +340,18 @@ type UpdateResult struct {
 
 // Update updates documents in collection.
 //
+// If an updated document violates a unique index, ErrorCodeIndexDuplicateKey is returned.
+//
 // Database or collection may not exist; that's not an error.
 func (cc *collectionContract) Update(ctx context.Context, params *UpdateParams) (*UpdateResult, error) {
 	defer observability.FuncCall(ctx)()
 
+	if err := checkFailPoint(ctx, "Collection.Update"); err != nil {
+		return nil, err
+	}
+
 	res, err := cc.c.Update(ctx, params)
-	checkError(err)
+	checkError(err, ErrorCodeIndexDuplicateKey)
 
 	return res, err
 }
@@ -161,6 +378,10 @@ type DeleteAllResult struct {
 func (cc *collectionContract) DeleteAll(ctx context.Context, params *DeleteAllParams) (*DeleteAllResult, error) {
 	defer observability.FuncCall(ctx)()
 
+	if err := checkFailPoint(ctx, "Collection.DeleteAll"); err != nil {
+		return nil, err
+	}
+
 	res, err := cc.c.DeleteAll(ctx, params)
 	checkError(err)
 
@@ -184,12 +405,259 @@ type ExplainResult struct {
 func (cc *collectionContract) Explain(ctx context.Context, params *ExplainParams) (*ExplainResult, error) {
 	defer observability.FuncCall(ctx)()
 
+	if err := checkFailPoint(ctx, "Collection.Explain"); err != nil {
+		return nil, err
+	}
+
 	res, err := cc.c.Explain(ctx, params)
 	checkError(err)
 
 	return res, err
 }
 
+// ListIndexesParams represents the parameters of Collection.ListIndexes method.
+type ListIndexesParams struct{}
+
+// ListIndexesResult represents the results of Collection.ListIndexes method.
+type ListIndexesResult struct {
+	Indexes []IndexInfo
+}
+
+// IndexInfo represents information about a single index.
+type IndexInfo struct {
+	Name               string
+	Key                []IndexKeyPair
+	Unique             bool
+	ExpireAfterSeconds *int64         // nil for non-TTL indexes
+	Text               *TextIndexInfo // set for text indexes only
+}
+
+// IndexKeyPair consists of a field name and a sort order that are part of the index.
+type IndexKeyPair struct {
+	Field      string
+	Descending bool
+	Sphere     bool // if true, the field value is indexed as geometry on a sphere; Descending is always false
+	Text       bool // if true, string values of the field are indexed for $text queries; Descending is always false
+}
+
+// TextIndexInfo represents options of the text index.
+type TextIndexInfo struct {
+	Weights          map[string]int32 // weights of all indexed fields, including ones set by the weights option only
+	DefaultLanguage  string
+	LanguageOverride string
+}
+
+// ListIndexes returns information about indexes in the collection, sorted by name.
+//
+// The default _id index is always present in the result for existing collections.
+//
+// Database or collection may not exist; ErrorCodeCollectionDoesNotExist is returned in that case.
+func (cc *collectionContract) ListIndexes(ctx context.Context, params *ListIndexesParams) (*ListIndexesResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	if err := checkFailPoint(ctx, "Collection.ListIndexes"); err != nil {
+		return nil, err
+	}
+
+	res, err := cc.c.ListIndexes(ctx, params)
+	checkError(err, ErrorCodeCollectionDoesNotExist)
+
+	return res, err
+}
+
+// CreateIndexesParams represents the parameters of Collection.CreateIndexes method.
+type CreateIndexesParams struct {
+	Indexes []IndexInfo
+}
+
+// CreateIndexesResult represents the results of Collection.CreateIndexes method.
+type CreateIndexesResult struct{}
+
+// CreateIndexes creates indexes for the collection.
+//
+// The operation should be atomic.
+// Indexes that already exist with the same name are skipped;
+// the caller is responsible for checking that their keys and options match.
+// If a unique index can't be created because existing documents contain duplicate keys,
+// ErrorCodeIndexDuplicateKey is returned, and no indexes are created.
+//
+// Both database and collection may or may not exist; they should be created automatically if needed.
+func (cc *collectionContract) CreateIndexes(ctx context.Context, params *CreateIndexesParams) (*CreateIndexesResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	if err := checkFailPoint(ctx, "Collection.CreateIndexes"); err != nil {
+		return nil, err
+	}
+
+	res, err := cc.c.CreateIndexes(ctx, params)
+	checkError(err, ErrorCodeIndexDuplicateKey)
+
+	return res, err
+}
+
+// DropIndexesParams represents the parameters of Collection.DropIndexes method.
+type DropIndexesParams struct {
+	Indexes []string
+}
+
+// DropIndexesResult represents the results of Collection.DropIndexes method.
+type DropIndexesResult struct{}
+
+// DropIndexes drops indexes of the collection by their names.
+//
+// Indexes that do not exist are ignored.
+// The default _id index can't be dropped; the caller is responsible for not passing it.
+//
+// Database or collection may not exist; that's not an error.
+func (cc *collectionContract) DropIndexes(ctx context.Context, params *DropIndexesParams) (*DropIndexesResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	if err := checkFailPoint(ctx, "Collection.DropIndexes"); err != nil {
+		return nil, err
+	}
+
+	res, err := cc.c.DropIndexes(ctx, params)
+	checkError(err)
+
+	return res, err
+}
+
+// ReIndexParams represents the parameters of Collection.ReIndex method.
+type ReIndexParams struct{}
+
+// ReIndexResult represents the results of Collection.ReIndex method.
+type ReIndexResult struct{}
+
+// ReIndex rebuilds all indexes of the collection, including the default _id index.
+//
+// Database or collection may not exist; ErrorCodeCollectionDoesNotExist is returned in that case.
+func (cc *collectionContract) ReIndex(ctx context.Context, params *ReIndexParams) (*ReIndexResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	if err := checkFailPoint(ctx, "Collection.ReIndex"); err != nil {
+		return nil, err
+	}
+
+	res, err := cc.c.ReIndex(ctx, params)
+	checkError(err, ErrorCodeCollectionDoesNotExist)
+
+	return res, err
+}
+
+// CompactParams represents the parameters of Collection.Compact method.
+type CompactParams struct {
+	// If true, the backend may use a slower compaction that blocks other operations
+	// but frees more space.
+	Full bool
+
+	// If true, nothing is compacted, and BytesFreed is an estimate.
+	DryRun bool
+}
+
+// CompactResult represents the results of Collection.Compact method.
+type CompactResult struct {
+	BytesFreed int64
+}
+
+// Compact reclaims unused disk space of the collection and rebuilds its indexes.
+//
+// Backends may compact the whole database instead; in that case,
+// BytesFreed is the number of bytes freed in the whole database.
+//
+// Database or collection may not exist; ErrorCodeCollectionDoesNotExist is returned in that case.
+func (cc *collectionContract) Compact(ctx context.Context, params *CompactParams) (*CompactResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	if err := checkFailPoint(ctx, "Collection.Compact"); err != nil {
+		return nil, err
+	}
+
+	res, err := cc.c.Compact(ctx, params)
+	checkError(err, ErrorCodeCollectionDoesNotExist)
+
+	return res, err
+}
+
+// ValidateParams represents the parameters of Collection.Validate method.
+type ValidateParams struct {
+	// If true, corrupt records are removed and indexes are rebuilt if they are inconsistent.
+	Repair bool
+}
+
+// ValidateResult represents the results of Collection.Validate method.
+type ValidateResult struct {
+	NRecords int64
+
+	// Record IDs of documents that could not be decoded; see types.Document.RecordID.
+	CorruptRecords []int64
+
+	// Record IDs of documents that were decoded but did not pass types validation.
+	InvalidRecords []int64
+
+	// Number of keys by index name.
+	KeysPerIndex map[string]int64
+
+	// Index inconsistencies, if any.
+	IndexErrors []string
+
+	// Number of corrupt records removed by the repair.
+	RemovedRecords int64
+
+	// True if indexes were rebuilt by the repair.
+	IndexesRebuilt bool
+}
+
+// Validate walks all collection's records and indexes and reports problems.
+//
+// Database or collection may not exist; ErrorCodeCollectionDoesNotExist is returned in that case.
+func (cc *collectionContract) Validate(ctx context.Context, params *ValidateParams) (*ValidateResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	if err := checkFailPoint(ctx, "Collection.Validate"); err != nil {
+		return nil, err
+	}
+
+	res, err := cc.c.Validate(ctx, params)
+	checkError(err, ErrorCodeCollectionDoesNotExist)
+
+	return res, err
+}
+
+// UpdateMetadataParams represents the parameters of Collection.UpdateMetadata method.
+type UpdateMetadataParams struct {
+	// Validator replaces the collection validator if not nil;
+	// ValidatorParams with nil Filter removes it.
+	Validator *ValidatorParams
+
+	// Index is the name of the existing TTL index to modify; empty for no index changes.
+	Index              string
+	ExpireAfterSeconds int64 // new TTL of the index, non-negative
+}
+
+// UpdateMetadataResult represents the results of Collection.UpdateMetadata method.
+type UpdateMetadataResult struct{}
+
+// UpdateMetadata updates the collection validator and the TTL of the given index.
+//
+// Database or collection may not exist; ErrorCodeCollectionDoesNotExist is returned in that case.
+// Non-existing index is ignored.
+func (cc *collectionContract) UpdateMetadata(ctx context.Context, params *UpdateMetadataParams) (*UpdateMetadataResult, error) {
+	defer observability.FuncCall(ctx)()
+
+	if err := checkFailPoint(ctx, "Collection.UpdateMetadata"); err != nil {
+		return nil, err
+	}
+
+	if params.Index != "" && params.ExpireAfterSeconds < 0 {
+		panic("invalid expireAfterSeconds")
+	}
+
+	res, err := cc.c.UpdateMetadata(ctx, params)
+	checkError(err, ErrorCodeCollectionDoesNotExist)
+
+	return res, err
+}
+
 // check interfaces
 var (
 	_ Collection = (*collectionContract)(nil)
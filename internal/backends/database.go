@@ -17,6 +17,7 @@ package backends
 import (
 	"context"
 
+	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/observability"
 	"github.com/FerretDB/FerretDB/internal/util/resource"
 )
@@ -91,7 +92,9 @@ func (dbc *databaseContract) Collection(name string) (Collection, error) {
 }
 
 // ListCollectionsParams represents the parameters of Database.ListCollections method.
-type ListCollectionsParams struct{}
+type ListCollectionsParams struct {
+	Name string // if not empty, only the collection with that name is returned, if it exists
+}
 
 // ListCollectionsResult represents the results of Database.ListCollections method.
 type ListCollectionsResult struct {
@@ -100,7 +103,11 @@ type ListCollectionsResult struct {
 
 // CollectionInfo represents information about a single collection.
 type CollectionInfo struct {
-	Name string
+	Name      string
+	Capped    *CappedParams    // nil for non-capped collections
+	Validator *ValidatorParams // nil for collections without validator
+	Collation *types.Document  // nil for the simple binary comparison
+	Clustered bool             // true if documents are stored clustered by _id
 }
 
 // ListCollections returns information about collections in the database.
@@ -109,6 +116,10 @@ type CollectionInfo struct {
 func (dbc *databaseContract) ListCollections(ctx context.Context, params *ListCollectionsParams) (*ListCollectionsResult, error) {
 	defer observability.FuncCall(ctx)()
 
+	if err := checkFailPoint(ctx, "Database.ListCollections"); err != nil {
+		return nil, err
+	}
+
 	res, err := dbc.db.ListCollections(ctx, params)
 	checkError(err)
 
@@ -117,7 +128,29 @@ func (dbc *databaseContract) ListCollections(ctx context.Context, params *ListCo
 
 // CreateCollectionParams represents the parameters of Database.CreateCollection method.
 type CreateCollectionParams struct {
-	Name string
+	Name      string
+	Capped    *CappedParams    // nil for non-capped collections
+	Validator *ValidatorParams // nil for collections without validator
+	Collation *types.Document  // default collation of the collection; nil for the simple binary comparison
+	Clustered bool             // store documents clustered by _id; incompatible with Capped
+}
+
+// CappedParams represents the parameters of a capped collection.
+//
+// When new documents are inserted into a capped collection,
+// the oldest documents are removed to keep the collection within limits.
+type CappedParams struct {
+	Size      int64 // maximum total size of documents in bytes, always positive
+	Documents int64 // maximum number of documents, 0 means no limit
+}
+
+// ValidatorParams represents the validator of a collection.
+//
+// Backends only store it; documents are validated by handlers.
+type ValidatorParams struct {
+	Filter *types.Document // query filter that documents should match
+	Level  string          // "off", "strict", or "moderate"
+	Action string          // "error" or "warn"
 }
 
 // CreateCollection creates a new collection with valid name in the database; it should not already exist.
@@ -127,6 +160,18 @@ type CreateCollectionParams struct {
 func (dbc *databaseContract) CreateCollection(ctx context.Context, params *CreateCollectionParams) error {
 	defer observability.FuncCall(ctx)()
 
+	if err := checkFailPoint(ctx, "Database.CreateCollection"); err != nil {
+		return err
+	}
+
+	if c := params.Capped; c != nil && (c.Size <= 0 || c.Documents < 0) {
+		panic("invalid capped collection parameters")
+	}
+
+	if v := params.Validator; v != nil && v.Filter == nil {
+		panic("invalid validator parameters")
+	}
+
 	err := validateCollectionName(params.Name)
 	if err == nil {
 		err = dbc.db.CreateCollection(ctx, params)
@@ -148,6 +193,10 @@ type DropCollectionParams struct {
 func (dbc *databaseContract) DropCollection(ctx context.Context, params *DropCollectionParams) error {
 	defer observability.FuncCall(ctx)()
 
+	if err := checkFailPoint(ctx, "Database.DropCollection"); err != nil {
+		return err
+	}
+
 	err := validateCollectionName(params.Name)
 	if err == nil {
 		err = dbc.db.DropCollection(ctx, params)
@@ -165,12 +214,16 @@ type RenameCollectionParams struct {
 }
 
 // RenameCollection renames existing collection in the database.
-// Both old and new names should be valid.
+// Both old and new names should be valid, and the collection with the new name should not exist.
 //
 // The errors for non-existing database and non-existing collection are the same (TODO?).
 func (dbc *databaseContract) RenameCollection(ctx context.Context, params *RenameCollectionParams) error {
 	defer observability.FuncCall(ctx)()
 
+	if err := checkFailPoint(ctx, "Database.RenameCollection"); err != nil {
+		return err
+	}
+
 	err := validateCollectionName(params.OldName)
 
 	if err == nil {
@@ -181,7 +234,12 @@ func (dbc *databaseContract) RenameCollection(ctx context.Context, params *Renam
 		err = dbc.db.RenameCollection(ctx, params)
 	}
 
-	checkError(err, ErrorCodeCollectionNameIsInvalid, ErrorCodeCollectionDoesNotExist) // TODO: ErrorCodeDatabaseDoesNotExist ?
+	checkError(
+		err,
+		ErrorCodeCollectionNameIsInvalid,
+		ErrorCodeCollectionDoesNotExist, // TODO: ErrorCodeDatabaseDoesNotExist ?
+		ErrorCodeCollectionAlreadyExists,
+	)
 
 	return err
 }
@@ -207,6 +265,10 @@ type StatsResult struct {
 func (dbc *databaseContract) Stats(ctx context.Context, params *StatsParams) (*StatsResult, error) {
 	defer observability.FuncCall(ctx)()
 
+	if err := checkFailPoint(ctx, "Database.Stats"); err != nil {
+		return nil, err
+	}
+
 	res, err := dbc.db.Stats(ctx, params)
 	checkError(err)
 
@@ -40,6 +40,10 @@ const (
 	ErrorCodeCollectionAlreadyExists
 
 	ErrorCodeInsertDuplicateID
+
+	ErrorCodeIndexDuplicateKey
+
+	ErrorCodeTransactionsNotSupported
 )
 
 // Error represents a backend error returned by all Backend, Database and Collection methods.
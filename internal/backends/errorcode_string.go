@@ -14,11 +14,13 @@ func _() {
 	_ = x[ErrorCodeCollectionDoesNotExist-4]
 	_ = x[ErrorCodeCollectionAlreadyExists-5]
 	_ = x[ErrorCodeInsertDuplicateID-6]
+	_ = x[ErrorCodeIndexDuplicateKey-7]
+	_ = x[ErrorCodeTransactionsNotSupported-8]
 }
 
-const _ErrorCode_name = "ErrorCodeDatabaseNameIsInvalidErrorCodeDatabaseDoesNotExistErrorCodeCollectionNameIsInvalidErrorCodeCollectionDoesNotExistErrorCodeCollectionAlreadyExistsErrorCodeInsertDuplicateID"
+const _ErrorCode_name = "ErrorCodeDatabaseNameIsInvalidErrorCodeDatabaseDoesNotExistErrorCodeCollectionNameIsInvalidErrorCodeCollectionDoesNotExistErrorCodeCollectionAlreadyExistsErrorCodeInsertDuplicateIDErrorCodeIndexDuplicateKeyErrorCodeTransactionsNotSupported"
 
-var _ErrorCode_index = [...]uint8{0, 30, 59, 91, 122, 154, 180}
+var _ErrorCode_index = [...]uint8{0, 30, 59, 91, 122, 154, 180, 206, 239}
 
 func (i ErrorCode) String() string {
 	i -= 1
@@ -0,0 +1,48 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backends
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/failpoints"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// checkFailPoint evaluates failBackend failpoint for the given method name
+// (like "Collection.Query") and returns an opaque error if it should fail.
+//
+// Failpoint's data may contain `methods` array to limit methods it applies to,
+// `blockTimeMS` to add latency, and `errorMessage` to return an error.
+func checkFailPoint(ctx context.Context, method string) error {
+	data, ok := failpoints.Evaluate(ctx, failpoints.FailBackend, func(data *types.Document) bool {
+		return failpoints.Contains(data, "methods", method, true)
+	})
+	if !ok {
+		return nil
+	}
+
+	if err := failpoints.Block(ctx, data); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if msg, _ := data.Get("errorMessage"); msg != nil {
+		s, _ := msg.(string)
+		return lazyerrors.Errorf("%s: failBackend failpoint: %s", method, s)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backends
+
+import "github.com/FerretDB/FerretDB/internal/types"
+
+// FilterOp represents an operator of the filter condition pushed down to the backend.
+type FilterOp int
+
+const (
+	_ FilterOp = iota
+
+	// FilterEq selects values equal to the condition's value.
+	FilterEq
+
+	// FilterGt selects values greater than the condition's value.
+	FilterGt
+
+	// FilterGte selects values greater than or equal to the condition's value.
+	FilterGte
+
+	// FilterLt selects values less than the condition's value.
+	FilterLt
+
+	// FilterLte selects values less than or equal to the condition's value.
+	FilterLte
+
+	// FilterIn selects values equal to any element of the condition's value.
+	FilterIn
+)
+
+// FilterCondition represents a single condition of the filter pushed down to the backend.
+//
+// The document matches the condition if the value at Path compares with Value using Op,
+// following MongoDB comparison rules: numbers of all types are compared by their values,
+// and values of different types never match.
+// If the value at Path or any value on the way to it is an array, the document may match;
+// backends may return such documents without checking the condition.
+//
+// Value is float64, int32, int64, string, types.ObjectID or bool for FilterEq;
+// float64, int32, int64 or string for range operators;
+// and a non-empty slice of values of FilterEq types for FilterIn.
+type FilterCondition struct {
+	Path  types.Path
+	Op    FilterOp
+	Value any
+}
@@ -0,0 +1,220 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/exp/maps"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// backend implements backends.Backend interface.
+type backend struct {
+	backends map[string]backends.Backend
+	m        *Mapping
+	def      string
+}
+
+// NewBackendParams represents the parameters of NewBackend function.
+type NewBackendParams struct {
+	Backends map[string]backends.Backend // by name
+	Mapping  *Mapping
+	Default  string // name of the default backend
+}
+
+// NewBackend creates a new backend that routes databases and collections to the given backends.
+//
+// It takes ownership of given backends; they are closed when the returned backend is closed.
+func NewBackend(params *NewBackendParams) (backends.Backend, error) {
+	if params.Backends[params.Default] == nil {
+		return nil, fmt.Errorf("multi.NewBackend: unknown default backend %q", params.Default)
+	}
+
+	for _, name := range params.Mapping.Backends() {
+		if params.Backends[name] == nil {
+			return nil, fmt.Errorf("multi.NewBackend: unknown backend %q in the mapping table", name)
+		}
+	}
+
+	return backends.BackendContract(&backend{
+		backends: params.Backends,
+		m:        params.Mapping,
+		def:      params.Default,
+	}), nil
+}
+
+// Close implements backends.Backend interface.
+func (b *backend) Close() {
+	for _, sb := range b.backends {
+		sb.Close()
+	}
+}
+
+// Database implements backends.Backend interface.
+func (b *backend) Database(name string) (backends.Database, error) {
+	return newDatabase(b, name), nil
+}
+
+// ListDatabases implements backends.Backend interface.
+//
+// Databases of all backends are listed; sizes are summed.
+//
+//nolint:lll // for readability
+func (b *backend) ListDatabases(ctx context.Context, params *backends.ListDatabasesParams) (*backends.ListDatabasesResult, error) {
+	sizes := map[string]int64{}
+
+	for _, sb := range b.backends {
+		res, err := sb.ListDatabases(ctx, params)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		for _, db := range res.Databases {
+			sizes[db.Name] += db.Size
+		}
+	}
+
+	names := maps.Keys(sizes)
+	sort.Strings(names)
+
+	res := &backends.ListDatabasesResult{
+		Databases: make([]backends.DatabaseInfo, len(names)),
+	}
+
+	for i, name := range names {
+		res.Databases[i] = backends.DatabaseInfo{Name: name, Size: sizes[name]}
+	}
+
+	return res, nil
+}
+
+// DropDatabase implements backends.Backend interface.
+//
+// The database is dropped in all backends where it exists.
+func (b *backend) DropDatabase(ctx context.Context, params *backends.DropDatabaseParams) error {
+	var dropped bool
+
+	for _, sb := range b.backends {
+		err := sb.DropDatabase(ctx, params)
+
+		switch {
+		case err == nil:
+			dropped = true
+		case backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseDoesNotExist):
+			// nothing
+		default:
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if !dropped {
+		return backends.NewError(backends.ErrorCodeDatabaseDoesNotExist, nil)
+	}
+
+	return nil
+}
+
+// BeginTransaction implements backends.Backend interface.
+//
+// Transactions can't span multiple backends, so they are not supported.
+//
+//nolint:lll // for readability
+func (b *backend) BeginTransaction(ctx context.Context, params *backends.BeginTransactionParams) (backends.Transaction, error) {
+	return nil, backends.NewError(backends.ErrorCodeTransactionsNotSupported, nil)
+}
+
+// Checkpoint implements backends.Backend interface.
+//
+// All backends are checkpointed.
+func (b *backend) Checkpoint(ctx context.Context, params *backends.CheckpointParams) error {
+	for _, sb := range b.backends {
+		if err := sb.Checkpoint(ctx, params); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// Status implements backends.Backend interface.
+//
+// The name and version of the default backend are returned;
+// pool statistics are summed, and the most recent error of all backends is returned.
+func (b *backend) Status(ctx context.Context, params *backends.StatusParams) (*backends.StatusResult, error) {
+	res := new(backends.StatusResult)
+
+	names := maps.Keys(b.backends)
+	sort.Strings(names)
+
+	for _, name := range names {
+		st, err := b.backends[name].Status(ctx, params)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if name == b.def {
+			res.Name = st.Name
+			res.Version = st.Version
+		}
+
+		res.Pool.MaxOpen += st.Pool.MaxOpen
+		res.Pool.Open += st.Pool.Open
+		res.Pool.InUse += st.Pool.InUse
+		res.Pool.Idle += st.Pool.Idle
+		res.Pool.WaitCount += st.Pool.WaitCount
+		res.Pool.WaitDuration += st.Pool.WaitDuration
+
+		if st.LastError != nil && st.LastErrorTime.After(res.LastErrorTime) {
+			res.LastError = st.LastError
+			res.LastErrorTime = st.LastErrorTime
+		}
+	}
+
+	return res, nil
+}
+
+// Describe implements prometheus.Collector.
+func (b *backend) Describe(ch chan<- *prometheus.Desc) {
+	b.backends[b.def].Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (b *backend) Collect(ch chan<- prometheus.Metric) {
+	b.backends[b.def].Collect(ch)
+}
+
+// collection returns a collection of the backend the given collection is mapped to.
+func (b *backend) collection(backendName, dbName, collectionName string) (backends.Collection, error) {
+	db, err := b.backends[backendName].Database(dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Database instances of all backends are stateless
+	defer db.Close()
+
+	return db.Collection(collectionName)
+}
+
+// check interfaces
+var (
+	_ backends.Backend = (*backend)(nil)
+)
@@ -0,0 +1,228 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multi
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// collection implements backends.Collection interface.
+//
+// Each method is routed to the backend the collection is mapped to at the time of the call.
+type collection struct {
+	b      *backend
+	dbName string
+	name   string
+}
+
+// newCollection creates a new Collection.
+func newCollection(b *backend, dbName, name string) backends.Collection {
+	return backends.CollectionContract(&collection{
+		b:      b,
+		dbName: dbName,
+		name:   name,
+	})
+}
+
+// get returns the collection of the mapped backend, locked for the operation.
+// The returned function should be called to unlock the collection.
+func (c *collection) get() (backends.Collection, func(), error) {
+	backendName, unlock := c.b.m.rLock(c.dbName, c.name)
+
+	sc, err := c.b.collection(backendName, c.dbName, c.name)
+	if err != nil {
+		unlock()
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	return sc, unlock, nil
+}
+
+// Query implements backends.Collection interface.
+func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*backends.QueryResult, error) {
+	sc, unlock, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return sc.Query(ctx, params)
+}
+
+// Aggregate implements backends.Collection interface.
+func (c *collection) Aggregate(ctx context.Context, params *backends.AggregateParams) (*backends.AggregateResult, error) {
+	sc, unlock, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return sc.Aggregate(ctx, params)
+}
+
+// Count implements backends.Collection interface.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	sc, unlock, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return sc.Count(ctx, params)
+}
+
+// InsertAll implements backends.Collection interface.
+func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllParams) (*backends.InsertAllResult, error) {
+	sc, unlock, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return sc.InsertAll(ctx, params)
+}
+
+// UpsertAll implements backends.Collection interface.
+func (c *collection) UpsertAll(ctx context.Context, params *backends.UpsertAllParams) (*backends.UpsertAllResult, error) {
+	sc, unlock, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return sc.UpsertAll(ctx, params)
+}
+
+// Update implements backends.Collection interface.
+func (c *collection) Update(ctx context.Context, params *backends.UpdateParams) (*backends.UpdateResult, error) {
+	sc, unlock, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return sc.Update(ctx, params)
+}
+
+// DeleteAll implements backends.Collection interface.
+func (c *collection) DeleteAll(ctx context.Context, params *backends.DeleteAllParams) (*backends.DeleteAllResult, error) {
+	sc, unlock, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return sc.DeleteAll(ctx, params)
+}
+
+// Explain implements backends.Collection interface.
+func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams) (*backends.ExplainResult, error) {
+	sc, unlock, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return sc.Explain(ctx, params)
+}
+
+// ListIndexes implements backends.Collection interface.
+func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndexesParams) (*backends.ListIndexesResult, error) {
+	sc, unlock, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return sc.ListIndexes(ctx, params)
+}
+
+// CreateIndexes implements backends.Collection interface.
+//
+//nolint:lll // for readability
+func (c *collection) CreateIndexes(ctx context.Context, params *backends.CreateIndexesParams) (*backends.CreateIndexesResult, error) {
+	sc, unlock, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return sc.CreateIndexes(ctx, params)
+}
+
+// DropIndexes implements backends.Collection interface.
+func (c *collection) DropIndexes(ctx context.Context, params *backends.DropIndexesParams) (*backends.DropIndexesResult, error) {
+	sc, unlock, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return sc.DropIndexes(ctx, params)
+}
+
+// ReIndex implements backends.Collection interface.
+func (c *collection) ReIndex(ctx context.Context, params *backends.ReIndexParams) (*backends.ReIndexResult, error) {
+	sc, unlock, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return sc.ReIndex(ctx, params)
+}
+
+// Compact implements backends.Collection interface.
+func (c *collection) Compact(ctx context.Context, params *backends.CompactParams) (*backends.CompactResult, error) {
+	sc, unlock, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return sc.Compact(ctx, params)
+}
+
+// Validate implements backends.Collection interface.
+func (c *collection) Validate(ctx context.Context, params *backends.ValidateParams) (*backends.ValidateResult, error) {
+	sc, unlock, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return sc.Validate(ctx, params)
+}
+
+// UpdateMetadata implements backends.Collection interface.
+//
+//nolint:lll // for readability
+func (c *collection) UpdateMetadata(ctx context.Context, params *backends.UpdateMetadataParams) (*backends.UpdateMetadataResult, error) {
+	sc, unlock, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return sc.UpdateMetadata(ctx, params)
+}
+
+// check interfaces
+var (
+	_ backends.Collection = (*collection)(nil)
+)
@@ -0,0 +1,168 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multi
+
+import (
+	"context"
+	"sort"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// database implements backends.Database interface.
+type database struct {
+	b    *backend
+	name string
+}
+
+// newDatabase creates a new Database.
+func newDatabase(b *backend, name string) backends.Database {
+	return backends.DatabaseContract(&database{
+		b:    b,
+		name: name,
+	})
+}
+
+// Close implements backends.Database interface.
+func (db *database) Close() {
+	// nothing
+}
+
+// Collection implements backends.Database interface.
+func (db *database) Collection(name string) (backends.Collection, error) {
+	return newCollection(db.b, db.name, name), nil
+}
+
+// ListCollections implements backends.Database interface.
+//
+// Collections are listed only from backends they are mapped to.
+//
+//nolint:lll // for readability
+func (db *database) ListCollections(ctx context.Context, params *backends.ListCollectionsParams) (*backends.ListCollectionsResult, error) {
+	var res []backends.CollectionInfo
+
+	for backendName, sb := range db.b.backends {
+		sdb, err := sb.Database(db.name)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		list, err := sdb.ListCollections(ctx, params)
+		sdb.Close()
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		for _, c := range list.Collections {
+			if db.b.m.Backend(db.name, c.Name) == backendName {
+				res = append(res, c)
+			}
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+
+	return &backends.ListCollectionsResult{
+		Collections: res,
+	}, nil
+}
+
+// CreateCollection implements backends.Database interface.
+func (db *database) CreateCollection(ctx context.Context, params *backends.CreateCollectionParams) error {
+	backendName, unlock := db.b.m.rLock(db.name, params.Name)
+	defer unlock()
+
+	sdb, err := db.b.backends[backendName].Database(db.name)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+	defer sdb.Close()
+
+	return sdb.CreateCollection(ctx, params)
+}
+
+// DropCollection implements backends.Database interface.
+func (db *database) DropCollection(ctx context.Context, params *backends.DropCollectionParams) error {
+	backendName, unlock := db.b.m.rLock(db.name, params.Name)
+	defer unlock()
+
+	sdb, err := db.b.backends[backendName].Database(db.name)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+	defer sdb.Close()
+
+	return sdb.DropCollection(ctx, params)
+}
+
+// RenameCollection implements backends.Database interface.
+//
+// Collections can be renamed only within the same backend.
+func (db *database) RenameCollection(ctx context.Context, params *backends.RenameCollectionParams) error {
+	backendName, unlock := db.b.m.rLock(db.name, params.OldName)
+	defer unlock()
+
+	if newBackendName := db.b.m.Backend(db.name, params.NewName); newBackendName != backendName {
+		return lazyerrors.Errorf(
+			"can't rename %s.%s to %s: collections are mapped to different backends %q and %q",
+			db.name, params.OldName, params.NewName, backendName, newBackendName,
+		)
+	}
+
+	sdb, err := db.b.backends[backendName].Database(db.name)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+	defer sdb.Close()
+
+	return sdb.RenameCollection(ctx, params)
+}
+
+// Stats implements backends.Database interface.
+//
+// Statistics of all backends are summed.
+func (db *database) Stats(ctx context.Context, params *backends.StatsParams) (*backends.StatsResult, error) {
+	var res backends.StatsResult
+
+	for _, sb := range db.b.backends {
+		sdb, err := sb.Database(db.name)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		stats, err := sdb.Stats(ctx, params)
+		sdb.Close()
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		res.CountCollections += stats.CountCollections
+		res.CountObjects += stats.CountObjects
+		res.CountIndexes += stats.CountIndexes
+		res.SizeTotal += stats.SizeTotal
+		res.SizeIndexes += stats.SizeIndexes
+		res.SizeCollections += stats.SizeCollections
+	}
+
+	return &res, nil
+}
+
+// check interfaces
+var (
+	_ backends.Database = (*database)(nil)
+)
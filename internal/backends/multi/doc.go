@@ -0,0 +1,24 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package multi provides a backend that routes databases and collections to multiple backends.
+//
+// The mapping table maps databases and individual collections to named backends;
+// everything that is not mapped is stored in the default backend.
+// Collections could be moved between backends online with MoveCollection;
+// operations on the moved collection wait until the move is complete.
+//
+// Multi-document transactions are not supported.
+// Only metrics of the default backend are collected, as metrics of other backends would have the same names.
+package multi
@@ -0,0 +1,232 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multi
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// mappingFile represents the JSON representation of the mapping table.
+type mappingFile struct {
+	Databases   map[string]string `json:"databases"`   // database name -> backend name
+	Collections map[string]string `json:"collections"` // "database.collection" -> backend name
+}
+
+// Mapping is a mapping table of databases and collections to backend names.
+//
+// It is safe for concurrent use.
+type Mapping struct {
+	file string
+	def  string
+
+	rw          sync.RWMutex
+	databases   map[string]string
+	collections map[string]string
+
+	// locks for collections, see lock and rLock methods
+	lm    sync.Mutex
+	locks map[string]*sync.RWMutex
+}
+
+// LoadMapping loads the mapping table from the given JSON file.
+//
+// Collection mappings are stored to that file by SetCollection.
+// If file is empty, the mapping table is not persisted.
+// If the file does not exist, it will be created on the first change.
+// Unmapped databases and collections use the given default backend.
+func LoadMapping(file, def string) (*Mapping, error) {
+	m := &Mapping{
+		file:        file,
+		def:         def,
+		databases:   map[string]string{},
+		collections: map[string]string{},
+		locks:       map[string]*sync.RWMutex{},
+	}
+
+	if file == "" {
+		return m, nil
+	}
+
+	b, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var mf mappingFile
+	if err = json.Unmarshal(b, &mf); err != nil {
+		return nil, lazyerrors.Errorf("%s: %w", file, err)
+	}
+
+	if mf.Databases != nil {
+		m.databases = mf.Databases
+	}
+
+	if mf.Collections != nil {
+		m.collections = mf.Collections
+	}
+
+	return m, nil
+}
+
+// Backends returns all backend names used by the mapping table, including the default one.
+func (m *Mapping) Backends() []string {
+	m.rw.RLock()
+	defer m.rw.RUnlock()
+
+	res := map[string]struct{}{m.def: {}}
+
+	for _, name := range m.databases {
+		res[name] = struct{}{}
+	}
+
+	for _, name := range m.collections {
+		res[name] = struct{}{}
+	}
+
+	return maps.Keys(res)
+}
+
+// Backend returns the backend name for the given collection.
+func (m *Mapping) Backend(dbName, collectionName string) string {
+	m.rw.RLock()
+	defer m.rw.RUnlock()
+
+	if name, ok := m.collections[dbName+"."+collectionName]; ok {
+		return name
+	}
+
+	if name, ok := m.databases[dbName]; ok {
+		return name
+	}
+
+	return m.def
+}
+
+// SetCollection maps the given collection to the given backend and persists the mapping table.
+func (m *Mapping) SetCollection(dbName, collectionName, backend string) error {
+	m.rw.Lock()
+	defer m.rw.Unlock()
+
+	key := dbName + "." + collectionName
+
+	prev, hasPrev := m.collections[key]
+
+	// do not store redundant mappings
+	if name, ok := m.databases[dbName]; (ok && name == backend) || (!ok && backend == m.def) {
+		delete(m.collections, key)
+	} else {
+		m.collections[key] = backend
+	}
+
+	if err := m.save(); err != nil {
+		if hasPrev {
+			m.collections[key] = prev
+		} else {
+			delete(m.collections, key)
+		}
+
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// save writes the mapping table to the file atomically.
+//
+// It does not hold the lock.
+func (m *Mapping) save() error {
+	if m.file == "" {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(&mappingFile{
+		Databases:   m.databases,
+		Collections: m.collections,
+	}, "", "  ")
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(m.file), filepath.Base(m.file)+".*.tmp")
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	defer os.Remove(tmp.Name()) //nolint:errcheck // temporary file is renamed on success
+
+	if _, err = tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return lazyerrors.Error(err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err = os.Rename(tmp.Name(), m.file); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// collectionLock returns a lock for the given collection.
+func (m *Mapping) collectionLock(dbName, collectionName string) *sync.RWMutex {
+	m.lm.Lock()
+	defer m.lm.Unlock()
+
+	key := dbName + "." + collectionName
+
+	l := m.locks[key]
+	if l == nil {
+		l = new(sync.RWMutex)
+		m.locks[key] = l
+	}
+
+	return l
+}
+
+// rLock locks the given collection for an operation and returns the backend name for it.
+// The returned function should be called to unlock the collection.
+//
+// Operations on the collection wait until its move is complete.
+func (m *Mapping) rLock(dbName, collectionName string) (string, func()) {
+	l := m.collectionLock(dbName, collectionName)
+	l.RLock()
+
+	return m.Backend(dbName, collectionName), l.RUnlock
+}
+
+// lock locks the given collection exclusively for a move.
+// The returned function should be called to unlock the collection.
+func (m *Mapping) lock(dbName, collectionName string) func() {
+	l := m.collectionLock(dbName, collectionName)
+	l.Lock()
+
+	return l.Unlock
+}
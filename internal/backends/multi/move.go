@@ -0,0 +1,181 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// moveBatchSize is the number of documents inserted into the target backend at once.
+const moveBatchSize = 100
+
+// MoveCollectionParams represents the parameters of MoveCollection function.
+type MoveCollectionParams struct {
+	Backends       map[string]backends.Backend // the same as passed to NewBackend
+	Mapping        *Mapping                    // the same as passed to NewBackend
+	DBName         string
+	CollectionName string
+	ToBackend      string
+}
+
+// MoveCollection moves the collection with all documents and indexes to the given backend,
+// updates the mapping table, and drops the collection in the previous backend.
+//
+// Operations on the collection wait until the move is complete.
+// If the collection is already mapped to the given backend, nothing is done.
+// If the collection does not exist, ErrorCodeCollectionDoesNotExist is returned.
+// If the collection already exists in the target backend (but is not mapped to it),
+// ErrorCodeCollectionAlreadyExists is returned.
+func MoveCollection(ctx context.Context, params *MoveCollectionParams) error {
+	m := params.Mapping
+	dbName, collectionName := params.DBName, params.CollectionName
+
+	to := params.Backends[params.ToBackend]
+	if to == nil {
+		return lazyerrors.Errorf("unknown backend %q", params.ToBackend)
+	}
+
+	unlock := m.lock(dbName, collectionName)
+	defer unlock()
+
+	fromName := m.Backend(dbName, collectionName)
+	if fromName == params.ToBackend {
+		return nil
+	}
+
+	fromDB, err := params.Backends[fromName].Database(dbName)
+	if err != nil {
+		return err
+	}
+	defer fromDB.Close()
+
+	list, err := fromDB.ListCollections(ctx, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	var info *backends.CollectionInfo
+
+	for i, c := range list.Collections {
+		if c.Name == collectionName {
+			info = &list.Collections[i]
+			break
+		}
+	}
+
+	if info == nil {
+		return backends.NewError(backends.ErrorCodeCollectionDoesNotExist, nil)
+	}
+
+	toDB, err := to.Database(dbName)
+	if err != nil {
+		return err
+	}
+	defer toDB.Close()
+
+	err = toDB.CreateCollection(ctx, &backends.CreateCollectionParams{
+		Name:      collectionName,
+		Capped:    info.Capped,
+		Validator: info.Validator,
+		Collation: info.Collation,
+		Clustered: info.Clustered,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = copyCollection(ctx, fromDB, toDB, collectionName); err == nil {
+		err = m.SetCollection(dbName, collectionName, params.ToBackend)
+	}
+
+	if err != nil {
+		_ = toDB.DropCollection(ctx, &backends.DropCollectionParams{Name: collectionName})
+		return lazyerrors.Error(err)
+	}
+
+	// the old copy is not visible anymore, even if it can't be dropped
+	if err = fromDB.DropCollection(ctx, &backends.DropCollectionParams{Name: collectionName}); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// copyCollection copies indexes and documents of the collection between databases of different backends.
+func copyCollection(ctx context.Context, fromDB, toDB backends.Database, collectionName string) error {
+	from, err := fromDB.Collection(collectionName)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	to, err := toDB.Collection(collectionName)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	indexes, err := from.ListIndexes(ctx, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if _, err = to.CreateIndexes(ctx, &backends.CreateIndexesParams{Indexes: indexes.Indexes}); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	res, err := from.Query(ctx, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+	defer res.Iter.Close()
+
+	batch := make([]*types.Document, 0, moveBatchSize)
+
+	for {
+		_, doc, err := res.Iter.Next()
+
+		switch {
+		case err == nil:
+			batch = append(batch, doc)
+
+			if len(batch) < moveBatchSize {
+				continue
+			}
+
+		case errors.Is(err, iterator.ErrIteratorDone):
+			if len(batch) == 0 {
+				return nil
+			}
+
+		default:
+			return lazyerrors.Error(err)
+		}
+
+		if _, err = to.InsertAll(ctx, &backends.InsertAllParams{Docs: batch}); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			return nil
+		}
+
+		batch = batch[:0]
+	}
+}
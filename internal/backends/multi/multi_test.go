@@ -0,0 +1,142 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multi
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/sqlite"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// setup returns the multi backend with "hot" (default) and "cold" SQLite backends,
+// and the mapping table stored in the given file.
+func setup(t *testing.T, file string) (backends.Backend, map[string]backends.Backend, *Mapping) {
+	t.Helper()
+
+	bs := map[string]backends.Backend{}
+
+	for _, name := range []string{"hot", "cold"} {
+		b, err := sqlite.NewBackend(&sqlite.NewBackendParams{
+			URI: "file:" + t.TempDir() + "/",
+			L:   testutil.Logger(t),
+		})
+		require.NoError(t, err)
+
+		bs[name] = b
+	}
+
+	m, err := LoadMapping(file, "hot")
+	require.NoError(t, err)
+
+	b, err := NewBackend(&NewBackendParams{Backends: bs, Mapping: m, Default: "hot"})
+	require.NoError(t, err)
+
+	t.Cleanup(b.Close)
+
+	return b, bs, m
+}
+
+func TestMoveCollection(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+	file := filepath.Join(t.TempDir(), "mapping.json")
+
+	b, bs, m := setup(t, file)
+
+	dbName, collectionName := testutil.DatabaseName(t), testutil.CollectionName(t)
+
+	db, err := b.Database(dbName)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	c, err := db.Collection(collectionName)
+	require.NoError(t, err)
+
+	docs := make([]*types.Document, 250)
+	for i := range docs {
+		docs[i] = must.NotFail(types.NewDocument("_id", int32(i), "v", int32(i%10)))
+	}
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: docs})
+	require.NoError(t, err)
+
+	_, err = c.CreateIndexes(ctx, &backends.CreateIndexesParams{Indexes: []backends.IndexInfo{{
+		Name: "v_1",
+		Key:  []backends.IndexKeyPair{{Field: "v"}},
+	}}})
+	require.NoError(t, err)
+
+	params := &MoveCollectionParams{
+		Backends:       bs,
+		Mapping:        m,
+		DBName:         dbName,
+		CollectionName: collectionName,
+		ToBackend:      "cold",
+	}
+	require.NoError(t, MoveCollection(ctx, params))
+
+	assert.Equal(t, "cold", m.Backend(dbName, collectionName))
+
+	// the same collection object now uses the cold backend
+	res, err := c.Query(ctx, nil)
+	require.NoError(t, err)
+
+	actual, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](res.Iter))
+	require.NoError(t, err)
+	assert.Len(t, actual, len(docs))
+
+	indexes, err := c.ListIndexes(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, indexes.Indexes, 2)
+	assert.Equal(t, "v_1", indexes.Indexes[1].Name)
+
+	list, err := db.ListCollections(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, list.Collections, 1)
+	assert.Equal(t, collectionName, list.Collections[0].Name)
+
+	hotDB, err := bs["hot"].Database(dbName)
+	require.NoError(t, err)
+
+	defer hotDB.Close()
+
+	list, err = hotDB.ListCollections(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, list.Collections)
+
+	// moving to the same backend does nothing
+	require.NoError(t, MoveCollection(ctx, params))
+
+	// the mapping table is persisted
+	loaded, err := LoadMapping(file, "hot")
+	require.NoError(t, err)
+	assert.Equal(t, "cold", loaded.Backend(dbName, collectionName))
+	assert.Equal(t, "hot", loaded.Backend(dbName, "other"))
+
+	params.CollectionName = "missing"
+	err = MoveCollection(ctx, params)
+	assert.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist))
+}
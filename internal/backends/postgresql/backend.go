@@ -60,6 +60,23 @@ func (b *backend) DropDatabase(ctx context.Context, params *backends.DropDatabas
 	panic("not implemented")
 }
 
+// BeginTransaction implements backends.Backend interface.
+//
+//nolint:lll // for readability
+func (b *backend) BeginTransaction(ctx context.Context, params *backends.BeginTransactionParams) (backends.Transaction, error) {
+	panic("not implemented")
+}
+
+// Checkpoint implements backends.Backend interface.
+func (b *backend) Checkpoint(ctx context.Context, params *backends.CheckpointParams) error {
+	panic("not implemented")
+}
+
+// Status implements backends.Backend interface.
+func (b *backend) Status(ctx context.Context, params *backends.StatusParams) (*backends.StatusResult, error) {
+	panic("not implemented")
+}
+
 // Describe implements prometheus.Collector.
 func (b *backend) Describe(ch chan<- *prometheus.Desc) {
 	panic("not implemented")
@@ -39,11 +39,26 @@ func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*
 	panic("not implemented")
 }
 
+// Aggregate implements backends.Collection interface.
+func (c *collection) Aggregate(ctx context.Context, params *backends.AggregateParams) (*backends.AggregateResult, error) {
+	panic("not implemented")
+}
+
+// Count implements backends.Collection interface.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	panic("not implemented")
+}
+
 // Insert implements backends.Collection interface.
 func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllParams) (*backends.InsertAllResult, error) {
 	panic("not implemented")
 }
 
+// UpsertAll implements backends.Collection interface.
+func (c *collection) UpsertAll(ctx context.Context, params *backends.UpsertAllParams) (*backends.UpsertAllResult, error) {
+	panic("not implemented")
+}
+
 // Update implements backends.Collection interface.
 func (c *collection) Update(ctx context.Context, params *backends.UpdateParams) (*backends.UpdateResult, error) {
 	panic("not implemented")
@@ -59,6 +74,45 @@ func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams
 	panic("not implemented")
 }
 
+// ListIndexes implements backends.Collection interface.
+func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndexesParams) (*backends.ListIndexesResult, error) {
+	panic("not implemented")
+}
+
+// CreateIndexes implements backends.Collection interface.
+//
+//nolint:lll // for readability
+func (c *collection) CreateIndexes(ctx context.Context, params *backends.CreateIndexesParams) (*backends.CreateIndexesResult, error) {
+	panic("not implemented")
+}
+
+// DropIndexes implements backends.Collection interface.
+func (c *collection) DropIndexes(ctx context.Context, params *backends.DropIndexesParams) (*backends.DropIndexesResult, error) {
+	panic("not implemented")
+}
+
+// ReIndex implements backends.Collection interface.
+func (c *collection) ReIndex(ctx context.Context, params *backends.ReIndexParams) (*backends.ReIndexResult, error) {
+	panic("not implemented")
+}
+
+// Compact implements backends.Collection interface.
+func (c *collection) Compact(ctx context.Context, params *backends.CompactParams) (*backends.CompactResult, error) {
+	panic("not implemented")
+}
+
+// Validate implements backends.Collection interface.
+func (c *collection) Validate(ctx context.Context, params *backends.ValidateParams) (*backends.ValidateResult, error) {
+	panic("not implemented")
+}
+
+// UpdateMetadata implements backends.Collection interface.
+//
+//nolint:lll // for readability
+func (c *collection) UpdateMetadata(ctx context.Context, params *backends.UpdateMetadataParams) (*backends.UpdateMetadataResult, error) {
+	panic("not implemented")
+}
+
 // check interfaces
 var (
 	_ backends.Collection = (*collection)(nil)
@@ -0,0 +1,387 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/sqlite/metadata"
+	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// groupKeyTypes are sjson types of group keys that could be grouped by SQLite exactly like MongoDB does.
+//
+// Doubles are not included, because SQLite may parse stored JSON numbers not exactly like Go.
+const groupKeyTypes = `'string', 'int', 'long', 'bool', 'objectId', 'date', 'null'`
+
+// Aggregate implements backends.Collection interface.
+//
+// Aggregation is executed only if it could not differ from the handler's one:
+// if no document has arrays on used paths, group keys of unsupported types, or doubles to sum,
+// and if all filter conditions could be applied exactly.
+func (c *collection) Aggregate(ctx context.Context, params *backends.AggregateParams) (*backends.AggregateResult, error) {
+	db := c.r.DatabaseGetExisting(ctx, c.dbName)
+	if db == nil {
+		return &backends.AggregateResult{
+			Iter: newQueryIterator(ctx, nil, false),
+		}, nil
+	}
+
+	meta := c.r.CollectionGet(ctx, c.dbName, c.name)
+	if meta == nil {
+		return &backends.AggregateResult{
+			Iter: newQueryIterator(ctx, nil, false),
+		}, nil
+	}
+
+	q, args := prepareAggregate(meta, params)
+	if q == "" {
+		return new(backends.AggregateResult), nil
+	}
+
+	check, checkArgs := prepareAggregateCheck(meta, params)
+
+	var unsupported bool
+	if err := db.QueryRowContext(ctx, check, checkArgs...).Scan(&unsupported); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if unsupported {
+		return new(backends.AggregateResult), nil
+	}
+
+	rows, err := db.QueryContext(ctx, q, args...)
+	if err != nil {
+		if integerOverflow(err) {
+			return new(backends.AggregateResult), nil
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	defer rows.Close()
+
+	var docs []*types.Document
+
+	for rows.Next() {
+		var key string
+		dest := []any{&key}
+
+		sums := make([]sql.NullInt64, len(params.Accumulators))
+		counts := make([]int64, len(params.Accumulators))
+		longs := make([]int64, len(params.Accumulators))
+
+		for i, acc := range params.Accumulators {
+			switch acc.Op {
+			case backends.AggregateCount:
+				dest = append(dest, &counts[i])
+			case backends.AggregateSum, backends.AggregateAvg:
+				dest = append(dest, &sums[i], &counts[i], &longs[i])
+			}
+		}
+
+		var order any
+		if err = rows.Scan(append(dest, &order)...); err != nil {
+			break
+		}
+
+		var doc *types.Document
+
+		if doc, err = sjson.Unmarshal([]byte(key)); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		for i, acc := range params.Accumulators {
+			doc.Set(acc.Name, aggregateValue(acc.Op, sums[i].Int64, counts[i], longs[i]))
+		}
+
+		docs = append(docs, doc)
+	}
+
+	if err == nil {
+		err = rows.Err()
+	}
+
+	if err != nil {
+		if integerOverflow(err) {
+			return new(backends.AggregateResult), nil
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &backends.AggregateResult{
+		Iter: iterator.Values(iterator.ForSlice(docs)),
+	}, nil
+}
+
+// integerOverflow returns true if the given error is returned by SQLite's SUM for overflowed integers;
+// the handler sums them as doubles in that case.
+func integerOverflow(err error) bool {
+	return strings.Contains(err.Error(), "integer overflow")
+}
+
+// aggregateValue returns the result of the accumulator with the given operator
+// for the sum and count of numbers (or documents for AggregateCount) and the count of int64 numbers.
+func aggregateValue(op backends.AggregateOp, sum, count, longs int64) any {
+	switch op {
+	case backends.AggregateCount:
+		if count <= math.MaxInt32 {
+			return int32(count)
+		}
+
+		return count
+
+	case backends.AggregateSum:
+		if longs == 0 && sum >= math.MinInt32 && sum <= math.MaxInt32 {
+			return int32(sum)
+		}
+
+		return sum
+
+	case backends.AggregateAvg:
+		if count == 0 {
+			return types.Null
+		}
+
+		return float64(sum) / float64(count)
+
+	default:
+		panic(fmt.Sprintf("unexpected aggregate operator: %d", op))
+	}
+}
+
+// prepareAggregate returns SQL query with arguments for the given aggregation,
+// or empty string if it can't be executed.
+//
+// Documents are grouped by the type bracket and the value of the group key;
+// the key of the group is taken from its first document in natural order.
+func prepareAggregate(meta *metadata.Collection, params *backends.AggregateParams) (string, []any) {
+	// rowid is an alias of the record ID column for capped collections;
+	// the natural order of clustered collections is the _id order
+	order := `rowid`
+	if meta.Settings.Clustered {
+		order = metadata.ClusteredIDColumn
+	}
+
+	var columns []string
+	var args []any
+
+	if params.GroupBy == nil {
+		columns = append(
+			columns,
+			`'{"$s":{"p":{"_id":{"t":"null"}},"$k":["_id"]},"_id":null}' AS _key`,
+			`NULL AS _type`,
+			`NULL AS _value`,
+		)
+	} else {
+		paths := jsonPaths(*params.GroupBy)
+		if paths == nil {
+			return "", nil
+		}
+
+		path, typePath := paths[len(paths)-1], schemaTypePath(*params.GroupBy)
+
+		columns = append(
+			columns,
+			fmt.Sprintf(
+				`json_object(`+
+					`'$s', json_object('p', json_object('_id', json_object('t', COALESCE(json_extract(%[1]s, ?), 'null'))), '$k', json_array('_id')), `+
+					`'_id', json(COALESCE(%[1]s -> ?, 'null'))`+
+					`) AS _key`,
+				metadata.DefaultColumn,
+			),
+			// numbers of all types are grouped together, and missing values are grouped with nulls
+			fmt.Sprintf(
+				`CASE json_extract(%[1]s, ?) WHEN 'int' THEN 'number' WHEN 'long' THEN 'number' WHEN 'null' THEN NULL `+
+					`ELSE json_extract(%[1]s, ?) END AS _type`,
+				metadata.DefaultColumn,
+			),
+			fmt.Sprintf(`json_extract(%s, ?) AS _value`, metadata.DefaultColumn),
+		)
+		args = append(args, typePath, path, typePath, typePath, path)
+	}
+
+	aggregates := []string{`_key`}
+
+	for i, acc := range params.Accumulators {
+		switch acc.Op {
+		case backends.AggregateCount:
+			aggregates = append(aggregates, `COUNT(*)`)
+
+		case backends.AggregateSum, backends.AggregateAvg:
+			paths := jsonPaths(acc.Path)
+			if paths == nil {
+				return "", nil
+			}
+
+			columns = append(
+				columns,
+				fmt.Sprintf(
+					`CASE WHEN json_extract(%[1]s, ?) IN ('int', 'long') THEN json_extract(%[1]s, ?) END AS _number%[2]d`,
+					metadata.DefaultColumn, i,
+				),
+				fmt.Sprintf(`CASE WHEN json_extract(%s, ?) = 'long' THEN 1 END AS _long%d`, metadata.DefaultColumn, i),
+			)
+			args = append(args, schemaTypePath(acc.Path), paths[len(paths)-1], schemaTypePath(acc.Path))
+
+			aggregates = append(
+				aggregates,
+				fmt.Sprintf(`SUM(_number%d)`, i),
+				fmt.Sprintf(`COUNT(_number%d)`, i),
+				fmt.Sprintf(`COUNT(_long%d)`, i),
+			)
+
+		default:
+			panic(fmt.Sprintf("unexpected aggregate operator: %d", acc.Op))
+		}
+	}
+
+	q := fmt.Sprintf(`SELECT %s AS _order, %s FROM %q`, order, strings.Join(columns, `, `), meta.TableName)
+
+	if len(params.Filter) > 0 {
+		where, whereArgs := exactFilterWhere(params.Filter)
+		if where == "" {
+			return "", nil
+		}
+
+		q += where
+		args = append(args, whereArgs...)
+	}
+
+	// _key is a bare column; with a single min() aggregate, it is taken from the row with the minimal value
+	q = fmt.Sprintf(
+		`SELECT %s, MIN(_order) FROM (%s) GROUP BY _type, _value ORDER BY MIN(_order)`,
+		strings.Join(aggregates, `, `), q,
+	)
+
+	return q, args
+}
+
+// exactFilterWhere returns SQL WHERE clause with arguments that selects exactly documents
+// matching all given filter conditions if there are no arrays on their paths,
+// or empty string if that's not possible.
+func exactFilterWhere(filter []backends.FilterCondition) (string, []any) {
+	conditions := make([]string, len(filter))
+
+	var args []any
+
+	for i, cond := range filter {
+		c, a := exactFilterCondition(cond)
+		if c == "" {
+			return "", nil
+		}
+
+		conditions[i] = c
+		args = append(args, a...)
+	}
+
+	return ` WHERE ` + strings.Join(conditions, ` AND `), args
+}
+
+// exactFilterCondition returns SQL condition with arguments that selects exactly documents
+// matching the given filter condition if there are no arrays on its path,
+// or empty string if that's not possible.
+func exactFilterCondition(cond backends.FilterCondition) (string, []any) {
+	if cond.Op != backends.FilterEq {
+		return "", nil
+	}
+
+	paths := jsonPaths(cond.Path)
+	if paths == nil {
+		return "", nil
+	}
+
+	path, typePath := paths[len(paths)-1], schemaTypePath(cond.Path)
+
+	switch v := cond.Value.(type) {
+	case string:
+		q := fmt.Sprintf(`(json_extract(%[1]s, ?) = 'string' AND json_extract(%[1]s, ?) = ?)`, metadata.DefaultColumn)
+		return q, []any{typePath, path, v}
+
+	case types.ObjectID:
+		q := fmt.Sprintf(`(json_extract(%[1]s, ?) = 'objectId' AND json_extract(%[1]s, ?) = ?)`, metadata.DefaultColumn)
+		return q, []any{typePath, path, hex.EncodeToString(v[:])}
+
+	case bool:
+		q := fmt.Sprintf(`(json_extract(%[1]s, ?) = 'bool' AND json_type(%[1]s, ?) = ?)`, metadata.DefaultColumn)
+		return q, []any{typePath, path, fmt.Sprint(v)}
+
+	default:
+		// numbers are not compared exactly; see numberBounds
+		return "", nil
+	}
+}
+
+// prepareAggregateCheck returns SQL query with arguments that returns true
+// if some document of the collection prevents executing the given aggregation exactly.
+//
+// It should be called only if prepareAggregate returned a query, so all paths are valid.
+func prepareAggregateCheck(meta *metadata.Collection, params *backends.AggregateParams) (string, []any) {
+	var conditions []string
+	var args []any
+
+	arrays := func(path types.Path) {
+		for _, p := range jsonPaths(path) {
+			conditions = append(conditions, fmt.Sprintf(`json_type(%s, ?) = 'array'`, metadata.DefaultColumn))
+			args = append(args, p)
+		}
+	}
+
+	for _, cond := range params.Filter {
+		arrays(cond.Path)
+	}
+
+	if params.GroupBy != nil {
+		arrays(*params.GroupBy)
+
+		conditions = append(
+			conditions,
+			fmt.Sprintf(`json_extract(%s, ?) NOT IN (%s)`, metadata.DefaultColumn, groupKeyTypes),
+		)
+		args = append(args, schemaTypePath(*params.GroupBy))
+	}
+
+	for _, acc := range params.Accumulators {
+		if acc.Op == backends.AggregateCount {
+			continue
+		}
+
+		arrays(acc.Path)
+
+		conditions = append(conditions, fmt.Sprintf(`json_extract(%s, ?) = 'double'`, metadata.DefaultColumn))
+		args = append(args, schemaTypePath(acc.Path))
+	}
+
+	if len(conditions) == 0 {
+		return `SELECT FALSE`, nil
+	}
+
+	q := fmt.Sprintf(
+		`SELECT EXISTS (SELECT 1 FROM %q WHERE %s)`,
+		meta.TableName, strings.Join(conditions, ` OR `),
+	)
+
+	return q, args
+}
@@ -22,6 +22,8 @@ import (
 
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/backends/sqlite/metadata"
+	"github.com/FerretDB/FerretDB/internal/util/lasterror"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 )
 
 // backend implements backends.Backend interface.
@@ -84,6 +86,77 @@ func (b *backend) DropDatabase(ctx context.Context, params *backends.DropDatabas
 	return nil
 }
 
+// BeginTransaction implements backends.Backend interface.
+//
+// Multi-document transactions are not supported yet,
+// as each database is stored in a separate SQLite file.
+//
+//nolint:lll // for readability
+func (b *backend) BeginTransaction(ctx context.Context, params *backends.BeginTransactionParams) (backends.Transaction, error) {
+	return nil, backends.NewError(backends.ErrorCodeTransactionsNotSupported, nil)
+}
+
+// Checkpoint implements backends.Backend interface.
+//
+// It checkpoints WAL files of all databases without waiting for readers and writers.
+func (b *backend) Checkpoint(ctx context.Context, params *backends.CheckpointParams) error {
+	for _, dbName := range b.r.DatabaseList(ctx) {
+		db := b.r.DatabaseGetExisting(ctx, dbName)
+		if db == nil {
+			// database was dropped concurrently
+			continue
+		}
+
+		if _, err := db.ExecContext(ctx, "PRAGMA wal_checkpoint(PASSIVE)"); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// Status implements backends.Backend interface.
+//
+// Each database has its own connection pool; their statistics are summed.
+func (b *backend) Status(ctx context.Context, params *backends.StatusParams) (*backends.StatusResult, error) {
+	res := &backends.StatusResult{
+		Name: "SQLite",
+	}
+
+	var last *lasterror.Entry
+
+	for _, dbName := range b.r.DatabaseList(ctx) {
+		db := b.r.DatabaseGetExisting(ctx, dbName)
+		if db == nil {
+			// database was dropped concurrently
+			continue
+		}
+
+		if res.Version == "" {
+			if err := db.QueryRowContext(ctx, "SELECT sqlite_version()").Scan(&res.Version); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+		}
+
+		stats := db.Stats()
+		res.Pool.MaxOpen += stats.MaxOpenConnections
+		res.Pool.Open += stats.OpenConnections
+		res.Pool.InUse += stats.InUse
+		res.Pool.Idle += stats.Idle
+		res.Pool.WaitCount += stats.WaitCount
+		res.Pool.WaitDuration += stats.WaitDuration
+
+		last = lasterror.Latest(last, db.LastError())
+	}
+
+	if last != nil {
+		res.LastError = last.Err
+		res.LastErrorTime = last.Time
+	}
+
+	return res, nil
+}
+
 // Describe implements prometheus.Collector.
 func (b *backend) Describe(ch chan<- *prometheus.Desc) {
 	b.r.Describe(ch)
@@ -0,0 +1,97 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestCheckpoint(t *testing.T) {
+	b, err := NewBackend(&NewBackendParams{URI: "file:" + t.TempDir() + "/", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	ctx := testutil.Ctx(t)
+
+	// no databases yet
+	require.NoError(t, b.Checkpoint(ctx, new(backends.CheckpointParams)))
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	c, err := db.Collection(testutil.CollectionName(t))
+	require.NoError(t, err)
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{
+		Docs: []*types.Document{must.NotFail(types.NewDocument("_id", types.NewObjectID()))},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, b.Checkpoint(ctx, new(backends.CheckpointParams)))
+}
+
+func TestStatus(t *testing.T) {
+	b, err := NewBackend(&NewBackendParams{URI: "file:" + t.TempDir() + "/", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	ctx := testutil.Ctx(t)
+
+	// no databases yet
+	res, err := b.Status(ctx, new(backends.StatusParams))
+	require.NoError(t, err)
+	assert.Equal(t, &backends.StatusResult{Name: "SQLite"}, res)
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	c, err := db.Collection(testutil.CollectionName(t))
+	require.NoError(t, err)
+
+	doc := must.NotFail(types.NewDocument("_id", types.NewObjectID()))
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{doc}})
+	require.NoError(t, err)
+
+	res, err = b.Status(ctx, new(backends.StatusParams))
+	require.NoError(t, err)
+	assert.Equal(t, "SQLite", res.Name)
+	assert.Regexp(t, `^3\.`, res.Version)
+	assert.GreaterOrEqual(t, res.Pool.Open, 1)
+	assert.NoError(t, res.LastError)
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{doc}})
+	require.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID))
+
+	res, err = b.Status(ctx, new(backends.StatusParams))
+	require.NoError(t, err)
+	assert.Error(t, res.LastError)
+	assert.WithinDuration(t, time.Now(), res.LastErrorTime, time.Minute)
+}
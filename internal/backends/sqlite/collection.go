@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	sqlite3 "modernc.org/sqlite"
@@ -54,32 +55,141 @@ func (c *collection) Query(ctx context.Context, params *backends.QueryParams) (*
 	db := c.r.DatabaseGetExisting(ctx, c.dbName)
 	if db == nil {
 		return &backends.QueryResult{
-			Iter: newQueryIterator(ctx, nil),
+			Iter: newQueryIterator(ctx, nil, false),
 		}, nil
 	}
 
 	meta := c.r.CollectionGet(ctx, c.dbName, c.name)
 	if meta == nil {
 		return &backends.QueryResult{
-			Iter: newQueryIterator(ctx, nil),
+			Iter: newQueryIterator(ctx, nil, false),
 		}, nil
 	}
 
-	q := fmt.Sprintf(`SELECT %s FROM %q`, metadata.DefaultColumn, meta.TableName)
+	if params == nil {
+		params = new(backends.QueryParams)
+	}
 
-	rows, err := db.QueryContext(ctx, q)
+	// rowid is an alias of the record ID column for capped collections
+	orderBy := ` ORDER BY rowid`
+	if params.ReverseNatural {
+		orderBy += ` DESC`
+	}
+
+	sortTerms, sortArgs := prepareOrderBy(params.Sort)
+
+	switch {
+	case params.Sample != 0:
+		orderBy = fmt.Sprintf(` ORDER BY random() LIMIT %d`, params.Sample)
+		sortTerms, sortArgs = nil, nil
+
+	case sortTerms != nil:
+		// ties are returned in natural order
+		orderBy = ` ORDER BY ` + strings.Join(sortTerms, `, `) + `, rowid`
+	}
+
+	// see QueryParams.Limit
+	var limit string
+	var limitArgs []any
+
+	if (params.Limit != 0 || params.Skip != 0) &&
+		params.Sample == 0 && params.ID == nil && len(params.Filter) == 0 {
+		// negative LIMIT means no limit in SQLite
+		l := params.Limit
+		if l == 0 {
+			l = -1
+		}
+
+		limit = ` LIMIT ? OFFSET ?`
+		limitArgs = []any{l, params.Skip}
+	}
+
+	filter, filterArgs := prepareFilter(params.Filter)
+
+	column, columnArgs := prepareProjection(params.Fields)
+
+	if meta.Capped() {
+		q := fmt.Sprintf(
+			`SELECT %[1]s, %[2]s FROM %[3]q WHERE %[1]s > ?`,
+			metadata.RecordIDColumn, column, meta.TableName,
+		)
+
+		for _, f := range filter {
+			q += ` AND ` + f
+		}
+
+		q += orderBy + limit
+
+		args := append(columnArgs, params.RecordIDAfter)
+		args = append(args, filterArgs...)
+		args = append(args, sortArgs...)
+
+		rows, err := db.QueryContext(ctx, q, append(args, limitArgs...)...)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		return &backends.QueryResult{
+			Iter:          newQueryIterator(ctx, rows, true),
+			SortPushdown:  sortTerms != nil,
+			LimitPushdown: limit != "",
+		}, nil
+	}
+
+	// rowid is used as the record ID for non-capped collections
+	q := fmt.Sprintf(`SELECT rowid, %s FROM %q`, column, meta.TableName)
+
+	// WITHOUT ROWID tables of clustered collections have no record IDs,
+	// and their natural order is the _id order
+	if meta.Settings.Clustered {
+		q = fmt.Sprintf(`SELECT 0, %s FROM %q`, column, meta.TableName)
+
+		orderBy = strings.Replace(orderBy, `rowid`, metadata.ClusteredIDColumn, 1)
+	}
+
+	var conditions []string
+	args := columnArgs
+
+	// the _id index can't be used if the hint selects another index
+	if params.Index == "" || params.Index == "_id_" {
+		switch params.ID.(type) {
+		case string, types.ObjectID:
+			conditions = append(conditions, fmt.Sprintf(`%s = ?`, meta.IDColumn()))
+			args = append(args, string(must.NotFail(sjson.MarshalSingleValue(params.ID))))
+		}
+	}
+
+	if params.RecordIDAfter != 0 && !meta.Settings.Clustered {
+		conditions = append(conditions, `rowid > ?`)
+		args = append(args, params.RecordIDAfter)
+	}
+
+	conditions = append(conditions, filter...)
+	args = append(args, filterArgs...)
+
+	if len(conditions) > 0 {
+		q += ` WHERE ` + strings.Join(conditions, ` AND `)
+	}
+
+	q += orderBy + limit
+
+	args = append(args, sortArgs...)
+
+	rows, err := db.QueryContext(ctx, q, append(args, limitArgs...)...)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
 	return &backends.QueryResult{
-		Iter: newQueryIterator(ctx, rows),
+		Iter:          newQueryIterator(ctx, rows, true),
+		SortPushdown:  sortTerms != nil,
+		LimitPushdown: limit != "",
 	}, nil
 }
 
 // Insert implements backends.Collection interface.
 func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllParams) (*backends.InsertAllResult, error) {
-	if _, err := c.r.CollectionCreate(ctx, c.dbName, c.name); err != nil {
+	if _, err := c.r.CollectionCreate(ctx, &metadata.CollectionCreateParams{DBName: c.dbName, Name: c.name}); err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
@@ -88,6 +198,10 @@ func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllPa
 	db := c.r.DatabaseGetExisting(ctx, c.dbName)
 	meta := c.r.CollectionGet(ctx, c.dbName, c.name)
 
+	// use batches: INSERT INTO %q %s VALUES (?), (?), (?), ... up to, say, 100 documents
+	// TODO https://github.com/FerretDB/FerretDB/issues/3271
+	q := insertQuery(meta)
+
 	err := db.InTransaction(ctx, func(tx *fsql.Tx) error {
 		for _, doc := range params.Docs {
 			b, err := sjson.Marshal(doc)
@@ -95,20 +209,19 @@ func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllPa
 				return lazyerrors.Error(err)
 			}
 
-			// use batches: INSERT INTO %q %s VALUES (?), (?), (?), ... up to, say, 100 documents
-			// TODO https://github.com/FerretDB/FerretDB/issues/3271
-			q := fmt.Sprintf(`INSERT INTO %q (%s) VALUES (?)`, meta.TableName, metadata.DefaultColumn)
-
 			if _, err = tx.ExecContext(ctx, q, string(b)); err != nil {
-				var se *sqlite3.Error
-				if errors.As(err, &se) && se.Code() == sqlite3lib.SQLITE_CONSTRAINT_UNIQUE {
-					return backends.NewError(backends.ErrorCodeInsertDuplicateID, err)
+				if e := duplicateKeyError(meta, err); e != nil {
+					return e
 				}
 
 				return lazyerrors.Error(err)
 			}
 		}
 
+		if meta.Capped() {
+			return evictCapped(ctx, tx, meta)
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -118,6 +231,149 @@ func (c *collection) InsertAll(ctx context.Context, params *backends.InsertAllPa
 	return new(backends.InsertAllResult), nil
 }
 
+// UpsertAll implements backends.Collection interface.
+//
+// SQLite does not report whether INSERT ... ON CONFLICT DO UPDATE inserted or updated the row,
+// so each document is inserted with ON CONFLICT DO NOTHING, and the existing one is replaced if nothing was inserted.
+// That is still atomic because the transaction holds the write lock after the first statement.
+func (c *collection) UpsertAll(ctx context.Context, params *backends.UpsertAllParams) (*backends.UpsertAllResult, error) {
+	if _, err := c.r.CollectionCreate(ctx, &metadata.CollectionCreateParams{DBName: c.dbName, Name: c.name}); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	db := c.r.DatabaseGetExisting(ctx, c.dbName)
+	meta := c.r.CollectionGet(ctx, c.dbName, c.name)
+
+	insertQ := insertQuery(meta) + fmt.Sprintf(` ON CONFLICT (%s) DO NOTHING`, meta.IDColumn())
+	replaceQ := fmt.Sprintf(`UPDATE %q SET %s = ? WHERE %s = ?`, meta.TableName, metadata.DefaultColumn, meta.IDColumn())
+
+	var res backends.UpsertAllResult
+
+	err := db.InTransaction(ctx, func(tx *fsql.Tx) error {
+		for _, doc := range params.Docs {
+			b, err := sjson.Marshal(doc)
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			r, err := tx.ExecContext(ctx, insertQ, string(b))
+			if err != nil {
+				if e := duplicateKeyError(meta, err); e != nil {
+					return e
+				}
+
+				return lazyerrors.Error(err)
+			}
+
+			ra, err := r.RowsAffected()
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			if ra == 1 {
+				res.Inserted++
+				continue
+			}
+
+			id := must.NotFail(doc.Get("_id"))
+			idArg := string(must.NotFail(sjson.MarshalSingleValue(id)))
+
+			if _, err = tx.ExecContext(ctx, replaceQ, string(b), idArg); err != nil {
+				if e := duplicateKeyError(meta, err); e != nil {
+					return e
+				}
+
+				return lazyerrors.Error(err)
+			}
+
+			res.Replaced++
+		}
+
+		if meta.Capped() {
+			return evictCapped(ctx, tx, meta)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// insertQuery returns a query that inserts a single sjson-encoded document passed as the only argument.
+//
+// For clustered collections, the _id column is filled from the document.
+func insertQuery(meta *metadata.Collection) string {
+	if meta.Settings.Clustered {
+		return fmt.Sprintf(
+			`INSERT INTO %q (%s, %s) VALUES (?1->'$._id', ?1)`,
+			meta.TableName, metadata.ClusteredIDColumn, metadata.DefaultColumn,
+		)
+	}
+
+	return fmt.Sprintf(`INSERT INTO %q (%s) VALUES (?)`, meta.TableName, metadata.DefaultColumn)
+}
+
+// duplicateKeyError returns backend error if err is a violation of the unique index, and nil otherwise.
+//
+// Violations of the default _id index (or the primary key of clustered collections)
+// are reported as ErrorCodeInsertDuplicateID, other unique indexes - as ErrorCodeIndexDuplicateKey.
+func duplicateKeyError(meta *metadata.Collection, err error) error {
+	var se *sqlite3.Error
+	if !errors.As(err, &se) {
+		return nil
+	}
+
+	if se.Code() == sqlite3lib.SQLITE_CONSTRAINT_PRIMARYKEY && meta.Settings.Clustered {
+		return backends.NewError(backends.ErrorCodeInsertDuplicateID, err)
+	}
+
+	if se.Code() != sqlite3lib.SQLITE_CONSTRAINT_UNIQUE {
+		return nil
+	}
+
+	// SQLite includes the name of the violated expression index in the error message
+	if strings.Contains(se.Error(), fmt.Sprintf("index '%s'", meta.IndexName("_id_"))) {
+		return backends.NewError(backends.ErrorCodeInsertDuplicateID, err)
+	}
+
+	return backends.NewError(backends.ErrorCodeIndexDuplicateKey, err)
+}
+
+// evictCapped removes the oldest documents from the capped collection
+// until it fits into configured size and documents limits.
+//
+// The size of the document is approximated by the length of its SJSON representation.
+// The newest document is never removed.
+func evictCapped(ctx context.Context, tx *fsql.Tx, meta *metadata.Collection) error {
+	if meta.Settings.CappedDocuments > 0 {
+		q := fmt.Sprintf(
+			`DELETE FROM %[2]q WHERE %[1]s IN (SELECT %[1]s FROM %[2]q ORDER BY %[1]s DESC LIMIT -1 OFFSET ?)`,
+			metadata.RecordIDColumn, meta.TableName,
+		)
+
+		if _, err := tx.ExecContext(ctx, q, meta.Settings.CappedDocuments); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	q := fmt.Sprintf(
+		`DELETE FROM %[3]q WHERE %[1]s IN (`+
+			`SELECT %[1]s FROM (SELECT %[1]s, SUM(length(%[2]s)) OVER (ORDER BY %[1]s DESC) AS total FROM %[3]q) `+
+			`WHERE total > ?`+
+			`) AND %[1]s < (SELECT MAX(%[1]s) FROM %[3]q)`,
+		metadata.RecordIDColumn, metadata.DefaultColumn, meta.TableName,
+	)
+
+	if _, err := tx.ExecContext(ctx, q, meta.Settings.CappedSize); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
 // Update implements backends.Collection interface.
 func (c *collection) Update(ctx context.Context, params *backends.UpdateParams) (*backends.UpdateResult, error) {
 	db := c.r.DatabaseGetExisting(ctx, c.dbName)
@@ -131,7 +387,7 @@ func (c *collection) Update(ctx context.Context, params *backends.UpdateParams)
 		return &res, nil
 	}
 
-	q := fmt.Sprintf(`UPDATE %q SET %s = ? WHERE %s = ?`, meta.TableName, metadata.DefaultColumn, metadata.IDColumn)
+	q := fmt.Sprintf(`UPDATE %q SET %s = ? WHERE %s = ?`, meta.TableName, metadata.DefaultColumn, meta.IDColumn())
 
 	iter := params.Docs.Iterator()
 	defer iter.Close()
@@ -158,6 +414,10 @@ func (c *collection) Update(ctx context.Context, params *backends.UpdateParams)
 
 		r, err := db.ExecContext(ctx, q, docArg, idArg)
 		if err != nil {
+			if e := duplicateKeyError(meta, err); e != nil {
+				return nil, e
+			}
+
 			return nil, lazyerrors.Error(err)
 		}
 
@@ -192,7 +452,7 @@ func (c *collection) DeleteAll(ctx context.Context, params *backends.DeleteAllPa
 		args[i] = string(must.NotFail(sjson.MarshalSingleValue(id)))
 	}
 
-	q := fmt.Sprintf(`DELETE FROM %q WHERE %s IN (%s)`, meta.TableName, metadata.IDColumn, strings.Join(placeholders, ", "))
+	q := fmt.Sprintf(`DELETE FROM %q WHERE %s IN (%s)`, meta.TableName, meta.IDColumn(), strings.Join(placeholders, ", "))
 
 	res, err := db.ExecContext(ctx, q, args...)
 	if err != nil {
@@ -215,6 +475,382 @@ func (c *collection) Explain(ctx context.Context, params *backends.ExplainParams
 	panic("not implemented")
 }
 
+// ListIndexes implements backends.Collection interface.
+func (c *collection) ListIndexes(ctx context.Context, params *backends.ListIndexesParams) (*backends.ListIndexesResult, error) {
+	meta := c.r.CollectionGet(ctx, c.dbName, c.name)
+	if meta == nil {
+		return nil, backends.NewError(
+			backends.ErrorCodeCollectionDoesNotExist,
+			lazyerrors.Errorf("no ns %s.%s", c.dbName, c.name),
+		)
+	}
+
+	res := &backends.ListIndexesResult{
+		Indexes: make([]backends.IndexInfo, len(meta.Settings.Indexes)),
+	}
+
+	for i, index := range meta.Settings.Indexes {
+		res.Indexes[i] = backends.IndexInfo{
+			Name:               index.Name,
+			Key:                make([]backends.IndexKeyPair, len(index.Key)),
+			Unique:             index.Unique,
+			ExpireAfterSeconds: index.ExpireAfterSeconds,
+		}
+
+		for j, pair := range index.Key {
+			res.Indexes[i].Key[j] = backends.IndexKeyPair{
+				Field:      pair.Field,
+				Descending: pair.Descending,
+				Sphere:     pair.Sphere,
+				Text:       pair.Text,
+			}
+		}
+
+		if index.Text != nil {
+			res.Indexes[i].Text = &backends.TextIndexInfo{
+				Weights:          index.Text.Weights,
+				DefaultLanguage:  index.Text.DefaultLanguage,
+				LanguageOverride: index.Text.LanguageOverride,
+			}
+		}
+	}
+
+	sort.Slice(res.Indexes, func(i, j int) bool {
+		return res.Indexes[i].Name < res.Indexes[j].Name
+	})
+
+	return res, nil
+}
+
+// CreateIndexes implements backends.Collection interface.
+//
+//nolint:lll // for readability
+func (c *collection) CreateIndexes(ctx context.Context, params *backends.CreateIndexesParams) (*backends.CreateIndexesResult, error) {
+	indexes := make([]metadata.IndexInfo, len(params.Indexes))
+
+	for i, index := range params.Indexes {
+		indexes[i] = metadata.IndexInfo{
+			Name:               index.Name,
+			Key:                make([]metadata.IndexKeyPair, len(index.Key)),
+			Unique:             index.Unique,
+			ExpireAfterSeconds: index.ExpireAfterSeconds,
+		}
+
+		for j, pair := range index.Key {
+			indexes[i].Key[j] = metadata.IndexKeyPair{
+				Field:      pair.Field,
+				Descending: pair.Descending,
+				Sphere:     pair.Sphere,
+				Text:       pair.Text,
+			}
+		}
+
+		if index.Text != nil {
+			indexes[i].Text = &metadata.TextIndexInfo{
+				Weights:          index.Text.Weights,
+				DefaultLanguage:  index.Text.DefaultLanguage,
+				LanguageOverride: index.Text.LanguageOverride,
+			}
+		}
+	}
+
+	err := c.r.IndexesCreate(ctx, c.dbName, c.name, indexes)
+	if err != nil {
+		var se *sqlite3.Error
+		if errors.As(err, &se) && se.Code() == sqlite3lib.SQLITE_CONSTRAINT_UNIQUE {
+			return nil, backends.NewError(backends.ErrorCodeIndexDuplicateKey, err)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	return new(backends.CreateIndexesResult), nil
+}
+
+// DropIndexes implements backends.Collection interface.
+func (c *collection) DropIndexes(ctx context.Context, params *backends.DropIndexesParams) (*backends.DropIndexesResult, error) {
+	if err := c.r.IndexesDrop(ctx, c.dbName, c.name, params.Indexes); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return new(backends.DropIndexesResult), nil
+}
+
+// ReIndex implements backends.Collection interface.
+func (c *collection) ReIndex(ctx context.Context, params *backends.ReIndexParams) (*backends.ReIndexResult, error) {
+	db := c.r.DatabaseGetExisting(ctx, c.dbName)
+	if db == nil {
+		return nil, backends.NewError(
+			backends.ErrorCodeCollectionDoesNotExist,
+			lazyerrors.Errorf("no ns %s.%s", c.dbName, c.name),
+		)
+	}
+
+	meta := c.r.CollectionGet(ctx, c.dbName, c.name)
+	if meta == nil {
+		return nil, backends.NewError(
+			backends.ErrorCodeCollectionDoesNotExist,
+			lazyerrors.Errorf("no ns %s.%s", c.dbName, c.name),
+		)
+	}
+
+	// REINDEX with a table name rebuilds all indexes of that table
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(`REINDEX %q`, meta.TableName)); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return new(backends.ReIndexResult), nil
+}
+
+// Compact implements backends.Collection interface.
+//
+// VACUUM rebuilds the whole database file, including all tables and indexes,
+// so other collections of the database are compacted too.
+// Record IDs (rowid) of non-capped collections may change, invalidating their resume tokens.
+func (c *collection) Compact(ctx context.Context, params *backends.CompactParams) (*backends.CompactResult, error) {
+	db := c.r.DatabaseGetExisting(ctx, c.dbName)
+	if db == nil {
+		return nil, backends.NewError(
+			backends.ErrorCodeCollectionDoesNotExist,
+			lazyerrors.Errorf("no ns %s.%s", c.dbName, c.name),
+		)
+	}
+
+	if meta := c.r.CollectionGet(ctx, c.dbName, c.name); meta == nil {
+		return nil, backends.NewError(
+			backends.ErrorCodeCollectionDoesNotExist,
+			lazyerrors.Errorf("no ns %s.%s", c.dbName, c.name),
+		)
+	}
+
+	var pageSize, pageCount, freePages int64
+
+	q := `SELECT page_size, page_count, freelist_count FROM pragma_page_size, pragma_page_count, pragma_freelist_count`
+	if err := db.QueryRowContext(ctx, q).Scan(&pageSize, &pageCount, &freePages); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if params != nil && params.DryRun {
+		return &backends.CompactResult{
+			BytesFreed: freePages * pageSize,
+		}, nil
+	}
+
+	if _, err := db.ExecContext(ctx, `VACUUM`); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var newPageCount int64
+	if err := db.QueryRowContext(ctx, `SELECT page_count FROM pragma_page_count`).Scan(&newPageCount); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var freed int64
+	if newPageCount < pageCount {
+		freed = (pageCount - newPageCount) * pageSize
+	}
+
+	return &backends.CompactResult{
+		BytesFreed: freed,
+	}, nil
+}
+
+// Validate implements backends.Collection interface.
+//
+// Each SQLite index has exactly one entry per row, so SQLite's integrity check is used to find inconsistencies.
+func (c *collection) Validate(ctx context.Context, params *backends.ValidateParams) (*backends.ValidateResult, error) {
+	db := c.r.DatabaseGetExisting(ctx, c.dbName)
+	if db == nil {
+		return nil, backends.NewError(
+			backends.ErrorCodeCollectionDoesNotExist,
+			lazyerrors.Errorf("no ns %s.%s", c.dbName, c.name),
+		)
+	}
+
+	meta := c.r.CollectionGet(ctx, c.dbName, c.name)
+	if meta == nil {
+		return nil, backends.NewError(
+			backends.ErrorCodeCollectionDoesNotExist,
+			lazyerrors.Errorf("no ns %s.%s", c.dbName, c.name),
+		)
+	}
+
+	// rows are identified by rowid, except for WITHOUT ROWID tables of clustered collections;
+	// the record ID is 0 for them, like in Query
+	q := fmt.Sprintf(`SELECT rowid, rowid, %s FROM %q`, metadata.DefaultColumn, meta.TableName)
+	if meta.Settings.Clustered {
+		q = fmt.Sprintf(`SELECT 0, %s, %s FROM %q`, metadata.ClusteredIDColumn, metadata.DefaultColumn, meta.TableName)
+	}
+
+	rows, err := db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	defer rows.Close()
+
+	res := new(backends.ValidateResult)
+
+	var corruptKeys []any
+
+	for rows.Next() {
+		var recordID int64
+		var key any
+		var b []byte
+
+		if err = rows.Scan(&recordID, &key, &b); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		res.NRecords++
+
+		doc, err := sjson.Unmarshal(b)
+		if err != nil {
+			res.CorruptRecords = append(res.CorruptRecords, recordID)
+			corruptKeys = append(corruptKeys, key)
+
+			continue
+		}
+
+		if err = doc.ValidateData(); err != nil {
+			res.InvalidRecords = append(res.InvalidRecords, recordID)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// release the connection before the repair
+	if err = rows.Close(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if res.IndexErrors, err = integrityCheck(ctx, db, meta.TableName); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if params != nil && params.Repair {
+		keyColumn := "rowid"
+		if meta.Settings.Clustered {
+			keyColumn = metadata.ClusteredIDColumn
+		}
+
+		q = fmt.Sprintf(`DELETE FROM %q WHERE %s = ?`, meta.TableName, keyColumn)
+
+		err = db.InTransaction(ctx, func(tx *fsql.Tx) error {
+			for _, key := range corruptKeys {
+				r, err := tx.ExecContext(ctx, q, key)
+				if err != nil {
+					return lazyerrors.Error(err)
+				}
+
+				ra, err := r.RowsAffected()
+				if err != nil {
+					return lazyerrors.Error(err)
+				}
+
+				res.RemovedRecords += ra
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		res.NRecords -= res.RemovedRecords
+
+		if len(res.IndexErrors) > 0 {
+			// REINDEX with a table name rebuilds all indexes of that table
+			if _, err = db.ExecContext(ctx, fmt.Sprintf(`REINDEX %q`, meta.TableName)); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			res.IndexesRebuilt = true
+
+			if res.IndexErrors, err = integrityCheck(ctx, db, meta.TableName); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+		}
+	}
+
+	res.KeysPerIndex = make(map[string]int64, len(meta.Settings.Indexes))
+	for _, index := range meta.Settings.Indexes {
+		res.KeysPerIndex[index.Name] = res.NRecords
+	}
+
+	return res, nil
+}
+
+// integrityCheck runs SQLite integrity check for the given table and its indexes.
+//
+// It returns found problems, if any.
+func integrityCheck(ctx context.Context, db *fsql.DB, tableName string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`PRAGMA integrity_check(%q)`, tableName))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	defer rows.Close()
+
+	var res []string
+
+	for rows.Next() {
+		var msg string
+		if err = rows.Scan(&msg); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if msg != "ok" {
+			res = append(res, msg)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
+
+// UpdateMetadata implements backends.Collection interface.
+//
+//nolint:lll // for readability
+func (c *collection) UpdateMetadata(ctx context.Context, params *backends.UpdateMetadataParams) (*backends.UpdateMetadataResult, error) {
+	p := &metadata.CollectionUpdateParams{
+		DBName:             c.dbName,
+		Name:               c.name,
+		Index:              params.Index,
+		ExpireAfterSeconds: params.ExpireAfterSeconds,
+	}
+
+	if params.Validator != nil {
+		v, err := marshalValidator(params.Validator)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		// empty validator removes the existing one
+		p.Validator = new(metadata.Validator)
+		if v != nil {
+			p.Validator = v
+		}
+	}
+
+	exists, err := c.r.CollectionUpdate(ctx, p)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if !exists {
+		return nil, backends.NewError(
+			backends.ErrorCodeCollectionDoesNotExist,
+			lazyerrors.Errorf("no ns %s.%s", c.dbName, c.name),
+		)
+	}
+
+	return new(backends.UpdateMetadataResult), nil
+}
+
 // check interfaces
 var (
 	_ backends.Collection = (*collection)(nil)
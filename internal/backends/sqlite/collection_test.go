@@ -15,13 +15,25 @@
 package sqlite
 
 import (
+	"database/sql"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/AlekSi/pointer"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/util/testutil"
+	"github.com/FerretDB/FerretDB/internal/util/testutil/teststress"
 )
 
 func TestInsert(t *testing.T) {
@@ -53,3 +65,1195 @@ func TestInsert(t *testing.T) {
 	})
 	require.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID))
 }
+
+func TestUpsertAll(t *testing.T) {
+	b, err := NewBackend(&NewBackendParams{URI: "file:./?mode=memory", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	c, err := db.Collection(testutil.CollectionName(t))
+	require.NoError(t, err)
+
+	ctx := testutil.Ctx(t)
+
+	res, err := c.UpsertAll(ctx, &backends.UpsertAllParams{
+		Docs: []*types.Document{
+			must.NotFail(types.NewDocument("_id", int32(1), "v", "foo")),
+			must.NotFail(types.NewDocument("_id", int32(2), "v", "bar")),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &backends.UpsertAllResult{Inserted: 2}, res)
+
+	res, err = c.UpsertAll(ctx, &backends.UpsertAllParams{
+		Docs: []*types.Document{
+			must.NotFail(types.NewDocument("_id", int32(2), "v", "baz")),
+			must.NotFail(types.NewDocument("_id", int32(3), "v", "qux")),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &backends.UpsertAllResult{Inserted: 1, Replaced: 1}, res)
+
+	queryRes, err := c.Query(ctx, nil)
+	require.NoError(t, err)
+
+	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](queryRes.Iter))
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+	assert.Equal(t, "baz", must.NotFail(docs[1].Get("v")))
+
+	t.Run("Stress", func(t *testing.T) {
+		var i atomic.Int32
+
+		teststress.Stress(t, func(ready chan<- struct{}, start <-chan struct{}) {
+			doc := must.NotFail(types.NewDocument("_id", "stress", "v", i.Add(1)))
+
+			ready <- struct{}{}
+			<-start
+
+			_, err := c.UpsertAll(ctx, &backends.UpsertAllParams{Docs: []*types.Document{doc}})
+			assert.NoError(t, err)
+		})
+	})
+}
+
+func TestCappedCollection(t *testing.T) {
+	b, err := NewBackend(&NewBackendParams{URI: "file:./?mode=memory", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	ctx := testutil.Ctx(t)
+
+	t.Run("Documents", func(t *testing.T) {
+		name := testutil.CollectionName(t)
+
+		err = db.CreateCollection(ctx, &backends.CreateCollectionParams{
+			Name:   name,
+			Capped: &backends.CappedParams{Size: 1 << 20, Documents: 3},
+		})
+		require.NoError(t, err)
+
+		c, err := db.Collection(name)
+		require.NoError(t, err)
+
+		for i := int32(1); i <= 5; i++ {
+			_, err = c.InsertAll(ctx, &backends.InsertAllParams{
+				Docs: []*types.Document{must.NotFail(types.NewDocument("_id", i))},
+			})
+			require.NoError(t, err)
+		}
+
+		res, err := c.Query(ctx, nil)
+		require.NoError(t, err)
+
+		docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](res.Iter))
+		require.NoError(t, err)
+		require.Len(t, docs, 3)
+
+		for i, doc := range docs {
+			assert.Equal(t, int32(i+3), must.NotFail(doc.Get("_id")))
+			assert.Equal(t, int64(i+3), doc.RecordID())
+		}
+
+		res, err = c.Query(ctx, &backends.QueryParams{RecordIDAfter: 4})
+		require.NoError(t, err)
+
+		docs, err = iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](res.Iter))
+		require.NoError(t, err)
+		require.Len(t, docs, 1)
+		assert.Equal(t, int32(5), must.NotFail(docs[0].Get("_id")))
+
+		list, err := db.ListCollections(ctx, nil)
+		require.NoError(t, err)
+		require.Len(t, list.Collections, 1)
+		assert.Equal(t, &backends.CappedParams{Size: 1 << 20, Documents: 3}, list.Collections[0].Capped)
+	})
+
+	t.Run("Size", func(t *testing.T) {
+		name := testutil.CollectionName(t)
+
+		err = db.CreateCollection(ctx, &backends.CreateCollectionParams{
+			Name:   name,
+			Capped: &backends.CappedParams{Size: 1},
+		})
+		require.NoError(t, err)
+
+		c, err := db.Collection(name)
+		require.NoError(t, err)
+
+		_, err = c.InsertAll(ctx, &backends.InsertAllParams{
+			Docs: []*types.Document{
+				must.NotFail(types.NewDocument("_id", int32(1))),
+				must.NotFail(types.NewDocument("_id", int32(2))),
+			},
+		})
+		require.NoError(t, err)
+
+		res, err := c.Query(ctx, nil)
+		require.NoError(t, err)
+
+		docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](res.Iter))
+		require.NoError(t, err)
+		require.Len(t, docs, 1)
+		assert.Equal(t, int32(2), must.NotFail(docs[0].Get("_id")))
+	})
+}
+
+func TestQuerySample(t *testing.T) {
+	b, err := NewBackend(&NewBackendParams{URI: "file:./?mode=memory", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	c, err := db.Collection(testutil.CollectionName(t))
+	require.NoError(t, err)
+
+	ctx := testutil.Ctx(t)
+
+	docs := make([]*types.Document, 10)
+	for i := range docs {
+		docs[i] = must.NotFail(types.NewDocument("_id", int32(i)))
+	}
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: docs})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		sample   int64
+		expected int
+	}{
+		{sample: 3, expected: 3},
+		{sample: 100, expected: 10},
+	} {
+		res, err := c.Query(ctx, &backends.QueryParams{Sample: tc.sample})
+		require.NoError(t, err)
+
+		sampled, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](res.Iter))
+		require.NoError(t, err)
+		require.Len(t, sampled, tc.expected)
+
+		seen := map[any]struct{}{}
+
+		for _, doc := range sampled {
+			id := must.NotFail(doc.Get("_id"))
+			assert.NotContains(t, seen, id)
+			seen[id] = struct{}{}
+		}
+	}
+}
+
+func TestIndexes(t *testing.T) {
+	b, err := NewBackend(&NewBackendParams{URI: "file:./?mode=memory", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	c, err := db.Collection(testutil.CollectionName(t))
+	require.NoError(t, err)
+
+	ctx := testutil.Ctx(t)
+
+	_, err = c.ListIndexes(ctx, nil)
+	require.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist))
+
+	_, err = c.ReIndex(ctx, nil)
+	require.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist))
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{
+		Docs: []*types.Document{
+			must.NotFail(types.NewDocument("_id", int32(1), "a", "x", "b", int32(1))),
+			must.NotFail(types.NewDocument("_id", int32(2), "a", "x", "b", int32(2))),
+			must.NotFail(types.NewDocument("_id", int32(3), "a", "y")),
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = c.CreateIndexes(ctx, &backends.CreateIndexesParams{
+		Indexes: []backends.IndexInfo{{
+			Name:   "a_1",
+			Key:    []backends.IndexKeyPair{{Field: "a"}},
+			Unique: true,
+		}},
+	})
+	require.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeIndexDuplicateKey), "%v", err)
+
+	compound := backends.IndexInfo{
+		Name:   "a_1_b_-1",
+		Key:    []backends.IndexKeyPair{{Field: "a"}, {Field: "b", Descending: true}},
+		Unique: true,
+	}
+
+	_, err = c.CreateIndexes(ctx, &backends.CreateIndexesParams{Indexes: []backends.IndexInfo{compound}})
+	require.NoError(t, err)
+
+	list, err := c.ListIndexes(ctx, nil)
+	require.NoError(t, err)
+
+	expected := []backends.IndexInfo{
+		{
+			Name:   "_id_",
+			Key:    []backends.IndexKeyPair{{Field: "_id"}},
+			Unique: true,
+		},
+		compound,
+	}
+	assert.Equal(t, expected, list.Indexes)
+
+	t.Run("Insert", func(t *testing.T) {
+		_, err = c.InsertAll(ctx, &backends.InsertAllParams{
+			Docs: []*types.Document{must.NotFail(types.NewDocument("_id", int32(4), "a", "x", "b", int32(1)))},
+		})
+		assert.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeIndexDuplicateKey), "%v", err)
+
+		// missing fields are indexed as nulls
+		_, err = c.InsertAll(ctx, &backends.InsertAllParams{
+			Docs: []*types.Document{must.NotFail(types.NewDocument("_id", int32(4), "a", "y", "b", types.Null))},
+		})
+		assert.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeIndexDuplicateKey), "%v", err)
+
+		_, err = c.InsertAll(ctx, &backends.InsertAllParams{
+			Docs: []*types.Document{must.NotFail(types.NewDocument("_id", int32(1), "a", "z"))},
+		})
+		assert.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID), "%v", err)
+	})
+
+	t.Run("Upsert", func(t *testing.T) {
+		_, err = c.UpsertAll(ctx, &backends.UpsertAllParams{
+			Docs: []*types.Document{must.NotFail(types.NewDocument("_id", int32(4), "a", "x", "b", int32(1)))},
+		})
+		assert.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeIndexDuplicateKey), "%v", err)
+
+		_, err = c.UpsertAll(ctx, &backends.UpsertAllParams{
+			Docs: []*types.Document{must.NotFail(types.NewDocument("_id", int32(2), "a", "x", "b", int32(1)))},
+		})
+		assert.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeIndexDuplicateKey), "%v", err)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		_, err = c.Update(ctx, &backends.UpdateParams{
+			Docs: must.NotFail(types.NewArray(must.NotFail(types.NewDocument("_id", int32(2), "a", "x", "b", int32(1))))),
+		})
+		assert.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeIndexDuplicateKey), "%v", err)
+	})
+
+	t.Run("ReIndex", func(t *testing.T) {
+		_, err = c.ReIndex(ctx, nil)
+		require.NoError(t, err)
+
+		list, err = c.ListIndexes(ctx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, expected, list.Indexes)
+
+		_, err = c.InsertAll(ctx, &backends.InsertAllParams{
+			Docs: []*types.Document{must.NotFail(types.NewDocument("_id", int32(4), "a", "x", "b", int32(1)))},
+		})
+		assert.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeIndexDuplicateKey), "%v", err)
+	})
+
+	_, err = c.DropIndexes(ctx, &backends.DropIndexesParams{Indexes: []string{compound.Name, "missing"}})
+	require.NoError(t, err)
+
+	list, err = c.ListIndexes(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, expected[:1], list.Indexes)
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{
+		Docs: []*types.Document{must.NotFail(types.NewDocument("_id", int32(4), "a", "x", "b", int32(1)))},
+	})
+	require.NoError(t, err)
+}
+
+func TestUpdateMetadata(t *testing.T) {
+	b, err := NewBackend(&NewBackendParams{URI: "file:./?mode=memory", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	collectionName := testutil.CollectionName(t)
+	c, err := db.Collection(collectionName)
+	require.NoError(t, err)
+
+	ctx := testutil.Ctx(t)
+
+	_, err = c.UpdateMetadata(ctx, new(backends.UpdateMetadataParams))
+	require.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist))
+
+	validator := &backends.ValidatorParams{
+		Filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$type", "string")))),
+		Level:  "strict",
+		Action: "error",
+	}
+
+	err = db.CreateCollection(ctx, &backends.CreateCollectionParams{Name: collectionName, Validator: validator})
+	require.NoError(t, err)
+
+	ttl := int64(3600)
+	_, err = c.CreateIndexes(ctx, &backends.CreateIndexesParams{
+		Indexes: []backends.IndexInfo{{
+			Name:               "v_1",
+			Key:                []backends.IndexKeyPair{{Field: "v"}},
+			ExpireAfterSeconds: &ttl,
+		}},
+	})
+	require.NoError(t, err)
+
+	getValidator := func() *backends.ValidatorParams {
+		list, err := db.ListCollections(ctx, nil)
+		require.NoError(t, err)
+		require.Len(t, list.Collections, 1)
+
+		return list.Collections[0].Validator
+	}
+
+	assert.Equal(t, validator, getValidator())
+
+	validator.Level = "moderate"
+
+	_, err = c.UpdateMetadata(ctx, &backends.UpdateMetadataParams{
+		Validator:          validator,
+		Index:              "v_1",
+		ExpireAfterSeconds: 60,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, validator, getValidator())
+
+	indexes, err := c.ListIndexes(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, indexes.Indexes, 2)
+	assert.Equal(t, int64(60), *indexes.Indexes[1].ExpireAfterSeconds)
+
+	_, err = c.UpdateMetadata(ctx, &backends.UpdateMetadataParams{Validator: new(backends.ValidatorParams)})
+	require.NoError(t, err)
+
+	assert.Nil(t, getValidator())
+}
+
+func TestCreateCollectionCollation(t *testing.T) {
+	b, err := NewBackend(&NewBackendParams{URI: "file:./?mode=memory", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	ctx := testutil.Ctx(t)
+
+	collation := must.NotFail(types.NewDocument("locale", "en", "strength", int32(2)))
+
+	err = db.CreateCollection(ctx, &backends.CreateCollectionParams{Name: "collation", Collation: collation})
+	require.NoError(t, err)
+
+	err = db.CreateCollection(ctx, &backends.CreateCollectionParams{Name: "simple"})
+	require.NoError(t, err)
+
+	list, err := db.ListCollections(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, list.Collections, 2)
+
+	assert.Equal(t, "collation", list.Collections[0].Name)
+	assert.Equal(t, collation, list.Collections[0].Collation)
+
+	assert.Equal(t, "simple", list.Collections[1].Name)
+	assert.Nil(t, list.Collections[1].Collation)
+
+	list, err = db.ListCollections(ctx, &backends.ListCollectionsParams{Name: "collation"})
+	require.NoError(t, err)
+	require.Len(t, list.Collections, 1)
+	assert.Equal(t, collation, list.Collections[0].Collation)
+
+	list, err = db.ListCollections(ctx, &backends.ListCollectionsParams{Name: "missing"})
+	require.NoError(t, err)
+	assert.Empty(t, list.Collections)
+}
+
+func TestClusteredCollection(t *testing.T) {
+	b, err := NewBackend(&NewBackendParams{URI: "file:./?mode=memory", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	ctx := testutil.Ctx(t)
+	cName := testutil.CollectionName(t)
+
+	err = db.CreateCollection(ctx, &backends.CreateCollectionParams{Name: cName, Clustered: true})
+	require.NoError(t, err)
+
+	list, err := db.ListCollections(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, list.Collections, 1)
+	assert.True(t, list.Collections[0].Clustered)
+
+	c, err := db.Collection(cName)
+	require.NoError(t, err)
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{
+		must.NotFail(types.NewDocument("_id", "c", "v", int32(1))),
+		must.NotFail(types.NewDocument("_id", "a", "v", int32(2))),
+		must.NotFail(types.NewDocument("_id", "b", "v", int32(3))),
+	}})
+	require.NoError(t, err)
+
+	queryIDs := func(t *testing.T, params *backends.QueryParams) []any {
+		t.Helper()
+
+		res, err := c.Query(ctx, params)
+		require.NoError(t, err)
+
+		docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](res.Iter))
+		require.NoError(t, err)
+
+		ids := make([]any, len(docs))
+		for i, doc := range docs {
+			ids[i] = must.NotFail(doc.Get("_id"))
+		}
+
+		return ids
+	}
+
+	t.Run("NaturalOrder", func(t *testing.T) {
+		assert.Equal(t, []any{"a", "b", "c"}, queryIDs(t, nil))
+		assert.Equal(t, []any{"c", "b", "a"}, queryIDs(t, &backends.QueryParams{ReverseNatural: true}))
+	})
+
+	t.Run("QueryID", func(t *testing.T) {
+		assert.Equal(t, []any{"b"}, queryIDs(t, &backends.QueryParams{ID: "b"}))
+	})
+
+	t.Run("DuplicateID", func(t *testing.T) {
+		_, err := c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{
+			must.NotFail(types.NewDocument("_id", "a")),
+		}})
+		assert.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID), "%v", err)
+	})
+
+	t.Run("UpsertUpdateDelete", func(t *testing.T) {
+		res, err := c.UpsertAll(ctx, &backends.UpsertAllParams{Docs: []*types.Document{
+			must.NotFail(types.NewDocument("_id", "a", "v", int32(4))),
+			must.NotFail(types.NewDocument("_id", "d", "v", int32(5))),
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, &backends.UpsertAllResult{Inserted: 1, Replaced: 1}, res)
+
+		updated, err := c.Update(ctx, &backends.UpdateParams{
+			Docs: must.NotFail(types.NewArray(must.NotFail(types.NewDocument("_id", "b", "v", int32(6))))),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), updated.Updated)
+
+		deleted, err := c.DeleteAll(ctx, &backends.DeleteAllParams{IDs: []any{"c", "e"}})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), deleted.Deleted)
+
+		assert.Equal(t, []any{"a", "b", "d"}, queryIDs(t, nil))
+	})
+}
+
+func TestQueryID(t *testing.T) {
+	b, err := NewBackend(&NewBackendParams{URI: "file:./?mode=memory", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	c, err := db.Collection(testutil.CollectionName(t))
+	require.NoError(t, err)
+
+	ctx := testutil.Ctx(t)
+
+	id := types.NewObjectID()
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{
+		must.NotFail(types.NewDocument("_id", "1")),
+		must.NotFail(types.NewDocument("_id", id)),
+		must.NotFail(types.NewDocument("_id", int32(1))),
+	}})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		id       any
+		index    string
+		expected int
+	}{
+		"String":       {id: "1", expected: 1},
+		"ObjectID":     {id: id, expected: 1},
+		"Missing":      {id: "2", expected: 0},
+		"Ignored":      {id: int32(1), expected: 3},
+		"IDIndex":      {id: "1", index: "_id_", expected: 1},
+		"AnotherIndex": {id: "1", index: "v_1", expected: 3},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := c.Query(ctx, &backends.QueryParams{ID: tc.id, Index: tc.index})
+			require.NoError(t, err)
+
+			docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](res.Iter))
+			require.NoError(t, err)
+			assert.Len(t, docs, tc.expected)
+		})
+	}
+}
+
+func TestQueryFilter(t *testing.T) {
+	b, err := NewBackend(&NewBackendParams{URI: "file:./?mode=memory", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	c, err := db.Collection(testutil.CollectionName(t))
+	require.NoError(t, err)
+
+	ctx := testutil.Ctx(t)
+
+	id := types.NewObjectID()
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{
+		must.NotFail(types.NewDocument("_id", "int", "v", int32(42))),
+		must.NotFail(types.NewDocument("_id", "long", "v", int64(43))),
+		must.NotFail(types.NewDocument("_id", "double", "v", 41.5)),
+		must.NotFail(types.NewDocument("_id", "string", "v", "foo")),
+		must.NotFail(types.NewDocument("_id", "objectID", "v", id)),
+		must.NotFail(types.NewDocument("_id", "bool", "v", true)),
+		must.NotFail(types.NewDocument("_id", "array", "v", must.NotFail(types.NewArray(int32(1))))),
+		must.NotFail(types.NewDocument("_id", "nested", "v", must.NotFail(types.NewDocument("foo", int32(42))))),
+		must.NotFail(types.NewDocument("_id", "nestedArray", "v", must.NotFail(types.NewArray(
+			must.NotFail(types.NewDocument("foo", int32(1))),
+		)))),
+		must.NotFail(types.NewDocument("_id", "missing")),
+	}})
+	require.NoError(t, err)
+
+	v := types.NewStaticPath("v")
+	foo := types.NewStaticPath("v", "foo")
+
+	for name, tc := range map[string]struct {
+		filter   []backends.FilterCondition
+		expected []any
+	}{
+		"EqInt": {
+			filter:   []backends.FilterCondition{{Path: v, Op: backends.FilterEq, Value: int64(42)}},
+			expected: []any{"int", "array", "nestedArray"},
+		},
+		"EqDouble": {
+			filter:   []backends.FilterCondition{{Path: v, Op: backends.FilterEq, Value: 43.0}},
+			expected: []any{"long", "array", "nestedArray"},
+		},
+		"EqString": {
+			filter:   []backends.FilterCondition{{Path: v, Op: backends.FilterEq, Value: "foo"}},
+			expected: []any{"string", "array", "nestedArray"},
+		},
+		"EqObjectID": {
+			filter:   []backends.FilterCondition{{Path: v, Op: backends.FilterEq, Value: id}},
+			expected: []any{"objectID", "array", "nestedArray"},
+		},
+		"EqBool": {
+			filter:   []backends.FilterCondition{{Path: v, Op: backends.FilterEq, Value: true}},
+			expected: []any{"bool", "array", "nestedArray"},
+		},
+		// numbers equal to the boundary values of strict ranges are returned too; see numberBounds
+		"Gt": {
+			filter:   []backends.FilterCondition{{Path: v, Op: backends.FilterGt, Value: int32(42)}},
+			expected: []any{"int", "long", "array", "nestedArray"},
+		},
+		"Gte": {
+			filter:   []backends.FilterCondition{{Path: v, Op: backends.FilterGte, Value: int32(42)}},
+			expected: []any{"int", "long", "array", "nestedArray"},
+		},
+		"Lt": {
+			filter:   []backends.FilterCondition{{Path: v, Op: backends.FilterLt, Value: int64(42)}},
+			expected: []any{"int", "double", "array", "nestedArray"},
+		},
+		"LteString": {
+			filter:   []backends.FilterCondition{{Path: v, Op: backends.FilterLte, Value: "foo"}},
+			expected: []any{"string", "objectID", "array", "nestedArray"},
+		},
+		"Range": {
+			filter: []backends.FilterCondition{
+				{Path: v, Op: backends.FilterGte, Value: 42.0},
+				{Path: v, Op: backends.FilterLte, Value: 42.5},
+			},
+			expected: []any{"int", "array", "nestedArray"},
+		},
+		"In": {
+			filter:   []backends.FilterCondition{{Path: v, Op: backends.FilterIn, Value: []any{int32(42), "foo"}}},
+			expected: []any{"int", "string", "array", "nestedArray"},
+		},
+		"DotNotation": {
+			filter:   []backends.FilterCondition{{Path: foo, Op: backends.FilterEq, Value: int32(42)}},
+			expected: []any{"array", "nested", "nestedArray"},
+		},
+		"Unsupported": {
+			filter:   []backends.FilterCondition{{Path: v, Op: backends.FilterEq, Value: types.Null}},
+			expected: []any{"int", "long", "double", "string", "objectID", "bool", "array", "nested", "nestedArray", "missing"},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			res, err := c.Query(ctx, &backends.QueryParams{Filter: tc.filter})
+			require.NoError(t, err)
+
+			docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](res.Iter))
+			require.NoError(t, err)
+
+			ids := make([]any, len(docs))
+			for i, doc := range docs {
+				ids[i] = must.NotFail(doc.Get("_id"))
+			}
+
+			assert.Equal(t, tc.expected, ids)
+		})
+	}
+}
+
+func TestQuerySort(t *testing.T) {
+	b, err := NewBackend(&NewBackendParams{URI: "file:./?mode=memory", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	c, err := db.Collection(testutil.CollectionName(t))
+	require.NoError(t, err)
+
+	ctx := testutil.Ctx(t)
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{
+		must.NotFail(types.NewDocument("_id", "int", "v", int32(42))),
+		must.NotFail(types.NewDocument("_id", "true", "v", true)),
+		must.NotFail(types.NewDocument("_id", "null", "v", types.Null)),
+		must.NotFail(types.NewDocument("_id", "foo", "v", "foo")),
+		must.NotFail(types.NewDocument("_id", "long", "v", int64(43))),
+		must.NotFail(types.NewDocument("_id", "date", "v", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))),
+		must.NotFail(types.NewDocument("_id", "missing")),
+		must.NotFail(types.NewDocument("_id", "false", "v", false)),
+		must.NotFail(types.NewDocument("_id", "bar", "v", "bar")),
+		must.NotFail(types.NewDocument("_id", "double", "v", 41.5)),
+	}})
+	require.NoError(t, err)
+
+	v := types.NewStaticPath("v")
+
+	for name, tc := range map[string]struct {
+		sort     []backends.SortParams
+		expected []any
+	}{
+		"Ascending": {
+			sort: []backends.SortParams{{Path: v}},
+			expected: []any{
+				"null", "missing", "double", "int", "long", "bar", "foo", "false", "true", "date",
+			},
+		},
+		"Descending": {
+			sort: []backends.SortParams{{Path: v, Descending: true}},
+			expected: []any{
+				"date", "true", "false", "foo", "bar", "long", "int", "double", "null", "missing",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			res, err := c.Query(ctx, &backends.QueryParams{Sort: tc.sort})
+			require.NoError(t, err)
+			assert.True(t, res.SortPushdown)
+
+			docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](res.Iter))
+			require.NoError(t, err)
+
+			ids := make([]any, len(docs))
+			for i, doc := range docs {
+				ids[i] = must.NotFail(doc.Get("_id"))
+			}
+
+			assert.Equal(t, tc.expected, ids)
+		})
+	}
+}
+
+func TestQueryLimit(t *testing.T) {
+	b, err := NewBackend(&NewBackendParams{URI: "file:./?mode=memory", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	c, err := db.Collection(testutil.CollectionName(t))
+	require.NoError(t, err)
+
+	ctx := testutil.Ctx(t)
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{
+		must.NotFail(types.NewDocument("_id", int32(1), "v", "c")),
+		must.NotFail(types.NewDocument("_id", int32(2), "v", "a")),
+		must.NotFail(types.NewDocument("_id", int32(3), "v", "d")),
+		must.NotFail(types.NewDocument("_id", int32(4), "v", "b")),
+	}})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		params        *backends.QueryParams
+		expected      []any
+		limitPushdown bool
+	}{
+		"Limit": {
+			params:        &backends.QueryParams{Limit: 2},
+			expected:      []any{int32(1), int32(2)},
+			limitPushdown: true,
+		},
+		"Skip": {
+			params:        &backends.QueryParams{Skip: 3},
+			expected:      []any{int32(4)},
+			limitPushdown: true,
+		},
+		"LimitSkipReverse": {
+			params:        &backends.QueryParams{Limit: 2, Skip: 1, ReverseNatural: true},
+			expected:      []any{int32(3), int32(2)},
+			limitPushdown: true,
+		},
+		"LimitSort": {
+			params: &backends.QueryParams{
+				Limit: 1,
+				Skip:  1,
+				Sort:  []backends.SortParams{{Path: types.NewStaticPath("v")}},
+			},
+			expected:      []any{int32(4)},
+			limitPushdown: true,
+		},
+		"Filter": {
+			params: &backends.QueryParams{
+				Limit: 1,
+				Filter: []backends.FilterCondition{{
+					Path:  types.NewStaticPath("v"),
+					Op:    backends.FilterGt,
+					Value: "a",
+				}},
+			},
+			expected: []any{int32(1), int32(3), int32(4)},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			res, err := c.Query(ctx, tc.params)
+			require.NoError(t, err)
+			assert.Equal(t, tc.limitPushdown, res.LimitPushdown)
+
+			docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](res.Iter))
+			require.NoError(t, err)
+
+			ids := make([]any, len(docs))
+			for i, doc := range docs {
+				ids[i] = must.NotFail(doc.Get("_id"))
+			}
+
+			assert.Equal(t, tc.expected, ids)
+		})
+	}
+}
+
+func TestQueryFields(t *testing.T) {
+	b, err := NewBackend(&NewBackendParams{URI: "file:./?mode=memory", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	c, err := db.Collection(testutil.CollectionName(t))
+	require.NoError(t, err)
+
+	ctx := testutil.Ctx(t)
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{
+		must.NotFail(types.NewDocument(
+			"_id", int32(1),
+			"a", 0.1+0.2,
+			"b", must.NotFail(types.NewDocument("c", true)),
+			"d", types.Null,
+			"e", "foo",
+		)),
+		must.NotFail(types.NewDocument("_id", int32(2), "e", "bar")),
+	}})
+	require.NoError(t, err)
+
+	res, err := c.Query(ctx, &backends.QueryParams{
+		Fields: []string{"e", "_id", "b", "d", "missing"},
+		Sort:   []backends.SortParams{{Path: types.NewStaticPath("a"), Descending: true}},
+	})
+	require.NoError(t, err)
+
+	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](res.Iter))
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+
+	// fields are returned in the original order
+	testutil.AssertEqual(t, must.NotFail(types.NewDocument(
+		"_id", int32(1),
+		"b", must.NotFail(types.NewDocument("c", true)),
+		"d", types.Null,
+		"e", "foo",
+	)), docs[0])
+	testutil.AssertEqual(t, must.NotFail(types.NewDocument("_id", int32(2), "e", "bar")), docs[1])
+
+	res, err = c.Query(ctx, &backends.QueryParams{
+		Fields: []string{"a"},
+		Filter: []backends.FilterCondition{{Path: types.NewStaticPath("e"), Op: backends.FilterEq, Value: "foo"}},
+	})
+	require.NoError(t, err)
+
+	docs, err = iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](res.Iter))
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+
+	// values are returned as stored
+	testutil.AssertEqual(t, must.NotFail(types.NewDocument("a", 0.1+0.2)), docs[0])
+	assert.Equal(t, int64(1), docs[0].RecordID())
+}
+
+func TestAggregate(t *testing.T) {
+	b, err := NewBackend(&NewBackendParams{URI: "file:./?mode=memory", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	c, err := db.Collection(testutil.CollectionName(t))
+	require.NoError(t, err)
+
+	ctx := testutil.Ctx(t)
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{
+		must.NotFail(types.NewDocument("_id", int32(1), "k", "a", "n", int32(1), "f", true, "l", int64(math.MaxInt64))),
+		must.NotFail(types.NewDocument("_id", int32(2), "k", "b", "n", int64(5), "f", false, "l", int64(1))),
+		must.NotFail(types.NewDocument("_id", int32(3), "k", "a", "n", int32(2), "f", true)),
+		must.NotFail(types.NewDocument("_id", int32(4), "n", int32(3), "d", 1.5)),
+		must.NotFail(types.NewDocument("_id", int32(5), "k", types.Null, "n", "3")),
+	}})
+	require.NoError(t, err)
+
+	accumulators := []backends.AggregateAccumulator{
+		{Name: "count", Op: backends.AggregateCount},
+		{Name: "sum", Op: backends.AggregateSum, Path: types.NewStaticPath("n")},
+		{Name: "avg", Op: backends.AggregateAvg, Path: types.NewStaticPath("n")},
+	}
+
+	for name, tc := range map[string]struct {
+		params   *backends.AggregateParams
+		expected []*types.Document // nil if aggregation is not executed
+	}{
+		"GroupBy": {
+			params: &backends.AggregateParams{
+				GroupBy:      pointer.To(types.NewStaticPath("k")),
+				Accumulators: accumulators,
+			},
+			expected: []*types.Document{
+				must.NotFail(types.NewDocument("_id", "a", "count", int32(2), "sum", int32(3), "avg", 1.5)),
+				must.NotFail(types.NewDocument("_id", "b", "count", int32(1), "sum", int64(5), "avg", 5.0)),
+				must.NotFail(types.NewDocument("_id", types.Null, "count", int32(2), "sum", int32(3), "avg", 3.0)),
+			},
+		},
+		"Filter": {
+			params: &backends.AggregateParams{
+				Filter:       []backends.FilterCondition{{Path: types.NewStaticPath("f"), Op: backends.FilterEq, Value: true}},
+				Accumulators: accumulators,
+			},
+			expected: []*types.Document{
+				must.NotFail(types.NewDocument("_id", types.Null, "count", int32(2), "sum", int32(3), "avg", 1.5)),
+			},
+		},
+		"Empty": {
+			params: &backends.AggregateParams{
+				Filter:       []backends.FilterCondition{{Path: types.NewStaticPath("k"), Op: backends.FilterEq, Value: "c"}},
+				Accumulators: accumulators,
+			},
+			expected: []*types.Document{},
+		},
+		"FilterRange": {
+			params: &backends.AggregateParams{
+				Filter: []backends.FilterCondition{{Path: types.NewStaticPath("k"), Op: backends.FilterGt, Value: "a"}},
+			},
+		},
+		"Double": {
+			params: &backends.AggregateParams{
+				Accumulators: []backends.AggregateAccumulator{
+					{Name: "sum", Op: backends.AggregateSum, Path: types.NewStaticPath("d")},
+				},
+			},
+		},
+		"Overflow": {
+			params: &backends.AggregateParams{
+				Accumulators: []backends.AggregateAccumulator{
+					{Name: "sum", Op: backends.AggregateSum, Path: types.NewStaticPath("l")},
+				},
+			},
+		},
+		"GroupByDouble": {
+			params: &backends.AggregateParams{
+				GroupBy: pointer.To(types.NewStaticPath("d")),
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			res, err := c.Aggregate(ctx, tc.params)
+			require.NoError(t, err)
+
+			if tc.expected == nil {
+				assert.Nil(t, res.Iter)
+				return
+			}
+
+			require.NotNil(t, res.Iter)
+
+			docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](res.Iter))
+			require.NoError(t, err)
+			require.Len(t, docs, len(tc.expected))
+
+			for i, doc := range docs {
+				testutil.AssertEqual(t, tc.expected[i], doc)
+			}
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	b, err := NewBackend(&NewBackendParams{URI: "file:./?mode=memory", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	c, err := db.Collection(testutil.CollectionName(t))
+	require.NoError(t, err)
+
+	ctx := testutil.Ctx(t)
+
+	res, err := c.Count(ctx, &backends.CountParams{Estimated: true})
+	require.NoError(t, err)
+	assert.Equal(t, &backends.CountResult{CountPushdown: true}, res)
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{
+		must.NotFail(types.NewDocument("_id", int32(1), "k", "a")),
+		must.NotFail(types.NewDocument("_id", int32(2), "k", "b")),
+		must.NotFail(types.NewDocument("_id", int32(3), "k", "a")),
+		must.NotFail(types.NewDocument("_id", int32(4), "a", must.NotFail(types.NewArray("a")))),
+	}})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		params   *backends.CountParams
+		expected *backends.CountResult
+	}{
+		"Estimated": {
+			params:   &backends.CountParams{Estimated: true},
+			expected: &backends.CountResult{Count: 4, CountPushdown: true},
+		},
+		"Filter": {
+			params: &backends.CountParams{
+				Filter: []backends.FilterCondition{{Path: types.NewStaticPath("k"), Op: backends.FilterEq, Value: "a"}},
+			},
+			expected: &backends.CountResult{Count: 2, CountPushdown: true},
+		},
+		"FilterRange": {
+			params: &backends.CountParams{
+				Filter: []backends.FilterCondition{{Path: types.NewStaticPath("k"), Op: backends.FilterGt, Value: "a"}},
+			},
+			expected: new(backends.CountResult),
+		},
+		"FilterArray": {
+			params: &backends.CountParams{
+				Filter: []backends.FilterCondition{{Path: types.NewStaticPath("a"), Op: backends.FilterEq, Value: "a"}},
+			},
+			expected: new(backends.CountResult),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			res, err := c.Count(ctx, tc.params)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestCompact(t *testing.T) {
+	b, err := NewBackend(&NewBackendParams{URI: "file:./?mode=memory", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	db, err := b.Database(testutil.DatabaseName(t))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	c, err := db.Collection(testutil.CollectionName(t))
+	require.NoError(t, err)
+
+	ctx := testutil.Ctx(t)
+
+	_, err = c.Compact(ctx, nil)
+	require.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist))
+
+	docs := make([]*types.Document, 1000)
+	ids := make([]any, len(docs))
+
+	for i := range docs {
+		ids[i] = int32(i)
+		docs[i] = must.NotFail(types.NewDocument("_id", ids[i], "v", strings.Repeat("x", 1000)))
+	}
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: docs})
+	require.NoError(t, err)
+
+	_, err = c.DeleteAll(ctx, &backends.DeleteAllParams{IDs: ids})
+	require.NoError(t, err)
+
+	res, err := c.Compact(ctx, &backends.CompactParams{DryRun: true})
+	require.NoError(t, err)
+	estimated := res.BytesFreed
+	assert.Positive(t, estimated)
+
+	res, err = c.Compact(ctx, nil)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, res.BytesFreed, estimated)
+
+	res, err = c.Compact(ctx, &backends.CompactParams{DryRun: true})
+	require.NoError(t, err)
+	assert.Zero(t, res.BytesFreed)
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+
+	b, err := NewBackend(&NewBackendParams{URI: "file:" + dir + "/", L: testutil.Logger(t)})
+	require.NoError(t, err)
+
+	defer b.Close()
+
+	dbName := testutil.DatabaseName(t)
+
+	db, err := b.Database(dbName)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	c, err := db.Collection(testutil.CollectionName(t))
+	require.NoError(t, err)
+
+	ctx := testutil.Ctx(t)
+
+	_, err = c.Validate(ctx, new(backends.ValidateParams))
+	require.True(t, backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist))
+
+	_, err = c.InsertAll(ctx, &backends.InsertAllParams{
+		Docs: []*types.Document{
+			must.NotFail(types.NewDocument("_id", int32(1))),
+			must.NotFail(types.NewDocument("_id", int32(2))),
+			must.NotFail(types.NewDocument("_id", int32(3))),
+		},
+	})
+	require.NoError(t, err)
+
+	res, err := c.Validate(ctx, new(backends.ValidateParams))
+	require.NoError(t, err)
+	assert.Equal(t, &backends.ValidateResult{
+		NRecords:     3,
+		KeysPerIndex: map[string]int64{"_id_": 3},
+	}, res)
+
+	// replace the second document with JSON that is not valid SJSON
+	sqlDB, err := sql.Open("sqlite", "file:"+filepath.Join(dir, dbName+".sqlite"))
+	require.NoError(t, err)
+
+	defer sqlDB.Close()
+
+	var table string
+	q := `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE '_ferretdb_%'`
+	require.NoError(t, sqlDB.QueryRowContext(ctx, q).Scan(&table))
+
+	_, err = sqlDB.ExecContext(ctx, fmt.Sprintf(`UPDATE %q SET _ferretdb_sjson = '{"_id":2}' WHERE rowid = 2`, table))
+	require.NoError(t, err)
+
+	res, err = c.Validate(ctx, new(backends.ValidateParams))
+	require.NoError(t, err)
+	assert.Equal(t, &backends.ValidateResult{
+		NRecords:       3,
+		CorruptRecords: []int64{2},
+		KeysPerIndex:   map[string]int64{"_id_": 3},
+	}, res)
+
+	res, err = c.Validate(ctx, &backends.ValidateParams{Repair: true})
+	require.NoError(t, err)
+	assert.Equal(t, &backends.ValidateResult{
+		NRecords:       2,
+		CorruptRecords: []int64{2},
+		KeysPerIndex:   map[string]int64{"_id_": 2},
+		RemovedRecords: 1,
+	}, res)
+
+	res, err = c.Validate(ctx, new(backends.ValidateParams))
+	require.NoError(t, err)
+	assert.Equal(t, &backends.ValidateResult{
+		NRecords:     2,
+		KeysPerIndex: map[string]int64{"_id_": 2},
+	}, res)
+}
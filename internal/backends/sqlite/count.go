@@ -0,0 +1,75 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// Count implements backends.Collection interface.
+//
+// Documents are counted with SQL COUNT(*) if all filter conditions could be applied exactly,
+// and no document has arrays on their paths.
+// SQLite does not keep row counts in its catalog, so estimated counts are exact too.
+func (c *collection) Count(ctx context.Context, params *backends.CountParams) (*backends.CountResult, error) {
+	db := c.r.DatabaseGetExisting(ctx, c.dbName)
+	if db == nil {
+		return &backends.CountResult{CountPushdown: true}, nil
+	}
+
+	meta := c.r.CollectionGet(ctx, c.dbName, c.name)
+	if meta == nil {
+		return &backends.CountResult{CountPushdown: true}, nil
+	}
+
+	q := fmt.Sprintf(`SELECT COUNT(*) FROM %q`, meta.TableName)
+
+	var args []any
+
+	if len(params.Filter) > 0 {
+		where, whereArgs := exactFilterWhere(params.Filter)
+		if where == "" {
+			return new(backends.CountResult), nil
+		}
+
+		check, checkArgs := prepareAggregateCheck(meta, &backends.AggregateParams{Filter: params.Filter})
+
+		var unsupported bool
+		if err := db.QueryRowContext(ctx, check, checkArgs...).Scan(&unsupported); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if unsupported {
+			return new(backends.CountResult), nil
+		}
+
+		q += where
+		args = whereArgs
+	}
+
+	var count int64
+	if err := db.QueryRowContext(ctx, q, args...).Scan(&count); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &backends.CountResult{
+		Count:         count,
+		CountPushdown: true,
+	}, nil
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/backends/sqlite/metadata"
+	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 )
 
@@ -50,16 +51,49 @@ func (db *database) Collection(name string) (backends.Collection, error) {
 //
 //nolint:lll // for readability
 func (db *database) ListCollections(ctx context.Context, params *backends.ListCollectionsParams) (*backends.ListCollectionsResult, error) {
-	list, err := db.r.CollectionList(ctx, db.name)
-	if err != nil {
+	var list []string
+	var err error
+
+	if params != nil && params.Name != "" {
+		// the collection is looked up directly, the database is not scanned
+		list = []string{params.Name}
+	} else if list, err = db.r.CollectionList(ctx, db.name); err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
-	res := make([]backends.CollectionInfo, len(list))
-	for i, name := range list {
-		res[i] = backends.CollectionInfo{
-			Name: name,
+	res := make([]backends.CollectionInfo, 0, len(list))
+	for _, name := range list {
+		// collection could be dropped concurrently
+		c := db.r.CollectionGet(ctx, db.name, name)
+		if c == nil {
+			continue
+		}
+
+		info := backends.CollectionInfo{
+			Name:      name,
+			Clustered: c.Settings.Clustered,
 		}
+
+		if c.Capped() {
+			info.Capped = &backends.CappedParams{
+				Size:      c.Settings.CappedSize,
+				Documents: c.Settings.CappedDocuments,
+			}
+		}
+
+		if c.Settings.Validator != nil {
+			if info.Validator, err = unmarshalValidator(c.Settings.Validator); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+		}
+
+		if c.Settings.Collation != nil {
+			if info.Collation, err = sjson.Unmarshal(c.Settings.Collation); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+		}
+
+		res = append(res, info)
 	}
 
 	return &backends.ListCollectionsResult{
@@ -69,7 +103,36 @@ func (db *database) ListCollections(ctx context.Context, params *backends.ListCo
 
 // CreateCollection implements backends.Database interface.
 func (db *database) CreateCollection(ctx context.Context, params *backends.CreateCollectionParams) error {
-	created, err := db.r.CollectionCreate(ctx, db.name, params.Name)
+	p := &metadata.CollectionCreateParams{
+		DBName:    db.name,
+		Name:      params.Name,
+		Clustered: params.Clustered,
+	}
+
+	if params.Capped != nil {
+		p.CappedSize = params.Capped.Size
+		p.CappedDocuments = params.Capped.Documents
+	}
+
+	if params.Validator != nil {
+		v, err := marshalValidator(params.Validator)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		p.Validator = v
+	}
+
+	if params.Collation != nil {
+		b, err := sjson.Marshal(params.Collation)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		p.Collation = b
+	}
+
+	created, err := db.r.CollectionCreate(ctx, p)
 	if err != nil {
 		return lazyerrors.Error(err)
 	}
@@ -97,8 +160,20 @@ func (db *database) DropCollection(ctx context.Context, params *backends.DropCol
 
 // RenameCollection implements backends.Database interface.
 func (db *database) RenameCollection(ctx context.Context, params *backends.RenameCollectionParams) error {
-	// TODO https://github.com/FerretDB/FerretDB/issues/2760
-	panic("not implemented")
+	if db.r.CollectionGet(ctx, db.name, params.NewName) != nil {
+		return backends.NewError(backends.ErrorCodeCollectionAlreadyExists, nil)
+	}
+
+	renamed, err := db.r.CollectionRename(ctx, db.name, params.OldName, params.NewName)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if !renamed {
+		return backends.NewError(backends.ErrorCodeCollectionDoesNotExist, nil)
+	}
+
+	return nil
 }
 
 // Stats implements backends.Database interface.
@@ -110,3 +185,37 @@ func (db *database) Stats(ctx context.Context, params *backends.StatsParams) (*b
 var (
 	_ backends.Database = (*database)(nil)
 )
+
+// marshalValidator converts the validator to the metadata representation.
+//
+// It returns nil for validators without filter.
+func marshalValidator(v *backends.ValidatorParams) (*metadata.Validator, error) {
+	if v.Filter == nil {
+		return nil, nil
+	}
+
+	b, err := sjson.Marshal(v.Filter)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &metadata.Validator{
+		Filter: b,
+		Level:  v.Level,
+		Action: v.Action,
+	}, nil
+}
+
+// unmarshalValidator converts the validator from the metadata representation.
+func unmarshalValidator(v *metadata.Validator) (*backends.ValidatorParams, error) {
+	filter, err := sjson.Unmarshal(v.Filter)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &backends.ValidatorParams{
+		Filter: filter,
+		Level:  v.Level,
+		Action: v.Action,
+	}, nil
+}
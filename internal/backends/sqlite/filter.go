@@ -0,0 +1,243 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/sqlite/metadata"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// prepareFilter returns SQL conditions with arguments for the given filter conditions.
+//
+// Conditions that can't be pushed down are skipped;
+// the handler filters returned documents anyway.
+func prepareFilter(filter []backends.FilterCondition) ([]string, []any) {
+	var conditions []string
+	var args []any
+
+	for _, cond := range filter {
+		c, a := filterCondition(cond)
+		if c == "" {
+			continue
+		}
+
+		conditions = append(conditions, c)
+		args = append(args, a...)
+	}
+
+	return conditions, args
+}
+
+// filterCondition returns SQL condition with arguments for the given filter condition,
+// or empty string if it can't be pushed down.
+//
+// Documents with arrays on the condition's path are always selected,
+// because the condition could match any of their elements.
+func filterCondition(cond backends.FilterCondition) (string, []any) {
+	paths := jsonPaths(cond.Path)
+	if paths == nil {
+		return "", nil
+	}
+
+	path := paths[len(paths)-1]
+
+	var value string
+	var valueArgs []any
+
+	switch cond.Op {
+	case backends.FilterEq:
+		value, valueArgs = filterEqual(path, cond.Value)
+
+	case backends.FilterGt, backends.FilterGte, backends.FilterLt, backends.FilterLte:
+		value, valueArgs = filterRange(path, cond.Op, cond.Value)
+
+	case backends.FilterIn:
+		values, _ := cond.Value.([]any)
+		if len(values) == 0 {
+			return "", nil
+		}
+
+		conditions := make([]string, len(values))
+
+		for i, v := range values {
+			var c string
+			var a []any
+
+			if c, a = filterEqual(path, v); c == "" {
+				return "", nil
+			}
+
+			conditions[i] = c
+			valueArgs = append(valueArgs, a...)
+		}
+
+		value = strings.Join(conditions, ` OR `)
+
+	default:
+		panic(fmt.Sprintf("unexpected filter operator: %d", cond.Op))
+	}
+
+	if value == "" {
+		return "", nil
+	}
+
+	conditions := make([]string, 0, len(paths)+1)
+	args := make([]any, 0, len(paths)+len(valueArgs))
+
+	for _, p := range paths {
+		conditions = append(conditions, fmt.Sprintf(`json_type(%s, ?) = 'array'`, metadata.DefaultColumn))
+		args = append(args, p)
+	}
+
+	conditions = append(conditions, value)
+	args = append(args, valueArgs...)
+
+	return `(` + strings.Join(conditions, ` OR `) + `)`, args
+}
+
+// filterEqual returns SQL condition with arguments that selects documents
+// where the value at the given JSON path is equal to v,
+// or empty string if v has unsupported type.
+func filterEqual(path string, v any) (string, []any) {
+	switch v := v.(type) {
+	case float64, int32, int64:
+		// text values are always greater than numbers in SQLite, so types are not checked
+		lo, hi := numberBounds(v)
+		return fmt.Sprintf(`json_extract(%s, ?) BETWEEN ? AND ?`, metadata.DefaultColumn), []any{path, lo, hi}
+
+	case string:
+		return fmt.Sprintf(`json_extract(%s, ?) = ?`, metadata.DefaultColumn), []any{path, v}
+
+	case types.ObjectID:
+		return fmt.Sprintf(`json_extract(%s, ?) = ?`, metadata.DefaultColumn), []any{path, hex.EncodeToString(v[:])}
+
+	case bool:
+		return fmt.Sprintf(`json_type(%s, ?) = ?`, metadata.DefaultColumn), []any{path, fmt.Sprint(v)}
+
+	default:
+		return "", nil
+	}
+}
+
+// filterRange returns SQL condition with arguments that selects documents
+// where the value at the given JSON path compares with v using op,
+// or empty string if v has unsupported type.
+//
+// Like in MongoDB, only values of the same type bracket are compared.
+func filterRange(path string, op backends.FilterOp, v any) (string, []any) {
+	var jsonTypes string
+	var lo, hi any
+
+	switch v := v.(type) {
+	case float64, int32, int64:
+		jsonTypes = `'integer', 'real'`
+		lo, hi = numberBounds(v)
+	case string:
+		jsonTypes = `'text'`
+		lo, hi = v, v
+	default:
+		return "", nil
+	}
+
+	var sqlOp string
+	var arg any
+
+	switch op {
+	case backends.FilterGt:
+		sqlOp, arg = `>`, lo
+	case backends.FilterGte:
+		sqlOp, arg = `>=`, lo
+	case backends.FilterLt:
+		sqlOp, arg = `<`, hi
+	case backends.FilterLte:
+		sqlOp, arg = `<=`, hi
+	default:
+		panic(fmt.Sprintf("unexpected filter operator: %d", op))
+	}
+
+	q := fmt.Sprintf(
+		`(json_type(%[1]s, ?) IN (%[2]s) AND json_extract(%[1]s, ?) %[3]s ?)`,
+		metadata.DefaultColumn, jsonTypes, sqlOp,
+	)
+
+	return q, []any{path, path, arg}
+}
+
+// numberBounds returns the range of SQLite values that could be equal to the given number.
+//
+// SQLite may parse stored JSON numbers not exactly like Go, and int64 values may not fit into float64,
+// so the range is slightly widened; the handler filters extra documents anyway.
+func numberBounds(v any) (lo, hi float64) {
+	var f float64
+
+	switch v := v.(type) {
+	case float64:
+		f = v
+	case int32:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	default:
+		panic(fmt.Sprintf("unexpected number type: %T", v))
+	}
+
+	d := math.Abs(f) * 1e-12
+
+	return f - d, f + d
+}
+
+// jsonPaths returns SQLite JSON paths for all prefixes of the given path, from the shortest to the full one,
+// or nil if some path element can't be used in JSON path.
+func jsonPaths(path types.Path) []string {
+	if path.Len() == 0 {
+		return nil
+	}
+
+	res := make([]string, path.Len())
+
+	p := "$"
+	for i, e := range path.Slice() {
+		if e == "" || strings.ContainsAny(e, `"\`) {
+			return nil
+		}
+
+		p += `."` + e + `"`
+		res[i] = p
+	}
+
+	return res
+}
+
+// schemaTypePath returns SQLite JSON path of the sjson schema type for the given path;
+// path elements should be checked by jsonPaths first.
+func schemaTypePath(path types.Path) string {
+	var res strings.Builder
+
+	res.WriteString(`$`)
+
+	for _, e := range path.Slice() {
+		fmt.Fprintf(&res, `."$s"."p"."%s"`, e)
+	}
+
+	res.WriteString(`."t"`)
+
+	return res.String()
+}
@@ -15,6 +15,17 @@
 // Package metadata provides access to SQLite databases and collections information.
 package metadata
 
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
 // Collection will probably have a method for getting column name / SQLite path expression for the given document field
 // once we implement field extraction.
 // IDColumn probably should go away.
@@ -26,11 +37,136 @@ const (
 
 	// DefaultColumn is a column name for all fields expect _id.
 	DefaultColumn = "_ferretdb_sjson"
+
+	// RecordIDColumn is a column name for record ID of capped collections.
+	// Other collections don't have it.
+	RecordIDColumn = "_ferretdb_record_id"
+
+	// ClusteredIDColumn is a column name for _id field of clustered collections.
+	// It is the primary key of their WITHOUT ROWID tables. Other collections don't have it.
+	ClusteredIDColumn = "_ferretdb_id"
 )
 
 // Collection represents collection metadata.
 type Collection struct {
 	Name      string
 	TableName string
-	Settings  string
+	Settings  Settings
+}
+
+// Settings represents collection settings stored as JSON.
+type Settings struct {
+	Indexes         []IndexInfo `json:"indexes"`
+	CappedSize      int64       `json:"cappedSize,omitempty"`
+	CappedDocuments int64       `json:"cappedDocuments,omitempty"`
+	Validator       *Validator  `json:"validator,omitempty"`
+	Clustered       bool        `json:"clustered,omitempty"`
+
+	// Collation is the sjson-encoded default collation document, empty for the simple binary comparison.
+	Collation json.RawMessage `json:"collation,omitempty"`
+}
+
+// Validator represents the collection validator.
+type Validator struct {
+	Filter json.RawMessage `json:"filter"` // sjson-encoded document
+	Level  string          `json:"level"`
+	Action string          `json:"action"`
+}
+
+// IndexInfo represents information about a single index.
+type IndexInfo struct {
+	Name               string         `json:"name"`
+	Key                []IndexKeyPair `json:"key"`
+	Unique             bool           `json:"unique,omitempty"`
+	ExpireAfterSeconds *int64         `json:"expireAfterSeconds,omitempty"`
+	Text               *TextIndexInfo `json:"text,omitempty"`
+}
+
+// IndexKeyPair consists of a field name and a sort order that are part of the index.
+type IndexKeyPair struct {
+	Field      string `json:"field"`
+	Descending bool   `json:"descending,omitempty"`
+	Sphere     bool   `json:"sphere,omitempty"`
+	Text       bool   `json:"text,omitempty"`
+}
+
+// TextIndexInfo represents options of the text index.
+type TextIndexInfo struct {
+	Weights          map[string]int32 `json:"weights"`
+	DefaultLanguage  string           `json:"defaultLanguage"`
+	LanguageOverride string           `json:"languageOverride"`
+}
+
+// defaultIndexName is the name of the default _id index.
+const defaultIndexName = "_id_"
+
+// defaultIndex returns the default _id index that is created for every collection.
+func defaultIndex() IndexInfo {
+	return IndexInfo{
+		Name:   defaultIndexName,
+		Key:    []IndexKeyPair{{Field: "_id"}},
+		Unique: true,
+	}
+}
+
+// Capped returns true if collection is capped.
+func (c *Collection) Capped() bool {
+	return c.Settings.CappedSize > 0
+}
+
+// IDColumn returns SQLite expression for _id field of the collection.
+//
+// Clustered collections store it in the primary key column, others use the expression index.
+func (c *Collection) IDColumn() string {
+	if c.Settings.Clustered {
+		return ClusteredIDColumn
+	}
+
+	return IDColumn
+}
+
+// IndexName returns SQLite index name for the given index of the collection.
+//
+// Index names are unique for the whole SQLite database, so they are derived from the table name.
+func (c *Collection) IndexName(name string) string {
+	if name == defaultIndexName {
+		return c.TableName + "_id"
+	}
+
+	h := fnv.New32a()
+	must.NotFail(h.Write([]byte(name)))
+
+	return fmt.Sprintf("%s_%08x", c.TableName, h.Sum32())
+}
+
+// metadataOnly returns true if the index exists only in metadata without SQLite index,
+// because SQLite can't index some of its fields (like 2dsphere and text ones).
+// Queries that need such indexes are evaluated in memory.
+func (i *IndexInfo) metadataOnly() bool {
+	return slices.ContainsFunc(i.Key, func(p IndexKeyPair) bool { return p.Sphere || p.Text })
+}
+
+// indexColumns returns SQLite expressions for the given index key.
+//
+// Missing fields are indexed as nulls, like in MongoDB.
+func indexColumns(key []IndexKeyPair) string {
+	columns := make([]string, len(key))
+
+	for i, pair := range key {
+		path := "$"
+		for _, e := range strings.Split(pair.Field, ".") {
+			path += `."` + e + `"`
+		}
+
+		columns[i] = fmt.Sprintf(
+			"COALESCE(%s->'%s', 'null')",
+			DefaultColumn, strings.ReplaceAll(path, "'", "''"),
+		)
+
+		if pair.Descending {
+			columns[i] += " DESC"
+		}
+	}
+
+	return strings.Join(columns, ", ")
 }
@@ -16,6 +16,7 @@ package metadata
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"hash/fnv"
 	"sort"
@@ -25,6 +26,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/internal/backends/sqlite/metadata/pool"
 	"github.com/FerretDB/FerretDB/internal/util/fsql"
@@ -104,10 +106,20 @@ func (r *Registry) initCollections(ctx context.Context, dbName string, db *fsql.
 
 	for rows.Next() {
 		var c Collection
-		if err = rows.Scan(&c.Name, &c.TableName, &c.Settings); err != nil {
+		var settings string
+		if err = rows.Scan(&c.Name, &c.TableName, &settings); err != nil {
 			return lazyerrors.Error(err)
 		}
 
+		if err = json.Unmarshal([]byte(settings), &c.Settings); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		// collections created by older versions do not store the default index
+		if len(c.Settings.Indexes) == 0 {
+			c.Settings.Indexes = []IndexInfo{defaultIndex()}
+		}
+
 		colls[c.Name] = &c
 	}
 
@@ -223,16 +235,41 @@ func (r *Registry) CollectionList(ctx context.Context, dbName string) ([]string,
 	return res, nil
 }
 
+// CollectionCreateParams contains parameters for CollectionCreate.
+type CollectionCreateParams struct {
+	DBName          string
+	Name            string
+	CappedSize      int64 // 0 for non-capped collections
+	CappedDocuments int64
+	Validator       *Validator      // nil for collections without validator
+	Collation       json.RawMessage // sjson-encoded document, nil for the simple binary comparison
+	Clustered       bool            // can't be used together with CappedSize
+}
+
 // CollectionCreate creates a collection in the database.
 //
 // Returned boolean value indicates whether the collection was created.
 // If collection already exists, (false, nil) is returned.
-func (r *Registry) CollectionCreate(ctx context.Context, dbName, collectionName string) (bool, error) {
+func (r *Registry) CollectionCreate(ctx context.Context, params *CollectionCreateParams) (bool, error) {
 	defer observability.FuncCall(ctx)()
 
 	r.rw.Lock()
 	defer r.rw.Unlock()
 
+	return r.collectionCreate(ctx, params)
+}
+
+// collectionCreate creates a collection in the database.
+//
+// Returned boolean value indicates whether the collection was created.
+// If collection already exists, (false, nil) is returned.
+//
+// It does not hold the lock.
+func (r *Registry) collectionCreate(ctx context.Context, params *CollectionCreateParams) (bool, error) {
+	defer observability.FuncCall(ctx)()
+
+	dbName, collectionName := params.DBName, params.Name
+
 	db, err := r.databaseGetOrCreate(ctx, dbName)
 	if err != nil {
 		return false, lazyerrors.Error(err)
@@ -247,33 +284,74 @@ func (r *Registry) CollectionCreate(ctx context.Context, dbName, collectionName
 	must.NotFail(h.Write([]byte(collectionName)))
 	s := h.Sum32()
 
-	// TODO https://github.com/FerretDB/FerretDB/issues/2760
-
 	tableName := fmt.Sprintf("%s_%08x", strings.ToLower(collectionName), s)
 	if strings.HasPrefix(tableName, reservedTablePrefix) {
 		tableName = "_" + tableName
 	}
 
-	q := fmt.Sprintf("CREATE TABLE %[1]q (%[2]s TEXT NOT NULL CHECK(%[2]s != '')) STRICT", tableName, DefaultColumn)
-	if _, err = db.ExecContext(ctx, q); err != nil {
+	// renamed collections keep their tables, so the table name could be already taken
+	for base, i := tableName, 1; tableNameTaken(colls, tableName); i++ {
+		tableName = fmt.Sprintf("%s_%d", base, i)
+	}
+
+	c := &Collection{
+		Name:      collectionName,
+		TableName: tableName,
+		Settings: Settings{
+			Indexes:         []IndexInfo{defaultIndex()},
+			CappedSize:      params.CappedSize,
+			CappedDocuments: params.CappedDocuments,
+			Validator:       params.Validator,
+			Collation:       params.Collation,
+			Clustered:       params.Clustered,
+		},
+	}
+
+	settings, err := json.Marshal(c.Settings)
+	if err != nil {
 		return false, lazyerrors.Error(err)
 	}
 
-	pkName := tableName + "_id"
-	q = fmt.Sprintf("CREATE UNIQUE INDEX %q ON %q (%s)", pkName, tableName, IDColumn)
+	q := fmt.Sprintf("CREATE TABLE %[1]q (%[2]s TEXT NOT NULL CHECK(%[2]s != '')) STRICT", tableName, DefaultColumn)
+
+	switch {
+	case c.Capped() && c.Settings.Clustered:
+		return false, lazyerrors.Errorf("clustered capped collections are not supported")
+
+	case c.Capped():
+		// AUTOINCREMENT guarantees that record IDs are never reused, even after eviction
+		q = fmt.Sprintf(
+			"CREATE TABLE %[1]q (%[2]s INTEGER PRIMARY KEY AUTOINCREMENT, %[3]s TEXT NOT NULL CHECK(%[3]s != '')) STRICT",
+			tableName, RecordIDColumn, DefaultColumn,
+		)
+
+	case c.Settings.Clustered:
+		// documents are stored in the B-tree of the primary key itself;
+		// CHECK keeps the _id column in sync with the document
+		q = fmt.Sprintf(
+			"CREATE TABLE %[1]q ("+
+				"%[2]s TEXT PRIMARY KEY NOT NULL CHECK(%[2]s = %[4]s), "+
+				"%[3]s TEXT NOT NULL CHECK(%[3]s != '')"+
+				") STRICT, WITHOUT ROWID",
+			tableName, ClusteredIDColumn, DefaultColumn, IDColumn,
+		)
+	}
+
 	if _, err = db.ExecContext(ctx, q); err != nil {
-		_, _ = db.ExecContext(ctx, fmt.Sprintf("DROP TABLE %q", tableName))
 		return false, lazyerrors.Error(err)
 	}
 
-	c := &Collection{
-		Name:      collectionName,
-		TableName: tableName,
-		Settings:  "{}",
+	// the primary key of clustered collections is used as the default index
+	if !c.Settings.Clustered {
+		q = fmt.Sprintf("CREATE UNIQUE INDEX %q ON %q (%s)", c.IndexName(defaultIndexName), tableName, IDColumn)
+		if _, err = db.ExecContext(ctx, q); err != nil {
+			_, _ = db.ExecContext(ctx, fmt.Sprintf("DROP TABLE %q", tableName))
+			return false, lazyerrors.Error(err)
+		}
 	}
 
 	q = fmt.Sprintf("INSERT INTO %q (name, table_name, settings) VALUES (?, ?, ?)", metadataTableName)
-	if _, err = db.ExecContext(ctx, q, c.Name, c.TableName, c.Settings); err != nil {
+	if _, err = db.ExecContext(ctx, q, c.Name, c.TableName, string(settings)); err != nil {
 		_, _ = db.ExecContext(ctx, fmt.Sprintf("DROP TABLE %q", tableName))
 		return false, lazyerrors.Error(err)
 	}
@@ -286,6 +364,17 @@ func (r *Registry) CollectionCreate(ctx context.Context, dbName, collectionName
 	return true, nil
 }
 
+// tableNameTaken returns true if the given table name is used by one of the given collections.
+func tableNameTaken(colls map[string]*Collection, tableName string) bool {
+	for _, c := range colls {
+		if c.TableName == tableName {
+			return true
+		}
+	}
+
+	return false
+}
+
 // CollectionGet returns collection metadata.
 //
 // If database or collection does not exist, nil is returned.
@@ -344,12 +433,231 @@ func (r *Registry) CollectionDrop(ctx context.Context, dbName, collectionName st
 }
 
 // CollectionRename renames a collection in the database.
+// The underlying table is kept, so indexes are kept too.
 //
 // Returned boolean value indicates whether the collection was renamed.
 // If database or collection did not exist, (false, nil) is returned.
+// If a collection with the new name already exists, an error is returned.
 func (r *Registry) CollectionRename(ctx context.Context, dbName, oldCollectionName, newCollectionName string) (bool, error) {
-	// TODO https://github.com/FerretDB/FerretDB/issues/2760
-	panic("not implemented")
+	defer observability.FuncCall(ctx)()
+
+	db := r.p.GetExisting(ctx, dbName)
+	if db == nil {
+		return false, nil
+	}
+
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	colls := r.colls[dbName]
+	if colls == nil {
+		return false, nil
+	}
+
+	c := colls[oldCollectionName]
+	if c == nil {
+		return false, nil
+	}
+
+	if colls[newCollectionName] != nil {
+		return false, lazyerrors.Errorf("collection %s.%s already exists", dbName, newCollectionName)
+	}
+
+	q := fmt.Sprintf("UPDATE %q SET name = ? WHERE name = ?", metadataTableName)
+	if _, err := db.ExecContext(ctx, q, newCollectionName, oldCollectionName); err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	renamed := *c
+	renamed.Name = newCollectionName
+
+	delete(colls, oldCollectionName)
+	colls[newCollectionName] = &renamed
+
+	return true, nil
+}
+
+// CollectionUpdateParams contains parameters for CollectionUpdate.
+type CollectionUpdateParams struct {
+	DBName             string
+	Name               string
+	Validator          *Validator // replaces the validator if not nil; Validator with empty Filter removes it
+	Index              string     // name of the index to modify; empty for no index changes
+	ExpireAfterSeconds int64
+}
+
+// CollectionUpdate updates settings of the collection.
+//
+// Returned boolean value indicates whether the collection exists.
+// Non-existing index is ignored.
+func (r *Registry) CollectionUpdate(ctx context.Context, params *CollectionUpdateParams) (bool, error) {
+	defer observability.FuncCall(ctx)()
+
+	db := r.p.GetExisting(ctx, params.DBName)
+	if db == nil {
+		return false, nil
+	}
+
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	existing := r.colls[params.DBName][params.Name]
+	if existing == nil {
+		return false, nil
+	}
+
+	c := *existing
+	c.Settings.Indexes = slices.Clone(c.Settings.Indexes)
+
+	if v := params.Validator; v != nil {
+		c.Settings.Validator = v
+		if len(v.Filter) == 0 {
+			c.Settings.Validator = nil
+		}
+	}
+
+	if params.Index != "" {
+		if i := slices.IndexFunc(c.Settings.Indexes, func(i IndexInfo) bool { return i.Name == params.Index }); i >= 0 {
+			expireAfterSeconds := params.ExpireAfterSeconds
+			c.Settings.Indexes[i].ExpireAfterSeconds = &expireAfterSeconds
+		}
+	}
+
+	err := db.InTransaction(ctx, func(tx *fsql.Tx) error {
+		return r.collectionSaveSettings(ctx, tx, &c)
+	})
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	r.colls[params.DBName][params.Name] = &c
+
+	return true, nil
+}
+
+// IndexesCreate creates indexes in the collection.
+//
+// Indexes that already exist with the same names are skipped.
+// All indexes are created in a single transaction; if some index can't be created, none are.
+//
+// Both database and collection are created if they do not exist.
+func (r *Registry) IndexesCreate(ctx context.Context, dbName, collectionName string, indexes []IndexInfo) error {
+	defer observability.FuncCall(ctx)()
+
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	if _, err := r.collectionCreate(ctx, &CollectionCreateParams{DBName: dbName, Name: collectionName}); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	db := r.p.GetExisting(ctx, dbName)
+	c := *r.colls[dbName][collectionName]
+	c.Settings.Indexes = slices.Clone(c.Settings.Indexes)
+
+	err := db.InTransaction(ctx, func(tx *fsql.Tx) error {
+		for _, index := range indexes {
+			if slices.ContainsFunc(c.Settings.Indexes, func(i IndexInfo) bool { return i.Name == index.Name }) {
+				continue
+			}
+
+			if index.metadataOnly() {
+				c.Settings.Indexes = append(c.Settings.Indexes, index)
+				continue
+			}
+
+			unique := ""
+			if index.Unique {
+				unique = "UNIQUE "
+			}
+
+			q := fmt.Sprintf(
+				"CREATE %sINDEX %q ON %q (%s)",
+				unique, c.IndexName(index.Name), c.TableName, indexColumns(index.Key),
+			)
+			if _, err := tx.ExecContext(ctx, q); err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			c.Settings.Indexes = append(c.Settings.Indexes, index)
+		}
+
+		return r.collectionSaveSettings(ctx, tx, &c)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.colls[dbName][collectionName] = &c
+
+	return nil
+}
+
+// IndexesDrop drops indexes of the collection by their names.
+//
+// Indexes that do not exist are ignored, as well as non-existing database or collection.
+func (r *Registry) IndexesDrop(ctx context.Context, dbName, collectionName string, names []string) error {
+	defer observability.FuncCall(ctx)()
+
+	db := r.p.GetExisting(ctx, dbName)
+	if db == nil {
+		return nil
+	}
+
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	existing := r.colls[dbName][collectionName]
+	if existing == nil {
+		return nil
+	}
+
+	c := *existing
+	c.Settings.Indexes = slices.Clone(c.Settings.Indexes)
+
+	err := db.InTransaction(ctx, func(tx *fsql.Tx) error {
+		for _, name := range names {
+			i := slices.IndexFunc(c.Settings.Indexes, func(i IndexInfo) bool { return i.Name == name })
+			if i < 0 {
+				continue
+			}
+
+			if !c.Settings.Indexes[i].metadataOnly() {
+				q := fmt.Sprintf("DROP INDEX %q", c.IndexName(name))
+				if _, err := tx.ExecContext(ctx, q); err != nil {
+					return lazyerrors.Error(err)
+				}
+			}
+
+			c.Settings.Indexes = slices.Delete(c.Settings.Indexes, i, i+1)
+		}
+
+		return r.collectionSaveSettings(ctx, tx, &c)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.colls[dbName][collectionName] = &c
+
+	return nil
+}
+
+// collectionSaveSettings stores settings of the given collection in the metadata table.
+//
+// It does not hold the lock.
+func (r *Registry) collectionSaveSettings(ctx context.Context, tx *fsql.Tx, c *Collection) error {
+	settings, err := json.Marshal(c.Settings)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	q := fmt.Sprintf("UPDATE %q SET settings = ? WHERE name = ?", metadataTableName)
+	if _, err = tx.ExecContext(ctx, q, string(settings), c.Name); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
 }
 
 // Describe implements prometheus.Collector.
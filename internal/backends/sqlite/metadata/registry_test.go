@@ -34,11 +34,11 @@ func testCollection(t *testing.T, ctx context.Context, r *Registry, db *fsql.DB,
 	c := r.CollectionGet(ctx, dbName, collectionName)
 	require.Nil(t, c)
 
-	created, err := r.CollectionCreate(ctx, dbName, collectionName)
+	created, err := r.CollectionCreate(ctx, &CollectionCreateParams{DBName: dbName, Name: collectionName})
 	require.NoError(t, err)
 	require.True(t, created)
 
-	created, err = r.CollectionCreate(ctx, dbName, collectionName)
+	created, err = r.CollectionCreate(ctx, &CollectionCreateParams{DBName: dbName, Name: collectionName})
 	require.NoError(t, err)
 	require.False(t, created)
 
@@ -90,6 +90,58 @@ func TestCreateDrop(t *testing.T) {
 	testCollection(t, ctx, r, db, dbName, collectionName)
 }
 
+func TestRename(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.Ctx(t)
+
+	r, err := NewRegistry("file:./?mode=memory", testutil.Logger(t))
+	require.NoError(t, err)
+	t.Cleanup(r.Close)
+
+	dbName := testutil.DatabaseName(t)
+
+	db, err := r.DatabaseGetOrCreate(ctx, dbName)
+	require.NoError(t, err)
+	require.NotNil(t, db)
+
+	t.Cleanup(func() {
+		r.DatabaseDrop(ctx, dbName)
+	})
+
+	oldName := testutil.CollectionName(t)
+	newName := oldName + "_renamed"
+
+	renamed, err := r.CollectionRename(ctx, dbName, oldName, newName)
+	require.NoError(t, err)
+	require.False(t, renamed)
+
+	created, err := r.CollectionCreate(ctx, &CollectionCreateParams{DBName: dbName, Name: oldName})
+	require.NoError(t, err)
+	require.True(t, created)
+
+	tableName := r.CollectionGet(ctx, dbName, oldName).TableName
+
+	renamed, err = r.CollectionRename(ctx, dbName, oldName, newName)
+	require.NoError(t, err)
+	require.True(t, renamed)
+
+	require.Nil(t, r.CollectionGet(ctx, dbName, oldName))
+
+	c := r.CollectionGet(ctx, dbName, newName)
+	require.NotNil(t, c)
+	require.Equal(t, newName, c.Name)
+	require.Equal(t, tableName, c.TableName)
+
+	// the renamed collection keeps its table, so the new one uses another table
+	created, err = r.CollectionCreate(ctx, &CollectionCreateParams{DBName: dbName, Name: oldName})
+	require.NoError(t, err)
+	require.True(t, created)
+	require.NotEqual(t, tableName, r.CollectionGet(ctx, dbName, oldName).TableName)
+
+	_, err = r.CollectionRename(ctx, dbName, oldName, newName)
+	require.Error(t, err)
+}
+
 func TestCreateDropStress(t *testing.T) {
 	ctx := testutil.Ctx(t)
 
@@ -164,13 +216,13 @@ func TestCreateSameStress(t *testing.T) {
 				ready <- struct{}{}
 				<-start
 
-				created, err := r.CollectionCreate(ctx, dbName, collectionName)
+				created, err := r.CollectionCreate(ctx, &CollectionCreateParams{DBName: dbName, Name: collectionName})
 				require.NoError(t, err)
 				if created {
 					createdTotal.Add(1)
 				}
 
-				created, err = r.CollectionCreate(ctx, dbName, collectionName)
+				created, err = r.CollectionCreate(ctx, &CollectionCreateParams{DBName: dbName, Name: collectionName})
 				require.NoError(t, err)
 				require.False(t, created)
 
@@ -220,7 +272,7 @@ func TestDropSameStress(t *testing.T) {
 
 			collectionName := "collection"
 
-			created, err := r.CollectionCreate(ctx, dbName, collectionName)
+			created, err := r.CollectionCreate(ctx, &CollectionCreateParams{DBName: dbName, Name: collectionName})
 			require.NoError(t, err)
 			require.True(t, created)
 
@@ -278,7 +330,7 @@ func TestCreateDropSameStress(t *testing.T) {
 				<-start
 
 				if id%2 == 0 {
-					created, err := r.CollectionCreate(ctx, dbName, collectionName)
+					created, err := r.CollectionCreate(ctx, &CollectionCreateParams{DBName: dbName, Name: collectionName})
 					require.NoError(t, err)
 					if created {
 						createdTotal.Add(1)
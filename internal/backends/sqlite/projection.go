@@ -0,0 +1,62 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/backends/sqlite/metadata"
+)
+
+// prepareProjection returns SQL expression with arguments that selects documents
+// with only the given top-level fields, or just the document column if fields are empty.
+//
+// Fields that are not kept are removed from both the document and its sjson schema with json_patch,
+// so values of kept fields are returned as stored.
+func prepareProjection(fields []string) (string, []any) {
+	if len(fields) == 0 {
+		return metadata.DefaultColumn, nil
+	}
+
+	placeholders := strings.TrimPrefix(strings.Repeat(`, ?`, len(fields)), `, `)
+
+	// object with null values for all fields that are not kept; json_patch removes them
+	removed := fmt.Sprintf(
+		`(SELECT json_group_object(value, NULL) FROM json_each(%s, '$."$s"."$k"') WHERE value NOT IN (%s))`,
+		metadata.DefaultColumn, placeholders,
+	)
+
+	// keys of the kept fields in the original order
+	kept := fmt.Sprintf(
+		`(SELECT json_group_array(value) FROM json_each(%s, '$."$s"."$k"') WHERE value IN (%s))`,
+		metadata.DefaultColumn, placeholders,
+	)
+
+	expr := fmt.Sprintf(
+		`json_patch(json_patch(%s, %s), json_object('$s', json_object('p', json(%s), '$k', json(%s))))`,
+		metadata.DefaultColumn, removed, removed, kept,
+	)
+
+	args := make([]any, 0, len(fields)*3)
+
+	for i := 0; i < 3; i++ {
+		for _, f := range fields {
+			args = append(args, f)
+		}
+	}
+
+	return expr, args
+}
@@ -31,10 +31,11 @@ import (
 type queryIterator struct {
 	// the order of fields is weird to make the struct smaller due to alignment
 
-	ctx   context.Context
-	rows  *fsql.Rows // protected by m
-	token *resource.Token
-	m     sync.Mutex
+	ctx      context.Context
+	rows     *fsql.Rows // protected by m
+	token    *resource.Token
+	m        sync.Mutex
+	recordID bool // rows contain record ID as the first column
 }
 
 // newQueryIterator returns a new queryIterator for the given *sql.Rows.
@@ -44,13 +45,17 @@ type queryIterator struct {
 // to make sure that the database connection is released as early as possible.
 // In that case, the iterator's Close method should still be called.
 //
+// If recordID is true, rows should contain record ID as the first column;
+// it is set on returned documents.
+//
 // Nil rows are possible and return already done iterator.
 // It still should be Close'd.
-func newQueryIterator(ctx context.Context, rows *fsql.Rows) types.DocumentsIterator {
+func newQueryIterator(ctx context.Context, rows *fsql.Rows, recordID bool) types.DocumentsIterator {
 	iter := &queryIterator{
-		ctx:   ctx,
-		rows:  rows,
-		token: resource.NewToken(),
+		ctx:      ctx,
+		rows:     rows,
+		token:    resource.NewToken(),
+		recordID: recordID,
 	}
 	resource.Track(iter, iter.token)
 
@@ -88,8 +93,15 @@ func (iter *queryIterator) Next() (struct{}, *types.Document, error) {
 		return unused, nil, lazyerrors.Error(err)
 	}
 
+	var recordID int64
 	var b []byte
-	if err := iter.rows.Scan(&b); err != nil {
+
+	dest := []any{&b}
+	if iter.recordID {
+		dest = []any{&recordID, &b}
+	}
+
+	if err := iter.rows.Scan(dest...); err != nil {
 		iter.close()
 		return unused, nil, lazyerrors.Error(err)
 	}
@@ -100,6 +112,8 @@ func (iter *queryIterator) Next() (struct{}, *types.Document, error) {
 		return unused, nil, lazyerrors.Error(err)
 	}
 
+	doc.SetRecordID(recordID)
+
 	return unused, doc, nil
 }
 
@@ -0,0 +1,91 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/sqlite/metadata"
+)
+
+// sortTypeOrder is an SQL expression that returns the order of the value's type in MongoDB's BSON comparison
+// for the given sjson schema path; numbers of all types have the same order,
+// and null and missing values go first.
+var sortTypeOrder = func() string {
+	// see sjson's elemType
+	brackets := [][]string{
+		{"double", "int", "long"},
+		{"string"},
+		{"object"},
+		{"array"},
+		{"binData"},
+		{"objectId"},
+		{"bool"},
+		{"date"},
+		{"timestamp"},
+		{"regex"},
+	}
+
+	var res strings.Builder
+
+	fmt.Fprintf(&res, `CASE json_extract(%s, ?)`, metadata.DefaultColumn)
+
+	for i, names := range brackets {
+		for _, t := range names {
+			fmt.Fprintf(&res, ` WHEN '%s' THEN %d`, t, i+1)
+		}
+	}
+
+	res.WriteString(` ELSE 0 END`)
+
+	return res.String()
+}()
+
+// prepareOrderBy returns SQL ORDER BY terms with arguments for the given sort keys,
+// or nil if some of them can't be pushed down.
+//
+// Values of the same type are compared by their JSON values;
+// arrays and documents are not compared like MongoDB does.
+func prepareOrderBy(sort []backends.SortParams) ([]string, []any) {
+	if len(sort) == 0 {
+		return nil, nil
+	}
+
+	terms := make([]string, 0, len(sort)*2)
+	args := make([]any, 0, len(sort)*2)
+
+	for _, s := range sort {
+		paths := jsonPaths(s.Path)
+		if paths == nil {
+			return nil, nil
+		}
+
+		order := ``
+		if s.Descending {
+			order = ` DESC`
+		}
+
+		terms = append(
+			terms,
+			sortTypeOrder+order,
+			fmt.Sprintf(`json_extract(%s, ?)`, metadata.DefaultColumn)+order,
+		)
+		args = append(args, schemaTypePath(s.Path), paths[len(paths)-1])
+	}
+
+	return terms, args
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backends
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/util/observability"
+	"github.com/FerretDB/FerretDB/internal/util/resource"
+)
+
+// Transaction is a generic interface for all backends for multi-document transactions.
+//
+// Transaction object is stateful and wraps a database connection.
+// Exactly one of Commit or Rollback methods should be called to release it.
+// Methods should not be called concurrently.
+//
+// See transactionContract and its methods for additional details.
+type Transaction interface {
+	Context(context.Context) context.Context
+	Commit(context.Context) error
+	Rollback(context.Context) error
+}
+
+// transactionContract implements Transaction interface.
+type transactionContract struct {
+	t     Transaction
+	token *resource.Token
+}
+
+// newTransactionContract wraps Transaction and enforces its contract.
+//
+// It is used by the backendContract; backend implementations should not use that function.
+//
+// See transactionContract and its methods for additional details.
+func newTransactionContract(t Transaction) Transaction {
+	tc := &transactionContract{
+		t:     t,
+		token: resource.NewToken(),
+	}
+	resource.Track(tc, tc.token)
+
+	return tc
+}
+
+// Context returns a new context for running Database and Collection methods inside the transaction.
+func (tc *transactionContract) Context(ctx context.Context) context.Context {
+	return tc.t.Context(ctx)
+}
+
+// Commit commits the transaction.
+func (tc *transactionContract) Commit(ctx context.Context) error {
+	defer observability.FuncCall(ctx)()
+
+	err := tc.t.Commit(ctx)
+	checkError(err)
+
+	resource.Untrack(tc, tc.token)
+
+	return err
+}
+
+// Rollback aborts the transaction.
+func (tc *transactionContract) Rollback(ctx context.Context) error {
+	defer observability.FuncCall(ctx)()
+
+	err := tc.t.Rollback(ctx)
+	checkError(err)
+
+	resource.Untrack(tc, tc.token)
+
+	return err
+}
+
+// check interfaces
+var (
+	_ Transaction = (*transactionContract)(nil)
+)
@@ -18,6 +18,8 @@ import (
 	"regexp"
 	"strings"
 	"unicode/utf8"
+
+	"golang.org/x/exp/slices"
 )
 
 // databaseNameRe validates database name.
@@ -48,12 +50,17 @@ func validateDatabaseName(name string) error {
 	return nil
 }
 
+// systemCollections are collections with `system.` prefix that are used by handlers.
+var systemCollections = []string{"system.users", "system.views"}
+
 // validateCollectionName checks that collection name is valid for FerretDB.
 //
 // It follows MongoDB restrictions plus:
 //   - allows only UTF-8 characters;
 //   - disallows '.' prefix (MongoDB fails to work with such collections correctly too);
-//   - disallows `_ferretdb_` prefix.
+//   - disallows `_ferretdb_` prefix;
+//   - disallows `system.` prefix, except for the `system.users` and `system.views` collections
+//     that store users and views.
 //
 // That validation is quite lax because
 // we expect it to be hard for users to change collection names in their software.
@@ -64,7 +71,7 @@ func validateCollectionName(name string) error {
 		return NewError(ErrorCodeCollectionNameIsInvalid, nil)
 	}
 
-	if strings.HasPrefix(name, "_ferretdb_") || strings.HasPrefix(name, "system.") {
+	if strings.HasPrefix(name, "_ferretdb_") || (strings.HasPrefix(name, "system.") && !slices.Contains(systemCollections, name)) {
 		return NewError(ErrorCodeCollectionNameIsInvalid, nil)
 	}
 
@@ -0,0 +1,80 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bson
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Size returns the size of the BSON encoding of the given value in bytes
+// without marshaling it.
+//
+// For documents and arrays, that's the size of the whole encoded document
+// (including the length and the terminating byte).
+// For other values, that's the size of the encoded value without the element type and name.
+func Size(v any) int {
+	switch v := v.(type) {
+	case *types.Document:
+		size := 5 // int32 length and terminating byte
+
+		keys := v.Keys()
+		for i, value := range v.Values() {
+			// element type, name as cstring, and value
+			size += 1 + len(keys[i]) + 1 + Size(value)
+		}
+
+		return size
+
+	case *types.Array:
+		size := 5 // int32 length and terminating byte
+
+		for i := 0; i < v.Len(); i++ {
+			// element type, index as cstring, and value
+			size += 1 + len(strconv.Itoa(i)) + 1 + Size(must.NotFail(v.Get(i)))
+		}
+
+		return size
+
+	case float64:
+		return 8
+	case string:
+		return 4 + len(v) + 1
+	case types.Binary:
+		return 4 + 1 + len(v.B)
+	case types.ObjectID:
+		return len(v)
+	case bool:
+		return 1
+	case time.Time:
+		return 8
+	case types.NullType:
+		return 0
+	case types.Regex:
+		return len(v.Pattern) + 1 + len(v.Options) + 1
+	case int32:
+		return 4
+	case types.Timestamp:
+		return 8
+	case int64:
+		return 8
+	default:
+		panic(fmt.Sprintf("unexpected type %T", v))
+	}
+}
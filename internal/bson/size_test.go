@@ -0,0 +1,62 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bson
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestSize(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range documentTestCases {
+		tc := tc
+		if tc.bErr != "" {
+			continue
+		}
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			doc, err := types.ConvertDocument(tc.v.(*Document))
+			if err != nil {
+				t.Skip(err)
+			}
+
+			assert.Equal(t, len(tc.b), Size(doc))
+		})
+	}
+
+	t.Run("Large", func(t *testing.T) {
+		t.Parallel()
+
+		doc := must.NotFail(types.NewDocument(
+			"_id", int32(1),
+			"v", strings.Repeat("x", types.MaxDocumentLen/2),
+			"a", must.NotFail(types.NewArray(int64(1), "foo", types.Null, must.NotFail(types.NewDocument()))),
+		))
+
+		b, err := MustConvertDocument(doc).MarshalBinary()
+		require.NoError(t, err)
+		assert.Equal(t, len(b), Size(doc))
+	})
+}
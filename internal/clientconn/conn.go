@@ -19,6 +19,7 @@ import (
 	"bufio"
 	"context"
 	"crypto/sha256"
+	"crypto/tls"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -27,6 +28,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime/pprof"
+	"strconv"
 	"sync/atomic"
 	"time"
 
@@ -36,11 +38,19 @@ import (
 
 	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
 	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
+	"github.com/FerretDB/FerretDB/internal/clientconn/fsynclock"
+	"github.com/FerretDB/FerretDB/internal/clientconn/maintenance"
+	"github.com/FerretDB/FerretDB/internal/clientconn/operation"
+	"github.com/FerretDB/FerretDB/internal/clientconn/session"
+	"github.com/FerretDB/FerretDB/internal/clientconn/shape"
+	"github.com/FerretDB/FerretDB/internal/clientconn/shutdown"
 	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/commoncommands"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
 	"github.com/FerretDB/FerretDB/internal/handlers/proxy"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/failpoints"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/util/observability"
@@ -73,25 +83,47 @@ var AllModes = []string{
 
 // conn represents client connection.
 type conn struct {
-	netConn        net.Conn
-	mode           Mode
-	l              *zap.SugaredLogger
-	h              handlers.Interface
-	m              *connmetrics.ConnMetrics
-	proxy          *proxy.Router
-	lastRequestID  atomic.Int32
-	testRecordsDir string // if empty, no records are created
+	netConn          net.Conn
+	mode             Mode
+	l                *zap.SugaredLogger
+	h                handlers.Interface
+	m                *connmetrics.ConnMetrics
+	sessions         *session.Registry
+	failPoints       *failpoints.Registry
+	operations       *operation.Registry
+	fsyncLock        *fsynclock.Lock
+	maintenance      *maintenance.Mode
+	shutdown         *shutdown.Trigger
+	shapes           *shape.Report
+	proxy            *proxy.Router
+	currentOp        atomic.Pointer[operation.Operation]
+	lastRequestID    atomic.Int32
+	acceptedAt       time.Time
+	handshakeTimeout time.Duration
+	handshakeDone    bool
+	testRecordsDir   string // if empty, no records are created
+	testCommands     bool
 }
 
 // newConnOpts represents newConn options.
 type newConnOpts struct {
-	netConn        net.Conn
-	mode           Mode
-	l              *zap.Logger
-	handler        handlers.Interface
-	connMetrics    *connmetrics.ConnMetrics
-	proxyAddr      string
-	testRecordsDir string // if empty, no records are created
+	netConn          net.Conn
+	mode             Mode
+	l                *zap.Logger
+	handler          handlers.Interface
+	connMetrics      *connmetrics.ConnMetrics
+	sessions         *session.Registry
+	failPoints       *failpoints.Registry
+	operations       *operation.Registry
+	fsyncLock        *fsynclock.Lock
+	maintenance      *maintenance.Mode
+	shutdown         *shutdown.Trigger
+	shapes           *shape.Report // used only in diff modes
+	proxyAddr        string
+	acceptedAt       time.Time     // if zero, the time of newConn call is used
+	handshakeTimeout time.Duration // if zero, there is no limit for the first message
+	testRecordsDir   string        // if empty, no records are created
+	testCommands     bool          // if true, test commands are available in non-debug builds
 }
 
 // newConn creates a new client connection for given net.Conn.
@@ -102,6 +134,24 @@ func newConn(opts *newConnOpts) (*conn, error) {
 	if opts.handler == nil {
 		panic("handler required")
 	}
+	if opts.sessions == nil {
+		panic("sessions required")
+	}
+	if opts.failPoints == nil {
+		panic("failPoints required")
+	}
+	if opts.operations == nil {
+		panic("operations required")
+	}
+	if opts.fsyncLock == nil {
+		panic("fsyncLock required")
+	}
+	if opts.maintenance == nil {
+		panic("maintenance required")
+	}
+	if opts.shutdown == nil {
+		panic("shutdown required")
+	}
 
 	var p *proxy.Router
 	if opts.mode != NormalMode {
@@ -111,14 +161,29 @@ func newConn(opts *newConnOpts) (*conn, error) {
 		}
 	}
 
+	acceptedAt := opts.acceptedAt
+	if acceptedAt.IsZero() {
+		acceptedAt = time.Now()
+	}
+
 	return &conn{
-		netConn:        opts.netConn,
-		mode:           opts.mode,
-		l:              opts.l.Sugar(),
-		h:              opts.handler,
-		m:              opts.connMetrics,
-		proxy:          p,
-		testRecordsDir: opts.testRecordsDir,
+		netConn:          opts.netConn,
+		mode:             opts.mode,
+		l:                opts.l.Sugar(),
+		h:                opts.handler,
+		m:                opts.connMetrics,
+		sessions:         opts.sessions,
+		failPoints:       opts.failPoints,
+		operations:       opts.operations,
+		fsyncLock:        opts.fsyncLock,
+		maintenance:      opts.maintenance,
+		shutdown:         opts.shutdown,
+		shapes:           opts.shapes,
+		proxy:            p,
+		acceptedAt:       acceptedAt,
+		handshakeTimeout: opts.handshakeTimeout,
+		testRecordsDir:   opts.testRecordsDir,
+		testCommands:     opts.testCommands,
 	}, nil
 }
 
@@ -142,11 +207,38 @@ func (c *conn) run(ctx context.Context) (err error) {
 	}
 
 	ctx = conninfo.WithConnInfo(ctx, connInfo)
+	ctx = session.WithRegistry(ctx, c.sessions)
+	ctx = failpoints.WithRegistry(ctx, c.failPoints)
+	ctx = operation.WithRegistry(ctx, c.operations)
+	ctx = fsynclock.WithLock(ctx, c.fsyncLock)
+	ctx = maintenance.WithMode(ctx, c.maintenance)
+	ctx = shutdown.WithTrigger(ctx, c.shutdown)
+	ctx = wire.WithRecordsDir(ctx, c.testRecordsDir)
+	ctx = commoncommands.WithTestCommands(ctx, c.testCommands)
 
 	done := make(chan struct{})
 
-	// handle ctx cancellation
+	// handle ctx cancellation and graceful shutdown
 	go func() {
+		var drain <-chan struct{}
+		if c.shutdown != nil {
+			drain = c.shutdown.Done()
+		}
+
+		select {
+		case <-done:
+			// nothing, let goroutine exit
+			return
+		case <-drain:
+			// unblocks ReadMessage below if the connection is idle;
+			// the request in progress, if any, is handled, and then the loop exits
+			// the connection could be already closed by the loop below
+			if e := c.netConn.SetReadDeadline(time.Unix(0, 0)); e != nil && !errors.Is(e, net.ErrClosed) {
+				c.l.Warnf("Failed to set read deadline: %s", e)
+			}
+		case <-ctx.Done():
+		}
+
 		select {
 		case <-done:
 			// nothing, let goroutine exit
@@ -155,6 +247,11 @@ func (c *conn) run(ctx context.Context) (err error) {
 			if e := c.netConn.SetDeadline(time.Unix(0, 0)); e != nil {
 				c.l.Warnf("Failed to set deadline: %s", e)
 			}
+
+			// operation contexts are not derived from ctx
+			if op := c.currentOp.Load(); op != nil {
+				op.Kill(context.Cause(ctx))
+			}
 		}
 	}()
 
@@ -236,6 +333,14 @@ func (c *conn) run(ctx context.Context) (err error) {
 		// c.netConn is closed by the caller
 	}()
 
+	// slow clients should not hold connections without sending anything
+	firstMessage := c.handshakeTimeout > 0
+	if firstMessage {
+		if err = c.netConn.SetReadDeadline(c.acceptedAt.Add(c.handshakeTimeout)); err != nil {
+			return
+		}
+	}
+
 	for {
 		var reqHeader *wire.MsgHeader
 		var reqBody wire.MsgBody
@@ -243,7 +348,32 @@ func (c *conn) run(ctx context.Context) (err error) {
 		var resBody wire.MsgBody
 		var validationErr *wire.ValidationError
 
+		if c.draining() {
+			err = errDrained
+			return
+		}
+
 		reqHeader, reqBody, err = wire.ReadMessage(bufr)
+
+		if err != nil && c.draining() {
+			err = errDrained
+			return
+		}
+
+		if firstMessage {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() && ctx.Err() == nil {
+				err = fmt.Errorf("%w: %w", errHandshakeTimeout, err)
+				return
+			}
+
+			if e := c.clearReadDeadline(ctx); e != nil {
+				err = e
+				return
+			}
+
+			firstMessage = false
+		}
 		if err != nil && errors.As(err, &validationErr) {
 			// Currently, we respond with OP_MSG containing an error and don't close the connection.
 			// That's probably not right. First, we always respond with OP_MSG, even to OP_QUERY.
@@ -263,7 +393,7 @@ func (c *conn) run(ctx context.Context) (err error) {
 			b := must.NotFail(res.MarshalBinary())
 
 			resHeader = &wire.MsgHeader{
-				OpCode:        reqHeader.OpCode,
+				OpCode:        wire.OpCodeMsg,
 				RequestID:     c.lastRequestID.Add(1),
 				ResponseTo:    reqHeader.RequestID,
 				MessageLength: int32(wire.MsgHeaderLen + len(b)),
@@ -287,6 +417,14 @@ func (c *conn) run(ctx context.Context) (err error) {
 		c.l.Debugf("Request header: %s", reqHeader)
 		c.l.Debugf("Request message:\n%s\n\n\n", reqBody)
 
+		// handle and proxy the original message;
+		// the response is compressed with the same compressor below
+		var compressor *wire.CompressorID
+		if compressed, ok := reqBody.(*wire.OpCompressed); ok {
+			compressor = &compressed.CompressorID
+			reqHeader, reqBody = compressed.Decompress(reqHeader)
+		}
+
 		// diffLogLevel provides the level of logging for the diff between the "normal" and "proxy" responses.
 		// It is set to the highest level of logging used to log response.
 		var diffLogLevel zapcore.Level
@@ -358,6 +496,10 @@ func (c *conn) run(ctx context.Context) (err error) {
 			}
 
 			c.l.Desugar().Check(diffLogLevel, fmt.Sprintf("Header diff:\n%s\nBody diff:\n%s\n\n", diffHeader, diffBody)).Write()
+
+			if c.shapes != nil {
+				c.checkShape(reqBody, resBody, proxyBody)
+			}
 		}
 
 		// replace response with one from proxy in proxy and diff-proxy modes
@@ -370,6 +512,12 @@ func (c *conn) run(ctx context.Context) (err error) {
 			panic("no response to send to client")
 		}
 
+		if compressor != nil {
+			if resHeader, resBody, err = wire.Compress(resHeader, resBody, *compressor); err != nil {
+				return
+			}
+		}
+
 		if err = wire.WriteMessage(bufw, resHeader, resBody); err != nil {
 			return
 		}
@@ -385,6 +533,45 @@ func (c *conn) run(ctx context.Context) (err error) {
 	}
 }
 
+// errHandshakeTimeout is returned by run when the client does not send the first message in time.
+var errHandshakeTimeout = errors.New("handshake timeout")
+
+// errDrained is returned by run when the connection is closed on graceful shutdown.
+var errDrained = errors.New("connection drained on shutdown")
+
+// draining returns true if graceful shutdown was requested.
+func (c *conn) draining() bool {
+	if c.shutdown == nil {
+		return false
+	}
+
+	select {
+	case <-c.shutdown.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// clearReadDeadline removes the read deadline set for the first message.
+//
+// If ctx is already canceled or shutdown was requested,
+// the past deadline set by the goroutine in run is restored.
+func (c *conn) clearReadDeadline(ctx context.Context) error {
+	if err := c.netConn.SetReadDeadline(time.Time{}); err != nil {
+		return err
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		return c.netConn.SetDeadline(time.Unix(0, 0))
+	case c.draining():
+		return c.netConn.SetReadDeadline(time.Unix(0, 0))
+	default:
+		return nil
+	}
+}
+
 // route sends request to a handler's command based on the op code provided in the request header.
 //
 // The passed context is canceled when the client disconnects.
@@ -394,6 +581,9 @@ func (c *conn) run(ctx context.Context) (err error) {
 //
 // Returned resBody can be nil.
 func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wire.MsgBody) (resHeader *wire.MsgHeader, resBody wire.MsgBody, closeConn bool) { //nolint:lll // argument list is too long
+	c.m.Connections.RequestStarted()
+	defer c.m.Connections.RequestFinished()
+
 	var command, result, argument string
 	defer func() {
 		if result == "" {
@@ -405,6 +595,14 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 		}
 
 		c.m.Responses.WithLabelValues(resHeader.OpCode.String(), command, argument, result).Inc()
+
+		if result == commonerrors.ErrMaxTimeMSExpired.String() {
+			c.m.MaxTimeMSExpired.WithLabelValues(resHeader.OpCode.String(), command).Inc()
+		}
+
+		if result == "ok" {
+			c.observeHandshake(ctx, command)
+		}
 	}()
 
 	resHeader = new(wire.MsgHeader)
@@ -423,7 +621,16 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 			// do not store typed nil in interface, it makes it non-nil
 
 			var resMsg *wire.OpMsg
-			resMsg, err = c.handleOpMsg(ctx, msg, command)
+			opCtx, op, done := c.startOperation(ctx, document)
+			resMsg, err = c.handleOpMsg(opCtx, msg, command)
+
+			if errors.Is(context.Cause(opCtx), operation.ErrKilled) {
+				resMsg = nil
+				err = commonerrors.NewCommandErrorMsg(commonerrors.ErrInterrupted, "operation was interrupted")
+			}
+
+			done()
+			c.observeTop(document, command, time.Since(op.Started), err)
 
 			if resMsg != nil {
 				resBody = resMsg
@@ -434,12 +641,18 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 		query := reqBody.(*wire.OpQuery)
 		resHeader.OpCode = wire.OpCodeReply
 
+		command = query.Query.Command()
+
 		// do not store typed nil in interface, it makes it non-nil
 
 		var resReply *wire.OpReply
-		resReply, err = c.h.CmdQuery(ctx, query)
+		if err = checkOpQueryFlags(query); err == nil {
+			resReply, err = c.h.CmdQuery(ctx, query)
+		}
 
 		if resReply != nil {
+			// like MongoDB, always report that awaitData is supported
+			resReply.ResponseFlags |= wire.OpReplyFlags(wire.OpReplyAwaitCapable)
 			resBody = resReply
 		}
 
@@ -458,6 +671,7 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 	case wire.OpCodeKillCursors:
 		fallthrough
 	case wire.OpCodeCompressed:
+		// compressed messages are decompressed by the caller
 		err = lazyerrors.Errorf("unhandled OpCode %s", reqHeader.OpCode)
 
 	default:
@@ -473,14 +687,18 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 	// set body for error
 	if err != nil {
 		switch resHeader.OpCode {
-		case wire.OpCodeMsg:
+		case wire.OpCodeMsg, wire.OpCodeReply:
 			protoErr := commonerrors.ProtocolError(err)
 
-			var res wire.OpMsg
-			must.NoError(res.SetSections(wire.OpMsgSection{
-				Documents: []*types.Document{protoErr.Document()},
-			}))
-			resBody = &res
+			if resHeader.OpCode == wire.OpCodeReply {
+				resBody = opReplyError(reqBody.(*wire.OpQuery), protoErr)
+			} else {
+				var res wire.OpMsg
+				must.NoError(res.SetSections(wire.OpMsgSection{
+					Documents: []*types.Document{protoErr.Document()},
+				}))
+				resBody = &res
+			}
 
 			switch protoErr := protoErr.(type) {
 			case *commonerrors.CommandError:
@@ -497,8 +715,6 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 
 		case wire.OpCodeQuery:
 			fallthrough
-		case wire.OpCodeReply:
-			fallthrough
 		case wire.OpCodeUpdate:
 			fallthrough
 		case wire.OpCodeInsert:
@@ -554,12 +770,181 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 	return
 }
 
+// handshakeCommands contains commands that drivers send during connection handshake and authentication.
+var handshakeCommands = map[string]struct{}{
+	"hello":        {},
+	"isMaster":     {},
+	"ismaster":     {},
+	"saslStart":    {},
+	"saslContinue": {},
+}
+
+// observeHandshake records the time from accepting the connection to the first successful command
+// that is not a part of the handshake, labeled by TLS usage and authentication mechanism.
+//
+// It does nothing for handshake commands and after the first call that records the time.
+func (c *conn) observeHandshake(ctx context.Context, command string) {
+	if c.handshakeDone {
+		return
+	}
+
+	if _, ok := handshakeCommands[command]; ok {
+		return
+	}
+
+	c.handshakeDone = true
+
+	_, isTLS := c.netConn.(*tls.Conn)
+
+	mechanism := conninfo.Get(ctx).Mechanism()
+	if mechanism == "" {
+		mechanism = "none"
+	}
+
+	c.m.Handshakes.WithLabelValues(strconv.FormatBool(isTLS), mechanism).Observe(time.Since(c.acceptedAt).Seconds())
+}
+
+// observeTop records the command execution time for the namespace of the command in top totals.
+//
+// Commands without a collection namespace are not recorded.
+// Successful drop and dropDatabase commands remove totals of dropped namespaces.
+func (c *conn) observeTop(document *types.Document, command string, d time.Duration, err error) {
+	db, _ := document.Get("$db")
+	dbName, _ := db.(string)
+
+	if dbName == "" {
+		return
+	}
+
+	if command == "dropDatabase" {
+		if err == nil {
+			c.m.Top.Drop(dbName, "")
+		}
+
+		return
+	}
+
+	field := command
+	if command == "getMore" {
+		field = "collection"
+	}
+
+	v, _ := document.Get(field)
+	collection, _ := v.(string)
+
+	if collection == "" {
+		return
+	}
+
+	if command == "drop" && err == nil {
+		c.m.Top.Drop(dbName, collection)
+		return
+	}
+
+	c.m.Top.Observe(dbName, collection, command, d)
+}
+
+// startOperation registers the command in the operation registry, so it is reported by currentOp
+// and could be killed by killOp.
+//
+// The returned context is canceled by killOp or when the connection's context is canceled.
+// The returned function must be called when the command finishes.
+func (c *conn) startOperation(ctx context.Context, document *types.Document) (context.Context, *operation.Operation, func()) { //nolint:lll // argument list is too long
+	db, _ := document.Get("$db")
+	dbName, _ := db.(string)
+
+	connInfo := conninfo.Get(ctx)
+	username, _ := connInfo.Auth()
+
+	opCtx, op, done := c.operations.Start(ctx, dbName, document, connInfo.PeerAddr, username)
+
+	c.currentOp.Store(op)
+
+	// ctx could be canceled before the operation was stored
+	if ctx.Err() != nil {
+		op.Kill(context.Cause(ctx))
+	}
+
+	return opCtx, op, func() {
+		c.currentOp.Store(nil)
+		done()
+	}
+}
+
+// writeCommands contains commands that modify data or metadata;
+// they wait while the server is locked by fsync command,
+// and they are rejected in maintenance mode.
+var writeCommands = map[string]struct{}{
+	"applyOps":                {},
+	"bulkWrite":               {},
+	"cloneCollectionAsCapped": {},
+	"collMod":                 {},
+	"compact":                 {},
+	"convertToCapped":         {},
+	"create":                  {},
+	"createIndexes":           {},
+	"createUser":              {},
+	"delete":                  {},
+	"drop":                    {},
+	"dropDatabase":            {},
+	"dropIndexes":             {},
+	"dropUser":                {},
+	"findAndModify":           {},
+	"findandmodify":           {},
+	"insert":                  {},
+	"reIndex":                 {},
+	"renameCollection":        {},
+	"update":                  {},
+	"updateUser":              {},
+}
+
 // handleOpMsg processes OP_MSG request.
 //
 // The passed context is canceled when the client disconnects.
 func (c *conn) handleOpMsg(ctx context.Context, msg *wire.OpMsg, command string) (*wire.OpMsg, error) {
 	if cmd, ok := commoncommands.Commands[command]; ok {
-		if cmd.Handler != nil {
+		if cmd.Handler != nil && (!cmd.Test || commoncommands.TestCommandsEnabled(ctx)) {
+			document, err := msg.Document()
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			common.TrackSession(ctx, document)
+
+			if err = c.checkFailCommand(ctx, command); err != nil {
+				return nil, err
+			}
+
+			if !cmd.Public {
+				db, _ := document.Get("$db")
+				dbName, _ := db.(string)
+
+				if err = common.Authorize(ctx, command, dbName); err != nil {
+					return nil, err
+				}
+
+				if err = common.AuthorizeBypassDocumentValidation(ctx, document, dbName); err != nil {
+					return nil, err
+				}
+			}
+
+			if _, ok := writeCommands[command]; ok {
+				if enabled, message := c.maintenance.State(); enabled {
+					return nil, commonerrors.NewCommandErrorMsgWithLabels(
+						commonerrors.ErrNotWritablePrimary,
+						message,
+						"RetryableWriteError",
+					)
+				}
+
+				var finish func()
+				if finish, err = c.fsyncLock.StartWrite(ctx); err != nil {
+					return nil, lazyerrors.Error(err)
+				}
+
+				defer finish()
+			}
+
 			// TODO move it to route, closer to Prometheus metrics
 			defer observability.FuncCall(ctx)()
 
@@ -567,7 +952,20 @@ func (c *conn) handleOpMsg(ctx context.Context, msg *wire.OpMsg, command string)
 			ctx = pprof.WithLabels(ctx, pprof.Labels("command", command))
 			pprof.SetGoroutineLabels(ctx)
 
-			return cmd.Handler(c.h, ctx, msg)
+			ctx = common.WithWarnings(ctx)
+
+			res, err := common.RunInTransaction(ctx, document, c.h.BeginTransaction, func(ctx context.Context) (*wire.OpMsg, error) {
+				return cmd.Handler(c.h, ctx, msg)
+			})
+			if err != nil {
+				return res, err
+			}
+
+			if err = common.SetWarnings(ctx, res); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			return res, nil
 		}
 	}
 
@@ -607,3 +1005,46 @@ func (c *conn) logResponse(who string, resHeader *wire.MsgHeader, resBody wire.M
 
 	return level
 }
+
+// checkShape compares shapes of OP_MSG responses in diff modes,
+// records differences in the report and logs them.
+func (c *conn) checkShape(reqBody, resBody, proxyBody wire.MsgBody) {
+	reqMsg, ok := reqBody.(*wire.OpMsg)
+	if !ok {
+		return
+	}
+
+	resMsg, ok := resBody.(*wire.OpMsg)
+	if !ok {
+		return
+	}
+
+	proxyMsg, ok := proxyBody.(*wire.OpMsg)
+	if !ok {
+		return
+	}
+
+	reqDoc, err := reqMsg.Document()
+	if err != nil {
+		return
+	}
+
+	resDoc, err := resMsg.Document()
+	if err != nil {
+		return
+	}
+
+	proxyDoc, err := proxyMsg.Document()
+	if err != nil {
+		return
+	}
+
+	command := reqDoc.Command()
+	diffs := shape.Compare(resDoc, proxyDoc)
+
+	c.shapes.Add(command, diffs)
+
+	for _, d := range diffs {
+		c.l.Warnf("Response shape difference for %q: %s", command, d)
+	}
+}
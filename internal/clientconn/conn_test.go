@@ -0,0 +1,120 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
+	"github.com/FerretDB/FerretDB/internal/clientconn/shutdown"
+)
+
+func TestObserveHandshake(t *testing.T) {
+	t.Parallel()
+
+	netConn, _ := net.Pipe()
+	t.Cleanup(func() { netConn.Close() })
+
+	m := connmetrics.NewListenerMetrics().ConnMetrics
+
+	c := &conn{
+		netConn:    netConn,
+		m:          m,
+		acceptedAt: time.Now(),
+	}
+
+	connInfo := conninfo.NewConnInfo()
+	t.Cleanup(connInfo.Close)
+
+	ctx := conninfo.WithConnInfo(context.Background(), connInfo)
+
+	c.observeHandshake(ctx, "hello")
+	c.observeHandshake(ctx, "saslStart")
+	assert.Equal(t, 0, testutil.CollectAndCount(m.Handshakes))
+
+	connInfo.SetMechanism("PLAIN")
+
+	c.observeHandshake(ctx, "find")
+	c.observeHandshake(ctx, "insert")
+	assert.Equal(t, 1, testutil.CollectAndCount(m.Handshakes))
+
+	var metric dto.Metric
+	require.NoError(t, m.Handshakes.WithLabelValues("false", "PLAIN").(prometheus.Histogram).Write(&metric))
+	assert.Equal(t, uint64(1), metric.GetHistogram().GetSampleCount())
+}
+
+func TestHandshakeTimeout(t *testing.T) {
+	t.Parallel()
+
+	netConn, clientConn := net.Pipe()
+	t.Cleanup(func() {
+		netConn.Close()
+		clientConn.Close()
+	})
+
+	c := &conn{
+		netConn:          netConn,
+		mode:             NormalMode,
+		l:                zaptest.NewLogger(t).Sugar(),
+		m:                connmetrics.NewListenerMetrics().ConnMetrics,
+		acceptedAt:       time.Now(),
+		handshakeTimeout: 50 * time.Millisecond,
+	}
+
+	err := c.run(context.Background())
+	assert.ErrorIs(t, err, errHandshakeTimeout)
+}
+
+func TestDrain(t *testing.T) {
+	t.Parallel()
+
+	netConn, clientConn := net.Pipe()
+	t.Cleanup(func() {
+		netConn.Close()
+		clientConn.Close()
+	})
+
+	trigger := shutdown.NewTrigger(time.Second)
+
+	c := &conn{
+		netConn:    netConn,
+		mode:       NormalMode,
+		l:          zaptest.NewLogger(t).Sugar(),
+		m:          connmetrics.NewListenerMetrics().ConnMetrics,
+		shutdown:   trigger,
+		acceptedAt: time.Now(),
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.run(context.Background())
+	}()
+
+	// idle connection is closed without waiting for the timeout
+	trigger.Shutdown(time.Hour)
+
+	assert.ErrorIs(t, <-errCh, errDrained)
+}
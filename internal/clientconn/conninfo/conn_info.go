@@ -19,6 +19,9 @@ import (
 	"context"
 	"sync"
 
+	"github.com/xdg-go/scram"
+
+	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/resource"
 )
 
@@ -37,6 +40,12 @@ type ConnInfo struct {
 	rw       sync.RWMutex
 	username string
 	password string
+	roles    *types.Array
+
+	mechanism string
+
+	conv      *scram.ServerConversation
+	convRoles *types.Array
 }
 
 // NewConnInfo return a new ConnInfo.
@@ -63,12 +72,71 @@ func (connInfo *ConnInfo) Auth() (username, password string) {
 }
 
 // SetAuth stores username and password.
+//
+// It also removes stored roles.
 func (connInfo *ConnInfo) SetAuth(username, password string) {
 	connInfo.rw.Lock()
 	defer connInfo.rw.Unlock()
 
 	connInfo.username = username
 	connInfo.password = password
+	connInfo.roles = nil
+}
+
+// Mechanism returns the authentication mechanism of the last successful authentication,
+// or empty string if the connection was not authenticated.
+func (connInfo *ConnInfo) Mechanism() string {
+	connInfo.rw.RLock()
+	defer connInfo.rw.RUnlock()
+
+	return connInfo.mechanism
+}
+
+// SetMechanism stores the authentication mechanism.
+func (connInfo *ConnInfo) SetMechanism(mechanism string) {
+	connInfo.rw.Lock()
+	defer connInfo.rw.Unlock()
+
+	connInfo.mechanism = mechanism
+}
+
+// Roles returns stored roles of the authenticated user.
+//
+// Nil value means that the connection is not authenticated as a user stored by FerretDB,
+// and authorization is not enforced.
+func (connInfo *ConnInfo) Roles() *types.Array {
+	connInfo.rw.RLock()
+	defer connInfo.rw.RUnlock()
+
+	return connInfo.roles
+}
+
+// SetRoles stores roles of the authenticated user.
+func (connInfo *ConnInfo) SetRoles(roles *types.Array) {
+	connInfo.rw.Lock()
+	defer connInfo.rw.Unlock()
+
+	connInfo.roles = roles
+}
+
+// SASLConversation returns stored SCRAM server conversation and roles of the user, if any.
+func (connInfo *ConnInfo) SASLConversation() (*scram.ServerConversation, *types.Array) {
+	connInfo.rw.RLock()
+	defer connInfo.rw.RUnlock()
+
+	return connInfo.conv, connInfo.convRoles
+}
+
+// SetSASLConversation stores SCRAM server conversation and roles of the user
+// that should be stored when the conversation successfully completes.
+//
+// Nil value removes stored conversation.
+func (connInfo *ConnInfo) SetSASLConversation(conv *scram.ServerConversation, roles *types.Array) {
+	connInfo.rw.Lock()
+	defer connInfo.rw.Unlock()
+
+	connInfo.conv = conv
+	connInfo.convRoles = roles
 }
 
 // WithConnInfo returns a new context with the given ConnInfo.
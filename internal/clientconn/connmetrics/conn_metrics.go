@@ -26,8 +26,14 @@ import (
 
 // ConnMetrics represents metrics of an individual conn or a collection of conns.
 type ConnMetrics struct {
-	Requests  *prometheus.CounterVec
-	Responses *prometheus.CounterVec
+	Requests         *prometheus.CounterVec
+	Responses        *prometheus.CounterVec
+	MaxTimeMSExpired *prometheus.CounterVec
+	Handshakes       *prometheus.HistogramVec
+
+	// Top and Connections are not Prometheus collectors; see their documentation.
+	Top         *Top
+	Connections *Connections
 }
 
 // commandMetrics represents command results metrics.
@@ -57,6 +63,36 @@ func newConnMetrics() *ConnMetrics {
 			},
 			[]string{"opcode", "command", "argument", "result"},
 		),
+		MaxTimeMSExpired: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "maxtimems_expired_total",
+				Help:      "Total number of commands that exceeded their maxTimeMS time limit.",
+			},
+			[]string{"opcode", "command"},
+		),
+		Handshakes: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "handshake_duration_seconds",
+				Help:      "Time from accepting client connection to the first successful command after handshake in seconds.",
+				Buckets: []float64{
+					0.001,
+					0.005,
+					0.01,
+					0.05,
+					0.1,
+					0.5,
+					1,
+					5,
+				},
+			},
+			[]string{"tls", "mechanism"},
+		),
+		Top:         NewTop(),
+		Connections: NewConnections(),
 	}
 }
 
@@ -64,12 +100,16 @@ func newConnMetrics() *ConnMetrics {
 func (cm *ConnMetrics) Describe(ch chan<- *prometheus.Desc) {
 	cm.Requests.Describe(ch)
 	cm.Responses.Describe(ch)
+	cm.MaxTimeMSExpired.Describe(ch)
+	cm.Handshakes.Describe(ch)
 }
 
 // Collect implements prometheus.Collector.
 func (cm *ConnMetrics) Collect(ch chan<- prometheus.Metric) {
 	cm.Requests.Collect(ch)
 	cm.Responses.Collect(ch)
+	cm.MaxTimeMSExpired.Collect(ch)
+	cm.Handshakes.Collect(ch)
 }
 
 // GetResponses returns a map with all response metrics:
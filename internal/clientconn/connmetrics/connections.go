@@ -0,0 +1,81 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmetrics
+
+import "sync/atomic"
+
+// connectionsMax is the maximum number of connections used to report available connections.
+//
+// FerretDB does not limit the number of incoming connections,
+// so that's the default value of MongoDB's net.maxIncomingConnections.
+const connectionsMax = 1_000_000
+
+// ConnectionsStats represents a snapshot of connection counters, like in MongoDB's serverStatus.connections.
+type ConnectionsStats struct {
+	Current      int64 // open connections
+	Available    int64 // connections that could be opened in addition to current ones
+	TotalCreated int64 // connections created since the start, including closed ones
+	Active       int64 // connections with a request in progress
+}
+
+// Connections tracks the lifecycle of client connections.
+//
+// Like Top, it is not a Prometheus collector;
+// Prometheus metrics for accepted connections are provided by ListenerMetrics.
+//
+// Connections methods are thread-safe.
+type Connections struct {
+	current      atomic.Int64
+	totalCreated atomic.Int64
+	active       atomic.Int64
+}
+
+// NewConnections creates a new Connections.
+func NewConnections() *Connections {
+	return new(Connections)
+}
+
+// Opened registers a new connection.
+func (c *Connections) Opened() {
+	c.current.Add(1)
+	c.totalCreated.Add(1)
+}
+
+// Closed unregisters a connection previously registered with Opened.
+func (c *Connections) Closed() {
+	c.current.Add(-1)
+}
+
+// RequestStarted marks the connection as active until RequestFinished is called.
+func (c *Connections) RequestStarted() {
+	c.active.Add(1)
+}
+
+// RequestFinished marks the connection as no longer active.
+func (c *Connections) RequestFinished() {
+	c.active.Add(-1)
+}
+
+// Stats returns the current values of connection counters.
+func (c *Connections) Stats() ConnectionsStats {
+	current := c.current.Load()
+
+	return ConnectionsStats{
+		Current:      current,
+		Available:    connectionsMax - current,
+		TotalCreated: c.totalCreated.Load(),
+		Active:       c.active.Load(),
+	}
+}
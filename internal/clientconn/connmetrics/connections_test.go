@@ -0,0 +1,48 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmetrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnections(t *testing.T) {
+	t.Parallel()
+
+	c := NewConnections()
+
+	c.Opened()
+	c.Opened()
+	c.RequestStarted()
+
+	assert.Equal(t, ConnectionsStats{
+		Current:      2,
+		Available:    connectionsMax - 2,
+		TotalCreated: 2,
+		Active:       1,
+	}, c.Stats())
+
+	c.RequestFinished()
+	c.Closed()
+
+	assert.Equal(t, ConnectionsStats{
+		Current:      1,
+		Available:    connectionsMax - 1,
+		TotalCreated: 2,
+		Active:       0,
+	}, c.Stats())
+}
@@ -30,6 +30,7 @@ const (
 type ListenerMetrics struct {
 	Accepts     *prometheus.CounterVec
 	Durations   *prometheus.HistogramVec
+	Dropped     *prometheus.CounterVec
 	ConnMetrics *ConnMetrics
 }
 
@@ -64,6 +65,15 @@ func NewListenerMetrics() *ListenerMetrics {
 			},
 			[]string{"error"},
 		),
+		Dropped: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "dropped_total",
+				Help:      "Total number of client connections dropped before the first message was handled.",
+			},
+			[]string{"reason"},
+		),
 
 		ConnMetrics: newConnMetrics(),
 	}
@@ -73,6 +83,7 @@ func NewListenerMetrics() *ListenerMetrics {
 func (lm *ListenerMetrics) Describe(ch chan<- *prometheus.Desc) {
 	lm.Accepts.Describe(ch)
 	lm.Durations.Describe(ch)
+	lm.Dropped.Describe(ch)
 	lm.ConnMetrics.Describe(ch)
 }
 
@@ -80,6 +91,7 @@ func (lm *ListenerMetrics) Describe(ch chan<- *prometheus.Desc) {
 func (lm *ListenerMetrics) Collect(ch chan<- prometheus.Metric) {
 	lm.Accepts.Collect(ch)
 	lm.Durations.Collect(ch)
+	lm.Dropped.Collect(ch)
 	lm.ConnMetrics.Collect(ch)
 }
 
@@ -0,0 +1,152 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmetrics
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// TopStat represents the total time and the number of operations.
+type TopStat struct {
+	Time  time.Duration
+	Count int64
+}
+
+// add adds a single operation with the given duration.
+func (s *TopStat) add(d time.Duration) {
+	s.Time += d
+	s.Count++
+}
+
+// TopNamespace contains operation totals for a single namespace, like in MongoDB's top command.
+type TopNamespace struct {
+	Total     TopStat
+	ReadLock  TopStat
+	WriteLock TopStat
+	Queries   TopStat
+	GetMore   TopStat
+	Insert    TopStat
+	Update    TopStat
+	Remove    TopStat
+	Commands  TopStat
+}
+
+// topWriteCommands contains commands other than insert, update, and delete that modify the namespace.
+var topWriteCommands = map[string]struct{}{
+	"cloneCollectionAsCapped": {},
+	"collMod":                 {},
+	"compact":                 {},
+	"convertToCapped":         {},
+	"create":                  {},
+	"createIndexes":           {},
+	"drop":                    {},
+	"dropIndexes":             {},
+	"findAndModify":           {},
+	"findandmodify":           {},
+	"reIndex":                 {},
+	"renameCollection":        {},
+}
+
+// Top tracks per-namespace operation latency totals.
+//
+// Unlike other metrics, they are not exported to Prometheus
+// to avoid unbounded cardinality of namespace labels.
+//
+// Top methods are thread-safe.
+type Top struct {
+	rw         sync.RWMutex
+	namespaces map[string]*TopNamespace
+}
+
+// NewTop creates a new Top.
+func NewTop() *Top {
+	return &Top{
+		namespaces: map[string]*TopNamespace{},
+	}
+}
+
+// Observe records the command execution time for the given database and collection.
+func (t *Top) Observe(db, collection, command string, d time.Duration) {
+	ns := db + "." + collection
+
+	t.rw.Lock()
+	defer t.rw.Unlock()
+
+	n := t.namespaces[ns]
+	if n == nil {
+		n = new(TopNamespace)
+		t.namespaces[ns] = n
+	}
+
+	n.Total.add(d)
+
+	write := true
+
+	switch command {
+	case "find":
+		n.Queries.add(d)
+		write = false
+	case "getMore":
+		n.GetMore.add(d)
+		write = false
+	case "insert":
+		n.Insert.add(d)
+	case "update":
+		n.Update.add(d)
+	case "delete":
+		n.Remove.add(d)
+	default:
+		n.Commands.add(d)
+		_, write = topWriteCommands[command]
+	}
+
+	if write {
+		n.WriteLock.add(d)
+	} else {
+		n.ReadLock.add(d)
+	}
+}
+
+// Drop removes totals of the given collection, or of all database collections if collection is empty.
+func (t *Top) Drop(db, collection string) {
+	t.rw.Lock()
+	defer t.rw.Unlock()
+
+	if collection != "" {
+		delete(t.namespaces, db+"."+collection)
+		return
+	}
+
+	for ns := range t.namespaces {
+		if strings.HasPrefix(ns, db+".") {
+			delete(t.namespaces, ns)
+		}
+	}
+}
+
+// Namespaces returns a copy of totals by namespace.
+func (t *Top) Namespaces() map[string]TopNamespace {
+	t.rw.RLock()
+	defer t.rw.RUnlock()
+
+	res := make(map[string]TopNamespace, len(t.namespaces))
+	for ns, n := range t.namespaces {
+		res[ns] = *n
+	}
+
+	return res
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmetrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/maps"
+)
+
+func TestTop(t *testing.T) {
+	top := NewTop()
+
+	top.Observe("db", "coll", "find", 2*time.Microsecond)
+	top.Observe("db", "coll", "insert", 3*time.Microsecond)
+	top.Observe("db", "coll", "createIndexes", 5*time.Microsecond)
+	top.Observe("db", "coll", "count", 7*time.Microsecond)
+	top.Observe("db", "other", "getMore", time.Microsecond)
+	top.Observe("other", "coll", "delete", time.Microsecond)
+
+	expected := TopNamespace{
+		Total:     TopStat{Time: 17 * time.Microsecond, Count: 4},
+		ReadLock:  TopStat{Time: 9 * time.Microsecond, Count: 2},
+		WriteLock: TopStat{Time: 8 * time.Microsecond, Count: 2},
+		Queries:   TopStat{Time: 2 * time.Microsecond, Count: 1},
+		Insert:    TopStat{Time: 3 * time.Microsecond, Count: 1},
+		Commands:  TopStat{Time: 12 * time.Microsecond, Count: 2},
+	}
+
+	actual := top.Namespaces()
+	assert.Len(t, actual, 3)
+	assert.Equal(t, expected, actual["db.coll"])
+	assert.Equal(t, TopStat{Time: time.Microsecond, Count: 1}, actual["db.other"].GetMore)
+	assert.Equal(t, TopStat{Time: time.Microsecond, Count: 1}, actual["other.coll"].Remove)
+
+	top.Drop("db", "coll")
+	assert.Len(t, top.Namespaces(), 2)
+
+	top.Drop("db", "")
+	assert.Equal(t, []string{"other.coll"}, maps.Keys(top.Namespaces()))
+}
@@ -17,8 +17,11 @@ package cursor
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.uber.org/zap"
+
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/resource"
 )
@@ -40,30 +43,48 @@ import (
 type Cursor struct {
 	// the order of fields is weird to make the struct smaller due to alignment
 
-	created    time.Time
-	iter       types.DocumentsIterator
-	r          *Registry
-	token      *resource.Token
-	closed     chan struct{}
-	DB         string
-	Collection string
-	Username   string
-	ID         int64
-	closeOnce  sync.Once
+	created            time.Time
+	iter               types.DocumentsIterator
+	pending            *types.Document // returned by Unread
+	r                  *Registry
+	token              *resource.Token
+	closed             chan struct{}
+	returned           atomic.Int64 // number of documents returned by Next and not unread
+	OriginatingCommand *types.Document
+	Comment            any
+	DB                 string
+	Collection         string
+	Username           string
+	Client             string // address of the originating connection; empty for Unix sockets
+	QueryShapeHash     string
+	ID                 int64
+	LastRecordID       int64 // of the last document returned to the client; used for resume tokens
+	closeOnce          sync.Once
+	Tailable           bool
+	AwaitData          bool
+	ResumeToken        bool
 }
 
 // newCursor creates a new cursor.
-func newCursor(id int64, db, collection, username string, iter types.DocumentsIterator, r *Registry) *Cursor {
+func newCursor(id int64, params *NewParams, r *Registry) *Cursor {
 	c := &Cursor{
-		ID:         id,
-		DB:         db,
-		Collection: collection,
-		Username:   username,
-		iter:       iter,
-		r:          r,
-		created:    time.Now(),
-		closed:     make(chan struct{}),
-		token:      resource.NewToken(),
+		ID:                 id,
+		DB:                 params.DB,
+		Collection:         params.Collection,
+		Username:           params.Username,
+		Client:             params.Client,
+		OriginatingCommand: params.OriginatingCommand,
+		Comment:            params.Comment,
+		QueryShapeHash:     params.QueryShapeHash,
+		Tailable:           params.Tailable,
+		AwaitData:          params.AwaitData,
+		ResumeToken:        params.ResumeToken,
+		LastRecordID:       params.LastRecordID,
+		iter:               params.Iter,
+		r:                  r,
+		created:            time.Now(),
+		closed:             make(chan struct{}),
+		token:              resource.NewToken(),
 	}
 
 	resource.Track(c, c.token)
@@ -73,7 +94,72 @@ func newCursor(id int64, db, collection, username string, iter types.DocumentsIt
 
 // Next implements types.DocumentsIterator interface.
 func (c *Cursor) Next() (struct{}, *types.Document, error) {
-	return c.iter.Next()
+	if doc := c.pending; doc != nil {
+		c.pending = nil
+		c.returned.Add(1)
+
+		return struct{}{}, doc, nil
+	}
+
+	_, doc, err := c.iter.Next()
+	if err == nil {
+		c.returned.Add(1)
+	}
+
+	return struct{}{}, doc, err
+}
+
+// Unread returns the document to the cursor, so it is returned by the next call of Next.
+// That's used when the document does not fit into the current batch.
+//
+// Only one document could be returned between calls of Next.
+func (c *Cursor) Unread(doc *types.Document) {
+	if c.pending != nil {
+		panic("cursor already has an unread document")
+	}
+
+	c.pending = doc
+	c.returned.Add(-1)
+}
+
+// Created returns the time when the cursor was created.
+func (c *Cursor) Created() time.Time {
+	return c.created
+}
+
+// Returned returns the number of documents returned to the client so far.
+//
+// It is safe to call it concurrently with the cursor iteration.
+func (c *Cursor) Returned() int64 {
+	return c.returned.Load()
+}
+
+// NS returns the namespace of the cursor that is used in cursor replies and getMore commands.
+func (c *Cursor) NS() string {
+	return c.DB + "." + c.Collection
+}
+
+// LogOperation logs the operation on the cursor, such as getMore or killCursors,
+// with the comment and the query shape hash of the originating command,
+// so it could be correlated with the initial find or aggregate.
+//
+// The comment of the operation itself is logged if it is not nil.
+func (c *Cursor) LogOperation(command string, comment any) {
+	fields := []zap.Field{
+		zap.Int64("id", c.ID),
+		zap.String("command", command),
+		zap.String("queryShapeHash", c.QueryShapeHash),
+	}
+
+	if c.Comment != nil {
+		fields = append(fields, zap.String("originatingComment", types.FormatAnyValue(c.Comment)))
+	}
+
+	if comment != nil {
+		fields = append(fields, zap.String("comment", types.FormatAnyValue(comment)))
+	}
+
+	c.r.l.Debug("Operation", fields...)
 }
 
 // Close implements types.DocumentsIterator interface.
@@ -25,6 +25,7 @@ import (
 	"go.uber.org/zap"
 	"golang.org/x/exp/maps"
 
+	"github.com/FerretDB/FerretDB/internal/clientconn/operation"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/debugbuild"
 )
@@ -112,13 +113,38 @@ type NewParams struct {
 	DB         string
 	Collection string
 	Username   string
+
+	// Client is the address of the originating connection; empty for Unix sockets.
+	Client string
+
+	// OriginatingCommand is the find or aggregate command document that created the cursor.
+	OriginatingCommand *types.Document
+
+	// Comment and QueryShapeHash of the originating command correlate getMore and killCursors with it.
+	Comment        any
+	QueryShapeHash string
+
+	// Tailable cursors are not closed when the iterator is exhausted;
+	// their iterator may return new documents after iterator.ErrIteratorDone.
+	Tailable bool
+
+	// AwaitData makes getMore on tailable cursor wait for new documents.
+	AwaitData bool
+
+	// ResumeToken makes find and getMore replies include postBatchResumeToken.
+	// LastRecordID is the initial position for it.
+	ResumeToken  bool
+	LastRecordID int64
 }
 
 // NewCursor creates and stores a new cursor.
 //
 // The cursor will be closed automatically when a given context is canceled,
 // even if the cursor is not being used at that time.
+// If the context belongs to an operation, the connection context of the operation is used instead.
 func (r *Registry) NewCursor(ctx context.Context, params *NewParams) *Cursor {
+	ctx = operation.ConnContext(ctx)
+
 	r.rw.Lock()
 	defer r.rw.Unlock()
 
@@ -133,11 +159,12 @@ func (r *Registry) NewCursor(ctx context.Context, params *NewParams) *Cursor {
 		zap.Int64("id", id),
 		zap.String("db", params.DB),
 		zap.String("collection", params.Collection),
+		zap.String("queryShapeHash", params.QueryShapeHash),
 	)
 
 	r.created.WithLabelValues(params.DB, params.Collection, params.Username).Inc()
 
-	c := newCursor(id, params.DB, params.Collection, params.Username, params.Iter, r)
+	c := newCursor(id, params, r)
 	r.m[id] = c
 
 	r.wg.Add(1)
@@ -182,6 +209,7 @@ func (r *Registry) delete(c *Cursor) {
 		zap.Int("total", len(r.m)),
 		zap.Int64("id", c.ID),
 		zap.Duration("duration", d),
+		zap.String("queryShapeHash", c.QueryShapeHash),
 	)
 
 	r.duration.WithLabelValues(c.DB, c.Collection, c.Username).Observe(d.Seconds())
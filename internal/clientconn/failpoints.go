@@ -0,0 +1,83 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"context"
+	"errors"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/failpoints"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// checkFailCommand evaluates failCommand failpoint for the given command.
+//
+// Failpoint's data must contain `failCommands` array with command names it applies to.
+// It may also contain:
+//   - `blockConnection` and `blockTimeMS` to delay the command;
+//   - `closeConnection` to close the connection without a response;
+//   - `errorCode` to return a command error with that code.
+//
+// The configureFailPoint command itself is never affected.
+func (c *conn) checkFailCommand(ctx context.Context, command string) error {
+	if command == "configureFailPoint" {
+		return nil
+	}
+
+	data, ok := failpoints.Evaluate(ctx, failpoints.FailCommand, func(data *types.Document) bool {
+		return failpoints.Contains(data, "failCommands", command, false)
+	})
+	if !ok {
+		return nil
+	}
+
+	if v, _ := data.Get("blockConnection"); v == true {
+		if err := failpoints.Block(ctx, data); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if v, _ := data.Get("closeConnection"); v == true {
+		c.l.Debugf("Closing connection via failCommand failpoint for %q.", command)
+
+		if err := c.netConn.Close(); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return lazyerrors.Error(errors.New("connection closed via failCommand failpoint"))
+	}
+
+	var code int64
+
+	switch v, _ := data.Get("errorCode"); v := v.(type) {
+	case float64:
+		code = int64(v)
+	case int32:
+		code = int64(v)
+	case int64:
+		code = v
+	}
+
+	if code == 0 {
+		return nil
+	}
+
+	return commonerrors.NewCommandErrorMsg(
+		commonerrors.ErrorCode(code),
+		"Failing command via 'failCommand' failpoint",
+	)
+}
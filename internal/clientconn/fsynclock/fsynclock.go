@@ -0,0 +1,174 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fsynclock provides a lock that blocks write commands while the server is locked by fsync command.
+package fsynclock
+
+import (
+	"context"
+	"sync"
+)
+
+// contextKey is a named unexported type for the safe use of context.WithValue.
+type contextKey struct{}
+
+// Context key for WithLock/GetLock.
+var lockKey = contextKey{}
+
+// Lock blocks write commands while the server is locked by fsync command.
+//
+// Like in MongoDB, fsync locks are counted: the server is unlocked only
+// when fsyncUnlock was called as many times as fsync with lock.
+//
+// Unlike sync.RWMutex, waiting for Lock can be canceled, and Unlock could be called by a different connection.
+//
+//nolint:vet // for readability
+type Lock struct {
+	m        sync.Mutex
+	count    int64         // number of fsync locks
+	writes   int64         // number of write commands in progress
+	unlocked chan struct{} // closed when count drops to zero; nil when count is zero
+	drained  chan struct{} // closed when writes drop to zero; nil if nobody waits for that
+}
+
+// NewLock creates a new Lock.
+func NewLock() *Lock {
+	return new(Lock)
+}
+
+// WithLock returns a new context with the given Lock.
+func WithLock(ctx context.Context, l *Lock) context.Context {
+	return context.WithValue(ctx, lockKey, l)
+}
+
+// GetLock returns the Lock value stored in ctx.
+func GetLock(ctx context.Context) *Lock {
+	value := ctx.Value(lockKey)
+	if value == nil {
+		panic("fsynclock.GetLock: fsync lock is not set")
+	}
+
+	l, ok := value.(*Lock)
+	if !ok {
+		panic("fsynclock.GetLock: fsync lock is set but has a wrong type")
+	}
+
+	return l
+}
+
+// StartWrite waits until the server is unlocked and registers a write command.
+//
+// It returns a function that should be called when the write command finishes,
+// or the context error if ctx is canceled while waiting.
+func (l *Lock) StartWrite(ctx context.Context) (func(), error) {
+	for {
+		l.m.Lock()
+
+		if l.count == 0 {
+			l.writes++
+			l.m.Unlock()
+
+			return l.finishWrite, nil
+		}
+
+		unlocked := l.unlocked
+		l.m.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		case <-unlocked:
+		}
+	}
+}
+
+// finishWrite unregisters a write command registered by StartWrite.
+func (l *Lock) finishWrite() {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	l.writes--
+
+	if l.writes == 0 && l.drained != nil {
+		close(l.drained)
+		l.drained = nil
+	}
+}
+
+// Lock increments the lock count, blocking new write commands,
+// and waits until write commands in progress finish.
+//
+// It returns the new lock count, or the context error if ctx is canceled while waiting;
+// in that case, the lock count is not changed.
+func (l *Lock) Lock(ctx context.Context) (int64, error) {
+	l.m.Lock()
+
+	l.count++
+	if l.count == 1 {
+		l.unlocked = make(chan struct{})
+	}
+
+	count := l.count
+
+	for l.writes > 0 {
+		if l.drained == nil {
+			l.drained = make(chan struct{})
+		}
+
+		drained := l.drained
+		l.m.Unlock()
+
+		select {
+		case <-ctx.Done():
+			l.Unlock()
+			return 0, context.Cause(ctx)
+		case <-drained:
+		}
+
+		l.m.Lock()
+	}
+
+	l.m.Unlock()
+
+	return count, nil
+}
+
+// Unlock decrements the lock count, unblocking write commands when it drops to zero.
+//
+// It returns the new lock count, and false if the server was not locked.
+func (l *Lock) Unlock() (int64, bool) {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	if l.count == 0 {
+		return 0, false
+	}
+
+	l.count--
+
+	if l.count == 0 {
+		close(l.unlocked)
+		l.unlocked = nil
+	}
+
+	return l.count, true
+}
+
+// Count returns the current lock count.
+func (l *Lock) Count() int64 {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	return l.count
+}
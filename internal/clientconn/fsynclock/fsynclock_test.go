@@ -0,0 +1,119 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fsynclock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	l := NewLock()
+
+	_, ok := l.Unlock()
+	assert.False(t, ok)
+
+	finish, err := l.StartWrite(ctx)
+	require.NoError(t, err)
+
+	locked := make(chan int64)
+
+	go func() {
+		count, lockErr := l.Lock(ctx)
+		assert.NoError(t, lockErr)
+		locked <- count
+	}()
+
+	// Lock waits for the write in progress
+	select {
+	case <-locked:
+		t.Fatal("Lock returned before the write finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	finish()
+	assert.Equal(t, int64(1), <-locked)
+
+	count, err := l.Lock(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.Equal(t, int64(2), l.Count())
+
+	t.Run("WriteCanceled", func(t *testing.T) {
+		cancelCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		_, err := l.StartWrite(cancelCtx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	written := make(chan struct{})
+
+	go func() {
+		f, writeErr := l.StartWrite(ctx)
+		assert.NoError(t, writeErr)
+		f()
+		close(written)
+	}()
+
+	count, ok = l.Unlock()
+	require.True(t, ok)
+	assert.Equal(t, int64(1), count)
+
+	// still locked
+	select {
+	case <-written:
+		t.Fatal("write was not blocked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	count, ok = l.Unlock()
+	require.True(t, ok)
+	assert.Equal(t, int64(0), count)
+
+	<-written
+	assert.Equal(t, int64(0), l.Count())
+}
+
+func TestLockCanceled(t *testing.T) {
+	t.Parallel()
+
+	l := NewLock()
+
+	finish, err := l.StartWrite(context.Background())
+	require.NoError(t, err)
+
+	defer finish()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = l.Lock(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the lock count is restored, so other writes are not blocked
+	assert.Equal(t, int64(0), l.Count())
+
+	finish2, err := l.StartWrite(context.Background())
+	require.NoError(t, err)
+	finish2()
+}
@@ -24,6 +24,7 @@ import (
 	"net"
 	"os"
 	"runtime/pprof"
+	"strings"
 	"sync"
 	"time"
 
@@ -31,9 +32,18 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
+	"github.com/FerretDB/FerretDB/internal/clientconn/fsynclock"
+	"github.com/FerretDB/FerretDB/internal/clientconn/maintenance"
+	"github.com/FerretDB/FerretDB/internal/clientconn/operation"
+	"github.com/FerretDB/FerretDB/internal/clientconn/session"
+	"github.com/FerretDB/FerretDB/internal/clientconn/shape"
+	"github.com/FerretDB/FerretDB/internal/clientconn/shutdown"
 	"github.com/FerretDB/FerretDB/internal/handlers"
 	"github.com/FerretDB/FerretDB/internal/util/ctxutil"
+	"github.com/FerretDB/FerretDB/internal/util/failpoints"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/logging"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
@@ -49,6 +59,16 @@ type Listener struct {
 	tcpListenerReady  chan struct{}
 	unixListenerReady chan struct{}
 	tlsListenerReady  chan struct{}
+
+	sessions    *session.Registry
+	failPoints  *failpoints.Registry
+	operations  *operation.Registry
+	fsyncLock   *fsynclock.Lock
+	maintenance *maintenance.Mode
+	shutdown    *shutdown.Trigger
+	shapes      *shape.Report
+
+	acceptLimiter *acceptLimiter // nil if AcceptRateLimit is not set
 }
 
 // NewListenerOpts represents listener configuration.
@@ -66,24 +86,73 @@ type NewListenerOpts struct {
 	Handler        handlers.Interface
 	Logger         *zap.Logger
 	TestRecordsDir string // if empty, no records are created
+
+	// HandshakeTimeout limits the time between accepting the connection
+	// and receiving the first message, including TLS handshake.
+	// If zero, there is no limit.
+	HandshakeTimeout time.Duration
+
+	// AcceptRateLimit is the maximum number of accepted connections per second from a single IP address.
+	// Connections over the limit are closed immediately.
+	// If zero, there is no limit.
+	AcceptRateLimit float64
+
+	// ShutdownTimeout is the time to wait for requests in progress on graceful shutdown,
+	// unless the shutdown command specifies a different one.
+	ShutdownTimeout time.Duration
+
+	// Maintenance enables maintenance mode on start:
+	// write commands are rejected until it is disabled by setMaintenanceMode command.
+	Maintenance bool
+
+	// MaintenanceMessage is the error message for write commands rejected in maintenance mode.
+	// If empty, the default message is used.
+	MaintenanceMessage string
+
+	// EnableTestCommands makes test commands like sleep available in non-debug builds.
+	EnableTestCommands bool
+
+	// TestShapeReportFile is a path of the file for the report of response shape differences in diff modes.
+	// If empty, the report is only logged.
+	TestShapeReportFile string
 }
 
 // NewListener returns a new listener, configured by the NewListenerOpts argument.
 func NewListener(opts *NewListenerOpts) *Listener {
+	var al *acceptLimiter
+	if opts.AcceptRateLimit > 0 {
+		al = newAcceptLimiter(opts.AcceptRateLimit)
+	}
+
 	return &Listener{
 		NewListenerOpts:   opts,
 		tcpListenerReady:  make(chan struct{}),
 		unixListenerReady: make(chan struct{}),
 		tlsListenerReady:  make(chan struct{}),
+		sessions:          session.NewRegistry(session.DefaultTimeout, opts.Logger.Named("sessions")),
+		failPoints:        failpoints.NewRegistry(),
+		operations:        operation.NewRegistry(),
+		fsyncLock:         fsynclock.NewLock(),
+		maintenance:       maintenance.NewMode(opts.Maintenance, opts.MaintenanceMessage),
+		shutdown:          shutdown.NewTrigger(opts.ShutdownTimeout),
+		shapes:            shape.NewReport(),
+		acceptLimiter:     al,
 	}
 }
 
-// Run runs the listener until ctx is canceled or some unrecoverable error occurs.
+// Run runs the listener until ctx is canceled, the shutdown command is called, or some unrecoverable error occurs.
+//
+// In the first two cases, the listener shuts down gracefully:
+// it stops accepting new connections, closes idle connections,
+// and waits for requests in progress up to the shutdown timeout.
 //
 // When this method returns, listener and all connections, as well as handler are closed.
 func (l *Listener) Run(ctx context.Context) error {
 	defer l.Handler.Close()
 
+	// abort active transactions before closing the handler
+	defer l.sessions.Close()
+
 	logger := l.Logger.Named("listener")
 
 	if l.TCP != "" {
@@ -124,13 +193,36 @@ func (l *Listener) Run(ctx context.Context) error {
 		logger.Sugar().Infof("Listening on TLS %s ...", l.TLSAddr())
 	}
 
+	// acceptCtx is canceled when the listener stops accepting new connections
+	acceptCtx, acceptCancel := context.WithCancel(context.Background())
+	defer acceptCancel()
+
+	// connCtx is canceled when the shutdown timeout expires; it interrupts requests in progress
+	connCtx, connCancel := context.WithCancel(context.Background())
+	defer connCancel()
+
+	// SIGTERM and other reasons for ctx cancellation use the same path as the shutdown command
+	go func() {
+		select {
+		case <-ctx.Done():
+			l.shutdown.Shutdown(l.ShutdownTimeout)
+		case <-l.shutdown.Done():
+		}
+	}()
+
 	var wg sync.WaitGroup
 
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 
-		<-ctx.Done()
+		timeout := l.shutdown.Timeout()
+
+		logger.Sugar().Infof("Shutting down, waiting for requests in progress for up to %s...", timeout)
+
+		acceptCancel()
+
+		time.AfterFunc(timeout, connCancel)
 
 		if l.tcpListener != nil {
 			l.tcpListener.Close()
@@ -154,7 +246,7 @@ func (l *Listener) Run(ctx context.Context) error {
 				wg.Done()
 			}()
 
-			acceptLoop(ctx, l.tcpListener, &wg, l, logger)
+			acceptLoop(acceptCtx, connCtx, l.tcpListener, &wg, l, logger)
 		}()
 	}
 
@@ -167,7 +259,7 @@ func (l *Listener) Run(ctx context.Context) error {
 				wg.Done()
 			}()
 
-			acceptLoop(ctx, l.unixListener, &wg, l, logger)
+			acceptLoop(acceptCtx, connCtx, l.unixListener, &wg, l, logger)
 		}()
 	}
 
@@ -180,13 +272,16 @@ func (l *Listener) Run(ctx context.Context) error {
 				wg.Done()
 			}()
 
-			acceptLoop(ctx, l.tlsListener, &wg, l, logger)
+			acceptLoop(acceptCtx, connCtx, l.tlsListener, &wg, l, logger)
 		}()
 	}
 
 	logger.Info("Waiting for all connections to stop...")
 	wg.Wait()
 
+	l.writeShapeReport(logger)
+
+	// nil if shutdown was requested by the command
 	return context.Cause(ctx)
 }
 
@@ -245,8 +340,12 @@ func setupTLSListener(opts *setupTLSListenerOpts) (net.Listener, error) {
 	return listener, nil
 }
 
-// acceptLoop runs listener's connection accepting loop until context is canceled.
-func acceptLoop(ctx context.Context, listener net.Listener, wg *sync.WaitGroup, l *Listener, logger *zap.Logger) {
+// acceptLoop runs listener's connection accepting loop until ctx is canceled.
+//
+// Connections run until they are drained on shutdown or connCtx is canceled.
+//
+//nolint:lll // argument list is too long
+func acceptLoop(ctx, connCtx context.Context, listener net.Listener, wg *sync.WaitGroup, l *Listener, logger *zap.Logger) {
 	var retry int64
 	for {
 		netConn, err := listener.Accept()
@@ -266,9 +365,19 @@ func acceptLoop(ctx context.Context, listener net.Listener, wg *sync.WaitGroup,
 			continue
 		}
 
-		wg.Add(1)
 		l.Metrics.Accepts.WithLabelValues("0").Inc()
 
+		if !l.allowConn(netConn) {
+			l.Metrics.Dropped.WithLabelValues("rate_limit").Inc()
+
+			logger.Debug("Connection dropped by accept rate limit", zap.Stringer("remote", netConn.RemoteAddr()))
+			netConn.Close()
+
+			continue
+		}
+
+		wg.Add(1)
+
 		go func() {
 			var connErr error
 			start := time.Now()
@@ -292,8 +401,7 @@ func acceptLoop(ctx context.Context, listener net.Listener, wg *sync.WaitGroup,
 
 			connID := fmt.Sprintf("%s -> %s", remoteAddr, netConn.LocalAddr())
 
-			// give clients a few seconds to disconnect after ctx is canceled
-			runCtx, runCancel := ctxutil.WithDelay(ctx.Done(), 3*time.Second)
+			runCtx, runCancel := context.WithCancel(connCtx)
 			defer runCancel()
 
 			defer pprof.SetGoroutineLabels(runCtx)
@@ -301,13 +409,23 @@ func acceptLoop(ctx context.Context, listener net.Listener, wg *sync.WaitGroup,
 			pprof.SetGoroutineLabels(runCtx)
 
 			opts := &newConnOpts{
-				netConn:        netConn,
-				mode:           l.Mode,
-				l:              l.Logger.Named("// " + connID + " "), // derive from the original unnamed logger
-				handler:        l.Handler,
-				connMetrics:    l.Metrics.ConnMetrics, // share between all conns
-				proxyAddr:      l.ProxyAddr,
-				testRecordsDir: l.TestRecordsDir,
+				netConn:          netConn,
+				mode:             l.Mode,
+				l:                l.Logger.Named("// " + connID + " "), // derive from the original unnamed logger
+				handler:          l.Handler,
+				connMetrics:      l.Metrics.ConnMetrics, // share between all conns
+				sessions:         l.sessions,            // share between all conns
+				failPoints:       l.failPoints,          // share between all conns
+				operations:       l.operations,          // share between all conns
+				fsyncLock:        l.fsyncLock,           // share between all conns
+				maintenance:      l.maintenance,         // share between all conns
+				shutdown:         l.shutdown,            // share between all conns
+				shapes:           l.shapes,              // share between all conns
+				proxyAddr:        l.ProxyAddr,
+				acceptedAt:       start,
+				handshakeTimeout: l.HandshakeTimeout,
+				testRecordsDir:   l.TestRecordsDir,
+				testCommands:     l.EnableTestCommands,
 			}
 
 			conn, connErr := newConn(opts)
@@ -316,19 +434,53 @@ func acceptLoop(ctx context.Context, listener net.Listener, wg *sync.WaitGroup,
 				return
 			}
 
-			logger.Info("Connection started", zap.String("conn", connID))
+			l.Metrics.ConnMetrics.Connections.Opened()
+			defer l.Metrics.ConnMetrics.Connections.Closed()
+
+			connLevel := zap.InfoLevel
+			if logging.Quiet.Load() {
+				connLevel = zap.DebugLevel
+			}
+
+			logger.Log(connLevel, "Connection started", zap.String("conn", connID))
 
 			connErr = conn.run(runCtx)
-			if errors.Is(connErr, wire.ErrZeroRead) {
+
+			switch {
+			case errors.Is(connErr, wire.ErrZeroRead), errors.Is(connErr, errDrained):
 				connErr = nil
-				logger.Info("Connection stopped", zap.String("conn", connID))
-			} else {
+				logger.Log(connLevel, "Connection stopped", zap.String("conn", connID))
+			case errors.Is(connErr, errHandshakeTimeout):
+				l.Metrics.Dropped.WithLabelValues("handshake_timeout").Inc()
+				logger.Warn("Connection stopped", zap.String("conn", connID), zap.Error(connErr))
+			default:
 				logger.Warn("Connection stopped", zap.String("conn", connID), zap.Error(connErr))
 			}
 		}()
 	}
 }
 
+// allowConn returns false if the connection from the given client exceeds the accept rate limit.
+//
+// Unix domain socket connections are always allowed.
+func (l *Listener) allowConn(netConn net.Conn) bool {
+	if l.acceptLimiter == nil {
+		return true
+	}
+
+	addr := netConn.RemoteAddr()
+	if addr.Network() == "unix" {
+		return true
+	}
+
+	ip, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		ip = addr.String()
+	}
+
+	return l.acceptLimiter.allow(ip, time.Now())
+}
+
 // TCPAddr returns TCP listener's address.
 // It can be used to determine an actually used port, if it was zero.
 func (l *Listener) TCPAddr() net.Addr {
@@ -349,6 +501,27 @@ func (l *Listener) TLSAddr() net.Addr {
 	return l.tlsListener.Addr()
 }
 
+// writeShapeReport logs the report of response shape differences collected in diff modes,
+// and writes it to TestShapeReportFile, if set.
+func (l *Listener) writeShapeReport(logger *zap.Logger) {
+	if l.shapes.Empty() {
+		return
+	}
+
+	var buf strings.Builder
+	must.NoError(l.shapes.Write(&buf))
+
+	logger.Sugar().Infof("Response shape report:\n%s", buf.String())
+
+	if l.TestShapeReportFile == "" {
+		return
+	}
+
+	if err := os.WriteFile(l.TestShapeReportFile, []byte(buf.String()), 0o666); err != nil {
+		logger.Warn("Failed to write response shape report", zap.Error(err))
+	}
+}
+
 // Describe implements prometheus.Collector.
 func (l *Listener) Describe(ch chan<- *prometheus.Desc) {
 	l.Metrics.Describe(ch)
@@ -0,0 +1,99 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package maintenance provides the maintenance mode shared by all connections.
+package maintenance
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultMessage is the error message for rejected write commands used when no message is configured.
+const DefaultMessage = "FerretDB is in maintenance mode; write commands are temporarily disabled"
+
+// contextKey is a named unexported type for the safe use of context.WithValue.
+type contextKey struct{}
+
+// Context key for WithMode/GetMode.
+var modeKey = contextKey{}
+
+// Mode represents the maintenance mode state.
+//
+// In maintenance mode, read commands are served as usual,
+// but write commands are rejected with a retryable error.
+type Mode struct {
+	m       sync.RWMutex
+	enabled bool
+	message string
+}
+
+// NewMode creates a new Mode with the given initial state.
+//
+// If message is empty, DefaultMessage is used.
+func NewMode(enabled bool, message string) *Mode {
+	if message == "" {
+		message = DefaultMessage
+	}
+
+	return &Mode{
+		enabled: enabled,
+		message: message,
+	}
+}
+
+// WithMode returns a new context with the given Mode.
+func WithMode(ctx context.Context, m *Mode) context.Context {
+	return context.WithValue(ctx, modeKey, m)
+}
+
+// GetMode returns the Mode value stored in ctx.
+func GetMode(ctx context.Context) *Mode {
+	value := ctx.Value(modeKey)
+	if value == nil {
+		panic("maintenance.GetMode: maintenance mode is not set")
+	}
+
+	m, ok := value.(*Mode)
+	if !ok {
+		panic("maintenance.GetMode: maintenance mode is set but has a wrong type")
+	}
+
+	return m
+}
+
+// State returns true and the message for rejected write commands if maintenance mode is enabled.
+func (m *Mode) State() (bool, string) {
+	m.m.RLock()
+	defer m.m.RUnlock()
+
+	return m.enabled, m.message
+}
+
+// Set enables or disables maintenance mode and returns the previous state.
+//
+// If message is not empty, it replaces the message for rejected write commands.
+func (m *Mode) Set(enabled bool, message string) bool {
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	was := m.enabled
+	m.enabled = enabled
+
+	if message != "" {
+		m.message = message
+	}
+
+	return was
+}
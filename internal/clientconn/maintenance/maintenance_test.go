@@ -0,0 +1,48 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMode(t *testing.T) {
+	t.Parallel()
+
+	m := NewMode(false, "")
+
+	ctx := WithMode(context.Background(), m)
+	assert.Same(t, m, GetMode(ctx))
+
+	enabled, message := m.State()
+	assert.False(t, enabled)
+	assert.Equal(t, DefaultMessage, message)
+
+	assert.False(t, m.Set(true, "upgrading"))
+
+	enabled, message = m.State()
+	assert.True(t, enabled)
+	assert.Equal(t, "upgrading", message)
+
+	// empty message keeps the current one
+	assert.True(t, m.Set(false, ""))
+
+	enabled, message = m.State()
+	assert.False(t, enabled)
+	assert.Equal(t, "upgrading", message)
+}
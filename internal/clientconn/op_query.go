@@ -0,0 +1,98 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// isCommandQuery returns true if OP_QUERY runs a command against `$cmd` collection.
+func isCommandQuery(query *wire.OpQuery) bool {
+	return strings.HasSuffix(query.FullCollectionName, ".$cmd")
+}
+
+// checkOpQueryFlags returns protocol error for invalid or unsupported OP_QUERY flags.
+//
+// Flags are ignored for commands, like in MongoDB.
+func checkOpQueryFlags(query *wire.OpQuery) error {
+	if isCommandQuery(query) {
+		return nil
+	}
+
+	if query.Flags.FlagSet(wire.OpQueryAwaitData) && !query.Flags.FlagSet(wire.OpQueryTailableCursor) {
+		return commonerrors.NewCommandErrorMsg(
+			commonerrors.ErrFailedToParse,
+			"Cannot set 'awaitData' without also setting 'tailable'",
+		)
+	}
+
+	if query.Flags.FlagSet(wire.OpQueryExhaust) {
+		return commonerrors.NewCommandErrorMsg(
+			commonerrors.ErrNotImplemented,
+			"OP_QUERY exhaust flag is not supported",
+		)
+	}
+
+	return nil
+}
+
+// opReplyError returns OP_REPLY for the given OP_QUERY's protocol error.
+//
+// Command errors are returned as a regular command reply document, like for OP_MSG.
+// Other errors are returned with `$err` document and QueryFailure flag,
+// or with CursorNotFound flag and no documents for not found cursors.
+func opReplyError(query *wire.OpQuery, protoErr commonerrors.ProtoErr) *wire.OpReply {
+	doc := protoErr.Document()
+
+	reply := &wire.OpReply{
+		ResponseFlags: wire.OpReplyFlags(wire.OpReplyAwaitCapable),
+	}
+
+	if isCommandQuery(query) {
+		reply.NumberReturned = 1
+		reply.Documents = []*types.Document{doc}
+
+		return reply
+	}
+
+	code, _ := doc.Get("code")
+
+	errmsg, err := doc.Get("errmsg")
+	if err != nil {
+		errmsg = protoErr.Error()
+	}
+
+	if code == int32(commonerrors.ErrCursorNotFound) {
+		reply.ResponseFlags |= wire.OpReplyFlags(wire.OpReplyCursorNotFound)
+		return reply
+	}
+
+	reply.ResponseFlags |= wire.OpReplyFlags(wire.OpReplyQueryFailure)
+	reply.NumberReturned = 1
+
+	errDoc := must.NotFail(types.NewDocument("$err", errmsg))
+	if code != nil {
+		errDoc.Set("code", code)
+	}
+
+	reply.Documents = []*types.Document{errDoc}
+
+	return reply
+}
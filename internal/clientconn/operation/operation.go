@@ -0,0 +1,213 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operation provides a registry of operations in progress.
+package operation
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// ErrKilled is the cause of the operation context cancellation by killOp.
+var ErrKilled = errors.New("operation was interrupted")
+
+// contextKey is a named unexported type for the safe use of context.WithValue.
+type contextKey struct{}
+
+// connContextKey is a named unexported type for the operation's connection context.
+type connContextKey struct{}
+
+// Context keys for WithRegistry/GetRegistry and ConnContext.
+var (
+	registryKey = contextKey{}
+	connKey     = connContextKey{}
+)
+
+// Operation represents a command in progress.
+type Operation struct {
+	ID       int32
+	DB       string
+	Command  *types.Document
+	Client   string // empty for Unix socket connections
+	Username string
+	Started  time.Time
+
+	cancel context.CancelCauseFunc
+}
+
+// NS returns the namespace of the operation.
+//
+// The collection part is present only for commands with a collection name as their value.
+func (op *Operation) NS() string {
+	if op.Command.Len() == 0 {
+		return op.DB
+	}
+
+	v, _ := op.Command.Get(op.Command.Command())
+	collection, _ := v.(string)
+	if collection == "" {
+		return op.DB + ".$cmd"
+	}
+
+	return op.DB + "." + collection
+}
+
+// Registry stores operations in progress of all connections.
+//
+//nolint:vet // for readability
+type Registry struct {
+	rw     sync.RWMutex
+	m      map[int32]*Operation
+	lastID int32
+}
+
+// NewRegistry creates a new Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		m: map[int32]*Operation{},
+	}
+}
+
+// WithRegistry returns a new context with the given Registry.
+func WithRegistry(ctx context.Context, r *Registry) context.Context {
+	return context.WithValue(ctx, registryKey, r)
+}
+
+// GetRegistry returns the Registry value stored in ctx.
+func GetRegistry(ctx context.Context) *Registry {
+	value := ctx.Value(registryKey)
+	if value == nil {
+		panic("operation.GetRegistry: operation registry is not set")
+	}
+
+	r, ok := value.(*Registry)
+	if !ok {
+		panic("operation.GetRegistry: operation registry is set but has a wrong type")
+	}
+
+	return r
+}
+
+// ConnContext returns the context the operation in ctx was started with,
+// or ctx itself if there is no operation.
+//
+// Resources that outlive the operation, like cursors, should be bound to it.
+func ConnContext(ctx context.Context) context.Context {
+	if connCtx, ok := ctx.Value(connKey).(context.Context); ok {
+		return connCtx
+	}
+
+	return ctx
+}
+
+// detachedContext has values of the parent context, but is never canceled.
+type detachedContext struct {
+	parent context.Context
+}
+
+// Deadline implements context.Context.
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+// Done implements context.Context.
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+// Err implements context.Context.
+func (detachedContext) Err() error { return nil }
+
+// Value implements context.Context.
+func (c detachedContext) Value(key any) any { return c.parent.Value(key) }
+
+// Start registers a new operation for the given command document.
+//
+// It returns a context that is canceled only by Kill, and a function that unregisters the operation;
+// it must be called when the operation finishes.
+// The returned context is not canceled when the operation finishes or when ctx is canceled,
+// because iterators of cursors created by the operation are bound to it;
+// the caller is responsible for killing the operation when ctx is canceled.
+func (r *Registry) Start(ctx context.Context, db string, command *types.Document, client, username string) (context.Context, *Operation, func()) { //nolint:lll // argument list is too long
+	connCtx := ConnContext(ctx)
+	opCtx, cancel := context.WithCancelCause(context.WithValue(detachedContext{parent: ctx}, connKey, connCtx))
+
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	r.lastID++
+
+	op := &Operation{
+		ID:       r.lastID,
+		DB:       db,
+		Command:  command,
+		Client:   client,
+		Username: username,
+		Started:  time.Now(),
+		cancel:   cancel,
+	}
+	r.m[op.ID] = op
+
+	return opCtx, op, func() {
+		r.rw.Lock()
+		delete(r.m, op.ID)
+		r.rw.Unlock()
+	}
+}
+
+// All returns all operations in progress sorted by ID.
+func (r *Registry) All() []*Operation {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+
+	res := maps.Values(r.m)
+	slices.SortFunc(res, func(a, b *Operation) int {
+		switch {
+		case a.ID < b.ID:
+			return -1
+		case a.ID > b.ID:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return res
+}
+
+// Kill cancels the context of the operation with the given ID.
+//
+// It returns false if there is no such operation.
+func (r *Registry) Kill(id int32) bool {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+
+	op := r.m[id]
+	if op == nil {
+		return false
+	}
+
+	op.Kill(ErrKilled)
+
+	return true
+}
+
+// Kill cancels the context of the operation with the given cause.
+func (op *Operation) Kill(cause error) {
+	op.cancel(cause)
+}
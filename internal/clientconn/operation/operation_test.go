@@ -0,0 +1,73 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+
+	connCtx, connCancel := context.WithCancel(context.Background())
+	defer connCancel()
+
+	ctx1, op1, done1 := r.Start(connCtx, "db", must.NotFail(types.NewDocument("find", "coll")), "127.0.0.1:1234", "user")
+	ctx2, op2, done2 := r.Start(connCtx, "admin", must.NotFail(types.NewDocument("sleep", int32(1))), "", "")
+
+	assert.Equal(t, "db.coll", op1.NS())
+	assert.Equal(t, "admin.$cmd", op2.NS())
+	assert.Equal(t, []*Operation{op1, op2}, r.All())
+
+	t.Run("ConnContext", func(t *testing.T) {
+		assert.Equal(t, connCtx, ConnContext(ctx1))
+		assert.Equal(t, connCtx, ConnContext(connCtx))
+	})
+
+	t.Run("Kill", func(t *testing.T) {
+		assert.False(t, r.Kill(op2.ID+1))
+
+		require.True(t, r.Kill(op2.ID))
+		assert.ErrorIs(t, context.Cause(ctx2), ErrKilled)
+		assert.NoError(t, ctx1.Err())
+	})
+
+	t.Run("Done", func(t *testing.T) {
+		done1()
+		done2()
+
+		assert.Empty(t, r.All())
+		assert.False(t, r.Kill(op1.ID))
+
+		// the context of the finished operation is still usable
+		assert.NoError(t, ctx1.Err())
+	})
+
+	t.Run("ConnCanceled", func(t *testing.T) {
+		connCancel()
+
+		// operation contexts are not derived from the connection context
+		assert.NoError(t, ctx1.Err())
+	})
+}
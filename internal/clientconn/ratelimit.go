@@ -0,0 +1,102 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// acceptLimiter limits the rate of accepted connections from each client IP address.
+//
+// Each address has its own token bucket;
+// buckets that are full again are removed periodically, so memory usage stays bounded.
+//
+//nolint:vet // for readability
+type acceptLimiter struct {
+	rate  float64 // tokens per second
+	burst float64
+
+	m       sync.Mutex
+	buckets map[string]*acceptBucket
+	swept   time.Time
+}
+
+// acceptBucket represents a token bucket of a single IP address.
+type acceptBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newAcceptLimiter creates a new acceptLimiter for the given rate of connections per second.
+//
+// Bursts of up to rate connections (but at least one) are allowed.
+func newAcceptLimiter(rate float64) *acceptLimiter {
+	if rate <= 0 {
+		panic("rate must be positive")
+	}
+
+	return &acceptLimiter{
+		rate:    rate,
+		burst:   math.Max(1, math.Ceil(rate)),
+		buckets: map[string]*acceptBucket{},
+	}
+}
+
+// allow returns true if a new connection from the given IP address at the given time should be accepted.
+func (al *acceptLimiter) allow(ip string, now time.Time) bool {
+	al.m.Lock()
+	defer al.m.Unlock()
+
+	if now.Sub(al.swept) > time.Minute {
+		al.sweep(now)
+	}
+
+	b := al.buckets[ip]
+	if b == nil {
+		b = &acceptBucket{tokens: al.burst, last: now}
+		al.buckets[ip] = b
+	}
+
+	b.tokens = al.refill(b, now)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+
+	return true
+}
+
+// refill returns the number of tokens in the bucket at the given time.
+func (al *acceptLimiter) refill(b *acceptBucket, now time.Time) float64 {
+	return math.Min(al.burst, b.tokens+now.Sub(b.last).Seconds()*al.rate)
+}
+
+// sweep removes buckets that are full at the given time.
+//
+// It should be called with the mutex held.
+func (al *acceptLimiter) sweep(now time.Time) {
+	for ip, b := range al.buckets {
+		if al.refill(b, now) >= al.burst {
+			delete(al.buckets, ip)
+		}
+	}
+
+	al.swept = now
+}
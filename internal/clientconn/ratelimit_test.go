@@ -0,0 +1,47 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcceptLimiter(t *testing.T) {
+	t.Parallel()
+
+	al := newAcceptLimiter(2)
+	now := time.Now()
+
+	// burst
+	assert.True(t, al.allow("127.0.0.1", now))
+	assert.True(t, al.allow("127.0.0.1", now))
+	assert.False(t, al.allow("127.0.0.1", now))
+
+	// other addresses are not affected
+	assert.True(t, al.allow("127.0.0.2", now))
+
+	// refill
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, al.allow("127.0.0.1", now))
+	assert.False(t, al.allow("127.0.0.1", now))
+
+	// full buckets are removed
+	now = now.Add(2 * time.Minute)
+	assert.True(t, al.allow("127.0.0.3", now))
+	assert.Len(t, al.buckets, 1)
+}
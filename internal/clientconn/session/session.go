@@ -0,0 +1,337 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package session provides a registry of logical sessions.
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
+)
+
+// DefaultTimeout is the default logical session timeout.
+//
+// It is reported by the hello command as logicalSessionTimeoutMinutes.
+const DefaultTimeout = 30 * time.Minute
+
+// contextKey is a named unexported type for the safe use of context.WithValue.
+type contextKey struct{}
+
+// Context key for WithRegistry/GetRegistry.
+var registryKey = contextKey{}
+
+// Session represents a logical session.
+type Session struct {
+	ID       uuid.UUID
+	Username string
+	Created  time.Time
+	LastUse  time.Time
+
+	// TxnNumber is the number of the last started transaction.
+	TxnNumber int64
+
+	txn *Txn // active transaction, if any
+}
+
+// Stats represents logical session cache statistics.
+type Stats struct {
+	ActiveSessions int
+
+	// expiration jobs
+	JobCount            int64
+	LastJobDuration     time.Duration
+	LastJobTimestamp    time.Time
+	LastJobEntriesEnded int
+}
+
+// Registry stores logical sessions.
+//
+// Sessions are created explicitly by startSession command
+// or implicitly on the first use of lsid, and expire after timeout of inactivity.
+//
+//nolint:vet // for readability
+type Registry struct {
+	rw sync.RWMutex
+	m  map[uuid.UUID]*Session
+
+	timeout time.Duration
+	l       *zap.Logger
+	wg      sync.WaitGroup
+
+	stats Stats
+}
+
+// NewRegistry creates a new Registry with the given sessions timeout.
+func NewRegistry(timeout time.Duration, l *zap.Logger) *Registry {
+	return &Registry{
+		m:       map[uuid.UUID]*Session{},
+		timeout: timeout,
+		l:       l,
+	}
+}
+
+// Close removes all sessions and waits for their transactions to be aborted.
+func (r *Registry) Close() {
+	r.Kill(nil)
+
+	r.wg.Wait()
+}
+
+// WithRegistry returns a new context with the given Registry.
+func WithRegistry(ctx context.Context, r *Registry) context.Context {
+	return context.WithValue(ctx, registryKey, r)
+}
+
+// GetRegistry returns the Registry value stored in ctx.
+func GetRegistry(ctx context.Context) *Registry {
+	value := ctx.Value(registryKey)
+	if value == nil {
+		panic("session.GetRegistry: session registry is not set")
+	}
+
+	r, ok := value.(*Registry)
+	if !ok {
+		panic("session.GetRegistry: session registry is set but has a wrong type")
+	}
+
+	return r
+}
+
+// Timeout returns sessions timeout.
+func (r *Registry) Timeout() time.Duration {
+	return r.timeout
+}
+
+// Start creates and stores a new session for the given user.
+func (r *Registry) Start(username string) *Session {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	now := time.Now()
+	r.expire(now)
+
+	s := &Session{
+		ID:       uuid.New(),
+		Username: username,
+		Created:  now,
+		LastUse:  now,
+	}
+	r.m[s.ID] = s
+
+	r.l.Debug("Starting", zap.Stringer("id", s.ID), zap.String("username", username))
+
+	return s
+}
+
+// Use marks the session with the given ID as used by the given user.
+//
+// If the session doesn't exist, it is created, like MongoDB does for lsid of unknown sessions.
+// Sessions owned by other users are not changed.
+func (r *Registry) Use(id uuid.UUID, username string) {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	now := time.Now()
+	r.expire(now)
+
+	r.use(id, username, now)
+}
+
+// Refresh marks sessions with the given IDs as used by the given user.
+//
+// Like Use, it creates sessions that don't exist.
+func (r *Registry) Refresh(ids []uuid.UUID, username string) {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	now := time.Now()
+	r.expire(now)
+
+	for _, id := range ids {
+		r.use(id, username, now)
+	}
+}
+
+// use marks the session as used, creating it if needed.
+//
+// It should be called with the lock held.
+func (r *Registry) use(id uuid.UUID, username string, now time.Time) {
+	s := r.m[id]
+	if s == nil {
+		s = &Session{
+			ID:       id,
+			Username: username,
+			Created:  now,
+		}
+		r.m[id] = s
+	}
+
+	if s.Username != username {
+		return
+	}
+
+	s.LastUse = now
+}
+
+// End removes sessions with the given IDs owned by the given user.
+//
+// Unknown sessions and sessions owned by other users are ignored.
+// It returns the number of ended sessions.
+func (r *Registry) End(ids []uuid.UUID, username string) int {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	var n int
+
+	for _, id := range ids {
+		if s := r.m[id]; s != nil && s.Username == username {
+			r.remove(s)
+			n++
+		}
+	}
+
+	r.l.Debug("Ending", zap.Int("requested", len(ids)), zap.Int("ended", n))
+
+	return n
+}
+
+// Kill removes sessions with the given IDs regardless of their owners.
+//
+// If no IDs are given, all sessions are removed.
+// It returns the number of killed sessions.
+func (r *Registry) Kill(ids []uuid.UUID) int {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	var n int
+
+	if len(ids) == 0 {
+		ids = maps.Keys(r.m)
+	}
+
+	for _, id := range ids {
+		if s := r.m[id]; s != nil {
+			r.remove(s)
+			n++
+		}
+	}
+
+	r.l.Debug("Killing", zap.Int("requested", len(ids)), zap.Int("killed", n))
+
+	return n
+}
+
+// KillUsers removes all sessions owned by the given users.
+//
+// It returns the number of killed sessions.
+func (r *Registry) KillUsers(usernames []string) int {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	owners := make(map[string]struct{}, len(usernames))
+	for _, u := range usernames {
+		owners[u] = struct{}{}
+	}
+
+	var n int
+
+	for _, s := range r.m {
+		if _, ok := owners[s.Username]; ok {
+			r.remove(s)
+			n++
+		}
+	}
+
+	r.l.Debug("Killing users' sessions", zap.Strings("usernames", usernames), zap.Int("killed", n))
+
+	return n
+}
+
+// remove removes the session and aborts its active transaction.
+//
+// It should be called with the lock held.
+func (r *Registry) remove(s *Session) {
+	delete(r.m, s.ID)
+
+	if s.txn != nil {
+		r.rollback(s.txn)
+		s.txn = nil
+	}
+}
+
+// Get returns a copy of the stored session by ID, or nil.
+func (r *Registry) Get(id uuid.UUID) *Session {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+
+	s := r.m[id]
+	if s == nil {
+		return nil
+	}
+
+	res := *s
+
+	return &res
+}
+
+// Stats returns sessions statistics.
+func (r *Registry) Stats() Stats {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	r.expire(time.Now())
+
+	res := r.stats
+	res.ActiveSessions = len(r.m)
+
+	return res
+}
+
+// expire removes sessions that were not used for longer than timeout.
+// Expiration job runs at most once per minute (or timeout, if it is shorter).
+//
+// It should be called with the lock held.
+func (r *Registry) expire(now time.Time) {
+	interval := time.Minute
+	if r.timeout < interval {
+		interval = r.timeout
+	}
+
+	if now.Sub(r.stats.LastJobTimestamp) < interval {
+		return
+	}
+
+	var n int
+
+	for _, s := range r.m {
+		if now.Sub(s.LastUse) > r.timeout {
+			r.remove(s)
+			n++
+		}
+	}
+
+	r.stats.JobCount++
+	r.stats.LastJobDuration = time.Since(now)
+	r.stats.LastJobTimestamp = now
+	r.stats.LastJobEntriesEnded = n
+
+	if n > 0 {
+		r.l.Debug("Expired", zap.Int("expired", n), zap.Int("active", len(r.m)))
+	}
+}
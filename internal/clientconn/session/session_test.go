@@ -0,0 +1,90 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry(DefaultTimeout, testutil.Logger(t))
+
+	s1 := r.Start("user1")
+	s2 := r.Start("user2")
+	assert.NotEqual(t, s1.ID, s2.ID)
+	assert.Equal(t, 2, r.Stats().ActiveSessions)
+
+	implicit := uuid.New()
+	r.Use(implicit, "user1")
+	require.NotNil(t, r.Get(implicit))
+	assert.Equal(t, "user1", r.Get(implicit).Username)
+	assert.Equal(t, 3, r.Stats().ActiveSessions)
+
+	t.Run("End", func(t *testing.T) {
+		// sessions of other users and unknown sessions are ignored
+		assert.Equal(t, 1, r.End([]uuid.UUID{s1.ID, s2.ID, uuid.New()}, "user1"))
+		assert.Nil(t, r.Get(s1.ID))
+		assert.NotNil(t, r.Get(s2.ID))
+	})
+
+	t.Run("KillUsers", func(t *testing.T) {
+		assert.Equal(t, 1, r.KillUsers([]string{"user1"}))
+		assert.Nil(t, r.Get(implicit))
+		assert.Equal(t, 1, r.Stats().ActiveSessions)
+	})
+
+	t.Run("Kill", func(t *testing.T) {
+		r.Start("user3")
+		assert.Equal(t, 1, r.Kill([]uuid.UUID{s2.ID}))
+		assert.Equal(t, 1, r.Kill(nil))
+		assert.Equal(t, 0, r.Stats().ActiveSessions)
+	})
+}
+
+func TestRegistryExpire(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry(10*time.Millisecond, testutil.Logger(t))
+
+	s := r.Start("")
+	assert.Equal(t, 1, r.Stats().ActiveSessions)
+
+	time.Sleep(50 * time.Millisecond)
+
+	stats := r.Stats()
+	assert.Equal(t, 0, stats.ActiveSessions)
+	assert.Equal(t, 1, stats.LastJobEntriesEnded)
+	assert.Nil(t, r.Get(s.ID))
+}
+
+func TestRegistryContext(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry(DefaultTimeout, testutil.Logger(t))
+	ctx := WithRegistry(context.Background(), r)
+	assert.Same(t, r, GetRegistry(ctx))
+
+	assert.Panics(t, func() { GetRegistry(context.Background()) })
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Transaction represents a handler's multi-document transaction.
+type Transaction interface {
+	// Context returns a new context for running commands inside the transaction.
+	Context(ctx context.Context) context.Context
+
+	// Commit commits the transaction.
+	Commit(ctx context.Context) error
+
+	// Rollback aborts the transaction.
+	Rollback(ctx context.Context) error
+}
+
+// Txn represents a transaction associated with the logical session.
+//
+// Commands of the same transaction should not run concurrently;
+// Lock and Unlock methods should be used for that.
+type Txn struct {
+	Transaction
+
+	Number int64
+
+	mu sync.Mutex
+}
+
+// Lock locks the transaction.
+func (t *Txn) Lock() {
+	t.mu.Lock()
+}
+
+// Unlock unlocks the transaction.
+func (t *Txn) Unlock() {
+	t.mu.Unlock()
+}
+
+// SetTransaction associates a new transaction with the given number with the session of the given user,
+// creating the session if needed.
+//
+// If the session already has a transaction, it is aborted.
+// It returns false if the transaction number is not greater than the number of the last transaction of that session.
+func (r *Registry) SetTransaction(id uuid.UUID, username string, number int64, txn Transaction) (*Txn, bool) {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	s := r.m[id]
+	if s == nil {
+		r.use(id, username, time.Now())
+		s = r.m[id]
+	}
+
+	if number <= s.TxnNumber {
+		return nil, false
+	}
+
+	if s.txn != nil {
+		r.rollback(s.txn)
+	}
+
+	s.TxnNumber = number
+	s.txn = &Txn{
+		Transaction: txn,
+		Number:      number,
+	}
+
+	return s.txn, true
+}
+
+// GetTransaction returns the active transaction of the session with the given number, or nil.
+func (r *Registry) GetTransaction(id uuid.UUID, number int64) *Txn {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+
+	s := r.m[id]
+	if s == nil || s.txn == nil || s.txn.Number != number {
+		return nil
+	}
+
+	return s.txn
+}
+
+// RemoveTransaction removes the active transaction of the session with the given number
+// and returns it, or nil.
+//
+// The caller is responsible for committing or aborting the returned transaction.
+func (r *Registry) RemoveTransaction(id uuid.UUID, number int64) *Txn {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	s := r.m[id]
+	if s == nil || s.txn == nil || s.txn.Number != number {
+		return nil
+	}
+
+	txn := s.txn
+	s.txn = nil
+
+	return txn
+}
+
+// rollback aborts the transaction of the removed or ended session in the background.
+//
+// It should be called with the lock held.
+func (r *Registry) rollback(txn *Txn) {
+	r.wg.Add(1)
+
+	go func() {
+		defer r.wg.Done()
+
+		txn.Lock()
+		defer txn.Unlock()
+
+		if err := txn.Rollback(context.Background()); err != nil {
+			r.l.Warn("Failed to abort transaction", zap.Int64("txnNumber", txn.Number), zap.Error(err))
+		}
+	}()
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// testTransaction is a Transaction that counts rollbacks.
+type testTransaction struct {
+	rollbacks atomic.Int32
+}
+
+func (t *testTransaction) Context(ctx context.Context) context.Context { return ctx }
+func (t *testTransaction) Commit(ctx context.Context) error            { return nil }
+func (t *testTransaction) Rollback(ctx context.Context) error          { t.rollbacks.Add(1); return nil }
+
+func TestRegistryTransactions(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry(DefaultTimeout, testutil.Logger(t))
+	id := uuid.New()
+
+	t1 := new(testTransaction)
+	txn, ok := r.SetTransaction(id, "user", 1, t1)
+	require.True(t, ok)
+	assert.Equal(t, int64(1), txn.Number)
+	assert.Same(t, txn, r.GetTransaction(id, 1))
+	assert.Nil(t, r.GetTransaction(id, 2))
+	assert.Equal(t, "user", r.Get(id).Username)
+
+	t.Run("TooOld", func(t *testing.T) {
+		_, ok := r.SetTransaction(id, "user", 1, new(testTransaction))
+		assert.False(t, ok)
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		t2 := new(testTransaction)
+		_, ok := r.SetTransaction(id, "user", 2, t2)
+		require.True(t, ok)
+		assert.Nil(t, r.GetTransaction(id, 1))
+
+		assert.Nil(t, r.RemoveTransaction(id, 1))
+		assert.NotNil(t, r.RemoveTransaction(id, 2))
+		assert.Nil(t, r.GetTransaction(id, 2))
+		assert.Equal(t, int32(0), t2.rollbacks.Load())
+	})
+
+	t.Run("End", func(t *testing.T) {
+		t3 := new(testTransaction)
+		_, ok := r.SetTransaction(id, "user", 3, t3)
+		require.True(t, ok)
+
+		assert.Equal(t, 1, r.End([]uuid.UUID{id}, "user"))
+		r.Close()
+
+		assert.Equal(t, int32(1), t3.rollbacks.Load())
+	})
+
+	assert.Equal(t, int32(1), t1.rollbacks.Load())
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Report collects shape differences per command.
+//
+// It is safe for concurrent use.
+type Report struct {
+	rw       sync.RWMutex
+	commands map[string]*commandReport
+}
+
+// commandReport represents shape differences of a single command.
+type commandReport struct {
+	checked     int
+	mismatched  int
+	differences map[string]int // formatted difference -> count
+}
+
+// NewReport creates a new empty report.
+func NewReport() *Report {
+	return &Report{
+		commands: map[string]*commandReport{},
+	}
+}
+
+// Add records the result of a single shape check of the given command.
+func (r *Report) Add(command string, diffs []Difference) {
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	c := r.commands[command]
+	if c == nil {
+		c = &commandReport{
+			differences: map[string]int{},
+		}
+		r.commands[command] = c
+	}
+
+	c.checked++
+
+	if len(diffs) == 0 {
+		return
+	}
+
+	c.mismatched++
+
+	for _, d := range diffs {
+		c.differences[d.String()]++
+	}
+}
+
+// Empty returns true if no checks were recorded.
+func (r *Report) Empty() bool {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+
+	return len(r.commands) == 0
+}
+
+// Write writes a human-readable report to w.
+//
+// Commands and their differences are sorted by name.
+func (r *Report) Write(w io.Writer) error {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+
+	commands := make([]string, 0, len(r.commands))
+	for command := range r.commands {
+		commands = append(commands, command)
+	}
+
+	sort.Strings(commands)
+
+	for _, command := range commands {
+		c := r.commands[command]
+
+		if _, err := fmt.Fprintf(w, "%s: %d checked, %d mismatched\n", command, c.checked, c.mismatched); err != nil {
+			return err
+		}
+
+		diffs := make([]string, 0, len(c.differences))
+		for d := range c.differences {
+			diffs = append(diffs, d)
+		}
+
+		sort.Strings(diffs)
+
+		for _, d := range diffs {
+			if _, err := fmt.Fprintf(w, "  %s (%d)\n", d, c.differences[d]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
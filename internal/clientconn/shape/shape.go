@@ -0,0 +1,185 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shape provides a checker of response shapes for diff modes.
+//
+// Responses of FerretDB and MongoDB are compared by their shapes: field names, their order,
+// and value types (including int32, int64 and double distinction), but not the values themselves.
+package shape
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// ignoredFields contains top-level response fields that are set only by MongoDB replica sets and sharded clusters.
+var ignoredFields = map[string]struct{}{
+	"$clusterTime":        {},
+	"operationTime":       {},
+	"electionId":          {},
+	"opTime":              {},
+	"$configServerState":  {},
+	"$gleStats":           {},
+	"$topologyTime":       {},
+	"lastCommittedOpTime": {},
+}
+
+// Kind represents a kind of shape difference.
+type Kind string
+
+// Kinds of shape differences.
+const (
+	// Missing indicates that the field is present in the expected response only.
+	Missing = Kind("missing")
+
+	// Extra indicates that the field is present in the actual response only.
+	Extra = Kind("extra")
+
+	// Type indicates that values have different types.
+	Type = Kind("type")
+
+	// Order indicates that fields are in a different order.
+	Order = Kind("order")
+)
+
+// Difference represents a single shape difference.
+type Difference struct {
+	Kind     Kind
+	Path     string // dot-separated path of the field, or empty for the whole response
+	Actual   string // type or fields order of the actual response
+	Expected string // type or fields order of the expected response
+}
+
+// String returns a human-readable representation of the difference.
+func (d Difference) String() string {
+	path := d.Path
+	if path == "" {
+		path = "<root>"
+	}
+
+	switch d.Kind {
+	case Missing, Extra:
+		return fmt.Sprintf("%s %s", d.Kind, path)
+	default:
+		return fmt.Sprintf("%s %s: %s, expected %s", d.Kind, path, d.Actual, d.Expected)
+	}
+}
+
+// Compare returns shape differences of the actual response (FerretDB's)
+// from the expected response (MongoDB's).
+func Compare(actual, expected *types.Document) []Difference {
+	return compareDocuments("", actual, expected)
+}
+
+// compareDocuments returns shape differences of documents at the given path.
+func compareDocuments(path string, actual, expected *types.Document) []Difference {
+	var res []Difference
+
+	actualKeys := filterKeys(path, actual.Keys())
+	expectedKeys := filterKeys(path, expected.Keys())
+
+	var actualCommon, expectedCommon []string
+
+	for _, k := range expectedKeys {
+		if !actual.Has(k) {
+			res = append(res, Difference{Kind: Missing, Path: join(path, k)})
+			continue
+		}
+
+		expectedCommon = append(expectedCommon, k)
+	}
+
+	for _, k := range actualKeys {
+		if !expected.Has(k) {
+			res = append(res, Difference{Kind: Extra, Path: join(path, k)})
+			continue
+		}
+
+		actualCommon = append(actualCommon, k)
+	}
+
+	if a, e := strings.Join(actualCommon, ","), strings.Join(expectedCommon, ","); a != e {
+		res = append(res, Difference{Kind: Order, Path: path, Actual: a, Expected: e})
+	}
+
+	for _, k := range expectedCommon {
+		res = append(res, compareValues(join(path, k), must.NotFail(actual.Get(k)), must.NotFail(expected.Get(k)))...)
+	}
+
+	return res
+}
+
+// compareValues returns shape differences of values at the given path.
+//
+// Only common elements of arrays are compared, as array lengths depend on data.
+func compareValues(path string, actual, expected any) []Difference {
+	a, e := commonparams.AliasFromType(actual), commonparams.AliasFromType(expected)
+	if a != e {
+		return []Difference{{Kind: Type, Path: path, Actual: a, Expected: e}}
+	}
+
+	switch actual := actual.(type) {
+	case *types.Document:
+		return compareDocuments(path, actual, expected.(*types.Document))
+
+	case *types.Array:
+		expected := expected.(*types.Array)
+
+		var res []Difference
+
+		for i := 0; i < actual.Len() && i < expected.Len(); i++ {
+			res = append(res, compareValues(
+				join(path, strconv.Itoa(i)),
+				must.NotFail(actual.Get(i)),
+				must.NotFail(expected.Get(i)),
+			)...)
+		}
+
+		return res
+
+	default:
+		return nil
+	}
+}
+
+// filterKeys returns keys without ignored top-level fields.
+func filterKeys(path string, keys []string) []string {
+	if path != "" {
+		return keys
+	}
+
+	res := make([]string, 0, len(keys))
+
+	for _, k := range keys {
+		if _, ok := ignoredFields[k]; !ok {
+			res = append(res, k)
+		}
+	}
+
+	return res
+}
+
+// join returns a path with the given element appended.
+func join(path, elem string) string {
+	if path == "" {
+		return elem
+	}
+
+	return path + "." + elem
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shape
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		actual   *types.Document
+		expected *types.Document
+		res      []Difference
+	}{
+		"Equal": {
+			actual:   must.NotFail(types.NewDocument("n", int32(1), "ok", float64(1))),
+			expected: must.NotFail(types.NewDocument("n", int32(2), "ok", float64(1), "$clusterTime", int64(1))),
+		},
+		"Type": {
+			actual: must.NotFail(types.NewDocument(
+				"n", int64(1),
+				"upserted", must.NotFail(types.NewArray(must.NotFail(types.NewDocument("index", int64(0))))),
+			)),
+			expected: must.NotFail(types.NewDocument(
+				"n", int32(1),
+				"upserted", must.NotFail(types.NewArray(must.NotFail(types.NewDocument("index", int32(0))))),
+			)),
+			res: []Difference{
+				{Kind: Type, Path: "n", Actual: "long", Expected: "int"},
+				{Kind: Type, Path: "upserted.0.index", Actual: "long", Expected: "int"},
+			},
+		},
+		"OrderMissingExtra": {
+			actual:   must.NotFail(types.NewDocument("nModified", int32(0), "n", int32(1), "extra", true)),
+			expected: must.NotFail(types.NewDocument("n", int32(1), "missing", true, "nModified", int32(0))),
+			res: []Difference{
+				{Kind: Missing, Path: "missing"},
+				{Kind: Extra, Path: "extra"},
+				{Kind: Order, Actual: "nModified,n", Expected: "n,nModified"},
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.res, Compare(tc.actual, tc.expected))
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	t.Parallel()
+
+	r := NewReport()
+	assert.True(t, r.Empty())
+
+	diff := Difference{Kind: Type, Path: "n", Actual: "long", Expected: "int"}
+
+	r.Add("update", nil)
+	r.Add("update", []Difference{diff})
+	r.Add("update", []Difference{diff})
+	r.Add("find", nil)
+	assert.False(t, r.Empty())
+
+	var buf strings.Builder
+	require.NoError(t, r.Write(&buf))
+
+	expected := "find: 1 checked, 0 mismatched\n" +
+		"update: 3 checked, 2 mismatched\n" +
+		"  type n: long, expected int (2)\n"
+	assert.Equal(t, expected, buf.String())
+}
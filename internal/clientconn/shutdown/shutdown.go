@@ -0,0 +1,96 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shutdown provides a way to request graceful shutdown of the listener.
+package shutdown
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// contextKey is a named unexported type for the safe use of context.WithValue.
+type contextKey struct{}
+
+// Context key for WithTrigger/GetTrigger.
+var triggerKey = contextKey{}
+
+// Trigger requests graceful shutdown of the listener.
+//
+// When shutdown is requested, the listener stops accepting new connections,
+// closes idle connections, and waits for requests in progress up to the given timeout.
+type Trigger struct {
+	defaultTimeout time.Duration
+
+	once    sync.Once
+	done    chan struct{}
+	timeout time.Duration
+}
+
+// NewTrigger creates a new Trigger with the given default timeout.
+func NewTrigger(defaultTimeout time.Duration) *Trigger {
+	return &Trigger{
+		defaultTimeout: defaultTimeout,
+		done:           make(chan struct{}),
+	}
+}
+
+// WithTrigger returns a new context with the given Trigger.
+func WithTrigger(ctx context.Context, t *Trigger) context.Context {
+	return context.WithValue(ctx, triggerKey, t)
+}
+
+// GetTrigger returns the Trigger value stored in ctx.
+func GetTrigger(ctx context.Context) *Trigger {
+	value := ctx.Value(triggerKey)
+	if value == nil {
+		panic("shutdown.GetTrigger: trigger is not set")
+	}
+
+	t, ok := value.(*Trigger)
+	if !ok {
+		panic("shutdown.GetTrigger: trigger is set but has a wrong type")
+	}
+
+	return t
+}
+
+// Shutdown requests graceful shutdown with the given timeout for requests in progress.
+//
+// Only the first call has an effect.
+func (t *Trigger) Shutdown(timeout time.Duration) {
+	t.once.Do(func() {
+		t.timeout = timeout
+		close(t.done)
+	})
+}
+
+// DefaultTimeout returns the timeout that should be used if it is not specified explicitly.
+func (t *Trigger) DefaultTimeout() time.Duration {
+	return t.defaultTimeout
+}
+
+// Done returns a channel that is closed when shutdown is requested.
+func (t *Trigger) Done() <-chan struct{} {
+	return t.done
+}
+
+// Timeout returns the timeout passed to the first Shutdown call.
+//
+// It should be called only after the channel returned by Done is closed.
+func (t *Trigger) Timeout() time.Duration {
+	<-t.done
+	return t.timeout
+}
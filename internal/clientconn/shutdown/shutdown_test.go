@@ -0,0 +1,45 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shutdown
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrigger(t *testing.T) {
+	t.Parallel()
+
+	tr := NewTrigger(time.Second)
+	assert.Equal(t, time.Second, tr.DefaultTimeout())
+
+	ctx := WithTrigger(context.Background(), tr)
+	assert.Same(t, tr, GetTrigger(ctx))
+
+	select {
+	case <-tr.Done():
+		t.Fatal("shutdown was not requested yet")
+	default:
+	}
+
+	tr.Shutdown(5 * time.Second)
+	tr.Shutdown(0) // ignored
+
+	<-tr.Done()
+	assert.Equal(t, 5*time.Second, tr.Timeout())
+}
@@ -31,20 +31,40 @@ import (
 // Next method returns the next document after adding the new field to the document.
 //
 // Close method closes the underlying iterator.
-func AddFieldsIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser, newField *types.Document) types.DocumentsIterator { //nolint:lll // for readability
+//
+// Operators of new fields are created once, before any document is processed.
+func AddFieldsIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser, newField *types.Document) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	ops := make(map[string]operators.Operator)
+
+	for _, key := range newField.Keys() {
+		v, ok := must.NotFail(newField.Get(key)).(*types.Document)
+		if !ok || !operators.IsOperator(v) {
+			continue
+		}
+
+		op, err := operators.NewOperator(v)
+		if err = processAddFieldsError(err); err != nil {
+			return nil, err
+		}
+
+		ops[key] = op
+	}
+
 	res := &addFieldsIterator{
 		iter:     iter,
 		newField: newField,
+		ops:      ops,
 	}
 	closer.Add(res)
 
-	return res
+	return res, nil
 }
 
 // addFieldsIterator is returned by AddFieldsIterator.
 type addFieldsIterator struct {
 	iter     types.DocumentsIterator
 	newField *types.Document
+	ops      map[string]operators.Operator // by new field keys
 }
 
 // Next implements iterator.Interface. See addFieldsIterator for details.
@@ -59,20 +79,9 @@ func (iter *addFieldsIterator) Next() (struct{}, *types.Document, error) {
 	for _, key := range iter.newField.Keys() {
 		val := must.NotFail(iter.newField.Get(key))
 
-		switch v := val.(type) {
-		case *types.Document:
-			if !operators.IsOperator(v) {
-				break
-			}
-
-			op, err := operators.NewOperator(v)
-			if err = processAddFieldsError(err); err != nil {
-				return unused, nil, err
-			}
-
-			val, err = op.Process(doc)
-			if err = processAddFieldsError(err); err != nil {
-				return unused, nil, err
+		if op, ok := iter.ops[key]; ok {
+			if val, err = op.Process(doc); err != nil {
+				return unused, nil, processAddFieldsError(err)
 			}
 		}
 
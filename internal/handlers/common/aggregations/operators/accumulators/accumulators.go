@@ -103,7 +103,9 @@ func NewAccumulator(stage, key string, value any) (Accumulator, error) {
 // Accumulators maps all aggregation accumulators.
 var Accumulators = map[string]newAccumulatorFunc{
 	// sorted alphabetically
-	"$count": newCount,
-	"$sum":   newSum,
+	"$accumulator": newAccumulatorJS,
+	"$avg":         newAvg,
+	"$count":       newCount,
+	"$sum":         newSum,
 	// please keep sorted alphabetically
 }
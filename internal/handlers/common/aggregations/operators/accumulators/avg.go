@@ -0,0 +1,141 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accumulators
+
+import (
+	"errors"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// avg represents $avg aggregation operator.
+type avg struct {
+	expression *aggregations.Expression
+	operator   operators.Operator
+	value      any
+}
+
+// newAvg creates a new $avg aggregation operator.
+func newAvg(args ...any) (Accumulator, error) {
+	if len(args) != 1 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageGroupUnaryOperator,
+			"The $avg accumulator is a unary operator",
+			"$avg (accumulator)",
+		)
+	}
+
+	accumulator := new(avg)
+
+	switch arg := args[0].(type) {
+	case *types.Document:
+		if !operators.IsOperator(arg) {
+			accumulator.value = arg
+			break
+		}
+
+		op, err := operators.NewOperator(arg)
+		if err != nil {
+			var opErr operators.OperatorError
+			if !errors.As(err, &opErr) {
+				return nil, lazyerrors.Error(err)
+			}
+
+			return nil, opErr
+		}
+
+		accumulator.operator = op
+
+	case string:
+		var err error
+		if accumulator.expression, err = aggregations.NewExpression(arg, nil); err != nil {
+			// non-path strings are constant values
+			accumulator.value = arg
+		}
+
+	default:
+		accumulator.value = arg
+	}
+
+	return accumulator, nil
+}
+
+// Accumulate implements Accumulator interface.
+//
+// Non-numeric values are ignored.
+// If there are no numeric values, null is returned.
+func (a *avg) Accumulate(iter types.DocumentsIterator) (any, error) {
+	var numbers []any
+
+	for {
+		_, doc, err := iter.Next()
+
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		var v any
+
+		switch {
+		case a.operator != nil:
+			if v, err = a.operator.Process(doc); err != nil {
+				return nil, err
+			}
+
+		case a.expression != nil:
+			// non-existent fields are ignored
+			v, _ = a.expression.Evaluate(doc)
+
+		default:
+			v = a.value
+		}
+
+		switch v.(type) {
+		case float64, int32, int64:
+			numbers = append(numbers, v)
+		}
+	}
+
+	if len(numbers) == 0 {
+		return types.Null, nil
+	}
+
+	var sum float64
+
+	switch s := aggregations.SumNumbers(numbers...).(type) {
+	case float64:
+		sum = s
+	case int32:
+		sum = float64(s)
+	case int64:
+		sum = float64(s)
+	}
+
+	return sum / float64(len(numbers)), nil
+}
+
+// check interfaces
+var (
+	_ Accumulator = (*avg)(nil)
+)
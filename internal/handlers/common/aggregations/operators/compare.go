@@ -0,0 +1,114 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operators provides aggregation operators.
+package operators
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// compare represents comparison operators `$cmp`, `$eq`, `$gt`, `$gte`, `$lt`, `$lte` and `$ne`.
+type compare struct {
+	left     evalFunc
+	right    evalFunc
+	operator string
+}
+
+// newCompare returns a function that creates the given comparison operator.
+func newCompare(operator string) newOperatorFunc {
+	return func(args ...any) (Operator, error) {
+		if len(args) != 2 {
+			return nil, newOperatorError(
+				ErrArgsInvalidLen,
+				operator,
+				fmt.Sprintf("Expression %s takes exactly 2 arguments. %d were passed in.", operator, len(args)),
+			)
+		}
+
+		left, err := compileArg(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := compileArg(args[1])
+		if err != nil {
+			return nil, err
+		}
+
+		return &compare{
+			left:     left,
+			right:    right,
+			operator: operator,
+		}, nil
+	}
+}
+
+// Process implements Operator interface.
+//
+// Values of different types are compared using BSON comparison order.
+// Missing field is less than any other value, including null.
+func (c *compare) Process(doc *types.Document) (any, error) {
+	left, err := c.left(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := c.right(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	res := compareValues(left, right)
+
+	switch c.operator {
+	case "$cmp":
+		return int32(res), nil
+	case "$eq":
+		return res == types.Equal, nil
+	case "$gt":
+		return res == types.Greater, nil
+	case "$gte":
+		return res != types.Less, nil
+	case "$lt":
+		return res == types.Less, nil
+	case "$lte":
+		return res != types.Greater, nil
+	case "$ne":
+		return res != types.Equal, nil
+	default:
+		panic(fmt.Sprintf("unexpected comparison operator %q", c.operator))
+	}
+}
+
+// compareValues compares two evaluated values; nil represents a missing field.
+func compareValues(a, b any) types.CompareResult {
+	switch {
+	case a == nil && b == nil:
+		return types.Equal
+	case a == nil:
+		return types.Less
+	case b == nil:
+		return types.Greater
+	default:
+		return types.CompareForAggregation(a, b)
+	}
+}
+
+// check interfaces
+var (
+	_ Operator = (*compare)(nil)
+)
@@ -0,0 +1,204 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// evalFunc is a compiled operator argument.
+//
+// It returns the value of the argument for the given document,
+// or nil if the argument is a path expression that refers to a missing field.
+type evalFunc func(doc *types.Document) (any, error)
+
+// compileArg compiles the operator argument into a closure.
+//
+// Nested operators are created, path expressions are parsed,
+// and array elements and document fields are compiled recursively,
+// so that is done once per query instead of once per document.
+// Arguments that do not refer to the document are evaluated once.
+func compileArg(arg any) (evalFunc, error) {
+	eval, err := compileValue(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isConstant(arg) {
+		return eval, nil
+	}
+
+	v, err := eval(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return constant(v), nil
+}
+
+// compileValue compiles the operator argument without evaluating constant parts.
+// See compileArg for details.
+func compileValue(arg any) (evalFunc, error) {
+	switch arg := arg.(type) {
+	case *types.Document:
+		if IsOperator(arg) {
+			op, err := NewOperator(arg)
+			if err != nil {
+				var opErr OperatorError
+				if errors.As(err, &opErr) && opErr.Code() == ErrInvalidExpression {
+					opErr.code = ErrInvalidNestedExpression
+					return nil, opErr
+				}
+
+				return nil, err
+			}
+
+			return op.Process, nil
+		}
+
+		keys := arg.Keys()
+		fields := make([]evalFunc, len(keys))
+
+		for i, v := range arg.Values() {
+			var err error
+			if fields[i], err = compileValue(v); err != nil {
+				return nil, err
+			}
+		}
+
+		return func(doc *types.Document) (any, error) {
+			res := types.MakeDocument(len(keys))
+
+			for i, field := range fields {
+				v, err := field(doc)
+				if err != nil {
+					return nil, err
+				}
+
+				// missing fields are not set
+				if v != nil {
+					res.Set(keys[i], v)
+				}
+			}
+
+			return res, nil
+		}, nil
+
+	case *types.Array:
+		elements := make([]evalFunc, arg.Len())
+
+		for i := range elements {
+			var err error
+			if elements[i], err = compileValue(must.NotFail(arg.Get(i))); err != nil {
+				return nil, err
+			}
+		}
+
+		return func(doc *types.Document) (any, error) {
+			res := types.MakeArray(len(elements))
+
+			for _, element := range elements {
+				v, err := element(doc)
+				if err != nil {
+					return nil, err
+				}
+
+				// missing values are set to null
+				if v == nil {
+					v = types.Null
+				}
+
+				res.Append(v)
+			}
+
+			return res, nil
+		}, nil
+
+	case string:
+		expression, err := aggregations.NewExpression(arg, nil)
+
+		var exprErr *aggregations.ExpressionError
+		if errors.As(err, &exprErr) && exprErr.Code() == aggregations.ErrNotExpression {
+			return constant(arg), nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return func(doc *types.Document) (any, error) {
+			v, err := expression.Evaluate(doc)
+			if err != nil {
+				// missing field
+				return nil, nil
+			}
+
+			return v, nil
+		}, nil
+
+	default:
+		return constant(arg), nil
+	}
+}
+
+// constant returns a closure that returns the given value.
+//
+// Documents and arrays are copied, so the caller could modify the returned value.
+func constant(v any) evalFunc {
+	switch v := v.(type) {
+	case *types.Document:
+		return func(*types.Document) (any, error) { return v.DeepCopy(), nil }
+	case *types.Array:
+		return func(*types.Document) (any, error) { return v.DeepCopy(), nil }
+	default:
+		return func(*types.Document) (any, error) { return v, nil }
+	}
+}
+
+// isConstant returns true if the operator argument does not refer to the document,
+// so it has the same value for all documents.
+func isConstant(arg any) bool {
+	switch arg := arg.(type) {
+	case *types.Document:
+		for _, v := range arg.Values() {
+			if !isConstant(v) {
+				return false
+			}
+		}
+
+		return true
+
+	case *types.Array:
+		for i := 0; i < arg.Len(); i++ {
+			if !isConstant(must.NotFail(arg.Get(i))) {
+				return false
+			}
+		}
+
+		return true
+
+	case string:
+		// path expressions and variables
+		return !strings.HasPrefix(arg, "$")
+
+	default:
+		return true
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// doc is a shortcut for creating documents in tests.
+func doc(pairs ...any) *types.Document {
+	return must.NotFail(types.NewDocument(pairs...))
+}
+
+// arr is a shortcut for creating arrays in tests.
+func arr(values ...any) *types.Array {
+	return must.NotFail(types.NewArray(values...))
+}
+
+func TestCompileArg(t *testing.T) {
+	t.Parallel()
+
+	in := doc("_id", int32(1), "v", int32(42), "a", arr(doc("b", "foo"), doc("b", "bar")))
+
+	for name, tc := range map[string]struct {
+		arg      any
+		expected any // nil for missing field
+		constant bool
+	}{
+		"Scalar": {
+			arg:      int32(1),
+			expected: int32(1),
+			constant: true,
+		},
+		"String": {
+			arg:      "foo",
+			expected: "foo",
+			constant: true,
+		},
+		"Path": {
+			arg:      "$v",
+			expected: int32(42),
+		},
+		"PathMissing": {
+			arg: "$missing",
+		},
+		"PathArray": {
+			arg:      "$a.b",
+			expected: arr("foo", "bar"),
+		},
+		"Operator": {
+			arg:      doc("$gt", arr("$v", int32(1))),
+			expected: true,
+		},
+		"OperatorConstant": {
+			arg:      doc("$gt", arr(int32(2), int32(1))),
+			expected: true,
+			constant: true,
+		},
+		"Document": {
+			arg:      doc("x", "$v", "y", "$missing", "z", doc("$not", "$missing")),
+			expected: doc("x", int32(42), "z", true),
+		},
+		"DocumentConstant": {
+			arg:      doc("x", int32(1), "y", doc("$type", "foo")),
+			expected: doc("x", int32(1), "y", "string"),
+			constant: true,
+		},
+		"Array": {
+			arg:      arr("$v", "$missing", int32(1)),
+			expected: arr(int32(42), types.Null, int32(1)),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.constant, isConstant(tc.arg))
+
+			eval, err := compileArg(tc.arg)
+			require.NoError(t, err)
+
+			for i := 0; i < 2; i++ {
+				actual, err := eval(in)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCompileArgErrors(t *testing.T) {
+	t.Parallel()
+
+	// errors are returned once on compilation, not for each document
+	_, err := compileArg(doc("x", doc("$unknown", int32(1))))
+
+	var opErr OperatorError
+	require.ErrorAs(t, err, &opErr)
+	assert.Equal(t, ErrInvalidNestedExpression, opErr.Code())
+
+	_, err = NewOperator(doc("$and", arr(true, doc("$eq", "$v", "$not", "$v"))))
+	require.ErrorAs(t, err, &opErr)
+	assert.Equal(t, ErrTooManyFields, opErr.Code())
+
+	_, err = NewOperator(doc("$and", arr(true, doc("$eq", arr(int32(1))))))
+	require.ErrorAs(t, err, &opErr)
+	assert.Equal(t, ErrArgsInvalidLen, opErr.Code())
+}
+
+func TestCompileArgConstantCopy(t *testing.T) {
+	t.Parallel()
+
+	eval, err := compileArg(doc("x", arr(int32(1))))
+	require.NoError(t, err)
+
+	v1, err := eval(nil)
+	require.NoError(t, err)
+
+	// modifying the returned value does not affect other documents
+	v1.(*types.Document).Set("y", int32(2))
+
+	v2, err := eval(nil)
+	require.NoError(t, err)
+	assert.Equal(t, doc("x", arr(int32(1))), v2)
+}
+
+func BenchmarkCompiledOperator(b *testing.B) {
+	op := must.NotFail(NewOperator(doc("$and", arr(
+		doc("$gt", arr("$v", int32(1))),
+		doc("$eq", arr(doc("$type", "$s"), "string")),
+		doc("$not", arr(doc("$lt", arr(int32(2), int32(1))))),
+	))))
+
+	in := doc("_id", int32(1), "v", int32(42), "s", "foo")
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := op.Process(in); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
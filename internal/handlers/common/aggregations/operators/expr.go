@@ -29,7 +29,7 @@ import (
 
 // expr represents $expr operator.
 type expr struct {
-	exprValue   any
+	eval        evalFunc
 	errArgument string
 }
 
@@ -41,7 +41,6 @@ type expr struct {
 func NewExpr(exprValue *types.Document, errArgument string) (Operator, error) {
 	v := must.NotFail(exprValue.Get("$expr"))
 	e := &expr{
-		exprValue:   v,
 		errArgument: errArgument,
 	}
 
@@ -49,12 +48,29 @@ func NewExpr(exprValue *types.Document, errArgument string) (Operator, error) {
 		return nil, err
 	}
 
+	eval, err := compileExpr(v)
+	if err != nil {
+		// $expr was validated above
+		return nil, lazyerrors.Error(err)
+	}
+
+	if isConstant(v) {
+		var res any
+		if res, err = eval(nil); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		eval = constant(res)
+	}
+
+	e.eval = eval
+
 	return e, nil
 }
 
 // Process implements Operator interface.
 func (e *expr) Process(doc *types.Document) (any, error) {
-	return e.processExpr(e.exprValue, doc)
+	return e.eval(doc)
 }
 
 // processExpr recursively validates operators and expressions.
@@ -130,103 +146,99 @@ func (e *expr) validateExpr(exprValue any) error {
 	return nil
 }
 
-// processExpr recursively processes operators and expressions and returns processed `exprValue`.
+// compileExpr recursively compiles operators and expressions of `exprValue` into a closure.
 //
-// Each array values and document fields are processed recursively.
+// Each array values and document fields are compiled recursively.
 // String expression is evaluated if any, and Null is returned if field is missing.
-// Any value that does not require processing, it returns the original value.
-func (e *expr) processExpr(exprValue any, doc *types.Document) (any, error) {
+// Any value that does not require processing is returned as is.
+func compileExpr(exprValue any) (evalFunc, error) {
 	switch exprValue := exprValue.(type) {
 	case *types.Document:
 		if IsOperator(exprValue) {
 			op, err := NewOperator(exprValue)
 			if err != nil {
-				// $expr was validated in NewExpr
-				return nil, lazyerrors.Error(err)
-			}
-
-			v, err := op.Process(doc)
-			if err != nil {
-				// Process does not return error for existing operators
 				return nil, lazyerrors.Error(err)
 			}
 
-			return v, nil
+			return op.Process, nil
 		}
 
-		iter := exprValue.Iterator()
-		defer iter.Close()
-
-		res := new(types.Document)
+		keys := exprValue.Keys()
+		fields := make([]evalFunc, len(keys))
 
-		for {
-			k, v, err := iter.Next()
-			if errors.Is(err, iterator.ErrIteratorDone) {
-				break
+		for i, v := range exprValue.Values() {
+			var err error
+			if fields[i], err = compileExpr(v); err != nil {
+				return nil, err
 			}
+		}
 
-			if err != nil {
-				return nil, lazyerrors.Error(err)
-			}
+		return func(doc *types.Document) (any, error) {
+			res := types.MakeDocument(len(keys))
 
-			processed, err := e.processExpr(v, doc)
-			if err != nil {
-				return nil, lazyerrors.Error(err)
+			for i, field := range fields {
+				v, err := field(doc)
+				if err != nil {
+					return nil, lazyerrors.Error(err)
+				}
+
+				res.Set(keys[i], v)
 			}
 
-			res.Set(k, processed)
-		}
+			return res, nil
+		}, nil
 
-		return res, nil
 	case *types.Array:
-		iter := exprValue.Iterator()
-		defer iter.Close()
-
-		res := types.MakeArray(exprValue.Len())
+		elements := make([]evalFunc, exprValue.Len())
 
-		for {
-			_, v, err := iter.Next()
-			if errors.Is(err, iterator.ErrIteratorDone) {
-				break
+		for i := range elements {
+			var err error
+			if elements[i], err = compileExpr(must.NotFail(exprValue.Get(i))); err != nil {
+				return nil, err
 			}
+		}
 
-			if err != nil {
-				return nil, lazyerrors.Error(err)
-			}
+		return func(doc *types.Document) (any, error) {
+			res := types.MakeArray(len(elements))
 
-			processed, err := e.processExpr(v, doc)
-			if err != nil {
-				return nil, lazyerrors.Error(err)
+			for _, element := range elements {
+				v, err := element(doc)
+				if err != nil {
+					return nil, lazyerrors.Error(err)
+				}
+
+				res.Append(v)
 			}
 
-			res.Append(processed)
-		}
+			return res, nil
+		}, nil
 
-		return res, nil
 	case string:
 		expression, err := aggregations.NewExpression(exprValue, nil)
 
 		var exprErr *aggregations.ExpressionError
 		if errors.As(err, &exprErr) && exprErr.Code() == aggregations.ErrNotExpression {
 			// not an expression, return the original value
-			return exprValue, nil
+			return constant(exprValue), nil
 		}
 
 		if err != nil {
-			// expression error was validated in NewExpr
 			return nil, lazyerrors.Error(err)
 		}
 
-		v, err := expression.Evaluate(doc)
-		if err != nil {
-			// missing field is set to null
-			return types.Null, nil
-		}
+		return func(doc *types.Document) (any, error) {
+			v, err := expression.Evaluate(doc)
+			if err != nil {
+				// missing field is set to null
+				return types.Null, nil
+			}
+
+			return v, nil
+		}, nil
 
-		return v, nil
 	default:
 		// nothing to process, return the original value
-		return exprValue, nil
+		return constant(exprValue), nil
 	}
 }
 
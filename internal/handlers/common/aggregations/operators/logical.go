@@ -0,0 +1,134 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package operators provides aggregation operators.
+package operators
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// logical represents `$and` and `$or` operators.
+type logical struct {
+	args []evalFunc
+	and  bool
+}
+
+// newAnd returns `$and` operator.
+func newAnd(args ...any) (Operator, error) {
+	return newLogical(args, true)
+}
+
+// newOr returns `$or` operator.
+func newOr(args ...any) (Operator, error) {
+	return newLogical(args, false)
+}
+
+// newLogical compiles arguments of `$and` or `$or` operator.
+//
+// All arguments are compiled, so errors in nested operators are reported
+// even if the result could be known earlier.
+func newLogical(args []any, and bool) (Operator, error) {
+	l := &logical{
+		args: make([]evalFunc, len(args)),
+		and:  and,
+	}
+
+	for i, arg := range args {
+		var err error
+		if l.args[i], err = compileArg(arg); err != nil {
+			return nil, err
+		}
+	}
+
+	return l, nil
+}
+
+// Process implements Operator interface.
+func (l *logical) Process(doc *types.Document) (any, error) {
+	res := l.and
+
+	for _, arg := range l.args {
+		v, err := arg(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if l.and {
+			res = res && isTrue(v)
+		} else {
+			res = res || isTrue(v)
+		}
+	}
+
+	return res, nil
+}
+
+// not represents `$not` operator.
+type not struct {
+	arg evalFunc
+}
+
+// newNot returns `$not` operator.
+func newNot(args ...any) (Operator, error) {
+	if len(args) != 1 {
+		return nil, newOperatorError(
+			ErrArgsInvalidLen,
+			"$not",
+			fmt.Sprintf("Expression $not takes exactly 1 arguments. %d were passed in.", len(args)),
+		)
+	}
+
+	arg, err := compileArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &not{
+		arg: arg,
+	}, nil
+}
+
+// Process implements Operator interface.
+func (n *not) Process(doc *types.Document) (any, error) {
+	v, err := n.arg(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return !isTrue(v), nil
+}
+
+// isTrue returns true if the evaluated value is considered true by logical operators.
+// Missing field, null, false and zero numbers are false, all other values are true.
+func isTrue(v any) bool {
+	switch v := v.(type) {
+	case nil, types.NullType:
+		return false
+	case bool:
+		return v
+	case float64, int32, int64:
+		return types.Compare(v, int32(0)) != types.Equal
+	default:
+		return true
+	}
+}
+
+// check interfaces
+var (
+	_ Operator = (*logical)(nil)
+	_ Operator = (*not)(nil)
+)
@@ -137,11 +137,28 @@ func NewOperator(doc *types.Document) (Operator, error) {
 }
 
 // Operators maps all standard aggregation operators.
-var Operators = map[string]newOperatorFunc{
-	// sorted alphabetically
-	"$sum":  newSum,
-	"$type": newType,
-	// please keep sorted alphabetically
+var Operators map[string]newOperatorFunc
+
+func init() {
+	// operators are registered there to avoid initialization cycle,
+	// as they create nested operators with NewOperator.
+	Operators = map[string]newOperatorFunc{
+		// sorted alphabetically
+		"$and":      newAnd,
+		"$cmp":      newCompare("$cmp"),
+		"$eq":       newCompare("$eq"),
+		"$function": newFunction,
+		"$gt":       newCompare("$gt"),
+		"$gte":      newCompare("$gte"),
+		"$lt":       newCompare("$lt"),
+		"$lte":      newCompare("$lte"),
+		"$ne":       newCompare("$ne"),
+		"$not":      newNot,
+		"$or":       newOr,
+		"$sum":      newSum,
+		"$type":     newType,
+		// please keep sorted alphabetically
+	}
 }
 
 // unsupportedOperators maps all unsupported yet operators.
@@ -152,7 +169,6 @@ var unsupportedOperators = map[string]struct{}{
 	"$acosh":            {},
 	"$add":              {},
 	"$allElementsTrue":  {},
-	"$and":              {},
 	"$anyElementTrue":   {},
 	"$arrayElemAt":      {},
 	"$arrayToObject":    {},
@@ -165,7 +181,6 @@ var unsupportedOperators = map[string]struct{}{
 	"$binarySize":       {},
 	"$bsonSize":         {},
 	"$ceil":             {},
-	"$cmp":              {},
 	"$concat":           {},
 	"$concatArrays":     {},
 	"$cond":             {},
@@ -190,15 +205,11 @@ var unsupportedOperators = map[string]struct{}{
 	"$derivative":       {},
 	"$divide":           {},
 	"$documentNumber":   {},
-	"$eq":               {},
 	"$exp":              {},
 	"$expMovingAvg":     {},
 	"$filter":           {},
 	"$floor":            {},
-	"$function":         {},
 	"$getField":         {},
-	"$gt":               {},
-	"$gte":              {},
 	"$hour":             {},
 	"$ifNull":           {},
 	"$in":               {},
@@ -218,8 +229,6 @@ var unsupportedOperators = map[string]struct{}{
 	"$locf":             {},
 	"$log":              {},
 	"$log10":            {},
-	"$lt":               {},
-	"$lte":              {},
 	"$ltrim":            {},
 	"$map":              {},
 	"$max":              {},
@@ -231,10 +240,7 @@ var unsupportedOperators = map[string]struct{}{
 	"$mod":              {},
 	"$month":            {},
 	"$multiply":         {},
-	"$ne":               {},
-	"$not":              {},
 	"$objectToArray":    {},
-	"$or":               {},
 	"$pow":              {},
 	"$radiansToDegrees": {},
 	"$rand":             {},
@@ -28,8 +28,8 @@ import (
 type sum struct {
 	// expressions are valid path expression requiring evaluation
 	expressions []*aggregations.Expression
-	// operators are documents containing operator expressions i.e. `[{$sum: 1}]`
-	operators []*types.Document
+	// operators are nested operators i.e. `[{$sum: 1}]`
+	operators []Operator
 	// numbers are int32, int64 or float64 values
 	numbers []any
 	// arrayLen is set when $sum operator contains array field such as `{$sum: [1, "$v"]}`
@@ -37,7 +37,7 @@ type sum struct {
 }
 
 // newSum collects values that can be summed in `numbers`,
-// finds nested operators if any, validates path expressions
+// creates nested operators if any, validates path expressions
 // to populate `$sum` operator. It ignores values that are not summable.
 func newSum(args ...any) (Operator, error) {
 	operator := new(sum)
@@ -47,9 +47,16 @@ func newSum(args ...any) (Operator, error) {
 	for _, arg := range args {
 		switch arg := arg.(type) {
 		case *types.Document:
-			if IsOperator(arg) {
-				operator.operators = append(operator.operators, arg)
+			if !IsOperator(arg) {
+				break
+			}
+
+			op, err := NewOperator(arg)
+			if err != nil {
+				return nil, err
 			}
+
+			operator.operators = append(operator.operators, op)
 		case float64:
 			operator.numbers = append(operator.numbers, arg)
 		case string:
@@ -74,7 +81,7 @@ func newSum(args ...any) (Operator, error) {
 }
 
 // Process implements Operator interface.
-// It evaluates expressions if any to fetch a value, creates new operator and processes them if any
+// It evaluates expressions if any to fetch a value, processes nested operators if any
 // and sums all int32, int64 and float64 numbers ignoring other types.
 func (s *sum) Process(doc *types.Document) (any, error) {
 	var numbers []any
@@ -119,13 +126,7 @@ func (s *sum) Process(doc *types.Document) (any, error) {
 		}
 	}
 
-	for _, operatorExpr := range s.operators {
-		// NewOperator is created here, doing it in newSum() creates initialization cycle for operators
-		op, err := NewOperator(operatorExpr)
-		if err != nil {
-			return nil, err
-		}
-
+	for _, op := range s.operators {
 		v, err := op.Process(doc)
 		if err != nil {
 			return nil, err
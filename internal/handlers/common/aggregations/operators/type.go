@@ -16,20 +16,15 @@
 package operators
 
 import (
-	"errors"
 	"fmt"
-	"strings"
-	"time"
 
-	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
 	"github.com/FerretDB/FerretDB/internal/types"
-	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 )
 
 // typeOp represents `$type` operator.
 type typeOp struct {
-	param any
+	arg evalFunc
 }
 
 // newType returns `$type` operator.
@@ -42,84 +37,28 @@ func newType(args ...any) (Operator, error) {
 		)
 	}
 
+	arg, err := compileArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+
 	return &typeOp{
-		param: args[0],
+		arg: arg,
 	}, nil
 }
 
 // Process implements Operator interface.
 func (t *typeOp) Process(doc *types.Document) (any, error) {
-	typeParam := t.param
-
-	var paramEvaluated bool
-
-	var res any
-
-	for !paramEvaluated {
-		paramEvaluated = true
-
-		switch param := typeParam.(type) {
-		case *types.Document:
-			if !IsOperator(param) {
-				res = param
-				break
-			}
-
-			operator, err := NewOperator(param)
-			if err != nil {
-				var opErr OperatorError
-				if !errors.As(err, &opErr) {
-					return nil, lazyerrors.Error(err)
-				}
-
-				if opErr.Code() == ErrInvalidExpression {
-					opErr.code = ErrInvalidNestedExpression
-				}
-
-				return nil, opErr
-			}
-
-			if typeParam, err = operator.Process(doc); err != nil {
-				var opErr OperatorError
-				if !errors.As(err, &opErr) {
-					return nil, lazyerrors.Error(err)
-				}
-
-				return nil, err
-			}
-
-			// the result of nested operator needs to be evaluated
-			paramEvaluated = false
-
-		case *types.Array, float64, types.Binary, types.ObjectID, bool, time.Time,
-			types.NullType, types.Regex, int32, types.Timestamp, int64:
-			res = param
-
-		case string:
-			if strings.HasPrefix(param, "$") {
-				expression, err := aggregations.NewExpression(param, nil)
-				if err != nil {
-					return nil, err
-				}
-
-				value, err := expression.Evaluate(doc)
-				if err != nil {
-					return "missing", nil
-				}
-
-				res = value
-
-				continue
-			}
-
-			res = param
+	v, err := t.arg(doc)
+	if err != nil {
+		return nil, err
+	}
 
-		default:
-			panic(fmt.Sprint("wrong type of value: ", typeParam))
-		}
+	if v == nil {
+		return "missing", nil
 	}
 
-	return commonparams.AliasFromType(res), nil
+	return commonparams.AliasFromType(v), nil
 }
 
 // check interfaces
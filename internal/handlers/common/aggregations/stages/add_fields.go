@@ -64,7 +64,7 @@ func newAddFields(stage *types.Document) (aggregations.Stage, error) {
 
 // Process implements Stage interface.
 func (s *addFields) Process(_ context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
-	return common.AddFieldsIterator(iter, closer, s.newField), nil
+	return common.AddFieldsIterator(iter, closer, s.newField)
 }
 
 // check interfaces
@@ -0,0 +1,440 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators/accumulators"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// bucket represents $bucket stage.
+//
+//	{ $bucket: {
+//		groupBy: <expression>,
+//		boundaries: [ <lowerbound1>, <lowerbound2>, ... ],
+//		default: <literal>,
+//		output: {
+//			<output1>: { <$accumulator expression> },
+//			...
+//			<outputN>: { <$accumulator expression> }
+//		}
+//	}}
+//
+// $bucket groups documents into buckets by the evaluated groupBy expression.
+// Each bucket includes values from its lower boundary inclusive to the next boundary exclusive,
+// values outside of all buckets are placed to the default bucket.
+// The lower boundary becomes the _id of the bucket; for the default bucket, the default value is used.
+type bucket struct {
+	groupBy    any
+	boundaries []any
+	def        any // nil if default is not set
+	output     []groupBy
+}
+
+// newBucket creates a new $bucket stage.
+func newBucket(stage *types.Document) (aggregations.Stage, error) {
+	fields, ok := must.NotFail(stage.Get("$bucket")).(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageBucketNotObject,
+			fmt.Sprintf(
+				"Argument to $bucket stage must be an object, but found type: %s.",
+				commonparams.AliasFromType(must.NotFail(stage.Get("$bucket"))),
+			),
+			"$bucket (stage)",
+		)
+	}
+
+	var b bucket
+	var boundaries *types.Array
+
+	iter := fields.Iterator()
+	defer iter.Close()
+
+	for {
+		k, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		switch k {
+		case "groupBy":
+			if !isGroupByExpression(v) {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrStageBucketInvalidGroupBy,
+					fmt.Sprintf(
+						"The $bucket 'groupBy' field must be defined as a $-prefixed path or an expression, but found: %s.",
+						types.FormatAnyValue(v),
+					),
+					"$bucket (stage)",
+				)
+			}
+
+			if err = validateGroupKey(v); err != nil {
+				return nil, err
+			}
+
+			b.groupBy = v
+
+		case "boundaries":
+			if boundaries, ok = v.(*types.Array); !ok {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrStageBucketInvalidBoundaries,
+					fmt.Sprintf(
+						"The $bucket 'boundaries' field must be an array, but found type: %s.",
+						commonparams.AliasFromType(v),
+					),
+					"$bucket (stage)",
+				)
+			}
+
+		case "default":
+			b.def = v
+
+		case "output":
+			output, ok := v.(*types.Document)
+			if !ok {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrStageBucketInvalidOutput,
+					fmt.Sprintf(
+						"The $bucket 'output' field must be an object, but found type: %s.",
+						commonparams.AliasFromType(v),
+					),
+					"$bucket (stage)",
+				)
+			}
+
+			if b.output, err = newBucketOutput("$bucket", output); err != nil {
+				return nil, err
+			}
+
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrStageBucketUnknownField,
+				fmt.Sprintf("Unrecognized option to $bucket: %s.", k),
+				"$bucket (stage)",
+			)
+		}
+	}
+
+	if b.groupBy == nil || boundaries == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageBucketMissingField,
+			"$bucket requires 'groupBy' and 'boundaries' to be specified.",
+			"$bucket (stage)",
+		)
+	}
+
+	var err error
+	if b.boundaries, err = validateBucketBoundaries(boundaries); err != nil {
+		return nil, err
+	}
+
+	if b.def != nil {
+		lowest, highest := b.boundaries[0], b.boundaries[len(b.boundaries)-1]
+
+		if types.CompareOrder(b.def, lowest, types.Ascending) != types.Less &&
+			types.CompareOrder(b.def, highest, types.Ascending) == types.Less {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrStageBucketInvalidDefault,
+				"The $bucket 'default' field must be less than the lowest boundary or "+
+					"greater than or equal to the highest boundary.",
+				"$bucket (stage)",
+			)
+		}
+	}
+
+	if b.output == nil {
+		b.output = defaultBucketOutput("$bucket")
+	}
+
+	return &b, nil
+}
+
+// validateBucketBoundaries checks that $bucket boundaries are at least two constant values
+// of the same type in ascending order, and returns them.
+func validateBucketBoundaries(boundaries *types.Array) ([]any, error) {
+	if boundaries.Len() < 2 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageBucketFewBoundaries,
+			fmt.Sprintf(
+				"The $bucket 'boundaries' field must have at least 2 values, but found %d value(s).",
+				boundaries.Len(),
+			),
+			"$bucket (stage)",
+		)
+	}
+
+	res := make([]any, boundaries.Len())
+
+	for i := 0; i < boundaries.Len(); i++ {
+		v := must.NotFail(boundaries.Get(i))
+
+		if isGroupByExpression(v) {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrStageBucketNotConstantBoundaries,
+				fmt.Sprintf(
+					"The $bucket 'boundaries' field must be an array of constant values, but found value: %s.",
+					types.FormatAnyValue(v),
+				),
+				"$bucket (stage)",
+			)
+		}
+
+		res[i] = v
+
+		if i == 0 {
+			continue
+		}
+
+		prev := res[i-1]
+
+		if !isNumber(prev) || !isNumber(v) {
+			if prevType, vType := commonparams.AliasFromType(prev), commonparams.AliasFromType(v); prevType != vType {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrStageBucketMixedBoundaries,
+					fmt.Sprintf(
+						"All values in the the 'boundaries' option to $bucket must have the same type. "+
+							"Found conflicting types %s and %s.",
+						prevType, vType,
+					),
+					"$bucket (stage)",
+				)
+			}
+		}
+
+		if types.CompareOrder(prev, v, types.Ascending) != types.Less {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrStageBucketUnsortedBoundaries,
+				fmt.Sprintf(
+					"The 'boundaries' option to $bucket must be sorted, but elements %d and %d "+
+						"are not in ascending order (%s is not less than %s).",
+					i-1, i, types.FormatAnyValue(prev), types.FormatAnyValue(v),
+				),
+				"$bucket (stage)",
+			)
+		}
+	}
+
+	return res, nil
+}
+
+// Process implements Stage interface.
+func (b *bucket) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// the last bucket is the default one
+	buckets := make([][]*types.Document, len(b.boundaries))
+
+	for _, doc := range docs {
+		v, err := evaluateGroupBy(b.groupBy, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		i := b.bucketIndex(v)
+		if i < 0 {
+			if b.def == nil {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrStageBucketNoMatchingBranch,
+					"$switch could not find a matching branch for an input, and no default was specified.",
+					"$bucket (stage)",
+				)
+			}
+
+			i = len(buckets) - 1
+		}
+
+		buckets[i] = append(buckets[i], doc)
+	}
+
+	var res []*types.Document
+
+	for i, bucketDocs := range buckets {
+		if len(bucketDocs) == 0 {
+			continue
+		}
+
+		id := b.def
+		if i < len(buckets)-1 {
+			id = b.boundaries[i]
+		}
+
+		doc, err := accumulateBucket(id, bucketDocs, b.output)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, doc)
+	}
+
+	// buckets are sorted by _id, so the default bucket goes first if default is less than the lowest boundary
+	if n := len(res); n > 1 && len(buckets[len(buckets)-1]) > 0 &&
+		types.CompareOrder(b.def, b.boundaries[0], types.Ascending) == types.Less {
+		res = append([]*types.Document{res[n-1]}, res[:n-1]...)
+	}
+
+	iter = iterator.Values(iterator.ForSlice(res))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// bucketIndex returns the index of the bucket for the given value, or -1 if value is outside of all buckets.
+func (b *bucket) bucketIndex(v any) int {
+	for i := 0; i < len(b.boundaries)-1; i++ {
+		if types.CompareOrder(v, b.boundaries[i], types.Ascending) == types.Less {
+			return -1
+		}
+
+		if types.CompareOrder(v, b.boundaries[i+1], types.Ascending) == types.Less {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// isGroupByExpression returns true if the value is a $-prefixed path or an operator expression.
+func isGroupByExpression(v any) bool {
+	switch v := v.(type) {
+	case string:
+		return strings.HasPrefix(v, "$")
+	case *types.Document:
+		return v.Len() > 0 && strings.HasPrefix(v.Keys()[0], "$")
+	default:
+		return false
+	}
+}
+
+// isNumber returns true if the value is a BSON number.
+func isNumber(v any) bool {
+	switch v.(type) {
+	case float64, int32, int64:
+		return true
+	default:
+		return false
+	}
+}
+
+// evaluateGroupBy evaluates groupBy expression of bucket stages for the given document.
+// Non-existent paths are evaluated to null.
+func evaluateGroupBy(groupBy any, doc *types.Document) (any, error) {
+	switch groupBy := groupBy.(type) {
+	case *types.Document:
+		if !operators.IsOperator(groupBy) {
+			return evaluateDocument(groupBy, doc, false)
+		}
+
+		op, err := operators.NewOperator(groupBy)
+		if err != nil {
+			return nil, processGroupStageError(err)
+		}
+
+		v, err := op.Process(doc)
+		if err != nil {
+			return nil, processGroupStageError(err)
+		}
+
+		return v, nil
+
+	case string:
+		expression, err := aggregations.NewExpression(groupBy, nil)
+		if err != nil {
+			return nil, processGroupStageError(err)
+		}
+
+		v, err := expression.Evaluate(doc)
+		if err != nil {
+			return types.Null, nil
+		}
+
+		return v, nil
+
+	default:
+		panic(fmt.Sprintf("unexpected groupBy type %T", groupBy))
+	}
+}
+
+// newBucketOutput creates accumulators for the output field of bucket stages.
+func newBucketOutput(stage string, output *types.Document) ([]groupBy, error) {
+	res := make([]groupBy, 0, output.Len())
+
+	for _, field := range output.Keys() {
+		accumulator, err := accumulators.NewAccumulator(stage, field, must.NotFail(output.Get(field)))
+		if err != nil {
+			return nil, processGroupStageError(err)
+		}
+
+		res = append(res, groupBy{
+			outputField: field,
+			accumulator: accumulator,
+		})
+	}
+
+	return res, nil
+}
+
+// defaultBucketOutput returns the output of bucket stages used if output field is not specified:
+// the count of documents in each bucket.
+func defaultBucketOutput(stage string) []groupBy {
+	output := must.NotFail(types.NewDocument("count", must.NotFail(types.NewDocument("$sum", int32(1)))))
+	return must.NotFail(newBucketOutput(stage, output))
+}
+
+// accumulateBucket returns a document with the given _id and the output fields
+// accumulated over the bucket's documents.
+func accumulateBucket(id any, docs []*types.Document, output []groupBy) (*types.Document, error) {
+	res := must.NotFail(types.NewDocument("_id", id))
+
+	for _, accumulation := range output {
+		groupIter := iterator.Values(iterator.ForSlice(docs))
+
+		out, err := accumulation.accumulator.Accumulate(groupIter)
+		groupIter.Close()
+
+		if err != nil {
+			return nil, processGroupStageError(err)
+		}
+
+		res.Set(accumulation.outputField, out)
+	}
+
+	return res, nil
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*bucket)(nil)
+)
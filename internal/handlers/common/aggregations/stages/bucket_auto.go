@@ -0,0 +1,290 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// bucketAuto represents $bucketAuto stage.
+//
+//	{ $bucketAuto: {
+//		groupBy: <expression>,
+//		buckets: <number>,
+//		output: {
+//			<output1>: { <$accumulator expression> },
+//			...
+//		},
+//		granularity: <string>
+//	}}
+//
+// $bucketAuto sorts documents by the evaluated groupBy expression and splits them
+// into the given number of buckets of approximately the same size.
+// Documents with the same groupBy value are always placed to the same bucket,
+// so the actual number of buckets could be less than requested.
+// The _id of each bucket is a document with min (inclusive) and max boundaries;
+// max boundary is exclusive for all buckets except the last one.
+type bucketAuto struct {
+	groupBy any
+	buckets int
+	output  []groupBy
+}
+
+// newBucketAuto creates a new $bucketAuto stage.
+func newBucketAuto(stage *types.Document) (aggregations.Stage, error) {
+	fields, ok := must.NotFail(stage.Get("$bucketAuto")).(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageBucketAutoNotObject,
+			fmt.Sprintf(
+				"The argument to $bucketAuto must be an object, but found type: %s.",
+				commonparams.AliasFromType(must.NotFail(stage.Get("$bucketAuto"))),
+			),
+			"$bucketAuto (stage)",
+		)
+	}
+
+	var b bucketAuto
+
+	iter := fields.Iterator()
+	defer iter.Close()
+
+	for {
+		k, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		switch k {
+		case "groupBy":
+			if !isGroupByExpression(v) {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrStageBucketAutoInvalidGroupBy,
+					fmt.Sprintf(
+						"The $bucketAuto 'groupBy' field must be defined as a $-prefixed path "+
+							"or an expression object, but found: %s.",
+						types.FormatAnyValue(v),
+					),
+					"$bucketAuto (stage)",
+				)
+			}
+
+			if err = validateGroupKey(v); err != nil {
+				return nil, err
+			}
+
+			b.groupBy = v
+
+		case "buckets":
+			if b.buckets, err = getBucketAutoBuckets(v); err != nil {
+				return nil, err
+			}
+
+		case "output":
+			output, ok := v.(*types.Document)
+			if !ok {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrStageBucketAutoInvalidOutput,
+					fmt.Sprintf(
+						"The $bucketAuto 'output' field must be an object, but found type: %s.",
+						commonparams.AliasFromType(v),
+					),
+					"$bucketAuto (stage)",
+				)
+			}
+
+			if b.output, err = newBucketOutput("$bucketAuto", output); err != nil {
+				return nil, err
+			}
+
+		case "granularity":
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				"$bucketAuto granularity is not implemented yet",
+				"$bucketAuto (stage)",
+			)
+
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrStageBucketAutoUnknownField,
+				fmt.Sprintf("Unrecognized option to $bucketAuto: %s.", k),
+				"$bucketAuto (stage)",
+			)
+		}
+	}
+
+	if b.groupBy == nil || b.buckets == 0 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageBucketAutoMissingField,
+			"$bucketAuto requires 'groupBy' and 'buckets' to be specified",
+			"$bucketAuto (stage)",
+		)
+	}
+
+	if b.output == nil {
+		b.output = defaultBucketOutput("$bucketAuto")
+	}
+
+	return &b, nil
+}
+
+// getBucketAutoBuckets returns the number of buckets for $bucketAuto stage.
+// It must be a positive number representable as a 32-bit integer.
+func getBucketAutoBuckets(v any) (int, error) {
+	var n float64
+
+	switch v := v.(type) {
+	case float64:
+		n = v
+	case int32:
+		n = float64(v)
+	case int64:
+		n = float64(v)
+	default:
+		return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageBucketAutoInvalidBuckets,
+			fmt.Sprintf(
+				"The $bucketAuto 'buckets' field must be a numeric value, but found type: %s",
+				commonparams.AliasFromType(v),
+			),
+			"$bucketAuto (stage)",
+		)
+	}
+
+	if n != math.Trunc(n) || n < math.MinInt32 || n > math.MaxInt32 {
+		return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageBucketAutoNonIntegerBuckets,
+			fmt.Sprintf(
+				"The $bucketAuto 'buckets' field must be representable as a 32-bit integer, but found %s",
+				types.FormatAnyValue(v),
+			),
+			"$bucketAuto (stage)",
+		)
+	}
+
+	if n <= 0 {
+		return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageBucketAutoNonPositiveBuckets,
+			fmt.Sprintf("The $bucketAuto 'buckets' field must be greater than 0, but found: %d", int64(n)),
+			"$bucketAuto (stage)",
+		)
+	}
+
+	return int(n), nil
+}
+
+// bucketAutoValue is a document with its evaluated groupBy value.
+type bucketAutoValue struct {
+	value any
+	doc   *types.Document
+}
+
+// Process implements Stage interface.
+func (b *bucketAuto) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	values := make([]bucketAutoValue, len(docs))
+
+	for i, doc := range docs {
+		v, err := evaluateGroupBy(b.groupBy, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		values[i] = bucketAutoValue{value: v, doc: doc}
+	}
+
+	slices.SortStableFunc(values, func(a, b bucketAutoValue) int {
+		switch types.CompareOrder(a.value, b.value, types.Ascending) {
+		case types.Less:
+			return -1
+		case types.Greater:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	size := int(math.Round(float64(len(values)) / float64(b.buckets)))
+	if size < 1 {
+		size = 1
+	}
+
+	var res []*types.Document
+
+	for i := 0; i < b.buckets && len(values) > 0; i++ {
+		n := len(values)
+		if i < b.buckets-1 && size < n {
+			n = size
+
+			// values equal to the last value in the bucket are placed to the same bucket
+			for n < len(values) && types.CompareOrder(values[n].value, values[n-1].value, types.Ascending) == types.Equal {
+				n++
+			}
+		}
+
+		lower := values[0].value
+		upper := values[n-1].value
+
+		if n < len(values) {
+			upper = values[n].value
+		}
+
+		bucketDocs := make([]*types.Document, n)
+		for j, v := range values[:n] {
+			bucketDocs[j] = v.doc
+		}
+
+		id := must.NotFail(types.NewDocument("min", lower, "max", upper))
+
+		doc, err := accumulateBucket(id, bucketDocs, b.output)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, doc)
+		values = values[n:]
+	}
+
+	iter = iterator.Values(iterator.ForSlice(res))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*bucketAuto)(nil)
+)
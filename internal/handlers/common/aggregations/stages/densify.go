@@ -0,0 +1,539 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// maxDensifyDocs is the maximum number of documents $densify stage can generate.
+const maxDensifyDocs = 500_000
+
+// densifyUnits maps supported $densify time units to their durations.
+// Zero duration is used for calendar units.
+var densifyUnits = map[string]time.Duration{
+	"millisecond": time.Millisecond,
+	"second":      time.Second,
+	"minute":      time.Minute,
+	"hour":        time.Hour,
+	"day":         24 * time.Hour,
+	"week":        7 * 24 * time.Hour,
+	"month":       0,
+	"quarter":     0,
+	"year":        0,
+}
+
+// densify represents $densify stage.
+//
+//	{ $densify: {
+//		field: <fieldName>,
+//		partitionByFields: [ <field 1>, <field 2> ... <field n> ],
+//		range: {
+//			step: <number>,
+//			unit: <time unit>,
+//			bounds: < "full" || "partition" || [ < lower bound >, < upper bound > ] >
+//		}
+//	}}
+//
+// $densify creates new documents with missing values of the field,
+// so that values of each partition are spaced by step within bounds.
+// Explicit upper bound is exclusive.
+// New documents contain only the field and partitionByFields.
+type densify struct {
+	field             types.Path
+	partitionByFields []types.Path
+	step              any    // positive number
+	unit              string // empty for numeric values
+	bounds            string // "full", "partition" or empty for explicit bounds
+	lower, upper      any    // explicit bounds
+}
+
+// newDensify validates stage document and creates a new $densify stage.
+func newDensify(stage *types.Document) (aggregations.Stage, error) {
+	spec, ok := must.NotFail(stage.Get("$densify")).(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFailedToParse,
+			fmt.Sprintf(
+				"the $densify stage specification must be an object, found %s",
+				commonparams.AliasFromType(must.NotFail(stage.Get("$densify"))),
+			),
+			"$densify (stage)",
+		)
+	}
+
+	var d densify
+	var fieldName string
+	var rangeSpec *types.Document
+
+	for _, key := range spec.Keys() {
+		v := must.NotFail(spec.Get(key))
+
+		switch key {
+		case "field":
+			if fieldName, ok = v.(string); !ok {
+				return nil, newDensifyTypeError("$densify."+key, v, "string")
+			}
+
+		case "partitionByFields":
+			arr, ok := v.(*types.Array)
+			if !ok {
+				return nil, newDensifyTypeError("$densify."+key, v, "array")
+			}
+
+			for i := 0; i < arr.Len(); i++ {
+				f, ok := must.NotFail(arr.Get(i)).(string)
+				if !ok {
+					return nil, newDensifyTypeError(fmt.Sprintf("$densify.%s.%d", key, i), must.NotFail(arr.Get(i)), "string")
+				}
+
+				path, err := densifyPath(f)
+				if err != nil {
+					return nil, err
+				}
+
+				d.partitionByFields = append(d.partitionByFields, path)
+			}
+
+		case "range":
+			if rangeSpec, ok = v.(*types.Document); !ok {
+				return nil, newDensifyTypeError("$densify."+key, v, "object")
+			}
+
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field '$densify.%s' is an unknown field.", key),
+				"$densify (stage)",
+			)
+		}
+	}
+
+	for _, key := range []string{"field", "range"} {
+		if !spec.Has(key) {
+			return nil, newDensifyMissingFieldError("$densify." + key)
+		}
+	}
+
+	var err error
+	if d.field, err = densifyPath(fieldName); err != nil {
+		return nil, err
+	}
+
+	for _, p := range d.partitionByFields {
+		if p.String() == d.field.String() {
+			return nil, newDensifyBadValueError("$densify field to densify must not be in partitionByFields")
+		}
+	}
+
+	if err = d.parseRange(rangeSpec); err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+// densifyPath returns a path of $densify field.
+func densifyPath(field string) (types.Path, error) {
+	path, err := types.NewPathFromString(field)
+	if err != nil || strings.HasPrefix(field, "$") {
+		return types.Path{}, newDensifyBadValueError(fmt.Sprintf("Cannot densify field with invalid name: '%s'", field))
+	}
+
+	return path, nil
+}
+
+// parseRange parses range of $densify stage.
+func (d *densify) parseRange(spec *types.Document) error {
+	var bounds any
+
+	for _, key := range spec.Keys() {
+		v := must.NotFail(spec.Get(key))
+
+		switch key {
+		case "step":
+			switch v.(type) {
+			case float64, int32, int64:
+				d.step = v
+			default:
+				return newDensifyTypeError("$densify.range."+key, v, "number")
+			}
+
+		case "unit":
+			var ok bool
+			if d.unit, ok = v.(string); !ok {
+				return newDensifyTypeError("$densify.range."+key, v, "string")
+			}
+
+			if _, ok = densifyUnits[d.unit]; !ok {
+				return newDensifyBadValueError(fmt.Sprintf("unknown time unit value: %s", d.unit))
+			}
+
+		case "bounds":
+			bounds = v
+
+		default:
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field '$densify.range.%s' is an unknown field.", key),
+				"$densify (stage)",
+			)
+		}
+	}
+
+	for _, key := range []string{"step", "bounds"} {
+		if !spec.Has(key) {
+			return newDensifyMissingFieldError("$densify.range." + key)
+		}
+	}
+
+	if types.Compare(d.step, int32(0)) != types.Greater {
+		return newDensifyBadValueError("the step parameter in a range statement must be a strictly positive numeric value")
+	}
+
+	if d.unit != "" {
+		step, err := commonparams.GetWholeNumberParam(d.step)
+		if err != nil {
+			return newDensifyBadValueError("The step parameter in a range statement must be a whole number when densifying a date range")
+		}
+
+		d.step = step
+	}
+
+	switch bounds := bounds.(type) {
+	case string:
+		if bounds != "full" && bounds != "partition" {
+			return newDensifyBadValueError("Bounds string must either be 'full' or 'partition'")
+		}
+
+		if bounds == "partition" && len(d.partitionByFields) == 0 {
+			return newDensifyBadValueError(
+				"one may not specify the bounds as 'partition' without specifying a non-empty array of partitionByFields",
+			)
+		}
+
+		d.bounds = bounds
+
+	case *types.Array:
+		if bounds.Len() != 2 {
+			return newDensifyBadValueError("A bounding array in a range statement must have exactly two elements")
+		}
+
+		d.lower, d.upper = must.NotFail(bounds.Get(0)), must.NotFail(bounds.Get(1))
+
+		for _, b := range []any{d.lower, d.upper} {
+			switch b.(type) {
+			case float64, int32, int64:
+				if d.unit != "" {
+					return newDensifyBadValueError("A bounding array must contain dates if using a unit")
+				}
+			case time.Time:
+				if d.unit == "" {
+					return newDensifyBadValueError("A bounding array must be numeric if not using a unit")
+				}
+			default:
+				return newDensifyBadValueError("A bounding array must be an ascending array of either two dates or two numbers")
+			}
+		}
+
+		if types.CompareOrder(d.lower, d.upper, types.Ascending) == types.Greater {
+			return newDensifyBadValueError("A bounding array must be an ascending array of either two dates or two numbers")
+		}
+
+	default:
+		return newDensifyBadValueError("The bounds in a range statement must be the string 'full', 'partition', or an ascending array")
+	}
+
+	return nil
+}
+
+// newDensifyTypeError returns an error for $densify field of the wrong type.
+func newDensifyTypeError(field string, v any, expected string) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrTypeMismatch,
+		fmt.Sprintf(
+			"BSON field '%s' is the wrong type '%s', expected type '%s'",
+			field, commonparams.AliasFromType(v), expected,
+		),
+		"$densify (stage)",
+	)
+}
+
+// newDensifyMissingFieldError returns an error for missing required $densify field.
+func newDensifyMissingFieldError(field string) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrMissingField,
+		fmt.Sprintf("BSON field '%s' is missing but a required field", field),
+		"$densify (stage)",
+	)
+}
+
+// newDensifyBadValueError returns an error for invalid $densify specification or field value.
+func newDensifyBadValueError(msg string) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrBadValue, msg, "$densify (stage)")
+}
+
+// densifyPartition represents documents of a single $densify partition.
+type densifyPartition struct {
+	key    *types.Document   // values of partitionByFields
+	nulls  []*types.Document // documents without the field value
+	docs   []*types.Document // documents with the field value, sorted by it
+	values []any             // field values of docs
+}
+
+// Process implements Stage interface.
+//
+// Documents are returned grouped by partitions and sorted by the field.
+func (d *densify) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	partitions, err := d.partition(docs)
+	if err != nil {
+		return nil, err
+	}
+
+	var min, max any
+
+	if d.bounds == "full" {
+		for _, p := range partitions {
+			if len(p.values) == 0 {
+				continue
+			}
+
+			if min == nil || types.CompareOrder(p.values[0], min, types.Ascending) == types.Less {
+				min = p.values[0]
+			}
+
+			if last := p.values[len(p.values)-1]; max == nil || types.CompareOrder(last, max, types.Ascending) == types.Greater {
+				max = last
+			}
+		}
+	}
+
+	res := make([]*types.Document, 0, len(docs))
+
+	var generated int
+
+	for _, p := range partitions {
+		res = append(res, p.nulls...)
+
+		var lower, upper any
+		inclusive := true
+
+		switch d.bounds {
+		case "full":
+			lower, upper = min, max
+		case "partition":
+			if len(p.values) > 0 {
+				lower, upper = p.values[0], p.values[len(p.values)-1]
+			}
+		default:
+			lower, upper = d.lower, d.upper
+			inclusive = false
+		}
+
+		if lower == nil {
+			res = append(res, p.docs...)
+			continue
+		}
+
+		var i int
+
+		for v := lower; ; v = d.next(v) {
+			c := types.CompareOrder(v, upper, types.Ascending)
+			if c == types.Greater || (c == types.Equal && !inclusive) {
+				break
+			}
+
+			for i < len(p.docs) && types.CompareOrder(p.values[i], v, types.Ascending) == types.Less {
+				res = append(res, p.docs[i])
+				i++
+			}
+
+			if i < len(p.docs) && types.CompareOrder(p.values[i], v, types.Ascending) == types.Equal {
+				continue
+			}
+
+			if generated++; generated > maxDensifyDocs {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrStageDensifyTooManyDocuments,
+					fmt.Sprintf(
+						"Generated %d documents in $densify, which is over the limit of %d.",
+						generated, maxDensifyDocs,
+					),
+					"$densify (stage)",
+				)
+			}
+
+			doc, err := d.newDocument(p.key, v)
+			if err != nil {
+				return nil, err
+			}
+
+			res = append(res, doc)
+		}
+
+		res = append(res, p.docs[i:]...)
+	}
+
+	iter = iterator.Values(iterator.ForSlice(res))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// partition returns documents grouped by partitionByFields values.
+// Partitions are sorted by those values.
+func (d *densify) partition(docs []*types.Document) ([]*densifyPartition, error) {
+	var res []*densifyPartition
+
+	for _, doc := range docs {
+		key := must.NotFail(types.NewDocument())
+
+		for _, path := range d.partitionByFields {
+			if v, err := doc.GetByPath(path); err == nil {
+				key.Set(path.String(), v)
+			}
+		}
+
+		var p *densifyPartition
+
+		for _, existing := range res {
+			if types.CompareForAggregation(existing.key, key) == types.Equal {
+				p = existing
+				break
+			}
+		}
+
+		if p == nil {
+			p = &densifyPartition{key: key}
+			res = append(res, p)
+		}
+
+		v, err := doc.GetByPath(d.field)
+		if err != nil || v == types.Null {
+			p.nulls = append(p.nulls, doc)
+			continue
+		}
+
+		switch v.(type) {
+		case float64, int32, int64:
+			if d.unit != "" {
+				return nil, newDensifyBadValueError("Densify field type must be a date when unit is specified")
+			}
+		case time.Time:
+			if d.unit == "" {
+				return nil, newDensifyBadValueError("Densify field type must be numeric if unit is not specified")
+			}
+		default:
+			return nil, newDensifyBadValueError("Densify field type must be numeric or a date")
+		}
+
+		p.docs = append(p.docs, doc)
+		p.values = append(p.values, v)
+	}
+
+	for _, p := range res {
+		idx := make([]int, len(p.docs))
+		for i := range idx {
+			idx[i] = i
+		}
+
+		slices.SortStableFunc(idx, func(a, b int) int {
+			return compareResultToInt(types.CompareOrder(p.values[a], p.values[b], types.Ascending))
+		})
+
+		docs, values := make([]*types.Document, len(idx)), make([]any, len(idx))
+		for i, j := range idx {
+			docs[i], values[i] = p.docs[j], p.values[j]
+		}
+
+		p.docs, p.values = docs, values
+	}
+
+	slices.SortStableFunc(res, func(a, b *densifyPartition) int {
+		return compareResultToInt(types.CompareOrder(a.key, b.key, types.Ascending))
+	})
+
+	return res, nil
+}
+
+// next returns the next value of the field after v.
+func (d *densify) next(v any) any {
+	t, ok := v.(time.Time)
+	if !ok {
+		return aggregations.SumNumbers(v, d.step)
+	}
+
+	step := d.step.(int64)
+
+	switch d.unit {
+	case "month":
+		return t.AddDate(0, int(step), 0)
+	case "quarter":
+		return t.AddDate(0, 3*int(step), 0)
+	case "year":
+		return t.AddDate(int(step), 0, 0)
+	default:
+		return t.Add(time.Duration(step) * densifyUnits[d.unit])
+	}
+}
+
+// newDocument returns a new document with the given field value and partition key values.
+func (d *densify) newDocument(key *types.Document, v any) (*types.Document, error) {
+	doc := must.NotFail(types.NewDocument())
+	must.NoError(doc.SetByPath(d.field, v))
+
+	for _, k := range key.Keys() {
+		if err := doc.SetByPath(must.NotFail(types.NewPathFromString(k)), must.NotFail(key.Get(k))); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	return doc, nil
+}
+
+// compareResultToInt converts CompareResult to the value expected by slices.SortStableFunc.
+func compareResultToInt(res types.CompareResult) int {
+	switch res {
+	case types.Less:
+		return -1
+	case types.Greater:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*densify)(nil)
+)
@@ -0,0 +1,121 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// evaluateExpression evaluates aggregation expression against the given document
+// and returns CommandError that can be returned by the given aggregation stage.
+//
+// Strings prefixed with `$` are field paths, `$$ROOT` and `$$CURRENT` variables refer to the whole document,
+// documents with `$`-prefixed keys are operators, and values of other documents and arrays
+// are evaluated recursively. Any other value is returned as is.
+//
+// The second returned value is false if the expression refers to a missing field.
+func evaluateExpression(stage string, doc *types.Document, expr any) (any, bool, error) {
+	switch expr := expr.(type) {
+	case string:
+		if !strings.HasPrefix(expr, "$") {
+			return expr, true, nil
+		}
+
+		for _, v := range []string{"$$ROOT", "$$CURRENT"} {
+			if expr == v {
+				return doc, true, nil
+			}
+
+			if strings.HasPrefix(expr, v+".") {
+				expr = "$" + strings.TrimPrefix(expr, v+".")
+				break
+			}
+		}
+
+		expression, err := aggregations.NewExpression(expr, nil)
+		if err != nil {
+			var exprErr *aggregations.ExpressionError
+			if errors.As(err, &exprErr) {
+				return nil, false, processStageError(stage, err)
+			}
+
+			return nil, false, err
+		}
+
+		v, err := expression.Evaluate(doc)
+		if err != nil {
+			return nil, false, nil
+		}
+
+		return v, true, nil
+
+	case *types.Document:
+		if operators.IsOperator(expr) {
+			op, err := operators.NewOperator(expr)
+			if err != nil {
+				return nil, false, processStageError(stage, err)
+			}
+
+			v, err := op.Process(doc)
+			if err != nil {
+				return nil, false, processStageError(stage, err)
+			}
+
+			return v, true, nil
+		}
+
+		res := must.NotFail(types.NewDocument())
+
+		for _, key := range expr.Keys() {
+			v, found, err := evaluateExpression(stage, doc, must.NotFail(expr.Get(key)))
+			if err != nil {
+				return nil, false, err
+			}
+
+			if found {
+				res.Set(key, v)
+			}
+		}
+
+		return res, true, nil
+
+	case *types.Array:
+		res := types.MakeArray(expr.Len())
+
+		for i := 0; i < expr.Len(); i++ {
+			v, found, err := evaluateExpression(stage, doc, must.NotFail(expr.Get(i)))
+			if err != nil {
+				return nil, false, err
+			}
+
+			if !found {
+				v = types.Null
+			}
+
+			res.Append(v)
+		}
+
+		return res, true, nil
+
+	default:
+		return expr, true, nil
+	}
+}
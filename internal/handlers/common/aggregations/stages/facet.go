@@ -0,0 +1,241 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// facetDisallowedStages contains stages that are not allowed within $facet sub-pipelines.
+var facetDisallowedStages = map[string]struct{}{
+	// sorted alphabetically
+	"$changeStream":   {},
+	"$collStats":      {},
+	"$currentOp":      {},
+	"$documents":      {},
+	"$facet":          {},
+	"$geoNear":        {},
+	"$indexStats":     {},
+	"$listSessions":   {},
+	"$merge":          {},
+	"$out":            {},
+	"$planCacheStats": {},
+	"$search":         {},
+	"$searchMeta":     {},
+	// please keep sorted alphabetically
+}
+
+// facetPipeline represents a single named sub-pipeline of $facet stage.
+type facetPipeline struct {
+	name   string
+	stages []aggregations.Stage
+}
+
+// facet represents $facet stage.
+//
+//	{ $facet: { <outputField1>: [ <stage1>, <stage2>, ... ], ... } }
+type facet struct {
+	pipelines []facetPipeline
+}
+
+// newFacet validates facet document and creates a new $facet stage.
+func newFacet(stage *types.Document) (aggregations.Stage, error) {
+	spec := must.NotFail(stage.Get("$facet"))
+
+	specDoc, ok := spec.(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageFacetNotObject,
+			"the $facet specification must be a non-empty object",
+			"$facet (stage)",
+		)
+	}
+
+	if specDoc.Len() == 0 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageFacetEmpty,
+			"the $facet specification must be a non-empty object",
+			"$facet (stage)",
+		)
+	}
+
+	pipelines := make([]facetPipeline, 0, specDoc.Len())
+
+	iter := specDoc.Iterator()
+	defer iter.Close()
+
+	for {
+		name, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if err = validateFacetName(name); err != nil {
+			return nil, err
+		}
+
+		arr, ok := v.(*types.Array)
+		if !ok {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrStageFacetNotArray,
+				fmt.Sprintf(
+					"arguments to $facet must be arrays, %s is type %s",
+					name, commonparams.AliasFromType(v),
+				),
+				"$facet (stage)",
+			)
+		}
+
+		if arr.Len() == 0 {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrStageFacetEmptyPipeline,
+				"sub-pipeline in $facet stage cannot be empty",
+				"$facet (stage)",
+			)
+		}
+
+		p := facetPipeline{
+			name:   name,
+			stages: make([]aggregations.Stage, 0, arr.Len()),
+		}
+
+		for i := 0; i < arr.Len(); i++ {
+			stageDoc, ok := must.NotFail(arr.Get(i)).(*types.Document)
+			if !ok {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrTypeMismatch,
+					"Each element of the 'pipeline' array must be an object",
+					"$facet (stage)",
+				)
+			}
+
+			if _, disallowed := facetDisallowedStages[stageDoc.Command()]; disallowed {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrStageFacetNotAllowed,
+					fmt.Sprintf("%s is not allowed to be used within a $facet stage", stageDoc.Command()),
+					"$facet (stage)",
+				)
+			}
+
+			s, err := NewStage(stageDoc)
+			if err != nil {
+				return nil, err
+			}
+
+			p.stages = append(p.stages, s)
+		}
+
+		pipelines = append(pipelines, p)
+	}
+
+	return &facet{
+		pipelines: pipelines,
+	}, nil
+}
+
+// validateFacetName returns an error if $facet output field name is not valid.
+func validateFacetName(name string) error {
+	switch {
+	case name == "":
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrEmptyFieldPath,
+			"FieldPath cannot be constructed with empty string",
+			"$facet (stage)",
+		)
+	case strings.HasPrefix(name, "$"):
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFieldPathInvalidName,
+			"FieldPath field names may not start with '$'. Consider using $getField or $setField.",
+			"$facet (stage)",
+		)
+	}
+
+	return nil
+}
+
+// Process implements Stage interface.
+//
+// All input documents are consumed, then each sub-pipeline is applied to their copies.
+// It returns a single document with a field per sub-pipeline containing its results.
+func (f *facet) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res := must.NotFail(types.NewDocument())
+
+	for _, p := range f.pipelines {
+		out, err := f.processPipeline(ctx, p.stages, docs)
+		if err != nil {
+			return nil, err
+		}
+
+		arr := types.MakeArray(len(out))
+		for _, doc := range out {
+			arr.Append(doc)
+		}
+
+		res.Set(p.name, arr)
+	}
+
+	resIter := iterator.Values(iterator.ForSlice([]*types.Document{res}))
+	closer.Add(resIter)
+
+	return resIter, nil
+}
+
+// processPipeline applies sub-pipeline stages to copies of the given documents and returns the results.
+func (f *facet) processPipeline(ctx context.Context, stages []aggregations.Stage, docs []*types.Document) ([]*types.Document, error) { //nolint:lll // for readability
+	copies := make([]*types.Document, len(docs))
+	for i, doc := range docs {
+		copies[i] = doc.DeepCopy()
+	}
+
+	closer := iterator.NewMultiCloser()
+	defer closer.Close()
+
+	var iter types.DocumentsIterator = iterator.Values(iterator.ForSlice(copies))
+	closer.Add(iter)
+
+	for _, s := range stages {
+		var err error
+		if iter, err = s.Process(ctx, iter, closer); err != nil {
+			return nil, err
+		}
+	}
+
+	return iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*facet)(nil)
+)
@@ -0,0 +1,440 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// fill represents $fill stage.
+//
+//	{ $fill: {
+//		partitionBy: <expression>,
+//		partitionByFields: [ <field 1>, <field 2>, ... , <field n> ],
+//		sortBy: { <sort field 1>: <sort order>, ... },
+//		output: {
+//			<field 1>: { value: <expression> },
+//			<field 2>: { method: <string> },
+//			...
+//		}
+//	}}
+//
+// $fill sets null and missing output fields either to the evaluated value expression,
+// or by the method applied to documents of the same partition sorted by sortBy:
+// "locf" carries the last observed non-null value forward,
+// "linear" interpolates between surrounding non-null values.
+type fill struct {
+	partitionBy any             // nil if neither partitionBy nor partitionByFields is set
+	sortBy      *types.Document // nil if sortBy is not set
+	output      []fillOutput
+	hasMethod   bool
+}
+
+// fillOutput represents a single output field of $fill stage.
+type fillOutput struct {
+	path   types.Path
+	value  any    // value expression; nil if method is set
+	method string // "locf" or "linear"; empty if value is set
+}
+
+// newFill validates stage document and creates a new $fill stage.
+func newFill(stage *types.Document) (aggregations.Stage, error) {
+	spec, ok := must.NotFail(stage.Get("$fill")).(*types.Document)
+	if !ok {
+		return nil, newFillParseError(
+			"the $fill stage specification must be an object, found %s",
+			commonparams.AliasFromType(must.NotFail(stage.Get("$fill"))),
+		)
+	}
+
+	if !spec.Has("output") {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMissingField,
+			"BSON field '$fill.output' is missing but a required field",
+			"$fill (stage)",
+		)
+	}
+
+	var f fill
+	var output *types.Document
+
+	for _, key := range spec.Keys() {
+		v := must.NotFail(spec.Get(key))
+
+		switch key {
+		case "partitionBy":
+			if err := validateGroupKey(v); err != nil {
+				return nil, err
+			}
+
+			f.partitionBy = v
+
+		case "partitionByFields":
+			arr, ok := v.(*types.Array)
+			if !ok {
+				return nil, newFillTypeError(key, v, "array")
+			}
+
+			partitionBy := must.NotFail(types.NewDocument())
+
+			for i := 0; i < arr.Len(); i++ {
+				field, ok := must.NotFail(arr.Get(i)).(string)
+				if !ok {
+					return nil, newFillTypeError(fmt.Sprintf("%s.%d", key, i), must.NotFail(arr.Get(i)), "string")
+				}
+
+				if strings.HasPrefix(field, "$") {
+					return nil, newFillParseError("Invalid field name in partitionByFields: '%s'", field)
+				}
+
+				partitionBy.Set(strconv.Itoa(i), "$"+field)
+			}
+
+			f.partitionBy = partitionBy
+
+		case "sortBy":
+			if f.sortBy, ok = v.(*types.Document); !ok {
+				return nil, newFillTypeError(key, v, "object")
+			}
+
+			for _, field := range f.sortBy.Keys() {
+				if _, err := common.GetSortType(field, must.NotFail(f.sortBy.Get(field))); err != nil {
+					return nil, err
+				}
+			}
+
+		case "output":
+			if output, ok = v.(*types.Document); !ok {
+				return nil, newFillTypeError(key, v, "object")
+			}
+
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field '$fill.%s' is an unknown field.", key),
+				"$fill (stage)",
+			)
+		}
+	}
+
+	if spec.Has("partitionBy") && spec.Has("partitionByFields") {
+		return nil, newFillParseError("Only one of 'partitionBy' and 'partitionByFields' can be specified in '$fill'")
+	}
+
+	for _, field := range output.Keys() {
+		out, err := newFillOutput(field, must.NotFail(output.Get(field)))
+		if err != nil {
+			return nil, err
+		}
+
+		if out.method != "" {
+			f.hasMethod = true
+		}
+
+		if out.method == "linear" && (f.sortBy == nil || f.sortBy.Len() != 1) {
+			return nil, newFillParseError("Method 'linear' requires exactly one field in sortBy")
+		}
+
+		f.output = append(f.output, *out)
+	}
+
+	if f.hasMethod && f.sortBy == nil {
+		return nil, newFillParseError("sortBy required if any output field specifies a 'method'")
+	}
+
+	return &f, nil
+}
+
+// newFillOutput parses a single output field of $fill stage.
+func newFillOutput(field string, v any) (*fillOutput, error) {
+	path, err := types.NewPathFromString(field)
+	if err != nil || strings.HasPrefix(field, "$") {
+		return nil, newFillParseError("Invalid output field name: '%s'", field)
+	}
+
+	spec, ok := v.(*types.Document)
+	if !ok {
+		return nil, newFillTypeError("output."+field, v, "object")
+	}
+
+	out := fillOutput{path: path}
+
+	for _, key := range spec.Keys() {
+		switch key {
+		case "value":
+			out.value = must.NotFail(spec.Get(key))
+
+		case "method":
+			method, ok := must.NotFail(spec.Get(key)).(string)
+			if !ok || (method != "locf" && method != "linear") {
+				return nil, newFillParseError("Method must be either locf or linear")
+			}
+
+			out.method = method
+
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field '$fill.output.%s.%s' is an unknown field.", field, key),
+				"$fill (stage)",
+			)
+		}
+	}
+
+	if spec.Has("value") == spec.Has("method") {
+		return nil, newFillParseError("Exactly one of 'value' and 'method' must be specified in an output field")
+	}
+
+	return &out, nil
+}
+
+// newFillTypeError returns an error for $fill field of the wrong type.
+func newFillTypeError(key string, v any, expected string) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrTypeMismatch,
+		fmt.Sprintf(
+			"BSON field '$fill.%s' is the wrong type '%s', expected type '%s'",
+			key, commonparams.AliasFromType(v), expected,
+		),
+		"$fill (stage)",
+	)
+}
+
+// newFillParseError returns an error for invalid $fill specification.
+func newFillParseError(format string, args ...any) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrFailedToParse,
+		fmt.Sprintf(format, args...),
+		"$fill (stage)",
+	)
+}
+
+// Process implements Stage interface.
+//
+// If any output field uses a method, documents are returned grouped by partitions and sorted by sortBy.
+// Otherwise, the order of documents is preserved.
+func (f *fill) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	partitions := [][]*types.Document{make([]*types.Document, len(docs))}
+
+	if f.hasMethod {
+		w := setWindowFields{partitionBy: f.partitionBy}
+		if partitions, err = w.partition(docs); err != nil {
+			return nil, err
+		}
+	} else {
+		for i, doc := range docs {
+			partitions[0][i] = doc.DeepCopy()
+		}
+	}
+
+	res := make([]*types.Document, 0, len(docs))
+
+	for _, partition := range partitions {
+		if f.hasMethod {
+			if err = common.SortDocuments(partition, f.sortBy, nil); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, out := range f.output {
+			if err = f.fillPartition(partition, &out); err != nil {
+				return nil, err
+			}
+		}
+
+		res = append(res, partition...)
+	}
+
+	iter = iterator.Values(iterator.ForSlice(res))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// fillPartition sets null and missing values of the output field in the given documents.
+func (f *fill) fillPartition(partition []*types.Document, out *fillOutput) error {
+	switch out.method {
+	case "locf":
+		var last any = types.Null
+
+		for _, doc := range partition {
+			if v, ok := fillValue(doc, out.path); ok {
+				last = v
+				continue
+			}
+
+			if err := doc.SetByPath(out.path, last); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
+	case "linear":
+		return f.fillLinear(partition, out)
+
+	default:
+		for _, doc := range partition {
+			if _, ok := fillValue(doc, out.path); ok {
+				continue
+			}
+
+			v, found, err := evaluateExpression("$fill", doc, out.value)
+			if err != nil {
+				return err
+			}
+
+			if !found {
+				v = types.Null
+			}
+
+			if err = doc.SetByPath(out.path, v); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// fillLinear sets null and missing values of the output field in the given sorted documents
+// to values linearly interpolated between surrounding non-null values.
+// Values before the first and after the last non-null value are set to null.
+func (f *fill) fillLinear(partition []*types.Document, out *fillOutput) error {
+	sortPath := must.NotFail(types.NewPathFromString(f.sortBy.Keys()[0]))
+
+	xs := make([]float64, len(partition))
+	ys := make([]float64, len(partition))
+	known := make([]bool, len(partition))
+
+	for i, doc := range partition {
+		var err error
+		if xs[i], err = fillSortValue(doc, sortPath); err != nil {
+			return err
+		}
+
+		v, ok := fillValue(doc, out.path)
+		if !ok {
+			continue
+		}
+
+		if ys[i], ok = fillNumber(v); !ok {
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				fmt.Sprintf("Value to be filled with method 'linear' must be numeric, found %s", commonparams.AliasFromType(v)),
+				"$fill (stage)",
+			)
+		}
+
+		known[i] = true
+	}
+
+	prev := -1
+
+	for i := range partition {
+		if known[i] {
+			prev = i
+			continue
+		}
+
+		next := i + 1
+		for next < len(partition) && !known[next] {
+			next++
+		}
+
+		var v any = types.Null
+
+		if prev >= 0 && next < len(partition) {
+			y := ys[prev]
+			if xs[next] != xs[prev] {
+				y += (ys[next] - ys[prev]) * (xs[i] - xs[prev]) / (xs[next] - xs[prev])
+			}
+
+			v = y
+		}
+
+		if err := partition[i].SetByPath(out.path, v); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// fillValue returns the value of the given path, and false if it is null or missing.
+func fillValue(doc *types.Document, path types.Path) (any, bool) {
+	v, err := doc.GetByPath(path)
+	if err != nil || v == types.Null {
+		return nil, false
+	}
+
+	return v, true
+}
+
+// fillNumber returns the number as float64, and false if the value is not a number.
+func fillNumber(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// fillSortValue returns the value of the sortBy field used for linear interpolation.
+// Dates are converted to milliseconds.
+func fillSortValue(doc *types.Document, path types.Path) (float64, error) {
+	v, err := doc.GetByPath(path)
+	if err == nil {
+		if t, ok := v.(time.Time); ok {
+			return float64(t.UnixMilli()), nil
+		}
+
+		if x, ok := fillNumber(v); ok {
+			return x, nil
+		}
+	}
+
+	return 0, commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrTypeMismatch,
+		"The sortBy field of method 'linear' must be numeric or a date",
+		"$fill (stage)",
+	)
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*fill)(nil)
+)
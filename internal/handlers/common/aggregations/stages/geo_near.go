@@ -0,0 +1,299 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// GeoNear represents $geoNear stage.
+//
+// It must be the first stage of the pipeline.
+// If the key is not specified, handlers set it from 2dsphere indexes of the collection with ResolveKey.
+type GeoNear struct {
+	near               *common.GeoNear
+	query              *types.Document
+	distanceField      types.Path
+	includeLocs        *types.Path
+	key                string
+	distanceMultiplier float64
+}
+
+// newGeoNear validates $geoNear stage document and creates a new $geoNear stage.
+func newGeoNear(stage *types.Document) (aggregations.Stage, error) {
+	spec, ok := must.NotFail(stage.Get("$geoNear")).(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			"$geoNear argument must be an object",
+			"$geoNear (stage)",
+		)
+	}
+
+	res := &GeoNear{
+		distanceMultiplier: 1,
+	}
+
+	var near, minDistance, maxDistance any
+	var spherical bool
+
+	for _, k := range spec.Keys() {
+		v := must.NotFail(spec.Get(k))
+
+		var err error
+
+		switch k {
+		case "near":
+			near = v
+
+		case "distanceField", "includeLocs":
+			s, ok := v.(string)
+			if !ok {
+				return nil, newGeoNearError(
+					commonerrors.ErrTypeMismatch,
+					fmt.Sprintf("%s must be a string, but found %s", k, commonparams.AliasFromType(v)),
+				)
+			}
+
+			var path types.Path
+			if path, err = types.NewPathFromString(s); err != nil {
+				return nil, newGeoNearError(commonerrors.ErrBadValue, fmt.Sprintf("invalid %s: '%s'", k, s))
+			}
+
+			if k == "distanceField" {
+				res.distanceField = path
+			} else {
+				res.includeLocs = &path
+			}
+
+		case "spherical":
+			if spherical, err = commonparams.GetBoolOptionalParam(k, v); err != nil {
+				return nil, err
+			}
+
+		case "minDistance":
+			minDistance = v
+
+		case "maxDistance":
+			maxDistance = v
+
+		case "distanceMultiplier":
+			var m float64
+			if m, ok = geoNearNumber(v); !ok {
+				return nil, newGeoNearError(commonerrors.ErrTypeMismatch, "distanceMultiplier must be a number")
+			}
+
+			if m < 0 {
+				return nil, newGeoNearError(commonerrors.ErrBadValue, "distanceMultiplier must be nonnegative")
+			}
+
+			res.distanceMultiplier = m
+
+		case "query":
+			if res.query, ok = v.(*types.Document); !ok {
+				return nil, newGeoNearError(commonerrors.ErrTypeMismatch, "query must be an object")
+			}
+
+			if common.HasGeoNearOperator(res.query) {
+				return nil, newGeoNearError(
+					commonerrors.ErrBadValue,
+					"$geoNear, $near, and $nearSphere are not allowed in this context",
+				)
+			}
+
+		case "key":
+			s, ok := v.(string)
+			if !ok {
+				return nil, newGeoNearError(
+					commonerrors.ErrTypeMismatch,
+					fmt.Sprintf(
+						"$geoNear parameter 'key' must be of type string but found type: %s",
+						commonparams.AliasFromType(v),
+					),
+				)
+			}
+
+			if s == "" {
+				return nil, newGeoNearError(
+					commonerrors.ErrBadValue,
+					"$geoNear parameter 'key' cannot be the empty string",
+				)
+			}
+
+			res.key = s
+
+		default:
+			return nil, newGeoNearError(commonerrors.ErrBadValue, fmt.Sprintf("Unknown argument to $geoNear: %s", k))
+		}
+	}
+
+	if near == nil {
+		return nil, newGeoNearError(commonerrors.ErrBadValue, "$geoNear requires a 'near' option as an Array")
+	}
+
+	if res.distanceField.Len() == 0 {
+		return nil, newGeoNearError(commonerrors.ErrBadValue, "$geoNear requires a 'distanceField' option as a String")
+	}
+
+	var err error
+	if res.near, err = common.NewGeoNear(near, spherical, minDistance, maxDistance); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ResolveKey sets the field of documents' geometries from the given fields of collection's 2dsphere indexes
+// if the key was not specified.
+func (g *GeoNear) ResolveKey(fields []string) error {
+	if g.key != "" {
+		return nil
+	}
+
+	switch len(fields) {
+	case 0:
+		return newGeoNearError(
+			commonerrors.ErrIndexNotFound,
+			"$geoNear requires a 2d or 2dsphere index, but none were found",
+		)
+	case 1:
+		g.key = fields[0]
+		return nil
+	default:
+		return newGeoNearError(
+			commonerrors.ErrIndexNotFound,
+			"There is more than one 2dsphere index; unsure which to use for $geoNear",
+		)
+	}
+}
+
+// Process implements Stage interface.
+func (g *GeoNear) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	if g.key == "" {
+		if err := g.ResolveKey(nil); err != nil {
+			return nil, err
+		}
+	}
+
+	type geoNearDocument struct {
+		doc      *types.Document
+		distance float64
+	}
+
+	var docs []geoNearDocument
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if g.query != nil {
+			var matches bool
+			if matches, err = common.FilterDocument(doc, g.query); err != nil {
+				return nil, err
+			}
+
+			if !matches {
+				continue
+			}
+		}
+
+		distance, loc, ok, err := g.near.DocumentDistance(doc, g.key)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if !ok || !g.near.Matches(distance) {
+			continue
+		}
+
+		doc = doc.DeepCopy()
+
+		if err = doc.SetByPath(g.distanceField, distance*g.distanceMultiplier); err != nil {
+			return nil, newGeoNearError(commonerrors.ErrBadValue, err.Error())
+		}
+
+		if g.includeLocs != nil {
+			if err = doc.SetByPath(*g.includeLocs, loc); err != nil {
+				return nil, newGeoNearError(commonerrors.ErrBadValue, err.Error())
+			}
+		}
+
+		docs = append(docs, geoNearDocument{doc: doc, distance: distance})
+	}
+
+	slices.SortStableFunc(docs, func(a, b geoNearDocument) int {
+		switch {
+		case a.distance < b.distance:
+			return -1
+		case a.distance > b.distance:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	res := make([]*types.Document, len(docs))
+	for i, d := range docs {
+		res[i] = d.doc
+	}
+
+	iter = iterator.Values(iterator.ForSlice(res))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// newGeoNearError returns $geoNear stage error with the given code and message.
+func newGeoNearError(code commonerrors.ErrorCode, msg string) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(code, msg, "$geoNear (stage)")
+}
+
+// geoNearNumber returns the float64 value of the number.
+func geoNearNumber(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*GeoNear)(nil)
+)
@@ -18,8 +18,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
+	"sync"
 	"time"
 
+	"golang.org/x/exp/slices"
+
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
 	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators"
@@ -117,46 +121,119 @@ func newGroup(stage *types.Document) (aggregations.Stage, error) {
 }
 
 // Process implements Stage interface.
+//
+// Input documents are partitioned by group key hashes between workers,
+// one per available CPU, which group them and then apply accumulators in parallel.
+// Partitions that exceed the memory budget spill documents to temporary files; see groupPartition.
+// Like before, groups are returned in the order of their first documents in the input.
 func (g *group) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
-	// TODO https://github.com/FerretDB/FerretDB/issues/2863
-	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
-	if err != nil {
-		return nil, lazyerrors.Error(err)
+	defer iter.Close()
+
+	n := runtime.GOMAXPROCS(0)
+
+	partitions := make([]*groupPartition, n)
+	records := make([]chan *groupRecord, n)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+
+	for i := range partitions {
+		p := newGroupPartition(groupMaxMemoryBytes / n)
+		defer p.Close()
+
+		ch := make(chan *groupRecord, 100)
+
+		partitions[i] = p
+		records[i] = ch
+
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			for r := range ch {
+				// drain the channel on error
+				if errs[i] == nil {
+					errs[i] = p.add(r)
+				}
+			}
+		}(i)
+	}
+
+	err := g.partitionDocuments(iter, records)
+
+	for _, ch := range records {
+		close(ch)
 	}
 
-	groupedDocuments, err := g.groupDocuments(docs)
+	wg.Wait()
+
 	if err != nil {
 		return nil, err
 	}
 
-	var res []*types.Document
+	for _, err = range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	for _, groupedDocument := range groupedDocuments {
-		doc := must.NotFail(types.NewDocument("_id", groupedDocument.groupID))
+	results := make([][]*groupResult, n)
 
-		groupIter := iterator.Values(iterator.ForSlice(groupedDocument.documents))
-		defer groupIter.Close()
+	for i, p := range partitions {
+		wg.Add(1)
 
-		for _, accumulation := range g.groupBy {
-			out, err := accumulation.accumulator.Accumulate(groupIter)
-			if err != nil {
-				// existing accumulators do not return error
-				return nil, processGroupStageError(err)
-			}
+		go func(i int, p *groupPartition) {
+			defer wg.Done()
 
-			if doc.Has(accumulation.outputField) {
-				// document has duplicate key
-				return nil, commonerrors.NewCommandErrorMsgWithArgument(
-					commonerrors.ErrDuplicateField,
-					fmt.Sprintf("duplicate field: %s", accumulation.outputField),
-					"$group (stage)",
-				)
-			}
+			errs[i] = p.buckets(func(groups map[uint64][]*groupState) error {
+				for _, states := range groups {
+					if err := ctx.Err(); err != nil {
+						return lazyerrors.Error(err)
+					}
+
+					for _, state := range states {
+						doc, err := g.accumulate(state)
+						if err != nil {
+							return err
+						}
+
+						results[i] = append(results[i], &groupResult{seq: state.seq, doc: doc})
+					}
+				}
+
+				return nil
+			})
+		}(i, p)
+	}
+
+	wg.Wait()
+
+	for _, err = range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var all []*groupResult
+	for _, r := range results {
+		all = append(all, r...)
+	}
 
-			doc.Set(accumulation.outputField, out)
+	slices.SortFunc(all, func(a, b *groupResult) int {
+		switch {
+		case a.seq < b.seq:
+			return -1
+		case a.seq > b.seq:
+			return 1
+		default:
+			return 0
 		}
+	})
 
-		res = append(res, doc)
+	res := make([]*types.Document, len(all))
+	for i, r := range all {
+		res[i] = r.doc
 	}
 
 	iter = iterator.Values(iterator.ForSlice(res))
@@ -165,6 +242,126 @@ func (g *group) Process(ctx context.Context, iter types.DocumentsIterator, close
 	return iter, nil
 }
 
+// groupResult represents the output document of a single group.
+type groupResult struct {
+	seq int64 // see groupState
+	doc *types.Document
+}
+
+// partitionDocuments evaluates group keys of input documents
+// and sends them to partitions selected by group key hashes.
+func (g *group) partitionDocuments(iter types.DocumentsIterator, partitions []chan *groupRecord) error {
+	var expression *aggregations.Expression
+
+	if s, ok := g.groupExpression.(string); ok {
+		var err error
+		if expression, err = aggregations.NewExpression(s, nil); err != nil {
+			var exprErr *aggregations.ExpressionError
+			if !errors.As(err, &exprErr) {
+				return lazyerrors.Error(err)
+			}
+
+			if exprErr.Code() != aggregations.ErrNotExpression {
+				return processGroupStageError(err)
+			}
+		}
+	}
+
+	for seq := int64(0); ; seq++ {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			return nil
+		}
+
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		key, err := g.groupKey(expression, doc)
+		if err != nil {
+			return err
+		}
+
+		hash := groupKeyHash(key)
+
+		partitions[hash%uint64(len(partitions))] <- &groupRecord{
+			seq:  seq,
+			hash: hash,
+			key:  key,
+			doc:  doc,
+		}
+	}
+}
+
+// groupKey returns the group key of the document.
+// If group key contains expressions or operators, they are evaluated.
+//
+// For string group keys, the expression should be created by the caller;
+// it is nil if the group key is not an expression.
+func (g *group) groupKey(expression *aggregations.Expression, doc *types.Document) (any, error) {
+	switch groupKey := g.groupExpression.(type) {
+	case *types.Document:
+		val, err := evaluateDocument(groupKey, doc, false)
+		if err != nil {
+			// operator and expression errors are validated in newGroup
+			return nil, lazyerrors.Error(err)
+		}
+
+		return val, nil
+
+	case *types.Array, float64, types.Binary, types.ObjectID, bool, time.Time, types.NullType,
+		types.Regex, int32, types.Timestamp, int64:
+		return groupKey, nil
+
+	case string:
+		if expression == nil {
+			return groupKey, nil
+		}
+
+		val, err := expression.Evaluate(doc)
+		if err != nil {
+			// $group treats non-existent fields as nulls
+			val = types.Null
+		}
+
+		return val, nil
+
+	default:
+		panic(fmt.Sprintf("unexpected type %[1]T (%#[1]v)", groupKey))
+	}
+}
+
+// accumulate applies accumulators to documents of the group and returns the output document.
+func (g *group) accumulate(state *groupState) (*types.Document, error) {
+	doc := must.NotFail(types.NewDocument("_id", state.key))
+
+	for _, accumulation := range g.groupBy {
+		groupIter := iterator.Values(iterator.ForSlice(state.docs))
+
+		out, err := accumulation.accumulator.Accumulate(groupIter)
+
+		groupIter.Close()
+
+		if err != nil {
+			// existing accumulators do not return error
+			return nil, processGroupStageError(err)
+		}
+
+		if doc.Has(accumulation.outputField) {
+			// document has duplicate key
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrDuplicateField,
+				fmt.Sprintf("duplicate field: %s", accumulation.outputField),
+				"$group (stage)",
+			)
+		}
+
+		doc.Set(accumulation.outputField, out)
+	}
+
+	return doc, nil
+}
+
 // validateGroupKey returns error on invalid group key.
 // If group key is a document, it recursively validates operator and expression.
 func validateGroupKey(groupKey any) error {
@@ -230,55 +427,6 @@ func validateGroupKey(groupKey any) error {
 	return nil
 }
 
-// groupDocuments groups documents into groups using group key. If group key contains expressions
-// or operators, they are evaluated before using it as the group key of documents.
-func (g *group) groupDocuments(in []*types.Document) ([]groupedDocuments, error) {
-	var m groupMap
-
-	for _, doc := range in {
-		switch groupKey := g.groupExpression.(type) {
-		case *types.Document:
-			val, err := evaluateDocument(groupKey, doc, false)
-			if err != nil {
-				// operator and expression errors are validated in newGroup
-				return nil, lazyerrors.Error(err)
-			}
-
-			m.addOrAppend(val, doc)
-		case *types.Array, float64, types.Binary, types.ObjectID, bool, time.Time, types.NullType,
-			types.Regex, int32, types.Timestamp, int64:
-			m.addOrAppend(groupKey, doc)
-		case string:
-			expression, err := aggregations.NewExpression(groupKey, nil)
-			if err != nil {
-				var exprErr *aggregations.ExpressionError
-				if errors.As(err, &exprErr) {
-					if exprErr.Code() == aggregations.ErrNotExpression {
-						m.addOrAppend(groupKey, doc)
-						continue
-					}
-
-					return nil, processGroupStageError(err)
-				}
-
-				return nil, lazyerrors.Error(err)
-			}
-
-			val, err := expression.Evaluate(doc)
-			if err != nil {
-				// $group treats non-existent fields as nulls
-				val = types.Null
-			}
-
-			m.addOrAppend(val, doc)
-		default:
-			panic(fmt.Sprintf("unexpected type %[1]T (%#[1]v)", groupKey))
-		}
-	}
-
-	return m.docs, nil
-}
-
 // evaluateDocument recursively evaluates document's field expressions and operators.
 func evaluateDocument(expr, doc *types.Document, nestedField bool) (any, error) {
 	if operators.IsOperator(expr) {
@@ -384,10 +532,17 @@ func (m *groupMap) addOrAppend(groupKey any, docs ...*types.Document) {
 	})
 }
 
-// processGroupError takes internal error related to operator evaluation and
+// processGroupStageError takes internal error related to operator evaluation and
 // expression evaluation and returns CommandError that can be returned by $group
 // aggregation stage.
 func processGroupStageError(err error) error {
+	return processStageError("$group", err)
+}
+
+// processStageError takes internal error related to operator evaluation and
+// expression evaluation and returns CommandError that can be returned by the given
+// aggregation stage.
+func processStageError(stage string, err error) error {
 	var opErr operators.OperatorError
 	var exErr *aggregations.ExpressionError
 
@@ -398,31 +553,31 @@ func processGroupStageError(err error) error {
 			return commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrExpressionWrongLenOfFields,
 				"An object representing an expression must have exactly one field",
-				"$group (stage)",
+				stage+" (stage)",
 			)
 		case operators.ErrNotImplemented:
 			return commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrNotImplemented,
-				"Invalid $group :: caused by :: "+opErr.Error(),
-				"$group (stage)",
+				"Invalid "+stage+" :: caused by :: "+opErr.Error(),
+				stage+" (stage)",
 			)
 		case operators.ErrArgsInvalidLen:
 			return commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrOperatorWrongLenOfArgs,
 				opErr.Error(),
-				"$group (stage)",
+				stage+" (stage)",
 			)
 		case operators.ErrInvalidExpression:
 			return commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrInvalidPipelineOperator,
 				opErr.Error(),
-				"$group (stage)",
+				stage+" (stage)",
 			)
 		case operators.ErrInvalidNestedExpression:
 			return commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrInvalidPipelineOperator,
 				opErr.Error(),
-				"$group (stage)",
+				stage+" (stage)",
 			)
 		}
 
@@ -435,26 +590,26 @@ func processGroupStageError(err error) error {
 			return commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrFailedToParse,
 				"'$' starts with an invalid character for a user variable name",
-				"$group (stage)",
+				stage+" (stage)",
 			)
 		case aggregations.ErrEmptyFieldPath:
 			return commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrGroupInvalidFieldPath,
 				"'$' by itself is not a valid FieldPath",
-				"$group (stage)",
+				stage+" (stage)",
 			)
 		case aggregations.ErrUndefinedVariable:
 			// TODO https://github.com/FerretDB/FerretDB/issues/2275
 			return commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrNotImplemented,
 				"Aggregation expression variables are not implemented yet",
-				"$group (stage)",
+				stage+" (stage)",
 			)
 		case aggregations.ErrEmptyVariable:
 			return commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrFailedToParse,
 				"empty variable names are not allowed",
-				"$group (stage)",
+				stage+" (stage)",
 			)
 		}
 	}
@@ -0,0 +1,426 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// groupMaxMemoryBytes is the approximate size of documents that a single $group stage keeps in memory,
+// like MongoDB's internalDocumentSourceGroupMaxMemoryBytes parameter.
+// It is split evenly between partitions; partitions that exceed their budget spill documents to temporary files.
+//
+// It is a variable for testing.
+var groupMaxMemoryBytes = 100 * 1024 * 1024
+
+// groupSpillBuckets is the number of temporary files used by a single spilled partition.
+// Each of them is read back and grouped in memory separately.
+const groupSpillBuckets = 16
+
+// groupHashSeed is used for hashing group keys.
+var groupHashSeed = maphash.MakeSeed()
+
+// groupRecord represents an input document of $group stage with its evaluated group key.
+type groupRecord struct {
+	seq  int64  // sequence number of the document in the input
+	hash uint64 // see groupKeyHash
+	key  any
+	doc  *types.Document
+}
+
+// groupState represents a single group of documents.
+type groupState struct {
+	seq  int64 // sequence number of the first document of the group
+	key  any
+	docs []*types.Document
+}
+
+// groupPartition groups documents with group key hashes assigned to it.
+//
+// It keeps groups in memory until the size of their documents exceeds the limit.
+// After that, all documents of the partition are written to temporary files (buckets)
+// by group key hashes, so all documents of a single group are always in the same bucket.
+//
+// It is not safe for concurrent use.
+type groupPartition struct {
+	groups map[uint64][]*groupState
+	size   int // approximate size of documents in memory
+	limit  int
+
+	// set when partition is spilled
+	files   []*os.File
+	writers []*bufio.Writer
+}
+
+// newGroupPartition creates a new partition with the given memory limit in bytes.
+func newGroupPartition(limit int) *groupPartition {
+	return &groupPartition{
+		groups: map[uint64][]*groupState{},
+		limit:  limit,
+	}
+}
+
+// add adds a document to the partition, spilling all documents to disk if needed.
+func (p *groupPartition) add(r *groupRecord) error {
+	if p.files != nil {
+		return p.write(r)
+	}
+
+	addToGroups(p.groups, r)
+
+	if p.size += bson.Size(r.doc); p.size <= p.limit {
+		return nil
+	}
+
+	return p.spill()
+}
+
+// spill writes all documents in memory to temporary files.
+func (p *groupPartition) spill() error {
+	if p.files == nil {
+		p.files = make([]*os.File, groupSpillBuckets)
+		p.writers = make([]*bufio.Writer, groupSpillBuckets)
+
+		for i := range p.files {
+			f, err := os.CreateTemp("", "ferretdb-group-*")
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			p.files[i] = f
+			p.writers[i] = bufio.NewWriter(f)
+		}
+	}
+
+	for h, groups := range p.groups {
+		for _, g := range groups {
+			for _, doc := range g.docs {
+				// the first document sequence number is enough to order groups
+				if err := p.write(&groupRecord{seq: g.seq, hash: h, key: g.key, doc: doc}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	p.groups = map[uint64][]*groupState{}
+	p.size = 0
+
+	return nil
+}
+
+// write writes a document with its group key to the bucket file.
+//
+// Each record consists of the header document with the sequence number and the group key,
+// followed by the document itself.
+func (p *groupPartition) write(r *groupRecord) error {
+	w := p.writers[groupSpillBucket(r.hash)]
+
+	header := must.NotFail(types.NewDocument("seq", r.seq, "key", r.key))
+
+	for _, doc := range []*types.Document{header, r.doc} {
+		d, err := bson.ConvertDocument(doc)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if err = d.WriteTo(w); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// buckets calls f for groups of each bucket, starting with groups in memory.
+// Groups of a spilled bucket are read into memory only for the duration of the call.
+func (p *groupPartition) buckets(f func(groups map[uint64][]*groupState) error) error {
+	if p.files == nil {
+		return f(p.groups)
+	}
+
+	if len(p.groups) > 0 {
+		if err := p.spill(); err != nil {
+			return err
+		}
+	}
+
+	for i, file := range p.files {
+		if err := p.writers[i].Flush(); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		groups, err := readGroupBucket(bufio.NewReader(file))
+		if err != nil {
+			return err
+		}
+
+		if err = f(groups); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close removes temporary files, if any.
+func (p *groupPartition) Close() {
+	for _, f := range p.files {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+
+	p.files = nil
+	p.writers = nil
+	p.groups = nil
+}
+
+// readGroupBucket reads all records of the spilled bucket and groups them.
+func readGroupBucket(r *bufio.Reader) (map[uint64][]*groupState, error) {
+	groups := map[uint64][]*groupState{}
+
+	for {
+		if _, err := r.Peek(1); errors.Is(err, io.EOF) {
+			return groups, nil
+		}
+
+		header, err := readGroupDocument(r)
+		if err != nil {
+			return nil, err
+		}
+
+		doc, err := readGroupDocument(r)
+		if err != nil {
+			return nil, err
+		}
+
+		key := must.NotFail(header.Get("key"))
+
+		addToGroups(groups, &groupRecord{
+			seq:  must.NotFail(header.Get("seq")).(int64),
+			hash: groupKeyHash(key),
+			key:  key,
+			doc:  doc,
+		})
+	}
+}
+
+// readGroupDocument reads a single document written by groupPartition.write.
+func readGroupDocument(r *bufio.Reader) (*types.Document, error) {
+	var d bson.Document
+	if err := d.ReadFrom(r); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	doc, err := types.ConvertDocument(&d)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return doc, nil
+}
+
+// addToGroups adds the document to the group with the same key, creating it if needed.
+func addToGroups(groups map[uint64][]*groupState, r *groupRecord) {
+	for _, g := range groups[r.hash] {
+		// numbers are grouped for the same value regardless of their number type,
+		// so hash collisions are resolved with comparison
+		if types.CompareForAggregation(r.key, g.key) == types.Equal {
+			g.docs = append(g.docs, r.doc)
+
+			if r.seq < g.seq {
+				g.seq = r.seq
+			}
+
+			return
+		}
+	}
+
+	groups[r.hash] = append(groups[r.hash], &groupState{
+		seq:  r.seq,
+		key:  r.key,
+		docs: []*types.Document{r.doc},
+	})
+}
+
+// groupSpillBucket returns the bucket number for the given group key hash.
+//
+// It uses other bits than those used for selecting partitions.
+func groupSpillBucket(hash uint64) int {
+	return int((hash >> 32) % groupSpillBuckets)
+}
+
+// groupKeyHash returns the hash of the group key.
+//
+// Keys that are equal according to types.CompareForAggregation have the same hash.
+func groupKeyHash(key any) uint64 {
+	var h maphash.Hash
+	h.SetSeed(groupHashSeed)
+
+	writeGroupKeyHash(&h, key, true)
+
+	return h.Sum64()
+}
+
+// Type tags for writeGroupKeyHash; all numbers share the same tag.
+const (
+	groupHashNumber byte = iota + 1
+	groupHashLong
+	groupHashString
+	groupHashDocument
+	groupHashArray
+	groupHashBinary
+	groupHashObjectID
+	groupHashBool
+	groupHashDateTime
+	groupHashNull
+	groupHashRegex
+	groupHashTimestamp
+)
+
+// writeGroupKeyHash writes the given value to the hash.
+//
+// Arrays nested in other values are compared with filtering semantics,
+// and regular expressions are compared by their compiled form,
+// so only their types are hashed; that is correct but less selective.
+func writeGroupKeyHash(h *maphash.Hash, v any, top bool) {
+	var b [8]byte
+
+	switch v := v.(type) {
+	case *types.Document:
+		_ = h.WriteByte(groupHashDocument)
+
+		binary.LittleEndian.PutUint64(b[:], uint64(v.Len()))
+		_, _ = h.Write(b[:])
+
+		keys := v.Keys()
+		for i, value := range v.Values() {
+			_, _ = h.WriteString(keys[i])
+			_ = h.WriteByte(0)
+
+			writeGroupKeyHash(h, value, false)
+		}
+
+	case *types.Array:
+		_ = h.WriteByte(groupHashArray)
+
+		if !top {
+			return
+		}
+
+		binary.LittleEndian.PutUint64(b[:], uint64(v.Len()))
+		_, _ = h.Write(b[:])
+
+		for i := 0; i < v.Len(); i++ {
+			writeGroupKeyHash(h, must.NotFail(v.Get(i)), false)
+		}
+
+	case float64:
+		writeGroupNumberHash(h, v)
+
+	case int32:
+		writeGroupNumberHash(h, float64(v))
+
+	case int64:
+		// int64 values that can't be represented as float64 exactly are never equal to doubles
+		if f := float64(v); f >= -(1<<63) && f < 1<<63 && int64(f) == v {
+			writeGroupNumberHash(h, f)
+			return
+		}
+
+		_ = h.WriteByte(groupHashLong)
+
+		binary.LittleEndian.PutUint64(b[:], uint64(v))
+		_, _ = h.Write(b[:])
+
+	case string:
+		_ = h.WriteByte(groupHashString)
+		_, _ = h.WriteString(v)
+
+	case types.Binary:
+		_ = h.WriteByte(groupHashBinary)
+		_ = h.WriteByte(byte(v.Subtype))
+		_, _ = h.Write(v.B)
+
+	case types.ObjectID:
+		_ = h.WriteByte(groupHashObjectID)
+		_, _ = h.Write(v[:])
+
+	case bool:
+		_ = h.WriteByte(groupHashBool)
+
+		if v {
+			_ = h.WriteByte(1)
+		} else {
+			_ = h.WriteByte(0)
+		}
+
+	case time.Time:
+		_ = h.WriteByte(groupHashDateTime)
+
+		binary.LittleEndian.PutUint64(b[:], uint64(v.UnixMilli()))
+		_, _ = h.Write(b[:])
+
+	case types.NullType:
+		_ = h.WriteByte(groupHashNull)
+
+	case types.Regex:
+		_ = h.WriteByte(groupHashRegex)
+
+	case types.Timestamp:
+		_ = h.WriteByte(groupHashTimestamp)
+
+		binary.LittleEndian.PutUint64(b[:], uint64(v))
+		_, _ = h.Write(b[:])
+
+	default:
+		panic(fmt.Sprintf("unexpected type %T", v))
+	}
+}
+
+// writeGroupNumberHash writes the number to the hash.
+func writeGroupNumberHash(h *maphash.Hash, f float64) {
+	var b [8]byte
+
+	switch {
+	case math.IsNaN(f):
+		f = math.NaN()
+	case f == 0:
+		// -0 is equal to 0
+		f = 0
+	}
+
+	_ = h.WriteByte(groupHashNumber)
+
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(f))
+	_, _ = h.Write(b[:])
+}
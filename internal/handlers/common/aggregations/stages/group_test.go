@@ -0,0 +1,110 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestGroupKeyHash(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		a, b any
+	}{
+		"Numbers":     {a: int32(42), b: 42.0},
+		"Longs":       {a: int64(42), b: int32(42)},
+		"NegZero":     {a: 0.0, b: -0.0},
+		"Documents":   {a: must.NotFail(types.NewDocument("v", int64(1))), b: must.NotFail(types.NewDocument("v", 1.0))},
+		"Arrays":      {a: must.NotFail(types.NewArray(int32(1), "foo")), b: must.NotFail(types.NewArray(1.0, "foo"))},
+		"LargeLongs":  {a: int64(1<<53 + 1), b: int64(1<<53 + 1)},
+		"NestedArray": {a: must.NotFail(types.NewArray(must.NotFail(types.NewArray(int32(1))))), b: must.NotFail(types.NewArray(must.NotFail(types.NewArray(1.0))))},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, types.Equal, types.CompareForAggregation(tc.a, tc.b))
+			assert.Equal(t, groupKeyHash(tc.a), groupKeyHash(tc.b))
+		})
+	}
+}
+
+// groupDocuments runs $group stage with the given fields on the given documents.
+func groupDocuments(t *testing.T, fields *types.Document, docs []*types.Document) []*types.Document {
+	t.Helper()
+
+	g, err := newGroup(must.NotFail(types.NewDocument("$group", fields)))
+	require.NoError(t, err)
+
+	closer := iterator.NewMultiCloser()
+	defer closer.Close()
+
+	iter, err := g.Process(context.Background(), iterator.Values(iterator.ForSlice(docs)), closer)
+	require.NoError(t, err)
+
+	res, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+	require.NoError(t, err)
+
+	return res
+}
+
+func TestGroupSpill(t *testing.T) {
+	// not parallel because of groupMaxMemoryBytes
+
+	docs := make([]*types.Document, 1000)
+	for i := range docs {
+		var v any
+		switch i % 3 {
+		case 0:
+			v = int32(i % 37)
+		case 1:
+			v = int64(i % 37)
+		default:
+			v = float64(i % 37)
+		}
+
+		docs[i] = must.NotFail(types.NewDocument("_id", int32(i), "v", v))
+	}
+
+	fields := must.NotFail(types.NewDocument(
+		"_id", "$v",
+		"sum", must.NotFail(types.NewDocument("$sum", "$_id")),
+		"count", must.NotFail(types.NewDocument("$count", must.NotFail(types.NewDocument()))),
+	))
+
+	expected := groupDocuments(t, fields, docs)
+	require.Len(t, expected, 37)
+
+	for i, doc := range expected {
+		// groups are returned in the order of their first documents, with their group keys
+		assert.Equal(t, must.NotFail(docs[i].Get("v")), must.NotFail(doc.Get("_id")), "%d", i)
+	}
+
+	old := groupMaxMemoryBytes
+	groupMaxMemoryBytes = 1
+
+	t.Cleanup(func() { groupMaxMemoryBytes = old })
+
+	assert.Equal(t, expected, groupDocuments(t, fields, docs))
+}
@@ -45,6 +45,14 @@ func newMatch(stage *types.Document) (aggregations.Stage, error) {
 		return nil, err
 	}
 
+	if common.HasGeoNearOperator(filter) {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"$geoNear, $near, and $nearSphere are not allowed in this context",
+			"$match (stage)",
+		)
+	}
+
 	return &match{
 		filter: filter,
 	}, nil
@@ -52,7 +60,7 @@ func newMatch(stage *types.Document) (aggregations.Stage, error) {
 
 // Process implements Stage interface.
 func (m *match) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
-	return common.FilterIterator(iter, closer, m.filter), nil
+	return common.FilterIterator(iter, closer, m.filter, common.GetCollationFromContext(ctx)), nil
 }
 
 // validateMatch validates $expr field if any.
@@ -0,0 +1,374 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// $merge whenMatched and whenNotMatched modes.
+const (
+	mergeReplace      = "replace"
+	mergeKeepExisting = "keepExisting"
+	mergeMerge        = "merge"
+	mergeFail         = "fail"
+	mergePipeline     = "pipeline" // whenMatched is an update pipeline
+	mergeInsert       = "insert"
+	mergeDiscard      = "discard"
+)
+
+// Merge represents $merge stage.
+//
+// It is a terminal stage: handlers write documents returned by the Documents method
+// to the target collection.
+type Merge struct {
+	DB         string // empty for the database of the aggregate command
+	Collection string
+
+	whenMatched         string
+	whenMatchedPipeline *types.Array // set if whenMatched is mergePipeline
+	whenNotMatched      string
+}
+
+// newMerge validates $merge stage document and creates a new $merge stage.
+func newMerge(stage *types.Document) (aggregations.Stage, error) {
+	spec := must.NotFail(stage.Get("$merge"))
+
+	m := Merge{
+		whenMatched:    mergeMerge,
+		whenNotMatched: mergeInsert,
+	}
+
+	var err error
+
+	switch spec := spec.(type) {
+	case string:
+		if m.DB, m.Collection, err = getOutputNamespace("$merge", "$merge.into", spec); err != nil {
+			return nil, err
+		}
+
+	case *types.Document:
+		if err = m.parseSpec(spec); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageMergeInvalidArg,
+			fmt.Sprintf(
+				"$merge only supports a string or object argument, but found %s",
+				commonparams.AliasFromType(spec),
+			),
+			"$merge (stage)",
+		)
+	}
+
+	return &m, nil
+}
+
+// parseSpec parses $merge stage specification document.
+func (m *Merge) parseSpec(spec *types.Document) error {
+	if !spec.Has("into") {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMissingField,
+			"BSON field '$merge.into' is missing but a required field",
+			"$merge (stage)",
+		)
+	}
+
+	for _, key := range spec.Keys() {
+		v := must.NotFail(spec.Get(key))
+
+		var err error
+
+		switch key {
+		case "into":
+			switch v.(type) {
+			case string, *types.Document:
+				m.DB, m.Collection, err = getOutputNamespace("$merge", "$merge.into", v)
+			default:
+				err = newMergeTypeError(key, v, "'[string, object]'")
+			}
+
+		case "on":
+			err = checkMergeOn(v)
+
+		case "let":
+			err = commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				"$merge 'let' field is not implemented yet",
+				"$merge (stage)",
+			)
+
+		case "whenMatched":
+			err = m.parseWhenMatched(v)
+
+		case "whenNotMatched":
+			mode, ok := v.(string)
+			if !ok {
+				err = newMergeTypeError(key, v, "'string'")
+				break
+			}
+
+			switch mode {
+			case mergeInsert, mergeDiscard, mergeFail:
+				m.whenNotMatched = mode
+			default:
+				err = newMergeEnumerationError(key, mode)
+			}
+
+		default:
+			err = commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field '$merge.%s' is an unknown field.", key),
+				"$merge (stage)",
+			)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseWhenMatched parses $merge whenMatched field that is either a mode string or an update pipeline.
+func (m *Merge) parseWhenMatched(v any) error {
+	switch v := v.(type) {
+	case string:
+		switch v {
+		case mergeReplace, mergeKeepExisting, mergeMerge, mergeFail:
+			m.whenMatched = v
+			return nil
+		default:
+			return newMergeEnumerationError("whenMatched", v)
+		}
+
+	case *types.Array:
+		if err := common.ValidateUpdatePipeline("aggregate", v); err != nil {
+			return err
+		}
+
+		m.whenMatched = mergePipeline
+		m.whenMatchedPipeline = v
+
+		return nil
+
+	default:
+		return newMergeTypeError("whenMatched", v, "'[string, array]'")
+	}
+}
+
+// checkMergeOn checks $merge on field; only _id field is supported.
+func checkMergeOn(v any) error {
+	var fields []any
+
+	switch v := v.(type) {
+	case string:
+		fields = []any{v}
+	case *types.Array:
+		fields = must.NotFail(iterator.ConsumeValues(v.Iterator()))
+	default:
+		return newMergeTypeError("on", v, "'[string, array]'")
+	}
+
+	if len(fields) == 1 && fields[0] == "_id" {
+		return nil
+	}
+
+	// other fields require a unique index on them
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrNotImplemented,
+		"$merge 'on' field other than _id is not implemented yet",
+		"$merge (stage)",
+	)
+}
+
+// newMergeTypeError returns an error for $merge field of the wrong type.
+func newMergeTypeError(field string, v any, expected string) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrTypeMismatch,
+		fmt.Sprintf(
+			"BSON field '$merge.%s' is the wrong type '%s', expected types %s",
+			field, commonparams.AliasFromType(v), expected,
+		),
+		"$merge (stage)",
+	)
+}
+
+// newMergeEnumerationError returns an error for invalid $merge mode.
+func newMergeEnumerationError(field, mode string) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrBadValue,
+		fmt.Sprintf("Enumeration value '%s' for field '$merge.%s' is not a valid value.", mode, field),
+		"$merge (stage)",
+	)
+}
+
+// Process implements Stage interface.
+//
+// It returns documents as is; handlers write them to the target collection.
+func (m *Merge) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	return iter, nil
+}
+
+// Documents returns documents that should be inserted into and updated in the target collection
+// for the given documents returned by the pipeline and all documents of the target collection.
+//
+// Documents are matched by _id values. Source documents without _id get a new one.
+// Written documents are validated; protocol error is returned for invalid documents.
+func (m *Merge) Documents(source, target []*types.Document) (insert, update []*types.Document, err error) {
+	// all target documents, including inserted ones
+	targets := make([]*types.Document, len(target), len(target)+len(source))
+	copy(targets, target)
+
+	inserted := make(map[int]struct{}, len(source))
+	updated := make(map[int]struct{}, len(source))
+
+	for _, doc := range source {
+		if !doc.Has("_id") {
+			doc.Set("_id", types.NewObjectID())
+		}
+
+		id := must.NotFail(doc.Get("_id"))
+
+		i := slices.IndexFunc(targets, func(t *types.Document) bool {
+			return types.Compare(must.NotFail(t.Get("_id")), id) == types.Equal
+		})
+
+		var t *types.Document
+		if i >= 0 {
+			t = targets[i]
+		}
+
+		var res *types.Document
+		if res, err = m.apply(doc, t); err != nil {
+			return nil, nil, err
+		}
+
+		if res == nil {
+			continue
+		}
+
+		if err = validateOutputDocument(res); err != nil {
+			return nil, nil, err
+		}
+
+		if i < 0 {
+			inserted[len(targets)] = struct{}{}
+			targets = append(targets, res)
+
+			continue
+		}
+
+		targets[i] = res
+
+		if _, ok := inserted[i]; !ok {
+			updated[i] = struct{}{}
+		}
+	}
+
+	for i, doc := range targets {
+		if _, ok := inserted[i]; ok {
+			insert = append(insert, doc)
+		}
+
+		if _, ok := updated[i]; ok {
+			update = append(update, doc)
+		}
+	}
+
+	return insert, update, nil
+}
+
+// apply returns the document that should be written to the target collection
+// for the source document returned by the pipeline
+// and the target document with the same _id value (nil if there is no such document).
+//
+// It returns nil if nothing should be written.
+func (m *Merge) apply(source, target *types.Document) (*types.Document, error) {
+	if target == nil {
+		switch m.whenNotMatched {
+		case mergeInsert:
+			return source, nil
+
+		case mergeDiscard:
+			return nil, nil
+
+		case mergeFail:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrMergeStageNoMatchingDocument,
+				"$merge could not find a matching document in the target collection "+
+					"for at least one document in the source collection",
+				"$merge (stage)",
+			)
+
+		default:
+			panic(fmt.Sprintf("unexpected whenNotMatched mode %q", m.whenNotMatched))
+		}
+	}
+
+	switch m.whenMatched {
+	case mergeReplace:
+		return source, nil
+
+	case mergeKeepExisting:
+		return nil, nil
+
+	case mergeMerge:
+		res := target.DeepCopy()
+
+		for _, key := range source.Keys() {
+			res.Set(key, must.NotFail(source.Get(key)))
+		}
+
+		return res, nil
+
+	case mergeFail:
+		id := must.NotFail(types.NewDocument("_id", must.NotFail(target.Get("_id"))))
+
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrDuplicateKeyInsert,
+			fmt.Sprintf(
+				"$merge with whenMatched: fail found an existing document with the same values for the 'on' field: %s",
+				types.FormatAnyValue(id),
+			),
+			"$merge (stage)",
+		)
+
+	case mergePipeline:
+		return common.UpdateDocumentWithPipeline("aggregate", target, m.whenMatchedPipeline)
+
+	default:
+		panic(fmt.Sprintf("unexpected whenMatched mode %q", m.whenMatched))
+	}
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*Merge)(nil)
+)
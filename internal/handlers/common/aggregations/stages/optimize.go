@@ -0,0 +1,471 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// prunableAccumulators contains $group accumulators that reference document fields only by field paths,
+// so fields used by them could be found.
+var prunableAccumulators = map[string]struct{}{
+	"$addToSet": {},
+	"$avg":      {},
+	"$count":    {},
+	"$first":    {},
+	"$last":     {},
+	"$max":      {},
+	"$min":      {},
+	"$push":     {},
+	"$sum":      {},
+}
+
+// Optimize returns an optimized version of the given valid aggregation pipeline stages
+// and true if anything was changed.
+//
+// Like MongoDB, it applies the following rules until nothing changes:
+//   - $match is moved before $sort;
+//   - $match is moved before $project without expressions if it uses only fields kept by that projection;
+//   - consecutive $match stages are merged with $and;
+//   - consecutive $limit stages are merged into one with the smallest limit.
+//
+// Then, if the pipeline has $group or $count stage preceded only by $match, $sort, $limit, and $skip stages,
+// and all fields used by them are known, $project stage that keeps only those fields
+// is added after the leading $match and $sort stages.
+//
+// Stages are not modified; unexpected stage specifications are left as is.
+func Optimize(docs []*types.Document) ([]*types.Document, bool) {
+	res := make([]*types.Document, len(docs))
+	copy(res, docs)
+
+	var optimized bool
+
+	for changed := true; changed; {
+		changed = false
+
+		for i := 0; i < len(res)-1; i++ {
+			cur, next := res[i], res[i+1]
+
+			var merged *types.Document
+
+			switch cur.Command() + next.Command() {
+			case "$sort$match":
+				res[i], res[i+1] = next, cur
+				changed = true
+
+			case "$project$match":
+				if matchBeforeProject(next, cur) {
+					res[i], res[i+1] = next, cur
+					changed = true
+				}
+
+			case "$match$match":
+				merged = mergeMatch(cur, next)
+
+			case "$limit$limit":
+				merged = mergeLimit(cur, next)
+			}
+
+			if merged != nil {
+				res = append(res[:i], append([]*types.Document{merged}, res[i+2:]...)...)
+				changed = true
+			}
+		}
+
+		optimized = optimized || changed
+	}
+
+	if pruned := pruneFields(res); pruned != nil {
+		res = pruned
+		optimized = true
+	}
+
+	return res, optimized
+}
+
+// mergeMatch returns a single $match stage for two consecutive $match stages, or nil.
+func mergeMatch(first, second *types.Document) *types.Document {
+	f1, ok1 := must.NotFail(first.Get("$match")).(*types.Document)
+	f2, ok2 := must.NotFail(second.Get("$match")).(*types.Document)
+
+	if !ok1 || !ok2 {
+		return nil
+	}
+
+	switch {
+	case f1.Len() == 0:
+		return second
+	case f2.Len() == 0:
+		return first
+	}
+
+	return must.NotFail(types.NewDocument(
+		"$match", must.NotFail(types.NewDocument("$and", must.NotFail(types.NewArray(f1, f2)))),
+	))
+}
+
+// mergeLimit returns a single $limit stage for two consecutive $limit stages, or nil.
+func mergeLimit(first, second *types.Document) *types.Document {
+	l1, err1 := commonparams.GetWholeNumberParam(must.NotFail(first.Get("$limit")))
+	l2, err2 := commonparams.GetWholeNumberParam(must.NotFail(second.Get("$limit")))
+
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+
+	if l2 < l1 {
+		return second
+	}
+
+	return first
+}
+
+// matchBeforeProject returns true if the given $match stage could be applied before the given $project stage
+// without changing the result.
+func matchBeforeProject(match, project *types.Document) bool {
+	filter, ok := must.NotFail(match.Get("$match")).(*types.Document)
+	if !ok {
+		return false
+	}
+
+	fields, ok := filterFields(filter)
+	if !ok {
+		return false
+	}
+
+	spec, ok := must.NotFail(project.Get("$project")).(*types.Document)
+	if !ok {
+		return false
+	}
+
+	var included, excluded []string
+	var idKept, idExcluded, inclusion bool
+
+	for _, path := range spec.Keys() {
+		if strings.Contains(path, "$") {
+			return false
+		}
+
+		var keep bool
+
+		switch v := must.NotFail(spec.Get(path)).(type) {
+		case bool:
+			keep = v
+		case int32, int64, float64:
+			keep = v != int32(0) && v != int64(0) && v != float64(0)
+		default:
+			// computed fields
+			return false
+		}
+
+		switch {
+		case path == "_id":
+			idKept, idExcluded = keep, !keep
+		case keep:
+			inclusion = true
+			included = append(included, path)
+		default:
+			excluded = append(excluded, path)
+		}
+	}
+
+	// {_id: 1} is an inclusion projection
+	if idKept && len(included) == 0 && len(excluded) == 0 {
+		inclusion = true
+	}
+
+	for _, f := range fields {
+		if f == "_id" || strings.HasPrefix(f, "_id.") {
+			if idExcluded {
+				return false
+			}
+
+			continue
+		}
+
+		if inclusion {
+			var kept bool
+
+			for _, p := range included {
+				if f == p || strings.HasPrefix(f, p+".") {
+					kept = true
+					break
+				}
+			}
+
+			if !kept {
+				return false
+			}
+
+			continue
+		}
+
+		for _, p := range excluded {
+			if f == p || strings.HasPrefix(f, p+".") || strings.HasPrefix(p, f+".") {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// filterFields returns field paths used by the given $match filter.
+//
+// It returns false if fields could not be determined, for example, if $expr is used.
+func filterFields(filter *types.Document) ([]string, bool) {
+	var res []string
+
+	for _, k := range filter.Keys() {
+		if !strings.HasPrefix(k, "$") {
+			res = append(res, k)
+			continue
+		}
+
+		switch k {
+		case "$and", "$or", "$nor":
+			arr, ok := must.NotFail(filter.Get(k)).(*types.Array)
+			if !ok {
+				return nil, false
+			}
+
+			for i := 0; i < arr.Len(); i++ {
+				d, ok := must.NotFail(arr.Get(i)).(*types.Document)
+				if !ok {
+					return nil, false
+				}
+
+				fields, ok := filterFields(d)
+				if !ok {
+					return nil, false
+				}
+
+				res = append(res, fields...)
+			}
+
+		default:
+			return nil, false
+		}
+	}
+
+	return res, true
+}
+
+// pruneFields returns the pipeline with added $project stage that keeps only fields
+// used by the leading stages and the following $group or $count stage, or nil if that is not possible.
+func pruneFields(docs []*types.Document) []*types.Document {
+	fields := map[string]struct{}{}
+
+	// position of the added $project stage
+	pos := -1
+
+	for i, d := range docs {
+		name := d.Command()
+
+		if pos == -1 && name != "$match" && name != "$sort" {
+			pos = i
+		}
+
+		switch name {
+		case "$match":
+			filter, ok := must.NotFail(d.Get(name)).(*types.Document)
+			if !ok {
+				return nil
+			}
+
+			f, ok := filterFields(filter)
+			if !ok {
+				return nil
+			}
+
+			addTopLevelFields(fields, f)
+
+		case "$sort":
+			spec, ok := must.NotFail(d.Get(name)).(*types.Document)
+			if !ok {
+				return nil
+			}
+
+			for _, k := range spec.Keys() {
+				// {$meta: ...} sorts
+				if _, ok = must.NotFail(spec.Get(k)).(*types.Document); ok {
+					return nil
+				}
+			}
+
+			addTopLevelFields(fields, spec.Keys())
+
+		case "$limit", "$skip":
+			// no fields are used
+
+		case "$count":
+			return insertProjection(docs, pos, fields)
+
+		case "$group":
+			spec, ok := must.NotFail(d.Get(name)).(*types.Document)
+			if !ok {
+				return nil
+			}
+
+			for _, k := range spec.Keys() {
+				v := must.NotFail(spec.Get(k))
+
+				if k != "_id" {
+					acc, ok := v.(*types.Document)
+					if !ok || acc.Len() != 1 {
+						return nil
+					}
+
+					if _, ok = prunableAccumulators[acc.Command()]; !ok {
+						return nil
+					}
+				}
+
+				if !expressionFields(fields, v) {
+					return nil
+				}
+			}
+
+			return insertProjection(docs, pos, fields)
+
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// insertProjection returns the pipeline with $project stage that keeps given fields inserted at the given position.
+func insertProjection(docs []*types.Document, pos int, fields map[string]struct{}) []*types.Document {
+	delete(fields, "_id")
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	spec := must.NotFail(types.NewDocument("_id", true))
+	for _, k := range keys {
+		spec.Set(k, true)
+	}
+
+	res := make([]*types.Document, 0, len(docs)+1)
+	res = append(res, docs[:pos]...)
+	res = append(res, must.NotFail(types.NewDocument("$project", spec)))
+	res = append(res, docs[pos:]...)
+
+	return res
+}
+
+// addTopLevelFields adds top-level fields of the given paths to the set.
+func addTopLevelFields(set map[string]struct{}, paths []string) {
+	for _, p := range paths {
+		top, _, _ := strings.Cut(p, ".")
+		set[top] = struct{}{}
+	}
+}
+
+// expressionFields adds top-level fields used by the given aggregation expression to the set.
+//
+// It returns false if used fields could not be determined,
+// for example, if the whole document is referenced with $$ROOT.
+func expressionFields(set map[string]struct{}, expr any) bool {
+	switch expr := expr.(type) {
+	case string:
+		switch {
+		case strings.HasPrefix(expr, "$$"):
+			v, path, _ := strings.Cut(expr[2:], ".")
+
+			switch v {
+			case "ROOT":
+				return false
+			case "CURRENT":
+				if path == "" {
+					return false
+				}
+
+				addTopLevelFields(set, []string{path})
+			}
+
+		case strings.HasPrefix(expr, "$"):
+			addTopLevelFields(set, []string{expr[1:]})
+		}
+
+		return true
+
+	case *types.Document:
+		for _, k := range expr.Keys() {
+			switch k {
+			case "$literal":
+				continue
+			case "$getField", "$setField", "$unsetField":
+				// they could reference fields by name without $
+				return false
+			}
+
+			if !expressionFields(set, must.NotFail(expr.Get(k))) {
+				return false
+			}
+		}
+
+		return true
+
+	case *types.Array:
+		for i := 0; i < expr.Len(); i++ {
+			if !expressionFields(set, must.NotFail(expr.Get(i))) {
+				return false
+			}
+		}
+
+		return true
+
+	default:
+		return true
+	}
+}
+
+// OptimizeDocs is a variant of Optimize for stages that were not validated yet, like in explain command.
+//
+// Stages are not optimized if any of them is not a document with a single field.
+func OptimizeDocs(stagesDocs []any) ([]any, bool) {
+	docs := make([]*types.Document, len(stagesDocs))
+
+	for i, s := range stagesDocs {
+		d, ok := s.(*types.Document)
+		if !ok || d.Len() != 1 {
+			return stagesDocs, false
+		}
+
+		docs[i] = d
+	}
+
+	docs, optimized := Optimize(docs)
+
+	res := make([]any, len(docs))
+	for i, d := range docs {
+		res[i] = d
+	}
+
+	return res, optimized
+}
@@ -0,0 +1,179 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// stage creates a stage document for tests.
+func stage(name string, spec any) *types.Document {
+	return must.NotFail(types.NewDocument(name, spec))
+}
+
+// doc is a shortcut for creating documents in tests.
+func doc(pairs ...any) *types.Document {
+	return must.NotFail(types.NewDocument(pairs...))
+}
+
+func TestOptimize(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		pipeline []*types.Document
+		expected []*types.Document // nil if the pipeline is not changed
+	}{
+		"Empty": {},
+		"MatchBeforeSort": {
+			pipeline: []*types.Document{
+				stage("$sort", doc("v", int32(1))),
+				stage("$match", doc("v", int32(42))),
+			},
+			expected: []*types.Document{
+				stage("$match", doc("v", int32(42))),
+				stage("$sort", doc("v", int32(1))),
+			},
+		},
+		"MatchBeforeInclusionProject": {
+			pipeline: []*types.Document{
+				stage("$project", doc("v", true, "w.x", int32(1))),
+				stage("$match", doc("v", int32(42), "w.x.y", "foo")),
+			},
+			expected: []*types.Document{
+				stage("$match", doc("v", int32(42), "w.x.y", "foo")),
+				stage("$project", doc("v", true, "w.x", int32(1))),
+			},
+		},
+		"MatchNotKeptByProject": {
+			pipeline: []*types.Document{
+				stage("$project", doc("v", true)),
+				stage("$match", doc("w", int32(42))),
+			},
+		},
+		"MatchExcludedByProject": {
+			pipeline: []*types.Document{
+				stage("$project", doc("v.w", false)),
+				stage("$match", doc("v", nil)),
+			},
+		},
+		"MatchExcludedIDProject": {
+			pipeline: []*types.Document{
+				stage("$project", doc("_id", false)),
+				stage("$match", doc("_id", int32(1))),
+			},
+		},
+		"MatchOnlyIDProject": {
+			pipeline: []*types.Document{
+				stage("$project", doc("_id", true)),
+				stage("$match", doc("v", nil)),
+			},
+		},
+		"MatchComputedProject": {
+			pipeline: []*types.Document{
+				stage("$project", doc("v", "$w")),
+				stage("$match", doc("v", int32(42))),
+			},
+		},
+		"MatchExpr": {
+			pipeline: []*types.Document{
+				stage("$project", doc("v", true)),
+				stage("$match", doc("$expr", doc("$eq", must.NotFail(types.NewArray("$v", int32(42)))))),
+			},
+		},
+		"MergeMatch": {
+			pipeline: []*types.Document{
+				stage("$match", doc("v", int32(42))),
+				stage("$sort", doc("v", int32(1))),
+				stage("$match", doc("w", int32(1))),
+			},
+			expected: []*types.Document{
+				stage("$match", doc("$and", must.NotFail(types.NewArray(doc("v", int32(42)), doc("w", int32(1)))))),
+				stage("$sort", doc("v", int32(1))),
+			},
+		},
+		"MergeLimit": {
+			pipeline: []*types.Document{
+				stage("$limit", int32(10)),
+				stage("$limit", int64(5)),
+				stage("$limit", float64(7)),
+			},
+			expected: []*types.Document{
+				stage("$limit", int64(5)),
+			},
+		},
+		"PruneGroup": {
+			pipeline: []*types.Document{
+				stage("$match", doc("v", int32(42))),
+				stage("$sort", doc("w.x", int32(1))),
+				stage("$limit", int32(10)),
+				stage("$group", doc("_id", "$a.b", "total", doc("$sum", doc("$add", must.NotFail(types.NewArray("$c", "$$CURRENT.d")))))),
+			},
+			expected: []*types.Document{
+				stage("$match", doc("v", int32(42))),
+				stage("$sort", doc("w.x", int32(1))),
+				stage("$project", doc("_id", true, "a", true, "c", true, "d", true, "v", true, "w", true)),
+				stage("$limit", int32(10)),
+				stage("$group", doc("_id", "$a.b", "total", doc("$sum", doc("$add", must.NotFail(types.NewArray("$c", "$$CURRENT.d")))))),
+			},
+		},
+		"PruneCount": {
+			pipeline: []*types.Document{
+				stage("$count", "n"),
+			},
+			expected: []*types.Document{
+				stage("$project", doc("_id", true)),
+				stage("$count", "n"),
+			},
+		},
+		"NoPruneRoot": {
+			pipeline: []*types.Document{
+				stage("$group", doc("_id", nil, "docs", doc("$push", "$$ROOT"))),
+			},
+		},
+		"NoPruneAccumulator": {
+			pipeline: []*types.Document{
+				stage("$group", doc("_id", nil, "top", doc("$top", doc("output", "$v", "sortBy", doc("w", int32(1)))))),
+			},
+		},
+		"NoPruneOtherStage": {
+			pipeline: []*types.Document{
+				stage("$unwind", "$v"),
+				stage("$group", doc("_id", "$v")),
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, optimized := Optimize(tc.pipeline)
+
+			if tc.expected == nil {
+				assert.False(t, optimized)
+				assert.Equal(t, len(tc.pipeline), len(actual))
+
+				return
+			}
+
+			assert.True(t, optimized)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Out represents $out stage.
+//
+// It is a terminal stage: handlers write documents returned by the pipeline
+// and prepared by the PrepareDocuments method to the target collection, replacing its existing documents.
+type Out struct {
+	DB         string // empty for the database of the aggregate command
+	Collection string
+}
+
+// newOut validates $out stage document and creates a new $out stage.
+func newOut(stage *types.Document) (aggregations.Stage, error) {
+	spec := must.NotFail(stage.Get("$out"))
+
+	switch spec.(type) {
+	case string, *types.Document:
+		// valid
+	default:
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageOutInvalidArg,
+			fmt.Sprintf(
+				"$out only supports a string or object argument, but found %s",
+				commonparams.AliasFromType(spec),
+			),
+			"$out (stage)",
+		)
+	}
+
+	db, collection, err := getOutputNamespace("$out", "$out", spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Out{
+		DB:         db,
+		Collection: collection,
+	}, nil
+}
+
+// Process implements Stage interface.
+//
+// It returns documents as is; handlers write them to the target collection.
+func (o *Out) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	return iter, nil
+}
+
+// PrepareDocuments prepares documents returned by the pipeline to be written to the target collection
+// of the given database.
+//
+// Documents without _id get a new one.
+// Documents are validated; protocol error is returned for invalid documents
+// and for documents with duplicate _id values, so the target collection is not modified in those cases.
+func (o *Out) PrepareDocuments(db string, docs []*types.Document) error {
+	for i, doc := range docs {
+		if !doc.Has("_id") {
+			doc.Set("_id", types.NewObjectID())
+		}
+
+		if err := validateOutputDocument(doc); err != nil {
+			return err
+		}
+
+		id := must.NotFail(doc.Get("_id"))
+
+		for _, prev := range docs[:i] {
+			if types.Compare(must.NotFail(prev.Get("_id")), id) != types.Equal {
+				continue
+			}
+
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrDuplicateKeyInsert,
+				fmt.Sprintf(
+					"E11000 duplicate key error collection: %s.%s index: _id_ dup key: %s",
+					db, o.Collection, types.FormatAnyValue(must.NotFail(types.NewDocument("_id", id))),
+				),
+				"$out (stage)",
+			)
+		}
+	}
+
+	return nil
+}
+
+// validateOutputDocument validates the document written by $out or $merge stage.
+func validateOutputDocument(doc *types.Document) error {
+	err := doc.ValidateData()
+	if err == nil {
+		return nil
+	}
+
+	var ve *types.ValidationError
+	if !errors.As(err, &ve) {
+		return lazyerrors.Error(err)
+	}
+
+	code := commonerrors.ErrBadValue
+	if ve.Code() == types.ErrWrongIDType {
+		code = commonerrors.ErrInvalidID
+	}
+
+	return commonerrors.NewCommandErrorMsg(code, ve.Error())
+}
+
+// getOutputNamespace returns the target database and collection names of $out or $merge stage
+// specified either as a collection name string or as `{db: <db>, coll: <collection>}` document.
+// The database name is empty if it is not specified.
+//
+// The path argument is the path of the spec field used in error messages.
+func getOutputNamespace(stage, path string, spec any) (string, string, error) {
+	var db, collection string
+
+	switch spec := spec.(type) {
+	case string:
+		collection = spec
+
+	case *types.Document:
+		for _, key := range spec.Keys() {
+			if key != "db" && key != "coll" {
+				return "", "", commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrFailedToParseInput,
+					fmt.Sprintf("BSON field '%s.%s' is an unknown field.", path, key),
+					stage+" (stage)",
+				)
+			}
+
+			v := must.NotFail(spec.Get(key))
+
+			s, ok := v.(string)
+			if !ok {
+				return "", "", commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrTypeMismatch,
+					fmt.Sprintf(
+						"BSON field '%s.%s' is the wrong type '%s', expected type 'string'",
+						path, key, commonparams.AliasFromType(v),
+					),
+					stage+" (stage)",
+				)
+			}
+
+			if key == "db" {
+				db = s
+			} else {
+				collection = s
+			}
+		}
+
+		if !spec.Has("coll") {
+			return "", "", commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrMissingField,
+				fmt.Sprintf("BSON field '%s.coll' is missing but a required field", path),
+				stage+" (stage)",
+			)
+		}
+	}
+
+	if collection == "" {
+		return "", "", commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidNamespace,
+			fmt.Sprintf("Invalid %s target namespace, collection name must not be empty", stage),
+			stage+" (stage)",
+		)
+	}
+
+	return db, collection, nil
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*Out)(nil)
+)
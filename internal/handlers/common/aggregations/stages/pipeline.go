@@ -0,0 +1,274 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// PipelineStage is a parsed stage of the aggregation pipeline.
+type PipelineStage struct {
+	Stage aggregations.Stage
+	Doc   *types.Document
+	Name  string
+	Index int
+}
+
+// Pipeline is a parsed and validated aggregation pipeline.
+type Pipeline struct {
+	Stages []PipelineStage
+
+	// Optimized is true if stages were changed by Optimize.
+	Optimized bool
+}
+
+// Optimize optimizes the pipeline stages; see Optimize function for details.
+//
+// Stage indexes are positions in the optimized pipeline.
+func (p *Pipeline) Optimize() error {
+	docs := make([]*types.Document, len(p.Stages))
+	for i, s := range p.Stages {
+		docs[i] = s.Doc
+	}
+
+	docs, optimized := Optimize(docs)
+	if !optimized {
+		return nil
+	}
+
+	stages := make([]PipelineStage, len(docs))
+
+	for i, d := range docs {
+		s, err := NewStage(d)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		stages[i] = PipelineStage{Stage: s, Doc: d, Name: d.Command(), Index: i}
+	}
+
+	p.Stages = stages
+	p.Optimized = true
+
+	return nil
+}
+
+// Docs returns specifications of all stages of the pipeline.
+func (p *Pipeline) Docs() []any {
+	res := make([]any, len(p.Stages))
+
+	for i, s := range p.Stages {
+		res[i] = s.Doc
+	}
+
+	return res
+}
+
+// Output returns the $out or $merge stage that writes pipeline results, or nil.
+func (p *Pipeline) Output() aggregations.Stage {
+	if len(p.Stages) == 0 {
+		return nil
+	}
+
+	switch last := p.Stages[len(p.Stages)-1]; last.Name {
+	case "$out", "$merge":
+		return last.Stage
+	default:
+		return nil
+	}
+}
+
+// HasCollStats returns true if the pipeline starts with the $collStats stage.
+func (p *Pipeline) HasCollStats() bool {
+	return len(p.Stages) > 0 && p.Stages[0].Name == "$collStats"
+}
+
+// DocumentsStages returns stages that should be applied to documents fetched from the backend.
+//
+// It does not include the $collStats stage.
+func (p *Pipeline) DocumentsStages() []aggregations.Stage {
+	res := make([]aggregations.Stage, 0, len(p.Stages))
+
+	for _, s := range p.Stages {
+		if s.Name == "$collStats" {
+			continue
+		}
+
+		res = append(res, s.Stage)
+	}
+
+	return res
+}
+
+// AllStages returns all stages of the pipeline, including $collStats.
+func (p *Pipeline) AllStages() []aggregations.Stage {
+	res := make([]aggregations.Stage, len(p.Stages))
+
+	for i, s := range p.Stages {
+		res[i] = s.Stage
+	}
+
+	return res
+}
+
+// StageError describes an invalid stage of the aggregation pipeline.
+type StageError struct {
+	err   error
+	Name  string
+	Index int
+}
+
+// Error implements error interface.
+func (e *StageError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("stage %d: %s", e.Index, e.err)
+	}
+
+	return fmt.Sprintf("stage %d (%s): %s", e.Index, e.Name, e.err)
+}
+
+// Unwrap implements standard error unwrapping interface.
+func (e *StageError) Unwrap() error {
+	return e.err
+}
+
+// PipelineError describes all invalid stages of the aggregation pipeline.
+//
+// It unwraps to the error of the first invalid stage,
+// so the client receives the same error as with MongoDB.
+type PipelineError struct {
+	Errors []*StageError
+}
+
+// Error implements error interface.
+func (e *PipelineError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+
+	return "invalid pipeline: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap implements standard error unwrapping interface.
+func (e *PipelineError) Unwrap() error {
+	return e.Errors[0]
+}
+
+// ParsePipeline parses and validates all stages of the given aggregation pipeline
+// before any of them are executed.
+//
+// Like MongoDB, it checks element types first, then parses all stages, and then checks stage placement.
+// If any check fails, *PipelineError with all errors of that step is returned.
+// Command is used as an argument of returned errors.
+func ParsePipeline(pipeline *types.Array, command string) (*Pipeline, error) {
+	values := must.NotFail(iterator.ConsumeValues(pipeline.Iterator()))
+	docs := make([]*types.Document, len(values))
+
+	var errs []*StageError
+
+	for i, v := range values {
+		d, ok := v.(*types.Document)
+		if !ok {
+			errs = append(errs, &StageError{
+				err: commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrTypeMismatch,
+					"Each element of the 'pipeline' array must be an object",
+					command,
+				),
+				Index: i,
+			})
+
+			continue
+		}
+
+		docs[i] = d
+	}
+
+	if errs != nil {
+		return nil, &PipelineError{Errors: errs}
+	}
+
+	res := &Pipeline{
+		Stages: make([]PipelineStage, len(docs)),
+	}
+
+	for i, d := range docs {
+		name := d.Command()
+
+		s, err := NewStage(d)
+		if err != nil {
+			errs = append(errs, &StageError{err: err, Name: name, Index: i})
+			continue
+		}
+
+		res.Stages[i] = PipelineStage{Stage: s, Doc: d, Name: name, Index: i}
+	}
+
+	if errs != nil {
+		return nil, &PipelineError{Errors: errs}
+	}
+
+	for i, s := range res.Stages {
+		var err error
+
+		switch s.Name {
+		case "$out", "$merge":
+			if i != len(res.Stages)-1 {
+				err = commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrStageOutputNotLast,
+					fmt.Sprintf("%s can only be the final stage in the pipeline", s.Name),
+					command,
+				)
+			}
+
+		case "$collStats":
+			if i > 0 {
+				err = commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCollStatsIsNotFirstStage,
+					"$collStats is only valid as the first stage in a pipeline",
+					command,
+				)
+			}
+
+		case "$geoNear":
+			if i > 0 {
+				err = commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrGeoNearNotFirstStage,
+					"$geoNear was not the first stage in the pipeline.",
+					command,
+				)
+			}
+		}
+
+		if err != nil {
+			errs = append(errs, &StageError{err: err, Name: s.Name, Index: i})
+		}
+	}
+
+	if errs != nil {
+		return nil, &PipelineError{Errors: errs}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestParsePipeline(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := must.NotFail(types.NewArray(
+			must.NotFail(types.NewDocument("$match", must.NotFail(types.NewDocument("v", int32(1))))),
+			must.NotFail(types.NewDocument("$sort", must.NotFail(types.NewDocument("v", int32(1))))),
+			must.NotFail(types.NewDocument("$out", "out")),
+		))
+
+		p, err := ParsePipeline(pipeline, "aggregate")
+		require.NoError(t, err)
+
+		require.Len(t, p.Stages, 3)
+		assert.Equal(t, "$sort", p.Stages[1].Name)
+		assert.Equal(t, 1, p.Stages[1].Index)
+		assert.NotNil(t, p.Output())
+		assert.False(t, p.HasCollStats())
+		assert.Len(t, p.DocumentsStages(), 3)
+	})
+
+	for name, tc := range map[string]struct {
+		pipeline *types.Array
+		code     commonerrors.ErrorCode
+		indexes  []int
+	}{
+		"NotDocument": {
+			pipeline: must.NotFail(types.NewArray(
+				must.NotFail(types.NewDocument("$match", must.NotFail(types.NewDocument()))),
+				"foo",
+				must.NotFail(types.NewDocument("$unknown", int32(1))),
+			)),
+			code:    commonerrors.ErrTypeMismatch,
+			indexes: []int{1},
+		},
+		"InvalidStages": {
+			pipeline: must.NotFail(types.NewArray(
+				must.NotFail(types.NewDocument("$match", must.NotFail(types.NewDocument()))),
+				must.NotFail(types.NewDocument("$unknown", int32(1))),
+				must.NotFail(types.NewDocument("$limit", "foo")),
+			)),
+			code:    commonerrors.ErrStageGroupInvalidAccumulator,
+			indexes: []int{1, 2},
+		},
+		"ParsedBeforePlacement": {
+			pipeline: must.NotFail(types.NewArray(
+				must.NotFail(types.NewDocument("$out", "out")),
+				must.NotFail(types.NewDocument("$unknown", int32(1))),
+			)),
+			code:    commonerrors.ErrStageGroupInvalidAccumulator,
+			indexes: []int{1},
+		},
+		"Placement": {
+			pipeline: must.NotFail(types.NewArray(
+				must.NotFail(types.NewDocument("$out", "out")),
+				must.NotFail(types.NewDocument("$match", must.NotFail(types.NewDocument()))),
+				must.NotFail(types.NewDocument("$collStats", must.NotFail(types.NewDocument()))),
+			)),
+			code:    commonerrors.ErrStageOutputNotLast,
+			indexes: []int{0, 2},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParsePipeline(tc.pipeline, "aggregate")
+			require.Error(t, err)
+
+			var pe *PipelineError
+			require.True(t, errors.As(err, &pe))
+
+			indexes := make([]int, len(pe.Errors))
+			for i, e := range pe.Errors {
+				indexes[i] = e.Index
+			}
+			assert.Equal(t, tc.indexes, indexes)
+
+			var ce *commonerrors.CommandError
+			require.True(t, errors.As(err, &ce))
+			assert.Equal(t, tc.code, ce.Code())
+		})
+	}
+}
@@ -15,6 +15,7 @@
 package projection
 
 import (
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
@@ -32,9 +33,15 @@ func ProjectionIterator(iter types.DocumentsIterator, closer *iterator.MultiClos
 		return nil, err
 	}
 
+	ops, err := compileOperators(projectionValidated)
+	if err != nil {
+		return nil, err
+	}
+
 	res := &projectionIterator{
 		iter:       iter,
 		projection: projectionValidated,
+		ops:        ops,
 		inclusion:  inclusion,
 	}
 	closer.Add(res)
@@ -46,6 +53,7 @@ func ProjectionIterator(iter types.DocumentsIterator, closer *iterator.MultiClos
 type projectionIterator struct {
 	iter       types.DocumentsIterator
 	projection *types.Document
+	ops        map[string]operators.Operator
 	inclusion  bool
 }
 
@@ -58,7 +66,7 @@ func (iter *projectionIterator) Next() (struct{}, *types.Document, error) {
 		return unused, nil, lazyerrors.Error(err)
 	}
 
-	projected, err := ProjectDocument(doc, iter.projection, iter.inclusion)
+	projected, err := projectDocument(doc, iter.projection, iter.ops, iter.inclusion)
 	if err != nil {
 		return unused, nil, err
 	}
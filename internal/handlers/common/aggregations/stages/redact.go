@@ -0,0 +1,384 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// redactAction represents the result of $redact expression.
+type redactAction string
+
+// Actions of $redact stage.
+const (
+	redactDescend = redactAction("$$DESCEND")
+	redactPrune   = redactAction("$$PRUNE")
+	redactKeep    = redactAction("$$KEEP")
+)
+
+// redactCond represents $cond operator used in $redact expression.
+//
+// Branches are either redactAction, *redactCond or any other expression.
+type redactCond struct {
+	ifExpr   any
+	thenExpr any
+	elseExpr any
+}
+
+// redact represents $redact stage.
+//
+//	{ $redact: <expression> }
+//
+// Expression should evaluate to $$DESCEND, $$PRUNE or $$KEEP.
+// Only $cond operator and field paths are supported for now.
+type redact struct {
+	expr any
+}
+
+// newRedact validates stage document and creates a new $redact stage.
+func newRedact(stage *types.Document) (aggregations.Stage, error) {
+	expr, err := parseRedactExpression(must.NotFail(stage.Get("$redact")))
+	if err != nil {
+		return nil, err
+	}
+
+	return &redact{
+		expr: expr,
+	}, nil
+}
+
+// parseRedactExpression parses $redact expression, replacing $redact variables by redactAction
+// and $cond operators by *redactCond.
+func parseRedactExpression(expr any) (any, error) {
+	switch expr := expr.(type) {
+	case string:
+		switch action := redactAction(expr); action {
+		case redactDescend, redactPrune, redactKeep:
+			return action, nil
+		}
+
+		if strings.HasPrefix(expr, "$$") {
+			name, _, _ := strings.Cut(strings.TrimPrefix(expr, "$$"), ".")
+			if name != "ROOT" && name != "CURRENT" {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrGroupUndefinedVariable,
+					fmt.Sprintf("Use of undefined variable: %s", name),
+					"$redact (stage)",
+				)
+			}
+		}
+
+		return expr, nil
+
+	case *types.Document:
+		if expr.Len() != 1 || expr.Command() != "$cond" {
+			return expr, nil
+		}
+
+		return parseRedactCond(must.NotFail(expr.Get("$cond")))
+
+	default:
+		return expr, nil
+	}
+}
+
+// parseRedactCond parses arguments of $cond operator used in $redact expression.
+//
+// Both `{ if: <expr>, then: <expr>, else: <expr> }` and `[ <if>, <then>, <else> ]` forms are supported.
+func parseRedactCond(args any) (*redactCond, error) {
+	var ifExpr, thenExpr, elseExpr any
+
+	switch args := args.(type) {
+	case *types.Document:
+		for _, key := range args.Keys() {
+			switch key {
+			case "if", "then", "else":
+			default:
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCondUnrecognizedParam,
+					fmt.Sprintf("Unrecognized parameter to $cond: %s", key),
+					"$redact (stage)",
+				)
+			}
+		}
+
+		var err error
+
+		if ifExpr, err = args.Get("if"); err != nil {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrCondMissingIfParam,
+				"Missing 'if' parameter to $cond",
+				"$redact (stage)",
+			)
+		}
+
+		if thenExpr, err = args.Get("then"); err != nil {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrCondMissingThenParam,
+				"Missing 'then' parameter to $cond",
+				"$redact (stage)",
+			)
+		}
+
+		if elseExpr, err = args.Get("else"); err != nil {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrCondMissingElseParam,
+				"Missing 'else' parameter to $cond",
+				"$redact (stage)",
+			)
+		}
+
+	case *types.Array:
+		if args.Len() != 3 {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrOperatorWrongLenOfArgs,
+				fmt.Sprintf("Expression $cond takes exactly 3 arguments. %d were passed in.", args.Len()),
+				"$redact (stage)",
+			)
+		}
+
+		ifExpr, thenExpr, elseExpr = must.NotFail(args.Get(0)), must.NotFail(args.Get(1)), must.NotFail(args.Get(2))
+
+	default:
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrOperatorWrongLenOfArgs,
+			"Expression $cond takes exactly 3 arguments. 1 were passed in.",
+			"$redact (stage)",
+		)
+	}
+
+	res := new(redactCond)
+
+	var err error
+
+	if res.ifExpr, err = parseRedactExpression(ifExpr); err != nil {
+		return nil, err
+	}
+
+	if res.thenExpr, err = parseRedactExpression(thenExpr); err != nil {
+		return nil, err
+	}
+
+	if res.elseExpr, err = parseRedactExpression(elseExpr); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Process implements Stage interface.
+func (r *redact) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	res := iterator.ForFunc(func() (struct{}, *types.Document, error) {
+		var unused struct{}
+
+		for {
+			_, doc, err := iter.Next()
+			if err != nil {
+				return unused, nil, lazyerrors.Error(err)
+			}
+
+			redacted, err := r.redactDocument(doc)
+			if err != nil {
+				return unused, nil, err
+			}
+
+			if redacted != nil {
+				return unused, redacted, nil
+			}
+		}
+	})
+	closer.Add(res)
+
+	return res, nil
+}
+
+// redactDocument applies $redact expression to the given document and its subdocuments.
+// It returns nil if the document is pruned.
+func (r *redact) redactDocument(doc *types.Document) (*types.Document, error) {
+	action, err := r.evaluate(doc, r.expr)
+	if err != nil {
+		return nil, err
+	}
+
+	switch action {
+	case redactPrune:
+		return nil, nil
+	case redactKeep:
+		return doc, nil
+	}
+
+	res := must.NotFail(types.NewDocument())
+
+	for _, key := range doc.Keys() {
+		v := must.NotFail(doc.Get(key))
+
+		switch v := v.(type) {
+		case *types.Document:
+			redacted, err := r.redactDocument(v)
+			if err != nil {
+				return nil, err
+			}
+
+			if redacted != nil {
+				res.Set(key, redacted)
+			}
+
+		case *types.Array:
+			redacted, err := r.redactArray(v)
+			if err != nil {
+				return nil, err
+			}
+
+			res.Set(key, redacted)
+
+		default:
+			res.Set(key, v)
+		}
+	}
+
+	return res, nil
+}
+
+// redactArray applies $redact expression to documents in the given array, including nested arrays.
+// Pruned documents are removed from the array.
+func (r *redact) redactArray(arr *types.Array) (*types.Array, error) {
+	res := types.MakeArray(arr.Len())
+
+	for i := 0; i < arr.Len(); i++ {
+		v := must.NotFail(arr.Get(i))
+
+		switch v := v.(type) {
+		case *types.Document:
+			redacted, err := r.redactDocument(v)
+			if err != nil {
+				return nil, err
+			}
+
+			if redacted != nil {
+				res.Append(redacted)
+			}
+
+		case *types.Array:
+			redacted, err := r.redactArray(v)
+			if err != nil {
+				return nil, err
+			}
+
+			res.Append(redacted)
+
+		default:
+			res.Append(v)
+		}
+	}
+
+	return res, nil
+}
+
+// evaluate evaluates parsed $redact expression against the given document.
+func (r *redact) evaluate(doc *types.Document, expr any) (redactAction, error) {
+	switch expr := expr.(type) {
+	case redactAction:
+		return expr, nil
+
+	case *redactCond:
+		cond, found, err := r.evaluateValue(doc, expr.ifExpr)
+		if err != nil {
+			return "", err
+		}
+
+		if found && isTrue(cond) {
+			return r.evaluate(doc, expr.thenExpr)
+		}
+
+		return r.evaluate(doc, expr.elseExpr)
+
+	default:
+		v, found, err := evaluateExpression("$redact", doc, expr)
+		if err != nil {
+			return "", err
+		}
+
+		res := "missing"
+		if found {
+			res = types.FormatAnyValue(v)
+		}
+
+		return "", commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageRedactInvalidResult,
+			fmt.Sprintf(
+				"$redact's expression should not return anything aside from the variables $$KEEP, "+
+					"$$DESCEND, and $$PRUNE, but returned %s",
+				res,
+			),
+			"$redact (stage)",
+		)
+	}
+}
+
+// evaluateValue evaluates parsed $redact expression that is used as a value, not as a result of $redact.
+func (r *redact) evaluateValue(doc *types.Document, expr any) (any, bool, error) {
+	switch expr := expr.(type) {
+	case redactAction:
+		return string(expr), true, nil
+
+	case *redactCond:
+		cond, found, err := r.evaluateValue(doc, expr.ifExpr)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if found && isTrue(cond) {
+			return r.evaluateValue(doc, expr.thenExpr)
+		}
+
+		return r.evaluateValue(doc, expr.elseExpr)
+
+	default:
+		return evaluateExpression("$redact", doc, expr)
+	}
+}
+
+// isTrue returns false for false, null, undefined and zero values, and true for all other values,
+// the same way as aggregation expressions evaluate conditions.
+func isTrue(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case types.NullType:
+		return false
+	case float64:
+		return v != 0
+	case int32:
+		return v != 0
+	case int64:
+		return v != 0
+	default:
+		return true
+	}
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*redact)(nil)
+)
@@ -0,0 +1,141 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// replaceRoot represents $replaceRoot and $replaceWith stages.
+//
+//	{ $replaceRoot: { newRoot: <replacementDocument> } }
+//	{ $replaceWith: <replacementDocument> }
+type replaceRoot struct {
+	stage   string
+	newRoot any
+}
+
+// newReplaceRoot validates stage document and creates a new $replaceRoot stage.
+func newReplaceRoot(stage *types.Document) (aggregations.Stage, error) {
+	spec := must.NotFail(stage.Get("$replaceRoot"))
+
+	specDoc, ok := spec.(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFailedToParse,
+			fmt.Sprintf(
+				"BSON field '$replaceRoot' is the wrong type '%s', expected type 'object'",
+				commonparams.AliasFromType(spec),
+			),
+			"$replaceRoot (stage)",
+		)
+	}
+
+	for _, key := range specDoc.Keys() {
+		if key != "newRoot" {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field '$replaceRoot.%s' is an unknown field.", key),
+				"$replaceRoot (stage)",
+			)
+		}
+	}
+
+	newRoot, err := specDoc.Get("newRoot")
+	if err != nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageReplaceRootNoNewRoot,
+			"no newRoot specified for the $replaceRoot stage",
+			"$replaceRoot (stage)",
+		)
+	}
+
+	return &replaceRoot{
+		stage:   "$replaceRoot",
+		newRoot: newRoot,
+	}, nil
+}
+
+// newReplaceWith creates a new $replaceWith stage.
+// It is an alias of $replaceRoot that takes the replacement document expression directly.
+func newReplaceWith(stage *types.Document) (aggregations.Stage, error) {
+	return &replaceRoot{
+		stage:   "$replaceWith",
+		newRoot: must.NotFail(stage.Get("$replaceWith")),
+	}, nil
+}
+
+// Process implements Stage interface.
+func (r *replaceRoot) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	res := iterator.ForFunc(func() (struct{}, *types.Document, error) {
+		var unused struct{}
+
+		_, doc, err := iter.Next()
+		if err != nil {
+			return unused, nil, lazyerrors.Error(err)
+		}
+
+		v, found, err := evaluateExpression(r.stage, doc, r.newRoot)
+		if err != nil {
+			return unused, nil, err
+		}
+
+		newRoot, ok := v.(*types.Document)
+		if !ok || !found {
+			return unused, nil, r.notObjectError(v, found)
+		}
+
+		return unused, newRoot.DeepCopy(), nil
+	})
+	closer.Add(res)
+
+	return res, nil
+}
+
+// notObjectError returns an error for the new root that is not a document.
+func (r *replaceRoot) notObjectError(v any, found bool) error {
+	name := "'newRoot' expression"
+	if r.stage == "$replaceWith" {
+		name = "'replacement document'"
+	}
+
+	value, typ := "MISSING", "missing"
+	if found {
+		value, typ = types.FormatAnyValue(v), commonparams.AliasFromType(v)
+	}
+
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrStageReplaceRootNotObject,
+		fmt.Sprintf(
+			"%s must evaluate to an object, but resulting value was: %s. Type of resulting value: '%s'.",
+			name, value, typ,
+		),
+		r.stage+" (stage)",
+	)
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*replaceRoot)(nil)
+)
@@ -0,0 +1,204 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// sample represents $sample stage.
+//
+//	{ $sample: { size: <positive integer N> } }
+type sample struct {
+	size int64
+}
+
+// newSample creates a new $sample stage.
+func newSample(stage *types.Document) (aggregations.Stage, error) {
+	size, err := sampleSize(stage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sample{
+		size: size,
+	}, nil
+}
+
+// GetPushdownSample returns the sample size for pushdown if the first stage is $sample.
+//
+// If the first stage is not $sample, or its specification is invalid, 0 is returned.
+// The $sample stage should still be processed with documents returned by the backend,
+// as sampling done by the backend may return more documents than requested.
+func GetPushdownSample(stagesDocs []any) int64 {
+	if len(stagesDocs) == 0 {
+		return 0
+	}
+
+	stage, ok := stagesDocs[0].(*types.Document)
+	if !ok || stage.Len() != 1 || stage.Command() != "$sample" {
+		return 0
+	}
+
+	size, err := sampleSize(stage)
+	if err != nil {
+		return 0
+	}
+
+	return size
+}
+
+// sampleSize returns the size of $sample stage.
+//
+// It returns CommandError if the stage specification is invalid.
+func sampleSize(stage *types.Document) (int64, error) {
+	spec, ok := must.NotFail(stage.Get("$sample")).(*types.Document)
+	if !ok {
+		return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageSampleNotObject,
+			"the $sample stage specification must be an object",
+			"$sample (stage)",
+		)
+	}
+
+	var size int64
+	var found bool
+
+	iter := spec.Iterator()
+	defer iter.Close()
+
+	for {
+		k, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return 0, lazyerrors.Error(err)
+		}
+
+		if k != "size" {
+			return 0, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrStageSampleUnknownOption,
+				fmt.Sprintf("unrecognized option to $sample: %s", k),
+				"$sample (stage)",
+			)
+		}
+
+		found = true
+
+		switch v := v.(type) {
+		case float64:
+			// fractional sizes are truncated, like MongoDB does
+			switch {
+			case math.IsNaN(v):
+				size = 0
+			case v >= math.MaxInt64:
+				size = math.MaxInt64
+			case v <= math.MinInt64:
+				size = math.MinInt64
+			default:
+				size = int64(v)
+			}
+
+		case int32, int64:
+			size = must.NotFail(commonparams.GetWholeNumberParam(v))
+
+		default:
+			return 0, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrStageSampleSizeNotNumber,
+				"size argument to $sample must be a number",
+				"$sample (stage)",
+			)
+		}
+
+		if size < 0 {
+			return 0, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrStageSampleSizeNegative,
+				"size argument to $sample must not be negative",
+				"$sample (stage)",
+			)
+		}
+	}
+
+	if !found {
+		return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageSampleMissingSize,
+			"$sample stage must specify a size",
+			"$sample (stage)",
+		)
+	}
+
+	return size, nil
+}
+
+// Process implements Stage interface.
+//
+// It uses reservoir sampling, so at most size documents are kept in memory.
+// Returned documents are in random order.
+func (s *sample) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	defer iter.Close()
+
+	var reservoir []*types.Document
+	var seen int64
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		seen++
+
+		if int64(len(reservoir)) < s.size {
+			reservoir = append(reservoir, doc)
+			continue
+		}
+
+		if i := rand.Int63n(seen); i < s.size {
+			reservoir[i] = doc
+		}
+	}
+
+	rand.Shuffle(len(reservoir), func(i, j int) {
+		reservoir[i], reservoir[j] = reservoir[j], reservoir[i]
+	})
+
+	res := iterator.Values(iterator.ForSlice(reservoir))
+	closer.Add(res)
+
+	return res, nil
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*sample)(nil)
+)
@@ -66,7 +66,7 @@ func newSet(stage *types.Document) (aggregations.Stage, error) {
 
 // Process implements Stage interface.
 func (s *set) Process(_ context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
-	return common.AddFieldsIterator(iter, closer, s.newField), nil
+	return common.AddFieldsIterator(iter, closer, s.newField)
 }
 
 // check interfaces
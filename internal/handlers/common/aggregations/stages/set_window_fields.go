@@ -0,0 +1,640 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stages
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators/accumulators"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// setWindowFields represents $setWindowFields stage.
+//
+//	{ $setWindowFields: {
+//		partitionBy: <expression>,
+//		sortBy: { <sort field>: <sort order>, ... },
+//		output: {
+//			<output field>: {
+//				<window operator>: <expression>,
+//				window: {
+//					documents: [ <lower boundary>, <upper boundary> ],
+//					range: [ <lower boundary>, <upper boundary> ]
+//				}
+//			},
+//			...
+//		}
+//	}}
+//
+// $setWindowFields partitions documents by the evaluated partitionBy expression,
+// sorts documents of each partition by sortBy,
+// and sets output fields to the results of window operators.
+// Rank operators ($rank, $denseRank) take no window; accumulators ($sum, $avg, etc.)
+// are applied to documents of the window, or of the whole partition if window is not set.
+type setWindowFields struct {
+	partitionBy any             // nil if partitionBy is not set
+	sortBy      *types.Document // nil if sortBy is not set
+	output      []windowOutput
+}
+
+// windowOutput represents a single output field of $setWindowFields stage.
+type windowOutput struct {
+	path        types.Path
+	rank        string                   // $rank or $denseRank; empty for accumulators
+	accumulator accumulators.Accumulator // nil for rank operators
+	window      *window                  // nil for the whole partition
+}
+
+// window represents bounds of $setWindowFields window relative to the current document.
+//
+// For document-based windows, bounds are positions of documents;
+// for range-based windows, bounds are added to the current value of the sortBy field.
+// Nil bound is unbounded.
+type window struct {
+	lower, upper any
+	isRange      bool
+}
+
+// newSetWindowFields creates a new $setWindowFields stage.
+func newSetWindowFields(stage *types.Document) (aggregations.Stage, error) {
+	spec, ok := must.NotFail(stage.Get("$setWindowFields")).(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFailedToParse,
+			fmt.Sprintf(
+				"the $setWindowFields stage specification must be an object, found %s",
+				commonparams.AliasFromType(must.NotFail(stage.Get("$setWindowFields"))),
+			),
+			"$setWindowFields (stage)",
+		)
+	}
+
+	if !spec.Has("output") {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMissingField,
+			"BSON field '$setWindowFields.output' is missing but a required field",
+			"$setWindowFields (stage)",
+		)
+	}
+
+	var s setWindowFields
+	var output *types.Document
+
+	for _, key := range spec.Keys() {
+		v := must.NotFail(spec.Get(key))
+
+		switch key {
+		case "partitionBy":
+			if err := validateGroupKey(v); err != nil {
+				return nil, err
+			}
+
+			s.partitionBy = v
+
+		case "sortBy":
+			if s.sortBy, ok = v.(*types.Document); !ok {
+				return nil, newSetWindowFieldsTypeError(key, v)
+			}
+
+			for _, field := range s.sortBy.Keys() {
+				if _, err := common.GetSortType(field, must.NotFail(s.sortBy.Get(field))); err != nil {
+					return nil, err
+				}
+			}
+
+		case "output":
+			if output, ok = v.(*types.Document); !ok {
+				return nil, newSetWindowFieldsTypeError(key, v)
+			}
+
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field '$setWindowFields.%s' is an unknown field.", key),
+				"$setWindowFields (stage)",
+			)
+		}
+	}
+
+	for _, field := range output.Keys() {
+		out, err := s.newWindowOutput(field, must.NotFail(output.Get(field)))
+		if err != nil {
+			return nil, err
+		}
+
+		s.output = append(s.output, *out)
+	}
+
+	return &s, nil
+}
+
+// newSetWindowFieldsTypeError returns an error for $setWindowFields field of the wrong type.
+func newSetWindowFieldsTypeError(key string, v any) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrTypeMismatch,
+		fmt.Sprintf(
+			"BSON field '$setWindowFields.%s' is the wrong type '%s', expected type 'object'",
+			key, commonparams.AliasFromType(v),
+		),
+		"$setWindowFields (stage)",
+	)
+}
+
+// newWindowParseError returns an error for invalid window operator specification.
+func newWindowParseError(format string, args ...any) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrFailedToParse,
+		fmt.Sprintf(format, args...),
+		"$setWindowFields (stage)",
+	)
+}
+
+// newWindowOutput parses a single output field of $setWindowFields stage.
+func (s *setWindowFields) newWindowOutput(field string, v any) (*windowOutput, error) {
+	path, err := types.NewPathFromString(field)
+	if err != nil || strings.HasPrefix(field, "$") {
+		return nil, newWindowParseError("Invalid output field name: '%s'", field)
+	}
+
+	spec, ok := v.(*types.Document)
+	if !ok {
+		return nil, newWindowParseError("The field '%s' must be an object", field)
+	}
+
+	var operator string
+	var windowSpec *types.Document
+
+	for _, key := range spec.Keys() {
+		switch {
+		case key == "window":
+			if windowSpec, ok = must.NotFail(spec.Get(key)).(*types.Document); !ok {
+				return nil, newWindowParseError("'window' field must be an object")
+			}
+
+		case strings.HasPrefix(key, "$") && operator == "":
+			operator = key
+
+		case strings.HasPrefix(key, "$"):
+			return nil, newWindowParseError("Cannot specify multiple functions in window function spec")
+
+		default:
+			return nil, newWindowParseError("Window function found an unknown argument: %s", key)
+		}
+	}
+
+	if operator == "" {
+		return nil, newWindowParseError("Expected a $-prefixed window function, %s", types.FormatAnyValue(spec))
+	}
+
+	out := windowOutput{
+		path: path,
+	}
+
+	switch operator {
+	case "$rank", "$denseRank":
+		if arg, ok := must.NotFail(spec.Get(operator)).(*types.Document); !ok || arg.Len() != 0 {
+			return nil, newWindowParseError("%s must be specified with '{}' as the value", operator)
+		}
+
+		if windowSpec != nil {
+			return nil, newWindowParseError("Rank style window functions take no other arguments")
+		}
+
+		if s.sortBy == nil || s.sortBy.Len() != 1 {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrStageSetWindowFieldsRankSortBy,
+				fmt.Sprintf("%s must be specified with a top level sortBy expression with exactly one element", operator),
+				"$setWindowFields (stage)",
+			)
+		}
+
+		out.rank = operator
+
+		return &out, nil
+
+	default:
+		if _, ok := accumulators.Accumulators[operator]; !ok {
+			return nil, newWindowParseError("Unrecognized window function, %s", operator)
+		}
+	}
+
+	accumulator := must.NotFail(types.NewDocument(operator, must.NotFail(spec.Get(operator))))
+
+	if out.accumulator, err = accumulators.NewAccumulator("$setWindowFields", field, accumulator); err != nil {
+		return nil, processGroupStageError(err)
+	}
+
+	if windowSpec != nil {
+		if out.window, err = s.newWindow(windowSpec); err != nil {
+			return nil, err
+		}
+	}
+
+	return &out, nil
+}
+
+// newWindow parses window field of $setWindowFields output.
+func (s *setWindowFields) newWindow(spec *types.Document) (*window, error) {
+	var w window
+	var bounds *types.Array
+
+	for _, key := range spec.Keys() {
+		v := must.NotFail(spec.Get(key))
+
+		switch key {
+		case "documents", "range":
+			if bounds != nil {
+				return nil, newWindowParseError("Window bounds can only specify one of 'documents' or 'range'")
+			}
+
+			var ok bool
+			if bounds, ok = v.(*types.Array); !ok || bounds.Len() != 2 {
+				return nil, newWindowParseError("Window bounds must be a 2-element array: %s", types.FormatAnyValue(v))
+			}
+
+			w.isRange = key == "range"
+
+		case "unit":
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				"$setWindowFields window 'unit' is not implemented yet",
+				"$setWindowFields (stage)",
+			)
+
+		default:
+			return nil, newWindowParseError(
+				"'window' field can only contain 'documents' as the only argument or 'range' with an optional 'unit' field",
+			)
+		}
+	}
+
+	if bounds == nil {
+		// the whole partition
+		return nil, nil
+	}
+
+	var err error
+
+	if w.lower, err = parseWindowBound(must.NotFail(bounds.Get(0)), w.isRange); err != nil {
+		return nil, err
+	}
+
+	if w.upper, err = parseWindowBound(must.NotFail(bounds.Get(1)), w.isRange); err != nil {
+		return nil, err
+	}
+
+	if w.lower != nil && w.upper != nil &&
+		types.CompareOrder(w.lower, w.upper, types.Ascending) == types.Greater {
+		return nil, newWindowParseError("Lower bound must not exceed upper bound: %s", types.FormatAnyValue(bounds))
+	}
+
+	switch {
+	case w.lower == nil && w.upper == nil:
+		// the whole partition, sortBy is not required
+		return nil, nil
+
+	case w.isRange && (s.sortBy == nil || s.sortBy.Len() != 1):
+		return nil, newWindowParseError("Range-based window requires sortBy a single field")
+
+	case !w.isRange && s.sortBy == nil:
+		return nil, newWindowParseError("Document-based bounds require a sortBy")
+	}
+
+	return &w, nil
+}
+
+// parseWindowBound parses a single window bound.
+// It returns nil for "unbounded", int64 for documents offset, and a number for range offset.
+func parseWindowBound(v any, isRange bool) (any, error) {
+	switch v := v.(type) {
+	case string:
+		switch v {
+		case "unbounded":
+			return nil, nil
+		case "current":
+			if isRange {
+				return int32(0), nil
+			}
+
+			return int64(0), nil
+		}
+
+	case float64, int32, int64:
+		if isRange {
+			return v, nil
+		}
+
+		n, err := commonparams.GetWholeNumberParam(v)
+		if err != nil {
+			return nil, newWindowParseError("Numeric document-based bounds must be an integer")
+		}
+
+		return n, nil
+	}
+
+	if isRange {
+		return nil, newWindowParseError(
+			"Range-based bounds expression must be a number, 'unbounded' or 'current': %s",
+			types.FormatAnyValue(v),
+		)
+	}
+
+	return nil, newWindowParseError(
+		"Document-based bounds must be an integer, 'unbounded' or 'current': %s",
+		types.FormatAnyValue(v),
+	)
+}
+
+// Process implements Stage interface.
+func (s *setWindowFields) Process(ctx context.Context, iter types.DocumentsIterator, closer *iterator.MultiCloser) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	partitions, err := s.partition(docs)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*types.Document, 0, len(docs))
+
+	for _, partition := range partitions {
+		if s.sortBy != nil {
+			if err = common.SortDocuments(partition, s.sortBy, nil); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, out := range s.output {
+			var values []any
+
+			if out.rank != "" {
+				values = s.rank(partition, out.rank == "$denseRank")
+			} else if values, err = s.accumulate(partition, &out); err != nil {
+				return nil, err
+			}
+
+			for i, doc := range partition {
+				if err = doc.SetByPath(out.path, values[i]); err != nil {
+					return nil, lazyerrors.Error(err)
+				}
+			}
+		}
+
+		res = append(res, partition...)
+	}
+
+	iter = iterator.Values(iterator.ForSlice(res))
+	closer.Add(iter)
+
+	return iter, nil
+}
+
+// partition returns copies of documents grouped by the evaluated partitionBy expression.
+// Partitions are sorted by that value.
+func (s *setWindowFields) partition(docs []*types.Document) ([][]*types.Document, error) {
+	var m groupMap
+
+	for _, doc := range docs {
+		doc = doc.DeepCopy()
+
+		if s.partitionBy == nil {
+			m.addOrAppend(types.Null, doc)
+			continue
+		}
+
+		key := s.partitionBy
+
+		switch partitionBy := s.partitionBy.(type) {
+		case *types.Document:
+			var err error
+			if key, err = evaluateGroupBy(partitionBy, doc); err != nil {
+				return nil, err
+			}
+
+		case string:
+			if strings.HasPrefix(partitionBy, "$") {
+				var err error
+				if key, err = evaluateGroupBy(partitionBy, doc); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		m.addOrAppend(key, doc)
+	}
+
+	slices.SortStableFunc(m.docs, func(a, b groupedDocuments) int {
+		switch types.CompareOrder(a.groupID, b.groupID, types.Ascending) {
+		case types.Less:
+			return -1
+		case types.Greater:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	res := make([][]*types.Document, len(m.docs))
+	for i, g := range m.docs {
+		res[i] = g.documents
+	}
+
+	return res, nil
+}
+
+// sortValue returns the value of the single sortBy field of the document, or null if it does not exist.
+func (s *setWindowFields) sortValue(doc *types.Document) any {
+	path := must.NotFail(types.NewPathFromString(s.sortBy.Keys()[0]))
+
+	v, err := doc.GetByPath(path)
+	if err != nil {
+		return types.Null
+	}
+
+	return v
+}
+
+// rank returns ranks of sorted partition documents.
+//
+// Documents with equal sortBy values have the same rank.
+// For dense ranks, there are no gaps after such documents.
+func (s *setWindowFields) rank(partition []*types.Document, dense bool) []any {
+	res := make([]any, len(partition))
+
+	var prev any
+	var rank int32
+
+	for i, doc := range partition {
+		v := s.sortValue(doc)
+
+		if i == 0 || types.CompareOrder(prev, v, types.Ascending) != types.Equal {
+			if dense {
+				rank++
+			} else {
+				rank = int32(i + 1)
+			}
+		}
+
+		res[i] = rank
+		prev = v
+	}
+
+	return res
+}
+
+// accumulate returns results of the output's accumulator applied to the window of each partition document.
+func (s *setWindowFields) accumulate(partition []*types.Document, out *windowOutput) ([]any, error) {
+	res := make([]any, len(partition))
+
+	for i := range partition {
+		start, end, err := s.windowRange(partition, i, out.window)
+		if err != nil {
+			return nil, err
+		}
+
+		var windowDocs []*types.Document
+		if start < end {
+			windowDocs = partition[start:end]
+		}
+
+		iter := iterator.Values(iterator.ForSlice(windowDocs))
+
+		v, err := out.accumulator.Accumulate(iter)
+		iter.Close()
+
+		if err != nil {
+			return nil, processGroupStageError(err)
+		}
+
+		res[i] = v
+	}
+
+	return res, nil
+}
+
+// windowRange returns the range [start, end) of sorted partition documents
+// that belong to the window of the i-th document.
+func (s *setWindowFields) windowRange(partition []*types.Document, i int, w *window) (int, int, error) {
+	start, end := 0, len(partition)
+
+	if w == nil {
+		return start, end, nil
+	}
+
+	if !w.isRange {
+		if w.lower != nil {
+			start = clampWindowIndex(int64(i)+w.lower.(int64), len(partition))
+		}
+
+		if w.upper != nil {
+			end = clampWindowIndex(int64(i)+w.upper.(int64)+1, len(partition))
+		}
+
+		return start, end, nil
+	}
+
+	current, ok := windowRangeValue(s.sortValue(partition[i]))
+	if !ok {
+		return 0, 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf(
+				"Invalid range: Expected the sortBy field to be a number, but it was %s",
+				commonparams.AliasFromType(s.sortValue(partition[i])),
+			),
+			"$setWindowFields (stage)",
+		)
+	}
+
+	var lower, upper *float64
+
+	if w.lower != nil {
+		l, _ := windowRangeValue(w.lower)
+		l += current
+		lower = &l
+	}
+
+	if w.upper != nil {
+		u, _ := windowRangeValue(w.upper)
+		u += current
+		upper = &u
+	}
+
+	start, end = len(partition), 0
+
+	for j, doc := range partition {
+		v, ok := windowRangeValue(s.sortValue(doc))
+		if !ok {
+			continue
+		}
+
+		if (lower != nil && v < *lower) || (upper != nil && v > *upper) {
+			continue
+		}
+
+		if j < start {
+			start = j
+		}
+
+		if j+1 > end {
+			end = j + 1
+		}
+	}
+
+	return start, end, nil
+}
+
+// clampWindowIndex returns i limited to [0, n].
+func clampWindowIndex(i int64, n int) int {
+	switch {
+	case i < 0:
+		return 0
+	case i > int64(n):
+		return n
+	default:
+		return int(i)
+	}
+}
+
+// windowRangeValue returns the number as float64 for range-based windows.
+func windowRangeValue(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// check interfaces
+var (
+	_ aggregations.Stage = (*setWindowFields)(nil)
+)
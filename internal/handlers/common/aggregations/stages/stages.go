@@ -29,50 +29,54 @@ type newStageFunc func(stage *types.Document) (aggregations.Stage, error)
 // Stages maps all supported aggregation Stages.
 var Stages = map[string]newStageFunc{
 	// sorted alphabetically
-	"$addFields": newAddFields,
-	"$collStats": newCollStats,
-	"$count":     newCount,
-	"$group":     newGroup,
-	"$limit":     newLimit,
-	"$match":     newMatch,
-	"$project":   newProject,
-	"$set":       newSet,
-	"$skip":      newSkip,
-	"$sort":      newSort,
-	"$unset":     newUnset,
-	"$unwind":    newUnwind,
+	"$addFields":       newAddFields,
+	"$bucket":          newBucket,
+	"$bucketAuto":      newBucketAuto,
+	"$collStats":       newCollStats,
+	"$count":           newCount,
+	"$densify":         newDensify,
+	"$fill":            newFill,
+	"$geoNear":         newGeoNear,
+	"$group":           newGroup,
+	"$limit":           newLimit,
+	"$match":           newMatch,
+	"$merge":           newMerge,
+	"$out":             newOut,
+	"$project":         newProject,
+	"$redact":          newRedact,
+	"$replaceRoot":     newReplaceRoot,
+	"$replaceWith":     newReplaceWith,
+	"$sample":          newSample,
+	"$set":             newSet,
+	"$setWindowFields": newSetWindowFields,
+	"$skip":            newSkip,
+	"$sort":            newSort,
+	"$sortByCount":     newSortByCount,
+	"$unset":           newUnset,
+	"$unwind":          newUnwind,
 	// please keep sorted alphabetically
 }
 
+func init() {
+	// $facet is registered there to avoid initialization cycle, as it creates sub-pipeline stages with NewStage.
+	Stages["$facet"] = newFacet
+}
+
 // unsupportedStages maps all unsupported yet stages.
 var unsupportedStages = map[string]struct{}{
 	// sorted alphabetically
-	"$bucket":                 {},
-	"$bucketAuto":             {},
 	"$changeStream":           {},
 	"$currentOp":              {},
-	"$densify":                {},
 	"$documents":              {},
-	"$facet":                  {},
-	"$fill":                   {},
-	"$geoNear":                {},
 	"$graphLookup":            {},
 	"$indexStats":             {},
 	"$listLocalSessions":      {},
 	"$listSessions":           {},
 	"$lookup":                 {},
-	"$merge":                  {},
-	"$out":                    {},
 	"$planCacheStats":         {},
-	"$redact":                 {},
-	"$replaceRoot":            {},
-	"$replaceWith":            {},
-	"$sample":                 {},
 	"$search":                 {},
 	"$searchMeta":             {},
-	"$setWindowFields":        {},
 	"$sharedDataDistribution": {},
-	"$sortByCount":            {},
 	"$unionWith":              {},
 	// please keep sorted alphabetically
 }
@@ -0,0 +1,192 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// ApplyOpsParams represents parameters for the applyOps command.
+type ApplyOpsParams struct {
+	// Ops contains one operation per oplog entry.
+	// No-op entries have an empty Type.
+	Ops []BulkWriteOp
+
+	BypassDocumentValidation bool
+}
+
+// applyOpsParams represents raw parameters of the applyOps command.
+type applyOpsParams struct {
+	DB       string                `ferretdb:"$db"`
+	ApplyOps []applyOpsEntryParams `ferretdb:"collection"`
+
+	BypassDocumentValidation bool `ferretdb:"bypassDocumentValidation,opt"`
+
+	PreCondition *types.Array `ferretdb:"preCondition,unimplemented"`
+
+	AllowAtomic  bool            `ferretdb:"allowAtomic,ignored"`
+	Comment      any             `ferretdb:"comment,ignored"`
+	WriteConcern *types.Document `ferretdb:"writeConcern,ignored"`
+	LSID         any             `ferretdb:"lsid,ignored"`
+}
+
+// applyOpsEntryParams represents a single oplog entry of the applyOps command.
+type applyOpsEntryParams struct {
+	Op string          `ferretdb:"op"`
+	NS string          `ferretdb:"ns"`
+	O  *types.Document `ferretdb:"o"`
+	O2 *types.Document `ferretdb:"o2,opt"`
+	B  bool            `ferretdb:"b,opt"`
+
+	TS          any  `ferretdb:"ts,ignored"`
+	T           any  `ferretdb:"t,ignored"`
+	H           any  `ferretdb:"h,ignored"`
+	V           any  `ferretdb:"v,ignored"`
+	Version     any  `ferretdb:"version,ignored"`
+	UI          any  `ferretdb:"ui,ignored"`
+	Wall        any  `ferretdb:"wall,ignored"`
+	LSID        any  `ferretdb:"lsid,ignored"`
+	TxnNumber   any  `ferretdb:"txnNumber,ignored"`
+	StmtID      any  `ferretdb:"stmtId,ignored"`
+	PrevOpTime  any  `ferretdb:"prevOpTime,ignored"`
+	FromMigrate bool `ferretdb:"fromMigrate,ignored"`
+}
+
+// GetApplyOpsParams returns parameters for the applyOps command.
+//
+// Oplog entries are converted to bulkWrite operations with resolved namespaces.
+func GetApplyOpsParams(document *types.Document, l *zap.Logger) (*ApplyOpsParams, error) {
+	command := document.Command()
+
+	var raw applyOpsParams
+
+	if err := commonparams.ExtractParams(document, command, &raw, l); err != nil {
+		return nil, err
+	}
+
+	if n := len(raw.ApplyOps); n > bulkWriteMaxOps {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidLength,
+			fmt.Sprintf("applyOps may contain at most %d operations. Got %d operations.", bulkWriteMaxOps, n),
+			command,
+		)
+	}
+
+	params := &ApplyOpsParams{
+		Ops:                      make([]BulkWriteOp, len(raw.ApplyOps)),
+		BypassDocumentValidation: raw.BypassDocumentValidation,
+	}
+
+	for i, entry := range raw.ApplyOps {
+		if entry.Op == "n" {
+			continue
+		}
+
+		db, collection, ok := strings.Cut(entry.NS, ".")
+		if !ok || db == "" || collection == "" {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrInvalidNamespace,
+				fmt.Sprintf("Invalid namespace specified '%s'", entry.NS),
+				command,
+			)
+		}
+
+		op := BulkWriteOp{
+			DB:         db,
+			Collection: collection,
+		}
+
+		switch entry.Op {
+		case "i":
+			op.Type = BulkWriteInsert
+			op.Document = entry.O
+
+		case "u":
+			if entry.O2 == nil {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrMissingField,
+					fmt.Sprintf("applyOps entry %d of type 'u' is missing the required field 'o2'", i),
+					command,
+				)
+			}
+
+			update, err := applyOpsUpdate(command, i, entry.O)
+			if err != nil {
+				return nil, err
+			}
+
+			op.Type = BulkWriteUpdate
+			op.Filter = entry.O2
+			op.Update = update
+			op.Upsert = entry.B
+
+		case "d":
+			op.Type = BulkWriteDelete
+			op.Filter = entry.O
+
+		case "c":
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				fmt.Sprintf("applyOps entry %d: command oplog entries are not implemented yet", i),
+				command,
+			)
+
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFailedToParse,
+				fmt.Sprintf("applyOps entry %d has an invalid operation type '%s'", i, entry.Op),
+				command,
+			)
+		}
+
+		params.Ops[i] = op
+	}
+
+	return params, nil
+}
+
+// applyOpsUpdate returns the update document of the update oplog entry with the given index.
+//
+// Legacy `$v: 1` update documents are supported; delta (`$v: 2`) updates are not.
+func applyOpsUpdate(command string, i int, o *types.Document) (*types.Document, error) {
+	update := o
+
+	if o.Has("$v") {
+		if v, _ := commonparams.GetWholeNumberParam(must.NotFail(o.Get("$v"))); v != 1 {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				fmt.Sprintf("applyOps entry %d: only update oplog entries with '$v: 1' are supported", i),
+				command,
+			)
+		}
+
+		update = o.DeepCopy()
+		update.Remove("$v")
+	}
+
+	if err := ValidateUpdateOperators(command, update); err != nil {
+		return nil, err
+	}
+
+	return update, nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/clientconn/cursor"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+)
+
+// maxBatchSize is the maximum total BSON size of documents in a single batch
+// of find, aggregate and getMore replies.
+//
+// Like in MongoDB, that's the maximum document size;
+// the rest of the reply (cursor ID, namespace, etc.) is small enough to fit into the message.
+const maxBatchSize = types.MaxDocumentLen
+
+// awaitDataPollInterval is the interval between attempts to fetch new documents for tailable awaitData cursor.
+const awaitDataPollInterval = 100 * time.Millisecond
+
+// batch accumulates documents of a single batch, tracking their total size.
+type batch struct {
+	docs []*types.Document
+	size int
+}
+
+// add adds the document to the batch and returns true if it fits.
+// The first document always fits, even if it is larger than maxBatchSize.
+func (b *batch) add(doc *types.Document) bool {
+	// element type, array index as cstring, and the document itself
+	size := 1 + len(strconv.Itoa(len(b.docs))) + 1 + bson.Size(doc)
+
+	if len(b.docs) > 0 && b.size+size > maxBatchSize {
+		return false
+	}
+
+	b.docs = append(b.docs, doc)
+	b.size += size
+
+	return true
+}
+
+// ConsumeBatch returns up to n documents from the cursor for the first or the next batch.
+//
+// The total size of returned documents does not exceed maxBatchSize,
+// so the batch could be shorter than n even if the cursor is not exhausted.
+// The document that does not fit is returned by the cursor in the next batch.
+//
+// The returned boolean is true if the cursor is exhausted.
+// The cursor is closed in that case and on any error.
+// If the cursor's context deadline (set by maxTimeMS) is exceeded,
+// MaxTimeMSExpired error is returned.
+func ConsumeBatch(c *cursor.Cursor, n int) ([]*types.Document, bool, error) {
+	var b batch
+
+	for len(b.docs) < n {
+		_, doc, err := c.Next()
+		if err != nil {
+			c.Close()
+
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				return b.docs, true, nil
+			}
+
+			return nil, false, CheckMaxTimeMS(err)
+		}
+
+		if !b.add(doc) {
+			c.Unread(doc)
+			break
+		}
+	}
+
+	return b.docs, false, nil
+}
+
+// ConsumeTailable returns up to n documents from the tailable cursor.
+// Like ConsumeBatch, it does not return more documents than fit into a single batch.
+//
+// Unlike ConsumeBatch, it does not close the cursor when it is exhausted,
+// because new documents may be returned later.
+// If no documents are available and await is positive,
+// it polls the cursor for new documents until await elapses or ctx is done.
+// The cursor is closed on other errors.
+func ConsumeTailable(ctx context.Context, c *cursor.Cursor, n int, await time.Duration) ([]*types.Document, error) {
+	var b batch
+
+	deadline := time.Now().Add(await)
+
+	for len(b.docs) < n {
+		_, doc, err := c.Next()
+
+		switch {
+		case err == nil:
+			if !b.add(doc) {
+				c.Unread(doc)
+				return b.docs, nil
+			}
+
+			continue
+
+		case !errors.Is(err, iterator.ErrIteratorDone):
+			c.Close()
+			return nil, CheckMaxTimeMS(err)
+
+		case len(b.docs) > 0 || !time.Now().Before(deadline):
+			return b.docs, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return b.docs, nil
+		case <-time.After(awaitDataPollInterval):
+		}
+	}
+
+	return b.docs, nil
+}
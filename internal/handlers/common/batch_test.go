@@ -0,0 +1,108 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/cursor"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// largeDocument returns a document with the given _id and a string field of the given size.
+func largeDocument(id int32, size int) *types.Document {
+	return must.NotFail(types.NewDocument("_id", id, "v", strings.Repeat("x", size)))
+}
+
+func TestConsumeBatch(t *testing.T) {
+	t.Parallel()
+
+	// about 15.9 MB, so two documents do not fit into a single batch
+	const size = 15_900_000
+
+	r := cursor.NewRegistry(testutil.Logger(t))
+	t.Cleanup(r.Close)
+
+	docs := []*types.Document{
+		largeDocument(0, size),
+		largeDocument(1, size),
+		largeDocument(2, 10),
+		largeDocument(3, 10),
+		largeDocument(4, 10),
+	}
+
+	c := r.NewCursor(context.Background(), &cursor.NewParams{
+		Iter: iterator.Values(iterator.ForSlice(docs)),
+	})
+
+	res, done, err := ConsumeBatch(c, 101)
+	require.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, docs[:1], res)
+	assert.Equal(t, int64(1), c.Returned())
+
+	res, done, err = ConsumeBatch(c, 2)
+	require.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, docs[1:3], res)
+	assert.Equal(t, int64(3), c.Returned())
+
+	res, done, err = ConsumeBatch(c, 1)
+	require.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, docs[3:4], res)
+	assert.Equal(t, int64(4), c.Returned())
+
+	res, done, err = ConsumeBatch(c, 101)
+	require.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, docs[4:], res)
+	assert.Equal(t, int64(5), c.Returned())
+}
+
+func TestConsumeBatchTooLarge(t *testing.T) {
+	t.Parallel()
+
+	r := cursor.NewRegistry(testutil.Logger(t))
+	t.Cleanup(r.Close)
+
+	// the first document is returned even if it does not fit on its own
+	docs := []*types.Document{
+		largeDocument(0, types.MaxDocumentLen),
+		largeDocument(1, 10),
+	}
+
+	c := r.NewCursor(context.Background(), &cursor.NewParams{
+		Iter: iterator.Values(iterator.ForSlice(docs)),
+	})
+
+	res, done, err := ConsumeBatch(c, 101)
+	require.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, docs[:1], res)
+
+	res, done, err = ConsumeBatch(c, 101)
+	require.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, docs[1:], res)
+}
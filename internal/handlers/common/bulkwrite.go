@@ -0,0 +1,273 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// bulkWriteMaxOps is the maximum number of operations in a single bulkWrite command.
+const bulkWriteMaxOps = 100_000
+
+// BulkWriteOpType represents the type of a single bulkWrite operation.
+type BulkWriteOpType string
+
+// Supported bulkWrite operation types.
+const (
+	BulkWriteInsert = BulkWriteOpType("insert")
+	BulkWriteUpdate = BulkWriteOpType("update")
+	BulkWriteDelete = BulkWriteOpType("delete")
+)
+
+// BulkWriteParams represents parameters for the bulkWrite command.
+type BulkWriteParams struct {
+	Ops []BulkWriteOp
+
+	Ordered                  bool
+	BypassDocumentValidation bool
+	ErrorsOnly               bool
+}
+
+// BulkWriteOp represents a single bulkWrite operation with resolved namespace.
+type BulkWriteOp struct {
+	// Document to insert, set only for insert operations.
+	Document *types.Document
+
+	// Filter is set for update and delete operations.
+	Filter *types.Document
+
+	// Update is set only for update operations.
+	Update *types.Document
+
+	Hint any
+
+	Type       BulkWriteOpType
+	DB         string
+	Collection string
+
+	Multi  bool
+	Upsert bool
+}
+
+// bulkWriteParams represents raw parameters of the bulkWrite command.
+type bulkWriteParams struct {
+	DB        string                  `ferretdb:"$db"`
+	BulkWrite any                     `ferretdb:"collection,ignored"`
+	Ops       []bulkWriteOpParams     `ferretdb:"ops"`
+	NsInfo    []bulkWriteNsInfoParams `ferretdb:"nsInfo"`
+
+	Ordered                  bool `ferretdb:"ordered,opt"`
+	BypassDocumentValidation bool `ferretdb:"bypassDocumentValidation,opt"`
+	ErrorsOnly               bool `ferretdb:"errorsOnly,opt"`
+
+	Let *types.Document `ferretdb:"let,unimplemented"`
+
+	Comment          any             `ferretdb:"comment,ignored"`
+	Cursor           *types.Document `ferretdb:"cursor,ignored"`
+	WriteConcern     *types.Document `ferretdb:"writeConcern,ignored"`
+	LSID             any             `ferretdb:"lsid,ignored"`
+	TxnNumber        int64           `ferretdb:"txnNumber,ignored"`
+	StartTransaction bool            `ferretdb:"startTransaction,ignored"`
+	Autocommit       bool            `ferretdb:"autocommit,ignored"`
+}
+
+// bulkWriteOpParams represents raw parameters of a single bulkWrite operation.
+//
+// Exactly one of Insert, Update, or Delete must be set to the index of the nsInfo entry.
+type bulkWriteOpParams struct {
+	Insert any `ferretdb:"insert,opt"`
+	Update any `ferretdb:"update,opt"`
+	Delete any `ferretdb:"delete,opt"`
+
+	Document   *types.Document `ferretdb:"document,opt"`
+	Filter     *types.Document `ferretdb:"filter,opt"`
+	UpdateMods *types.Document `ferretdb:"updateMods,opt"`
+	Multi      bool            `ferretdb:"multi,opt"`
+	Upsert     bool            `ferretdb:"upsert,opt"`
+	Hint       any             `ferretdb:"hint,opt"`
+
+	ArrayFilters *types.Array    `ferretdb:"arrayFilters,unimplemented"`
+	Collation    *types.Document `ferretdb:"collation,unimplemented"`
+	Constants    *types.Document `ferretdb:"constants,unimplemented"`
+	Sort         *types.Document `ferretdb:"sort,unimplemented"`
+}
+
+// bulkWriteNsInfoParams represents a single nsInfo entry of the bulkWrite command.
+type bulkWriteNsInfoParams struct {
+	NS string `ferretdb:"ns"`
+
+	CollectionUUID        any             `ferretdb:"collectionUUID,ignored"`
+	EncryptionInformation *types.Document `ferretdb:"encryptionInformation,unimplemented"`
+	IsTimeseriesNamespace bool            `ferretdb:"isTimeseriesNamespace,ignored"`
+}
+
+// GetBulkWriteParams returns parameters for the bulkWrite command.
+//
+// Namespaces of all operations are resolved and validated.
+func GetBulkWriteParams(document *types.Document, l *zap.Logger) (*BulkWriteParams, error) {
+	command := document.Command()
+
+	raw := bulkWriteParams{
+		Ordered: true,
+	}
+
+	if err := commonparams.ExtractParams(document, command, &raw, l); err != nil {
+		return nil, err
+	}
+
+	if n := len(raw.Ops); n == 0 || n > bulkWriteMaxOps {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidLength,
+			fmt.Sprintf("Write batch sizes must be between 1 and %d. Got %d operations.", bulkWriteMaxOps, n),
+			command,
+		)
+	}
+
+	type namespace struct {
+		db         string
+		collection string
+	}
+
+	namespaces := make([]namespace, len(raw.NsInfo))
+
+	for i, ns := range raw.NsInfo {
+		db, collection, ok := strings.Cut(ns.NS, ".")
+		if !ok || db == "" || collection == "" {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrInvalidNamespace,
+				fmt.Sprintf("Invalid namespace specified '%s'", ns.NS),
+				command,
+			)
+		}
+
+		namespaces[i] = namespace{db: db, collection: collection}
+	}
+
+	params := &BulkWriteParams{
+		Ops:                      make([]BulkWriteOp, len(raw.Ops)),
+		Ordered:                  raw.Ordered,
+		BypassDocumentValidation: raw.BypassDocumentValidation,
+		ErrorsOnly:               raw.ErrorsOnly,
+	}
+
+	for i, op := range raw.Ops {
+		var t BulkWriteOpType
+		var nsIndex any
+
+		for _, v := range []struct {
+			t     BulkWriteOpType
+			index any
+		}{
+			{BulkWriteInsert, op.Insert},
+			{BulkWriteUpdate, op.Update},
+			{BulkWriteDelete, op.Delete},
+		} {
+			if v.index == nil {
+				continue
+			}
+
+			if t != "" {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrFailedToParse,
+					fmt.Sprintf("BulkWrite ops entry %d has more than one operation type", i),
+					command,
+				)
+			}
+
+			t, nsIndex = v.t, v.index
+		}
+
+		if t == "" {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFailedToParse,
+				fmt.Sprintf("BulkWrite ops entry %d must be an insert, update, or delete operation", i),
+				command,
+			)
+		}
+
+		index, err := commonparams.GetWholeNumberParam(nsIndex)
+		if err != nil || index < 0 || index >= int64(len(namespaces)) {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("BulkWrite ops entry %d has an invalid nsInfo index.", i),
+				command,
+			)
+		}
+
+		ns := namespaces[index]
+
+		res := BulkWriteOp{
+			Hint:       op.Hint,
+			Type:       t,
+			DB:         ns.db,
+			Collection: ns.collection,
+		}
+
+		switch t {
+		case BulkWriteInsert:
+			if op.Document == nil {
+				return nil, bulkWriteMissingField(command, "document")
+			}
+
+			res.Document = op.Document
+
+		case BulkWriteUpdate:
+			if op.Filter == nil {
+				return nil, bulkWriteMissingField(command, "filter")
+			}
+
+			if op.UpdateMods == nil {
+				return nil, bulkWriteMissingField(command, "updateMods")
+			}
+
+			if err = ValidateUpdateOperators(command, op.UpdateMods); err != nil {
+				return nil, err
+			}
+
+			res.Filter = op.Filter
+			res.Update = op.UpdateMods
+			res.Multi = op.Multi
+			res.Upsert = op.Upsert
+
+		case BulkWriteDelete:
+			if op.Filter == nil {
+				return nil, bulkWriteMissingField(command, "filter")
+			}
+
+			res.Filter = op.Filter
+			res.Multi = op.Multi
+		}
+
+		params.Ops[i] = res
+	}
+
+	return params, nil
+}
+
+// bulkWriteMissingField returns an error for the missing required field of bulkWrite operation.
+func bulkWriteMissingField(command, field string) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrMissingField,
+		fmt.Sprintf("BSON field '%s.ops.%s' is missing but a required field", command, field),
+		command,
+	)
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestGetBulkWriteParams(t *testing.T) {
+	t.Parallel()
+
+	nsInfo := must.NotFail(types.NewArray(
+		must.NotFail(types.NewDocument("ns", "db1.c1")),
+		must.NotFail(types.NewDocument("ns", "db2.c2")),
+	))
+
+	filter := must.NotFail(types.NewDocument("_id", int32(1)))
+	update := must.NotFail(types.NewDocument("$set", must.NotFail(types.NewDocument("v", int32(1)))))
+
+	for name, tc := range map[string]struct {
+		ops      *types.Array
+		expected []BulkWriteOp
+		code     commonerrors.ErrorCode
+	}{
+		"Valid": {
+			ops: must.NotFail(types.NewArray(
+				must.NotFail(types.NewDocument("insert", int32(0), "document", filter)),
+				must.NotFail(types.NewDocument(
+					"update", int32(1), "filter", filter, "updateMods", update, "multi", true, "upsert", true,
+				)),
+				must.NotFail(types.NewDocument("delete", int64(0), "filter", filter)),
+			)),
+			expected: []BulkWriteOp{{
+				Document: filter, Type: BulkWriteInsert, DB: "db1", Collection: "c1",
+			}, {
+				Filter: filter, Update: update, Type: BulkWriteUpdate, DB: "db2", Collection: "c2", Multi: true, Upsert: true,
+			}, {
+				Filter: filter, Type: BulkWriteDelete, DB: "db1", Collection: "c1",
+			}},
+		},
+		"Empty": {
+			ops:  must.NotFail(types.NewArray()),
+			code: commonerrors.ErrInvalidLength,
+		},
+		"NoType": {
+			ops:  must.NotFail(types.NewArray(must.NotFail(types.NewDocument("document", filter)))),
+			code: commonerrors.ErrFailedToParse,
+		},
+		"TwoTypes": {
+			ops: must.NotFail(types.NewArray(must.NotFail(types.NewDocument(
+				"insert", int32(0), "delete", int32(0), "document", filter, "filter", filter,
+			)))),
+			code: commonerrors.ErrFailedToParse,
+		},
+		"InvalidIndex": {
+			ops:  must.NotFail(types.NewArray(must.NotFail(types.NewDocument("insert", int32(2), "document", filter)))),
+			code: commonerrors.ErrBadValue,
+		},
+		"MissingUpdateMods": {
+			ops:  must.NotFail(types.NewArray(must.NotFail(types.NewDocument("update", int32(0), "filter", filter)))),
+			code: commonerrors.ErrMissingField,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			doc := must.NotFail(types.NewDocument(
+				"bulkWrite", int32(1),
+				"ops", tc.ops,
+				"nsInfo", nsInfo,
+				"$db", "admin",
+			))
+
+			params, err := GetBulkWriteParams(doc, zap.NewNop())
+			if tc.code != 0 {
+				var ce *commonerrors.CommandError
+				require.True(t, errors.As(err, &ce), "%v", err)
+				assert.Equal(t, tc.code, ce.Code())
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, params.Ordered)
+			assert.Equal(t, tc.expected, params.Ops)
+		})
+	}
+}
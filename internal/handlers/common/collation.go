@@ -0,0 +1,346 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// simpleLocale is the locale of the simple binary comparison of strings.
+const simpleLocale = "simple"
+
+// collationLevels maps collation strengths to Unicode locale extension values.
+var collationLevels = map[int32]string{
+	1: "level1",
+	2: "level2",
+	3: "level3",
+	4: "level4",
+	5: "identic",
+}
+
+// collationContextKey is a named unexported type for the safe use of context.WithValue.
+type collationContextKey struct{}
+
+// collationMatcher matches requested locales with ones supported by the collation tables.
+var collationMatcher = language.NewMatcher(collate.Supported())
+
+// collators caches pools of collators by locale tags with collation options.
+//
+// Creating a collator is expensive, and they are not safe for concurrent use,
+// so each pool contains collators with their buffers for the same tag.
+var collators sync.Map // string -> *sync.Pool of *collator
+
+// collator is a collate.Collator with its buffer for collation keys.
+type collator struct {
+	c   *collate.Collator
+	buf collate.Buffer
+}
+
+// collatorPool returns a cached pool of collators for the given tag.
+func collatorPool(tag language.Tag) *sync.Pool {
+	key := tag.String()
+
+	if pool, ok := collators.Load(key); ok {
+		return pool.(*sync.Pool)
+	}
+
+	pool := &sync.Pool{
+		New: func() any {
+			return &collator{
+				c: collate.New(tag, collate.OptionsFromTag(tag)),
+			}
+		},
+	}
+
+	res, _ := collators.LoadOrStore(key, pool)
+
+	return res.(*sync.Pool)
+}
+
+// Collation compares strings using language-specific rules.
+//
+// Nil value represents the simple binary comparison and is safe to use.
+type Collation struct {
+	locale          string
+	strength        int32
+	caseLevel       bool
+	numericOrdering bool
+
+	collators *sync.Pool
+}
+
+// GetCollation returns a collation for the given `collation` document.
+//
+// It returns nil for nil document and for the `simple` locale.
+func GetCollation(doc *types.Document) (*Collation, error) {
+	if doc == nil {
+		return nil, nil
+	}
+
+	c := &Collation{
+		strength: 3,
+	}
+
+	iter := doc.Iterator()
+	defer iter.Close()
+
+	var localeSet bool
+
+	for {
+		k, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		switch k {
+		case "locale":
+			if c.locale, err = getCollationParam[string](k, v); err != nil {
+				return nil, err
+			}
+
+			localeSet = true
+
+		case "strength":
+			strength, err := commonparams.GetWholeNumberParam(v)
+			if err != nil || strength < 1 || strength > 5 {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrBadValue,
+					"Field 'strength' must be an integer 1 through 5",
+					"collation",
+				)
+			}
+
+			c.strength = int32(strength)
+
+		case "caseLevel":
+			if c.caseLevel, err = getCollationParam[bool](k, v); err != nil {
+				return nil, err
+			}
+
+		case "numericOrdering":
+			if c.numericOrdering, err = getCollationParam[bool](k, v); err != nil {
+				return nil, err
+			}
+
+		case "version":
+			// only the current version is supported, and it is always returned
+
+		case "caseFirst", "alternate", "maxVariable", "normalization", "backwards":
+			if isDefaultCollationOption(k, v) {
+				continue
+			}
+
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				fmt.Sprintf("Collation option %q is not implemented yet", k),
+				"collation",
+			)
+
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field 'collation.%s' is an unknown field.", k),
+				"collation",
+			)
+		}
+	}
+
+	if !localeSet {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMissingField,
+			"BSON field 'locale' is missing but a required field",
+			"collation",
+		)
+	}
+
+	if c.locale == simpleLocale {
+		return nil, nil
+	}
+
+	tag, err := language.Parse(strings.ReplaceAll(c.locale, "_", "-"))
+	if err == nil {
+		_, _, confidence := collationMatcher.Match(tag)
+		if confidence == language.No {
+			err = fmt.Errorf("unsupported locale %q", c.locale)
+		}
+	}
+
+	if err != nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("Field 'locale' is invalid in: %s", types.FormatAnyValue(doc)),
+			"collation",
+		)
+	}
+
+	tag, err = tag.SetTypeForKey("ks", collationLevels[c.strength])
+
+	if err == nil && c.caseLevel {
+		tag, err = tag.SetTypeForKey("kc", "true")
+	}
+
+	if err == nil && c.numericOrdering {
+		tag, err = tag.SetTypeForKey("kn", "true")
+	}
+
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	c.collators = collatorPool(tag)
+
+	return c, nil
+}
+
+// WithCollation returns a new context with the given collation.
+//
+// It is used to pass the command's collation to aggregation stages.
+func WithCollation(ctx context.Context, c *Collation) context.Context {
+	return context.WithValue(ctx, collationContextKey{}, c)
+}
+
+// GetCollationFromContext returns the collation stored in ctx, or nil.
+func GetCollationFromContext(ctx context.Context) *Collation {
+	c, _ := ctx.Value(collationContextKey{}).(*Collation)
+	return c
+}
+
+// getCollationParam returns the value of the collation document field with the expected type.
+func getCollationParam[T bool | string](key string, v any) (T, error) {
+	res, ok := v.(T)
+	if !ok {
+		var zero T
+
+		return zero, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field 'collation.%s' is the wrong type '%s', expected type '%s'",
+				key, commonparams.AliasFromType(v), commonparams.AliasFromType(zero),
+			),
+			"collation",
+		)
+	}
+
+	return res, nil
+}
+
+// isDefaultCollationOption returns true if the given value of the collation option is the default one.
+func isDefaultCollationOption(key string, v any) bool {
+	switch key {
+	case "caseFirst":
+		return v == "off"
+	case "alternate":
+		return v == "non-ignorable"
+	case "maxVariable":
+		return v == "punct"
+	case "normalization", "backwards":
+		return v == false
+	default:
+		return false
+	}
+}
+
+// Document returns the collation document with all options,
+// like one returned by MongoDB for indexes.
+//
+// It returns nil for the simple binary comparison.
+func (c *Collation) Document() *types.Document {
+	if c == nil {
+		return nil
+	}
+
+	return must.NotFail(types.NewDocument(
+		"locale", c.locale,
+		"caseLevel", c.caseLevel,
+		"caseFirst", "off",
+		"strength", c.strength,
+		"numericOrdering", c.numericOrdering,
+		"alternate", "non-ignorable",
+		"maxVariable", "punct",
+		"normalization", false,
+		"backwards", false,
+		"version", "57.1",
+	))
+}
+
+// key returns the collation key of the given string.
+// Binary comparison of keys gives the same result as comparison of strings using collation.
+func (c *Collation) key(s string) string {
+	coll := c.collators.Get().(*collator)
+	defer c.collators.Put(coll)
+
+	defer coll.buf.Reset()
+
+	return string(coll.c.KeyFromString(&coll.buf, s))
+}
+
+// keyValue returns the given value with strings replaced by their collation keys,
+// including strings in nested documents and arrays.
+//
+// It returns the value as is for nil Collation.
+func (c *Collation) keyValue(v any) any {
+	if c == nil {
+		return v
+	}
+
+	switch v := v.(type) {
+	case string:
+		return c.key(v)
+
+	case *types.Document:
+		res := types.MakeDocument(v.Len())
+
+		for _, k := range v.Keys() {
+			res.Set(k, c.keyValue(must.NotFail(v.Get(k))))
+		}
+
+		return res
+
+	case *types.Array:
+		res := types.MakeArray(v.Len())
+
+		for i := 0; i < v.Len(); i++ {
+			res.Append(c.keyValue(must.NotFail(v.Get(i))))
+		}
+
+		return res
+
+	default:
+		return v
+	}
+}
+
+// compare compares values like types.Compare, but strings are compared using collation.
+func (c *Collation) compare(docValue, filterValue any) types.CompareResult {
+	return types.Compare(c.keyValue(docValue), c.keyValue(filterValue))
+}
@@ -0,0 +1,319 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestGetCollation(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		doc  *types.Document
+		code commonerrors.ErrorCode
+		nil  bool
+	}{
+		"Nil": {
+			nil: true,
+		},
+		"Simple": {
+			doc: must.NotFail(types.NewDocument("locale", "simple")),
+			nil: true,
+		},
+		"Locale": {
+			doc: must.NotFail(types.NewDocument("locale", "en_US", "strength", int32(2))),
+		},
+		"MissingLocale": {
+			doc:  must.NotFail(types.NewDocument("strength", int32(2))),
+			code: commonerrors.ErrMissingField,
+		},
+		"InvalidLocale": {
+			doc:  must.NotFail(types.NewDocument("locale", "xx_invalid")),
+			code: commonerrors.ErrBadValue,
+		},
+		"InvalidStrength": {
+			doc:  must.NotFail(types.NewDocument("locale", "en", "strength", int32(6))),
+			code: commonerrors.ErrBadValue,
+		},
+		"WrongType": {
+			doc:  must.NotFail(types.NewDocument("locale", "en", "caseLevel", "true")),
+			code: commonerrors.ErrTypeMismatch,
+		},
+		"UnknownField": {
+			doc:  must.NotFail(types.NewDocument("locale", "en", "foo", int32(1))),
+			code: commonerrors.ErrFailedToParseInput,
+		},
+		"DefaultOption": {
+			doc: must.NotFail(types.NewDocument("locale", "en", "caseFirst", "off")),
+		},
+		"NotImplementedOption": {
+			doc:  must.NotFail(types.NewDocument("locale", "en", "caseFirst", "upper")),
+			code: commonerrors.ErrNotImplemented,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := GetCollation(tc.doc)
+			if tc.code != 0 {
+				var ce *commonerrors.CommandError
+				require.ErrorAs(t, err, &ce)
+				assert.Equal(t, tc.code, ce.Code())
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.nil, c == nil)
+		})
+	}
+}
+
+func TestCollationCompare(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		doc      *types.Document
+		a, b     any
+		expected types.CompareResult
+	}{
+		"Simple": {
+			a:        "abc",
+			b:        "ABC",
+			expected: types.Greater,
+		},
+		"Strength1": {
+			doc:      must.NotFail(types.NewDocument("locale", "fr", "strength", int32(1))),
+			a:        "côte",
+			b:        "Cote",
+			expected: types.Equal,
+		},
+		"Strength2": {
+			doc:      must.NotFail(types.NewDocument("locale", "en", "strength", int32(2))),
+			a:        "abc",
+			b:        "ABC",
+			expected: types.Equal,
+		},
+		"Strength2Diacritics": {
+			doc:      must.NotFail(types.NewDocument("locale", "fr", "strength", int32(2))),
+			a:        "côte",
+			b:        "cote",
+			expected: types.Greater,
+		},
+		"Strength3": {
+			doc:      must.NotFail(types.NewDocument("locale", "en")),
+			a:        "abc",
+			b:        "ABC",
+			expected: types.Less,
+		},
+		"CaseLevel": {
+			doc:      must.NotFail(types.NewDocument("locale", "en", "strength", int32(1), "caseLevel", true)),
+			a:        "abc",
+			b:        "ABC",
+			expected: types.Less,
+		},
+		"NumericOrdering": {
+			doc:      must.NotFail(types.NewDocument("locale", "en", "numericOrdering", true)),
+			a:        "10",
+			b:        "9",
+			expected: types.Greater,
+		},
+		"Array": {
+			doc:      must.NotFail(types.NewDocument("locale", "en", "strength", int32(2))),
+			a:        must.NotFail(types.NewArray("abc", int32(42))),
+			b:        must.NotFail(types.NewArray("ABC", int32(42))),
+			expected: types.Equal,
+		},
+		"NonString": {
+			doc:      must.NotFail(types.NewDocument("locale", "en", "strength", int32(2))),
+			a:        int32(1),
+			b:        "a",
+			expected: types.Less,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := GetCollation(tc.doc)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, c.compare(tc.a, tc.b))
+		})
+	}
+}
+
+func TestCollationFilterSortDistinct(t *testing.T) {
+	t.Parallel()
+
+	c, err := GetCollation(must.NotFail(types.NewDocument("locale", "en", "strength", int32(2))))
+	require.NoError(t, err)
+
+	docs := []*types.Document{
+		must.NotFail(types.NewDocument("_id", int32(1), "v", "b")),
+		must.NotFail(types.NewDocument("_id", int32(2), "v", "A")),
+		must.NotFail(types.NewDocument("_id", int32(3), "v", "a")),
+		must.NotFail(types.NewDocument("_id", int32(4), "v", "B")),
+	}
+
+	filter := must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray("a"))))))
+
+	var matched []int32
+
+	for _, doc := range docs {
+		ok, err := filterDocument(doc, filter, c)
+		require.NoError(t, err)
+
+		if ok {
+			matched = append(matched, must.NotFail(doc.Get("_id")).(int32))
+		}
+	}
+
+	assert.Equal(t, []int32{2, 3}, matched)
+
+	sorted := make([]*types.Document, len(docs))
+	copy(sorted, docs)
+
+	// documents with equal collation keys are sorted by _id
+	require.NoError(t, SortDocuments(sorted, must.NotFail(types.NewDocument("v", int32(1), "_id", int32(1))), c))
+
+	var ids []int32
+	for _, doc := range sorted {
+		ids = append(ids, must.NotFail(doc.Get("_id")).(int32))
+	}
+
+	assert.Equal(t, []int32{2, 3, 1, 4}, ids)
+
+	iter := iterator.Values(iterator.ForSlice(docs))
+	defer iter.Close()
+
+	distinct, err := FilterDistinctValues(iter, "v", c)
+	require.NoError(t, err)
+	assert.Equal(t, 2, distinct.Len())
+}
+
+func TestCollationCache(t *testing.T) {
+	t.Parallel()
+
+	c1, err := GetCollation(must.NotFail(types.NewDocument("locale", "de", "strength", int32(2))))
+	require.NoError(t, err)
+
+	c2, err := GetCollation(must.NotFail(types.NewDocument("locale", "de", "strength", int32(2))))
+	require.NoError(t, err)
+
+	c3, err := GetCollation(must.NotFail(types.NewDocument("locale", "de", "strength", int32(1))))
+	require.NoError(t, err)
+
+	assert.Same(t, c1.collators, c2.collators)
+	assert.NotSame(t, c1.collators, c3.collators)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				assert.Equal(t, types.Equal, c1.compare("Straße", "STRAßE"))
+				assert.Equal(t, types.Less, c2.compare("a", "B"))
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// collationBenchmarkDocs returns documents with string values for benchmarks.
+func collationBenchmarkDocs(n int) []*types.Document {
+	docs := make([]*types.Document, n)
+	for i := range docs {
+		docs[i] = must.NotFail(types.NewDocument("_id", int32(i), "v", fmt.Sprintf("Value %d côte", (i*7919)%n)))
+	}
+
+	return docs
+}
+
+func BenchmarkGetCollation(b *testing.B) {
+	doc := must.NotFail(types.NewDocument("locale", "en", "strength", int32(2)))
+
+	var c *Collation
+	var err error
+
+	for i := 0; i < b.N; i++ {
+		c, err = GetCollation(doc)
+	}
+
+	b.StopTimer()
+
+	require.NoError(b, err)
+	require.NotNil(b, c)
+}
+
+func BenchmarkSortDocumentsCollation(b *testing.B) {
+	for _, locale := range []string{"simple", "en"} {
+		b.Run(locale, func(b *testing.B) {
+			c, err := GetCollation(must.NotFail(types.NewDocument("locale", locale)))
+			require.NoError(b, err)
+
+			docs := collationBenchmarkDocs(1000)
+			sortDoc := must.NotFail(types.NewDocument("v", int32(1)))
+			sorted := make([]*types.Document, len(docs))
+
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				copy(sorted, docs)
+
+				err = SortDocuments(sorted, sortDoc, c)
+			}
+
+			b.StopTimer()
+
+			require.NoError(b, err)
+		})
+	}
+}
+
+func BenchmarkFilterDocumentCollation(b *testing.B) {
+	c, err := GetCollation(must.NotFail(types.NewDocument("locale", "en", "strength", int32(2))))
+	require.NoError(b, err)
+
+	docs := collationBenchmarkDocs(1000)
+	filter := must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$gte", "value 500"))))
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, doc := range docs {
+			if _, err = filterDocument(doc, filter, c); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// CompactParams represents parameters for the compact command.
+type CompactParams struct {
+	DB         string `ferretdb:"$db"`
+	Collection string `ferretdb:"collection"`
+
+	Force  bool `ferretdb:"force,opt"`
+	DryRun bool `ferretdb:"dryRun,opt"`
+
+	FreeSpaceTargetMB int64           `ferretdb:"freeSpaceTargetMB,ignored"`
+	WriteConcern      *types.Document `ferretdb:"writeConcern,ignored"`
+	Comment           any             `ferretdb:"comment,ignored"`
+	LSID              any             `ferretdb:"lsid,ignored"`
+}
+
+// GetCompactParams returns the parameters for the compact command.
+func GetCompactParams(document *types.Document, l *zap.Logger) (*CompactParams, error) {
+	var compact CompactParams
+
+	err := commonparams.ExtractParams(document, "compact", &compact, l)
+	if err != nil {
+		return nil, err
+	}
+
+	return &compact, nil
+}
+
+// CompactReply returns the reply document for the compact command.
+//
+// For dry runs, the number of freed bytes is an estimate.
+func CompactReply(bytesFreed int64, dryRun bool) *types.Document {
+	field := "bytesFreed"
+	if dryRun {
+		field = "estimatedBytesFreed"
+	}
+
+	return must.NotFail(types.NewDocument(
+		field, bytesFreed,
+		"ok", float64(1),
+	))
+}
@@ -27,17 +27,21 @@ type CountParams struct {
 	DB         string          `ferretdb:"$db"`
 	Collection string          `ferretdb:"collection"`
 
-	Skip  int64 `ferretdb:"skip,opt,positiveNumber"`
-	Limit int64 `ferretdb:"limit,opt,positiveNumber"`
+	Skip      int64 `ferretdb:"skip,opt,positiveNumber"`
+	Limit     int64 `ferretdb:"limit,opt,positiveNumber"`
+	MaxTimeMS int64 `ferretdb:"maxTimeMS,opt,wholePositiveNumber"`
 
 	Collation *types.Document `ferretdb:"collation,unimplemented"`
 
 	Fields any `ferretdb:"fields,ignored"` // legacy MongoDB shell adds it, but it is never actually used
 
-	Hint        any             `ferretdb:"hint,ignored"`
-	ReadConcern *types.Document `ferretdb:"readConcern,ignored"`
-	Comment     string          `ferretdb:"comment,ignored"`
-	LSID        any             `ferretdb:"lsid,ignored"`
+	Hint             any             `ferretdb:"hint,opt"`
+	ReadConcern      *types.Document `ferretdb:"readConcern,ignored"`
+	Comment          string          `ferretdb:"comment,ignored"`
+	LSID             any             `ferretdb:"lsid,ignored"`
+	TxnNumber        int64           `ferretdb:"txnNumber,ignored"`
+	StartTransaction bool            `ferretdb:"startTransaction,ignored"`
+	Autocommit       bool            `ferretdb:"autocommit,ignored"`
 }
 
 // GetCountParams returns the parameters for the count command.
@@ -0,0 +1,109 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// CreateUserParams represents parameters of the createUser command.
+type CreateUserParams struct {
+	DB       string
+	Username string
+
+	// User is a new user document to be stored in UsersCollection.
+	User *types.Document
+}
+
+// GetCreateUserParams returns parameters of the createUser command,
+// including the new user document with salted credentials.
+func GetCreateUserParams(document *types.Document, l *zap.Logger) (*CreateUserParams, error) {
+	command := document.Command()
+
+	if err := Unimplemented(document, "authenticationRestrictions"); err != nil {
+		return nil, err
+	}
+
+	Ignored(document, l, "writeConcern", "digestPassword", "comment")
+
+	db, err := GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	username, err := GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	if username == "" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"User document needs 'user' field to be non-empty",
+			command,
+		)
+	}
+
+	password, err := GetRequiredParam[string](document, "pwd")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkPassword(command, password); err != nil {
+		return nil, err
+	}
+
+	roles, err := GetRequiredParam[*types.Array](document, "roles")
+	if err != nil {
+		return nil, err
+	}
+
+	if roles, err = userRoles(command, db, roles); err != nil {
+		return nil, err
+	}
+
+	mechanisms, err := GetOptionalParam[*types.Array](document, "mechanisms", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkMechanisms(command, mechanisms); err != nil {
+		return nil, err
+	}
+
+	customData, err := GetOptionalParam[*types.Document](document, "customData", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := MakeUser(db, username, password, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	if customData != nil {
+		user.Set("customData", customData)
+	}
+
+	return &CreateUserParams{
+		DB:       db,
+		Username: username,
+		User:     user,
+	}, nil
+}
@@ -0,0 +1,208 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/clientconn/cursor"
+	"github.com/FerretDB/FerretDB/internal/clientconn/operation"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// currentOpIgnoredFields contains currentOp command fields that are not used as a filter.
+var currentOpIgnoredFields = map[string]struct{}{
+	"$db":                  {},
+	"$readPreference":      {},
+	"$clusterTime":         {},
+	"lsid":                 {},
+	"comment":              {},
+	"apiVersion":           {},
+	"apiStrict":            {},
+	"apiDeprecationErrors": {},
+}
+
+// CurrentOp is a part of common implementation of the currentOp command.
+//
+// Active operations of all connections are returned from the operation registry.
+// If $all is true, idle cursors are also returned with their originating find or aggregate command
+// and its query shape hash, so getMore and killCursors could be correlated with them.
+// If $ownOps is true, only operations and cursors of the current user are returned.
+// Other fields of the command are used as a filter.
+func CurrentOp(ctx context.Context, msg *wire.OpMsg, registry *cursor.Registry) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	var all, ownOps bool
+	filter := types.MakeDocument(0)
+
+	for _, k := range document.Keys() {
+		v := must.NotFail(document.Get(k))
+
+		switch k {
+		case command:
+			// nothing
+		case "$all":
+			if all, err = commonparams.GetBoolOptionalParam(k, v); err != nil {
+				return nil, err
+			}
+		case "$ownOps":
+			if ownOps, err = commonparams.GetBoolOptionalParam(k, v); err != nil {
+				return nil, err
+			}
+		default:
+			if _, ok := currentOpIgnoredFields[k]; !ok {
+				filter.Set(k, v)
+			}
+		}
+	}
+
+	inprog := types.MakeArray(0)
+	username, _ := conninfo.Get(ctx).Auth()
+	now := time.Now()
+
+	for _, o := range operation.GetRegistry(ctx).All() {
+		if ownOps && o.Username != username {
+			continue
+		}
+
+		op := activeOp(o, now)
+
+		var matches bool
+		if matches, err = FilterDocument(op, filter); err != nil {
+			return nil, err
+		}
+
+		if matches {
+			inprog.Append(op)
+		}
+	}
+
+	if all {
+		cursors := registry.All()
+		slices.SortFunc(cursors, func(a, b *cursor.Cursor) int {
+			switch {
+			case a.ID < b.ID:
+				return -1
+			case a.ID > b.ID:
+				return 1
+			default:
+				return 0
+			}
+		})
+
+		for _, c := range cursors {
+			if ownOps && c.Username != username {
+				continue
+			}
+
+			op := idleCursorOp(c)
+
+			var matches bool
+			if matches, err = FilterDocument(op, filter); err != nil {
+				return nil, err
+			}
+
+			if matches {
+				inprog.Append(op)
+			}
+		}
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"inprog", inprog,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// activeOpTypes maps command names to values of the op field of currentOp entries.
+var activeOpTypes = map[string]string{
+	"find":    "query",
+	"getMore": "getmore",
+	"insert":  "insert",
+	"update":  "update",
+	"delete":  "remove",
+}
+
+// activeOp returns currentOp entry for the operation in progress.
+func activeOp(o *operation.Operation, now time.Time) *types.Document {
+	running := now.Sub(o.Started)
+
+	opType, ok := activeOpTypes[o.Command.Command()]
+	if !ok {
+		opType = "command"
+	}
+
+	res := must.NotFail(types.NewDocument(
+		"type", "op",
+		"active", true,
+		"opid", o.ID,
+		"secs_running", int64(running.Seconds()),
+		"microsecs_running", running.Microseconds(),
+		"op", opType,
+		"ns", o.NS(),
+		"command", o.Command,
+	))
+
+	if o.Client != "" {
+		res.Set("client", o.Client)
+	}
+
+	return res
+}
+
+// idleCursorOp returns currentOp entry for the idle cursor.
+func idleCursorOp(c *cursor.Cursor) *types.Document {
+	cursorDoc := must.NotFail(types.NewDocument(
+		"cursorId", c.ID,
+		"createdDate", c.Created(),
+		"tailable", c.Tailable,
+		"awaitData", c.AwaitData,
+	))
+
+	if c.OriginatingCommand != nil {
+		cursorDoc.Set("originatingCommand", c.OriginatingCommand)
+	}
+
+	res := must.NotFail(types.NewDocument(
+		"type", "idleCursor",
+		"ns", c.NS(),
+	))
+
+	if c.QueryShapeHash != "" {
+		res.Set("queryShapeHash", c.QueryShapeHash)
+	}
+
+	res.Set("cursor", cursorDoc)
+
+	return res
+}
@@ -26,14 +26,18 @@ type DeleteParams struct {
 	DB         string `ferretdb:"$db"`
 	Collection string `ferretdb:"collection"`
 
-	Comment string   `ferretdb:"comment,opt"`
-	Deletes []Delete `ferretdb:"deletes,opt"`
-	Ordered bool     `ferretdb:"ordered,opt"`
+	Comment   string   `ferretdb:"comment,opt"`
+	Deletes   []Delete `ferretdb:"deletes,opt"`
+	Ordered   bool     `ferretdb:"ordered,opt"`
+	MaxTimeMS int64    `ferretdb:"maxTimeMS,opt,wholePositiveNumber"`
 
 	Let *types.Document `ferretdb:"let,unimplemented"`
 
-	WriteConcern *types.Document `ferretdb:"writeConcern,ignored"`
-	LSID         any             `ferretdb:"lsid,ignored"`
+	WriteConcern     *types.Document `ferretdb:"writeConcern,ignored"`
+	LSID             any             `ferretdb:"lsid,ignored"`
+	TxnNumber        int64           `ferretdb:"txnNumber,ignored"`
+	StartTransaction bool            `ferretdb:"startTransaction,ignored"`
+	Autocommit       bool            `ferretdb:"autocommit,ignored"`
 }
 
 // Delete represents single delete operation parameters.
@@ -43,9 +47,9 @@ type Delete struct {
 	// TODO https://github.com/FerretDB/FerretDB/issues/2627
 	Comment string `ferretdb:"comment,opt"`
 
-	Collation *types.Document `ferretdb:"collation,unimplemented"`
+	Collation *types.Document `ferretdb:"collation,opt"`
 
-	Hint string `ferretdb:"hint,ignored"`
+	Hint any `ferretdb:"hint,opt"`
 }
 
 // GetDeleteParams returns parameters for delete operation.
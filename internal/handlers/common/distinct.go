@@ -40,10 +40,13 @@ type DistinctParams struct {
 
 	Query any `ferretdb:"query,opt"`
 
-	Collation *types.Document `ferretdb:"collation,unimplemented"`
+	Collation *types.Document `ferretdb:"collation,opt"`
 
-	ReadConcern *types.Document `ferretdb:"readConcern,ignored"`
-	LSID        any             `ferretdb:"lsid,ignored"`
+	ReadConcern      *types.Document `ferretdb:"readConcern,ignored"`
+	LSID             any             `ferretdb:"lsid,ignored"`
+	TxnNumber        int64           `ferretdb:"txnNumber,ignored"`
+	StartTransaction bool            `ferretdb:"startTransaction,ignored"`
+	Autocommit       bool            `ferretdb:"autocommit,ignored"`
 }
 
 // GetDistinctParams returns `distinct` command parameters.
@@ -87,9 +90,24 @@ func GetDistinctParams(document *types.Document, l *zap.Logger) (*DistinctParams
 //
 // If the key is found in the document, and the value is an array, each element of the array is added to the result.
 // Otherwise, the value itself is added to the result.
-func FilterDistinctValues(iter types.DocumentsIterator, key string) (*types.Array, error) {
+//
+// Values are deduplicated using the given collation; nil collation means binary comparison.
+// The first found value of equal ones is returned.
+func FilterDistinctValues(iter types.DocumentsIterator, key string, collation *Collation) (*types.Array, error) {
 	distinct := types.MakeArray(0)
 
+	// collation keys of distinct values
+	keys := types.MakeArray(0)
+
+	add := func(v any) {
+		k := collation.keyValue(v)
+
+		if !keys.Contains(k) {
+			keys.Append(k)
+			distinct.Append(v)
+		}
+	}
+
 	defer iter.Close()
 
 	for {
@@ -126,15 +144,11 @@ func FilterDistinctValues(iter types.DocumentsIterator, key string) (*types.Arra
 						return nil, lazyerrors.Error(err)
 					}
 
-					if !distinct.Contains(el) {
-						distinct.Append(el)
-					}
+					add(el)
 				}
 
 			default:
-				if !distinct.Contains(v) {
-					distinct.Append(v)
-				}
+				add(v)
 			}
 		}
 	}
@@ -0,0 +1,48 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// DropUserParams represents parameters of the dropUser command.
+type DropUserParams struct {
+	DB       string
+	Username string
+}
+
+// GetDropUserParams returns parameters of the dropUser command.
+func GetDropUserParams(document *types.Document, l *zap.Logger) (*DropUserParams, error) {
+	Ignored(document, l, "writeConcern", "comment")
+
+	db, err := GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	username, err := GetRequiredParam[string](document, document.Command())
+	if err != nil {
+		return nil, err
+	}
+
+	return &DropUserParams{
+		DB:       db,
+		Username: username,
+	}, nil
+}
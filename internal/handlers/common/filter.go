@@ -37,6 +37,22 @@ import (
 //
 // Passed arguments must not be modified.
 func FilterDocument(doc, filter *types.Document) (bool, error) {
+	return filterDocument(doc, filter, nil)
+}
+
+// FilterDocumentWithCollation is like FilterDocument, but strings are compared using the given collation;
+// nil collation means binary comparison.
+//
+// Passed arguments must not be modified.
+func FilterDocumentWithCollation(doc, filter *types.Document, c *Collation) (bool, error) {
+	return filterDocument(doc, filter, c)
+}
+
+// filterDocument returns true if given document satisfies given filter expression.
+// Strings are compared using the given collation; nil collation means binary comparison.
+//
+// Passed arguments must not be modified.
+func filterDocument(doc, filter *types.Document, c *Collation) (bool, error) {
 	iter := filter.Iterator()
 	defer iter.Close()
 
@@ -51,7 +67,7 @@ func FilterDocument(doc, filter *types.Document) (bool, error) {
 		}
 
 		// top-level filters are ANDed together
-		matches, err := filterDocumentPair(doc, filterKey, filterValue)
+		matches, err := filterDocumentPair(doc, filterKey, filterValue, c)
 		if err != nil {
 			return false, lazyerrors.Error(err)
 		}
@@ -97,7 +113,7 @@ func HasQueryOperator(filter *types.Document) (bool, error) {
 }
 
 // filterDocumentPair handles a single filter element key/value pair {filterKey: filterValue}.
-func filterDocumentPair(doc *types.Document, filterKey string, filterValue any) (bool, error) {
+func filterDocumentPair(doc *types.Document, filterKey string, filterValue any, c *Collation) (bool, error) {
 	var vals []any
 	filterSuffix := filterKey
 
@@ -125,7 +141,7 @@ func filterDocumentPair(doc *types.Document, filterKey string, filterValue any)
 
 	if strings.HasPrefix(filterKey, "$") {
 		// {$operator: filterValue}
-		return filterOperator(doc, filterKey, filterValue)
+		return filterOperator(doc, filterKey, filterValue, c)
 	}
 
 	switch filterValue := filterValue.(type) {
@@ -142,7 +158,7 @@ func filterDocumentPair(doc *types.Document, filterKey string, filterValue any)
 
 		for _, doc := range docs {
 			// {field: {expr}} or {field: {document}}
-			ok, err := filterFieldExpr(doc, filterKey, filterSuffix, filterValue)
+			ok, err := filterFieldExpr(doc, filterKey, filterSuffix, filterValue, c)
 			if err != nil {
 				return false, err
 			}
@@ -158,7 +174,7 @@ func filterDocumentPair(doc *types.Document, filterKey string, filterValue any)
 		}
 
 		for _, val := range vals {
-			if result := types.Compare(val, filterValue); result == types.Equal {
+			if result := c.compare(val, filterValue); result == types.Equal {
 				return true, nil
 			}
 		}
@@ -175,7 +191,7 @@ func filterDocumentPair(doc *types.Document, filterKey string, filterValue any)
 		}
 	default:
 		for _, val := range vals {
-			if result := types.Compare(val, filterValue); result == types.Equal {
+			if result := c.compare(val, filterValue); result == types.Equal {
 				return true, nil
 			}
 		}
@@ -186,7 +202,7 @@ func filterDocumentPair(doc *types.Document, filterKey string, filterValue any)
 }
 
 // filterOperator handles a top-level operator filter {$operator: filterValue}.
-func filterOperator(doc *types.Document, operator string, filterValue any) (bool, error) {
+func filterOperator(doc *types.Document, operator string, filterValue any, c *Collation) (bool, error) {
 	switch operator {
 	case "$and":
 		// {$and: [{expr1}, {expr2}, ...]}
@@ -221,7 +237,7 @@ func filterOperator(doc *types.Document, operator string, filterValue any) (bool
 		for i := 0; i < exprs.Len(); i++ {
 			expr := must.NotFail(exprs.Get(i)).(*types.Document)
 
-			matches, err := FilterDocument(doc, expr)
+			matches, err := filterDocument(doc, expr, c)
 			if err != nil {
 				return false, err
 			}
@@ -265,7 +281,7 @@ func filterOperator(doc *types.Document, operator string, filterValue any) (bool
 		for i := 0; i < exprs.Len(); i++ {
 			expr := must.NotFail(exprs.Get(i)).(*types.Document)
 
-			matches, err := FilterDocument(doc, expr)
+			matches, err := filterDocument(doc, expr, c)
 			if err != nil {
 				return false, err
 			}
@@ -309,7 +325,7 @@ func filterOperator(doc *types.Document, operator string, filterValue any) (bool
 		for i := 0; i < exprs.Len(); i++ {
 			expr := must.NotFail(exprs.Get(i)).(*types.Document)
 
-			matches, err := FilterDocument(doc, expr)
+			matches, err := filterDocument(doc, expr, c)
 			if err != nil {
 				return false, err
 			}
@@ -323,6 +339,31 @@ func filterOperator(doc *types.Document, operator string, filterValue any) (bool
 	case "$comment":
 		return true, nil
 
+	case "$where":
+		// FerretDB does not execute server-side JavaScript,
+		// so it behaves like MongoDB with scripting disabled
+		if _, ok := filterValue.(string); !ok {
+			return false, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				"$where got bad type",
+				operator,
+			)
+		}
+
+		return false, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"no globalScriptEngine in $where parsing",
+			operator,
+		)
+
+	case "$text":
+		// top-level $text is handled by TextIterator for find command
+		return false, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrNotImplemented,
+			"$text is not implemented in this context",
+			operator,
+		)
+
 	case "$expr":
 		return filterExprOperator(doc, must.NotFail(types.NewDocument(operator, filterValue)))
 	default:
@@ -369,7 +410,7 @@ func filterExprOperator(doc, filter *types.Document) (bool, error) {
 }
 
 // filterFieldExpr handles {field: {expr}} or {field: {document}} filter.
-func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *types.Document) (bool, error) {
+func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *types.Document, c *Collation) (bool, error) {
 	// check if both documents are empty
 	if expr.Len() == 0 {
 		fieldValue, err := doc.Get(filterSuffix)
@@ -383,9 +424,13 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 	}
 
 	for _, exprKey := range expr.Keys() {
-		if exprKey == "$options" {
+		switch exprKey {
+		case "$options":
 			// handled by $regex
 			continue
+		case "$minDistance", "$maxDistance":
+			// handled by $near and $nearSphere
+			continue
 		}
 
 		exprValue := must.NotFail(expr.Get(exprKey))
@@ -409,7 +454,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 
 		if !strings.HasPrefix(exprKey, "$") {
 			if documentValue, ok := fieldValue.(*types.Document); ok {
-				result := types.Compare(documentValue, expr)
+				result := c.compare(documentValue, expr)
 				return result == types.Equal, nil
 			}
 			return false, nil
@@ -421,12 +466,12 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 			switch exprValue := exprValue.(type) {
 			case *types.Document:
 				if fieldValue, ok := fieldValue.(*types.Document); ok {
-					result := types.Compare(exprValue, fieldValue)
+					result := c.compare(exprValue, fieldValue)
 					return result == types.Equal, nil
 				}
 				return false, nil
 			default:
-				result := types.Compare(fieldValue, exprValue)
+				result := c.compare(fieldValue, exprValue)
 				if result != types.Equal {
 					return false, nil
 				}
@@ -437,7 +482,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 			switch exprValue := exprValue.(type) {
 			case *types.Document:
 				if fieldValue, ok := fieldValue.(*types.Document); ok {
-					result := types.Compare(exprValue, fieldValue)
+					result := c.compare(exprValue, fieldValue)
 					return result != types.Equal, nil
 				}
 
@@ -449,7 +494,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 					exprKey,
 				)
 			default:
-				result := types.Compare(fieldValue, exprValue)
+				result := c.compare(fieldValue, exprValue)
 				if result == types.Equal {
 					return false, nil
 				}
@@ -475,7 +520,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 			// and results in Less. Other values "foo" and nil which are
 			// not number type are not considered for $gt comparison.
 
-			result := types.CompareOrderForOperator(fieldValue, exprValue, types.Descending)
+			result := types.CompareOrderForOperator(c.keyValue(fieldValue), c.keyValue(exprValue), types.Descending)
 			if result != types.Greater {
 				return false, nil
 			}
@@ -499,7 +544,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 			// Above compares the maximum number of array 41.5 to the filter 42,
 			// and results in Less. Other values "foo" and nil which are
 			// not number type are not considered for $gte comparison.
-			result := types.CompareOrderForOperator(fieldValue, exprValue, types.Descending)
+			result := types.CompareOrderForOperator(c.keyValue(fieldValue), c.keyValue(exprValue), types.Descending)
 			if result != types.Equal && result != types.Greater {
 				return false, nil
 			}
@@ -524,7 +569,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 			// and results in Less. Other values "foo" and nil which are
 			// not number type are not considered for $lt comparison.
 
-			result := types.CompareOrderForOperator(fieldValue, exprValue, types.Ascending)
+			result := types.CompareOrderForOperator(c.keyValue(fieldValue), c.keyValue(exprValue), types.Ascending)
 			if result != types.Less {
 				return false, nil
 			}
@@ -549,7 +594,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 			// and results in Less. Other values "foo" and nil which are
 			// not number type are not considered for $lt comparison.
 
-			result := types.CompareOrderForOperator(fieldValue, exprValue, types.Ascending)
+			result := types.CompareOrderForOperator(c.keyValue(fieldValue), c.keyValue(exprValue), types.Ascending)
 			if result != types.Equal && result != types.Less {
 				return false, nil
 			}
@@ -580,7 +625,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 					}
 
 					if fieldValue, ok := fieldValue.(*types.Document); ok {
-						if result := types.Compare(fieldValue, arrValue); result == types.Equal {
+						if result := c.compare(fieldValue, arrValue); result == types.Equal {
 							found = true
 						}
 					}
@@ -593,7 +638,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 						found = true
 					}
 				default:
-					result := types.Compare(fieldValue, arrValue)
+					result := c.compare(fieldValue, arrValue)
 					if result == types.Equal {
 						found = true
 					}
@@ -634,7 +679,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 					}
 
 					if fieldValue, ok := fieldValue.(*types.Document); ok {
-						if result := types.Compare(fieldValue, arrValue); result == types.Equal {
+						if result := c.compare(fieldValue, arrValue); result == types.Equal {
 							found = true
 						}
 					}
@@ -647,7 +692,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 						found = true
 					}
 				default:
-					result := types.Compare(fieldValue, arrValue)
+					result := c.compare(fieldValue, arrValue)
 					if result == types.Equal {
 						found = true
 					}
@@ -662,7 +707,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 			// {field: {$not: {expr}}}
 			switch exprValue := exprValue.(type) {
 			case *types.Document:
-				res, err := filterFieldExpr(doc, filterKey, filterSuffix, exprValue)
+				res, err := filterFieldExpr(doc, filterKey, filterSuffix, exprValue, c)
 				if res || err != nil {
 					return false, err
 				}
@@ -690,7 +735,7 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 
 		case "$elemMatch":
 			// {field: {$elemMatch: value}}
-			res, err := filterFieldExprElemMatch(doc, filterKey, filterSuffix, exprValue)
+			res, err := filterFieldExprElemMatch(doc, filterKey, filterSuffix, exprValue, c)
 			if !res || err != nil {
 				return false, err
 			}
@@ -704,35 +749,14 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 
 		case "$all":
 			// {field: {$all: [value, another_value, ...]}}
-			res, err := filterFieldExprAll(fieldValue, exprValue)
-			if !res || err != nil {
-				return false, err
-			}
-
-		case "$bitsAllClear":
-			// {field: {$bitsAllClear: value}}
-			res, err := filterFieldExprBitsAllClear(fieldValue, exprValue)
+			res, err := filterFieldExprAll(fieldValue, exprValue, c)
 			if !res || err != nil {
 				return false, err
 			}
 
-		case "$bitsAllSet":
-			// {field: {$bitsAllSet: value}}
-			res, err := filterFieldExprBitsAllSet(fieldValue, exprValue)
-			if !res || err != nil {
-				return false, err
-			}
-
-		case "$bitsAnyClear":
-			// {field: {$bitsAnyClear: value}}
-			res, err := filterFieldExprBitsAnyClear(fieldValue, exprValue)
-			if !res || err != nil {
-				return false, err
-			}
-
-		case "$bitsAnySet":
-			// {field: {$bitsAnySet: value}}
-			res, err := filterFieldExprBitsAnySet(fieldValue, exprValue)
+		case "$bitsAllClear", "$bitsAllSet", "$bitsAnyClear", "$bitsAnySet":
+			// {field: {$bitsAllClear: mask}} and other bitwise operators
+			res, err := filterFieldExprBits(exprKey, fieldValue, exprValue)
 			if !res || err != nil {
 				return false, err
 			}
@@ -758,6 +782,25 @@ func filterFieldExpr(doc *types.Document, filterKey, filterSuffix string, expr *
 				return false, err
 			}
 
+		case "$geoWithin", "$within":
+			// {field: {$geoWithin: {$geometry: value}}}
+			res, err := filterFieldExprGeoWithin(fieldValue, exprValue)
+			if !res || err != nil {
+				return false, err
+			}
+
+		case "$near", "$nearSphere":
+			// {field: {$near: {$geometry: value, $maxDistance: value}}}
+			near, err := newGeoNearFromFilter(exprKey, expr)
+			if err != nil {
+				return false, err
+			}
+
+			distance, _, ok := near.Distance(fieldValue)
+			if !ok || !near.Matches(distance) {
+				return false, nil
+			}
+
 		default:
 			return false, commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrBadValue,
@@ -924,7 +967,7 @@ func filterFieldExprSize(fieldValue any, sizeValue any) (bool, error) {
 // filterFieldExprAll handles {field: {$all: [value, another_value, ...]}} filter.
 // The main purpose of $all is to filter arrays.
 // It is possible to filter non-arrays: {field: {$all: [value]}}, but such statement is equivalent to {field: value}.
-func filterFieldExprAll(fieldValue any, allValue any) (bool, error) {
+func filterFieldExprAll(fieldValue any, allValue any, c *Collation) (bool, error) {
 	query, ok := allValue.(*types.Array)
 	if !ok {
 		return false, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrBadValue, "$all needs an array", "$all")
@@ -941,13 +984,13 @@ func filterFieldExprAll(fieldValue any, allValue any) (bool, error) {
 
 	case *types.Array:
 		// For arrays we check that the array contains all the elements of the query.
-		return value.ContainsAll(query), nil
+		return c.keyValue(value).(*types.Array).ContainsAll(c.keyValue(query).(*types.Array)), nil
 
 	default:
 		// For other types (scalars) we check that the value is equal to each scalar in the query.
 		// Example: value: 42, query: [42, 42] should give us `true`
 		for i := 0; i < query.Len(); i++ {
-			res := types.Compare(value, must.NotFail(query.Get(i)))
+			res := c.compare(value, must.NotFail(query.Get(i)))
 			if res != types.Equal {
 				return false, nil
 			}
@@ -956,9 +999,12 @@ func filterFieldExprAll(fieldValue any, allValue any) (bool, error) {
 	}
 }
 
-// filterFieldExprBitsAllClear handles {field: {$bitsAllClear: value}} filter.
-func filterFieldExprBitsAllClear(fieldValue, maskValue any) (bool, error) {
-	bitmask, err := getBinaryMaskParam("$bitsAllClear", maskValue)
+// filterFieldExprBits handles {field: {$bitsAllClear: value}}, {field: {$bitsAllSet: value}},
+// {field: {$bitsAnyClear: value}}, and {field: {$bitsAnySet: value}} filters.
+//
+// Only whole numbers and binary data values could match.
+func filterFieldExprBits(operator string, fieldValue, maskValue any) (bool, error) {
+	positions, err := getBitPositionsParam(operator, maskValue)
 	if err != nil {
 		return false, err
 	}
@@ -968,128 +1014,67 @@ func filterFieldExprBitsAllClear(fieldValue, maskValue any) (bool, error) {
 		if isInvalidBitwiseValue(value) {
 			return false, nil
 		}
-
-		return (^uint64(value) & bitmask) == bitmask, nil
-
-	case types.Binary:
-		// TODO https://github.com/FerretDB/FerretDB/issues/508
-		return false, commonerrors.NewCommandErrorMsgWithArgument(
-			commonerrors.ErrNotImplemented,
-			"BinData() not supported yet",
-			"$bitsAllClear",
-		)
-
-	case int32:
-		return (^uint64(value) & bitmask) == bitmask, nil
-
-	case int64:
-		return (^uint64(value) & bitmask) == bitmask, nil
-
+	case types.Binary, int32, int64:
+		// nothing
 	default:
 		return false, nil
 	}
-}
 
-// filterFieldExprBitsAllSet handles {field: {$bitsAllSet: value}} filter.
-func filterFieldExprBitsAllSet(fieldValue, maskValue any) (bool, error) {
-	bitmask, err := getBinaryMaskParam("$bitsAllSet", maskValue)
-	if err != nil {
-		return false, err
-	}
+	var set, clear int
 
-	switch value := fieldValue.(type) {
-	case float64:
-		if isInvalidBitwiseValue(value) {
-			return false, nil
+	for _, pos := range positions {
+		if getBit(fieldValue, pos) {
+			set++
+		} else {
+			clear++
 		}
+	}
 
-		return (uint64(value) & bitmask) == bitmask, nil
-
-	case types.Binary:
-		// TODO https://github.com/FerretDB/FerretDB/issues/508
-		return false, commonerrors.NewCommandErrorMsgWithArgument(
-			commonerrors.ErrNotImplemented,
-			"BinData() not supported yet",
-			"$bitsAllSet",
-		)
-
-	case int32:
-		return (uint64(value) & bitmask) == bitmask, nil
-
-	case int64:
-		return (uint64(value) & bitmask) == bitmask, nil
-
+	switch operator {
+	case "$bitsAllClear":
+		return set == 0, nil
+	case "$bitsAllSet":
+		return clear == 0, nil
+	case "$bitsAnyClear":
+		return clear > 0, nil
+	case "$bitsAnySet":
+		return set > 0, nil
 	default:
-		return false, nil
+		panic(fmt.Sprintf("unexpected bitwise operator %q", operator))
 	}
 }
 
-// filterFieldExprBitsAnyClear handles {field: {$bitsAnyClear: value}} filter.
-func filterFieldExprBitsAnyClear(fieldValue, maskValue any) (bool, error) {
-	bitmask, err := getBinaryMaskParam("$bitsAnyClear", maskValue)
-	if err != nil {
-		return false, err
-	}
+// getBit returns true if the bit at the given position of the value is set.
+//
+// Numbers are treated as 64-bit two's complement integers with the sign extended,
+// so bits at positions beyond 63 are equal to the sign bit.
+// Bits at positions beyond the length of binary data are clear.
+func getBit(value any, pos uint64) bool {
+	var n int64
 
-	switch value := fieldValue.(type) {
-	case float64:
-		if isInvalidBitwiseValue(value) {
-			return false, nil
+	switch value := value.(type) {
+	case types.Binary:
+		if pos >= uint64(len(value.B))*8 {
+			return false
 		}
 
-		return (^uint64(value) & bitmask) != 0, nil
-
-	case types.Binary:
-		// TODO https://github.com/FerretDB/FerretDB/issues/508
-		return false, commonerrors.NewCommandErrorMsgWithArgument(
-			commonerrors.ErrNotImplemented,
-			"BinData() not supported yet",
-			"$bitsAnyClear",
-		)
+		return value.B[pos/8]&(1<<(pos%8)) != 0
 
+	case float64:
+		n = int64(value)
 	case int32:
-		return (^uint64(value) & bitmask) != 0, nil
-
+		n = int64(value)
 	case int64:
-		return (^uint64(value) & bitmask) != 0, nil
-
+		n = value
 	default:
-		return false, nil
+		panic(fmt.Sprintf("unexpected type %T", value))
 	}
-}
 
-// filterFieldExprBitsAnySet handles {field: {$bitsAnySet: value}} filter.
-func filterFieldExprBitsAnySet(fieldValue, maskValue any) (bool, error) {
-	bitmask, err := getBinaryMaskParam("$bitsAnySet", maskValue)
-	if err != nil {
-		return false, err
+	if pos > 63 {
+		pos = 63
 	}
 
-	switch value := fieldValue.(type) {
-	case float64:
-		if isInvalidBitwiseValue(value) {
-			return false, nil
-		}
-
-		return (uint64(value) & bitmask) != 0, nil
-
-	case types.Binary:
-		// TODO https://github.com/FerretDB/FerretDB/issues/508
-		return false, commonerrors.NewCommandErrorMsgWithArgument(
-			commonerrors.ErrNotImplemented,
-			"BinData() not supported yet",
-			"$bitsAnySet",
-		)
-
-	case int32:
-		return (uint64(value) & bitmask) != 0, nil
-
-	case int64:
-		return (uint64(value) & bitmask) != 0, nil
-
-	default:
-		return false, nil
-	}
+	return n&(1<<pos) != 0
 }
 
 // isInvalidBitwiseValue returns true for an invalid value of float64
@@ -1107,7 +1092,15 @@ func isInvalidBitwiseValue(value float64) bool {
 
 // filterFieldMod handles {field: {$mod: [divisor, remainder]}} filter.
 func filterFieldMod(fieldValue, exprValue any) (bool, error) {
-	arr := exprValue.(*types.Array)
+	arr, ok := exprValue.(*types.Array)
+	if !ok {
+		return false, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			`malformed mod, needs to be an array`,
+			"$mod",
+		)
+	}
+
 	if arr.Len() < 2 {
 		return false, commonerrors.NewCommandErrorMsgWithArgument(
 			commonerrors.ErrBadValue,
@@ -1488,7 +1481,7 @@ func filterFieldValueByTypeCode(fieldValue any, code commonparams.TypeCode) (boo
 // filterFieldExprElemMatch handles {field: {$elemMatch: value}}.
 // Returns false if doc value is not an array.
 // TODO https://github.com/FerretDB/FerretDB/issues/364
-func filterFieldExprElemMatch(doc *types.Document, filterKey, filterSuffix string, exprValue any) (bool, error) {
+func filterFieldExprElemMatch(doc *types.Document, filterKey, filterSuffix string, exprValue any, c *Collation) (bool, error) {
 	expr, ok := exprValue.(*types.Document)
 	if !ok {
 		return false, commonerrors.NewCommandErrorMsgWithArgument(
@@ -1543,5 +1536,5 @@ func filterFieldExprElemMatch(doc *types.Document, filterKey, filterSuffix strin
 		return false, nil
 	}
 
-	return filterFieldExpr(doc, filterKey, filterSuffix, expr)
+	return filterFieldExpr(doc, filterKey, filterSuffix, expr, c)
 }
@@ -21,15 +21,17 @@ import (
 )
 
 // FilterIterator returns an iterator that filters out documents that don't match the filter.
+// Strings are compared using the given collation; nil collation means binary comparison.
 // It will be added to the given closer.
 //
 // Next method returns the next document that matches the filter.
 //
 // Close method closes the underlying iterator.
-func FilterIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser, filter *types.Document) types.DocumentsIterator {
+func FilterIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser, filter *types.Document, collation *Collation) types.DocumentsIterator { //nolint:lll // for readability
 	res := &filterIterator{
-		iter:   iter,
-		filter: filter,
+		iter:      iter,
+		filter:    filter,
+		collation: collation,
 	}
 	closer.Add(res)
 
@@ -38,8 +40,9 @@ func FilterIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser,
 
 // filterIterator is returned by FilterIterator.
 type filterIterator struct {
-	iter   types.DocumentsIterator
-	filter *types.Document
+	iter      types.DocumentsIterator
+	filter    *types.Document
+	collation *Collation
 }
 
 // Next implements iterator.Interface. See FilterIterator for details.
@@ -52,7 +55,7 @@ func (iter *filterIterator) Next() (struct{}, *types.Document, error) {
 			return unused, nil, lazyerrors.Error(err)
 		}
 
-		matches, err := FilterDocument(doc, iter.filter)
+		matches, err := filterDocument(doc, iter.filter, iter.collation)
 		if err != nil {
 			return unused, nil, lazyerrors.Error(err)
 		}
@@ -22,6 +22,7 @@ import (
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 // FindParams represents parameters for the find command.
@@ -40,23 +41,47 @@ type FindParams struct {
 	Comment     string          `ferretdb:"comment,opt"`
 	MaxTimeMS   int64           `ferretdb:"maxTimeMS,opt,wholePositiveNumber"`
 
-	Collation *types.Document `ferretdb:"collation,unimplemented"`
+	// `{$natural: <order>}` hint forces a collection scan;
+	// other hints select the index by its name or key pattern.
+	Hint any `ferretdb:"hint,opt"`
+
+	ReturnKey    bool            `ferretdb:"returnKey,opt"`
+	ShowRecordId bool            `ferretdb:"showRecordId,opt"`
+	Min          *types.Document `ferretdb:"min,opt"`
+	Max          *types.Document `ferretdb:"max,opt"`
+
+	// Natural is set if documents should be returned in natural order (if Ascending) or
+	// reverse natural order (if Descending) because of `{$natural: <order>}` sort or hint.
+	// Sort is nil in that case.
+	Natural types.SortType `ferretdb:"-"`
+
+	// CollectionScan is set if `{$natural: <order>}` hint forces a collection scan.
+	CollectionScan bool `ferretdb:"-"`
+
+	Collation *types.Document `ferretdb:"collation,opt"`
 	Let       *types.Document `ferretdb:"let,unimplemented"`
 
-	AllowDiskUse bool            `ferretdb:"allowDiskUse,ignored"`
-	ReadConcern  *types.Document `ferretdb:"readConcern,ignored"`
-	Max          *types.Document `ferretdb:"max,ignored"`
-	Min          *types.Document `ferretdb:"min,ignored"`
-	Hint         any             `ferretdb:"hint,ignored"`
-	LSID         any             `ferretdb:"lsid,ignored"`
-
-	ReturnKey           bool `ferretdb:"returnKey,unimplemented-non-default"`
-	ShowRecordId        bool `ferretdb:"showRecordId,unimplemented-non-default"`
-	Tailable            bool `ferretdb:"tailable,unimplemented-non-default"`
-	OplogReplay         bool `ferretdb:"oplogReplay,unimplemented-non-default"`
-	NoCursorTimeout     bool `ferretdb:"noCursorTimeout,unimplemented-non-default"`
-	AwaitData           bool `ferretdb:"awaitData,unimplemented-non-default"`
-	AllowPartialResults bool `ferretdb:"allowPartialResults,unimplemented-non-default"`
+	// RequestResumeToken makes cursor replies include postBatchResumeToken;
+	// the collection scan could be resumed after that position with ResumeAfter.
+	RequestResumeToken bool         `ferretdb:"$_requestResumeToken,opt"`
+	ResumeAfterToken   any          `ferretdb:"$_resumeAfter,opt"`
+	ResumeAfter        *ResumeToken `ferretdb:"-"`
+
+	AllowDiskUse     bool            `ferretdb:"allowDiskUse,ignored"`
+	ReadConcern      *types.Document `ferretdb:"readConcern,ignored"`
+	LSID             any             `ferretdb:"lsid,ignored"`
+	TxnNumber        int64           `ferretdb:"txnNumber,ignored"`
+	StartTransaction bool            `ferretdb:"startTransaction,ignored"`
+	Autocommit       bool            `ferretdb:"autocommit,ignored"`
+
+	Tailable        bool `ferretdb:"tailable,opt"`
+	OplogReplay     bool `ferretdb:"oplogReplay,unimplemented-non-default"`
+	NoCursorTimeout bool `ferretdb:"noCursorTimeout,unimplemented-non-default"`
+	AwaitData       bool `ferretdb:"awaitData,opt"`
+
+	// All results are always returned without sharding,
+	// so cursor replies never set partialResultsReturned, like MongoDB's mongod.
+	AllowPartialResults bool `ferretdb:"allowPartialResults,ignored"`
 }
 
 // GetFindParams returns `find` command parameters.
@@ -78,5 +103,186 @@ func GetFindParams(doc *types.Document, l *zap.Logger) (*FindParams, error) {
 		return nil, err
 	}
 
+	if err = checkFindOptions(&params); err != nil {
+		return nil, err
+	}
+
+	if err = setNaturalOrder(&params); err != nil {
+		return nil, err
+	}
+
+	if err = setResumeAfter(&params); err != nil {
+		return nil, err
+	}
+
 	return &params, nil
 }
+
+// setResumeAfter checks resume token parameters and sets ResumeAfter.
+//
+// Like in MongoDB, resume tokens could be used only for collection scans in natural order.
+func setResumeAfter(params *FindParams) error {
+	if params.RequestResumeToken && (!params.CollectionScan || params.Natural != types.Ascending) {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"$_requestResumeToken can only be used if hint: {$natural:1} is also specified",
+			"find",
+		)
+	}
+
+	if params.ResumeAfterToken == nil {
+		return nil
+	}
+
+	if !params.RequestResumeToken {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"$_resumeAfter can only be set if $_requestResumeToken is true",
+			"find",
+		)
+	}
+
+	var err error
+	params.ResumeAfter, err = GetResumeToken(params.ResumeAfterToken, "$_resumeAfter", "find")
+
+	return err
+}
+
+// setNaturalOrder sets Natural and CollectionScan parameters from `{$natural: <order>}` sort and hint.
+func setNaturalOrder(params *FindParams) error {
+	natural, err := GetNaturalSortOrder(params.Sort)
+	if err != nil {
+		return err
+	}
+
+	if natural != 0 {
+		params.Natural = natural
+		params.Sort = nil
+	}
+
+	hint, ok := params.Hint.(*types.Document)
+	if !ok {
+		return nil
+	}
+
+	if natural, err = GetNaturalSortOrder(hint); err != nil {
+		return err
+	}
+
+	if natural == 0 {
+		return nil
+	}
+
+	params.CollectionScan = true
+
+	// sort takes precedence over hint order
+	if params.Natural == 0 && params.Sort.Len() == 0 {
+		params.Natural = natural
+	}
+
+	return nil
+}
+
+// checkFindOptions returns protocol error for invalid combinations of find options,
+// with the same codes, messages, and precedence as MongoDB.
+func checkFindOptions(params *FindParams) error {
+	if err := checkMinMax(params); err != nil {
+		return err
+	}
+
+	if params.AwaitData && !params.Tailable {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFailedToParse,
+			"Cannot set 'awaitData' without also setting 'tailable'",
+			"find",
+		)
+	}
+
+	if !params.Tailable {
+		return nil
+	}
+
+	if params.Sort != nil && params.Sort.Len() > 0 {
+		naturalSort := params.Sort.Len() == 1 && params.Sort.Has("$natural")
+
+		if naturalSort {
+			v := must.NotFail(params.Sort.Get("$natural"))
+			n, err := commonparams.GetWholeNumberParam(v)
+			naturalSort = err == nil && n == 1
+		}
+
+		if !naturalSort {
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				"cannot use tailable option with a sort other than {$natural: 1}",
+				"find",
+			)
+		}
+	}
+
+	if params.SingleBatch {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"cannot use tailable option with the 'singleBatch' option",
+			"find",
+		)
+	}
+
+	return nil
+}
+
+// NewTailableNonCappedError returns an error for tailable find on a collection that is not capped.
+// Handlers should return it after checking the collection.
+func NewTailableNonCappedError(params *FindParams) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrBadValue,
+		"error processing query: ns="+params.DB+"."+params.Collection+
+			" tailable cursor requested on non capped collection",
+		"find",
+	)
+}
+
+// checkMinMax returns protocol error for invalid min and max find options.
+func checkMinMax(params *FindParams) error {
+	if params.Min == nil && params.Max == nil {
+		return nil
+	}
+
+	// `{$natural: <order>}` hint does not select an index
+	hint, _ := params.Hint.(*types.Document)
+
+	if params.Hint == nil || (hint != nil && hint.Has("$natural")) {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMinMaxNoHint,
+			"When using min()/max() a hint of which index to use must be specified",
+			"find",
+		)
+	}
+
+	if params.Min == nil || params.Max == nil {
+		return nil
+	}
+
+	minKeys, maxKeys := params.Min.Keys(), params.Max.Keys()
+
+	if len(minKeys) != len(maxKeys) {
+		return newMinMaxFieldsMismatchError()
+	}
+
+	for i := range minKeys {
+		if minKeys[i] != maxKeys[i] {
+			return newMinMaxFieldsMismatchError()
+		}
+	}
+
+	return nil
+}
+
+// newMinMaxFieldsMismatchError returns an error for min and max find options with different field names.
+func newMinMaxFieldsMismatchError() error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrMinMaxFieldsMismatch,
+		"min() and max() must have the same field names",
+		"find",
+	)
+}
@@ -59,15 +59,22 @@ type FindAndModifyParams struct {
 
 	HasUpdateOperators bool `ferretdb:"-"`
 
-	Let          *types.Document `ferretdb:"let,unimplemented"`
-	Collation    *types.Document `ferretdb:"collation,unimplemented"`
-	Fields       *types.Document `ferretdb:"fields,unimplemented"`
-	ArrayFilters *types.Array    `ferretdb:"arrayFilters,unimplemented"`
+	ArrayFilters *types.Array    `ferretdb:"arrayFilters,opt"`
+	Fields       *types.Document `ferretdb:"fields,opt"`
+
+	Projection          *types.Document `ferretdb:"-"`
+	ProjectionInclusion bool            `ferretdb:"-"`
+
+	Let       *types.Document `ferretdb:"let,unimplemented"`
+	Collation *types.Document `ferretdb:"collation,unimplemented"`
 
 	Hint                     string          `ferretdb:"hint,ignored"`
 	WriteConcern             *types.Document `ferretdb:"writeConcern,ignored"`
-	BypassDocumentValidation bool            `ferretdb:"bypassDocumentValidation,ignored"`
+	BypassDocumentValidation bool            `ferretdb:"bypassDocumentValidation,opt"`
 	LSID                     any             `ferretdb:"lsid,ignored"`
+	TxnNumber                int64           `ferretdb:"txnNumber,ignored"`
+	StartTransaction         bool            `ferretdb:"startTransaction,ignored"`
+	Autocommit               bool            `ferretdb:"autocommit,ignored"`
 }
 
 // UpsertParams represents parameters for upsert, if the document exists UpdateParams is set.
@@ -115,17 +122,23 @@ func GetFindAndModifyParams(doc *types.Document, l *zap.Logger) (*FindAndModifyP
 		)
 	}
 
+	if params.Fields != nil {
+		if params.Projection, params.ProjectionInclusion, err = ValidateProjection(params.Fields); err != nil {
+			return nil, err
+		}
+	}
+
 	if params.UpdateValue != nil {
 		switch updateParam := params.UpdateValue.(type) {
 		case *types.Document:
 			params.Update = updateParam
 		case *types.Array:
 			// TODO aggregation pipeline stages metrics
-			return nil, commonerrors.NewCommandErrorMsgWithArgument(
-				commonerrors.ErrNotImplemented,
-				"Aggregation pipelines are not supported yet",
-				"update",
-			)
+			if err = ValidateUpdatePipeline("findAndModify", updateParam); err != nil {
+				return nil, err
+			}
+
+			params.Aggregation = updateParam
 		default:
 			return nil, commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrFailedToParse,
@@ -135,7 +148,7 @@ func GetFindAndModifyParams(doc *types.Document, l *zap.Logger) (*FindAndModifyP
 		}
 	}
 
-	if params.Update != nil && params.Remove {
+	if (params.Update != nil || params.Aggregation != nil) && params.Remove {
 		return nil, commonerrors.NewCommandErrorMsg(
 			commonerrors.ErrFailedToParse,
 			"Cannot specify both an update and remove=true",
@@ -156,9 +169,59 @@ func GetFindAndModifyParams(doc *types.Document, l *zap.Logger) (*FindAndModifyP
 
 	params.HasUpdateOperators = hasUpdateOperators
 
+	if params.ArrayFilters != nil {
+		if params.Remove {
+			return nil, commonerrors.NewCommandErrorMsg(
+				commonerrors.ErrFailedToParse,
+				"Cannot specify arrayFilters and remove=true",
+			)
+		}
+
+		if params.Aggregation != nil {
+			return nil, commonerrors.NewCommandErrorMsg(
+				commonerrors.ErrFailedToParse,
+				"Cannot specify arrayFilters and a pipeline update",
+			)
+		}
+	}
+
+	if params.Update != nil {
+		if err = ValidateArrayFilters("findAndModify", params.ArrayFilters, params.Update); err != nil {
+			return nil, err
+		}
+	}
+
 	return &params, nil
 }
 
+// UpdateDocumentWithParams returns a copy of the given document with update operators
+// or aggregation pipeline from params applied.
+// It must not be called for replacement updates.
+func UpdateDocumentWithParams(command string, doc *types.Document, params *FindAndModifyParams) (*types.Document, error) {
+	if params.Aggregation != nil {
+		return UpdateDocumentWithPipeline(command, doc, params.Aggregation)
+	}
+
+	res := doc.DeepCopy()
+
+	if _, err := UpdateDocumentWithArrayFilters(command, res, params.Update, params.ArrayFilters); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ProjectFindAndModifyValue applies the fields projection from params to the value returned by findAndModify command.
+// Values other than documents (null) are returned as is.
+func ProjectFindAndModifyValue(value any, params *FindAndModifyParams) (any, error) {
+	doc, ok := value.(*types.Document)
+	if !ok || params.Projection == nil {
+		return value, nil
+	}
+
+	return ProjectDocument(doc, params.Projection, params.Query, params.ProjectionInclusion)
+}
+
 // PrepareDocumentForUpsert prepares the document used for upsert operation.
 // If docs is empty it prepares a document for insert using params.
 // Otherwise, it takes the first document of docs and prepare document for update.
@@ -200,11 +263,29 @@ func PrepareDocumentForUpsert(docs []*types.Document, params *FindAndModifyParam
 func prepareDocumentForInsert(params *FindAndModifyParams) (*types.Document, error) {
 	insert := must.NotFail(types.NewDocument())
 
-	if params.HasUpdateOperators {
-		if _, err := UpdateDocument("findAndModify", insert, params.Update); err != nil {
+	var err error
+
+	switch {
+	case params.Aggregation != nil:
+		var id any
+
+		// _id is set before applying pipeline, so it could be used by pipeline expressions
+		if id, err = getUpsertID(params.Query); err != nil {
 			return nil, err
 		}
-	} else {
+
+		insert.Set("_id", id)
+
+		if insert, err = UpdateDocumentWithParams("findAndModify", insert, params); err != nil {
+			return nil, err
+		}
+
+	case params.HasUpdateOperators:
+		if insert, err = UpdateDocumentWithParams("findAndModify", insert, params); err != nil {
+			return nil, err
+		}
+
+	default:
 		insert = params.Update
 	}
 
@@ -222,16 +303,12 @@ func prepareDocumentForInsert(params *FindAndModifyParams) (*types.Document, err
 
 // prepareDocumentForUpdate takes the first document of docs and apply update params.
 func prepareDocumentForUpdate(docs []*types.Document, params *FindAndModifyParams) (*types.Document, error) {
-	update := docs[0].DeepCopy()
-
-	if params.HasUpdateOperators {
-		if _, err := UpdateDocument("findAndModify", update, params.Update); err != nil {
-			return nil, err
-		}
-
-		return update, nil
+	if params.Aggregation != nil || params.HasUpdateOperators {
+		return UpdateDocumentWithParams("findAndModify", docs[0], params)
 	}
 
+	update := docs[0].DeepCopy()
+
 	for _, k := range params.Update.Keys() {
 		v := must.NotFail(params.Update.Get(k))
 		update.Set(k, v)
@@ -0,0 +1,102 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/fsynclock"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// Fsync is a part of common implementation of the fsync command.
+//
+// The checkpoint function should flush pending writes of the backend to the persistent storage.
+// If the lock option is true, the server is locked against writes
+// until the fsyncUnlock command is called.
+func Fsync(ctx context.Context, msg *wire.OpMsg, checkpoint func(context.Context) error) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	db, err := GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if db != "admin" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	var lock bool
+
+	if v, _ := document.Get("lock"); v != nil {
+		if lock, err = commonparams.GetBoolOptionalParam("lock", v); err != nil {
+			return nil, err
+		}
+	}
+
+	var resDoc *types.Document
+
+	if !lock {
+		if err = checkpoint(ctx); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		resDoc = must.NotFail(types.NewDocument(
+			"numFiles", int32(1),
+			"ok", float64(1),
+		))
+	} else {
+		l := fsynclock.GetLock(ctx)
+
+		// wait for write commands in progress before checkpointing
+		count, err := l.Lock(ctx)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if err = checkpoint(ctx); err != nil {
+			l.Unlock()
+			return nil, lazyerrors.Error(err)
+		}
+
+		resDoc = must.NotFail(types.NewDocument(
+			"info", "now locked against writes, use db.fsyncUnlock() to unlock",
+			"lockCount", count,
+			"seeAlso", "http://dochub.mongodb.org/core/fsynccommand",
+			"ok", float64(1),
+		))
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{resDoc},
+	}))
+
+	return &reply, nil
+}
@@ -0,0 +1,785 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonpath"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// earthRadius is the radius of the Earth in meters used by MongoDB for spherical distances.
+const earthRadius = 6378100.0
+
+// geoPoint represents a single position: longitude and latitude, or x and y for legacy coordinates.
+type geoPoint struct {
+	x, y float64
+}
+
+// geoUnits represents units of geospatial distances.
+type geoUnits int
+
+const (
+	// geoUnitsPlanar is a flat distance in legacy coordinate units.
+	geoUnitsPlanar geoUnits = iota
+
+	// geoUnitsRadians is a spherical distance in radians.
+	geoUnitsRadians
+
+	// geoUnitsMeters is a spherical distance in meters.
+	geoUnitsMeters
+)
+
+// distance returns the distance between two points in the given units.
+func (u geoUnits) distance(a, b geoPoint) float64 {
+	if u == geoUnitsPlanar {
+		return math.Hypot(a.x-b.x, a.y-b.y)
+	}
+
+	lat1, lat2 := a.y*math.Pi/180, b.y*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.x - a.x) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	d := 2 * math.Asin(math.Min(1, math.Sqrt(h)))
+
+	if u == geoUnitsMeters {
+		d *= earthRadius
+	}
+
+	return d
+}
+
+// GeoNear represents parameters of proximity queries:
+// $near and $nearSphere query operators and $geoNear aggregation stage.
+type GeoNear struct {
+	point       geoPoint
+	units       geoUnits
+	minDistance float64
+	maxDistance float64
+}
+
+// NewGeoNear returns proximity query parameters for the given point.
+//
+// The point could be a GeoJSON point or a legacy coordinate pair.
+// Distances of GeoJSON points are always spherical and measured in meters;
+// distances of legacy coordinate pairs are measured in radians if spherical is true,
+// and in coordinate units otherwise.
+// Nil minDistance and maxDistance values are not checked.
+func NewGeoNear(point any, spherical bool, minDistance, maxDistance any) (*GeoNear, error) {
+	res := &GeoNear{
+		minDistance: 0,
+		maxDistance: math.Inf(1),
+	}
+
+	var err error
+
+	if d, ok := point.(*types.Document); ok && d.Has("type") {
+		if res.point, err = parseGeoJSONPoint(d); err != nil {
+			return nil, err
+		}
+
+		res.units = geoUnitsMeters
+	} else {
+		p, ok := legacyPoint(point)
+		if !ok {
+			return nil, commonerrors.NewCommandErrorMsg(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("invalid argument in geo near query: %s", types.FormatAnyValue(point)),
+			)
+		}
+
+		res.point = p
+
+		if spherical {
+			res.units = geoUnitsRadians
+		}
+	}
+
+	for _, d := range []struct {
+		name  string
+		value any
+		dst   *float64
+	}{
+		{"minDistance", minDistance, &res.minDistance},
+		{"maxDistance", maxDistance, &res.maxDistance},
+	} {
+		if d.value == nil {
+			continue
+		}
+
+		f, ok := geoNumber(d.value)
+		if !ok {
+			return nil, commonerrors.NewCommandErrorMsg(
+				commonerrors.ErrTypeMismatch,
+				fmt.Sprintf("%s must be a number", d.name),
+			)
+		}
+
+		if f < 0 || math.IsNaN(f) {
+			return nil, commonerrors.NewCommandErrorMsg(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("%s must be non-negative", d.name),
+			)
+		}
+
+		*d.dst = f
+	}
+
+	return res, nil
+}
+
+// Distance returns the minimal distance from the query point to the geometries of the given value,
+// the geometry value with that distance, and true.
+// If the value does not contain geometries, it returns false.
+func (n *GeoNear) Distance(v any) (float64, any, bool) {
+	res := math.Inf(1)
+
+	var loc any
+
+	for _, g := range geoValues(v) {
+		for _, p := range g.points {
+			if d := n.units.distance(n.point, p); d < res {
+				res = d
+				loc = g.value
+			}
+		}
+	}
+
+	return res, loc, loc != nil
+}
+
+// Matches returns true if the given distance is within the query bounds.
+func (n *GeoNear) Matches(distance float64) bool {
+	return distance >= n.minDistance && distance <= n.maxDistance
+}
+
+// DocumentDistance is like Distance, but for the document field with the given key that could be a dot notation path.
+func (n *GeoNear) DocumentDistance(doc *types.Document, key string) (float64, any, bool, error) {
+	var values []any
+
+	if v, _ := doc.Get(key); v != nil {
+		values = []any{v}
+	}
+
+	if len(values) == 0 {
+		path, err := types.NewPathFromString(key)
+		if err != nil {
+			return 0, nil, false, lazyerrors.Error(err)
+		}
+
+		if path.Len() > 1 {
+			if values, err = commonpath.FindValues(doc, path, &commonpath.FindValuesOpts{
+				FindArrayIndex:     true,
+				FindArrayDocuments: true,
+			}); err != nil {
+				return 0, nil, false, lazyerrors.Error(err)
+			}
+		}
+	}
+
+	res := math.Inf(1)
+
+	var loc any
+
+	for _, v := range values {
+		if d, l, ok := n.Distance(v); ok && d < res {
+			res, loc = d, l
+		}
+	}
+
+	return res, loc, loc != nil, nil
+}
+
+// newGeoNearFromFilter returns proximity query parameters for {field: {$near: ...}}
+// and {field: {$nearSphere: ...}} filter expression.
+func newGeoNearFromFilter(operator string, expr *types.Document) (*GeoNear, error) {
+	value := must.NotFail(expr.Get(operator))
+
+	minDistance, _ := expr.Get("$minDistance")
+	maxDistance, _ := expr.Get("$maxDistance")
+
+	if d, ok := value.(*types.Document); ok && d.Has("$geometry") {
+		for _, k := range d.Keys() {
+			switch k {
+			case "$geometry":
+				// handled below
+			case "$minDistance":
+				minDistance = must.NotFail(d.Get(k))
+			case "$maxDistance":
+				maxDistance = must.NotFail(d.Get(k))
+			default:
+				return nil, commonerrors.NewCommandErrorMsg(
+					commonerrors.ErrBadValue,
+					fmt.Sprintf("invalid argument in geo near query: %s", k),
+				)
+			}
+		}
+
+		return NewGeoNear(must.NotFail(d.Get("$geometry")), true, minDistance, maxDistance)
+	}
+
+	return NewGeoNear(value, operator == "$nearSphere", minDistance, maxDistance)
+}
+
+// FindGeoNear returns the field and proximity query parameters of the top-level
+// $near or $nearSphere expression of the filter, or nil if there is none.
+func FindGeoNear(filter *types.Document) (string, *GeoNear, error) {
+	if filter == nil {
+		return "", nil, nil
+	}
+
+	var key string
+	var res *GeoNear
+
+	iter := filter.Iterator()
+	defer iter.Close()
+
+	for {
+		k, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			return key, res, nil
+		}
+
+		if err != nil {
+			return "", nil, lazyerrors.Error(err)
+		}
+
+		expr, ok := v.(*types.Document)
+		if !ok {
+			continue
+		}
+
+		for _, op := range []string{"$near", "$nearSphere"} {
+			if !expr.Has(op) {
+				continue
+			}
+
+			if res != nil {
+				return "", nil, commonerrors.NewCommandErrorMsg(
+					commonerrors.ErrBadValue,
+					"Too many geoNear expressions",
+				)
+			}
+
+			if res, err = newGeoNearFromFilter(op, expr); err != nil {
+				return "", nil, err
+			}
+
+			key = k
+		}
+	}
+}
+
+// HasGeoNearOperator returns true if the filter contains $near or $nearSphere operators at any level.
+func HasGeoNearOperator(v any) bool {
+	switch v := v.(type) {
+	case *types.Document:
+		for _, k := range v.Keys() {
+			if k == "$near" || k == "$nearSphere" || HasGeoNearOperator(must.NotFail(v.Get(k))) {
+				return true
+			}
+		}
+
+	case *types.Array:
+		for i := 0; i < v.Len(); i++ {
+			if HasGeoNearOperator(must.NotFail(v.Get(i))) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// geoShape represents a region of $geoWithin query.
+type geoShape interface {
+	// contains returns true if the point is inside the region or on its boundary.
+	contains(p geoPoint) bool
+}
+
+// geoBox is a rectangle of $box.
+type geoBox struct {
+	min, max geoPoint
+}
+
+// contains implements geoShape interface.
+func (b geoBox) contains(p geoPoint) bool {
+	return p.x >= b.min.x && p.x <= b.max.x && p.y >= b.min.y && p.y <= b.max.y
+}
+
+// geoCircle is a circle of $center and $centerSphere.
+type geoCircle struct {
+	center geoPoint
+	radius float64
+	units  geoUnits
+}
+
+// contains implements geoShape interface.
+func (c geoCircle) contains(p geoPoint) bool {
+	return c.units.distance(c.center, p) <= c.radius
+}
+
+// geoPolygon is a polygon with optional holes, or a multi-polygon.
+//
+// Edges are treated as straight lines in coordinate space, including GeoJSON polygons,
+// which is close to spherical geodesic edges for small polygons.
+type geoPolygon struct {
+	polygons [][][]geoPoint // polygons of rings; the first ring of each polygon is the exterior
+}
+
+// contains implements geoShape interface.
+func (g geoPolygon) contains(p geoPoint) bool {
+	for _, rings := range g.polygons {
+		if !ringContains(rings[0], p) {
+			continue
+		}
+
+		inHole := false
+
+		for _, hole := range rings[1:] {
+			if ringContains(hole, p) && !ringBoundary(hole, p) {
+				inHole = true
+				break
+			}
+		}
+
+		if !inHole {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ringContains returns true if the point is inside the ring or on its boundary.
+func ringContains(ring []geoPoint, p geoPoint) bool {
+	if ringBoundary(ring, p) {
+		return true
+	}
+
+	var inside bool
+
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+
+		if (a.y > p.y) != (b.y > p.y) && p.x < (b.x-a.x)*(p.y-a.y)/(b.y-a.y)+a.x {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// ringBoundary returns true if the point is on one of the ring edges.
+func ringBoundary(ring []geoPoint, p geoPoint) bool {
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+
+		if (b.x-a.x)*(p.y-a.y)-(b.y-a.y)*(p.x-a.x) != 0 {
+			continue
+		}
+
+		if p.x >= math.Min(a.x, b.x) && p.x <= math.Max(a.x, b.x) &&
+			p.y >= math.Min(a.y, b.y) && p.y <= math.Max(a.y, b.y) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newGeoShape returns a region for {field: {$geoWithin: value}} filter expression.
+func newGeoShape(value any) (geoShape, error) {
+	d, ok := value.(*types.Document)
+	if !ok || d.Len() != 1 {
+		return nil, commonerrors.NewCommandErrorMsg(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("$geoWithin not object: %s", types.FormatAnyValue(value)),
+		)
+	}
+
+	specifier := d.Command()
+	v := must.NotFail(d.Get(specifier))
+
+	invalid := commonerrors.NewCommandErrorMsg(
+		commonerrors.ErrBadValue,
+		fmt.Sprintf("malformed geo query: %s", types.FormatAnyValue(d)),
+	)
+
+	switch specifier {
+	case "$geometry":
+		g, ok := v.(*types.Document)
+		if !ok {
+			return nil, invalid
+		}
+
+		t, _ := g.Get("type")
+		coordinates, _ := g.Get("coordinates")
+
+		var polygons [][][]geoPoint
+
+		switch t {
+		case "Polygon":
+			rings, ok := geoRings(coordinates)
+			if !ok {
+				return nil, invalid
+			}
+
+			polygons = [][][]geoPoint{rings}
+
+		case "MultiPolygon":
+			arr, ok := coordinates.(*types.Array)
+			if !ok {
+				return nil, invalid
+			}
+
+			for i := 0; i < arr.Len(); i++ {
+				rings, ok := geoRings(must.NotFail(arr.Get(i)))
+				if !ok {
+					return nil, invalid
+				}
+
+				polygons = append(polygons, rings)
+			}
+
+		default:
+			return nil, commonerrors.NewCommandErrorMsg(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("$geoWithin not supported with provided geometry: %s", types.FormatAnyValue(g)),
+			)
+		}
+
+		return geoPolygon{polygons: polygons}, nil
+
+	case "$box":
+		points, ok := legacyPoints(v)
+		if !ok || len(points) != 2 {
+			return nil, invalid
+		}
+
+		return geoBox{
+			min: geoPoint{x: math.Min(points[0].x, points[1].x), y: math.Min(points[0].y, points[1].y)},
+			max: geoPoint{x: math.Max(points[0].x, points[1].x), y: math.Max(points[0].y, points[1].y)},
+		}, nil
+
+	case "$polygon":
+		points, ok := legacyPoints(v)
+		if !ok || len(points) < 3 {
+			return nil, invalid
+		}
+
+		return geoPolygon{polygons: [][][]geoPoint{{points}}}, nil
+
+	case "$center", "$centerSphere":
+		arr, ok := v.(*types.Array)
+		if !ok || arr.Len() != 2 {
+			return nil, invalid
+		}
+
+		center, ok := legacyPoint(must.NotFail(arr.Get(0)))
+		if !ok {
+			return nil, invalid
+		}
+
+		radius, ok := geoNumber(must.NotFail(arr.Get(1)))
+		if !ok || radius < 0 {
+			return nil, invalid
+		}
+
+		c := geoCircle{center: center, radius: radius}
+		if specifier == "$centerSphere" {
+			c.units = geoUnitsRadians
+		}
+
+		return c, nil
+
+	default:
+		return nil, commonerrors.NewCommandErrorMsg(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("unknown geo specifier: %s: %s", specifier, types.FormatAnyValue(v)),
+		)
+	}
+}
+
+// filterFieldExprGeoWithin handles {field: {$geoWithin: value}} filter.
+// The field value matches if all positions of any of its geometries are inside the region.
+func filterFieldExprGeoWithin(fieldValue, exprValue any) (bool, error) {
+	shape, err := newGeoShape(exprValue)
+	if err != nil {
+		return false, err
+	}
+
+	for _, g := range geoValues(fieldValue) {
+		within := true
+
+		for _, p := range g.points {
+			if !shape.contains(p) {
+				within = false
+				break
+			}
+		}
+
+		if within {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// geoJSONTypes contains all supported GeoJSON geometry types.
+var geoJSONTypes = map[string]struct{}{
+	"Point":           {},
+	"MultiPoint":      {},
+	"LineString":      {},
+	"MultiLineString": {},
+	"Polygon":         {},
+	"MultiPolygon":    {},
+}
+
+// IsGeoValue returns true if the document field value could be indexed by 2dsphere index:
+// it is null, a GeoJSON object, a legacy coordinate pair, or an array of those,
+// and all positions are valid longitudes and latitudes.
+func IsGeoValue(v any) bool {
+	switch v := v.(type) {
+	case types.NullType:
+		return true
+	case *types.Array:
+		if v.Len() == 0 {
+			return true
+		}
+	}
+
+	values := geoValues(v)
+	if len(values) == 0 {
+		return false
+	}
+
+	for _, g := range values {
+		if d, ok := g.value.(*types.Document); ok && d.Has("type") {
+			t, _ := d.Get("type")
+			if _, ok = geoJSONTypes[fmt.Sprint(t)]; !ok {
+				return false
+			}
+		}
+
+		for _, p := range g.points {
+			if p.x < -180 || p.x > 180 || p.y < -90 || p.y > 90 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// geoValue is a geometry found in the document field value.
+type geoValue struct {
+	value  any        // original value
+	points []geoPoint // all positions of the geometry
+}
+
+// geoValues returns geometries of the document field value: GeoJSON objects and legacy coordinate pairs.
+// Arrays of geometries are expanded.
+func geoValues(v any) []geoValue {
+	switch v := v.(type) {
+	case *types.Document:
+		if v.Has("type") {
+			coordinates, _ := v.Get("coordinates")
+
+			points, ok := geoPositions(coordinates)
+			if !ok || len(points) == 0 {
+				return nil
+			}
+
+			return []geoValue{{value: v, points: points}}
+		}
+
+		if p, ok := legacyPoint(v); ok {
+			return []geoValue{{value: v, points: []geoPoint{p}}}
+		}
+
+	case *types.Array:
+		if p, ok := legacyPoint(v); ok {
+			return []geoValue{{value: v, points: []geoPoint{p}}}
+		}
+
+		var res []geoValue
+
+		for i := 0; i < v.Len(); i++ {
+			e := must.NotFail(v.Get(i))
+
+			if _, ok := e.(*types.Array); ok {
+				if p, ok := legacyPoint(e); ok {
+					res = append(res, geoValue{value: e, points: []geoPoint{p}})
+				}
+
+				continue
+			}
+
+			res = append(res, geoValues(e)...)
+		}
+
+		return res
+	}
+
+	return nil
+}
+
+// geoPositions returns all positions of GeoJSON coordinates of any nesting level.
+func geoPositions(v any) ([]geoPoint, bool) {
+	arr, ok := v.(*types.Array)
+	if !ok {
+		return nil, false
+	}
+
+	if p, ok := legacyPoint(arr); ok {
+		return []geoPoint{p}, true
+	}
+
+	var res []geoPoint
+
+	for i := 0; i < arr.Len(); i++ {
+		points, ok := geoPositions(must.NotFail(arr.Get(i)))
+		if !ok {
+			return nil, false
+		}
+
+		res = append(res, points...)
+	}
+
+	return res, true
+}
+
+// geoRings returns rings of GeoJSON polygon coordinates.
+func geoRings(v any) ([][]geoPoint, bool) {
+	arr, ok := v.(*types.Array)
+	if !ok || arr.Len() == 0 {
+		return nil, false
+	}
+
+	res := make([][]geoPoint, arr.Len())
+
+	for i := 0; i < arr.Len(); i++ {
+		ring, ok := legacyPoints(must.NotFail(arr.Get(i)))
+		if !ok || len(ring) < 4 || ring[0] != ring[len(ring)-1] {
+			return nil, false
+		}
+
+		res[i] = ring
+	}
+
+	return res, true
+}
+
+// parseGeoJSONPoint returns the position of GeoJSON point.
+func parseGeoJSONPoint(d *types.Document) (geoPoint, error) {
+	t, _ := d.Get("type")
+	coordinates, _ := d.Get("coordinates")
+
+	p, ok := legacyPoint(coordinates)
+	if t != "Point" || !ok {
+		return geoPoint{}, commonerrors.NewCommandErrorMsg(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("invalid point in geo near query $geometry argument: %s", types.FormatAnyValue(d)),
+		)
+	}
+
+	if p.x < -180 || p.x > 180 || p.y < -90 || p.y > 90 {
+		return geoPoint{}, commonerrors.NewCommandErrorMsg(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("longitude/latitude is out of bounds, lng: %v lat: %v", p.x, p.y),
+		)
+	}
+
+	return p, nil
+}
+
+// legacyPoints returns positions of the array of coordinate pairs.
+func legacyPoints(v any) ([]geoPoint, bool) {
+	arr, ok := v.(*types.Array)
+	if !ok {
+		return nil, false
+	}
+
+	res := make([]geoPoint, arr.Len())
+
+	for i := 0; i < arr.Len(); i++ {
+		if res[i], ok = legacyPoint(must.NotFail(arr.Get(i))); !ok {
+			return nil, false
+		}
+	}
+
+	return res, true
+}
+
+// legacyPoint returns the position of legacy coordinate pair:
+// an array or a document with at least two numeric values, where the first two are used.
+func legacyPoint(v any) (geoPoint, bool) {
+	var values []any
+
+	switch v := v.(type) {
+	case *types.Array:
+		if v.Len() < 2 {
+			return geoPoint{}, false
+		}
+
+		values = []any{must.NotFail(v.Get(0)), must.NotFail(v.Get(1))}
+
+	case *types.Document:
+		if v.Len() < 2 {
+			return geoPoint{}, false
+		}
+
+		values = v.Values()[:2]
+
+	default:
+		return geoPoint{}, false
+	}
+
+	x, ok := geoNumber(values[0])
+	if !ok {
+		return geoPoint{}, false
+	}
+
+	y, ok := geoNumber(values[1])
+	if !ok {
+		return geoPoint{}, false
+	}
+
+	return geoPoint{x: x, y: y}, true
+}
+
+// geoNumber returns the float64 value of the number.
+func geoNumber(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, !math.IsNaN(v)
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
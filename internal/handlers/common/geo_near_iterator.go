@@ -0,0 +1,71 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// GeoNearIterator returns an iterator of documents sorted by distance
+// for the top-level $near or $nearSphere expression of the filter.
+// It will be added to the given closer.
+//
+// If the filter does not contain such an expression, the given iterator is returned.
+// Otherwise, this function fully consumes and closes the underlying iterator,
+// sorts documents in memory and returns a new iterator over the sorted slice.
+func GeoNearIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser, filter *types.Document) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	key, near, err := FindGeoNear(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if near == nil {
+		return iter, nil
+	}
+
+	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	distances := make(map[*types.Document]float64, len(docs))
+
+	for _, doc := range docs {
+		// documents without geometries do not match $near, so they are not expected there
+		if distances[doc], _, _, err = near.DocumentDistance(doc, key); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	slices.SortStableFunc(docs, func(a, b *types.Document) int {
+		switch da, db := distances[a], distances[b]; {
+		case da < db:
+			return -1
+		case da > db:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	res := iterator.Values(iterator.ForSlice(docs))
+	closer.Add(res)
+
+	return res, nil
+}
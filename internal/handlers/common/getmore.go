@@ -19,13 +19,13 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
 	"github.com/FerretDB/FerretDB/internal/clientconn/cursor"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
 	"github.com/FerretDB/FerretDB/internal/types"
-	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
@@ -83,7 +83,6 @@ func GetMore(ctx context.Context, msg *wire.OpMsg, registry *cursor.Registry) (*
 		)
 	}
 
-	// TODO https://github.com/FerretDB/FerretDB/issues/2984
 	v, _ = document.Get("maxTimeMS")
 	if v == nil {
 		v = int64(0)
@@ -135,8 +134,8 @@ func GetMore(ctx context.Context, msg *wire.OpMsg, registry *cursor.Registry) (*
 		)
 	}
 
-	// Handle comment.
-	// TODO https://github.com/FerretDB/FerretDB/issues/2986
+	// comment of any type is allowed
+	comment, _ := document.Get("comment")
 
 	username, _ := conninfo.Get(ctx).Auth()
 
@@ -153,11 +152,9 @@ func GetMore(ctx context.Context, msg *wire.OpMsg, registry *cursor.Registry) (*
 
 	v, _ = document.Get("batchSize")
 	if v == nil || types.Compare(v, int32(0)) == types.Equal {
-		// Use 16MB batchSize limit.
-		// Unlimited default batchSize is used for missing batchSize and zero values,
-		// set 250 assuming it is small enough not to crash FerretDB.
-		// TODO https://github.com/FerretDB/FerretDB/issues/2824
-		v = int32(250)
+		// Unlimited default batchSize is used for missing batchSize and zero values;
+		// the batch is still limited by its size, see ConsumeBatch.
+		v = int32(math.MaxInt32)
 	}
 
 	batchSize, err := commonparams.GetValidatedNumberParamWithMinValue(document.Command(), "batchSize", v, 0)
@@ -179,7 +176,38 @@ func GetMore(ctx context.Context, msg *wire.OpMsg, registry *cursor.Registry) (*
 		)
 	}
 
-	resDocs, err := iterator.ConsumeValuesN(iterator.Interface[struct{}, *types.Document](cursor), int(batchSize))
+	// maxTimeMS set on find or aggregate is enforced by the cursor's context;
+	// getMore's own maxTimeMS only limits how long awaitData cursor waits for new documents, like in MongoDB
+	if maxTimeMS != 0 && !cursor.AwaitData {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"cannot set maxTimeMS on getMore command for a non-awaitData cursor",
+			document.Command(),
+		)
+	}
+
+	cursor.LogOperation(document.Command(), comment)
+
+	var resDocs []*types.Document
+	var done bool
+
+	if cursor.Tailable {
+		var await time.Duration
+		if cursor.AwaitData {
+			await = defaultAwaitDataTimeout
+			if maxTimeMS > 0 {
+				await = time.Duration(maxTimeMS) * time.Millisecond
+			}
+		}
+
+		awaitCtx, cancel := context.WithTimeout(ctx, await)
+		defer cancel()
+
+		resDocs, err = ConsumeTailable(awaitCtx, cursor, int(batchSize), await)
+	} else {
+		resDocs, done, err = ConsumeBatch(cursor, int(batchSize))
+	}
+
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
@@ -189,23 +217,34 @@ func GetMore(ctx context.Context, msg *wire.OpMsg, registry *cursor.Registry) (*
 		nextBatch.Append(doc)
 	}
 
-	if nextBatch.Len() < int(batchSize) {
+	if done {
 		// Cursor ID 0 lets the client know that there are no more results.
 		// Cursor is already closed and removed from the registry by this point.
 		cursorID = 0
 	}
 
+	cursorDoc := must.NotFail(types.NewDocument(
+		"nextBatch", nextBatch,
+	))
+
+	if cursor.ResumeToken {
+		cursorDoc.Set("postBatchResumeToken", PostBatchResumeToken(cursor, resDocs))
+	}
+
+	cursorDoc.Set("id", cursorID)
+	cursorDoc.Set("ns", cursor.NS())
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"cursor", must.NotFail(types.NewDocument(
-				"nextBatch", nextBatch,
-				"id", cursorID,
-				"ns", db+"."+collection,
-			)),
+			"cursor", cursorDoc,
 			"ok", float64(1),
 		))},
 	}))
 
 	return &reply, nil
 }
+
+// defaultAwaitDataTimeout is the time getMore waits for new documents
+// on tailable awaitData cursor if maxTimeMS is not set; that's the MongoDB default.
+const defaultAwaitDataTimeout = time.Second
@@ -30,7 +30,9 @@ import (
 )
 
 // GetParameter is a part of common implementation of the getParameter command.
-func GetParameter(_ context.Context, msg *wire.OpMsg, l *zap.Logger) (*wire.OpMsg, error) {
+//
+// Parameters are taken from the given registry shared with the setParameter command.
+func GetParameter(_ context.Context, msg *wire.OpMsg, l *zap.Logger, parameters *Parameters) (*wire.OpMsg, error) {
 	document, err := msg.Document()
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -45,37 +47,7 @@ func GetParameter(_ context.Context, msg *wire.OpMsg, l *zap.Logger) (*wire.OpMs
 
 	Ignored(document, l, "comment")
 
-	parameters := must.NotFail(types.NewDocument(
-		// to add a new parameter, fill template and place it in the alphabetical order position
-		//"<name>", must.NotFail(types.NewDocument(
-		//	"value", <value>,
-		//	"settableAtRuntime", <bool>,
-		//	"settableAtStartup", <bool>,
-		//)),
-		"authenticationMechanisms", must.NotFail(types.NewDocument(
-			"value", must.NotFail(types.NewArray("PLAIN")),
-			"settableAtRuntime", false,
-			"settableAtStartup", true,
-		)),
-		"authSchemaVersion", must.NotFail(types.NewDocument(
-			"value", int32(5),
-			"settableAtRuntime", true,
-			"settableAtStartup", true,
-		)),
-		"featureCompatibilityVersion", must.NotFail(types.NewDocument(
-			"value", must.NotFail(types.NewDocument("version", "6.0")),
-			"settableAtRuntime", false,
-			"settableAtStartup", false,
-		)),
-		"quiet", must.NotFail(types.NewDocument(
-			"value", false,
-			"settableAtRuntime", true,
-			"settableAtStartup", true,
-		)),
-		// parameters are alphabetically ordered
-	))
-
-	resDoc, err := selectParameters(document, parameters, showDetails, allParameters)
+	resDoc, err := selectParameters(document, parameters.document(), showDetails, allParameters)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
@@ -0,0 +1,83 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// HintIndex represents an index that could be selected by the hint.
+type HintIndex struct {
+	Name string
+	Key  *types.Document
+}
+
+// GetHintIndex returns the index selected by the hint, which could be an index name or an index key pattern.
+//
+// It returns nil if the hint is not set or is `{$natural: <order>}`.
+// It returns protocol error if the hint does not correspond to any of the given indexes.
+func GetHintIndex(hint any, indexes []HintIndex) (*HintIndex, error) {
+	switch hint := hint.(type) {
+	case nil:
+		return nil, nil
+
+	case string:
+		for i, index := range indexes {
+			if index.Name == hint {
+				return &indexes[i], nil
+			}
+		}
+
+	case *types.Document:
+		if hint.Has("$natural") {
+			return nil, nil
+		}
+
+		for i, index := range indexes {
+			if indexKeysEqual(index.Key, hint) {
+				return &indexes[i], nil
+			}
+		}
+	}
+
+	return nil, commonerrors.NewCommandErrorMsg(
+		commonerrors.ErrBadValue,
+		"error processing query: planner returned error :: caused by :: "+
+			"hint provided does not correspond to an existing index",
+	)
+}
+
+// indexKeysEqual returns true if both index key patterns have the same fields
+// in the same order with equal values.
+func indexKeysEqual(a, b *types.Document) bool {
+	aKeys, bKeys := a.Keys(), b.Keys()
+	if len(aKeys) != len(bKeys) {
+		return false
+	}
+
+	for i, field := range aKeys {
+		if bKeys[i] != field {
+			return false
+		}
+
+		if types.Compare(must.NotFail(a.Get(field)), must.NotFail(b.Get(field))) != types.Equal {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,145 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// MaxIndexKeyPathDepth is the maximum number of elements in the index key path.
+// Documents can't be nested deeper, so longer paths would never match anything.
+const MaxIndexKeyPathDepth = 100
+
+// indexPlugins contains all known index types that are specified by string values in the index key.
+var indexPlugins = map[string]struct{}{
+	"hashed":      {},
+	"text":        {},
+	"2d":          {},
+	"2dsphere":    {},
+	"geoHaystack": {},
+	"columnstore": {},
+}
+
+// ValidateIndexKey checks that the index key pattern is valid for MongoDB,
+// and returns protocol error with the same code as MongoDB otherwise.
+//
+// It checks field names and value types only;
+// handlers perform additional checks for the index types they support.
+func ValidateIndexKey(command string, keyDoc *types.Document) error {
+	iter := keyDoc.Iterator()
+	defer iter.Close()
+
+	for {
+		field, value, err := iter.Next()
+
+		switch {
+		case err == nil:
+			// nothing
+		case errors.Is(err, iterator.ErrIteratorDone):
+			return nil
+		default:
+			return lazyerrors.Error(err)
+		}
+
+		msg := indexKeyValueError(value)
+		if msg == "" {
+			msg = indexKeyFieldError(field)
+		}
+
+		if msg != "" {
+			return commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrCannotCreateIndex, msg, command)
+		}
+	}
+}
+
+// indexKeyValueError checks the value of the index key pattern for a single field.
+// It returns an error message for invalid value, or an empty string.
+func indexKeyValueError(value any) string {
+	switch value := value.(type) {
+	case float64:
+		if !(value > 0 || value < 0) {
+			return "Values in the index key pattern can't be 0."
+		}
+
+	case int32:
+		if value == 0 {
+			return "Values in the index key pattern can't be 0."
+		}
+
+	case int64:
+		if value == 0 {
+			return "Values in the index key pattern can't be 0."
+		}
+
+	case string:
+		if _, ok := indexPlugins[value]; !ok {
+			return fmt.Sprintf("Unknown index plugin '%s'", value)
+		}
+
+	default:
+		return fmt.Sprintf(
+			"Values in v:2 index key pattern cannot be of type %s. "+
+				"Only numbers > 0, numbers < 0, and strings are allowed.",
+			commonparams.AliasFromType(value),
+		)
+	}
+
+	return ""
+}
+
+// indexKeyFieldError checks the field path of the index key pattern.
+// It returns an error message for invalid path, or an empty string.
+func indexKeyFieldError(field string) string {
+	if field == "" {
+		return "Index keys cannot be an empty field."
+	}
+
+	// wildcard fields are checked by handlers that support them
+	if field == "$**" || strings.HasSuffix(field, ".$**") {
+		field = strings.TrimSuffix(strings.TrimSuffix(field, "$**"), ".")
+		if field == "" {
+			return ""
+		}
+	}
+
+	elements := strings.Split(field, ".")
+
+	if len(elements) > MaxIndexKeyPathDepth {
+		return fmt.Sprintf(
+			"Index key path %q has %d elements, the maximum is %d.",
+			field, len(elements), MaxIndexKeyPathDepth,
+		)
+	}
+
+	for _, e := range elements {
+		if e == "" {
+			return "Index keys cannot contain an empty field."
+		}
+
+		if strings.HasPrefix(e, "$") {
+			return "Index key contains an illegal field name: field name starts with '$'."
+		}
+	}
+
+	return ""
+}
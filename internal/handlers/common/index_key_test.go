@@ -0,0 +1,110 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestValidateIndexKey(t *testing.T) {
+	t.Parallel()
+
+	deep := strings.Repeat("a.", MaxIndexKeyPathDepth) + "a"
+
+	for name, tc := range map[string]struct {
+		key *types.Document
+		err string // empty if key is valid
+	}{
+		"Valid": {
+			key: must.NotFail(types.NewDocument("v", int32(1), "foo.bar", int64(-1), "baz", 2.5)),
+		},
+		"Plugins": {
+			key: must.NotFail(types.NewDocument("v", "hashed", "t", "text", "l", "2dsphere")),
+		},
+		"Wildcard": {
+			key: must.NotFail(types.NewDocument("$**", int32(1))),
+		},
+		"WildcardPrefix": {
+			key: must.NotFail(types.NewDocument("foo.$**", int32(1))),
+		},
+		"MaxDepth": {
+			key: must.NotFail(types.NewDocument(strings.Repeat("a.", MaxIndexKeyPathDepth-1)+"a", int32(1))),
+		},
+		"Zero": {
+			key: must.NotFail(types.NewDocument("v", int32(0))),
+			err: "Values in the index key pattern can't be 0.",
+		},
+		"ZeroDouble": {
+			key: must.NotFail(types.NewDocument("v", 0.0)),
+			err: "Values in the index key pattern can't be 0.",
+		},
+		"UnknownPlugin": {
+			key: must.NotFail(types.NewDocument("v", "foo")),
+			err: "Unknown index plugin 'foo'",
+		},
+		"Bool": {
+			key: must.NotFail(types.NewDocument("v", true)),
+			err: "Values in v:2 index key pattern cannot be of type bool. " +
+				"Only numbers > 0, numbers < 0, and strings are allowed.",
+		},
+		"Document": {
+			key: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument()))),
+			err: "Values in v:2 index key pattern cannot be of type object. " +
+				"Only numbers > 0, numbers < 0, and strings are allowed.",
+		},
+		"EmptyField": {
+			key: must.NotFail(types.NewDocument("", int32(1))),
+			err: "Index keys cannot be an empty field.",
+		},
+		"EmptyElement": {
+			key: must.NotFail(types.NewDocument("foo..bar", int32(1))),
+			err: "Index keys cannot contain an empty field.",
+		},
+		"TrailingDot": {
+			key: must.NotFail(types.NewDocument("foo.", int32(1))),
+			err: "Index keys cannot contain an empty field.",
+		},
+		"Dollar": {
+			key: must.NotFail(types.NewDocument("foo.$bar", int32(1))),
+			err: "Index key contains an illegal field name: field name starts with '$'.",
+		},
+		"TooDeep": {
+			key: must.NotFail(types.NewDocument(deep, int32(1))),
+			err: `Index key path "` + deep + `" has 101 elements, the maximum is 100.`,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateIndexKey("createIndexes", tc.key)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			expected := commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrCannotCreateIndex, tc.err, "createIndexes")
+			assert.Equal(t, expected, err)
+		})
+	}
+}
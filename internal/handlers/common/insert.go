@@ -33,9 +33,12 @@ type InsertParams struct {
 	Ordered    bool         `ferretdb:"ordered,opt"`
 
 	WriteConcern             any    `ferretdb:"writeConcern,ignored"`
-	BypassDocumentValidation bool   `ferretdb:"bypassDocumentValidation,ignored"`
+	BypassDocumentValidation bool   `ferretdb:"bypassDocumentValidation,opt"`
 	Comment                  string `ferretdb:"comment,ignored"`
 	LSID                     any    `ferretdb:"lsid,ignored"`
+	TxnNumber                int64  `ferretdb:"txnNumber,ignored"`
+	StartTransaction         bool   `ferretdb:"startTransaction,ignored"`
+	Autocommit               bool   `ferretdb:"autocommit,ignored"`
 }
 
 // GetInsertParams returns the parameters for an insert command.
@@ -15,35 +15,193 @@
 package common
 
 import (
-	"time"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
 
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/maintenance"
+	"github.com/FerretDB/FerretDB/internal/clientconn/session"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // IsMaster is a common implementation of the isMaster command used by deprecated OP_QUERY message.
-func IsMaster() (*wire.OpReply, error) {
+//
+// See IsMasterDocuments for getUser description.
+func IsMaster(ctx context.Context, query *types.Document, getUser UserGetter) (*wire.OpReply, error) {
+	docs, err := IsMasterDocuments(ctx, query, getUser)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
 	return &wire.OpReply{
 		NumberReturned: 1,
-		Documents:      IsMasterDocuments(),
+		Documents:      docs,
 	}, nil
 }
 
 // IsMasterDocuments returns isMaster's Documents field (identical for both OP_MSG and OP_QUERY).
-func IsMasterDocuments() []*types.Document {
-	return []*types.Document{must.NotFail(types.NewDocument(
+//
+// The getUser function is used to report SASL mechanisms supported for the user;
+// it may be nil if the handler does not store users.
+func IsMasterDocuments(ctx context.Context, doc *types.Document, getUser UserGetter) ([]*types.Document, error) {
+	compression, err := HelloCompression(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	mechs, err := HelloSASLSupportedMechs(ctx, doc, getUser)
+	if err != nil {
+		return nil, err
+	}
+
+	res := must.NotFail(types.NewDocument(
 		"ismaster", true, // only lowercase
 		// topologyVersion
 		"maxBsonObjectSize", int32(types.MaxDocumentLen),
 		"maxMessageSizeBytes", int32(wire.MaxMsgLen),
 		"maxWriteBatchSize", int32(100000),
-		"localTime", time.Now(),
-		// logicalSessionTimeoutMinutes
+		"localTime", types.Now(),
+		"logicalSessionTimeoutMinutes", int32(session.GetRegistry(ctx).Timeout().Minutes()),
 		"connectionId", int32(42),
 		"minWireVersion", MinWireVersion,
 		"maxWireVersion", MaxWireVersion,
 		"readOnly", false,
-		"ok", float64(1),
-	))}
+	))
+
+	if compression.Len() > 0 {
+		res.Set("compression", compression)
+	}
+
+	if mechs != nil {
+		res.Set("saslSupportedMechs", mechs)
+	}
+
+	HelloMaintenance(ctx, res, "ismaster")
+
+	res.Set("ok", float64(1))
+
+	return []*types.Document{res}, nil
+}
+
+// HelloMaintenance updates hello or isMaster response when maintenance mode is enabled:
+// the server is reported as not writable (using the given field) and read-only,
+// and the maintenanceMessage field is added.
+func HelloMaintenance(ctx context.Context, res *types.Document, writableField string) {
+	enabled, message := maintenance.GetMode(ctx).State()
+	if !enabled {
+		return
+	}
+
+	res.Set(writableField, false)
+	res.Set("readOnly", true)
+	res.Set("maintenanceMessage", message)
+}
+
+// HelloCompression returns the value of the "compression" field of hello and isMaster responses.
+//
+// It contains names of compressors that were requested by the client and are supported by FerretDB,
+// in the client's order of preference.
+// The returned array is empty if the client did not request any supported compressor.
+func HelloCompression(doc *types.Document) (*types.Array, error) {
+	command := doc.Command()
+
+	requested, err := GetOptionalParam(doc, "compression", types.MakeArray(0))
+	if err != nil {
+		return nil, err
+	}
+
+	res := types.MakeArray(requested.Len())
+
+	iter := requested.Iterator()
+	defer iter.Close()
+
+	for {
+		_, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		name, ok := v.(string)
+		if !ok {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				fmt.Sprintf(
+					"BSON field '%s.compression' is the wrong type '%s', expected type 'string'",
+					command, commonparams.AliasFromType(v),
+				),
+				"compression",
+			)
+		}
+
+		if slices.Contains(wire.SupportedCompressors, name) && !res.Contains(name) {
+			res.Append(name)
+		}
+	}
+
+	return res, nil
+}
+
+// HelloSASLSupportedMechs returns the value of the "saslSupportedMechs" field of hello and isMaster responses.
+//
+// The client requests it with the "<db>.<username>" string to negotiate the authentication mechanism.
+// It returns nil if the client did not request it, the user does not exist, or getUser is nil;
+// in that case, the field should not be set.
+func HelloSASLSupportedMechs(ctx context.Context, doc *types.Document, getUser UserGetter) (*types.Array, error) {
+	v, _ := doc.Get("saslSupportedMechs")
+	if v == nil || getUser == nil {
+		return nil, nil
+	}
+
+	command := doc.Command()
+
+	name, ok := v.(string)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field '%s.saslSupportedMechs' is the wrong type '%s', expected type 'string'",
+				command, commonparams.AliasFromType(v),
+			),
+			"saslSupportedMechs",
+		)
+	}
+
+	db, username, ok := strings.Cut(name, ".")
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"UserName must contain a '.' separated database.user pair",
+			"saslSupportedMechs",
+		)
+	}
+
+	user, err := getUser(ctx, db, username)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if user == nil {
+		return nil, nil
+	}
+
+	res := types.MakeArray(1)
+
+	if _, err = scramSHA256StoredCredentials(user); err == nil {
+		res.Append("SCRAM-SHA-256")
+	}
+
+	return res, nil
 }
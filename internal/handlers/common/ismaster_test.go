@@ -0,0 +1,92 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestHelloCompression(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		doc      *types.Document
+		expected *types.Array
+		err      error
+	}{
+		"Missing": {
+			doc:      must.NotFail(types.NewDocument("hello", int32(1))),
+			expected: must.NotFail(types.NewArray()),
+		},
+		"ClientOrder": {
+			doc: must.NotFail(types.NewDocument(
+				"hello", int32(1),
+				"compression", must.NotFail(types.NewArray("zstd", "unknown", "snappy", "zstd")),
+			)),
+			expected: must.NotFail(types.NewArray("zstd", "snappy")),
+		},
+		"Unsupported": {
+			doc: must.NotFail(types.NewDocument(
+				"isMaster", int32(1),
+				"compression", must.NotFail(types.NewArray("lz4", "noop")),
+			)),
+			expected: must.NotFail(types.NewArray()),
+		},
+		"NotArray": {
+			doc: must.NotFail(types.NewDocument(
+				"hello", int32(1),
+				"compression", "snappy",
+			)),
+			err: commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				"BSON field 'compression' is the wrong type 'string', expected type 'array'",
+				"compression",
+			),
+		},
+		"NotString": {
+			doc: must.NotFail(types.NewDocument(
+				"hello", int32(1),
+				"compression", must.NotFail(types.NewArray("snappy", int32(1))),
+			)),
+			err: commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				"BSON field 'hello.compression' is the wrong type 'int', expected type 'string'",
+				"compression",
+			),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := HelloCompression(tc.doc)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			testutil.AssertEqual(t, tc.expected, actual)
+		})
+	}
+}
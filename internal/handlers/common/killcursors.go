@@ -51,6 +51,9 @@ func KillCursors(ctx context.Context, msg *wire.OpMsg, registry *cursor.Registry
 
 	username, _ := conninfo.Get(ctx).Auth()
 
+	// comment of any type is allowed
+	comment, _ := document.Get("comment")
+
 	cursors, err := GetRequiredParam[*types.Array](document, "cursors")
 	if err != nil {
 		return nil, err
@@ -98,6 +101,7 @@ func KillCursors(ctx context.Context, msg *wire.OpMsg, registry *cursor.Registry
 			continue
 		}
 
+		cursor.LogOperation(command, comment)
 		cursor.Close()
 		cursorsKilled.Append(id)
 	}
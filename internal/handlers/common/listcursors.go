@@ -0,0 +1,111 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/cursor"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// ListCursors is a part of common implementation of the listCursors command.
+//
+// It returns all open cursors of all connections sorted by their IDs,
+// so cursors that are never exhausted or killed by clients could be found.
+func ListCursors(ctx context.Context, msg *wire.OpMsg, registry *cursor.Registry) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	db, err := GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if db != "admin" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	cursors := registry.All()
+	slices.SortFunc(cursors, func(a, b *cursor.Cursor) int {
+		switch {
+		case a.ID < b.ID:
+			return -1
+		case a.ID > b.ID:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	now := time.Now()
+	res := types.MakeArray(len(cursors))
+
+	for _, c := range cursors {
+		res.Append(cursorInfo(c, now))
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"cursors", res,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// cursorInfo returns listCursors entry for the open cursor.
+func cursorInfo(c *cursor.Cursor, now time.Time) *types.Document {
+	res := must.NotFail(types.NewDocument(
+		"id", c.ID,
+		"ns", c.NS(),
+		"createdDate", c.Created(),
+		"ageMillis", now.Sub(c.Created()).Milliseconds(),
+		"nDocsReturned", c.Returned(),
+		"tailable", c.Tailable,
+		"awaitData", c.AwaitData,
+	))
+
+	if c.Client != "" {
+		res.Set("client", c.Client)
+	}
+
+	if c.Username != "" {
+		res.Set("username", c.Username)
+	}
+
+	if c.OriginatingCommand != nil {
+		res.Set("originatingCommand", c.OriginatingCommand)
+	}
+
+	return res
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// WithMaxTimeMS returns a copy of ctx with the deadline set by maxTimeMS parameter,
+// and a function that releases its resources; it should be called when the command finishes.
+//
+// Zero maxTimeMS means no time limit, like in MongoDB.
+func WithMaxTimeMS(ctx context.Context, maxTimeMS int64) (context.Context, context.CancelFunc) {
+	if maxTimeMS == 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, time.Duration(maxTimeMS)*time.Millisecond)
+}
+
+// CheckMaxTimeMS returns MaxTimeMSExpired error if err was caused by the exceeded context deadline.
+// Other errors are returned wrapped.
+func CheckMaxTimeMS(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return commonerrors.NewCommandErrorMsg(commonerrors.ErrMaxTimeMSExpired, "operation exceeded time limit")
+	}
+
+	return lazyerrors.Error(err)
+}
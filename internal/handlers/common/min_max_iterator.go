@@ -0,0 +1,177 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// MinMaxIterator returns an iterator that filters out documents with index key values
+// outside of the range defined by find's min (inclusive) and max (exclusive) bounds.
+// Any of them may be nil. Key values are compared field by field in the index order.
+// It will be added to the given closer.
+//
+// It returns protocol error if bounds do not match the index key pattern.
+//
+// Close method closes the underlying iterator.
+func MinMaxIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser, key, min, max *types.Document) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	if min == nil && max == nil {
+		return iter, nil
+	}
+
+	sort := IndexKeySort(key)
+
+	fields := sort.Keys()
+	orders := make([]types.SortType, len(fields))
+
+	for i, field := range fields {
+		orders[i] = types.SortType(must.NotFail(sort.Get(field)).(int32))
+	}
+
+	for _, bound := range []*types.Document{min, max} {
+		if bound == nil {
+			continue
+		}
+
+		boundFields := bound.Keys()
+		valid := len(boundFields) == len(fields)
+
+		for i := 0; valid && i < len(fields); i++ {
+			valid = boundFields[i] == fields[i]
+		}
+
+		if !valid {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrMinMaxInvalidIndex,
+				"error processing query: planner returned error :: caused by :: "+
+					"The index chosen is not valid for satisfying the min/max query",
+				"find",
+			)
+		}
+	}
+
+	res := &minMaxIterator{
+		iter:   iter,
+		fields: fields,
+		orders: orders,
+		min:    min,
+		max:    max,
+	}
+	closer.Add(res)
+
+	return res, nil
+}
+
+// IndexKeySort returns the sort document for documents to be returned in the index order
+// of the given index key pattern. Non-numeric key values (like "hashed") are treated as ascending.
+func IndexKeySort(key *types.Document) *types.Document {
+	res := types.MakeDocument(key.Len())
+
+	for _, field := range key.Keys() {
+		order, err := GetSortType(field, must.NotFail(key.Get(field)))
+		if err != nil {
+			order = types.Ascending
+		}
+
+		res.Set(field, int32(order))
+	}
+
+	return res
+}
+
+// minMaxIterator is returned by MinMaxIterator.
+type minMaxIterator struct {
+	iter   types.DocumentsIterator
+	min    *types.Document
+	max    *types.Document
+	fields []string
+	orders []types.SortType
+}
+
+// Next implements iterator.Interface. See MinMaxIterator for details.
+func (iter *minMaxIterator) Next() (struct{}, *types.Document, error) {
+	var unused struct{}
+
+	for {
+		_, doc, err := iter.iter.Next()
+		if err != nil {
+			return unused, nil, lazyerrors.Error(err)
+		}
+
+		if iter.min != nil && iter.compare(doc, iter.min) == types.Less {
+			continue
+		}
+
+		if iter.max != nil && iter.compare(doc, iter.max) != types.Less {
+			continue
+		}
+
+		return unused, doc, nil
+	}
+}
+
+// compare compares index key values of the document with the bound in the index order.
+func (iter *minMaxIterator) compare(doc, bound *types.Document) types.CompareResult {
+	for i, field := range iter.fields {
+		order := iter.orders[i]
+
+		res := types.CompareOrderForSort(indexKeyValue(doc, field), must.NotFail(bound.Get(field)), order)
+		if res == types.Equal {
+			continue
+		}
+
+		if order == types.Descending {
+			if res == types.Less {
+				return types.Greater
+			}
+
+			return types.Less
+		}
+
+		return res
+	}
+
+	return types.Equal
+}
+
+// Close implements iterator.Interface. See MinMaxIterator for details.
+func (iter *minMaxIterator) Close() {
+	iter.iter.Close()
+}
+
+// indexKeyValue returns the value of the document at the index key field path,
+// or null if it is not present.
+func indexKeyValue(doc *types.Document, field string) any {
+	path, err := types.NewPathFromString(field)
+	if err != nil {
+		return types.Null
+	}
+
+	v, err := doc.GetByPath(path)
+	if err != nil {
+		return types.Null
+	}
+
+	return v
+}
+
+// check interfaces
+var (
+	_ types.DocumentsIterator = (*minMaxIterator)(nil)
+)
@@ -0,0 +1,176 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"math"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// NumericTypes represents the mode of numeric types in administrative responses.
+type NumericTypes string
+
+const (
+	// NumericTypesSimple returns all integer statistics as int64.
+	NumericTypesSimple NumericTypes = "simple"
+
+	// NumericTypesMongoDB returns int32, int64 and double values the same way as MongoDB,
+	// as declared by NumericSchema of each response.
+	NumericTypesMongoDB NumericTypes = "mongodb"
+)
+
+// AllNumericTypes includes all numeric types modes; the first one is the default.
+var AllNumericTypes = []string{
+	string(NumericTypesSimple),
+	string(NumericTypesMongoDB),
+}
+
+// NumericType represents the BSON numeric type MongoDB uses for the response field.
+type NumericType int
+
+const (
+	_ NumericType = iota
+
+	// NumericInt32 is always int32.
+	NumericInt32
+
+	// NumericInt64 is always int64.
+	NumericInt64
+
+	// NumericDouble is always double.
+	NumericDouble
+
+	// NumericNumber is int32 for small values and int64 for others,
+	// like values appended by MongoDB's BSONObjBuilder::appendNumber.
+	NumericNumber
+)
+
+// numberMaxInt32 is the bound of NumericNumber values that are returned as int32.
+const numberMaxInt32 = 1 << 30
+
+// NumericSchema declares numeric types of administrative response fields.
+//
+// Keys are dot notation paths of the response document.
+// Fields that are not present in the response or do not have numeric values are skipped.
+type NumericSchema map[string]NumericType
+
+// CollStatsNumericSchema declares numeric types of collStats response.
+var CollStatsNumericSchema = NumericSchema{
+	"size":           NumericNumber,
+	"count":          NumericNumber,
+	"avgObjSize":     NumericNumber,
+	"storageSize":    NumericNumber,
+	"nindexes":       NumericInt32,
+	"totalIndexSize": NumericNumber,
+	"totalSize":      NumericNumber,
+	"scaleFactor":    NumericInt32,
+}
+
+// DBStatsNumericSchema declares numeric types of dbStats response.
+var DBStatsNumericSchema = NumericSchema{
+	"collections": NumericNumber,
+	"views":       NumericNumber,
+	"objects":     NumericNumber,
+	"avgObjSize":  NumericDouble,
+	"dataSize":    NumericDouble,
+	"storageSize": NumericDouble,
+	"indexes":     NumericNumber,
+	"indexSize":   NumericDouble,
+	"totalSize":   NumericDouble,
+	"scaleFactor": NumericDouble,
+}
+
+// DataSizeNumericSchema declares numeric types of dataSize response.
+var DataSizeNumericSchema = NumericSchema{
+	"size":       NumericNumber,
+	"numObjects": NumericNumber,
+	"millis":     NumericInt64,
+}
+
+// Apply converts numeric values of the document fields declared by the schema
+// if mode is NumericTypesMongoDB. Otherwise, the document is not modified.
+func (s NumericSchema) Apply(doc *types.Document, mode NumericTypes) error {
+	if mode != NumericTypesMongoDB {
+		return nil
+	}
+
+	for key, t := range s {
+		path, err := types.NewPathFromString(key)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		v, err := doc.GetByPath(path)
+		if err != nil {
+			continue
+		}
+
+		converted, ok := convertNumericType(v, t)
+		if !ok {
+			continue
+		}
+
+		if err = doc.SetByPath(path, converted); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// convertNumericType returns the numeric value converted to the given type.
+// It returns false if the value is not a number.
+func convertNumericType(v any, t NumericType) (any, bool) {
+	var f float64
+	var i int64
+
+	switch v := v.(type) {
+	case float64:
+		f, i = v, int64(v)
+	case int32:
+		f, i = float64(v), int64(v)
+	case int64:
+		f, i = float64(v), v
+	default:
+		return nil, false
+	}
+
+	switch t {
+	case NumericInt32:
+		if i > math.MaxInt32 || i < math.MinInt32 {
+			return i, true
+		}
+
+		return int32(i), true
+
+	case NumericInt64:
+		return i, true
+
+	case NumericDouble:
+		return f, true
+
+	case NumericNumber:
+		if i > -numberMaxInt32 && i < numberMaxInt32 {
+			return int32(i), true
+		}
+
+		return i, true
+
+	default:
+		panic("unexpected numeric type")
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestNumericSchemaApply(t *testing.T) {
+	t.Parallel()
+
+	schema := NumericSchema{
+		"small":        NumericNumber,
+		"large":        NumericNumber,
+		"int32":        NumericInt32,
+		"int64":        NumericInt64,
+		"double":       NumericDouble,
+		"nested.value": NumericInt32,
+		"missing":      NumericInt32,
+		"string":       NumericInt32,
+	}
+
+	newDoc := func() *types.Document {
+		return must.NotFail(types.NewDocument(
+			"small", int64(42),
+			"large", int64(1<<30),
+			"int32", int64(1),
+			"int64", int32(2),
+			"double", int64(3),
+			"nested", must.NotFail(types.NewDocument("value", 4.0)),
+			"string", "foo",
+			"ok", float64(1),
+		))
+	}
+
+	t.Run("Simple", func(t *testing.T) {
+		t.Parallel()
+
+		doc := newDoc()
+		require.NoError(t, schema.Apply(doc, NumericTypesSimple))
+		testutil.AssertEqual(t, newDoc(), doc)
+	})
+
+	t.Run("MongoDB", func(t *testing.T) {
+		t.Parallel()
+
+		doc := newDoc()
+		require.NoError(t, schema.Apply(doc, NumericTypesMongoDB))
+
+		expected := must.NotFail(types.NewDocument(
+			"small", int32(42),
+			"large", int64(1<<30),
+			"int32", int32(1),
+			"int64", int64(2),
+			"double", float64(3),
+			"nested", must.NotFail(types.NewDocument("value", int32(4))),
+			"string", "foo",
+			"ok", float64(1),
+		))
+		testutil.AssertEqual(t, expected, doc)
+	})
+}
@@ -0,0 +1,219 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/logging"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Parameters is a registry of server parameters shared by getParameter and setParameter commands.
+//
+// It contains common parameters; handler-specific parameters are added with Add
+// before the registry is used.
+type Parameters struct {
+	m map[string]*registeredParameter
+}
+
+// registeredParameter represents a parameter with its metadata reported by getParameter.
+type registeredParameter struct {
+	Parameter
+	settableAtRuntime bool
+	settableAtStartup bool
+}
+
+// NewParameters creates a new registry with common parameters.
+func NewParameters() *Parameters {
+	ps := &Parameters{
+		m: map[string]*registeredParameter{},
+	}
+
+	ps.add("authenticationMechanisms", &constParameter{
+		name:  "authenticationMechanisms",
+		value: must.NotFail(types.NewArray("PLAIN", "SCRAM-SHA-256")),
+	}, false, true)
+	ps.add("authSchemaVersion", &constParameter{name: "authSchemaVersion", value: int32(5)}, true, true)
+	ps.add("featureCompatibilityVersion", &constParameter{
+		name:  "featureCompatibilityVersion",
+		value: must.NotFail(types.NewDocument("version", "6.0")),
+	}, false, false)
+	ps.add("ferretdbResponseWarnings", responseWarningsParameter{}, true, true)
+	ps.add("logLevel", logLevelParameter{}, true, true)
+	ps.add("quiet", quietParameter{}, true, true)
+
+	return ps
+}
+
+// Add adds a handler-specific parameter that could be set at runtime, but not at startup.
+func (ps *Parameters) Add(name string, p Parameter) {
+	ps.add(name, p, true, false)
+}
+
+// add adds a parameter with the given metadata.
+func (ps *Parameters) add(name string, p Parameter, settableAtRuntime, settableAtStartup bool) {
+	if _, ok := ps.m[name]; ok {
+		panic(fmt.Sprintf("parameter %q already added", name))
+	}
+
+	ps.m[name] = &registeredParameter{
+		Parameter:         p,
+		settableAtRuntime: settableAtRuntime,
+		settableAtStartup: settableAtStartup,
+	}
+}
+
+// document returns all parameters with their current values and metadata
+// in the case-insensitive alphabetical order.
+func (ps *Parameters) document() *types.Document {
+	names := make([]string, 0, len(ps.m))
+	for name := range ps.m {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return strings.ToLower(names[i]) < strings.ToLower(names[j])
+	})
+
+	res := types.MakeDocument(len(names))
+
+	for _, name := range names {
+		p := ps.m[name]
+
+		res.Set(name, must.NotFail(types.NewDocument(
+			"value", p.Get(),
+			"settableAtRuntime", p.settableAtRuntime,
+			"settableAtStartup", p.settableAtStartup,
+		)))
+	}
+
+	return res
+}
+
+// ParameterTypeError returns protocol error for the parameter value of the wrong type.
+func ParameterTypeError(name string, v any, expected string) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrTypeMismatch,
+		fmt.Sprintf(
+			"BSON field 'setParameter.%s' is the wrong type '%s', expected type '%s'",
+			name, commonparams.AliasFromType(v), expected,
+		),
+		"setParameter",
+	)
+}
+
+// constParameter is a parameter with the value that could not be changed.
+type constParameter struct {
+	name  string
+	value any
+}
+
+// Get implements Parameter interface.
+func (p *constParameter) Get() any {
+	return p.value
+}
+
+// Set implements Parameter interface.
+//
+// Setting the current value is allowed.
+func (p *constParameter) Set(v any) error {
+	if types.Compare(v, p.value) == types.Equal {
+		return nil
+	}
+
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrNotImplemented,
+		fmt.Sprintf("Changing %s is not implemented yet", p.name),
+		"setParameter",
+	)
+}
+
+// logLevelMax is the maximum log verbosity level, like in MongoDB.
+const logLevelMax = 5
+
+// logLevelParameter represents MongoDB's log verbosity level.
+//
+// Level 0 corresponds to the info level of FerretDB logger, and all higher levels to the debug level.
+type logLevelParameter struct{}
+
+// Get implements Parameter interface.
+func (logLevelParameter) Get() any {
+	if logging.Level.Enabled(zap.DebugLevel) {
+		return int32(1)
+	}
+
+	return int32(0)
+}
+
+// Set implements Parameter interface.
+func (logLevelParameter) Set(v any) error {
+	n, err := commonparams.GetWholeNumberParam(v)
+	if err != nil {
+		return ParameterTypeError("logLevel", v, "int")
+	}
+
+	if n < 0 || n > logLevelMax {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("'logLevel' must be between 0 and %d, got %d", logLevelMax, n),
+			"setParameter",
+		)
+	}
+
+	level := zapcore.InfoLevel
+	if n > 0 {
+		level = zapcore.DebugLevel
+	}
+
+	logging.Level.SetLevel(level)
+
+	return nil
+}
+
+// quietParameter represents MongoDB's quiet mode.
+type quietParameter struct{}
+
+// Get implements Parameter interface.
+func (quietParameter) Get() any {
+	return logging.Quiet.Load()
+}
+
+// Set implements Parameter interface.
+func (quietParameter) Set(v any) error {
+	b, ok := v.(bool)
+	if !ok {
+		return ParameterTypeError("quiet", v, "bool")
+	}
+
+	logging.Quiet.Store(b)
+
+	return nil
+}
+
+// check interfaces
+var (
+	_ Parameter = (*constParameter)(nil)
+	_ Parameter = logLevelParameter{}
+	_ Parameter = quietParameter{}
+)
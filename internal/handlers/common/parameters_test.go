@@ -0,0 +1,101 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/logging"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// testParameter is a settable parameter for tests.
+type testParameter struct {
+	v any
+}
+
+// Get implements Parameter interface.
+func (p *testParameter) Get() any { return p.v }
+
+// Set implements Parameter interface.
+func (p *testParameter) Set(v any) error { p.v = v; return nil }
+
+func TestParameters(t *testing.T) {
+	// global logging state is changed, so the test is not parallel
+
+	level, quiet := logging.Level.Level(), logging.Quiet.Load()
+	t.Cleanup(func() {
+		logging.Level.SetLevel(level)
+		logging.Quiet.Store(quiet)
+	})
+
+	ps := NewParameters()
+	ps.Add("ferretdbTest", &testParameter{v: int32(42)})
+
+	assert.Panics(t, func() { ps.Add("quiet", &testParameter{}) })
+
+	doc := ps.document()
+	assert.Equal(t, []string{
+		"authenticationMechanisms",
+		"authSchemaVersion",
+		"featureCompatibilityVersion",
+		"ferretdbResponseWarnings",
+		"ferretdbTest",
+		"logLevel",
+		"quiet",
+	}, doc.Keys())
+
+	test := must.NotFail(doc.Get("ferretdbTest")).(*types.Document)
+	assert.Equal(t, true, must.NotFail(test.Get("settableAtRuntime")))
+	assert.Equal(t, false, must.NotFail(test.Get("settableAtStartup")))
+
+	t.Run("LogLevel", func(t *testing.T) {
+		p := ps.m["logLevel"]
+
+		require.NoError(t, p.Set(int32(2)))
+		assert.Equal(t, zapcore.DebugLevel, logging.Level.Level())
+		assert.Equal(t, int32(1), p.Get())
+
+		require.NoError(t, p.Set(float64(0)))
+		assert.Equal(t, zapcore.InfoLevel, logging.Level.Level())
+		assert.Equal(t, int32(0), p.Get())
+
+		assert.Error(t, p.Set(int32(6)))
+		assert.Error(t, p.Set("1"))
+	})
+
+	t.Run("Quiet", func(t *testing.T) {
+		p := ps.m["quiet"]
+
+		require.NoError(t, p.Set(true))
+		assert.True(t, logging.Quiet.Load())
+		assert.Equal(t, true, p.Get())
+
+		assert.Error(t, p.Set(int32(1)))
+	})
+
+	t.Run("Const", func(t *testing.T) {
+		p := ps.m["authSchemaVersion"]
+
+		assert.NoError(t, p.Set(int64(5)))
+		assert.Error(t, p.Set(int32(3)))
+		assert.Equal(t, int32(5), p.Get())
+	})
+}
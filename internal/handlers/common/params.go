@@ -184,10 +184,10 @@ func GetSkipStageParam(value any) (int64, error) {
 	return limit, nil
 }
 
-// getBinaryMaskParam matches value type, returning bit mask and error if match failed.
-// Possible values are: position array ([1,3,5] == 010101), whole number value and types.Binary value.
-func getBinaryMaskParam(operator string, mask any) (uint64, error) {
-	var bitmask uint64
+// getBitPositionsParam matches value type, returning positions of bits set in the mask and error if match failed.
+// Possible values are: position array ([1,3,5] == 101010), whole number value and types.Binary value.
+func getBitPositionsParam(operator string, mask any) ([]uint64, error) {
+	var positions []uint64
 
 	switch mask := mask.(type) {
 	case *types.Array:
@@ -200,13 +200,13 @@ func getBinaryMaskParam(operator string, mask any) (uint64, error) {
 				switch {
 				case errors.Is(err, commonparams.ErrNotWholeNumber), errors.Is(err, commonparams.ErrInfinity),
 					errors.Is(err, commonparams.ErrLongExceededPositive), errors.Is(err, commonparams.ErrLongExceededNegative):
-					return 0, commonerrors.NewCommandErrorMsgWithArgument(
+					return nil, commonerrors.NewCommandErrorMsgWithArgument(
 						commonerrors.ErrBadValue,
 						fmt.Sprintf(`Failed to parse bit position. Expected an integer: %d: %#v`, i, val),
 						operator,
 					)
 				default:
-					return 0, commonerrors.NewCommandErrorMsgWithArgument(
+					return nil, commonerrors.NewCommandErrorMsgWithArgument(
 						commonerrors.ErrBadValue,
 						fmt.Sprintf(`Failed to parse bit position. Expected a number in: %d: %#v`, i, val),
 						operator,
@@ -215,20 +215,20 @@ func getBinaryMaskParam(operator string, mask any) (uint64, error) {
 			}
 
 			if b < 0 {
-				return 0, commonerrors.NewCommandErrorMsgWithArgument(
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
 					commonerrors.ErrBadValue,
 					fmt.Sprintf("Failed to parse bit position. Expected a non-negative number in: %d: %d", i, b),
 					operator,
 				)
 			}
 
-			bitmask |= 1 << uint64(math.Min(float64(b), 63))
+			positions = append(positions, uint64(b))
 		}
 
 	case float64:
 		// {field: {$bitsAllClear: bitmask}}
 		if mask != math.Trunc(mask) || math.IsInf(mask, 0) {
-			return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrFailedToParse,
 				fmt.Sprintf("Expected an integer: %s: %#v", operator, mask),
 				operator,
@@ -236,64 +236,73 @@ func getBinaryMaskParam(operator string, mask any) (uint64, error) {
 		}
 
 		if mask < 0 {
-			return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrFailedToParse,
 				fmt.Sprintf(`Expected a non-negative number in: %s: %.1f`, operator, mask),
 				operator,
 			)
 		}
 
-		bitmask = uint64(mask)
+		positions = bitPositions(uint64(mask))
 
 	case types.Binary:
 		// {field: {$bitsAllClear: BinData()}}
-		for b := 0; b < len(mask.B); b++ {
-			byteAt := mask.B[b]
-
-			if byteAt == 0 {
-				continue
-			}
-
-			if b < 8 {
-				bitmask |= uint64(byteAt) << uint64(b*8)
-			} else {
-				bitmask |= 1 << 63
+		for i, b := range mask.B {
+			for bit := 0; bit < 8; bit++ {
+				if b&(1<<bit) != 0 {
+					positions = append(positions, uint64(i*8+bit))
+				}
 			}
 		}
 
 	case int32:
 		// {field: {$bitsAllClear: bitmask}}
 		if mask < 0 {
-			return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrFailedToParse,
 				fmt.Sprintf(`Expected a non-negative number in: %s: %v`, operator, mask),
 				operator,
 			)
 		}
 
-		bitmask = uint64(mask)
+		positions = bitPositions(uint64(mask))
 
 	case int64:
 		// {field: {$bitsAllClear: bitmask}}
 		if mask < 0 {
-			return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrFailedToParse,
 				fmt.Sprintf(`Expected a non-negative number in: %s: %v`, operator, mask),
 				operator,
 			)
 		}
 
-		bitmask = uint64(mask)
+		positions = bitPositions(uint64(mask))
 
 	default:
-		return 0, commonerrors.NewCommandErrorMsgWithArgument(
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
 			commonerrors.ErrBadValue,
 			fmt.Sprintf(`value takes an Array, a number, or a BinData but received: %s: %#v`, operator, mask),
 			operator,
 		)
 	}
 
-	return bitmask, nil
+	return positions, nil
+}
+
+// bitPositions returns positions of bits set in the given number.
+func bitPositions(n uint64) []uint64 {
+	var res []uint64
+
+	for pos := uint64(0); n != 0; pos++ {
+		if n&1 != 0 {
+			res = append(res, pos)
+		}
+
+		n >>= 1
+	}
+
+	return res
 }
 
 // addNumbers returns the result of v1 and v2 addition and error if addition failed.
@@ -124,7 +124,7 @@ func getPositionalProjection(arr *types.Array, filter *types.Document, positiona
 			// matched the filter.
 			// In this call, we already know that the array matched the filter,
 			// and we want to find out which array element matched the filter.
-			matched, err := filterFieldExpr(doc, key, key, expr)
+			matched, err := filterFieldExpr(doc, key, key, expr, nil)
 			if err != nil {
 				// the array already matched the filter, so it cannot fail.
 				panic(err)
@@ -47,7 +47,15 @@ import (
 //   - `ErrBadPositionalProjection` when array or filter at positional projection path is empty;
 //   - `ErrBadPositionalProjection` when there is no filter field key for positional projection path;
 //   - `ErrElementMismatchPositionalProjection` when unexpected array was found on positional projection path;
+//   - `ErrMultiplePositionalProjection` when there is more than one positional projection;
+//   - `ErrElemMatchPositionalProjection` when positional projection is used with `$elemMatch`;
+//   - `ErrElemMatchProjectionObject` when `$elemMatch` argument is not a document;
+//   - `ErrElemMatchProjectionNested` when `$elemMatch` is used on a nested field;
+//   - `ErrSliceProjectionArgType`, `ErrSliceProjectionArrayLen`, `ErrSliceProjectionFirstArg`,
+//     `ErrSliceProjectionSecondArg`, and `ErrSliceProjectionLimit` when `$slice` argument is invalid;
 //   - `ErrNotImplemented` when there is unimplemented projection operators and expressions.
+//
+// `$slice` does not make the projection inclusion or exclusion, `$elemMatch` makes it inclusion.
 func ValidateProjection(projection *types.Document) (*types.Document, bool, error) {
 	validated := types.MakeDocument(0)
 
@@ -57,6 +65,7 @@ func ValidateProjection(projection *types.Document) (*types.Document, bool, erro
 	}
 
 	var inclusion *bool
+	var positional, elemMatch bool
 
 	iter := projection.Iterator()
 	defer iter.Close()
@@ -148,10 +157,47 @@ func ValidateProjection(projection *types.Document) (*types.Document, bool, erro
 
 		switch value := value.(type) {
 		case *types.Document:
-			return nil, false, commonerrors.NewCommandErrorMsg(
-				commonerrors.ErrNotImplemented,
-				fmt.Sprintf("projection expression %s is not supported", types.FormatAnyValue(value)),
-			)
+			// {$meta: "textScore"} adds the field to both inclusion and exclusion projections
+			if IsTextScoreMeta(value) && path.Len() == 1 {
+				validated.Set(key, value)
+				continue
+			}
+
+			switch projectionOperator(value) {
+			case "$slice":
+				if _, _, err = getSliceProjectionArgs(must.NotFail(value.Get("$slice"))); err != nil {
+					return nil, false, err
+				}
+
+				// $slice is applied to both inclusion and exclusion projections
+				validated.Set(key, value)
+
+				continue
+
+			case "$elemMatch":
+				if err = validateElemMatchProjection(path, must.NotFail(value.Get("$elemMatch"))); err != nil {
+					return nil, false, err
+				}
+
+				if positional {
+					return nil, false, commonerrors.NewCommandErrorMsgWithArgument(
+						commonerrors.ErrElemMatchPositionalProjection,
+						"Cannot specify positional operator and $elemMatch.",
+						"projection",
+					)
+				}
+
+				elemMatch = true
+				inclusionField = true
+
+				validated.Set(key, value)
+
+			default:
+				return nil, false, commonerrors.NewCommandErrorMsg(
+					commonerrors.ErrNotImplemented,
+					fmt.Sprintf("projection expression %s is not supported", types.FormatAnyValue(value)),
+				)
+			}
 		case *types.Array, string, types.Binary, types.ObjectID,
 			time.Time, types.NullType, types.Regex, types.Timestamp: // all these types are treated as new fields value
 			inclusionField = true
@@ -188,14 +234,34 @@ func ValidateProjection(projection *types.Document) (*types.Document, bool, erro
 			)
 		}
 
-		// if inclusion is nil we are processing the first field
-		if inclusion == nil {
-			if key == "_id" {
-				continue
+		if path.Suffix() == "$" {
+			if elemMatch {
+				return nil, false, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrElemMatchPositionalProjection,
+					"Cannot specify positional operator and $elemMatch.",
+					"projection",
+				)
 			}
 
-			inclusion = &inclusionField
+			if positional {
+				return nil, false, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrMultiplePositionalProjection,
+					"Cannot specify more than one positional projection per query.",
+					"projection",
+				)
+			}
 
+			positional = true
+		}
+
+		// _id field could be included or excluded in any projection
+		if key == "_id" {
+			continue
+		}
+
+		// if inclusion is nil we are processing the first field
+		if inclusion == nil {
+			inclusion = &inclusionField
 			continue
 		}
 
@@ -216,6 +282,14 @@ func ValidateProjection(projection *types.Document) (*types.Document, bool, erro
 		}
 	}
 
+	if inclusion == nil {
+		// projection contains only _id and {$meta: "textScore"} fields
+		id, _ := validated.Get("_id")
+		idInclusion, _ := id.(bool)
+
+		return validated, idInclusion, nil
+	}
+
 	return validated, *inclusion, nil
 }
 
@@ -274,6 +348,9 @@ func ProjectDocument(doc, projection, filter *types.Document, inclusion bool) (*
 		projected.Set(key, must.NotFail(projectedWithoutID.Get(key)))
 	}
 
+	// keep record ID for the showRecordId find option
+	projected.SetRecordID(doc.RecordID())
+
 	return projected, nil
 }
 
@@ -292,6 +369,9 @@ func projectDocumentWithoutID(doc *types.Document, projection, filter *types.Doc
 		projected = docWithoutID.DeepCopy()
 	}
 
+	// $elemMatch fields are set after all other fields, like in MongoDB
+	var elemMatchKeys []string
+
 	iter := projectionWithoutID.Iterator()
 	defer iter.Close()
 
@@ -312,6 +392,30 @@ func projectDocumentWithoutID(doc *types.Document, projection, filter *types.Doc
 
 		switch value := value.(type) { // found in the projection
 		case *types.Document: // field: { $elemMatch: { field2: value }}
+			if IsTextScoreMeta(value) {
+				projected.Set(key, doc.TextScore())
+				continue
+			}
+
+			switch projectionOperator(value) {
+			case "$slice":
+				if inclusion {
+					if _, err = includeProjection(path, 0, docWithoutID, projected, filter); err != nil {
+						return nil, err
+					}
+				}
+
+				if err = sliceProjection(path, projected, must.NotFail(value.Get("$slice"))); err != nil {
+					return nil, err
+				}
+
+				continue
+
+			case "$elemMatch":
+				elemMatchKeys = append(elemMatchKeys, key)
+				continue
+			}
+
 			return nil, commonerrors.NewCommandErrorMsg(
 				commonerrors.ErrCommandNotFound,
 				fmt.Sprintf("projection %s is not supported",
@@ -340,9 +444,35 @@ func projectDocumentWithoutID(doc *types.Document, projection, filter *types.Doc
 		}
 	}
 
+	for _, key := range elemMatchKeys {
+		expr := must.NotFail(must.NotFail(projectionWithoutID.Get(key)).(*types.Document).Get("$elemMatch"))
+
+		arr, err := elemMatchProjection(docWithoutID, key, expr.(*types.Document))
+		if err != nil {
+			return nil, err
+		}
+
+		if arr != nil {
+			projected.Set(key, arr)
+		}
+	}
+
 	return projected, nil
 }
 
+// ApplyPathProjection applies inclusion or exclusion of the field on the path from source to projected.
+// It is used by $project aggregation stage, so it handles paths the same way as find projection does.
+func ApplyPathProjection(path types.Path, source, projected *types.Document, inclusion bool) error {
+	if !inclusion {
+		excludeProjection(path, projected)
+		return nil
+	}
+
+	_, err := includeProjection(path, 0, source, projected, nil)
+
+	return err
+}
+
 // includeProjection copies the field on the path from source to projected.
 // When an array is on the path, it returns the array containing any document
 // with the same key. Dot notation with array index path does not include
@@ -531,44 +661,17 @@ func includeProjection(path types.Path, curIndex int, source any, projected, fil
 // Example: "v.0.foo" path exclusion projection:
 //
 //	{v: [{foo: 1}, {foo: 2}]}           -> {v: [{foo: 1}, {foo: 2}]}
-func excludeProjection(path types.Path, projected any) {
-	key := path.Prefix()
+func excludeProjection(path types.Path, projected *types.Document) {
+	var paths []types.Path
 
-	switch projected := projected.(type) {
-	case *types.Document:
-		embeddedSource, err := projected.Get(key)
-		if err != nil {
-			// key does not exist, nothing to exclude.
-			return
-		}
-
-		if path.Len() <= 1 {
-			// path reached suffix, remove the field from the document.
-			projected.Remove(key)
-			return
-		}
+	// concrete paths contain indexes of traversed arrays, so RemoveByPath removes only fields of documents
+	must.NoError(types.WalkPath(projected, path, &types.WalkPathOpts{ArrayDocuments: true}, func(p types.Path, _ any) error {
+		paths = append(paths, p)
+		return nil
+	}))
 
-		// recursively remove field from the embeddedSource.
-		excludeProjection(path.TrimPrefix(), embeddedSource)
-
-		return
-	case *types.Array:
-		// modifies the field of projected, hence not using iterator.
-		for i := 0; i < projected.Len(); i++ {
-			arrElem := must.NotFail(projected.Get(i))
-
-			if _, ok := arrElem.(*types.Document); !ok {
-				// not a document, cannot possibly be part of path, do nothing.
-				continue
-			}
-
-			excludeProjection(path, arrElem)
-		}
-
-		return
-	default:
-		// not a path, nothing to exclude.
-		return
+	for _, p := range paths {
+		projected.RemoveByPath(p)
 	}
 }
 
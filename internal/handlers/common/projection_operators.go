@@ -0,0 +1,298 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"math"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// projectionOperator returns the name of the projection operator such as `$slice` or `$elemMatch`
+// if the given projection value is a document with a single operator, or an empty string otherwise.
+func projectionOperator(value *types.Document) string {
+	if value.Len() != 1 {
+		return ""
+	}
+
+	return value.Command()
+}
+
+// getSliceProjectionArgs validates the argument of `$slice` projection operator
+// and returns skip (nil if not set) and limit.
+// The argument is either a number of elements to return (negative for elements from the end),
+// or [skip, limit] array.
+//
+// Command error codes:
+//   - ErrSliceProjectionArgType when the argument is neither a number nor an array;
+//   - ErrSliceProjectionArrayLen when the array does not contain exactly two elements;
+//   - ErrSliceProjectionFirstArg when skip is not a number;
+//   - ErrSliceProjectionSecondArg when limit is not a number;
+//   - ErrSliceProjectionLimit when limit is not positive.
+func getSliceProjectionArgs(arg any) (*int64, int64, error) {
+	switch arg := arg.(type) {
+	case float64, int32, int64:
+		limit, _ := sliceProjectionNumber(arg)
+		return nil, limit, nil
+
+	case *types.Array:
+		if arg.Len() != 2 {
+			return nil, 0, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrSliceProjectionArrayLen,
+				"$slice array argument should be of form [skip, limit]",
+				"projection",
+			)
+		}
+
+		skip, ok := sliceProjectionNumber(must.NotFail(arg.Get(0)))
+		if !ok {
+			return nil, 0, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrSliceProjectionFirstArg,
+				"$slice expects the first argument to be a number",
+				"projection",
+			)
+		}
+
+		limit, ok := sliceProjectionNumber(must.NotFail(arg.Get(1)))
+		if !ok {
+			return nil, 0, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrSliceProjectionSecondArg,
+				"$slice expects the second argument to be a number",
+				"projection",
+			)
+		}
+
+		if limit <= 0 {
+			return nil, 0, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrSliceProjectionLimit,
+				"$slice limit must be positive",
+				"projection",
+			)
+		}
+
+		return &skip, limit, nil
+
+	default:
+		return nil, 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrSliceProjectionArgType,
+			"$slice only supports numbers and [skip, limit] arrays",
+			"projection",
+		)
+	}
+}
+
+// sliceProjectionNumber converts the number to int64 clamped to int32 range like MongoDB does.
+// Fractional part is truncated.
+// It returns false if the value is not a number.
+func sliceProjectionNumber(v any) (int64, bool) {
+	var f float64
+
+	switch v := v.(type) {
+	case float64:
+		if math.IsNaN(v) {
+			return 0, true
+		}
+
+		f = v
+	case int32:
+		return int64(v), true
+	case int64:
+		f = float64(v)
+	default:
+		return 0, false
+	}
+
+	switch {
+	case f > math.MaxInt32:
+		return math.MaxInt32, true
+	case f < math.MinInt32:
+		return math.MinInt32, true
+	default:
+		return int64(f), true
+	}
+}
+
+// sliceProjection replaces arrays on the path in projected with their slices
+// specified by `$slice` projection operator argument.
+// Like exclusion projection, it traverses documents of arrays on the path.
+// Non-array values are not changed.
+//
+// Example: "v" path with {$slice: [1, 2]}:
+//
+//	{v: [1, 2, 3, 4]} -> {v: [2, 3]}
+//	{v: 42}           -> {v: 42}
+//
+// Example: "v.foo" path with {$slice: -1}:
+//
+//	{v: [{foo: [1, 2]}, {foo: [3, 4]}]} -> {v: [{foo: [2]}, {foo: [4]}]}
+func sliceProjection(path types.Path, projected *types.Document, arg any) error {
+	skip, limit, err := getSliceProjectionArgs(arg)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	var paths []types.Path
+	var arrays []*types.Array
+
+	// concrete paths contain indexes of traversed arrays, so SetByPath sets only fields of documents
+	must.NoError(types.WalkPath(projected, path, &types.WalkPathOpts{ArrayDocuments: true}, func(p types.Path, v any) error {
+		if arr, ok := v.(*types.Array); ok {
+			paths = append(paths, p)
+			arrays = append(arrays, arr)
+		}
+
+		return nil
+	}))
+
+	for i, p := range paths {
+		if err = projected.SetByPath(p, sliceArray(arrays[i], skip, limit)); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// sliceArray returns a new array with elements of arr selected by skip and limit
+// as described in getSliceProjectionArgs.
+func sliceArray(arr *types.Array, skip *int64, limit int64) *types.Array {
+	l := int64(arr.Len())
+
+	var start, end int64
+
+	switch {
+	case skip != nil:
+		start = *skip
+		if start < 0 {
+			start += l
+		}
+
+		if start < 0 {
+			start = 0
+		}
+
+		if start > l {
+			start = l
+		}
+
+		end = start + limit
+	case limit >= 0:
+		end = limit
+	default:
+		start = l + limit
+		if start < 0 {
+			start = 0
+		}
+
+		end = l
+	}
+
+	if end > l {
+		end = l
+	}
+
+	res := types.MakeArray(int(end - start))
+	for i := start; i < end; i++ {
+		res.Append(must.NotFail(arr.Get(int(i))))
+	}
+
+	return res
+}
+
+// validateElemMatchProjection validates `$elemMatch` projection operator argument for the given path.
+//
+// Command error codes:
+//   - ErrElemMatchProjectionObject when the argument is not a document;
+//   - ErrElemMatchProjectionNested when the path is not a top-level field;
+//   - errors returned by the `$elemMatch` query operator for invalid argument.
+func validateElemMatchProjection(path types.Path, arg any) error {
+	expr, ok := arg.(*types.Document)
+	if !ok {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrElemMatchProjectionObject,
+			"elemMatch: Invalid argument, object required.",
+			"projection",
+		)
+	}
+
+	if path.Len() > 1 {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrElemMatchProjectionNested,
+			"Cannot use $elemMatch projection on a nested field.",
+			"projection",
+		)
+	}
+
+	// an empty document is matched to validate the argument
+	_, err := elemMatchElement(new(types.Document), expr)
+
+	return err
+}
+
+// elemMatchProjection returns an array with the first element of the array field of the document
+// that matches `$elemMatch` projection operator argument.
+// It returns nil if the field is not an array, or if no element matches.
+func elemMatchProjection(doc *types.Document, key string, expr *types.Document) (*types.Array, error) {
+	v, err := doc.Get(key)
+	if err != nil {
+		return nil, nil
+	}
+
+	arr, ok := v.(*types.Array)
+	if !ok {
+		return nil, nil
+	}
+
+	for i := 0; i < arr.Len(); i++ {
+		elem := must.NotFail(arr.Get(i))
+
+		matches, err := elemMatchElement(elem, expr)
+		if err != nil {
+			return nil, err
+		}
+
+		if matches {
+			return types.NewArray(elem)
+		}
+	}
+
+	return nil, nil
+}
+
+// elemMatchElement returns true if the array element matches `$elemMatch` argument.
+//
+// The argument with query operators such as `{$gt: 42}` is applied to the element itself,
+// the argument with field conditions such as `{foo: {$gt: 42}}` is applied to document elements as a query filter.
+func elemMatchElement(elem any, expr *types.Document) (bool, error) {
+	if key := expr.Command(); strings.HasPrefix(key, "$") && !slices.Contains([]string{"$and", "$or", "$nor", "$expr"}, key) {
+		return FilterDocument(
+			must.NotFail(types.NewDocument("v", elem)),
+			must.NotFail(types.NewDocument("v", expr)),
+		)
+	}
+
+	doc, ok := elem.(*types.Document)
+	if !ok {
+		return false, nil
+	}
+
+	return FilterDocument(doc, expr)
+}
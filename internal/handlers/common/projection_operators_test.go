@@ -0,0 +1,170 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestProjectionOperators(t *testing.T) {
+	t.Parallel()
+
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+	a := func(values ...any) *types.Array { return must.NotFail(types.NewArray(values...)) }
+
+	b := func() *types.Array { return a(d("x", int32(1)), d("x", int32(2)), d("x", int32(3))) }
+
+	for name, tc := range map[string]struct {
+		projection *types.Document
+		expected   *types.Document
+		code       commonerrors.ErrorCode
+	}{
+		"SliceLimit": {
+			projection: d("a", d("$slice", int32(2))),
+			expected:   d("_id", int32(1), "a", a(int32(1), int32(2)), "b", b(), "f", int32(42)),
+		},
+		"SliceNegativeLimit": {
+			projection: d("a", d("$slice", int64(-1))),
+			expected:   d("_id", int32(1), "a", a(int32(4)), "b", b(), "f", int32(42)),
+		},
+		"SliceSkipLimit": {
+			projection: d("a", d("$slice", a(int32(1), 2.5))),
+			expected:   d("_id", int32(1), "a", a(int32(2), int32(3)), "b", b(), "f", int32(42)),
+		},
+		"SliceNegativeSkip": {
+			projection: d("a", d("$slice", a(int32(-3), int32(10)))),
+			expected:   d("_id", int32(1), "a", a(int32(2), int32(3), int32(4)), "b", b(), "f", int32(42)),
+		},
+		"SliceSkipOutOfRange": {
+			projection: d("a", d("$slice", a(int32(10), int32(1)))),
+			expected:   d("_id", int32(1), "a", a(), "b", b(), "f", int32(42)),
+		},
+		"SliceNested": {
+			projection: d("b.x", d("$slice", int32(1)), "a", int32(0)),
+			expected:   d("_id", int32(1), "b", b(), "f", int32(42)),
+		},
+		"SliceInclusion": {
+			projection: d("f", true, "a", d("$slice", int32(1))),
+			expected:   d("_id", int32(1), "a", a(int32(1)), "f", int32(42)),
+		},
+		"SliceNonArray": {
+			projection: d("f", d("$slice", int32(1))),
+			expected:   d("_id", int32(1), "a", a(int32(1), int32(2), int32(3), int32(4)), "b", b(), "f", int32(42)),
+		},
+		"ElemMatch": {
+			projection: d("b", d("$elemMatch", d("x", d("$gt", int32(1))))),
+			expected:   d("_id", int32(1), "b", a(d("x", int32(2)))),
+		},
+		"ElemMatchScalar": {
+			projection: d("a", d("$elemMatch", d("$gte", int32(3)))),
+			expected:   d("_id", int32(1), "a", a(int32(3))),
+		},
+		"ElemMatchLast": {
+			projection: d("b", d("$elemMatch", d("x", int32(3))), "f", int32(1), "_id", false),
+			expected:   d("f", int32(42), "b", a(d("x", int32(3)))),
+		},
+		"ElemMatchNoMatch": {
+			projection: d("b", d("$elemMatch", d("x", int32(5)))),
+			expected:   d("_id", int32(1)),
+		},
+		"ElemMatchNonArray": {
+			projection: d("f", d("$elemMatch", d("$gt", int32(1)))),
+			expected:   d("_id", int32(1)),
+		},
+		"SliceArrayLen": {
+			projection: d("a", d("$slice", a(int32(1)))),
+			code:       commonerrors.ErrSliceProjectionArrayLen,
+		},
+		"SliceArgType": {
+			projection: d("a", d("$slice", "1")),
+			code:       commonerrors.ErrSliceProjectionArgType,
+		},
+		"SliceFirstArg": {
+			projection: d("a", d("$slice", a("1", int32(1)))),
+			code:       commonerrors.ErrSliceProjectionFirstArg,
+		},
+		"SliceSecondArg": {
+			projection: d("a", d("$slice", a(int32(1), "1"))),
+			code:       commonerrors.ErrSliceProjectionSecondArg,
+		},
+		"SliceLimitZero": {
+			projection: d("a", d("$slice", a(int32(1), int32(0)))),
+			code:       commonerrors.ErrSliceProjectionLimit,
+		},
+		"ElemMatchObject": {
+			projection: d("b", d("$elemMatch", int32(1))),
+			code:       commonerrors.ErrElemMatchProjectionObject,
+		},
+		"ElemMatchNested": {
+			projection: d("b.x", d("$elemMatch", d("$gt", int32(1)))),
+			code:       commonerrors.ErrElemMatchProjectionNested,
+		},
+		"ElemMatchInvalid": {
+			projection: d("b", d("$elemMatch", d("$foo", int32(1)))),
+			code:       commonerrors.ErrBadValue,
+		},
+		"ElemMatchExclusion": {
+			projection: d("b", d("$elemMatch", d("x", int32(1))), "f", int32(0)),
+			code:       commonerrors.ErrProjectionExIn,
+		},
+		"ElemMatchPositional": {
+			projection: d("a.$", int32(1), "b", d("$elemMatch", d("x", int32(1)))),
+			code:       commonerrors.ErrElemMatchPositionalProjection,
+		},
+		"PositionalElemMatch": {
+			projection: d("b", d("$elemMatch", d("x", int32(1))), "a.$", int32(1)),
+			code:       commonerrors.ErrElemMatchPositionalProjection,
+		},
+		"MultiplePositional": {
+			projection: d("a.$", int32(1), "b.$", int32(1)),
+			code:       commonerrors.ErrMultiplePositionalProjection,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			projection, inclusion, err := ValidateProjection(tc.projection)
+			if tc.code != 0 {
+				var ce *commonerrors.CommandError
+				require.ErrorAs(t, err, &ce)
+				assert.Equal(t, tc.code, ce.Code())
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			doc := d(
+				"_id", int32(1),
+				"a", a(int32(1), int32(2), int32(3), int32(4)),
+				"b", b(),
+				"f", int32(42),
+			)
+
+			actual, err := ProjectDocument(doc, projection, nil, inclusion)
+			require.NoError(t, err)
+			testutil.AssertEqual(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// queryShapeFields contains fields of find and aggregate commands that are included in the query shape.
+// If the value is true, literals of the field are replaced with their type placeholders.
+var queryShapeFields = map[string]bool{
+	"filter":       true,
+	"pipeline":     true,
+	"let":          true,
+	"min":          true,
+	"max":          true,
+	"limit":        true,
+	"skip":         true,
+	"projection":   false,
+	"sort":         false,
+	"hint":         false,
+	"collation":    false,
+	"returnKey":    false,
+	"showRecordId": false,
+	"tailable":     false,
+	"awaitData":    false,
+}
+
+// QueryShape returns the query shape of find or aggregate command document.
+//
+// Queries with the same shape differ only in literal values;
+// the comment, batch size, and other options that do not change the query are not included.
+func QueryShape(document *types.Document) *types.Document {
+	command := document.Command()
+
+	db, _ := document.Get("$db")
+	collection := must.NotFail(document.Get(command))
+
+	res := must.NotFail(types.NewDocument(
+		"cmdNs", must.NotFail(types.NewDocument("db", db, "coll", collection)),
+		"command", command,
+	))
+
+	for _, k := range document.Keys() {
+		literals, ok := queryShapeFields[k]
+		if !ok {
+			continue
+		}
+
+		v := must.NotFail(document.Get(k))
+		if literals {
+			v = literalShape(v)
+		}
+
+		res.Set(k, v)
+	}
+
+	return res
+}
+
+// QueryShapeHash returns the hex-encoded SHA-256 hash of the query shape of find or aggregate command document.
+func QueryShapeHash(document *types.Document) string {
+	h := sha256.Sum256([]byte(types.FormatAnyValue(QueryShape(document))))
+	return strings.ToUpper(hex.EncodeToString(h[:]))
+}
+
+// literalShape returns the value with literals replaced by their type placeholders like "?string".
+//
+// Documents and arrays of documents keep their structure; arrays of other values
+// are replaced with "?array<?type>" if all elements have the same type, and with "?array<>" otherwise.
+// $comment operators are removed.
+func literalShape(v any) any {
+	switch v := v.(type) {
+	case *types.Document:
+		res := types.MakeDocument(v.Len())
+
+		for _, k := range v.Keys() {
+			if k == "$comment" {
+				continue
+			}
+
+			res.Set(k, literalShape(must.NotFail(v.Get(k))))
+		}
+
+		return res
+
+	case *types.Array:
+		if v.Len() == 0 {
+			return "?array<>"
+		}
+
+		res := types.MakeArray(v.Len())
+		alias := literalAlias(must.NotFail(v.Get(0)))
+
+		for i := 0; i < v.Len(); i++ {
+			e := must.NotFail(v.Get(i))
+
+			if _, ok := e.(*types.Document); ok {
+				res.Append(literalShape(e))
+				continue
+			}
+
+			if literalAlias(e) != alias {
+				alias = ""
+			}
+		}
+
+		if res.Len() == v.Len() {
+			return res
+		}
+
+		if alias == "" {
+			return "?array<>"
+		}
+
+		return "?array<?" + alias + ">"
+
+	default:
+		return "?" + literalAlias(v)
+	}
+}
+
+// literalAlias returns the type alias of the literal; all numeric types share "number" alias.
+func literalAlias(v any) string {
+	switch v.(type) {
+	case float64, int32, int64:
+		return "number"
+	default:
+		return commonparams.AliasFromType(v)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestQueryShape(t *testing.T) {
+	t.Parallel()
+
+	find := func(filter *types.Document, comment string) *types.Document {
+		return must.NotFail(types.NewDocument(
+			"find", "coll",
+			"filter", filter,
+			"sort", must.NotFail(types.NewDocument("v", int32(1))),
+			"batchSize", int32(1),
+			"comment", comment,
+			"$db", "db",
+		))
+	}
+
+	doc := find(must.NotFail(types.NewDocument(
+		"v", must.NotFail(types.NewDocument("$gt", int32(42), "$comment", "foo")),
+		"s", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray("a", "b")))),
+	)), "first")
+
+	expected := must.NotFail(types.NewDocument(
+		"cmdNs", must.NotFail(types.NewDocument("db", "db", "coll", "coll")),
+		"command", "find",
+		"filter", must.NotFail(types.NewDocument(
+			"v", must.NotFail(types.NewDocument("$gt", "?number")),
+			"s", must.NotFail(types.NewDocument("$in", "?array<?string>")),
+		)),
+		"sort", must.NotFail(types.NewDocument("v", int32(1))),
+	))
+	testutil.AssertEqual(t, expected, QueryShape(doc))
+
+	hash := QueryShapeHash(doc)
+	assert.Len(t, hash, 64)
+
+	sameShape := find(must.NotFail(types.NewDocument(
+		"v", must.NotFail(types.NewDocument("$gt", 3.14)),
+		"s", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray("c")))),
+	)), "second")
+	assert.Equal(t, hash, QueryShapeHash(sameShape))
+
+	otherShape := find(must.NotFail(types.NewDocument(
+		"v", must.NotFail(types.NewDocument("$lt", int32(42))),
+	)), "first")
+	assert.NotEqual(t, hash, QueryShapeHash(otherShape))
+}
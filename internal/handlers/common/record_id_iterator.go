@@ -0,0 +1,65 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// RecordIDIterator returns an iterator that adds `$recordId` field with document's record ID
+// for find's showRecordId option. It will be added to the given closer.
+//
+// Next method returns the next document with `$recordId` field set; see types.Document.RecordID.
+//
+// Close method closes the underlying iterator.
+func RecordIDIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser) types.DocumentsIterator {
+	res := &recordIDIterator{
+		iter: iter,
+	}
+	closer.Add(res)
+
+	return res
+}
+
+// recordIDIterator is returned by RecordIDIterator.
+type recordIDIterator struct {
+	iter types.DocumentsIterator
+}
+
+// Next implements iterator.Interface. See RecordIDIterator for details.
+func (iter *recordIDIterator) Next() (struct{}, *types.Document, error) {
+	var unused struct{}
+
+	_, doc, err := iter.iter.Next()
+	if err != nil {
+		return unused, nil, lazyerrors.Error(err)
+	}
+
+	doc.Set("$recordId", doc.RecordID())
+
+	return unused, doc, nil
+}
+
+// Close implements iterator.Interface. See RecordIDIterator for details.
+func (iter *recordIDIterator) Close() {
+	iter.iter.Close()
+}
+
+// check interfaces
+var (
+	_ types.DocumentsIterator = (*recordIDIterator)(nil)
+)
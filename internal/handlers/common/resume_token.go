@@ -0,0 +1,117 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/cursor"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// resumeTokenVersion is the version of the resume token binary format.
+const resumeTokenVersion = 1
+
+// resumeTokenLen is the length of the encoded resume token in bytes.
+const resumeTokenLen = 1 + 8 + 8
+
+// ResumeToken represents a position in the collection.
+//
+// It is returned to clients as postBatchResumeToken in cursor replies,
+// and is designed to be used by change streams and incremental backups too.
+// Clients should treat it as opaque.
+//
+// It is encoded like MongoDB tokens, as a document `{_data: <string>}`
+// with the uppercase hex representation of the following bytes:
+//   - format version (1 byte);
+//   - clock, the cluster time when the position was reached (8 bytes, big-endian types.Timestamp);
+//   - backend-specific record ID of the last seen document (8 bytes, big-endian);
+//     that's SQLite rowid or PostgreSQL LSN.
+//
+// Big-endian encoding makes the lexicographical order of encoded tokens of the same collection
+// the same as the order of positions.
+type ResumeToken struct {
+	Clock    types.Timestamp
+	RecordID int64
+}
+
+// Document returns the resume token document.
+func (rt *ResumeToken) Document() *types.Document {
+	b := make([]byte, resumeTokenLen)
+	b[0] = resumeTokenVersion
+	binary.BigEndian.PutUint64(b[1:], uint64(rt.Clock))
+	binary.BigEndian.PutUint64(b[9:], uint64(rt.RecordID))
+
+	return must.NotFail(types.NewDocument(
+		"_data", strings.ToUpper(hex.EncodeToString(b)),
+	))
+}
+
+// GetResumeToken returns the resume token for the given value of the field of the given command.
+//
+// Command error codes:
+//   - ErrBadValue when the value is not a valid resume token.
+func GetResumeToken(v any, field, command string) (*ResumeToken, error) {
+	invalid := commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrBadValue,
+		fmt.Sprintf("Invalid %s: %s", field, types.FormatAnyValue(v)),
+		command,
+	)
+
+	doc, ok := v.(*types.Document)
+	if !ok || doc.Len() != 1 {
+		return nil, invalid
+	}
+
+	v, _ = doc.Get("_data")
+
+	data, ok := v.(string)
+	if !ok {
+		return nil, invalid
+	}
+
+	b, err := hex.DecodeString(data)
+	if err != nil || len(b) != resumeTokenLen || b[0] != resumeTokenVersion {
+		return nil, invalid
+	}
+
+	return &ResumeToken{
+		Clock:    types.Timestamp(binary.BigEndian.Uint64(b[1:])),
+		RecordID: int64(binary.BigEndian.Uint64(b[9:])),
+	}, nil
+}
+
+// PostBatchResumeToken returns the resume token document for the position after the given batch of the cursor,
+// and remembers that position in the cursor.
+//
+// For empty batches, the position of the last returned document stays the same.
+func PostBatchResumeToken(c *cursor.Cursor, batch []*types.Document) *types.Document {
+	if len(batch) > 0 {
+		c.LastRecordID = batch[len(batch)-1].RecordID()
+	}
+
+	rt := &ResumeToken{
+		Clock:    types.NextTimestamp(time.Now()),
+		RecordID: c.LastRecordID,
+	}
+
+	return rt.Document()
+}
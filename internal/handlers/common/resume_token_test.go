@@ -0,0 +1,67 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestResumeToken(t *testing.T) {
+	t.Parallel()
+
+	rt := &ResumeToken{
+		Clock:    types.NewTimestamp(time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC), 42),
+		RecordID: 1234,
+	}
+
+	doc := rt.Document()
+	assert.Equal(t, "0164F129800000002A00000000000004D2", must.NotFail(doc.Get("_data")))
+
+	actual, err := GetResumeToken(doc, "$_resumeAfter", "find")
+	require.NoError(t, err)
+	assert.Equal(t, rt, actual)
+
+	next := &ResumeToken{Clock: rt.Clock, RecordID: rt.RecordID + 1}
+	assert.Less(t, must.NotFail(doc.Get("_data")), must.NotFail(next.Document().Get("_data")))
+
+	for name, v := range map[string]any{
+		"String":    "0164F129800000002A00000000000004D2",
+		"Empty":     must.NotFail(types.NewDocument()),
+		"Type":      must.NotFail(types.NewDocument("_data", int32(1))),
+		"Hex":       must.NotFail(types.NewDocument("_data", "foo")),
+		"Length":    must.NotFail(types.NewDocument("_data", "0164F12900")),
+		"Version":   must.NotFail(types.NewDocument("_data", "0264F129800000002A00000000000004D2")),
+		"ExtraKeys": must.NotFail(types.NewDocument("_data", "0164F129800000002A00000000000004D2", "foo", "bar")),
+	} {
+		name, v := name, v
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := GetResumeToken(v, "$_resumeAfter", "find")
+
+			var ce *commonerrors.CommandError
+			require.ErrorAs(t, err, &ce)
+			assert.Equal(t, commonerrors.ErrBadValue, ce.Code())
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// ReturnKeyIterator returns an iterator that replaces documents with their index key values
+// for find's returnKey option. It will be added to the given closer.
+//
+// Next method returns the next document with only index key fields of the given key pattern;
+// missing values are null. If key is nil (no index is used), the returned document is empty.
+//
+// Close method closes the underlying iterator.
+func ReturnKeyIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser, key *types.Document) types.DocumentsIterator { //nolint:lll // for readability
+	res := &returnKeyIterator{
+		iter: iter,
+		key:  key,
+	}
+	closer.Add(res)
+
+	return res
+}
+
+// returnKeyIterator is returned by ReturnKeyIterator.
+type returnKeyIterator struct {
+	iter types.DocumentsIterator
+	key  *types.Document
+}
+
+// Next implements iterator.Interface. See ReturnKeyIterator for details.
+func (iter *returnKeyIterator) Next() (struct{}, *types.Document, error) {
+	var unused struct{}
+
+	_, doc, err := iter.iter.Next()
+	if err != nil {
+		return unused, nil, lazyerrors.Error(err)
+	}
+
+	res := types.MakeDocument(iter.key.Len())
+
+	for _, field := range iter.key.Keys() {
+		res.Set(field, indexKeyValue(doc, field))
+	}
+
+	res.SetRecordID(doc.RecordID())
+
+	return unused, res, nil
+}
+
+// Close implements iterator.Interface. See ReturnKeyIterator for details.
+func (iter *returnKeyIterator) Close() {
+	iter.iter.Close()
+}
+
+// check interfaces
+var (
+	_ types.DocumentsIterator = (*returnKeyIterator)(nil)
+)
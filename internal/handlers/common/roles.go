@@ -0,0 +1,316 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// builtInRole represents a built-in role.
+type builtInRole struct {
+	// commands that are allowed by that role, sorted
+	commands []string
+
+	// cluster roles exist only in the admin database and allow commands for all databases
+	cluster bool
+}
+
+// readCommands are commands allowed by the read role.
+var readCommands = []string{
+	"aggregate",
+	"collStats",
+	"count",
+	"dataSize",
+	"dbStats",
+	"dbstats",
+	"distinct",
+	"explain",
+	"find",
+	"getMore",
+	"killCursors",
+	"listCollections",
+	"listIndexes",
+}
+
+// writeCommands are commands allowed by the readWrite role in addition to readCommands.
+var writeCommands = []string{
+	"cloneCollectionAsCapped",
+	"convertToCapped",
+	"create",
+	"createIndexes",
+	"delete",
+	"drop",
+	"dropIndexes",
+	"findAndModify",
+	"findandmodify",
+	"insert",
+	"renameCollection",
+	"update",
+}
+
+// bypassDocumentValidationAction is the action that allows write commands
+// to set the bypassDocumentValidation option.
+// Roles use command names as actions, so it is listed with them.
+const bypassDocumentValidationAction = "bypassDocumentValidation"
+
+// builtInRoles contains built-in roles by name.
+var builtInRoles = map[string]builtInRole{
+	"read": {
+		commands: sortedCommands(readCommands),
+	},
+	"readWrite": {
+		commands: sortedCommands(readCommands, writeCommands),
+	},
+	"dbAdmin": {
+		commands: sortedCommands([]string{
+			bypassDocumentValidationAction,
+			"collMod",
+			"collStats",
+			"compact",
+			"convertToCapped",
+			"create",
+			"createIndexes",
+			"dbStats",
+			"dbstats",
+			"drop",
+			"dropDatabase",
+			"dropIndexes",
+			"explain",
+			"listCollections",
+			"listIndexes",
+			"reIndex",
+			"renameCollection",
+			"validate",
+		}),
+	},
+	"clusterAdmin": {
+		commands: sortedCommands([]string{
+			"applyOps",
+			"archiveCollection",
+			"currentOp",
+			"debugError",
+			"dropDatabase",
+			"fsync",
+			"fsyncUnlock",
+			"getCmdLineOpts",
+			"getFreeMonitoringStatus",
+			"getLog",
+			"getParameter",
+			"hostInfo",
+			"killAllSessions",
+			"killOp",
+			"listCursors",
+			"listDatabases",
+			"logRotate",
+			"migrationAssessment",
+			"moveCollection",
+			"restoreCollection",
+			"serverStatus",
+			"setFreeMonitoring",
+			"setMaintenanceMode",
+			"setParameter",
+			"shutdown",
+			"top",
+		}),
+		cluster: true,
+	},
+}
+
+// sortedCommands returns a sorted union of the given command lists.
+func sortedCommands(lists ...[]string) []string {
+	var res []string
+	for _, l := range lists {
+		res = append(res, l...)
+	}
+
+	sort.Strings(res)
+
+	return slices.Compact(res)
+}
+
+// roleExists returns true if the built-in role with the given name exists in the given database.
+func roleExists(name, db string) bool {
+	r, ok := builtInRoles[name]
+	if !ok {
+		return false
+	}
+
+	return !r.cluster || db == UsersDatabase
+}
+
+// RoleNotFound returns protocol error for the role that does not exist.
+func RoleNotFound(command, db, name string) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrRoleNotFound,
+		fmt.Sprintf("Could not find role: %s@%s", name, db),
+		command,
+	)
+}
+
+// Authorize returns Unauthorized protocol error if the user authenticated on the connection
+// is not allowed to run the given command on the given database by any of the user's roles.
+//
+// Authorization is enforced only for users stored by FerretDB and authenticated with SCRAM-SHA-256;
+// for other connections, the backend's own access control is used, and nil is returned.
+// Roles are read at authentication time, so changes of user's roles are applied
+// when the user authenticates the next time.
+func Authorize(ctx context.Context, command, db string) error {
+	ok, err := authorized(ctx, command, db)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if ok {
+		return nil
+	}
+
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrUnauthorized,
+		fmt.Sprintf("not authorized on %s to execute command { %s: ... }", db, command),
+		command,
+	)
+}
+
+// AuthorizeBypassDocumentValidation returns Unauthorized protocol error if the given command document
+// sets the bypassDocumentValidation option, but the user authenticated on the connection
+// is not allowed to bypass document validation on the given database.
+//
+// Like Authorize, it is enforced only for users stored by FerretDB.
+func AuthorizeBypassDocumentValidation(ctx context.Context, document *types.Document, db string) error {
+	if v, _ := document.Get("bypassDocumentValidation"); v != true {
+		return nil
+	}
+
+	ok, err := authorized(ctx, bypassDocumentValidationAction, db)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if ok {
+		return nil
+	}
+
+	command := document.Command()
+
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrUnauthorized,
+		fmt.Sprintf("not authorized on %s to execute command { %s: ..., bypassDocumentValidation: true }", db, command),
+		command,
+	)
+}
+
+// authorized returns true if the user authenticated on the connection
+// is allowed to perform the given action on the given database by any of the user's roles,
+// or if authorization is not enforced for the connection.
+func authorized(ctx context.Context, action, db string) (bool, error) {
+	roles := conninfo.Get(ctx).Roles()
+	if roles == nil {
+		return true, nil
+	}
+
+	iter := roles.Iterator()
+	defer iter.Close()
+
+	for {
+		_, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return false, lazyerrors.Error(err)
+		}
+
+		doc, ok := v.(*types.Document)
+		if !ok {
+			continue
+		}
+
+		name, _ := doc.Get("role")
+		roleDB, _ := doc.Get("db")
+
+		n, _ := name.(string)
+
+		r, ok := builtInRoles[n]
+		if !ok {
+			continue
+		}
+
+		switch {
+		case r.cluster && roleDB != UsersDatabase:
+			continue
+		case !r.cluster && roleDB != db:
+			continue
+		}
+
+		if _, found := slices.BinarySearch(r.commands, action); found {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// roleInfo returns a document describing the built-in role in the given database
+// in the format of the rolesInfo command.
+func roleInfo(name, db string, showPrivileges bool) *types.Document {
+	res := must.NotFail(types.NewDocument(
+		"_id", db+"."+name,
+		"role", name,
+		"db", db,
+		"isBuiltin", true,
+		"roles", types.MakeArray(0),
+		"inheritedRoles", types.MakeArray(0),
+	))
+
+	if !showPrivileges {
+		return res
+	}
+
+	r := builtInRoles[name]
+
+	resource := must.NotFail(types.NewDocument("db", db, "collection", ""))
+	if r.cluster {
+		resource = must.NotFail(types.NewDocument("db", "", "collection", ""))
+	}
+
+	// FerretDB uses command names as actions
+	actions := types.MakeArray(len(r.commands))
+	for _, c := range r.commands {
+		actions.Append(c)
+	}
+
+	privileges := must.NotFail(types.NewArray(must.NotFail(types.NewDocument(
+		"resource", resource,
+		"actions", actions,
+	))))
+
+	res.Set("privileges", privileges)
+	res.Set("inheritedPrivileges", privileges.DeepCopy())
+
+	return res
+}
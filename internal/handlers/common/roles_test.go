@@ -0,0 +1,242 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestAuthorize(t *testing.T) {
+	t.Parallel()
+
+	role := func(name, db string) *types.Document {
+		return must.NotFail(types.NewDocument("role", name, "db", db))
+	}
+
+	for name, tc := range map[string]struct {
+		roles      *types.Array // nil means not authenticated with SCRAM
+		command    string
+		db         string
+		authorized bool
+	}{
+		"NotEnforced": {
+			command:    "insert",
+			db:         "test",
+			authorized: true,
+		},
+		"NoRoles": {
+			roles:   types.MakeArray(0),
+			command: "find",
+			db:      "test",
+		},
+		"Read": {
+			roles:      must.NotFail(types.NewArray(role("read", "test"))),
+			command:    "find",
+			db:         "test",
+			authorized: true,
+		},
+		"ReadInsert": {
+			roles:   must.NotFail(types.NewArray(role("read", "test"))),
+			command: "insert",
+			db:      "test",
+		},
+		"ReadOtherDatabase": {
+			roles:   must.NotFail(types.NewArray(role("read", "test"))),
+			command: "find",
+			db:      "other",
+		},
+		"ReadWrite": {
+			roles:      must.NotFail(types.NewArray(role("read", "other"), role("readWrite", "test"))),
+			command:    "insert",
+			db:         "test",
+			authorized: true,
+		},
+		"DBAdmin": {
+			roles:      must.NotFail(types.NewArray(role("dbAdmin", "test"))),
+			command:    "createIndexes",
+			db:         "test",
+			authorized: true,
+		},
+		"DBAdminFind": {
+			roles:   must.NotFail(types.NewArray(role("dbAdmin", "test"))),
+			command: "find",
+			db:      "test",
+		},
+		"ClusterAdmin": {
+			roles:      must.NotFail(types.NewArray(role("clusterAdmin", "admin"))),
+			command:    "listDatabases",
+			db:         "admin",
+			authorized: true,
+		},
+		"ClusterAdminOtherDatabase": {
+			roles:      must.NotFail(types.NewArray(role("clusterAdmin", "admin"))),
+			command:    "dropDatabase",
+			db:         "test",
+			authorized: true,
+		},
+		"ClusterAdminNotAdmin": {
+			roles:   must.NotFail(types.NewArray(role("clusterAdmin", "test"))),
+			command: "serverStatus",
+			db:      "test",
+		},
+		"UnknownRole": {
+			roles:   must.NotFail(types.NewArray(role("root", "admin"))),
+			command: "find",
+			db:      "test",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			connInfo := conninfo.NewConnInfo()
+			t.Cleanup(connInfo.Close)
+
+			connInfo.SetAuth("user", "")
+			connInfo.SetRoles(tc.roles)
+
+			ctx := conninfo.WithConnInfo(testutil.Ctx(t), connInfo)
+
+			err := Authorize(ctx, tc.command, tc.db)
+			if tc.authorized {
+				require.NoError(t, err)
+				return
+			}
+
+			var cmdErr *commonerrors.CommandError
+			require.ErrorAs(t, err, &cmdErr)
+			assert.Equal(t, commonerrors.ErrUnauthorized, cmdErr.Code())
+		})
+	}
+}
+
+func TestAuthorizeBypassDocumentValidation(t *testing.T) {
+	t.Parallel()
+
+	role := func(name, db string) *types.Document {
+		return must.NotFail(types.NewDocument("role", name, "db", db))
+	}
+
+	for name, tc := range map[string]struct {
+		roles      *types.Array // nil means not authenticated with SCRAM
+		bypass     any
+		authorized bool
+	}{
+		"NotEnforced": {
+			bypass:     true,
+			authorized: true,
+		},
+		"NotSet": {
+			roles:      must.NotFail(types.NewArray(role("readWrite", "test"))),
+			authorized: true,
+		},
+		"False": {
+			roles:      must.NotFail(types.NewArray(role("readWrite", "test"))),
+			bypass:     false,
+			authorized: true,
+		},
+		"ReadWrite": {
+			roles:  must.NotFail(types.NewArray(role("readWrite", "test"))),
+			bypass: true,
+		},
+		"DBAdmin": {
+			roles:      must.NotFail(types.NewArray(role("readWrite", "test"), role("dbAdmin", "test"))),
+			bypass:     true,
+			authorized: true,
+		},
+		"DBAdminOtherDatabase": {
+			roles:  must.NotFail(types.NewArray(role("readWrite", "test"), role("dbAdmin", "other"))),
+			bypass: true,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			connInfo := conninfo.NewConnInfo()
+			t.Cleanup(connInfo.Close)
+
+			connInfo.SetAuth("user", "")
+			connInfo.SetRoles(tc.roles)
+
+			ctx := conninfo.WithConnInfo(testutil.Ctx(t), connInfo)
+
+			document := must.NotFail(types.NewDocument("insert", "values"))
+			if tc.bypass != nil {
+				document.Set("bypassDocumentValidation", tc.bypass)
+			}
+
+			err := AuthorizeBypassDocumentValidation(ctx, document, "test")
+			if tc.authorized {
+				require.NoError(t, err)
+				return
+			}
+
+			var cmdErr *commonerrors.CommandError
+			require.ErrorAs(t, err, &cmdErr)
+			assert.Equal(t, commonerrors.ErrUnauthorized, cmdErr.Code())
+		})
+	}
+}
+
+func TestUserRolesParams(t *testing.T) {
+	t.Parallel()
+
+	user := must.NotFail(types.NewDocument(
+		"_id", "test.user",
+		"roles", must.NotFail(types.NewArray(must.NotFail(types.NewDocument("role", "read", "db", "test")))),
+	))
+
+	params, err := GetUserRolesParams(must.NotFail(types.NewDocument(
+		"grantRolesToUser", "user",
+		"roles", must.NotFail(types.NewArray("read", "readWrite")),
+		"$db", "test",
+	)), testutil.Logger(t))
+	require.NoError(t, err)
+
+	params.Grant(user)
+	assert.Equal(t, must.NotFail(types.NewArray(
+		must.NotFail(types.NewDocument("role", "read", "db", "test")),
+		must.NotFail(types.NewDocument("role", "readWrite", "db", "test")),
+	)), must.NotFail(user.Get("roles")))
+
+	params, err = GetUserRolesParams(must.NotFail(types.NewDocument(
+		"revokeRolesFromUser", "user",
+		"roles", must.NotFail(types.NewArray("read", "dbAdmin")),
+		"$db", "test",
+	)), testutil.Logger(t))
+	require.NoError(t, err)
+
+	params.Revoke(user)
+	assert.Equal(t, must.NotFail(types.NewArray(
+		must.NotFail(types.NewDocument("role", "readWrite", "db", "test")),
+	)), must.NotFail(user.Get("roles")))
+
+	_, err = GetUserRolesParams(must.NotFail(types.NewDocument(
+		"grantRolesToUser", "user",
+		"roles", must.NotFail(types.NewArray("clusterAdmin")),
+		"$db", "test",
+	)), testutil.Logger(t))
+	assert.Equal(t, RoleNotFound("grantRolesToUser", "test", "clusterAdmin"), err)
+}
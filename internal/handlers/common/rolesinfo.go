@@ -0,0 +1,137 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// RolesInfoParams represents parameters of the rolesInfo command.
+type RolesInfoParams struct {
+	DB string
+
+	// Roles contains {role, db} documents of requested roles.
+	// If it is nil, all roles of DB are requested.
+	Roles *types.Array
+
+	ShowPrivileges   bool
+	ShowBuiltinRoles bool
+}
+
+// GetRolesInfoParams returns parameters of the rolesInfo command.
+func GetRolesInfoParams(document *types.Document) (*RolesInfoParams, error) {
+	command := document.Command()
+
+	db, err := GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	params := RolesInfoParams{
+		DB: db,
+	}
+
+	for _, f := range []struct {
+		key string
+		dst *bool
+	}{
+		{key: "showPrivileges", dst: &params.ShowPrivileges},
+		{key: "showBuiltinRoles", dst: &params.ShowBuiltinRoles},
+	} {
+		v, _ := document.Get(f.key)
+		if v == nil {
+			continue
+		}
+
+		if *f.dst, err = commonparams.GetBoolOptionalParam(f.key, v); err != nil {
+			return nil, err
+		}
+	}
+
+	switch v := must.NotFail(document.Get(command)).(type) {
+	case float64, int32, int64:
+		// all roles of the database
+
+	case string, *types.Document:
+		if params.Roles, err = roleDocuments(command, db, must.NotFail(types.NewArray(v))); err != nil {
+			return nil, err
+		}
+
+	case *types.Array:
+		if params.Roles, err = roleDocuments(command, db, v); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("Role names must be either strings or objects, got %s", commonparams.AliasFromType(v)),
+			command,
+		)
+	}
+
+	return &params, nil
+}
+
+// RolesInfo returns the roles array of the rolesInfo command response.
+//
+// Only built-in roles exist; they are returned for all roles of the database
+// only if ShowBuiltinRoles is set, like in MongoDB.
+// Requested roles that do not exist are omitted.
+func RolesInfo(params *RolesInfoParams) *types.Array {
+	if params.Roles == nil {
+		res := types.MakeArray(0)
+
+		if !params.ShowBuiltinRoles {
+			return res
+		}
+
+		names := make([]string, 0, len(builtInRoles))
+		for name := range builtInRoles {
+			if roleExists(name, params.DB) {
+				names = append(names, name)
+			}
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			res.Append(roleInfo(name, params.DB, params.ShowPrivileges))
+		}
+
+		return res
+	}
+
+	res := types.MakeArray(params.Roles.Len())
+
+	for i := 0; i < params.Roles.Len(); i++ {
+		role := must.NotFail(params.Roles.Get(i)).(*types.Document)
+		name := must.NotFail(role.Get("role")).(string)
+		db := must.NotFail(role.Get("db")).(string)
+
+		if roleExists(name, db) {
+			res.Append(roleInfo(name, db, params.ShowPrivileges))
+		}
+	}
+
+	return res
+}
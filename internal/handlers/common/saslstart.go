@@ -16,44 +16,94 @@ package common
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 
 	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
-// SASLStart returns a common part of saslStart command response.
-func SASLStart(ctx context.Context, doc *types.Document) error {
+// SASLStart is a common implementation of the saslStart command.
+//
+// For the PLAIN mechanism, it stores username and password in the connection info
+// so the handler could authenticate the client in the backend.
+// For the SCRAM-SHA-256 mechanism, it starts the conversation that is continued by SASLContinue;
+// stored user credentials are fetched with getUser.
+//
+// It returns the response document.
+func SASLStart(ctx context.Context, doc *types.Document, getUser UserGetter) (*types.Document, error) {
 	mechanism, err := GetRequiredParam[string](doc, "mechanism")
 	if err != nil {
-		return lazyerrors.Error(err)
+		return nil, lazyerrors.Error(err)
 	}
 
-	var username, password string
-
 	switch mechanism {
 	case "PLAIN":
-		username, password, err = saslStartPlain(doc)
-
+		username, password, err := saslStartPlain(doc)
 		if err != nil {
-			return err
+			return nil, err
+		}
+
+		conninfo.Get(ctx).SetSASLConversation(nil, nil)
+		conninfo.Get(ctx).SetAuth(username, password)
+		conninfo.Get(ctx).SetMechanism(mechanism)
+
+		var emptyPayload types.Binary
+
+		return must.NotFail(types.NewDocument(
+			"conversationId", int32(1),
+			"done", true,
+			"payload", emptyPayload,
+			"ok", float64(1),
+		)), nil
+
+	case "SCRAM-SHA-256":
+		if getUser == nil {
+			break
 		}
 
-	default:
-		msg := fmt.Sprintf("Unsupported authentication mechanism %q.\n", mechanism) +
-			"See https://docs.ferretdb.io/security/authentication/ for more details."
-		err = commonerrors.NewCommandErrorMsgWithArgument(
-			commonerrors.ErrAuthenticationFailed,
-			msg,
-			"mechanism",
-		)
+		return saslStartSCRAMSHA256(ctx, doc, getUser)
+	}
+
+	msg := fmt.Sprintf("Unsupported authentication mechanism %q.\n", mechanism) +
+		"See https://docs.ferretdb.io/security/authentication/ for more details."
+
+	return nil, commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrAuthenticationFailed,
+		msg,
+		"mechanism",
+	)
+}
+
+// saslPayload returns the decoded payload of saslStart and saslContinue commands.
+func saslPayload(doc *types.Document) ([]byte, error) {
+	var payload []byte
 
-		return err
+	// some drivers send payload as a string
+	stringPayload, err := GetRequiredParam[string](doc, "payload")
+	if err == nil {
+		if payload, err = base64.StdEncoding.DecodeString(stringPayload); err != nil {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("Invalid payload: %v", err),
+				"payload",
+			)
+		}
+	}
+
+	// most drivers follow spec and send payload as a binary
+	binaryPayload, err := GetRequiredParam[types.Binary](doc, "payload")
+	if err == nil {
+		payload = binaryPayload.B
 	}
 
-	conninfo.Get(ctx).SetAuth(username, password)
+	// as spec's payload should be binary, we return an error mentioned binary as expected type
+	if payload == nil {
+		return nil, err
+	}
 
-	return nil
+	return payload, nil
 }
@@ -16,7 +16,6 @@ package common
 
 import (
 	"bytes"
-	"encoding/base64"
 	"fmt"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
@@ -25,28 +24,8 @@ import (
 
 // saslStartPlain extracts username and password from PLAIN `saslStart` payload.
 func saslStartPlain(doc *types.Document) (string, string, error) {
-	var payload []byte
-
-	// some drivers send payload as a string
-	stringPayload, err := GetRequiredParam[string](doc, "payload")
-	if err == nil {
-		if payload, err = base64.StdEncoding.DecodeString(stringPayload); err != nil {
-			return "", "", commonerrors.NewCommandErrorMsgWithArgument(
-				commonerrors.ErrBadValue,
-				fmt.Sprintf("Invalid payload: %v", err),
-				"payload",
-			)
-		}
-	}
-
-	// most drivers follow spec and send payload as a binary
-	binaryPayload, err := GetRequiredParam[types.Binary](doc, "payload")
-	if err == nil {
-		payload = binaryPayload.B
-	}
-
-	// as spec's payload should be binary, we return an error mentioned binary as expected type
-	if payload == nil {
+	payload, err := saslPayload(doc)
+	if err != nil {
 		return "", "", err
 	}
 
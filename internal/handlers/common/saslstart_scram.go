@@ -0,0 +1,253 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+
+	"github.com/xdg-go/scram"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+const (
+	// scramSHA256IterationCount is the default number of PBKDF2 iterations, the same as MongoDB's.
+	scramSHA256IterationCount = 15000
+
+	// scramSHA256SaltLen is the salt length in bytes, the same as MongoDB's (hash length - 4).
+	scramSHA256SaltLen = 28
+)
+
+// errUserNotFound is returned by SCRAM credential lookup when the user does not exist.
+var errUserNotFound = errors.New("user not found")
+
+// MakeSCRAMSHA256Credentials returns salted SCRAM-SHA-256 credentials for the given password
+// in the same format as MongoDB stores them.
+func MakeSCRAMSHA256Credentials(password string) (*types.Document, error) {
+	salt := make([]byte, scramSHA256SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// SASLprep is applied to the password by the client constructor
+	client, err := scram.SHA256.NewClient("", password, "")
+	if err != nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"Error preflighting SCRAM-SHA-256 credentials: "+err.Error(),
+			"pwd",
+		)
+	}
+
+	creds := client.GetStoredCredentials(scram.KeyFactors{
+		Salt:  string(salt),
+		Iters: scramSHA256IterationCount,
+	})
+
+	return must.NotFail(types.NewDocument(
+		"iterationCount", int32(creds.Iters),
+		"salt", base64.StdEncoding.EncodeToString(salt),
+		"storedKey", base64.StdEncoding.EncodeToString(creds.StoredKey),
+		"serverKey", base64.StdEncoding.EncodeToString(creds.ServerKey),
+	)), nil
+}
+
+// scramSHA256StoredCredentials returns SCRAM-SHA-256 credentials stored in the user document.
+func scramSHA256StoredCredentials(user *types.Document) (scram.StoredCredentials, error) {
+	var res scram.StoredCredentials
+
+	v, err := user.GetByPath(types.NewStaticPath("credentials", "SCRAM-SHA-256"))
+	if err != nil {
+		return res, errUserNotFound
+	}
+
+	credentials, ok := v.(*types.Document)
+	if !ok {
+		return res, lazyerrors.Errorf("invalid credentials type %T", v)
+	}
+
+	iters, err := GetRequiredParam[int32](credentials, "iterationCount")
+	if err != nil {
+		return res, lazyerrors.Error(err)
+	}
+
+	res.Iters = int(iters)
+
+	for _, f := range []struct {
+		key string
+		dst *[]byte
+	}{
+		{key: "salt"},
+		{key: "storedKey", dst: &res.StoredKey},
+		{key: "serverKey", dst: &res.ServerKey},
+	} {
+		s, err := GetRequiredParam[string](credentials, f.key)
+		if err != nil {
+			return res, lazyerrors.Error(err)
+		}
+
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return res, lazyerrors.Error(err)
+		}
+
+		if f.dst == nil {
+			res.Salt = string(b)
+			continue
+		}
+
+		*f.dst = b
+	}
+
+	return res, nil
+}
+
+// saslStartSCRAMSHA256 starts SCRAM-SHA-256 conversation and returns saslStart response document.
+func saslStartSCRAMSHA256(ctx context.Context, doc *types.Document, getUser UserGetter) (*types.Document, error) {
+	payload, err := saslPayload(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := GetRequiredParam[string](doc, "$db")
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// lookup errors other than errUserNotFound are internal errors, not authentication failures
+	var lookupErr error
+
+	var roles *types.Array
+
+	server, err := scram.SHA256.NewServer(func(username string) (scram.StoredCredentials, error) {
+		var user *types.Document
+		if user, lookupErr = getUser(ctx, db, username); lookupErr != nil {
+			return scram.StoredCredentials{}, lookupErr
+		}
+
+		if user == nil {
+			return scram.StoredCredentials{}, errUserNotFound
+		}
+
+		v, _ := user.Get("roles")
+		if roles, _ = v.(*types.Array); roles == nil {
+			roles = types.MakeArray(0)
+		}
+
+		creds, err := scramSHA256StoredCredentials(user)
+		if err != nil && !errors.Is(err, errUserNotFound) {
+			lookupErr = err
+		}
+
+		return creds, err
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	connInfo := conninfo.Get(ctx)
+	connInfo.SetSASLConversation(nil, nil)
+
+	conv := server.NewConversation()
+
+	response, err := conv.Step(string(payload))
+	if lookupErr != nil {
+		return nil, lazyerrors.Error(lookupErr)
+	}
+
+	if err != nil {
+		return nil, authenticationFailed("saslStart")
+	}
+
+	connInfo.SetSASLConversation(conv, roles)
+
+	return must.NotFail(types.NewDocument(
+		"conversationId", int32(1),
+		"done", false,
+		"payload", types.Binary{B: []byte(response)},
+		"ok", float64(1),
+	)), nil
+}
+
+// SASLContinue is a common implementation of the saslContinue command.
+//
+// It continues the SCRAM-SHA-256 conversation started by SASLStart.
+// When the conversation successfully completes, the username and user's roles are stored in the connection info
+// without a password, so the handler uses its own backend credentials, and roles are enforced by Authorize.
+//
+// It returns the response document.
+func SASLContinue(ctx context.Context, doc *types.Document) (*types.Document, error) {
+	payload, err := saslPayload(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	connInfo := conninfo.Get(ctx)
+
+	conv, roles := connInfo.SASLConversation()
+	if conv == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrProtocolError,
+			"No SASL session state found",
+			"saslContinue",
+		)
+	}
+
+	var response string
+
+	// the client may send an additional empty message after the conversation is completed
+	if !conv.Done() {
+		if response, err = conv.Step(string(payload)); err != nil {
+			connInfo.SetSASLConversation(nil, nil)
+			return nil, authenticationFailed("saslContinue")
+		}
+	}
+
+	if conv.Done() {
+		if !conv.Valid() {
+			connInfo.SetSASLConversation(nil, nil)
+			return nil, authenticationFailed("saslContinue")
+		}
+
+		connInfo.SetAuth(conv.Username(), "")
+		connInfo.SetRoles(roles)
+		connInfo.SetMechanism("SCRAM-SHA-256")
+	}
+
+	return must.NotFail(types.NewDocument(
+		"conversationId", int32(1),
+		"done", conv.Done(),
+		"payload", types.Binary{B: []byte(response)},
+		"ok", float64(1),
+	)), nil
+}
+
+// authenticationFailed returns a protocol error for failed authentication.
+//
+// Like MongoDB, it does not reveal the reason.
+func authenticationFailed(command string) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrAuthenticationFailed,
+		"Authentication failed.",
+		command,
+	)
+}
@@ -0,0 +1,169 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xdg-go/scram"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestSASLSCRAMSHA256(t *testing.T) {
+	t.Parallel()
+
+	roles := must.NotFail(types.NewArray(must.NotFail(types.NewDocument("role", "read", "db", "admin"))))
+
+	user, err := MakeUser("admin", "user", "password", roles)
+	require.NoError(t, err)
+
+	getUser := func(_ context.Context, db, username string) (*types.Document, error) {
+		if UserID(db, username) == "admin.user" {
+			return user, nil
+		}
+
+		return nil, nil
+	}
+
+	for name, tc := range map[string]struct {
+		username string
+		password string
+		err      error
+	}{
+		"Valid": {
+			username: "user",
+			password: "password",
+		},
+		"WrongPassword": {
+			username: "user",
+			password: "wrong",
+			err: commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrAuthenticationFailed,
+				"Authentication failed.",
+				"saslContinue",
+			),
+		},
+		"UnknownUser": {
+			username: "unknown",
+			password: "password",
+			err: commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrAuthenticationFailed,
+				"Authentication failed.",
+				"saslStart",
+			),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			connInfo := conninfo.NewConnInfo()
+			t.Cleanup(connInfo.Close)
+
+			ctx := conninfo.WithConnInfo(testutil.Ctx(t), connInfo)
+
+			client, err := scram.SHA256.NewClient(tc.username, tc.password, "")
+			require.NoError(t, err)
+
+			conv := client.NewConversation()
+
+			payload, err := conv.Step("")
+			require.NoError(t, err)
+
+			res, err := SASLStart(ctx, must.NotFail(types.NewDocument(
+				"saslStart", int32(1),
+				"mechanism", "SCRAM-SHA-256",
+				"payload", types.Binary{B: []byte(payload)},
+				"$db", "admin",
+			)), getUser)
+
+			for {
+				if err != nil {
+					break
+				}
+
+				assert.Equal(t, int32(1), must.NotFail(res.Get("conversationId")))
+				assert.Equal(t, float64(1), must.NotFail(res.Get("ok")))
+
+				// verify server signature, if any
+				if serverPayload := must.NotFail(res.Get("payload")).(types.Binary).B; len(serverPayload) > 0 {
+					payload, err = conv.Step(string(serverPayload))
+					require.NoError(t, err)
+				}
+
+				if must.NotFail(res.Get("done")).(bool) {
+					break
+				}
+
+				res, err = SASLContinue(ctx, must.NotFail(types.NewDocument(
+					"saslContinue", int32(1),
+					"conversationId", int32(1),
+					"payload", types.Binary{B: []byte(payload)},
+					"$db", "admin",
+				)))
+			}
+
+			username, password := connInfo.Auth()
+
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				assert.Empty(t, username)
+				assert.Nil(t, connInfo.Roles())
+
+				conv, convRoles := connInfo.SASLConversation()
+				assert.Nil(t, conv)
+				assert.Nil(t, convRoles)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, conv.Valid())
+			assert.Equal(t, tc.username, username)
+			assert.Empty(t, password)
+			assert.Equal(t, roles, connInfo.Roles())
+		})
+	}
+}
+
+func TestSASLContinueWithoutStart(t *testing.T) {
+	t.Parallel()
+
+	connInfo := conninfo.NewConnInfo()
+	t.Cleanup(connInfo.Close)
+
+	ctx := conninfo.WithConnInfo(testutil.Ctx(t), connInfo)
+
+	_, err := SASLContinue(ctx, must.NotFail(types.NewDocument(
+		"saslContinue", int32(1),
+		"conversationId", int32(1),
+		"payload", types.Binary{B: []byte("c=biws,r=nonce,p=proof")},
+	)))
+
+	expected := commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrProtocolError,
+		"No SASL session state found",
+		"saslContinue",
+	)
+	assert.Equal(t, expected, err)
+}
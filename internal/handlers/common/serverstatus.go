@@ -15,6 +15,7 @@
 package common
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"time"
@@ -28,7 +29,7 @@ import (
 )
 
 // ServerStatus returns a common part of serverStatus command response.
-func ServerStatus(state *state.State, cm *connmetrics.ConnMetrics) (*types.Document, error) {
+func ServerStatus(ctx context.Context, state *state.State, cm *connmetrics.ConnMetrics) (*types.Document, error) {
 	host, err := os.Hostname()
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -40,6 +41,7 @@ func ServerStatus(state *state.State, cm *connmetrics.ConnMetrics) (*types.Docum
 	}
 
 	uptime := time.Since(state.Start)
+	conns := cm.Connections.Stats()
 
 	metricsDoc := types.MakeDocument(0)
 
@@ -68,13 +70,20 @@ func ServerStatus(state *state.State, cm *connmetrics.ConnMetrics) (*types.Docum
 		"uptime", uptime.Seconds(),
 		"uptimeMillis", uptime.Milliseconds(),
 		"uptimeEstimate", int64(uptime.Seconds()),
-		"localTime", time.Now(),
+		"localTime", types.Now(),
+		"connections", must.NotFail(types.NewDocument(
+			"current", int32(conns.Current),
+			"available", int32(conns.Available),
+			"totalCreated", int32(conns.TotalCreated),
+			"active", int32(conns.Active),
+		)),
 		"freeMonitoring", must.NotFail(types.NewDocument(
 			"state", state.TelemetryString(),
 		)),
 		"metrics", must.NotFail(types.NewDocument(
 			"commands", metricsDoc,
 		)),
+		"logicalSessionRecordCache", LogicalSessionRecordCache(ctx),
 
 		// our extensions
 		"ferretdbVersion", version.Get().Version,
@@ -84,3 +93,46 @@ func ServerStatus(state *state.State, cm *connmetrics.ConnMetrics) (*types.Docum
 
 	return res, nil
 }
+
+// BackendStatus represents backend-specific health information.
+type BackendStatus struct {
+	Name    string
+	Version string // may be empty if FerretDB did not connect to the backend yet
+
+	PoolMaxOpen      int
+	PoolOpen         int
+	PoolInUse        int
+	PoolIdle         int
+	PoolWaitCount    int64
+	PoolWaitDuration time.Duration
+
+	LastError     error // nil if there were no errors
+	LastErrorTime time.Time
+}
+
+// SetBackendStatus sets FerretDB-specific ferretdb.backend section of serverStatus command response.
+func SetBackendStatus(res *types.Document, bs *BackendStatus) {
+	var lastError any = types.Null
+	if bs.LastError != nil {
+		lastError = must.NotFail(types.NewDocument(
+			"message", bs.LastError.Error(),
+			"time", bs.LastErrorTime,
+		))
+	}
+
+	backend := must.NotFail(types.NewDocument(
+		"name", bs.Name,
+		"version", bs.Version,
+		"pool", must.NotFail(types.NewDocument(
+			"maxOpen", int32(bs.PoolMaxOpen),
+			"open", int32(bs.PoolOpen),
+			"inUse", int32(bs.PoolInUse),
+			"idle", int32(bs.PoolIdle),
+			"waitCount", bs.PoolWaitCount,
+			"waitDurationMillis", bs.PoolWaitDuration.Milliseconds(),
+		)),
+		"lastError", lastError,
+	))
+
+	res.Set("ferretdb", must.NotFail(types.NewDocument("backend", backend)))
+}
@@ -0,0 +1,190 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/clientconn/session"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// TrackSession marks the logical session from the command's lsid field as used
+// by the current connection's user.
+//
+// Commands without lsid or with invalid lsid are ignored.
+func TrackSession(ctx context.Context, document *types.Document) {
+	v, _ := document.Get("lsid")
+	if v == nil {
+		return
+	}
+
+	lsid, ok := v.(*types.Document)
+	if !ok {
+		return
+	}
+
+	id, err := sessionID(document.Command(), "lsid", lsid)
+	if err != nil {
+		return
+	}
+
+	username, _ := conninfo.Get(ctx).Auth()
+	session.GetRegistry(ctx).Use(id, username)
+}
+
+// GetSessionIDs returns logical session IDs from the command's array of {id: <UUID>} documents.
+func GetSessionIDs(document *types.Document) ([]uuid.UUID, error) {
+	command := document.Command()
+	field := command + "." + command
+
+	arr, err := GetRequiredParam[*types.Array](document, command)
+	if err != nil {
+		return nil, sessionsTypeMismatch(field, must.NotFail(document.Get(command)), "array")
+	}
+
+	res := make([]uuid.UUID, arr.Len())
+
+	for i := 0; i < arr.Len(); i++ {
+		v := must.NotFail(arr.Get(i))
+
+		doc, ok := v.(*types.Document)
+		if !ok {
+			return nil, sessionsTypeMismatch(fmt.Sprintf("%s.%d", field, i), v, "object")
+		}
+
+		if res[i], err = sessionID(command, field, doc); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// GetSessionUsers returns usernames from the killAllSessions command's array of {user: <string>, db: <string>} documents.
+func GetSessionUsers(document *types.Document) ([]string, error) {
+	command := document.Command()
+	field := command + "." + command
+
+	arr, err := GetRequiredParam[*types.Array](document, command)
+	if err != nil {
+		return nil, sessionsTypeMismatch(field, must.NotFail(document.Get(command)), "array")
+	}
+
+	res := make([]string, arr.Len())
+
+	for i := 0; i < arr.Len(); i++ {
+		v := must.NotFail(arr.Get(i))
+
+		doc, ok := v.(*types.Document)
+		if !ok {
+			return nil, sessionsTypeMismatch(fmt.Sprintf("%s.%d", field, i), v, "object")
+		}
+
+		user, err := doc.Get("user")
+		if err != nil {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrMissingField,
+				fmt.Sprintf("BSON field '%s.user' is missing but a required field", field),
+				command,
+			)
+		}
+
+		if res[i], ok = user.(string); !ok {
+			return nil, sessionsTypeMismatch(field+".user", user, "string")
+		}
+	}
+
+	return res, nil
+}
+
+// SessionDocument returns logical session ID document {id: <UUID>}.
+func SessionDocument(id uuid.UUID) *types.Document {
+	return must.NotFail(types.NewDocument(
+		"id", types.Binary{Subtype: types.BinaryUUID, B: must.NotFail(id.MarshalBinary())},
+	))
+}
+
+// LogicalSessionRecordCache returns logicalSessionRecordCache part of serverStatus command response.
+func LogicalSessionRecordCache(ctx context.Context) *types.Document {
+	stats := session.GetRegistry(ctx).Stats()
+
+	return must.NotFail(types.NewDocument(
+		"activeSessionsCount", int32(stats.ActiveSessions),
+		"sessionsCollectionJobCount", stats.JobCount,
+		"lastSessionsCollectionJobDurationMillis", int32(stats.LastJobDuration.Milliseconds()),
+		"lastSessionsCollectionJobTimestamp", stats.LastJobTimestamp,
+		"lastSessionsCollectionJobEntriesRefreshed", int32(0),
+		"lastSessionsCollectionJobEntriesEnded", int32(stats.LastJobEntriesEnded),
+		"lastSessionsCollectionJobCursorsClosed", int32(0),
+		"transactionReaperJobCount", int64(0),
+		"lastTransactionReaperJobDurationMillis", int32(0),
+		"lastTransactionReaperJobTimestamp", stats.LastJobTimestamp,
+		"lastTransactionReaperJobEntriesCleanedUp", int32(0),
+		"sessionCatalogSize", int32(stats.ActiveSessions),
+	))
+}
+
+// sessionID returns logical session ID from the {id: <UUID>} document.
+func sessionID(command, field string, doc *types.Document) (uuid.UUID, error) {
+	v, err := doc.Get("id")
+	if err != nil {
+		return uuid.Nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMissingField,
+			fmt.Sprintf("BSON field '%s.id' is missing but a required field", field),
+			command,
+		)
+	}
+
+	bin, ok := v.(types.Binary)
+	if !ok {
+		return uuid.Nil, sessionsTypeMismatch(field+".id", v, "binData")
+	}
+
+	if bin.Subtype != types.BinaryUUID || len(bin.B) != 16 {
+		return uuid.Nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"uuid must be a 16-byte binary field with UUID (4) subtype",
+			command,
+		)
+	}
+
+	id, err := uuid.FromBytes(bin.B)
+	if err != nil {
+		return uuid.Nil, lazyerrors.Error(err)
+	}
+
+	return id, nil
+}
+
+// sessionsTypeMismatch returns TypeMismatch error for the field with the given value and expected type.
+func sessionsTypeMismatch(field string, v any, expected string) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrTypeMismatch,
+		fmt.Sprintf(
+			"BSON field '%s' is the wrong type '%s', expected type '%s'",
+			field, commonparams.AliasFromType(v), expected,
+		),
+		field,
+	)
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// Parameter represents a server parameter reported by the getParameter command
+// and changed by the setParameter command.
+//
+// Implementations should be safe for concurrent use.
+type Parameter interface {
+	// Get returns the current value.
+	Get() any
+
+	// Set validates and sets a new value.
+	// It returns protocol error if the value is invalid.
+	Set(value any) error
+}
+
+// SetParameter is a common implementation of the setParameter command.
+//
+// Only parameters of the given registry that are settable at runtime could be set.
+func SetParameter(_ context.Context, msg *wire.OpMsg, l *zap.Logger, parameters *Parameters) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	Ignored(document, l, "comment")
+
+	command := document.Command()
+	res := must.NotFail(types.NewDocument())
+
+	iter := document.Iterator()
+	defer iter.Close()
+
+	for {
+		k, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		switch k {
+		case command, "$db", "comment", "lsid", "$clusterTime", "$readPreference":
+			continue
+		}
+
+		p := parameters.m[k]
+		if p == nil {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrInvalidOptions,
+				fmt.Sprintf("attempted to set unrecognized parameter [%s], use help:true to see options ", k),
+				command,
+			)
+		}
+
+		if !p.settableAtRuntime {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrIllegalOperation,
+				fmt.Sprintf("not allowed to change [%s] at runtime", k),
+				command,
+			)
+		}
+
+		was := p.Get()
+		if err = p.Set(v); err != nil {
+			return nil, err
+		}
+
+		l.Info("Parameter was set", zap.String("name", k), zap.Any("was", was), zap.Any("value", v))
+
+		// like MongoDB, report the previous value of the first parameter only
+		if !res.Has("was") {
+			res.Set("was", was)
+		}
+	}
+
+	if !res.Has("was") {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidOptions,
+			"no option found to set, use help:true to see options ",
+			command,
+		)
+	}
+
+	res.Set("ok", float64(1))
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{res},
+	}))
+
+	return &reply, nil
+}
@@ -23,14 +23,16 @@ import (
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 // SortDocuments sorts given documents in place according to the given sorting conditions.
+// Strings are compared using the given collation; nil collation means binary comparison.
 //
 // If sort path is invalid, it returns a possibly wrapped types.PathError.
-func SortDocuments(docs []*types.Document, sortDoc *types.Document) error {
+func SortDocuments(docs []*types.Document, sortDoc *types.Document, collation *Collation) error {
 	if sortDoc.Len() == 0 {
 		return nil
 	}
@@ -39,11 +41,10 @@ func SortDocuments(docs []*types.Document, sortDoc *types.Document) error {
 		return lazyerrors.Errorf("maximum sort keys exceeded: %v", sortDoc.Len())
 	}
 
-	sortFuncs := make([]sortFunc, len(sortDoc.Keys()))
+	fields := make([]sortField, sortDoc.Len())
 
-	for i, sortKey := range sortDoc.Keys() {
-		fields := strings.Split(sortKey, ".")
-		for _, field := range fields {
+	for i, key := range sortDoc.Keys() {
+		for _, field := range strings.Split(key, ".") {
 			if strings.HasPrefix(field, "$") {
 				return commonerrors.NewCommandErrorMsgWithArgument(
 					commonerrors.ErrFieldPathInvalidName,
@@ -53,90 +54,173 @@ func SortDocuments(docs []*types.Document, sortDoc *types.Document) error {
 			}
 		}
 
-		sortField := must.NotFail(sortDoc.Get(sortKey))
+		sortValue := must.NotFail(sortDoc.Get(key))
 
-		sortType, err := GetSortType(sortKey, sortField)
+		// documents with higher text scores go first
+		if IsTextScoreMeta(sortValue) {
+			fields[i] = sortField{sortType: types.Descending, textScore: true}
+			continue
+		}
+
+		sortType, err := GetSortType(key, sortValue)
 		if err != nil {
 			return err
 		}
 
-		sortPath, err := types.NewPathFromString(sortKey)
+		sortPath, err := types.NewPathFromString(key)
 		if err != nil {
 			return err
 		}
 
-		sortFuncs[i] = lessFunc(sortPath, sortType)
+		fields[i] = sortField{path: sortPath, sortType: sortType}
 	}
 
-	if len(sortFuncs) == 0 {
-		// no keys to sort by
-		return nil
+	// sort keys are computed once for each document
+	keys := make([][]any, len(docs))
+
+	for i, doc := range docs {
+		keys[i] = make([]any, len(fields))
+
+		for j, field := range fields {
+			if field.textScore {
+				keys[i][j] = doc.TextScore()
+				continue
+			}
+
+			keys[i][j] = sortKey(doc, field.path, field.sortType, collation)
+		}
 	}
 
-	sorter := &docsSorter{docs: docs, sorts: sortFuncs}
-	sort.Sort(sorter)
+	// documents with equal sort keys keep their order
+	sort.Stable(&docsSorter{docs: docs, keys: keys, fields: fields})
 
 	return nil
 }
 
-// lessFunc takes sort key and type and returns sort.Interface's Less function which
-// compares selected key of 2 documents.
-func lessFunc(sortPath types.Path, sortType types.SortType) func(a, b *types.Document) bool {
-	return func(a, b *types.Document) bool {
-		aField, err := a.GetByPath(sortPath)
-		if err != nil {
-			// sort order treats null and non-existent field equivalent,
-			// hence use null for sorting.
-			aField = types.Null
+// sortField represents a single sort condition.
+type sortField struct {
+	path      types.Path
+	sortType  types.SortType
+	textScore bool
+}
+
+// sortKey returns the value used to sort the document by the given path, like MongoDB does.
+//
+// Values are collected through arrays of documents, and arrays at the end of the path are replaced by their elements.
+// The minimal value is used for ascending sort, and the maximal value is used for descending sort.
+// Missing values are treated as null.
+// Empty arrays at the end of the path are returned as nil that sorts before all other values.
+// Strings are replaced by collation keys.
+func sortKey(doc *types.Document, path types.Path, sortType types.SortType, collation *Collation) any {
+	var key any
+	var found bool
+
+	opts := &types.WalkPathOpts{
+		ArrayIndexes:   true,
+		ArrayDocuments: true,
+		Missing:        true,
+	}
+
+	_ = types.WalkPath(doc, path, opts, func(_ types.Path, v any) error {
+		var values []any
+
+		switch v := v.(type) {
+		case nil:
+			values = []any{types.Null}
+		case *types.Array:
+			values = []any{nil}
+			if v.Len() > 0 {
+				values = must.NotFail(iterator.ConsumeValues(v.Iterator()))
+			}
+		default:
+			values = []any{v}
 		}
 
-		bField, err := b.GetByPath(sortPath)
-		if err != nil {
-			// same logic as above
-			bField = types.Null
+		for _, value := range values {
+			if value != nil {
+				value = collation.keyValue(value)
+			}
+
+			if !found {
+				key, found = value, true
+				continue
+			}
+
+			res := compareSortKeys(value, key)
+			if (sortType == types.Ascending && res == types.Less) || (sortType == types.Descending && res == types.Greater) {
+				key = value
+			}
 		}
 
-		result := types.CompareOrderForSort(aField, bField, sortType)
+		return nil
+	})
 
-		return result == types.Less
+	if !found {
+		// arrays without documents
+		return types.Null
 	}
+
+	return key
 }
 
-type sortFunc func(a, b *types.Document) bool
+// compareSortKeys compares values returned by sortKey in ascending order.
+func compareSortKeys(a, b any) types.CompareResult {
+	switch {
+	case a == nil && b == nil:
+		return types.Equal
+	case a == nil:
+		return types.Less
+	case b == nil:
+		return types.Greater
+	default:
+		return types.CompareOrder(a, b, types.Ascending)
+	}
+}
 
+// docsSorter implements sort.Interface for documents and their sort keys.
 type docsSorter struct {
-	docs  []*types.Document
-	sorts []sortFunc
+	docs   []*types.Document
+	keys   [][]any
+	fields []sortField
 }
 
+// Len implements sort.Interface.
 func (ds *docsSorter) Len() int {
 	return len(ds.docs)
 }
 
+// Swap implements sort.Interface.
 func (ds *docsSorter) Swap(i, j int) {
 	ds.docs[i], ds.docs[j] = ds.docs[j], ds.docs[i]
+	ds.keys[i], ds.keys[j] = ds.keys[j], ds.keys[i]
 }
 
+// Less implements sort.Interface.
 func (ds *docsSorter) Less(i, j int) bool {
-	p, q := ds.docs[i], ds.docs[j]
-	// Try all but the last comparison.
-	var k int
-	for k = 0; k < len(ds.sorts)-1; k++ {
-		sortFunc := ds.sorts[k]
+	for k, field := range ds.fields {
+		res := compareSortKeys(ds.keys[i][k], ds.keys[j][k])
+		if res == types.Equal {
+			continue
+		}
 
-		switch {
-		case sortFunc(p, q):
-			// p < q, so we have a decision.
-			return true
-		case sortFunc(q, p):
-			// p > q, so we have a decision.
-			return false
+		if field.sortType == types.Descending {
+			return res == types.Greater
 		}
-		// p == q; try the next comparison.
+
+		return res == types.Less
 	}
-	// All comparisons to here said "equal", so just return whatever
-	// the final comparison reports.
-	return ds.sorts[k](p, q)
+
+	return false
+}
+
+// GetNaturalSortOrder returns the order of `{$natural: <order>}` document used as sort or hint.
+// For any other document, it returns zero value.
+func GetNaturalSortOrder(doc *types.Document) (types.SortType, error) {
+	if doc.Len() != 1 || !doc.Has("$natural") {
+		return 0, nil
+	}
+
+	return GetSortType("$natural", must.NotFail(doc.Get("$natural")))
 }
 
 // GetSortType determines SortType from input sort value.
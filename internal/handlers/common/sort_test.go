@@ -0,0 +1,141 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestSortDocuments(t *testing.T) {
+	t.Parallel()
+
+	doc := func(pairs ...any) *types.Document {
+		return must.NotFail(types.NewDocument(pairs...))
+	}
+
+	arr := func(values ...any) *types.Array {
+		return must.NotFail(types.NewArray(values...))
+	}
+
+	for name, tc := range map[string]struct {
+		docs     []*types.Document
+		sort     *types.Document
+		expected []int32 // _id values
+	}{
+		"ArraysAscending": {
+			docs: []*types.Document{
+				doc("_id", int32(1), "v", arr(int32(1), int32(5))),
+				doc("_id", int32(2), "v", int32(3)),
+				doc("_id", int32(3), "v", types.MakeArray(0)),
+				doc("_id", int32(4)),
+				doc("_id", int32(5), "v", types.Null),
+				doc("_id", int32(6), "v", arr("foo", int32(2))),
+			},
+			sort:     doc("v", int32(1)),
+			expected: []int32{3, 4, 5, 1, 6, 2},
+		},
+		"ArraysDescending": {
+			docs: []*types.Document{
+				doc("_id", int32(1), "v", arr(int32(1), int32(5))),
+				doc("_id", int32(2), "v", int32(3)),
+				doc("_id", int32(3), "v", types.MakeArray(0)),
+				doc("_id", int32(4)),
+				doc("_id", int32(5), "v", types.Null),
+				doc("_id", int32(6), "v", arr("foo", int32(2))),
+			},
+			sort:     doc("v", int32(-1)),
+			expected: []int32{6, 1, 2, 4, 5, 3},
+		},
+		"NestedArray": {
+			docs: []*types.Document{
+				doc("_id", int32(1), "v", arr(arr(int32(0)))),
+				doc("_id", int32(2), "v", arr(int32(1), arr(int32(0)))),
+				doc("_id", int32(3), "v", int32(2)),
+			},
+			sort:     doc("v", int32(1)),
+			expected: []int32{2, 3, 1},
+		},
+		"ArrayDocuments": {
+			docs: []*types.Document{
+				doc("_id", int32(1), "a", arr(doc("b", int32(3)), doc("b", int32(1)))),
+				doc("_id", int32(2), "a", arr(doc("b", int32(2)))),
+				doc("_id", int32(3), "a", arr(doc("c", int32(1)))),
+				doc("_id", int32(4), "a", arr(doc("b", int32(4)), doc())),
+			},
+			sort:     doc("a.b", int32(1)),
+			expected: []int32{3, 4, 1, 2},
+		},
+		"ArrayDocumentsDescending": {
+			docs: []*types.Document{
+				doc("_id", int32(1), "a", arr(doc("b", int32(3)), doc("b", int32(1)))),
+				doc("_id", int32(2), "a", arr(doc("b", int32(2)))),
+				doc("_id", int32(3), "a", arr(doc("c", int32(1)))),
+				doc("_id", int32(4), "a", arr(doc("b", int32(4)), doc())),
+			},
+			sort:     doc("a.b", int32(-1)),
+			expected: []int32{4, 1, 2, 3},
+		},
+		"ArrayIndex": {
+			docs: []*types.Document{
+				doc("_id", int32(1), "v", arr(int32(3), int32(0))),
+				doc("_id", int32(2), "v", arr(int32(1))),
+				doc("_id", int32(3), "v", arr(doc("0", int32(2)))),
+			},
+			sort:     doc("v.0", int32(1)),
+			expected: []int32{2, 1, 3},
+		},
+		"Stable": {
+			docs: []*types.Document{
+				doc("_id", int32(1), "v", int32(1)),
+				doc("_id", int32(2), "v", int64(0)),
+				doc("_id", int32(3), "v", float64(1)),
+				doc("_id", int32(4), "v", int64(1)),
+				doc("_id", int32(5), "v", float64(0)),
+			},
+			sort:     doc("v", int32(1)),
+			expected: []int32{2, 5, 1, 3, 4},
+		},
+		"MultipleKeys": {
+			docs: []*types.Document{
+				doc("_id", int32(1), "a", int32(1), "b", arr(int32(1), int32(4))),
+				doc("_id", int32(2), "a", int32(1), "b", int32(2)),
+				doc("_id", int32(3), "a", int32(0), "b", int32(3)),
+			},
+			sort:     doc("a", int32(-1), "b", int32(-1)),
+			expected: []int32{1, 2, 3},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := SortDocuments(tc.docs, tc.sort, nil)
+			require.NoError(t, err)
+
+			actual := make([]int32, len(tc.docs))
+			for i, doc := range tc.docs {
+				actual[i] = must.NotFail(doc.Get("_id")).(int32)
+			}
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,595 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonpath"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// TextIndexWildcard is the text index field that indexes all string fields of the document.
+const TextIndexWildcard = "$**"
+
+// TextIndex represents the text index of the collection used by $text query operator.
+type TextIndex struct {
+	// Weights contains weights of indexed fields by their dot notation paths.
+	Weights map[string]int32
+
+	// DefaultLanguage is the language of documents and queries that do not specify it.
+	DefaultLanguage string
+
+	// LanguageOverride is the name of the document field that contains the language of the document.
+	LanguageOverride string
+}
+
+// textLanguages maps language names and ISO 639-1 codes supported by text indexes to language names.
+var textLanguages = map[string]string{
+	"none":       "none",
+	"danish":     "danish",
+	"da":         "danish",
+	"dutch":      "dutch",
+	"nl":         "dutch",
+	"english":    "english",
+	"en":         "english",
+	"finnish":    "finnish",
+	"fi":         "finnish",
+	"french":     "french",
+	"fr":         "french",
+	"german":     "german",
+	"de":         "german",
+	"hungarian":  "hungarian",
+	"hu":         "hungarian",
+	"italian":    "italian",
+	"it":         "italian",
+	"norwegian":  "norwegian",
+	"nb":         "norwegian",
+	"portuguese": "portuguese",
+	"pt":         "portuguese",
+	"romanian":   "romanian",
+	"ro":         "romanian",
+	"russian":    "russian",
+	"ru":         "russian",
+	"spanish":    "spanish",
+	"es":         "spanish",
+	"swedish":    "swedish",
+	"sv":         "swedish",
+	"turkish":    "turkish",
+	"tr":         "turkish",
+}
+
+// TextLanguage returns the language name for the given language name or code supported by text indexes.
+// It returns false if the language is not supported.
+func TextLanguage(language string) (string, bool) {
+	res, ok := textLanguages[strings.ToLower(language)]
+	return res, ok
+}
+
+// englishStopWords contains English words that are not indexed and ignored in search strings.
+var englishStopWords = map[string]struct{}{}
+
+func init() {
+	for _, w := range strings.Fields(`
+		a about above after again against all am an and any are as at be because been before being below
+		between both but by cannot could did do does doing down during each few for from further had has
+		have having he her here hers herself him himself his how i if in into is it its itself me more most
+		my myself no nor not of off on once only or other ought our ours ourselves out over own same she
+		should so some such than that the their theirs them themselves then there these they this those
+		through to too under until up very was we were what when where which while who whom why with would
+		you your yours yourself yourselves
+	`) {
+		englishStopWords[w] = struct{}{}
+	}
+}
+
+// TextSearch represents parameters of the $text query operator.
+type TextSearch struct {
+	index              *TextIndex
+	terms              []string
+	negatedTerms       []string
+	phrases            []string
+	negatedPhrases     []string
+	caseSensitive      bool
+	diacriticSensitive bool
+}
+
+// NewTextSearch returns $text query operator parameters for the given operator value and text index.
+//
+// If the index is nil, it returns an error after the operator value is validated.
+func NewTextSearch(expr any, index *TextIndex) (*TextSearch, error) {
+	doc, ok := expr.(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"$text expects an object",
+			"$text",
+		)
+	}
+
+	res := &TextSearch{
+		index: index,
+	}
+
+	var search, language string
+	var hasSearch bool
+
+	for _, k := range doc.Keys() {
+		v := must.NotFail(doc.Get(k))
+
+		switch k {
+		case "$search":
+			if search, hasSearch = v.(string); !hasSearch {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrBadValue, "$search needs a String", "$text")
+			}
+
+		case "$language":
+			l, ok := v.(string)
+			if !ok {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrBadValue, "$language needs a String", "$text")
+			}
+
+			if language, ok = TextLanguage(l); !ok {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrBadValue,
+					fmt.Sprintf("unsupported language: %q for text index version 3", l),
+					"$text",
+				)
+			}
+
+		case "$caseSensitive":
+			if res.caseSensitive, ok = v.(bool); !ok {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrBadValue,
+					"$caseSensitive needs a boolean",
+					"$text",
+				)
+			}
+
+		case "$diacriticSensitive":
+			if res.diacriticSensitive, ok = v.(bool); !ok {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrBadValue,
+					"$diacriticSensitive needs a boolean",
+					"$text",
+				)
+			}
+
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrBadValue, "extra fields in $text", "$text")
+		}
+	}
+
+	if !hasSearch {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrBadValue, "$search needs a String", "$text")
+	}
+
+	if index == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrIndexNotFound,
+			"text index required for $text query",
+			"$text",
+		)
+	}
+
+	if language == "" {
+		language = index.DefaultLanguage
+	}
+
+	res.parse(search, language)
+
+	return res, nil
+}
+
+// parse parses the search string.
+//
+// Terms are separated by whitespaces and punctuation; terms prefixed by hyphen are negated.
+// Phrases are enclosed in double quotes; their terms are also added to the search terms.
+func (s *TextSearch) parse(search, language string) {
+	rs := []rune(search)
+
+	var negated, inPhrase, phraseNegated bool
+	var phraseStart int
+
+	for i := 0; i < len(rs); {
+		r := rs[i]
+
+		switch {
+		case r == '"':
+			if inPhrase {
+				phrase := string(rs[phraseStart:i])
+				if phraseNegated {
+					s.negatedPhrases = append(s.negatedPhrases, phrase)
+				} else {
+					s.phrases = append(s.phrases, phrase)
+				}
+			} else {
+				phraseStart = i + 1
+				phraseNegated = negated
+			}
+
+			inPhrase = !inPhrase
+			negated = false
+			i++
+
+		case r == '-' && !inPhrase && (i == 0 || unicode.IsSpace(rs[i-1])):
+			negated = true
+			i++
+
+		case isTextRune(r):
+			j := i
+			for j < len(rs) && isTextRune(rs[j]) {
+				j++
+			}
+
+			term, ok := s.term(string(rs[i:j]), language)
+
+			switch {
+			case !ok:
+				// stop word
+			case inPhrase && phraseNegated:
+				// terms of negated phrases are not negated themselves
+			case negated && !inPhrase:
+				s.negatedTerms = appendUnique(s.negatedTerms, term)
+			default:
+				s.terms = appendUnique(s.terms, term)
+			}
+
+			i = j
+
+		default:
+			if unicode.IsSpace(r) && !inPhrase {
+				negated = false
+			}
+
+			i++
+		}
+	}
+}
+
+// term returns the normalized and stemmed term for the given token.
+// It returns false if the token is a stop word.
+func (s *TextSearch) term(token, language string) (string, bool) {
+	token = s.normalize(token)
+
+	if language == "english" {
+		if _, ok := englishStopWords[strings.ToLower(token)]; ok {
+			return "", false
+		}
+
+		token = stemEnglish(token)
+	}
+
+	return token, true
+}
+
+// normalize returns the string with case and diacritics folded as requested by the search.
+func (s *TextSearch) normalize(str string) string {
+	if !s.caseSensitive {
+		str = strings.ToLower(str)
+	}
+
+	if !s.diacriticSensitive {
+		t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+		if res, _, err := transform.String(t, str); err == nil {
+			str = res
+		}
+	}
+
+	return str
+}
+
+// Score returns the text score of the document and true if the document matches the search.
+//
+// Scores are calculated like MongoDB does: term frequencies within each indexed string are weighted
+// by the ratio of term occurrences to the number of string's terms and by the field's weight,
+// and scores of all search terms are summed.
+func (s *TextSearch) Score(doc *types.Document) (float64, bool, error) {
+	language := s.index.DefaultLanguage
+
+	if s.index.LanguageOverride != "" {
+		if v, err := doc.Get(s.index.LanguageOverride); err == nil {
+			if l, ok := v.(string); ok {
+				if l, ok = TextLanguage(l); ok {
+					language = l
+				}
+			}
+		}
+	}
+
+	scores := map[string]float64{}
+
+	var texts []string
+
+	err := s.index.strings(doc, func(str string, weight int32) {
+		texts = append(texts, s.normalize(str))
+		s.scoreString(scores, str, float64(weight), language)
+	})
+	if err != nil {
+		return 0, false, lazyerrors.Error(err)
+	}
+
+	for _, term := range s.negatedTerms {
+		if _, ok := scores[term]; ok {
+			return 0, false, nil
+		}
+	}
+
+	for _, phrase := range s.negatedPhrases {
+		if containsPhrase(texts, s.normalize(phrase)) {
+			return 0, false, nil
+		}
+	}
+
+	for _, phrase := range s.phrases {
+		if !containsPhrase(texts, s.normalize(phrase)) {
+			return 0, false, nil
+		}
+	}
+
+	var res float64
+	var found bool
+
+	for _, term := range s.terms {
+		if score, ok := scores[term]; ok {
+			res += score
+			found = true
+		}
+	}
+
+	return res, found, nil
+}
+
+// scoreString adds scores of terms of the given indexed string to scores.
+func (s *TextSearch) scoreString(scores map[string]float64, str string, weight float64, language string) {
+	type termStats struct {
+		freq  float64
+		exp   float64
+		count int
+	}
+
+	var terms []string
+	var tokens int
+	stats := map[string]*termStats{}
+
+	for _, token := range strings.FieldsFunc(str, func(r rune) bool { return !isTextRune(r) }) {
+		term, ok := s.term(token, language)
+		if !ok {
+			continue
+		}
+
+		st := stats[term]
+		if st == nil {
+			st = &termStats{exp: 1}
+			stats[term] = st
+			terms = append(terms, term)
+		} else {
+			st.exp *= 2
+		}
+
+		st.freq += 1 / st.exp
+		st.count++
+		tokens++
+	}
+
+	for _, term := range terms {
+		st := stats[term]
+
+		coeff := 0.5*float64(st.count)/float64(tokens) + 0.5
+
+		// strings consisting of a single unstemmed term get a small boost
+		adjustment := 1.0
+		if len(str) == len(term) && strings.EqualFold(str, term) {
+			adjustment += 0.1
+		}
+
+		scores[term] += weight * st.freq * coeff * adjustment
+	}
+}
+
+// strings calls fn for all string values of the document indexed by the text index, with their weights.
+func (idx *TextIndex) strings(doc *types.Document, fn func(str string, weight int32)) error {
+	if _, ok := idx.Weights[TextIndexWildcard]; ok {
+		idx.walkStrings("", doc, fn)
+		return nil
+	}
+
+	fields := maps.Keys(idx.Weights)
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		path, err := types.NewPathFromString(field)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		values, err := commonpath.FindValues(doc, path, &commonpath.FindValuesOpts{FindArrayDocuments: true})
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		for _, v := range values {
+			switch v := v.(type) {
+			case string:
+				fn(v, idx.Weights[field])
+
+			case *types.Array:
+				for i := 0; i < v.Len(); i++ {
+					if str, ok := must.NotFail(v.Get(i)).(string); ok {
+						fn(str, idx.Weights[field])
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// walkStrings calls fn for all string values of the wildcard text index
+// within the given value with the given dot notation path.
+// Fields with explicit weights use them, other fields use the wildcard weight.
+func (idx *TextIndex) walkStrings(path string, v any, fn func(str string, weight int32)) {
+	switch v := v.(type) {
+	case string:
+		weight, ok := idx.Weights[path]
+		if !ok {
+			weight = idx.Weights[TextIndexWildcard]
+		}
+
+		fn(v, weight)
+
+	case *types.Document:
+		for _, k := range v.Keys() {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+
+			idx.walkStrings(p, must.NotFail(v.Get(k)), fn)
+		}
+
+	case *types.Array:
+		for i := 0; i < v.Len(); i++ {
+			idx.walkStrings(path, must.NotFail(v.Get(i)), fn)
+		}
+	}
+}
+
+// FindText returns the value of the top-level $text query operator and the filter without it.
+// If the filter does not contain $text, it returns nil and the filter itself.
+//
+// $text is supported only at the top level of the filter.
+func FindText(filter *types.Document) (any, *types.Document, error) {
+	if filter == nil {
+		return nil, filter, nil
+	}
+
+	for _, k := range filter.Keys() {
+		if k != "$text" && hasTextOperator(must.NotFail(filter.Get(k))) {
+			return nil, nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				"$text is supported only at the top level of the filter",
+				"$text",
+			)
+		}
+	}
+
+	text, err := filter.Get("$text")
+	if err != nil {
+		return nil, filter, nil
+	}
+
+	res := filter.DeepCopy()
+	res.Remove("$text")
+
+	return text, res, nil
+}
+
+// hasTextOperator returns true if the value contains $text operator at any level.
+func hasTextOperator(v any) bool {
+	switch v := v.(type) {
+	case *types.Document:
+		for _, k := range v.Keys() {
+			if k == "$text" || hasTextOperator(must.NotFail(v.Get(k))) {
+				return true
+			}
+		}
+
+	case *types.Array:
+		for i := 0; i < v.Len(); i++ {
+			if hasTextOperator(must.NotFail(v.Get(i))) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// IsTextScoreMeta returns true if the projection or sort value is {$meta: "textScore"}.
+func IsTextScoreMeta(v any) bool {
+	doc, ok := v.(*types.Document)
+	if !ok || doc.Len() != 1 {
+		return false
+	}
+
+	meta, _ := doc.Get("$meta")
+
+	return meta == "textScore"
+}
+
+// CheckTextScore returns an error if the projection or the sort use {$meta: "textScore"},
+// but the query does not contain $text operator.
+func CheckTextScore(text any, projection, sort *types.Document) error {
+	if text != nil {
+		return nil
+	}
+
+	for _, doc := range []*types.Document{projection, sort} {
+		if doc == nil {
+			continue
+		}
+
+		for _, v := range doc.Values() {
+			if IsTextScoreMeta(v) {
+				return commonerrors.NewCommandErrorMsg(
+					commonerrors.ErrTextScoreNotAvailable,
+					"query requires text score metadata, but it is not available",
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isTextRune returns true if the rune is a part of a term, and false if it is a delimiter.
+func isTextRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
+
+// containsPhrase returns true if some of the texts contains the phrase.
+func containsPhrase(texts []string, phrase string) bool {
+	for _, text := range texts {
+		if strings.Contains(text, phrase) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// appendUnique appends the string to the slice if it is not there yet.
+func appendUnique(s []string, str string) []string {
+	for _, e := range s {
+		if e == str {
+			return s
+		}
+	}
+
+	return append(s, str)
+}
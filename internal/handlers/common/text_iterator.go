@@ -0,0 +1,75 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// TextIterator returns an iterator that filters out documents that don't match the $text query operator.
+// It will be added to the given closer.
+//
+// Next method returns the next matching document with the text score set; see types.Document.TextScore.
+//
+// Close method closes the underlying iterator.
+func TextIterator(iter types.DocumentsIterator, closer *iterator.MultiCloser, search *TextSearch) types.DocumentsIterator {
+	res := &textIterator{
+		iter:   iter,
+		search: search,
+	}
+	closer.Add(res)
+
+	return res
+}
+
+// textIterator is returned by TextIterator.
+type textIterator struct {
+	iter   types.DocumentsIterator
+	search *TextSearch
+}
+
+// Next implements iterator.Interface. See TextIterator for details.
+func (iter *textIterator) Next() (struct{}, *types.Document, error) {
+	var unused struct{}
+
+	for {
+		_, doc, err := iter.iter.Next()
+		if err != nil {
+			return unused, nil, lazyerrors.Error(err)
+		}
+
+		score, matches, err := iter.search.Score(doc)
+		if err != nil {
+			return unused, nil, lazyerrors.Error(err)
+		}
+
+		if matches {
+			doc.SetTextScore(score)
+			return unused, doc, nil
+		}
+	}
+}
+
+// Close implements iterator.Interface. See TextIterator for details.
+func (iter *textIterator) Close() {
+	iter.iter.Close()
+}
+
+// check interfaces
+var (
+	_ types.DocumentsIterator = (*textIterator)(nil)
+)
@@ -0,0 +1,150 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import "strings"
+
+// stemEnglish returns the stem of the English word.
+//
+// It implements the first step of the Porter stemming algorithm that removes plurals,
+// -ed and -ing suffixes, so "cats", "running" and "hoped" become "cat", "run" and "hope".
+// Words that are not in lowercase ASCII are returned as is.
+func stemEnglish(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	// step 1a
+	switch {
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "ies"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"):
+		// nothing
+	case strings.HasSuffix(word, "s"):
+		word = word[:len(word)-1]
+	}
+
+	// step 1b
+	switch {
+	case strings.HasSuffix(word, "eed"):
+		if stemMeasure(word[:len(word)-3]) > 0 {
+			word = word[:len(word)-1]
+		}
+
+	case strings.HasSuffix(word, "ed") && stemHasVowel(word[:len(word)-2]):
+		word = stemStep1bCleanup(word[:len(word)-2])
+
+	case strings.HasSuffix(word, "ing") && stemHasVowel(word[:len(word)-3]):
+		word = stemStep1bCleanup(word[:len(word)-3])
+	}
+
+	// step 1c
+	if strings.HasSuffix(word, "y") && stemHasVowel(word[:len(word)-1]) {
+		word = word[:len(word)-1] + "i"
+	}
+
+	return word
+}
+
+// stemStep1bCleanup restores or removes the last letter of the word after -ed or -ing suffix is removed.
+func stemStep1bCleanup(word string) string {
+	switch {
+	case strings.HasSuffix(word, "at"), strings.HasSuffix(word, "bl"), strings.HasSuffix(word, "iz"):
+		return word + "e"
+
+	case stemDoubleConsonant(word):
+		if last := word[len(word)-1]; last != 'l' && last != 's' && last != 'z' {
+			return word[:len(word)-1]
+		}
+
+	case stemMeasure(word) == 1 && stemCVC(word):
+		return word + "e"
+	}
+
+	return word
+}
+
+// stemConsonant returns true if the i-th letter of the word is a consonant.
+func stemConsonant(word string, i int) bool {
+	switch word[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !stemConsonant(word, i-1)
+	default:
+		return true
+	}
+}
+
+// stemMeasure returns the number of vowel-consonant sequences in the word.
+func stemMeasure(word string) int {
+	var res int
+	var vowel bool
+
+	for i := range word {
+		if !stemConsonant(word, i) {
+			vowel = true
+			continue
+		}
+
+		if vowel {
+			res++
+			vowel = false
+		}
+	}
+
+	return res
+}
+
+// stemHasVowel returns true if the word contains a vowel.
+func stemHasVowel(word string) bool {
+	for i := range word {
+		if !stemConsonant(word, i) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stemDoubleConsonant returns true if the word ends with a double consonant.
+func stemDoubleConsonant(word string) bool {
+	l := len(word)
+
+	return l >= 2 && word[l-1] == word[l-2] && stemConsonant(word, l-1)
+}
+
+// stemCVC returns true if the word ends with consonant-vowel-consonant sequence,
+// where the last consonant is not w, x or y.
+func stemCVC(word string) bool {
+	l := len(word)
+	if l < 3 {
+		return false
+	}
+
+	if !stemConsonant(word, l-3) || stemConsonant(word, l-2) || !stemConsonant(word, l-1) {
+		return false
+	}
+
+	last := word[l-1]
+
+	return last != 'w' && last != 'x' && last != 'y'
+}
@@ -0,0 +1,130 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestTextSearch(t *testing.T) {
+	t.Parallel()
+
+	index := &TextIndex{
+		Weights:          map[string]int32{"title": 10, "body": 1},
+		DefaultLanguage:  "english",
+		LanguageOverride: "language",
+	}
+
+	for name, tc := range map[string]struct {
+		search  *types.Document
+		doc     *types.Document
+		score   float64
+		matches bool
+	}{
+		"SingleTerm": {
+			search:  must.NotFail(types.NewDocument("$search", "coffee")),
+			doc:     must.NotFail(types.NewDocument("body", "coffee")),
+			score:   1.1,
+			matches: true,
+		},
+		"Stemmed": {
+			search:  must.NotFail(types.NewDocument("$search", "shops")),
+			doc:     must.NotFail(types.NewDocument("body", "Coffee shop")),
+			score:   0.75,
+			matches: true,
+		},
+		"Weights": {
+			search:  must.NotFail(types.NewDocument("$search", "coffee")),
+			doc:     must.NotFail(types.NewDocument("title", "Coffee shop", "body", "coffee")),
+			score:   7.5 + 1.1,
+			matches: true,
+		},
+		"StopWords": {
+			search: must.NotFail(types.NewDocument("$search", "the")),
+			doc:    must.NotFail(types.NewDocument("body", "the coffee")),
+		},
+		"Negated": {
+			search: must.NotFail(types.NewDocument("$search", "coffee -shop")),
+			doc:    must.NotFail(types.NewDocument("body", "Coffee shop")),
+		},
+		"Phrase": {
+			search:  must.NotFail(types.NewDocument("$search", `"coffee shop"`)),
+			doc:     must.NotFail(types.NewDocument("body", "Coffee shop")),
+			score:   1.5,
+			matches: true,
+		},
+		"PhraseNotFound": {
+			search: must.NotFail(types.NewDocument("$search", `"shop coffee"`)),
+			doc:    must.NotFail(types.NewDocument("body", "Coffee shop")),
+		},
+		"CaseSensitive": {
+			search: must.NotFail(types.NewDocument("$search", "coffee", "$caseSensitive", true)),
+			doc:    must.NotFail(types.NewDocument("body", "Coffee")),
+		},
+		"Diacritics": {
+			search:  must.NotFail(types.NewDocument("$search", "cafe")),
+			doc:     must.NotFail(types.NewDocument("body", "Café")),
+			score:   1,
+			matches: true,
+		},
+		"LanguageOverride": {
+			search: must.NotFail(types.NewDocument("$search", "shops")),
+			doc:    must.NotFail(types.NewDocument("body", "shops", "language", "none")),
+		},
+		"Array": {
+			search:  must.NotFail(types.NewDocument("$search", "tea")),
+			doc:     must.NotFail(types.NewDocument("body", must.NotFail(types.NewArray("coffee", "tea")))),
+			score:   1.1,
+			matches: true,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			search, err := NewTextSearch(tc.search, index)
+			require.NoError(t, err)
+
+			score, matches, err := search.Score(tc.doc)
+			require.NoError(t, err)
+			assert.Equal(t, tc.matches, matches)
+			assert.InDelta(t, tc.score, score, 1e-9)
+		})
+	}
+}
+
+func TestStemEnglish(t *testing.T) {
+	t.Parallel()
+
+	for word, expected := range map[string]string{
+		"caresses": "caress",
+		"ponies":   "poni",
+		"cats":     "cat",
+		"agreed":   "agree",
+		"running":  "run",
+		"hoping":   "hope",
+		"happy":    "happi",
+		"coffee":   "coffee",
+		"Running":  "Running",
+	} {
+		assert.Equal(t, expected, stemEnglish(word), word)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"sort"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// Top is a part of common implementation of the top command.
+func Top(msg *wire.OpMsg, cm *connmetrics.ConnMetrics) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	db, err := GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if db != "admin" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	namespaces := cm.Top.Namespaces()
+
+	names := maps.Keys(namespaces)
+	sort.Strings(names)
+
+	totals := must.NotFail(types.NewDocument("note", "all times in microseconds"))
+
+	for _, name := range names {
+		ns := namespaces[name]
+
+		totals.Set(name, must.NotFail(types.NewDocument(
+			"total", topStatDocument(ns.Total),
+			"readLock", topStatDocument(ns.ReadLock),
+			"writeLock", topStatDocument(ns.WriteLock),
+			"queries", topStatDocument(ns.Queries),
+			"getmore", topStatDocument(ns.GetMore),
+			"insert", topStatDocument(ns.Insert),
+			"update", topStatDocument(ns.Update),
+			"remove", topStatDocument(ns.Remove),
+			"commands", topStatDocument(ns.Commands),
+		)))
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"totals", totals,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// topStatDocument returns the document with total time in microseconds and count of operations.
+func topStatDocument(s connmetrics.TopStat) *types.Document {
+	return must.NotFail(types.NewDocument(
+		"time", s.Time.Microseconds(),
+		"count", s.Count,
+	))
+}
@@ -0,0 +1,306 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/clientconn/session"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// transactionCommands are commands that could be run in multi-document transactions, sorted.
+// Transaction control commands are not included.
+var transactionCommands = []string{
+	"aggregate",
+	"create",
+	"createIndexes",
+	"delete",
+	"distinct",
+	"find",
+	"findAndModify",
+	"findandmodify",
+	"getMore",
+	"insert",
+	"killCursors",
+	"update",
+}
+
+// TransactionParams represents transaction-related fields of the command.
+type TransactionParams struct {
+	SessionID        uuid.UUID
+	TxnNumber        int64
+	StartTransaction bool
+}
+
+// GetTransactionParams returns transaction-related fields of the command,
+// or nil if the command is not a part of a multi-document transaction.
+func GetTransactionParams(document *types.Document) (*TransactionParams, error) {
+	command := document.Command()
+
+	v, _ := document.Get("txnNumber")
+	if v == nil {
+		if document.Has("startTransaction") || document.Has("autocommit") {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrInvalidOptions,
+				"Specifying autocommit=false is not allowed outside of a multi-statement transaction",
+				command,
+			)
+		}
+
+		return nil, nil
+	}
+
+	txnNumber, ok := v.(int64)
+	if !ok {
+		return nil, sessionsTypeMismatch("OperationSessionInfo.txnNumber", v, "long")
+	}
+
+	v, _ = document.Get("lsid")
+	if v == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidOptions,
+			"Transaction number requires a session ID to also be specified",
+			command,
+		)
+	}
+
+	lsid, ok := v.(*types.Document)
+	if !ok {
+		return nil, sessionsTypeMismatch("OperationSessionInfo.lsid", v, "object")
+	}
+
+	id, err := sessionID(command, "OperationSessionInfo.lsid", lsid)
+	if err != nil {
+		return nil, err
+	}
+
+	// retryable writes are not supported, so txnNumber is allowed only for multi-document transactions
+	v, _ = document.Get("autocommit")
+	if v == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrIllegalOperation,
+			"Transaction numbers are only allowed on a replica set member or mongos",
+			command,
+		)
+	}
+
+	if autocommit, ok := v.(bool); !ok {
+		return nil, sessionsTypeMismatch("OperationSessionInfo.autocommit", v, "bool")
+	} else if autocommit {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidOptions,
+			"autocommit field can only be specified as false",
+			command,
+		)
+	}
+
+	var start bool
+
+	if v, _ = document.Get("startTransaction"); v != nil {
+		if start, ok = v.(bool); !ok {
+			return nil, sessionsTypeMismatch("OperationSessionInfo.startTransaction", v, "bool")
+		}
+
+		if !start {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrInvalidOptions,
+				"startTransaction field can only be specified as true",
+				command,
+			)
+		}
+	}
+
+	return &TransactionParams{
+		SessionID:        id,
+		TxnNumber:        txnNumber,
+		StartTransaction: start,
+	}, nil
+}
+
+// ErrTransactionsNotSupported should be returned by handlers that do not support transactions.
+var ErrTransactionsNotSupported = errors.New("transactions are not supported")
+
+// RunInTransaction runs the command handler f inside the multi-document transaction of the logical session
+// if the command is a part of it; otherwise, f is just called.
+//
+// Function begin is called to start a new handler's transaction.
+// If the command fails, the transaction is aborted, like MongoDB does.
+func RunInTransaction(ctx context.Context, document *types.Document, begin func(context.Context) (session.Transaction, error), f func(context.Context) (*wire.OpMsg, error)) (*wire.OpMsg, error) { //nolint:lll // for readability
+	params, err := GetTransactionParams(document)
+	if err != nil {
+		return nil, err
+	}
+
+	command := document.Command()
+
+	// transaction control commands are handled separately
+	if params == nil || command == "commitTransaction" || command == "abortTransaction" {
+		return f(ctx)
+	}
+
+	if _, ok := slices.BinarySearch(transactionCommands, command); !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrOperationNotSupportedInTransaction,
+			fmt.Sprintf("Cannot run '%s' in a multi-document transaction.", command),
+			command,
+		)
+	}
+
+	r := session.GetRegistry(ctx)
+
+	var txn *session.Txn
+
+	if params.StartTransaction {
+		var t session.Transaction
+		if t, err = begin(ctx); err != nil {
+			if errors.Is(err, ErrTransactionsNotSupported) {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrIllegalOperation,
+					"Transaction numbers are only allowed on a replica set member or mongos",
+					command,
+				)
+			}
+
+			return nil, lazyerrors.Error(err)
+		}
+
+		username, _ := conninfo.Get(ctx).Auth()
+
+		var ok bool
+		if txn, ok = r.SetTransaction(params.SessionID, username, params.TxnNumber, t); !ok {
+			_ = t.Rollback(ctx)
+
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTransactionTooOld,
+				fmt.Sprintf(
+					"Cannot start transaction %d on session %s because a newer transaction %d has already started",
+					params.TxnNumber, params.SessionID, r.Get(params.SessionID).TxnNumber,
+				),
+				command,
+			)
+		}
+	} else {
+		if txn = r.GetTransaction(params.SessionID, params.TxnNumber); txn == nil {
+			return nil, noSuchTransaction(r, params, command)
+		}
+	}
+
+	txn.Lock()
+	defer txn.Unlock()
+
+	res, err := f(txn.Context(ctx))
+
+	if err == nil {
+		doc, docErr := res.Document()
+		if docErr != nil {
+			return nil, lazyerrors.Error(docErr)
+		}
+
+		if !doc.Has("writeErrors") {
+			return res, nil
+		}
+	}
+
+	// we already hold the lock
+	if t := r.RemoveTransaction(params.SessionID, params.TxnNumber); t != nil {
+		_ = t.Rollback(ctx)
+	}
+
+	return res, err
+}
+
+// CommitTransaction commits the multi-document transaction of the logical session.
+func CommitTransaction(ctx context.Context, document *types.Document) error {
+	return endTransaction(ctx, document, true)
+}
+
+// AbortTransaction aborts the multi-document transaction of the logical session.
+func AbortTransaction(ctx context.Context, document *types.Document) error {
+	return endTransaction(ctx, document, false)
+}
+
+// endTransaction commits or aborts the multi-document transaction of the logical session.
+func endTransaction(ctx context.Context, document *types.Document, commit bool) error {
+	command := document.Command()
+
+	params, err := GetTransactionParams(document)
+	if err != nil {
+		return err
+	}
+
+	if params == nil {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidOptions,
+			fmt.Sprintf("%s must be run within a transaction", command),
+			command,
+		)
+	}
+
+	r := session.GetRegistry(ctx)
+
+	txn := r.RemoveTransaction(params.SessionID, params.TxnNumber)
+	if txn == nil {
+		return noSuchTransaction(r, params, command)
+	}
+
+	txn.Lock()
+	defer txn.Unlock()
+
+	if !commit {
+		if err = txn.Rollback(ctx); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	}
+
+	if err = txn.Commit(ctx); err != nil {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrNoSuchTransaction,
+			fmt.Sprintf("Transaction with { txnNumber: %d } has been aborted.", params.TxnNumber),
+			command,
+		)
+	}
+
+	return nil
+}
+
+// noSuchTransaction returns NoSuchTransaction error for the transaction that is not in progress.
+func noSuchTransaction(r *session.Registry, params *TransactionParams, command string) error {
+	active := int64(-1)
+	if s := r.Get(params.SessionID); s != nil && r.GetTransaction(params.SessionID, s.TxnNumber) != nil {
+		active = s.TxnNumber
+	}
+
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrNoSuchTransaction,
+		fmt.Sprintf(
+			"Given transaction number %d does not match any in-progress transactions. "+
+				"The active transaction number is %d",
+			params.TxnNumber, active,
+		),
+		command,
+	)
+}
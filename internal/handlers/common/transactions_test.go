@@ -0,0 +1,98 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestGetTransactionParams(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	lsid := SessionDocument(id)
+
+	for name, tc := range map[string]struct {
+		doc      *types.Document
+		expected *TransactionParams
+		code     commonerrors.ErrorCode
+	}{
+		"NoTransaction": {
+			doc: must.NotFail(types.NewDocument("find", "test", "lsid", lsid)),
+		},
+		"Start": {
+			doc: must.NotFail(types.NewDocument(
+				"find", "test", "lsid", lsid, "txnNumber", int64(1), "startTransaction", true, "autocommit", false,
+			)),
+			expected: &TransactionParams{SessionID: id, TxnNumber: 1, StartTransaction: true},
+		},
+		"Continue": {
+			doc: must.NotFail(types.NewDocument(
+				"insert", "test", "lsid", lsid, "txnNumber", int64(2), "autocommit", false,
+			)),
+			expected: &TransactionParams{SessionID: id, TxnNumber: 2},
+		},
+		"RetryableWrite": {
+			doc:  must.NotFail(types.NewDocument("insert", "test", "lsid", lsid, "txnNumber", int64(1))),
+			code: commonerrors.ErrIllegalOperation,
+		},
+		"AutocommitTrue": {
+			doc: must.NotFail(types.NewDocument(
+				"insert", "test", "lsid", lsid, "txnNumber", int64(1), "autocommit", true,
+			)),
+			code: commonerrors.ErrInvalidOptions,
+		},
+		"StartFalse": {
+			doc: must.NotFail(types.NewDocument(
+				"insert", "test", "lsid", lsid, "txnNumber", int64(1), "startTransaction", false, "autocommit", false,
+			)),
+			code: commonerrors.ErrInvalidOptions,
+		},
+		"NoTxnNumber": {
+			doc:  must.NotFail(types.NewDocument("insert", "test", "lsid", lsid, "autocommit", false)),
+			code: commonerrors.ErrInvalidOptions,
+		},
+		"TxnNumberType": {
+			doc: must.NotFail(types.NewDocument(
+				"insert", "test", "lsid", lsid, "txnNumber", int32(1), "autocommit", false,
+			)),
+			code: commonerrors.ErrTypeMismatch,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := GetTransactionParams(tc.doc)
+			if tc.code != 0 {
+				var ce *commonerrors.CommandError
+				require.ErrorAs(t, err, &ce)
+				assert.Equal(t, tc.code, ce.Code())
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -20,7 +20,6 @@ import (
 	"math"
 	"sort"
 	"strings"
-	"time"
 
 	"golang.org/x/exp/slices"
 
@@ -291,23 +290,38 @@ func processRenameFieldExpression(command string, doc *types.Document, update *t
 				continue
 			}
 
-			if dpe.Code() == types.ErrPathIndexInvalid {
-				return false, newUpdateError(
-					commonerrors.ErrUnsuitableValueType,
-					fmt.Sprintf("cannot use path '%s' to traverse the document", sourcePath),
-					command,
-				)
-			}
-
 			return changed, newUpdateError(commonerrors.ErrUnsuitableValueType, dpe.Error(), command)
 		}
 
+		// renaming through an array is prohibited, but the renamed value itself can be an array
+		if array, ok := doc.TraversedArray(sourcePath); ok {
+			return false, newUpdateError(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf(
+					"The source field cannot be an array element, '%s' in doc with _id: %s has an array field called '%s'",
+					key, types.FormatAnyValue(must.NotFail(doc.Get("_id"))), array,
+				),
+				command,
+			)
+		}
+
+		if array, ok := doc.TraversedArray(targetPath); ok {
+			return false, newUpdateError(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf(
+					"The destination field cannot be an array element, '%s' in doc with _id: %s has an array field called '%s'",
+					renameValue, types.FormatAnyValue(must.NotFail(doc.Get("_id"))), array,
+				),
+				command,
+			)
+		}
+
 		// Remove old document
 		doc.RemoveByPath(sourcePath)
 
 		// Set new path with old value
 		if err := doc.SetByPath(targetPath, val); err != nil {
-			return false, lazyerrors.Error(err)
+			return false, newUpdateError(commonerrors.ErrUnsuitableValueType, err.Error(), command)
 		}
 
 		changed = true
@@ -711,7 +725,7 @@ func processCurrentDateFieldExpression(doc *types.Document, currentDateVal any)
 	var changed bool
 	currentDateExpression := currentDateVal.(*types.Document)
 
-	now := time.Now().UTC()
+	now := types.Now().UTC()
 	keys := currentDateExpression.Keys()
 	sort.Strings(keys)
 
@@ -889,10 +903,12 @@ func HasSupportedUpdateModifiers(command string, update *types.Document) (bool,
 	return false, nil
 }
 
-// newUpdateError returns CommandError for findAndModify command, WriteError for other commands.
+// newUpdateError returns CommandError for findAndModify and aggregate commands, WriteError for other commands.
 func newUpdateError(code commonerrors.ErrorCode, msg, command string) error {
 	// Depending on the driver, the command may be camel case or lower case.
-	if strings.ToLower(command) == "findandmodify" {
+	// Updates made by aggregate's $merge stage also return command errors.
+	switch strings.ToLower(command) {
+	case "findandmodify", "aggregate":
 		return commonerrors.NewCommandErrorMsgWithArgument(code, msg, command)
 	}
 
@@ -0,0 +1,394 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// arrayFilterElementKey is the key under which an array element is placed
+// to be matched against array filter.
+const arrayFilterElementKey = "elem"
+
+// arrayFilterIdentifierRe matches valid array filter identifiers.
+var arrayFilterIdentifierRe = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+
+// ValidateArrayFilters validates arrayFilters parameter against the update document.
+//
+// It checks that each array filter has a single valid identifier,
+// that every filtered positional operator `$[<identifier>]` used in update paths
+// has a corresponding array filter, and that every array filter is used.
+//
+// ValidateArrayFilters returns CommandError for findAndModify case-insensitive command name,
+// WriteError for other commands.
+func ValidateArrayFilters(command string, arrayFilters *types.Array, update *types.Document) error {
+	filters, err := parseArrayFilters(command, arrayFilters)
+	if err != nil {
+		return err
+	}
+
+	used := map[string]struct{}{}
+
+	for _, path := range updateOperatorPaths(update) {
+		for i, elem := range strings.Split(path, ".") {
+			identifier, ok := positionalIdentifier(elem)
+			if !ok {
+				continue
+			}
+
+			if i == 0 {
+				return newUpdateError(
+					commonerrors.ErrBadValue,
+					fmt.Sprintf(
+						"Cannot have array filter identifier (i.e. '$[<id>]') element "+
+							"in the first position in path '%s'",
+						path,
+					),
+					command,
+				)
+			}
+
+			if identifier == "" {
+				continue
+			}
+
+			if _, ok = filters[identifier]; !ok {
+				return newUpdateError(
+					commonerrors.ErrBadValue,
+					fmt.Sprintf("No array filter found for identifier '%s' in path '%s'", identifier, path),
+					command,
+				)
+			}
+
+			used[identifier] = struct{}{}
+		}
+	}
+
+	for _, identifier := range arrayFilterIdentifiers(arrayFilters) {
+		if _, ok := used[identifier]; ok {
+			continue
+		}
+
+		return newUpdateError(
+			commonerrors.ErrFailedToParse,
+			fmt.Sprintf(
+				"The array filter for identifier '%s' was not used in the update %s",
+				identifier, types.FormatAnyValue(update),
+			),
+			command,
+		)
+	}
+
+	return nil
+}
+
+// UpdateDocumentWithArrayFilters is like UpdateDocument, but it also resolves
+// all positional operators `$[]` and filtered positional operators `$[<identifier>]`
+// in update paths against the given document.
+//
+// To validate arrayFilters, must call ValidateArrayFilters before calling UpdateDocumentWithArrayFilters.
+func UpdateDocumentWithArrayFilters(command string, doc, update *types.Document, arrayFilters *types.Array) (bool, error) {
+	filters, err := parseArrayFilters(command, arrayFilters)
+	if err != nil {
+		return false, err
+	}
+
+	expanded := must.NotFail(types.NewDocument())
+
+	for _, op := range update.Keys() {
+		v := must.NotFail(update.Get(op))
+
+		opDoc, ok := v.(*types.Document)
+		if !ok || !strings.HasPrefix(op, "$") || op == "$rename" {
+			expanded.Set(op, v)
+			continue
+		}
+
+		expandedOpDoc := must.NotFail(types.NewDocument())
+
+		for _, key := range opDoc.Keys() {
+			val := must.NotFail(opDoc.Get(key))
+
+			if !strings.Contains(key, "$[") {
+				expandedOpDoc.Set(key, val)
+				continue
+			}
+
+			var path types.Path
+
+			if path, err = types.NewPathFromString(key); err != nil {
+				// invalid paths are reported by UpdateDocument
+				expandedOpDoc.Set(key, val)
+				continue
+			}
+
+			var paths []string
+
+			paths, err = resolvePositionalPath(command, doc, path, filters)
+			if err != nil {
+				return false, err
+			}
+
+			for _, p := range paths {
+				expandedOpDoc.Set(p, val)
+			}
+		}
+
+		expanded.Set(op, expandedOpDoc)
+	}
+
+	return UpdateDocument(command, doc, expanded)
+}
+
+// parseArrayFilters returns array filters mapped by their identifiers.
+//
+// Filter keys are rewritten to be relative to arrayFilterElementKey,
+// so `{"x.a": {$gt: 1}}` becomes `{"elem.a": {$gt: 1}}` for identifier `x`.
+func parseArrayFilters(command string, arrayFilters *types.Array) (map[string]*types.Document, error) {
+	res := map[string]*types.Document{}
+
+	if arrayFilters == nil {
+		return res, nil
+	}
+
+	iter := arrayFilters.Iterator()
+	defer iter.Close()
+
+	for {
+		i, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			return res, nil
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		filter, ok := v.(*types.Document)
+		if !ok {
+			return nil, newUpdateError(
+				commonerrors.ErrTypeMismatch,
+				fmt.Sprintf(
+					"BSON field '%s.arrayFilters.%d' is the wrong type '%s', expected type 'object'",
+					command, i, commonparams.AliasFromType(v),
+				),
+				command,
+			)
+		}
+
+		if filter.Len() == 0 {
+			return nil, newUpdateError(
+				commonerrors.ErrFailedToParse,
+				"Cannot use an expression without a top-level field name in arrayFilters",
+				command,
+			)
+		}
+
+		var identifier string
+		rewritten := must.NotFail(types.NewDocument())
+
+		for _, key := range filter.Keys() {
+			top, rest, _ := strings.Cut(key, ".")
+
+			if identifier != "" && top != identifier {
+				return nil, newUpdateError(
+					commonerrors.ErrFailedToParse,
+					fmt.Sprintf(
+						"Error parsing array filter :: caused by :: "+
+							"Expected a single top-level field name, found '%s' and '%s'",
+						identifier, top,
+					),
+					command,
+				)
+			}
+
+			if !arrayFilterIdentifierRe.MatchString(top) {
+				return nil, newUpdateError(
+					commonerrors.ErrBadValue,
+					fmt.Sprintf(
+						"Error parsing array filter :: caused by :: The top-level field name must be "+
+							"an alphanumeric string beginning with a lowercase letter, found '%s'",
+						top,
+					),
+					command,
+				)
+			}
+
+			identifier = top
+
+			newKey := arrayFilterElementKey
+			if rest != "" {
+				newKey += "." + rest
+			}
+
+			rewritten.Set(newKey, must.NotFail(filter.Get(key)))
+		}
+
+		if _, ok = res[identifier]; ok {
+			return nil, newUpdateError(
+				commonerrors.ErrFailedToParse,
+				fmt.Sprintf("Found multiple array filters with the same top-level field name %s", identifier),
+				command,
+			)
+		}
+
+		res[identifier] = rewritten
+	}
+}
+
+// arrayFilterIdentifiers returns identifiers of array filters in their original order.
+// Array filters must be parsed by parseArrayFilters before.
+func arrayFilterIdentifiers(arrayFilters *types.Array) []string {
+	if arrayFilters == nil {
+		return nil
+	}
+
+	res := make([]string, 0, arrayFilters.Len())
+
+	for i := 0; i < arrayFilters.Len(); i++ {
+		filter := must.NotFail(arrayFilters.Get(i)).(*types.Document)
+		top, _, _ := strings.Cut(filter.Keys()[0], ".")
+		res = append(res, top)
+	}
+
+	return res
+}
+
+// updateOperatorPaths returns all paths modified by update operators of the update document.
+// $rename is not included as positional operators are not allowed for it.
+func updateOperatorPaths(update *types.Document) []string {
+	var res []string
+
+	for _, op := range update.Keys() {
+		if !strings.HasPrefix(op, "$") || op == "$rename" {
+			continue
+		}
+
+		opDoc, ok := must.NotFail(update.Get(op)).(*types.Document)
+		if !ok {
+			continue
+		}
+
+		res = append(res, opDoc.Keys()...)
+	}
+
+	return res
+}
+
+// positionalIdentifier returns the identifier of filtered positional operator `$[<identifier>]`,
+// or an empty string for all positional operator `$[]`.
+// The second returned value is false if path element is not a positional operator.
+func positionalIdentifier(elem string) (string, bool) {
+	if !strings.HasPrefix(elem, "$[") || !strings.HasSuffix(elem, "]") {
+		return "", false
+	}
+
+	return strings.TrimSuffix(strings.TrimPrefix(elem, "$["), "]"), true
+}
+
+// resolvePositionalPath replaces positional operators in the path by indexes
+// of matching array elements of the document and returns all resulting paths.
+func resolvePositionalPath(command string, doc *types.Document, path types.Path, filters map[string]*types.Document) ([]string, error) { //nolint:lll // for readability
+	opts := &types.WalkPathOpts{
+		Positional: func(prefix types.Path, identifier string, value any) ([]int, error) {
+			return positionalIndexes(command, path, prefix, identifier, value, filters)
+		},
+		ArrayIndexes: true,
+		Missing:      true,
+	}
+
+	var res []string
+
+	err := types.WalkPath(doc, path, opts, func(p types.Path, _ any) error {
+		res = append(res, p.String())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// positionalIndexes returns indexes of array elements matching the positional operator
+// with the given identifier.
+// The prefix contains already resolved path elements leading to value.
+func positionalIndexes(command string, path, prefix types.Path, identifier string, value any, filters map[string]*types.Document) ([]int, error) { //nolint:lll // for readability
+	if prefix.Len() == 0 {
+		return nil, newUpdateError(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf(
+				"Cannot have array filter identifier (i.e. '$[<id>]') element "+
+					"in the first position in path '%s'",
+				path,
+			),
+			command,
+		)
+	}
+
+	arr, ok := value.(*types.Array)
+	if !ok {
+		if value == nil {
+			return nil, newUpdateError(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf(
+					"The path '%s' must exist in the document in order to apply array updates.",
+					prefix,
+				),
+				command,
+			)
+		}
+
+		return nil, newUpdateError(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf(
+				"Cannot apply array updates to non-array element %s: %s",
+				prefix.Suffix(), types.FormatAnyValue(value),
+			),
+			command,
+		)
+	}
+
+	var res []int
+
+	for i := 0; i < arr.Len(); i++ {
+		if identifier != "" {
+			v := must.NotFail(arr.Get(i))
+
+			matches, err := FilterDocument(must.NotFail(types.NewDocument(arrayFilterElementKey, v)), filters[identifier])
+			if err != nil {
+				return nil, err
+			}
+
+			if !matches {
+				continue
+			}
+		}
+
+		res = append(res, i)
+	}
+
+	return res, nil
+}
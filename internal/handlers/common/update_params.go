@@ -27,14 +27,18 @@ type UpdatesParams struct {
 	Collection string         `ferretdb:"collection"`
 	Updates    []UpdateParams `ferretdb:"updates"`
 
-	Comment string `ferretdb:"comment,opt"`
+	Comment   string `ferretdb:"comment,opt"`
+	MaxTimeMS int64  `ferretdb:"maxTimeMS,opt,wholePositiveNumber"`
 
 	Let *types.Document `ferretdb:"let,unimplemented"`
 
 	Ordered                  bool            `ferretdb:"ordered,ignored"`
-	BypassDocumentValidation bool            `ferretdb:"bypassDocumentValidation,ignored"`
+	BypassDocumentValidation bool            `ferretdb:"bypassDocumentValidation,opt"`
 	WriteConcern             *types.Document `ferretdb:"writeConcern,ignored"`
 	LSID                     any             `ferretdb:"lsid,ignored"`
+	TxnNumber                int64           `ferretdb:"txnNumber,ignored"`
+	StartTransaction         bool            `ferretdb:"startTransaction,ignored"`
+	Autocommit               bool            `ferretdb:"autocommit,ignored"`
 }
 
 // UpdateParams represents a single update operation parameters.
@@ -47,10 +51,10 @@ type UpdateParams struct {
 	Upsert bool            `ferretdb:"upsert,opt,numericBool"`
 
 	C            *types.Document `ferretdb:"c,unimplemented"`
-	Collation    *types.Document `ferretdb:"collation,unimplemented"`
+	Collation    *types.Document `ferretdb:"collation,opt"`
 	ArrayFilters *types.Array    `ferretdb:"arrayFilters,unimplemented"`
 
-	Hint string `ferretdb:"hint,ignored"`
+	Hint any `ferretdb:"hint,opt"`
 }
 
 // GetUpdateParams returns parameters for update command.
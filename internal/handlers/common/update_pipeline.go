@@ -0,0 +1,440 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// ValidateUpdatePipeline validates aggregation pipeline used as an update.
+//
+// Only $addFields, $set, $project, $unset, $replaceRoot and $replaceWith stages
+// are allowed in update pipelines.
+//
+// ValidateUpdatePipeline returns CommandError for findAndModify and aggregate case-insensitive command names,
+// WriteError for other commands.
+func ValidateUpdatePipeline(command string, pipeline *types.Array) error {
+	iter := pipeline.Iterator()
+	defer iter.Close()
+
+	for {
+		_, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			return nil
+		}
+
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		stage, ok := v.(*types.Document)
+		if !ok {
+			return newUpdateError(
+				commonerrors.ErrTypeMismatch,
+				"Each element of the 'pipeline' array must be an object",
+				command,
+			)
+		}
+
+		if stage.Len() != 1 {
+			return newUpdateError(
+				commonerrors.ErrStageInvalid,
+				"A pipeline stage specification object must contain exactly one field.",
+				command,
+			)
+		}
+
+		name := stage.Command()
+		spec := must.NotFail(stage.Get(name))
+
+		switch name {
+		case "$addFields", "$set":
+			if _, ok = spec.(*types.Document); !ok {
+				return newUpdateError(
+					commonerrors.ErrSetBadExpression,
+					fmt.Sprintf("%s specification stage must be an object, got %s", name, commonparams.AliasFromType(spec)),
+					command,
+				)
+			}
+
+		case "$project":
+			specDoc, ok := spec.(*types.Document)
+			if !ok {
+				return newUpdateError(
+					commonerrors.ErrProjectBadExpression,
+					fmt.Sprintf("$project specification must be an object, got %s", commonparams.AliasFromType(spec)),
+					command,
+				)
+			}
+
+			if _, _, err = ValidateProjection(specDoc); err != nil {
+				return err
+			}
+
+		case "$unset":
+			if _, err = unsetStagePaths(command, spec); err != nil {
+				return err
+			}
+
+		case "$replaceRoot":
+			if _, err = replaceRootExpression(command, spec); err != nil {
+				return err
+			}
+
+		case "$replaceWith":
+			// any expression is valid there
+
+		default:
+			return newUpdateError(
+				commonerrors.ErrInvalidOptions,
+				fmt.Sprintf("%s is not allowed to be used within an update", name),
+				command,
+			)
+		}
+	}
+}
+
+// UpdateDocumentWithPipeline returns a new document produced by applying
+// aggregation pipeline update stages to the given document.
+// The given document is not modified.
+//
+// The _id field of the document is immutable: if the pipeline removes it, the original value is restored;
+// if the pipeline changes it, an error is returned.
+//
+// To validate pipeline, must call ValidateUpdatePipeline before calling UpdateDocumentWithPipeline.
+func UpdateDocumentWithPipeline(command string, doc *types.Document, pipeline *types.Array) (*types.Document, error) {
+	res := doc.DeepCopy()
+
+	for i := 0; i < pipeline.Len(); i++ {
+		stage := must.NotFail(pipeline.Get(i)).(*types.Document)
+		name := stage.Command()
+		spec := must.NotFail(stage.Get(name))
+
+		var err error
+
+		switch name {
+		case "$addFields", "$set":
+			specDoc := spec.(*types.Document)
+
+			for _, key := range specDoc.Keys() {
+				var path types.Path
+
+				if path, err = types.NewPathFromString(key); err != nil {
+					return nil, newUpdateError(
+						commonerrors.ErrPathContainsEmptyElement,
+						"FieldPath field names may not be empty strings.",
+						command,
+					)
+				}
+
+				v, found, err := evaluateUpdateExpression(command, res, must.NotFail(specDoc.Get(key)))
+				if err != nil {
+					return nil, err
+				}
+
+				if !found {
+					continue
+				}
+
+				if err = res.SetByPath(path, v); err != nil {
+					return nil, newUpdateError(commonerrors.ErrUnsuitableValueType, err.Error(), command)
+				}
+			}
+
+		case "$project":
+			hasID := res.Has("_id")
+			if !hasID {
+				// ProjectDocument requires _id, so it is set temporarily
+				res.Set("_id", types.Null)
+			}
+
+			projection, inclusion, err := ValidateProjection(spec.(*types.Document))
+			if err != nil {
+				return nil, err
+			}
+
+			if res, err = ProjectDocument(res, projection, nil, inclusion); err != nil {
+				return nil, err
+			}
+
+			if !hasID {
+				res.Remove("_id")
+			}
+
+		case "$unset":
+			paths := must.NotFail(unsetStagePaths(command, spec))
+
+			for _, path := range paths {
+				res.RemoveByPath(path)
+			}
+
+		case "$replaceRoot", "$replaceWith":
+			expr := spec
+
+			if name == "$replaceRoot" {
+				expr = must.NotFail(replaceRootExpression(command, spec))
+			}
+
+			v, _, err := evaluateUpdateExpression(command, res, expr)
+			if err != nil {
+				return nil, err
+			}
+
+			newRoot, ok := v.(*types.Document)
+			if !ok {
+				return nil, newUpdateError(
+					commonerrors.ErrStageReplaceRootNotObject,
+					fmt.Sprintf(
+						"'replacement document' must evaluate to an object, but resulting value was: %s. "+
+							"Type of resulting value: '%s'.",
+						types.FormatAnyValue(v), commonparams.AliasFromType(v),
+					),
+					command,
+				)
+			}
+
+			res = newRoot.DeepCopy()
+
+		default:
+			panic(fmt.Sprintf("unexpected update pipeline stage %q", name))
+		}
+	}
+
+	return restoreImmutableID(command, doc, res)
+}
+
+// restoreImmutableID checks that _id of the original document was not changed by the update.
+// If _id was removed, it is restored as the first field of the updated document.
+func restoreImmutableID(command string, original, updated *types.Document) (*types.Document, error) {
+	id, err := original.Get("_id")
+	if err != nil {
+		return updated, nil
+	}
+
+	newID, err := updated.Get("_id")
+	if err != nil {
+		res := must.NotFail(types.NewDocument("_id", id))
+
+		for _, key := range updated.Keys() {
+			res.Set(key, must.NotFail(updated.Get(key)))
+		}
+
+		return res, nil
+	}
+
+	if types.Compare(id, newID) != types.Equal {
+		return nil, newUpdateError(
+			commonerrors.ErrImmutableField,
+			"Performing an update on the path '_id' would modify the immutable field '_id'",
+			command,
+		)
+	}
+
+	return updated, nil
+}
+
+// evaluateUpdateExpression evaluates aggregation expression used in update pipeline stages.
+//
+// Strings prefixed with `$` are field paths, documents with `$`-prefixed keys are operators,
+// and values of other documents and arrays are evaluated recursively.
+// Any other value is returned as is.
+//
+// The second returned value is false if the expression refers to a missing field.
+func evaluateUpdateExpression(command string, doc *types.Document, expr any) (any, bool, error) {
+	switch expr := expr.(type) {
+	case string:
+		if !strings.HasPrefix(expr, "$") {
+			return expr, true, nil
+		}
+
+		expression, err := aggregations.NewExpression(expr, nil)
+		if err != nil {
+			var exprErr *aggregations.ExpressionError
+			if errors.As(err, &exprErr) && exprErr.Code() == aggregations.ErrUndefinedVariable {
+				return nil, false, newUpdateError(
+					commonerrors.ErrNotImplemented,
+					"Aggregation expression variables are not implemented yet",
+					command,
+				)
+			}
+
+			return nil, false, newUpdateError(
+				commonerrors.ErrFailedToParse,
+				fmt.Sprintf("Invalid expression %q", expr),
+				command,
+			)
+		}
+
+		v, err := expression.Evaluate(doc)
+		if err != nil {
+			return nil, false, nil
+		}
+
+		return v, true, nil
+
+	case *types.Document:
+		if operators.IsOperator(expr) {
+			op, err := operators.NewOperator(expr)
+			if err = processAddFieldsError(err); err != nil {
+				return nil, false, err
+			}
+
+			v, err := op.Process(doc)
+			if err = processAddFieldsError(err); err != nil {
+				return nil, false, err
+			}
+
+			return v, true, nil
+		}
+
+		res := must.NotFail(types.NewDocument())
+
+		for _, key := range expr.Keys() {
+			v, found, err := evaluateUpdateExpression(command, doc, must.NotFail(expr.Get(key)))
+			if err != nil {
+				return nil, false, err
+			}
+
+			if found {
+				res.Set(key, v)
+			}
+		}
+
+		return res, true, nil
+
+	case *types.Array:
+		res := types.MakeArray(expr.Len())
+
+		for i := 0; i < expr.Len(); i++ {
+			v, found, err := evaluateUpdateExpression(command, doc, must.NotFail(expr.Get(i)))
+			if err != nil {
+				return nil, false, err
+			}
+
+			if !found {
+				v = types.Null
+			}
+
+			res.Append(v)
+		}
+
+		return res, true, nil
+
+	default:
+		return expr, true, nil
+	}
+}
+
+// unsetStagePaths returns paths of $unset update pipeline stage.
+func unsetStagePaths(command string, spec any) ([]types.Path, error) {
+	var fields []any
+
+	switch spec := spec.(type) {
+	case string:
+		fields = []any{spec}
+	case *types.Array:
+		if spec.Len() == 0 {
+			return nil, newUpdateError(
+				commonerrors.ErrStageUnsetNoPath,
+				"$unset specification must be a string or an array with at least one field",
+				command,
+			)
+		}
+
+		for i := 0; i < spec.Len(); i++ {
+			fields = append(fields, must.NotFail(spec.Get(i)))
+		}
+	default:
+		return nil, newUpdateError(
+			commonerrors.ErrStageUnsetInvalidType,
+			"$unset specification must be a string or an array",
+			command,
+		)
+	}
+
+	res := make([]types.Path, 0, len(fields))
+
+	for _, f := range fields {
+		field, ok := f.(string)
+		if !ok {
+			return nil, newUpdateError(
+				commonerrors.ErrStageUnsetArrElementInvalidType,
+				"$unset specification must be a string or an array containing only string values",
+				command,
+			)
+		}
+
+		path, err := types.NewPathFromString(field)
+		if err != nil || strings.HasPrefix(field, "$") {
+			return nil, newUpdateError(
+				commonerrors.ErrFieldPathInvalidName,
+				fmt.Sprintf("Invalid $unset :: caused by :: FieldPath field names may not start with '$'. "+
+					"Consider using $getField or $setField. Field: %q", field),
+				command,
+			)
+		}
+
+		res = append(res, path)
+	}
+
+	return res, nil
+}
+
+// replaceRootExpression returns newRoot expression of $replaceRoot update pipeline stage.
+func replaceRootExpression(command string, spec any) (any, error) {
+	specDoc, ok := spec.(*types.Document)
+	if !ok {
+		return nil, newUpdateError(
+			commonerrors.ErrFailedToParse,
+			fmt.Sprintf(
+				"BSON field '$replaceRoot' is the wrong type '%s', expected type 'object'",
+				commonparams.AliasFromType(spec),
+			),
+			command,
+		)
+	}
+
+	for _, key := range specDoc.Keys() {
+		if key != "newRoot" {
+			return nil, newUpdateError(
+				commonerrors.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field '$replaceRoot.%s' is an unknown field.", key),
+				command,
+			)
+		}
+	}
+
+	newRoot, err := specDoc.Get("newRoot")
+	if err != nil {
+		return nil, newUpdateError(
+			commonerrors.ErrStageReplaceRootNoNewRoot,
+			"no newRoot specified for the $replaceRoot stage",
+			command,
+		)
+	}
+
+	return newRoot, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// UpdateUserParams represents parameters of the updateUser command.
+//
+// Nil fields are not updated.
+type UpdateUserParams struct {
+	DB       string
+	Username string
+
+	Credentials *types.Document
+	Roles       *types.Array
+	CustomData  *types.Document
+}
+
+// GetUpdateUserParams returns parameters of the updateUser command.
+//
+// If the password is changed, new salted credentials are generated.
+func GetUpdateUserParams(document *types.Document, l *zap.Logger) (*UpdateUserParams, error) {
+	command := document.Command()
+
+	if err := Unimplemented(document, "authenticationRestrictions"); err != nil {
+		return nil, err
+	}
+
+	Ignored(document, l, "writeConcern", "digestPassword", "comment")
+
+	db, err := GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	username, err := GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	params := UpdateUserParams{
+		DB:       db,
+		Username: username,
+	}
+
+	if document.Has("pwd") {
+		var password string
+		if password, err = GetRequiredParam[string](document, "pwd"); err != nil {
+			return nil, err
+		}
+
+		if err = checkPassword(command, password); err != nil {
+			return nil, err
+		}
+
+		var credentials *types.Document
+		if credentials, err = MakeSCRAMSHA256Credentials(password); err != nil {
+			return nil, err
+		}
+
+		params.Credentials = must.NotFail(types.NewDocument("SCRAM-SHA-256", credentials))
+	}
+
+	if params.Roles, err = GetOptionalParam[*types.Array](document, "roles", nil); err != nil {
+		return nil, err
+	}
+
+	if params.Roles != nil {
+		if params.Roles, err = userRoles(command, db, params.Roles); err != nil {
+			return nil, err
+		}
+	}
+
+	if params.CustomData, err = GetOptionalParam[*types.Document](document, "customData", nil); err != nil {
+		return nil, err
+	}
+
+	mechanisms, err := GetOptionalParam[*types.Array](document, "mechanisms", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkMechanisms(command, mechanisms); err != nil {
+		return nil, err
+	}
+
+	if params.Credentials == nil && params.Roles == nil && params.CustomData == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"Must specify at least one field to update in updateUser",
+			command,
+		)
+	}
+
+	return &params, nil
+}
+
+// Apply updates the given user document.
+func (params *UpdateUserParams) Apply(user *types.Document) {
+	if params.Credentials != nil {
+		user.Set("credentials", params.Credentials)
+	}
+
+	if params.Roles != nil {
+		user.Set("roles", params.Roles)
+	}
+
+	if params.CustomData != nil {
+		user.Set("customData", params.CustomData)
+	}
+}
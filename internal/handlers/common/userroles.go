@@ -0,0 +1,107 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// UserRolesParams represents parameters of the grantRolesToUser and revokeRolesFromUser commands.
+type UserRolesParams struct {
+	DB       string
+	Username string
+
+	// Roles contains {role, db} documents of existing roles.
+	Roles *types.Array
+}
+
+// GetUserRolesParams returns parameters of the grantRolesToUser and revokeRolesFromUser commands.
+func GetUserRolesParams(document *types.Document, l *zap.Logger) (*UserRolesParams, error) {
+	command := document.Command()
+
+	Ignored(document, l, "writeConcern", "comment")
+
+	db, err := GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	username, err := GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := GetRequiredParam[*types.Array](document, "roles")
+	if err != nil {
+		return nil, err
+	}
+
+	if roles, err = userRoles(command, db, roles); err != nil {
+		return nil, err
+	}
+
+	return &UserRolesParams{
+		DB:       db,
+		Username: username,
+		Roles:    roles,
+	}, nil
+}
+
+// Grant adds roles to the given user document.
+//
+// Roles that the user already has are not duplicated.
+func (params *UserRolesParams) Grant(user *types.Document) {
+	roles := userRolesArray(user)
+
+	for i := 0; i < params.Roles.Len(); i++ {
+		role := must.NotFail(params.Roles.Get(i)).(*types.Document)
+		if !roles.Contains(role) {
+			roles.Append(role)
+		}
+	}
+
+	user.Set("roles", roles)
+}
+
+// Revoke removes roles from the given user document.
+//
+// Roles that the user does not have are ignored.
+func (params *UserRolesParams) Revoke(user *types.Document) {
+	roles := userRolesArray(user)
+	res := types.MakeArray(roles.Len())
+
+	for i := 0; i < roles.Len(); i++ {
+		role := must.NotFail(roles.Get(i))
+		if !params.Roles.Contains(role) {
+			res.Append(role)
+		}
+	}
+
+	user.Set("roles", res)
+}
+
+// userRolesArray returns roles array of the given user document.
+func userRolesArray(user *types.Document) *types.Array {
+	v, _ := user.Get("roles")
+	if roles, _ := v.(*types.Array); roles != nil {
+		return roles
+	}
+
+	return types.MakeArray(0)
+}
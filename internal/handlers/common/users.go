@@ -0,0 +1,225 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+const (
+	// UsersDatabase is the name of the database that stores users, like in MongoDB.
+	UsersDatabase = "admin"
+
+	// UsersCollection is the name of the collection in UsersDatabase that stores users.
+	UsersCollection = "system.users"
+)
+
+// UserGetter returns the user document for the given database and username,
+// or nil if such user does not exist.
+//
+// Handlers implement it on top of their storage of UsersCollection.
+type UserGetter func(ctx context.Context, db, username string) (*types.Document, error)
+
+// UserID returns the _id of the user document for the given database and username.
+func UserID(db, username string) string {
+	return db + "." + username
+}
+
+// FindUser returns the user document with the given database and username from the iterator,
+// or nil if there is no such document.
+//
+// The iterator is consumed until the user is found, but not closed.
+func FindUser(iter types.DocumentsIterator, db, username string) (*types.Document, error) {
+	id := UserID(db, username)
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			return nil, nil
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if v, _ := doc.Get("_id"); v == id {
+			return doc, nil
+		}
+	}
+}
+
+// MakeUser returns a new user document with salted SCRAM-SHA-256 credentials for the given password.
+//
+// The document has the same layout as documents in MongoDB's admin.system.users collection;
+// the password itself is not stored.
+func MakeUser(db, username, password string, roles *types.Array) (*types.Document, error) {
+	credentials, err := MakeSCRAMSHA256Credentials(password)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return must.NotFail(types.NewDocument(
+		"_id", UserID(db, username),
+		"userId", types.Binary{Subtype: types.BinaryUUID, B: must.NotFail(uuid.New().MarshalBinary())},
+		"user", username,
+		"db", db,
+		"credentials", must.NotFail(types.NewDocument(
+			"SCRAM-SHA-256", credentials,
+		)),
+		"roles", roles,
+	)), nil
+}
+
+// userRoles returns roles of the user document for the given roles parameter.
+//
+// Role names are converted to documents with the given database.
+// It returns RoleNotFound protocol error if some role does not exist.
+func userRoles(command, db string, roles *types.Array) (*types.Array, error) {
+	res, err := roleDocuments(command, db, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < res.Len(); i++ {
+		role := must.NotFail(res.Get(i)).(*types.Document)
+		name := must.NotFail(role.Get("role")).(string)
+		roleDB := must.NotFail(role.Get("db")).(string)
+
+		if !roleExists(name, roleDB) {
+			return nil, RoleNotFound(command, roleDB, name)
+		}
+	}
+
+	return res, nil
+}
+
+// roleDocuments returns {role, db} documents for the given array of role names and documents.
+//
+// Role names are converted to documents with the given database.
+func roleDocuments(command, db string, roles *types.Array) (*types.Array, error) {
+	res := types.MakeArray(roles.Len())
+
+	iter := roles.Iterator()
+	defer iter.Close()
+
+	for {
+		_, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		switch role := v.(type) {
+		case string:
+			res.Append(must.NotFail(types.NewDocument("role", role, "db", db)))
+
+		case *types.Document:
+			name, err := GetRequiredParam[string](role, "role")
+			if err != nil {
+				return nil, err
+			}
+
+			roleDB, err := GetRequiredParam[string](role, "db")
+			if err != nil {
+				return nil, err
+			}
+
+			res.Append(must.NotFail(types.NewDocument("role", name, "db", roleDB)))
+
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("Role names must be either strings or objects, got %s", commonparams.AliasFromType(v)),
+				command,
+			)
+		}
+	}
+
+	return res, nil
+}
+
+// checkPassword returns protocol error if the password could not be used.
+func checkPassword(command string, password string) error {
+	if password == "" {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"Password cannot be empty",
+			command,
+		)
+	}
+
+	return nil
+}
+
+// checkMechanisms returns protocol error if the mechanisms parameter contains unsupported mechanisms.
+func checkMechanisms(command string, mechanisms *types.Array) error {
+	if mechanisms == nil {
+		return nil
+	}
+
+	iter := mechanisms.Iterator()
+	defer iter.Close()
+
+	for {
+		_, v, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			return nil
+		}
+
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if v != "SCRAM-SHA-256" {
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("Unknown auth mechanism '%v'", v),
+				command,
+			)
+		}
+	}
+}
+
+// UserNotFound returns protocol error for the user that does not exist.
+func UserNotFound(command, db, username string) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrUserNotFound,
+		fmt.Sprintf("User '%s@%s' not found", username, db),
+		command,
+	)
+}
+
+// UserAlreadyExists returns protocol error for the user that already exists.
+func UserAlreadyExists(command, db, username string) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrUserAlreadyExists,
+		fmt.Sprintf("User \"%s@%s\" already exists", username, db),
+		command,
+	)
+}
@@ -0,0 +1,261 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// UsersInfoParams represents parameters of the usersInfo command.
+type UsersInfoParams struct {
+	DB string
+
+	// IDs contains _id values of requested users.
+	// If it is nil, all users of DB are requested (or all users if AllDBs is true).
+	IDs []string
+
+	AllDBs          bool
+	ShowCredentials bool
+	ShowCustomData  bool
+}
+
+// GetUsersInfoParams returns parameters of the usersInfo command.
+func GetUsersInfoParams(document *types.Document, l *zap.Logger) (*UsersInfoParams, error) {
+	command := document.Command()
+
+	if err := UnimplementedNonDefault(document, "showPrivileges", func(v any) bool {
+		b, ok := v.(bool)
+		return ok && !b
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := Unimplemented(document, "filter"); err != nil {
+		return nil, err
+	}
+
+	Ignored(document, l, "showAuthenticationRestrictions", "comment")
+
+	db, err := GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	params := UsersInfoParams{
+		DB:             db,
+		ShowCustomData: true,
+	}
+
+	for _, f := range []struct {
+		key string
+		dst *bool
+	}{
+		{key: "showCredentials", dst: &params.ShowCredentials},
+		{key: "showCustomData", dst: &params.ShowCustomData},
+	} {
+		v, _ := document.Get(f.key)
+		if v == nil {
+			continue
+		}
+
+		if *f.dst, err = commonparams.GetBoolOptionalParam(f.key, v); err != nil {
+			return nil, err
+		}
+	}
+
+	switch v := must.NotFail(document.Get(command)).(type) {
+	case float64, int32, int64:
+		// all users of the database
+
+	case string:
+		params.IDs = []string{UserID(db, v)}
+
+	case *types.Document:
+		if v.Has("forAllDBs") {
+			if params.AllDBs, err = commonparams.GetBoolOptionalParam("forAllDBs", must.NotFail(v.Get("forAllDBs"))); err != nil {
+				return nil, err
+			}
+
+			if !params.AllDBs {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrBadValue,
+					"forAllDBs must be true",
+					command,
+				)
+			}
+
+			break
+		}
+
+		id, err := usersInfoUserID(db, v)
+		if err != nil {
+			return nil, err
+		}
+
+		params.IDs = []string{id}
+
+	case *types.Array:
+		params.IDs = make([]string, 0, v.Len())
+
+		iter := v.Iterator()
+		defer iter.Close()
+
+		for {
+			_, u, err := iter.Next()
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				break
+			}
+
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			id, err := usersInfoUserID(db, u)
+			if err != nil {
+				return nil, err
+			}
+
+			params.IDs = append(params.IDs, id)
+		}
+
+	default:
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("User and role names must be either strings or objects, got %s", commonparams.AliasFromType(v)),
+			command,
+		)
+	}
+
+	return &params, nil
+}
+
+// usersInfoUserID returns _id of the user document for the given usersInfo's user specification.
+func usersInfoUserID(db string, v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return UserID(db, v), nil
+
+	case *types.Document:
+		username, err := GetRequiredParam[string](v, "user")
+		if err != nil {
+			return "", err
+		}
+
+		userDB, err := GetRequiredParam[string](v, "db")
+		if err != nil {
+			return "", err
+		}
+
+		return UserID(userDB, username), nil
+
+	default:
+		return "", commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("User and role names must be either strings or objects, got %s", commonparams.AliasFromType(v)),
+			"usersInfo",
+		)
+	}
+}
+
+// UsersInfo returns the users array of the usersInfo command response
+// for user documents from the given iterator.
+//
+// The iterator is consumed, but not closed.
+// Users are sorted by _id.
+func UsersInfo(iter types.DocumentsIterator, params *UsersInfoParams) (*types.Array, error) {
+	var users []*types.Document
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		id, _ := doc.Get("_id")
+		db, _ := doc.Get("db")
+
+		switch {
+		case params.IDs != nil:
+			var found bool
+
+			for _, requested := range params.IDs {
+				if id == requested {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				continue
+			}
+
+		case !params.AllDBs && db != params.DB:
+			continue
+		}
+
+		user := doc.DeepCopy()
+
+		mechanisms := types.MakeArray(1)
+
+		if v, _ := user.Get("credentials"); v != nil {
+			if credentials, ok := v.(*types.Document); ok {
+				for _, mechanism := range credentials.Keys() {
+					mechanisms.Append(mechanism)
+				}
+			}
+		}
+
+		if !params.ShowCredentials {
+			user.Remove("credentials")
+		}
+
+		if !params.ShowCustomData {
+			user.Remove("customData")
+		}
+
+		user.Set("mechanisms", mechanisms)
+
+		users = append(users, user)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		idI, _ := users[i].Get("_id")
+		idJ, _ := users[j].Get("_id")
+
+		return fmt.Sprint(idI) < fmt.Sprint(idJ)
+	})
+
+	res := types.MakeArray(len(users))
+	for _, user := range users {
+		res.Append(user)
+	}
+
+	return res, nil
+}
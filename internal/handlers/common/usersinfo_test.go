@@ -0,0 +1,113 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestUsersInfo(t *testing.T) {
+	t.Parallel()
+
+	var users []*types.Document
+
+	for _, u := range []struct{ db, username string }{
+		{"test", "b"},
+		{"test", "a"},
+		{"other", "a"},
+	} {
+		roles := must.NotFail(types.NewArray("read"))
+		roles, err := userRoles("createUser", u.db, roles)
+		require.NoError(t, err)
+
+		user, err := MakeUser(u.db, u.username, "password", roles)
+		require.NoError(t, err)
+
+		users = append(users, user)
+	}
+
+	for name, tc := range map[string]struct {
+		command  *types.Document
+		expected []string
+	}{
+		"DB": {
+			command:  must.NotFail(types.NewDocument("usersInfo", int32(1), "$db", "test")),
+			expected: []string{"test.a", "test.b"},
+		},
+		"Name": {
+			command:  must.NotFail(types.NewDocument("usersInfo", "a", "$db", "test")),
+			expected: []string{"test.a"},
+		},
+		"Document": {
+			command: must.NotFail(types.NewDocument(
+				"usersInfo", must.NotFail(types.NewDocument("user", "a", "db", "other")),
+				"$db", "test",
+			)),
+			expected: []string{"other.a"},
+		},
+		"Array": {
+			command: must.NotFail(types.NewDocument(
+				"usersInfo", must.NotFail(types.NewArray("b", must.NotFail(types.NewDocument("user", "a", "db", "other")))),
+				"$db", "test",
+			)),
+			expected: []string{"other.a", "test.b"},
+		},
+		"AllDBs": {
+			command: must.NotFail(types.NewDocument(
+				"usersInfo", must.NotFail(types.NewDocument("forAllDBs", true)),
+				"$db", "test",
+			)),
+			expected: []string{"other.a", "test.a", "test.b"},
+		},
+		"NotFound": {
+			command:  must.NotFail(types.NewDocument("usersInfo", "c", "$db", "test")),
+			expected: []string{},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			params, err := GetUsersInfoParams(tc.command, testutil.Logger(t))
+			require.NoError(t, err)
+
+			iter := iterator.Values(iterator.ForSlice(users))
+			defer iter.Close()
+
+			res, err := UsersInfo(iter, params)
+			require.NoError(t, err)
+
+			actual := make([]string, 0, res.Len())
+
+			for i := 0; i < res.Len(); i++ {
+				user := must.NotFail(res.Get(i)).(*types.Document)
+				assert.False(t, user.Has("credentials"))
+				assert.Equal(t, must.NotFail(types.NewArray("SCRAM-SHA-256")), must.NotFail(user.Get("mechanisms")))
+
+				actual = append(actual, must.NotFail(user.Get("_id")).(string))
+			}
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
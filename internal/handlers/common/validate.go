@@ -19,13 +19,42 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
+// ValidateParams represents parameters for the validate command.
+type ValidateParams struct {
+	DB         string `ferretdb:"$db"`
+	Collection string `ferretdb:"collection"`
+
+	Full   bool `ferretdb:"full,opt"`
+	Repair bool `ferretdb:"repair,opt"`
+
+	Metadata   bool `ferretdb:"metadata,ignored"`
+	Background bool `ferretdb:"background,ignored"`
+	Comment    any  `ferretdb:"comment,ignored"`
+	LSID       any  `ferretdb:"lsid,ignored"`
+}
+
+// GetValidateParams returns the parameters for the validate command.
+func GetValidateParams(document *types.Document, l *zap.Logger) (*ValidateParams, error) {
+	var params ValidateParams
+
+	err := commonparams.ExtractParams(document, "validate", &params, l)
+	if err != nil {
+		return nil, err
+	}
+
+	return &params, nil
+}
+
 // Validate is a part of a common implementation of the validate command.
+//
+// It does not perform any checks and always reports the collection as valid.
 func Validate(ctx context.Context, msg *wire.OpMsg, l *zap.Logger) (*wire.OpMsg, error) {
 	document, err := msg.Document()
 	if err != nil {
@@ -0,0 +1,214 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// ValidateDBMetadataParams represents parameters for the validateDBMetadata command.
+type ValidateDBMetadataParams struct {
+	DB         string // empty for all databases
+	Collection string // empty for all collections
+
+	APIParameters APIParameters
+}
+
+// APIParameters represents the API version parameters to check stored metadata against.
+type APIParameters struct {
+	Version           string `ferretdb:"version"`
+	Strict            bool   `ferretdb:"strict,opt"`
+	DeprecationErrors bool   `ferretdb:"deprecationErrors,opt"`
+}
+
+// apiStrictIndexKeyTypes contains index key types that are not a part of API version 1.
+var apiStrictIndexKeyTypes = map[string]struct{}{
+	"text":        {},
+	"geoHaystack": {},
+}
+
+// apiStrictValidatorOperators contains operators that are not a part of API version 1.
+//
+// The list is conservative; operators that FerretDB does not support in validators are not listed.
+var apiStrictValidatorOperators = map[string]struct{}{
+	"$text":        {},
+	"$where":       {},
+	"$function":    {},
+	"$accumulator": {},
+}
+
+// GetValidateDBMetadataParams returns the parameters for the validateDBMetadata command.
+//
+// The command has a `collection` field that clashes with the way ExtractParams handles
+// the command's own value, so only the nested apiParameters document uses it.
+func GetValidateDBMetadataParams(document *types.Document, l *zap.Logger) (*ValidateDBMetadataParams, error) {
+	command := document.Command()
+
+	Ignored(document, l, "comment", "lsid")
+
+	var res ValidateDBMetadataParams
+	var err error
+
+	if res.DB, err = GetOptionalParam(document, "db", ""); err != nil {
+		return nil, err
+	}
+
+	if res.Collection, err = GetOptionalParam(document, "collection", ""); err != nil {
+		return nil, err
+	}
+
+	v, _ := document.Get("apiParameters")
+	if v == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMissingField,
+			fmt.Sprintf("BSON field '%s.apiParameters' is missing but a required field", command),
+			command,
+		)
+	}
+
+	apiParameters, ok := v.(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field '%s.apiParameters' is the wrong type '%s', expected type 'object'",
+				command, commonparams.AliasFromType(v),
+			),
+			command,
+		)
+	}
+
+	if err = commonparams.ExtractParams(apiParameters, command+".apiParameters", &res.APIParameters, l); err != nil {
+		return nil, err
+	}
+
+	if res.APIParameters.Version != "1" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("API version must be \"1\", got %q", res.APIParameters.Version),
+			command,
+		)
+	}
+
+	return &res, nil
+}
+
+// ValidateIndexAPIStrict returns the message describing why the index specification
+// (as returned by listIndexes) is not allowed in API version 1, or empty string if it is allowed.
+func ValidateIndexAPIStrict(index *types.Document) string {
+	name, _ := index.Get("name")
+
+	if sparse, _ := index.Get("sparse"); sparse == true {
+		return fmt.Sprintf("The index with name %v is not allowed in API version 1.", name)
+	}
+
+	key, _ := index.Get("key")
+
+	keyDoc, ok := key.(*types.Document)
+	if !ok {
+		return ""
+	}
+
+	for _, v := range keyDoc.Values() {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+
+		if _, ok = apiStrictIndexKeyTypes[s]; ok {
+			return fmt.Sprintf("The index with name %v is not allowed in API version 1.", name)
+		}
+	}
+
+	return ""
+}
+
+// ValidateValidatorAPIStrict returns the message describing why the collection validator
+// is not allowed in API version 1, or empty string if it is allowed.
+func ValidateValidatorAPIStrict(validator *types.Document) (string, error) {
+	op, err := findAPIStrictOperator(validator)
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	if op == "" {
+		return "", nil
+	}
+
+	return fmt.Sprintf("The validator uses %s which is not allowed in API version 1.", op), nil
+}
+
+// findAPIStrictOperator returns the first operator not allowed in API version 1
+// found anywhere in the given value, or empty string.
+func findAPIStrictOperator(v any) (string, error) {
+	switch v := v.(type) {
+	case *types.Document:
+		iter := v.Iterator()
+		defer iter.Close()
+
+		for {
+			k, val, err := iter.Next()
+			if errors.Is(err, iterator.ErrIteratorDone) {
+				return "", nil
+			}
+
+			if err != nil {
+				return "", lazyerrors.Error(err)
+			}
+
+			if strings.HasPrefix(k, "$") {
+				if _, ok := apiStrictValidatorOperators[k]; ok {
+					return k, nil
+				}
+			}
+
+			op, err := findAPIStrictOperator(val)
+			if err != nil || op != "" {
+				return op, err
+			}
+		}
+
+	case *types.Array:
+		for i := 0; i < v.Len(); i++ {
+			op, err := findAPIStrictOperator(must.NotFail(v.Get(i)))
+			if err != nil || op != "" {
+				return op, err
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// APIVersionError returns an element of the apiVersionErrors array of the validateDBMetadata reply.
+func APIVersionError(ns, msg string) *types.Document {
+	return must.NotFail(types.NewDocument(
+		"ns", ns,
+		"code", int32(commonerrors.ErrAPIStrictError),
+		"codeName", commonerrors.ErrAPIStrictError.String(),
+		"errmsg", msg,
+	))
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestValidateIndexAPIStrict(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		index    *types.Document
+		expected string
+	}{
+		"Regular": {
+			index: must.NotFail(types.NewDocument(
+				"key", must.NotFail(types.NewDocument("v", int32(1))),
+				"name", "v_1",
+			)),
+		},
+		"Text": {
+			index: must.NotFail(types.NewDocument(
+				"key", must.NotFail(types.NewDocument("v", "text")),
+				"name", "v_text",
+			)),
+			expected: "The index with name v_text is not allowed in API version 1.",
+		},
+		"Sparse": {
+			index: must.NotFail(types.NewDocument(
+				"key", must.NotFail(types.NewDocument("v", int32(1))),
+				"name", "v_1",
+				"sparse", true,
+			)),
+			expected: "The index with name v_1 is not allowed in API version 1.",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, ValidateIndexAPIStrict(tc.index))
+		})
+	}
+}
+
+func TestValidateValidatorAPIStrict(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		validator *types.Document
+		expected  string
+	}{
+		"Regular": {
+			validator: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$gt", int32(0))),
+			)),
+		},
+		"Nested": {
+			validator: must.NotFail(types.NewDocument(
+				"$or", must.NotFail(types.NewArray(
+					must.NotFail(types.NewDocument("v", int32(1))),
+					must.NotFail(types.NewDocument("$where", "true")),
+				)),
+			)),
+			expected: "The validator uses $where which is not allowed in API version 1.",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := ValidateValidatorAPIStrict(tc.validator)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
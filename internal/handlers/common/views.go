@@ -0,0 +1,191 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+const (
+	// ViewsCollection is the name of the collection that stores views of the database, like in MongoDB.
+	ViewsCollection = "system.views"
+
+	// maxViewDepth is the maximal number of nested views, like in MongoDB.
+	maxViewDepth = 20
+)
+
+// View represents a view resolved down to the underlying collection.
+type View struct {
+	// Collection is the name of the underlying collection.
+	Collection string
+
+	// Pipeline contains stages of all nested views, starting with the innermost one.
+	Pipeline *types.Array
+}
+
+// ViewID returns the _id of the view document for the given database and view name.
+func ViewID(db, name string) string {
+	return db + "." + name
+}
+
+// MakeView returns a new view document for the given database and view name.
+//
+// The document has the same layout as documents in MongoDB's system.views collection.
+func MakeView(db, name, viewOn string, pipeline *types.Array) *types.Document {
+	return must.NotFail(types.NewDocument(
+		"_id", ViewID(db, name),
+		"viewOn", viewOn,
+		"pipeline", pipeline,
+	))
+}
+
+// ResolveView returns the view with the given database and name from the iterator of view documents,
+// or nil if there is no such view.
+// Views on other views are resolved down to the underlying collection.
+//
+// The iterator is consumed, but not closed.
+//
+// Command error codes:
+//   - ErrViewDepthLimitExceeded when views are nested too deeply or form a cycle.
+func ResolveView(iter types.DocumentsIterator, db, name string) (*View, error) {
+	views := map[string]*types.Document{}
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if id, ok := must.NotFail(doc.Get("_id")).(string); ok {
+			views[id] = doc
+		}
+	}
+
+	doc := views[ViewID(db, name)]
+	if doc == nil {
+		return nil, nil
+	}
+
+	var res View
+	var pipelines []*types.Array
+
+	for doc != nil {
+		if len(pipelines) == maxViewDepth {
+			return nil, commonerrors.NewCommandErrorMsg(
+				commonerrors.ErrViewDepthLimitExceeded,
+				fmt.Sprintf("View depth too deep or view cycle detected. Maximum depth is %d", maxViewDepth),
+			)
+		}
+
+		res.Collection, _ = must.NotFail(doc.Get("viewOn")).(string)
+
+		pipeline, _ := must.NotFail(doc.Get("pipeline")).(*types.Array)
+		pipelines = append(pipelines, pipeline)
+
+		doc = views[ViewID(db, res.Collection)]
+	}
+
+	res.Pipeline = types.MakeArray(0)
+
+	for i := len(pipelines) - 1; i >= 0; i-- {
+		res.Pipeline.Append(must.NotFail(iterator.ConsumeValues(pipelines[i].Iterator()))...)
+	}
+
+	return &res, nil
+}
+
+// GetViewParams returns viewOn and pipeline parameters of the create or collMod command.
+// The returned viewOn is empty and pipeline is nil if they are not set.
+//
+// Command error codes:
+//   - ErrTypeMismatch when viewOn is not a string, pipeline is not an array, or its element is not a document;
+//   - ErrBadValue when viewOn is empty;
+//   - ErrInvalidOptions when pipeline is set without viewOn.
+func GetViewParams(document *types.Document) (string, *types.Array, error) {
+	command := document.Command()
+
+	var viewOn string
+
+	if v, _ := document.Get("viewOn"); v != nil {
+		var ok bool
+		if viewOn, ok = v.(string); !ok {
+			return "", nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				fmt.Sprintf(
+					"BSON field '%s.viewOn' is the wrong type '%s', expected type 'string'",
+					command, commonparams.AliasFromType(v),
+				),
+				command,
+			)
+		}
+
+		if viewOn == "" {
+			return "", nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				"'viewOn' cannot be empty",
+				command,
+			)
+		}
+	}
+
+	v, _ := document.Get("pipeline")
+	if v == nil {
+		return viewOn, nil, nil
+	}
+
+	if viewOn == "" {
+		return "", nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidOptions,
+			"'pipeline' requires 'viewOn' to also be specified",
+			command,
+		)
+	}
+
+	pipeline, ok := v.(*types.Array)
+	if !ok {
+		return "", nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field '%s.pipeline' is the wrong type '%s', expected type 'array'",
+				command, commonparams.AliasFromType(v),
+			),
+			command,
+		)
+	}
+
+	for i := 0; i < pipeline.Len(); i++ {
+		if _, ok = must.NotFail(pipeline.Get(i)).(*types.Document); !ok {
+			return "", nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				"Each element of the 'pipeline' array must be an object",
+				command,
+			)
+		}
+	}
+
+	return viewOn, pipeline, nil
+}
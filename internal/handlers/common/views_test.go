@@ -0,0 +1,113 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestResolveView(t *testing.T) {
+	t.Parallel()
+
+	stage := func(n int32) *types.Document {
+		return must.NotFail(types.NewDocument("$limit", n))
+	}
+
+	views := []*types.Document{
+		MakeView("test", "v1", "c", must.NotFail(types.NewArray(stage(1)))),
+		MakeView("test", "v2", "v1", must.NotFail(types.NewArray(stage(2), stage(3)))),
+		MakeView("other", "v3", "v2", types.MakeArray(0)),
+		MakeView("test", "cycle1", "cycle2", types.MakeArray(0)),
+		MakeView("test", "cycle2", "cycle1", types.MakeArray(0)),
+	}
+
+	for name, tc := range map[string]struct {
+		db       string
+		name     string
+		expected *View
+		err      error
+	}{
+		"Collection": {
+			db:   "test",
+			name: "c",
+		},
+		"View": {
+			db:   "test",
+			name: "v1",
+			expected: &View{
+				Collection: "c",
+				Pipeline:   must.NotFail(types.NewArray(stage(1))),
+			},
+		},
+		"Nested": {
+			db:   "test",
+			name: "v2",
+			expected: &View{
+				Collection: "c",
+				Pipeline:   must.NotFail(types.NewArray(stage(1), stage(2), stage(3))),
+			},
+		},
+		"OtherDatabase": {
+			db:   "other",
+			name: "v3",
+			expected: &View{
+				Collection: "v2",
+				Pipeline:   types.MakeArray(0),
+			},
+		},
+		"Cycle": {
+			db:   "test",
+			name: "cycle1",
+			err: commonerrors.NewCommandErrorMsg(
+				commonerrors.ErrViewDepthLimitExceeded,
+				"View depth too deep or view cycle detected. Maximum depth is 20",
+			),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			iter := iterator.Values(iterator.ForSlice(views))
+			defer iter.Close()
+
+			actual, err := ResolveView(iter, tc.db, tc.name)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			if tc.expected == nil {
+				assert.Nil(t, actual)
+				return
+			}
+
+			require.NotNil(t, actual)
+			assert.Equal(t, tc.expected.Collection, actual.Collection)
+			testutil.AssertEqual(t, tc.expected.Pipeline, actual.Pipeline)
+		})
+	}
+}
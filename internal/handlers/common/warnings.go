@@ -0,0 +1,125 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// responseWarnings is true if non-fatal warnings are added to responses.
+//
+// It could be changed with the setParameter command.
+var responseWarnings atomic.Bool
+
+// warningsKey is a context key for warnings.
+type warningsKey struct{}
+
+// warnings collects non-fatal warnings of a single command.
+type warnings struct {
+	m    sync.Mutex
+	msgs []string
+}
+
+// WithWarnings returns a derived context that collects warnings added by AddWarning
+// if the ferretdbResponseWarnings parameter is enabled.
+// Otherwise, it returns the given context.
+func WithWarnings(ctx context.Context) context.Context {
+	if !responseWarnings.Load() {
+		return ctx
+	}
+
+	return context.WithValue(ctx, warningsKey{}, new(warnings))
+}
+
+// AddWarning adds a non-fatal warning, like about the slow path being used, to the command's response.
+//
+// Duplicate warnings are ignored.
+// It does nothing if the context was not created by WithWarnings or warnings are disabled.
+func AddWarning(ctx context.Context, msg string) {
+	w, _ := ctx.Value(warningsKey{}).(*warnings)
+	if w == nil {
+		return
+	}
+
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	if !slices.Contains(w.msgs, msg) {
+		w.msgs = append(w.msgs, msg)
+	}
+}
+
+// SetWarnings sets the "warning" field of the response document to collected warnings, if any.
+func SetWarnings(ctx context.Context, reply *wire.OpMsg) error {
+	w, _ := ctx.Value(warningsKey{}).(*warnings)
+	if w == nil {
+		return nil
+	}
+
+	w.m.Lock()
+	msgs := slices.Clone(w.msgs)
+	w.m.Unlock()
+
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	doc, err := reply.Document()
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	doc.Set("warning", strings.Join(msgs, "; "))
+
+	if err = reply.SetSections(wire.OpMsgSection{Documents: []*types.Document{doc}}); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// responseWarningsParameter represents FerretDB-specific parameter that enables warnings in responses.
+type responseWarningsParameter struct{}
+
+// Get implements Parameter interface.
+func (responseWarningsParameter) Get() any {
+	return responseWarnings.Load()
+}
+
+// Set implements Parameter interface.
+func (responseWarningsParameter) Set(v any) error {
+	b, ok := v.(bool)
+	if !ok {
+		return ParameterTypeError("ferretdbResponseWarnings", v, "bool")
+	}
+
+	responseWarnings.Store(b)
+
+	return nil
+}
+
+// check interfaces
+var (
+	_ Parameter = responseWarningsParameter{}
+)
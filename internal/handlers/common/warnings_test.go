@@ -0,0 +1,90 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+func TestWarnings(t *testing.T) {
+	// global warnings state is changed, so the test is not parallel
+
+	enabled := responseWarnings.Load()
+	t.Cleanup(func() {
+		responseWarnings.Store(enabled)
+	})
+
+	p := NewParameters().m["ferretdbResponseWarnings"]
+	assert.Error(t, p.Set(int32(1)))
+
+	newReply := func() *wire.OpMsg {
+		var reply wire.OpMsg
+		must.NoError(reply.SetSections(wire.OpMsgSection{
+			Documents: []*types.Document{must.NotFail(types.NewDocument("ok", float64(1)))},
+		}))
+
+		return &reply
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		require.NoError(t, p.Set(false))
+
+		ctx := WithWarnings(context.Background())
+		AddWarning(ctx, "foo")
+
+		reply := newReply()
+		require.NoError(t, SetWarnings(ctx, reply))
+		assert.Equal(t, []string{"ok"}, must.NotFail(reply.Document()).Keys())
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		require.NoError(t, p.Set(true))
+		assert.Equal(t, true, p.Get())
+
+		ctx := WithWarnings(context.Background())
+
+		reply := newReply()
+		require.NoError(t, SetWarnings(ctx, reply))
+		assert.Equal(t, []string{"ok"}, must.NotFail(reply.Document()).Keys())
+
+		AddWarning(ctx, "foo")
+		AddWarning(ctx, "bar")
+		AddWarning(ctx, "foo")
+
+		require.NoError(t, SetWarnings(ctx, reply))
+
+		doc := must.NotFail(reply.Document())
+		assert.Equal(t, "foo; bar", must.NotFail(doc.Get("warning")))
+	})
+
+	t.Run("NoCollector", func(t *testing.T) {
+		require.NoError(t, p.Set(true))
+
+		ctx := context.Background()
+		AddWarning(ctx, "foo")
+
+		reply := newReply()
+		require.NoError(t, SetWarnings(ctx, reply))
+		assert.Equal(t, []string{"ok"}, must.NotFail(reply.Document()).Keys())
+	})
+}
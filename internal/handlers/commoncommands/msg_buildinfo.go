@@ -38,6 +38,7 @@ func MsgBuildInfo(context.Context, *wire.OpMsg) (*wire.OpMsg, error) {
 			"version", version.Get().MongoDBVersion,
 			"gitVersion", version.Get().Commit,
 			"modules", must.NotFail(types.NewArray()),
+			"javascriptEngine", "none", // server-side JavaScript is not supported
 			"sysInfo", "deprecated",
 			"versionArray", version.Get().MongoDBVersionArray,
 			"bits", int32(strconv.IntSize),
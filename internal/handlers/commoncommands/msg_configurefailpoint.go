@@ -0,0 +1,176 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commoncommands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/failpoints"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// init adds configureFailPoint test command to Commands.
+func init() {
+	Commands["configureFailPoint"] = command{
+		Help:    "Configures failpoints (test command).",
+		Handler: msgConfigureFailPoint,
+		Test:    true,
+	}
+}
+
+// msgConfigureFailPoint implements configureFailPoint command.
+func msgConfigureFailPoint(_ handlers.Interface, ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	db, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if db != "admin" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	name, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := failpoints.GetRegistry(ctx)
+	if registry == nil {
+		return nil, lazyerrors.New("no failpoints registry in context")
+	}
+
+	fp := registry.Get(name)
+	if fp == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("Cannot find failpoint %s", name),
+			command,
+		)
+	}
+
+	modeV, err := document.Get("mode")
+	if err != nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"Missing mode",
+			command,
+		)
+	}
+
+	mode, err := parseFailPointMode(modeV)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := common.GetOptionalParam(document, "data", new(types.Document))
+	if err != nil {
+		return nil, err
+	}
+
+	count := fp.Configure(mode, data.DeepCopy())
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"count", count,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// parseFailPointMode parses configureFailPoint's mode parameter.
+//
+// Mode is either "off", "alwaysOn", or a document with a single
+// `times`, `skip`, or `activationProbability` field.
+func parseFailPointMode(v any) (failpoints.Mode, error) {
+	switch v := v.(type) {
+	case string:
+		switch v {
+		case "off":
+			return failpoints.Off(), nil
+		case "alwaysOn":
+			return failpoints.AlwaysOn(), nil
+		}
+
+	case *types.Document:
+		if v.Len() != 1 {
+			break
+		}
+
+		key := v.Keys()[0]
+		val := must.NotFail(v.Get(key))
+
+		switch key {
+		case "times", "skip":
+			n, err := commonparams.GetWholeNumberParam(val)
+			if err != nil || n < 0 {
+				return failpoints.Mode{}, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrBadValue,
+					fmt.Sprintf("'%s' must be a non-negative integer", key),
+					"configureFailPoint",
+				)
+			}
+
+			if key == "skip" {
+				return failpoints.Skip(n), nil
+			}
+
+			if n == 0 {
+				return failpoints.Off(), nil
+			}
+
+			return failpoints.Times(n), nil
+
+		case "activationProbability":
+			p, ok := val.(float64)
+			if !ok || p < 0 || p > 1 {
+				return failpoints.Mode{}, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrBadValue,
+					"'activationProbability' must be a double between 0 and 1",
+					"configureFailPoint",
+				)
+			}
+
+			return failpoints.ActivationProbability(p), nil
+		}
+	}
+
+	return failpoints.Mode{}, commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrBadValue,
+		fmt.Sprintf("Unsupported mode %s", types.FormatAnyValue(v)),
+		"configureFailPoint",
+	)
+}
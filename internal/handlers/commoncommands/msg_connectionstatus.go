@@ -25,20 +25,27 @@ import (
 
 // MsgConnectionStatus is a common implementation of the connectionStatus command.
 func MsgConnectionStatus(ctx context.Context, _ *wire.OpMsg) (*wire.OpMsg, error) {
+	connInfo := conninfo.Get(ctx)
+
 	users := types.MakeArray(1)
 
-	if username, _ := conninfo.Get(ctx).Auth(); username != "" {
+	if username, _ := connInfo.Auth(); username != "" {
 		users.Append(must.NotFail(types.NewDocument(
 			"user", username,
 		)))
 	}
 
+	roles := connInfo.Roles()
+	if roles == nil {
+		roles = types.MakeArray(0)
+	}
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
 			"authInfo", must.NotFail(types.NewDocument(
 				"authenticatedUsers", users,
-				"authenticatedUserRoles", must.NotFail(types.NewArray()),
+				"authenticatedUserRoles", roles,
 				"authenticatedUserPrivileges", must.NotFail(types.NewArray()),
 			)),
 			"ok", float64(1),
@@ -0,0 +1,66 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commoncommands
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/fsynclock"
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// msgFsyncUnlock implements fsyncUnlock command.
+//
+// The fsync lock is shared by all connections, so the command does not depend on the handler.
+// Write commands are unblocked only when fsyncUnlock was called as many times as fsync with lock.
+func msgFsyncUnlock(_ handlers.Interface, ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	db, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if db != "admin" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	count, ok := fsynclock.GetLock(ctx).Unlock()
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrIllegalOperation,
+			"fsyncUnlock called when not locked",
+			command,
+		)
+	}
+
+	return okReply(
+		"info", "fsyncUnlock completed",
+		"lockCount", count,
+	), nil
+}
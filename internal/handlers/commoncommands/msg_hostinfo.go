@@ -22,7 +22,6 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
@@ -32,7 +31,7 @@ import (
 
 // MsgHostInfo is a common implementation of the hostInfo command.
 func MsgHostInfo(context.Context, *wire.OpMsg) (*wire.OpMsg, error) {
-	now := time.Now().UTC()
+	now := types.Now().UTC()
 	hostname, err := os.Hostname()
 	if err != nil {
 		return nil, lazyerrors.Error(err)
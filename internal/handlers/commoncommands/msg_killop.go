@@ -0,0 +1,86 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commoncommands
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/operation"
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// msgKillOp implements killOp command.
+//
+// Operations are stored in the registry shared by all connections,
+// so the command does not depend on the handler.
+// Like MongoDB, it succeeds even if the operation does not exist or has already finished.
+func msgKillOp(_ handlers.Interface, ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	db, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if db != "admin" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	v, err := document.Get("op")
+	if err != nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			`Did not provide "op" field`,
+			command,
+		)
+	}
+
+	id, err := commonparams.GetWholeNumberParam(v)
+	if errors.Is(err, commonparams.ErrUnexpectedType) {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			`Expected field "op" to be a number`,
+			command,
+		)
+	}
+
+	if err != nil || id < math.MinInt32 || id > math.MaxInt32 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"Invalid op field: must be a 32-bit integer",
+			command,
+		)
+	}
+
+	operation.GetRegistry(ctx).Kill(int32(id))
+
+	return okReply("info", "attempting to kill op"), nil
+}
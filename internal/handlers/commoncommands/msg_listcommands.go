@@ -36,6 +36,14 @@ type command struct {
 	//
 	// The passed context is canceled when the client disconnects.
 	Handler func(handlers.Interface, context.Context, *wire.OpMsg) (*wire.OpMsg, error)
+
+	// Public commands are allowed for all users regardless of their roles.
+	// Other commands are authorized by common.Authorize.
+	Public bool
+
+	// Test commands are available only if TestCommandsEnabled returns true.
+	// Otherwise, they are handled and listed as unknown commands.
+	Test bool
 }
 
 // Commands is a map of Commands that Handler interface can support.
@@ -44,16 +52,40 @@ type command struct {
 // Please keep help text in sync with handlers.Interface methods documentation.
 var Commands = map[string]command{
 	// sorted alphabetically
+	"abortTransaction": {
+		Help:    "Aborts the multi-document transaction.",
+		Handler: msgAbortTransaction,
+		Public:  true,
+	},
 	"aggregate": {
 		Help:    "Returns aggregated data.",
 		Handler: handlers.Interface.MsgAggregate,
 	},
+	"applyOps": {
+		Help:    "Applies insert, update, and delete oplog entries.",
+		Handler: handlers.Interface.MsgApplyOps,
+	},
+	"archiveCollection": {
+		Help:    "Stores the collection to the archive and drops it.",
+		Handler: handlers.Interface.MsgArchiveCollection,
+	},
 	"buildInfo": {
 		Help:    "Returns a summary of the build information.",
 		Handler: handlers.Interface.MsgBuildInfo,
+		Public:  true,
 	},
 	"buildinfo": { // old lowercase variant
 		Handler: handlers.Interface.MsgBuildInfo,
+		Public:  true,
+	},
+	"bulkWrite": {
+		Help:    "Performs insert, update, and delete operations on multiple collections.",
+		Handler: handlers.Interface.MsgBulkWrite,
+		Public:  true, // operations are authorized by the handler for each namespace
+	},
+	"cloneCollectionAsCapped": {
+		Help:    "Creates a new capped collection with documents of the existing collection.",
+		Handler: handlers.Interface.MsgCloneCollectionAsCapped,
 	},
 	"collMod": {
 		Help:    "Adds options to a collection or modify view definitions.",
@@ -63,10 +95,24 @@ var Commands = map[string]command{
 		Help:    "Returns storage data for a collection.",
 		Handler: handlers.Interface.MsgCollStats,
 	},
+	"commitTransaction": {
+		Help:    "Commits the multi-document transaction.",
+		Handler: msgCommitTransaction,
+		Public:  true,
+	},
+	"compact": {
+		Help:    "Reclaims unused disk space of the collection and rebuilds its indexes.",
+		Handler: handlers.Interface.MsgCompact,
+	},
 	"connectionStatus": {
 		Help: "Returns information about the current connection, " +
 			"specifically the state of authenticated users and their available permissions.",
 		Handler: handlers.Interface.MsgConnectionStatus,
+		Public:  true,
+	},
+	"convertToCapped": {
+		Help:    "Converts the existing collection to a capped collection.",
+		Handler: handlers.Interface.MsgConvertToCapped,
 	},
 	"count": {
 		Help:    "Returns the count of documents that's matched by the query.",
@@ -80,6 +126,10 @@ var Commands = map[string]command{
 		Help:    "Creates indexes on a collection.",
 		Handler: handlers.Interface.MsgCreateIndexes,
 	},
+	"createUser": {
+		Help:    "Creates a new user.",
+		Handler: handlers.Interface.MsgCreateUser,
+	},
 	"currentOp": {
 		Help:    "Returns information about operations currently in progress.",
 		Handler: handlers.Interface.MsgCurrentOp,
@@ -119,6 +169,15 @@ var Commands = map[string]command{
 		Help:    "Drops indexes on a collection.",
 		Handler: handlers.Interface.MsgDropIndexes,
 	},
+	"dropUser": {
+		Help:    "Drops the user.",
+		Handler: handlers.Interface.MsgDropUser,
+	},
+	"endSessions": {
+		Help:    "Ends logical sessions.",
+		Handler: msgEndSessions,
+		Public:  true,
+	},
 	"explain": {
 		Help:    "Returns the execution plan.",
 		Handler: handlers.Interface.MsgExplain,
@@ -134,6 +193,14 @@ var Commands = map[string]command{
 	"findandmodify": { // old lowercase variant
 		Handler: handlers.Interface.MsgFindAndModify,
 	},
+	"fsync": {
+		Help:    "Flushes pending writes to the storage and optionally locks the server against writes.",
+		Handler: handlers.Interface.MsgFsync,
+	},
+	"fsyncUnlock": {
+		Help:    "Unlocks the server locked by fsync command.",
+		Handler: msgFsyncUnlock,
+	},
 	"getCmdLineOpts": {
 		Help:    "Returns a summary of all runtime and configuration options.",
 		Handler: handlers.Interface.MsgGetCmdLineOpts,
@@ -154,9 +221,14 @@ var Commands = map[string]command{
 		Help:    "Returns the value of the parameter.",
 		Handler: handlers.Interface.MsgGetParameter,
 	},
+	"grantRolesToUser": {
+		Help:    "Grants roles to the user.",
+		Handler: handlers.Interface.MsgGrantRolesToUser,
+	},
 	"hello": {
 		Help:    "Returns the role of the FerretDB instance.",
 		Handler: handlers.Interface.MsgHello,
+		Public:  true,
 	},
 	"hostInfo": {
 		Help:    "Returns a summary of the system information.",
@@ -169,14 +241,29 @@ var Commands = map[string]command{
 	"isMaster": {
 		Help:    "Returns the role of the FerretDB instance.",
 		Handler: handlers.Interface.MsgIsMaster,
+		Public:  true,
 	},
 	"ismaster": { // old lowercase variant
 		Handler: handlers.Interface.MsgIsMaster,
+		Public:  true,
+	},
+	"killAllSessions": {
+		Help:    "Kills all logical sessions of the given users.",
+		Handler: msgKillAllSessions,
 	},
 	"killCursors": {
 		Help:    "Closes server cursors.",
 		Handler: handlers.Interface.MsgKillCursors,
 	},
+	"killOp": {
+		Help:    "Terminates an operation as specified by the operation ID.",
+		Handler: msgKillOp,
+	},
+	"killSessions": {
+		Help:    "Kills logical sessions.",
+		Handler: msgKillSessions,
+		Public:  true,
+	},
 	"listCollections": {
 		Help:    "Returns the information of the collections and views in the database.",
 		Handler: handlers.Interface.MsgListCollections,
@@ -184,6 +271,11 @@ var Commands = map[string]command{
 	"listCommands": {
 		Help:    "Returns a list of currently supported commands.",
 		Handler: handlers.Interface.MsgListCommands,
+		Public:  true,
+	},
+	"listCursors": {
+		Help:    "Returns information about open cursors of all connections.",
+		Handler: handlers.Interface.MsgListCursors,
 	},
 	"listDatabases": {
 		Help:    "Returns a summary of all the databases.",
@@ -193,21 +285,58 @@ var Commands = map[string]command{
 		Help:    "Returns a summary of indexes of the specified collection.",
 		Handler: handlers.Interface.MsgListIndexes,
 	},
+	"logRotate": {
+		Help:    "Rotates the log file.",
+		Handler: msgLogRotate,
+	},
 	"logout": {
 		Help:    "Logs out from the current session.",
 		Handler: handlers.Interface.MsgLogout,
+		Public:  true,
+	},
+	"moveCollection": {
+		Help:    "Moves the collection to another backend.",
+		Handler: handlers.Interface.MsgMoveCollection,
 	},
 	"ping": {
 		Help:    "Returns a pong response.",
 		Handler: handlers.Interface.MsgPing,
+		Public:  true,
+	},
+	"refreshSessions": {
+		Help:    "Updates the last-use time of logical sessions.",
+		Handler: msgRefreshSessions,
+		Public:  true,
+	},
+	"reIndex": {
+		Help:    "Rebuilds all indexes of a collection.",
+		Handler: handlers.Interface.MsgReIndex,
 	},
 	"renameCollection": {
 		Help:    "Changes the name of an existing collection.",
 		Handler: handlers.Interface.MsgRenameCollection,
 	},
+	"restoreCollection": {
+		Help:    "Restores the collection from the archive.",
+		Handler: handlers.Interface.MsgRestoreCollection,
+	},
+	"revokeRolesFromUser": {
+		Help:    "Revokes roles from the user.",
+		Handler: handlers.Interface.MsgRevokeRolesFromUser,
+	},
+	"rolesInfo": {
+		Help:    "Returns information about roles.",
+		Handler: handlers.Interface.MsgRolesInfo,
+	},
+	"saslContinue": {
+		Help:    "Continues a SASL conversation.",
+		Handler: handlers.Interface.MsgSASLContinue,
+		Public:  true,
+	},
 	"saslStart": {
 		Help:    "Starts a SASL conversation.",
 		Handler: handlers.Interface.MsgSASLStart,
+		Public:  true,
 	},
 	"serverStatus": {
 		Help:    "Returns an overview of the databases state.",
@@ -217,30 +346,64 @@ var Commands = map[string]command{
 		Help:    "Toggles free monitoring.",
 		Handler: handlers.Interface.MsgSetFreeMonitoring,
 	},
+	"setMaintenanceMode": {
+		Help:    "Enables or disables maintenance mode that rejects write commands.",
+		Handler: msgSetMaintenanceMode,
+	},
+	"setParameter": {
+		Help:    "Sets the value of the runtime parameter.",
+		Handler: handlers.Interface.MsgSetParameter,
+	},
+	"shutdown": {
+		Help:    "Stops accepting new connections and shuts down the server gracefully.",
+		Handler: msgShutdown,
+	},
+	"startSession": {
+		Help:    "Starts a new logical session.",
+		Handler: msgStartSession,
+		Public:  true,
+	},
+	"top": {
+		Help:    "Returns usage statistics for each collection.",
+		Handler: handlers.Interface.MsgTop,
+	},
 	"update": {
 		Help:    "Updates documents that are matched by the query.",
 		Handler: handlers.Interface.MsgUpdate,
 	},
+	"updateUser": {
+		Help:    "Updates the user.",
+		Handler: handlers.Interface.MsgUpdateUser,
+	},
+	"usersInfo": {
+		Help:    "Returns information about users.",
+		Handler: handlers.Interface.MsgUsersInfo,
+	},
 	"validate": {
 		Help:    "Validate collection.",
 		Handler: handlers.Interface.MsgValidate,
 	},
+	"validateDBMetadata": {
+		Help:    "Checks stored indexes and validators against the API version.",
+		Handler: handlers.Interface.MsgValidateDBMetadata,
+	},
 	"whatsmyuri": {
 		Help:    "Returns peer information.",
 		Handler: handlers.Interface.MsgWhatsMyURI,
+		Public:  true,
 	},
 	// please keep sorted alphabetically
 }
 
 // MsgListCommands is a common implementation of the listCommands command.
-func MsgListCommands(context.Context, *wire.OpMsg) (*wire.OpMsg, error) {
+func MsgListCommands(ctx context.Context, _ *wire.OpMsg) (*wire.OpMsg, error) {
 	cmdList := must.NotFail(types.NewDocument())
 	names := maps.Keys(Commands)
 	sort.Strings(names)
 
 	for _, name := range names {
 		cmd := Commands[name]
-		if cmd.Help == "" {
+		if cmd.Help == "" || (cmd.Test && !TestCommandsEnabled(ctx)) {
 			continue
 		}
 
@@ -0,0 +1,338 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commoncommands
+
+import (
+	"context"
+	"regexp"
+	"sort"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/operators/accumulators"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/stages"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// init adds migrationAssessment command to Commands.
+//
+// It can't be added directly because the command handler uses Commands.
+func init() {
+	Commands["migrationAssessment"] = command{
+		Help:    "Returns commands and operators used by the recorded workload, including unsupported ones.",
+		Handler: msgMigrationAssessment,
+	}
+}
+
+// queryOperators contains query, update, and projection operators supported by FerretDB.
+//
+// Keep it in sync with common.FilterDocument, common.UpdateDocument, and projection.
+var queryOperators = map[string]struct{}{
+	// query operators
+	"$all":           {},
+	"$and":           {},
+	"$bitsAllClear":  {},
+	"$bitsAllSet":    {},
+	"$bitsAnyClear":  {},
+	"$bitsAnySet":    {},
+	"$caseSensitive": {},
+	"$comment":       {},
+	"$elemMatch":     {},
+	"$eq":            {},
+	"$exists":        {},
+	"$expr":          {},
+	"$geometry":      {},
+	"$geoWithin":     {},
+	"$gt":            {},
+	"$gte":           {},
+	"$in":            {},
+	"$language":      {},
+	"$lt":            {},
+	"$lte":           {},
+	"$maxDistance":   {},
+	"$minDistance":   {},
+	"$mod":           {},
+	"$ne":            {},
+	"$near":          {},
+	"$nearSphere":    {},
+	"$nin":           {},
+	"$nor":           {},
+	"$not":           {},
+	"$options":       {},
+	"$or":            {},
+	"$regex":         {},
+	"$search":        {},
+	"$size":          {},
+	"$text":          {},
+	"$type":          {},
+	"$within":        {},
+
+	// update operators
+	"$addToSet":    {},
+	"$currentDate": {},
+	"$each":        {},
+	"$inc":         {},
+	"$max":         {},
+	"$min":         {},
+	"$mul":         {},
+	"$pop":         {},
+	"$pull":        {},
+	"$pullAll":     {},
+	"$push":        {},
+	"$rename":      {},
+	"$set":         {},
+	"$setOnInsert": {},
+	"$unset":       {},
+
+	// projection operators
+	"$meta": {},
+}
+
+// operatorRe matches operator names; other `$`-prefixed keys (like `$**` in index keys) are skipped.
+var operatorRe = regexp.MustCompile(`^\$[a-zA-Z]+$`)
+
+// usage represents the usage of a single command or operator in the workload.
+type usage struct {
+	count     int32
+	supported bool
+}
+
+// assessment contains the compatibility report of the recorded workload.
+type assessment struct {
+	commands  map[string]*usage
+	operators map[string]*usage
+	messages  int32
+}
+
+// newAssessment returns a new empty assessment.
+func newAssessment() *assessment {
+	return &assessment{
+		commands:  map[string]*usage{},
+		operators: map[string]*usage{},
+	}
+}
+
+// addRecord adds the given recorded message to the assessment.
+// Messages other than OP_MSG and OP_QUERY commands are skipped.
+func (a *assessment) addRecord(r *wire.Record) error {
+	var doc *types.Document
+
+	switch body := r.Body.(type) {
+	case *wire.OpMsg:
+		var err error
+		if doc, err = body.Document(); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+	case *wire.OpQuery:
+		doc = body.Query
+
+	default:
+		return nil
+	}
+
+	if doc == nil || doc.Len() == 0 {
+		return nil
+	}
+
+	a.messages++
+
+	command := doc.Command()
+
+	u := a.commands[command]
+	if u == nil {
+		_, supported := Commands[command]
+		_, unsupported := unsupportedCommands[command]
+
+		u = &usage{supported: supported && !unsupported}
+		a.commands[command] = u
+	}
+
+	u.count++
+
+	// top-level `$`-prefixed fields like `$db` are not operators
+	for _, k := range doc.Keys() {
+		a.addOperators(must.NotFail(doc.Get(k)))
+	}
+
+	return nil
+}
+
+// addOperators adds operators used in the given value to the assessment.
+//
+// The context of the operator (query, update, aggregation stage or expression) is not taken into account.
+func (a *assessment) addOperators(v any) {
+	switch v := v.(type) {
+	case *types.Document:
+		for _, k := range v.Keys() {
+			if operatorRe.MatchString(k) {
+				a.addOperator(k)
+			}
+
+			a.addOperators(must.NotFail(v.Get(k)))
+		}
+
+	case *types.Array:
+		for i := 0; i < v.Len(); i++ {
+			a.addOperators(must.NotFail(v.Get(i)))
+		}
+	}
+}
+
+// addOperator adds the given operator to the assessment.
+func (a *assessment) addOperator(name string) {
+	u := a.operators[name]
+	if u == nil {
+		u = &usage{supported: isSupportedOperator(name)}
+		a.operators[name] = u
+	}
+
+	u.count++
+}
+
+// isSupportedOperator returns true if the given operator is supported in any context.
+func isSupportedOperator(name string) bool {
+	if _, ok := queryOperators[name]; ok {
+		return true
+	}
+
+	if _, ok := stages.Stages[name]; ok {
+		return true
+	}
+
+	if _, ok := operators.Operators[name]; ok {
+		return true
+	}
+
+	_, ok := accumulators.Accumulators[name]
+
+	return ok
+}
+
+// document returns the assessment report as a document.
+func (a *assessment) document() *types.Document {
+	commandsDoc, commandsUnsupported := usageDocument(a.commands)
+	operatorsDoc, operatorsUnsupported := usageDocument(a.operators)
+
+	return must.NotFail(types.NewDocument(
+		"messages", a.messages,
+		"commands", commandsDoc,
+		"operators", operatorsDoc,
+		"unsupported", must.NotFail(types.NewDocument(
+			"commands", commandsUnsupported,
+			"operators", operatorsUnsupported,
+		)),
+	))
+}
+
+// usageDocument returns a document with usages sorted by name,
+// and an array of names of unsupported commands or operators.
+func usageDocument(m map[string]*usage) (*types.Document, *types.Array) {
+	names := maps.Keys(m)
+	sort.Strings(names)
+
+	doc := types.MakeDocument(len(names))
+	unsupported := types.MakeArray(0)
+
+	for _, name := range names {
+		u := m[name]
+
+		doc.Set(name, must.NotFail(types.NewDocument(
+			"count", u.count,
+			"supported", u.supported,
+		)))
+
+		if !u.supported {
+			unsupported.Append(name)
+		}
+	}
+
+	return doc, unsupported
+}
+
+// msgMigrationAssessment implements migrationAssessment command.
+//
+// It analyzes messages recorded in the directory set by the `--test-records-dir` flag
+// and returns commands and operators used by the workload, including unsupported ones.
+func msgMigrationAssessment(_ handlers.Interface, ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	db, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if db != "admin" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	// all records are analyzed by default
+	var limit int64
+
+	if v, _ := document.Get("limit"); v != nil {
+		if limit, err = commonparams.GetValidatedNumberParamWithMinValue(command, "limit", v, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	dir := wire.GetRecordsDir(ctx)
+	if dir == "" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrIllegalOperation,
+			command+" requires record files; set --test-records-dir flag",
+			command,
+		)
+	}
+
+	records, err := wire.LoadRecords(dir, int(limit))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	a := newAssessment()
+
+	for i := range records {
+		if err = a.addRecord(&records[i]); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	res := a.document()
+	res.Set("ok", float64(1))
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{res},
+	}))
+
+	return &reply, nil
+}
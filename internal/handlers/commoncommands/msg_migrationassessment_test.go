@@ -0,0 +1,100 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commoncommands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// opMsgRecord returns a record of OP_MSG with the given document.
+func opMsgRecord(t *testing.T, doc *types.Document) wire.Record {
+	t.Helper()
+
+	var msg wire.OpMsg
+	require.NoError(t, msg.SetSections(wire.OpMsgSection{Documents: []*types.Document{doc}}))
+
+	return wire.Record{Body: &msg}
+}
+
+func TestAssessment(t *testing.T) {
+	t.Parallel()
+
+	records := []wire.Record{
+		opMsgRecord(t, must.NotFail(types.NewDocument(
+			"find", "values",
+			"filter", must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$gt", int32(1))),
+				"$where", "this.v > 1",
+			)),
+			"$db", "test",
+		))),
+		opMsgRecord(t, must.NotFail(types.NewDocument(
+			"aggregate", "values",
+			"pipeline", must.NotFail(types.NewArray(
+				must.NotFail(types.NewDocument("$match", must.NotFail(types.NewDocument(
+					"v", must.NotFail(types.NewDocument("$gt", int32(2))),
+				)))),
+				must.NotFail(types.NewDocument("$planCacheStats", types.MakeDocument(0))),
+			)),
+			"$db", "test",
+		))),
+		opMsgRecord(t, must.NotFail(types.NewDocument(
+			"createIndexes", "values",
+			"indexes", must.NotFail(types.NewArray(
+				must.NotFail(types.NewDocument("key", must.NotFail(types.NewDocument("$**", int32(1))), "name", "all")),
+			)),
+			"$db", "test",
+		))),
+		opMsgRecord(t, must.NotFail(types.NewDocument("replSetGetStatus", int32(1), "$db", "admin"))),
+		{Body: &wire.OpQuery{Query: must.NotFail(types.NewDocument("isMaster", int32(1)))}},
+		{Body: &wire.OpReply{}},
+	}
+
+	a := newAssessment()
+
+	for i := range records {
+		require.NoError(t, a.addRecord(&records[i]))
+	}
+
+	expected := must.NotFail(types.NewDocument(
+		"messages", int32(5),
+		"commands", must.NotFail(types.NewDocument(
+			"aggregate", must.NotFail(types.NewDocument("count", int32(1), "supported", true)),
+			"createIndexes", must.NotFail(types.NewDocument("count", int32(1), "supported", true)),
+			"find", must.NotFail(types.NewDocument("count", int32(1), "supported", true)),
+			"isMaster", must.NotFail(types.NewDocument("count", int32(1), "supported", true)),
+			"replSetGetStatus", must.NotFail(types.NewDocument("count", int32(1), "supported", false)),
+		)),
+		"operators", must.NotFail(types.NewDocument(
+			"$gt", must.NotFail(types.NewDocument("count", int32(2), "supported", true)),
+			"$match", must.NotFail(types.NewDocument("count", int32(1), "supported", true)),
+			"$planCacheStats", must.NotFail(types.NewDocument("count", int32(1), "supported", false)),
+			"$where", must.NotFail(types.NewDocument("count", int32(1), "supported", false)),
+		)),
+		"unsupported", must.NotFail(types.NewDocument(
+			"commands", must.NotFail(types.NewArray("replSetGetStatus")),
+			"operators", must.NotFail(types.NewArray("$planCacheStats", "$where")),
+		)),
+	))
+
+	testutil.AssertEqual(t, expected, a.document())
+}
@@ -0,0 +1,149 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commoncommands
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/clientconn/session"
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// Logical sessions are stored in the registry shared by all connections,
+// so session commands do not depend on the handler.
+
+// msgStartSession implements startSession command.
+func msgStartSession(_ handlers.Interface, ctx context.Context, _ *wire.OpMsg) (*wire.OpMsg, error) {
+	username, _ := conninfo.Get(ctx).Auth()
+	r := session.GetRegistry(ctx)
+
+	s := r.Start(username)
+
+	return okReply(
+		"id", common.SessionDocument(s.ID),
+		"timeoutMinutes", int32(r.Timeout().Minutes()),
+	), nil
+}
+
+// msgEndSessions implements endSessions command.
+func msgEndSessions(_ handlers.Interface, ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	ids, err := common.GetSessionIDs(document)
+	if err != nil {
+		return nil, err
+	}
+
+	username, _ := conninfo.Get(ctx).Auth()
+	session.GetRegistry(ctx).End(ids, username)
+
+	return okReply(), nil
+}
+
+// msgRefreshSessions implements refreshSessions command.
+func msgRefreshSessions(_ handlers.Interface, ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	ids, err := common.GetSessionIDs(document)
+	if err != nil {
+		return nil, err
+	}
+
+	username, _ := conninfo.Get(ctx).Auth()
+	session.GetRegistry(ctx).Refresh(ids, username)
+
+	return okReply(), nil
+}
+
+// msgKillSessions implements killSessions command.
+//
+// Users could kill only their own sessions, unless authorization is not enforced.
+// Empty array kills all sessions of the user.
+func msgKillSessions(_ handlers.Interface, ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	ids, err := common.GetSessionIDs(document)
+	if err != nil {
+		return nil, err
+	}
+
+	connInfo := conninfo.Get(ctx)
+	username, _ := connInfo.Auth()
+	r := session.GetRegistry(ctx)
+
+	switch {
+	case connInfo.Roles() == nil:
+		r.Kill(ids)
+	case len(ids) == 0:
+		r.KillUsers([]string{username})
+	default:
+		r.End(ids, username)
+	}
+
+	return okReply(), nil
+}
+
+// msgKillAllSessions implements killAllSessions command.
+//
+// Empty array kills all sessions.
+func msgKillAllSessions(_ handlers.Interface, ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	users, err := common.GetSessionUsers(document)
+	if err != nil {
+		return nil, err
+	}
+
+	r := session.GetRegistry(ctx)
+
+	if len(users) == 0 {
+		r.Kill(nil)
+	} else {
+		r.KillUsers(users)
+	}
+
+	return okReply(), nil
+}
+
+// okReply returns a reply with the given fields and ok: 1.
+func okReply(pairs ...any) *wire.OpMsg {
+	res := must.NotFail(types.NewDocument(pairs...))
+	res.Set("ok", float64(1))
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{res},
+	}))
+
+	return &reply
+}
@@ -0,0 +1,75 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commoncommands
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/maintenance"
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// msgSetMaintenanceMode implements setMaintenanceMode command.
+//
+// The maintenance mode is shared by all connections, so the command does not depend on the handler.
+// In maintenance mode, write commands are rejected with a retryable error with the configured message,
+// and hello reports the server as not writable.
+func msgSetMaintenanceMode(_ handlers.Interface, ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	db, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if db != "admin" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	enabled, err := commonparams.GetBoolOptionalParam(command, must.NotFail(document.Get(command)))
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := common.GetOptionalParam(document, "message", "")
+	if err != nil {
+		return nil, err
+	}
+
+	m := maintenance.GetMode(ctx)
+	was := m.Set(enabled, message)
+
+	_, message = m.State()
+
+	return okReply(
+		"was", was,
+		"message", message,
+	), nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commoncommands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/shutdown"
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// msgShutdown implements shutdown command.
+//
+// It requests graceful shutdown of the listener: new connections are not accepted,
+// idle connections are closed, and requests in progress are waited for up to timeoutSecs.
+// Unlike MongoDB, the reply is sent before the connection is closed.
+func msgShutdown(_ handlers.Interface, ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	db, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if db != "admin" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	var force bool
+
+	if v, _ := document.Get("force"); v != nil {
+		if force, err = commonparams.GetBoolOptionalParam("force", v); err != nil {
+			return nil, err
+		}
+	}
+
+	t := shutdown.GetTrigger(ctx)
+	timeout := t.DefaultTimeout()
+
+	if v, _ := document.Get("timeoutSecs"); v != nil {
+		secs, err := commonparams.GetWholeNumberParam(v)
+
+		switch {
+		case err == nil && secs >= 0:
+			timeout = time.Duration(secs) * time.Second
+		case errors.Is(err, commonparams.ErrUnexpectedType):
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				fmt.Sprintf(
+					"BSON field 'shutdown.timeoutSecs' is the wrong type '%s', expected types '[long, int, decimal, double]'",
+					commonparams.AliasFromType(v),
+				),
+				command,
+			)
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				"timeoutSecs must be a non-negative whole number",
+				command,
+			)
+		}
+	}
+
+	if force {
+		timeout = 0
+	}
+
+	t.Shutdown(timeout)
+
+	return okReply(), nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commoncommands
+
+import (
+	"context"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// init adds sleep test command to Commands.
+func init() {
+	Commands["sleep"] = command{
+		Help:    "Sleeps for the given time (test command).",
+		Handler: msgSleep,
+		Test:    true,
+	}
+}
+
+// defaultSleep is used when neither `secs` nor `millis` is set, like in MongoDB.
+const defaultSleep = 10 * time.Second
+
+// msgSleep implements sleep command.
+//
+// `lock` and `lockTarget` parameters are ignored because FerretDB does not take global or collection locks.
+func msgSleep(_ handlers.Interface, ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	db, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if db != "admin" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	var d time.Duration
+	var set bool
+
+	for _, p := range []struct {
+		name string
+		unit time.Duration
+	}{
+		{name: "secs", unit: time.Second},
+		{name: "millis", unit: time.Millisecond},
+	} {
+		v, _ := document.Get(p.name)
+		if v == nil {
+			continue
+		}
+
+		n, err := commonparams.GetValidatedNumberParamWithMinValue(command, p.name, v, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		d += time.Duration(n) * p.unit
+		set = true
+	}
+
+	if !set {
+		d = defaultSleep
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		return nil, lazyerrors.Error(context.Cause(ctx))
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commoncommands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/failpoints"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// init adds waitForFailPoint test command to Commands.
+func init() {
+	Commands["waitForFailPoint"] = command{
+		Help:    "Waits until the failpoint is entered the given number of times (test command).",
+		Handler: msgWaitForFailPoint,
+		Test:    true,
+	}
+}
+
+// msgWaitForFailPoint implements waitForFailPoint command.
+//
+// `timesEntered` is the cumulative number of times the failpoint was entered;
+// clients typically pass the `count` returned by configureFailPoint plus one.
+func msgWaitForFailPoint(_ handlers.Interface, ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	db, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if db != "admin" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	name, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]int64, 2)
+
+	for _, p := range []string{"timesEntered", "maxTimeMS"} {
+		v, _ := document.Get(p)
+		if v == nil {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrMissingField,
+				fmt.Sprintf("BSON field '%s.%s' is missing but a required field", command, p),
+				command,
+			)
+		}
+
+		if params[p], err = commonparams.GetValidatedNumberParamWithMinValue(command, p, v, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	registry := failpoints.GetRegistry(ctx)
+	if registry == nil {
+		return nil, lazyerrors.New("no failpoints registry in context")
+	}
+
+	fp := registry.Get(name)
+	if fp == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("Cannot find failpoint %s", name),
+			command,
+		)
+	}
+
+	// zero maxTimeMS means no time limit, like in MongoDB
+	if maxTimeMS := params["maxTimeMS"]; maxTimeMS > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(maxTimeMS)*time.Millisecond)
+
+		defer cancel()
+	}
+
+	if err = fp.Wait(ctx, params["timesEntered"]); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrMaxTimeMSExpired,
+				"operation exceeded time limit",
+				command,
+			)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
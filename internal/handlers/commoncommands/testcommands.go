@@ -0,0 +1,44 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commoncommands
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/util/debugbuild"
+)
+
+// contextKey is a named unexported type for the safe use of context.WithValue.
+type contextKey struct{}
+
+// Context key for WithTestCommands/TestCommandsEnabled.
+var testCommandsKey = contextKey{}
+
+// WithTestCommands returns a new context that enables or disables test commands.
+func WithTestCommands(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, testCommandsKey, enabled)
+}
+
+// TestCommandsEnabled returns true if test commands are available:
+// they are always available in debug builds, and enabled by WithTestCommands otherwise.
+func TestCommandsEnabled(ctx context.Context) bool {
+	if debugbuild.Enabled {
+		return true
+	}
+
+	enabled, _ := ctx.Value(testCommandsKey).(bool)
+
+	return enabled
+}
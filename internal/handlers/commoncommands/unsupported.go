@@ -0,0 +1,112 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commoncommands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// unsupportedCommand represents a known MongoDB command that FerretDB does not support.
+type unsupportedCommand struct {
+	code commonerrors.ErrorCode
+	msg  string
+}
+
+// Reasons for unsupported commands.
+const (
+	notReplicaSet         = "not running with --replSet"
+	notShardedCluster     = "server is not part of a sharded cluster or the sharding metadata is not yet initialized."
+	noQueryableEncryption = "Queryable Encryption is not supported by FerretDB"
+)
+
+// unsupportedCommands contains cluster-only (and other known, but unsupported) MongoDB commands.
+//
+// Unlike unknown commands that return CommandNotFound error,
+// they return errors with the same codes as MongoDB standalone instances or NotImplemented,
+// so drivers and tools could degrade gracefully.
+// The command name is used as an argument of the error, so their usage is visible in metrics and telemetry.
+var unsupportedCommands = map[string]unsupportedCommand{
+	// sorted alphabetically
+	"abortReshardCollection":          {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"addShard":                        {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"addShardToZone":                  {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"analyzeShardKey":                 {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"appendOplogNote":                 {commonerrors.ErrNoReplicationEnabled, notReplicaSet},
+	"balancerCollectionStatus":        {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"balancerStart":                   {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"balancerStatus":                  {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"balancerStop":                    {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"checkShardingIndex":              {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"cleanupOrphaned":                 {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"cleanupReshardCollection":        {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"clearJumboFlag":                  {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"commitReshardCollection":         {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"compactStructuredEncryptionData": {commonerrors.ErrNotImplemented, noQueryableEncryption},
+	"configureCollectionBalancing":    {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"enableSharding":                  {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"flushRouterConfig":               {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"getShardMap":                     {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"getShardVersion":                 {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"listShards":                      {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"mergeChunks":                     {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"moveChunk":                       {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"movePrimary":                     {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"refineCollectionShardKey":        {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"removeShard":                     {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"removeShardFromZone":             {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"replSetAbortPrimaryCatchUp":      {commonerrors.ErrNoReplicationEnabled, notReplicaSet},
+	"replSetFreeze":                   {commonerrors.ErrNoReplicationEnabled, notReplicaSet},
+	"replSetGetConfig":                {commonerrors.ErrNoReplicationEnabled, notReplicaSet},
+	"replSetGetStatus":                {commonerrors.ErrNoReplicationEnabled, notReplicaSet},
+	"replSetInitiate":                 {commonerrors.ErrNoReplicationEnabled, notReplicaSet},
+	"replSetMaintenance":              {commonerrors.ErrNoReplicationEnabled, notReplicaSet},
+	"replSetReconfig":                 {commonerrors.ErrNoReplicationEnabled, notReplicaSet},
+	"replSetResizeOplog":              {commonerrors.ErrNoReplicationEnabled, notReplicaSet},
+	"replSetStepDown":                 {commonerrors.ErrNoReplicationEnabled, notReplicaSet},
+	"replSetSyncFrom":                 {commonerrors.ErrNoReplicationEnabled, notReplicaSet},
+	"reshardCollection":               {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"shardCollection":                 {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"shardingState":                   {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"split":                           {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"splitVector":                     {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+	"updateZoneKeyRange":              {commonerrors.ErrShardingStateNotInitialized, notShardedCluster},
+}
+
+// init adds unsupported commands to Commands.
+func init() {
+	for name, uc := range unsupportedCommands {
+		if _, ok := Commands[name]; ok {
+			panic(fmt.Sprintf("command %q is both supported and unsupported", name))
+		}
+
+		// they always return an error without accessing any data
+		Commands[name] = command{
+			Handler: uc.handler(name),
+			Public:  true,
+		}
+	}
+}
+
+// handler returns a command handler that always returns an error for the unsupported command.
+func (uc unsupportedCommand) handler(name string) func(handlers.Interface, context.Context, *wire.OpMsg) (*wire.OpMsg, error) {
+	return func(handlers.Interface, context.Context, *wire.OpMsg) (*wire.OpMsg, error) {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(uc.code, uc.msg, name)
+	}
+}
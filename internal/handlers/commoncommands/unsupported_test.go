@@ -0,0 +1,52 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commoncommands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+)
+
+func TestUnsupportedCommands(t *testing.T) {
+	t.Parallel()
+
+	for name, expected := range map[string]commonerrors.ErrorCode{
+		"cleanupOrphaned":                 commonerrors.ErrShardingStateNotInitialized,
+		"compactStructuredEncryptionData": commonerrors.ErrNotImplemented,
+		"replSetGetStatus":                commonerrors.ErrNoReplicationEnabled,
+	} {
+		name, expected := name, expected
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd, ok := Commands[name]
+			require.True(t, ok)
+			assert.Empty(t, cmd.Help, "unsupported commands should not be listed")
+
+			res, err := cmd.Handler(nil, context.Background(), nil)
+			assert.Nil(t, res)
+
+			var cmdErr *commonerrors.CommandError
+			require.ErrorAs(t, err, &cmdErr)
+			assert.Equal(t, expected, cmdErr.Code())
+			assert.Equal(t, name, cmdErr.Info().Argument)
+		})
+	}
+}
@@ -26,9 +26,10 @@ import (
 type CommandError struct {
 	// the order of fields is weird to make the struct smaller due to alignment
 
-	err  error
-	info *ErrInfo
-	code ErrorCode
+	err    error
+	info   *ErrInfo
+	labels []string
+	code   ErrorCode
 }
 
 // There should not be NewCommandError function variant that accepts printf-like format specifiers.
@@ -66,6 +67,16 @@ func NewCommandErrorMsgWithArgument(code ErrorCode, msg string, argument string)
 	}
 }
 
+// NewCommandErrorMsgWithLabels is variant for NewCommandErrorMsg with error labels
+// like "RetryableWriteError" that drivers use to decide how to handle the error.
+func NewCommandErrorMsgWithLabels(code ErrorCode, msg string, labels ...string) error {
+	return &CommandError{
+		code:   code,
+		err:    errors.New(msg),
+		labels: labels,
+	}
+}
+
 // Err returns original error.
 //
 // It is not called Unwrap to prevent unwrapping by errors.Is and errors.As.
@@ -96,6 +107,15 @@ func (e *CommandError) Document() *types.Document {
 		d.Set("codeName", e.code.String())
 	}
 
+	if len(e.labels) > 0 {
+		labels := types.MakeArray(len(e.labels))
+		for _, l := range e.labels {
+			labels.Append(l)
+		}
+
+		d.Set("errorLabels", labels)
+	}
+
 	return d
 }
 
@@ -39,12 +39,21 @@ const (
 	// ErrFailedToParse indicates user input parsing failure.
 	ErrFailedToParse = ErrorCode(9) // FailedToParse
 
+	// ErrUserNotFound indicates that a user is not found.
+	ErrUserNotFound = ErrorCode(11) // UserNotFound
+
 	// ErrUnauthorized indicates that cursor is not authorized to access another namespace.
 	ErrUnauthorized = ErrorCode(13) // Unauthorized
 
 	// ErrTypeMismatch for $sort indicates that the expression in the $sort is not an object.
 	ErrTypeMismatch = ErrorCode(14) // TypeMismatch
 
+	// ErrInvalidLength indicates that the number of elements is out of the allowed range.
+	ErrInvalidLength = ErrorCode(16) // InvalidLength
+
+	// ErrProtocolError indicates a violation of the protocol, for example, an unexpected SASL message.
+	ErrProtocolError = ErrorCode(17) // ProtocolError
+
 	// ErrAuthenticationFailed indicates failed authentication.
 	ErrAuthenticationFailed = ErrorCode(18) // AuthenticationFailed
 
@@ -60,6 +69,9 @@ const (
 	// ErrUnsuitableValueType indicates that field could not be created for given value.
 	ErrUnsuitableValueType = ErrorCode(28) // PathNotViable
 
+	// ErrRoleNotFound indicates that a role does not exist.
+	ErrRoleNotFound = ErrorCode(31) // RoleNotFound
+
 	// ErrConflictingUpdateOperators indicates that $set, $inc or $setOnInsert were used together.
 	ErrConflictingUpdateOperators = ErrorCode(40) // ConflictingUpdateOperators
 
@@ -69,6 +81,9 @@ const (
 	// ErrNamespaceExists indicates that the collection already exists.
 	ErrNamespaceExists = ErrorCode(48) // NamespaceExists
 
+	// ErrMaxTimeMSExpired indicates that the operation exceeded its time limit.
+	ErrMaxTimeMSExpired = ErrorCode(50) // MaxTimeMSExpired
+
 	// ErrDollarPrefixedFieldName indicates the field name is prefixed with $.
 	ErrDollarPrefixedFieldName = ErrorCode(52) // DollarPrefixedFieldName
 
@@ -96,6 +111,9 @@ const (
 	// ErrInvalidNamespace indicates that the collection name is invalid.
 	ErrInvalidNamespace = ErrorCode(73) // InvalidNamespace
 
+	// ErrNoReplicationEnabled indicates that the server is not running as a replica set member.
+	ErrNoReplicationEnabled = ErrorCode(76) // NoReplicationEnabled
+
 	// ErrIndexOptionsConflict indicates that index build process failed due to options conflict.
 	ErrIndexOptionsConflict = ErrorCode(85) // IndexOptionsConflict
 
@@ -114,14 +132,50 @@ const (
 	// ErrInvalidPipelineOperator indicates that provided aggregation operator is invalid.
 	ErrInvalidPipelineOperator = ErrorCode(168) // InvalidPipelineOperator
 
+	// ErrViewDepthLimitExceeded indicates that views are nested too deeply or form a cycle.
+	ErrViewDepthLimitExceeded = ErrorCode(149) // ViewDepthLimitExceeded
+
+	// ErrOptionNotSupportedOnView indicates that the option is not supported for views.
+	ErrOptionNotSupportedOnView = ErrorCode(157) // OptionNotSupportedOnView
+
+	// ErrCommandNotSupportedOnView indicates that the command is not supported for views.
+	ErrCommandNotSupportedOnView = ErrorCode(166) // CommandNotSupportedOnView
+
+	// ErrCannotIndexParallelArrays indicates that the document has array values for more than one field of compound index.
+	ErrCannotIndexParallelArrays = ErrorCode(171) // CannotIndexParallelArrays
+
+	// ErrShardingStateNotInitialized indicates that the server is not a part of a sharded cluster.
+	ErrShardingStateNotInitialized = ErrorCode(203) // ShardingStateNotInitialized
+
+	// ErrTransactionTooOld indicates that a newer transaction was already started in the session.
+	ErrTransactionTooOld = ErrorCode(225) // TransactionTooOld
+
+	// ErrNoSuchTransaction indicates that the transaction does not exist or was aborted.
+	ErrNoSuchTransaction = ErrorCode(251) // NoSuchTransaction
+
+	// ErrOperationNotSupportedInTransaction indicates that the command can't run in a multi-document transaction.
+	ErrOperationNotSupportedInTransaction = ErrorCode(263) // OperationNotSupportedInTransaction
+
+	// ErrAPIStrictError indicates that the feature is not allowed with apiStrict: true.
+	ErrAPIStrictError = ErrorCode(323) // APIStrictError
+
 	// ErrNotImplemented indicates that a flag or command is not implemented.
 	ErrNotImplemented = ErrorCode(238) // NotImplemented
 
 	// ErrIndexesWrongType indicates that indexes parameter has wrong type.
 	ErrIndexesWrongType = ErrorCode(10065) // Location10065
 
+	// ErrNotWritablePrimary indicates that the server does not accept writes, for example, in maintenance mode.
+	ErrNotWritablePrimary = ErrorCode(10107) // NotWritablePrimary
+
+	// ErrUserAlreadyExists indicates that a user already exists.
+	ErrUserAlreadyExists = ErrorCode(51003) // Location51003
+
 	// ErrDuplicateKeyInsert indicates duplicate key violation on inserting document.
-	ErrDuplicateKeyInsert = ErrorCode(11000) // Location11000
+	ErrDuplicateKeyInsert = ErrorCode(11000) // DuplicateKey
+
+	// ErrInterrupted indicates that the operation was killed.
+	ErrInterrupted = ErrorCode(11601) // Interrupted
 
 	// ErrSetBadExpression indicates set expression is not object.
 	ErrSetBadExpression = ErrorCode(40272) // Location40272
@@ -129,6 +183,9 @@ const (
 	// ErrStageGroupInvalidFields indicates group's fields must be an object.
 	ErrStageGroupInvalidFields = ErrorCode(15947) // Location15947
 
+	// ErrStageFacetNotObject indicates that $facet stage specification is not an object.
+	ErrStageFacetNotObject = ErrorCode(15947) // Location15947
+
 	// ErrStageGroupID indicates _id for a group can only be specified once.
 	ErrStageGroupID = ErrorCode(15948) // Location15948
 
@@ -175,9 +232,31 @@ const (
 	// ErrFieldPathInvalidName indicates that FieldPath is invalid.
 	ErrFieldPathInvalidName = ErrorCode(16410) // Location16410
 
+	// ErrHashedIndexArray indicates that the field of a hashed index has an array value.
+	ErrHashedIndexArray = ErrorCode(16766) // Location16766
+
+	// ErrCannotExtractGeoKeys indicates that the field of a 2dsphere index has a value that is not a geometry.
+	ErrCannotExtractGeoKeys = ErrorCode(16755) // Location16755
+
 	// ErrGroupInvalidFieldPath indicates invalid path is given for group _id.
 	ErrGroupInvalidFieldPath = ErrorCode(16872) // Location16872
 
+	// ErrStageRedactInvalidResult indicates that $redact expression returned
+	// something other than $$KEEP, $$DESCEND or $$PRUNE.
+	ErrStageRedactInvalidResult = ErrorCode(17053) // Location17053
+
+	// ErrCondMissingIfParam indicates that $cond operator is missing the if parameter.
+	ErrCondMissingIfParam = ErrorCode(17080) // Location17080
+
+	// ErrCondMissingThenParam indicates that $cond operator is missing the then parameter.
+	ErrCondMissingThenParam = ErrorCode(17081) // Location17081
+
+	// ErrCondMissingElseParam indicates that $cond operator is missing the else parameter.
+	ErrCondMissingElseParam = ErrorCode(17082) // Location17082
+
+	// ErrCondUnrecognizedParam indicates that $cond operator has an unknown parameter.
+	ErrCondUnrecognizedParam = ErrorCode(17083) // Location17083
+
 	// ErrGroupUndefinedVariable indicates the variable is not defined.
 	ErrGroupUndefinedVariable = ErrorCode(17276) // Location17276
 
@@ -196,6 +275,21 @@ const (
 	// ErrStageUnsetInvalidType indicates that $unset stage arguments has unexpected type.
 	ErrStageUnsetInvalidType = ErrorCode(31002) // Location31002
 
+	// ErrStageSampleNotObject indicates that $sample stage specification is not an object.
+	ErrStageSampleNotObject = ErrorCode(28745) // Location28745
+
+	// ErrStageSampleSizeNotNumber indicates that $sample stage size is not a number.
+	ErrStageSampleSizeNotNumber = ErrorCode(28746) // Location28746
+
+	// ErrStageSampleSizeNegative indicates that $sample stage size is negative.
+	ErrStageSampleSizeNegative = ErrorCode(28747) // Location28747
+
+	// ErrStageSampleUnknownOption indicates that $sample stage specification has unknown field.
+	ErrStageSampleUnknownOption = ErrorCode(28748) // Location28748
+
+	// ErrStageSampleMissingSize indicates that $sample stage size is not specified.
+	ErrStageSampleMissingSize = ErrorCode(28749) // Location28749
+
 	// ErrStageUnwindNoPath indicates that $unwind aggregation stage is empty.
 	ErrStageUnwindNoPath = ErrorCode(28812) // Location28812
 
@@ -216,6 +310,36 @@ const (
 	// while projection document already marked as inclusion.
 	ErrProjectionExIn = ErrorCode(31254) // Location31254
 
+	// ErrElemMatchPositionalProjection indicates that $elemMatch and positional projections are used together.
+	ErrElemMatchPositionalProjection = ErrorCode(31255) // Location31255
+
+	// ErrSliceProjectionFirstArg indicates that the skip argument of $slice projection is not a number.
+	ErrSliceProjectionFirstArg = ErrorCode(31257) // Location31257
+
+	// ErrSliceProjectionSecondArg indicates that the limit argument of $slice projection is not a number.
+	ErrSliceProjectionSecondArg = ErrorCode(31258) // Location31258
+
+	// ErrSliceProjectionLimit indicates that the limit argument of $slice projection is not positive.
+	ErrSliceProjectionLimit = ErrorCode(31259) // Location31259
+
+	// ErrJavaScriptDisabled indicates that server-side JavaScript can't be executed.
+	ErrJavaScriptDisabled = ErrorCode(31264) // Location31264
+
+	// ErrSliceProjectionArrayLen indicates that $slice projection array argument is not [skip, limit].
+	ErrSliceProjectionArrayLen = ErrorCode(31272) // Location31272
+
+	// ErrSliceProjectionArgType indicates that $slice projection argument is neither a number nor an array.
+	ErrSliceProjectionArgType = ErrorCode(31273) // Location31273
+
+	// ErrElemMatchProjectionObject indicates that $elemMatch projection argument is not a document.
+	ErrElemMatchProjectionObject = ErrorCode(31274) // Location31274
+
+	// ErrElemMatchProjectionNested indicates that $elemMatch projection is used on a nested field.
+	ErrElemMatchProjectionNested = ErrorCode(31275) // Location31275
+
+	// ErrMultiplePositionalProjection indicates that there is more than one positional projection.
+	ErrMultiplePositionalProjection = ErrorCode(31276) // Location31276
+
 	// ErrAggregatePositionalProject indicates that positional projection cannot be used in aggregation.
 	ErrAggregatePositionalProject = ErrorCode(31324) // Location31324
 
@@ -241,10 +365,26 @@ const (
 	// ErrStageCountBadValue indicates that $count stage contains invalid value.
 	ErrStageCountBadValue = ErrorCode(40160) // Location40160
 
+	// ErrStageFacetEmpty indicates that $facet stage specification is empty.
+	ErrStageFacetEmpty = ErrorCode(40169) // Location40169
+
+	// ErrStageFacetNotArray indicates that $facet stage sub-pipeline is not an array.
+	ErrStageFacetNotArray = ErrorCode(40170) // Location40170
+
+	// ErrStageFacetEmptyPipeline indicates that $facet stage sub-pipeline is empty.
+	ErrStageFacetEmptyPipeline = ErrorCode(40171) // Location40171
+
 	// ErrAddFieldsExpressionWrongAmountOfArgs indicates that $addFields stage expression contain invalid
 	// amount of arguments.
 	ErrAddFieldsExpressionWrongAmountOfArgs = ErrorCode(40181) // Location40181
 
+	// ErrStageReplaceRootNotObject indicates that $replaceRoot or $replaceWith
+	// expression does not evaluate to a document.
+	ErrStageReplaceRootNotObject = ErrorCode(40228) // Location40228
+
+	// ErrStageReplaceRootNoNewRoot indicates that $replaceRoot stage has no newRoot field.
+	ErrStageReplaceRootNoNewRoot = ErrorCode(40231) // Location40231
+
 	// ErrStageGroupUnaryOperator indicates that $sum is a unary operator.
 	ErrStageGroupUnaryOperator = ErrorCode(40237) // Location40237
 
@@ -269,9 +409,109 @@ const (
 	// ErrFailedToParseInput indicates invalid input (absent or malformed fields).
 	ErrFailedToParseInput = ErrorCode(40415) // Location40415
 
+	// ErrStageFacetNotAllowed indicates that the stage is not allowed within $facet stage.
+	ErrStageFacetNotAllowed = ErrorCode(40600) // Location40600
+
+	// ErrStageOutputNotLast indicates that $out or $merge stage is not the last stage in the pipeline.
+	ErrStageOutputNotLast = ErrorCode(40601) // Location40601
+
+	// ErrStageOutInvalidArg indicates that $out stage argument is neither a string nor an object.
+	ErrStageOutInvalidArg = ErrorCode(16990) // Location16990
+
+	// ErrStageOutCapped indicates that $out stage target collection is capped.
+	ErrStageOutCapped = ErrorCode(17152) // Location17152
+
+	// ErrStageSortByCountInvalidObject indicates that $sortByCount stage object argument is not an expression.
+	ErrStageSortByCountInvalidObject = ErrorCode(40147) // Location40147
+
+	// ErrStageSortByCountInvalidPath indicates that $sortByCount stage argument is not a $-prefixed path.
+	ErrStageSortByCountInvalidPath = ErrorCode(40148) // Location40148
+
+	// ErrStageSortByCountInvalidType indicates that $sortByCount stage argument is neither a string nor an object.
+	ErrStageSortByCountInvalidType = ErrorCode(40149) // Location40149
+
+	// ErrStageBucketNotConstantBoundaries indicates that $bucket stage boundaries are not constant values.
+	ErrStageBucketNotConstantBoundaries = ErrorCode(40191) // Location40191
+
+	// ErrStageBucketFewBoundaries indicates that $bucket stage has less than two boundaries.
+	ErrStageBucketFewBoundaries = ErrorCode(40192) // Location40192
+
+	// ErrStageBucketMixedBoundaries indicates that $bucket stage boundaries have different types.
+	ErrStageBucketMixedBoundaries = ErrorCode(40193) // Location40193
+
+	// ErrStageBucketUnsortedBoundaries indicates that $bucket stage boundaries are not sorted.
+	ErrStageBucketUnsortedBoundaries = ErrorCode(40194) // Location40194
+
+	// ErrStageBucketInvalidOutput indicates that $bucket stage output is not an object.
+	ErrStageBucketInvalidOutput = ErrorCode(40196) // Location40196
+
+	// ErrStageBucketUnknownField indicates that $bucket stage has an unknown field.
+	ErrStageBucketUnknownField = ErrorCode(40197) // Location40197
+
+	// ErrStageBucketMissingField indicates that $bucket stage is missing groupBy or boundaries.
+	ErrStageBucketMissingField = ErrorCode(40198) // Location40198
+
+	// ErrStageBucketInvalidDefault indicates that $bucket stage default value is within boundaries.
+	ErrStageBucketInvalidDefault = ErrorCode(40199) // Location40199
+
+	// ErrStageBucketInvalidBoundaries indicates that $bucket stage boundaries are not an array.
+	ErrStageBucketInvalidBoundaries = ErrorCode(40200) // Location40200
+
+	// ErrStageBucketNotObject indicates that $bucket stage argument is not an object.
+	ErrStageBucketNotObject = ErrorCode(40201) // Location40201
+
+	// ErrStageBucketInvalidGroupBy indicates that $bucket stage groupBy is not a path or an expression.
+	ErrStageBucketInvalidGroupBy = ErrorCode(40202) // Location40202
+
+	// ErrStageBucketNoMatchingBranch indicates that a value does not fall into any $bucket stage bucket
+	// and no default was specified.
+	ErrStageBucketNoMatchingBranch = ErrorCode(40066) // Location40066
+
+	// ErrStageBucketAutoInvalidGroupBy indicates that $bucketAuto stage groupBy is not a path or an expression.
+	ErrStageBucketAutoInvalidGroupBy = ErrorCode(40239) // Location40239
+
+	// ErrStageBucketAutoNotObject indicates that $bucketAuto stage argument is not an object.
+	ErrStageBucketAutoNotObject = ErrorCode(40240) // Location40240
+
+	// ErrStageBucketAutoInvalidBuckets indicates that $bucketAuto stage buckets is not a number.
+	ErrStageBucketAutoInvalidBuckets = ErrorCode(40241) // Location40241
+
+	// ErrStageBucketAutoNonIntegerBuckets indicates that $bucketAuto stage buckets is not a 32-bit integer.
+	ErrStageBucketAutoNonIntegerBuckets = ErrorCode(40242) // Location40242
+
+	// ErrStageBucketAutoNonPositiveBuckets indicates that $bucketAuto stage buckets is not positive.
+	ErrStageBucketAutoNonPositiveBuckets = ErrorCode(40243) // Location40243
+
+	// ErrStageBucketAutoInvalidOutput indicates that $bucketAuto stage output is not an object.
+	ErrStageBucketAutoInvalidOutput = ErrorCode(40244) // Location40244
+
+	// ErrStageBucketAutoUnknownField indicates that $bucketAuto stage has an unknown field.
+	ErrStageBucketAutoUnknownField = ErrorCode(40245) // Location40245
+
+	// ErrStageBucketAutoMissingField indicates that $bucketAuto stage is missing groupBy or buckets.
+	ErrStageBucketAutoMissingField = ErrorCode(40246) // Location40246
+
+	// ErrStageSetWindowFieldsRankSortBy indicates that rank window function of $setWindowFields stage
+	// is used without sortBy with exactly one field.
+	ErrStageSetWindowFieldsRankSortBy = ErrorCode(5371602) // Location5371602
+
+	// ErrStageMergeInvalidArg indicates that $merge stage argument is neither a string nor an object.
+	ErrStageMergeInvalidArg = ErrorCode(51182) // Location51182
+
+	// ErrMergeStageNoMatchingDocument indicates that $merge stage with whenNotMatched: fail
+	// found no matching document in the target collection.
+	ErrMergeStageNoMatchingDocument = ErrorCode(13113) // MergeStageNoMatchingDocument
+
 	// ErrCollStatsIsNotFirstStage indicates that $collStats must be the first stage in the pipeline.
 	ErrCollStatsIsNotFirstStage = ErrorCode(40415) // Location40602
 
+	// ErrGeoNearNotFirstStage indicates that $geoNear must be the first stage in the pipeline.
+	ErrGeoNearNotFirstStage = ErrorCode(40603) // Location40603
+
+	// ErrTextScoreNotAvailable indicates that text score metadata is requested
+	// by the query without $text operator.
+	ErrTextScoreNotAvailable = ErrorCode(40218) // Location40218
+
 	// ErrFreeMonitoringDisabled indicates that free monitoring is disabled
 	// by command-line or config file.
 	ErrFreeMonitoringDisabled = ErrorCode(50840) // Location50840
@@ -288,6 +528,15 @@ const (
 	// ErrBadRegexOption indicates bad regex option value passed.
 	ErrBadRegexOption = ErrorCode(51108) // Location51108
 
+	// ErrMinMaxNoHint indicates that min or max find option is used without hint.
+	ErrMinMaxNoHint = ErrorCode(51173) // Location51173
+
+	// ErrMinMaxInvalidIndex indicates that hinted index does not match min or max find option.
+	ErrMinMaxInvalidIndex = ErrorCode(51174) // Location51174
+
+	// ErrMinMaxFieldsMismatch indicates that min and max find options have different field names.
+	ErrMinMaxFieldsMismatch = ErrorCode(51176) // Location51176
+
 	// ErrBadPositionalProjection indicates that positional operator could not find a matching element in the array.
 	ErrBadPositionalProjection = ErrorCode(51246) // Location51246
 
@@ -311,6 +560,9 @@ const (
 
 	// ErrStageCollStatsInvalidArg indicates invalid argument for the aggregation $collStats stage.
 	ErrStageCollStatsInvalidArg = ErrorCode(5447000) // Location5447000
+
+	// ErrStageDensifyTooManyDocuments indicates that $densify stage generated too many documents.
+	ErrStageDensifyTooManyDocuments = ErrorCode(5897900) // Location5897900
 )
 
 // ErrInfo represents additional optional error information.
@@ -12,16 +12,21 @@ func _() {
 	_ = x[errInternalError-1]
 	_ = x[ErrBadValue-2]
 	_ = x[ErrFailedToParse-9]
+	_ = x[ErrUserNotFound-11]
 	_ = x[ErrUnauthorized-13]
 	_ = x[ErrTypeMismatch-14]
+	_ = x[ErrInvalidLength-16]
+	_ = x[ErrProtocolError-17]
 	_ = x[ErrAuthenticationFailed-18]
 	_ = x[ErrIllegalOperation-20]
 	_ = x[ErrNamespaceNotFound-26]
 	_ = x[ErrIndexNotFound-27]
 	_ = x[ErrUnsuitableValueType-28]
+	_ = x[ErrRoleNotFound-31]
 	_ = x[ErrConflictingUpdateOperators-40]
 	_ = x[ErrCursorNotFound-43]
 	_ = x[ErrNamespaceExists-48]
+	_ = x[ErrMaxTimeMSExpired-50]
 	_ = x[ErrDollarPrefixedFieldName-52]
 	_ = x[ErrInvalidID-53]
 	_ = x[ErrEmptyName-56]
@@ -31,17 +36,31 @@ func _() {
 	_ = x[ErrIndexAlreadyExists-68]
 	_ = x[ErrInvalidOptions-72]
 	_ = x[ErrInvalidNamespace-73]
+	_ = x[ErrNoReplicationEnabled-76]
 	_ = x[ErrIndexOptionsConflict-85]
 	_ = x[ErrIndexKeySpecsConflict-86]
 	_ = x[ErrOperationFailed-96]
 	_ = x[ErrDocumentValidationFailure-121]
 	_ = x[ErrInvalidIndexSpecificationOption-197]
 	_ = x[ErrInvalidPipelineOperator-168]
+	_ = x[ErrViewDepthLimitExceeded-149]
+	_ = x[ErrOptionNotSupportedOnView-157]
+	_ = x[ErrCommandNotSupportedOnView-166]
+	_ = x[ErrCannotIndexParallelArrays-171]
+	_ = x[ErrShardingStateNotInitialized-203]
+	_ = x[ErrTransactionTooOld-225]
+	_ = x[ErrNoSuchTransaction-251]
+	_ = x[ErrOperationNotSupportedInTransaction-263]
+	_ = x[ErrAPIStrictError-323]
 	_ = x[ErrNotImplemented-238]
 	_ = x[ErrIndexesWrongType-10065]
+	_ = x[ErrNotWritablePrimary-10107]
+	_ = x[ErrUserAlreadyExists-51003]
 	_ = x[ErrDuplicateKeyInsert-11000]
+	_ = x[ErrInterrupted-11601]
 	_ = x[ErrSetBadExpression-40272]
 	_ = x[ErrStageGroupInvalidFields-15947]
+	_ = x[ErrStageFacetNotObject-15947]
 	_ = x[ErrStageGroupID-15948]
 	_ = x[ErrStageGroupMissingID-15955]
 	_ = x[ErrStageLimitZero-15958]
@@ -57,19 +76,41 @@ func _() {
 	_ = x[ErrPathContainsEmptyElement-15998]
 	_ = x[ErrOperatorWrongLenOfArgs-16020]
 	_ = x[ErrFieldPathInvalidName-16410]
+	_ = x[ErrHashedIndexArray-16766]
+	_ = x[ErrCannotExtractGeoKeys-16755]
 	_ = x[ErrGroupInvalidFieldPath-16872]
+	_ = x[ErrStageRedactInvalidResult-17053]
+	_ = x[ErrCondMissingIfParam-17080]
+	_ = x[ErrCondMissingThenParam-17081]
+	_ = x[ErrCondMissingElseParam-17082]
+	_ = x[ErrCondUnrecognizedParam-17083]
 	_ = x[ErrGroupUndefinedVariable-17276]
 	_ = x[ErrInvalidArg-28667]
 	_ = x[ErrSliceFirstArg-28724]
 	_ = x[ErrStageUnsetNoPath-31119]
 	_ = x[ErrStageUnsetArrElementInvalidType-31120]
 	_ = x[ErrStageUnsetInvalidType-31002]
+	_ = x[ErrStageSampleNotObject-28745]
+	_ = x[ErrStageSampleSizeNotNumber-28746]
+	_ = x[ErrStageSampleSizeNegative-28747]
+	_ = x[ErrStageSampleUnknownOption-28748]
+	_ = x[ErrStageSampleMissingSize-28749]
 	_ = x[ErrStageUnwindNoPath-28812]
 	_ = x[ErrStageUnwindNoPrefix-28818]
 	_ = x[ErrUnsetPathCollision-31249]
 	_ = x[ErrUnsetPathOverwrite-31250]
 	_ = x[ErrProjectionInEx-31253]
 	_ = x[ErrProjectionExIn-31254]
+	_ = x[ErrElemMatchPositionalProjection-31255]
+	_ = x[ErrSliceProjectionFirstArg-31257]
+	_ = x[ErrSliceProjectionSecondArg-31258]
+	_ = x[ErrSliceProjectionLimit-31259]
+	_ = x[ErrJavaScriptDisabled-31264]
+	_ = x[ErrSliceProjectionArrayLen-31272]
+	_ = x[ErrSliceProjectionArgType-31273]
+	_ = x[ErrElemMatchProjectionObject-31274]
+	_ = x[ErrElemMatchProjectionNested-31275]
+	_ = x[ErrMultiplePositionalProjection-31276]
 	_ = x[ErrAggregatePositionalProject-31324]
 	_ = x[ErrAggregateInvalidExpression-31325]
 	_ = x[ErrWrongPositionalOperatorLocation-31394]
@@ -78,7 +119,12 @@ func _() {
 	_ = x[ErrStageCountNonEmptyString-40157]
 	_ = x[ErrStageCountBadPrefix-40158]
 	_ = x[ErrStageCountBadValue-40160]
+	_ = x[ErrStageFacetEmpty-40169]
+	_ = x[ErrStageFacetNotArray-40170]
+	_ = x[ErrStageFacetEmptyPipeline-40171]
 	_ = x[ErrAddFieldsExpressionWrongAmountOfArgs-40181]
+	_ = x[ErrStageReplaceRootNotObject-40228]
+	_ = x[ErrStageReplaceRootNoNewRoot-40231]
 	_ = x[ErrStageGroupUnaryOperator-40237]
 	_ = x[ErrStageGroupMultipleAccumulator-40238]
 	_ = x[ErrStageGroupInvalidAccumulator-40234]
@@ -87,12 +133,47 @@ func _() {
 	_ = x[ErrInvalidFieldPath-40353]
 	_ = x[ErrMissingField-40414]
 	_ = x[ErrFailedToParseInput-40415]
+	_ = x[ErrStageFacetNotAllowed-40600]
+	_ = x[ErrStageOutputNotLast-40601]
+	_ = x[ErrStageOutInvalidArg-16990]
+	_ = x[ErrStageOutCapped-17152]
+	_ = x[ErrStageSortByCountInvalidObject-40147]
+	_ = x[ErrStageSortByCountInvalidPath-40148]
+	_ = x[ErrStageSortByCountInvalidType-40149]
+	_ = x[ErrStageBucketNotConstantBoundaries-40191]
+	_ = x[ErrStageBucketFewBoundaries-40192]
+	_ = x[ErrStageBucketMixedBoundaries-40193]
+	_ = x[ErrStageBucketUnsortedBoundaries-40194]
+	_ = x[ErrStageBucketInvalidOutput-40196]
+	_ = x[ErrStageBucketUnknownField-40197]
+	_ = x[ErrStageBucketMissingField-40198]
+	_ = x[ErrStageBucketInvalidDefault-40199]
+	_ = x[ErrStageBucketInvalidBoundaries-40200]
+	_ = x[ErrStageBucketNotObject-40201]
+	_ = x[ErrStageBucketInvalidGroupBy-40202]
+	_ = x[ErrStageBucketNoMatchingBranch-40066]
+	_ = x[ErrStageBucketAutoInvalidGroupBy-40239]
+	_ = x[ErrStageBucketAutoNotObject-40240]
+	_ = x[ErrStageBucketAutoInvalidBuckets-40241]
+	_ = x[ErrStageBucketAutoNonIntegerBuckets-40242]
+	_ = x[ErrStageBucketAutoNonPositiveBuckets-40243]
+	_ = x[ErrStageBucketAutoInvalidOutput-40244]
+	_ = x[ErrStageBucketAutoUnknownField-40245]
+	_ = x[ErrStageBucketAutoMissingField-40246]
+	_ = x[ErrStageSetWindowFieldsRankSortBy-5371602]
+	_ = x[ErrStageMergeInvalidArg-51182]
+	_ = x[ErrMergeStageNoMatchingDocument-13113]
 	_ = x[ErrCollStatsIsNotFirstStage-40415]
+	_ = x[ErrGeoNearNotFirstStage-40603]
+	_ = x[ErrTextScoreNotAvailable-40218]
 	_ = x[ErrFreeMonitoringDisabled-50840]
 	_ = x[ErrValueNegative-51024]
 	_ = x[ErrRegexOptions-51075]
 	_ = x[ErrRegexMissingParen-51091]
 	_ = x[ErrBadRegexOption-51108]
+	_ = x[ErrMinMaxNoHint-51173]
+	_ = x[ErrMinMaxInvalidIndex-51174]
+	_ = x[ErrMinMaxFieldsMismatch-51176]
 	_ = x[ErrBadPositionalProjection-51246]
 	_ = x[ErrElementMismatchPositionalProjection-51247]
 	_ = x[ErrEmptySubProject-51270]
@@ -101,103 +182,185 @@ func _() {
 	_ = x[ErrStageSkipBadValue-5107200]
 	_ = x[ErrStageLimitInvalidArg-5107201]
 	_ = x[ErrStageCollStatsInvalidArg-5447000]
+	_ = x[ErrStageDensifyTooManyDocuments-5897900]
 }
 
-const _ErrorCode_name = "UnsetInternalErrorBadValueFailedToParseUnauthorizedTypeMismatchAuthenticationFailedIllegalOperationNamespaceNotFoundIndexNotFoundPathNotViableConflictingUpdateOperatorsCursorNotFoundNamespaceExistsDollarPrefixedFieldNameInvalidIDEmptyFieldNameCommandNotFoundImmutableFieldCannotCreateIndexIndexAlreadyExistsInvalidOptionsInvalidNamespaceIndexOptionsConflictIndexKeySpecsConflictOperationFailedDocumentValidationFailureInvalidPipelineOperatorInvalidIndexSpecificationOptionNotImplementedLocation10065Location11000Location15947Location15948Location15955Location15958Location15959Location15969Location15973Location15974Location15975Location15976Location15981Location15983Location15998Location16020Location16406Location16410Location16872Location17276Location28667Location28724Location28812Location28818Location31002Location31119Location31120Location31249Location31250Location31253Location31254Location31324Location31325Location31394Location31395Location40156Location40157Location40158Location40160Location40181Location40234Location40237Location40238Location40272Location40323Location40352Location40353Location40414Location40415Location50840Location51024Location51075Location51091Location51108Location51246Location51247Location51270Location51272Location4822819Location5107200Location5107201Location5447000"
+const _ErrorCode_name = "UnsetInternalErrorBadValueFailedToParseUserNotFoundUnauthorizedTypeMismatchInvalidLengthProtocolErrorAuthenticationFailedIllegalOperationNamespaceNotFoundIndexNotFoundPathNotViableRoleNotFoundConflictingUpdateOperatorsCursorNotFoundNamespaceExistsMaxTimeMSExpiredDollarPrefixedFieldNameInvalidIDEmptyFieldNameCommandNotFoundImmutableFieldCannotCreateIndexIndexAlreadyExistsInvalidOptionsInvalidNamespaceNoReplicationEnabledIndexOptionsConflictIndexKeySpecsConflictOperationFailedDocumentValidationFailureViewDepthLimitExceededOptionNotSupportedOnViewCommandNotSupportedOnViewInvalidPipelineOperatorCannotIndexParallelArraysInvalidIndexSpecificationOptionShardingStateNotInitializedTransactionTooOldNotImplementedNoSuchTransactionOperationNotSupportedInTransactionAPIStrictErrorLocation10065NotWritablePrimaryDuplicateKeyInterruptedMergeStageNoMatchingDocumentLocation15947Location15948Location15955Location15958Location15959Location15969Location15973Location15974Location15975Location15976Location15981Location15983Location15998Location16020Location16406Location16410Location16755Location16766Location16872Location16990Location17053Location17080Location17081Location17082Location17083Location17152Location17276Location28667Location28724Location28745Location28746Location28747Location28748Location28749Location28812Location28818Location31002Location31119Location31120Location31249Location31250Location31253Location31254Location31255Location31257Location31258Location31259Location31264Location31272Location31273Location31274Location31275Location31276Location31324Location31325Location31394Location31395Location40066Location40147Location40148Location40149Location40156Location40157Location40158Location40160Location40169Location40170Location40171Location40181Location40191Location40192Location40193Location40194Location40196Location40197Location40198Location40199Location40200Location40201Location40202Location40218Location40228Location40231Location40234Location40237Location40238Location40239Location40240Location40241Location40242Location40243Location40244Location40245Location40246Location40272Location40323Location40352Location40353Location40414Location40415Location40600Location40601Location40603Location50840Location51003Location51024Location51075Location51091Location51108Location51173Location51174Location51176Location51182Location51246Location51247Location51270Location51272Location4822819Location5107200Location5107201Location5371602Location5447000Location5897900"
 
 var _ErrorCode_map = map[ErrorCode]string{
 	0:       _ErrorCode_name[0:5],
 	1:       _ErrorCode_name[5:18],
 	2:       _ErrorCode_name[18:26],
 	9:       _ErrorCode_name[26:39],
-	13:      _ErrorCode_name[39:51],
-	14:      _ErrorCode_name[51:63],
-	18:      _ErrorCode_name[63:83],
-	20:      _ErrorCode_name[83:99],
-	26:      _ErrorCode_name[99:116],
-	27:      _ErrorCode_name[116:129],
-	28:      _ErrorCode_name[129:142],
-	40:      _ErrorCode_name[142:168],
-	43:      _ErrorCode_name[168:182],
-	48:      _ErrorCode_name[182:197],
-	52:      _ErrorCode_name[197:220],
-	53:      _ErrorCode_name[220:229],
-	56:      _ErrorCode_name[229:243],
-	59:      _ErrorCode_name[243:258],
-	66:      _ErrorCode_name[258:272],
-	67:      _ErrorCode_name[272:289],
-	68:      _ErrorCode_name[289:307],
-	72:      _ErrorCode_name[307:321],
-	73:      _ErrorCode_name[321:337],
-	85:      _ErrorCode_name[337:357],
-	86:      _ErrorCode_name[357:378],
-	96:      _ErrorCode_name[378:393],
-	121:     _ErrorCode_name[393:418],
-	168:     _ErrorCode_name[418:441],
-	197:     _ErrorCode_name[441:472],
-	238:     _ErrorCode_name[472:486],
-	10065:   _ErrorCode_name[486:499],
-	11000:   _ErrorCode_name[499:512],
-	15947:   _ErrorCode_name[512:525],
-	15948:   _ErrorCode_name[525:538],
-	15955:   _ErrorCode_name[538:551],
-	15958:   _ErrorCode_name[551:564],
-	15959:   _ErrorCode_name[564:577],
-	15969:   _ErrorCode_name[577:590],
-	15973:   _ErrorCode_name[590:603],
-	15974:   _ErrorCode_name[603:616],
-	15975:   _ErrorCode_name[616:629],
-	15976:   _ErrorCode_name[629:642],
-	15981:   _ErrorCode_name[642:655],
-	15983:   _ErrorCode_name[655:668],
-	15998:   _ErrorCode_name[668:681],
-	16020:   _ErrorCode_name[681:694],
-	16406:   _ErrorCode_name[694:707],
-	16410:   _ErrorCode_name[707:720],
-	16872:   _ErrorCode_name[720:733],
-	17276:   _ErrorCode_name[733:746],
-	28667:   _ErrorCode_name[746:759],
-	28724:   _ErrorCode_name[759:772],
-	28812:   _ErrorCode_name[772:785],
-	28818:   _ErrorCode_name[785:798],
-	31002:   _ErrorCode_name[798:811],
-	31119:   _ErrorCode_name[811:824],
-	31120:   _ErrorCode_name[824:837],
-	31249:   _ErrorCode_name[837:850],
-	31250:   _ErrorCode_name[850:863],
-	31253:   _ErrorCode_name[863:876],
-	31254:   _ErrorCode_name[876:889],
-	31324:   _ErrorCode_name[889:902],
-	31325:   _ErrorCode_name[902:915],
-	31394:   _ErrorCode_name[915:928],
-	31395:   _ErrorCode_name[928:941],
-	40156:   _ErrorCode_name[941:954],
-	40157:   _ErrorCode_name[954:967],
-	40158:   _ErrorCode_name[967:980],
-	40160:   _ErrorCode_name[980:993],
-	40181:   _ErrorCode_name[993:1006],
-	40234:   _ErrorCode_name[1006:1019],
-	40237:   _ErrorCode_name[1019:1032],
-	40238:   _ErrorCode_name[1032:1045],
-	40272:   _ErrorCode_name[1045:1058],
-	40323:   _ErrorCode_name[1058:1071],
-	40352:   _ErrorCode_name[1071:1084],
-	40353:   _ErrorCode_name[1084:1097],
-	40414:   _ErrorCode_name[1097:1110],
-	40415:   _ErrorCode_name[1110:1123],
-	50840:   _ErrorCode_name[1123:1136],
-	51024:   _ErrorCode_name[1136:1149],
-	51075:   _ErrorCode_name[1149:1162],
-	51091:   _ErrorCode_name[1162:1175],
-	51108:   _ErrorCode_name[1175:1188],
-	51246:   _ErrorCode_name[1188:1201],
-	51247:   _ErrorCode_name[1201:1214],
-	51270:   _ErrorCode_name[1214:1227],
-	51272:   _ErrorCode_name[1227:1240],
-	4822819: _ErrorCode_name[1240:1255],
-	5107200: _ErrorCode_name[1255:1270],
-	5107201: _ErrorCode_name[1270:1285],
-	5447000: _ErrorCode_name[1285:1300],
+	11:      _ErrorCode_name[39:51],
+	13:      _ErrorCode_name[51:63],
+	14:      _ErrorCode_name[63:75],
+	16:      _ErrorCode_name[75:88],
+	17:      _ErrorCode_name[88:101],
+	18:      _ErrorCode_name[101:121],
+	20:      _ErrorCode_name[121:137],
+	26:      _ErrorCode_name[137:154],
+	27:      _ErrorCode_name[154:167],
+	28:      _ErrorCode_name[167:180],
+	31:      _ErrorCode_name[180:192],
+	40:      _ErrorCode_name[192:218],
+	43:      _ErrorCode_name[218:232],
+	48:      _ErrorCode_name[232:247],
+	50:      _ErrorCode_name[247:263],
+	52:      _ErrorCode_name[263:286],
+	53:      _ErrorCode_name[286:295],
+	56:      _ErrorCode_name[295:309],
+	59:      _ErrorCode_name[309:324],
+	66:      _ErrorCode_name[324:338],
+	67:      _ErrorCode_name[338:355],
+	68:      _ErrorCode_name[355:373],
+	72:      _ErrorCode_name[373:387],
+	73:      _ErrorCode_name[387:403],
+	76:      _ErrorCode_name[403:423],
+	85:      _ErrorCode_name[423:443],
+	86:      _ErrorCode_name[443:464],
+	96:      _ErrorCode_name[464:479],
+	121:     _ErrorCode_name[479:504],
+	149:     _ErrorCode_name[504:526],
+	157:     _ErrorCode_name[526:550],
+	166:     _ErrorCode_name[550:575],
+	168:     _ErrorCode_name[575:598],
+	171:     _ErrorCode_name[598:623],
+	197:     _ErrorCode_name[623:654],
+	203:     _ErrorCode_name[654:681],
+	225:     _ErrorCode_name[681:698],
+	238:     _ErrorCode_name[698:712],
+	251:     _ErrorCode_name[712:729],
+	263:     _ErrorCode_name[729:763],
+	323:     _ErrorCode_name[763:777],
+	10065:   _ErrorCode_name[777:790],
+	10107:   _ErrorCode_name[790:808],
+	11000:   _ErrorCode_name[808:820],
+	11601:   _ErrorCode_name[820:831],
+	13113:   _ErrorCode_name[831:859],
+	15947:   _ErrorCode_name[859:872],
+	15948:   _ErrorCode_name[872:885],
+	15955:   _ErrorCode_name[885:898],
+	15958:   _ErrorCode_name[898:911],
+	15959:   _ErrorCode_name[911:924],
+	15969:   _ErrorCode_name[924:937],
+	15973:   _ErrorCode_name[937:950],
+	15974:   _ErrorCode_name[950:963],
+	15975:   _ErrorCode_name[963:976],
+	15976:   _ErrorCode_name[976:989],
+	15981:   _ErrorCode_name[989:1002],
+	15983:   _ErrorCode_name[1002:1015],
+	15998:   _ErrorCode_name[1015:1028],
+	16020:   _ErrorCode_name[1028:1041],
+	16406:   _ErrorCode_name[1041:1054],
+	16410:   _ErrorCode_name[1054:1067],
+	16755:   _ErrorCode_name[1067:1080],
+	16766:   _ErrorCode_name[1080:1093],
+	16872:   _ErrorCode_name[1093:1106],
+	16990:   _ErrorCode_name[1106:1119],
+	17053:   _ErrorCode_name[1119:1132],
+	17080:   _ErrorCode_name[1132:1145],
+	17081:   _ErrorCode_name[1145:1158],
+	17082:   _ErrorCode_name[1158:1171],
+	17083:   _ErrorCode_name[1171:1184],
+	17152:   _ErrorCode_name[1184:1197],
+	17276:   _ErrorCode_name[1197:1210],
+	28667:   _ErrorCode_name[1210:1223],
+	28724:   _ErrorCode_name[1223:1236],
+	28745:   _ErrorCode_name[1236:1249],
+	28746:   _ErrorCode_name[1249:1262],
+	28747:   _ErrorCode_name[1262:1275],
+	28748:   _ErrorCode_name[1275:1288],
+	28749:   _ErrorCode_name[1288:1301],
+	28812:   _ErrorCode_name[1301:1314],
+	28818:   _ErrorCode_name[1314:1327],
+	31002:   _ErrorCode_name[1327:1340],
+	31119:   _ErrorCode_name[1340:1353],
+	31120:   _ErrorCode_name[1353:1366],
+	31249:   _ErrorCode_name[1366:1379],
+	31250:   _ErrorCode_name[1379:1392],
+	31253:   _ErrorCode_name[1392:1405],
+	31254:   _ErrorCode_name[1405:1418],
+	31255:   _ErrorCode_name[1418:1431],
+	31257:   _ErrorCode_name[1431:1444],
+	31258:   _ErrorCode_name[1444:1457],
+	31259:   _ErrorCode_name[1457:1470],
+	31264:   _ErrorCode_name[1470:1483],
+	31272:   _ErrorCode_name[1483:1496],
+	31273:   _ErrorCode_name[1496:1509],
+	31274:   _ErrorCode_name[1509:1522],
+	31275:   _ErrorCode_name[1522:1535],
+	31276:   _ErrorCode_name[1535:1548],
+	31324:   _ErrorCode_name[1548:1561],
+	31325:   _ErrorCode_name[1561:1574],
+	31394:   _ErrorCode_name[1574:1587],
+	31395:   _ErrorCode_name[1587:1600],
+	40066:   _ErrorCode_name[1600:1613],
+	40147:   _ErrorCode_name[1613:1626],
+	40148:   _ErrorCode_name[1626:1639],
+	40149:   _ErrorCode_name[1639:1652],
+	40156:   _ErrorCode_name[1652:1665],
+	40157:   _ErrorCode_name[1665:1678],
+	40158:   _ErrorCode_name[1678:1691],
+	40160:   _ErrorCode_name[1691:1704],
+	40169:   _ErrorCode_name[1704:1717],
+	40170:   _ErrorCode_name[1717:1730],
+	40171:   _ErrorCode_name[1730:1743],
+	40181:   _ErrorCode_name[1743:1756],
+	40191:   _ErrorCode_name[1756:1769],
+	40192:   _ErrorCode_name[1769:1782],
+	40193:   _ErrorCode_name[1782:1795],
+	40194:   _ErrorCode_name[1795:1808],
+	40196:   _ErrorCode_name[1808:1821],
+	40197:   _ErrorCode_name[1821:1834],
+	40198:   _ErrorCode_name[1834:1847],
+	40199:   _ErrorCode_name[1847:1860],
+	40200:   _ErrorCode_name[1860:1873],
+	40201:   _ErrorCode_name[1873:1886],
+	40202:   _ErrorCode_name[1886:1899],
+	40218:   _ErrorCode_name[1899:1912],
+	40228:   _ErrorCode_name[1912:1925],
+	40231:   _ErrorCode_name[1925:1938],
+	40234:   _ErrorCode_name[1938:1951],
+	40237:   _ErrorCode_name[1951:1964],
+	40238:   _ErrorCode_name[1964:1977],
+	40239:   _ErrorCode_name[1977:1990],
+	40240:   _ErrorCode_name[1990:2003],
+	40241:   _ErrorCode_name[2003:2016],
+	40242:   _ErrorCode_name[2016:2029],
+	40243:   _ErrorCode_name[2029:2042],
+	40244:   _ErrorCode_name[2042:2055],
+	40245:   _ErrorCode_name[2055:2068],
+	40246:   _ErrorCode_name[2068:2081],
+	40272:   _ErrorCode_name[2081:2094],
+	40323:   _ErrorCode_name[2094:2107],
+	40352:   _ErrorCode_name[2107:2120],
+	40353:   _ErrorCode_name[2120:2133],
+	40414:   _ErrorCode_name[2133:2146],
+	40415:   _ErrorCode_name[2146:2159],
+	40600:   _ErrorCode_name[2159:2172],
+	40601:   _ErrorCode_name[2172:2185],
+	40603:   _ErrorCode_name[2185:2198],
+	50840:   _ErrorCode_name[2198:2211],
+	51003:   _ErrorCode_name[2211:2224],
+	51024:   _ErrorCode_name[2224:2237],
+	51075:   _ErrorCode_name[2237:2250],
+	51091:   _ErrorCode_name[2250:2263],
+	51108:   _ErrorCode_name[2263:2276],
+	51173:   _ErrorCode_name[2276:2289],
+	51174:   _ErrorCode_name[2289:2302],
+	51176:   _ErrorCode_name[2302:2315],
+	51182:   _ErrorCode_name[2315:2328],
+	51246:   _ErrorCode_name[2328:2341],
+	51247:   _ErrorCode_name[2341:2354],
+	51270:   _ErrorCode_name[2354:2367],
+	51272:   _ErrorCode_name[2367:2380],
+	4822819: _ErrorCode_name[2380:2395],
+	5107200: _ErrorCode_name[2395:2410],
+	5107201: _ErrorCode_name[2410:2425],
+	5371602: _ErrorCode_name[2425:2440],
+	5447000: _ErrorCode_name[2440:2455],
+	5897900: _ErrorCode_name[2455:2470],
 }
 
 func (i ErrorCode) String() string {
@@ -16,11 +16,7 @@
 package commonpath
 
 import (
-	"errors"
-	"strconv"
-
 	"github.com/FerretDB/FerretDB/internal/types"
-	"github.com/FerretDB/FerretDB/internal/util/iterator"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 )
 
@@ -56,95 +52,20 @@ func FindValues(doc *types.Document, path types.Path, opts *FindValuesOpts) ([]a
 		opts = new(FindValuesOpts)
 	}
 
-	nextValues := []any{doc}
-
-	for _, e := range path.Slice() {
-		values := []any{}
-
-		for _, next := range nextValues {
-			switch next := next.(type) {
-			case *types.Document:
-				v, _ := next.Get(e)
-				if v == nil {
-					continue
-				}
-
-				values = append(values, v)
-
-			case *types.Array:
-				if opts.FindArrayIndex {
-					res, err := findArrayIndex(next, e)
-					if err == nil {
-						values = append(values, res)
-						continue
-					}
-				}
-
-				if opts.FindArrayDocuments {
-					res, err := lookupArrayDocuments(next, e)
-					if err != nil {
-						return nil, lazyerrors.Error(err)
-					}
-
-					values = append(values, res...)
-				}
-
-			default:
-				// path does not exist in scalar values, nothing to do
-			}
-		}
-
-		nextValues = values
+	walkOpts := &types.WalkPathOpts{
+		ArrayIndexes:   opts.FindArrayIndex,
+		ArrayDocuments: opts.FindArrayDocuments,
 	}
 
-	return nextValues, nil
-}
-
-// findArrayIndex returns the value by valid array index.
-//
-// Error is returned if index is not a number or index does not exist in array.
-func findArrayIndex(array *types.Array, index string) (any, error) {
-	i, err := strconv.Atoi(index)
-	if err != nil {
-		return nil, lazyerrors.Error(err)
-	}
+	res := []any{}
 
-	v, err := array.Get(i)
+	err := types.WalkPath(doc, path, walkOpts, func(_ types.Path, v any) error {
+		res = append(res, v)
+		return nil
+	})
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
-	return v, nil
-}
-
-// lookupArrayDocuments returns values for the given key in array's document.
-//
-// Non-document array values, documents without that key, etc. are skipped.
-func lookupArrayDocuments(array *types.Array, documentKey string) ([]any, error) {
-	iter := array.Iterator()
-	defer iter.Close()
-
-	res := []any{}
-
-	for {
-		_, v, err := iter.Next()
-		if errors.Is(err, iterator.ErrIteratorDone) {
-			break
-		}
-
-		if err != nil {
-			return nil, lazyerrors.Error(err)
-		}
-
-		doc, ok := v.(*types.Document)
-		if !ok {
-			continue
-		}
-
-		if v, _ = doc.Get(documentKey); v != nil {
-			res = append(res, v)
-		}
-	}
-
 	return res, nil
 }
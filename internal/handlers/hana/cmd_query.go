@@ -17,7 +17,6 @@ package hana
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
@@ -39,7 +38,7 @@ func (h *Handler) CmdQuery(ctx context.Context, query *wire.OpQuery) (*wire.OpRe
 					"maxBsonObjectSize", int32(types.MaxDocumentLen),
 					"maxMessageSizeBytes", int32(wire.MaxMsgLen),
 					"maxWriteBatchSize", int32(100000),
-					"localTime", time.Now(),
+					"localTime", types.Now(),
 					// logicalSessionTimeoutMinutes
 					"connectionId", int32(42),
 					"minWireVersion", common.MinWireVersion,
@@ -24,6 +24,7 @@ import (
 
 	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
 	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
 	"github.com/FerretDB/FerretDB/internal/handlers/hana/hanadb"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
@@ -40,6 +41,8 @@ type Handler struct {
 	pools map[string]*hanadb.Pool
 	*NewOpts
 
+	parameters *common.Parameters
+
 	// accessed by DBPool(ctx)
 	rw sync.RWMutex
 }
@@ -59,8 +62,9 @@ func New(opts *NewOpts) (handlers.Interface, error) {
 	}
 
 	h := &Handler{
-		NewOpts: opts,
-		pools:   make(map[string]*hanadb.Pool, 1),
+		NewOpts:    opts,
+		pools:      make(map[string]*hanadb.Pool, 1),
+		parameters: common.NewParameters(),
 	}
 
 	return h, nil
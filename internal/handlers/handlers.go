@@ -20,6 +20,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/FerretDB/FerretDB/internal/clientconn/session"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
@@ -40,6 +41,11 @@ type Interface interface {
 
 	prometheus.Collector
 
+	// BeginTransaction starts a new multi-document transaction.
+	// Commands of that transaction are called with the context returned by the transaction's Context method.
+	// Handlers that do not support transactions return (possibly wrapped) common.ErrTransactionsNotSupported.
+	BeginTransaction(ctx context.Context) (session.Transaction, error)
+
 	// CmdQuery queries collections for documents.
 	// Used by deprecated OP_QUERY message during connection handshake with an old client.
 	CmdQuery(ctx context.Context, query *wire.OpQuery) (*wire.OpReply, error)
@@ -49,19 +55,37 @@ type Interface interface {
 	// MsgAggregate returns aggregated data.
 	MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgApplyOps applies insert, update, and delete oplog entries.
+	MsgApplyOps(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
+	// MsgArchiveCollection stores the collection to the archive and drops it.
+	MsgArchiveCollection(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgBuildInfo returns a summary of the build information.
 	MsgBuildInfo(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgBulkWrite performs insert, update, and delete operations on multiple collections.
+	MsgBulkWrite(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
+	// MsgCloneCollectionAsCapped creates a new capped collection with documents of the existing collection.
+	MsgCloneCollectionAsCapped(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgCollMod adds options to a collection or modify view definitions.
 	MsgCollMod(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
 	// MsgCollStats returns storage data for a collection.
 	MsgCollStats(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgCompact reclaims unused disk space of the collection and rebuilds its indexes.
+	MsgCompact(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgConnectionStatus returns information about the current connection,
 	// specifically the state of authenticated users and their available permissions.
 	MsgConnectionStatus(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgConvertToCapped converts the existing collection to a capped collection.
+	MsgConvertToCapped(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgCount returns the count of documents that's matched by the query.
 	MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -71,6 +95,9 @@ type Interface interface {
 	// MsgCreateIndexes creates indexes on a collection.
 	MsgCreateIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgCreateUser creates a new user.
+	MsgCreateUser(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgCurrentOp returns information about operations currently in progress.
 	MsgCurrentOp(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -98,6 +125,9 @@ type Interface interface {
 	// MsgDropDatabase drops production database.
 	MsgDropDatabase(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgDropUser drops the user.
+	MsgDropUser(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgExplain returns the execution plan.
 	MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -107,6 +137,9 @@ type Interface interface {
 	// MsgFindAndModify inserts, updates, or deletes, and returns a document matched by the query.
 	MsgFindAndModify(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgFsync flushes pending writes to the storage and optionally locks the server against writes.
+	MsgFsync(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgGetCmdLineOpts returns a summary of all runtime and configuration options.
 	MsgGetCmdLineOpts(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -122,6 +155,9 @@ type Interface interface {
 	// MsgGetParameter returns the value of the parameter.
 	MsgGetParameter(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgGrantRolesToUser grants roles to the user.
+	MsgGrantRolesToUser(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgHello returns the role of the FerretDB instance.
 	MsgHello(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -143,6 +179,9 @@ type Interface interface {
 	// MsgListCommands returns a list of supported commands.
 	MsgListCommands(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgListCursors returns information about open cursors of all connections.
+	MsgListCursors(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgListDatabases returns a summary of all the databases.
 	MsgListDatabases(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -152,12 +191,30 @@ type Interface interface {
 	// MsgLogout logs out from the current session
 	MsgLogout(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgMoveCollection moves the collection to another backend.
+	MsgMoveCollection(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgPing returns a pong response.
 	MsgPing(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgReIndex rebuilds all indexes of a collection.
+	MsgReIndex(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgRenameCollection changes the name of an existing collection.
 	MsgRenameCollection(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgRestoreCollection restores the collection from the archive.
+	MsgRestoreCollection(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
+	// MsgRevokeRolesFromUser revokes roles from the user.
+	MsgRevokeRolesFromUser(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
+	// MsgRolesInfo returns information about roles.
+	MsgRolesInfo(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
+	// MsgSASLContinue continues the SASL authentication process.
+	MsgSASLContinue(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgSASLStart starts the SASL authentication process.
 	MsgSASLStart(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -167,12 +224,27 @@ type Interface interface {
 	// MsgSetFreeMonitoring toggles free monitoring.
 	MsgSetFreeMonitoring(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgSetParameter sets the value of the runtime parameter.
+	MsgSetParameter(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
+	// MsgTop returns usage statistics for each collection.
+	MsgTop(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgUpdate updates documents that are matched by the query.
 	MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgUpdateUser updates the user.
+	MsgUpdateUser(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
+	// MsgUsersInfo returns information about users.
+	MsgUsersInfo(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgValidate validates collection.
 	MsgValidate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgValidateDBMetadata checks stored indexes and validators against the API version.
+	MsgValidateDBMetadata(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgWhatsMyURI returns peer information.
 	MsgWhatsMyURI(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
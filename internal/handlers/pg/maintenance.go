@@ -0,0 +1,273 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pg
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+	"github.com/FerretDB/FerretDB/internal/util/ctxutil"
+)
+
+// maintenanceConfig represents runtime-changeable configuration of the maintenance scheduler.
+type maintenanceConfig struct {
+	enabled  bool
+	interval time.Duration
+	params   pgdb.MaintenanceParams
+}
+
+// defaultMaintenanceConfig is the initial configuration of the maintenance scheduler.
+//
+// Thresholds are the same as PostgreSQL's autovacuum defaults.
+// The scheduler is disabled by default.
+var defaultMaintenanceConfig = maintenanceConfig{
+	enabled:  false,
+	interval: time.Minute,
+	params: pgdb.MaintenanceParams{
+		AnalyzeThreshold:   50,
+		AnalyzeScaleFactor: 0.1,
+		VacuumThreshold:    50,
+		VacuumScaleFactor:  0.2,
+	},
+}
+
+// maintenance runs ANALYZE and VACUUM for collections in the background
+// to keep PostgreSQL statistics used by pushdown query plans up to date.
+type maintenance struct {
+	h *Handler
+	l *zap.Logger
+
+	rw     sync.RWMutex
+	config maintenanceConfig
+}
+
+// newMaintenance creates a new maintenance scheduler for the given handler.
+func newMaintenance(h *Handler) *maintenance {
+	return &maintenance{
+		h:      h,
+		l:      h.L.Named("maintenance"),
+		config: defaultMaintenanceConfig,
+	}
+}
+
+// run runs maintenance until ctx is canceled.
+//
+// Runs are jittered by up to ±20% of the interval,
+// so multiple FerretDB instances using the same PostgreSQL database do not run maintenance at the same time.
+func (m *maintenance) run(ctx context.Context) {
+	for {
+		m.rw.RLock()
+		config := m.config
+		m.rw.RUnlock()
+
+		jitter := time.Duration((rand.Float64()*0.4 - 0.2) * float64(config.interval))
+		ctxutil.Sleep(ctx, config.interval+jitter)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		// re-read configuration as it could have been changed during sleep
+		m.rw.RLock()
+		config = m.config
+		m.rw.RUnlock()
+
+		if !config.enabled {
+			continue
+		}
+
+		p := m.h.anyPool()
+		if p == nil {
+			m.l.Debug("No connection pool, skipping maintenance")
+			continue
+		}
+
+		start := time.Now()
+
+		res, err := p.Maintain(ctx, &config.params)
+		if err != nil {
+			m.l.Warn("Maintenance failed", zap.Error(err))
+		}
+
+		if res != nil {
+			m.l.Debug(
+				"Maintenance done",
+				zap.Strings("analyzed", res.Analyzed), zap.Strings("vacuumed", res.Vacuumed),
+				zap.Duration("duration", time.Since(start)),
+			)
+		}
+	}
+}
+
+// parameters returns runtime parameters of the maintenance scheduler.
+func (m *maintenance) parameters() map[string]common.Parameter {
+	res := map[string]*maintenanceParameter{
+		"ferretdbMaintenanceEnabled": {
+			get: func(c *maintenanceConfig) any { return c.enabled },
+			set: func(c *maintenanceConfig, name string, v any) error {
+				b, ok := v.(bool)
+				if !ok {
+					return common.ParameterTypeError(name, v, "bool")
+				}
+
+				c.enabled = b
+
+				return nil
+			},
+		},
+		"ferretdbMaintenanceIntervalSecs": {
+			get: func(c *maintenanceConfig) any { return int64(c.interval / time.Second) },
+			set: func(c *maintenanceConfig, name string, v any) error {
+				secs, err := positiveWholeNumber(name, v)
+				c.interval = time.Duration(secs) * time.Second
+
+				return err
+			},
+		},
+		"ferretdbMaintenanceAnalyzeThreshold": {
+			get: func(c *maintenanceConfig) any { return c.params.AnalyzeThreshold },
+			set: func(c *maintenanceConfig, name string, v any) error {
+				n, err := positiveWholeNumber(name, v)
+				c.params.AnalyzeThreshold = n
+
+				return err
+			},
+		},
+		"ferretdbMaintenanceAnalyzeScaleFactor": {
+			get: func(c *maintenanceConfig) any { return c.params.AnalyzeScaleFactor },
+			set: func(c *maintenanceConfig, name string, v any) error {
+				f, err := scaleFactor(name, v)
+				c.params.AnalyzeScaleFactor = f
+
+				return err
+			},
+		},
+		"ferretdbMaintenanceVacuumThreshold": {
+			get: func(c *maintenanceConfig) any { return c.params.VacuumThreshold },
+			set: func(c *maintenanceConfig, name string, v any) error {
+				n, err := positiveWholeNumber(name, v)
+				c.params.VacuumThreshold = n
+
+				return err
+			},
+		},
+		"ferretdbMaintenanceVacuumScaleFactor": {
+			get: func(c *maintenanceConfig) any { return c.params.VacuumScaleFactor },
+			set: func(c *maintenanceConfig, name string, v any) error {
+				f, err := scaleFactor(name, v)
+				c.params.VacuumScaleFactor = f
+
+				return err
+			},
+		},
+	}
+
+	params := make(map[string]common.Parameter, len(res))
+
+	for name, p := range res {
+		p.m = m
+		p.name = name
+		params[name] = p
+	}
+
+	return params
+}
+
+// maintenanceParameter implements common.Parameter for maintenance scheduler configuration.
+type maintenanceParameter struct {
+	m    *maintenance
+	name string
+	get  func(c *maintenanceConfig) any
+	set  func(c *maintenanceConfig, name string, v any) error
+}
+
+// Get implements common.Parameter interface.
+func (p *maintenanceParameter) Get() any {
+	p.m.rw.RLock()
+	defer p.m.rw.RUnlock()
+
+	return p.get(&p.m.config)
+}
+
+// Set implements common.Parameter interface.
+//
+// Configuration is not changed if the value is invalid.
+func (p *maintenanceParameter) Set(v any) error {
+	p.m.rw.Lock()
+	defer p.m.rw.Unlock()
+
+	config := p.m.config
+	if err := p.set(&config, p.name, v); err != nil {
+		return err
+	}
+
+	p.m.config = config
+
+	return nil
+}
+
+// positiveWholeNumber returns positive whole number value of the parameter or protocol error.
+func positiveWholeNumber(name string, v any) (int64, error) {
+	n, err := commonparams.GetWholeNumberParam(v)
+	if err != nil {
+		return 0, common.ParameterTypeError(name, v, "long")
+	}
+
+	if n <= 0 {
+		return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("'%s' must be positive, got %d", name, n),
+			"setParameter",
+		)
+	}
+
+	return n, nil
+}
+
+// scaleFactor returns non-negative scale factor value of the parameter or protocol error.
+func scaleFactor(name string, v any) (float64, error) {
+	var f float64
+
+	switch v := v.(type) {
+	case float64:
+		f = v
+	case int32:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	default:
+		return 0, common.ParameterTypeError(name, v, "double")
+	}
+
+	if math.IsNaN(f) || f < 0 || f > 100 {
+		return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("'%s' must be between 0 and 100, got %v", name, f),
+			"setParameter",
+		)
+	}
+
+	return f, nil
+}
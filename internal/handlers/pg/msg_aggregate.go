@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
 
 	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
 	"github.com/FerretDB/FerretDB/internal/clientconn/cursor"
@@ -53,7 +54,17 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 
 	common.Ignored(document, h.L, "lsid")
 
-	if err = common.Unimplemented(document, "explain", "collation", "let"); err != nil {
+	if err = common.Unimplemented(document, "explain", "let"); err != nil {
+		return nil, err
+	}
+
+	collationDoc, err := common.GetOptionalParam[*types.Document](document, "collation", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	collation, err := common.GetCollation(collationDoc)
+	if err != nil {
 		return nil, err
 	}
 
@@ -162,44 +173,25 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		)
 	}
 
-	aggregationStages := must.NotFail(iterator.ConsumeValues(pipeline.Iterator()))
-	stagesDocuments := make([]aggregations.Stage, 0, len(aggregationStages))
-	collStatsDocuments := make([]aggregations.Stage, 0, len(aggregationStages))
-
-	for i, d := range aggregationStages {
-		d, ok := d.(*types.Document)
-		if !ok {
-			return nil, commonerrors.NewCommandErrorMsgWithArgument(
-				commonerrors.ErrTypeMismatch,
-				"Each element of the 'pipeline' array must be an object",
-				document.Command(),
-			)
-		}
+	// all stages are parsed and validated before execution
+	p, err := stages.ParsePipeline(pipeline, document.Command())
+	if err != nil {
+		h.L.Debug("Invalid aggregation pipeline", zap.Error(err))
+		return nil, err
+	}
 
-		var s aggregations.Stage
+	if err = p.Optimize(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
 
-		if s, err = stages.NewStage(d); err != nil {
-			return nil, err
-		}
+	// stages of the optimized pipeline are used for pushdown
+	aggregationStages := p.Docs()
 
-		switch d.Command() {
-		case "$collStats":
-			if i > 0 {
-				// Add a test to cover this error.
-				// TODO https://github.com/FerretDB/FerretDB/issues/2349
-				return nil, commonerrors.NewCommandErrorMsgWithArgument(
-					commonerrors.ErrCollStatsIsNotFirstStage,
-					"$collStats is only valid as the first stage in a pipeline",
-					document.Command(),
-				)
-			}
+	stagesDocuments := p.DocumentsStages()
+	collStatsDocuments := p.AllStages()
 
-			collStatsDocuments = append(collStatsDocuments, s)
-		default:
-			stagesDocuments = append(stagesDocuments, s)
-			collStatsDocuments = append(collStatsDocuments, s) // It's possible to apply any stage after $collStats stage
-		}
-	}
+	// $out or $merge stage that writes pipeline results, if any
+	output := p.Output()
 
 	// validate cursor after validating pipeline stages to keep compatibility
 	v, _ = document.Get("cursor")
@@ -235,13 +227,17 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 
 	cancel := func() {}
 	if maxTimeMS != 0 {
-		// It is not clear if maxTimeMS affects only aggregate, or both aggregate and getMore (as the current code does).
+		// Like in MongoDB, maxTimeMS limits both aggregate and getMore commands for the created cursor;
+		// unlike MongoDB, the time between getMore commands is counted too.
 		// TODO https://github.com/FerretDB/FerretDB/issues/2983
 		ctx, cancel = context.WithTimeout(ctx, time.Duration(maxTimeMS)*time.Millisecond)
 	}
 
 	closer := iterator.NewMultiCloser(iterator.CloserFunc(cancel))
 
+	// stages compare strings using the collation from the context
+	ctx = common.WithCollation(ctx, collation)
+
 	var iter iterator.Interface[struct{}, *types.Document]
 
 	// At this point we have a list of stages to apply to the documents or stats.
@@ -256,14 +252,17 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 			Collection: collection,
 		}
 
-		if !h.DisableFilterPushdown {
+		// strings are compared using collation in memory
+		if !h.DisableFilterPushdown && collation == nil {
 			qp.Filter = filter
 		}
 
-		if h.EnableSortPushdown {
+		if h.EnableSortPushdown && collation == nil {
 			qp.Sort = sort
 		}
 
+		qp.Sample = stages.GetPushdownSample(aggregationStages)
+
 		iter, err = processStagesDocuments(ctx, closer, &stagesDocumentsParams{dbPool, &qp, stagesDocuments})
 	} else {
 		// stats stages are provided - fetch stats from the DB and apply stages to them
@@ -278,21 +277,37 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 
 	if err != nil {
 		closer.Close()
-		return nil, err
+		return nil, common.CheckMaxTimeMS(err)
+	}
+
+	if output != nil {
+		if err = writeAggregationOutput(ctx, dbPool, db, output, iter); err != nil {
+			closer.Close()
+			return nil, err
+		}
+
+		// $out and $merge stages return no documents
+		iter = iterator.Values(iterator.ForSlice([]*types.Document{}))
 	}
 
 	closer.Add(iter)
 
+	comment, _ := document.Get("comment")
+
 	cursor := h.cursors.NewCursor(ctx, &cursor.NewParams{
-		Iter:       iterator.WithClose(iter, closer.Close),
-		DB:         db,
-		Collection: collection,
-		Username:   username,
+		Iter:               iterator.WithClose(iter, closer.Close),
+		DB:                 db,
+		Collection:         collection,
+		Username:           username,
+		Client:             conninfo.Get(ctx).PeerAddr,
+		OriginatingCommand: document.DeepCopy(),
+		Comment:            comment,
+		QueryShapeHash:     common.QueryShapeHash(document),
 	})
 
 	cursorID := cursor.ID
 
-	firstBatchDocs, err := iterator.ConsumeValuesN(iterator.Interface[struct{}, *types.Document](cursor), int(batchSize))
+	firstBatchDocs, done, err := common.ConsumeBatch(cursor, int(batchSize))
 	if err != nil {
 		cursor.Close()
 		return nil, lazyerrors.Error(err)
@@ -303,7 +318,7 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		firstBatch.Append(doc)
 	}
 
-	if firstBatch.Len() < int(batchSize) {
+	if done {
 		// let the client know that there are no more results
 		cursorID = 0
 
@@ -316,7 +331,7 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 			"cursor", must.NotFail(types.NewDocument(
 				"firstBatch", firstBatch,
 				"id", cursorID,
-				"ns", db+"."+collection,
+				"ns", cursor.NS(),
 			)),
 			"ok", float64(1),
 		))},
@@ -349,6 +364,12 @@ func processStagesDocuments(ctx context.Context, closer *iterator.MultiCloser, p
 		closer.Add(iter)
 
 		for _, s := range p.stages {
+			if g, ok := s.(*stages.GeoNear); ok {
+				if err = resolveGeoNearKey(ctx, tx, p.qp, g); err != nil {
+					return err
+				}
+			}
+
 			if iter, err = s.Process(ctx, iter, closer); err != nil {
 				return err
 			}
@@ -369,6 +390,30 @@ func processStagesDocuments(ctx context.Context, closer *iterator.MultiCloser, p
 	return iter, nil
 }
 
+// resolveGeoNearKey sets the key of $geoNear stage from 2dsphere indexes of the collection if it was not specified.
+func resolveGeoNearKey(ctx context.Context, tx pgx.Tx, qp *pgdb.QueryParams, g *stages.GeoNear) error {
+	indexes, err := pgdb.Indexes(ctx, tx, qp.DB, qp.Collection)
+	if err != nil && !errors.Is(err, pgdb.ErrTableNotExist) {
+		return lazyerrors.Error(err)
+	}
+
+	var fields []string
+
+	for _, index := range indexes {
+		if index.Hidden {
+			continue
+		}
+
+		for _, pair := range index.Key {
+			if pair.Sphere {
+				fields = append(fields, pair.Field)
+			}
+		}
+	}
+
+	return g.ResolveKey(fields)
+}
+
 // stagesStatsParams contains the parameters for processStagesStats.
 type stagesStatsParams struct {
 	dbPool     *pgdb.Pool
@@ -398,7 +443,7 @@ func processStagesStats(ctx context.Context, closer *iterator.MultiCloser, p *st
 	doc := must.NotFail(types.NewDocument(
 		"ns", p.db+"."+p.collection,
 		"host", host,
-		"localTime", time.Now().UTC().Format(time.RFC3339),
+		"localTime", types.Now().UTC().Format(time.RFC3339),
 	))
 
 	var collStats *pgdb.CollStats
@@ -469,3 +514,142 @@ func processStagesStats(ctx context.Context, closer *iterator.MultiCloser, p *st
 
 	return iter, nil
 }
+
+// writeAggregationOutput consumes all documents returned by the pipeline
+// and writes them to the target collection of $out or $merge stage in a single transaction.
+func writeAggregationOutput(ctx context.Context, dbPool *pgdb.Pool, db string, output aggregations.Stage, iter types.DocumentsIterator) error { //nolint:lll // for readability
+	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+
+	// close the iterator before writing as the target collection may be the source one
+	iter.Close()
+
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	switch output := output.(type) {
+	case *stages.Out:
+		if output.DB != "" {
+			db = output.DB
+		}
+
+		if err = output.PrepareDocuments(db, docs); err != nil {
+			return err
+		}
+
+		qp := &pgdb.QueryParams{DB: db, Collection: output.Collection}
+
+		return dbPool.InTransactionRetry(ctx, func(tx pgx.Tx) error {
+			return writeOut(ctx, tx, qp, docs)
+		})
+
+	case *stages.Merge:
+		if output.DB != "" {
+			db = output.DB
+		}
+
+		qp := &pgdb.QueryParams{DB: db, Collection: output.Collection}
+
+		return dbPool.InTransactionRetry(ctx, func(tx pgx.Tx) error {
+			return writeMerge(ctx, tx, qp, output, docs)
+		})
+
+	default:
+		panic(fmt.Sprintf("unexpected output stage %T", output))
+	}
+}
+
+// writeOut replaces all documents of $out stage's target collection with the given documents.
+func writeOut(ctx context.Context, tx pgx.Tx, qp *pgdb.QueryParams, docs []*types.Document) error {
+	if _, err := pgdb.CreateCollectionIfNotExists(ctx, tx, qp.DB, qp.Collection); err != nil {
+		return outputError(err, qp)
+	}
+
+	iter, _, err := pgdb.QueryDocuments(ctx, tx, qp)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	existing, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+	iter.Close()
+
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if len(existing) > 0 {
+		ids := make([]any, len(existing))
+		for i, doc := range existing {
+			ids[i] = must.NotFail(doc.Get("_id"))
+		}
+
+		if _, err = pgdb.DeleteDocumentsByID(ctx, tx, qp, ids); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	for _, doc := range docs {
+		if err = pgdb.InsertDocument(ctx, tx, qp.DB, qp.Collection, doc); err != nil {
+			return outputError(err, qp)
+		}
+	}
+
+	return nil
+}
+
+// writeMerge merges the given documents into $merge stage's target collection.
+func writeMerge(ctx context.Context, tx pgx.Tx, qp *pgdb.QueryParams, merge *stages.Merge, docs []*types.Document) error {
+	if _, err := pgdb.CreateCollectionIfNotExists(ctx, tx, qp.DB, qp.Collection); err != nil {
+		return outputError(err, qp)
+	}
+
+	iter, _, err := pgdb.QueryDocuments(ctx, tx, qp)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	target, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+	iter.Close()
+
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	insert, update, err := merge.Documents(docs, target)
+	if err != nil {
+		return err
+	}
+
+	for _, doc := range update {
+		if _, err = pgdb.SetDocumentByID(ctx, tx, qp, must.NotFail(doc.Get("_id")), doc); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	for _, doc := range insert {
+		if err = pgdb.InsertDocument(ctx, tx, qp.DB, qp.Collection, doc); err != nil {
+			return outputError(err, qp)
+		}
+	}
+
+	return nil
+}
+
+// outputError converts errors of writing to $out or $merge target collection to command errors.
+func outputError(err error, qp *pgdb.QueryParams) error {
+	switch {
+	case errors.Is(err, pgdb.ErrInvalidCollectionName), errors.Is(err, pgdb.ErrInvalidDatabaseName):
+		msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", qp.DB, qp.Collection)
+		return commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, "aggregate")
+
+	case errors.Is(err, pgdb.ErrUniqueViolation):
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrDuplicateKeyInsert,
+			fmt.Sprintf("E11000 duplicate key error collection: %s.%s index: _id_", qp.DB, qp.Collection),
+			"aggregate",
+		)
+
+	default:
+		return lazyerrors.Error(err)
+	}
+}
@@ -16,15 +16,226 @@ package pg
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgCollMod implements HandlerInterface.
 func (h *Handler) MsgCollMod(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	return nil, commonerrors.NewCommandErrorMsg(
-		commonerrors.ErrNotImplemented,
-		"`collMod` command is not implemented yet",
-	)
+	dbPool, err := h.DBPool(ctx)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	unimplementedFields := []string{
+		"validator",
+		"validationLevel",
+		"validationAction",
+		"viewOn",
+		"pipeline",
+		"expireAfterSeconds",
+		"changeStreamPreAndPostImages",
+		"cappedSize",
+		"cappedMax",
+	}
+	if err = common.Unimplemented(document, unimplementedFields...); err != nil {
+		return nil, err
+	}
+
+	common.Ignored(document, h.L, "writeConcern", "comment")
+
+	command := document.Command()
+
+	db, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	v, _ := document.Get("index")
+	if v == nil {
+		// nothing to modify
+		var reply wire.OpMsg
+		must.NoError(reply.SetSections(wire.OpMsgSection{
+			Documents: []*types.Document{must.NotFail(types.NewDocument(
+				"ok", float64(1),
+			))},
+		}))
+
+		return &reply, nil
+	}
+
+	indexDoc, ok := v.(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field 'collMod.index' is the wrong type '%s', expected type 'object'",
+				commonparams.AliasFromType(v),
+			),
+			command,
+		)
+	}
+
+	index, hidden, err := processCollModIndex(indexDoc, command)
+	if err != nil {
+		return nil, err
+	}
+
+	var hiddenOld bool
+
+	err = dbPool.InTransactionRetry(ctx, func(tx pgx.Tx) error {
+		hiddenOld, err = pgdb.SetIndexHidden(ctx, tx, db, collection, index, hidden)
+		return err
+	})
+
+	switch {
+	case err == nil:
+		// nothing
+	case errors.Is(err, pgdb.ErrTableNotExist):
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrNamespaceNotFound,
+			"ns does not exist",
+			command,
+		)
+	case errors.Is(err, pgdb.ErrIndexNotExist):
+		spec := index.Name
+		if spec == "" {
+			spec = types.FormatAnyValue(must.NotFail(indexDoc.Get("keyPattern")))
+		}
+
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrIndexNotFound,
+			fmt.Sprintf("cannot find index %s for ns %s.%s", spec, db, collection),
+			command,
+		)
+	case errors.Is(err, pgdb.ErrIndexCannotHide):
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"can't hide _id index",
+			command,
+		)
+	default:
+		return nil, lazyerrors.Error(err)
+	}
+
+	replyDoc := must.NotFail(types.NewDocument())
+
+	// like MongoDB, report the change only if there was one
+	if hiddenOld != hidden {
+		replyDoc.Set("hidden_old", hiddenOld)
+		replyDoc.Set("hidden_new", hidden)
+	}
+
+	replyDoc.Set("ok", float64(1))
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{replyDoc},
+	}))
+
+	return &reply, nil
+}
+
+// processCollModIndex parses the index document of collMod command.
+// It returns the index to modify (with either name or key set) and the new hidden value.
+func processCollModIndex(indexDoc *types.Document, command string) (*pgdb.Index, bool, error) {
+	var index pgdb.Index
+
+	name, _ := indexDoc.Get("name")
+	keyPattern, _ := indexDoc.Get("keyPattern")
+
+	switch {
+	case name != nil && keyPattern != nil:
+		return nil, false, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidOptions,
+			"Cannot specify both key pattern and name.",
+			command,
+		)
+
+	case name != nil:
+		s, ok := name.(string)
+		if !ok {
+			return nil, false, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				fmt.Sprintf(
+					"BSON field 'collMod.index.name' is the wrong type '%s', expected type 'string'",
+					commonparams.AliasFromType(name),
+				),
+				command,
+			)
+		}
+
+		index.Name = s
+
+	case keyPattern != nil:
+		keyDoc, ok := keyPattern.(*types.Document)
+		if !ok {
+			return nil, false, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				fmt.Sprintf(
+					"BSON field 'collMod.index.keyPattern' is the wrong type '%s', expected type 'object'",
+					commonparams.AliasFromType(keyPattern),
+				),
+				command,
+			)
+		}
+
+		key, err := processIndexKey(keyDoc)
+		if err != nil {
+			return nil, false, err
+		}
+
+		index.Key = key
+
+	default:
+		return nil, false, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidOptions,
+			"Must specify either index name or key pattern.",
+			command,
+		)
+	}
+
+	v, _ := indexDoc.Get("hidden")
+	if v == nil {
+		return nil, false, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidOptions,
+			"no expireAfterSeconds or hidden field",
+			command,
+		)
+	}
+
+	hidden, ok := v.(bool)
+	if !ok {
+		return nil, false, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field 'collMod.index.hidden' is the wrong type '%s', expected type 'bool'",
+				commonparams.AliasFromType(v),
+			),
+			command,
+		)
+	}
+
+	return &index, hidden, nil
 }
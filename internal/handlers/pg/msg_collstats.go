@@ -101,9 +101,14 @@ func (h *Handler) MsgCollStats(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		"ok", float64(1),
 	)
 
+	res := must.NotFail(types.NewDocument(pairs...))
+	if err = common.CollStatsNumericSchema.Apply(res, h.NumericTypes); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{must.NotFail(types.NewDocument(pairs...))},
+		Documents: []*types.Document{res},
 	}))
 
 	return &reply, nil
@@ -46,6 +46,9 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 		return nil, err
 	}
 
+	ctx, cancel := common.WithMaxTimeMS(ctx, params.MaxTimeMS)
+	defer cancel()
+
 	qp := pgdb.QueryParams{
 		Filter:     params.Filter,
 		DB:         params.DB,
@@ -68,7 +71,7 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 		closer := iterator.NewMultiCloser(iter)
 		defer closer.Close()
 
-		iter = common.FilterIterator(iter, closer, qp.Filter)
+		iter = common.FilterIterator(iter, closer, qp.Filter, nil)
 
 		iter = common.SkipIterator(iter, closer, params.Skip)
 
@@ -94,7 +97,7 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, common.CheckMaxTimeMS(err)
 	}
 
 	var reply wire.OpMsg
@@ -53,6 +53,7 @@ func (h *Handler) MsgCreate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		"viewOn",
 		"pipeline",
 		"collation",
+		"clusteredIndex",
 	}
 	if err := common.Unimplemented(document, unimplementedFields...); err != nil {
 		return nil, err
@@ -18,9 +18,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/AlekSi/pointer"
 	"github.com/jackc/pgx/v5"
+	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
@@ -248,6 +250,28 @@ func (h *Handler) MsgCreateIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.
 			document.Command(),
 		)
 	default:
+		var pae *pgdb.ParallelArraysError
+		if errors.As(err, &pae) {
+			return nil, commonerrors.NewCommandErrorMsg(
+				commonerrors.ErrCannotIndexParallelArrays,
+				"Index build failed: "+pae.Error(),
+			)
+		}
+
+		if errors.Is(err, pgdb.ErrHashedArray) {
+			return nil, commonerrors.NewCommandErrorMsg(
+				commonerrors.ErrHashedIndexArray,
+				"Index build failed: "+pgdb.ErrHashedArray.Error(),
+			)
+		}
+
+		if errors.Is(err, pgdb.ErrGeoKeys) {
+			return nil, commonerrors.NewCommandErrorMsg(
+				commonerrors.ErrCannotExtractGeoKeys,
+				"Index build failed: "+pgdb.ErrGeoKeys.Error(),
+			)
+		}
+
 		return nil, lazyerrors.Error(err)
 	}
 
@@ -298,6 +322,17 @@ func processIndexOptions(indexDoc *types.Document) (*pgdb.Index, error) {
 				)
 			}
 
+			// collation keys are not stored in PostgreSQL indexes, so uniqueness can't be enforced
+			if index.Unique != nil && *index.Unique && index.Collation != nil {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrNotImplemented,
+					"Unique indexes with non-simple collation are not implemented yet",
+					"createIndexes",
+				)
+			}
+
+			setTextIndexDefaults(&index)
+
 			return &index, nil
 		default:
 			return nil, lazyerrors.Error(err)
@@ -341,6 +376,20 @@ func processIndexOptions(indexDoc *types.Document) (*pgdb.Index, error) {
 			}
 		}
 
+		if err = common.ValidateIndexKey("createIndexes", keyDoc); err != nil {
+			return nil, err
+		}
+
+		for _, v := range keyDoc.Values() {
+			if t, ok := v.(string); ok && t != "hashed" && t != "2dsphere" && t != "text" {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrNotImplemented,
+					fmt.Sprintf("Index type %q is not implemented yet", t),
+					"createIndexes",
+				)
+			}
+		}
+
 		index.Key, err = processIndexKey(keyDoc)
 		if err != nil {
 			return nil, err
@@ -403,16 +452,129 @@ func processIndexOptions(indexDoc *types.Document) (*pgdb.Index, error) {
 				)
 			}
 
+			if unique && slices.ContainsFunc(index.Key, func(p pgdb.IndexKeyPair) bool { return p.Hashed }) {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCannotCreateIndex,
+					"Currently hashed indexes cannot guarantee uniqueness. Use a regular index.",
+					"createIndexes",
+				)
+			}
+
+			if unique && slices.ContainsFunc(index.Key, func(p pgdb.IndexKeyPair) bool { return p.Sphere }) {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCannotCreateIndex,
+					"Index type '2dsphere' does not support the unique option",
+					"createIndexes",
+				)
+			}
+
+			if unique && slices.ContainsFunc(index.Key, func(p pgdb.IndexKeyPair) bool { return p.Text }) {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrNotImplemented,
+					"Unique text indexes are not implemented yet",
+					"createIndexes",
+				)
+			}
+
+			if _, wildcard := index.Key.Wildcard(); wildcard && unique {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCannotCreateIndex,
+					"Index type 'wildcard' does not support the unique option",
+					"createIndexes",
+				)
+			}
+
 			if unique {
 				index.Unique = pointer.ToBool(true)
 			}
 
+		case "hidden":
+			v := must.NotFail(indexDoc.Get("hidden"))
+
+			hidden, ok := v.(bool)
+			if !ok {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrTypeMismatch,
+					fmt.Sprintf(
+						"Error in specification { key: %s, name: \"%s\", hidden: %s } "+
+							":: caused by :: "+
+							"The field 'hidden' has value hidden: %[3]s, which is not convertible to bool",
+						types.FormatAnyValue(must.NotFail(indexDoc.Get("key"))),
+						index.Name, types.FormatAnyValue(v),
+					),
+					"createIndexes",
+				)
+			}
+
+			if hidden && len(index.Key) == 1 && index.Key[0].Field == "_id" {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrBadValue,
+					"can't hide _id index",
+					"createIndexes",
+				)
+			}
+
+			index.Hidden = hidden
+
+		case "collation":
+			v := must.NotFail(indexDoc.Get("collation"))
+
+			collationDoc, ok := v.(*types.Document)
+			if !ok {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrTypeMismatch,
+					fmt.Sprintf(
+						"Error in specification { key: %s, name: \"%s\", collation: %s } "+
+							":: caused by :: "+
+							"The field 'collation' must be an object",
+						types.FormatAnyValue(must.NotFail(indexDoc.Get("key"))),
+						index.Name, types.FormatAnyValue(v),
+					),
+					"createIndexes",
+				)
+			}
+
+			collation, err := common.GetCollation(collationDoc)
+			if err != nil {
+				return nil, err
+			}
+
+			index.Collation = collation.Document()
+
 		case "background":
 			// ignore deprecated options
 
-		case "sparse", "partialFilterExpression", "expireAfterSeconds", "hidden", "storageEngine",
-			"weights", "default_language", "language_override", "textIndexVersion", "2dsphereIndexVersion",
-			"bits", "min", "max", "bucketSize", "collation", "wildcardProjection":
+		case "2dsphereIndexVersion":
+			// only the latest version is supported, and it is always returned by listIndexes
+			if !slices.ContainsFunc(index.Key, func(p pgdb.IndexKeyPair) bool { return p.Sphere }) {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrNotImplemented,
+					fmt.Sprintf("Index option %q is not implemented yet", opt),
+					"createIndexes",
+				)
+			}
+
+		case "weights", "default_language", "language_override", "textIndexVersion":
+			if !slices.ContainsFunc(index.Key, func(p pgdb.IndexKeyPair) bool { return p.Text }) {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrNotImplemented,
+					fmt.Sprintf("Index option %q is not implemented yet", opt),
+					"createIndexes",
+				)
+			}
+
+			if index.Text == nil {
+				index.Text = &pgdb.TextIndexOptions{
+					Weights: map[string]int32{},
+				}
+			}
+
+			if err = processTextIndexOption(index.Text, opt, must.NotFail(indexDoc.Get(opt))); err != nil {
+				return nil, err
+			}
+
+		case "sparse", "partialFilterExpression", "expireAfterSeconds", "storageEngine",
+			"bits", "min", "max", "bucketSize", "wildcardProjection":
 			return nil, commonerrors.NewCommandErrorMsgWithArgument(
 				commonerrors.ErrNotImplemented,
 				fmt.Sprintf("Index option %q is not implemented yet", opt),
@@ -438,6 +600,8 @@ func processIndexKey(keyDoc *types.Document) (pgdb.IndexKey, error) {
 
 	duplicateChecker := make(map[string]struct{}, keyDoc.Len())
 
+	var hashed bool
+
 	for {
 		field, order, err := keyIter.Next()
 
@@ -445,6 +609,15 @@ func processIndexKey(keyDoc *types.Document) (pgdb.IndexKey, error) {
 		case err == nil:
 			// do nothing
 		case errors.Is(err, iterator.ErrIteratorDone):
+			text := slices.ContainsFunc(res, func(p pgdb.IndexKeyPair) bool { return p.Text })
+			if text && slices.ContainsFunc(res, func(p pgdb.IndexKeyPair) bool { return !p.Text }) {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrNotImplemented,
+					"Compound text indexes with non-text fields are not implemented yet",
+					"createIndexes",
+				)
+			}
+
 			return res, nil
 		default:
 			return nil, lazyerrors.Error(err)
@@ -463,6 +636,92 @@ func processIndexKey(keyDoc *types.Document) (pgdb.IndexKey, error) {
 
 		duplicateChecker[field] = struct{}{}
 
+		wildcard := strings.Contains(field, "$**")
+		if wildcard {
+			if _, ok := (pgdb.IndexKeyPair{Field: field}).Wildcard(); !ok {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCannotCreateIndex,
+					fmt.Sprintf("Index key contains an illegal field name: %q", field),
+					"createIndexes",
+				)
+			}
+
+			if keyDoc.Len() > 1 {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCannotCreateIndex,
+					"wildcard indexes do not allow compounding",
+					"createIndexes",
+				)
+			}
+		}
+
+		if order == "2dsphere" {
+			if wildcard {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCannotCreateIndex,
+					"Index key contains an illegal field name: wildcard indexes could not be 2dsphere",
+					"createIndexes",
+				)
+			}
+
+			res = append(res, pgdb.IndexKeyPair{
+				Field:  field,
+				Order:  types.Ascending,
+				Sphere: true,
+			})
+
+			continue
+		}
+
+		if order == "text" {
+			if wildcard && field != common.TextIndexWildcard {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCannotCreateIndex,
+					fmt.Sprintf("Index key contains an illegal field name: %q", field),
+					"createIndexes",
+				)
+			}
+
+			res = append(res, pgdb.IndexKeyPair{
+				Field: field,
+				Order: types.Ascending,
+				Text:  true,
+			})
+
+			continue
+		}
+
+		if order == "hashed" {
+			if wildcard {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCannotCreateIndex,
+					"Index key contains an illegal field name: wildcard indexes could not be hashed",
+					"createIndexes",
+				)
+			}
+
+			if hashed {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCannotCreateIndex,
+					fmt.Sprintf(
+						"A maximum of one index field is allowed to be hashed but found 2 for 'key' %s",
+						types.FormatAnyValue(keyDoc),
+					),
+					"createIndexes",
+				)
+			}
+
+			hashed = true
+
+			res = append(res, pgdb.IndexKeyPair{
+				Field:  field,
+				Order:  types.Ascending,
+				Hashed: true,
+			})
+
+			continue
+		}
+
 		var orderParam int64
 
 		if orderParam, err = commonparams.GetWholeNumberParam(order); err != nil {
@@ -473,6 +732,14 @@ func processIndexKey(keyDoc *types.Document) (pgdb.IndexKey, error) {
 			)
 		}
 
+		if wildcard && orderParam != 1 {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrCannotCreateIndex,
+				"A numeric value in a $** index key pattern must be positive.",
+				"createIndexes",
+			)
+		}
+
 		var indexOrder types.SortType
 
 		switch orderParam {
@@ -494,3 +761,103 @@ func processIndexKey(keyDoc *types.Document) (pgdb.IndexKey, error) {
 		})
 	}
 }
+
+// processTextIndexOption processes the option of the text index.
+func processTextIndexOption(text *pgdb.TextIndexOptions, opt string, v any) error {
+	switch opt {
+	case "weights":
+		weights, ok := v.(*types.Document)
+		if !ok {
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrCannotCreateIndex,
+				"weights must be an object",
+				"createIndexes",
+			)
+		}
+
+		for _, field := range weights.Keys() {
+			weight, err := commonparams.GetWholeNumberParam(must.NotFail(weights.Get(field)))
+			if err != nil || weight <= 0 || weight >= 100_000 {
+				return commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCannotCreateIndex,
+					fmt.Sprintf(
+						"text index weight must be an integer in the exclusive interval (0,100000) but found: %s",
+						types.FormatAnyValue(must.NotFail(weights.Get(field))),
+					),
+					"createIndexes",
+				)
+			}
+
+			text.Weights[field] = int32(weight)
+		}
+
+	case "default_language":
+		l, ok := v.(string)
+		if ok {
+			text.DefaultLanguage, ok = common.TextLanguage(l)
+		}
+
+		if !ok {
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrCannotCreateIndex,
+				fmt.Sprintf("default_language %s is not supported", types.FormatAnyValue(v)),
+				"createIndexes",
+			)
+		}
+
+	case "language_override":
+		l, ok := v.(string)
+		if !ok || l == "" {
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrCannotCreateIndex,
+				"language_override must be a non-empty string",
+				"createIndexes",
+			)
+		}
+
+		text.LanguageOverride = l
+
+	case "textIndexVersion":
+		// only the latest version is supported, and it is always returned by listIndexes
+		if version, err := commonparams.GetWholeNumberParam(v); err != nil || version != 3 {
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				fmt.Sprintf("textIndexVersion %s is not implemented yet", types.FormatAnyValue(v)),
+				"createIndexes",
+			)
+		}
+
+	default:
+		panic(fmt.Sprintf("unexpected text index option %q", opt))
+	}
+
+	return nil
+}
+
+// setTextIndexDefaults sets default options of the text index
+// and adds weights of the index key fields that were not set by the weights option.
+func setTextIndexDefaults(index *pgdb.Index) {
+	if !slices.ContainsFunc(index.Key, func(p pgdb.IndexKeyPair) bool { return p.Text }) {
+		return
+	}
+
+	if index.Text == nil {
+		index.Text = &pgdb.TextIndexOptions{
+			Weights: map[string]int32{},
+		}
+	}
+
+	if index.Text.DefaultLanguage == "" {
+		index.Text.DefaultLanguage = "english"
+	}
+
+	if index.Text.LanguageOverride == "" {
+		index.Text.LanguageOverride = "language"
+	}
+
+	for _, pair := range index.Key {
+		if _, ok := index.Text.Weights[pair.Field]; !ok {
+			index.Text.Weights[pair.Field] = 1
+		}
+	}
+}
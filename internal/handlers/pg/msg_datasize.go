@@ -93,15 +93,20 @@ func (h *Handler) MsgDataSize(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg
 		pairs = append(pairs, "estimate", false)
 	}
 	pairs = append(pairs,
-		"size", stats.SizeTotal,
+		"size", stats.SizeCollection,
 		"numObjects", stats.CountObjects,
 		"millis", int32(elapses.Milliseconds()),
 		"ok", float64(1),
 	)
 
+	res := must.NotFail(types.NewDocument(pairs...))
+	if err = common.DataSizeNumericSchema.Apply(res, h.NumericTypes); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{must.NotFail(types.NewDocument(pairs...))},
+		Documents: []*types.Document{res},
 	}))
 
 	return &reply, nil
@@ -79,7 +79,7 @@ func (h *Handler) MsgDBStats(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 
 	pairs = append(pairs,
 		"dataSize", stats.SizeCollections/scale,
-		"storageSize", stats.SizeCollections/scale,
+		"storageSize", stats.SizeStorage/scale,
 		"indexes", stats.CountIndexes,
 		"indexSize", stats.SizeIndexes/scale,
 		"totalSize", stats.SizeTotal/scale,
@@ -87,9 +87,14 @@ func (h *Handler) MsgDBStats(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		"ok", float64(1),
 	)
 
+	res := must.NotFail(types.NewDocument(pairs...))
+	if err = common.DBStatsNumericSchema.Apply(res, h.NumericTypes); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{must.NotFail(types.NewDocument(pairs...))},
+		Documents: []*types.Document{res},
 	}))
 
 	return &reply, nil
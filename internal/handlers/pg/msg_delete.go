@@ -48,6 +48,16 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, lazyerrors.Error(err)
 	}
 
+	// explicit collation of deletes is supported by the SQLite handler only
+	for _, d := range params.Deletes {
+		if d.Collation != nil {
+			return nil, unimplementedCollation(document.Command(), d.Collation)
+		}
+	}
+
+	ctx, cancel := common.WithMaxTimeMS(ctx, params.MaxTimeMS)
+	defer cancel()
+
 	qp := pgdb.QueryParams{
 		DB:         params.DB,
 		Collection: params.Collection,
@@ -73,6 +83,10 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			continue
 		}
 
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, common.CheckMaxTimeMS(err)
+		}
+
 		delErrors.Append(err, int32(i))
 
 		if params.Ordered {
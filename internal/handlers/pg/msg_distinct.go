@@ -45,13 +45,19 @@ func (h *Handler) MsgDistinct(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg
 		return nil, err
 	}
 
+	collation, err := common.GetCollation(dp.Collation)
+	if err != nil {
+		return nil, err
+	}
+
 	qp := pgdb.QueryParams{
 		DB:         dp.DB,
 		Collection: dp.Collection,
 		Comment:    dp.Comment,
 	}
 
-	if !h.DisableFilterPushdown {
+	// strings are compared using collation in memory
+	if !h.DisableFilterPushdown && collation == nil {
 		qp.Filter = dp.Filter
 	}
 
@@ -67,9 +73,9 @@ func (h *Handler) MsgDistinct(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg
 		closer := iterator.NewMultiCloser(iter)
 		defer closer.Close()
 
-		iter = common.FilterIterator(iter, closer, qp.Filter)
+		iter = common.FilterIterator(iter, closer, dp.Filter, collation)
 
-		distinct, err = common.FilterDistinctValues(iter, dp.Key)
+		distinct, err = common.FilterDistinctValues(iter, dp.Key, collation)
 		if err != nil {
 			return lazyerrors.Error(err)
 		}
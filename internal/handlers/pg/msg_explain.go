@@ -23,6 +23,7 @@ import (
 	"github.com/FerretDB/FerretDB/build/version"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/stages"
 	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
@@ -55,8 +56,12 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		Sort:       params.Sort,
 	}
 
+	var stagesDocs []any
+	var optimized bool
+
 	if params.Aggregate {
-		qp.Filter, qp.Sort = aggregations.GetPushdownQuery(params.StagesDocs)
+		stagesDocs, optimized = stages.OptimizeDocs(params.StagesDocs)
+		qp.Filter, qp.Sort = aggregations.GetPushdownQuery(stagesDocs)
 	}
 
 	if h.DisableFilterPushdown {
@@ -67,14 +72,14 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		qp.Sort = nil
 	}
 
-	// Limit pushdown is not applied if:
+	// Limit and skip pushdown is not applied if:
 	//  - `filter` is set, it must fetch all documents to filter them in memory;
 	//  - `sort` is set but `EnableSortPushdown` is not set, it must fetch all documents
-	//  and sort them in memory;
-	//  - `skip` is non-zero value, skip pushdown is not supported yet.
+	//  and sort them in memory.
 	// TODO https://github.com/FerretDB/FerretDB/issues/3016
-	if params.Filter.Len() == 0 && (params.Sort.Len() == 0 || h.EnableSortPushdown) && params.Skip == 0 {
+	if params.Filter.Len() == 0 && (params.Sort.Len() == 0 || h.EnableSortPushdown) {
 		qp.Limit = params.Limit
+		qp.Offset = params.Skip
 	}
 
 	var queryPlanner *types.Document
@@ -106,21 +111,32 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	cmd := params.Command
 	cmd.Set("$db", qp.DB)
 
+	if optimized {
+		queryPlanner.Set("optimizedPipeline", true)
+	}
+
+	res := must.NotFail(types.NewDocument(
+		"queryPlanner", queryPlanner,
+		"explainVersion", "1",
+		"command", cmd,
+		"serverInfo", serverInfo,
+
+		// our extensions
+		"pushdown", results.FilterPushdown,
+		"sortingPushdown", results.SortPushdown,
+		"limitPushdown", results.LimitPushdown,
+		"skipPushdown", results.OffsetPushdown,
+	))
+
+	if params.Aggregate {
+		res.Set("stages", must.NotFail(types.NewArray(stagesDocs...)))
+	}
+
+	res.Set("ok", float64(1))
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"queryPlanner", queryPlanner,
-			"explainVersion", "1",
-			"command", cmd,
-			"serverInfo", serverInfo,
-
-			// our extensions
-			"pushdown", results.FilterPushdown,
-			"sortingPushdown", results.SortPushdown,
-			"limitPushdown", results.LimitPushdown,
-
-			"ok", float64(1),
-		))},
+		Documents: []*types.Document{res},
 	}))
 
 	return &reply, nil
@@ -50,12 +50,42 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		return nil, err
 	}
 
+	// capped collections are not supported by this handler, so all collections are non-capped
+	if params.Tailable {
+		return nil, common.NewTailableNonCappedError(params)
+	}
+
+	if params.RequestResumeToken {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrNotImplemented,
+			"$_requestResumeToken is not implemented for this handler yet",
+			"find",
+		)
+	}
+
+	text, filter, err := common.FindText(params.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = common.CheckTextScore(text, params.Projection, params.Sort); err != nil {
+		return nil, err
+	}
+
+	collation, err := common.GetCollation(params.Collation)
+	if err != nil {
+		return nil, err
+	}
+
 	username, _ := conninfo.Get(ctx).Auth()
 
 	qp := &pgdb.QueryParams{
-		DB:         params.DB,
-		Collection: params.Collection,
-		Comment:    params.Comment,
+		DB:             params.DB,
+		Collection:     params.Collection,
+		Comment:        params.Comment,
+		Natural:        params.Natural,
+		CollectionScan: params.CollectionScan,
+		RecordID:       params.ShowRecordId,
 	}
 
 	// get comment from query, e.g. db.collection.find({$comment: "test"})
@@ -65,26 +95,34 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		}
 	}
 
-	if !h.DisableFilterPushdown {
+	// strings are compared using collation in memory
+	if !h.DisableFilterPushdown && collation == nil {
 		qp.Filter = params.Filter
 	}
 
-	if h.EnableSortPushdown && params.Projection == nil {
+	// documents are sorted by text score or using collation in memory
+	sortPushdown := h.EnableSortPushdown && collation == nil
+
+	if sortPushdown && params.Projection == nil && text == nil {
 		qp.Sort = params.Sort
 	}
 
-	// Limit pushdown is not applied if:
+	// Limit and skip pushdown is not applied if:
 	//  - `filter` is set, it must fetch all documents to filter them in memory;
-	//  - `sort` is set but `EnableSortPushdown` is not set, it must fetch all documents
+	//  - `sort` is set but `EnableSortPushdown` is not set or collation is used, it must fetch all documents
 	//  and sort them in memory;
-	//  - `skip` is non-zero value, skip pushdown is not supported yet.
-	if params.Filter.Len() == 0 && (params.Sort.Len() == 0 || h.EnableSortPushdown) && params.Skip == 0 {
+	//  - `min` or `max` is set, documents are filtered by index bounds in memory.
+	// Skip is pushed down as SQL OFFSET, so skipped documents are not fetched from the backend.
+	if params.Filter.Len() == 0 && (params.Sort.Len() == 0 || sortPushdown) &&
+		params.Min == nil && params.Max == nil {
 		qp.Limit = params.Limit
+		qp.Offset = params.Skip
 	}
 
 	cancel := func() {}
 	if params.MaxTimeMS != 0 {
-		// It is not clear if maxTimeMS affects only find, or both find and getMore (as the current code does).
+		// Like in MongoDB, maxTimeMS limits both find and getMore commands for the created cursor;
+		// unlike MongoDB, the time between getMore commands is counted too.
 		// TODO https://github.com/FerretDB/FerretDB/issues/2983
 		ctx, cancel = context.WithTimeout(ctx, time.Duration(params.MaxTimeMS)*time.Millisecond)
 	}
@@ -97,6 +135,33 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 	err = dbPool.InTransactionKeep(ctx, func(tx pgx.Tx) error {
 		keepTx = tx
 
+		var hintIndex *common.HintIndex
+		var search *common.TextSearch
+
+		if params.ReturnKey || params.Min != nil || params.Max != nil || text != nil {
+			var indexes []pgdb.Index
+			indexes, err = pgdb.Indexes(ctx, tx, params.DB, params.Collection)
+
+			switch {
+			case err == nil:
+				if params.ReturnKey || params.Min != nil || params.Max != nil {
+					if hintIndex, err = common.GetHintIndex(params.Hint, hintIndexes(indexes)); err != nil {
+						return err
+					}
+				}
+
+				if text != nil {
+					if search, err = common.NewTextSearch(text, textIndex(indexes)); err != nil {
+						return err
+					}
+				}
+			case errors.Is(err, pgdb.ErrTableNotExist):
+				// no documents will be returned, so there is nothing to check
+			default:
+				return lazyerrors.Error(err)
+			}
+		}
+
 		var queryRes pgdb.QueryResults
 		iter, queryRes, err = pgdb.QueryDocuments(ctx, tx, qp)
 		if err != nil {
@@ -105,10 +170,33 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 
 		closer.Add(iter)
 
-		iter = common.FilterIterator(iter, closer, params.Filter)
+		iter = common.FilterIterator(iter, closer, filter, collation)
+
+		if search != nil {
+			iter = common.TextIterator(iter, closer, search)
+		}
+
+		// documents matching $near and $nearSphere are returned from nearest to farthest unless sorted
+		if params.Sort.Len() == 0 {
+			if iter, err = common.GeoNearIterator(iter, closer, params.Filter); err != nil {
+				return err
+			}
+		}
+
+		if hintIndex != nil {
+			iter, err = common.MinMaxIterator(iter, closer, hintIndex.Key, params.Min, params.Max)
+			if err != nil {
+				return err
+			}
+
+			// documents are returned in the index order
+			if (params.Min != nil || params.Max != nil) && params.Sort.Len() == 0 && params.Natural == 0 {
+				params.Sort = common.IndexKeySort(hintIndex.Key)
+			}
+		}
 
 		if !queryRes.SortPushdown {
-			iter, err = common.SortIterator(iter, closer, params.Sort)
+			iter, err = common.SortIterator(iter, closer, params.Sort, collation)
 			if err != nil {
 				var pathErr *types.PathError
 				if errors.As(err, &pathErr) && pathErr.Code() == types.ErrPathElementEmpty {
@@ -123,13 +211,28 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 			}
 		}
 
-		iter = common.SkipIterator(iter, closer, params.Skip)
+		if !queryRes.OffsetPushdown {
+			iter = common.SkipIterator(iter, closer, params.Skip)
+		}
 
 		iter = common.LimitIterator(iter, closer, params.Limit)
 
-		iter, err = common.ProjectionIterator(iter, closer, params.Projection, params.Filter)
-		if err != nil {
-			return lazyerrors.Error(err)
+		if params.ReturnKey {
+			var key *types.Document
+			if hintIndex != nil {
+				key = hintIndex.Key
+			}
+
+			iter = common.ReturnKeyIterator(iter, closer, key)
+		} else {
+			iter, err = common.ProjectionIterator(iter, closer, params.Projection, params.Filter)
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
+		if params.ShowRecordId {
+			iter = common.RecordIDIterator(iter, closer)
 		}
 
 		return nil
@@ -137,7 +240,7 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 
 	if err != nil {
 		closer.Close()
-		return nil, lazyerrors.Error(err)
+		return nil, common.CheckMaxTimeMS(err)
 	}
 
 	closer.Add(iterator.CloserFunc(func() {
@@ -147,16 +250,22 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		_ = keepTx.Rollback(context.Background())
 	}))
 
+	comment, _ := document.Get("comment")
+
 	cursor := h.cursors.NewCursor(ctx, &cursor.NewParams{
-		Iter:       iterator.WithClose(iterator.Interface[struct{}, *types.Document](iter), closer.Close),
-		DB:         params.DB,
-		Collection: params.Collection,
-		Username:   username,
+		Iter:               iterator.WithClose(iterator.Interface[struct{}, *types.Document](iter), closer.Close),
+		DB:                 params.DB,
+		Collection:         params.Collection,
+		Username:           username,
+		Client:             conninfo.Get(ctx).PeerAddr,
+		OriginatingCommand: document.DeepCopy(),
+		Comment:            comment,
+		QueryShapeHash:     common.QueryShapeHash(document),
 	})
 
 	cursorID := cursor.ID
 
-	firstBatchDocs, err := iterator.ConsumeValuesN(iterator.Interface[struct{}, *types.Document](cursor), int(params.BatchSize))
+	firstBatchDocs, done, err := common.ConsumeBatch(cursor, int(params.BatchSize))
 	if err != nil {
 		cursor.Close()
 		return nil, lazyerrors.Error(err)
@@ -167,7 +276,7 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		firstBatch.Append(doc)
 	}
 
-	if params.SingleBatch || firstBatch.Len() < int(params.BatchSize) {
+	if params.SingleBatch || done {
 		// Support tailable cursors.
 		// TODO https://github.com/FerretDB/FerretDB/issues/2283
 
@@ -183,7 +292,7 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 			"cursor", must.NotFail(types.NewDocument(
 				"firstBatch", firstBatch,
 				"id", cursorID,
-				"ns", qp.DB+"."+qp.Collection,
+				"ns", cursor.NS(),
 			)),
 			"ok", float64(1),
 		))},
@@ -217,7 +326,7 @@ func fetchAndFilterDocs(ctx context.Context, fp *fetchParams) ([]*types.Document
 	closer := iterator.NewMultiCloser(iter)
 	defer closer.Close()
 
-	f := common.FilterIterator(iter, closer, filter)
+	f := common.FilterIterator(iter, closer, filter, nil)
 
 	return iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](f))
 }
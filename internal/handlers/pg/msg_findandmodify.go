@@ -18,7 +18,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"time"
 
 	"github.com/jackc/pgx/v5"
 
@@ -54,12 +53,8 @@ func (h *Handler) MsgFindAndModify(ctx context.Context, msg *wire.OpMsg) (*wire.
 		}
 	}
 
-	if params.MaxTimeMS != 0 {
-		ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Duration(params.MaxTimeMS)*time.Millisecond)
-		defer cancel()
-
-		ctx = ctxWithTimeout
-	}
+	ctx, cancel := common.WithMaxTimeMS(ctx, params.MaxTimeMS)
+	defer cancel()
 
 	qp := pgdb.QueryParams{
 		DB:         params.DB,
@@ -78,7 +73,7 @@ func (h *Handler) MsgFindAndModify(ctx context.Context, msg *wire.OpMsg) (*wire.
 			return err
 		}
 
-		if err = common.SortDocuments(resDocs, params.Sort); err != nil {
+		if err = common.SortDocuments(resDocs, params.Sort, nil); err != nil {
 			var pathErr *types.PathError
 			if errors.As(err, &pathErr) && pathErr.Code() == types.ErrPathElementEmpty {
 				return commonerrors.NewCommandErrorMsgWithArgument(
@@ -96,7 +91,7 @@ func (h *Handler) MsgFindAndModify(ctx context.Context, msg *wire.OpMsg) (*wire.
 			return err
 		}
 
-		if params.Update != nil { // we have update part
+		if params.Update != nil || params.Aggregation != nil { // we have update part
 			var resValue any
 			var insertedID any
 
@@ -128,9 +123,8 @@ func (h *Handler) MsgFindAndModify(ctx context.Context, msg *wire.OpMsg) (*wire.
 				// TODO https://github.com/FerretDB/FerretDB/issues/3040
 				var upsert *types.Document
 
-				if params.HasUpdateOperators {
-					upsert = resDocs[0].DeepCopy()
-					_, err = common.UpdateDocument(document.Command(), upsert, params.Update)
+				if params.Aggregation != nil || params.HasUpdateOperators {
+					upsert, err = common.UpdateDocumentWithParams(document.Command(), resDocs[0], params)
 					if err != nil {
 						return err
 					}
@@ -153,6 +147,10 @@ func (h *Handler) MsgFindAndModify(ctx context.Context, msg *wire.OpMsg) (*wire.
 				}
 			}
 
+			if resValue, err = common.ProjectFindAndModifyValue(resValue, params); err != nil {
+				return err
+			}
+
 			lastErrorObject := must.NotFail(types.NewDocument(
 				"n", int32(1),
 				"updatedExisting", len(resDocs) > 0,
@@ -191,10 +189,16 @@ func (h *Handler) MsgFindAndModify(ctx context.Context, msg *wire.OpMsg) (*wire.
 				return err
 			}
 
+			var resValue any
+
+			if resValue, err = common.ProjectFindAndModifyValue(resDocs[0], params); err != nil {
+				return err
+			}
+
 			must.NoError(reply.SetSections(wire.OpMsgSection{
 				Documents: []*types.Document{must.NotFail(types.NewDocument(
 					"lastErrorObject", must.NotFail(types.NewDocument("n", int32(1))),
-					"value", resDocs[0],
+					"value", resValue,
 					"ok", float64(1),
 				))},
 			}))
@@ -205,7 +209,7 @@ func (h *Handler) MsgFindAndModify(ctx context.Context, msg *wire.OpMsg) (*wire.
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, common.CheckMaxTimeMS(err)
 	}
 
 	return &reply, nil
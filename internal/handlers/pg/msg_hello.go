@@ -16,32 +16,61 @@ package pg
 
 import (
 	"context"
-	"time"
 
+	"github.com/FerretDB/FerretDB/internal/clientconn/session"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgHello implements HandlerInterface.
 func (h *Handler) MsgHello(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	doc, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	compression, err := common.HelloCompression(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	mechs, err := common.HelloSASLSupportedMechs(ctx, doc, h.getUser)
+	if err != nil {
+		return nil, err
+	}
+
+	res := must.NotFail(types.NewDocument(
+		"isWritablePrimary", true,
+		// topologyVersion
+		"maxBsonObjectSize", int32(types.MaxDocumentLen),
+		"maxMessageSizeBytes", int32(wire.MaxMsgLen),
+		"maxWriteBatchSize", int32(100000),
+		"localTime", types.Now(),
+		"logicalSessionTimeoutMinutes", int32(session.GetRegistry(ctx).Timeout().Minutes()),
+		"connectionId", int32(42),
+		"minWireVersion", common.MinWireVersion,
+		"maxWireVersion", common.MaxWireVersion,
+		"readOnly", false,
+	))
+
+	if compression.Len() > 0 {
+		res.Set("compression", compression)
+	}
+
+	if mechs != nil {
+		res.Set("saslSupportedMechs", mechs)
+	}
+
+	common.HelloMaintenance(ctx, res, "isWritablePrimary")
+
+	res.Set("ok", float64(1))
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"isWritablePrimary", true,
-			// topologyVersion
-			"maxBsonObjectSize", int32(types.MaxDocumentLen),
-			"maxMessageSizeBytes", int32(wire.MaxMsgLen),
-			"maxWriteBatchSize", int32(100000),
-			"localTime", time.Now(),
-			// logicalSessionTimeoutMinutes
-			"connectionId", int32(42),
-			"minWireVersion", common.MinWireVersion,
-			"maxWireVersion", common.MaxWireVersion,
-			"readOnly", false,
-			"ok", float64(1),
-		))},
+		Documents: []*types.Document{res},
 	}))
 
 	return &reply, nil
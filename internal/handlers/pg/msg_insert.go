@@ -18,6 +18,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
+	"sync"
 
 	"github.com/jackc/pgx/v5"
 
@@ -71,57 +73,171 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	return &reply, nil
 }
 
-// insertMany inserts many documents into the collection one by one.
+// Unordered inserts of many documents are split between several concurrent transactions.
+const (
+	// insertWorkersMax is the maximum number of concurrent transactions.
+	insertWorkersMax = 8
+
+	// insertChunkMin is the minimum number of documents inserted by a single concurrent transaction.
+	insertChunkMin = 100
+)
+
+// insertError represents an error of inserting a single document.
+type insertError struct {
+	err   error
+	index int32
+}
+
+// insertMany inserts many documents into the collection.
 //
 // If insert is ordered, and a document fails to insert, handling of the remaining documents will be stopped.
 // If insert is unordered, a document fails to insert, handling of the remaining documents will be continued.
 //
-// It always returns the number of successfully inserted documents and a document with errors.
+// Large unordered inserts outside of multi-document transactions are split into chunks
+// that are inserted in concurrent transactions.
+//
+// It always returns the number of successfully inserted documents and a document with errors
+// sorted by document index.
 func insertMany(ctx context.Context, dbPool *pgdb.Pool, qp *pgdb.QueryParams, docs *types.Array, ordered bool) (int32, *commonerrors.WriteErrors) { //nolint:lll // argument list is too long
-	var inserted int32
-	var insErrors commonerrors.WriteErrors
+	all := make([]*types.Document, docs.Len())
+	for i := range all {
+		all[i] = must.NotFail(docs.Get(i)).(*types.Document)
+	}
 
-	// attempt to insert all documents in a single transaction
-	err := dbPool.InTransaction(ctx, func(tx pgx.Tx) error {
-		for i := 0; i < docs.Len(); i++ {
-			doc := must.NotFail(docs.Get(i))
+	var workers int
+	if !ordered && !pgdb.HasTransaction(ctx) {
+		workers = insertWorkers(len(all))
+	}
 
-			err := insertDocument(ctx, tx, qp, doc.(*types.Document))
-			if err != nil {
-				return err
-			}
+	// create collection first, so concurrent transactions do not conflict while creating it;
+	// on errors, insert documents in a single chunk to report them
+	if workers > 1 {
+		err := dbPool.InTransactionRetry(ctx, func(tx pgx.Tx) error {
+			_, err := pgdb.CreateCollectionIfNotExists(ctx, tx, qp.DB, qp.Collection)
+			return err
+		})
+		if err != nil {
+			workers = 1
 		}
-		return nil
+	}
+
+	if workers <= 1 {
+		inserted, errs := insertChunk(ctx, dbPool, qp, all, 0, ordered)
+		return inserted, insertWriteErrors(errs)
+	}
+
+	chunkSize := (len(all) + workers - 1) / workers
+
+	inserted := make([]int32, workers)
+	errs := make([][]insertError, workers)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		offset := w * chunkSize
+
+		end := offset + chunkSize
+		if end > len(all) {
+			end = len(all)
+		}
+
+		wg.Add(1)
+
+		go func(w, offset, end int) {
+			defer wg.Done()
+
+			inserted[w], errs[w] = insertChunk(ctx, dbPool, qp, all[offset:end], offset, false)
+		}(w, offset, end)
+	}
+
+	wg.Wait()
+
+	var total int32
+	var allErrs []insertError
+
+	for w := range inserted {
+		total += inserted[w]
+		allErrs = append(allErrs, errs[w]...)
+	}
+
+	return total, insertWriteErrors(allErrs)
+}
+
+// insertWorkers returns the number of concurrent transactions for an unordered insert of n documents.
+func insertWorkers(n int) int {
+	res := n / insertChunkMin
+
+	if procs := runtime.GOMAXPROCS(-1); res > procs {
+		res = procs
+	}
+
+	if res > insertWorkersMax {
+		res = insertWorkersMax
+	}
+
+	return res
+}
+
+// insertChunk inserts documents into the collection.
+// Offset is the index of the first document in the whole insert.
+//
+// All documents are inserted in a single transaction first.
+// If it fails, documents are inserted one by one to find out which of them could not be inserted;
+// if insert is ordered, the first error stops that.
+//
+// It returns the number of successfully inserted documents and errors with document indexes.
+func insertChunk(ctx context.Context, dbPool *pgdb.Pool, qp *pgdb.QueryParams, docs []*types.Document, offset int, ordered bool) (int32, []insertError) { //nolint:lll // argument list is too long
+	for _, doc := range docs {
+		if !doc.Has("_id") {
+			doc.Set("_id", types.NewObjectID())
+		}
+	}
+
+	// attempt to insert all documents in a single transaction
+	err := dbPool.InTransaction(ctx, func(tx pgx.Tx) error {
+		return pgdb.InsertDocuments(ctx, tx, qp.DB, qp.Collection, docs)
 	})
+	if err == nil {
+		return int32(len(docs)), nil
+	}
+
 	// if transaction fails with err
 	// try inserting one document at a time
-	if err != nil {
-		for i := 0; i < docs.Len(); i++ {
-			doc := must.NotFail(docs.Get(i)).(*types.Document)
-
-			err := insertDocumentSeparately(ctx, dbPool, qp, doc)
-
-			var we *commonerrors.WriteErrors
-
-			switch {
-			case err == nil:
-				inserted++
-				continue
-			case errors.As(err, &we):
-				insErrors.Merge(we, int32(i))
-			default:
-				insErrors.Append(err, int32(i))
-			}
-
-			if ordered {
-				return inserted, &insErrors
-			}
+	var inserted int32
+	var errs []insertError
+
+	for i, doc := range docs {
+		err := insertDocumentSeparately(ctx, dbPool, qp, doc)
+		if err == nil {
+			inserted++
+			continue
 		}
 
-		return inserted, &insErrors
+		errs = append(errs, insertError{err: err, index: int32(offset + i)})
+
+		if ordered {
+			break
+		}
 	}
 
-	return int32(docs.Len()), &insErrors
+	return inserted, errs
+}
+
+// insertWriteErrors converts insert errors to write errors.
+func insertWriteErrors(errs []insertError) *commonerrors.WriteErrors {
+	var insErrors commonerrors.WriteErrors
+
+	for _, e := range errs {
+		var we *commonerrors.WriteErrors
+		if errors.As(e.err, &we) {
+			insErrors.Merge(we, e.index)
+			continue
+		}
+
+		insErrors.Append(e.err, e.index)
+	}
+
+	return &insErrors
 }
 
 // insertDocument prepares and executes actual INSERT request to Postgres in provided transaction.
@@ -150,6 +266,19 @@ func insertDocument(ctx context.Context, tx pgx.Tx, qp *pgdb.QueryParams, doc *t
 		)
 
 	default:
+		var pae *pgdb.ParallelArraysError
+		if errors.As(err, &pae) {
+			return commonerrors.NewWriteErrorMsg(commonerrors.ErrCannotIndexParallelArrays, pae.Error())
+		}
+
+		if errors.Is(err, pgdb.ErrHashedArray) {
+			return commonerrors.NewWriteErrorMsg(commonerrors.ErrHashedIndexArray, pgdb.ErrHashedArray.Error())
+		}
+
+		if errors.Is(err, pgdb.ErrGeoKeys) {
+			return commonerrors.NewWriteErrorMsg(commonerrors.ErrCannotExtractGeoKeys, pgdb.ErrGeoKeys.Error())
+		}
+
 		var ve *types.ValidationError
 
 		if !errors.As(err, &ve) {
@@ -20,6 +20,8 @@ import (
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
@@ -87,25 +89,8 @@ func (h *Handler) MsgListIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.Op
 
 	firstBatch := types.MakeArray(len(indexes))
 
-	for _, index := range indexes {
-		indexKey := must.NotFail(types.NewDocument())
-
-		for _, key := range index.Key {
-			indexKey.Set(key.Field, int32(key.Order))
-		}
-
-		indexDoc := must.NotFail(types.NewDocument(
-			"v", int32(2),
-			"key", indexKey,
-			"name", index.Name,
-		))
-
-		// only non-default unique indexes should have unique field in the response
-		if index.Unique != nil && *index.Unique && index.Name != "_id_" {
-			indexDoc.Set("unique", *index.Unique)
-		}
-
-		firstBatch.Append(indexDoc)
+	for i := range indexes {
+		firstBatch.Append(indexDocument(&indexes[i]))
 	}
 
 	var reply wire.OpMsg
@@ -122,3 +107,114 @@ func (h *Handler) MsgListIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.Op
 
 	return &reply, nil
 }
+
+// indexDocument returns the index specification document as it is returned by listIndexes.
+func indexDocument(index *pgdb.Index) *types.Document {
+	indexDoc := must.NotFail(types.NewDocument(
+		"v", int32(2),
+		"key", indexKeyDocument(index.Key),
+		"name", index.Name,
+	))
+
+	// only non-default unique indexes should have unique field in the response
+	if index.Unique != nil && *index.Unique && index.Name != "_id_" {
+		indexDoc.Set("unique", *index.Unique)
+	}
+
+	if index.Hidden {
+		indexDoc.Set("hidden", true)
+	}
+
+	if slices.ContainsFunc(index.Key, func(p pgdb.IndexKeyPair) bool { return p.Sphere }) {
+		indexDoc.Set("2dsphereIndexVersion", int32(3))
+	}
+
+	if index.Text != nil {
+		fields := maps.Keys(index.Text.Weights)
+		slices.Sort(fields)
+
+		weights := types.MakeDocument(len(fields))
+		for _, field := range fields {
+			weights.Set(field, index.Text.Weights[field])
+		}
+
+		indexDoc.Set("weights", weights)
+		indexDoc.Set("default_language", index.Text.DefaultLanguage)
+		indexDoc.Set("language_override", index.Text.LanguageOverride)
+		indexDoc.Set("textIndexVersion", int32(3))
+	}
+
+	if index.Collation != nil {
+		indexDoc.Set("collation", index.Collation)
+	}
+
+	return indexDoc
+}
+
+// indexKeyDocument returns the index key pattern document as it is specified by the client.
+//
+// Like MongoDB, text index fields are replaced by `{_fts: "text", _ftsx: 1}`;
+// they are returned in the weights field of the index instead.
+func indexKeyDocument(key pgdb.IndexKey) *types.Document {
+	res := must.NotFail(types.NewDocument())
+
+	for _, pair := range key {
+		if pair.Text {
+			if !res.Has("_fts") {
+				res.Set("_fts", "text")
+				res.Set("_ftsx", int32(1))
+			}
+
+			continue
+		}
+
+		if pair.Hashed {
+			res.Set(pair.Field, "hashed")
+			continue
+		}
+
+		if pair.Sphere {
+			res.Set(pair.Field, "2dsphere")
+			continue
+		}
+
+		res.Set(pair.Field, int32(pair.Order))
+	}
+
+	return res
+}
+
+// hintIndexes returns non-hidden indexes that could be selected by the hint.
+func hintIndexes(indexes []pgdb.Index) []common.HintIndex {
+	res := make([]common.HintIndex, 0, len(indexes))
+
+	for _, index := range indexes {
+		if index.Hidden {
+			continue
+		}
+
+		res = append(res, common.HintIndex{
+			Name: index.Name,
+			Key:  indexKeyDocument(index.Key),
+		})
+	}
+
+	return res
+}
+
+// textIndex returns the non-hidden text index for the $text query operator, or nil if there is none.
+func textIndex(indexes []pgdb.Index) *common.TextIndex {
+	for _, index := range indexes {
+		if index.Hidden || index.Text == nil {
+			continue
+		}
+
+		return &common.TextIndex{
+			Weights:          index.Text.Weights,
+			DefaultLanguage:  index.Text.DefaultLanguage,
+			LanguageOverride: index.Text.LanguageOverride,
+		}
+	}
+
+	return nil
+}
@@ -16,6 +16,7 @@ package pg
 
 import (
 	"context"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 
@@ -34,7 +35,7 @@ func (h *Handler) MsgServerStatus(ctx context.Context, msg *wire.OpMsg) (*wire.O
 		return nil, lazyerrors.Error(err)
 	}
 
-	res, err := common.ServerStatus(h.StateProvider.Get(), h.ConnMetrics)
+	res, err := common.ServerStatus(ctx, h.StateProvider.Get(), h.ConnMetrics)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
@@ -58,6 +59,28 @@ func (h *Handler) MsgServerStatus(ctx context.Context, msg *wire.OpMsg) (*wire.O
 		"internalViews", int32(0),
 	)))
 
+	// keep only the version number, like getLog's startupWarnings
+	version, _, _ := strings.Cut(h.StateProvider.Get().HandlerVersion, " ")
+	stat := dbPool.Stat()
+
+	bs := &common.BackendStatus{
+		Name:             "PostgreSQL",
+		Version:          version,
+		PoolMaxOpen:      int(stat.MaxConns()),
+		PoolOpen:         int(stat.TotalConns()),
+		PoolInUse:        int(stat.AcquiredConns()),
+		PoolIdle:         int(stat.IdleConns()),
+		PoolWaitCount:    stat.EmptyAcquireCount(),
+		PoolWaitDuration: stat.AcquireDuration(), // pgxpool does not track the waiting time separately
+	}
+
+	if last := dbPool.LastError(); last != nil {
+		bs.LastError = last.Err
+		bs.LastErrorTime = last.Time
+	}
+
+	common.SetBackendStatus(res, bs)
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{res},
@@ -47,6 +47,16 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, lazyerrors.Error(err)
 	}
 
+	// explicit collation of updates is supported by the SQLite handler only
+	for _, u := range params.Updates {
+		if u.Collation != nil {
+			return nil, unimplementedCollation(document.Command(), u.Collation)
+		}
+	}
+
+	ctx, cancel := common.WithMaxTimeMS(ctx, params.MaxTimeMS)
+	defer cancel()
+
 	err = dbPool.InTransactionRetry(ctx, func(tx pgx.Tx) error {
 		_, err = pgdb.CreateCollectionIfNotExists(ctx, tx, params.DB, params.Collection)
 		return err
@@ -159,7 +169,7 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	})
 
 	if err != nil {
-		return nil, lazyerrors.Error(err)
+		return nil, common.CheckMaxTimeMS(err)
 	}
 
 	res := must.NotFail(types.NewDocument(
@@ -190,6 +200,26 @@ func updateDocument(ctx context.Context, tx pgx.Tx, qp *pgdb.QueryParams, doc *t
 		return res, nil
 	}
 
+	if errors.Is(err, pgdb.ErrUniqueViolation) {
+		return 0, commonerrors.NewWriteErrorMsg(
+			commonerrors.ErrDuplicateKeyInsert,
+			fmt.Sprintf(`E11000 duplicate key error collection: %s.%s`, qp.DB, qp.Collection),
+		)
+	}
+
+	var pae *pgdb.ParallelArraysError
+	if errors.As(err, &pae) {
+		return 0, commonerrors.NewWriteErrorMsg(commonerrors.ErrCannotIndexParallelArrays, pae.Error())
+	}
+
+	if errors.Is(err, pgdb.ErrHashedArray) {
+		return 0, commonerrors.NewWriteErrorMsg(commonerrors.ErrHashedIndexArray, pgdb.ErrHashedArray.Error())
+	}
+
+	if errors.Is(err, pgdb.ErrGeoKeys) {
+		return 0, commonerrors.NewWriteErrorMsg(commonerrors.ErrCannotExtractGeoKeys, pgdb.ErrGeoKeys.Error())
+	}
+
 	var ve *types.ValidationError
 
 	if !errors.As(err, &ve) {
@@ -205,3 +235,12 @@ func updateDocument(ctx context.Context, tx pgx.Tx, qp *pgdb.QueryParams, doc *t
 		panic(fmt.Sprintf("Unknown error code: %v", ve.Code()))
 	}
 }
+
+// unimplementedCollation returns an error for the explicit collation of the update or delete statement.
+func unimplementedCollation(command string, collation *types.Document) error {
+	return commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrNotImplemented,
+		fmt.Sprintf("%s: support for field %q with value %v is not implemented yet", command, "collation", collation),
+		"collation",
+	)
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
 	"github.com/FerretDB/FerretDB/internal/clientconn/cursor"
 	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/state"
@@ -37,8 +38,14 @@ import (
 type Handler struct {
 	*NewOpts
 
-	url     url.URL
-	cursors *cursor.Registry
+	url         url.URL
+	cursors     *cursor.Registry
+	maintenance *maintenance
+	parameters  *common.Parameters
+
+	// stops maintenance goroutine
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 
 	// accessed by DBPool(ctx)
 	rw    sync.RWMutex
@@ -52,6 +59,7 @@ type NewOpts struct {
 	L             *zap.Logger
 	ConnMetrics   *connmetrics.ConnMetrics
 	StateProvider *state.Provider
+	NumericTypes  common.NumericTypes
 
 	// test options
 	DisableFilterPushdown bool
@@ -76,11 +84,31 @@ func New(opts *NewOpts) (handlers.Interface, error) {
 		pools:   make(map[string]*pgdb.Pool, 1),
 	}
 
+	h.maintenance = newMaintenance(h)
+
+	h.parameters = common.NewParameters()
+	for name, p := range h.maintenance.parameters() {
+		h.parameters.Add(name, p)
+	}
+
+	var ctx context.Context
+	ctx, h.cancel = context.WithCancel(context.Background())
+
+	h.wg.Add(1)
+
+	go func() {
+		defer h.wg.Done()
+		h.maintenance.run(ctx)
+	}()
+
 	return h, nil
 }
 
 // Close implements HandlerInterface.
 func (h *Handler) Close() {
+	h.cancel()
+	h.wg.Wait()
+
 	h.rw.Lock()
 	defer h.rw.Unlock()
 
@@ -146,6 +174,20 @@ func (h *Handler) DBPool(ctx context.Context) (*pgdb.Pool, error) {
 	return p, nil
 }
 
+// anyPool returns any existing connection pool, or nil if there are none.
+//
+// It is used for background tasks that are not associated with any client connection.
+func (h *Handler) anyPool() *pgdb.Pool {
+	h.rw.RLock()
+	defer h.rw.RUnlock()
+
+	for _, p := range h.pools {
+		return p
+	}
+
+	return nil
+}
+
 // Describe implements handlers.Interface.
 func (h *Handler) Describe(ch chan<- *prometheus.Desc) {
 	// TODO
@@ -23,6 +23,8 @@ import (
 	"regexp"
 
 	"github.com/jackc/pgx/v5"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/iterator"
@@ -63,12 +65,43 @@ type metadata struct {
 	collection string // _id
 	table      string
 	indexes    []metadataIndex
+
+	// dataSize is the total BSON size of all collection documents, updated on every write.
+	// It is nil for collections created by older versions until it is computed by getDataSize.
+	dataSize *int64
+}
+
+// pushdownIndexes returns information about collection indexes that is used for filter pushdown.
+func (m *metadata) pushdownIndexes() *pushdownIndexes {
+	res := new(pushdownIndexes)
+
+	for _, idx := range m.indexes {
+		if idx.Hidden {
+			continue
+		}
+
+		if prefix, ok := idx.Key.Wildcard(); ok {
+			res.wildcards = append(res.wildcards, prefix)
+			continue
+		}
+
+		for _, pair := range idx.Key {
+			if pair.Hashed {
+				res.hashed = append(res.hashed, pair.Field)
+			}
+		}
+	}
+
+	return res
 }
 
 // metadataIndex stores information about FerretDB index.
 type metadataIndex struct {
 	pgIndex string
 	Index
+
+	// multikey is true if some document has an array value for some indexed field.
+	multikey bool
 }
 
 // newMetadataStorage returns a new instance of metadata for the given transaction, database and collection names.
@@ -167,6 +200,7 @@ func (ms *metadataStorage) store(ctx context.Context) (tableName string, created
 		collection: ms.collection,
 		table:      tableName,
 		indexes:    []metadataIndex{},
+		dataSize:   new(int64),
 	}
 
 	err = insert(ctx, ms.tx, &insertParams{
@@ -332,10 +366,18 @@ func documentToMetadata(doc *types.Document) (*metadata, error) {
 		indexes[i] = *idx
 	}
 
+	// dataSize field is absent for collections created by older versions
+	var dataSize *int64
+	if v, err := doc.Get("dataSize"); err == nil {
+		size := v.(int64)
+		dataSize = &size
+	}
+
 	return &metadata{
 		collection: must.NotFail(doc.Get("_id")).(string),
 		table:      must.NotFail(doc.Get("table")).(string),
 		indexes:    indexes,
+		dataSize:   dataSize,
 	}, nil
 }
 
@@ -352,6 +394,36 @@ func documentToMetadataIndex(doc *types.Document) (*metadataIndex, error) {
 
 		switch {
 		case err == nil:
+			if value == hashedIndexValue {
+				key[i] = IndexKeyPair{
+					Field:  field,
+					Order:  types.Ascending,
+					Hashed: true,
+				}
+
+				continue
+			}
+
+			if value == sphereIndexValue {
+				key[i] = IndexKeyPair{
+					Field:  field,
+					Order:  types.Ascending,
+					Sphere: true,
+				}
+
+				continue
+			}
+
+			if value == textIndexValue {
+				key[i] = IndexKeyPair{
+					Field: field,
+					Order: types.Ascending,
+					Text:  true,
+				}
+
+				continue
+			}
+
 			key[i] = IndexKeyPair{
 				Field: field,
 				Order: types.SortType(value.(int32)),
@@ -366,13 +438,48 @@ func documentToMetadataIndex(doc *types.Document) (*metadataIndex, error) {
 		unique = &u
 	}
 
+	// multikey and hidden fields are absent for indexes created by older versions
+	var multikey, hidden bool
+	if m, err := doc.Get("multikey"); err == nil {
+		multikey, _ = m.(bool)
+	}
+
+	if h, err := doc.Get("hidden"); err == nil {
+		hidden, _ = h.(bool)
+	}
+
+	var collation *types.Document
+	if c, err := doc.Get("collation"); err == nil {
+		collation, _ = c.(*types.Document)
+	}
+
+	var text *TextIndexOptions
+
+	if w, err := doc.Get("weights"); err == nil {
+		weights := w.(*types.Document)
+
+		text = &TextIndexOptions{
+			Weights:          make(map[string]int32, weights.Len()),
+			DefaultLanguage:  must.NotFail(doc.Get("default_language")).(string),
+			LanguageOverride: must.NotFail(doc.Get("language_override")).(string),
+		}
+
+		for _, field := range weights.Keys() {
+			text.Weights[field] = must.NotFail(weights.Get(field)).(int32)
+		}
+	}
+
 	return &metadataIndex{
 		Index: Index{
-			Name:   must.NotFail(doc.Get("name")).(string),
-			Key:    key,
-			Unique: unique,
+			Name:      must.NotFail(doc.Get("name")).(string),
+			Key:       key,
+			Unique:    unique,
+			Hidden:    hidden,
+			Text:      text,
+			Collation: collation,
 		},
-		pgIndex: must.NotFail(doc.Get("pgindex")).(string),
+		pgIndex:  must.NotFail(doc.Get("pgindex")).(string),
+		multikey: multikey,
 	}, nil
 }
 
@@ -390,6 +497,78 @@ func (ms *metadataStorage) set(ctx context.Context, metadata *metadata) error {
 	return nil
 }
 
+// setMultikey marks indexes with the given names as multikey.
+func (ms *metadataStorage) setMultikey(ctx context.Context, indexes []string) error {
+	metadata, err := ms.get(ctx, true)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	for i := range metadata.indexes {
+		for _, name := range indexes {
+			if metadata.indexes[i].Name == name {
+				metadata.indexes[i].multikey = true
+			}
+		}
+	}
+
+	return ms.set(ctx, metadata)
+}
+
+// addDataSize adds the given delta to the total BSON size of collection documents.
+//
+// It does nothing if the size is not tracked yet; getDataSize computes it from scratch in that case.
+func (ms *metadataStorage) addDataSize(ctx context.Context, delta int64) error {
+	if delta == 0 {
+		return nil
+	}
+
+	metadata, err := ms.get(ctx, true)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if metadata.dataSize == nil {
+		return nil
+	}
+
+	*metadata.dataSize += delta
+
+	return ms.set(ctx, metadata)
+}
+
+// getDataSize returns the total BSON size of collection documents.
+//
+// For collections created by older versions, it computes the size and stores it in the metadata,
+// so subsequent writes keep it up-to-date.
+func (ms *metadataStorage) getDataSize(ctx context.Context) (int64, error) {
+	metadata, err := ms.get(ctx, false)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	if metadata.dataSize != nil {
+		return *metadata.dataSize, nil
+	}
+
+	if metadata, err = ms.get(ctx, true); err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	size, err := documentsSize(ctx, ms.tx, ms.db, metadata.table, nil)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	metadata.dataSize = &size
+
+	if err = ms.set(ctx, metadata); err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	return size, nil
+}
+
 // metadataToDocument converts metadata to *types.Document.
 // Use this function to transform metadata to document to be stored in the database.
 func metadataToDocument(metadata *metadata) *types.Document {
@@ -398,6 +577,21 @@ func metadataToDocument(metadata *metadata) *types.Document {
 	for _, idx := range metadata.indexes {
 		keyDoc := types.MakeDocument(len(idx.Key))
 		for _, pair := range idx.Key {
+			if pair.Hashed {
+				keyDoc.Set(pair.Field, hashedIndexValue)
+				continue
+			}
+
+			if pair.Sphere {
+				keyDoc.Set(pair.Field, sphereIndexValue)
+				continue
+			}
+
+			if pair.Text {
+				keyDoc.Set(pair.Field, textIndexValue)
+				continue
+			}
+
 			keyDoc.Set(pair.Field, int32(pair.Order)) // order is set as int32 to be sjson-marshaled correctly
 		}
 
@@ -409,19 +603,47 @@ func metadataToDocument(metadata *metadata) *types.Document {
 			unique = *idx.Unique
 		}
 
-		indexesArr.Append(must.NotFail(types.NewDocument(
+		indexDoc := must.NotFail(types.NewDocument(
 			"pgindex", idx.pgIndex,
 			"name", idx.Name,
 			"key", keyDoc,
 			"unique", unique,
-		)))
+			"multikey", idx.multikey,
+			"hidden", idx.Hidden,
+		))
+
+		if idx.Text != nil {
+			fields := maps.Keys(idx.Text.Weights)
+			slices.Sort(fields)
+
+			weights := types.MakeDocument(len(fields))
+			for _, field := range fields {
+				weights.Set(field, idx.Text.Weights[field])
+			}
+
+			indexDoc.Set("weights", weights)
+			indexDoc.Set("default_language", idx.Text.DefaultLanguage)
+			indexDoc.Set("language_override", idx.Text.LanguageOverride)
+		}
+
+		if idx.Collation != nil {
+			indexDoc.Set("collation", idx.Collation)
+		}
+
+		indexesArr.Append(indexDoc)
 	}
 
-	return must.NotFail(types.NewDocument(
+	doc := must.NotFail(types.NewDocument(
 		"_id", metadata.collection,
 		"table", metadata.table,
 		"indexes", indexesArr,
 	))
+
+	if metadata.dataSize != nil {
+		doc.Set("dataSize", *metadata.dataSize)
+	}
+
+	return doc
 }
 
 // remove removes metadata.
@@ -471,21 +693,17 @@ func collectionNameToTableName(name string) string {
 //   - ErrTableNotExist - if the metadata table doesn't exist.
 //   - ErrIndexKeyAlreadyExist - if the given index key already exists.
 //   - ErrIndexNameAlreadyExist - if the given index name already exists.
-func (ms *metadataStorage) setIndex(ctx context.Context, index string, key IndexKey, unique *bool) (pgTable string, pgIndex string, err error) { //nolint:lll // for readability
+func (ms *metadataStorage) setIndex(ctx context.Context, index *Index) (pgTable string, pgIndex string, err error) {
 	metadata, err := ms.get(ctx, true)
 	if err != nil {
 		return
 	}
 
 	pgTable = metadata.table
-	pgIndex = indexNameToPgIndexName(ms.collection, index)
+	pgIndex = indexNameToPgIndexName(ms.collection, index.Name)
 
 	newIndex := metadataIndex{
-		Index: Index{
-			Name:   index,
-			Key:    key,
-			Unique: unique,
-		},
+		Index:   *index,
 		pgIndex: pgIndex,
 	}
 
@@ -533,6 +751,11 @@ func checkExistingIndex(existing *metadataIndex, new *metadataIndex) (bool, erro
 		indexNameMatch = true
 	}
 
+	// collection could have only one text index, and all of them have the same MongoDB key
+	if existing.Text != nil && new.Text != nil && !indexNameMatch {
+		return false, ErrIndexKeyAlreadyExist
+	}
+
 	if len(existing.Key) != len(new.Key) {
 		if indexNameMatch {
 			return false, ErrIndexNameAlreadyExist
@@ -15,12 +15,16 @@
 package pgdb
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/util/testutil"
 )
 
@@ -62,3 +66,167 @@ func TestDatabaseMetadata(t *testing.T) {
 	})
 	require.NoError(t, err)
 }
+
+func TestMetadataPushdownIndexes(t *testing.T) {
+	t.Parallel()
+
+	m := &metadata{
+		indexes: []metadataIndex{
+			{Index: Index{Name: "_id_", Key: IndexKey{{Field: "_id", Order: types.Ascending}}}},
+			{Index: Index{Name: "a_hashed", Key: IndexKey{{Field: "a", Order: types.Ascending, Hashed: true}}}},
+			{Index: Index{Name: "b_hashed", Key: IndexKey{{Field: "b", Order: types.Ascending, Hashed: true}}, Hidden: true}},
+			{Index: Index{Name: "$**_1", Key: IndexKey{{Field: "$**", Order: types.Ascending}}, Hidden: true}},
+			{Index: Index{Name: "c.$**_1", Key: IndexKey{{Field: "c.$**", Order: types.Ascending}}}},
+		},
+	}
+
+	expected := &pushdownIndexes{
+		wildcards: []string{"c"},
+		hashed:    []string{"a"},
+	}
+	assert.Equal(t, expected, m.pushdownIndexes())
+}
+
+func TestMetadataIndexHiddenRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	m := &metadata{
+		collection: "test",
+		table:      "test_1234",
+		indexes: []metadataIndex{
+			{Index: Index{Name: "v_1", Key: IndexKey{{Field: "v", Order: types.Ascending}}, Hidden: true}, pgIndex: "test_v_1_idx"},
+		},
+	}
+
+	actual, err := documentToMetadata(metadataToDocument(m))
+	require.NoError(t, err)
+	assert.Equal(t, m, actual)
+}
+
+func TestMetadataDataSizeRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	size := int64(42)
+	m := &metadata{
+		collection: "test",
+		table:      "test_1234",
+		indexes:    []metadataIndex{},
+		dataSize:   &size,
+	}
+
+	actual, err := documentToMetadata(metadataToDocument(m))
+	require.NoError(t, err)
+	assert.Equal(t, m, actual)
+}
+
+func TestMetadataDataSize(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t)
+	databaseName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+	setupDatabase(ctx, t, pool, databaseName)
+
+	doc1 := must.NotFail(types.NewDocument("_id", int32(1), "v", "foo"))
+	doc2 := must.NotFail(types.NewDocument("_id", int32(2), "v", int64(42)))
+	doc1Updated := must.NotFail(types.NewDocument("_id", int32(1), "v", "foobarbaz"))
+
+	qp := &QueryParams{DB: databaseName, Collection: collectionName}
+
+	getDataSize := func(t *testing.T) int64 {
+		t.Helper()
+
+		var size int64
+
+		err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+			var err error
+			size, err = newMetadataStorage(tx, databaseName, collectionName).getDataSize(ctx)
+			return err
+		})
+		require.NoError(t, err)
+
+		return size
+	}
+
+	err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+		if err := InsertDocument(ctx, tx, databaseName, collectionName, doc1); err != nil {
+			return err
+		}
+
+		return InsertDocument(ctx, tx, databaseName, collectionName, doc2)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(bson.Size(doc1)+bson.Size(doc2)), getDataSize(t))
+
+	err = pool.InTransaction(ctx, func(tx pgx.Tx) error {
+		_, err := SetDocumentByID(ctx, tx, qp, int32(1), doc1Updated)
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(bson.Size(doc1Updated)+bson.Size(doc2)), getDataSize(t))
+
+	err = pool.InTransaction(ctx, func(tx pgx.Tx) error {
+		_, err := DeleteDocumentsByID(ctx, tx, qp, []any{int32(1), int32(2)})
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), getDataSize(t))
+}
+
+func TestMetadataDataSizeConcurrentInserts(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t)
+	databaseName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+	setupDatabase(ctx, t, pool, databaseName)
+
+	err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+		return CreateCollection(ctx, tx, databaseName, collectionName)
+	})
+	require.NoError(t, err)
+
+	const workers, n = 4, 10
+
+	var expected int64
+	chunks := make([][]*types.Document, workers)
+
+	for w := range chunks {
+		for i := 0; i < n; i++ {
+			doc := must.NotFail(types.NewDocument("_id", int32(w*n+i), "v", "foo"))
+			chunks[w] = append(chunks[w], doc)
+			expected += int64(bson.Size(doc))
+		}
+	}
+
+	var wg sync.WaitGroup
+
+	for _, chunk := range chunks {
+		wg.Add(1)
+
+		go func(chunk []*types.Document) {
+			defer wg.Done()
+
+			err := pool.InTransactionRetry(ctx, func(tx pgx.Tx) error {
+				return InsertDocuments(ctx, tx, databaseName, collectionName, chunk)
+			})
+			assert.NoError(t, err)
+		}(chunk)
+	}
+
+	wg.Wait()
+
+	var size int64
+
+	err = pool.InTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		size, err = newMetadataStorage(tx, databaseName, collectionName).getDataSize(ctx)
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expected, size)
+}
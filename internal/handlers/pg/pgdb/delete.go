@@ -21,22 +21,39 @@ import (
 	"github.com/jackc/pgx/v5"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 // DeleteDocumentsByID deletes documents by given IDs.
 func DeleteDocumentsByID(ctx context.Context, tx pgx.Tx, qp *QueryParams, ids []any) (int64, error) {
-	table, err := newMetadataStorage(tx, qp.DB, qp.Collection).getTableName(ctx)
+	ms := newMetadataStorage(tx, qp.DB, qp.Collection)
+
+	table, err := ms.getTableName(ctx)
 	if err != nil {
 		return 0, err
 	}
 
-	return deleteByIDs(ctx, tx, execDeleteParams{
+	size, err := documentsSize(ctx, tx, qp.DB, table, ids)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	res, err := deleteByIDs(ctx, tx, execDeleteParams{
 		schema:  qp.DB,
 		table:   table,
 		comment: qp.Comment,
 	}, ids,
 	)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = ms.addDataSize(ctx, -size); err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	return res, nil
 }
 
 // execDeleteParams describes the parameters for deleting from a table.
@@ -23,6 +23,7 @@ import (
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
@@ -32,7 +33,19 @@ import (
 type Index struct {
 	Name   string
 	Key    IndexKey
-	Unique *bool // we have to use pointer to determine whether the field was set or not
+	Unique *bool             // we have to use pointer to determine whether the field was set or not
+	Hidden bool              // hidden indexes are maintained, but not used for query planning
+	Text   *TextIndexOptions // set for text indexes only
+
+	// Collation is the full collation document of the index, nil for the simple binary comparison.
+	Collation *types.Document
+}
+
+// TextIndexOptions contains options of the text index.
+type TextIndexOptions struct {
+	Weights          map[string]int32 // weights of all indexed fields, including ones set by the weights option only
+	DefaultLanguage  string
+	LanguageOverride string
 }
 
 // IndexKey is a list of field name + sort order pairs.
@@ -40,8 +53,53 @@ type IndexKey []IndexKeyPair
 
 // IndexKeyPair consists of a field name and a sort order that are part of the index.
 type IndexKeyPair struct {
-	Field string
-	Order types.SortType
+	Field  string
+	Order  types.SortType
+	Hashed bool // if true, the field value's hash is indexed; Order is always ascending
+	Sphere bool // if true, the field value is indexed as geometry on a sphere; Order is always ascending
+	Text   bool // if true, string values of the field are indexed for $text queries; Order is always ascending
+}
+
+// hashedIndexValue is the index key value of hashed fields.
+const hashedIndexValue = "hashed"
+
+// sphereIndexValue is the index key value of 2dsphere fields.
+const sphereIndexValue = "2dsphere"
+
+// textIndexValue is the index key value of text fields.
+const textIndexValue = "text"
+
+// wildcardField is the last path element of wildcard index key fields.
+const wildcardField = "$**"
+
+// Wildcard returns true and the path prefix covered by the index if the field is a wildcard one,
+// like `$**` (empty prefix, all fields) or `foo.bar.$**`.
+//
+// Text index field `$**` that indexes all string fields is not a wildcard one.
+func (p IndexKeyPair) Wildcard() (string, bool) {
+	if p.Text {
+		return "", false
+	}
+
+	if p.Field == wildcardField {
+		return "", true
+	}
+
+	prefix, ok := strings.CutSuffix(p.Field, "."+wildcardField)
+	if !ok || prefix == "" || strings.Contains(prefix, wildcardField) {
+		return "", false
+	}
+
+	return prefix, true
+}
+
+// Wildcard returns true and the covered path prefix if the index key consists of a single wildcard field.
+func (k IndexKey) Wildcard() (string, bool) {
+	if len(k) != 1 {
+		return "", false
+	}
+
+	return k[0].Wildcard()
 }
 
 // Indexes returns a list of indexes for the given database and collection.
@@ -75,11 +133,27 @@ func CreateIndexIfNotExists(ctx context.Context, tx pgx.Tx, db, collection strin
 		return false, err
 	}
 
-	pgTable, pgIndex, err := newMetadataStorage(tx, db, collection).setIndex(ctx, i.Name, i.Key, i.Unique)
+	ms := newMetadataStorage(tx, db, collection)
+
+	pgTable, pgIndex, err := ms.setIndex(ctx, i)
 	if err != nil {
 		return false, err
 	}
 
+	// Text indexes exist only in FerretDB metadata: they could not be unique, any document could be indexed,
+	// and $text queries are evaluated by FerretDB itself with stemming that PostgreSQL text search can't reproduce,
+	// so PostgreSQL index would only slow down writes.
+	if i.Text != nil {
+		return collCreated, nil
+	}
+
+	// wildcard indexes could not be unique or compound, so there is nothing to check
+	if _, wildcard := i.Key.Wildcard(); !wildcard {
+		if err = checkExistingIndexKeys(ctx, tx, ms, pgTable, i); err != nil {
+			return false, err
+		}
+	}
+
 	var unique bool
 	if i.Unique != nil {
 		unique = *i.Unique
@@ -107,6 +181,53 @@ func (k IndexKey) Equal(v IndexKey) bool {
 	return true
 }
 
+// SetIndexHidden hides or unhides the index found by name, or by key if name is empty.
+// It returns the previous hidden value.
+//
+// It returns a possibly wrapped error:
+//   - ErrTableNotExist - if the collection doesn't exist.
+//   - ErrIndexNotExist - if the index doesn't exist.
+//   - ErrIndexCannotHide - if the index is the _id index.
+func SetIndexHidden(ctx context.Context, tx pgx.Tx, db, collection string, index *Index, hidden bool) (bool, error) {
+	ms := newMetadataStorage(tx, db, collection)
+
+	metadata, err := ms.get(ctx, true)
+	if err != nil {
+		return false, err
+	}
+
+	for i := range metadata.indexes {
+		current := &metadata.indexes[i]
+
+		if index.Name != "" && current.Name != index.Name {
+			continue
+		}
+
+		if index.Name == "" && !current.Key.Equal(index.Key) {
+			continue
+		}
+
+		if current.Name == "_id_" {
+			return false, ErrIndexCannotHide
+		}
+
+		old := current.Hidden
+		if old == hidden {
+			return old, nil
+		}
+
+		current.Hidden = hidden
+
+		if err = ms.set(ctx, metadata); err != nil {
+			return false, lazyerrors.Error(err)
+		}
+
+		return old, nil
+	}
+
+	return false, ErrIndexNotExist
+}
+
 // DropIndex drops index. If the index was not found, it returns error.
 func DropIndex(ctx context.Context, tx pgx.Tx, db, collection string, index *Index) (int32, error) {
 	ms := newMetadataStorage(tx, db, collection)
@@ -189,6 +310,24 @@ func DropAllIndexes(ctx context.Context, tx pgx.Tx, db, collection string) (int3
 	return nIndexesWas, nil
 }
 
+// ReIndex rebuilds all indexes on the collection, including _id index.
+//
+// If the given collection does not exist, it returns ErrTableNotExist.
+func ReIndex(ctx context.Context, tx pgx.Tx, db, collection string) error {
+	metadata, err := newMetadataStorage(tx, db, collection).get(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	sql := `REINDEX TABLE ` + pgx.Identifier{db, metadata.table}.Sanitize()
+
+	if _, err = tx.Exec(ctx, sql); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
 // createPgIndexIfNotExists creates a new index for the given params if it does not exist.
 func createPgIndexIfNotExists(ctx context.Context, tx pgx.Tx, schema, table, index string, fields IndexKey, isUnique bool) error {
 	if len(fields) == 0 {
@@ -202,6 +341,23 @@ func createPgIndexIfNotExists(ctx context.Context, tx pgx.Tx, schema, table, ind
 		unique = " UNIQUE"
 	}
 
+	// Wildcard indexes use GIN index over the whole document with containment queries,
+	// as documents could have arrays anywhere on the path to the indexed field, including the prefix.
+	if _, wildcard := fields.Wildcard(); wildcard {
+		sql := `CREATE INDEX IF NOT EXISTS ` + pgx.Identifier{index}.Sanitize() +
+			` ON ` + pgx.Identifier{schema, table}.Sanitize() + ` USING GIN (_jsonb jsonb_path_ops)`
+
+		if _, err = tx.Exec(ctx, sql); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	}
+
+	if slices.ContainsFunc(fields, func(p IndexKeyPair) bool { return p.Sphere }) {
+		return createPgSphereIndexIfNotExists(ctx, tx, schema, table, index, fields)
+	}
+
 	fieldsDef := make([]string, len(fields))
 
 	for i, field := range fields {
@@ -216,15 +372,14 @@ func createPgIndexIfNotExists(ctx context.Context, tx pgx.Tx, schema, table, ind
 			return lazyerrors.Errorf("unknown sort order: %d", field.Order)
 		}
 
-		// if the key is foo.bar, then need to modify it to foo -> bar
-		fs := strings.Split(field.Field, ".")
-		transformedParts := make([]string, len(fs))
+		if field.Hashed {
+			// jsonb hash is consistent with jsonb equality, including numbers of different types
+			fieldsDef[i] = fmt.Sprintf(`(jsonb_hash_extended(%s, 0)) %s`, jsonbField(field.Field), order)
 
-		for j, f := range fs {
-			// It's important to sanitize field.Field data here, as it's a user-provided value.
-			transformedParts[j] = quoteString(f)
+			continue
 		}
-		fieldsDef[i] = fmt.Sprintf(`((_jsonb->%s)) %s`, strings.Join(transformedParts, " -> "), order)
+
+		fieldsDef[i] = fmt.Sprintf(`((%s)) %s`, jsonbField(field.Field), order)
 	}
 
 	sql := `CREATE` + unique + ` INDEX IF NOT EXISTS ` + pgx.Identifier{index}.Sanitize() +
@@ -248,11 +403,71 @@ func createPgIndexIfNotExists(ctx context.Context, tx pgx.Tx, schema, table, ind
 	}
 }
 
+// createPgSphereIndexIfNotExists creates a new GiST index for 2dsphere index fields if it does not exist.
+//
+// Such indexes require PostGIS extension to be installed in the database.
+// If it is not, nothing is created, and 2dsphere index exists only in FerretDB metadata;
+// queries work the same way in both cases.
+// Only GeoJSON objects are indexed; legacy coordinate pairs are not.
+func createPgSphereIndexIfNotExists(ctx context.Context, tx pgx.Tx, schema, table, index string, fields IndexKey) error {
+	var postgis bool
+	if err := tx.QueryRow(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'postgis')`,
+	).Scan(&postgis); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if !postgis {
+		return nil
+	}
+
+	// jsonb values do not have GiST operator class, so other fields of compound index are not included
+	var fieldsDef []string
+
+	for _, field := range fields {
+		if !field.Sphere {
+			continue
+		}
+
+		// documents were validated by checkIndexKeys, so all objects are valid GeoJSON
+		fieldsDef = append(fieldsDef, fmt.Sprintf(
+			`(CASE WHEN %[1]s ? 'type' THEN ST_GeomFromGeoJSON(%[1]s)::geography END)`, jsonbField(field.Field),
+		))
+	}
+
+	sql := `CREATE INDEX IF NOT EXISTS ` + pgx.Identifier{index}.Sanitize() +
+		` ON ` + pgx.Identifier{schema, table}.Sanitize() + ` USING GIST (` + strings.Join(fieldsDef, `, `) + `)`
+
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// jsonbField returns SQL expression for the value of the document field with the given dot notation path.
+//
+// For example, the path foo.bar becomes _jsonb->'foo'->'bar'.
+func jsonbField(field string) string {
+	fs := strings.Split(field, ".")
+	transformedParts := make([]string, len(fs))
+
+	for j, f := range fs {
+		// It's important to sanitize field data here, as it's a user-provided value.
+		transformedParts[j] = quoteString(f)
+	}
+
+	return `_jsonb->` + strings.Join(transformedParts, " -> ")
+}
+
 // dropPgIndex drops the given index.
+//
+// Some indexes (like text ones) exist only in FerretDB metadata, so the index may not exist.
 func dropPgIndex(ctx context.Context, tx pgx.Tx, schema, index string) error {
 	var err error
 
-	sql := `DROP INDEX ` + pgx.Identifier{schema, index}.Sanitize()
+	sql := `DROP INDEX IF EXISTS ` + pgx.Identifier{schema, index}.Sanitize()
 
 	if _, err = tx.Exec(ctx, sql); err != nil {
 		return lazyerrors.Error(err)
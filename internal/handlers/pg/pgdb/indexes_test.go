@@ -445,3 +445,27 @@ func TestDropIndexesStress(t *testing.T) {
 	require.Equal(t, initialIndexes, indexesAfterDrop)
 	require.NotEqual(t, indexesAfterCreate, indexesAfterDrop)
 }
+
+func TestIndexKeyWildcard(t *testing.T) {
+	t.Parallel()
+
+	for field, expected := range map[string]struct {
+		prefix   string
+		wildcard bool
+	}{
+		"$**":       {prefix: "", wildcard: true},
+		"v.$**":     {prefix: "v", wildcard: true},
+		"v.foo.$**": {prefix: "v.foo", wildcard: true},
+		"v":         {},
+		".$**":      {},
+		"v$**":      {},
+		"v.$**.foo": {},
+	} {
+		prefix, wildcard := IndexKey{{Field: field, Order: types.Ascending}}.Wildcard()
+		assert.Equal(t, expected.prefix, prefix, field)
+		assert.Equal(t, expected.wildcard, wildcard, field)
+	}
+
+	_, wildcard := IndexKey{{Field: "$**"}, {Field: "v"}}.Wildcard()
+	assert.False(t, wildcard)
+}
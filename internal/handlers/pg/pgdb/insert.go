@@ -22,6 +22,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 
+	"github.com/FerretDB/FerretDB/internal/bson"
 	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
@@ -33,13 +34,30 @@ import (
 //
 // It returns possibly wrapped error:
 //   - *types.ValidationError - if the document is not valid.
-//   - ErrUniqueViolation - if pgerrcode.UniqueViolation error is caught (e.g. due to unique index constraint).
+//   - ErrUniqueViolation - if pgerrcode.UniqueViolation error is caught (e.g. due to unique index constraint)
+//     or if the document violates unique multikey index.
+//   - *ParallelArraysError - if the document has parallel arrays for some compound index.
+//   - ErrHashedArray - if the document has an array value for some hashed index field.
 //   - ErrInvalidCollectionName - if the given collection name doesn't conform to restrictions.
 //   - ErrInvalidDatabaseName - if the given database name doesn't conform to restrictions.
 //   - *transactionConflictError - if a PostgreSQL conflict occurs (the caller could retry the transaction).
 func InsertDocument(ctx context.Context, tx pgx.Tx, db, collection string, doc *types.Document) error {
-	if err := doc.ValidateData(); err != nil {
-		return err
+	return InsertDocuments(ctx, tx, db, collection, []*types.Document{doc})
+}
+
+// InsertDocuments inserts documents into FerretDB database and collection in the given transaction.
+// If database or collection does not exist, it will be created.
+//
+// Unlike calling InsertDocument for each document, it updates the collection's data size only once
+// at the end, so the metadata row is not locked while documents are being inserted,
+// and concurrent transactions inserting into the same collection do not block each other.
+//
+// It returns the same errors as InsertDocument for the first document that fails to insert.
+func InsertDocuments(ctx context.Context, tx pgx.Tx, db, collection string, docs []*types.Document) error {
+	for _, doc := range docs {
+		if err := doc.ValidateData(); err != nil {
+			return err
+		}
 	}
 
 	var err error
@@ -48,20 +66,34 @@ func InsertDocument(ctx context.Context, tx pgx.Tx, db, collection string, doc *
 		return lazyerrors.Error(err)
 	}
 
-	var table string
-	table, err = newMetadataStorage(tx, db, collection).getTableName(ctx)
+	ms := newMetadataStorage(tx, db, collection)
 
+	m, err := ms.get(ctx, false)
 	if err != nil {
 		return lazyerrors.Error(err)
 	}
 
-	p := &insertParams{
-		schema: db,
-		table:  table,
-		doc:    doc,
+	var size int64
+
+	for _, doc := range docs {
+		if err = checkIndexKeys(ctx, tx, ms, m, doc); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		p := &insertParams{
+			schema: db,
+			table:  m.table,
+			doc:    doc,
+		}
+
+		if err = insert(ctx, tx, p); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		size += int64(bson.Size(doc))
 	}
-	err = insert(ctx, tx, p)
-	if err != nil {
+
+	if err = ms.addDataSize(ctx, size); err != nil {
 		return lazyerrors.Error(err)
 	}
 
@@ -0,0 +1,229 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// MaintenanceParams represents thresholds of the automatic ANALYZE/VACUUM policy.
+//
+// A table is analyzed when the number of rows changed since the last ANALYZE exceeds
+// AnalyzeThreshold + AnalyzeScaleFactor * live rows.
+// A table is vacuumed when the number of dead rows exceeds
+// VacuumThreshold + VacuumScaleFactor * live rows.
+// That makes thresholds proportional to each collection's size.
+type MaintenanceParams struct {
+	AnalyzeThreshold   int64
+	AnalyzeScaleFactor float64
+	VacuumThreshold    int64
+	VacuumScaleFactor  float64
+}
+
+// check returns true for operations that should be run for the table with the given statistics.
+func (params *MaintenanceParams) check(liveRows, modSinceAnalyze, deadRows int64) (analyze, vacuum bool) {
+	live := float64(liveRows)
+	analyze = float64(modSinceAnalyze) > float64(params.AnalyzeThreshold)+params.AnalyzeScaleFactor*live
+	vacuum = float64(deadRows) > float64(params.VacuumThreshold)+params.VacuumScaleFactor*live
+
+	return
+}
+
+// MaintenanceResult represents tables processed by Maintain.
+//
+// Table names are fully qualified and sanitized.
+type MaintenanceResult struct {
+	Analyzed []string
+	Vacuumed []string
+}
+
+// maintenanceCandidate represents a table that should be analyzed and/or vacuumed.
+type maintenanceCandidate struct {
+	table   string // fully qualified and sanitized
+	analyze bool
+	vacuum  bool
+}
+
+// Maintain runs ANALYZE and VACUUM for FerretDB tables that exceed thresholds.
+//
+// Tables are processed in random order,
+// so a table that fails all the time does not prevent maintenance of other tables,
+// and concurrent FerretDB instances do not process the same tables in lockstep.
+// Tables dropped concurrently are skipped.
+//
+// VACUUM can't run inside a transaction block, so statements are executed outside of transactions.
+func (pgPool *Pool) Maintain(ctx context.Context, params *MaintenanceParams) (*MaintenanceResult, error) {
+	// FerretDB metadata tables are excluded by reserved prefix
+	sql := `
+		SELECT schemaname, relname, n_live_tup, n_mod_since_analyze, n_dead_tup
+		FROM pg_stat_user_tables
+		WHERE relname NOT LIKE $1`
+	args := []any{reservedPrefix + "%"}
+
+	rows, err := pgPool.p.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	defer rows.Close()
+
+	var candidates []maintenanceCandidate
+
+	for rows.Next() {
+		var schema, table string
+		var liveRows, modSinceAnalyze, deadRows int64
+
+		if err = rows.Scan(&schema, &table, &liveRows, &modSinceAnalyze, &deadRows); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		analyze, vacuum := params.check(liveRows, modSinceAnalyze, deadRows)
+		if !analyze && !vacuum {
+			continue
+		}
+
+		candidates = append(candidates, maintenanceCandidate{
+			table:   pgx.Identifier{schema, table}.Sanitize(),
+			analyze: analyze,
+			vacuum:  vacuum,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+
+	var res MaintenanceResult
+
+	for _, c := range candidates {
+		switch {
+		case c.analyze && c.vacuum:
+			sql = `VACUUM (ANALYZE) ` + c.table
+		case c.vacuum:
+			sql = `VACUUM ` + c.table
+		default:
+			sql = `ANALYZE ` + c.table
+		}
+
+		if _, err = pgPool.p.Exec(ctx, sql); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UndefinedTable {
+				continue
+			}
+
+			return &res, lazyerrors.Error(err)
+		}
+
+		if c.analyze {
+			res.Analyzed = append(res.Analyzed, c.table)
+		}
+
+		if c.vacuum {
+			res.Vacuumed = append(res.Vacuumed, c.table)
+		}
+	}
+
+	return &res, nil
+}
+
+// Compact runs VACUUM and REINDEX for the table of the given collection
+// and returns the number of bytes freed.
+//
+// If full is true, VACUUM FULL is used; it returns space to the operating system, but locks the table.
+// If dryRun is true, nothing is changed, and the number of bytes occupied by dead rows is estimated
+// from table statistics.
+//
+// If the given collection does not exist, it returns ErrTableNotExist.
+func (pgPool *Pool) Compact(ctx context.Context, db, collection string, full, dryRun bool) (int64, error) {
+	var table string
+
+	err := pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		table, err = newMetadataStorage(tx, db, collection).getTableName(ctx)
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	fullName := pgx.Identifier{db, table}.Sanitize()
+
+	var sizeBefore, liveRows, deadRows int64
+
+	sql := `
+		SELECT pg_total_relation_size(relid), n_live_tup, n_dead_tup
+		FROM pg_stat_user_tables
+		WHERE schemaname = $1 AND relname = $2`
+	if err = pgPool.p.QueryRow(ctx, sql, db, table).Scan(&sizeBefore, &liveRows, &deadRows); err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	if dryRun {
+		if liveRows+deadRows == 0 {
+			return 0, nil
+		}
+
+		return sizeBefore * deadRows / (liveRows + deadRows), nil
+	}
+
+	sql = `VACUUM ` + fullName
+	if full {
+		sql = `VACUUM FULL ` + fullName
+	}
+
+	// VACUUM can't run inside a transaction block
+	for _, sql := range []string{sql, `REINDEX TABLE ` + fullName} {
+		if _, err = pgPool.p.Exec(ctx, sql); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UndefinedTable {
+				return 0, ErrTableNotExist
+			}
+
+			return 0, lazyerrors.Error(err)
+		}
+	}
+
+	var sizeAfter int64
+	if err = pgPool.p.QueryRow(ctx, `SELECT pg_total_relation_size($1)`, fullName).Scan(&sizeAfter); err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	if sizeAfter > sizeBefore {
+		return 0, nil
+	}
+
+	return sizeBefore - sizeAfter, nil
+}
+
+// Checkpoint forces a PostgreSQL checkpoint, flushing all dirty buffers to disk.
+//
+// It requires superuser or pg_checkpoint role.
+func (pgPool *Pool) Checkpoint(ctx context.Context) error {
+	if _, err := pgPool.p.Exec(ctx, `CHECKPOINT`); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
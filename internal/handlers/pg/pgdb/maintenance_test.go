@@ -0,0 +1,75 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenanceParamsCheck(t *testing.T) {
+	t.Parallel()
+
+	params := &MaintenanceParams{
+		AnalyzeThreshold:   50,
+		AnalyzeScaleFactor: 0.1,
+		VacuumThreshold:    50,
+		VacuumScaleFactor:  0.2,
+	}
+
+	for name, tc := range map[string]struct {
+		liveRows        int64
+		modSinceAnalyze int64
+		deadRows        int64
+		analyze         bool
+		vacuum          bool
+	}{
+		"Empty": {},
+		"SmallBelow": {
+			liveRows:        10,
+			modSinceAnalyze: 50,
+			deadRows:        50,
+		},
+		"SmallAbove": {
+			liveRows:        10,
+			modSinceAnalyze: 52,
+			deadRows:        53,
+			analyze:         true,
+			vacuum:          true,
+		},
+		"LargeAnalyzeOnly": {
+			liveRows:        10_000,
+			modSinceAnalyze: 1_051,
+			deadRows:        2_050,
+			analyze:         true,
+		},
+		"LargeVacuumOnly": {
+			liveRows:        10_000,
+			modSinceAnalyze: 1_050,
+			deadRows:        2_051,
+			vacuum:          true,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			analyze, vacuum := params.check(tc.liveRows, tc.modSinceAnalyze, tc.deadRows)
+			assert.Equal(t, tc.analyze, analyze)
+			assert.Equal(t, tc.vacuum, vacuum)
+		})
+	}
+}
@@ -0,0 +1,501 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// maxContainmentPathLen is the maximal number of path elements of the first indexed field
+// for which candidates for unique multikey index violations are selected by PostgreSQL.
+// For longer paths, all documents of the collection are checked.
+const maxContainmentPathLen = 4
+
+// ParallelArraysError indicates that the document has array values
+// for more than one field of the compound index.
+type ParallelArraysError struct {
+	first  string
+	second string
+}
+
+// Error implements error interface.
+func (e *ParallelArraysError) Error() string {
+	return fmt.Sprintf("cannot index parallel arrays [%s] [%s]", e.second, e.first)
+}
+
+// indexKeys returns keys of the given index for the document,
+// and true if the document makes the index multikey.
+//
+// Like MongoDB's multikey indexes, if the value of the indexed field is an array,
+// the document has a separate key for each array element.
+// Missing fields, nulls, and empty arrays produce nil component values.
+// Keys are returned as canonical strings (see keyString) that are equal for equal MongoDB index keys;
+// keys with nil components are not returned, as they are not checked for uniqueness.
+//
+// It returns *ParallelArraysError if more than one indexed field has array values,
+// ErrHashedArray if the hashed field has array values,
+// and ErrGeoKeys if the 2dsphere field has values that are not geometries.
+//
+// Keys of indexes with 2dsphere and text fields are not returned, as such indexes could not be unique.
+func indexKeys(doc *types.Document, key IndexKey) ([]string, bool, error) {
+	components := make([][]any, len(key))
+
+	var arrayField string
+
+	for i, pair := range key {
+		if pair.Text {
+			components[i] = []any{nil}
+			continue
+		}
+
+		path, err := types.NewPathFromString(pair.Field)
+		if err != nil {
+			return nil, false, lazyerrors.Error(err)
+		}
+
+		if pair.Sphere {
+			for _, v := range sphereValues(doc, path) {
+				if !common.IsGeoValue(v) {
+					return nil, false, ErrGeoKeys
+				}
+			}
+
+			components[i] = []any{nil}
+
+			continue
+		}
+
+		values, isArray := pathValues(doc, path)
+		components[i] = values
+
+		if !isArray {
+			continue
+		}
+
+		if pair.Hashed {
+			return nil, false, ErrHashedArray
+		}
+
+		if arrayField != "" {
+			return nil, false, &ParallelArraysError{first: arrayField, second: path.Suffix()}
+		}
+
+		arrayField = path.Suffix()
+	}
+
+	// only one component could have more than one value, so there is no combinatorial explosion
+	keys := []string{""}
+
+	for _, values := range components {
+		var next []string
+
+		for _, prefix := range keys {
+			for _, v := range values {
+				if v == nil {
+					continue
+				}
+
+				next = append(next, prefix+keyString(v)+"\x00")
+			}
+		}
+
+		keys = next
+	}
+
+	return keys, arrayField != "", nil
+}
+
+// pathValues returns values of the document field with the given path
+// and true if any path element has an array value.
+//
+// Arrays of documents are traversed implicitly.
+// Leaf arrays are expanded to their elements (but not recursively).
+func pathValues(doc *types.Document, path types.Path) ([]any, bool) {
+	var res []any
+	var isArray bool
+
+	opts := &types.WalkPathOpts{
+		ArrayDocuments: true,
+		Missing:        true,
+	}
+
+	_ = types.WalkPath(doc, path, opts, func(p types.Path, v any) error {
+		// concrete path is longer if arrays of documents were traversed
+		if p.Len() > path.Len() {
+			isArray = true
+		}
+
+		switch v := v.(type) {
+		case *types.Array:
+			isArray = true
+
+			if v.Len() == 0 {
+				res = append(res, nil)
+				break
+			}
+
+			for i := 0; i < v.Len(); i++ {
+				e := must.NotFail(v.Get(i))
+				if _, ok := e.(types.NullType); ok {
+					e = nil
+				}
+
+				res = append(res, e)
+			}
+
+		case types.NullType:
+			res = append(res, nil)
+
+		default:
+			res = append(res, v)
+		}
+
+		return nil
+	})
+
+	// nothing is reached only if an array without documents was traversed
+	if len(res) == 0 {
+		return []any{nil}, true
+	}
+
+	return res, isArray
+}
+
+// sphereValues returns values of the document field with the given path for 2dsphere index fields.
+//
+// Unlike pathValues, leaf arrays are not expanded, as they could be legacy coordinate pairs.
+// Missing fields are not returned.
+func sphereValues(doc *types.Document, path types.Path) []any {
+	var res []any
+
+	_ = types.WalkPath(doc, path, &types.WalkPathOpts{ArrayDocuments: true}, func(_ types.Path, v any) error {
+		res = append(res, v)
+		return nil
+	})
+
+	return res
+}
+
+// keyString returns a canonical string representation of the index key component value.
+//
+// Equal values have equal representations, even if they have different numeric types.
+func keyString(v any) string {
+	switch v := v.(type) {
+	case *types.Document:
+		var sb strings.Builder
+
+		sb.WriteString("{")
+
+		for _, k := range v.Keys() {
+			sb.WriteString(strconv.Quote(k))
+			sb.WriteString(":")
+			sb.WriteString(keyString(must.NotFail(v.Get(k))))
+			sb.WriteString(",")
+		}
+
+		sb.WriteString("}")
+
+		return sb.String()
+
+	case *types.Array:
+		var sb strings.Builder
+
+		sb.WriteString("[")
+
+		for i := 0; i < v.Len(); i++ {
+			sb.WriteString(keyString(must.NotFail(v.Get(i))))
+			sb.WriteString(",")
+		}
+
+		sb.WriteString("]")
+
+		return sb.String()
+
+	case float64:
+		if v == 0 {
+			// -0 and 0 are equal
+			v = 0
+		}
+
+		return "n:" + strconv.FormatFloat(v, 'g', -1, 64)
+
+	case int32:
+		return keyString(float64(v))
+
+	case int64:
+		if f := float64(v); f < math.MaxInt64 && int64(f) == v {
+			return keyString(f)
+		}
+
+		return "n:" + strconv.FormatInt(v, 10)
+
+	case string:
+		return "s:" + strconv.Quote(v)
+
+	case time.Time:
+		return "d:" + strconv.FormatInt(v.UnixMilli(), 10)
+
+	case types.NullType:
+		return "null"
+
+	default:
+		return fmt.Sprintf("%T:%v", v, v)
+	}
+}
+
+// containmentPatterns returns JSON values that are contained (in terms of the PostgreSQL @> operator)
+// in every sjson-encoded document that has the given value at the given path,
+// possibly traversing arrays of documents and with the value being an array element.
+func containmentPatterns(path []string, v any) ([]string, error) {
+	b, err := sjson.MarshalSingleValue(v)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res := []string{string(b), "[" + string(b) + "]"}
+
+	for i := len(path) - 1; i >= 0; i-- {
+		k, err := sjson.MarshalSingleValue(path[i])
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		next := make([]string, 0, len(res)*2)
+
+		for _, p := range res {
+			obj := "{" + string(k) + ":" + p + "}"
+			next = append(next, obj)
+
+			// arrays of documents are traversed implicitly, but not the document itself
+			if i > 0 {
+				next = append(next, "["+obj+"]")
+			}
+		}
+
+		res = next
+	}
+
+	return res, nil
+}
+
+// checkMultikeyUnique returns ErrUniqueViolation if any other document in the table
+// has a key of the given unique multikey index that is equal to one of the given keys.
+//
+// PostgreSQL unique index could not do that itself, as it indexes the whole array value.
+func checkMultikeyUnique(ctx context.Context, tx pgx.Tx, schema, table string, idx *metadataIndex, doc *types.Document, keys []string) error { //nolint:lll // for readability
+	if len(keys) == 0 {
+		return nil
+	}
+
+	id := must.NotFail(doc.Get("_id"))
+
+	sql := `SELECT _jsonb FROM ` + pgx.Identifier{schema, table}.Sanitize() + ` WHERE _jsonb->'_id' <> $1`
+	args := []any{must.NotFail(sjson.MarshalSingleValue(id))}
+
+	// select candidates by the first indexed field values
+	if path, err := types.NewPathFromString(idx.Key[0].Field); err == nil && path.Len() <= maxContainmentPathLen {
+		values, _ := pathValues(doc, path)
+
+		var patterns []string
+
+		for _, v := range values {
+			if v == nil {
+				continue
+			}
+
+			p, err := containmentPatterns(path.Slice(), v)
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			patterns = append(patterns, p...)
+		}
+
+		sql += ` AND _jsonb @> ANY($2::jsonb[])`
+		args = append(args, patterns)
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	iter := newIterator(ctx, rows, new(iteratorParams))
+	defer iter.Close()
+
+	set := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		set[k] = struct{}{}
+	}
+
+	for {
+		_, other, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			return nil
+		}
+
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		otherKeys, _, err := indexKeys(other, idx.Key)
+		if err != nil {
+			// should not happen, as the existing document was checked on insert
+			continue
+		}
+
+		for _, k := range otherKeys {
+			if _, ok := set[k]; ok {
+				return ErrUniqueViolation
+			}
+		}
+	}
+}
+
+// checkIndexKeys checks that the document could be indexed by all collection's indexes
+// with MongoDB's multikey semantics.
+//
+// If the document makes some indexes multikey, their metadata is updated.
+//
+// It returns a possibly wrapped error:
+//   - *ParallelArraysError - if the document has parallel arrays for some compound index.
+//   - ErrUniqueViolation - if the document violates some unique multikey index.
+//   - ErrHashedArray - if the document has an array value for some hashed index field.
+//   - ErrGeoKeys - if the document has a value that is not a geometry for some 2dsphere index field.
+func checkIndexKeys(ctx context.Context, tx pgx.Tx, ms *metadataStorage, m *metadata, doc *types.Document) error {
+	var becameMultikey []string
+
+	for i := range m.indexes {
+		idx := &m.indexes[i]
+
+		if idx.Name == "_id_" {
+			continue
+		}
+
+		if _, wildcard := idx.Key.Wildcard(); wildcard {
+			continue
+		}
+
+		keys, multikey, err := indexKeys(doc, idx.Key)
+		if err != nil {
+			return err
+		}
+
+		if multikey && !idx.multikey {
+			idx.multikey = true
+			becameMultikey = append(becameMultikey, idx.Name)
+		}
+
+		// non-multikey unique indexes are handled by PostgreSQL
+		if idx.Unique == nil || !*idx.Unique || !idx.multikey {
+			continue
+		}
+
+		if err = checkMultikeyUnique(ctx, tx, ms.db, m.table, idx, doc, keys); err != nil {
+			return err
+		}
+	}
+
+	if len(becameMultikey) == 0 {
+		return nil
+	}
+
+	return ms.setMultikey(ctx, becameMultikey)
+}
+
+// checkExistingIndexKeys checks that all documents in the collection could be indexed by the given new index
+// with MongoDB's multikey semantics, and marks the index multikey if needed.
+//
+// It returns a possibly wrapped error:
+//   - *ParallelArraysError - if some document has parallel arrays.
+//   - ErrUniqueViolation - if some documents violate the unique index.
+//   - ErrHashedArray - if some document has an array value for the hashed index field.
+//   - ErrGeoKeys - if some document has a value that is not a geometry for the 2dsphere index field.
+func checkExistingIndexKeys(ctx context.Context, tx pgx.Tx, ms *metadataStorage, table string, i *Index) error {
+	iter, _, err := buildIterator(ctx, tx, &iteratorParams{
+		schema: ms.db,
+		table:  table,
+	})
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	defer iter.Close()
+
+	unique := i.Unique != nil && *i.Unique
+	seen := map[string]struct{}{}
+
+	var multikey bool
+
+	for {
+		_, doc, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		keys, docMultikey, err := indexKeys(doc, i.Key)
+		if err != nil {
+			return err
+		}
+
+		multikey = multikey || docMultikey
+
+		if !unique {
+			continue
+		}
+
+		// the same key could be generated multiple times for a single document
+		docKeys := make(map[string]struct{}, len(keys))
+
+		for _, k := range keys {
+			if _, ok := docKeys[k]; ok {
+				continue
+			}
+
+			docKeys[k] = struct{}{}
+
+			if _, ok := seen[k]; ok {
+				return ErrUniqueViolation
+			}
+
+			seen[k] = struct{}{}
+		}
+	}
+
+	if !multikey {
+		return nil
+	}
+
+	return ms.setMultikey(ctx, []string{i.Name})
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestIndexKeys(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:vet // for readability
+		doc      *types.Document
+		key      IndexKey
+		keys     []string
+		multikey bool
+		err      string
+	}{
+		"Scalar": {
+			doc:  must.NotFail(types.NewDocument("v", int32(42))),
+			key:  IndexKey{{Field: "v"}},
+			keys: []string{"n:42\x00"},
+		},
+		"Missing": {
+			doc: must.NotFail(types.NewDocument("foo", int32(42))),
+			key: IndexKey{{Field: "v"}},
+		},
+		"Null": {
+			doc: must.NotFail(types.NewDocument("v", types.Null)),
+			key: IndexKey{{Field: "v"}},
+		},
+		"MixedArray": {
+			doc: must.NotFail(types.NewDocument("v", must.NotFail(types.NewArray(
+				int32(42), 42.5, int64(43), "foo", types.Null,
+				must.NotFail(types.NewArray(int32(1))),
+				must.NotFail(types.NewDocument("foo", "bar")),
+			)))),
+			key:      IndexKey{{Field: "v"}},
+			keys:     []string{"n:42\x00", "n:42.5\x00", "n:43\x00", "s:\"foo\"\x00", "[n:1,]\x00", "{\"foo\":s:\"bar\",}\x00"},
+			multikey: true,
+		},
+		"EmptyArray": {
+			doc:      must.NotFail(types.NewDocument("v", must.NotFail(types.NewArray()))),
+			key:      IndexKey{{Field: "v"}},
+			multikey: true,
+		},
+		"ArrayDocuments": {
+			doc: must.NotFail(types.NewDocument("v", must.NotFail(types.NewArray(
+				must.NotFail(types.NewDocument("foo", int32(1))),
+				must.NotFail(types.NewDocument("foo", must.NotFail(types.NewArray("bar", 2.0)))),
+				"baz",
+			)))),
+			key:      IndexKey{{Field: "v.foo"}},
+			keys:     []string{"n:1\x00", "s:\"bar\"\x00", "n:2\x00"},
+			multikey: true,
+		},
+		"Compound": {
+			doc: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewArray(int32(1), int32(2))),
+				"foo", "bar",
+			)),
+			key:      IndexKey{{Field: "foo"}, {Field: "v"}},
+			keys:     []string{"s:\"bar\"\x00n:1\x00", "s:\"bar\"\x00n:2\x00"},
+			multikey: true,
+		},
+		"ParallelArrays": {
+			doc: must.NotFail(types.NewDocument(
+				"a", must.NotFail(types.NewArray(int32(1))),
+				"b", must.NotFail(types.NewArray(int32(2))),
+			)),
+			key: IndexKey{{Field: "a"}, {Field: "b"}},
+			err: "cannot index parallel arrays [b] [a]",
+		},
+		"Hashed": {
+			doc:  must.NotFail(types.NewDocument("v", "foo")),
+			key:  IndexKey{{Field: "v", Hashed: true}},
+			keys: []string{"s:\"foo\"\x00"},
+		},
+		"HashedArray": {
+			doc: must.NotFail(types.NewDocument("v", must.NotFail(types.NewArray("foo")))),
+			key: IndexKey{{Field: "v", Hashed: true}},
+			err: ErrHashedArray.Error(),
+		},
+		"HashedCompoundArray": {
+			doc: must.NotFail(types.NewDocument(
+				"a", must.NotFail(types.NewArray(int32(1))),
+				"b", int32(2),
+			)),
+			key:      IndexKey{{Field: "a"}, {Field: "b", Hashed: true}},
+			keys:     []string{"n:1\x00n:2\x00"},
+			multikey: true,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			keys, multikey, err := indexKeys(tc.doc, tc.key)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Equal(t, tc.err, err.Error())
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.keys, keys)
+			assert.Equal(t, tc.multikey, multikey)
+		})
+	}
+}
+
+func TestContainmentPatterns(t *testing.T) {
+	t.Parallel()
+
+	actual, err := containmentPatterns([]string{"v", "foo"}, int32(42))
+	require.NoError(t, err)
+
+	expected := []string{
+		`{"v":{"foo":42}}`,
+		`{"v":[{"foo":42}]}`,
+		`{"v":{"foo":[42]}}`,
+		`{"v":[{"foo":[42]}]}`,
+	}
+	assert.Equal(t, expected, actual)
+}
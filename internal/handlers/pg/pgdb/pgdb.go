@@ -42,6 +42,9 @@ var (
 	// ErrIndexCannotDelete indicates the index cannot be deleted.
 	ErrIndexCannotDelete = fmt.Errorf("index cannot be deleted")
 
+	// ErrIndexCannotHide indicates that the index cannot be hidden.
+	ErrIndexCannotHide = fmt.Errorf("index cannot be hidden")
+
 	// ErrInvalidCollectionName indicates that a collection didn't pass name checks.
 	ErrInvalidCollectionName = fmt.Errorf("invalid FerretDB collection name")
 
@@ -53,4 +56,10 @@ var (
 
 	// ErrUniqueViolation indicates that operations violates a unique constraint.
 	ErrUniqueViolation = fmt.Errorf("unique constraint violation")
+
+	// ErrHashedArray indicates that the field of a hashed index has an array value.
+	ErrHashedArray = fmt.Errorf("hashed indexes do not currently support array values")
+
+	// ErrGeoKeys indicates that the field of a 2dsphere index has a value that is not a geometry.
+	ErrGeoKeys = fmt.Errorf("Can't extract geo keys")
 )
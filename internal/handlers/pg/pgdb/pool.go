@@ -26,6 +26,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/FerretDB/FerretDB/internal/util/debugbuild"
+	"github.com/FerretDB/FerretDB/internal/util/lasterror"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/state"
 )
@@ -40,8 +41,9 @@ var (
 
 // Pool represents PostgreSQL concurrency-safe connection pool.
 type Pool struct {
-	p      *pgxpool.Pool
-	logger *zapadapter.Logger
+	p         *pgxpool.Pool
+	logger    *zapadapter.Logger
+	lastError *lasterror.Tracker
 }
 
 // NewPool returns a new concurrency-safe connection pool.
@@ -77,8 +79,10 @@ func NewPool(ctx context.Context, uri string, logger *zap.Logger, p *state.Provi
 	}
 
 	pgdbLogger := zapadapter.NewLogger(logger.Named("pgdb"))
+	lastError := new(lasterror.Tracker)
 
 	tracers := []pgx.QueryTracer{
+		&lastErrorTracer{t: lastError},
 		// try to log everything; logger's configuration will skip extra levels if needed
 		&tracelog.TraceLog{
 			Logger:   pgdbLogger,
@@ -100,8 +104,9 @@ func NewPool(ctx context.Context, uri string, logger *zap.Logger, p *state.Provi
 	}
 
 	res := &Pool{
-		p:      pool,
-		logger: pgdbLogger,
+		p:         pool,
+		logger:    pgdbLogger,
+		lastError: lastError,
 	}
 
 	if err = res.checkConnection(ctx); err != nil {
@@ -119,6 +124,16 @@ func (pgPool *Pool) Close() {
 	pgPool.p.Close()
 }
 
+// Stat returns connection pool statistics.
+func (pgPool *Pool) Stat() *pgxpool.Stat {
+	return pgPool.p.Stat()
+}
+
+// LastError returns the last query error, or nil if there were no errors.
+func (pgPool *Pool) LastError() *lasterror.Entry {
+	return pgPool.lastError.Last()
+}
+
 // setDefaultValue sets default query parameters.
 //
 // Keep it in sync with docs.
@@ -19,11 +19,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
@@ -46,10 +48,26 @@ type QueryParams struct {
 	Filter     *types.Document
 	Sort       *types.Document
 	Limit      int64 // 0 does not apply limit to the query
+	Offset     int64 // 0 does not apply offset to the query
 	DB         string
 	Collection string
 	Comment    string
 	Explain    bool
+
+	// If set, documents are returned in natural or reverse natural order of the table.
+	// Sort should be nil in that case.
+	Natural types.SortType
+
+	// If true, indexes are not used by the query.
+	CollectionScan bool
+
+	// If true, record IDs derived from rows' physical locations are set on returned documents;
+	// see types.Document.RecordID.
+	RecordID bool
+
+	// If not zero, up to Sample randomly selected documents are returned in random order.
+	// Filter, Sort, Limit, Offset and Natural should not be set in that case.
+	Sample int64
 }
 
 // Explain returns SQL EXPLAIN results for given query parameters.
@@ -58,21 +76,26 @@ type QueryParams struct {
 func Explain(ctx context.Context, tx pgx.Tx, qp *QueryParams) (*types.Document, QueryResults, error) {
 	var res QueryResults
 
-	table, err := newMetadataStorage(tx, qp.DB, qp.Collection).getTableName(ctx)
+	m, err := newMetadataStorage(tx, qp.DB, qp.Collection).get(ctx, false)
 	if err != nil {
 		return nil, res, lazyerrors.Error(err)
 	}
 
 	var iter types.DocumentsIterator
 	iter, res, err = buildIterator(ctx, tx, &iteratorParams{
-		schema:    qp.DB,
-		table:     table,
-		comment:   qp.Comment,
-		explain:   qp.Explain,
-		filter:    qp.Filter,
-		sort:      qp.Sort,
-		limit:     qp.Limit,
-		unmarshal: unmarshalExplain,
+		schema:         qp.DB,
+		table:          m.table,
+		indexes:        m.pushdownIndexes(),
+		comment:        qp.Comment,
+		explain:        qp.Explain,
+		filter:         qp.Filter,
+		sort:           qp.Sort,
+		natural:        qp.Natural,
+		limit:          qp.Limit,
+		offset:         qp.Offset,
+		sample:         qp.Sample,
+		collectionScan: qp.CollectionScan,
+		unmarshal:      unmarshalExplain,
 	})
 	if err != nil {
 		return nil, res, lazyerrors.Error(err)
@@ -112,6 +135,7 @@ type QueryResults struct {
 	FilterPushdown bool
 	SortPushdown   bool
 	LimitPushdown  bool
+	OffsetPushdown bool
 }
 
 // QueryDocuments returns an queryIterator to fetch documents for given SQLParams.
@@ -120,7 +144,7 @@ type QueryResults struct {
 //
 // Transaction is not closed by this function. Use iterator.WithClose if needed.
 func QueryDocuments(ctx context.Context, tx pgx.Tx, qp *QueryParams) (types.DocumentsIterator, QueryResults, error) {
-	table, err := newMetadataStorage(tx, qp.DB, qp.Collection).getTableName(ctx)
+	m, err := newMetadataStorage(tx, qp.DB, qp.Collection).get(ctx, false)
 
 	var res QueryResults
 
@@ -135,13 +159,19 @@ func QueryDocuments(ctx context.Context, tx pgx.Tx, qp *QueryParams) (types.Docu
 
 	var iter types.DocumentsIterator
 	iter, res, err = buildIterator(ctx, tx, &iteratorParams{
-		schema:  qp.DB,
-		table:   table,
-		comment: qp.Comment,
-		explain: qp.Explain,
-		filter:  qp.Filter,
-		sort:    qp.Sort,
-		limit:   qp.Limit,
+		schema:         qp.DB,
+		table:          m.table,
+		comment:        qp.Comment,
+		explain:        qp.Explain,
+		filter:         qp.Filter,
+		sort:           qp.Sort,
+		natural:        qp.Natural,
+		limit:          qp.Limit,
+		offset:         qp.Offset,
+		sample:         qp.Sample,
+		collectionScan: qp.CollectionScan,
+		recordID:       qp.RecordID,
+		indexes:        m.pushdownIndexes(),
 	})
 	if err != nil {
 		return nil, res, lazyerrors.Error(err)
@@ -152,15 +182,21 @@ func QueryDocuments(ctx context.Context, tx pgx.Tx, qp *QueryParams) (types.Docu
 
 // iteratorParams contains parameters for building an iterator.
 type iteratorParams struct {
-	schema    string
-	table     string
-	comment   string
-	explain   bool
-	filter    *types.Document
-	sort      *types.Document
-	limit     int64
-	forUpdate bool                                    // if SELECT FOR UPDATE is needed.
-	unmarshal func(b []byte) (*types.Document, error) // if set, iterator uses unmarshal to convert row to *types.Document.
+	schema         string
+	table          string
+	comment        string
+	explain        bool
+	filter         *types.Document
+	sort           *types.Document
+	natural        types.SortType // if set, rows are returned in natural or reverse natural order
+	limit          int64
+	offset         int64
+	sample         int64                                   // if set, up to that number of random rows are returned.
+	forUpdate      bool                                    // if SELECT FOR UPDATE is needed.
+	collectionScan bool                                    // if true, indexes are not used.
+	recordID       bool                                    // if true, record IDs are set on documents.
+	indexes        *pushdownIndexes                        // if set, indexes are used for filter pushdown.
+	unmarshal      func(b []byte) (*types.Document, error) // if set, iterator uses unmarshal to convert row to *types.Document.
 }
 
 // buildIterator returns an iterator to fetch documents for given iteratorParams.
@@ -174,6 +210,11 @@ func buildIterator(ctx context.Context, tx pgx.Tx, p *iteratorParams) (types.Doc
 
 	query += `SELECT _jsonb `
 
+	if p.recordID && !p.explain {
+		// ctid is (block number, tuple index); combine them into a single number
+		query += `, ((ctid::text::point)[0]::bigint << 16) + (ctid::text::point)[1]::bigint `
+	}
+
 	if c := p.comment; c != "" {
 		// prevent SQL injections
 		c = strings.ReplaceAll(c, "/*", "/ *")
@@ -184,9 +225,18 @@ func buildIterator(ctx context.Context, tx pgx.Tx, p *iteratorParams) (types.Doc
 
 	query += ` FROM ` + pgx.Identifier{p.schema, p.table}.Sanitize()
 
+	if p.sample != 0 {
+		tableSample, err := prepareTableSampleClause(ctx, tx, p.schema, p.table, p.sample)
+		if err != nil {
+			return nil, res, lazyerrors.Error(err)
+		}
+
+		query += tableSample
+	}
+
 	var placeholder Placeholder
 
-	where, args, err := prepareWhereClause(&placeholder, p.filter)
+	where, args, err := prepareWhereClause(&placeholder, p.filter, p.indexes)
 	if err != nil {
 		return nil, res, lazyerrors.Error(err)
 	}
@@ -214,12 +264,44 @@ func buildIterator(ctx context.Context, tx pgx.Tx, p *iteratorParams) (types.Doc
 		res.SortPushdown = sort != ""
 	}
 
-	if p.limit != 0 {
+	if p.natural != 0 && !res.SortPushdown {
+		// natural order is the physical order of rows in the table
+		query += ` ORDER BY ctid`
+		if p.natural == types.Descending {
+			query += ` DESC`
+		}
+	}
+
+	if p.sample != 0 {
+		query += fmt.Sprintf(` ORDER BY random() LIMIT %s`, placeholder.Next())
+		args = append(args, p.sample)
+	}
+
+	// limit and offset can't be applied if documents are sorted in memory
+	ordered := p.sort.Len() == 0 || res.SortPushdown
+
+	if p.limit != 0 && ordered {
 		query += fmt.Sprintf(` LIMIT %s`, placeholder.Next())
 		args = append(args, p.limit)
 		res.LimitPushdown = true
 	}
 
+	if p.offset != 0 && ordered {
+		query += fmt.Sprintf(` OFFSET %s`, placeholder.Next())
+		args = append(args, p.offset)
+		res.OffsetPushdown = true
+	}
+
+	if p.collectionScan {
+		// settings are reset at the end of the transaction
+		q := `SELECT set_config('enable_indexscan', 'off', true), ` +
+			`set_config('enable_indexonlyscan', 'off', true), ` +
+			`set_config('enable_bitmapscan', 'off', true)`
+		if _, err = tx.Exec(ctx, q); err != nil {
+			return nil, res, lazyerrors.Error(err)
+		}
+	}
+
 	rows, err := tx.Query(ctx, query, args...)
 	if err != nil {
 		return nil, res, lazyerrors.Error(err)
@@ -228,8 +310,49 @@ func buildIterator(ctx context.Context, tx pgx.Tx, p *iteratorParams) (types.Doc
 	return newIterator(ctx, rows, p), res, nil
 }
 
+// prepareTableSampleClause returns TABLESAMPLE clause for selecting about n random rows of the table,
+// or an empty string if the whole table should be scanned instead.
+//
+// Like MongoDB, it scans the whole table if it is small or if the sample is big (5% of rows or more).
+// Otherwise, it uses Bernoulli sampling with enough rows on average to almost never return fewer than n rows;
+// the caller should randomly order them and apply the limit.
+func prepareTableSampleClause(ctx context.Context, tx pgx.Tx, schema, table string, n int64) (string, error) {
+	// reltuples is an estimate updated by VACUUM, ANALYZE, and a few DDL commands; it is -1 if unknown
+	var rows float32
+
+	q := `SELECT reltuples FROM pg_class WHERE oid = $1::regclass`
+	if err := tx.QueryRow(ctx, q, pgx.Identifier{schema, table}.Sanitize()).Scan(&rows); err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	if rows < 100 || float64(n) >= float64(rows)*0.05 {
+		return "", nil
+	}
+
+	percent := 100 * float64(2*n+100) / float64(rows)
+	if percent >= 100 {
+		return "", nil
+	}
+
+	return fmt.Sprintf(` TABLESAMPLE BERNOULLI (%f)`, percent), nil
+}
+
+// pushdownIndexes contains information about collection indexes that is used for filter pushdown.
+type pushdownIndexes struct {
+	wildcards []string // path prefixes covered by wildcard indexes
+	hashed    []string // fields of hashed indexes
+}
+
 // prepareWhereClause adds WHERE clause with given filters to the query and returns the query and arguments.
-func prepareWhereClause(p *Placeholder, sqlFilters *types.Document) (string, []any, error) {
+//
+// If indexes are given, equality filters on fields of hashed indexes are pushed down as hash comparisons,
+// and filters on paths covered by wildcard indexes are pushed down as containment queries,
+// so both could use those indexes.
+func prepareWhereClause(p *Placeholder, sqlFilters *types.Document, indexes *pushdownIndexes) (string, []any, error) {
+	if indexes == nil {
+		indexes = new(pushdownIndexes)
+	}
+
 	var filters []string
 	var args []any
 
@@ -252,6 +375,29 @@ func prepareWhereClause(p *Placeholder, sqlFilters *types.Document) (string, []a
 			continue
 		}
 
+		if slices.Contains(indexes.hashed, rootKey) {
+			if f, a := filterHashed(p, rootKey, rootVal); f != "" {
+				filters = append(filters, f)
+				args = append(args, a...)
+
+				continue
+			}
+		}
+
+		if path := wildcardPath(rootKey, indexes.wildcards); path != nil {
+			f, a, err := filterWildcard(p, path, rootVal)
+			if err != nil {
+				return "", nil, lazyerrors.Error(err)
+			}
+
+			if len(f) > 0 {
+				filters = append(filters, f...)
+				args = append(args, a...)
+
+				continue
+			}
+		}
+
 		path, err := types.NewPathFromString(rootKey)
 
 		var pe *types.PathError
@@ -467,6 +613,161 @@ func filterEqual(p *Placeholder, k string, v any) (filter string, args []any) {
 	return
 }
 
+// filterHashed returns the SQL filter with arguments that selects documents
+// where the value of the field k of a hashed index is equal to v
+// (or to the $eq operator value in v), or empty string if it could not be pushed down.
+//
+// The hash comparison uses the index; the value comparison excludes hash collisions.
+// Hashed index fields could not contain arrays, so the plain equality is enough.
+func filterHashed(p *Placeholder, k string, v any) (filter string, args []any) {
+	if d, ok := v.(*types.Document); ok {
+		if d.Len() != 1 {
+			return
+		}
+
+		if v, _ = d.Get("$eq"); v == nil {
+			return
+		}
+	}
+
+	switch v := v.(type) {
+	case float64:
+		if math.IsNaN(v) || v > types.MaxSafeDouble || v < -types.MaxSafeDouble {
+			return
+		}
+
+	case int64:
+		if v > int64(types.MaxSafeDouble) || v < -int64(types.MaxSafeDouble) {
+			return
+		}
+
+	case string, types.ObjectID, bool, time.Time, int32:
+		// pushdown is supported
+
+	default:
+		// type not supported for pushdown
+		return
+	}
+
+	path := strings.Split(k, ".")
+	placeholders := make([]string, len(path))
+
+	for i, e := range path {
+		placeholders[i] = p.Next()
+		args = append(args, e)
+	}
+
+	field := `_jsonb->` + strings.Join(placeholders, `->`)
+	value := p.Next() + `::jsonb`
+	args = append(args, string(must.NotFail(sjson.MarshalSingleValue(v))))
+
+	filter = `jsonb_hash_extended(` + field + `, 0) = jsonb_hash_extended(` + value + `, 0) AND ` +
+		field + ` = ` + value
+
+	return
+}
+
+// wildcardPath returns path elements of the given filter key if it is covered
+// by some wildcard index with the given path prefixes and could be pushed down, or nil.
+func wildcardPath(key string, wildcards []string) []string {
+	if len(wildcards) == 0 || key == "_id" {
+		return nil
+	}
+
+	path := strings.Split(key, ".")
+	if len(path) > maxContainmentPathLen {
+		return nil
+	}
+
+	for _, e := range path {
+		// skip operators and possible array indexes
+		if e == "" || strings.HasPrefix(e, "$") || strings.Trim(e, "0123456789") == "" {
+			return nil
+		}
+	}
+
+	for _, prefix := range wildcards {
+		if prefix == "" || key == prefix || strings.HasPrefix(key, prefix+".") {
+			return path
+		}
+	}
+
+	return nil
+}
+
+// filterWildcard returns SQL filters with arguments that select documents
+// where the value at the given path is equal to v (or to one of $eq operator values in v),
+// possibly traversing arrays like MongoDB does.
+func filterWildcard(p *Placeholder, path []string, v any) (filters []string, args []any, err error) {
+	if d, ok := v.(*types.Document); ok {
+		iter := d.Iterator()
+		defer iter.Close()
+
+		for {
+			k, v, err := iter.Next()
+			if err != nil {
+				if errors.Is(err, iterator.ErrIteratorDone) {
+					return filters, args, nil
+				}
+
+				return nil, nil, lazyerrors.Error(err)
+			}
+
+			// other operators are not pushed down
+			if k != "$eq" {
+				continue
+			}
+
+			if _, ok := v.(*types.Document); ok {
+				continue
+			}
+
+			f, a, err := filterWildcard(p, path, v)
+			if err != nil {
+				return nil, nil, lazyerrors.Error(err)
+			}
+
+			filters = append(filters, f...)
+			args = append(args, a...)
+		}
+	}
+
+	switch v := v.(type) {
+	case float64:
+		if math.IsNaN(v) || v > types.MaxSafeDouble || v < -types.MaxSafeDouble {
+			return nil, nil, nil
+		}
+
+	case int64:
+		if v > int64(types.MaxSafeDouble) || v < -int64(types.MaxSafeDouble) {
+			return nil, nil, nil
+		}
+
+	case string, types.ObjectID, bool, time.Time, int32:
+		// pushdown is supported
+
+	default:
+		// type not supported for pushdown
+		return nil, nil, nil
+	}
+
+	patterns, err := containmentPatterns(path, v)
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	conds := make([]string, len(patterns))
+
+	for i, pattern := range patterns {
+		conds[i] = `_jsonb @> ` + p.Next()
+		args = append(args, pattern)
+	}
+
+	filters = append(filters, `(`+strings.Join(conds, ` OR `)+`)`)
+
+	return filters, args, nil
+}
+
 // convertJSON transforms decoded JSON map[string]any value into *types.Document.
 func convertJSON(value any) any {
 	switch value := value.(type) {
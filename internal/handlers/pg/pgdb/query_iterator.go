@@ -37,6 +37,8 @@ type queryIterator struct {
 	rows pgx.Rows
 
 	token *resource.Token
+
+	recordID bool // rows contain record ID as the second column
 }
 
 // newIterator returns a new queryIterator for the given pgx.Rows.
@@ -55,6 +57,7 @@ func newIterator(ctx context.Context, rows pgx.Rows, p *iteratorParams) types.Do
 		unmarshal: unmarshalFunc,
 		rows:      rows,
 		token:     resource.NewToken(),
+		recordID:  p.recordID && !p.explain,
 	}
 	resource.Track(iter, iter.token)
 
@@ -100,7 +103,14 @@ func (iter *queryIterator) Next() (struct{}, *types.Document, error) {
 	}
 
 	var b []byte
-	if err := iter.rows.Scan(&b); err != nil {
+	var recordID int64
+
+	dest := []any{&b}
+	if iter.recordID {
+		dest = append(dest, &recordID)
+	}
+
+	if err := iter.rows.Scan(dest...); err != nil {
 		return unused, nil, lazyerrors.Error(err)
 	}
 
@@ -109,6 +119,8 @@ func (iter *queryIterator) Next() (struct{}, *types.Document, error) {
 		return unused, nil, lazyerrors.Error(err)
 	}
 
+	doc.SetRecordID(recordID)
+
 	return unused, doc, nil
 }
 
@@ -577,7 +577,142 @@ func TestPrepareWhereClause(t *testing.T) {
 				t.Skip(tc.skip)
 			}
 
-			actual, args, err := prepareWhereClause(new(Placeholder), tc.filter)
+			actual, args, err := prepareWhereClause(new(Placeholder), tc.filter, nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, actual)
+
+			if len(tc.args) == 0 {
+				return
+			}
+
+			assert.Equal(t, tc.args, args)
+		})
+	}
+}
+
+func TestPrepareWhereClauseWildcard(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		filter    *types.Document
+		wildcards []string
+		expected  string
+		args      []any
+	}{
+		"Root": {
+			filter:    must.NotFail(types.NewDocument("v", "foo")),
+			wildcards: []string{""},
+			expected:  ` WHERE (_jsonb @> $1 OR _jsonb @> $2)`,
+			args:      []any{`{"v":"foo"}`, `{"v":["foo"]}`},
+		},
+		"Nested": {
+			filter:    must.NotFail(types.NewDocument("v.foo", int32(42))),
+			wildcards: []string{"v"},
+			expected:  ` WHERE (_jsonb @> $1 OR _jsonb @> $2 OR _jsonb @> $3 OR _jsonb @> $4)`,
+			args: []any{
+				`{"v":{"foo":42}}`, `{"v":[{"foo":42}]}`, `{"v":{"foo":[42]}}`, `{"v":[{"foo":[42]}]}`,
+			},
+		},
+		"Eq": {
+			filter:    must.NotFail(types.NewDocument("v.foo", must.NotFail(types.NewDocument("$eq", true)))),
+			wildcards: []string{"v.foo"},
+			expected:  ` WHERE (_jsonb @> $1 OR _jsonb @> $2 OR _jsonb @> $3 OR _jsonb @> $4)`,
+		},
+		"NotCovered": {
+			filter:    must.NotFail(types.NewDocument("foo.bar", "baz")),
+			wildcards: []string{"v"},
+		},
+		"PrefixNotCovered": {
+			filter:    must.NotFail(types.NewDocument("vv.bar", "baz")),
+			wildcards: []string{"v"},
+		},
+		"ArrayIndex": {
+			filter:    must.NotFail(types.NewDocument("v.0", "foo")),
+			wildcards: []string{""},
+		},
+		"ID": {
+			filter:    must.NotFail(types.NewDocument("_id", "foo")),
+			wildcards: []string{""},
+			expected:  " WHERE _jsonb->$1 @> $2",
+		},
+		"Document": {
+			filter:    must.NotFail(types.NewDocument("v.foo", must.NotFail(types.NewDocument("bar", "baz")))),
+			wildcards: []string{""},
+		},
+		"TooLong": {
+			filter:    must.NotFail(types.NewDocument("a.b.c.d.e", "foo")),
+			wildcards: []string{""},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, args, err := prepareWhereClause(new(Placeholder), tc.filter, &pushdownIndexes{wildcards: tc.wildcards})
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, actual)
+
+			if len(tc.args) == 0 {
+				return
+			}
+
+			assert.Equal(t, tc.args, args)
+		})
+	}
+}
+
+func TestPrepareWhereClauseHashed(t *testing.T) {
+	t.Parallel()
+
+	where := ` WHERE jsonb_hash_extended(_jsonb->$1, 0) = jsonb_hash_extended($2::jsonb, 0) AND _jsonb->$1 = $2::jsonb`
+
+	for name, tc := range map[string]struct {
+		filter   *types.Document
+		expected string
+		args     []any
+	}{
+		"String": {
+			filter:   must.NotFail(types.NewDocument("v", "foo")),
+			expected: where,
+			args:     []any{"v", `"foo"`},
+		},
+		"Eq": {
+			filter:   must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$eq", int32(42))))),
+			expected: where,
+			args:     []any{"v", `42`},
+		},
+		"DotNotation": {
+			filter: must.NotFail(types.NewDocument("v.foo", true)),
+			expected: ` WHERE jsonb_hash_extended(_jsonb->$1->$2, 0) = jsonb_hash_extended($3::jsonb, 0) ` +
+				`AND _jsonb->$1->$2 = $3::jsonb`,
+			args: []any{"v", "foo", `true`},
+		},
+		"NotHashed": {
+			filter:   must.NotFail(types.NewDocument("foo", "bar")),
+			expected: " WHERE _jsonb->$1 @> $2",
+		},
+		"Ne": {
+			filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$ne", "foo")))),
+			expected: ` WHERE NOT ( _jsonb ? $1 AND _jsonb->$1 @> $2 AND ` +
+				`_jsonb->'$s'->'p'->$1->'t' = '"string"' )`,
+		},
+		"Document": {
+			filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("foo", "bar")))),
+		},
+		"LargeDouble": {
+			filter:   must.NotFail(types.NewDocument("v", float64(1<<60))),
+			expected: " WHERE _jsonb->$1 > $2",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			indexes := &pushdownIndexes{hashed: []string{"v", "v.foo"}}
+
+			actual, args, err := prepareWhereClause(new(Placeholder), tc.filter, indexes)
 			require.NoError(t, err)
 
 			assert.Equal(t, tc.expected, actual)
@@ -18,10 +18,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 // ServerStats describes statistics for all the FerretDB databases.
@@ -32,7 +36,7 @@ type ServerStats struct {
 // DBStats describes statistics for a FerretDB database (PostgreSQL schema).
 //
 // MongoDB uses "numbers" for those values that could be int32 or int64.
-// FerretDB always returns int64 for simplicity.
+// FerretDB returns int64 for simplicity unless MongoDB types are enabled by common.NumericTypesMongoDB.
 //
 // Include more data.
 // TODO https://github.com/FerretDB/FerretDB/issues/2447
@@ -42,13 +46,14 @@ type DBStats struct {
 	CountIndexes     int64
 	SizeTotal        int64
 	SizeIndexes      int64
-	SizeCollections  int64
+	SizeCollections  int64 // total BSON size of all documents
+	SizeStorage      int64 // disk space used by tables, excluding indexes
 }
 
 // CollStats describes statistics for a FerretDB collection (PostgreSQL table).
 //
 // MongoDB uses "numbers" for those values that could be int32 or int64.
-// FerretDB always returns int64 for simplicity.
+// FerretDB returns int64 for simplicity unless MongoDB types are enabled by common.NumericTypesMongoDB.
 //
 // Include more data.
 // TODO https://github.com/FerretDB/FerretDB/issues/2447
@@ -57,7 +62,7 @@ type CollStats struct {
 	CountIndexes   int64
 	SizeTotal      int64
 	SizeIndexes    int64
-	SizeCollection int64
+	SizeCollection int64 // total BSON size of all documents
 }
 
 // CalculateServerStats returns statistics for all the FerretDB databases on the server.
@@ -131,11 +136,26 @@ func CalculateDBStats(ctx context.Context, tx pgx.Tx, db string) (*DBStats, erro
 
 	row = tx.QueryRow(ctx, sql, args...)
 	if err := row.Scan(
-		&res.CountCollections, &res.CountIndexes, &res.CountObjects, &res.SizeCollections, &res.SizeIndexes,
+		&res.CountCollections, &res.CountIndexes, &res.CountObjects, &res.SizeStorage, &res.SizeIndexes,
 	); err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
+	// Data size does not depend on PostgreSQL storage overhead, so it is tracked in collections metadata.
+	collections, err := Collections(ctx, tx, db)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	for _, collection := range collections {
+		size, err := newMetadataStorage(tx, db, collection).getDataSize(ctx)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		res.SizeCollections += size
+	}
+
 	return &res, nil
 }
 
@@ -167,7 +187,6 @@ func CalculateCollStats(ctx context.Context, tx pgx.Tx, db, collection string) (
 		SELECT
 			COALESCE(reltuples, 0)                   AS CountRows,
 			COALESCE(pg_total_relation_size(oid), 0) AS SizeTotal,
-			COALESCE(pg_table_size(oid), 0)          AS SizeTable,
 			COALESCE(pg_indexes_size(oid), 0)        AS SizeIndexes
 		FROM pg_class
 		WHERE oid = '%s'::regclass`,
@@ -175,7 +194,12 @@ func CalculateCollStats(ctx context.Context, tx pgx.Tx, db, collection string) (
 	)
 	row := tx.QueryRow(ctx, sql)
 
-	if err := row.Scan(&res.CountObjects, &res.SizeTotal, &res.SizeCollection, &res.SizeIndexes); err != nil {
+	if err := row.Scan(&res.CountObjects, &res.SizeTotal, &res.SizeIndexes); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// Data size does not depend on PostgreSQL storage overhead, so it is tracked in collection metadata.
+	if res.SizeCollection, err = newMetadataStorage(tx, db, collection).getDataSize(ctx); err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
@@ -192,3 +216,59 @@ func CalculateCollStats(ctx context.Context, tx pgx.Tx, db, collection string) (
 
 	return &res, nil
 }
+
+// documentsSize returns the total BSON size of documents in the given table.
+//
+// If ids is not nil, only documents with those _id values are taken into account;
+// they are locked for update, so the returned size stays valid until the end of the transaction.
+func documentsSize(ctx context.Context, tx pgx.Tx, schema, table string, ids []any) (int64, error) {
+	sql := `SELECT _jsonb FROM ` + pgx.Identifier{schema, table}.Sanitize()
+
+	var args []any
+
+	if ids != nil {
+		if len(ids) == 0 {
+			return 0, nil
+		}
+
+		var p Placeholder
+		placeholders := make([]string, len(ids))
+		args = make([]any, len(ids))
+
+		for i, id := range ids {
+			placeholders[i] = p.Next()
+			args[i] = must.NotFail(sjson.MarshalSingleValue(id))
+		}
+
+		sql += ` WHERE _jsonb->'_id' IN (` + strings.Join(placeholders, ", ") + `) FOR UPDATE`
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	defer rows.Close()
+
+	var size int64
+
+	for rows.Next() {
+		var b []byte
+		if err = rows.Scan(&b); err != nil {
+			return 0, lazyerrors.Error(err)
+		}
+
+		doc, err := sjson.Unmarshal(b)
+		if err != nil {
+			return 0, lazyerrors.Error(err)
+		}
+
+		size += int64(bson.Size(doc))
+	}
+
+	if err = rows.Err(); err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	return size, nil
+}
@@ -23,6 +23,8 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FerretDB/FerretDB/internal/util/lasterror"
 )
 
 var tracer = otel.Tracer("internal/handlers/pg/pgdb")
@@ -53,6 +55,21 @@ func (t *debugTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.T
 	span.End()
 }
 
+// lastErrorTracer implements pgx.QueryTracer. It records the last error of Query, QueryRow, and Exec calls.
+type lastErrorTracer struct {
+	t *lasterror.Tracker
+}
+
+// TraceQueryStart does nothing.
+func (t *lastErrorTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	return ctx
+}
+
+// TraceQueryEnd records the error, if any.
+func (t *lastErrorTracer) TraceQueryEnd(_ context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	t.t.Record(data.Err)
+}
+
 // multiQueryTracer implements pgx.QueryTracer. It can be used to add
 // multiple tracers.
 type multiQueryTracer struct {
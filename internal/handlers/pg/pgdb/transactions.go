@@ -42,6 +42,36 @@ func (e *transactionConflictError) Error() string {
 	return "transactionConflictError: " + e.err.Error()
 }
 
+// txKey is a context key for the outer transaction set by WithTransaction.
+type txKey struct{}
+
+// WithTransaction returns a derived context that carries the given outer transaction.
+//
+// InTransaction, InTransactionKeep, and InTransactionRetry called with that context
+// use a savepoint of the outer transaction instead of starting a new one.
+// That way, all changes are committed or rolled back together with the outer transaction.
+func WithTransaction(ctx context.Context, tx pgx.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// HasTransaction returns true if ctx carries an outer transaction set by WithTransaction.
+func HasTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(pgx.Tx)
+	return ok
+}
+
+// Begin starts a new transaction that could be passed to WithTransaction.
+//
+// The caller is responsible for committing or rolling it back.
+func (pgPool *Pool) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := pgPool.p.Begin(ctx)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return tx, nil
+}
+
 // InTransaction wraps the given function f in a transaction.
 //
 // If f returns an error, the transaction is rolled back.
@@ -78,7 +108,13 @@ func (pgPool *Pool) InTransaction(ctx context.Context, f func(pgx.Tx) error) (er
 func (pgPool *Pool) InTransactionKeep(ctx context.Context, f func(pgx.Tx) error) (err error) {
 	var tx pgx.Tx
 
-	if tx, err = pgPool.p.Begin(ctx); err != nil {
+	if outer, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		tx, err = outer.Begin(ctx)
+	} else {
+		tx, err = pgPool.p.Begin(ctx)
+	}
+
+	if err != nil {
 		err = lazyerrors.Error(err)
 		return
 	}
@@ -16,28 +16,60 @@ package pgdb
 
 import (
 	"context"
+	"errors"
 	"strings"
 
+	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 
+	"github.com/FerretDB/FerretDB/internal/bson"
 	"github.com/FerretDB/FerretDB/internal/handlers/sjson"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 // SetDocumentByID sets a document by its ID.
-// If the document is not valid, it returns *types.ValidationError.
+//
+// It returns possibly wrapped error:
+//   - *types.ValidationError - if the document is not valid.
+//   - ErrUniqueViolation - if the document violates unique index.
+//   - *ParallelArraysError - if the document has parallel arrays for some compound index.
+//   - ErrHashedArray - if the document has an array value for some hashed index field.
 func SetDocumentByID(ctx context.Context, tx pgx.Tx, qp *QueryParams, id any, doc *types.Document) (int64, error) {
 	if err := doc.ValidateData(); err != nil {
 		return 0, err
 	}
 
-	table, err := newMetadataStorage(tx, qp.DB, qp.Collection).getTableName(ctx)
+	ms := newMetadataStorage(tx, qp.DB, qp.Collection)
+
+	m, err := ms.get(ctx, false)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	if err = checkIndexKeys(ctx, tx, ms, m, doc); err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	oldSize, err := documentsSize(ctx, tx, qp.DB, m.table, []any{id})
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	res, err := setById(ctx, tx, qp.DB, m.table, qp.Comment, id, doc)
 	if err != nil {
 		return 0, err
 	}
 
-	return setById(ctx, tx, qp.DB, table, qp.Comment, id, doc)
+	if res > 0 {
+		if err = ms.addDataSize(ctx, int64(bson.Size(doc))-oldSize); err != nil {
+			return 0, lazyerrors.Error(err)
+		}
+	}
+
+	return res, nil
 }
 
 // setById sets the document by its ID from the given PostgreSQL schema and table.
@@ -55,6 +87,11 @@ func setById(ctx context.Context, tx pgx.Tx, schema, table, comment string, id a
 
 	tag, err := tx.Exec(ctx, sql, must.NotFail(sjson.Marshal(doc)), must.NotFail(sjson.MarshalSingleValue(id)))
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return 0, ErrUniqueViolation
+		}
+
 		return 0, err
 	}
 
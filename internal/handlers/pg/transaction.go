@@ -0,0 +1,75 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pg
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/session"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// transaction implements session.Transaction on top of a PostgreSQL transaction.
+type transaction struct {
+	tx pgx.Tx
+}
+
+// BeginTransaction implements handlers.Interface.
+func (h *Handler) BeginTransaction(ctx context.Context) (session.Transaction, error) {
+	dbPool, err := h.DBPool(ctx)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	tx, err := dbPool.Begin(ctx)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &transaction{tx: tx}, nil
+}
+
+// Context implements session.Transaction.
+//
+// All pgdb operations called with the returned context run inside savepoints of that transaction.
+func (t *transaction) Context(ctx context.Context) context.Context {
+	return pgdb.WithTransaction(ctx, t.tx)
+}
+
+// Commit implements session.Transaction.
+func (t *transaction) Commit(ctx context.Context) error {
+	if err := t.tx.Commit(ctx); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// Rollback implements session.Transaction.
+func (t *transaction) Rollback(ctx context.Context) error {
+	if err := t.tx.Rollback(ctx); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// check interfaces
+var (
+	_ session.Transaction = (*transaction)(nil)
+)
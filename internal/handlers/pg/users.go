@@ -0,0 +1,74 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pg
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// getUser implements common.UserGetter.
+//
+// If FerretDB can't connect to PostgreSQL with the current credentials, users are not visible,
+// and nil is returned without an error.
+func (h *Handler) getUser(ctx context.Context, db, username string) (*types.Document, error) {
+	dbPool, err := h.DBPool(ctx)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgerrcode.IsInvalidAuthorizationSpecification(pgErr.Code) {
+			h.L.Debug("getUser: failed to authenticate in PostgreSQL", zap.Error(err))
+			return nil, nil
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	qp := pgdb.QueryParams{
+		DB:         common.UsersDatabase,
+		Collection: common.UsersCollection,
+		Filter:     must.NotFail(types.NewDocument("_id", common.UserID(db, username))),
+	}
+
+	var user *types.Document
+	err = dbPool.InTransaction(ctx, func(tx pgx.Tx) error {
+		iter, _, err := pgdb.QueryDocuments(ctx, tx, &qp)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		defer iter.Close()
+
+		user, err = common.FindUser(iter, db, username)
+
+		return err
+	})
+
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return user, nil
+}
@@ -30,6 +30,7 @@ func init() {
 			L:             opts.Logger,
 			ConnMetrics:   opts.ConnMetrics,
 			StateProvider: opts.StateProvider,
+			NumericTypes:  opts.NumericTypes,
 
 			DisableFilterPushdown: opts.DisableFilterPushdown,
 			EnableSortPushdown:    opts.EnableSortPushdown,
@@ -22,6 +22,7 @@ import (
 
 	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
 	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/util/state"
 )
 
@@ -40,6 +41,7 @@ type NewHandlerOpts struct {
 	Logger        *zap.Logger
 	ConnMetrics   *connmetrics.ConnMetrics
 	StateProvider *state.Provider
+	NumericTypes  common.NumericTypes
 
 	// for `pg` handler
 	PostgreSQLURL string
@@ -57,6 +59,12 @@ type NewHandlerOpts struct {
 type TestOpts struct {
 	DisableFilterPushdown bool
 	EnableSortPushdown    bool
+
+	// for `sqlite` and new `pg` handlers
+	Backends            map[string]string
+	BackendsMappingFile string
+	CacheCollections    map[string]int
+	ArchiveURL          string
 }
 
 // NewHandler constructs a new handler.
@@ -33,6 +33,11 @@ func init() {
 			StateProvider: opts.StateProvider,
 
 			DisableFilterPushdown: opts.DisableFilterPushdown,
+			EnableSortPushdown:    opts.EnableSortPushdown,
+			Backends:              opts.Backends,
+			BackendsMappingFile:   opts.BackendsMappingFile,
+			CacheCollections:      opts.CacheCollections,
+			ArchiveURL:            opts.ArchiveURL,
 		}
 
 		return sqlite.New(handlerOpts)
@@ -0,0 +1,284 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// aggregatePushdown returns parameters of the backend aggregation for a prefix of the given pipeline stages
+// and the number of stages in that prefix, or nil and 0 if there is no such prefix.
+//
+// The prefix consists of $match stages with equality conditions on string, ObjectID and boolean values
+// and inclusion $project stages (like the one added by the pipeline optimization),
+// followed by the $group stage with null or field path key and $count, $sum and $avg accumulators of field paths.
+// $sort stages before $group are not pushed down, because they change the order of groups.
+// The handler processes the remaining stages itself.
+func aggregatePushdown(stagesDocs []any) (*backends.AggregateParams, int) {
+	var res backends.AggregateParams
+
+	// top-level fields kept by $project stages; nil if all fields are kept
+	var kept map[string]struct{}
+
+	isKept := func(path types.Path) bool {
+		if kept == nil {
+			return true
+		}
+
+		_, ok := kept[path.Prefix()]
+
+		return ok
+	}
+
+	for i, s := range stagesDocs {
+		stage, ok := s.(*types.Document)
+		if !ok || stage.Len() != 1 {
+			return nil, 0
+		}
+
+		switch stage.Command() {
+		case "$match":
+			filter, ok := must.NotFail(stage.Get("$match")).(*types.Document)
+			if !ok {
+				return nil, 0
+			}
+
+			conditions, ok := exactFilterPushdown(filter)
+			if !ok {
+				return nil, 0
+			}
+
+			for _, c := range conditions {
+				if !isKept(c.Path) {
+					return nil, 0
+				}
+			}
+
+			res.Filter = append(res.Filter, conditions...)
+
+		case "$project":
+			spec, ok := must.NotFail(stage.Get("$project")).(*types.Document)
+			if !ok {
+				return nil, 0
+			}
+
+			fields := inclusionFields(spec)
+			if fields == nil {
+				return nil, 0
+			}
+
+			if kept != nil {
+				for f := range fields {
+					if _, ok := kept[f]; !ok {
+						delete(fields, f)
+					}
+				}
+			}
+
+			kept = fields
+
+		case "$group":
+			spec, ok := must.NotFail(stage.Get("$group")).(*types.Document)
+			if !ok || !groupPushdown(spec, &res) {
+				return nil, 0
+			}
+
+			if res.GroupBy != nil && !isKept(*res.GroupBy) {
+				return nil, 0
+			}
+
+			for _, a := range res.Accumulators {
+				if a.Op != backends.AggregateCount && !isKept(a.Path) {
+					return nil, 0
+				}
+			}
+
+			return &res, i + 1
+
+		default:
+			return nil, 0
+		}
+	}
+
+	return nil, 0
+}
+
+// inclusionFields returns top-level fields kept by the given $project stage specification,
+// or nil if it is not an inclusion projection of top-level fields.
+func inclusionFields(spec *types.Document) map[string]struct{} {
+	res := map[string]struct{}{"_id": {}}
+
+	values := spec.Values()
+
+	for i, k := range spec.Keys() {
+		if strings.HasPrefix(k, "$") || strings.Contains(k, ".") {
+			return nil
+		}
+
+		switch v := values[i]; {
+		case v == true || v == int32(1):
+			res[k] = struct{}{}
+		case k == "_id" && (v == false || v == int32(0)):
+			delete(res, k)
+		default:
+			return nil
+		}
+	}
+
+	return res
+}
+
+// exactFilterPushdown returns filter conditions that select exactly documents matching the given filter,
+// and false if some part of the filter can't be pushed down.
+func exactFilterPushdown(filter *types.Document) ([]backends.FilterCondition, bool) {
+	res := make([]backends.FilterCondition, 0, filter.Len())
+
+	values := filter.Values()
+
+	for i, k := range filter.Keys() {
+		if strings.HasPrefix(k, "$") {
+			return nil, false
+		}
+
+		path, err := types.NewPathFromString(k)
+		if err != nil {
+			return nil, false
+		}
+
+		v := values[i]
+
+		if doc, ok := v.(*types.Document); ok {
+			if doc.Len() != 1 || doc.Command() != "$eq" {
+				return nil, false
+			}
+
+			v = must.NotFail(doc.Get("$eq"))
+		}
+
+		switch v.(type) {
+		case string, types.ObjectID, bool:
+			res = append(res, backends.FilterCondition{Path: path, Op: backends.FilterEq, Value: v})
+		default:
+			return nil, false
+		}
+	}
+
+	return res, true
+}
+
+// groupPushdown sets the group key and accumulators of the given $group stage specification to params,
+// and returns false if it can't be pushed down.
+func groupPushdown(spec *types.Document, params *backends.AggregateParams) bool {
+	var hasID bool
+
+	names := make(map[string]struct{}, spec.Len())
+
+	values := spec.Values()
+
+	for i, k := range spec.Keys() {
+		v := values[i]
+
+		if _, ok := names[k]; ok {
+			return false
+		}
+
+		names[k] = struct{}{}
+
+		if k == "_id" {
+			switch v := v.(type) {
+			case types.NullType:
+				// all documents form a single group
+			case string:
+				path, ok := fieldPath(v)
+				if !ok {
+					return false
+				}
+
+				params.GroupBy = &path
+			default:
+				return false
+			}
+
+			hasID = true
+
+			continue
+		}
+
+		acc, ok := v.(*types.Document)
+		if !ok || acc.Len() != 1 {
+			return false
+		}
+
+		op, arg := acc.Command(), must.NotFail(acc.Get(acc.Command()))
+
+		var a backends.AggregateAccumulator
+
+		switch op {
+		case "$count":
+			a = backends.AggregateAccumulator{Name: k, Op: backends.AggregateCount}
+
+		case "$sum":
+			// {$sum: 1} counts documents
+			if arg == int32(1) {
+				a = backends.AggregateAccumulator{Name: k, Op: backends.AggregateCount}
+				break
+			}
+
+			fallthrough
+
+		case "$avg":
+			s, ok := arg.(string)
+			if !ok {
+				return false
+			}
+
+			path, ok := fieldPath(s)
+			if !ok {
+				return false
+			}
+
+			a = backends.AggregateAccumulator{Name: k, Op: backends.AggregateSum, Path: path}
+			if op == "$avg" {
+				a.Op = backends.AggregateAvg
+			}
+
+		default:
+			return false
+		}
+
+		params.Accumulators = append(params.Accumulators, a)
+	}
+
+	return hasID
+}
+
+// fieldPath returns the path of the given field path expression like "$a.b",
+// and false if it is not a field path.
+func fieldPath(expr string) (types.Path, bool) {
+	if !strings.HasPrefix(expr, "$") || strings.HasPrefix(expr, "$$") {
+		return types.Path{}, false
+	}
+
+	path, err := types.NewPathFromString(strings.TrimPrefix(expr, "$"))
+	if err != nil {
+		return types.Path{}, false
+	}
+
+	return path, true
+}
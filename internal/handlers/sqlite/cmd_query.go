@@ -22,7 +22,7 @@ import (
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
 	"github.com/FerretDB/FerretDB/internal/types"
-	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
@@ -33,22 +33,20 @@ func (h *Handler) CmdQuery(ctx context.Context, query *wire.OpQuery) (*wire.OpRe
 
 	// both are valid and are allowed to be run against any database as we don't support authorization yet
 	if (cmd == "ismaster" || cmd == "isMaster") && strings.HasSuffix(collection, ".$cmd") {
-		return common.IsMaster()
+		return common.IsMaster(ctx, query.Query, h.getUser)
 	}
 
 	// TODO https://github.com/FerretDB/FerretDB/issues/3008
 	// defaults to the database name if supplied on the connection string or $external
 	if cmd == "saslStart" && strings.HasSuffix(collection, ".$cmd") {
-		var emptyPayload types.Binary
+		res, err := common.SASLStart(ctx, query.Query, h.getUser)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
 
 		return &wire.OpReply{
 			NumberReturned: 1,
-			Documents: []*types.Document{must.NotFail(types.NewDocument(
-				"conversationId", int32(1),
-				"done", true,
-				"payload", emptyPayload,
-				"ok", float64(1),
-			))},
+			Documents:      []*types.Document{res},
 		}, nil
 	}
 
@@ -0,0 +1,51 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// countPushdown returns parameters for counting documents matching the given filter by the backend,
+// or nil if documents can't be counted there.
+//
+// Without filter, the backend may return an estimated count, like MongoDB's fast count does.
+func countPushdown(filter *types.Document) *backends.CountParams {
+	if filter.Len() == 0 {
+		return &backends.CountParams{Estimated: true}
+	}
+
+	conditions, ok := exactFilterPushdown(filter)
+	if !ok {
+		return nil
+	}
+
+	return &backends.CountParams{Filter: conditions}
+}
+
+// countSkipLimit returns the number of documents left from n documents after applying skip and limit;
+// zero limit means no limit.
+func countSkipLimit(n, skip, limit int64) int64 {
+	if n -= skip; n < 0 {
+		return 0
+	}
+
+	if limit > 0 && n > limit {
+		return limit
+	}
+
+	return n
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"math"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// rangeFilterOps maps range query operators to backend filter operators.
+var rangeFilterOps = map[string]backends.FilterOp{
+	"$gt":  backends.FilterGt,
+	"$gte": backends.FilterGte,
+	"$lt":  backends.FilterLt,
+	"$lte": backends.FilterLte,
+}
+
+// filterPushdown returns filter conditions that could be pushed down to the backend for the given filter.
+//
+// Only equality, range and $in conditions on scalar values of top-level fields
+// (including dot notation paths and conditions nested in $and) are returned;
+// other parts of the filter are ignored.
+// The handler should still filter returned documents.
+func filterPushdown(filter *types.Document) []backends.FilterCondition {
+	var res []backends.FilterCondition
+
+	values := filter.Values()
+
+	for i, k := range filter.Keys() {
+		v := values[i]
+
+		if k == "$and" {
+			arr, ok := v.(*types.Array)
+			if !ok {
+				continue
+			}
+
+			for i := 0; i < arr.Len(); i++ {
+				if expr, ok := must.NotFail(arr.Get(i)).(*types.Document); ok {
+					res = append(res, filterPushdown(expr)...)
+				}
+			}
+
+			continue
+		}
+
+		// other top-level operators are not pushed down
+		if strings.HasPrefix(k, "$") {
+			continue
+		}
+
+		path, err := types.NewPathFromString(k)
+		if err != nil {
+			continue
+		}
+
+		res = append(res, fieldFilterPushdown(path, v)...)
+	}
+
+	return res
+}
+
+// fieldFilterPushdown returns filter conditions for the given path and filter value or operators document.
+func fieldFilterPushdown(path types.Path, v any) []backends.FilterCondition {
+	doc, ok := v.(*types.Document)
+	if !ok {
+		if !pushdownScalar(v) {
+			return nil
+		}
+
+		return []backends.FilterCondition{{Path: path, Op: backends.FilterEq, Value: v}}
+	}
+
+	// documents without operators are compared as a whole
+	for _, k := range doc.Keys() {
+		if !strings.HasPrefix(k, "$") {
+			return nil
+		}
+	}
+
+	var res []backends.FilterCondition
+
+	opValues := doc.Values()
+
+	for i, op := range doc.Keys() {
+		opV := opValues[i]
+
+		switch op {
+		case "$eq":
+			if pushdownScalar(opV) {
+				res = append(res, backends.FilterCondition{Path: path, Op: backends.FilterEq, Value: opV})
+			}
+
+		case "$gt", "$gte", "$lt", "$lte":
+			// range conditions are pushed down only for numbers and strings
+			switch opV.(type) {
+			case types.ObjectID, bool:
+				continue
+			}
+
+			if !pushdownScalar(opV) {
+				continue
+			}
+
+			res = append(res, backends.FilterCondition{Path: path, Op: rangeFilterOps[op], Value: opV})
+
+		case "$in":
+			arr, ok := opV.(*types.Array)
+			if !ok || arr.Len() == 0 {
+				continue
+			}
+
+			values := make([]any, arr.Len())
+
+			for i := range values {
+				values[i] = must.NotFail(arr.Get(i))
+				if !pushdownScalar(values[i]) {
+					values = nil
+					break
+				}
+			}
+
+			if values != nil {
+				res = append(res, backends.FilterCondition{Path: path, Op: backends.FilterIn, Value: values})
+			}
+		}
+	}
+
+	return res
+}
+
+// pushdownScalar returns true if the given filter value could be pushed down to the backend.
+func pushdownScalar(v any) bool {
+	switch v := v.(type) {
+	case float64:
+		return !math.IsNaN(v) && !math.IsInf(v, 0)
+	case int32, int64, string, types.ObjectID, bool:
+		return true
+	default:
+		return false
+	}
+}
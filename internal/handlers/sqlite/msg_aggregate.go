@@ -21,6 +21,8 @@ import (
 	"math"
 	"time"
 
+	"go.uber.org/zap"
+
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
 	"github.com/FerretDB/FerretDB/internal/clientconn/cursor"
@@ -45,13 +47,23 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 
 	common.Ignored(document, h.L, "lsid")
 
-	if err = common.Unimplemented(document, "explain", "collation", "let"); err != nil {
+	if err = common.Unimplemented(document, "explain", "let"); err != nil {
+		return nil, err
+	}
+
+	collationDoc, err := common.GetOptionalParam[*types.Document](document, "collation", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// validate the explicit collation before accessing the database
+	if _, err = common.GetCollation(collationDoc); err != nil {
 		return nil, err
 	}
 
 	common.Ignored(
 		document, h.L,
-		"allowDiskUse", "bypassDocumentValidation", "readConcern", "hint", "comment", "writeConcern",
+		"allowDiskUse", "readConcern", "comment", "writeConcern",
 	)
 
 	var db string
@@ -78,6 +90,10 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		)
 	}
 
+	if err = h.checkArchived(document.Command(), db, collection); err != nil {
+		return nil, err
+	}
+
 	dbPool, err := h.b.Database(db)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
@@ -89,7 +105,17 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 	}
 	defer dbPool.Close()
 
-	c, err := dbPool.Collection(collection)
+	view, err := getView(ctx, dbPool, db, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	collectionName := collection
+	if view != nil {
+		collectionName = view.Collection
+	}
+
+	c, err := dbPool.Collection(collectionName)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
 			msg := fmt.Sprintf("Invalid collection name: %s", collection)
@@ -99,6 +125,18 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		return nil, lazyerrors.Error(err)
 	}
 
+	collation, err := collectionCollation(ctx, dbPool, collectionName, collationDoc)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	hint, _ := document.Get("hint")
+
+	hintIndex, err := getHintIndex(ctx, c, hint)
+	if err != nil {
+		return nil, err
+	}
+
 	username, _ := conninfo.Get(ctx).Auth()
 
 	v, _ := document.Get("maxTimeMS")
@@ -175,45 +213,25 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		)
 	}
 
-	aggregationStages := must.NotFail(iterator.ConsumeValues(pipeline.Iterator()))
-	stagesDocuments := make([]aggregations.Stage, 0, len(aggregationStages))
-	collStatsDocuments := make([]aggregations.Stage, 0, len(aggregationStages))
-
-	for i, v := range aggregationStages {
-		var d *types.Document
+	// all stages are parsed and validated before execution
+	p, err := stages.ParsePipeline(pipeline, document.Command())
+	if err != nil {
+		h.L.Debug("Invalid aggregation pipeline", zap.Error(err))
+		return nil, err
+	}
 
-		if d, ok = v.(*types.Document); !ok {
-			return nil, commonerrors.NewCommandErrorMsgWithArgument(
-				commonerrors.ErrTypeMismatch,
-				"Each element of the 'pipeline' array must be an object",
-				document.Command(),
-			)
-		}
+	if err = p.Optimize(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
 
-		var s aggregations.Stage
+	// stages of the optimized pipeline are used for pushdown
+	aggregationStages := p.Docs()
 
-		if s, err = stages.NewStage(d); err != nil {
-			return nil, err
-		}
+	stagesDocuments := p.DocumentsStages()
+	collStatsDocuments := p.AllStages()
 
-		switch d.Command() {
-		case "$collStats":
-			if i > 0 {
-				// Add a test to cover this error.
-				// TODO https://github.com/FerretDB/FerretDB/issues/2349
-				return nil, commonerrors.NewCommandErrorMsgWithArgument(
-					commonerrors.ErrCollStatsIsNotFirstStage,
-					"$collStats is only valid as the first stage in a pipeline",
-					document.Command(),
-				)
-			}
-
-			collStatsDocuments = append(collStatsDocuments, s)
-		default:
-			stagesDocuments = append(stagesDocuments, s)
-			collStatsDocuments = append(collStatsDocuments, s) // It's possible to apply any stage after $collStats stage
-		}
-	}
+	// $out or $merge stage that writes pipeline results, if any
+	output := p.Output()
 
 	// validate cursor after validating pipeline stages to keep compatibility
 	v, _ = document.Get("cursor")
@@ -249,13 +267,17 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 
 	cancel := func() {}
 	if maxTimeMS != 0 {
-		// It is not clear if maxTimeMS affects only aggregate, or both aggregate and getMore (as the current code does).
+		// Like in MongoDB, maxTimeMS limits both aggregate and getMore commands for the created cursor;
+		// unlike MongoDB, the time between getMore commands is counted too.
 		// TODO https://github.com/FerretDB/FerretDB/issues/2983
 		ctx, cancel = context.WithTimeout(ctx, time.Duration(maxTimeMS)*time.Millisecond)
 	}
 
 	closer := iterator.NewMultiCloser(iterator.CloserFunc(cancel))
 
+	// stages compare strings using the collation from the context
+	ctx = common.WithCollation(ctx, collation)
+
 	var iter iterator.Interface[struct{}, *types.Document]
 
 	// TODO https://github.com/FerretDB/FerretDB/issues/2775
@@ -271,25 +293,76 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 
 	// TODO https://github.com/FerretDB/FerretDB/issues/3235
 	// TODO https://github.com/FerretDB/FerretDB/issues/3181
-	iter, err = processStagesDocuments(ctx, closer, &stagesDocumentsParams{c, stagesDocuments})
+	sp := &stagesDocumentsParams{
+		c:      c,
+		sample: stages.GetPushdownSample(aggregationStages),
+		stages: stagesDocuments,
+	}
+
+	if view != nil {
+		var viewStagesDocuments []aggregations.Stage
+		if viewStagesDocuments, err = viewStages(view); err != nil {
+			closer.Close()
+			return nil, err
+		}
+
+		// the view pipeline is applied before the given one, so $sample can't be pushed down
+		sp.sample = 0
+		sp.stages = append(viewStagesDocuments, stagesDocuments...)
+	}
+
+	if hintIndex != nil {
+		sp.index = hintIndex.Name
+	}
+
+	// the backend can't use the hinted index or compare strings using the collation,
+	// and view stages are applied first
+	if !h.DisableFilterPushdown && view == nil && hintIndex == nil && collation == nil {
+		sp.aggregate, sp.aggregateStages = aggregatePushdown(aggregationStages)
+	}
+
+	iter, err = processStagesDocuments(ctx, closer, sp)
 
 	if err != nil {
 		closer.Close()
-		return nil, err
+		return nil, common.CheckMaxTimeMS(err)
+	}
+
+	if output != nil {
+		// authorization of bypassDocumentValidation is checked by the caller
+		bypass, err := common.GetOptionalParam(document, "bypassDocumentValidation", false)
+		if err != nil {
+			closer.Close()
+			return nil, err
+		}
+
+		if err = h.writeAggregationOutput(ctx, db, output, iter, bypass); err != nil {
+			closer.Close()
+			return nil, err
+		}
+
+		// $out and $merge stages return no documents
+		iter = iterator.Values(iterator.ForSlice([]*types.Document{}))
 	}
 
 	closer.Add(iter)
 
+	comment, _ := document.Get("comment")
+
 	cursor := h.cursors.NewCursor(ctx, &cursor.NewParams{
-		Iter:       iterator.WithClose(iter, closer.Close),
-		DB:         db,
-		Collection: collection,
-		Username:   username,
+		Iter:               iterator.WithClose(iter, closer.Close),
+		DB:                 db,
+		Collection:         collection,
+		Username:           username,
+		Client:             conninfo.Get(ctx).PeerAddr,
+		OriginatingCommand: document.DeepCopy(),
+		Comment:            comment,
+		QueryShapeHash:     common.QueryShapeHash(document),
 	})
 
 	cursorID := cursor.ID
 
-	firstBatchDocs, err := iterator.ConsumeValuesN(iterator.Interface[struct{}, *types.Document](cursor), int(batchSize))
+	firstBatchDocs, done, err := common.ConsumeBatch(cursor, int(batchSize))
 	if err != nil {
 		cursor.Close()
 		return nil, lazyerrors.Error(err)
@@ -300,7 +373,7 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		firstBatch.Append(doc)
 	}
 
-	if firstBatch.Len() < int(batchSize) {
+	if done {
 		// let the client know that there are no more results
 		cursorID = 0
 
@@ -313,7 +386,7 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 			"cursor", must.NotFail(types.NewDocument(
 				"firstBatch", firstBatch,
 				"id", cursorID,
-				"ns", db+"."+collection,
+				"ns", cursor.NS(),
 			)),
 			"ok", float64(1),
 		))},
@@ -325,12 +398,36 @@ func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 // stagesDocumentsParams contains the parameters for processStagesDocuments.
 type stagesDocumentsParams struct {
 	c      backends.Collection
+	sample int64  // the size of $sample stage to pushdown, if any
+	index  string // the name of the index selected by the hint, if any
 	stages []aggregations.Stage
+
+	aggregate       *backends.AggregateParams // the aggregation to pushdown, if any
+	aggregateStages int                       // the number of first stages replaced by that aggregation
 }
 
 // processStagesDocuments retrieves the documents from the database and then processes them through the stages.
+//
+// If the backend executes the aggregation pushdown, its results are processed through the remaining stages.
 func processStagesDocuments(ctx context.Context, closer *iterator.MultiCloser, p *stagesDocumentsParams) (types.DocumentsIterator, error) { //nolint:lll // for readability
-	queryRes, err := p.c.Query(ctx, nil)
+	if p.aggregate != nil {
+		aggregateRes, err := p.c.Aggregate(ctx, p.aggregate)
+		if err != nil {
+			closer.Close()
+			return nil, lazyerrors.Error(err)
+		}
+
+		if aggregateRes.Iter != nil {
+			closer.Add(aggregateRes.Iter)
+
+			return processStages(ctx, closer, aggregateRes.Iter, p.stages[p.aggregateStages:])
+		}
+	}
+
+	queryRes, err := p.c.Query(ctx, &backends.QueryParams{
+		Sample: p.sample,
+		Index:  p.index,
+	})
 	if err != nil {
 		closer.Close()
 		return nil, lazyerrors.Error(err)
@@ -338,9 +435,14 @@ func processStagesDocuments(ctx context.Context, closer *iterator.MultiCloser, p
 
 	closer.Add(queryRes.Iter)
 
-	iter := queryRes.Iter
+	return processStages(ctx, closer, queryRes.Iter, p.stages)
+}
+
+// processStages processes documents through the given stages.
+func processStages(ctx context.Context, closer *iterator.MultiCloser, iter types.DocumentsIterator, stagesDocuments []aggregations.Stage) (types.DocumentsIterator, error) { //nolint:lll // for readability
+	var err error
 
-	for _, s := range p.stages {
+	for _, s := range stagesDocuments {
 		if iter, err = s.Process(ctx, iter, closer); err != nil {
 			return nil, err
 		}
@@ -348,3 +450,211 @@ func processStagesDocuments(ctx context.Context, closer *iterator.MultiCloser, p
 
 	return iter, nil
 }
+
+// writeAggregationOutput consumes all documents returned by the pipeline
+// and writes them to the target collection of $out or $merge stage.
+//
+// Written documents are validated by the target collection validator unless bypass is true.
+func (h *Handler) writeAggregationOutput(ctx context.Context, db string, output aggregations.Stage, iter types.DocumentsIterator, bypass bool) error { //nolint:lll // for readability
+	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](iter))
+
+	// close the iterator before writing as the target collection may be the source one
+	iter.Close()
+
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	switch output := output.(type) {
+	case *stages.Out:
+		if output.DB != "" {
+			db = output.DB
+		}
+
+		return h.writeOut(ctx, db, output, docs, bypass)
+
+	case *stages.Merge:
+		if output.DB != "" {
+			db = output.DB
+		}
+
+		return h.writeMerge(ctx, db, output, docs, bypass)
+
+	default:
+		panic(fmt.Sprintf("unexpected output stage %T", output))
+	}
+}
+
+// outputDatabase returns the target database of $out or $merge stage.
+func (h *Handler) outputDatabase(dbName, collection string) (backends.Database, error) {
+	db, err := h.b.Database(dbName)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", dbName, collection)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, "aggregate")
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	return db, nil
+}
+
+// writeOut replaces all documents of $out stage's target collection with the given documents.
+func (h *Handler) writeOut(ctx context.Context, dbName string, out *stages.Out, docs []*types.Document, bypass bool) error {
+	if err := out.PrepareDocuments(dbName, docs); err != nil {
+		return err
+	}
+
+	db, err := h.outputDatabase(dbName, out.Collection)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	info, err := collectionInfo(ctx, db, out.Collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if info != nil && info.Capped != nil {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrStageOutCapped,
+			fmt.Sprintf("namespace '%s.%s' is capped so it can't be used for $out", dbName, out.Collection),
+			"$out (stage)",
+		)
+	}
+
+	if info != nil && !bypass {
+		if err = h.validateOutput(info.Validator, docs); err != nil {
+			return err
+		}
+	}
+
+	c, err := db.Collection(out.Collection)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid collection name: %s", out.Collection)
+			return commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, "aggregate")
+		}
+
+		return lazyerrors.Error(err)
+	}
+
+	queryRes, err := c.Query(ctx, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	existing, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](queryRes.Iter))
+	queryRes.Iter.Close()
+
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if len(existing) > 0 {
+		ids := make([]any, len(existing))
+		for i, doc := range existing {
+			ids[i] = must.NotFail(doc.Get("_id"))
+		}
+
+		if _, err = c.DeleteAll(ctx, &backends.DeleteAllParams{IDs: ids}); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if len(docs) > 0 {
+		if _, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: docs}); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// writeMerge merges the given documents into $merge stage's target collection.
+func (h *Handler) writeMerge(ctx context.Context, dbName string, merge *stages.Merge, docs []*types.Document, bypass bool) error {
+	db, err := h.outputDatabase(dbName, merge.Collection)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	c, err := db.Collection(merge.Collection)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid collection name: %s", merge.Collection)
+			return commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, "aggregate")
+		}
+
+		return lazyerrors.Error(err)
+	}
+
+	queryRes, err := c.Query(ctx, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	target, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](queryRes.Iter))
+	queryRes.Iter.Close()
+
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	insert, update, err := merge.Documents(docs, target)
+	if err != nil {
+		return err
+	}
+
+	if !bypass {
+		validator, err := collectionValidator(ctx, db, merge.Collection)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if err = h.validateOutput(validator, update); err != nil {
+			return err
+		}
+
+		if err = h.validateOutput(validator, insert); err != nil {
+			return err
+		}
+	}
+
+	// updated and inserted documents are written atomically;
+	// documents inserted concurrently after the query above are replaced
+	upsert := make([]*types.Document, 0, len(update)+len(insert))
+	upsert = append(upsert, update...)
+	upsert = append(upsert, insert...)
+
+	if len(upsert) > 0 {
+		if _, err = c.UpsertAll(ctx, &backends.UpsertAllParams{Docs: upsert}); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// validateOutput returns a command error if any of the documents written by $out or $merge stage
+// fails the target collection validator.
+func (h *Handler) validateOutput(v *backends.ValidatorParams, docs []*types.Document) error {
+	for _, doc := range docs {
+		valid, err := validateDocument(h.L, v, doc)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if !valid {
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrDocumentValidationFailure,
+				errValidationFailed,
+				"aggregate",
+			)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgApplyOps implements HandlerInterface.
+//
+// Oplog entries are applied one by one in a transaction if the backend supports them;
+// the execution stops at the first failed entry.
+func (h *Handler) MsgApplyOps(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	params, err := common.GetApplyOpsParams(document, h.L)
+	if err != nil {
+		return nil, err
+	}
+
+	// the command itself is authorized for cluster administrators only
+	for _, op := range params.Ops {
+		if op.Type == "" {
+			continue
+		}
+
+		if err = common.AuthorizeBypassDocumentValidation(ctx, document, op.DB); err != nil {
+			return nil, err
+		}
+	}
+
+	txn, err := h.BeginTransaction(ctx)
+
+	switch {
+	case err == nil:
+		ctx = txn.Context(ctx)
+	case errors.Is(err, common.ErrTransactionsNotSupported):
+		h.L.Debug("Transactions are not supported, applying oplog entries one by one.")
+	default:
+		return nil, lazyerrors.Error(err)
+	}
+
+	results, err := h.applyOps(ctx, params)
+
+	if txn != nil {
+		if err == nil {
+			err = txn.Commit(ctx)
+		} else {
+			_ = txn.Rollback(ctx)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"applied", int32(results.Len()),
+			"results", results,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// applyOps applies the given oplog entries in order and returns results of all of them.
+//
+// It returns CommandError for the first failed entry.
+func (h *Handler) applyOps(ctx context.Context, params *common.ApplyOpsParams) (*types.Array, error) {
+	bulkParams := &common.BulkWriteParams{
+		Ops:                      params.Ops,
+		Ordered:                  true,
+		BypassDocumentValidation: params.BypassDocumentValidation,
+	}
+
+	res := &bulkWriteResults{
+		batch: types.MakeArray(len(params.Ops)),
+	}
+
+	results := types.MakeArray(len(params.Ops))
+
+	for i, op := range params.Ops {
+		var err error
+
+		switch op.Type {
+		case "":
+			// no-op entry
+			res.success(i, 0)
+		case common.BulkWriteInsert:
+			_, err = h.bulkWriteInsert(ctx, bulkParams, i, 1, res)
+		case common.BulkWriteUpdate:
+			_, err = h.bulkWriteUpdate(ctx, bulkParams, i, res)
+		case common.BulkWriteDelete:
+			_, err = h.bulkWriteDelete(ctx, bulkParams, i, res)
+		default:
+			panic(fmt.Sprintf("unexpected operation type %q", op.Type))
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if res.nErrors > 0 {
+			doc := must.NotFail(res.batch.Get(res.batch.Len() - 1)).(*types.Document)
+
+			return nil, commonerrors.NewCommandErrorMsg(
+				commonerrors.ErrorCode(must.NotFail(doc.Get("code")).(int32)),
+				fmt.Sprintf("applyOps entry %d failed: %s", i, must.NotFail(doc.Get("errmsg"))),
+			)
+		}
+
+		results.Append(true)
+	}
+
+	return results, nil
+}
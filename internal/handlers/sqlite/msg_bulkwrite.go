@@ -0,0 +1,449 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgBulkWrite implements HandlerInterface.
+//
+// Operations are executed one by one, except for consecutive inserts into the same collection
+// that are inserted with a single backend call.
+// Errors of individual operations are reported in the results;
+// ordered execution stops at the first failed operation.
+func (h *Handler) MsgBulkWrite(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if dbName != "admin" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	params, err := common.GetBulkWriteParams(document, h.L)
+	if err != nil {
+		return nil, err
+	}
+
+	// the command is public, so each operation is authorized for its database
+	for i := range params.Ops {
+		op := &params.Ops[i]
+
+		if err = common.Authorize(ctx, string(op.Type), op.DB); err != nil {
+			return nil, err
+		}
+
+		if err = common.AuthorizeBypassDocumentValidation(ctx, document, op.DB); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &bulkWriteResults{
+		batch:      types.MakeArray(len(params.Ops)),
+		errorsOnly: params.ErrorsOnly,
+	}
+
+	for i := 0; i < len(params.Ops); {
+		op := &params.Ops[i]
+
+		// consecutive inserts into the same collection are batched
+		n := 1
+
+		if op.Type == common.BulkWriteInsert {
+			for i+n < len(params.Ops) {
+				next := &params.Ops[i+n]
+				if next.Type != common.BulkWriteInsert || next.DB != op.DB || next.Collection != op.Collection {
+					break
+				}
+
+				n++
+			}
+		}
+
+		var stop bool
+
+		switch op.Type {
+		case common.BulkWriteInsert:
+			stop, err = h.bulkWriteInsert(ctx, params, i, n, res)
+		case common.BulkWriteUpdate:
+			stop, err = h.bulkWriteUpdate(ctx, params, i, res)
+		case common.BulkWriteDelete:
+			stop, err = h.bulkWriteDelete(ctx, params, i, res)
+		default:
+			panic(fmt.Sprintf("unexpected operation type %q", op.Type))
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if stop {
+			break
+		}
+
+		i += n
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"cursor", must.NotFail(types.NewDocument(
+				"id", int64(0),
+				"firstBatch", res.batch,
+				"ns", "admin.$cmd.bulkWrite",
+			)),
+			"nErrors", res.nErrors,
+			"nInserted", res.nInserted,
+			"nMatched", res.nMatched,
+			"nModified", res.nModified,
+			"nUpserted", res.nUpserted,
+			"nDeleted", res.nDeleted,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// bulkWriteResults accumulates results of bulkWrite operations.
+type bulkWriteResults struct {
+	batch *types.Array
+
+	nErrors   int32
+	nInserted int32
+	nMatched  int32
+	nModified int32
+	nUpserted int32
+	nDeleted  int32
+
+	errorsOnly bool
+}
+
+// success adds the result of the successful operation with the given index.
+//
+// Pairs are additional fields of the result, like for types.NewDocument.
+func (r *bulkWriteResults) success(idx int, n int32, pairs ...any) {
+	if r.errorsOnly {
+		return
+	}
+
+	doc := must.NotFail(types.NewDocument("ok", float64(1), "idx", int32(idx), "n", n))
+
+	for i := 0; i < len(pairs); i += 2 {
+		doc.Set(pairs[i].(string), pairs[i+1])
+	}
+
+	r.batch.Append(doc)
+}
+
+// failure adds the result of the failed operation with the given index.
+//
+// It returns the given error if it is not an error of that operation, but a fatal one.
+func (r *bulkWriteResults) failure(idx int, err error) error {
+	var code commonerrors.ErrorCode
+	var msg string
+
+	var ce *commonerrors.CommandError
+	var we *commonerrors.WriteErrors
+
+	switch {
+	case errors.As(err, &ce):
+		code, msg = ce.Code(), ce.Err().Error()
+
+	case errors.As(err, &we):
+		// write errors of a single operation contain exactly one error
+		e := must.NotFail(must.NotFail(we.Document().Get("writeErrors")).(*types.Array).Get(0)).(*types.Document)
+		code = commonerrors.ErrorCode(must.NotFail(e.Get("code")).(int32))
+		msg = must.NotFail(e.Get("errmsg")).(string)
+
+	default:
+		return lazyerrors.Error(err)
+	}
+
+	r.nErrors++
+
+	r.batch.Append(must.NotFail(types.NewDocument(
+		"ok", float64(0),
+		"idx", int32(idx),
+		"code", int32(code),
+		"codeName", code.String(),
+		"errmsg", msg,
+		"n", int32(0),
+	)))
+
+	return nil
+}
+
+// bulkWriteCollection returns the database and the collection for the given bulkWrite operation.
+//
+// The caller should close the returned database.
+func (h *Handler) bulkWriteCollection(ctx context.Context, op *common.BulkWriteOp) (backends.Database, backends.Collection, error) { //nolint:lll // for readability
+	command := "bulkWrite"
+
+	if err := h.checkArchived(command, op.DB, op.Collection); err != nil {
+		return nil, nil, err
+	}
+
+	db, err := h.b.Database(op.DB)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", op.DB, op.Collection)
+			return nil, nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	if err = checkNotView(ctx, db, op.DB, op.Collection); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	c, err := db.Collection(op.Collection)
+	if err != nil {
+		db.Close()
+
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid collection name: %s", op.Collection)
+			return nil, nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	return db, c, nil
+}
+
+// bulkWriteInsert executes n consecutive insert operations into the same collection starting from the given index.
+//
+// Valid documents are inserted with a single InsertAll call;
+// if that fails because of a duplicate key, documents are inserted one by one to report errors.
+// It returns true if the execution should be stopped.
+func (h *Handler) bulkWriteInsert(ctx context.Context, params *common.BulkWriteParams, first, n int, res *bulkWriteResults) (bool, error) { //nolint:lll // for readability
+	ops := params.Ops[first : first+n]
+
+	db, c, err := h.bulkWriteCollection(ctx, &ops[0])
+	if err != nil {
+		for i := range ops {
+			if fatal := res.failure(first+i, err); fatal != nil {
+				return false, fatal
+			}
+
+			if params.Ordered {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+	defer db.Close()
+
+	var validator *backends.ValidatorParams
+
+	// authorization of bypassDocumentValidation is checked by the caller
+	if !params.BypassDocumentValidation {
+		if validator, err = collectionValidator(ctx, db, ops[0].Collection); err != nil {
+			return false, lazyerrors.Error(err)
+		}
+	}
+
+	// errors of operations; nil for successful ones
+	errs := make([]error, len(ops))
+	docs := make([]*types.Document, 0, len(ops))
+
+	for i, op := range ops {
+		doc := op.Document
+
+		if !doc.Has("_id") {
+			doc.Set("_id", types.NewObjectID())
+		}
+
+		if errs[i] = checkInsertDocument(h.L, validator, doc); errs[i] != nil && params.Ordered {
+			ops = ops[:i+1]
+			break
+		}
+
+		if errs[i] == nil {
+			docs = append(docs, doc)
+		}
+	}
+
+	if len(docs) > 0 {
+		_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: docs})
+		if err != nil && !backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID, backends.ErrorCodeIndexDuplicateKey) {
+			return false, lazyerrors.Error(err)
+		}
+
+		// nothing was inserted; downgrade to one-document batches
+		if err != nil {
+			for i, op := range ops {
+				if errs[i] != nil {
+					continue
+				}
+
+				_, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{op.Document}})
+				if err == nil {
+					continue
+				}
+
+				if !backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID, backends.ErrorCodeIndexDuplicateKey) {
+					return false, lazyerrors.Error(err)
+				}
+
+				errs[i] = commonerrors.NewCommandErrorMsg(
+					commonerrors.ErrDuplicateKeyInsert,
+					fmt.Sprintf(`E11000 duplicate key error collection: %s.%s`, op.DB, op.Collection),
+				)
+
+				if params.Ordered {
+					ops = ops[:i+1]
+					break
+				}
+			}
+		}
+	}
+
+	for i := range ops {
+		if errs[i] == nil {
+			res.nInserted++
+			res.success(first+i, 1)
+
+			continue
+		}
+
+		if err = res.failure(first+i, errs[i]); err != nil {
+			return false, err
+		}
+
+		if params.Ordered {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// bulkWriteUpdate executes the update operation with the given index.
+//
+// It returns true if the execution should be stopped.
+func (h *Handler) bulkWriteUpdate(ctx context.Context, params *common.BulkWriteParams, idx int, res *bulkWriteResults) (bool, error) { //nolint:lll // for readability
+	op := &params.Ops[idx]
+
+	// the same checks as for the other operations, updateDocument creates the collection itself
+	err := h.checkArchived("bulkWrite", op.DB, op.Collection)
+
+	var matched, modified int32
+	var upserted *types.Array
+
+	if err == nil {
+		matched, modified, upserted, err = h.updateDocument(ctx, &common.UpdatesParams{
+			DB:         op.DB,
+			Collection: op.Collection,
+			Updates: []common.UpdateParams{{
+				Filter: op.Filter,
+				Update: op.Update,
+				Multi:  op.Multi,
+				Upsert: op.Upsert,
+				Hint:   op.Hint,
+			}},
+			BypassDocumentValidation: params.BypassDocumentValidation,
+		})
+	}
+
+	if err != nil {
+		if err = res.failure(idx, err); err != nil {
+			return false, err
+		}
+
+		return params.Ordered, nil
+	}
+
+	if upserted.Len() == 0 {
+		res.nMatched += matched
+		res.nModified += modified
+		res.success(idx, matched, "nModified", modified)
+
+		return false, nil
+	}
+
+	u := must.NotFail(upserted.Get(0)).(*types.Document)
+
+	res.nUpserted++
+	res.success(idx, matched, "nModified", modified, "upserted", must.NotFail(types.NewDocument(
+		"_id", must.NotFail(u.Get("_id")),
+	)))
+
+	return false, nil
+}
+
+// bulkWriteDelete executes the delete operation with the given index.
+//
+// It returns true if the execution should be stopped.
+func (h *Handler) bulkWriteDelete(ctx context.Context, params *common.BulkWriteParams, idx int, res *bulkWriteResults) (bool, error) { //nolint:lll // for readability
+	op := &params.Ops[idx]
+
+	db, c, err := h.bulkWriteCollection(ctx, op)
+
+	var deleted int32
+
+	if err == nil {
+		var collation *common.Collation
+		if collation, err = collectionCollation(ctx, db, op.Collection, nil); err == nil {
+			deleted, err = execDelete(ctx, c, &common.Delete{
+				Filter:  op.Filter,
+				Limited: !op.Multi,
+				Hint:    op.Hint,
+			}, collation)
+		}
+
+		db.Close()
+	}
+
+	if err != nil {
+		if err = res.failure(idx, err); err != nil {
+			return false, err
+		}
+
+		return params.Ordered, nil
+	}
+
+	res.nDeleted += deleted
+	res.success(idx, deleted)
+
+	return false, nil
+}
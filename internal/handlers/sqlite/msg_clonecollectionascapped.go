@@ -0,0 +1,136 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgCloneCollectionAsCapped implements HandlerInterface.
+func (h *Handler) MsgCloneCollectionAsCapped(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	common.Ignored(document, h.L, "writeConcern", "comment")
+
+	command := document.Command()
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := common.GetRequiredParam[string](document, "toCollection")
+	if err != nil {
+		return nil, err
+	}
+
+	v, _ := document.Get("size")
+	if v == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMissingField,
+			"BSON field 'cloneCollectionAsCapped.size' is missing but a required field",
+			command,
+		)
+	}
+
+	size, err := cappedSize(v, command)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := h.b.Database(dbName)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", dbName, source)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+	defer db.Close()
+
+	if err = checkNotView(ctx, db, dbName, source); err != nil {
+		return nil, err
+	}
+
+	info, err := collectionInfo(ctx, db, source)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if info == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrNamespaceNotFound,
+			fmt.Sprintf("source collection %s.%s does not exist", dbName, source),
+			command,
+		)
+	}
+
+	view, err := getView(ctx, db, dbName, target)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if info, err = collectionInfo(ctx, db, target); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if view != nil || info != nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrNamespaceExists,
+			fmt.Sprintf("a collection '%s.%s' already exists", dbName, target),
+			command,
+		)
+	}
+
+	err = copyToCapped(ctx, db, source, target, &backends.CappedParams{Size: size})
+
+	switch {
+	case err == nil:
+		var reply wire.OpMsg
+		must.NoError(reply.SetSections(wire.OpMsgSection{
+			Documents: []*types.Document{must.NotFail(types.NewDocument(
+				"ok", float64(1),
+			))},
+		}))
+
+		return &reply, nil
+
+	case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid):
+		msg := fmt.Sprintf("Invalid collection name: %s", target)
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, command)
+
+	default:
+		_ = db.DropCollection(ctx, &backends.DropCollectionParams{Name: target})
+		return nil, lazyerrors.Error(err)
+	}
+}
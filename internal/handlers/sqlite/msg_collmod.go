@@ -16,15 +16,299 @@ package sqlite
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgCollMod implements HandlerInterface.
 func (h *Handler) MsgCollMod(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	return nil, commonerrors.NewCommandErrorMsg(
-		commonerrors.ErrNotImplemented,
-		"`collMod` command is not implemented yet",
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	unimplementedFields := []string{
+		"changeStreamPreAndPostImages",
+		"cappedSize",
+		"cappedMax",
+	}
+	if err = common.Unimplemented(document, unimplementedFields...); err != nil {
+		return nil, err
+	}
+
+	common.Ignored(document, h.L, "writeConcern", "comment")
+
+	command := document.Command()
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	collectionName, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := h.b.Database(dbName)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", dbName, collectionName)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+	defer db.Close()
+
+	viewOn, pipeline, err := common.GetViewParams(document)
+	if err != nil {
+		return nil, err
+	}
+
+	if viewOn != "" {
+		if pipeline == nil {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrInvalidOptions,
+				"Must specify both 'viewOn' and 'pipeline' when modifying a view",
+				command,
+			)
+		}
+
+		if err = modifyView(ctx, db, dbName, collectionName, viewOn, pipeline); err != nil {
+			return nil, err
+		}
+
+		var reply wire.OpMsg
+		must.NoError(reply.SetSections(wire.OpMsgSection{
+			Documents: []*types.Document{must.NotFail(types.NewDocument(
+				"ok", float64(1),
+			))},
+		}))
+
+		return &reply, nil
+	}
+
+	list, err := db.ListCollections(ctx, nil)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	i := slices.IndexFunc(list.Collections, func(c backends.CollectionInfo) bool { return c.Name == collectionName })
+	if i < 0 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrNamespaceNotFound,
+			"ns does not exist",
+			command,
+		)
+	}
+
+	var params backends.UpdateMetadataParams
+
+	if params.Validator, err = getValidatorParams(document, command, list.Collections[i].Validator); err != nil {
+		return nil, err
+	}
+
+	c, err := db.Collection(collectionName)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	replyDoc := must.NotFail(types.NewDocument())
+
+	if v, _ := document.Get("index"); v != nil {
+		indexDoc, ok := v.(*types.Document)
+		if !ok {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				fmt.Sprintf(
+					"BSON field 'collMod.index' is the wrong type '%s', expected type 'object'",
+					commonparams.AliasFromType(v),
+				),
+				command,
+			)
+		}
+
+		var index *backends.IndexInfo
+
+		index, params.ExpireAfterSeconds, err = collModIndex(ctx, c, indexDoc, command, dbName, collectionName)
+		if err != nil {
+			return nil, err
+		}
+
+		params.Index = index.Name
+
+		// like MongoDB, report the change only if there was one
+		if old := *index.ExpireAfterSeconds; old != params.ExpireAfterSeconds {
+			replyDoc.Set("expireAfterSeconds_old", old)
+			replyDoc.Set("expireAfterSeconds_new", params.ExpireAfterSeconds)
+		}
+	}
+
+	if _, err = c.UpdateMetadata(ctx, &params); err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist) {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNamespaceNotFound,
+				"ns does not exist",
+				command,
+			)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	replyDoc.Set("ok", float64(1))
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{replyDoc},
+	}))
+
+	return &reply, nil
+}
+
+// collModIndex parses the index document of collMod command.
+// It returns the existing TTL index to modify and its new expireAfterSeconds value.
+func collModIndex(ctx context.Context, c backends.Collection, indexDoc *types.Document, command, dbName, collectionName string) (*backends.IndexInfo, int64, error) { //nolint:lll // for readability
+	for _, opt := range []string{"hidden", "unique", "prepareUnique"} {
+		if indexDoc.Has(opt) {
+			return nil, 0, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				fmt.Sprintf("Index option %q is not implemented yet", opt),
+				command,
+			)
+		}
+	}
+
+	name, _ := indexDoc.Get("name")
+	keyPattern, _ := indexDoc.Get("keyPattern")
+
+	var match func(index *backends.IndexInfo) bool
+
+	switch {
+	case name != nil && keyPattern != nil:
+		return nil, 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidOptions,
+			"Cannot specify both key pattern and name.",
+			command,
+		)
+
+	case name != nil:
+		s, ok := name.(string)
+		if !ok {
+			return nil, 0, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				fmt.Sprintf(
+					"BSON field 'collMod.index.name' is the wrong type '%s', expected type 'string'",
+					commonparams.AliasFromType(name),
+				),
+				command,
+			)
+		}
+
+		match = func(index *backends.IndexInfo) bool { return index.Name == s }
+
+	case keyPattern != nil:
+		keyDoc, ok := keyPattern.(*types.Document)
+		if !ok {
+			return nil, 0, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				fmt.Sprintf(
+					"BSON field 'collMod.index.keyPattern' is the wrong type '%s', expected type 'object'",
+					commonparams.AliasFromType(keyPattern),
+				),
+				command,
+			)
+		}
+
+		key, err := processIndexKey(keyDoc)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		match = func(index *backends.IndexInfo) bool { return slices.Equal(index.Key, key) }
+
+	default:
+		return nil, 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidOptions,
+			"Must specify either index name or key pattern.",
+			command,
+		)
+	}
+
+	v, _ := indexDoc.Get("expireAfterSeconds")
+	if v == nil {
+		return nil, 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidOptions,
+			"no expireAfterSeconds or hidden field",
+			command,
+		)
+	}
+
+	ttl, err := getExpireAfterSeconds(v)
+
+	switch {
+	case err == nil:
+		// nothing
+	case errors.Is(err, commonparams.ErrUnexpectedType):
+		return nil, 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field 'collMod.index.expireAfterSeconds' is the wrong type '%s', "+
+					"expected types '[long, int, decimal, double']",
+				commonparams.AliasFromType(v),
+			),
+			command,
+		)
+	default:
+		return nil, 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"TTL index 'expireAfterSeconds' option must be within an acceptable range, try a lower number",
+			command,
+		)
+	}
+
+	res, err := c.ListIndexes(ctx, nil)
+	if err != nil {
+		return nil, 0, lazyerrors.Error(err)
+	}
+
+	for i := range res.Indexes {
+		index := &res.Indexes[i]
+		if !match(index) {
+			continue
+		}
+
+		if index.ExpireAfterSeconds == nil {
+			return nil, 0, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrInvalidOptions,
+				"no expireAfterSeconds field to update",
+				command,
+			)
+		}
+
+		return index, ttl, nil
+	}
+
+	spec := name
+	if spec == nil {
+		spec = types.FormatAnyValue(keyPattern)
+	}
+
+	return nil, 0, commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrIndexNotFound,
+		fmt.Sprintf("cannot find index %s for ns %s.%s", spec, dbName, collectionName),
+		command,
 	)
 }
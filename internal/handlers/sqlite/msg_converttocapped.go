@@ -0,0 +1,184 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// cappedCopyBatchSize is the maximum number of documents inserted into a capped collection at once
+// by convertToCapped and cloneCollectionAsCapped commands.
+const cappedCopyBatchSize = 1000
+
+// MsgConvertToCapped implements HandlerInterface.
+func (h *Handler) MsgConvertToCapped(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	common.Ignored(document, h.L, "writeConcern", "comment")
+
+	command := document.Command()
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	collectionName, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	v, _ := document.Get("size")
+	if v == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMissingField,
+			"BSON field 'convertToCapped.size' is missing but a required field",
+			command,
+		)
+	}
+
+	size, err := cappedSize(v, command)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := h.b.Database(dbName)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", dbName, collectionName)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+	defer db.Close()
+
+	if err = checkNotView(ctx, db, dbName, collectionName); err != nil {
+		return nil, err
+	}
+
+	info, err := collectionInfo(ctx, db, collectionName)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if info == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrNamespaceNotFound,
+			fmt.Sprintf("source collection %s.%s does not exist", dbName, collectionName),
+			command,
+		)
+	}
+
+	// The collection is replaced, so cursors for it would return wrong results; see MsgDrop.
+	for _, c := range h.cursors.All() {
+		if c.DB == dbName && c.Collection == collectionName {
+			c.Close()
+		}
+	}
+
+	tmpName := fmt.Sprintf("tmp%08x.convertToCapped.%s", rand.Uint32(), collectionName)
+
+	if err = copyToCapped(ctx, db, collectionName, tmpName, &backends.CappedParams{Size: size}); err != nil {
+		_ = db.DropCollection(ctx, &backends.DropCollectionParams{Name: tmpName})
+		return nil, err
+	}
+
+	if err = db.DropCollection(ctx, &backends.DropCollectionParams{Name: collectionName}); err != nil {
+		_ = db.DropCollection(ctx, &backends.DropCollectionParams{Name: tmpName})
+		return nil, lazyerrors.Error(err)
+	}
+
+	err = db.RenameCollection(ctx, &backends.RenameCollectionParams{
+		OldName: tmpName,
+		NewName: collectionName,
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// copyToCapped creates a new capped collection with the given name and parameters,
+// and inserts all documents of the source collection into it in natural order.
+//
+// If the capped collection is too small, the oldest documents are evicted, like in MongoDB.
+func copyToCapped(ctx context.Context, db backends.Database, source, target string, capped *backends.CappedParams) error {
+	err := db.CreateCollection(ctx, &backends.CreateCollectionParams{
+		Name:   target,
+		Capped: capped,
+	})
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	sc, err := db.Collection(source)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	res, err := sc.Query(ctx, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	// consume all documents before inserting to avoid holding the read transaction
+	docs, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](res.Iter))
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	tc, err := db.Collection(target)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	for len(docs) > 0 {
+		n := len(docs)
+		if n > cappedCopyBatchSize {
+			n = cappedCopyBatchSize
+		}
+
+		if _, err = tc.InsertAll(ctx, &backends.InsertAllParams{Docs: docs[:n]}); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		docs = docs[n:]
+	}
+
+	return nil
+}
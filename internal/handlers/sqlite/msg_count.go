@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
@@ -41,6 +42,13 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 		return nil, err
 	}
 
+	ctx, cancel := common.WithMaxTimeMS(ctx, params.MaxTimeMS)
+	defer cancel()
+
+	if err = h.checkArchived(document.Command(), params.DB, params.Collection); err != nil {
+		return nil, err
+	}
+
 	db, err := h.b.Database(params.DB)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
@@ -62,17 +70,50 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 		return nil, lazyerrors.Error(err)
 	}
 
-	queryRes, err := c.Query(ctx, nil)
+	hintIndex, err := getHintIndex(ctx, c, params.Hint)
+	if err != nil {
+		return nil, err
+	}
+
+	// explicit collation is not supported yet, but the default collation of the collection is used
+	collation, err := collectionCollation(ctx, db, params.Collection, nil)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
+	if !h.DisableFilterPushdown && collation == nil {
+		if cp := countPushdown(params.Filter); cp != nil {
+			var countRes *backends.CountResult
+			if countRes, err = c.Count(ctx, cp); err != nil {
+				return nil, common.CheckMaxTimeMS(err)
+			}
+
+			if countRes.CountPushdown {
+				return countReply(countSkipLimit(countRes.Count, params.Skip, params.Limit)), nil
+			}
+		}
+	}
+
+	var qp backends.QueryParams
+	if hintIndex != nil {
+		qp.Index = hintIndex.Name
+	}
+
+	if !h.DisableFilterPushdown && collation == nil {
+		qp.Filter = filterPushdown(params.Filter)
+	}
+
+	queryRes, err := c.Query(ctx, &qp)
+	if err != nil {
+		return nil, common.CheckMaxTimeMS(err)
+	}
+
 	iter := queryRes.Iter
 
 	closer := iterator.NewMultiCloser(iter)
 	defer closer.Close()
 
-	iter = common.FilterIterator(iter, closer, params.Filter)
+	iter = common.FilterIterator(iter, closer, params.Filter, collation)
 
 	iter = common.SkipIterator(iter, closer, params.Skip)
 
@@ -86,19 +127,29 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 	}
 
 	if err != nil {
-		return nil, lazyerrors.Error(err)
+		return nil, common.CheckMaxTimeMS(err)
 	}
 
 	count, _ := res.Get("count")
 	n, _ := count.(int32)
 
+	return countReply(int64(n)), nil
+}
+
+// countReply returns the reply of the count command for the given number of documents.
+func countReply(n int64) *wire.OpMsg {
+	var count any = n
+	if n <= math.MaxInt32 {
+		count = int32(n)
+	}
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"n", n,
+			"n", count,
 			"ok", float64(1),
 		))},
 	}))
 
-	return &reply, nil
+	return &reply
 }
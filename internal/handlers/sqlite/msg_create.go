@@ -21,6 +21,7 @@ import (
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -37,26 +38,11 @@ func (h *Handler) MsgCreate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	unimplementedFields := []string{
 		"timeseries",
 		"expireAfterSeconds",
-		"size",
-		"max",
-		"validator",
-		"validationLevel",
-		"validationAction",
-		"viewOn",
-		"pipeline",
-		"collation",
 	}
 	if err = common.Unimplemented(document, unimplementedFields...); err != nil {
 		return nil, err
 	}
 
-	if err = common.UnimplementedNonDefault(document, "capped", func(v any) bool {
-		b, ok := v.(bool)
-		return ok && !b
-	}); err != nil {
-		return nil, err
-	}
-
 	ignoredFields := []string{
 		"autoIndexId",
 		"storageEngine",
@@ -78,6 +64,69 @@ func (h *Handler) MsgCreate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, err
 	}
 
+	viewOn, pipeline, err := common.GetViewParams(document)
+	if err != nil {
+		return nil, err
+	}
+
+	capped, err := getCappedParams(document)
+	if err != nil {
+		return nil, err
+	}
+
+	clustered, err := getClusteredParams(document)
+	if err != nil {
+		return nil, err
+	}
+
+	if clustered && capped != nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidOptions,
+			"Clustered capped collections are not supported",
+			"create",
+		)
+	}
+
+	validator, err := getValidatorParams(document, command, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	collationDoc, err := common.GetOptionalParam[*types.Document](document, "collation", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	collation, err := common.GetCollation(collationDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	// validationLevel and validationAction without validator are not stored
+	if validator != nil && validator.Filter == nil {
+		validator = nil
+	}
+
+	if viewOn != "" {
+		for _, option := range []string{"capped", "validator", "clusteredIndex"} {
+			if v, _ := document.Get(option); v != nil {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrInvalidOptions,
+					fmt.Sprintf("option not supported on a view: %s", option),
+					"create",
+				)
+			}
+		}
+
+		if collation != nil {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				"Views with collation are not implemented yet",
+				"create",
+			)
+		}
+	}
+
 	db, err := h.b.Database(dbName)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
@@ -89,9 +138,17 @@ func (h *Handler) MsgCreate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	}
 	defer db.Close()
 
-	err = db.CreateCollection(ctx, &backends.CreateCollectionParams{
-		Name: collectionName,
-	})
+	if viewOn != "" {
+		err = createView(ctx, db, dbName, collectionName, viewOn, pipeline)
+	} else {
+		err = createCollection(ctx, db, dbName, &backends.CreateCollectionParams{
+			Name:      collectionName,
+			Capped:    capped,
+			Validator: validator,
+			Collation: collation.Document(), // nil for the simple binary comparison
+			Clustered: clustered,
+		})
+	}
 
 	switch {
 	case err == nil:
@@ -113,6 +170,210 @@ func (h *Handler) MsgCreate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrNamespaceExists, msg, "create")
 
 	default:
-		return nil, lazyerrors.Error(err)
+		return nil, err
+	}
+}
+
+// createCollection creates a new collection unless there is a view with the same name.
+func createCollection(ctx context.Context, db backends.Database, dbName string, params *backends.CreateCollectionParams) error {
+	view, err := getView(ctx, db, dbName, params.Name)
+	if err != nil {
+		return err
+	}
+
+	if view != nil {
+		return backends.NewError(backends.ErrorCodeCollectionAlreadyExists, nil)
+	}
+
+	err = db.CreateCollection(ctx, params)
+	if err != nil && !backends.ErrorCodeIs(
+		err, backends.ErrorCodeCollectionNameIsInvalid, backends.ErrorCodeCollectionAlreadyExists,
+	) {
+		return lazyerrors.Error(err)
 	}
+
+	return err
+}
+
+// cappedMinSize is the minimal size of capped collection in bytes.
+const cappedMinSize = 4096
+
+// getCappedParams returns capped collection parameters from the create command document,
+// or nil if the collection is not capped.
+func getCappedParams(document *types.Document) (*backends.CappedParams, error) {
+	v, _ := document.Get("capped")
+	if v == nil {
+		return nil, nil
+	}
+
+	capped, err := commonparams.GetBoolOptionalParam("capped", v)
+	if err != nil {
+		return nil, err
+	}
+
+	if !capped {
+		return nil, nil
+	}
+
+	v, _ = document.Get("size")
+	if v == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidOptions,
+			"the 'size' field is required when 'capped' is true",
+			"create",
+		)
+	}
+
+	size, err := cappedSize(v, "create")
+	if err != nil {
+		return nil, err
+	}
+
+	var documents int64
+
+	if v, _ = document.Get("max"); v != nil {
+		if documents, err = commonparams.GetWholeNumberParam(v); err != nil {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				fmt.Sprintf("BSON field 'max' is the wrong type '%s', expected types '[long, int, decimal, double]'",
+					commonparams.AliasFromType(v),
+				),
+				"create",
+			)
+		}
+
+		// zero or negative values mean no limit
+		if documents < 0 {
+			documents = 0
+		}
+	}
+
+	return &backends.CappedParams{
+		Size:      size,
+		Documents: documents,
+	}, nil
+}
+
+// getClusteredParams returns true if the create command document has a valid clusteredIndex option.
+//
+// Like in MongoDB, only the clustered index on {_id: 1} is supported.
+func getClusteredParams(document *types.Document) (bool, error) {
+	v, _ := document.Get("clusteredIndex")
+	if v == nil {
+		return false, nil
+	}
+
+	var spec *types.Document
+
+	switch v := v.(type) {
+	case *types.Document:
+		spec = v
+	case bool:
+		return false, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidOptions,
+			"The 'clusteredIndex' legacy format {clusteredIndex: <bool>} is only supported for specific internal collections",
+			"create",
+		)
+	default:
+		return false, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field 'create.clusteredIndex' is the wrong type '%s', expected types '[bool, object]'",
+				commonparams.AliasFromType(v),
+			),
+			"create",
+		)
+	}
+
+	for _, field := range spec.Keys() {
+		switch field {
+		case "key", "unique", "name", "v":
+		default:
+			return false, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field 'create.clusteredIndex.%s' is an unknown field.", field),
+				"create",
+			)
+		}
+	}
+
+	for _, field := range []string{"key", "unique"} {
+		if !spec.Has(field) {
+			return false, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrMissingField,
+				fmt.Sprintf("BSON field 'create.clusteredIndex.%s' is missing but a required field", field),
+				"create",
+			)
+		}
+	}
+
+	key, _ := spec.Get("key")
+	keyDoc, ok := key.(*types.Document)
+
+	if !ok || keyDoc.Len() != 1 || !keyDoc.Has("_id") {
+		return false, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidIndexSpecificationOption,
+			"The clusteredIndex option is only supported for key: {_id: 1}",
+			"create",
+		)
+	}
+
+	if order, err := commonparams.GetWholeNumberParam(must.NotFail(keyDoc.Get("_id"))); err != nil || order != 1 {
+		return false, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidIndexSpecificationOption,
+			"The clusteredIndex option is only supported for key: {_id: 1}",
+			"create",
+		)
+	}
+
+	if unique, _ := spec.Get("unique"); unique != true {
+		return false, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidIndexSpecificationOption,
+			"The clusteredIndex option requires unique: true",
+			"create",
+		)
+	}
+
+	if v, _ := spec.Get("v"); v != nil {
+		if version, err := commonparams.GetWholeNumberParam(v); err != nil || version != 2 {
+			return false, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("Invalid clustered index version: %s", types.FormatAnyValue(v)),
+				"create",
+			)
+		}
+	}
+
+	// the clustered index is reported as the default _id index, so its name can't be changed
+	if name, _ := spec.Get("name"); name != nil && name != "_id_" {
+		return false, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrNotImplemented,
+			"Custom names of the clustered index are not implemented yet",
+			"create",
+		)
+	}
+
+	return true, nil
+}
+
+// cappedSize returns the maximum size of capped collection in bytes for the given size parameter value,
+// rounded like in MongoDB.
+func cappedSize(v any, command string) (int64, error) {
+	size, err := commonparams.GetWholeNumberParam(v)
+	if err != nil || size < 0 {
+		return 0, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("BSON field 'size' value must be a non-negative number, actual value: %s", types.FormatAnyValue(v)),
+			command,
+		)
+	}
+
+	switch {
+	case size <= cappedMinSize:
+		size = cappedMinSize
+	case size%256 != 0:
+		size += 256 - size%256
+	}
+
+	return size, nil
 }
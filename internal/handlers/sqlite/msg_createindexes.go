@@ -16,22 +16,790 @@ package sqlite
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
 
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgCreateIndexes implements HandlerInterface.
 func (h *Handler) MsgCreateIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	// TODO
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	common.Ignored(document, h.L, "writeConcern", "commitQuorum", "comment")
+
+	command := document.Command()
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	if collection == "" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidNamespace,
+			fmt.Sprintf("Invalid namespace specified '%s.'", dbName),
+			command,
+		)
+	}
+
+	v, _ := document.Get("indexes")
+	if v == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMissingField,
+			"BSON field 'createIndexes.indexes' is missing but a required field",
+			command,
+		)
+	}
+
+	idxArr, ok := v.(*types.Array)
+	if !ok {
+		if _, ok = v.(types.NullType); ok {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrIndexesWrongType,
+				"invalid parameter: expected an object (indexes)",
+				command,
+			)
+		}
+
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field 'createIndexes.indexes' is the wrong type '%s', expected type 'array'",
+				commonparams.AliasFromType(v),
+			),
+			command,
+		)
+	}
+
+	if idxArr.Len() == 0 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"Must specify at least one index to create",
+			command,
+		)
+	}
+
+	db, err := h.b.Database(dbName)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", dbName, collection)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+	defer db.Close()
+
+	if err = checkNotView(ctx, db, dbName, collection); err != nil {
+		return nil, err
+	}
+
+	c, err := db.Collection(collection)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid collection name: %s", collection)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	var collCreated bool
+
+	// the default _id index is created with the collection
+	existing := []backends.IndexInfo{{
+		Name:   "_id_",
+		Key:    []backends.IndexKeyPair{{Field: "_id"}},
+		Unique: true,
+	}}
+
+	listRes, err := c.ListIndexes(ctx, nil)
+
+	switch {
+	case err == nil:
+		existing = listRes.Indexes
+	case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist):
+		collCreated = true
+	default:
+		return nil, lazyerrors.Error(err)
+	}
+
+	numIndexesBefore := int32(len(existing))
+
+	var toCreate []backends.IndexInfo
+
+	iter := idxArr.Iterator()
+	defer iter.Close()
+
+	for {
+		key, val, err := iter.Next()
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			break
+		}
+
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		indexDoc, ok := val.(*types.Document)
+		if !ok {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				fmt.Sprintf(
+					"BSON field 'createIndexes.indexes.%d' is the wrong type '%s', expected type 'object'",
+					key,
+					commonparams.AliasFromType(val),
+				),
+				command,
+			)
+		}
+
+		index, err := processIndexOptions(indexDoc)
+		if err != nil {
+			return nil, err
+		}
+
+		if index.Name == "" {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrCannotCreateIndex,
+				fmt.Sprintf(
+					"Error in specification %s :: caused by :: index name cannot be empty",
+					types.FormatAnyValue(indexDoc),
+				),
+				command,
+			)
+		}
+
+		for _, other := range toCreate {
+			if other.Name == index.Name && slices.Equal(other.Key, index.Key) {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrIndexAlreadyExists,
+					fmt.Sprintf("Identical index already exists: %s", other.Name),
+					command,
+				)
+			}
+		}
+
+		identical, err := checkIndexConflicts(append(existing, toCreate...), index, command)
+		if err != nil {
+			return nil, err
+		}
+
+		if identical {
+			continue
+		}
+
+		toCreate = append(toCreate, *index)
+	}
+
+	if len(toCreate) > 0 && !collCreated {
+		if err = checkUniqueCollation(ctx, db, collection, toCreate, command); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(toCreate) > 0 || collCreated {
+		_, err = c.CreateIndexes(ctx, &backends.CreateIndexesParams{Indexes: toCreate})
+
+		switch {
+		case err == nil:
+			// nothing
+		case backends.ErrorCodeIs(err, backends.ErrorCodeIndexDuplicateKey):
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrDuplicateKeyInsert,
+				"Index build failed",
+				command,
+			)
+		default:
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	numIndexesAfter := numIndexesBefore + int32(len(toCreate))
+
+	res := new(types.Document)
+
+	res.Set("numIndexesBefore", numIndexesBefore)
+	res.Set("numIndexesAfter", numIndexesAfter)
+
+	if numIndexesBefore != numIndexesAfter {
+		res.Set("createdCollectionAutomatically", collCreated)
+	} else {
+		res.Set("note", "all indexes already exist")
+	}
+
+	res.Set("ok", float64(1))
 
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"ok", float64(1),
-		))},
+		Documents: []*types.Document{res},
 	}))
 
 	return &reply, nil
 }
+
+// checkUniqueCollation returns an error if unique indexes are created on the collection with the default collation.
+//
+// SQLite indexes compare strings in binary, so uniqueness can't be enforced with collation.
+func checkUniqueCollation(
+	ctx context.Context, db backends.Database, collection string, indexes []backends.IndexInfo, command string,
+) error {
+	info, err := collectionInfo(ctx, db, collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if info == nil || info.Collation == nil {
+		return nil
+	}
+
+	for _, index := range indexes {
+		if index.Unique {
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				"Unique indexes on collections with default collation are not implemented yet",
+				command,
+			)
+		}
+	}
+
+	return nil
+}
+
+// checkIndexConflicts checks that the given index does not conflict with existing ones.
+//
+// It returns true if an identical index already exists.
+func checkIndexConflicts(existing []backends.IndexInfo, index *backends.IndexInfo, command string) (bool, error) {
+	for _, e := range existing {
+		sameKey := slices.Equal(e.Key, index.Key)
+
+		switch {
+		case sameKey && e.Name == index.Name && e.Unique == index.Unique &&
+			sameTTL(e.ExpireAfterSeconds, index.ExpireAfterSeconds) && sameTextIndex(e.Text, index.Text):
+			return true, nil
+
+		case sameKey && e.Name == index.Name:
+			return false, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrIndexKeySpecsConflict,
+				fmt.Sprintf("An existing index has the same name as the requested index but different options: %s", e.Name),
+				command,
+			)
+
+		// collection could have only one text index, and all of them have the same MongoDB key
+		case e.Text != nil && index.Text != nil && e.Name != index.Name:
+			return false, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrIndexOptionsConflict,
+				"An equivalent index already exists with a different name and options",
+				command,
+			)
+
+		case sameKey:
+			return false, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrIndexOptionsConflict,
+				fmt.Sprintf("Index already exists with a different name: %s", e.Name),
+				command,
+			)
+
+		case e.Name == index.Name:
+			return false, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrIndexKeySpecsConflict,
+				fmt.Sprintf(
+					"An existing index has the same name as the requested index. "+
+						"When index names are not specified, they are auto generated and can "+
+						"cause conflicts. Please refer to our documentation. "+
+						"Requested index: %s, existing index: %s",
+					types.FormatAnyValue(indexDocument(index)),
+					types.FormatAnyValue(indexDocument(&e)),
+				),
+				command,
+			)
+		}
+	}
+
+	return false, nil
+}
+
+// processIndexOptions processes the given index specification.
+func processIndexOptions(indexDoc *types.Document) (*backends.IndexInfo, error) {
+	var index backends.IndexInfo
+
+	v, _ := indexDoc.Get("key")
+	if v == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFailedToParse,
+			"Error in specification {} :: caused by :: "+
+				"The 'key' field is a required property of an index specification",
+			"createIndexes",
+		)
+	}
+
+	keyDoc, ok := v.(*types.Document)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			"'key' option must be specified as an object",
+			"createIndexes",
+		)
+	}
+
+	if keyDoc.Len() == 0 {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrCannotCreateIndex,
+			"Must specify at least one field for the index key",
+			"createIndexes",
+		)
+	}
+
+	if err := common.ValidateIndexKey("createIndexes", keyDoc); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if index.Key, err = processIndexKey(keyDoc); err != nil {
+		return nil, err
+	}
+
+	if len(index.Key) == 1 && index.Key[0].Field == "_id" && index.Key[0].Descending {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			"The field 'key' for an _id index must be {_id: 1}, but got { _id: -1 }",
+			"createIndexes",
+		)
+	}
+
+	v, _ = indexDoc.Get("name")
+	if v == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrFailedToParse,
+			fmt.Sprintf(
+				"Error in specification { key: %s } :: caused by :: "+
+					"The 'name' field is a required property of an index specification",
+				types.FormatAnyValue(keyDoc),
+			),
+			"createIndexes",
+		)
+	}
+
+	if index.Name, ok = v.(string); !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			"'name' option must be specified as a string",
+			"createIndexes",
+		)
+	}
+
+	for _, opt := range indexDoc.Keys() {
+		switch opt {
+		case "key", "name", "v":
+			// already processed or ignored, do nothing
+
+		case "unique":
+			v := must.NotFail(indexDoc.Get("unique"))
+
+			unique, ok := v.(bool)
+			if !ok {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrTypeMismatch,
+					fmt.Sprintf(
+						"Error in specification { key: %s, name: \"%s\", unique: %s } "+
+							":: caused by :: "+
+							"The field 'unique' has value unique: %[3]s, which is not convertible to bool",
+						types.FormatAnyValue(keyDoc), index.Name, types.FormatAnyValue(v),
+					),
+					"createIndexes",
+				)
+			}
+
+			if len(index.Key) == 1 && index.Key[0].Field == "_id" {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrInvalidIndexSpecificationOption,
+					fmt.Sprintf("The field 'unique' is not valid for an _id index specification. "+
+						"Specification: { key: %s, name: \"%s\", unique: true, v: 2 }",
+						types.FormatAnyValue(keyDoc), index.Name,
+					),
+					"createIndexes",
+				)
+			}
+
+			if unique && slices.ContainsFunc(index.Key, func(p backends.IndexKeyPair) bool { return p.Sphere }) {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCannotCreateIndex,
+					"Index type '2dsphere' does not support the unique option",
+					"createIndexes",
+				)
+			}
+
+			if unique && slices.ContainsFunc(index.Key, func(p backends.IndexKeyPair) bool { return p.Text }) {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrNotImplemented,
+					"Unique text indexes are not implemented yet",
+					"createIndexes",
+				)
+			}
+
+			index.Unique = unique
+
+		case "background":
+			// ignore deprecated options
+
+		case "expireAfterSeconds":
+			v := must.NotFail(indexDoc.Get("expireAfterSeconds"))
+
+			if len(index.Key) == 1 && index.Key[0].Field == "_id" {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrInvalidIndexSpecificationOption,
+					"The field 'expireAfterSeconds' is not valid for an _id index specification.",
+					"createIndexes",
+				)
+			}
+
+			ttl, err := getExpireAfterSeconds(v)
+			switch {
+			case err == nil:
+				index.ExpireAfterSeconds = &ttl
+			case errors.Is(err, commonparams.ErrUnexpectedType):
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCannotCreateIndex,
+					fmt.Sprintf(
+						"TTL index 'expireAfterSeconds' option must be numeric, but received a type of '%s'",
+						commonparams.AliasFromType(v),
+					),
+					"createIndexes",
+				)
+			default:
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCannotCreateIndex,
+					"TTL index 'expireAfterSeconds' option must be within an acceptable range, try a lower number",
+					"createIndexes",
+				)
+			}
+
+		case "weights", "default_language", "language_override", "textIndexVersion":
+			if !slices.ContainsFunc(index.Key, func(p backends.IndexKeyPair) bool { return p.Text }) {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrNotImplemented,
+					fmt.Sprintf("Index option %q is not implemented yet", opt),
+					"createIndexes",
+				)
+			}
+
+			if index.Text == nil {
+				index.Text = &backends.TextIndexInfo{
+					Weights: map[string]int32{},
+				}
+			}
+
+			if err = processTextIndexOption(index.Text, opt, must.NotFail(indexDoc.Get(opt))); err != nil {
+				return nil, err
+			}
+
+		case "sparse", "partialFilterExpression", "hidden", "storageEngine", "2dsphereIndexVersion",
+			"bits", "min", "max", "bucketSize", "collation", "wildcardProjection":
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				fmt.Sprintf("Index option %q is not implemented yet", opt),
+				"createIndexes",
+			)
+
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf("Index option %q is unknown", opt),
+				"createIndexes",
+			)
+		}
+	}
+
+	// the default index is unique, but it is not specified by clients
+	if index.Name == "_id_" || (len(index.Key) == 1 && index.Key[0].Field == "_id") {
+		index.Unique = true
+	}
+
+	setTextIndexDefaults(&index)
+
+	return &index, nil
+}
+
+// processTextIndexOption processes the option of the text index.
+func processTextIndexOption(text *backends.TextIndexInfo, opt string, v any) error {
+	switch opt {
+	case "weights":
+		weights, ok := v.(*types.Document)
+		if !ok {
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrCannotCreateIndex,
+				"weights must be an object",
+				"createIndexes",
+			)
+		}
+
+		for _, field := range weights.Keys() {
+			weight, err := commonparams.GetWholeNumberParam(must.NotFail(weights.Get(field)))
+			if err != nil || weight <= 0 || weight >= 100_000 {
+				return commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCannotCreateIndex,
+					fmt.Sprintf(
+						"text index weight must be an integer in the exclusive interval (0,100000) but found: %s",
+						types.FormatAnyValue(must.NotFail(weights.Get(field))),
+					),
+					"createIndexes",
+				)
+			}
+
+			text.Weights[field] = int32(weight)
+		}
+
+	case "default_language":
+		l, ok := v.(string)
+		if ok {
+			text.DefaultLanguage, ok = common.TextLanguage(l)
+		}
+
+		if !ok {
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrCannotCreateIndex,
+				fmt.Sprintf("default_language %s is not supported", types.FormatAnyValue(v)),
+				"createIndexes",
+			)
+		}
+
+	case "language_override":
+		l, ok := v.(string)
+		if !ok || l == "" {
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrCannotCreateIndex,
+				"language_override must be a non-empty string",
+				"createIndexes",
+			)
+		}
+
+		text.LanguageOverride = l
+
+	case "textIndexVersion":
+		// only the latest version is supported, and it is always returned by listIndexes
+		if version, err := commonparams.GetWholeNumberParam(v); err != nil || version != 3 {
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				fmt.Sprintf("textIndexVersion %s is not implemented yet", types.FormatAnyValue(v)),
+				"createIndexes",
+			)
+		}
+
+	default:
+		panic(fmt.Sprintf("unexpected text index option %q", opt))
+	}
+
+	return nil
+}
+
+// setTextIndexDefaults sets default options of the text index
+// and adds weights of the index key fields that were not set by the weights option.
+func setTextIndexDefaults(index *backends.IndexInfo) {
+	if !slices.ContainsFunc(index.Key, func(p backends.IndexKeyPair) bool { return p.Text }) {
+		return
+	}
+
+	if index.Text == nil {
+		index.Text = &backends.TextIndexInfo{
+			Weights: map[string]int32{},
+		}
+	}
+
+	if index.Text.DefaultLanguage == "" {
+		index.Text.DefaultLanguage = "english"
+	}
+
+	if index.Text.LanguageOverride == "" {
+		index.Text.LanguageOverride = "language"
+	}
+
+	for _, pair := range index.Key {
+		if _, ok := index.Text.Weights[pair.Field]; !ok {
+			index.Text.Weights[pair.Field] = 1
+		}
+	}
+}
+
+// sameTextIndex returns true if both text index options are equal or both are nil (for non-text indexes).
+func sameTextIndex(a, b *backends.TextIndexInfo) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return maps.Equal(a.Weights, b.Weights) &&
+		a.DefaultLanguage == b.DefaultLanguage &&
+		a.LanguageOverride == b.LanguageOverride
+}
+
+// maxExpireAfterSeconds is the maximal TTL of the index in seconds, like in MongoDB.
+const maxExpireAfterSeconds = math.MaxInt32
+
+// getExpireAfterSeconds returns the TTL of the index from the expireAfterSeconds option value.
+//
+// It returns commonparams.ErrUnexpectedType if the value is not a number,
+// and other errors if it is out of range.
+// Fractional values are truncated.
+func getExpireAfterSeconds(v any) (int64, error) {
+	var res int64
+
+	switch v := v.(type) {
+	case float64:
+		if math.IsNaN(v) || v < 0 || v > maxExpireAfterSeconds {
+			return 0, lazyerrors.Errorf("expireAfterSeconds is out of range: %v", v)
+		}
+
+		res = int64(v)
+
+	case int32:
+		res = int64(v)
+
+	case int64:
+		res = v
+
+	default:
+		return 0, commonparams.ErrUnexpectedType
+	}
+
+	if res < 0 || res > maxExpireAfterSeconds {
+		return 0, lazyerrors.Errorf("expireAfterSeconds is out of range: %d", res)
+	}
+
+	return res, nil
+}
+
+// sameTTL returns true if both TTL values are equal or both are nil (for non-TTL indexes).
+func sameTTL(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// processIndexKey processes the validated document containing the index key.
+func processIndexKey(keyDoc *types.Document) ([]backends.IndexKeyPair, error) {
+	res := make([]backends.IndexKeyPair, 0, keyDoc.Len())
+
+	for _, field := range keyDoc.Keys() {
+		if slices.ContainsFunc(res, func(p backends.IndexKeyPair) bool { return p.Field == field }) {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrBadValue,
+				fmt.Sprintf(
+					"Error in specification %s, the field %q appears multiple times",
+					types.FormatAnyValue(keyDoc), field,
+				),
+				"createIndexes",
+			)
+		}
+
+		order := must.NotFail(keyDoc.Get(field))
+
+		// 2dsphere indexes are stored in metadata only, geospatial queries are evaluated in memory
+		if order == "2dsphere" {
+			if strings.Contains(field, "$**") {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCannotCreateIndex,
+					"Index key contains an illegal field name: wildcard indexes could not be 2dsphere",
+					"createIndexes",
+				)
+			}
+
+			res = append(res, backends.IndexKeyPair{
+				Field:  field,
+				Sphere: true,
+			})
+
+			continue
+		}
+
+		// text indexes are stored in metadata only, $text queries are evaluated in memory
+		if order == "text" {
+			if strings.Contains(field, "$**") && field != common.TextIndexWildcard {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrCannotCreateIndex,
+					fmt.Sprintf("Index key contains an illegal field name: %q", field),
+					"createIndexes",
+				)
+			}
+
+			res = append(res, backends.IndexKeyPair{
+				Field: field,
+				Text:  true,
+			})
+
+			continue
+		}
+
+		if t, ok := order.(string); ok {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				fmt.Sprintf("Index type %q is not implemented yet", t),
+				"createIndexes",
+			)
+		}
+
+		if strings.Contains(field, "$**") {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				"Wildcard indexes are not implemented yet",
+				"createIndexes",
+			)
+		}
+
+		orderParam, err := commonparams.GetWholeNumberParam(order)
+		if err != nil {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrIndexNotFound,
+				fmt.Sprintf("can't find index with key: { %s: %s }", field, types.FormatAnyValue(order)),
+				"createIndexes",
+			)
+		}
+
+		switch orderParam {
+		case 1, -1:
+			res = append(res, backends.IndexKeyPair{
+				Field:      field,
+				Descending: orderParam == -1,
+			})
+		default:
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrNotImplemented,
+				fmt.Sprintf("Index key value %d is not implemented yet", orderParam),
+				"createIndexes",
+			)
+		}
+	}
+
+	text := slices.ContainsFunc(res, func(p backends.IndexKeyPair) bool { return p.Text })
+	if text && slices.ContainsFunc(res, func(p backends.IndexKeyPair) bool { return !p.Text }) {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrNotImplemented,
+			"Compound text indexes with non-text fields are not implemented yet",
+			"createIndexes",
+		)
+	}
+
+	return res, nil
+}
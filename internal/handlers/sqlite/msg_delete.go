@@ -41,6 +41,13 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, lazyerrors.Error(err)
 	}
 
+	if err = h.checkArchived(document.Command(), params.DB, params.Collection); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := common.WithMaxTimeMS(ctx, params.MaxTimeMS)
+	defer cancel()
+
 	db, err := h.b.Database(params.DB)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
@@ -52,6 +59,10 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	}
 	defer db.Close()
 
+	if err = checkNotView(ctx, db, params.DB, params.Collection); err != nil {
+		return nil, err
+	}
+
 	c, err := db.Collection(params.Collection)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
@@ -66,7 +77,13 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	writeErrors := types.MakeArray(0)
 
 	for i, p := range params.Deletes {
-		d, err := execDelete(ctx, c, &p)
+		// filters compare strings using the explicit or default collation of the collection
+		collation, err := collectionCollation(ctx, db, params.Collection, p.Collation)
+
+		var d int32
+		if err == nil {
+			d, err = execDelete(ctx, c, &p, collation)
+		}
 
 		deleted += d
 
@@ -88,7 +105,7 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 				continue
 			}
 
-			return nil, lazyerrors.Error(err)
+			return nil, common.CheckMaxTimeMS(err)
 		}
 	}
 
@@ -111,11 +128,22 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 }
 
 // execDelete performs a single delete operation.
+// Strings are compared using the given collation; nil collation means binary comparison.
 //
 // It returns a number of deleted documents or error.
 // The error is either a (wrapped) *commonerrors.CommandError or something fatal.
-func execDelete(ctx context.Context, c backends.Collection, p *common.Delete) (int32, error) {
-	q, err := c.Query(ctx, nil)
+func execDelete(ctx context.Context, c backends.Collection, p *common.Delete, collation *common.Collation) (int32, error) {
+	hintIndex, err := getHintIndex(ctx, c, p.Hint)
+	if err != nil {
+		return 0, err
+	}
+
+	var qp backends.QueryParams
+	if hintIndex != nil {
+		qp.Index = hintIndex.Name
+	}
+
+	q, err := c.Query(ctx, &qp)
 	if err != nil {
 		return 0, lazyerrors.Error(err)
 	}
@@ -135,7 +163,7 @@ func execDelete(ctx context.Context, c backends.Collection, p *common.Delete) (i
 
 		var matches bool
 
-		if matches, err = common.FilterDocument(doc, p.Filter); err != nil {
+		if matches, err = common.FilterDocumentWithCollation(doc, p.Filter, collation); err != nil {
 			q.Iter.Close()
 			return 0, lazyerrors.Error(err)
 		}
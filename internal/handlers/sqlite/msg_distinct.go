@@ -40,6 +40,15 @@ func (h *Handler) MsgDistinct(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg
 		return nil, err
 	}
 
+	// validate the explicit collation before accessing the database
+	if _, err = common.GetCollation(params.Collation); err != nil {
+		return nil, err
+	}
+
+	if err = h.checkArchived(document.Command(), params.DB, params.Collection); err != nil {
+		return nil, err
+	}
+
 	db, err := h.b.Database(params.DB)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
@@ -61,6 +70,11 @@ func (h *Handler) MsgDistinct(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg
 		return nil, lazyerrors.Error(err)
 	}
 
+	collation, err := collectionCollation(ctx, db, params.Collection, params.Collation)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
 	closer := iterator.NewMultiCloser()
 	defer closer.Close()
 
@@ -72,9 +86,9 @@ func (h *Handler) MsgDistinct(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg
 
 	closer.Add(queryRes.Iter)
 
-	iter := common.FilterIterator(queryRes.Iter, closer, params.Filter)
+	iter := common.FilterIterator(queryRes.Iter, closer, params.Filter, collation)
 
-	distinct, err := common.FilterDistinctValues(iter, params.Key)
+	distinct, err := common.FilterDistinctValues(iter, params.Key, collation)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
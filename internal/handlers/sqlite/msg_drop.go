@@ -70,6 +70,23 @@ func (h *Handler) MsgDrop(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 	}
 	defer db.Close()
 
+	dropped, err := dropView(ctx, db, dbName, collectionName)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if dropped {
+		var reply wire.OpMsg
+		must.NoError(reply.SetSections(wire.OpMsgSection{
+			Documents: []*types.Document{must.NotFail(types.NewDocument(
+				"ns", dbName+"."+collectionName,
+				"ok", float64(1),
+			))},
+		}))
+
+		return &reply, nil
+	}
+
 	err = db.DropCollection(ctx, &backends.DropCollectionParams{
 		Name: collectionName,
 	})
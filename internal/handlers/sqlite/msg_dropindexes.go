@@ -16,13 +16,231 @@ package sqlite
 
 import (
 	"context"
+	"fmt"
 
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgDropIndexes implements HandlerInterface.
 func (h *Handler) MsgDropIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	// TODO https://github.com/FerretDB/FerretDB/issues/3287
-	return nil, notImplemented(must.NotFail(msg.Document()).Command())
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	common.Ignored(document, h.L, "writeConcern", "comment")
+
+	command := document.Command()
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	if collection == "" {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidNamespace,
+			fmt.Sprintf("Invalid namespace specified '%s.'", dbName),
+			command,
+		)
+	}
+
+	if !document.Has("index") {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrMissingField,
+			"BSON field 'dropIndexes.index' is missing but a required field",
+			command,
+		)
+	}
+
+	db, err := h.b.Database(dbName)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", dbName, collection)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+	defer db.Close()
+
+	if err = checkNotView(ctx, db, dbName, collection); err != nil {
+		return nil, err
+	}
+
+	c, err := db.Collection(collection)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid collection name: %s", collection)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	listRes, err := c.ListIndexes(ctx, nil)
+
+	switch {
+	case err == nil:
+		// do nothing
+	case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist):
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrNamespaceNotFound,
+			fmt.Sprintf("ns not found %s.%s", dbName, collection),
+			command,
+		)
+	default:
+		return nil, lazyerrors.Error(err)
+	}
+
+	names, responseMsg, err := indexesToDrop(listRes.Indexes, document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = c.DropIndexes(ctx, &backends.DropIndexesParams{Indexes: names}); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	replyDoc := must.NotFail(types.NewDocument(
+		"nIndexesWas", int32(len(listRes.Indexes)),
+	))
+
+	if responseMsg != "" {
+		replyDoc.Set("msg", responseMsg)
+	}
+
+	replyDoc.Set("ok", float64(1))
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{replyDoc},
+	}))
+
+	return &reply, nil
+}
+
+// indexesToDrop returns names of existing indexes specified by the `index` parameter of dropIndexes command,
+// and the optional response message.
+func indexesToDrop(indexes []backends.IndexInfo, doc *types.Document, command string) ([]string, string, error) {
+	v := must.NotFail(doc.Get("index"))
+
+	switch v := v.(type) {
+	case *types.Document:
+		// keys that can't be parsed can't match any existing index
+		key, err := processIndexKey(v)
+
+		i := -1
+		if err == nil {
+			i = slices.IndexFunc(indexes, func(index backends.IndexInfo) bool { return slices.Equal(index.Key, key) })
+		}
+
+		if i < 0 {
+			return nil, "", commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrIndexNotFound,
+				fmt.Sprintf("can't find index with key: %s", types.FormatAnyValue(v)),
+				command,
+			)
+		}
+
+		name := indexes[i].Name
+		if name == "_id_" {
+			return nil, "", commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrInvalidOptions,
+				"cannot drop _id index",
+				command,
+			)
+		}
+
+		return []string{name}, "", nil
+
+	case *types.Array:
+		names := make([]string, v.Len())
+
+		for i := range names {
+			name, ok := must.NotFail(v.Get(i)).(string)
+			if !ok {
+				return nil, "", commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrTypeMismatch,
+					fmt.Sprintf(
+						"BSON field 'dropIndexes.index' is the wrong type '%s', expected types '[string, object]'",
+						commonparams.AliasFromType(v),
+					),
+					command,
+				)
+			}
+
+			if err := checkIndexToDrop(indexes, name, command); err != nil {
+				return nil, "", err
+			}
+
+			names[i] = name
+		}
+
+		return names, "", nil
+
+	case string:
+		if v == "*" {
+			var names []string
+
+			for _, index := range indexes {
+				if index.Name != "_id_" {
+					names = append(names, index.Name)
+				}
+			}
+
+			return names, "non-_id indexes dropped for collection", nil
+		}
+
+		if err := checkIndexToDrop(indexes, v, command); err != nil {
+			return nil, "", err
+		}
+
+		return []string{v}, "", nil
+
+	default:
+		return nil, "", commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field 'dropIndexes.index' is the wrong type '%s', expected types '[string, object]'",
+				commonparams.AliasFromType(v),
+			),
+			command,
+		)
+	}
+}
+
+// checkIndexToDrop checks that the index with the given name exists and can be dropped.
+func checkIndexToDrop(indexes []backends.IndexInfo, name, command string) error {
+	if name == "_id_" {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidOptions,
+			"cannot drop _id index",
+			command,
+		)
+	}
+
+	if !slices.ContainsFunc(indexes, func(index backends.IndexInfo) bool { return index.Name == name }) {
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrIndexNotFound,
+			fmt.Sprintf("index not found with name [%s]", name),
+			command,
+		)
+	}
+
+	return nil
 }
@@ -16,10 +16,14 @@ package sqlite
 
 import (
 	"context"
+	"fmt"
 	"os"
 
 	"github.com/FerretDB/FerretDB/build/version"
+	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/stages"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -55,26 +59,221 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	cmd := params.Command
 	cmd.Set("$db", params.DB)
 
+	db, err := h.b.Database(params.DB)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", params.DB, params.Collection)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, document.Command())
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+	defer db.Close()
+
+	c, err := db.Collection(params.Collection)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid collection name: %s", params.Collection)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, document.Command())
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	hintIndex, err := getHintIndex(ctx, c, explainHint(cmd))
+	if err != nil {
+		return nil, err
+	}
+
 	// TODO https://github.com/FerretDB/FerretDB/issues/3050
+	var winningPlan *types.Document
+
+	switch {
+	case hintIndex != nil:
+		winningPlan = must.NotFail(types.NewDocument(
+			"stage", "FETCH",
+			"inputStage", must.NotFail(types.NewDocument(
+				"stage", "IXSCAN",
+				"keyPattern", hintIndex.Key,
+				"indexName", hintIndex.Name,
+			)),
+		))
+
+	case cmd.Command() == "find" && !h.DisableFilterPushdown && explainPointRead(cmd):
+		winningPlan = must.NotFail(types.NewDocument("stage", "IDHACK"))
+
+	default:
+		winningPlan = must.NotFail(types.NewDocument("stage", "COLLSCAN"))
+	}
+
+	sortPushdown := cmd.Command() == "find" && h.EnableSortPushdown && explainSortPushdown(cmd)
+	limitPushdown := cmd.Command() == "find" && explainLimitPushdown(cmd, sortPushdown)
+
+	queryPlanner := must.NotFail(types.NewDocument(
+		"namespace", params.DB+"."+params.Collection,
+		"winningPlan", winningPlan,
+	))
 
-	queryPlanner := types.MakeDocument(0)
+	res := must.NotFail(types.NewDocument(
+		"queryPlanner", queryPlanner,
+		"explainVersion", "1",
+		"command", cmd,
+		"serverInfo", serverInfo,
+
+		// our extensions
+		"pushdown", !h.DisableFilterPushdown,
+		"sortingPushdown", sortPushdown,
+		"limitPushdown", limitPushdown && params.Limit != 0,
+		"skipPushdown", limitPushdown && params.Skip != 0,
+	))
+
+	if cmd.Command() == "count" {
+		countRes, err := h.explainCount(ctx, db, c, params.Collection, cmd)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Set("countPushdown", countRes.CountPushdown)
+		res.Set("estimatedCount", countRes.Estimated)
+	}
+
+	if params.Aggregate {
+		stagesDocs, optimized := stages.OptimizeDocs(params.StagesDocs)
+		if optimized {
+			queryPlanner.Set("optimizedPipeline", true)
+		}
+
+		res.Set("stages", must.NotFail(types.NewArray(stagesDocs...)))
+	}
+
+	res.Set("ok", float64(1))
 
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"queryPlanner", queryPlanner,
-			"explainVersion", "1",
-			"command", cmd,
-			"serverInfo", serverInfo,
-
-			// our extensions
-			"pushdown", !h.DisableFilterPushdown,
-			"sortingPushdown", false,
-			"limitPushdown", false,
-
-			"ok", float64(1),
-		))},
+		Documents: []*types.Document{res},
 	}))
 
 	return &reply, nil
 }
+
+// explainCount returns how documents of the explained count command are counted:
+// whether the backend counts them (see countPushdown), and whether that count is estimated.
+//
+// The backend's count is executed, because the backend may refuse it depending on the collection's data.
+func (h *Handler) explainCount(ctx context.Context, db backends.Database, c backends.Collection, collection string, cmd *types.Document) (*backends.CountResult, error) { //nolint:lll // for readability
+	if h.DisableFilterPushdown {
+		return new(backends.CountResult), nil
+	}
+
+	collation, err := collectionCollation(ctx, db, collection, nil)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	query, _ := cmd.Get("query")
+
+	filter, ok := query.(*types.Document)
+	if collation != nil || (query != nil && !ok) {
+		return new(backends.CountResult), nil
+	}
+
+	cp := countPushdown(filter)
+	if cp == nil {
+		return new(backends.CountResult), nil
+	}
+
+	res, err := c.Count(ctx, cp)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
+
+// explainHint returns the hint of the explained command, or nil if it is not set.
+//
+// For update and delete commands, the hint of the first statement is returned.
+func explainHint(cmd *types.Document) any {
+	var statements string
+
+	switch cmd.Command() {
+	case "update":
+		statements = "updates"
+	case "delete":
+		statements = "deletes"
+	default:
+		hint, _ := cmd.Get("hint")
+		return hint
+	}
+
+	arr, _ := cmd.Get(statements)
+
+	a, ok := arr.(*types.Array)
+	if !ok || a.Len() == 0 {
+		return nil
+	}
+
+	statement, ok := must.NotFail(a.Get(0)).(*types.Document)
+	if !ok {
+		return nil
+	}
+
+	hint, _ := statement.Get("hint")
+
+	return hint
+}
+
+// explainPointRead returns true if the explained find command is a point read
+// that uses the _id index (see pointReadID).
+func explainPointRead(cmd *types.Document) bool {
+	filter, _ := cmd.Get("filter")
+
+	f, ok := filter.(*types.Document)
+	if !ok || pointReadID(f) == nil {
+		return false
+	}
+
+	if collation, _ := cmd.Get("collation"); collation != nil {
+		return false
+	}
+
+	// {$natural: <order>} hint forces a collection scan
+	hint, _ := cmd.Get("hint")
+
+	return hint == nil
+}
+
+// explainSortPushdown returns true if the sort of the explained find command
+// could be pushed down to the backend (see sortPushdown).
+func explainSortPushdown(cmd *types.Document) bool {
+	if collation, _ := cmd.Get("collation"); collation != nil {
+		return false
+	}
+
+	sort, _ := cmd.Get("sort")
+
+	s, ok := sort.(*types.Document)
+
+	return ok && sortPushdown(s) != nil
+}
+
+// explainLimitPushdown returns true if limit and skip of the explained find command
+// could be pushed down to the backend; sortPushdown reports whether its sort could be.
+func explainLimitPushdown(cmd *types.Document, sortPushdown bool) bool {
+	if filter, _ := cmd.Get("filter"); filter != nil {
+		if f, ok := filter.(*types.Document); !ok || f.Len() > 0 {
+			return false
+		}
+	}
+
+	if sort, _ := cmd.Get("sort"); sort != nil && !sortPushdown {
+		if s, ok := sort.(*types.Document); !ok || s.Len() > 0 {
+			return false
+		}
+	}
+
+	minKey, _ := cmd.Get("min")
+	maxKey, _ := cmd.Get("max")
+
+	return minKey == nil && maxKey == nil
+}
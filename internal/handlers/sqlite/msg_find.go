@@ -44,6 +44,24 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		return nil, err
 	}
 
+	text, filter, err := common.FindText(params.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = common.CheckTextScore(text, params.Projection, params.Sort); err != nil {
+		return nil, err
+	}
+
+	// validate the explicit collation before accessing the database
+	if _, err = common.GetCollation(params.Collation); err != nil {
+		return nil, err
+	}
+
+	if err = h.checkArchived(document.Command(), params.DB, params.Collection); err != nil {
+		return nil, err
+	}
+
 	username, _ := conninfo.Get(ctx).Auth()
 
 	db, err := h.b.Database(params.DB)
@@ -57,7 +75,17 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 	}
 	defer db.Close()
 
-	c, err := db.Collection(params.Collection)
+	view, err := getView(ctx, db, params.DB, params.Collection)
+	if err != nil {
+		return nil, err
+	}
+
+	collectionName := params.Collection
+	if view != nil {
+		collectionName = view.Collection
+	}
+
+	c, err := db.Collection(collectionName)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
 			msg := fmt.Sprintf("Invalid collection name: %s", params.Collection)
@@ -67,64 +95,240 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		return nil, lazyerrors.Error(err)
 	}
 
+	collation, err := collectionCollation(ctx, db, collectionName, params.Collation)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var tailable bool
+	if params.Tailable {
+		info, err := collectionInfo(ctx, db, params.Collection)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if info != nil && info.Capped == nil {
+			return nil, common.NewTailableNonCappedError(params)
+		}
+
+		// non-existing collection returns empty result and closed cursor as usual
+		tailable = info != nil
+	}
+
+	hintIndex, err := getHintIndex(ctx, c, params.Hint)
+	if err != nil {
+		return nil, err
+	}
+
+	var search *common.TextSearch
+	if text != nil {
+		if search, err = textSearch(ctx, c, text); err != nil {
+			return nil, err
+		}
+	}
+
 	cancel := func() {}
-	if params.MaxTimeMS != 0 {
-		// It is not clear if maxTimeMS affects only find, or both find and getMore (as the current code does).
-		// TODO https://github.com/FerretDB/FerretDB/issues/2984
+	if params.MaxTimeMS != 0 && !tailable {
+		// Like in MongoDB, maxTimeMS limits both find and getMore commands for the created cursor;
+		// unlike MongoDB, the time between getMore commands is counted too.
+		// TODO https://github.com/FerretDB/FerretDB/issues/2983
 		ctx, cancel = context.WithTimeout(ctx, time.Duration(params.MaxTimeMS)*time.Millisecond)
 	}
 
 	// closer accumulates all things that should be closed / canceled.
 	closer := iterator.NewMultiCloser(iterator.CloserFunc(cancel))
 
-	queryRes, err := c.Query(ctx, nil)
-	if err != nil {
-		closer.Close()
-		return nil, lazyerrors.Error(err)
+	var resumeAfter int64
+	if params.ResumeAfter != nil {
+		resumeAfter = params.ResumeAfter.RecordID
 	}
 
-	closer.Add(queryRes.Iter)
+	var queryIter types.DocumentsIterator
 
-	iter := common.FilterIterator(queryRes.Iter, closer, params.Filter)
+	// true if the backend returns documents sorted by params.Sort
+	var sorted bool
 
-	iter, err = common.SortIterator(iter, closer, params.Sort)
-	if err != nil {
-		closer.Close()
+	switch {
+	case tailable:
+		queryIter = newTailableIterator(ctx, c, resumeAfter)
 
-		var pathErr *types.PathError
-		if errors.As(err, &pathErr) && pathErr.Code() == types.ErrPathElementEmpty {
-			return nil, commonerrors.NewCommandErrorMsgWithArgument(
-				commonerrors.ErrPathContainsEmptyElement,
-				"Empty field names in path are not allowed",
-				document.Command(),
-			)
+	case view != nil:
+		stages, err := viewStages(view)
+		if err != nil {
+			closer.Close()
+			return nil, err
 		}
 
-		return nil, lazyerrors.Error(err)
+		// the view pipeline is applied before the filter and other parameters
+		sp := &stagesDocumentsParams{
+			c:      c,
+			stages: stages,
+		}
+
+		if queryIter, err = processStagesDocuments(ctx, closer, sp); err != nil {
+			return nil, err
+		}
+
+	default:
+		qp := &backends.QueryParams{
+			RecordIDAfter:  resumeAfter,
+			ReverseNatural: params.Natural == types.Descending,
+		}
+
+		if hintIndex != nil {
+			qp.Index = hintIndex.Name
+		}
+
+		if !h.DisableFilterPushdown && collation == nil {
+			// {$natural: <order>} hint forces a collection scan, so the _id index can't be used
+			if !params.CollectionScan {
+				qp.ID = pointReadID(params.Filter)
+			}
+
+			qp.Filter = filterPushdown(params.Filter)
+		}
+
+		// documents are sorted by text score in memory
+		if h.EnableSortPushdown && collation == nil && text == nil {
+			qp.Sort = sortPushdown(params.Sort)
+		}
+
+		// limit and skip are pushed down only if documents are not filtered or sorted in memory,
+		// and if they are not filtered by index bounds
+		if params.Filter.Len() == 0 && (params.Sort.Len() == 0 || qp.Sort != nil) &&
+			params.Min == nil && params.Max == nil {
+			qp.Limit = params.Limit
+			qp.Skip = params.Skip
+		}
+
+		// returnKey, min and max need fields of the hinted index, and $text needs fields of the text index
+		if !params.ReturnKey && params.Min == nil && params.Max == nil && text == nil {
+			qp.Fields = projectionPushdown(params.Projection, params.Filter, params.Sort)
+		}
+
+		queryRes, err := c.Query(ctx, qp)
+		if err != nil {
+			closer.Close()
+			return nil, lazyerrors.Error(err)
+		}
+
+		queryIter = queryRes.Iter
+		sorted = len(qp.Sort) > 0 && queryRes.SortPushdown
+
+		// skipped documents were not returned by the backend
+		if queryRes.LimitPushdown {
+			params.Skip = 0
+		}
+
+		if qp.ID == nil && len(qp.Filter) == 0 && params.Filter.Len() > 0 {
+			common.AddWarning(ctx, "filter was not pushed down to the backend; all documents were scanned")
+		}
+	}
+
+	closer.Add(queryIter)
+
+	iter := common.FilterIterator(queryIter, closer, filter, collation)
+
+	if search != nil {
+		iter = common.TextIterator(iter, closer, search)
+	}
+
+	// documents matching $near and $nearSphere are returned from nearest to farthest unless sorted
+	if params.Sort.Len() == 0 {
+		if iter, err = common.GeoNearIterator(iter, closer, params.Filter); err != nil {
+			closer.Close()
+			return nil, err
+		}
+	}
+
+	if hintIndex != nil {
+		iter, err = common.MinMaxIterator(iter, closer, hintIndex.Key, params.Min, params.Max)
+		if err != nil {
+			closer.Close()
+			return nil, err
+		}
+
+		// documents are returned in the index order
+		if (params.Min != nil || params.Max != nil) && params.Sort.Len() == 0 && params.Natural == 0 {
+			params.Sort = common.IndexKeySort(hintIndex.Key)
+		}
+	}
+
+	if !sorted {
+		if params.Sort.Len() > 0 {
+			common.AddWarning(ctx, "sort was not pushed down to the backend; documents were sorted in memory")
+		}
+
+		iter, err = common.SortIterator(iter, closer, params.Sort, collation)
+		if err != nil {
+			closer.Close()
+
+			var pathErr *types.PathError
+			if errors.As(err, &pathErr) && pathErr.Code() == types.ErrPathElementEmpty {
+				return nil, commonerrors.NewCommandErrorMsgWithArgument(
+					commonerrors.ErrPathContainsEmptyElement,
+					"Empty field names in path are not allowed",
+					document.Command(),
+				)
+			}
+
+			return nil, common.CheckMaxTimeMS(err)
+		}
 	}
 
 	iter = common.SkipIterator(iter, closer, params.Skip)
 
 	iter = common.LimitIterator(iter, closer, params.Limit)
 
-	iter, err = common.ProjectionIterator(iter, closer, params.Projection, params.Filter)
-	if err != nil {
-		closer.Close()
-		return nil, lazyerrors.Error(err)
+	if params.ReturnKey {
+		var key *types.Document
+		if hintIndex != nil {
+			key = hintIndex.Key
+		}
+
+		iter = common.ReturnKeyIterator(iter, closer, key)
+	} else {
+		iter, err = common.ProjectionIterator(iter, closer, params.Projection, params.Filter)
+		if err != nil {
+			closer.Close()
+			return nil, lazyerrors.Error(err)
+		}
 	}
 
+	if params.ShowRecordId {
+		iter = common.RecordIDIterator(iter, closer)
+	}
+
+	comment, _ := document.Get("comment")
+
 	// Combine iterators chain and closer into a cursor to pass around.
 	// The context will be canceled when client disconnects or after maxTimeMS.
 	cursor := h.cursors.NewCursor(ctx, &cursor.NewParams{
-		Iter:       iterator.WithClose(iterator.Interface[struct{}, *types.Document](iter), closer.Close),
-		DB:         params.DB,
-		Collection: params.Collection,
-		Username:   username,
+		Iter:               iterator.WithClose(iterator.Interface[struct{}, *types.Document](iter), closer.Close),
+		DB:                 params.DB,
+		Collection:         params.Collection,
+		Username:           username,
+		Client:             conninfo.Get(ctx).PeerAddr,
+		OriginatingCommand: document.DeepCopy(),
+		Comment:            comment,
+		QueryShapeHash:     common.QueryShapeHash(document),
+		Tailable:           tailable,
+		AwaitData:          params.AwaitData,
+		ResumeToken:        params.RequestResumeToken,
+		LastRecordID:       resumeAfter,
 	})
 
 	cursorID := cursor.ID
 
-	firstBatchDocs, err := iterator.ConsumeValuesN(iterator.Interface[struct{}, *types.Document](cursor), int(params.BatchSize))
+	var firstBatchDocs []*types.Document
+	var done bool
+
+	if tailable {
+		firstBatchDocs, err = common.ConsumeTailable(ctx, cursor, int(params.BatchSize), 0)
+	} else {
+		firstBatchDocs, done, err = common.ConsumeBatch(cursor, int(params.BatchSize))
+	}
+
 	if err != nil {
 		cursor.Close()
 		return nil, lazyerrors.Error(err)
@@ -135,27 +339,92 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		firstBatch.Append(doc)
 	}
 
-	if params.SingleBatch || firstBatch.Len() < int(params.BatchSize) {
-		// support tailable cursors
-		// TODO https://github.com/FerretDB/FerretDB/issues/2283
+	closeCursor := params.SingleBatch || done
+	if tailable {
+		// tailable cursor stays open unless the initial query matches nothing, like in MongoDB
+		closeCursor = firstBatch.Len() == 0
+	}
 
+	if closeCursor {
 		// let the client know that there are no more results
 		cursorID = 0
 
 		cursor.Close()
 	}
 
+	cursorDoc := must.NotFail(types.NewDocument(
+		"firstBatch", firstBatch,
+	))
+
+	if params.RequestResumeToken {
+		cursorDoc.Set("postBatchResumeToken", common.PostBatchResumeToken(cursor, firstBatchDocs))
+	}
+
+	cursorDoc.Set("id", cursorID)
+	cursorDoc.Set("ns", cursor.NS())
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"cursor", must.NotFail(types.NewDocument(
-				"firstBatch", firstBatch,
-				"id", cursorID,
-				"ns", params.DB+"."+params.Collection,
-			)),
+			"cursor", cursorDoc,
 			"ok", float64(1),
 		))},
 	}))
 
 	return &reply, nil
 }
+
+// collectionInfo returns information about the collection, or nil if it does not exist.
+func collectionInfo(ctx context.Context, db backends.Database, name string) (*backends.CollectionInfo, error) {
+	res, err := db.ListCollections(ctx, &backends.ListCollectionsParams{Name: name})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if len(res.Collections) == 0 {
+		return nil, nil
+	}
+
+	return &res.Collections[0], nil
+}
+
+// collectionCollation returns the collation for the query on the given collection.
+//
+// The explicitly specified collation document takes precedence;
+// otherwise, the default collation of the collection is used, if any.
+func collectionCollation(ctx context.Context, db backends.Database, name string, doc *types.Document) (*common.Collation, error) {
+	if doc != nil {
+		return common.GetCollation(doc)
+	}
+
+	info, err := collectionInfo(ctx, db, name)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if info == nil {
+		return nil, nil
+	}
+
+	return common.GetCollation(info.Collation)
+}
+
+// pointReadID returns the _id value if the filter matches a single document by string or ObjectID _id,
+// and nil otherwise.
+func pointReadID(filter *types.Document) any {
+	if filter.Len() != 1 {
+		return nil
+	}
+
+	id, err := filter.Get("_id")
+	if err != nil {
+		return nil
+	}
+
+	switch id.(type) {
+	case string, types.ObjectID:
+		return id
+	default:
+		return nil
+	}
+}
@@ -19,6 +19,8 @@ import (
 	"errors"
 	"fmt"
 
+	"go.uber.org/zap"
+
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
@@ -62,6 +64,10 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, lazyerrors.Error(err)
 	}
 
+	if err = h.checkArchived(document.Command(), params.DB, params.Collection); err != nil {
+		return nil, err
+	}
+
 	db, err := h.b.Database(params.DB)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
@@ -73,6 +79,10 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	}
 	defer db.Close()
 
+	if err = checkNotView(ctx, db, params.DB, params.Collection); err != nil {
+		return nil, err
+	}
+
 	c, err := db.Collection(params.Collection)
 	if err != nil {
 		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
@@ -83,6 +93,15 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, lazyerrors.Error(err)
 	}
 
+	var validator *backends.ValidatorParams
+
+	// authorization of bypassDocumentValidation is checked by the caller
+	if !params.BypassDocumentValidation {
+		if validator, err = collectionValidator(ctx, db, params.Collection); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
 	docsIter := params.Docs.Iterator()
 	defer docsIter.Close()
 
@@ -105,28 +124,16 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			doc.Set("_id", types.NewObjectID())
 		}
 
-		if err = doc.ValidateData(); err != nil {
-			var ve *types.ValidationError
-
-			if !errors.As(err, &ve) {
+		if err = checkInsertDocument(h.L, validator, doc); err != nil {
+			var ce *commonerrors.CommandError
+			if !errors.As(err, &ce) {
 				return nil, lazyerrors.Error(err)
 			}
 
-			var code commonerrors.ErrorCode
-
-			switch ve.Code() {
-			case types.ErrValidation, types.ErrIDNotFound:
-				code = commonerrors.ErrBadValue
-			case types.ErrWrongIDType:
-				code = commonerrors.ErrInvalidID
-			default:
-				panic(fmt.Sprintf("Unknown error code: %v", ve.Code()))
-			}
-
 			we := &writeError{
 				index:  int32(i),
-				code:   code,
-				errmsg: ve.Error(),
+				code:   ce.Code(),
+				errmsg: ce.Err().Error(),
 			}
 			writeErrors.Append(we.Document())
 
@@ -144,7 +151,7 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			Docs: []*types.Document{doc},
 		})
 		if err != nil {
-			if backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID) {
+			if backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID, backends.ErrorCodeIndexDuplicateKey) {
 				we := &writeError{
 					index:  int32(i),
 					code:   commonerrors.ErrDuplicateKeyInsert,
@@ -182,3 +189,41 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 
 	return &reply, nil
 }
+
+// checkInsertDocument checks that the given document with _id could be inserted
+// into the collection with the given validator.
+//
+// It returns *commonerrors.CommandError for invalid documents.
+func checkInsertDocument(l *zap.Logger, validator *backends.ValidatorParams, doc *types.Document) error {
+	if err := doc.ValidateData(); err != nil {
+		var ve *types.ValidationError
+
+		if !errors.As(err, &ve) {
+			return lazyerrors.Error(err)
+		}
+
+		var code commonerrors.ErrorCode
+
+		switch ve.Code() {
+		case types.ErrValidation, types.ErrIDNotFound:
+			code = commonerrors.ErrBadValue
+		case types.ErrWrongIDType:
+			code = commonerrors.ErrInvalidID
+		default:
+			panic(fmt.Sprintf("Unknown error code: %v", ve.Code()))
+		}
+
+		return commonerrors.NewCommandErrorMsg(code, ve.Error())
+	}
+
+	valid, err := validateDocument(l, validator, doc)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if !valid {
+		return commonerrors.NewCommandErrorMsg(commonerrors.ErrDocumentValidationFailure, errValidationFailed)
+	}
+
+	return nil
+}
@@ -18,15 +18,26 @@ import (
 	"context"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgIsMaster implements HandlerInterface.
-func (h *Handler) MsgIsMaster(context.Context, *wire.OpMsg) (*wire.OpMsg, error) {
+func (h *Handler) MsgIsMaster(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	doc, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	docs, err := common.IsMasterDocuments(ctx, doc, h.getUser)
+	if err != nil {
+		return nil, err
+	}
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
-		Documents: common.IsMasterDocuments(),
+		Documents: docs,
 	}))
 
 	return &reply, nil
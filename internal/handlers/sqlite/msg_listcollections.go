@@ -17,6 +17,7 @@ package sqlite
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
@@ -79,6 +80,40 @@ func (h *Handler) MsgListCollections(ctx context.Context, msg *wire.OpMsg) (*wir
 			"type", "collection",
 		))
 
+		options := types.MakeDocument(0)
+
+		if collection.Capped != nil {
+			options.Set("capped", true)
+			options.Set("size", collection.Capped.Size)
+
+			if collection.Capped.Documents > 0 {
+				options.Set("max", collection.Capped.Documents)
+			}
+		}
+
+		if collection.Validator != nil {
+			options.Set("validator", collection.Validator.Filter)
+			options.Set("validationLevel", collection.Validator.Level)
+			options.Set("validationAction", collection.Validator.Action)
+		}
+
+		if collection.Collation != nil {
+			options.Set("collation", collection.Collation)
+		}
+
+		if collection.Clustered {
+			options.Set("clusteredIndex", must.NotFail(types.NewDocument(
+				"v", int32(2),
+				"key", must.NotFail(types.NewDocument("_id", int32(1))),
+				"name", "_id_",
+				"unique", true,
+			)))
+		}
+
+		if options.Len() > 0 {
+			d.Set("options", options)
+		}
+
 		matches, err := common.FilterDocument(d, filter)
 		if err != nil {
 			return nil, lazyerrors.Error(err)
@@ -97,6 +132,44 @@ func (h *Handler) MsgListCollections(ctx context.Context, msg *wire.OpMsg) (*wir
 		collections.Append(d)
 	}
 
+	views, err := listViews(ctx, db)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	for _, view := range views {
+		name := strings.TrimPrefix(must.NotFail(view.Get("_id")).(string), dbName+".")
+
+		d := must.NotFail(types.NewDocument(
+			"name", name,
+			"type", "view",
+			"options", must.NotFail(types.NewDocument(
+				"viewOn", must.NotFail(view.Get("viewOn")),
+				"pipeline", must.NotFail(view.Get("pipeline")),
+			)),
+			"info", must.NotFail(types.NewDocument(
+				"readOnly", true,
+			)),
+		))
+
+		matches, err := common.FilterDocument(d, filter)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if !matches {
+			continue
+		}
+
+		if nameOnly {
+			d = must.NotFail(types.NewDocument(
+				"name", name,
+			))
+		}
+
+		collections.Append(d)
+	}
+
 	var reply wire.OpMsg
 
 	must.NoError(reply.SetSections(wire.OpMsgSection{
@@ -16,13 +16,245 @@ package sqlite
 
 import (
 	"context"
+	"fmt"
 
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgListIndexes implements HandlerInterface.
 func (h *Handler) MsgListIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	// TODO https://github.com/FerretDB/FerretDB/issues/3175
-	return nil, notImplemented(must.NotFail(msg.Document()).Command())
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	common.Ignored(document, h.L, "comment", "cursor")
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	collectionParam, err := document.Get(document.Command())
+	if err != nil {
+		return nil, err
+	}
+
+	collection, ok := collectionParam.(string)
+	if !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("collection name has invalid type %s", commonparams.AliasFromType(collectionParam)),
+			document.Command(),
+		)
+	}
+
+	db, err := h.b.Database(dbName)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", dbName, collection)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, document.Command())
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+	defer db.Close()
+
+	c, err := db.Collection(collection)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid collection name: %s", collection)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, document.Command())
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := c.ListIndexes(ctx, nil)
+
+	switch {
+	case err == nil:
+		// do nothing
+	case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist):
+		return nil, commonerrors.NewCommandErrorMsg(
+			commonerrors.ErrNamespaceNotFound,
+			fmt.Sprintf("ns does not exist: %s.%s", dbName, collection),
+		)
+	default:
+		return nil, lazyerrors.Error(err)
+	}
+
+	info, err := collectionInfo(ctx, db, collection)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	firstBatch := types.MakeArray(len(res.Indexes))
+
+	for i := range res.Indexes {
+		indexDoc := indexDocument(&res.Indexes[i])
+
+		// indexes use the default collation of the collection
+		if info != nil && info.Collation != nil {
+			indexDoc.Set("collation", info.Collation)
+		}
+
+		firstBatch.Append(indexDoc)
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"cursor", must.NotFail(types.NewDocument(
+				"id", int64(0),
+				"ns", fmt.Sprintf("%s.%s", dbName, collection),
+				"firstBatch", firstBatch,
+			)),
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// indexDocument returns the index specification as it is returned by listIndexes.
+//
+// Like MongoDB, text index fields are replaced by `{_fts: "text", _ftsx: 1}`;
+// they are returned in the weights field of the index instead.
+func indexDocument(index *backends.IndexInfo) *types.Document {
+	key := types.MakeDocument(len(index.Key))
+
+	for _, pair := range index.Key {
+		if pair.Text {
+			if !key.Has("_fts") {
+				key.Set("_fts", "text")
+				key.Set("_ftsx", int32(1))
+			}
+
+			continue
+		}
+
+		if pair.Sphere {
+			key.Set(pair.Field, "2dsphere")
+			continue
+		}
+
+		order := int32(1)
+		if pair.Descending {
+			order = -1
+		}
+
+		key.Set(pair.Field, order)
+	}
+
+	res := must.NotFail(types.NewDocument(
+		"v", int32(2),
+		"key", key,
+		"name", index.Name,
+	))
+
+	// only non-default unique indexes should have unique field in the response
+	if index.Unique && index.Name != "_id_" {
+		res.Set("unique", true)
+	}
+
+	if index.ExpireAfterSeconds != nil {
+		res.Set("expireAfterSeconds", *index.ExpireAfterSeconds)
+	}
+
+	if slices.ContainsFunc(index.Key, func(p backends.IndexKeyPair) bool { return p.Sphere }) {
+		res.Set("2dsphereIndexVersion", int32(3))
+	}
+
+	if index.Text != nil {
+		fields := maps.Keys(index.Text.Weights)
+		slices.Sort(fields)
+
+		weights := types.MakeDocument(len(fields))
+		for _, f := range fields {
+			weights.Set(f, index.Text.Weights[f])
+		}
+
+		res.Set("weights", weights)
+		res.Set("default_language", index.Text.DefaultLanguage)
+		res.Set("language_override", index.Text.LanguageOverride)
+		res.Set("textIndexVersion", int32(3))
+	}
+
+	return res
+}
+
+// textSearch returns parameters of the given $text query operator for the text index of the collection.
+//
+// It returns nil if the collection does not exist
+// (non-existing collection has no documents, so there is nothing to search).
+// It returns protocol error if the collection has no text index.
+func textSearch(ctx context.Context, c backends.Collection, text any) (*common.TextSearch, error) {
+	res, err := c.ListIndexes(ctx, nil)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist) {
+			return nil, nil
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	var index *common.TextIndex
+
+	for _, i := range res.Indexes {
+		if i.Text == nil {
+			continue
+		}
+
+		index = &common.TextIndex{
+			Weights:          i.Text.Weights,
+			DefaultLanguage:  i.Text.DefaultLanguage,
+			LanguageOverride: i.Text.LanguageOverride,
+		}
+
+		break
+	}
+
+	return common.NewTextSearch(text, index)
+}
+
+// getHintIndex returns the collection index selected by the hint.
+//
+// It returns nil if the hint is not set, is `{$natural: <order>}`, or the collection does not exist
+// (non-existing collection has no documents, so there is nothing to check).
+// It returns protocol error if the hint does not correspond to any of the collection indexes.
+func getHintIndex(ctx context.Context, c backends.Collection, hint any) (*common.HintIndex, error) {
+	if hint == nil {
+		return nil, nil
+	}
+
+	res, err := c.ListIndexes(ctx, nil)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist) {
+			return nil, nil
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	indexes := make([]common.HintIndex, len(res.Indexes))
+
+	for i := range res.Indexes {
+		indexes[i] = common.HintIndex{
+			Name: res.Indexes[i].Name,
+			Key:  must.NotFail(indexDocument(&res.Indexes[i]).Get("key")).(*types.Document),
+		}
+	}
+
+	return common.GetHintIndex(hint, indexes)
 }
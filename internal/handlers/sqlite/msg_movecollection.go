@@ -0,0 +1,165 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/multi"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgMoveCollection implements HandlerInterface.
+//
+// It moves the collection with all documents and indexes to another backend
+// configured by the `--test-backends` flag and stores that in the mapping table.
+func (h *Handler) MsgMoveCollection(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	namespace, dbName, collectionName, err := adminNamespace(document)
+	if err != nil {
+		return nil, err
+	}
+
+	toBackend, err := common.GetRequiredParam[string](document, "toBackend")
+	if err != nil {
+		return nil, err
+	}
+
+	if h.backends == nil {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrIllegalOperation,
+			command+" requires additional backends; set --test-backends flag",
+			command,
+		)
+	}
+
+	if _, ok := h.backends[toBackend]; !ok {
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("Unknown backend '%s'", toBackend),
+			command,
+		)
+	}
+
+	from := h.mapping.Backend(dbName, collectionName)
+
+	// Backends could block on the move otherwise; see MsgDrop.
+	for _, c := range h.cursors.All() {
+		if c.DB == dbName && c.Collection == collectionName {
+			c.Close()
+		}
+	}
+
+	err = multi.MoveCollection(ctx, &multi.MoveCollectionParams{
+		Backends:       h.backends,
+		Mapping:        h.mapping,
+		DBName:         dbName,
+		CollectionName: collectionName,
+		ToBackend:      toBackend,
+	})
+
+	switch {
+	case err == nil:
+		// nothing
+
+	case backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid, backends.ErrorCodeCollectionNameIsInvalid):
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidNamespace,
+			fmt.Sprintf("Invalid namespace specified '%s'", namespace),
+			command,
+		)
+
+	case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist):
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrNamespaceNotFound, "ns not found", command)
+
+	case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionAlreadyExists):
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrNamespaceExists,
+			fmt.Sprintf("Collection %s already exists in backend '%s'", namespace, toBackend),
+			command,
+		)
+
+	default:
+		return nil, lazyerrors.Error(err)
+	}
+
+	h.L.Info(
+		"Collection moved",
+		zap.String("ns", namespace), zap.String("from", from), zap.String("to", toBackend),
+	)
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"ns", namespace,
+			"from", from,
+			"to", toBackend,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// adminNamespace checks that the command is run against the admin database,
+// and returns the namespace set by the command parameter, and its database and collection names.
+func adminNamespace(document *types.Document) (string, string, string, error) {
+	command := document.Command()
+
+	db, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return "", "", "", err
+	}
+
+	if db != "admin" {
+		return "", "", "", commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrUnauthorized,
+			command+" may only be run against the admin database.",
+			command,
+		)
+	}
+
+	namespace, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	dbName, collectionName, ok := strings.Cut(namespace, ".")
+	if !ok || dbName == "" || collectionName == "" {
+		return "", "", "", commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidNamespace,
+			fmt.Sprintf("Invalid namespace specified '%s'", namespace),
+			command,
+		)
+	}
+
+	return namespace, dbName, collectionName, nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgReIndex implements HandlerInterface.
+func (h *Handler) MsgReIndex(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	common.Ignored(document, h.L, "comment")
+
+	command := document.Command()
+
+	dbName, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := h.b.Database(dbName)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", dbName, collection)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+	defer db.Close()
+
+	c, err := db.Collection(collection)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid collection name: %s", collection)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, command)
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	notFound := commonerrors.NewCommandErrorMsgWithArgument(
+		commonerrors.ErrNamespaceNotFound,
+		"collection does not exist",
+		command,
+	)
+
+	listRes, err := c.ListIndexes(ctx, nil)
+
+	switch {
+	case err == nil:
+		// do nothing
+	case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist):
+		return nil, notFound
+	default:
+		return nil, lazyerrors.Error(err)
+	}
+
+	nIndexesWas := int32(len(listRes.Indexes))
+
+	_, err = c.ReIndex(ctx, nil)
+
+	switch {
+	case err == nil:
+		// do nothing
+	case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist):
+		// collection was dropped concurrently
+		return nil, notFound
+	default:
+		return nil, lazyerrors.Error(err)
+	}
+
+	if listRes, err = c.ListIndexes(ctx, nil); err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist) {
+			return nil, notFound
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	indexes := types.MakeArray(len(listRes.Indexes))
+	for i := range listRes.Indexes {
+		indexes.Append(indexDocument(&listRes.Indexes[i]))
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"nIndexesWas", nIndexesWas,
+			"nIndexes", int32(len(listRes.Indexes)),
+			"indexes", indexes,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
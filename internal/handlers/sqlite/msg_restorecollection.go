@@ -0,0 +1,95 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/archive"
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgRestoreCollection implements HandlerInterface.
+//
+// It restores the collection from the archive set by the `--test-archive-url` flag.
+func (h *Handler) MsgRestoreCollection(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	namespace, dbName, collectionName, err := adminNamespace(document)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.archive == nil {
+		return nil, archiveNotConfiguredError(command)
+	}
+
+	err = h.archive.RestoreCollection(ctx, h.b, dbName, collectionName)
+
+	switch {
+	case err == nil:
+		// nothing
+
+	case backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid, backends.ErrorCodeCollectionNameIsInvalid):
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrInvalidNamespace,
+			fmt.Sprintf("Invalid namespace specified '%s'", namespace),
+			command,
+		)
+
+	case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionAlreadyExists):
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrNamespaceExists,
+			fmt.Sprintf("Collection %s already exists", namespace),
+			command,
+		)
+
+	case errors.Is(err, archive.ErrNotArchived):
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrNamespaceNotFound,
+			fmt.Sprintf("Collection %s is not archived", namespace),
+			command,
+		)
+
+	default:
+		return nil, lazyerrors.Error(err)
+	}
+
+	h.L.Info("Collection restored", zap.String("ns", namespace))
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"ns", namespace,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
@@ -17,6 +17,7 @@ package sqlite
 import (
 	"context"
 
+	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
@@ -26,7 +27,7 @@ import (
 
 // MsgServerStatus implements HandlerInterface.
 func (h *Handler) MsgServerStatus(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	res, err := common.ServerStatus(h.StateProvider.Get(), h.ConnMetrics)
+	res, err := common.ServerStatus(ctx, h.StateProvider.Get(), h.ConnMetrics)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
@@ -41,6 +42,24 @@ func (h *Handler) MsgServerStatus(ctx context.Context, msg *wire.OpMsg) (*wire.O
 		"internalViews", int32(0),
 	)))
 
+	status, err := h.b.Status(ctx, new(backends.StatusParams))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	common.SetBackendStatus(res, &common.BackendStatus{
+		Name:             status.Name,
+		Version:          status.Version,
+		PoolMaxOpen:      status.Pool.MaxOpen,
+		PoolOpen:         status.Pool.Open,
+		PoolInUse:        status.Pool.InUse,
+		PoolIdle:         status.Pool.Idle,
+		PoolWaitCount:    status.Pool.WaitCount,
+		PoolWaitDuration: status.Pool.WaitDuration,
+		LastError:        status.LastError,
+		LastErrorTime:    status.LastErrorTime,
+	})
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{res},
@@ -41,9 +41,16 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, lazyerrors.Error(err)
 	}
 
+	if err = h.checkArchived(document.Command(), params.DB, params.Collection); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := common.WithMaxTimeMS(ctx, params.MaxTimeMS)
+	defer cancel()
+
 	matched, modified, upserted, err := h.updateDocument(ctx, params)
 	if err != nil {
-		return nil, lazyerrors.Error(err)
+		return nil, common.CheckMaxTimeMS(err)
 	}
 
 	res := must.NotFail(types.NewDocument(
@@ -81,6 +88,10 @@ func (h *Handler) updateDocument(ctx context.Context, params *common.UpdatesPara
 	}
 	defer db.Close()
 
+	if err = checkNotView(ctx, db, params.DB, params.Collection); err != nil {
+		return 0, 0, nil, err
+	}
+
 	err = db.CreateCollection(ctx, &backends.CreateCollectionParams{Name: params.Collection})
 
 	switch {
@@ -95,6 +106,15 @@ func (h *Handler) updateDocument(ctx context.Context, params *common.UpdatesPara
 		return 0, 0, nil, lazyerrors.Error(err)
 	}
 
+	var validator *backends.ValidatorParams
+
+	// authorization of bypassDocumentValidation is checked by the caller
+	if !params.BypassDocumentValidation {
+		if validator, err = collectionValidator(ctx, db, params.Collection); err != nil {
+			return 0, 0, nil, lazyerrors.Error(err)
+		}
+	}
+
 	for _, u := range params.Updates {
 		c, err := db.Collection(params.Collection)
 		if err != nil {
@@ -106,7 +126,23 @@ func (h *Handler) updateDocument(ctx context.Context, params *common.UpdatesPara
 			return 0, 0, nil, lazyerrors.Error(err)
 		}
 
-		res, err := c.Query(ctx, nil)
+		hintIndex, err := getHintIndex(ctx, c, u.Hint)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+
+		// filters compare strings using the explicit or default collation of the collection
+		collation, err := collectionCollation(ctx, db, params.Collection, u.Collation)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+
+		var qp backends.QueryParams
+		if hintIndex != nil {
+			qp.Index = hintIndex.Name
+		}
+
+		res, err := c.Query(ctx, &qp)
 		if err != nil {
 			return 0, 0, nil, lazyerrors.Error(err)
 		}
@@ -129,7 +165,7 @@ func (h *Handler) updateDocument(ctx context.Context, params *common.UpdatesPara
 
 			var matches bool
 
-			matches, err = common.FilterDocument(doc, u.Filter)
+			matches, err = common.FilterDocumentWithCollation(doc, u.Filter, collation)
 			if err != nil {
 				return 0, 0, nil, lazyerrors.Error(err)
 			}
@@ -179,22 +215,41 @@ func (h *Handler) updateDocument(ctx context.Context, params *common.UpdatesPara
 			if !doc.Has("_id") {
 				doc.Set("_id", types.NewObjectID())
 			}
-			upserted.Append(must.NotFail(types.NewDocument(
-				"index", int32(upserted.Len()),
-				"_id", must.NotFail(doc.Get("_id")),
-			)))
+
+			if err = checkValidator(h.L, validator, nil, doc); err != nil {
+				return 0, 0, nil, err
+			}
 
 			// TODO https://github.com/FerretDB/FerretDB/issues/2612
 
-			_, err = c.InsertAll(ctx, &backends.InsertAllParams{
+			// the document with the same _id could be inserted concurrently after the query above;
+			// it is replaced then, like MongoDB retries the upsert as an update
+			upsertRes, err := c.UpsertAll(ctx, &backends.UpsertAllParams{
 				Docs: []*types.Document{doc},
 			})
+			if backends.ErrorCodeIs(err, backends.ErrorCodeIndexDuplicateKey) {
+				return 0, 0, nil, commonerrors.NewWriteErrorMsg(
+					commonerrors.ErrDuplicateKeyInsert,
+					fmt.Sprintf(`E11000 duplicate key error collection: %s.%s`, params.DB, params.Collection),
+				)
+			}
+
 			if err != nil {
 				return 0, 0, nil, err
 			}
 
 			matched++
 
+			if upsertRes.Replaced > 0 {
+				modified++
+				continue
+			}
+
+			upserted.Append(must.NotFail(types.NewDocument(
+				"index", int32(upserted.Len()),
+				"_id", must.NotFail(doc.Get("_id")),
+			)))
+
 			continue
 		}
 
@@ -205,6 +260,12 @@ func (h *Handler) updateDocument(ctx context.Context, params *common.UpdatesPara
 		matched += int32(len(resDocs))
 
 		for _, doc := range resDocs {
+			// moderate validation level requires the document before the update
+			var original *types.Document
+			if validator != nil && validator.Level == validationLevelModerate {
+				original = doc.DeepCopy()
+			}
+
 			changed, err := common.UpdateDocument("update", doc, u.Update)
 			if err != nil {
 				return 0, 0, nil, lazyerrors.Error(err)
@@ -214,7 +275,18 @@ func (h *Handler) updateDocument(ctx context.Context, params *common.UpdatesPara
 				continue
 			}
 
+			if err = checkValidator(h.L, validator, original, doc); err != nil {
+				return 0, 0, nil, err
+			}
+
 			updateRes, err := c.Update(ctx, &backends.UpdateParams{Docs: must.NotFail(types.NewArray(doc))})
+			if backends.ErrorCodeIs(err, backends.ErrorCodeIndexDuplicateKey) {
+				return 0, 0, nil, commonerrors.NewWriteErrorMsg(
+					commonerrors.ErrDuplicateKeyInsert,
+					fmt.Sprintf(`E11000 duplicate key error collection: %s.%s`, params.DB, params.Collection),
+				)
+			}
+
 			if err != nil {
 				return 0, 0, nil, lazyerrors.Error(err)
 			}
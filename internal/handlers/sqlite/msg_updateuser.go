@@ -0,0 +1,78 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgUpdateUser implements HandlerInterface.
+func (h *Handler) MsgUpdateUser(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	params, err := common.GetUpdateUserParams(document, h.L)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := h.getUser(ctx, params.DB, params.Username)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if user == nil {
+		return nil, common.UserNotFound(document.Command(), params.DB, params.Username)
+	}
+
+	user = user.DeepCopy()
+	params.Apply(user)
+
+	usersDB, c, err := h.usersCollection()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	defer usersDB.Close()
+
+	res, err := c.Update(ctx, &backends.UpdateParams{
+		Docs: must.NotFail(types.NewArray(user)),
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// the user was dropped concurrently
+	if res.Updated == 0 {
+		return nil, common.UserNotFound(document.Command(), params.DB, params.Username)
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
@@ -16,12 +16,146 @@ package sqlite
 
 import (
 	"context"
+	"fmt"
+	"sort"
 
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgValidate implements HandlerInterface.
 func (h *Handler) MsgValidate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	return common.Validate(ctx, msg, h.L)
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	params, err := common.GetValidateParams(document, h.L)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := h.b.Database(params.DB)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeDatabaseNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid namespace specified '%s.%s'", params.DB, params.Collection)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, "validate")
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+	defer db.Close()
+
+	if err = checkNotView(ctx, db, params.DB, params.Collection); err != nil {
+		return nil, err
+	}
+
+	c, err := db.Collection(params.Collection)
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionNameIsInvalid) {
+			msg := fmt.Sprintf("Invalid collection name: %s", params.Collection)
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrInvalidNamespace, msg, "validate")
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := c.Validate(ctx, &backends.ValidateParams{
+		Repair: params.Repair,
+	})
+
+	switch {
+	case err == nil:
+		// do nothing
+	case backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist):
+		msg := fmt.Sprintf("Collection '%s.%s' does not exist to validate.", params.DB, params.Collection)
+		return nil, commonerrors.NewCommandErrorMsgWithArgument(commonerrors.ErrNamespaceNotFound, msg, "validate")
+	default:
+		return nil, lazyerrors.Error(err)
+	}
+
+	ns := params.DB + "." + params.Collection
+
+	warnings := types.MakeArray(0)
+	errs := types.MakeArray(0)
+
+	if n := len(res.InvalidRecords); n > 0 {
+		h.L.Warn(
+			"Detected invalid documents",
+			zap.String("ns", ns), zap.Int64s("recordIDs", res.InvalidRecords),
+		)
+
+		errs.Append("Detected one or more invalid documents. See logs.")
+	}
+
+	corruptRecords := types.MakeArray(len(res.CorruptRecords))
+	for _, recordID := range res.CorruptRecords {
+		corruptRecords.Append(recordID)
+	}
+
+	if n := len(res.CorruptRecords); n > 0 {
+		h.L.Warn(
+			"Detected corrupt records",
+			zap.String("ns", ns), zap.Int64s("recordIDs", res.CorruptRecords),
+		)
+
+		if res.RemovedRecords > 0 {
+			warnings.Append(fmt.Sprintf("Removed %d corrupt records.", res.RemovedRecords))
+		} else {
+			errs.Append(fmt.Sprintf("Detected %d corrupt records. Run validate with repair: true to remove them.", n))
+		}
+	}
+
+	if res.IndexesRebuilt {
+		warnings.Append("Rebuilt indexes to fix inconsistencies.")
+	}
+
+	for _, e := range res.IndexErrors {
+		errs.Append(e)
+	}
+
+	indexNames := make([]string, 0, len(res.KeysPerIndex))
+	for name := range res.KeysPerIndex {
+		indexNames = append(indexNames, name)
+	}
+
+	sort.Strings(indexNames)
+
+	keysPerIndex := types.MakeDocument(len(indexNames))
+	indexDetails := types.MakeDocument(len(indexNames))
+
+	for _, name := range indexNames {
+		keysPerIndex.Set(name, res.KeysPerIndex[name])
+		indexDetails.Set(name, must.NotFail(types.NewDocument("valid", len(res.IndexErrors) == 0)))
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"ns", ns,
+			"nInvalidDocuments", int32(len(res.InvalidRecords)),
+			"nNonCompliantDocuments", int32(0),
+			"nrecords", int32(res.NRecords),
+			"nIndexes", int32(len(indexNames)),
+			"keysPerIndex", keysPerIndex,
+			"indexDetails", indexDetails,
+			"valid", errs.Len() == 0,
+			"repaired", res.RemovedRecords > 0 || res.IndexesRebuilt,
+			"warnings", warnings,
+			"errors", errs,
+			"extraIndexEntries", types.MakeArray(0),
+			"missingIndexEntries", types.MakeArray(0),
+			"corruptRecords", corruptRecords,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
 }
@@ -0,0 +1,130 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgValidateDBMetadata implements HandlerInterface.
+//
+// It checks indexes and validators stored in the backend metadata
+// against the given API version.
+func (h *Handler) MsgValidateDBMetadata(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	params, err := common.GetValidateDBMetadataParams(document, h.L)
+	if err != nil {
+		return nil, err
+	}
+
+	apiVersionErrors := types.MakeArray(0)
+
+	// only the strict mode restricts stored metadata
+	if params.APIParameters.Strict {
+		list, err := h.b.ListDatabases(ctx, nil)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		for _, dbInfo := range list.Databases {
+			if params.DB != "" && dbInfo.Name != params.DB {
+				continue
+			}
+
+			if err = h.validateDatabaseMetadata(ctx, dbInfo.Name, params.Collection, apiVersionErrors); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"apiVersionErrors", apiVersionErrors,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// validateDatabaseMetadata appends API version errors for the given database's collections to res.
+//
+// If collectionName is not empty, only that collection is checked.
+func (h *Handler) validateDatabaseMetadata(ctx context.Context, dbName, collectionName string, res *types.Array) error {
+	db, err := h.b.Database(dbName)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+	defer db.Close()
+
+	list, err := db.ListCollections(ctx, nil)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	for _, cInfo := range list.Collections {
+		if collectionName != "" && cInfo.Name != collectionName {
+			continue
+		}
+
+		ns := dbName + "." + cInfo.Name
+
+		if cInfo.Validator != nil && cInfo.Validator.Filter != nil {
+			errMsg, err := common.ValidateValidatorAPIStrict(cInfo.Validator.Filter)
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+
+			if errMsg != "" {
+				res.Append(common.APIVersionError(ns, errMsg))
+			}
+		}
+
+		c, err := db.Collection(cInfo.Name)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		indexes, err := c.ListIndexes(ctx, nil)
+		if err != nil {
+			// the collection could be dropped concurrently
+			if backends.ErrorCodeIs(err, backends.ErrorCodeCollectionDoesNotExist) {
+				continue
+			}
+
+			return lazyerrors.Error(err)
+		}
+
+		for i := range indexes.Indexes {
+			if errMsg := common.ValidateIndexAPIStrict(indexDocument(&indexes.Indexes[i])); errMsg != "" {
+				res.Append(common.APIVersionError(ns, errMsg))
+			}
+		}
+	}
+
+	return nil
+}
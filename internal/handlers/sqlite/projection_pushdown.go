@@ -0,0 +1,107 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// projectionPushdown returns top-level fields that could be requested from the backend
+// for the given inclusion projection, or nil if whole documents are needed.
+//
+// Fields used by the filter and sort are also returned, as documents are filtered and sorted by the handler;
+// _id is always returned.
+// Exclusion projections, projection operators, and filters with top-level operators are not pushed down.
+func projectionPushdown(projection, filter, sort *types.Document) []string {
+	if projection.Len() == 0 {
+		return nil
+	}
+
+	res := []string{"_id"}
+
+	add := func(key string) bool {
+		field, _, _ := strings.Cut(key, ".")
+		if field == "" || strings.HasPrefix(field, "$") {
+			return false
+		}
+
+		if !slices.Contains(res, field) {
+			res = append(res, field)
+		}
+
+		return true
+	}
+
+	var inclusion bool
+
+	for _, key := range projection.Keys() {
+		if strings.Contains(key, ".$") {
+			return nil
+		}
+
+		var include bool
+
+		switch v := must.NotFail(projection.Get(key)).(type) {
+		case bool:
+			include = v
+		case float64:
+			include = v != 0
+		case int32:
+			include = v != 0
+		case int64:
+			include = v != 0
+		default:
+			return nil
+		}
+
+		if !include {
+			// only _id could be excluded by the inclusion projection
+			if key != "_id" {
+				return nil
+			}
+
+			continue
+		}
+
+		inclusion = true
+
+		if !add(key) {
+			return nil
+		}
+	}
+
+	if !inclusion {
+		return nil
+	}
+
+	for _, key := range filter.Keys() {
+		if !add(key) {
+			return nil
+		}
+	}
+
+	for _, key := range sort.Keys() {
+		if !add(key) {
+			return nil
+		}
+	}
+
+	return res
+}
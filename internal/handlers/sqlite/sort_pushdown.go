@@ -0,0 +1,61 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// sortPushdown returns sort keys that could be pushed down to the backend for the given sort document,
+// or nil if it can't be pushed down.
+//
+// Only ascending and descending sorts by field paths are returned;
+// invalid sort documents are left for the handler that returns errors for them.
+func sortPushdown(sort *types.Document) []backends.SortParams {
+	// see common.SortDocuments
+	if sort.Len() == 0 || sort.Len() > 32 {
+		return nil
+	}
+
+	res := make([]backends.SortParams, 0, sort.Len())
+
+	for _, key := range sort.Keys() {
+		if key == "" || strings.HasPrefix(key, "$") || strings.Contains(key, ".$") {
+			return nil
+		}
+
+		sortType, err := common.GetSortType(key, must.NotFail(sort.Get(key)))
+		if err != nil {
+			return nil
+		}
+
+		path, err := types.NewPathFromString(key)
+		if err != nil {
+			return nil
+		}
+
+		res = append(res, backends.SortParams{
+			Path:       path,
+			Descending: sortType == types.Descending,
+		})
+	}
+
+	return res
+}
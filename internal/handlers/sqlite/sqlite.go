@@ -18,15 +18,23 @@
 package sqlite
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 
+	"github.com/FerretDB/FerretDB/internal/archive"
 	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/backends/cache"
+	"github.com/FerretDB/FerretDB/internal/backends/multi"
 	"github.com/FerretDB/FerretDB/internal/backends/postgresql"
 	"github.com/FerretDB/FerretDB/internal/backends/sqlite"
 	"github.com/FerretDB/FerretDB/internal/clientconn/connmetrics"
 	"github.com/FerretDB/FerretDB/internal/clientconn/cursor"
 	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
 	"github.com/FerretDB/FerretDB/internal/util/state"
 )
@@ -48,7 +56,15 @@ type Handler struct {
 
 	b backends.Backend
 
-	cursors *cursor.Registry
+	// set only if additional backends are configured
+	backends map[string]backends.Backend
+	mapping  *multi.Mapping
+
+	// set only if archive storage is configured
+	archive *archive.Archive
+
+	cursors    *cursor.Registry
+	parameters *common.Parameters
 }
 
 // NewOpts represents handler configuration.
@@ -64,37 +80,142 @@ type NewOpts struct {
 
 	// test options
 	DisableFilterPushdown bool
+	EnableSortPushdown    bool
+	Backends              map[string]string // additional backends by name; `file:` URIs are for SQLite
+	BackendsMappingFile   string
+	CacheCollections      map[string]int // maximum number of cached point reads by "database.collection"
+	ArchiveURL            string
 }
 
-// New returns a new handler.
-func New(opts *NewOpts) (handlers.Interface, error) {
-	var b backends.Backend
-	var err error
+// defaultBackendName is the name of the main backend when additional backends are configured.
+const defaultBackendName = "default"
 
-	switch opts.Backend {
+// newBackend returns a new backend of the given type.
+func newBackend(backend, uri string, l *zap.Logger) (backends.Backend, error) {
+	switch backend {
 	case "postgresql":
-		b, err = postgresql.NewBackend(&postgresql.NewBackendParams{
-			URI: opts.URI,
-			L:   opts.L,
+		return postgresql.NewBackend(&postgresql.NewBackendParams{
+			URI: uri,
+			L:   l,
 		})
 	case "sqlite":
-		b, err = sqlite.NewBackend(&sqlite.NewBackendParams{
-			URI: opts.URI,
-			L:   opts.L,
+		return sqlite.NewBackend(&sqlite.NewBackendParams{
+			URI: uri,
+			L:   l,
 		})
 	default:
-		panic("unknown backend: " + opts.Backend)
+		panic("unknown backend: " + backend)
 	}
+}
+
+// backendForURI returns the backend type for the given URI of the additional backend.
+func backendForURI(uri string) (string, error) {
+	switch {
+	case strings.HasPrefix(uri, "file:"):
+		return "sqlite", nil
+	case strings.HasPrefix(uri, "postgres://"), strings.HasPrefix(uri, "postgresql://"):
+		return "postgresql", nil
+	default:
+		return "", fmt.Errorf("unexpected backend URI %q", uri)
+	}
+}
 
+// New returns a new handler.
+func New(opts *NewOpts) (handlers.Interface, error) {
+	b, err := newBackend(opts.Backend, opts.URI, opts.L)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Handler{
-		b:       b,
-		NewOpts: opts,
-		cursors: cursor.NewRegistry(opts.L.Named("cursors")),
-	}, nil
+	h := &Handler{
+		b:          b,
+		NewOpts:    opts,
+		cursors:    cursor.NewRegistry(opts.L.Named("cursors")),
+		parameters: common.NewParameters(),
+	}
+
+	if len(opts.Backends) != 0 {
+		if err = h.setupBackends(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(opts.CacheCollections) != 0 {
+		b, err = cache.NewBackend(&cache.NewBackendParams{
+			Backend:     h.b,
+			Collections: opts.CacheCollections,
+		})
+		if err != nil {
+			h.b.Close()
+			return nil, err
+		}
+
+		h.b = b
+	}
+
+	if opts.ArchiveURL != "" {
+		var s archive.Storage
+		if s, err = archive.NewStorage(opts.ArchiveURL); err == nil {
+			h.archive, err = archive.New(context.Background(), s)
+		}
+
+		if err != nil {
+			h.b.Close()
+			return nil, err
+		}
+	}
+
+	return h, nil
+}
+
+// setupBackends creates additional backends and replaces h.b with the multi backend.
+//
+// All backends are closed on error.
+func (h *Handler) setupBackends() error {
+	opts := h.NewOpts
+
+	var err error
+
+	h.backends = map[string]backends.Backend{defaultBackendName: h.b}
+
+	// close already created backends on error
+	closeBackends := func() {
+		for _, b := range h.backends {
+			b.Close()
+		}
+	}
+
+	for name, uri := range opts.Backends {
+		if _, ok := h.backends[name]; ok {
+			closeBackends()
+			return fmt.Errorf("duplicate backend name %q", name)
+		}
+
+		var backend string
+		if backend, err = backendForURI(uri); err == nil {
+			h.backends[name], err = newBackend(backend, uri, opts.L.Named(name))
+		}
+
+		if err != nil {
+			closeBackends()
+			return err
+		}
+	}
+
+	if h.mapping, err = multi.LoadMapping(opts.BackendsMappingFile, defaultBackendName); err == nil {
+		h.b, err = multi.NewBackend(&multi.NewBackendParams{
+			Backends: h.backends,
+			Mapping:  h.mapping,
+			Default:  defaultBackendName,
+		})
+	}
+
+	if err != nil {
+		closeBackends()
+		return err
+	}
+
+	return nil
 }
 
 // Close implements handlers.Interface.
@@ -0,0 +1,105 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// tailableIterator implements types.DocumentsIterator for tailable cursors on capped collections.
+//
+// Unlike other iterators, it does not stay done after returning iterator.ErrIteratorDone.
+// The next call to Next queries the collection again for documents inserted after the last returned one.
+// Only Close makes it permanently done.
+type tailableIterator struct {
+	ctx          context.Context
+	c            backends.Collection
+	iter         types.DocumentsIterator // protected by m
+	lastRecordID int64                   // protected by m
+	m            sync.Mutex
+	closed       bool // protected by m
+}
+
+// newTailableIterator returns a new tailableIterator for the given capped collection
+// that returns documents with record IDs greater than the given one.
+func newTailableIterator(ctx context.Context, c backends.Collection, lastRecordID int64) *tailableIterator {
+	return &tailableIterator{
+		ctx:          ctx,
+		c:            c,
+		lastRecordID: lastRecordID,
+	}
+}
+
+// Next implements iterator.Interface.
+func (iter *tailableIterator) Next() (struct{}, *types.Document, error) {
+	iter.m.Lock()
+	defer iter.m.Unlock()
+
+	var unused struct{}
+
+	if iter.closed {
+		return unused, nil, iterator.ErrIteratorDone
+	}
+
+	if iter.iter == nil {
+		res, err := iter.c.Query(iter.ctx, &backends.QueryParams{RecordIDAfter: iter.lastRecordID})
+		if err != nil {
+			return unused, nil, lazyerrors.Error(err)
+		}
+
+		iter.iter = res.Iter
+	}
+
+	_, doc, err := iter.iter.Next()
+	if err != nil {
+		iter.iter.Close()
+		iter.iter = nil
+
+		if errors.Is(err, iterator.ErrIteratorDone) {
+			return unused, nil, iterator.ErrIteratorDone
+		}
+
+		return unused, nil, lazyerrors.Error(err)
+	}
+
+	iter.lastRecordID = doc.RecordID()
+
+	return unused, doc, nil
+}
+
+// Close implements iterator.Interface.
+func (iter *tailableIterator) Close() {
+	iter.m.Lock()
+	defer iter.m.Unlock()
+
+	if iter.iter != nil {
+		iter.iter.Close()
+		iter.iter = nil
+	}
+
+	iter.closed = true
+}
+
+// check interfaces
+var (
+	_ types.DocumentsIterator = (*tailableIterator)(nil)
+)
@@ -0,0 +1,38 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/clientconn/session"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// BeginTransaction implements handlers.Interface.
+func (h *Handler) BeginTransaction(ctx context.Context) (session.Transaction, error) {
+	txn, err := h.b.BeginTransaction(ctx, &backends.BeginTransactionParams{})
+	if err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeTransactionsNotSupported) {
+			return nil, common.ErrTransactionsNotSupported
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	return txn, nil
+}
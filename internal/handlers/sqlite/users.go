@@ -0,0 +1,65 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// usersCollection returns the backend collection that stores users.
+//
+// The caller should close the returned database.
+func (h *Handler) usersCollection() (backends.Database, backends.Collection, error) {
+	db, err := h.b.Database(common.UsersDatabase)
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	c, err := db.Collection(common.UsersCollection)
+	if err != nil {
+		db.Close()
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	return db, c, nil
+}
+
+// getUser implements common.UserGetter.
+func (h *Handler) getUser(ctx context.Context, db, username string) (*types.Document, error) {
+	usersDB, c, err := h.usersCollection()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	defer usersDB.Close()
+
+	// backend may ignore ID, so FindUser checks _id again
+	res, err := c.Query(ctx, &backends.QueryParams{ID: common.UserID(db, username)})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	defer res.Iter.Close()
+
+	user, err := common.FindUser(res.Iter, db, username)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,209 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonparams"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// Validation levels and actions of collection validators.
+const (
+	validationLevelOff      = "off"
+	validationLevelStrict   = "strict"
+	validationLevelModerate = "moderate"
+
+	validationActionError = "error"
+	validationActionWarn  = "warn"
+)
+
+// errValidationFailed is the error message for documents that failed collection validation.
+const errValidationFailed = "Document failed validation"
+
+// getValidatorParams returns collection validator parameters from the create or collMod command document
+// merged with the current validator (nil for create).
+//
+// It returns nil if the document does not contain validator fields.
+// The returned validator has nil Filter if it should be removed.
+func getValidatorParams(document *types.Document, command string, current *backends.ValidatorParams) (*backends.ValidatorParams, error) { //nolint:lll // for readability
+	if !document.Has("validator") && !document.Has("validationLevel") && !document.Has("validationAction") {
+		return nil, nil
+	}
+
+	res := &backends.ValidatorParams{
+		Level:  validationLevelStrict,
+		Action: validationActionError,
+	}
+
+	if current != nil {
+		*res = *current
+	}
+
+	if v, _ := document.Get("validator"); v != nil {
+		filter, ok := v.(*types.Document)
+		if !ok {
+			return nil, commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrTypeMismatch,
+				fmt.Sprintf(
+					"BSON field '%s.validator' is the wrong type '%s', expected type 'object'",
+					command, commonparams.AliasFromType(v),
+				),
+				command,
+			)
+		}
+
+		// check that the filter is valid
+		if _, err := common.FilterDocument(types.MakeDocument(0), filter); err != nil {
+			return nil, err
+		}
+
+		res.Filter = filter
+		if filter.Len() == 0 {
+			res.Filter = nil
+		}
+	}
+
+	var err error
+
+	if res.Level, err = getValidatorEnum(document, command, "validationLevel", res.Level, []string{
+		validationLevelOff, validationLevelStrict, validationLevelModerate,
+	}); err != nil {
+		return nil, err
+	}
+
+	if res.Action, err = getValidatorEnum(document, command, "validationAction", res.Action, []string{
+		validationActionError, validationActionWarn,
+	}); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// getValidatorEnum returns the value of the given validator field from the command document,
+// or the given current value if the field is not present.
+func getValidatorEnum(document *types.Document, command, field, current string, values []string) (string, error) {
+	v, _ := document.Get(field)
+	if v == nil {
+		return current, nil
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return "", commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field '%s.%s' is the wrong type '%s', expected type 'string'",
+				command, field, commonparams.AliasFromType(v),
+			),
+			command,
+		)
+	}
+
+	if !slices.Contains(values, s) {
+		return "", commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrBadValue,
+			fmt.Sprintf("Enumeration value '%s' for field '%s.%s' is not a valid value.", s, command, field),
+			command,
+		)
+	}
+
+	return s, nil
+}
+
+// collectionValidator returns the validator of the given collection,
+// or nil if the collection does not exist or does not have a validator.
+func collectionValidator(ctx context.Context, db backends.Database, collection string) (*backends.ValidatorParams, error) {
+	res, err := db.ListCollections(ctx, nil)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	for _, c := range res.Collections {
+		if c.Name == collection {
+			return c.Validator, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// validateDocument returns true if the document passes the collection validator or validation is disabled.
+//
+// With the "warn" validation action, failed validation is logged, and true is returned.
+func validateDocument(l *zap.Logger, v *backends.ValidatorParams, doc *types.Document) (bool, error) {
+	if v == nil || v.Level == validationLevelOff {
+		return true, nil
+	}
+
+	matches, err := common.FilterDocument(doc, v.Filter)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	if matches {
+		return true, nil
+	}
+
+	if v.Action == validationActionWarn {
+		id, _ := doc.Get("_id")
+		l.Warn(errValidationFailed, zap.String("_id", types.FormatAnyValue(id)))
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// checkValidator returns a write error if the inserted or updated document fails the collection validator.
+//
+// The original document is nil for inserts.
+// With the "moderate" validation level, updates of original documents that already fail validation are allowed.
+func checkValidator(l *zap.Logger, v *backends.ValidatorParams, original, doc *types.Document) error {
+	if v == nil {
+		return nil
+	}
+
+	if original != nil && v.Level == validationLevelModerate {
+		matches, err := common.FilterDocument(original, v.Filter)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if !matches {
+			return nil
+		}
+	}
+
+	valid, err := validateDocument(l, v, doc)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if !valid {
+		return commonerrors.NewWriteErrorMsg(commonerrors.ErrDocumentValidationFailure, errValidationFailed)
+	}
+
+	return nil
+}
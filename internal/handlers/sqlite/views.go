@@ -0,0 +1,267 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/backends"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations/stages"
+	"github.com/FerretDB/FerretDB/internal/handlers/commonerrors"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/iterator"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// listViews returns documents of all views of the given database.
+func listViews(ctx context.Context, db backends.Database) ([]*types.Document, error) {
+	// avoid querying the collection in the common case of a database without views
+	info, err := collectionInfo(ctx, db, common.ViewsCollection)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if info == nil {
+		return nil, nil
+	}
+
+	c, err := db.Collection(common.ViewsCollection)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res, err := c.Query(ctx, nil)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	defer res.Iter.Close()
+
+	views, err := iterator.ConsumeValues(iterator.Interface[struct{}, *types.Document](res.Iter))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return views, nil
+}
+
+// getView returns the view with the given name resolved down to the underlying collection,
+// or nil if there is no such view.
+func getView(ctx context.Context, db backends.Database, dbName, name string) (*common.View, error) {
+	views, err := listViews(ctx, db)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	iter := iterator.Values(iterator.ForSlice(views))
+	defer iter.Close()
+
+	return common.ResolveView(iter, dbName, name)
+}
+
+// checkNotView returns an error if there is a view with the given name.
+//
+// It is used by commands that modify collections, as views are read-only.
+func checkNotView(ctx context.Context, db backends.Database, dbName, name string) error {
+	views, err := listViews(ctx, db)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	id := common.ViewID(dbName, name)
+
+	for _, view := range views {
+		if must.NotFail(view.Get("_id")) == id {
+			return commonerrors.NewCommandErrorMsg(
+				commonerrors.ErrCommandNotSupportedOnView,
+				fmt.Sprintf("Namespace %s.%s is a view, not a collection", dbName, name),
+			)
+		}
+	}
+
+	return nil
+}
+
+// viewStages returns aggregation stages for the pipeline of the resolved view.
+//
+// Command error codes:
+//   - ErrOptionNotSupportedOnView when the pipeline contains $out or $merge stage.
+func viewStages(view *common.View) ([]aggregations.Stage, error) {
+	res := make([]aggregations.Stage, 0, view.Pipeline.Len())
+
+	for i := 0; i < view.Pipeline.Len(); i++ {
+		d := must.NotFail(view.Pipeline.Get(i)).(*types.Document)
+
+		if name := d.Command(); name == "$out" || name == "$merge" {
+			return nil, commonerrors.NewCommandErrorMsg(
+				commonerrors.ErrOptionNotSupportedOnView,
+				fmt.Sprintf("%s cannot be used in a view definition", name),
+			)
+		}
+
+		s, err := stages.NewStage(d)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, s)
+	}
+
+	return res, nil
+}
+
+// createView creates a new view with the given name on the given collection or view.
+//
+// It returns backend errors for invalid and existing names, like backends.Database.CreateCollection.
+func createView(ctx context.Context, db backends.Database, dbName, name, viewOn string, pipeline *types.Array) error {
+	// views have the same naming rules as collections
+	if _, err := db.Collection(name); err != nil {
+		return err
+	}
+
+	info, err := collectionInfo(ctx, db, name)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if info != nil {
+		return backends.NewError(backends.ErrorCodeCollectionAlreadyExists, nil)
+	}
+
+	if pipeline == nil {
+		pipeline = types.MakeArray(0)
+	}
+
+	view := common.MakeView(dbName, name, viewOn, pipeline)
+
+	if err = validateView(ctx, db, dbName, name, view); err != nil {
+		return err
+	}
+
+	c, err := db.Collection(common.ViewsCollection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if _, err = c.InsertAll(ctx, &backends.InsertAllParams{Docs: []*types.Document{view}}); err != nil {
+		if backends.ErrorCodeIs(err, backends.ErrorCodeInsertDuplicateID) {
+			return backends.NewError(backends.ErrorCodeCollectionAlreadyExists, err)
+		}
+
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// modifyView replaces viewOn and pipeline of the existing view.
+//
+// Command error codes:
+//   - ErrNamespaceNotFound when there is no such view;
+//   - ErrInvalidOptions when the given name is a collection, not a view.
+func modifyView(ctx context.Context, db backends.Database, dbName, name, viewOn string, pipeline *types.Array) error {
+	view, err := getView(ctx, db, dbName, name)
+	if err != nil {
+		return err
+	}
+
+	if view == nil {
+		info, err := collectionInfo(ctx, db, name)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if info != nil {
+			return commonerrors.NewCommandErrorMsgWithArgument(
+				commonerrors.ErrInvalidOptions,
+				"option viewOn is only supported for views",
+				"collMod",
+			)
+		}
+
+		return commonerrors.NewCommandErrorMsgWithArgument(
+			commonerrors.ErrNamespaceNotFound,
+			"ns does not exist",
+			"collMod",
+		)
+	}
+
+	doc := common.MakeView(dbName, name, viewOn, pipeline)
+
+	if err = validateView(ctx, db, dbName, name, doc); err != nil {
+		return err
+	}
+
+	c, err := db.Collection(common.ViewsCollection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if _, err = c.Update(ctx, &backends.UpdateParams{Docs: must.NotFail(types.NewArray(doc))}); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// validateView checks the new or modified view document.
+// Its pipeline should contain valid stages, and views should not form a cycle.
+func validateView(ctx context.Context, db backends.Database, dbName, name string, view *types.Document) error {
+	pipeline := must.NotFail(view.Get("pipeline")).(*types.Array)
+
+	if _, err := viewStages(&common.View{Pipeline: pipeline}); err != nil {
+		return err
+	}
+
+	views, err := listViews(ctx, db)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	id := common.ViewID(dbName, name)
+	all := []*types.Document{view}
+
+	for _, v := range views {
+		if must.NotFail(v.Get("_id")) != id {
+			all = append(all, v)
+		}
+	}
+
+	iter := iterator.Values(iterator.ForSlice(all))
+	defer iter.Close()
+
+	_, err = common.ResolveView(iter, dbName, name)
+
+	return err
+}
+
+// dropView drops the view with the given name.
+// It returns false if there is no such view.
+func dropView(ctx context.Context, db backends.Database, dbName, name string) (bool, error) {
+	c, err := db.Collection(common.ViewsCollection)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	res, err := c.DeleteAll(ctx, &backends.DeleteAllParams{IDs: []any{common.ViewID(dbName, name)}})
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	return res.Deleted > 0, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// deterministicEpoch is the starting point of the deterministic clock.
+var deterministicEpoch = time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// deterministicClock is a clock that advances by a millisecond on each call.
+type deterministicClock struct {
+	m    sync.Mutex
+	next time.Time
+}
+
+// deterministic is a clock used for server-generated values, or nil if deterministic mode is disabled.
+var deterministic atomic.Pointer[deterministicClock]
+
+// SetDeterministic enables deterministic mode for server-generated values:
+// ObjectIDs, timestamps and server's current time returned by Now.
+//
+// Values are derived from the given seed and a clock that starts at a fixed point in time
+// and advances by a millisecond on each call.
+// The same sequence of calls produces the same values,
+// so it is only useful if requests are handled sequentially.
+//
+// It is intended for tests and diff mode only and should be called before any values are generated.
+func SetDeterministic(seed int64) {
+	r := rand.New(rand.NewSource(seed))
+
+	must.NotFail(r.Read(objectIDProcess[:]))
+	objectIDCounter.Store(r.Uint32())
+	atomic.StoreUint32(&timestampCounter, r.Uint32())
+
+	deterministic.Store(&deterministicClock{
+		next: deterministicEpoch,
+	})
+}
+
+// Now returns server's current time that should be used for server-generated values.
+//
+// In deterministic mode (see SetDeterministic), it returns the next time of the deterministic clock.
+func Now() time.Time {
+	c := deterministic.Load()
+	if c == nil {
+		return time.Now()
+	}
+
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	res := c.next
+	c.next = c.next.Add(time.Millisecond)
+
+	return res
+}
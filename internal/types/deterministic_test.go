@@ -0,0 +1,52 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//nolint:paralleltest // we modify the global state
+func TestSetDeterministic(t *testing.T) {
+	t.Cleanup(func() {
+		deterministic.Store(nil)
+	})
+
+	generate := func() (ObjectID, ObjectID, time.Time, Timestamp) {
+		return NewObjectID(), NewObjectID(), Now(), NextTimestamp(Now())
+	}
+
+	SetDeterministic(42)
+	id1, id2, now, ts := generate()
+
+	assert.NotEqual(t, id1, id2)
+	assert.Equal(t, deterministicEpoch.Add(2*time.Millisecond), now)
+
+	SetDeterministic(42)
+	actualID1, actualID2, actualNow, actualTS := generate()
+
+	assert.Equal(t, id1, actualID1)
+	assert.Equal(t, id2, actualID2)
+	assert.Equal(t, now, actualNow)
+	assert.Equal(t, ts, actualTS)
+
+	SetDeterministic(43)
+	otherID1, _, _, _ := generate()
+
+	assert.NotEqual(t, id1, otherID1)
+}
@@ -36,8 +36,10 @@ type document interface {
 // Document represents BSON document: an ordered collection of fields
 // (key/value pairs where key is a string and value is any BSON value).
 type Document struct {
-	fields []field
-	frozen bool
+	fields    []field
+	recordID  int64
+	textScore float64
+	frozen    bool
 }
 
 // field represents a field in the document.
@@ -131,6 +133,45 @@ func (d *Document) Freeze() {
 	}
 }
 
+// RecordID returns the record ID of the document set by the backend, or 0 if it is not set.
+//
+// Record IDs are not a part of the document itself; they are used to track insertion order
+// of documents in capped collections and to return them for the find's showRecordId option.
+func (d *Document) RecordID() int64 {
+	if d == nil {
+		return 0
+	}
+
+	return d.recordID
+}
+
+// SetRecordID sets the record ID of the document.
+func (d *Document) SetRecordID(recordID int64) {
+	d.checkFrozen()
+
+	d.recordID = recordID
+}
+
+// TextScore returns the relevance score of the document for the $text query operator,
+// or 0 if it is not set.
+//
+// Like record IDs, text scores are not a part of the document itself;
+// they are returned by {$meta: "textScore"} projection and used by sort.
+func (d *Document) TextScore() float64 {
+	if d == nil {
+		return 0
+	}
+
+	return d.textScore
+}
+
+// SetTextScore sets the text score of the document.
+func (d *Document) SetTextScore(score float64) {
+	d.checkFrozen()
+
+	d.textScore = score
+}
+
 // checkFrozen panics if document is frozen.
 func (d *Document) checkFrozen() {
 	if d.frozen {
@@ -338,6 +379,12 @@ func (d *Document) GetByPath(path Path) (any, error) {
 	return getByPath(d, path)
 }
 
+// TraversedArray returns the key of the innermost array traversed by the given path, and true if there is one.
+// Only the existing part of the path is traversed; the value at the end of the path may be an array itself.
+func (d *Document) TraversedArray(path Path) (string, bool) {
+	return traversedArray(d, path)
+}
+
 // SetByPath sets value by given path. If the Path has only one element, it sets the value for the given key.
 // If some parts of the path are missing, they will be created.
 // The Document type will be used to create these parts.
@@ -235,8 +235,13 @@ func IsConflictPath(paths []Path, path Path) error {
 }
 
 // getByPath returns a value by path - a sequence of indexes and keys.
+//
+// Errors for non-viable paths (with ErrPathIndexInvalid and ErrPathCannotAccess codes)
+// have the same messages as MongoDB's PathNotViable errors.
 func getByPath[T CompositeTypeInterface](comp T, path Path) (any, error) {
 	var next any = comp
+	var key string
+
 	for _, p := range path.Slice() {
 		switch s := next.(type) {
 		case *Document:
@@ -248,15 +253,8 @@ func getByPath[T CompositeTypeInterface](comp T, path Path) (any, error) {
 
 		case *Array:
 			index, err := strconv.Atoi(p)
-			if err != nil {
-				return nil, newPathError(ErrPathIndexInvalid, fmt.Errorf("types.getByPath: %w", err))
-			}
-
-			if index < 0 {
-				return nil, newPathError(
-					ErrPathIndexInvalid,
-					fmt.Errorf("types.getByPath: array index below zero: %d", index),
-				)
+			if err != nil || index < 0 {
+				return nil, newPathError(ErrPathIndexInvalid, notViableError(path, key, s))
 			}
 
 			next, err = s.Get(index)
@@ -265,16 +263,66 @@ func getByPath[T CompositeTypeInterface](comp T, path Path) (any, error) {
 			}
 
 		default:
-			return nil, newPathError(
-				ErrPathCannotAccess,
-				fmt.Errorf("types.getByPath: can't access %T by path %q", next, p),
-			)
+			return nil, newPathError(ErrPathCannotAccess, notViableError(path, key, s))
 		}
+
+		key = p
 	}
 
 	return next, nil
 }
 
+// notViableError returns an error for the path that can't traverse the value with the given key,
+// with the same message as MongoDB's PathNotViable error.
+func notViableError(path Path, key string, value any) error {
+	return fmt.Errorf(
+		"cannot use the part (%s of %s) to traverse the element ({%s: %s})",
+		key, path, key, FormatAnyValue(value),
+	)
+}
+
+// traversedArray returns the key of the innermost array traversed by the path, and true if there is one.
+//
+// Only the existing part of the path is traversed, and an array is traversed only by its non-negative index,
+// like MongoDB does.
+// The value at the end of the path is not traversed, so it may be an array itself.
+func traversedArray[T CompositeTypeInterface](comp T, path Path) (string, bool) {
+	var key string
+	var found bool
+
+	var next any = comp
+	var nextKey string
+
+	for _, p := range path.Slice() {
+		switch s := next.(type) {
+		case *Document:
+			var err error
+			if next, err = s.Get(p); err != nil {
+				return key, found
+			}
+
+		case *Array:
+			index, err := strconv.Atoi(p)
+			if err != nil || index < 0 {
+				return key, found
+			}
+
+			key, found = nextKey, true
+
+			if next, err = s.Get(index); err != nil {
+				return key, found
+			}
+
+		default:
+			return key, found
+		}
+
+		nextKey = p
+	}
+
+	return key, found
+}
+
 // removeByPath removes path elements for given value, which could be *Document or *Array.
 func removeByPath(v any, path Path) {
 	if path.Len() == 0 {
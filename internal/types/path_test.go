@@ -292,7 +292,7 @@ func TestGetByPath(t *testing.T) {
 		err:  `types.getByPath: types.Document.Get: key not found: "0"`,
 	}, {
 		path: NewStaticPath("compression", "invalid"),
-		err:  `types.getByPath: strconv.Atoi: parsing "invalid": invalid syntax`,
+		err:  `cannot use the part (compression of compression.invalid) to traverse the element ({compression: [ "none" ]})`,
 	}, {
 		path: NewStaticPath("client", "missing"),
 		err:  `types.getByPath: types.Document.Get: key not found: "missing"`,
@@ -301,7 +301,7 @@ func TestGetByPath(t *testing.T) {
 		err:  `types.getByPath: types.Array.Get: index 1 is out of bounds [0-1)`,
 	}, {
 		path: NewStaticPath("compression", "0", "invalid"),
-		err:  `types.getByPath: can't access string by path "invalid"`,
+		err:  `cannot use the part (0 of compression.0.invalid) to traverse the element ({0: "none"})`,
 	}} {
 		tc := tc
 		t.Run(fmt.Sprint(tc.path), func(t *testing.T) {
@@ -320,6 +320,63 @@ func TestGetByPath(t *testing.T) {
 	}
 }
 
+func TestTraversedArray(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(NewDocument(
+		"v", must.NotFail(NewArray(
+			must.NotFail(NewDocument("foo", must.NotFail(NewArray(int32(42))))),
+			must.NotFail(NewArray("bar")),
+		)),
+		"doc", must.NotFail(NewDocument("array", must.NotFail(NewArray(int32(42))))),
+		"scalar", "baz",
+	))
+
+	for _, tc := range []struct { //nolint:vet // for readability
+		path  Path
+		array string
+	}{{
+		path: NewStaticPath("v"),
+	}, {
+		path: NewStaticPath("doc", "array"),
+	}, {
+		path: NewStaticPath("missing", "0"),
+	}, {
+		path: NewStaticPath("scalar", "0"),
+	}, {
+		path: NewStaticPath("v", "foo"),
+	}, {
+		path: NewStaticPath("v", "-1"),
+	}, {
+		path:  NewStaticPath("v", "0"),
+		array: "v",
+	}, {
+		path:  NewStaticPath("v", "0", "foo"),
+		array: "v",
+	}, {
+		path:  NewStaticPath("v", "0", "foo", "0"),
+		array: "foo",
+	}, {
+		path:  NewStaticPath("v", "1", "0"),
+		array: "1",
+	}, {
+		path:  NewStaticPath("v", "10", "foo"),
+		array: "v",
+	}, {
+		path:  NewStaticPath("doc", "array", "0", "foo"),
+		array: "array",
+	}} {
+		tc := tc
+		t.Run(tc.path.String(), func(t *testing.T) {
+			t.Parallel()
+
+			array, ok := doc.TraversedArray(tc.path)
+			assert.Equal(t, tc.array, array)
+			assert.Equal(t, tc.array != "", ok)
+		})
+	}
+}
+
 func TestPathTrimSuffixPrefix(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,193 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// PathWildcard is the path element that matches all fields of the document recursively.
+const PathWildcard = "$**"
+
+// WalkPathFunc is the type of the function called by WalkPath for each value reached by the path.
+//
+// The path argument is the concrete path of the value:
+// array elements traversed implicitly or by positional operators are represented by their indexes,
+// and fields matched by the wildcard by their keys.
+// The value is nil for a missing path if WalkPathOpts.Missing is set.
+type WalkPathFunc func(path Path, value any) error
+
+// WalkPathOpts represents options of WalkPath.
+type WalkPathOpts struct {
+	// Positional is called for values traversed by the all positional operator `$[]` (with an empty identifier)
+	// and filtered positional operators `$[<identifier>]`, including nil for missing values if Missing is set.
+	// It returns indexes of array elements to traverse.
+	// If it is nil, positional operators are handled like field names.
+	Positional func(path Path, identifier string, value any) ([]int, error)
+
+	// ArrayIndexes makes numeric path elements traverse array elements by index.
+	ArrayIndexes bool
+
+	// ArrayDocuments makes path elements traverse all documents of arrays,
+	// like query filters and index keys do; other array elements are skipped.
+	// If ArrayIndexes is also set, it is used only if there is no array element with such index.
+	ArrayDocuments bool
+
+	// Wildcard makes the last `$**` path element match all values within the document recursively,
+	// as wildcard and text indexes do.
+	// Arrays are traversed too if ArrayDocuments is set.
+	Wildcard bool
+
+	// Missing makes WalkPath call the function with nil value for paths that do not exist.
+	// The rest of the path is then added to the concrete path as is.
+	Missing bool
+}
+
+// WalkPath traverses the document by the given path and calls fn for each value reached by it.
+//
+// Unlike GetByPath, it could reach many values, and it never returns errors for non-existent or non-viable paths;
+// it returns only errors returned by fn and WalkPathOpts.Positional.
+func WalkPath(doc *Document, path Path, opts *WalkPathOpts, fn WalkPathFunc) error {
+	if opts == nil {
+		opts = new(WalkPathOpts)
+	}
+
+	w := &pathWalker{
+		opts: opts,
+		fn:   fn,
+	}
+
+	return w.walk(Path{}, doc, path.e)
+}
+
+// pathWalker holds the state of WalkPath.
+type pathWalker struct {
+	opts *WalkPathOpts
+	fn   WalkPathFunc
+}
+
+// walk traverses the value v with the given concrete path by the rest of path elements.
+// The value is nil if the path does not exist.
+func (w *pathWalker) walk(path Path, v any, rest []string) error {
+	if v == nil && !w.opts.Missing {
+		return nil
+	}
+
+	if len(rest) == 0 {
+		return w.fn(path, v)
+	}
+
+	elem := rest[0]
+
+	if w.opts.Wildcard && elem == PathWildcard && len(rest) == 1 && v != nil {
+		return w.walkWildcard(path, v)
+	}
+
+	if w.opts.Positional != nil && strings.HasPrefix(elem, "$[") && strings.HasSuffix(elem, "]") {
+		indexes, err := w.opts.Positional(path, strings.TrimSuffix(strings.TrimPrefix(elem, "$["), "]"), v)
+		if err != nil {
+			return err
+		}
+
+		if len(indexes) == 0 {
+			return nil
+		}
+
+		// Positional returns indexes only for arrays
+		arr := v.(*Array)
+
+		for _, i := range indexes {
+			if err = w.walk(appendPath(path, strconv.Itoa(i)), must.NotFail(arr.Get(i)), rest[1:]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	switch v := v.(type) {
+	case *Document:
+		next, _ := v.Get(elem)
+		return w.walk(appendPath(path, elem), next, rest[1:])
+
+	case *Array:
+		if w.opts.ArrayIndexes {
+			if i, err := strconv.Atoi(elem); err == nil && i >= 0 && i < v.Len() {
+				return w.walk(appendPath(path, elem), must.NotFail(v.Get(i)), rest[1:])
+			}
+		}
+
+		if w.opts.ArrayDocuments {
+			for i := 0; i < v.Len(); i++ {
+				doc, ok := must.NotFail(v.Get(i)).(*Document)
+				if !ok {
+					continue
+				}
+
+				if err := w.walk(appendPath(path, strconv.Itoa(i)), doc, rest); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	}
+
+	// the path does not exist
+	return w.walk(appendPath(path, elem), nil, rest[1:])
+}
+
+// walkWildcard calls fn for all non-document values within the value v with the given concrete path.
+func (w *pathWalker) walkWildcard(path Path, v any) error {
+	switch v := v.(type) {
+	case *Document:
+		for _, field := range v.fields {
+			if err := w.walkWildcard(appendPath(path, field.key), field.value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	case *Array:
+		if !w.opts.ArrayDocuments {
+			break
+		}
+
+		for i, e := range v.s {
+			if err := w.walkWildcard(appendPath(path, strconv.Itoa(i)), e); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return w.fn(path, v)
+}
+
+// appendPath returns a new path with the given element appended.
+//
+// Unlike Path.Append, it does not validate the element,
+// as keys of existing documents could be invalid path elements.
+func appendPath(path Path, elem string) Path {
+	e := make([]string, len(path.e), len(path.e)+1)
+	copy(e, path.e)
+
+	return Path{e: append(e, elem)}
+}
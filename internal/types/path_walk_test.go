@@ -0,0 +1,183 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestWalkPath(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(NewDocument(
+		"v", must.NotFail(NewArray(
+			must.NotFail(NewDocument("foo", int32(1))),
+			int32(2),
+			must.NotFail(NewDocument("foo", must.NotFail(NewDocument("bar", "baz")))),
+			must.NotFail(NewDocument("other", int32(3))),
+		)),
+		"doc", must.NotFail(NewDocument("foo", "bar")),
+	))
+
+	type visited struct {
+		path  string
+		value any
+	}
+
+	for name, tc := range map[string]struct {
+		path     Path
+		opts     *WalkPathOpts
+		expected []visited
+	}{
+		"Field": {
+			path:     NewStaticPath("doc", "foo"),
+			expected: []visited{{"doc.foo", "bar"}},
+		},
+		"MissingSkipped": {
+			path: NewStaticPath("doc", "missing"),
+		},
+		"Missing": {
+			path:     NewStaticPath("doc", "missing", "foo"),
+			opts:     &WalkPathOpts{Missing: true},
+			expected: []visited{{"doc.missing.foo", nil}},
+		},
+		"ArrayIndex": {
+			path:     NewStaticPath("v", "0", "foo"),
+			opts:     &WalkPathOpts{ArrayIndexes: true},
+			expected: []visited{{"v.0.foo", int32(1)}},
+		},
+		"ArrayIndexDisabled": {
+			path: NewStaticPath("v", "0", "foo"),
+		},
+		"ArrayDocuments": {
+			path: NewStaticPath("v", "foo"),
+			opts: &WalkPathOpts{ArrayDocuments: true},
+			expected: []visited{
+				{"v.0.foo", int32(1)},
+				{"v.2.foo", must.NotFail(NewDocument("bar", "baz"))},
+			},
+		},
+		"ArrayDocumentsMissing": {
+			path: NewStaticPath("v", "foo", "bar"),
+			opts: &WalkPathOpts{ArrayDocuments: true, Missing: true},
+			expected: []visited{
+				{"v.0.foo.bar", nil},
+				{"v.2.foo.bar", "baz"},
+				{"v.3.foo.bar", nil},
+			},
+		},
+		"ArrayIndexesAndDocuments": {
+			path: NewStaticPath("v", "2", "foo", "bar"),
+			opts: &WalkPathOpts{ArrayIndexes: true, ArrayDocuments: true},
+			expected: []visited{
+				{"v.2.foo.bar", "baz"},
+			},
+		},
+		"Wildcard": {
+			path: NewStaticPath("v", PathWildcard),
+			opts: &WalkPathOpts{Wildcard: true, ArrayDocuments: true},
+			expected: []visited{
+				{"v.0.foo", int32(1)},
+				{"v.1", int32(2)},
+				{"v.2.foo.bar", "baz"},
+				{"v.3.other", int32(3)},
+			},
+		},
+		"WildcardDocuments": {
+			path: NewStaticPath(PathWildcard),
+			opts: &WalkPathOpts{Wildcard: true},
+			expected: []visited{
+				{"v", must.NotFail(doc.Get("v"))},
+				{"doc.foo", "bar"},
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var actual []visited
+
+			err := WalkPath(doc, tc.path, tc.opts, func(path Path, value any) error {
+				actual = append(actual, visited{path.String(), value})
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestWalkPathPositional(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(NewDocument(
+		"v", must.NotFail(NewArray(
+			must.NotFail(NewDocument("foo", int32(1))),
+			must.NotFail(NewDocument("foo", int32(2))),
+			must.NotFail(NewDocument("foo", int32(3))),
+		)),
+	))
+
+	positional := func(path Path, identifier string, value any) ([]int, error) {
+		arr, ok := value.(*Array)
+		if !ok {
+			return nil, errors.New(path.String() + " is not an array")
+		}
+
+		if identifier == "" {
+			indexes := make([]int, arr.Len())
+			for i := range indexes {
+				indexes[i] = i
+			}
+
+			return indexes, nil
+		}
+
+		// the only matching element
+		return []int{1}, nil
+	}
+
+	var actual []string
+
+	opts := &WalkPathOpts{Positional: positional, ArrayIndexes: true}
+
+	err := WalkPath(doc, NewStaticPath("v", "$[]", "foo"), opts, func(path Path, value any) error {
+		actual = append(actual, path.String())
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v.0.foo", "v.1.foo", "v.2.foo"}, actual)
+
+	actual = nil
+
+	err = WalkPath(doc, NewStaticPath("v", "$[odd]", "foo"), opts, func(path Path, value any) error {
+		actual = append(actual, path.String())
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v.1.foo"}, actual)
+
+	err = WalkPath(doc, NewStaticPath("v", "0", "$[]"), opts, func(path Path, value any) error {
+		return nil
+	})
+	assert.EqualError(t, err, "v.0 is not an array")
+}
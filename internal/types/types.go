@@ -122,7 +122,9 @@ func deepCopy(value any) any {
 		}
 
 		return &Document{
-			fields: fields,
+			fields:    fields,
+			recordID:  value.recordID,
+			textScore: value.textScore,
 		}
 
 	case *Array:
@@ -0,0 +1,310 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package failpoints provides failpoints for injecting errors and latency in tests.
+//
+// Failpoints are configured per listener with the configureFailPoint test command
+// that is available only in debug builds or with the `--enable-test-commands` flag.
+// Handlers and backends evaluate them at their boundaries
+// using the Registry stored in the context.
+package failpoints
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// Known failpoint names.
+const (
+	// FailCommand makes commands fail, block, or close the connection before they are handled.
+	FailCommand = "failCommand"
+
+	// FailBackend makes backend methods fail or block before they are called.
+	FailBackend = "failBackend"
+)
+
+// contextKey is a named unexported type for the safe use of context.WithValue.
+type contextKey struct{}
+
+// Context key for WithRegistry/GetRegistry.
+var registryKey = contextKey{}
+
+// modeKind represents the kind of failpoint's mode.
+type modeKind int
+
+const (
+	modeOff modeKind = iota
+	modeAlwaysOn
+	modeTimes
+	modeSkip
+	modeActivationProbability
+)
+
+// Mode represents failpoint's mode that controls when failpoint is activated.
+type Mode struct {
+	kind        modeKind
+	n           int64
+	probability float64
+}
+
+// Off returns mode that disables failpoint.
+func Off() Mode {
+	return Mode{kind: modeOff}
+}
+
+// AlwaysOn returns mode that activates failpoint every time it is evaluated.
+func AlwaysOn() Mode {
+	return Mode{kind: modeAlwaysOn}
+}
+
+// Times returns mode that activates failpoint the next n times it is evaluated, then disables it.
+func Times(n int64) Mode {
+	return Mode{kind: modeTimes, n: n}
+}
+
+// Skip returns mode that does not activate failpoint the next n times it is evaluated,
+// then activates it every time.
+func Skip(n int64) Mode {
+	return Mode{kind: modeSkip, n: n}
+}
+
+// ActivationProbability returns mode that activates failpoint with the given probability in [0, 1].
+func ActivationProbability(p float64) Mode {
+	return Mode{kind: modeActivationProbability, probability: p}
+}
+
+// FailPoint represents a single failpoint.
+//
+// It is safe for concurrent use.
+type FailPoint struct {
+	rw           sync.RWMutex
+	mode         Mode
+	data         *types.Document
+	timesEntered int64
+}
+
+// Configure sets failpoint's mode and data, and returns the number of times
+// the failpoint was activated before.
+// Like in MongoDB, that number is never reset, so it could be used with Wait.
+//
+// Data is frozen.
+func (fp *FailPoint) Configure(mode Mode, data *types.Document) int64 {
+	if data == nil {
+		data = new(types.Document)
+	}
+
+	data.Freeze()
+
+	fp.rw.Lock()
+	defer fp.rw.Unlock()
+
+	fp.mode = mode
+	fp.data = data
+
+	return fp.timesEntered
+}
+
+// Evaluate returns failpoint's data and true if failpoint is activated.
+//
+// The match function is called with failpoint's data to check whether failpoint applies to the caller;
+// if it returns false, failpoint is not activated and mode's counters are not changed.
+func (fp *FailPoint) Evaluate(match func(data *types.Document) bool) (*types.Document, bool) {
+	fp.rw.RLock()
+	off := fp.mode.kind == modeOff
+	fp.rw.RUnlock()
+
+	// fast path for the common case
+	if off {
+		return nil, false
+	}
+
+	fp.rw.Lock()
+	defer fp.rw.Unlock()
+
+	if fp.mode.kind == modeOff || !match(fp.data) {
+		return nil, false
+	}
+
+	switch fp.mode.kind {
+	case modeAlwaysOn:
+		// nothing
+
+	case modeTimes:
+		fp.mode.n--
+		if fp.mode.n <= 0 {
+			fp.mode = Off()
+		}
+
+	case modeSkip:
+		if fp.mode.n > 0 {
+			fp.mode.n--
+			return nil, false
+		}
+
+	case modeActivationProbability:
+		if rand.Float64() >= fp.mode.probability {
+			return nil, false
+		}
+
+	default:
+		panic("unexpected mode")
+	}
+
+	fp.timesEntered++
+
+	return fp.data, true
+}
+
+// TimesEntered returns the total number of times the failpoint was activated.
+func (fp *FailPoint) TimesEntered() int64 {
+	fp.rw.RLock()
+	defer fp.rw.RUnlock()
+
+	return fp.timesEntered
+}
+
+// Wait blocks until the total number of failpoint activations is at least n.
+//
+// It returns context's error if ctx is canceled before that.
+func (fp *FailPoint) Wait(ctx context.Context, n int64) error {
+	t := time.NewTicker(10 * time.Millisecond)
+	defer t.Stop()
+
+	for fp.TimesEntered() < n {
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+
+	return nil
+}
+
+// Registry contains all known failpoints.
+//
+// It is safe for concurrent use.
+type Registry struct {
+	fps map[string]*FailPoint
+}
+
+// NewRegistry creates a new Registry with all known failpoints disabled.
+func NewRegistry() *Registry {
+	return &Registry{
+		fps: map[string]*FailPoint{
+			FailCommand: new(FailPoint),
+			FailBackend: new(FailPoint),
+		},
+	}
+}
+
+// Get returns failpoint with the given name, or nil if there is no such failpoint.
+func (r *Registry) Get(name string) *FailPoint {
+	return r.fps[name]
+}
+
+// Names returns sorted names of all known failpoints.
+func (r *Registry) Names() []string {
+	res := maps.Keys(r.fps)
+	slices.Sort(res)
+
+	return res
+}
+
+// Evaluate is a shortcut for evaluating failpoint with the given name from the Registry stored in ctx.
+//
+// If there is no Registry in ctx, it returns false.
+func Evaluate(ctx context.Context, name string, match func(data *types.Document) bool) (*types.Document, bool) {
+	r := GetRegistry(ctx)
+	if r == nil {
+		return nil, false
+	}
+
+	fp := r.Get(name)
+	if fp == nil {
+		panic("unknown failpoint " + name)
+	}
+
+	return fp.Evaluate(match)
+}
+
+// Block blocks for the duration specified by blockTimeMS field of failpoint's data, if any.
+//
+// It returns context's error if ctx is canceled before that.
+func Block(ctx context.Context, data *types.Document) error {
+	var ms int64
+
+	switch v, _ := data.Get("blockTimeMS"); v := v.(type) {
+	case float64:
+		ms = int64(v)
+	case int32:
+		ms = int64(v)
+	case int64:
+		ms = v
+	}
+
+	if ms <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(time.Duration(ms) * time.Millisecond)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// Contains returns true if the given array field of failpoint's data contains the given string.
+// If data does not have that field, it returns def.
+func Contains(data *types.Document, field, s string, def bool) bool {
+	v, _ := data.Get(field)
+	if v == nil {
+		return def
+	}
+
+	arr, ok := v.(*types.Array)
+	if !ok {
+		return false
+	}
+
+	for i := 0; i < arr.Len(); i++ {
+		if e, _ := arr.Get(i); e == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithRegistry returns a new context with the given Registry.
+func WithRegistry(ctx context.Context, r *Registry) context.Context {
+	return context.WithValue(ctx, registryKey, r)
+}
+
+// GetRegistry returns the Registry stored in ctx, or nil.
+func GetRegistry(ctx context.Context) *Registry {
+	r, _ := ctx.Value(registryKey).(*Registry)
+	return r
+}
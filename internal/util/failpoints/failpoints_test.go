@@ -0,0 +1,156 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package failpoints
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestFailPoint(t *testing.T) {
+	t.Parallel()
+
+	match := func(*types.Document) bool { return true }
+
+	// evaluate returns the number of activations for n evaluations
+	evaluate := func(fp *FailPoint, n int) int {
+		var res int
+
+		for i := 0; i < n; i++ {
+			if _, ok := fp.Evaluate(match); ok {
+				res++
+			}
+		}
+
+		return res
+	}
+
+	for name, tc := range map[string]struct {
+		mode     Mode
+		expected int
+	}{
+		"Off": {
+			mode:     Off(),
+			expected: 0,
+		},
+		"AlwaysOn": {
+			mode:     AlwaysOn(),
+			expected: 10,
+		},
+		"Times": {
+			mode:     Times(3),
+			expected: 3,
+		},
+		"Skip": {
+			mode:     Skip(3),
+			expected: 7,
+		},
+		"ActivationProbabilityZero": {
+			mode:     ActivationProbability(0),
+			expected: 0,
+		},
+		"ActivationProbabilityOne": {
+			mode:     ActivationProbability(1),
+			expected: 10,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var fp FailPoint
+			assert.Equal(t, int64(0), fp.Configure(tc.mode, nil))
+			assert.Equal(t, tc.expected, evaluate(&fp, 10))
+			assert.Equal(t, int64(tc.expected), fp.Configure(Off(), nil))
+		})
+	}
+
+	t.Run("Match", func(t *testing.T) {
+		t.Parallel()
+
+		var fp FailPoint
+		fp.Configure(Times(1), must.NotFail(types.NewDocument("failCommands", must.NotFail(types.NewArray("find")))))
+
+		notFind := func(data *types.Document) bool { return Contains(data, "failCommands", "insert", false) }
+		_, ok := fp.Evaluate(notFind)
+		assert.False(t, ok)
+
+		find := func(data *types.Document) bool { return Contains(data, "failCommands", "find", false) }
+		data, ok := fp.Evaluate(find)
+		require.True(t, ok)
+		assert.True(t, data.Has("failCommands"))
+
+		_, ok = fp.Evaluate(find)
+		assert.False(t, ok)
+	})
+}
+
+func TestFailPointWait(t *testing.T) {
+	t.Parallel()
+
+	match := func(*types.Document) bool { return true }
+
+	var fp FailPoint
+	fp.Configure(AlwaysOn(), nil)
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			time.Sleep(10 * time.Millisecond)
+			fp.Evaluate(match)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, fp.Wait(ctx, 3))
+	assert.Equal(t, int64(3), fp.TimesEntered())
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, fp.Wait(ctx, 4), context.DeadlineExceeded)
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	_, ok := Evaluate(ctx, FailCommand, func(*types.Document) bool { return true })
+	assert.False(t, ok, "no registry")
+
+	r := NewRegistry()
+	assert.Equal(t, []string{FailBackend, FailCommand}, r.Names())
+	assert.Nil(t, r.Get("noSuchFailPoint"))
+
+	ctx = WithRegistry(ctx, r)
+	assert.Same(t, r, GetRegistry(ctx))
+
+	r.Get(FailBackend).Configure(AlwaysOn(), nil)
+
+	_, ok = Evaluate(ctx, FailBackend, func(*types.Document) bool { return true })
+	assert.True(t, ok)
+
+	_, ok = Evaluate(ctx, FailCommand, func(*types.Document) bool { return true })
+	assert.False(t, ok)
+}
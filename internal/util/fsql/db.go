@@ -23,6 +23,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 
+	"github.com/FerretDB/FerretDB/internal/util/lasterror"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/observability"
 	"github.com/FerretDB/FerretDB/internal/util/resource"
@@ -35,9 +36,10 @@ import (
 type DB struct {
 	*metricsCollector
 
-	sqlDB *sql.DB
-	l     *zap.Logger
-	token *resource.Token
+	sqlDB     *sql.DB
+	l         *zap.Logger
+	token     *resource.Token
+	lastError *lasterror.Tracker
 }
 
 // WrapDB creates a new DB.
@@ -54,6 +56,7 @@ func WrapDB(db *sql.DB, name string, l *zap.Logger) *DB {
 		sqlDB:            db,
 		l:                l.Named(name),
 		token:            resource.NewToken(),
+		lastError:        new(lasterror.Tracker),
 	}
 
 	resource.Track(res, res.token)
@@ -77,6 +80,7 @@ func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*Row
 	db.l.Sugar().With(fields...).Debugf(">>> %s", query)
 
 	rows, err := db.sqlDB.QueryContext(ctx, query, args...)
+	db.lastError.Record(err)
 
 	fields = append(fields, zap.Duration("time", time.Since(start)), zap.Error(err))
 	db.l.Sugar().With(fields...).Debugf("<<< %s", query)
@@ -94,6 +98,7 @@ func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *s
 	db.l.Sugar().With(fields...).Debugf(">>> %s", query)
 
 	row := db.sqlDB.QueryRowContext(ctx, query, args...)
+	db.lastError.Record(row.Err())
 
 	fields = append(fields, zap.Duration("time", time.Since(start)), zap.Error(row.Err()))
 	db.l.Sugar().With(fields...).Debugf("<<< %s", query)
@@ -111,6 +116,7 @@ func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.R
 	db.l.Sugar().With(fields...).Debugf(">>> %s", query)
 
 	res, err := db.sqlDB.ExecContext(ctx, query, args...)
+	db.lastError.Record(err)
 
 	// to differentiate between 0 and nil
 	var ra *int64
@@ -135,11 +141,12 @@ func (db *DB) InTransaction(ctx context.Context, f func(*Tx) error) (err error)
 	var sqlTx *sql.Tx
 
 	if sqlTx, err = db.sqlDB.BeginTx(ctx, nil); err != nil {
+		db.lastError.Record(err)
 		err = lazyerrors.Error(err)
 		return
 	}
 
-	tx := wrapTx(sqlTx, db.l)
+	tx := wrapTx(sqlTx, db.l, db.lastError)
 
 	var done bool
 
@@ -177,6 +184,16 @@ func (db *DB) InTransaction(ctx context.Context, f func(*Tx) error) (err error)
 	return
 }
 
+// Stats calls [*sql.DB.Stats].
+func (db *DB) Stats() sql.DBStats {
+	return db.sqlDB.Stats()
+}
+
+// LastError returns the last error returned by the database, or nil if there were no errors.
+func (db *DB) LastError() *lasterror.Entry {
+	return db.lastError.Last()
+}
+
 // check interfaces
 var (
 	_ prometheus.Collector = (*DB)(nil)
@@ -21,6 +21,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/FerretDB/FerretDB/internal/util/lasterror"
 	"github.com/FerretDB/FerretDB/internal/util/observability"
 	"github.com/FerretDB/FerretDB/internal/util/resource"
 )
@@ -29,21 +30,23 @@ import (
 //
 // It exposes the subset of *sql.Tx methods we use.
 type Tx struct {
-	sqlTx *sql.Tx
-	l     *zap.Logger
-	token *resource.Token
+	sqlTx     *sql.Tx
+	l         *zap.Logger
+	token     *resource.Token
+	lastError *lasterror.Tracker
 }
 
 // wrapTx creates new Tx.
-func wrapTx(tx *sql.Tx, l *zap.Logger) *Tx {
+func wrapTx(tx *sql.Tx, l *zap.Logger, lastError *lasterror.Tracker) *Tx {
 	if tx == nil {
 		return nil
 	}
 
 	res := &Tx{
-		sqlTx: tx,
-		l:     l,
-		token: resource.NewToken(),
+		sqlTx:     tx,
+		l:         l,
+		token:     resource.NewToken(),
+		lastError: lastError,
 	}
 
 	resource.Track(res, res.token)
@@ -54,7 +57,11 @@ func wrapTx(tx *sql.Tx, l *zap.Logger) *Tx {
 // Commit calls [*sql.Tx.Commit].
 func (tx *Tx) Commit() error {
 	resource.Untrack(tx, tx.token)
-	return tx.sqlTx.Commit()
+
+	err := tx.sqlTx.Commit()
+	tx.lastError.Record(err)
+
+	return err
 }
 
 // Rollback calls [*sql.Tx.Rollback].
@@ -73,6 +80,7 @@ func (tx *Tx) QueryContext(ctx context.Context, query string, args ...any) (*Row
 	tx.l.Sugar().With(fields...).Debugf(">>> %s", query)
 
 	rows, err := tx.sqlTx.QueryContext(ctx, query, args...)
+	tx.lastError.Record(err)
 
 	fields = append(fields, zap.Duration("time", time.Since(start)), zap.Error(err))
 	tx.l.Sugar().With(fields...).Debugf("<<< %s", query)
@@ -90,6 +98,7 @@ func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...any) *s
 	tx.l.Sugar().With(fields...).Debugf(">>> %s", query)
 
 	row := tx.sqlTx.QueryRowContext(ctx, query, args...)
+	tx.lastError.Record(row.Err())
 
 	fields = append(fields, zap.Duration("time", time.Since(start)), zap.Error(row.Err()))
 	tx.l.Sugar().With(fields...).Debugf("<<< %s", query)
@@ -107,6 +116,7 @@ func (tx *Tx) ExecContext(ctx context.Context, query string, args ...any) (sql.R
 	tx.l.Sugar().With(fields...).Debugf(">>> %s", query)
 
 	res, err := tx.sqlTx.ExecContext(ctx, query, args...)
+	tx.lastError.Record(err)
 
 	// to differentiate between 0 and nil
 	var ra *int64
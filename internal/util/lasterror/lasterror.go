@@ -0,0 +1,75 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lasterror provides a tracker of the last backend error.
+package lasterror
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Entry represents a recorded error.
+type Entry struct {
+	Err  error
+	Time time.Time
+}
+
+// Tracker records the last error.
+//
+// The zero value is ready to use. It is safe for concurrent use.
+type Tracker struct {
+	m    sync.Mutex
+	last *Entry
+}
+
+// Record records the given error if it is not nil.
+//
+// Context cancellation and deadline errors are not recorded as they are caused by clients, not backends.
+func (t *Tracker) Record(err error) {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return
+	}
+
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	t.last = &Entry{
+		Err:  err,
+		Time: time.Now(),
+	}
+}
+
+// Last returns the last recorded error, or nil if there were no errors.
+func (t *Tracker) Last() *Entry {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	return t.last
+}
+
+// Latest returns the most recent of the given entries, or nil if all of them are nil.
+func Latest(entries ...*Entry) *Entry {
+	var res *Entry
+
+	for _, e := range entries {
+		if e != nil && (res == nil || e.Time.After(res.Time)) {
+			res = e
+		}
+	}
+
+	return res
+}
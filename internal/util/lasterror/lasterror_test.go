@@ -0,0 +1,66 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lasterror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	t.Parallel()
+
+	var tr Tracker
+	assert.Nil(t, tr.Last())
+
+	tr.Record(nil)
+	tr.Record(context.Canceled)
+	tr.Record(fmt.Errorf("wrapped: %w", context.DeadlineExceeded))
+	assert.Nil(t, tr.Last())
+
+	err1 := errors.New("first")
+	tr.Record(err1)
+
+	first := tr.Last()
+	require.NotNil(t, first)
+	assert.Equal(t, err1, first.Err)
+
+	err2 := errors.New("second")
+	tr.Record(err2)
+
+	second := tr.Last()
+	require.NotNil(t, second)
+	assert.Equal(t, err2, second.Err)
+
+}
+
+func TestLatest(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	older := &Entry{Err: errors.New("older"), Time: now.Add(-time.Second)}
+	newer := &Entry{Err: errors.New("newer"), Time: now}
+
+	assert.Nil(t, Latest())
+	assert.Nil(t, Latest(nil, nil))
+	assert.Same(t, newer, Latest(older, nil, newer))
+	assert.Same(t, newer, Latest(newer, older))
+}
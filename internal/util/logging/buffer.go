@@ -35,6 +35,7 @@ type circularBuffer struct {
 	mu    sync.RWMutex
 	log   []*zapcore.Entry
 	index int64
+	total int64 // number of entries appended since creation, including overwritten ones
 }
 
 // NewCircularBuffer creates a circular buffer for log entries in memory.
@@ -55,6 +56,16 @@ func (l *circularBuffer) append(entry *zapcore.Entry) {
 
 	l.log[l.index] = entry
 	l.index = (l.index + 1) % int64(len(l.log))
+	l.total++
+}
+
+// TotalLinesWritten returns the number of entries appended to circularBuffer since its creation,
+// including entries that were already overwritten.
+func (l *circularBuffer) TotalLinesWritten() int64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.total
 }
 
 // get returns entries from circularBuffer with level at minLevel or above.
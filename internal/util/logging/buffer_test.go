@@ -109,6 +109,8 @@ func TestCircularBuffer(t *testing.T) {
 		})
 	}
 
+	assert.Equal(t, int64(3), logram.TotalLinesWritten())
+
 	Setup(zap.DebugLevel, "")
 	logger := zap.L()
 	for n, tc := range []struct {
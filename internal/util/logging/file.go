@@ -0,0 +1,233 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// rotatedTimeFormat is the time format of rotated log file name suffixes.
+// It sorts lexicographically in time order.
+const rotatedTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotatingFile is a log file that is rotated when it reaches the maximum size,
+// when it becomes older than the maximum age, or when Rotate is called.
+//
+// Rotated files are renamed by adding the UTC timestamp suffix, like MongoDB's "rename" mode does.
+//
+//nolint:vet // for readability
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+
+	m      sync.Mutex
+	f      *os.File
+	size   int64
+	opened time.Time
+}
+
+// NewRotatingFileOpts represents options of NewRotatingFile.
+type NewRotatingFileOpts struct {
+	Path       string
+	MaxSize    int64         // in bytes; if zero, there is no size limit
+	MaxAge     time.Duration // if zero, there is no age limit
+	MaxBackups int           // if zero, all rotated files are kept
+}
+
+// NewRotatingFile opens or creates the log file.
+func NewRotatingFile(opts *NewRotatingFileOpts) (*RotatingFile, error) {
+	if opts.Path == "" {
+		return nil, lazyerrors.New("log file path is empty")
+	}
+
+	rf := &RotatingFile{
+		path:       opts.Path,
+		maxSize:    opts.MaxSize,
+		maxAge:     opts.MaxAge,
+		maxBackups: opts.MaxBackups,
+	}
+
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+
+	return rf, nil
+}
+
+// Write implements io.Writer.
+//
+// The file is rotated before writing if the size or age limit is reached.
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.m.Lock()
+	defer rf.m.Unlock()
+
+	if rf.f == nil {
+		return 0, lazyerrors.New("log file is closed")
+	}
+
+	sizeExceeded := rf.maxSize > 0 && rf.size > 0 && rf.size+int64(len(p)) > rf.maxSize
+	ageExceeded := rf.maxAge > 0 && time.Since(rf.opened) > rf.maxAge
+
+	if sizeExceeded || ageExceeded {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.f.Write(p)
+	rf.size += int64(n)
+
+	return n, err
+}
+
+// Sync implements zapcore.WriteSyncer.
+func (rf *RotatingFile) Sync() error {
+	rf.m.Lock()
+	defer rf.m.Unlock()
+
+	if rf.f == nil {
+		return nil
+	}
+
+	return rf.f.Sync()
+}
+
+// Rotate renames the current log file and opens a new one.
+func (rf *RotatingFile) Rotate() error {
+	rf.m.Lock()
+	defer rf.m.Unlock()
+
+	if rf.f == nil {
+		return lazyerrors.New("log file is closed")
+	}
+
+	return rf.rotate()
+}
+
+// Close closes the log file.
+func (rf *RotatingFile) Close() error {
+	rf.m.Lock()
+	defer rf.m.Unlock()
+
+	if rf.f == nil {
+		return nil
+	}
+
+	err := rf.f.Close()
+	rf.f = nil
+
+	return err
+}
+
+// open opens or creates the log file.
+//
+// It should be called with the mutex held.
+func (rf *RotatingFile) open() error {
+	f, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o666)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return lazyerrors.Error(err)
+	}
+
+	rf.f = f
+	rf.size = fi.Size()
+	rf.opened = time.Now()
+
+	return nil
+}
+
+// rotate renames the current log file, opens a new one, and removes old rotated files.
+//
+// It should be called with the mutex held.
+func (rf *RotatingFile) rotate() error {
+	if err := rf.f.Close(); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	rf.f = nil
+
+	rotated := rf.path + "." + time.Now().UTC().Format(rotatedTimeFormat)
+	if err := os.Rename(rf.path, rotated); err != nil {
+		// try to continue writing to the current file
+		if openErr := rf.open(); openErr != nil {
+			return lazyerrors.Error(openErr)
+		}
+
+		return lazyerrors.Error(err)
+	}
+
+	if err := rf.open(); err != nil {
+		return err
+	}
+
+	return rf.removeBackups()
+}
+
+// removeBackups removes the oldest rotated files above the maximum number.
+//
+// It should be called with the mutex held.
+func (rf *RotatingFile) removeBackups() error {
+	if rf.maxBackups <= 0 {
+		return nil
+	}
+
+	matches, err := filepath.Glob(rf.path + ".*")
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	// skip unrelated files like compressed backups
+	var backups []string
+
+	for _, m := range matches {
+		if _, err = time.Parse(rotatedTimeFormat, strings.TrimPrefix(m, rf.path+".")); err == nil {
+			backups = append(backups, m)
+		}
+	}
+
+	// names differ only by timestamps, so lexicographical order is the time order
+	sort.Strings(backups)
+
+	for len(backups) > rf.maxBackups {
+		if err = os.Remove(backups[0]); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
+// check interfaces
+var (
+	_ zapcore.WriteSyncer = (*RotatingFile)(nil)
+)
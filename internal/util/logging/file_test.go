@@ -0,0 +1,114 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readBackups returns contents of rotated files in the time order.
+func readBackups(t *testing.T, path string) []string {
+	t.Helper()
+
+	matches, err := filepath.Glob(path + ".*")
+	require.NoError(t, err)
+
+	res := make([]string, len(matches))
+
+	for i, m := range matches {
+		b, err := os.ReadFile(m)
+		require.NoError(t, err)
+
+		res[i] = string(b)
+	}
+
+	return res
+}
+
+func TestRotatingFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Size", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "ferretdb.log")
+
+		rf, err := NewRotatingFile(&NewRotatingFileOpts{
+			Path:    path,
+			MaxSize: 10,
+		})
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			require.NoError(t, rf.Close())
+		})
+
+		_, err = rf.Write([]byte("12345\n"))
+		require.NoError(t, err)
+
+		_, err = rf.Write([]byte("1234\n"))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"12345\n"}, readBackups(t, path))
+
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "1234\n", string(b))
+	})
+
+	t.Run("RotateAndBackups", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "ferretdb.log")
+
+		// unrelated file should be kept
+		other := path + ".old"
+		require.NoError(t, os.WriteFile(other, []byte("other"), 0o666))
+
+		rf, err := NewRotatingFile(&NewRotatingFileOpts{
+			Path:       path,
+			MaxBackups: 2,
+		})
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			require.NoError(t, rf.Close())
+		})
+
+		for _, s := range []string{"a", "b", "c"} {
+			_, err = rf.Write([]byte(s))
+			require.NoError(t, err)
+
+			require.NoError(t, rf.Rotate())
+
+			// make rotated file names different
+			time.Sleep(2 * time.Millisecond)
+		}
+
+		assert.Equal(t, []string{"b", "c", "other"}, readBackups(t, path))
+
+		require.NoError(t, rf.Close())
+		assert.Error(t, rf.Rotate())
+
+		_, err = rf.Write([]byte("d"))
+		assert.Error(t, err)
+	})
+}
@@ -17,6 +17,7 @@ package logging
 
 import (
 	"log"
+	"sync/atomic"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -24,10 +25,34 @@ import (
 	"github.com/FerretDB/FerretDB/build/version"
 )
 
+// Level is the level of the logger initialized by Setup.
+//
+// It could be changed at runtime with the setParameter command.
+var Level = zap.NewAtomicLevel()
+
+// Quiet is true if messages about started and stopped connections should be logged at the debug level,
+// like in MongoDB's quiet mode.
+//
+// It could be changed at runtime with the setParameter command.
+var Quiet atomic.Bool
+
+// logFile is the log file set up by SetupWithFile, if any.
+var logFile atomic.Pointer[RotatingFile]
+
 // Setup initializes logging with a given level.
 func Setup(level zapcore.Level, uuid string) {
+	SetupWithFile(level, uuid, nil)
+}
+
+// SetupWithFile initializes logging with a given level.
+//
+// If file is not nil, logs are written to it in addition to the standard error,
+// and it could be rotated with Rotate.
+func SetupWithFile(level zapcore.Level, uuid string, file *RotatingFile) {
+	Level.SetLevel(level)
+
 	config := zap.Config{
-		Level:             zap.NewAtomicLevelAt(level),
+		Level:             Level,
 		Development:       version.Get().DebugBuild,
 		DisableCaller:     false,
 		DisableStacktrace: false,
@@ -56,11 +81,21 @@ func Setup(level zapcore.Level, uuid string) {
 		config.InitialFields = map[string]any{"uuid": uuid}
 	}
 
-	logger, err := config.Build()
+	var opts []zap.Option
+	if file != nil {
+		fileCore := zapcore.NewCore(zapcore.NewConsoleEncoder(config.EncoderConfig), file, Level)
+		opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(core, fileCore)
+		}))
+	}
+
+	logger, err := config.Build(opts...)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	logFile.Store(file)
+
 	logger = logger.WithOptions(zap.Hooks(func(entry zapcore.Entry) error {
 		RecentEntries.append(&entry)
 		return nil
@@ -77,3 +112,15 @@ func setupWithLogger(logger *zap.Logger) {
 		log.Fatal(err)
 	}
 }
+
+// Rotate rotates the log file set up by SetupWithFile.
+//
+// It does nothing if logging to file is not enabled.
+func Rotate() error {
+	file := logFile.Load()
+	if file == nil {
+		return nil
+	}
+
+	return file.Rotate()
+}
@@ -0,0 +1,169 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+//go:generate ../../bin/stringer -linecomment -type CompressorID
+
+// CompressorID represents OP_COMPRESSED compressor identifier.
+type CompressorID uint8
+
+const (
+	// CompressorNoop does not compress messages.
+	// It can't be negotiated, but clients may use it for messages that should not be compressed.
+	CompressorNoop = CompressorID(0) // noop
+
+	// CompressorSnappy uses snappy block format.
+	CompressorSnappy = CompressorID(1) // snappy
+
+	// CompressorZlib uses zlib format.
+	CompressorZlib = CompressorID(2) // zlib
+
+	// CompressorZstd uses zstd format.
+	CompressorZstd = CompressorID(3) // zstd
+)
+
+// SupportedCompressors contains names of compressors that could be negotiated by clients,
+// in the order of server preference.
+var SupportedCompressors = []string{
+	CompressorSnappy.String(),
+	CompressorZlib.String(),
+	CompressorZstd.String(),
+}
+
+// zstd encoder and decoder are safe for concurrent use of EncodeAll/DecodeAll,
+// but relatively expensive to create, so they are created once.
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	zstdErr     error
+)
+
+// initZstd initializes zstd encoder and decoder.
+func initZstd() error {
+	zstdOnce.Do(func() {
+		if zstdEncoder, zstdErr = zstd.NewWriter(nil); zstdErr != nil {
+			return
+		}
+
+		zstdDecoder, zstdErr = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(MaxMsgLen))
+	})
+
+	return zstdErr
+}
+
+// compress compresses b with the given compressor.
+func compress(compressor CompressorID, b []byte) ([]byte, error) {
+	switch compressor {
+	case CompressorNoop:
+		return b, nil
+
+	case CompressorSnappy:
+		return snappy.Encode(nil, b), nil
+
+	case CompressorZlib:
+		var buf bytes.Buffer
+
+		w := zlib.NewWriter(&buf)
+		if _, err := w.Write(b); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if err := w.Close(); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		return buf.Bytes(), nil
+
+	case CompressorZstd:
+		if err := initZstd(); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		return zstdEncoder.EncodeAll(b, nil), nil
+
+	default:
+		return nil, lazyerrors.Errorf("unsupported compressor %s", compressor)
+	}
+}
+
+// decompress decompresses b with the given compressor.
+//
+// The result is expected to have the given size.
+func decompress(compressor CompressorID, b []byte, size int32) ([]byte, error) {
+	var res []byte
+	var err error
+
+	switch compressor {
+	case CompressorNoop:
+		res = b
+
+	case CompressorSnappy:
+		var n int
+		if n, err = snappy.DecodedLen(b); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if n != int(size) {
+			return nil, lazyerrors.Errorf("expected uncompressed size %d, got %d", size, n)
+		}
+
+		res, err = snappy.Decode(nil, b)
+
+	case CompressorZlib:
+		var r io.ReadCloser
+		if r, err = zlib.NewReader(bytes.NewReader(b)); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		// read at most one byte more than expected to detect size mismatch
+		res, err = io.ReadAll(io.LimitReader(r, int64(size)+1))
+		if e := r.Close(); err == nil {
+			err = e
+		}
+
+	case CompressorZstd:
+		if err = initZstd(); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		res, err = zstdDecoder.DecodeAll(b, make([]byte, 0, size))
+
+	default:
+		return nil, lazyerrors.Errorf("unsupported compressor %s", compressor)
+	}
+
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if len(res) != int(size) {
+		return nil, lazyerrors.Errorf("expected uncompressed size %d, got %d", size, len(res))
+	}
+
+	return res, nil
+}
@@ -0,0 +1,227 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// OpCompressed is a message that wraps another OP_MSG, OP_QUERY or OP_REPLY message
+// compressed with one of the supported compressors.
+type OpCompressed struct {
+	OriginalOpCode OpCode
+	CompressorID   CompressorID
+
+	uncompressedSize  int32
+	compressedMessage []byte
+	msg               MsgBody
+}
+
+// Compress returns a header and an OP_COMPRESSED message wrapping the given message
+// compressed with the given compressor.
+//
+// The returned header has the same request ID and response to fields as the given one.
+func Compress(header *MsgHeader, msg MsgBody, compressor CompressorID) (*MsgHeader, *OpCompressed, error) {
+	var opCode OpCode
+
+	switch msg.(type) {
+	case *OpMsg:
+		opCode = OpCodeMsg
+	case *OpQuery:
+		opCode = OpCodeQuery
+	case *OpReply:
+		opCode = OpCodeReply
+	default:
+		return nil, nil, lazyerrors.Errorf("can't compress %T", msg)
+	}
+
+	b, err := msg.MarshalBinary()
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	compressed, err := compress(compressor, b)
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	res := &OpCompressed{
+		OriginalOpCode:    opCode,
+		CompressorID:      compressor,
+		uncompressedSize:  int32(len(b)),
+		compressedMessage: compressed,
+		msg:               msg,
+	}
+
+	resHeader := &MsgHeader{
+		MessageLength: int32(MsgHeaderLen + opCompressedPrefixLen + len(compressed)),
+		RequestID:     header.RequestID,
+		ResponseTo:    header.ResponseTo,
+		OpCode:        OpCodeCompressed,
+	}
+
+	return resHeader, res, nil
+}
+
+// opCompressedPrefixLen is the length of the original opcode, uncompressed size and compressor ID fields.
+const opCompressedPrefixLen = 4 + 4 + 1
+
+func (msg *OpCompressed) msgbody() {}
+
+// Decompress returns the header and the body of the original uncompressed message.
+//
+// The returned header has the same request ID and response to fields as the given OP_COMPRESSED message header.
+func (msg *OpCompressed) Decompress(header *MsgHeader) (*MsgHeader, MsgBody) {
+	resHeader := &MsgHeader{
+		MessageLength: MsgHeaderLen + msg.uncompressedSize,
+		RequestID:     header.RequestID,
+		ResponseTo:    header.ResponseTo,
+		OpCode:        msg.OriginalOpCode,
+	}
+
+	return resHeader, msg.msg
+}
+
+func (msg *OpCompressed) readFrom(bufr *bufio.Reader) error {
+	if err := binary.Read(bufr, binary.LittleEndian, &msg.OriginalOpCode); err != nil {
+		return lazyerrors.Errorf("wire.OpCompressed.readFrom (binary.Read): %w", err)
+	}
+
+	if err := binary.Read(bufr, binary.LittleEndian, &msg.uncompressedSize); err != nil {
+		return lazyerrors.Errorf("wire.OpCompressed.readFrom (binary.Read): %w", err)
+	}
+
+	if err := binary.Read(bufr, binary.LittleEndian, &msg.CompressorID); err != nil {
+		return lazyerrors.Errorf("wire.OpCompressed.readFrom (binary.Read): %w", err)
+	}
+
+	if s := msg.uncompressedSize; s < 0 || s > MaxMsgLen-MsgHeaderLen {
+		return lazyerrors.Errorf("wire.OpCompressed.readFrom: invalid uncompressed size %d", s)
+	}
+
+	var err error
+	if msg.compressedMessage, err = io.ReadAll(bufr); err != nil {
+		return lazyerrors.Errorf("wire.OpCompressed.readFrom: %w", err)
+	}
+
+	b, err := decompress(msg.CompressorID, msg.compressedMessage, msg.uncompressedSize)
+	if err != nil {
+		return lazyerrors.Errorf("wire.OpCompressed.readFrom: %w", err)
+	}
+
+	switch msg.OriginalOpCode {
+	case OpCodeMsg:
+		var m OpMsg
+		if err = m.UnmarshalBinary(b); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		msg.msg = &m
+
+	case OpCodeQuery:
+		var q OpQuery
+		if err = q.UnmarshalBinary(b); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		msg.msg = &q
+
+	case OpCodeReply:
+		var r OpReply
+		if err = r.UnmarshalBinary(b); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		msg.msg = &r
+
+	default:
+		return lazyerrors.Errorf("wire.OpCompressed.readFrom: unexpected original opcode %s", msg.OriginalOpCode)
+	}
+
+	return nil
+}
+
+// UnmarshalBinary reads an OpCompressed from a byte array.
+func (msg *OpCompressed) UnmarshalBinary(b []byte) error {
+	br := bytes.NewReader(b)
+	bufr := bufio.NewReader(br)
+
+	if err := msg.readFrom(bufr); err != nil {
+		return lazyerrors.Errorf("wire.OpCompressed.UnmarshalBinary: %w", err)
+	}
+
+	return nil
+}
+
+// MarshalBinary writes an OpCompressed to a byte array.
+func (msg *OpCompressed) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	bufw := bufio.NewWriter(&buf)
+
+	if err := binary.Write(bufw, binary.LittleEndian, msg.OriginalOpCode); err != nil {
+		return nil, lazyerrors.Errorf("wire.OpCompressed.MarshalBinary (binary.Write): %w", err)
+	}
+
+	if err := binary.Write(bufw, binary.LittleEndian, msg.uncompressedSize); err != nil {
+		return nil, lazyerrors.Errorf("wire.OpCompressed.MarshalBinary (binary.Write): %w", err)
+	}
+
+	if err := binary.Write(bufw, binary.LittleEndian, msg.CompressorID); err != nil {
+		return nil, lazyerrors.Errorf("wire.OpCompressed.MarshalBinary (binary.Write): %w", err)
+	}
+
+	if _, err := bufw.Write(msg.compressedMessage); err != nil {
+		return nil, lazyerrors.Errorf("wire.OpCompressed.MarshalBinary: %w", err)
+	}
+
+	if err := bufw.Flush(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// String returns a string representation for logging.
+func (msg *OpCompressed) String() string {
+	if msg == nil {
+		return "<nil>"
+	}
+
+	m := map[string]any{
+		"OriginalOpCode":   msg.OriginalOpCode.String(),
+		"UncompressedSize": msg.uncompressedSize,
+		"CompressorID":     msg.CompressorID.String(),
+	}
+
+	if msg.msg != nil {
+		m["Message"] = json.RawMessage(msg.msg.String())
+	}
+
+	return string(must.NotFail(json.MarshalIndent(m, "", "  ")))
+}
+
+// check interfaces
+var (
+	_ MsgBody      = (*OpCompressed)(nil)
+	_ fmt.Stringer = CompressorID(0)
+)
@@ -0,0 +1,128 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// noopCompressedB returns handshake5 message wrapped into OP_COMPRESSED with noop compressor.
+func noopCompressedB() []byte {
+	body := testutil.MustParseDumpFile("testdata", "handshake5_body.hex")
+
+	b := []byte{
+		0x65, 0x00, 0x00, 0x00, // MessageLength = 16 + 9 + 76
+		0x03, 0x00, 0x00, 0x00, // RequestID
+		0x00, 0x00, 0x00, 0x00, // ResponseTo
+		0xdc, 0x07, 0x00, 0x00, // OpCode = OP_COMPRESSED
+		0xdd, 0x07, 0x00, 0x00, // OriginalOpCode = OP_MSG
+		0x4c, 0x00, 0x00, 0x00, // UncompressedSize
+		0x00, // CompressorID = noop
+	}
+
+	return append(b, body...)
+}
+
+var compressedTestCases = []testCase{{
+	name:      "noop",
+	expectedB: noopCompressedB(),
+	msgHeader: &MsgHeader{
+		MessageLength: 101,
+		RequestID:     3,
+		OpCode:        OpCodeCompressed,
+	},
+	msgBody: &OpCompressed{
+		OriginalOpCode:    OpCodeMsg,
+		CompressorID:      CompressorNoop,
+		uncompressedSize:  76,
+		compressedMessage: testutil.MustParseDumpFile("testdata", "handshake5_body.hex"),
+		msg:               msgTestCases[0].msgBody,
+	},
+}, {
+	name: "UnsupportedCompressor",
+	expectedB: []byte{
+		0x1a, 0x00, 0x00, 0x00, // MessageLength
+		0x03, 0x00, 0x00, 0x00, // RequestID
+		0x00, 0x00, 0x00, 0x00, // ResponseTo
+		0xdc, 0x07, 0x00, 0x00, // OpCode = OP_COMPRESSED
+		0xdd, 0x07, 0x00, 0x00, // OriginalOpCode = OP_MSG
+		0x01, 0x00, 0x00, 0x00, // UncompressedSize
+		0x04, // CompressorID
+		0x00,
+	},
+	err: "unsupported compressor CompressorID(4)",
+}, {
+	name: "SizeMismatch",
+	expectedB: []byte{
+		0x1a, 0x00, 0x00, 0x00, // MessageLength
+		0x03, 0x00, 0x00, 0x00, // RequestID
+		0x00, 0x00, 0x00, 0x00, // ResponseTo
+		0xdc, 0x07, 0x00, 0x00, // OpCode = OP_COMPRESSED
+		0xdd, 0x07, 0x00, 0x00, // OriginalOpCode = OP_MSG
+		0x02, 0x00, 0x00, 0x00, // UncompressedSize
+		0x00, // CompressorID = noop
+		0x00,
+	},
+	err: "expected uncompressed size 2, got 1",
+}}
+
+func TestCompressed(t *testing.T) {
+	t.Parallel()
+	testMessages(t, compressedTestCases)
+}
+
+func FuzzCompressed(f *testing.F) {
+	fuzzMessages(f, compressedTestCases)
+}
+
+func TestCompressRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, compressor := range []CompressorID{CompressorNoop, CompressorSnappy, CompressorZlib, CompressorZstd} {
+		compressor := compressor
+		t.Run(compressor.String(), func(t *testing.T) {
+			t.Parallel()
+
+			for _, tc := range append(msgTestCases[:2:2], replyTestCases...) {
+				header, body, err := Compress(tc.msgHeader, tc.msgBody, compressor)
+				require.NoError(t, err)
+
+				var buf bytes.Buffer
+				bufw := bufio.NewWriter(&buf)
+				require.NoError(t, WriteMessage(bufw, header, body))
+				require.NoError(t, bufw.Flush())
+
+				readHeader, readBody, err := ReadMessage(bufio.NewReader(&buf))
+				require.NoError(t, err)
+				assert.Equal(t, header, readHeader)
+
+				compressed, ok := readBody.(*OpCompressed)
+				require.True(t, ok)
+				assert.Equal(t, compressor, compressed.CompressorID)
+
+				originalHeader, originalBody := compressed.Decompress(readHeader)
+				assert.Equal(t, tc.msgHeader, originalHeader)
+				assert.Equal(t, tc.msgBody, originalBody)
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ package wire
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"io/fs"
 	"math/rand"
@@ -25,6 +26,9 @@ import (
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 )
 
+// recordsDirKey is a named unexported type for the safe use of context.WithValue.
+type recordsDirKey struct{}
+
 // Record represents a single recorded wire protocol message, loaded from a .bin file.
 type Record struct {
 	// those may be unset if message is invalid
@@ -125,3 +129,14 @@ func loadRecordFile(file string) ([]Record, error) {
 
 	return res, nil
 }
+
+// WithRecordsDir returns a new context with the directory of record files.
+func WithRecordsDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, recordsDirKey{}, dir)
+}
+
+// GetRecordsDir returns the directory of record files stored in ctx, or empty string.
+func GetRecordsDir(ctx context.Context) string {
+	dir, _ := ctx.Value(recordsDirKey{}).(string)
+	return dir
+}